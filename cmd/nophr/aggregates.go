@@ -0,0 +1,109 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/sandwich/nophr/internal/aggregates"
+	"github.com/sandwich/nophr/internal/config"
+	"github.com/sandwich/nophr/internal/nostr/helpers"
+	"github.com/sandwich/nophr/internal/storage"
+)
+
+// handleAggregates handles the "nophr aggregates" subcommands
+func handleAggregates(args []string) {
+	if len(args) == 0 || args[0] != "rebuild" {
+		printAggregatesUsage()
+		os.Exit(1)
+	}
+
+	fs := flag.NewFlagSet("aggregates rebuild", flag.ExitOnError)
+	configPath := fs.String("config", globalConfig, "Path to configuration file")
+	eventID := fs.String("event", "", "Rebuild only this event (note1 or hex ID)")
+	batch := fs.Int("batch", 100, "Aggregates written per transaction")
+	pause := fs.Duration("pause", 100*time.Millisecond, "Wait between batches")
+	dryRun := fs.Bool("dry-run", false, "Report discrepancies without writing")
+	fs.Parse(args[1:])
+
+	if *configPath == "" {
+		fmt.Fprintln(os.Stderr, "Error: --config is required")
+		os.Exit(1)
+	}
+
+	cfg, err := config.Load(*configPath)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error loading configuration: %v\n", err)
+		os.Exit(1)
+	}
+
+	opts := aggregates.RebuildOptions{BatchSize: *batch, Pause: *pause, DryRun: *dryRun}
+	if *eventID != "" {
+		if opts.EventID, err = helpers.NormalizeEventID(*eventID); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+	}
+
+	ctx := context.Background()
+	st, err := storage.New(ctx, &cfg.Storage)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error initializing storage: %v\n", err)
+		os.Exit(1)
+	}
+	defer st.Close()
+
+	if err := runRebuild(ctx, aggregates.NewReconciler(st, aggregates.NewManager(st, cfg)), opts); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+// runRebuild recomputes aggregates and prints each discrepancy it found
+func runRebuild(ctx context.Context, r *aggregates.Reconciler, opts aggregates.RebuildOptions) error {
+	target := "all events"
+	if opts.EventID != "" {
+		target = opts.EventID
+	}
+	fmt.Printf("Rebuilding aggregates for %s...\n", target)
+
+	report, err := r.Rebuild(ctx, opts)
+	if report != nil {
+		for _, d := range report.Discrepancies {
+			fmt.Printf("%s  %s\n", d.EventID, strings.Join(d.Changes(), ", "))
+		}
+	}
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("\n%d interactions counted, %d aggregates checked\n", report.Interactions, report.Checked)
+	switch {
+	case len(report.Discrepancies) == 0:
+		fmt.Println("✓ Aggregates match the stored interactions")
+	case opts.DryRun:
+		fmt.Printf("%d discrepancies found\n", len(report.Discrepancies))
+		fmt.Println("Dry run: nothing was written")
+	default:
+		fmt.Printf("✓ Fixed %d discrepancies\n", len(report.Discrepancies))
+	}
+
+	return nil
+}
+
+// printAggregatesUsage prints usage for the aggregates subcommands
+func printAggregatesUsage() {
+	fmt.Println("Usage: nophr aggregates rebuild --config <path> [flags]")
+	fmt.Println()
+	fmt.Println("Recomputes reply, reaction and zap counts from the stored interactions")
+	fmt.Println("and overwrites those that have drifted. Safe to run while nophr is serving.")
+	fmt.Println()
+	fmt.Println("Flags:")
+	fmt.Println("  --event <id>      Rebuild only this event (note1 or hex)")
+	fmt.Println("  --batch N         Aggregates written per transaction (default 100)")
+	fmt.Println("  --pause D         Wait between batches (default 100ms)")
+	fmt.Println("  --dry-run         Report discrepancies without writing")
+}
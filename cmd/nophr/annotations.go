@@ -0,0 +1,61 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/sandwich/nophr/internal/config"
+	"github.com/sandwich/nophr/internal/storage"
+)
+
+// handleAnnotations handles the "nophr annotations" subcommands
+func handleAnnotations(args []string) {
+	if len(args) == 0 || args[0] != "rebuild" {
+		printAnnotationsUsage()
+		os.Exit(1)
+	}
+
+	fs := flag.NewFlagSet("annotations rebuild", flag.ExitOnError)
+	configPath := fs.String("config", globalConfig, "Path to configuration file")
+	fs.Usage = printAnnotationsUsage
+	fs.Parse(args[1:])
+
+	if *configPath == "" {
+		fmt.Fprintln(os.Stderr, "Error: --config is required")
+		os.Exit(1)
+	}
+
+	cfg, err := config.Load(*configPath)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error loading configuration: %v\n", err)
+		os.Exit(1)
+	}
+
+	ctx := context.Background()
+	st, err := storage.New(ctx, &cfg.Storage)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error initializing storage: %v\n", err)
+		os.Exit(1)
+	}
+	defer st.Close()
+
+	fmt.Println("Reclassifying stored notes and articles...")
+	started := time.Now()
+	if err := st.RebuildAnnotations(ctx); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	fmt.Printf("✓ Annotations rebuilt in %s\n", time.Since(started).Round(time.Millisecond))
+}
+
+// printAnnotationsUsage prints usage for the annotations subcommands
+func printAnnotationsUsage() {
+	fmt.Println("Usage: nophr annotations rebuild --config <path>")
+	fmt.Println()
+	fmt.Println("Reclassifies every stored note and article: language, hashtags and the")
+	fmt.Println("topics in storage.annotations.topics. Events are classified as they are")
+	fmt.Println("stored, so run this after changing topics. Safe to run while nophr is serving.")
+}
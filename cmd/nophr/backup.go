@@ -0,0 +1,154 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/sandwich/nophr/internal/config"
+	"github.com/sandwich/nophr/internal/ops"
+	"github.com/sandwich/nophr/internal/storage"
+)
+
+// handleBackup handles "nophr backup", writing a driver-agnostic snapshot
+func handleBackup(args []string) {
+	fs := flag.NewFlagSet("backup", flag.ExitOnError)
+	configPath := fs.String("config", globalConfig, "Path to configuration file")
+	out := fs.String("out", "", "Snapshot file (.tar.zst, .tar.gz or .tar)")
+	fs.Usage = printBackupUsage
+	fs.Parse(args)
+
+	if *configPath == "" || *out == "" || fs.NArg() != 0 {
+		printBackupUsage()
+		os.Exit(1)
+	}
+
+	cfg, err := config.Load(*configPath)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error loading configuration: %v\n", err)
+		os.Exit(1)
+	}
+
+	if err := runBackup(cfg, *out); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+// runBackup writes the snapshot next to out and renames it into place, so an
+// interrupted backup never leaves a truncated snapshot behind
+func runBackup(cfg *config.Config, out string) error {
+	ctx := context.Background()
+	st, err := storage.New(ctx, &cfg.Storage)
+	if err != nil {
+		return fmt.Errorf("failed to initialize storage: %w", err)
+	}
+	defer st.Close()
+
+	tmp := out + ".partial"
+	f, err := os.Create(tmp)
+	if err != nil {
+		return fmt.Errorf("failed to create snapshot: %w", err)
+	}
+	defer os.Remove(tmp)
+	defer f.Close()
+
+	fmt.Printf("Backing up %s storage to %s...\n", st.Driver(), out)
+	manifest, err := ops.WriteSnapshot(ctx, st, f, ops.SnapshotCompression(out), version)
+	if err != nil {
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("failed to write snapshot: %w", err)
+	}
+	if err := os.Rename(tmp, out); err != nil {
+		return fmt.Errorf("failed to write snapshot: %w", err)
+	}
+
+	fmt.Println("Backup complete")
+	fmt.Printf("  Events:             %d\n", manifest.Events)
+	fmt.Printf("  Aggregates:         %d\n", manifest.Aggregates)
+	fmt.Printf("  Sync cursors:       %d\n", manifest.Cursors)
+	fmt.Printf("  Retention metadata: %d\n", manifest.RetentionMetadata)
+	return nil
+}
+
+func printBackupUsage() {
+	fmt.Println("Usage: nophr backup --config <path> --out <snapshot>")
+	fmt.Println()
+	fmt.Println("Write events, aggregates, sync cursors and retention metadata to a snapshot")
+	fmt.Println("that 'nophr restore' can load into any storage driver. The extension picks")
+	fmt.Println("the compression: .tar.zst (zstd), .tar.gz (gzip) or .tar (none).")
+}
+
+// handleRestore handles "nophr restore", loading a snapshot into storage
+func handleRestore(args []string) {
+	fs := flag.NewFlagSet("restore", flag.ExitOnError)
+	configPath := fs.String("config", globalConfig, "Path to configuration file")
+	fs.Usage = printRestoreUsage
+	fs.Parse(args)
+
+	if *configPath == "" || fs.NArg() != 1 {
+		printRestoreUsage()
+		os.Exit(1)
+	}
+
+	cfg, err := config.Load(*configPath)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error loading configuration: %v\n", err)
+		os.Exit(1)
+	}
+
+	if err := runRestore(cfg, fs.Arg(0)); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+// runRestore loads the snapshot at path ("-" for stdin) into the configured storage
+func runRestore(cfg *config.Config, path string) error {
+	var r io.Reader = os.Stdin
+	if path != "-" {
+		f, err := os.Open(path)
+		if err != nil {
+			return fmt.Errorf("failed to open snapshot: %w", err)
+		}
+		defer f.Close()
+		r = f
+	}
+
+	ctx := context.Background()
+	st, err := storage.New(ctx, &cfg.Storage)
+	if err != nil {
+		return fmt.Errorf("failed to initialize storage: %w", err)
+	}
+	defer st.Close()
+
+	fmt.Printf("Restoring %s into %s storage...\n", path, st.Driver())
+	manifest, result, err := ops.RestoreSnapshot(ctx, st, r)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Restore complete (snapshot of %s storage from nophr %s, %s)\n",
+		manifest.Driver, manifest.Version, manifest.CreatedAt.Format("2006-01-02 15:04 MST"))
+	fmt.Printf("  Events:             %d\n", result.Events)
+	fmt.Printf("  Already stored:     %d\n", result.Existing)
+	fmt.Printf("  Aggregates:         %d\n", result.Aggregates)
+	fmt.Printf("  Sync cursors:       %d\n", result.Cursors)
+	fmt.Printf("  Retention metadata: %d\n", result.RetentionMetadata)
+	if result.Orphaned > 0 {
+		fmt.Printf("  Skipped metadata:   %d (event not in snapshot)\n", result.Orphaned)
+	}
+	return nil
+}
+
+func printRestoreUsage() {
+	fmt.Println("Usage: nophr restore --config <path> <snapshot>")
+	fmt.Println()
+	fmt.Println("Load a snapshot written by 'nophr backup' into the configured storage. Use -")
+	fmt.Println("to read stdin. Events already stored are kept; aggregates, sync cursors and")
+	fmt.Println("retention metadata from the snapshot replace stored ones. Stop nophr first.")
+}
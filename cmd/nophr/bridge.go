@@ -0,0 +1,68 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/sandwich/nophr/internal/config"
+	internalnostr "github.com/sandwich/nophr/internal/nostr"
+	"github.com/sandwich/nophr/internal/outbox"
+	"github.com/sandwich/nophr/internal/storage"
+)
+
+// handleBridge handles "nophr bridge", polling each bridged feed once
+func handleBridge(args []string) {
+	fs := flag.NewFlagSet("bridge", flag.ExitOnError)
+	configPath := fs.String("config", globalConfig, "Path to configuration file")
+	fs.Usage = printBridgeUsage
+	fs.Parse(args)
+
+	if *configPath == "" {
+		fmt.Fprintln(os.Stderr, "Error: --config is required")
+		os.Exit(1)
+	}
+
+	cfg, err := config.Load(*configPath)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error loading configuration: %v\n", err)
+		os.Exit(1)
+	}
+	if len(cfg.Outbox.Bridge.Feeds) == 0 {
+		fmt.Fprintln(os.Stderr, "Error: no feeds configured in outbox.bridge.feeds")
+		os.Exit(1)
+	}
+
+	ctx := context.Background()
+	st, err := storage.New(ctx, &cfg.Storage)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error initializing storage: %v\n", err)
+		os.Exit(1)
+	}
+	defer st.Close()
+
+	publisher, err := outbox.NewPublisher(cfg, st, internalnostr.New(ctx, &cfg.Relays))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	results := outbox.NewBridge(publisher).PollAll(ctx)
+	for _, result := range results {
+		fmt.Printf("✓ %s: %d published, %d recorded, %d failed\n", result.Feed, result.Published, result.Recorded, result.Failed)
+	}
+	if len(results) < len(cfg.Outbox.Bridge.Feeds) {
+		os.Exit(1)
+	}
+}
+
+// printBridgeUsage prints usage for the bridge command
+func printBridgeUsage() {
+	fmt.Println("Usage: nophr bridge --config <path>")
+	fmt.Println()
+	fmt.Println("Polls every feed in outbox.bridge.feeds once and publishes the items not")
+	fmt.Println("bridged before, signed with NOPHR_NSEC. On a feed's first poll its items")
+	fmt.Println("are only recorded, unless the feed sets backfill: true. \"nophr serve\" polls")
+	fmt.Println("the feeds on its own when outbox.bridge.enabled is true.")
+}
@@ -0,0 +1,445 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/nbd-wtf/go-nostr"
+	"github.com/nbd-wtf/go-nostr/nip19"
+	"github.com/sandwich/nophr/internal/aggregates"
+	"github.com/sandwich/nophr/internal/cache"
+	"github.com/sandwich/nophr/internal/config"
+	"github.com/sandwich/nophr/internal/finger"
+	"github.com/sandwich/nophr/internal/gemini"
+	"github.com/sandwich/nophr/internal/gopher"
+	"github.com/sandwich/nophr/internal/nntp"
+	"github.com/sandwich/nophr/internal/ops"
+	"github.com/sandwich/nophr/internal/pages"
+	"github.com/sandwich/nophr/internal/presentation"
+	"github.com/sandwich/nophr/internal/qotd"
+	"github.com/sandwich/nophr/internal/relay"
+	"github.com/sandwich/nophr/internal/sections"
+	"github.com/sandwich/nophr/internal/security"
+	"github.com/sandwich/nophr/internal/storage"
+	"github.com/sandwich/nophr/internal/sync"
+	"github.com/sandwich/nophr/internal/telnet"
+)
+
+// checkTimeout bounds each request the check makes
+const checkTimeout = 10 * time.Second
+
+// handleCheck handles "nophr check", a smoke test of a config before deploying it
+func handleCheck(args []string) {
+	fs := flag.NewFlagSet("check", flag.ExitOnError)
+	configPath := fs.String("config", globalConfig, "Path to configuration file")
+	offline := fs.Bool("offline", false, "Don't contact relays or Redis")
+	fs.Usage = printCheckUsage
+	fs.Parse(args)
+
+	if *configPath == "" || fs.NArg() != 0 {
+		printCheckUsage()
+		os.Exit(1)
+	}
+
+	cfg, err := config.Load(*configPath)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error loading configuration: %v\n", err)
+		os.Exit(1)
+	}
+	fmt.Printf("  ✓ configuration %s\n", *configPath)
+
+	if err := runCheck(cfg, *offline); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+// checker prints the outcome of each check and counts the failures
+type checker struct {
+	failed int
+}
+
+// run reports whether fn succeeded
+func (c *checker) run(name string, fn func() error) {
+	if err := fn(); err != nil {
+		c.failed++
+		fmt.Printf("  ✗ %s: %v\n", name, err)
+		return
+	}
+	fmt.Printf("  ✓ %s\n", name)
+}
+
+// skip reports a check that wasn't run
+func (c *checker) skip(name, reason string) {
+	fmt.Printf("  - %s: skipped (%s)\n", name, reason)
+}
+
+// runCheck builds every subsystem the config enables against a scratch
+// database, starts each enabled server on a free loopback port and requests
+// its home page. Relays are only contacted when offline is false.
+func runCheck(cfg *config.Config, offline bool) error {
+	ctx := context.Background()
+	c := &checker{}
+
+	// A scratch database, so the check never migrates or writes to the live one
+	dir, err := os.MkdirTemp("", "nophr-check-")
+	if err != nil {
+		return fmt.Errorf("failed to create scratch directory: %w", err)
+	}
+	defer os.RemoveAll(dir)
+
+	storageCfg := cfg.Storage
+	storageCfg.SQLitePath = filepath.Join(dir, "nophr.db")
+	storageCfg.LMDBPath = filepath.Join(dir, "nophr.lmdb")
+
+	var st *storage.Storage
+	c.run(fmt.Sprintf("storage (%s)", storageCfg.Driver), func() error {
+		st, err = storage.New(ctx, &storageCfg)
+		return err
+	})
+	if st == nil {
+		return fmt.Errorf("storage failed, skipping the remaining checks")
+	}
+	defer st.Close()
+	if denied := cfg.Sync.Scope.DenylistPubkeys; len(denied) > 0 {
+		st.SetEventFilter(security.NewCombinedFilter(security.NewDenyList(denied), nil).IsEventAllowed)
+	}
+
+	aggMgr := aggregates.NewManager(st, cfg)
+
+	c.run("retention", func() error {
+		retentionMgr := ops.NewRetentionManager(st, &cfg.Sync.Retention, ops.NewLogger(&cfg.Logging), cfg.Identity.Npub)
+		retentionMgr.Stop()
+		return nil
+	})
+
+	switch {
+	case !cfg.Caching.Enabled:
+	case cfg.Caching.Engine == "redis" && offline:
+		c.skip("cache (redis)", "offline")
+	default:
+		c.run(fmt.Sprintf("cache (%s)", cfg.Caching.Engine), func() error {
+			cacheCfg := cache.DefaultConfig()
+			cacheCfg.Engine = cfg.Caching.Engine
+			cacheCfg.RedisURL = cfg.Caching.RedisURL
+			responseCache, err := cache.New(cacheCfg)
+			if err != nil {
+				return err
+			}
+			return responseCache.Close()
+		})
+	}
+
+	if cfg.Sync.Enabled {
+		c.run("sync engine", func() error {
+			// Constructed but not started, so no relay is contacted
+			sync.NewEngine(st, cfg)
+			return nil
+		})
+	}
+
+	sectionManager := sections.NewManager(st)
+	if _, owner, err := nip19.Decode(cfg.Identity.Npub); err == nil {
+		sectionManager.SetOwner(owner.(string))
+	}
+	sectionManager.SetScopeLimits(&cfg.Sync.Scope)
+	c.run(fmt.Sprintf("sections (%d)", len(cfg.Sections)), func() error {
+		return sections.LoadFromConfig(sectionManager, cfg.Sections)
+	})
+
+	sitePages := pages.Load(cfg).All()
+	c.run(fmt.Sprintf("pages (%d)", len(sitePages)), func() error {
+		for _, page := range sitePages {
+			if _, err := page.Source(); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+
+	c.run("templates", func() error {
+		return presentation.CheckTemplates(cfg)
+	})
+
+	protocols := &cfg.Protocols
+
+	if protocols.Gopher.Enabled {
+		c.run("gopher home", func() error {
+			addr, err := useFreePort(&protocols.Gopher.Bind, &protocols.Gopher.Port)
+			if err != nil {
+				return err
+			}
+			server := gopher.New(&protocols.Gopher, cfg, st, protocols.Gopher.Host, aggMgr)
+			server.SetSectionManager(sectionManager)
+			if err := server.Start(); err != nil {
+				return err
+			}
+			defer server.Stop()
+
+			response, err := dialExchange(addr, "\r\n")
+			if err != nil {
+				return err
+			}
+			if strings.HasPrefix(response, "3") {
+				return fmt.Errorf("error item: %s", strings.SplitN(response[1:], "\t", 2)[0])
+			}
+			return nil
+		})
+	}
+
+	if protocols.Gemini.Enabled {
+		if tlsCfg := protocols.Gemini.TLS; tlsCfg.CertPath != "" && tlsCfg.KeyPath != "" && !tlsCfg.AutoGenerate {
+			if _, err := os.Stat(tlsCfg.CertPath); err == nil {
+				c.run("gemini certificate", func() error {
+					_, err := tls.LoadX509KeyPair(tlsCfg.CertPath, tlsCfg.KeyPath)
+					return err
+				})
+			}
+		}
+
+		c.run("gemini home", func() error {
+			addr, err := useFreePort(&protocols.Gemini.Bind, &protocols.Gemini.Port)
+			if err != nil {
+				return err
+			}
+			// Serve a throwaway certificate rather than loading or overwriting the live one
+			protocols.Gemini.TLS.CertPath = ""
+			protocols.Gemini.TLS.KeyPath = ""
+			server, err := gemini.New(&protocols.Gemini, cfg, st, protocols.Gemini.Host, aggMgr)
+			if err != nil {
+				return err
+			}
+			server.SetSectionManager(sectionManager)
+			if err := server.Start(); err != nil {
+				return err
+			}
+			defer server.Stop()
+
+			conn, err := tls.DialWithDialer(&net.Dialer{Timeout: checkTimeout}, "tcp", addr, &tls.Config{InsecureSkipVerify: true})
+			if err != nil {
+				return err
+			}
+			response, err := exchange(conn, fmt.Sprintf("gemini://%s/\r\n", protocols.Gemini.Host))
+			if err != nil {
+				return err
+			}
+			if status := strings.SplitN(response, "\r\n", 2)[0]; !strings.HasPrefix(status, "20") {
+				return fmt.Errorf("unexpected response %q", status)
+			}
+			return nil
+		})
+	}
+
+	if protocols.Finger.Enabled {
+		c.run("finger owner", func() error {
+			addr, err := useFreePort(&protocols.Finger.Bind, &protocols.Finger.Port)
+			if err != nil {
+				return err
+			}
+			server := finger.New(&protocols.Finger, cfg, st, aggMgr)
+			server.SetSectionManager(sectionManager)
+			if err := server.Start(); err != nil {
+				return err
+			}
+			defer server.Stop()
+
+			_, err = dialExchange(addr, "\r\n")
+			return err
+		})
+	}
+
+	if protocols.NNTP.Enabled {
+		c.run("nntp greeting", func() error {
+			addr, err := useFreePort(&protocols.NNTP.Bind, &protocols.NNTP.Port)
+			if err != nil {
+				return err
+			}
+			server := nntp.New(&protocols.NNTP, cfg, st, aggMgr)
+			server.SetSectionManager(sectionManager)
+			if err := server.Start(); err != nil {
+				return err
+			}
+			defer server.Stop()
+
+			response, err := dialExchange(addr, "")
+			if err != nil {
+				return err
+			}
+			if !strings.HasPrefix(response, "200") && !strings.HasPrefix(response, "201") {
+				return fmt.Errorf("unexpected greeting %q", strings.TrimSpace(response))
+			}
+			return nil
+		})
+	}
+
+	if protocols.Telnet.Enabled {
+		c.run("telnet menu", func() error {
+			addr, err := useFreePort(&protocols.Telnet.Bind, &protocols.Telnet.Port)
+			if err != nil {
+				return err
+			}
+			server := telnet.New(&protocols.Telnet, cfg, st, aggMgr)
+			server.SetSectionManager(sectionManager)
+			if err := server.Start(); err != nil {
+				return err
+			}
+			defer server.Stop()
+
+			_, err = dialExchange(addr, "")
+			return err
+		})
+	}
+
+	if protocols.QOTD.Enabled {
+		c.run("qotd quote", func() error {
+			addr, err := useFreePort(&protocols.QOTD.Bind, &protocols.QOTD.Port)
+			if err != nil {
+				return err
+			}
+			server := qotd.New(&protocols.QOTD, cfg, st, aggMgr)
+			if err := server.Start(); err != nil {
+				return err
+			}
+			defer server.Stop()
+
+			_, err = dialExchange(addr, "")
+			return err
+		})
+	}
+
+	if protocols.Relay.Enabled {
+		c.run("relay info document", func() error {
+			addr, err := useFreePort(&protocols.Relay.Bind, &protocols.Relay.Port)
+			if err != nil {
+				return err
+			}
+			server := relay.New(&protocols.Relay, cfg, st, aggMgr)
+			if err := server.Start(); err != nil {
+				return err
+			}
+			defer server.Stop()
+
+			req, err := http.NewRequest(http.MethodGet, "http://"+addr+"/", nil)
+			if err != nil {
+				return err
+			}
+			req.Header.Set("Accept", "application/nostr+json")
+			resp, err := (&http.Client{Timeout: checkTimeout}).Do(req)
+			if err != nil {
+				return err
+			}
+			defer resp.Body.Close()
+			if resp.StatusCode != http.StatusOK {
+				return fmt.Errorf("unexpected status %s", resp.Status)
+			}
+			var info map[string]any
+			return json.NewDecoder(resp.Body).Decode(&info)
+		})
+	}
+
+	for _, url := range cfg.Relays.Seeds {
+		name := "seed relay " + url
+		if offline {
+			c.skip(name, "offline")
+			continue
+		}
+		c.run(name, func() error {
+			ctx, cancel := context.WithTimeout(ctx, checkTimeout)
+			defer cancel()
+			r, err := nostr.RelayConnect(ctx, url)
+			if err != nil {
+				return err
+			}
+			return r.Close()
+		})
+	}
+
+	if c.failed > 0 {
+		return fmt.Errorf("%d checks failed", c.failed)
+	}
+	fmt.Println("All checks passed")
+	return nil
+}
+
+// useFreePort points a server's bind address and port at a free loopback
+// port, so the check can run next to a live instance, and returns the address
+func useFreePort(bind *string, port *int) (string, error) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return "", fmt.Errorf("no free port: %w", err)
+	}
+	defer listener.Close()
+
+	*bind = "127.0.0.1"
+	*port = listener.Addr().(*net.TCPAddr).Port
+	return listener.Addr().String(), nil
+}
+
+// dialExchange connects to a TCP server and exchanges one request
+func dialExchange(addr, request string) (string, error) {
+	conn, err := net.DialTimeout("tcp", addr, checkTimeout)
+	if err != nil {
+		return "", err
+	}
+	return exchange(conn, request)
+}
+
+// exchange sends a request, if any, and returns what the server writes back
+// until it closes the connection or goes quiet for a second
+func exchange(conn net.Conn, request string) (string, error) {
+	defer conn.Close()
+
+	conn.SetDeadline(time.Now().Add(checkTimeout))
+	if request != "" {
+		if _, err := io.WriteString(conn, request); err != nil {
+			return "", err
+		}
+	}
+
+	var response bytes.Buffer
+	buf := make([]byte, 4096)
+	for {
+		n, err := conn.Read(buf)
+		response.Write(buf[:n])
+		if err != nil {
+			var netErr net.Error
+			quiet := errors.As(err, &netErr) && netErr.Timeout()
+			switch {
+			case response.Len() > 0 && (err == io.EOF || quiet):
+				return response.String(), nil
+			case err == io.EOF:
+				return "", fmt.Errorf("connection closed without a response")
+			default:
+				return "", err
+			}
+		}
+		conn.SetReadDeadline(time.Now().Add(time.Second))
+	}
+}
+
+func printCheckUsage() {
+	fmt.Println("Usage: nophr check --config <path> [--offline]")
+	fmt.Println()
+	fmt.Println("Load the configuration, build storage, the cache, sections and pages, start")
+	fmt.Println("each enabled server on a free loopback port and request its home page, to")
+	fmt.Println("catch configuration problems before deploying. Storage is a scratch database")
+	fmt.Println("and the Gemini server uses a throwaway certificate, so nothing live is touched.")
+	fmt.Println()
+	fmt.Println("Options:")
+	fmt.Println("  --config <path>   Configuration file to check")
+	fmt.Println("  --offline         Don't connect to the seed relays or Redis")
+	fmt.Println()
+	fmt.Println("Exits with status 1 if any check fails.")
+}
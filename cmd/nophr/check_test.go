@@ -0,0 +1,59 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/sandwich/nophr/internal/config"
+)
+
+// checkConfig is the default config with Gopher, Gemini and Finger on and no
+// seed relays, so a check stays on loopback
+func checkConfig(t *testing.T) *config.Config {
+	t.Helper()
+
+	dir := t.TempDir()
+	cfg := config.Default()
+	cfg.Identity.Npub = "npub1nq3zgtqruwhnz0xx40gh4a4fkamlr2sc7ke5wqs2s3nyv2fpy9esg4hdwq"
+	cfg.Storage.SQLitePath = filepath.Join(dir, "live.db")
+	cfg.Relays.Seeds = nil
+	cfg.Protocols.Gopher.Enabled = true
+	cfg.Protocols.Gemini.Enabled = true
+	cfg.Protocols.Gemini.TLS.CertPath = filepath.Join(dir, "cert.pem")
+	cfg.Protocols.Gemini.TLS.KeyPath = filepath.Join(dir, "key.pem")
+	cfg.Protocols.Finger.Enabled = true
+	return cfg
+}
+
+func TestRunCheck(t *testing.T) {
+	cfg := checkConfig(t)
+	livePort := cfg.Protocols.Gopher.Port
+
+	if err := runCheck(cfg, true); err != nil {
+		t.Fatalf("runCheck() error = %v", err)
+	}
+
+	// The check runs against a scratch database and throwaway certificate
+	for _, path := range []string{cfg.Storage.SQLitePath, filepath.Join(filepath.Dir(cfg.Storage.SQLitePath), "cert.pem")} {
+		if _, err := os.Stat(path); !os.IsNotExist(err) {
+			t.Errorf("Expected the check to leave %s alone", path)
+		}
+	}
+	if cfg.Protocols.Gopher.Port == livePort || cfg.Protocols.Gopher.Bind != "127.0.0.1" {
+		t.Errorf("Expected Gopher to be checked on a free loopback port, got %s:%d", cfg.Protocols.Gopher.Bind, cfg.Protocols.Gopher.Port)
+	}
+}
+
+func TestRunCheckFailures(t *testing.T) {
+	cfg := checkConfig(t)
+	template := filepath.Join(t.TempDir(), "home.gmi")
+	os.WriteFile(template, []byte("{{ .Nope"), 0644)
+	cfg.Presentation.Templates.Gemini.Home = template
+
+	err := runCheck(cfg, true)
+	if err == nil || !strings.Contains(err.Error(), "1 checks failed") {
+		t.Errorf("runCheck() error = %v, want the broken template to fail one check", err)
+	}
+}
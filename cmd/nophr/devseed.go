@@ -0,0 +1,118 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/nbd-wtf/go-nostr/nip19"
+	"github.com/sandwich/nophr/internal/config"
+	"github.com/sandwich/nophr/internal/devseed"
+	"github.com/sandwich/nophr/internal/storage"
+)
+
+// handleDevseed handles "nophr devseed", filling an empty database with synthetic data
+func handleDevseed(args []string) {
+	fs := flag.NewFlagSet("devseed", flag.ExitOnError)
+	configPath := fs.String("config", globalConfig, "Path to configuration file")
+	seed := fs.Int64("seed", 1, "Seed the dataset is derived from")
+	users := fs.Int("users", 20, "Accounts besides the owner")
+	days := fs.Int("days", 30, "Days of activity")
+	end := fs.String("end", "", "Date of the newest activity, YYYY-MM-DD (default: today, UTC)")
+	fs.Usage = printDevseedUsage
+	fs.Parse(args)
+
+	if *configPath == "" || fs.NArg() != 0 {
+		printDevseedUsage()
+		os.Exit(1)
+	}
+
+	cfg, err := config.Load(*configPath)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error loading configuration: %v\n", err)
+		os.Exit(1)
+	}
+
+	opts := devseed.Options{Seed: *seed, Users: *users, Days: *days, End: time.Now().UTC().Truncate(24 * time.Hour)}
+	if *end != "" {
+		if opts.End, err = time.Parse(time.DateOnly, *end); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: --end must be a date like 2025-01-31\n")
+			os.Exit(1)
+		}
+	}
+
+	// Sign the owner's events with the real key when there is one, so the seeded
+	// site belongs to identity.npub
+	if cfg.Identity.Nsec != "" {
+		prefix, value, err := nip19.Decode(cfg.Identity.Nsec)
+		if err != nil || prefix != "nsec" {
+			fmt.Fprintln(os.Stderr, "Error: NOPHR_NSEC is not a valid nsec")
+			os.Exit(1)
+		}
+		opts.OwnerSecret = value.(string)
+	}
+
+	if err := runDevseed(cfg, opts); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+// runDevseed generates the dataset and stores it
+func runDevseed(cfg *config.Config, opts devseed.Options) error {
+	ctx := context.Background()
+	st, err := storage.New(ctx, &cfg.Storage)
+	if err != nil {
+		return fmt.Errorf("failed to initialize storage: %w", err)
+	}
+	defer st.Close()
+
+	// Synthetic events must never mix with synced ones
+	count, err := st.CountEvents(ctx)
+	if err != nil {
+		return err
+	}
+	if count > 0 {
+		return fmt.Errorf("the database already holds %d events; devseed only fills an empty one", count)
+	}
+
+	ds, err := devseed.Generate(opts)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Seeding %d events from seed %d...\n", len(ds.Events), opts.Seed)
+	if err := ds.Seed(ctx, st, cfg); err != nil {
+		return err
+	}
+
+	fmt.Println("✓ Database seeded")
+	fmt.Printf("  Accounts: %d\n", len(ds.Users)+1)
+	fmt.Printf("  Events:   %d\n", len(ds.Events))
+	fmt.Printf("  Graph:    %d nodes\n", len(ds.Graph))
+	if ds.Npub() != cfg.Identity.Npub {
+		fmt.Println()
+		fmt.Println("The seeded owner is not identity.npub. Set it to browse the data:")
+		fmt.Printf("  identity:\n    npub: %s\n", ds.Npub())
+	}
+	return nil
+}
+
+func printDevseedUsage() {
+	fmt.Println("Usage: nophr devseed --config <path> [flags]")
+	fmt.Println()
+	fmt.Println("Fill an empty database with synthetic profiles, notes, threads, reactions,")
+	fmt.Println("zaps, articles and a contact graph. The same seed and end date always give")
+	fmt.Println("the same events. Set sync.enabled: false so live relays don't mix in.")
+	fmt.Println()
+	fmt.Println("Flags:")
+	fmt.Println("  --seed N      Seed the dataset is derived from (default 1)")
+	fmt.Println("  --users N     Accounts besides the owner (default 20)")
+	fmt.Println("  --days N      Days of activity (default 30)")
+	fmt.Println("  --end DATE    Date of the newest activity, YYYY-MM-DD (default today, UTC)")
+	fmt.Println()
+	fmt.Println("Owner events are signed with NOPHR_NSEC when it is set; otherwise the owner")
+	fmt.Println("key is derived from the seed and its npub is printed.")
+}
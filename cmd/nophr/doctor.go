@@ -0,0 +1,287 @@
+package main
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/nbd-wtf/go-nostr"
+	"github.com/nbd-wtf/go-nostr/nip19"
+	"github.com/sandwich/nophr/internal/config"
+)
+
+// certExpiryWarning is how close to expiry the Gemini certificate is flagged
+const certExpiryWarning = 14 * 24 * time.Hour
+
+// ANSI colors for the doctor report
+const (
+	colorReset  = "\033[0m"
+	colorRed    = "\033[31m"
+	colorGreen  = "\033[32m"
+	colorYellow = "\033[33m"
+)
+
+// handleDoctor handles "nophr doctor", which diagnoses first-time setup problems
+func handleDoctor(args []string) {
+	fs := flag.NewFlagSet("doctor", flag.ExitOnError)
+	configPath := fs.String("config", globalConfig, "Path to configuration file")
+	noColor := fs.Bool("no-color", false, "Don't color the report")
+	fs.Usage = printDoctorUsage
+	fs.Parse(args)
+
+	if *configPath == "" || fs.NArg() != 0 {
+		printDoctorUsage()
+		os.Exit(1)
+	}
+
+	d := &doctor{color: !*noColor && os.Getenv("NO_COLOR") == "" && isTerminal(os.Stdout)}
+	if err := runDoctor(d, *configPath); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+// doctor prints each finding as pass, warn or fail and counts the failures
+type doctor struct {
+	color  bool
+	failed int
+	warned int
+}
+
+func (d *doctor) report(mark, color, name, detail string) {
+	if d.color {
+		mark = color + mark + colorReset
+	}
+	if detail == "" {
+		fmt.Printf("  %s %s\n", mark, name)
+		return
+	}
+	fmt.Printf("  %s %s: %s\n", mark, name, detail)
+}
+
+func (d *doctor) pass(name, detail string) {
+	d.report("✓", colorGreen, name, detail)
+}
+
+func (d *doctor) warn(name, detail string) {
+	d.warned++
+	d.report("!", colorYellow, name, detail)
+}
+
+func (d *doctor) fail(name string, err error) {
+	d.failed++
+	d.report("✗", colorRed, name, err.Error())
+}
+
+// runDoctor checks the config, the owner's npub, storage, the Gemini
+// certificate and each seed relay, then looks up the owner's profile, contact
+// list and relay list on the seeds
+func runDoctor(d *doctor, configPath string) error {
+	fmt.Println("Configuration")
+	cfg, err := config.Load(configPath)
+	if err != nil {
+		d.fail(configPath, err)
+		return fmt.Errorf("the configuration must load before anything else can be checked")
+	}
+	d.pass(configPath, "valid")
+
+	prefix, value, err := nip19.Decode(cfg.Identity.Npub)
+	owner, _ := value.(string)
+	if err != nil || prefix != "npub" || owner == "" {
+		d.fail("identity.npub", fmt.Errorf("%q doesn't decode to a public key", cfg.Identity.Npub))
+	} else {
+		d.pass("identity.npub", owner)
+	}
+
+	fmt.Println()
+	fmt.Println("Storage")
+	switch cfg.Storage.Driver {
+	case "lmdb":
+		doctorWritable(d, "storage.lmdb_path", cfg.Storage.LMDBPath)
+	default:
+		doctorWritable(d, "storage.sqlite_path", cfg.Storage.SQLitePath)
+	}
+
+	if cfg.Protocols.Gemini.Enabled {
+		fmt.Println()
+		fmt.Println("Gemini certificate")
+		doctorCertificate(d, &cfg.Protocols.Gemini)
+	}
+
+	fmt.Println()
+	fmt.Println("Seed relays")
+	ctx := context.Background()
+	var relays []*nostr.Relay
+	for _, url := range cfg.Relays.Seeds {
+		start := time.Now()
+		connectCtx, cancel := context.WithTimeout(ctx, checkTimeout)
+		r, err := nostr.RelayConnect(connectCtx, url)
+		cancel()
+		if err != nil {
+			d.fail(url, err)
+			continue
+		}
+		defer r.Close()
+		relays = append(relays, r)
+		d.pass(url, fmt.Sprintf("connected in %v", time.Since(start).Round(time.Millisecond)))
+	}
+	if len(cfg.Relays.Seeds) == 0 {
+		d.fail("relays.seeds", errors.New("no seed relays configured"))
+	}
+
+	if owner != "" && len(relays) > 0 {
+		fmt.Println()
+		fmt.Println("Owner events on the seed relays")
+		lookups := []struct {
+			kind    int
+			name    string
+			missing string
+		}{
+			{0, "profile (kind 0)", "profiles will show the npub instead of a name"},
+			{3, "contact list (kind 3)", "only your own events will be synced"},
+			{10002, "relay list (kind 10002)", "outbox discovery will fall back to the seed relays"},
+		}
+		for _, lookup := range lookups {
+			found, newest := 0, nostr.Timestamp(0)
+			for _, r := range relays {
+				queryCtx, cancel := context.WithTimeout(ctx, checkTimeout)
+				events, err := r.QuerySync(queryCtx, nostr.Filter{Kinds: []int{lookup.kind}, Authors: []string{owner}, Limit: 1})
+				cancel()
+				if err != nil || len(events) == 0 {
+					continue
+				}
+				found++
+				newest = max(newest, events[0].CreatedAt)
+			}
+			if found == 0 {
+				d.warn(lookup.name, "not found, "+lookup.missing)
+				continue
+			}
+			d.pass(lookup.name, fmt.Sprintf("on %d of %d relays, newest %s", found, len(relays), newest.Time().Format(time.RFC3339)))
+		}
+	}
+
+	fmt.Println()
+	if d.failed > 0 {
+		return fmt.Errorf("%d problems found, %d warnings", d.failed, d.warned)
+	}
+	fmt.Printf("No problems found, %d warnings\n", d.warned)
+	return nil
+}
+
+// doctorWritable checks a storage path can be written, or created in its
+// nearest existing parent directory
+func doctorWritable(d *doctor, name, path string) {
+	if path == "" {
+		d.fail(name, errors.New("not set"))
+		return
+	}
+
+	if info, err := os.Stat(path); err == nil {
+		if info.IsDir() {
+			// LMDB stores its files in a directory
+			doctorWritableDir(d, name, path, path+" exists")
+			return
+		}
+		file, err := os.OpenFile(path, os.O_WRONLY, 0)
+		if err != nil {
+			d.fail(name, err)
+			return
+		}
+		file.Close()
+		d.pass(name, path+" is writable")
+		return
+	}
+
+	dir := filepath.Dir(path)
+	for {
+		if _, err := os.Stat(dir); err == nil || filepath.Dir(dir) == dir {
+			break
+		}
+		dir = filepath.Dir(dir)
+	}
+	doctorWritableDir(d, name, dir, path+" will be created")
+}
+
+// doctorWritableDir checks a file can be created in dir
+func doctorWritableDir(d *doctor, name, dir, detail string) {
+	probe, err := os.CreateTemp(dir, ".nophr-doctor-")
+	if err != nil {
+		d.fail(name, fmt.Errorf("%s is not writable: %w", dir, err))
+		return
+	}
+	probe.Close()
+	os.Remove(probe.Name())
+	d.pass(name, detail)
+}
+
+// doctorCertificate checks the Gemini certificate and key load, are in their
+// validity period and name protocols.gemini.host
+func doctorCertificate(d *doctor, gemini *config.GeminiProtocol) {
+	tlsCfg := gemini.TLS
+	if _, err := os.Stat(tlsCfg.CertPath); tlsCfg.CertPath == "" || errors.Is(err, os.ErrNotExist) {
+		if tlsCfg.AutoGenerate {
+			d.pass("certificate", "will be generated on first start")
+		} else {
+			d.fail("certificate", fmt.Errorf("%q doesn't exist and auto_generate is off", tlsCfg.CertPath))
+		}
+		return
+	}
+
+	pair, err := tls.LoadX509KeyPair(tlsCfg.CertPath, tlsCfg.KeyPath)
+	if err != nil {
+		d.fail("certificate", err)
+		return
+	}
+	cert, err := x509.ParseCertificate(pair.Certificate[0])
+	if err != nil {
+		d.fail("certificate", err)
+		return
+	}
+
+	now := time.Now()
+	switch {
+	case now.After(cert.NotAfter):
+		d.fail("certificate", fmt.Errorf("expired %s", cert.NotAfter.Format(time.RFC3339)))
+	case now.Before(cert.NotBefore):
+		d.fail("certificate", fmt.Errorf("not valid until %s", cert.NotBefore.Format(time.RFC3339)))
+	case cert.NotAfter.Sub(now) < certExpiryWarning:
+		d.warn("certificate", "expires "+cert.NotAfter.Format(time.RFC3339))
+	default:
+		d.pass("certificate", "valid until "+cert.NotAfter.Format(time.RFC3339))
+	}
+
+	if err := cert.VerifyHostname(gemini.Host); err != nil {
+		d.warn("certificate host", fmt.Sprintf("doesn't name %s, clients will warn about it", gemini.Host))
+	} else {
+		d.pass("certificate host", gemini.Host)
+	}
+}
+
+// isTerminal reports whether f is a terminal rather than a file or pipe
+func isTerminal(f *os.File) bool {
+	info, err := f.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
+
+func printDoctorUsage() {
+	fmt.Println("Usage: nophr doctor --config <path> [--no-color]")
+	fmt.Println()
+	fmt.Println("Diagnose setup problems: load and validate the configuration, decode the")
+	fmt.Println("npub, check the storage path is writable and the Gemini certificate is")
+	fmt.Println("valid, connect to every seed relay and look up the owner's profile, contact")
+	fmt.Println("list and relay list (kinds 0, 3 and 10002) on them.")
+	fmt.Println()
+	fmt.Println("Options:")
+	fmt.Println("  --config <path>   Configuration file")
+	fmt.Println("  --no-color        Don't color the report (also off with NO_COLOR or when")
+	fmt.Println("                    output isn't a terminal)")
+	fmt.Println()
+	fmt.Println("Exits with status 1 if any problem is found; warnings don't fail.")
+}
@@ -0,0 +1,130 @@
+package main
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/sandwich/nophr/internal/config"
+)
+
+// writeCert writes a self-signed certificate for host, valid from notBefore
+// to notAfter, and its key to dir
+func writeCert(t *testing.T, dir, host string, notBefore, notAfter time.Time) (certPath, keyPath string) {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("Failed to generate key: %v", err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: host},
+		DNSNames:     []string{host},
+		NotBefore:    notBefore,
+		NotAfter:     notAfter,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatalf("Failed to create certificate: %v", err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatalf("Failed to marshal key: %v", err)
+	}
+
+	certPath = filepath.Join(dir, "cert.pem")
+	keyPath = filepath.Join(dir, "key.pem")
+	os.WriteFile(certPath, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0644)
+	os.WriteFile(keyPath, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0600)
+	return certPath, keyPath
+}
+
+func TestDoctorWritable(t *testing.T) {
+	dir := t.TempDir()
+	existing := filepath.Join(dir, "nophr.db")
+	os.WriteFile(existing, nil, 0644)
+
+	tests := []struct {
+		name   string
+		path   string
+		failed int
+	}{
+		{"existing file", existing, 0},
+		{"existing directory", dir, 0},
+		{"new file", filepath.Join(dir, "data", "nophr.db"), 0},
+		{"under a regular file", filepath.Join(existing, "nophr.db"), 1},
+		{"not set", "", 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := &doctor{}
+			doctorWritable(d, "storage.sqlite_path", tt.path)
+			if d.failed != tt.failed {
+				t.Errorf("doctorWritable(%q) failed %d checks, want %d", tt.path, d.failed, tt.failed)
+			}
+		})
+	}
+
+	if _, err := os.Stat(filepath.Join(dir, "data")); !os.IsNotExist(err) {
+		t.Error("Expected doctorWritable not to create missing directories")
+	}
+}
+
+func TestDoctorCertificate(t *testing.T) {
+	now := time.Now()
+	tests := []struct {
+		name      string
+		cert      bool // Write a certificate, or leave the path missing
+		auto      bool
+		host      string
+		notBefore time.Time
+		notAfter  time.Time
+		failed    int
+		warned    int
+	}{
+		{name: "missing, generated", auto: true},
+		{name: "missing, not generated", failed: 1},
+		{name: "valid", cert: true, host: "localhost", notBefore: now.Add(-time.Hour), notAfter: now.AddDate(1, 0, 0)},
+		{name: "expired", cert: true, host: "localhost", notBefore: now.AddDate(-1, 0, 0), notAfter: now.Add(-time.Hour), failed: 1},
+		{name: "not yet valid", cert: true, host: "localhost", notBefore: now.Add(time.Hour), notAfter: now.AddDate(1, 0, 0), failed: 1},
+		{name: "near expiry", cert: true, host: "localhost", notBefore: now.Add(-time.Hour), notAfter: now.Add(24 * time.Hour), warned: 1},
+		{name: "other host", cert: true, host: "example.com", notBefore: now.Add(-time.Hour), notAfter: now.AddDate(1, 0, 0), warned: 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			gemini := &config.GeminiProtocol{Host: "localhost"}
+			gemini.TLS.CertPath = filepath.Join(dir, "cert.pem")
+			gemini.TLS.KeyPath = filepath.Join(dir, "key.pem")
+			gemini.TLS.AutoGenerate = tt.auto
+			if tt.cert {
+				writeCert(t, dir, tt.host, tt.notBefore, tt.notAfter)
+			}
+
+			d := &doctor{}
+			doctorCertificate(d, gemini)
+			if d.failed != tt.failed || d.warned != tt.warned {
+				t.Errorf("doctorCertificate() failed %d and warned %d, want %d and %d", d.failed, d.warned, tt.failed, tt.warned)
+			}
+		})
+	}
+}
+
+func TestRunDoctorMissingConfig(t *testing.T) {
+	d := &doctor{}
+	if err := runDoctor(d, filepath.Join(t.TempDir(), "missing.yaml")); err == nil {
+		t.Fatal("Expected runDoctor to fail without a configuration")
+	}
+	if d.failed != 1 {
+		t.Errorf("Expected the configuration to fail one check, got %d", d.failed)
+	}
+}
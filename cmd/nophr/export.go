@@ -0,0 +1,121 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/nbd-wtf/go-nostr"
+	"github.com/sandwich/nophr/internal/aggregates"
+	"github.com/sandwich/nophr/internal/config"
+	"github.com/sandwich/nophr/internal/export"
+	"github.com/sandwich/nophr/internal/gemini"
+	"github.com/sandwich/nophr/internal/gopher"
+	"github.com/sandwich/nophr/internal/storage"
+)
+
+// handleExport handles "nophr export", rendering the site to static files
+func handleExport(args []string) {
+	fs := flag.NewFlagSet("export", flag.ExitOnError)
+	configPath := fs.String("config", globalConfig, "Path to configuration file")
+	format := fs.String("format", "", "Export format: "+strings.Join(export.Formats, "|"))
+	out := fs.String("out", "", "Output directory")
+	host := fs.String("host", "", "Hostname links point at (default: the protocol's configured host)")
+	port := fs.Int("port", 0, "Port links point at (default: the protocol's configured port)")
+	fs.Usage = printExportUsage
+	fs.Parse(args)
+
+	if *configPath == "" || *format == "" || *out == "" || fs.NArg() != 0 {
+		printExportUsage()
+		os.Exit(1)
+	}
+
+	cfg, err := config.Load(*configPath)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error loading configuration: %v\n", err)
+		os.Exit(1)
+	}
+
+	if err := runExport(cfg, *format, *out, *host, *port); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+// runExport renders every reachable page of the chosen protocol into out
+func runExport(cfg *config.Config, format, out, host string, port int) error {
+	ctx := context.Background()
+	st, err := storage.New(ctx, &cfg.Storage)
+	if err != nil {
+		return fmt.Errorf("failed to initialize storage: %w", err)
+	}
+	defer st.Close()
+
+	aggMgr := aggregates.NewManager(st, cfg)
+
+	var result *export.Result
+	switch format {
+	case "gopher":
+		if host == "" {
+			host = cfg.Protocols.Gopher.Host
+		}
+		if port != 0 {
+			cfg.Protocols.Gopher.Port = port
+		}
+		server := gopher.New(&cfg.Protocols.Gopher, cfg, st, host, aggMgr)
+
+		fmt.Printf("Exporting gopherhole for %s:%d to %s...\n", host, cfg.Protocols.Gopher.Port, out)
+		result, err = export.Gopher(server.Render, host, cfg.Protocols.Gopher.Port, out)
+	case "gemini":
+		if host == "" {
+			host = cfg.Protocols.Gemini.Host
+		}
+		// The export never serves TLS, so don't load or overwrite the live certificate
+		geminiCfg := cfg.Protocols.Gemini
+		geminiCfg.TLS.CertPath = ""
+		geminiCfg.TLS.KeyPath = ""
+		if port != 0 {
+			geminiCfg.Port = port
+		}
+		var server *gemini.Server
+		server, err = gemini.New(&geminiCfg, cfg, st, host, aggMgr)
+		if err != nil {
+			return fmt.Errorf("failed to initialize gemini renderer: %w", err)
+		}
+
+		lookup := func(id string) *nostr.Event {
+			events, err := st.QueryEvents(ctx, nostr.Filter{IDs: []string{id}, Limit: 1})
+			if err != nil || len(events) == 0 {
+				return nil
+			}
+			return events[0]
+		}
+
+		fmt.Printf("Exporting capsule for %s:%d to %s...\n", host, geminiCfg.Port, out)
+		result, err = export.Gemini(server.Render, lookup, host, geminiCfg.Port, out)
+	default:
+		return fmt.Errorf("unknown export format %q (want %s)", format, strings.Join(export.Formats, "|"))
+	}
+	if err != nil {
+		return err
+	}
+
+	fmt.Println("Export complete")
+	fmt.Printf("  Menus:   %d\n", result.Menus)
+	fmt.Printf("  Files:   %d\n", result.Files)
+	fmt.Printf("  Skipped: %d\n", result.Skipped)
+	return nil
+}
+
+func printExportUsage() {
+	fmt.Println("Usage: nophr export --config <path> --format <format> --out <dir> [--host <host>] [--port <port>]")
+	fmt.Println()
+	fmt.Println("Render all sections, notes, articles, threads and profiles to static files,")
+	fmt.Println("for hosting on another server without running nophr.")
+	fmt.Println()
+	fmt.Println("Formats:")
+	fmt.Println("  gopher   gophermap and .txt files for gophernicus or pygopherd")
+	fmt.Println("  gemini   .gmi capsule for molly-brown or agate, named by nevent/naddr/npub")
+}
@@ -0,0 +1,174 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/nbd-wtf/go-nostr"
+	"github.com/sandwich/nophr/internal/config"
+	"github.com/sandwich/nophr/internal/nostr/helpers"
+	"github.com/sandwich/nophr/internal/storage"
+	"github.com/sandwich/nophr/internal/sync"
+)
+
+// handleExportEvents handles "nophr export-events", streaming stored events
+func handleExportEvents(args []string) {
+	fs := flag.NewFlagSet("export-events", flag.ExitOnError)
+	configPath := fs.String("config", globalConfig, "Path to configuration file")
+	format := fs.String("format", sync.FormatJSONL, "Output format: "+strings.Join(sync.ExportFormats, "|"))
+	out := fs.String("out", "-", "Output file (- for stdout)")
+	kinds := fs.String("kinds", "", "Comma-separated kinds to export (default: all)")
+	authors := fs.String("authors", "", "Comma-separated npubs or hex pubkeys to export (default: all)")
+	since := fs.String("since", "", "Only events created at or after: YYYY-MM-DD, RFC 3339 or Unix seconds")
+	until := fs.String("until", "", "Only events created at or before: YYYY-MM-DD, RFC 3339 or Unix seconds")
+	fs.Usage = printExportEventsUsage
+	fs.Parse(args)
+
+	if *configPath == "" || fs.NArg() != 0 {
+		printExportEventsUsage()
+		os.Exit(1)
+	}
+
+	filter, err := exportFilter(*kinds, *authors, *since, *until)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	cfg, err := config.Load(*configPath)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error loading configuration: %v\n", err)
+		os.Exit(1)
+	}
+
+	if err := runExportEvents(cfg, *format, *out, filter); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+// exportFilter builds the filter events must match from the command's flags
+func exportFilter(kinds, authors, since, until string) (nostr.Filter, error) {
+	var filter nostr.Filter
+
+	for _, field := range splitList(kinds) {
+		kind, err := strconv.Atoi(field)
+		if err != nil {
+			return filter, fmt.Errorf("invalid kind %q", field)
+		}
+		filter.Kinds = append(filter.Kinds, kind)
+	}
+
+	for _, field := range splitList(authors) {
+		pubkey, err := helpers.NormalizePubkey(field)
+		if err != nil {
+			return filter, fmt.Errorf("invalid author %q: %w", field, err)
+		}
+		filter.Authors = append(filter.Authors, pubkey)
+	}
+
+	for _, bound := range []struct {
+		value string
+		dest  **nostr.Timestamp
+		name  string
+	}{{since, &filter.Since, "since"}, {until, &filter.Until, "until"}} {
+		if bound.value == "" {
+			continue
+		}
+		ts, err := parseExportTime(bound.value)
+		if err != nil {
+			return filter, fmt.Errorf("invalid --%s: %w", bound.name, err)
+		}
+		*bound.dest = &ts
+	}
+
+	return filter, nil
+}
+
+// splitList splits a comma-separated flag value, dropping empty entries
+func splitList(value string) []string {
+	var fields []string
+	for _, field := range strings.Split(value, ",") {
+		if field = strings.TrimSpace(field); field != "" {
+			fields = append(fields, field)
+		}
+	}
+	return fields
+}
+
+// parseExportTime accepts a date, an RFC 3339 time or Unix seconds
+func parseExportTime(value string) (nostr.Timestamp, error) {
+	if secs, err := strconv.ParseInt(value, 10, 64); err == nil {
+		return nostr.Timestamp(secs), nil
+	}
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return nostr.Timestamp(t.Unix()), nil
+	}
+	if t, err := time.Parse("2006-01-02", value); err == nil {
+		return nostr.Timestamp(t.Unix()), nil
+	}
+	return 0, fmt.Errorf("%q is not YYYY-MM-DD, RFC 3339 or Unix seconds", value)
+}
+
+// runExportEvents writes every stored event matching filter to out
+func runExportEvents(cfg *config.Config, format, out string, filter nostr.Filter) error {
+	// Progress goes to stderr when the events go to stdout
+	var w io.Writer = os.Stdout
+	log := os.Stdout
+	if out == "-" {
+		log = os.Stderr
+	} else {
+		f, err := os.Create(out)
+		if err != nil {
+			return fmt.Errorf("failed to create %s: %w", out, err)
+		}
+		defer f.Close()
+		w = f
+	}
+
+	archive, err := sync.NewArchiveWriter(w, format)
+	if err != nil {
+		return err
+	}
+
+	ctx := context.Background()
+	st, err := storage.New(ctx, &cfg.Storage)
+	if err != nil {
+		return fmt.Errorf("failed to initialize storage: %w", err)
+	}
+	defer st.Close()
+
+	err = st.IterateEvents(ctx, 1000, func(event *nostr.Event) error {
+		if !filter.Matches(event) {
+			return nil
+		}
+		return archive.Write(event)
+	})
+	if err != nil {
+		return err
+	}
+	if err := archive.Close(); err != nil {
+		return fmt.Errorf("failed to write events: %w", err)
+	}
+
+	fmt.Fprintf(log, "Exported %d events\n", archive.Count())
+	return nil
+}
+
+func printExportEventsUsage() {
+	fmt.Println("Usage: nophr export-events --config <path> [--kinds 1,30023] [--authors <npub,...>]")
+	fmt.Println("                           [--since <time>] [--until <time>] [--format <format>] [--out <file>]")
+	fmt.Println()
+	fmt.Println("Write stored events as raw signed Nostr events, for 'nophr import' on another")
+	fmt.Println("instance or any tool that reads events. Writes to stdout unless --out is given.")
+	fmt.Println()
+	fmt.Println("Formats:")
+	fmt.Println("  jsonl         One event per line (default)")
+	fmt.Println("  nostr-tools   JSON array of events")
+}
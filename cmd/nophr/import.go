@@ -0,0 +1,120 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/nbd-wtf/go-nostr/nip19"
+	"github.com/sandwich/nophr/internal/cache"
+	"github.com/sandwich/nophr/internal/config"
+	"github.com/sandwich/nophr/internal/ops"
+	"github.com/sandwich/nophr/internal/storage"
+	"github.com/sandwich/nophr/internal/sync"
+)
+
+// handleImport handles "nophr import", seeding storage from another client's export
+func handleImport(args []string) {
+	fs := flag.NewFlagSet("import", flag.ExitOnError)
+	configPath := fs.String("config", globalConfig, "Path to configuration file")
+	format := fs.String("format", sync.FormatAuto, "Archive format: "+strings.Join(sync.ArchiveFormats, "|"))
+	fs.Usage = printImportUsage
+	fs.Parse(args)
+
+	if *configPath == "" || fs.NArg() != 1 {
+		printImportUsage()
+		os.Exit(1)
+	}
+
+	cfg, err := config.Load(*configPath)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error loading configuration: %v\n", err)
+		os.Exit(1)
+	}
+
+	if err := runImport(cfg, *format, fs.Arg(0)); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+// runImport ingests the archive at path ("-" for stdin)
+func runImport(cfg *config.Config, format, path string) error {
+	var r io.Reader = os.Stdin
+	if path != "-" {
+		f, err := os.Open(path)
+		if err != nil {
+			return fmt.Errorf("failed to open archive: %w", err)
+		}
+		defer f.Close()
+		r = f
+	}
+
+	ctx := context.Background()
+	st, err := storage.New(ctx, &cfg.Storage)
+	if err != nil {
+		return fmt.Errorf("failed to initialize storage: %w", err)
+	}
+	defer st.Close()
+
+	engine := sync.NewEngine(st, cfg)
+
+	// Apply the same retention rules as live sync
+	if cfg.Sync.Retention.Advanced != nil && cfg.Sync.Retention.Advanced.Enabled {
+		logger := ops.NewLogger(&cfg.Logging)
+		retentionMgr := ops.NewRetentionManager(st, &cfg.Sync.Retention, logger, cfg.Identity.Npub)
+		engine.SetRetentionEvaluator(retentionMgr.EvaluateEvent)
+	}
+
+	// A shared Redis cache is what a running instance serves from, so drop the
+	// pages imported events change
+	if cfg.Caching.Enabled && cfg.Caching.Engine == "redis" {
+		cacheCfg := cache.DefaultConfig()
+		cacheCfg.Engine = cfg.Caching.Engine
+		cacheCfg.RedisURL = cfg.Caching.RedisURL
+		responseCache, err := cache.New(cacheCfg)
+		if err != nil {
+			fmt.Printf("⚠ Cache unavailable, cached pages won't show imported events until they expire: %v\n", err)
+		} else {
+			defer responseCache.Close()
+			invalidator := cache.NewInvalidator(responseCache)
+			if _, owner, err := nip19.Decode(cfg.Identity.Npub); err == nil {
+				invalidator.SetOwner(owner.(string))
+			}
+			engine.SetCacheInvalidator(invalidator.OnEventIngested)
+		}
+	}
+
+	fmt.Printf("Importing %s archive %s...\n", format, path)
+	result, err := engine.Import(r, format)
+	if err != nil {
+		return err
+	}
+
+	fmt.Println("Import complete")
+	fmt.Printf("  Imported:   %d\n", result.Imported)
+	fmt.Printf("  Duplicates: %d\n", result.Duplicates)
+	fmt.Printf("  Invalid:    %d\n", result.Invalid)
+	fmt.Printf("  Skipped:    %d\n", result.Skipped)
+	if result.Failed > 0 {
+		fmt.Printf("  Failed:     %d\n", result.Failed)
+	}
+	return nil
+}
+
+func printImportUsage() {
+	fmt.Println("Usage: nophr import --config <path> [--format <format>] <archive>")
+	fmt.Println()
+	fmt.Println("Ingest an event export from another client or relay. Use - to read stdin.")
+	fmt.Println()
+	fmt.Println("Formats:")
+	fmt.Println("  auto           JSON array or one event per line, detected (default)")
+	fmt.Println("  jsonl          One event per line, as written by 'nophr export-events'")
+	fmt.Println("  nostr-tools    JSON array of events")
+	fmt.Println("  primal         JSON array of events from a Primal export")
+	fmt.Println("  nostrudel      noStrudel export (JSON array or one event per line)")
+	fmt.Println("  strfry-jsonl   One event per line, as written by 'strfry export'")
+}
@@ -2,22 +2,37 @@ package main
 
 import (
 	"context"
+	"encoding/json"
 	"flag"
 	"fmt"
 	"os"
 	"os/signal"
+	"strings"
 	"syscall"
 	"time"
 
+	"github.com/nbd-wtf/go-nostr/nip19"
+	"github.com/sandwich/nophr/internal/admin"
 	"github.com/sandwich/nophr/internal/aggregates"
+	"github.com/sandwich/nophr/internal/cache"
 	"github.com/sandwich/nophr/internal/config"
 	"github.com/sandwich/nophr/internal/finger"
 	"github.com/sandwich/nophr/internal/gemini"
 	"github.com/sandwich/nophr/internal/gopher"
+	"github.com/sandwich/nophr/internal/idle"
+	"github.com/sandwich/nophr/internal/mediaproxy"
+	"github.com/sandwich/nophr/internal/nntp"
+	internalnostr "github.com/sandwich/nophr/internal/nostr"
+	"github.com/sandwich/nophr/internal/nwc"
 	"github.com/sandwich/nophr/internal/ops"
+	"github.com/sandwich/nophr/internal/outbox"
+	"github.com/sandwich/nophr/internal/qotd"
+	"github.com/sandwich/nophr/internal/relay"
 	"github.com/sandwich/nophr/internal/sections"
+	"github.com/sandwich/nophr/internal/security"
 	"github.com/sandwich/nophr/internal/storage"
 	"github.com/sandwich/nophr/internal/sync"
+	"github.com/sandwich/nophr/internal/telnet"
 )
 
 var (
@@ -27,36 +42,111 @@ var (
 	builtBy = "manual"
 )
 
+// globalConfig is a --config given before the subcommand, used as the default
+// of the subcommand's own --config flag
+var globalConfig string
+
 func main() {
+	args := takeGlobalConfig(os.Args[1:])
+
 	// Define subcommands
-	if len(os.Args) > 1 && os.Args[1] == "init" {
-		handleInit()
-		return
+	if len(args) > 0 {
+		switch args[0] {
+		case "init":
+			handleInit()
+			return
+		case "serve":
+			handleServe(args[1:])
+			return
+		case "sync":
+			handleSync(args[1:])
+			return
+		case "status":
+			handleStatus(args[1:])
+			return
+		case "retention":
+			handleRetention(args[1:])
+			return
+		case "import":
+			handleImport(args[1:])
+			return
+		case "export":
+			handleExport(args[1:])
+			return
+		case "export-events":
+			handleExportEvents(args[1:])
+			return
+		case "aggregates":
+			handleAggregates(args[1:])
+			return
+		case "annotations":
+			handleAnnotations(args[1:])
+			return
+		case "migrate":
+			handleMigrate(args[1:])
+			return
+		case "bridge":
+			handleBridge(args[1:])
+			return
+		case "backup":
+			handleBackup(args[1:])
+			return
+		case "restore":
+			handleRestore(args[1:])
+			return
+		case "check":
+			handleCheck(args[1:])
+			return
+		case "doctor":
+			handleDoctor(args[1:])
+			return
+		case "devseed":
+			handleDevseed(args[1:])
+			return
+		}
 	}
 
-	var (
-		showVersion = flag.Bool("version", false, "Show version information")
-		configPath  = flag.String("config", "", "Path to configuration file")
-	)
-	flag.Parse()
+	// Without a subcommand nophr serves, as "nophr --config <path>" always has
+	handleServe(args)
+}
+
+// takeGlobalConfig removes a leading --config <path> or --config=<path> from
+// args and stores it in globalConfig
+func takeGlobalConfig(args []string) []string {
+	for len(args) > 0 {
+		switch arg := args[0]; {
+		case (arg == "--config" || arg == "-config") && len(args) > 1:
+			globalConfig = args[1]
+			args = args[2:]
+		case strings.HasPrefix(arg, "--config="):
+			globalConfig = strings.TrimPrefix(arg, "--config=")
+			args = args[1:]
+		case strings.HasPrefix(arg, "-config="):
+			globalConfig = strings.TrimPrefix(arg, "-config=")
+			args = args[1:]
+		default:
+			return args
+		}
+	}
+	return args
+}
+
+// handleServe handles "nophr serve", starting sync and the protocol servers
+func handleServe(args []string) {
+	fs := flag.NewFlagSet("serve", flag.ExitOnError)
+	showVersion := fs.Bool("version", false, "Show version information")
+	versionJSON := fs.Bool("json", false, "With --version, print the build metadata as JSON")
+	configPath := fs.String("config", globalConfig, "Path to configuration file")
+	fs.Usage = printUsage
+	fs.Parse(args)
 
 	if *showVersion {
-		fmt.Printf("nophr %s\n", version)
-		fmt.Printf("  commit: %s\n", commit)
-		fmt.Printf("  built:  %s\n", date)
-		fmt.Printf("  by:     %s\n", builtBy)
+		printVersion(*configPath, *versionJSON)
 		os.Exit(0)
 	}
 
-	if *configPath == "" {
-		fmt.Println("nophr - Nostr to Gopher/Gemini/Finger Gateway")
-		fmt.Println()
-		fmt.Println("No configuration file specified. Use --config <path> to specify config.")
-		fmt.Println()
-		fmt.Println("Commands:")
-		fmt.Println("  nophr init              Generate example configuration")
-		fmt.Println("  nophr --version         Show version information")
-		fmt.Println("  nophr --config <path>   Start with configuration file")
+	if *configPath == "" || fs.NArg() != 0 {
+		printUsage()
 		os.Exit(1)
 	}
 
@@ -74,13 +164,66 @@ func main() {
 	fmt.Println()
 
 	// Run the application
-	if err := run(cfg); err != nil {
+	if err := run(cfg, *configPath); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+// printVersion prints the build metadata, with the features enabled in the
+// configuration at configPath when one is given and loads
+func printVersion(configPath string, asJSON bool) {
+	info := ops.NewBuildInfo(version, commit, date, builtBy)
+	if configPath != "" {
+		if cfg, err := config.Load(configPath); err == nil {
+			info.Features = ops.EnabledFeatures(cfg)
+		} else {
+			fmt.Fprintf(os.Stderr, "Warning: features not listed: %v\n", err)
+		}
+	}
+
+	if !asJSON {
+		fmt.Print(info.FormatAsText())
+		return
+	}
+	data, err := json.MarshalIndent(info, "", "  ")
+	if err != nil {
 		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
 		os.Exit(1)
 	}
+	fmt.Println(string(data))
 }
 
-func run(cfg *config.Config) error {
+func printUsage() {
+	fmt.Println("nophr - Nostr to Gopher/Gemini/Finger Gateway")
+	fmt.Println()
+	fmt.Println("Usage: nophr [--config <path>] <command> [options]")
+	fmt.Println()
+	fmt.Println("Commands:")
+	fmt.Println("  nophr init              Generate example configuration")
+	fmt.Println("  nophr serve             Sync from relays and start the protocol servers")
+	fmt.Println("  nophr sync ...          Sync from relays without serving, or once with --once")
+	fmt.Println("  nophr status            Show whether the servers are up and what is stored")
+	fmt.Println("  nophr retention ...     Manage protected events")
+	fmt.Println("  nophr import ...        Import events from another client's export")
+	fmt.Println("  nophr export ...        Export the site as static files")
+	fmt.Println("  nophr export-events ... Write stored events as JSON lines or a JSON array")
+	fmt.Println("  nophr aggregates ...    Rebuild interaction counts from stored events")
+	fmt.Println("  nophr annotations ...   Reclassify stored notes after changing topics")
+	fmt.Println("  nophr migrate ...       Show, apply or roll back storage schema migrations")
+	fmt.Println("  nophr bridge            Publish new items of the bridged RSS and Atom feeds now")
+	fmt.Println("  nophr backup ...        Write a snapshot of storage to a file")
+	fmt.Println("  nophr restore ...       Load a snapshot into storage, on any driver")
+	fmt.Println("  nophr devseed ...       Fill an empty database with synthetic test data")
+	fmt.Println("  nophr check ...         Smoke-test a configuration before deploying it")
+	fmt.Println("  nophr doctor            Diagnose config, storage, certificate and relay problems")
+	fmt.Println("  nophr --version [--json] Show version, build and enabled-feature information")
+	fmt.Println()
+	fmt.Println("--config may be given before the command or to the command itself. Without a")
+	fmt.Println("command, \"nophr --config <path>\" is the same as \"nophr --config <path> serve\".")
+}
+
+func run(cfg *config.Config, configPath string) error {
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
 
@@ -93,6 +236,26 @@ func run(cfg *config.Config) error {
 	defer st.Close()
 	fmt.Printf("  Storage: %s initialized\n", cfg.Storage.Driver)
 
+	// Lifecycle events go to the persistent admin log. The shutdown is
+	// recorded last, once every subsystem has stopped.
+	lifecycle := ops.NewLifecycle(st)
+	lifecycle.Startup(cfg, version)
+	defer func() {
+		if r := recover(); r != nil {
+			lifecycle.Crashed(r)
+			panic(r)
+		}
+		lifecycle.Shutdown()
+	}()
+
+	// Denied authors never reach any protocol, even if stored before being denied.
+	// The list is shared with sync and the admin API, which can add to it.
+	denyList := security.NewDenyList(cfg.Sync.Scope.DenylistPubkeys)
+	st.SetEventFilter(security.NewCombinedFilter(denyList, nil).IsEventAllowed)
+	if denied := cfg.Sync.Scope.DenylistPubkeys; len(denied) > 0 {
+		fmt.Printf("  Hiding %d denied authors\n", len(denied))
+	}
+
 	// Initialize aggregates manager
 	fmt.Println("Initializing aggregates manager...")
 	aggMgr := aggregates.NewManager(st, cfg)
@@ -127,11 +290,41 @@ func run(cfg *config.Config) error {
 
 	defer retentionMgr.Stop()
 
+	// Initialize response cache, consulted by the protocol servers and reported in diagnostics
+	var responseCache cache.Cache
+	if cfg.Caching.Enabled {
+		cacheCfg := cache.DefaultConfig()
+		cacheCfg.Engine = cfg.Caching.Engine
+		cacheCfg.RedisURL = cfg.Caching.RedisURL
+		responseCache, err = cache.New(cacheCfg)
+		if err != nil {
+			fmt.Printf("  ⚠ Cache unavailable: %v\n", err)
+			responseCache = nil
+		} else {
+			defer responseCache.Close()
+		}
+	}
+
+	// Warm hot pages at startup and after invalidation storms
+	var warmer *cache.Warmer
+	var invalidator *cache.Invalidator
+	if responseCache != nil {
+		warmer = cache.NewWarmer(responseCache)
+		invalidator = cache.NewInvalidator(responseCache)
+		if _, owner, err := nip19.Decode(cfg.Identity.Npub); err == nil {
+			invalidator.SetOwner(owner.(string))
+		}
+		invalidator.SetStormHandler(cache.DefaultStormThreshold, cache.DefaultStormWindow, func() {
+			go warmCache(ctx, warmer)
+		})
+	}
+
 	// Initialize sync engine if enabled
 	var syncEngine *sync.Engine
 	if cfg.Sync.Enabled {
 		fmt.Println("Initializing sync engine...")
 		syncEngine = sync.NewEngine(st, cfg)
+		syncEngine.SetDenyList(denyList)
 
 		// Phase 20: Integrate retention evaluation if advanced retention is enabled
 		if cfg.Sync.Retention.Advanced != nil && cfg.Sync.Retention.Advanced.Enabled {
@@ -139,55 +332,242 @@ func run(cfg *config.Config) error {
 			syncEngine.SetRetentionEvaluator(retentionMgr.EvaluateEvent)
 		}
 
+		// Drop cached pages as new events arrive
+		if invalidator != nil {
+			syncEngine.SetCacheInvalidator(invalidator.OnEventIngested)
+		}
+
 		if err := syncEngine.Start(); err != nil {
 			return fmt.Errorf("failed to start sync engine: %w", err)
 		}
-		defer syncEngine.Stop()
+		defer func() {
+			syncEngine.Stop()
+			lifecycle.Stopped("sync", nil)
+		}()
+		lifecycle.Started("sync", "Sync engine started")
 		fmt.Println("  Sync engine started")
 	}
 
-	// Initialize protocol servers
-	var servers []interface{ Stop() error }
+	// Weekly owner digest, signed with NOPHR_NSEC
+	if cfg.Outbox.Digest.Enabled {
+		publisher, err := outbox.NewPublisher(cfg, st, internalnostr.New(ctx, &cfg.Relays))
+		if err != nil {
+			fmt.Printf("⚠ Weekly digest unavailable: %v\n", err)
+		} else {
+			digestScheduler := outbox.NewDigestScheduler(publisher)
+			digestScheduler.Start(ctx)
+			defer digestScheduler.Stop()
+			fmt.Printf("Weekly digest scheduled for %s (kind %d)\n", cfg.Outbox.Digest.Weekday, cfg.Outbox.Digest.Kind)
+		}
+	}
+
+	// RSS and Atom feeds syndicated into the owner's archive, signed with NOPHR_NSEC
+	if cfg.Outbox.Bridge.Enabled {
+		publisher, err := outbox.NewPublisher(cfg, st, internalnostr.New(ctx, &cfg.Relays))
+		if err != nil {
+			fmt.Printf("⚠ Feed bridge unavailable: %v\n", err)
+		} else {
+			bridge := outbox.NewBridge(publisher)
+			bridge.Start(ctx)
+			defer bridge.Stop()
+			fmt.Printf("Feed bridge polling %d feeds every %d minutes\n", len(cfg.Outbox.Bridge.Feeds), cfg.Outbox.Bridge.IntervalMinutes)
+		}
+	}
 
-	// Gopher server
-	if cfg.Protocols.Gopher.Enabled {
-		fmt.Printf("Starting Gopher server on %s:%d...\n", cfg.Protocols.Gopher.Host, cfg.Protocols.Gopher.Port)
-		gopherServer := gopher.New(&cfg.Protocols.Gopher, cfg, st, cfg.Protocols.Gopher.Host, aggMgr)
+	// Initialize diagnostics collector
+	diagnostics := ops.NewDiagnosticsCollector(version, commit, st, syncEngine)
+	diagnostics.SetRetentionManager(retentionMgr)
+	diagnostics.SetConfig(cfg)
+	diagnostics.SetBuildInfo(ops.NewBuildInfo(version, commit, date, builtBy))
+	if responseCache != nil {
+		diagnostics.SetCache(responseCache, cfg.Caching.Engine)
+	}
 
-		// Load sections from config
-		if len(cfg.Sections) > 0 {
-			if err := sections.LoadFromConfig(gopherServer.GetSectionManager(), cfg.Sections); err != nil {
-				return fmt.Errorf("failed to load Gopher sections: %w", err)
-			}
-			fmt.Printf("  Loaded %d sections\n", len(cfg.Sections))
+	// Initialize per-IP rate limiting, shared by all protocol servers
+	var rateLimiter *security.ClientLimiter
+	if rl := cfg.Security.RateLimit; rl.Enabled {
+		rateLimiter = security.NewClientLimiter(rl.RequestsPerMinute, rl.Burst, time.Duration(rl.BanDurationSeconds)*time.Second)
+		defer rateLimiter.Close()
+		fmt.Printf("Rate limiting: %d requests/min per IP (burst %d)\n", rl.RequestsPerMinute, rl.Burst)
+	}
+
+	// Shed resources while nobody is reading, until the next request
+	var idleMonitor *idle.Monitor
+	if cfg.Idle.Enabled {
+		idleMonitor = idle.NewMonitor(time.Duration(cfg.Idle.AfterMinutes) * time.Minute)
+		if memCache, ok := responseCache.(*cache.MemoryCache); ok {
+			awakeSize := memCache.MaxSize()
+			idleSize := int64(cfg.Idle.CacheMaxMB) * 1024 * 1024
+			idleMonitor.Register("cache", func() { memCache.SetMaxSize(idleSize) }, func() { memCache.SetMaxSize(awakeSize) })
+		}
+		if syncEngine != nil {
+			idleMonitor.Register("sync", func() { syncEngine.SetIdle(true) }, func() { syncEngine.SetIdle(false) })
+		}
+		idleMonitor.Start()
+		defer idleMonitor.Stop()
+		fmt.Printf("Idle shedding after %d minutes without requests\n", cfg.Idle.AfterMinutes)
+	}
+
+	// Load custom sections once so every protocol routes and lists the same ones
+	sectionManager, err := loadSections(cfg, cfg.Sections, st)
+	if err != nil {
+		return err
+	}
+	if len(cfg.Sections) > 0 {
+		fmt.Printf("Loaded %d sections\n", len(cfg.Sections))
+	}
+
+	// Listeners whose profile sets sections get their own, one set per profile
+	profileSections := map[string]*sections.Manager{}
+	sectionsFor := func(profile string, listenerCfg *config.Config) (*sections.Manager, error) {
+		if !cfg.ProfileSets(profile, "sections") {
+			return sectionManager, nil
+		}
+		if manager, ok := profileSections[profile]; ok {
+			return manager, nil
+		}
+		manager, err := loadSections(cfg, listenerCfg.Sections, st)
+		if err != nil {
+			return nil, fmt.Errorf("profile %s: %w", profile, err)
+		}
+		profileSections[profile] = manager
+		return manager, nil
+	}
+
+	// Notes missing from storage are looked up on the seed relays by the
+	// listeners whose behavior.fetch_on_miss is set, sharing one fetcher
+	var fetcher *internalnostr.Fetcher
+	fetcherFor := func(listenerCfg *config.Config) *internalnostr.Fetcher {
+		if !listenerCfg.Behavior.FetchOnMiss {
+			return nil
 		}
+		if fetcher == nil {
+			fetcher = internalnostr.NewFetcher(internalnostr.New(ctx, &cfg.Relays), st)
+		}
+		return fetcher
+	}
+
+	// Initialize protocol servers, recording each in the admin log as it starts
+	type runningServer struct {
+		name   string
+		server interface{ Stop() error }
+	}
+	var servers []runningServer
+	started := func(name, detail string, server interface{ Stop() error }) {
+		servers = append(servers, runningServer{name: name, server: server})
+		lifecycle.Started(name, detail)
+	}
+
+	// Media linked on any server's pages is fetched through one proxy
+	media := mediaproxy.New(&cfg.Rendering.MediaProxy)
+
+	// Gopher servers, one per listener. Only the main one is cached: the
+	// others link to their own address and may render with another profile.
+	for i, listener := range cfg.Protocols.Gopher.EnabledListeners() {
+		listenerCfg, err := cfg.ForGopherListener(listener)
+		if err != nil {
+			return fmt.Errorf("failed to configure Gopher listener: %w", err)
+		}
+		gopherCfg := &listenerCfg.Protocols.Gopher
+		fmt.Printf("Starting Gopher server on %s:%d%s...\n", gopherCfg.Host, gopherCfg.Port, profileNote(listener.Profile))
+		gopherServer := gopher.New(gopherCfg, listenerCfg, st, gopherCfg.Host, aggMgr)
+		gopherServer.SetDiagnostics(diagnostics)
+		gopherServer.SetRateLimiter(rateLimiter)
+		gopherServer.SetIdleMonitor(idleMonitor)
+		gopherServer.SetFetcher(fetcherFor(listenerCfg))
+		gopherServer.SetMediaProxy(media)
+		if responseCache != nil && i == 0 {
+			gopherServer.SetCache(responseCache, renderTTL(cfg, "gopher_menu"))
+		}
+		if warmer != nil && i == 0 {
+			ttl := renderTTL(cfg, "gopher_menu")
+			warmer.Register("gopher", func(ctx context.Context, w *cache.Warmer) error {
+				return gopherServer.WarmCache(ctx, w, ttl)
+			})
+		}
+
+		listenerSections, err := sectionsFor(listener.Profile, listenerCfg)
+		if err != nil {
+			return err
+		}
+		gopherServer.SetSectionManager(listenerSections)
 
 		if err := gopherServer.Start(); err != nil {
 			return fmt.Errorf("failed to start Gopher server: %w", err)
 		}
-		servers = append(servers, gopherServer)
+		started("gopher", fmt.Sprintf("Listening on %s:%d%s", gopherCfg.Host, gopherCfg.Port, profileNote(listener.Profile)), gopherServer)
 		fmt.Println("  Gopher server ready")
 	}
 
-	// Gemini server
-	if cfg.Protocols.Gemini.Enabled {
-		fmt.Printf("Starting Gemini server on %s:%d...\n", cfg.Protocols.Gemini.Host, cfg.Protocols.Gemini.Port)
-		geminiServer, err := gemini.New(&cfg.Protocols.Gemini, cfg, st, cfg.Protocols.Gemini.Host, aggMgr)
+	// Gemini servers, one per listener, cached like the Gopher ones
+	for i, listener := range cfg.Protocols.Gemini.EnabledListeners() {
+		listenerCfg, err := cfg.ForGeminiListener(listener)
+		if err != nil {
+			return fmt.Errorf("failed to configure Gemini listener: %w", err)
+		}
+		geminiCfg := &listenerCfg.Protocols.Gemini
+		fmt.Printf("Starting Gemini server on %s:%d%s...\n", geminiCfg.Host, geminiCfg.Port, profileNote(listener.Profile))
+		geminiServer, err := gemini.New(geminiCfg, listenerCfg, st, geminiCfg.Host, aggMgr)
 		if err != nil {
 			return fmt.Errorf("failed to create Gemini server: %w", err)
 		}
+		geminiServer.SetDiagnostics(diagnostics)
+		geminiServer.SetRateLimiter(rateLimiter)
+		geminiServer.SetIdleMonitor(idleMonitor)
+		geminiServer.SetFetcher(fetcherFor(listenerCfg))
+		geminiServer.SetMediaProxy(media)
+
+		// Titan uploads and published drafts are notes signed with NOPHR_NSEC
+		if cfg.Protocols.Gemini.Titan.Enabled || cfg.Identity.Nsec != "" {
+			publisher, err := outbox.NewPublisher(cfg, st, internalnostr.New(ctx, &cfg.Relays))
+			if err != nil {
+				fmt.Printf("  ⚠ Publishing unavailable: %v\n", err)
+			} else {
+				geminiServer.SetPublisher(publisher)
+				if cfg.Protocols.Gemini.Titan.Enabled {
+					fmt.Println("  Titan uploads enabled at /publish")
+				}
+				fmt.Println("  Drafts can be published from /drafts")
+			}
+		}
 
-		// Load sections from config
-		if len(cfg.Sections) > 0 {
-			if err := sections.LoadFromConfig(geminiServer.GetSectionManager(), cfg.Sections); err != nil {
-				return fmt.Errorf("failed to load Gemini sections: %w", err)
+		// The owner's NWC wallet, with zaps signed by NOPHR_NSEC when it is set
+		if cfg.Protocols.Gemini.Wallet.Enabled {
+			timeout := time.Duration(cfg.Protocols.Gemini.Wallet.TimeoutSeconds) * time.Second
+			wallet, err := nwc.NewClient(cfg.Protocols.Gemini.Wallet.URI, timeout)
+			if err != nil {
+				fmt.Printf("  ⚠ Wallet unavailable (NOPHR_NWC_URI): %v\n", err)
+			} else {
+				var signer gemini.ZapSigner
+				if publisher, err := outbox.NewPublisher(cfg, st, internalnostr.New(ctx, &cfg.Relays)); err != nil {
+					fmt.Printf("  ⚠ Zapping unavailable: %v\n", err)
+				} else {
+					signer = publisher
+				}
+				geminiServer.SetWallet(wallet, signer)
+				fmt.Println("  Wallet page enabled at /wallet")
 			}
 		}
+		if responseCache != nil && i == 0 {
+			geminiServer.SetCache(responseCache, renderTTL(cfg, "gemini_page"))
+		}
+		if warmer != nil && i == 0 {
+			ttl := renderTTL(cfg, "gemini_page")
+			warmer.Register("gemini", func(ctx context.Context, w *cache.Warmer) error {
+				return geminiServer.WarmCache(ctx, w, ttl)
+			})
+		}
+
+		listenerSections, err := sectionsFor(listener.Profile, listenerCfg)
+		if err != nil {
+			return err
+		}
+		geminiServer.SetSectionManager(listenerSections)
 
 		if err := geminiServer.Start(); err != nil {
 			return fmt.Errorf("failed to start Gemini server: %w", err)
 		}
-		servers = append(servers, geminiServer)
+		started("gemini", fmt.Sprintf("Listening on %s:%d%s", geminiCfg.Host, geminiCfg.Port, profileNote(listener.Profile)), geminiServer)
 		fmt.Println("  Gemini server ready")
 	}
 
@@ -195,17 +575,120 @@ func run(cfg *config.Config) error {
 	if cfg.Protocols.Finger.Enabled {
 		fmt.Printf("Starting Finger server on port %d...\n", cfg.Protocols.Finger.Port)
 		fingerServer := finger.New(&cfg.Protocols.Finger, cfg, st, aggMgr)
+		fingerServer.SetDiagnostics(diagnostics)
+		fingerServer.SetRateLimiter(rateLimiter)
+		fingerServer.SetIdleMonitor(idleMonitor)
+		if responseCache != nil {
+			fingerServer.SetCache(responseCache, renderTTL(cfg, "finger_response"))
+		}
+		if warmer != nil {
+			ttl := renderTTL(cfg, "finger_response")
+			warmer.Register("finger", func(ctx context.Context, w *cache.Warmer) error {
+				return fingerServer.WarmCache(ctx, w, ttl)
+			})
+		}
+
+		// Sections answer as usernames, and aliases can target them
+		fingerServer.SetSectionManager(sectionManager)
+
 		if err := fingerServer.Start(); err != nil {
 			return fmt.Errorf("failed to start Finger server: %w", err)
 		}
-		servers = append(servers, fingerServer)
+		started("finger", fmt.Sprintf("Listening on port %d", cfg.Protocols.Finger.Port), fingerServer)
 		fmt.Println("  Finger server ready")
 	}
 
+	// NNTP server
+	if cfg.Protocols.NNTP.Enabled {
+		fmt.Printf("Starting NNTP server on port %d...\n", cfg.Protocols.NNTP.Port)
+		nntpServer := nntp.New(&cfg.Protocols.NNTP, cfg, st, aggMgr)
+		nntpServer.SetRateLimiter(rateLimiter)
+		nntpServer.SetIdleMonitor(idleMonitor)
+
+		// Custom sections are served as newsgroups
+		nntpServer.SetSectionManager(sectionManager)
+
+		if err := nntpServer.Start(); err != nil {
+			return fmt.Errorf("failed to start NNTP server: %w", err)
+		}
+		started("nntp", fmt.Sprintf("Listening on port %d", cfg.Protocols.NNTP.Port), nntpServer)
+		fmt.Println("  NNTP server ready")
+	}
+
+	// Telnet BBS
+	if cfg.Protocols.Telnet.Enabled {
+		fmt.Printf("Starting telnet server on port %d...\n", cfg.Protocols.Telnet.Port)
+		telnetServer := telnet.New(&cfg.Protocols.Telnet, cfg, st, aggMgr)
+		telnetServer.SetRateLimiter(rateLimiter)
+		telnetServer.SetIdleMonitor(idleMonitor)
+
+		// The BBS shows the Gopher menus, custom sections included
+		telnetServer.SetSectionManager(sectionManager)
+
+		if err := telnetServer.Start(); err != nil {
+			return fmt.Errorf("failed to start telnet server: %w", err)
+		}
+		started("telnet", fmt.Sprintf("Listening on port %d", cfg.Protocols.Telnet.Port), telnetServer)
+		fmt.Println("  Telnet server ready")
+	}
+
+	// Quote of the Day
+	if cfg.Protocols.QOTD.Enabled {
+		fmt.Printf("Starting QOTD server on port %d...\n", cfg.Protocols.QOTD.Port)
+		qotdServer := qotd.New(&cfg.Protocols.QOTD, cfg, st, aggMgr)
+		qotdServer.SetRateLimiter(rateLimiter)
+		qotdServer.SetIdleMonitor(idleMonitor)
+
+		if err := qotdServer.Start(); err != nil {
+			return fmt.Errorf("failed to start QOTD server: %w", err)
+		}
+		started("qotd", fmt.Sprintf("Listening on port %d", cfg.Protocols.QOTD.Port), qotdServer)
+		fmt.Println("  QOTD server ready")
+	}
+
+	// Nostr relay
+	if cfg.Protocols.Relay.Enabled {
+		fmt.Printf("Starting relay on port %d...\n", cfg.Protocols.Relay.Port)
+		relayServer := relay.New(&cfg.Protocols.Relay, cfg, st, aggMgr)
+		relayServer.SetRateLimiter(rateLimiter)
+		relayServer.SetIdleMonitor(idleMonitor)
+
+		// Published events go through the same pipeline as synced ones
+		if syncEngine != nil {
+			relayServer.SetIngester(syncEngine.Ingest)
+		}
+
+		if err := relayServer.Start(); err != nil {
+			return fmt.Errorf("failed to start relay: %w", err)
+		}
+		started("relay", fmt.Sprintf("Listening on port %d", cfg.Protocols.Relay.Port), relayServer)
+		fmt.Println("  Relay ready")
+	}
+
 	if len(servers) == 0 {
 		return fmt.Errorf("no protocol servers enabled")
 	}
 
+	// Admin API on a local socket, for managing the running instance
+	if cfg.Security.Admin.Enabled {
+		adminServer := admin.New(&cfg.Security.Admin, configPath)
+		adminServer.SetCache(responseCache)
+		adminServer.SetSyncEngine(syncEngine)
+		adminServer.SetSectionManager(sectionManager)
+		adminServer.SetDenyList(denyList)
+		adminServer.SetDiagnostics(diagnostics)
+		adminServer.SetStorage(st)
+		if err := adminServer.Start(); err != nil {
+			return err
+		}
+		defer func() { lifecycle.Stopped("admin", adminServer.Stop()) }()
+		lifecycle.Started("admin", "Listening on "+cfg.Security.Admin.Socket)
+	}
+
+	if warmer != nil {
+		go warmCache(ctx, warmer)
+	}
+
 	fmt.Println()
 	fmt.Println("✓ All services started successfully!")
 	fmt.Println()
@@ -220,16 +703,57 @@ func run(cfg *config.Config) error {
 	fmt.Println("Shutting down gracefully...")
 
 	// Stop all servers
-	for _, server := range servers {
-		if err := server.Stop(); err != nil {
+	for _, running := range servers {
+		err := running.server.Stop()
+		if err != nil {
 			fmt.Fprintf(os.Stderr, "Error stopping server: %v\n", err)
 		}
+		lifecycle.Stopped(running.name, err)
 	}
 
 	fmt.Println("✓ Shutdown complete")
 	return nil
 }
 
+// warmCache pre-renders hot pages into the response cache
+func warmCache(ctx context.Context, warmer *cache.Warmer) {
+	start := time.Now()
+	if err := warmer.WarmAll(ctx); err != nil {
+		fmt.Printf("Cache warming incomplete: %v\n", err)
+		return
+	}
+	fmt.Printf("Cache warmed in %s\n", time.Since(start).Round(time.Millisecond))
+}
+
+// loadSections creates a section manager serving the given sections
+func loadSections(cfg *config.Config, sectionConfigs []config.SectionConfig, st *storage.Storage) (*sections.Manager, error) {
+	manager := sections.NewManager(st)
+	if _, owner, err := nip19.Decode(cfg.Identity.Npub); err == nil {
+		manager.SetOwner(owner.(string))
+	}
+	manager.SetScopeLimits(&cfg.Sync.Scope)
+	if err := sections.LoadFromConfig(manager, sectionConfigs); err != nil {
+		return nil, fmt.Errorf("failed to load sections: %w", err)
+	}
+	return manager, nil
+}
+
+// profileNote describes a listener's profile for startup messages
+func profileNote(profile string) string {
+	if profile == "" {
+		return ""
+	}
+	return fmt.Sprintf(" (profile %s)", profile)
+}
+
+// renderTTL returns the caching.ttl.render entry for key, defaulting to 5 minutes
+func renderTTL(cfg *config.Config, key string) time.Duration {
+	if seconds, ok := cfg.Caching.TTL.Render[key]; ok && seconds > 0 {
+		return time.Duration(seconds) * time.Second
+	}
+	return 5 * time.Minute
+}
+
 func handleInit() {
 	exampleConfig, err := config.GetExampleConfig()
 	if err != nil {
@@ -0,0 +1,28 @@
+package main
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestTakeGlobalConfig(t *testing.T) {
+	tests := []struct {
+		args   []string
+		config string
+		rest   []string
+	}{
+		{[]string{"--config", "a.yaml", "sync", "--once"}, "a.yaml", []string{"sync", "--once"}},
+		{[]string{"-config=b.yaml", "status"}, "b.yaml", []string{"status"}},
+		{[]string{"--config=c.yaml"}, "c.yaml", []string{}},
+		{[]string{"status", "--config", "d.yaml"}, "", []string{"status", "--config", "d.yaml"}}, // The subcommand's own flag
+		{[]string{"--config"}, "", []string{"--config"}},                                         // Left for the flag parser to reject
+	}
+	for _, tt := range tests {
+		globalConfig = ""
+		rest := takeGlobalConfig(tt.args)
+		if globalConfig != tt.config || !slices.Equal(rest, tt.rest) {
+			t.Errorf("takeGlobalConfig(%q) = %q with config %q, want %q with %q", tt.args, rest, globalConfig, tt.rest, tt.config)
+		}
+	}
+	globalConfig = ""
+}
@@ -0,0 +1,106 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/sandwich/nophr/internal/config"
+	"github.com/sandwich/nophr/internal/storage"
+)
+
+// handleMigrate handles the "nophr migrate" subcommands
+func handleMigrate(args []string) {
+	if len(args) == 0 || (args[0] != "status" && args[0] != "up" && args[0] != "down") {
+		printMigrateUsage()
+		os.Exit(1)
+	}
+
+	fs := flag.NewFlagSet("migrate "+args[0], flag.ExitOnError)
+	configPath := fs.String("config", globalConfig, "Path to configuration file")
+	target := fs.Int("to", -1, "Version to roll back to (down only; default one version back)")
+	fs.Usage = printMigrateUsage
+	fs.Parse(args[1:])
+
+	if *configPath == "" {
+		fmt.Fprintln(os.Stderr, "Error: --config is required")
+		os.Exit(1)
+	}
+
+	cfg, err := config.Load(*configPath)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error loading configuration: %v\n", err)
+		os.Exit(1)
+	}
+
+	// Creating storage applies pending migrations, which is all "up" does;
+	// status and down look at the schema as it is
+	ctx := context.Background()
+	open := storage.Open
+	if args[0] == "up" {
+		open = storage.New
+	}
+	st, err := open(ctx, &cfg.Storage)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error initializing storage: %v\n", err)
+		os.Exit(1)
+	}
+	defer st.Close()
+
+	if args[0] == "down" {
+		migrations, err := st.Migrations(ctx)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		applied := 0
+		for _, m := range migrations {
+			if !m.AppliedAt.IsZero() {
+				applied = m.Version
+			}
+		}
+		to := *target
+		if to < 0 {
+			to = applied - 1
+		}
+		if to >= applied {
+			fmt.Printf("Schema is already at version %d\n", applied)
+			return
+		}
+		if err := st.MigrateDown(ctx, to); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Printf("✓ Schema rolled back to version %d\n", to)
+		fmt.Println("  Start an older nophr now; starting this one migrates up again.")
+	}
+
+	migrations, err := st.Migrations(ctx)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	fmt.Println("Schema migrations:")
+	for _, m := range migrations {
+		applied := "pending"
+		if !m.AppliedAt.IsZero() {
+			applied = "applied " + m.AppliedAt.Format("2006-01-02 15:04:05")
+		}
+		fmt.Printf("  %04d %-24s %s\n", m.Version, m.Name, applied)
+	}
+}
+
+// printMigrateUsage prints usage for the migrate subcommands
+func printMigrateUsage() {
+	fmt.Println("Usage: nophr migrate <status|up|down> --config <path> [--to <version>]")
+	fmt.Println()
+	fmt.Println("Storage applies pending schema migrations whenever nophr starts, and refuses")
+	fmt.Println("to start on a database migrated by a newer nophr.")
+	fmt.Println()
+	fmt.Println("  status   List the migrations and when each was applied")
+	fmt.Println("  up       Apply pending migrations without starting the servers")
+	fmt.Println("  down     Roll back to --to <version>, by default one version back, before")
+	fmt.Println("           downgrading nophr. Stop the running instance first. Version 1,")
+	fmt.Println("           the baseline schema, can't be rolled back.")
+}
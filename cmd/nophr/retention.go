@@ -0,0 +1,229 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/sandwich/nophr/internal/config"
+	"github.com/sandwich/nophr/internal/nostr/helpers"
+	"github.com/sandwich/nophr/internal/ops"
+	"github.com/sandwich/nophr/internal/storage"
+)
+
+// handleRetention handles the "nophr retention" subcommands
+func handleRetention(args []string) {
+	if len(args) == 0 {
+		printRetentionUsage()
+		os.Exit(1)
+	}
+
+	fs := flag.NewFlagSet("retention "+args[0], flag.ExitOnError)
+	configPath := fs.String("config", globalConfig, "Path to configuration file")
+	limit := fs.Int("limit", 100, "Maximum number of events to list")
+	fs.Parse(args[1:])
+
+	if *configPath == "" {
+		fmt.Fprintln(os.Stderr, "Error: --config is required")
+		os.Exit(1)
+	}
+
+	cfg, err := config.Load(*configPath)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error loading configuration: %v\n", err)
+		os.Exit(1)
+	}
+
+	ctx := context.Background()
+	st, err := storage.New(ctx, &cfg.Storage)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error initializing storage: %v\n", err)
+		os.Exit(1)
+	}
+	defer st.Close()
+
+	logger := ops.NewLogger(&cfg.Logging)
+	retentionMgr := ops.NewRetentionManager(st, &cfg.Sync.Retention, logger, cfg.Identity.Npub)
+
+	switch args[0] {
+	case "protect", "unprotect":
+		err = runProtect(ctx, retentionMgr, args[0], fs.Args())
+	case "protected":
+		err = runListProtected(ctx, retentionMgr, *limit)
+	case "explain":
+		err = runExplain(ctx, retentionMgr, fs.Args())
+	case "plan":
+		err = runPlan(ctx, retentionMgr)
+	default:
+		printRetentionUsage()
+		os.Exit(1)
+	}
+
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+// runProtect pins or unpins the given events
+func runProtect(ctx context.Context, rm *ops.RetentionManager, action string, ids []string) error {
+	if len(ids) == 0 {
+		return fmt.Errorf("%s requires at least one event ID", action)
+	}
+
+	for _, id := range ids {
+		eventID, err := helpers.NormalizeEventID(id)
+		if err != nil {
+			return err
+		}
+
+		if action == "protect" {
+			err = rm.ProtectEvent(ctx, eventID)
+		} else {
+			err = rm.UnprotectEvent(ctx, eventID)
+		}
+		if err != nil {
+			return err
+		}
+
+		fmt.Printf("✓ %sed %s\n", action, eventID)
+	}
+
+	return nil
+}
+
+// runListProtected prints the currently protected events
+func runListProtected(ctx context.Context, rm *ops.RetentionManager, limit int) error {
+	protected, err := rm.ListProtectedEvents(ctx, limit)
+	if err != nil {
+		return err
+	}
+
+	if len(protected) == 0 {
+		fmt.Println("No protected events")
+		return nil
+	}
+
+	fmt.Printf("%-64s  %-20s  %s\n", "EVENT", "RULE", "SINCE")
+	for _, meta := range protected {
+		fmt.Printf("%-64s  %-20s  %s\n", meta.EventID, meta.RuleName, meta.LastEvaluatedAt.Format(time.RFC3339))
+	}
+	fmt.Printf("\n%d protected events\n", len(protected))
+
+	return nil
+}
+
+// runExplain prints which rule matches an event, its score and retain-until date
+func runExplain(ctx context.Context, rm *ops.RetentionManager, ids []string) error {
+	if len(ids) != 1 {
+		return fmt.Errorf("explain requires exactly one event ID")
+	}
+
+	eventID, err := helpers.NormalizeEventID(ids[0])
+	if err != nil {
+		return err
+	}
+
+	explanation, stored, err := rm.ExplainEvent(ctx, eventID)
+	if err != nil {
+		return err
+	}
+
+	decision := explanation.Decision
+	fmt.Printf("Event:        %s\n", eventID)
+	if explanation.MatchedRule != nil {
+		fmt.Printf("Rule:         %s (priority %d)\n", explanation.MatchedRule.Name, explanation.MatchedRule.Priority)
+		if explanation.MatchedRule.Description != "" {
+			fmt.Printf("              %s\n", explanation.MatchedRule.Description)
+		}
+		fmt.Printf("Score:        %s\n", explanation.Score)
+	} else {
+		fmt.Printf("Rule:         none matched (%s)\n", decision.RuleName)
+	}
+	fmt.Printf("Retain until: %s\n", formatRetainUntil(decision.RetainUntil))
+	fmt.Printf("Protected:    %t\n", decision.Protected)
+
+	fmt.Println()
+	if stored == nil {
+		fmt.Println("Stored:       not yet evaluated")
+		return nil
+	}
+	fmt.Printf("Stored:       rule %s, score %d, retain until %s, evaluated %s\n",
+		stored.RuleName, stored.Score, formatRetainUntil(stored.RetainUntil),
+		stored.LastEvaluatedAt.Format(time.RFC3339))
+	if stored.RuleName == storage.ManualPinRuleName {
+		fmt.Println("              manually protected; rules are not applied")
+	}
+
+	return nil
+}
+
+// runPlan prints what a pruning run would remove, per rule and cap, without deleting anything
+func runPlan(ctx context.Context, rm *ops.RetentionManager) error {
+	plan, err := rm.PlanPrune(ctx)
+	if err != nil {
+		return err
+	}
+
+	mode := "keep_days"
+	if plan.Advanced {
+		mode = "advanced rules and caps"
+	}
+	fmt.Printf("Retention plan (%s), %d events scanned\n\n", mode, plan.ScannedEvents)
+
+	events, bytes := plan.Totals()
+	if events == 0 {
+		fmt.Println("Nothing would be removed")
+		return nil
+	}
+
+	fmt.Printf("%-28s  %8s  %10s  %-20s  %-20s\n", "RULE", "EVENTS", "BYTES", "OLDEST", "NEWEST")
+	for _, g := range plan.Groups {
+		fmt.Printf("%-28s  %8d  %10s  %-20s  %-20s\n", g.Name, g.Events, formatBytes(g.Bytes),
+			g.Oldest.Format(time.RFC3339), g.Newest.Format(time.RFC3339))
+	}
+	fmt.Printf("\n%d events (%s) would be removed\n", events, formatBytes(bytes))
+
+	if plan.TrashGraceDays > 0 {
+		fmt.Printf("Removed events stay restorable in the trash for %d days\n", plan.TrashGraceDays)
+	}
+	fmt.Println("Dry run: nothing was deleted")
+
+	return nil
+}
+
+// formatBytes formats a byte count with a binary unit, e.g. "1.5 MiB"
+func formatBytes(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	div, exp := int64(unit), 0
+	for m := n / unit; m >= unit; m /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(n)/float64(div), "KMGTPE"[exp])
+}
+
+// formatRetainUntil formats a retain-until time, where nil means forever
+func formatRetainUntil(t *time.Time) string {
+	if t == nil {
+		return "forever"
+	}
+	return t.Format(time.RFC3339)
+}
+
+// printRetentionUsage prints usage for the retention subcommands
+func printRetentionUsage() {
+	fmt.Println("Usage: nophr retention <command> --config <path> [args]")
+	fmt.Println()
+	fmt.Println("Commands:")
+	fmt.Println("  protect <event-id>...     Pin events so they are never pruned")
+	fmt.Println("  unprotect <event-id>...   Remove manual pins")
+	fmt.Println("  protected [--limit N]     List protected events")
+	fmt.Println("  explain <event-id>        Show the matching rule, score and retain-until date")
+	fmt.Println("  plan                      Report what pruning would remove, without deleting")
+}
@@ -0,0 +1,173 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"net"
+	"os"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/nbd-wtf/go-nostr/nip19"
+	"github.com/sandwich/nophr/internal/config"
+	"github.com/sandwich/nophr/internal/ops"
+	"github.com/sandwich/nophr/internal/storage"
+	"github.com/sandwich/nophr/internal/sync"
+)
+
+// statusTimeout bounds each listener probe
+const statusTimeout = 2 * time.Second
+
+// handleStatus handles the "nophr status" subcommand
+func handleStatus(args []string) {
+	fs := flag.NewFlagSet("status", flag.ExitOnError)
+	configPath := fs.String("config", globalConfig, "Path to configuration file")
+	fs.Usage = printStatusUsage
+	fs.Parse(args)
+
+	if *configPath == "" || fs.NArg() != 0 {
+		printStatusUsage()
+		os.Exit(1)
+	}
+
+	cfg, err := config.Load(*configPath)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error loading configuration: %v\n", err)
+		os.Exit(1)
+	}
+
+	if err := runStatus(cfg); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+// statusListener is an enabled protocol listener to probe
+type statusListener struct {
+	name string
+	bind string
+	port int
+}
+
+// runStatus probes each enabled listener and prints what is stored
+func runStatus(cfg *config.Config) error {
+	fmt.Println("Listeners:")
+	listeners := enabledListeners(cfg)
+	if len(listeners) == 0 {
+		fmt.Println("  (none enabled)")
+	}
+	for _, l := range listeners {
+		addr := probeAddr(l.bind, l.port)
+		state := "up"
+		if conn, err := net.DialTimeout("tcp", addr, statusTimeout); err != nil {
+			state = "down"
+		} else {
+			conn.Close()
+		}
+		fmt.Printf("  %-8s %-22s %s\n", l.name, addr, state)
+	}
+
+	ctx := context.Background()
+	st, err := storage.New(ctx, &cfg.Storage)
+	if err != nil {
+		return fmt.Errorf("failed to initialize storage: %w", err)
+	}
+	defer st.Close()
+
+	diagnostics := ops.NewDiagnosticsCollector(version, commit, st, nil)
+	stats, err := diagnostics.CollectStorageStats(ctx)
+	if err != nil {
+		return err
+	}
+
+	fmt.Println()
+	fmt.Printf("Storage (%s):\n", stats.Driver)
+	fmt.Printf("  Events:   %d\n", stats.TotalEvents)
+	fmt.Printf("  Size:     %.2f MB\n", stats.DatabaseSizeMB)
+	if stats.OldestEventTime != nil && stats.NewestEventTime != nil {
+		fmt.Printf("  Oldest:   %s\n", stats.OldestEventTime.Format(time.RFC3339))
+		fmt.Printf("  Newest:   %s\n", stats.NewestEventTime.Format(time.RFC3339))
+	}
+
+	kinds := make([]int, 0, len(stats.EventsByKind))
+	for kind := range stats.EventsByKind {
+		kinds = append(kinds, kind)
+	}
+	sort.Ints(kinds)
+	for _, kind := range kinds {
+		fmt.Printf("  Kind %-5d %d\n", kind, stats.EventsByKind[kind])
+	}
+
+	aggStats, err := diagnostics.CollectAggregateStats(ctx)
+	if err == nil {
+		fmt.Println()
+		fmt.Printf("Aggregates: %d\n", aggStats.TotalAggregates)
+	}
+
+	cursors, err := st.GetAllCursors(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to read sync cursors: %w", err)
+	}
+	fmt.Println()
+	fmt.Printf("Sync cursors: %d\n", len(cursors))
+	var lastSync time.Time
+	for _, c := range cursors {
+		if c.Updated.After(lastSync) {
+			lastSync = c.Updated
+		}
+	}
+	if !lastSync.IsZero() {
+		fmt.Printf("  Last updated: %s\n", lastSync.Format(time.RFC3339))
+	}
+
+	if _, owner, err := nip19.Decode(cfg.Identity.Npub); err == nil {
+		state, err := st.GetBootstrapState(ctx, owner.(string))
+		if err != nil {
+			return err
+		}
+		fmt.Printf("Bootstrap: %s", sync.DescribeBootstrap(state))
+		if state != nil {
+			fmt.Printf(" (%s)", time.Unix(state.UpdatedAt, 0).Format(time.RFC3339))
+		}
+		fmt.Println()
+	}
+	return nil
+}
+
+// enabledListeners lists the enabled protocols and where they listen
+func enabledListeners(cfg *config.Config) []statusListener {
+	p := cfg.Protocols
+	var listeners []statusListener
+	add := func(enabled bool, name, bind string, port int) {
+		if enabled {
+			listeners = append(listeners, statusListener{name: name, bind: bind, port: port})
+		}
+	}
+	add(p.Gopher.Enabled, "gopher", p.Gopher.Bind, p.Gopher.Port)
+	add(p.Gemini.Enabled, "gemini", p.Gemini.Bind, p.Gemini.Port)
+	add(p.Finger.Enabled, "finger", p.Finger.Bind, p.Finger.Port)
+	add(p.NNTP.Enabled, "nntp", p.NNTP.Bind, p.NNTP.Port)
+	add(p.Telnet.Enabled, "telnet", p.Telnet.Bind, p.Telnet.Port)
+	add(p.QOTD.Enabled, "qotd", p.QOTD.Bind, p.QOTD.Port)
+	add(p.Relay.Enabled, "relay", p.Relay.Bind, p.Relay.Port)
+	return listeners
+}
+
+// probeAddr is the address to dial for a listener, using loopback for
+// wildcard binds
+func probeAddr(bind string, port int) string {
+	if bind == "" || bind == "0.0.0.0" || bind == "::" {
+		bind = "127.0.0.1"
+	}
+	return net.JoinHostPort(bind, strconv.Itoa(port))
+}
+
+func printStatusUsage() {
+	fmt.Println("Usage: nophr status --config <path>")
+	fmt.Println()
+	fmt.Println("Report whether each enabled server accepts connections, then read the")
+	fmt.Println("database and print event counts, the stored time range, aggregates and")
+	fmt.Println("when the sync cursors last moved. Works whether or not nophr is running.")
+}
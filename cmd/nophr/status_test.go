@@ -0,0 +1,59 @@
+package main
+
+import (
+	"net"
+	"path/filepath"
+	"testing"
+
+	"github.com/sandwich/nophr/internal/config"
+)
+
+func TestEnabledListeners(t *testing.T) {
+	cfg := config.Default()
+	cfg.Protocols.Gopher.Enabled = true
+	cfg.Protocols.Gopher.Bind = "0.0.0.0"
+	cfg.Protocols.Gopher.Port = 7070
+	cfg.Protocols.Gemini.Enabled = false
+	cfg.Protocols.Finger.Enabled = true
+	cfg.Protocols.Finger.Bind = "10.0.0.2"
+	cfg.Protocols.Finger.Port = 7079
+	cfg.Protocols.NNTP.Enabled = false
+	cfg.Protocols.Telnet.Enabled = false
+	cfg.Protocols.QOTD.Enabled = false
+	cfg.Protocols.Relay.Enabled = false
+
+	listeners := enabledListeners(cfg)
+	if len(listeners) != 2 || listeners[0].name != "gopher" || listeners[1].name != "finger" {
+		t.Fatalf("enabledListeners() = %+v, want gopher and finger", listeners)
+	}
+
+	// Wildcard binds are probed on loopback
+	if addr := probeAddr(listeners[0].bind, listeners[0].port); addr != "127.0.0.1:7070" {
+		t.Errorf("probeAddr() = %s, want 127.0.0.1:7070", addr)
+	}
+	if addr := probeAddr(listeners[1].bind, listeners[1].port); addr != "10.0.0.2:7079" {
+		t.Errorf("probeAddr() = %s, want 10.0.0.2:7079", addr)
+	}
+	if addr := probeAddr("::", 70); addr != "127.0.0.1:70" {
+		t.Errorf("probeAddr(::) = %s, want 127.0.0.1:70", addr)
+	}
+}
+
+func TestRunStatus(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+	defer listener.Close()
+
+	cfg := config.Default()
+	cfg.Identity.Npub = "npub1nq3zgtqruwhnz0xx40gh4a4fkamlr2sc7ke5wqs2s3nyv2fpy9esg4hdwq"
+	cfg.Storage.SQLitePath = filepath.Join(t.TempDir(), "nophr.db")
+	cfg.Protocols.Gopher.Enabled = true
+	cfg.Protocols.Gopher.Bind = "127.0.0.1"
+	cfg.Protocols.Gopher.Port = listener.Addr().(*net.TCPAddr).Port
+
+	if err := runStatus(cfg); err != nil {
+		t.Errorf("runStatus() error = %v", err)
+	}
+}
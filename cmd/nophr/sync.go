@@ -0,0 +1,102 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/sandwich/nophr/internal/config"
+	"github.com/sandwich/nophr/internal/ops"
+	"github.com/sandwich/nophr/internal/storage"
+	"github.com/sandwich/nophr/internal/sync"
+)
+
+// handleSync handles the "nophr sync" subcommand
+func handleSync(args []string) {
+	fs := flag.NewFlagSet("sync", flag.ExitOnError)
+	configPath := fs.String("config", globalConfig, "Path to configuration file")
+	once := fs.Bool("once", false, "Run one sync pass and exit")
+	fs.Usage = printSyncUsage
+	fs.Parse(args)
+
+	if *configPath == "" || fs.NArg() != 0 {
+		printSyncUsage()
+		os.Exit(1)
+	}
+
+	cfg, err := config.Load(*configPath)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error loading configuration: %v\n", err)
+		os.Exit(1)
+	}
+
+	if err := runSync(cfg, *once); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+// runSync syncs from relays into storage without starting any protocol
+// server, either once or until interrupted
+func runSync(cfg *config.Config, once bool) error {
+	ctx := context.Background()
+	st, err := storage.New(ctx, &cfg.Storage)
+	if err != nil {
+		return fmt.Errorf("failed to initialize storage: %w", err)
+	}
+	defer st.Close()
+
+	before, err := st.CountEvents(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to count events: %w", err)
+	}
+
+	engine := sync.NewEngine(st, cfg)
+	if cfg.Sync.Retention.Advanced != nil && cfg.Sync.Retention.Advanced.Enabled {
+		logger := ops.NewLogger(&cfg.Logging)
+		retentionMgr := ops.NewRetentionManager(st, &cfg.Sync.Retention, logger, cfg.Identity.Npub)
+		engine.SetRetentionEvaluator(retentionMgr.EvaluateEvent)
+	}
+
+	if once {
+		if err := engine.RunOnce(); err != nil {
+			return err
+		}
+	} else {
+		if err := engine.Start(); err != nil {
+			return fmt.Errorf("failed to start sync engine: %w", err)
+		}
+		fmt.Println("Syncing until interrupted, press Ctrl+C to stop...")
+
+		sigChan := make(chan os.Signal, 1)
+		signal.Notify(sigChan, os.Interrupt, syscall.SIGTERM)
+		<-sigChan
+		engine.Stop()
+	}
+
+	after, err := st.CountEvents(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to count events: %w", err)
+	}
+	fmt.Println("Sync complete")
+	fmt.Printf("  Events stored: %d (%+d)\n", after, after-before)
+	return nil
+}
+
+func printSyncUsage() {
+	fmt.Println("Usage: nophr sync --config <path> [--once]")
+	fmt.Println()
+	fmt.Println("Sync events from relays into storage without starting any protocol server,")
+	fmt.Println("for filling the database before the first \"nophr serve\" or from a cron job")
+	fmt.Println("next to a static export.")
+	fmt.Println()
+	fmt.Println("Options:")
+	fmt.Println("  --config <path>   Configuration file")
+	fmt.Println("  --once            Bootstrap, run one pass over every relay, then exit")
+	fmt.Println()
+	fmt.Println("Without --once, sync keeps running until interrupted. Don't run it against")
+	fmt.Println("a database that \"nophr serve\" is already syncing into.")
+}
@@ -0,0 +1,188 @@
+package activity
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/nbd-wtf/go-nostr"
+	"github.com/sandwich/nophr/internal/aggregates"
+	"github.com/sandwich/nophr/internal/storage"
+)
+
+const (
+	// Weeks is how many week columns the heatmap shows, a year's worth
+	Weeks = 53
+
+	// cacheTTL is how long a built heatmap is reused before posts made since
+	// are counted
+	cacheTTL = 10 * time.Minute
+)
+
+// postKinds are the events counted as posting activity
+var postKinds = []int{nostr.KindTextNote, nostr.KindArticle}
+
+// Cells are the characters drawn for the five activity levels, from no posts
+// to the busiest days
+type Cells [5]string
+
+var (
+	// BlockCells draws levels with Unicode shade blocks, for Gemini
+	BlockCells = Cells{"·", "░", "▒", "▓", "█"}
+
+	// ASCIICells draws levels with plain ASCII, for Gopher clients that can't show Unicode
+	ASCIICells = Cells{".", "-", "+", "*", "#"}
+)
+
+// Heatmap is the owner's posts per UTC day, laid out as weeks (columns) by
+// weekdays (rows) starting on a Sunday
+type Heatmap struct {
+	Start time.Time // Sunday the first column starts on
+	End   time.Time // Last day counted, the day the heatmap was built
+	Days  []int     // Posts per day from Start to End
+	Max   int       // Most posts on one day
+	Total int       // Posts over the whole heatmap
+}
+
+// Builder computes the heatmap from stored events and caches it briefly
+type Builder struct {
+	storage *storage.Storage
+	queries *aggregates.QueryHelper
+
+	mu      sync.Mutex
+	current *Heatmap
+	builtAt time.Time
+}
+
+// NewBuilder creates a heatmap builder
+func NewBuilder(st *storage.Storage, queries *aggregates.QueryHelper) *Builder {
+	return &Builder{
+		storage: st,
+		queries: queries,
+	}
+}
+
+// Current returns the heatmap ending today, rebuilding it once it is older
+// than cacheTTL or the UTC day has changed
+func (b *Builder) Current(ctx context.Context, now time.Time) (*Heatmap, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	today := now.UTC().Truncate(24 * time.Hour)
+	if b.current != nil && b.current.End.Equal(today) && now.Sub(b.builtAt) < cacheTTL {
+		return b.current, nil
+	}
+
+	heatmap, err := b.Build(ctx, now)
+	if err != nil {
+		return nil, err
+	}
+	b.current, b.builtAt = heatmap, now
+	return heatmap, nil
+}
+
+// Build counts the owner's notes and articles per day for the Weeks weeks up to now
+func (b *Builder) Build(ctx context.Context, now time.Time) (*Heatmap, error) {
+	ownerHex, err := b.queries.OwnerHex()
+	if err != nil {
+		return nil, err
+	}
+
+	today := now.UTC().Truncate(24 * time.Hour)
+	start := today.AddDate(0, 0, -int(today.Weekday())-7*(Weeks-1))
+
+	counts, err := b.storage.CountEventsByDay(ctx, ownerHex, postKinds, start)
+	if err != nil {
+		return nil, err
+	}
+
+	heatmap := &Heatmap{Start: start, End: today}
+	for day := start; !day.After(today); day = day.AddDate(0, 0, 1) {
+		count := int(counts[day.Format(time.DateOnly)])
+		heatmap.Days = append(heatmap.Days, count)
+		heatmap.Total += count
+		heatmap.Max = max(heatmap.Max, count)
+	}
+	return heatmap, nil
+}
+
+// Busiest returns the first day with the most posts
+func (h *Heatmap) Busiest() (time.Time, int) {
+	for i, count := range h.Days {
+		if count == h.Max {
+			return h.Start.AddDate(0, 0, i), count
+		}
+	}
+	return h.Start, 0
+}
+
+// level maps a day's posts to 0 (none) through 4 (the busiest days), relative to Max
+func (h *Heatmap) level(count int) int {
+	if count == 0 || h.Max == 0 {
+		return 0
+	}
+	return min(4, (4*count+h.Max-1)/h.Max)
+}
+
+// Render draws the heatmap as text lines: month labels, one row per weekday
+// and a legend. Days after End are left blank.
+func (h *Heatmap) Render(cells Cells) []string {
+	const labelWidth = 4 // "Mon "
+
+	// Label each month above the first week column that starts in it, if it fits
+	months := []byte(strings.Repeat(" ", Weeks))
+	free := 0
+	for week := 0; week < Weeks; week++ {
+		first := h.Start.AddDate(0, 0, 7*week)
+		if week > 0 && first.Month() == first.AddDate(0, 0, -7).Month() {
+			continue
+		}
+		if week >= free && week+3 <= Weeks {
+			copy(months[week:], first.Format("Jan"))
+			free = week + 4
+		}
+	}
+	lines := []string{strings.Repeat(" ", labelWidth) + strings.TrimRight(string(months), " ")}
+
+	for weekday := time.Sunday; weekday <= time.Saturday; weekday++ {
+		var row strings.Builder
+		switch weekday {
+		case time.Monday, time.Wednesday, time.Friday:
+			row.WriteString(weekday.String()[:3] + " ")
+		default:
+			row.WriteString(strings.Repeat(" ", labelWidth))
+		}
+
+		for week := 0; week < Weeks; week++ {
+			day := 7*week + int(weekday)
+			if day >= len(h.Days) {
+				break
+			}
+			row.WriteString(cells[h.level(h.Days[day])])
+		}
+		lines = append(lines, row.String())
+	}
+
+	lines = append(lines, "", fmt.Sprintf("%sLess %s More", strings.Repeat(" ", labelWidth), strings.Join(cells[:], " ")))
+	return lines
+}
+
+// Summary describes the heatmap in words, e.g. "214 posts in the last year.
+// Busiest day: Mar 4, 2026 (9 posts)."
+func (h *Heatmap) Summary() string {
+	if h.Total == 0 {
+		return "No posts in the last year."
+	}
+	day, count := h.Busiest()
+	return fmt.Sprintf("%s in the last year. Busiest day: %s (%s).",
+		plural(h.Total, "post", "posts"), day.Format("Jan 2, 2006"), plural(count, "post", "posts"))
+}
+
+func plural(n int, one, many string) string {
+	if n == 1 {
+		return "1 " + one
+	}
+	return fmt.Sprintf("%d %s", n, many)
+}
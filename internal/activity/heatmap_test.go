@@ -0,0 +1,100 @@
+package activity
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/nbd-wtf/go-nostr"
+	"github.com/nbd-wtf/go-nostr/nip19"
+	"github.com/sandwich/nophr/internal/aggregates"
+	"github.com/sandwich/nophr/internal/config"
+	"github.com/sandwich/nophr/internal/storage"
+)
+
+func TestHeatmap(t *testing.T) {
+	ctx := context.Background()
+	sk := nostr.GeneratePrivateKey()
+	pubkey, _ := nostr.GetPublicKey(sk)
+
+	cfg := config.Default()
+	cfg.Identity.Npub, _ = nip19.EncodePublicKey(pubkey)
+	cfg.Storage.SQLitePath = filepath.Join(t.TempDir(), "test.db")
+
+	st, err := storage.New(ctx, &cfg.Storage)
+	if err != nil {
+		t.Fatalf("Failed to create storage: %v", err)
+	}
+	defer st.Close()
+
+	// Thursday Oct 15, 2026; the first column starts on Sunday Oct 12, 2025
+	now := time.Date(2026, time.October, 15, 18, 0, 0, 0, time.UTC)
+	posted := 0
+	post := func(day time.Time, kind int) {
+		posted++
+		event := &nostr.Event{CreatedAt: nostr.Timestamp(day.Add(time.Duration(posted) * time.Hour).Unix()), Kind: kind, Content: "post"}
+		event.Sign(sk)
+		if err := st.StoreEvent(ctx, event); err != nil {
+			t.Fatalf("Failed to store event: %v", err)
+		}
+	}
+	busy := time.Date(2026, time.March, 4, 0, 0, 0, 0, time.UTC) // A Wednesday
+	for i := 0; i < 4; i++ {
+		post(busy, nostr.KindTextNote)
+	}
+	post(time.Date(2026, time.October, 12, 0, 0, 0, 0, time.UTC), nostr.KindArticle)
+	post(time.Date(2026, time.October, 13, 0, 0, 0, 0, time.UTC), nostr.KindReaction)
+	post(time.Date(2025, time.October, 11, 0, 0, 0, 0, time.UTC), nostr.KindTextNote) // Before the first column
+
+	builder := NewBuilder(st, aggregates.NewQueryHelper(st, cfg, aggregates.NewManager(st, cfg)))
+	heatmap, err := builder.Current(ctx, now)
+	if err != nil {
+		t.Fatalf("Current() error = %v", err)
+	}
+
+	if want := time.Date(2025, time.October, 12, 0, 0, 0, 0, time.UTC); !heatmap.Start.Equal(want) {
+		t.Errorf("Start = %v, want %v", heatmap.Start, want)
+	}
+	if heatmap.Total != 5 || heatmap.Max != 4 {
+		t.Errorf("Total = %d, Max = %d; want 5 and 4", heatmap.Total, heatmap.Max)
+	}
+	if got := heatmap.Summary(); got != "5 posts in the last year. Busiest day: Mar 4, 2026 (4 posts)." {
+		t.Errorf("Summary() = %q", got)
+	}
+
+	lines := heatmap.Render(ASCIICells)
+	if len(lines) != 10 {
+		t.Fatalf("Expected month labels, 7 weekday rows, a gap and a legend, got %d lines:\n%s", len(lines), strings.Join(lines, "\n"))
+	}
+	if !strings.HasPrefix(lines[0], "    Oct ") || !strings.Contains(lines[0], "Mar") {
+		t.Errorf("Unexpected month labels: %q", lines[0])
+	}
+
+	week := int(busy.Sub(heatmap.Start).Hours()) / (24 * 7)
+	wednesday := lines[1+int(time.Wednesday)]
+	if !strings.HasPrefix(wednesday, "Wed ") || wednesday[4+week] != '#' {
+		t.Errorf("Expected the busiest day drawn darkest in week %d: %q", week, wednesday)
+	}
+	monday := lines[1+int(time.Monday)]
+	if len(monday) != 4+Weeks || monday[len(monday)-1] != '-' {
+		t.Errorf("Expected the one-post Monday in the last column at the lowest level: %q", monday)
+	}
+	// The current week stops at today
+	if friday := lines[1+int(time.Friday)]; len(friday) != 4+Weeks-1 {
+		t.Errorf("Expected Friday's row to end before the current week, got %q", friday)
+	}
+
+	if !strings.Contains(strings.Join(heatmap.Render(BlockCells), "\n"), "█") {
+		t.Error("Expected the block variant to use shade characters")
+	}
+
+	// The heatmap is cached until it goes stale
+	if again, _ := builder.Current(ctx, now.Add(time.Minute)); again != heatmap {
+		t.Error("Expected the cached heatmap within the cache TTL")
+	}
+	if later, _ := builder.Current(ctx, now.Add(cacheTTL)); later == heatmap {
+		t.Error("Expected a rebuilt heatmap after the cache TTL")
+	}
+}
@@ -0,0 +1,314 @@
+// Package admin serves a control API for managing a running instance: HTTP
+// over a unix socket, for tooling and cron jobs, e.g.
+//
+//	curl --unix-socket data/nophr.sock -X POST http://nophr/cache/flush
+package admin
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/sandwich/nophr/internal/cache"
+	"github.com/sandwich/nophr/internal/config"
+	"github.com/sandwich/nophr/internal/nostr/helpers"
+	"github.com/sandwich/nophr/internal/ops"
+	"github.com/sandwich/nophr/internal/sections"
+	"github.com/sandwich/nophr/internal/security"
+	"github.com/sandwich/nophr/internal/storage"
+	"github.com/sandwich/nophr/internal/sync"
+)
+
+// Admin log page sizes for GET /log
+const (
+	defaultLogLimit = 100
+	maxLogLimit     = 1000
+)
+
+// Server is the admin API. Operations whose subsystem isn't set, such as
+// flushing the cache with caching disabled, answer 409 Conflict.
+type Server struct {
+	config     *config.Admin
+	configPath string // Reread when sections are reloaded
+
+	cache       cache.Cache
+	syncEngine  *sync.Engine
+	sections    *sections.Manager
+	denied      *security.DenyList
+	diagnostics *ops.DiagnosticsCollector
+	storage     *storage.Storage
+
+	listener net.Listener
+	http     *http.Server
+}
+
+// New creates an admin server for the instance started from configPath
+func New(cfg *config.Admin, configPath string) *Server {
+	return &Server{config: cfg, configPath: configPath}
+}
+
+// SetCache sets the response cache flushed by /cache/flush
+func (s *Server) SetCache(c cache.Cache) {
+	s.cache = c
+}
+
+// SetSyncEngine sets the engine /sync runs an iteration of
+func (s *Server) SetSyncEngine(engine *sync.Engine) {
+	s.syncEngine = engine
+}
+
+// SetSectionManager sets the sections replaced by /sections/reload
+func (s *Server) SetSectionManager(manager *sections.Manager) {
+	s.sections = manager
+}
+
+// SetDenyList sets the deny list /denylist adds to, shared with storage and sync
+func (s *Server) SetDenyList(denied *security.DenyList) {
+	s.denied = denied
+}
+
+// SetDiagnostics sets the collector behind /stats
+func (s *Server) SetDiagnostics(diagnostics *ops.DiagnosticsCollector) {
+	s.diagnostics = diagnostics
+}
+
+// SetStorage sets the storage whose admin log /log lists
+func (s *Server) SetStorage(st *storage.Storage) {
+	s.storage = st
+}
+
+// Handler returns the API's routes
+func (s *Server) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /stats", s.handleStats)
+	mux.HandleFunc("GET /capabilities", s.handleCapabilities)
+	mux.HandleFunc("POST /cache/flush", s.handleCacheFlush)
+	mux.HandleFunc("POST /sync", s.handleSync)
+	mux.HandleFunc("POST /sections/reload", s.handleSectionsReload)
+	mux.HandleFunc("GET /denylist", s.handleDenylist)
+	mux.HandleFunc("POST /denylist", s.handleDeny)
+	mux.HandleFunc("GET /log", s.handleLog)
+	return mux
+}
+
+// Start listens on the unix socket, replacing a stale socket left by an
+// instance that didn't shut down cleanly
+func (s *Server) Start() error {
+	path := s.config.Socket
+	if info, err := os.Lstat(path); err == nil {
+		if info.Mode()&os.ModeSocket == 0 {
+			return fmt.Errorf("failed to start admin API: %s exists and is not a socket", path)
+		}
+		if conn, err := net.Dial("unix", path); err == nil {
+			conn.Close()
+			return fmt.Errorf("failed to start admin API: %s is in use by another instance", path)
+		}
+		os.Remove(path)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to start admin API: %w", err)
+	}
+
+	listener, err := net.Listen("unix", path)
+	if err != nil {
+		return fmt.Errorf("failed to start admin API: %w", err)
+	}
+	if err := os.Chmod(path, 0600); err != nil {
+		listener.Close()
+		return fmt.Errorf("failed to restrict admin socket: %w", err)
+	}
+
+	s.listener = listener
+	s.http = &http.Server{Handler: s.Handler(), ReadHeaderTimeout: 10 * time.Second}
+	go s.http.Serve(listener)
+
+	fmt.Printf("Admin API listening on %s\n", path)
+	return nil
+}
+
+// Stop closes the listener and removes the socket
+func (s *Server) Stop() error {
+	if s.http == nil {
+		return nil
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	err := s.http.Shutdown(ctx)
+	os.Remove(s.config.Socket)
+	return err
+}
+
+// handleStats answers with the diagnostics page's statistics as JSON
+func (s *Server) handleStats(w http.ResponseWriter, r *http.Request) {
+	if s.diagnostics == nil {
+		writeError(w, http.StatusConflict, errors.New("diagnostics are unavailable"))
+		return
+	}
+	diag, err := s.diagnostics.CollectAll(r.Context())
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, diag)
+}
+
+// handleCapabilities answers with the stable summary meant for monitoring
+func (s *Server) handleCapabilities(w http.ResponseWriter, r *http.Request) {
+	if s.diagnostics == nil {
+		writeError(w, http.StatusConflict, errors.New("diagnostics are unavailable"))
+		return
+	}
+	caps, err := s.diagnostics.CollectCapabilities(r.Context())
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, caps)
+}
+
+// handleCacheFlush drops every cached response
+func (s *Server) handleCacheFlush(w http.ResponseWriter, r *http.Request) {
+	if s.cache == nil {
+		writeError(w, http.StatusConflict, errors.New("caching is disabled"))
+		return
+	}
+	if err := s.cache.Clear(r.Context()); err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"flushed": true})
+}
+
+// handleSync starts a sync iteration now rather than at the next tick
+func (s *Server) handleSync(w http.ResponseWriter, r *http.Request) {
+	if s.syncEngine == nil {
+		writeError(w, http.StatusConflict, errors.New("sync is disabled"))
+		return
+	}
+	if err := s.syncEngine.SyncNow(); err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	writeJSON(w, http.StatusAccepted, map[string]any{"started": true})
+}
+
+// handleSectionsReload rereads the configuration file and replaces the
+// sections. Other settings keep their startup values. Cached pages are
+// flushed so menus list the new sections.
+func (s *Server) handleSectionsReload(w http.ResponseWriter, r *http.Request) {
+	if s.sections == nil {
+		writeError(w, http.StatusConflict, errors.New("sections are unavailable"))
+		return
+	}
+	cfg, err := config.Load(s.configPath)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	if err := sections.ReloadFromConfig(s.sections, cfg.Sections); err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	s.flushCache(r.Context())
+	writeJSON(w, http.StatusOK, map[string]any{"sections": len(cfg.Sections)})
+}
+
+// handleDenylist lists the denied pubkeys
+func (s *Server) handleDenylist(w http.ResponseWriter, r *http.Request) {
+	if s.denied == nil {
+		writeError(w, http.StatusConflict, errors.New("the deny list is unavailable"))
+		return
+	}
+	pubkeys := s.denied.ListDeniedPubkeys()
+	sort.Strings(pubkeys)
+	writeJSON(w, http.StatusOK, map[string]any{"pubkeys": pubkeys})
+}
+
+// handleDeny adds {"pubkey": "<npub or hex>"} to the deny list until restart.
+// Their events disappear from every protocol at once and are no longer synced.
+func (s *Server) handleDeny(w http.ResponseWriter, r *http.Request) {
+	if s.denied == nil {
+		writeError(w, http.StatusConflict, errors.New("the deny list is unavailable"))
+		return
+	}
+	var body struct {
+		Pubkey string `json:"pubkey"`
+	}
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, 4096)).Decode(&body); err != nil {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("invalid request body: %w", err))
+		return
+	}
+	pubkey, err := helpers.NormalizePubkey(body.Pubkey)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	s.denied.AddPubkey(pubkey)
+	s.flushCache(r.Context())
+	writeJSON(w, http.StatusOK, map[string]any{"denied": pubkey})
+}
+
+// handleLog lists admin log entries newest first: ?limit=N (default 100, at
+// most 1000) entries, older than the entry with ID ?before= if given
+func (s *Server) handleLog(w http.ResponseWriter, r *http.Request) {
+	if s.storage == nil {
+		writeError(w, http.StatusConflict, errors.New("the admin log is unavailable"))
+		return
+	}
+	limit := defaultLogLimit
+	if value := r.URL.Query().Get("limit"); value != "" {
+		n, err := strconv.Atoi(value)
+		if err != nil || n < 1 || n > maxLogLimit {
+			writeError(w, http.StatusBadRequest, fmt.Errorf("limit must be between 1 and %d", maxLogLimit))
+			return
+		}
+		limit = n
+	}
+	var before int64
+	if value := r.URL.Query().Get("before"); value != "" {
+		n, err := strconv.ParseInt(value, 10, 64)
+		if err != nil || n < 1 {
+			writeError(w, http.StatusBadRequest, errors.New("before must be an entry ID"))
+			return
+		}
+		before = n
+	}
+
+	entries, err := s.storage.GetAdminLog(r.Context(), limit, before)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	if entries == nil {
+		entries = []storage.AdminLogEntry{}
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"entries": entries})
+}
+
+// flushCache drops cached pages that may show outdated content, if caching is on
+func (s *Server) flushCache(ctx context.Context) {
+	if s.cache != nil {
+		if err := s.cache.Clear(ctx); err != nil {
+			fmt.Printf("[ADMIN] ⚠ Failed to flush cache: %v\n", err)
+		}
+	}
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+func writeError(w http.ResponseWriter, status int, err error) {
+	writeJSON(w, status, map[string]string{"error": err.Error()})
+}
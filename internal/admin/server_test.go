@@ -0,0 +1,116 @@
+package admin
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sandwich/nophr/internal/cache"
+	"github.com/sandwich/nophr/internal/config"
+	"github.com/sandwich/nophr/internal/security"
+	"github.com/sandwich/nophr/internal/storage"
+)
+
+const testPubkey = "3bf0c63fcb93463407af97a5e5ee64fa883d107ef9e558472c4eb9aaaefa459d"
+
+func request(t *testing.T, h http.Handler, method, path, body string) (int, map[string]any) {
+	t.Helper()
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(method, path, strings.NewReader(body)))
+
+	var decoded map[string]any
+	if err := json.Unmarshal(rec.Body.Bytes(), &decoded); err != nil {
+		t.Fatalf("%s %s: invalid JSON %q: %v", method, path, rec.Body.String(), err)
+	}
+	return rec.Code, decoded
+}
+
+func TestDenylist(t *testing.T) {
+	denied := security.NewDenyList(nil)
+	s := New(&config.Admin{}, "")
+	s.SetDenyList(denied)
+	h := s.Handler()
+
+	status, body := request(t, h, "POST", "/denylist", `{"pubkey": "npub180cvv07tjdrrgpa0j7j7tmnyl2yr6yr7l8j4s3evf6u64th6gkwsyjh6w6"}`)
+	if status != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %v", status, body)
+	}
+	if !denied.IsPubkeyDenied(testPubkey) {
+		t.Error("expected the npub to be denied as hex")
+	}
+
+	_, body = request(t, h, "GET", "/denylist", "")
+	pubkeys, _ := body["pubkeys"].([]any)
+	if len(pubkeys) != 1 || pubkeys[0] != testPubkey {
+		t.Errorf("expected [%s], got %v", testPubkey, body["pubkeys"])
+	}
+
+	if status, _ := request(t, h, "POST", "/denylist", `{"pubkey": "nope"}`); status != http.StatusBadRequest {
+		t.Errorf("expected 400 for an invalid pubkey, got %d", status)
+	}
+}
+
+func TestCacheFlush(t *testing.T) {
+	ctx := context.Background()
+	c := cache.NewMemoryCache(cache.DefaultConfig())
+	defer c.Close()
+	c.Set(ctx, "gopher:/", []byte("menu"), time.Minute)
+
+	s := New(&config.Admin{}, "")
+	s.SetCache(c)
+
+	if status, body := request(t, s.Handler(), "POST", "/cache/flush", ""); status != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %v", status, body)
+	}
+	if _, found, _ := c.Get(ctx, "gopher:/"); found {
+		t.Error("expected the cache to be empty after a flush")
+	}
+}
+
+func TestUnavailable(t *testing.T) {
+	h := New(&config.Admin{}, "").Handler()
+
+	for _, path := range []string{"/cache/flush", "/sync", "/sections/reload"} {
+		status, body := request(t, h, "POST", path, "")
+		if status != http.StatusConflict {
+			t.Errorf("POST %s: expected 409, got %d", path, status)
+		}
+		if body["error"] == "" {
+			t.Errorf("POST %s: expected an error message", path)
+		}
+	}
+}
+
+func TestLog(t *testing.T) {
+	ctx := context.Background()
+	st, err := storage.New(ctx, &config.Storage{Driver: "sqlite", SQLitePath: filepath.Join(t.TempDir(), "test.db")})
+	if err != nil {
+		t.Fatalf("storage.New() error = %v", err)
+	}
+	defer st.Close()
+	st.LogAdminEvent(ctx, storage.AdminLogStart, "gopher", "Listening on localhost:70")
+
+	h := New(&config.Admin{}, "").Handler()
+	if status, _ := request(t, h, "GET", "/log", ""); status != http.StatusConflict {
+		t.Errorf("expected 409 without storage, got %d", status)
+	}
+
+	s := New(&config.Admin{}, "")
+	s.SetStorage(st)
+	status, body := request(t, s.Handler(), "GET", "/log?limit=1", "")
+	if status != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %v", status, body)
+	}
+	entries, _ := body["entries"].([]any)
+	if len(entries) != 1 || entries[0].(map[string]any)["subsystem"] != "gopher" {
+		t.Errorf("expected the newest entry, got %v", body["entries"])
+	}
+	if status, _ := request(t, s.Handler(), "GET", "/log?limit=0", ""); status != http.StatusBadRequest {
+		t.Errorf("expected 400 for limit=0, got %d", status)
+	}
+}
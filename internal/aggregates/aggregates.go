@@ -57,7 +57,13 @@ func (m *Manager) processReply(ctx context.Context, event *nostr.Event) error {
 
 	// If it's a reply, update the parent's reply count
 	if threadInfo.IsReply() {
-		return m.storage.IncrementReplyCount(ctx, threadInfo.ReplyToID, int64(event.CreatedAt))
+		if seen, err := m.storage.IsInteractionSeen(ctx, event.ID); err != nil || seen {
+			return err
+		}
+		if err := m.storage.IncrementReplyCount(ctx, threadInfo.ReplyToID, int64(event.CreatedAt)); err != nil {
+			return err
+		}
+		return m.storage.MarkInteractionsSeen(ctx, map[string]string{event.ID: threadInfo.ReplyToID})
 	}
 
 	return nil
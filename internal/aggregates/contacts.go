@@ -0,0 +1,149 @@
+package aggregates
+
+import (
+	"context"
+	"sort"
+	"strings"
+
+	"github.com/nbd-wtf/go-nostr"
+	nostrclient "github.com/sandwich/nophr/internal/nostr"
+)
+
+// profileBatchSize bounds how many authors one kind 0 lookup asks for
+const profileBatchSize = 100
+
+// Contact is one account in the owner's following or followers list
+type Contact struct {
+	Pubkey string
+	Name   string // Display name from the cached kind 0 profile, empty if unknown
+}
+
+// ContactPage is one page of a contact listing
+type ContactPage struct {
+	Contacts   []*Contact
+	PageNumber int
+	TotalItems int
+	HasNext    bool
+}
+
+// GetFollowing returns one page of the accounts the owner follows.
+// The list comes from the owner's applied contact list in the social graph.
+func (qh *QueryHelper) GetFollowing(ctx context.Context, pageNum, perPage int) (*ContactPage, error) {
+	ownerHex, err := qh.getOwnerHex()
+	if err != nil {
+		return nil, err
+	}
+
+	pubkeys, err := qh.storage.GetFollowingPubkeys(ctx, ownerHex)
+	if err != nil {
+		return nil, err
+	}
+
+	return qh.contactPage(ctx, pubkeys, pageNum, perPage)
+}
+
+// GetFollowers returns one page of the accounts whose newest stored kind 3
+// contact list includes the owner, read from the follows index. Only
+// followers whose contact lists were synced are known.
+func (qh *QueryHelper) GetFollowers(ctx context.Context, pageNum, perPage int) (*ContactPage, error) {
+	ownerHex, err := qh.getOwnerHex()
+	if err != nil {
+		return nil, err
+	}
+
+	pubkeys, err := qh.storage.GetFollowers(ctx, ownerHex)
+	if err != nil {
+		return nil, err
+	}
+
+	return qh.contactPage(ctx, pubkeys, pageNum, perPage)
+}
+
+// contactPage names every contact from cached profiles, sorts by name and slices one page
+func (qh *QueryHelper) contactPage(ctx context.Context, pubkeys []string, pageNum, perPage int) (*ContactPage, error) {
+	if pageNum < 1 {
+		pageNum = 1
+	}
+
+	names, err := qh.profileNames(ctx, pubkeys)
+	if err != nil {
+		return nil, err
+	}
+
+	contacts := make([]*Contact, 0, len(pubkeys))
+	for _, pubkey := range pubkeys {
+		contacts = append(contacts, &Contact{Pubkey: pubkey, Name: names[pubkey]})
+	}
+
+	// Named contacts first, alphabetically; unnamed ones by pubkey
+	sort.Slice(contacts, func(i, j int) bool {
+		a, b := contacts[i], contacts[j]
+		if (a.Name == "") != (b.Name == "") {
+			return a.Name != ""
+		}
+		if an, bn := strings.ToLower(a.Name), strings.ToLower(b.Name); an != bn {
+			return an < bn
+		}
+		return a.Pubkey < b.Pubkey
+	})
+
+	page := &ContactPage{PageNumber: pageNum, TotalItems: len(contacts)}
+	start := (pageNum - 1) * perPage
+	if start < len(contacts) {
+		end := min(start+perPage, len(contacts))
+		page.Contacts = contacts[start:end]
+		page.HasNext = end < len(contacts)
+	}
+
+	return page, nil
+}
+
+// profileNames looks up display names from cached kind 0 profiles
+func (qh *QueryHelper) profileNames(ctx context.Context, pubkeys []string) (map[string]string, error) {
+	names := make(map[string]string, len(pubkeys))
+	latest := make(map[string]nostr.Timestamp, len(pubkeys))
+
+	for start := 0; start < len(pubkeys); start += profileBatchSize {
+		end := min(start+profileBatchSize, len(pubkeys))
+		events, err := qh.storage.QueryEvents(ctx, nostr.Filter{
+			Kinds:   []int{0},
+			Authors: pubkeys[start:end],
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		for _, event := range events {
+			if ts, ok := latest[event.PubKey]; ok && ts >= event.CreatedAt {
+				continue
+			}
+			latest[event.PubKey] = event.CreatedAt
+			if profile := nostrclient.ParseProfile(event); profile != nil {
+				names[event.PubKey] = profile.GetDisplayName()
+			}
+		}
+	}
+
+	return names, nil
+}
+
+// GetAuthorNotesPage returns one page of an author's root notes and reposts older than before
+func (qh *QueryHelper) GetAuthorNotesPage(ctx context.Context, pubkey string, before *Cursor, limit int) (*EventPage, error) {
+	filter := nostr.Filter{
+		Kinds:   []int{1},
+		Authors: []string{pubkey},
+	}
+
+	// Only root notes, not replies
+	return qh.queryPage(ctx, qh.withReposts(qh.rootNotes), filter, before, limit, qh.config.Behavior.SortPreferences.Notes)
+}
+
+// GetAuthorArticlesPage returns one page of an author's long-form articles older than before
+func (qh *QueryHelper) GetAuthorArticlesPage(ctx context.Context, pubkey string, before *Cursor, limit int) (*EventPage, error) {
+	filter := nostr.Filter{
+		Kinds:   []int{30023},
+		Authors: []string{pubkey},
+	}
+
+	return qh.queryPage(ctx, qh.storage.QueryEvents, filter, before, limit, qh.config.Behavior.SortPreferences.Articles)
+}
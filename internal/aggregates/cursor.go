@@ -0,0 +1,109 @@
+package aggregates
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/nbd-wtf/go-nostr"
+)
+
+// Cursor marks a position in a newest-first event listing.
+// Listings are ordered by created_at descending with the event ID as tiebreaker,
+// so a cursor stays valid as new events arrive.
+type Cursor struct {
+	CreatedAt nostr.Timestamp
+	ID        string
+}
+
+// CursorFor returns a cursor positioned at the given event
+func CursorFor(event *nostr.Event) *Cursor {
+	return &Cursor{CreatedAt: event.CreatedAt, ID: event.ID}
+}
+
+// String encodes the cursor for use in selectors and URLs as "<created_at>_<id>"
+func (c *Cursor) String() string {
+	return fmt.Sprintf("%d_%s", c.CreatedAt, c.ID)
+}
+
+// ParseCursor decodes a cursor produced by Cursor.String
+func ParseCursor(s string) (*Cursor, error) {
+	ts, id, ok := strings.Cut(s, "_")
+	if !ok || id == "" {
+		return nil, fmt.Errorf("invalid cursor: %s", s)
+	}
+
+	createdAt, err := strconv.ParseInt(ts, 10, 64)
+	if err != nil || createdAt < 0 {
+		return nil, fmt.Errorf("invalid cursor timestamp: %s", ts)
+	}
+
+	if len(id) != 64 {
+		return nil, fmt.Errorf("invalid cursor event ID: %s", id)
+	}
+
+	return &Cursor{CreatedAt: nostr.Timestamp(createdAt), ID: id}, nil
+}
+
+// CursorFromParts extracts a cursor from path parts like ["until", "<created_at>_<id>"].
+// Returns the cursor (nil for the newest page) and the remaining parts.
+func CursorFromParts(parts []string) (*Cursor, []string, error) {
+	for i := 0; i < len(parts)-1; i++ {
+		if parts[i] == "until" {
+			cursor, err := ParseCursor(parts[i+1])
+			if err != nil {
+				return nil, nil, err
+			}
+			remaining := append(append([]string{}, parts[:i]...), parts[i+2:]...)
+			return cursor, remaining, nil
+		}
+	}
+
+	return nil, parts, nil
+}
+
+// Precedes returns true if event comes after the cursor position in the listing
+func (c *Cursor) Precedes(event *nostr.Event) bool {
+	if event.CreatedAt != c.CreatedAt {
+		return event.CreatedAt < c.CreatedAt
+	}
+	return event.ID > c.ID
+}
+
+// EventPage is one page of a keyset-paginated listing
+type EventPage struct {
+	Events []*EnrichedEvent
+	Before *Cursor // Cursor this page was requested with (nil for the newest page)
+	Next   *Cursor // Cursor for the next (older) page, nil if this is the last page
+}
+
+// sortNewestFirst orders events by created_at descending, then ID ascending
+func sortNewestFirst(events []*nostr.Event) {
+	sort.SliceStable(events, func(i, j int) bool {
+		if events[i].CreatedAt != events[j].CreatedAt {
+			return events[i].CreatedAt > events[j].CreatedAt
+		}
+		return events[i].ID < events[j].ID
+	})
+}
+
+// PageEvents sorts events newest first and returns up to limit of them that come
+// after before (nil = from the newest), with the cursor for the next page when
+// more events remain
+func PageEvents(events []*nostr.Event, before *Cursor, limit int) ([]*nostr.Event, *Cursor) {
+	sortNewestFirst(events)
+
+	page := make([]*nostr.Event, 0, min(limit, len(events)))
+	for _, event := range events {
+		if before != nil && !before.Precedes(event) {
+			continue
+		}
+		if len(page) == limit {
+			return page, CursorFor(page[limit-1])
+		}
+		page = append(page, event)
+	}
+
+	return page, nil
+}
@@ -0,0 +1,120 @@
+package aggregates
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/nbd-wtf/go-nostr"
+)
+
+func TestCursorRoundTrip(t *testing.T) {
+	id := strings.Repeat("ab", 32)
+	cursor := CursorFor(&nostr.Event{ID: id, CreatedAt: 1700000000})
+
+	parsed, err := ParseCursor(cursor.String())
+	if err != nil {
+		t.Fatalf("ParseCursor failed: %v", err)
+	}
+	if *parsed != *cursor {
+		t.Errorf("Expected %+v, got %+v", cursor, parsed)
+	}
+
+	invalid := []string{"", "1700000000", "abc_" + id, "1700000000_short", "-5_" + id}
+	for _, s := range invalid {
+		if _, err := ParseCursor(s); err == nil {
+			t.Errorf("Expected error for cursor %q", s)
+		}
+	}
+}
+
+func TestCursorOrdering(t *testing.T) {
+	events := []*nostr.Event{
+		{ID: "b", CreatedAt: 100},
+		{ID: "c", CreatedAt: 200},
+		{ID: "a", CreatedAt: 100},
+		{ID: "d", CreatedAt: 50},
+	}
+	sortNewestFirst(events)
+
+	got := ""
+	for _, e := range events {
+		got += e.ID
+	}
+	if got != "cabd" {
+		t.Fatalf("Expected order cabd, got %s", got)
+	}
+
+	// A cursor at "a" must exclude everything up to and including "a"
+	cursor := CursorFor(events[1])
+	var after []string
+	for _, e := range events {
+		if cursor.Precedes(e) {
+			after = append(after, e.ID)
+		}
+	}
+	if strings.Join(after, "") != "bd" {
+		t.Errorf("Expected events after cursor to be bd, got %v", after)
+	}
+}
+
+func TestCursorFromParts(t *testing.T) {
+	id := strings.Repeat("cd", 32)
+
+	cursor, remaining, err := CursorFromParts([]string{"until", "1700000000_" + id})
+	if err != nil {
+		t.Fatalf("CursorFromParts failed: %v", err)
+	}
+	if cursor == nil || cursor.ID != id || cursor.CreatedAt != 1700000000 {
+		t.Errorf("Unexpected cursor %+v", cursor)
+	}
+	if len(remaining) != 0 {
+		t.Errorf("Expected no remaining parts, got %v", remaining)
+	}
+
+	cursor, remaining, err = CursorFromParts([]string{"abc"})
+	if err != nil || cursor != nil || len(remaining) != 1 || remaining[0] != "abc" {
+		t.Errorf("Expected no cursor and parts unchanged, got %+v %v %v", cursor, remaining, err)
+	}
+
+	if _, _, err := CursorFromParts([]string{"until", "bogus"}); err == nil {
+		t.Error("Expected error for invalid cursor")
+	}
+}
+
+func TestPageEvents(t *testing.T) {
+	events := []*nostr.Event{
+		{ID: "a", CreatedAt: 100},
+		{ID: "b", CreatedAt: 300},
+		{ID: "c", CreatedAt: 200},
+		{ID: "d", CreatedAt: 50},
+	}
+
+	page, next := PageEvents(events, nil, 2)
+	if len(page) != 2 || page[0].ID != "b" || page[1].ID != "c" {
+		t.Fatalf("Expected first page [b c], got %d events", len(page))
+	}
+	if next == nil || next.ID != "c" {
+		t.Fatalf("Expected next cursor at c, got %+v", next)
+	}
+
+	page, next = PageEvents(events, next, 2)
+	if len(page) != 2 || page[0].ID != "a" || page[1].ID != "d" {
+		t.Fatalf("Expected second page [a d], got %d events", len(page))
+	}
+	if next != nil {
+		t.Errorf("Expected no next cursor on the last page, got %+v", next)
+	}
+}
+
+func TestSnapshotFrom(t *testing.T) {
+	ctx := WithSnapshot(context.Background(), 1700000000)
+	if got := SnapshotFrom(ctx); got != 1700000000 {
+		t.Errorf("Expected pinned snapshot 1700000000, got %d", got)
+	}
+
+	before := nostr.Now()
+	if got := SnapshotFrom(context.Background()); got < before {
+		t.Errorf("Expected current time without a snapshot, got %d", got)
+	}
+}
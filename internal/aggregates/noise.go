@@ -0,0 +1,148 @@
+package aggregates
+
+import (
+	"strings"
+	"unicode/utf8"
+
+	"github.com/nbd-wtf/go-nostr"
+	"github.com/sandwich/nophr/internal/config"
+)
+
+// normalizeReaction canonicalizes a reaction so equivalent forms compare equal:
+// empty content is a like ("+") and emoji variation selectors are dropped, so
+// "❤" and "❤️" are the same reaction
+func normalizeReaction(reaction string) string {
+	reaction = strings.Map(func(r rune) rune {
+		if r == 0xFE0E || r == 0xFE0F {
+			return -1
+		}
+		return r
+	}, strings.TrimSpace(reaction))
+	if reaction == "" {
+		return "+"
+	}
+	return reaction
+}
+
+// reactionSet expands one allowed_reaction_chars entry into the reactions it allows.
+// Entries may list several reactions separated by spaces ("+ -"), and an entry made
+// only of emoji is a set of each emoji in it ("❤️🔥👍"). Anything else, such as
+// ":shortcode:" custom emoji, is a single reaction.
+func reactionSet(entry string) []string {
+	var set []string
+	for _, field := range strings.Fields(entry) {
+		field = normalizeReaction(field)
+		if isEmojiOnly(field) {
+			set = append(set, splitEmoji(field)...)
+		} else {
+			set = append(set, field)
+		}
+	}
+	return set
+}
+
+// isEmojiOnly reports whether s contains no ASCII, so splitting it can't break up text
+func isEmojiOnly(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] < utf8.RuneSelf {
+			return false
+		}
+	}
+	return true
+}
+
+// splitEmoji splits a run of emoji into individual emoji, keeping ZWJ sequences,
+// skin tone modifiers, keycaps, tag sequences and flag pairs together
+func splitEmoji(s string) []string {
+	var emoji []string
+	var current []rune
+	joinNext := false
+	for _, r := range s {
+		extends := joinNext ||
+			r == 0x200D || // zero width joiner
+			(r >= 0x1F3FB && r <= 0x1F3FF) || // skin tone modifiers
+			r == 0x20E3 || // combining keycap
+			(r >= 0xE0020 && r <= 0xE007F) || // tag sequence (subdivision flags)
+			(isRegionalIndicator(r) && len(current) == 1 && isRegionalIndicator(current[0]))
+		if !extends && len(current) > 0 {
+			emoji = append(emoji, string(current))
+			current = nil
+		}
+		current = append(current, r)
+		joinNext = r == 0x200D
+	}
+	if len(current) > 0 {
+		emoji = append(emoji, string(current))
+	}
+	return emoji
+}
+
+func isRegionalIndicator(r rune) bool {
+	return r >= 0x1F1E6 && r <= 0x1F1FF
+}
+
+// reactionAllowed reports whether a reaction passes the allowed_reaction_chars
+// filter. An empty list allows every reaction.
+func reactionAllowed(filters config.NoiseFilters, reaction string) bool {
+	if len(filters.AllowedReactionChars) == 0 {
+		return true
+	}
+
+	reaction = normalizeReaction(reaction)
+	for _, entry := range filters.AllowedReactionChars {
+		for _, allowed := range reactionSet(entry) {
+			if reaction == allowed {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// isLoneEmoji reports whether a note's content is nothing but a single emoji,
+// the reaction-as-reply some clients send instead of a kind 7
+func isLoneEmoji(content string) bool {
+	content = normalizeReaction(content)
+	return isEmojiOnly(content) && len(splitEmoji(content)) == 1
+}
+
+// isNoise reports whether an interaction with the owner should be hidden from the
+// inbox by inbox.noise_filters: reactions outside allowed_reaction_chars, zaps below
+// min_zap_sats, and replies or mentions that are just a disallowed emoji. Reactions
+// by authors with hide_reactions in rendering.authors are noise too. The owner's
+// own events are never noise.
+func (qh *QueryHelper) isNoise(event *nostr.Event, ownerHex string) bool {
+	if event.PubKey == ownerHex {
+		return false
+	}
+
+	filters := qh.config.Inbox.NoiseFilters
+	switch event.Kind {
+	case 7:
+		return qh.authors.HidesReactions(event.PubKey) || !reactionAllowed(filters, event.Content)
+	case 9735:
+		return ZapAmount(event) < int64(filters.MinZapSats)
+	case 1:
+		if !isLoneEmoji(event.Content) {
+			return false
+		}
+		// Only notes addressed to the owner are inbox interactions
+		for _, tag := range event.Tags {
+			if len(tag) >= 2 && tag[0] == "p" && tag[1] == ownerHex {
+				return !reactionAllowed(filters, event.Content)
+			}
+		}
+	}
+	return false
+}
+
+// withoutNoise drops noise (see isNoise) from a list of interactions with the owner
+func (qh *QueryHelper) withoutNoise(events []*nostr.Event, ownerHex string) []*nostr.Event {
+	filtered := events[:0]
+	for _, event := range events {
+		if !qh.isNoise(event, ownerHex) {
+			filtered = append(filtered, event)
+		}
+	}
+	return filtered
+}
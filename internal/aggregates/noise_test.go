@@ -0,0 +1,76 @@
+package aggregates
+
+import (
+	"testing"
+
+	"github.com/nbd-wtf/go-nostr"
+	"github.com/sandwich/nophr/internal/config"
+)
+
+func TestReactionAllowed(t *testing.T) {
+	tests := []struct {
+		name     string
+		allowed  []string
+		reaction string
+		want     bool
+	}{
+		{"no filter allows all", nil, "🚀", true},
+		{"empty content is a like", []string{"+"}, "", true},
+		{"exact match", []string{"+", "🔥"}, "🔥", true},
+		{"not in list", []string{"+"}, "🚀", false},
+		{"variation selector ignored", []string{"❤️"}, "❤", true},
+		{"emoji set", []string{"❤️🔥👍"}, "👍", true},
+		{"emoji set excludes others", []string{"❤️🔥👍"}, "🚀", false},
+		{"space separated", []string{"+ -"}, "-", true},
+		{"skin tone stays one emoji", []string{"👍🏽🔥"}, "👍🏽", true},
+		{"skin tone base not split out", []string{"👍🏽"}, "👍", false},
+		{"zwj sequence", []string{"👩‍💻🔥"}, "👩‍💻", true},
+		{"flag pair", []string{"🇺🇸🇯🇵"}, "🇯🇵", true},
+		{"custom emoji shortcode", []string{":soapbox:"}, ":soapbox:", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			filters := config.NoiseFilters{AllowedReactionChars: tt.allowed}
+			if got := reactionAllowed(filters, tt.reaction); got != tt.want {
+				t.Errorf("reactionAllowed(%v, %q) = %v, want %v", tt.allowed, tt.reaction, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestIsNoise(t *testing.T) {
+	owner := "owner"
+	cfg := config.Default()
+	cfg.Inbox.NoiseFilters = config.NoiseFilters{
+		MinZapSats:           10,
+		AllowedReactionChars: []string{"+", "❤️🔥"},
+	}
+	qh := &QueryHelper{config: cfg, authors: config.AuthorOverrides{"muted": {HideReactions: true}}}
+	toOwner := nostr.Tags{{"p", owner}}
+
+	tests := []struct {
+		name  string
+		event *nostr.Event
+		want  bool
+	}{
+		{"allowed reaction", &nostr.Event{Kind: 7, PubKey: "a", Content: "🔥"}, false},
+		{"spammy reaction", &nostr.Event{Kind: 7, PubKey: "a", Content: "🚀"}, true},
+		{"dust zap", &nostr.Event{Kind: 9735, PubKey: "a"}, true},
+		{"emoji-only reply", &nostr.Event{Kind: 1, PubKey: "a", Content: "🚀", Tags: toOwner}, true},
+		{"allowed emoji reply", &nostr.Event{Kind: 1, PubKey: "a", Content: "❤️", Tags: toOwner}, false},
+		{"text reply", &nostr.Event{Kind: 1, PubKey: "a", Content: "great post 🚀", Tags: toOwner}, false},
+		{"emoji note not addressed to owner", &nostr.Event{Kind: 1, PubKey: "a", Content: "🚀"}, false},
+		{"owner's own reaction", &nostr.Event{Kind: 7, PubKey: owner, Content: "🚀"}, false},
+		{"reaction by an author with hidden reactions", &nostr.Event{Kind: 7, PubKey: "muted", Content: "🔥"}, true},
+		{"note by an author with hidden reactions", &nostr.Event{Kind: 1, PubKey: "muted", Content: "hi", Tags: toOwner}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := qh.isNoise(tt.event, owner); got != tt.want {
+				t.Errorf("isNoise() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,348 @@
+package aggregates
+
+import (
+	"context"
+	"slices"
+	"time"
+
+	"github.com/nbd-wtf/go-nostr"
+	"github.com/sandwich/nophr/internal/storage"
+)
+
+// maxPageBatches bounds how many storage round trips one page may take
+// when most events are dropped by thread or content filters
+const maxPageBatches = 10
+
+// replyTargets is how many of the owner's newest notes the replies page
+// looks up replies to
+const replyTargets = 100
+
+// GetNotesPage returns one page of the owner's root notes and reposts older than before (nil = newest)
+func (qh *QueryHelper) GetNotesPage(ctx context.Context, before *Cursor, limit int) (*EventPage, error) {
+	ownerHex, err := qh.getOwnerHex()
+	if err != nil {
+		return nil, err
+	}
+
+	filter := nostr.Filter{
+		Kinds:   []int{1},
+		Authors: []string{ownerHex},
+	}
+
+	// Only root notes, not replies
+	return qh.queryPage(ctx, qh.withReposts(qh.rootNotes), filter, before, limit, qh.config.Behavior.SortPreferences.Notes)
+}
+
+// GetArticlesPage returns one page of the owner's long-form articles older than before
+func (qh *QueryHelper) GetArticlesPage(ctx context.Context, before *Cursor, limit int) (*EventPage, error) {
+	ownerHex, err := qh.getOwnerHex()
+	if err != nil {
+		return nil, err
+	}
+
+	filter := nostr.Filter{
+		Kinds:   []int{30023},
+		Authors: []string{ownerHex},
+	}
+
+	return qh.queryPage(ctx, qh.storage.QueryEvents, filter, before, limit, qh.config.Behavior.SortPreferences.Articles)
+}
+
+// GetRepliesPage returns one page of replies to the owner older than before:
+// replies that tag the owner, and replies to the owner's newest notes, since
+// not every client tags the author it replies to
+func (qh *QueryHelper) GetRepliesPage(ctx context.Context, before *Cursor, limit int) (*EventPage, error) {
+	ownerHex, err := qh.getOwnerHex()
+	if err != nil {
+		return nil, err
+	}
+
+	notes, err := qh.storage.QueryEvents(ctx, nostr.Filter{Kinds: []int{1}, Authors: []string{ownerHex}, Limit: replyTargets})
+	if err != nil {
+		return nil, err
+	}
+	tags := []nostr.TagMap{{"p": []string{ownerHex}}}
+	if len(notes) > 0 {
+		ids := make([]string, len(notes))
+		for i, note := range notes {
+			ids[i] = note.ID
+		}
+		tags = append(tags, nostr.TagMap{"e": ids})
+	}
+
+	// Only actual replies, filtered in storage
+	replies := storage.NewQueryExecutor(storage.QuerierFunc(qh.replyNotes), storage.DefaultQueryWorkers)
+	fetch := mergedFetch(replies, func(filter nostr.Filter) []nostr.Filter {
+		filters := make([]nostr.Filter, len(tags))
+		for i, tag := range tags {
+			filters[i] = filter
+			filters[i].Tags = tag
+		}
+		return filters
+	})
+	return qh.queryPage(ctx, fetch, nostr.Filter{Kinds: []int{1}}, before, limit, qh.config.Behavior.SortPreferences.Replies)
+}
+
+// GetMentionsPage returns one page of posts mentioning the owner, and reposts of the
+// owner's posts, older than before. Pinned authors' posts lead the page.
+func (qh *QueryHelper) GetMentionsPage(ctx context.Context, before *Cursor, limit int) (*EventPage, error) {
+	ownerHex, err := qh.getOwnerHex()
+	if err != nil {
+		return nil, err
+	}
+
+	filter := nostr.Filter{
+		Kinds: []int{1, KindRepost, KindGenericRepost},
+		Tags: nostr.TagMap{
+			"p": []string{ownerHex},
+		},
+	}
+
+	page, err := qh.queryPage(ctx, mergedFetch(qh.executor, eachKind), filter, before, limit, qh.config.Behavior.SortPreferences.Mentions)
+	if err != nil {
+		return nil, err
+	}
+	qh.pinAuthors(page.Events)
+	return page, nil
+}
+
+// pinAuthors moves events by authors pinned in rendering.authors to the front,
+// keeping the page's order otherwise. Cursors are unaffected since every event
+// stays on its page.
+func (qh *QueryHelper) pinAuthors(events []*EnrichedEvent) {
+	slices.SortStableFunc(events, func(a, b *EnrichedEvent) int {
+		pa, pb := qh.authors.Pinned(a.Event.PubKey), qh.authors.Pinned(b.Event.PubKey)
+		switch {
+		case pa && !pb:
+			return -1
+		case pb && !pa:
+			return 1
+		}
+		return 0
+	})
+}
+
+// GetArchivePage returns one page of the owner's notes and articles created in
+// [start, end), older than before
+func (qh *QueryHelper) GetArchivePage(ctx context.Context, start, end time.Time, before *Cursor, limit int) (*EventPage, error) {
+	ownerHex, err := qh.getOwnerHex()
+	if err != nil {
+		return nil, err
+	}
+
+	since := nostr.Timestamp(start.Unix())
+	until := nostr.Timestamp(end.Unix() - 1) // Until is inclusive
+	filter := nostr.Filter{
+		Kinds:   []int{1, 30023},
+		Authors: []string{ownerHex},
+		Since:   &since,
+		Until:   &until,
+	}
+
+	return qh.queryPage(ctx, qh.storage.QueryEvents, filter, before, limit, "chronological")
+}
+
+// GetAnnotatedPage returns one page of notes and articles by any author that
+// carry the given annotations (see storage.QueryAnnotated), older than before
+func (qh *QueryHelper) GetAnnotatedPage(ctx context.Context, annotations map[string][]string, before *Cursor, limit int) (*EventPage, error) {
+	filter := nostr.Filter{
+		Kinds: []int{1, 30023},
+	}
+	fetch := func(ctx context.Context, filter nostr.Filter) ([]*nostr.Event, error) {
+		return qh.storage.QueryAnnotated(ctx, filter, annotations)
+	}
+
+	return qh.queryPage(ctx, fetch, filter, before, limit, "chronological")
+}
+
+// pageFetcher loads one batch of a listing from storage
+type pageFetcher func(ctx context.Context, filter nostr.Filter) ([]*nostr.Event, error)
+
+// mergedFetch fetches each batch with the filters split returns for it, run
+// concurrently through executor and merged newest first
+func mergedFetch(executor *storage.QueryExecutor, split func(nostr.Filter) []nostr.Filter) pageFetcher {
+	return func(ctx context.Context, filter nostr.Filter) ([]*nostr.Event, error) {
+		return executor.Merge(ctx, split(filter), filter.Limit)
+	}
+}
+
+// eachKind splits a filter into one per kind. Each kind is its own range of
+// the kind and created_at index, so every query stops after the batch limit
+// instead of sorting all the kinds' matches together.
+func eachKind(filter nostr.Filter) []nostr.Filter {
+	filters := make([]nostr.Filter, len(filter.Kinds))
+	for i, kind := range filter.Kinds {
+		filters[i] = filter
+		filters[i].Kinds = []int{kind}
+	}
+	return filters
+}
+
+// rootNotes fetches kind 1 notes that are not replies, filtered in storage
+func (qh *QueryHelper) rootNotes(ctx context.Context, filter nostr.Filter) ([]*nostr.Event, error) {
+	return qh.storage.QueryNotes(ctx, filter, false)
+}
+
+// replyNotes fetches kind 1 notes that reply to another event, filtered in storage
+func (qh *QueryHelper) replyNotes(ctx context.Context, filter nostr.Filter) ([]*nostr.Event, error) {
+	return qh.storage.QueryNotes(ctx, filter, true)
+}
+
+// queryPage walks the listing newest-first from before, using until cursors instead of
+// OFFSET so deep pages cost the same as the first. fetch selects which events belong
+// in the listing.
+//
+// Cursors follow created_at, so only chronological listings page. Ranked sort modes
+// (engagement, zaps, reactions) return a single page: the newest events, ranked.
+//
+// Every batch is bounded by the request's snapshot time (see WithSnapshot), so events
+// ingested mid-render can't shift later batches and duplicate or skip items, and each
+// batch's aggregates are read in one statement rather than one query per event.
+//
+// With inbox.collapse_reposts, later reposts of an event already on the page are
+// folded into its first repost and don't count towards the limit. Interactions with
+// the owner that inbox.noise_filters treats as noise are dropped (see isNoise).
+func (qh *QueryHelper) queryPage(ctx context.Context, fetch pageFetcher, filter nostr.Filter, before *Cursor, limit int, sortMode string) (*EventPage, error) {
+	ranked := !IsChronological(sortMode)
+	if ranked {
+		before = nil
+	}
+
+	// One extra event tells whether an older page exists. Thread filtering happens
+	// in storage, so only content and noise filtering can drop events and
+	// force another batch.
+	batchSize := max(limit+1, 20)
+	snapshot := SnapshotFrom(ctx)
+	cursor := before
+	exhausted := false
+	collapse := qh.config.Inbox.CollapseReposts
+	ownerHex, _ := qh.getOwnerHex() // Without an owner nothing is addressed to them
+	seenReposts := make(map[string]*EnrichedEvent)
+	var collected []*EnrichedEvent
+	var lastTaken *nostr.Event // Last event taken into the page, where the next page starts
+
+	for batch := 0; batch < maxPageBatches && len(collected) <= limit; batch++ {
+		f := filter
+		f.Limit = batchSize
+		until := snapshot
+		if filter.Until != nil && *filter.Until < until {
+			until = *filter.Until
+		}
+		if cursor != nil && cursor.CreatedAt < until {
+			until = cursor.CreatedAt
+		}
+		f.Until = &until
+
+		events, err := fetch(ctx, f)
+		if err != nil {
+			return nil, err
+		}
+		sortNewestFirst(events)
+
+		candidates := make([]*nostr.Event, 0, len(events))
+		for _, event := range events {
+			// Until is inclusive; skip events at or before the cursor position
+			if cursor != nil && !cursor.Precedes(event) {
+				continue
+			}
+			candidates = append(candidates, event)
+		}
+
+		enrichedBatch, err := qh.enrichSnapshot(ctx, candidates)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, enriched := range enrichedBatch {
+			if qh.config.Behavior.ContentFiltering.Enabled && !qh.passesContentFilter(enriched) {
+				continue
+			}
+			if qh.isNoise(enriched.Event, ownerHex) {
+				continue
+			}
+			if collapse && collapseRepost(seenReposts, enriched) {
+				lastTaken = enriched.Event
+				continue
+			}
+
+			// Collect one extra event to know whether an older page exists
+			collected = append(collected, enriched)
+			if len(collected) > limit {
+				break
+			}
+			lastTaken = enriched.Event
+		}
+
+		if len(events) < batchSize {
+			exhausted = true
+			break
+		}
+
+		last := CursorFor(events[len(events)-1])
+		if cursor != nil && *last == *cursor {
+			// More events share one timestamp than fit in a batch; stop rather than loop
+			exhausted = true
+			break
+		}
+		cursor = last
+	}
+
+	page := &EventPage{Before: before}
+	switch {
+	case len(collected) > limit:
+		collected = collected[:limit]
+		page.Next = CursorFor(lastTaken)
+	case !exhausted && cursor != nil:
+		// Ran out of batches; continue scanning from where we stopped
+		page.Next = cursor
+	}
+	if ranked {
+		page.Next = nil
+	}
+
+	if err := qh.resolveReposts(ctx, collected); err != nil {
+		return nil, err
+	}
+
+	page.Events = qh.sortEnriched(collected, sortMode)
+	return page, nil
+}
+
+// enrichSnapshot attaches aggregates to events using a single read, so every
+// event in the batch reflects the same aggregate state. Reposts carry the
+// aggregates of the event they repost.
+func (qh *QueryHelper) enrichSnapshot(ctx context.Context, events []*nostr.Event) ([]*EnrichedEvent, error) {
+	ids := make([]string, len(events))
+	for i, event := range events {
+		ids[i] = event.ID
+		if IsRepost(event) {
+			if target := RepostTarget(event); target != "" {
+				ids[i] = target
+			}
+		}
+	}
+
+	aggs, err := qh.manager.GetMultipleAggregates(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	enriched := make([]*EnrichedEvent, len(events))
+	for i, event := range events {
+		agg := aggs[ids[i]]
+		if agg == nil {
+			agg = &EventAggregates{EventID: ids[i]}
+		}
+		enriched[i] = &EnrichedEvent{Event: event, Aggregates: agg}
+		if IsRepost(event) {
+			enriched[i].Reposters = []string{event.PubKey}
+		}
+	}
+	return enriched, nil
+}
+
+// IsChronological reports whether a sort mode lists events newest first,
+// the only order that until cursors can page through
+func IsChronological(sortMode string) bool {
+	return sortMode == "" || sortMode == "chronological"
+}
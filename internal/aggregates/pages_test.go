@@ -0,0 +1,172 @@
+package aggregates
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/nbd-wtf/go-nostr"
+	"github.com/nbd-wtf/go-nostr/nip19"
+	"github.com/sandwich/nophr/internal/config"
+	"github.com/sandwich/nophr/internal/storage"
+)
+
+func TestGetArchivePage(t *testing.T) {
+	ctx := context.Background()
+	st, err := storage.New(ctx, &config.Storage{Driver: "sqlite", SQLitePath: filepath.Join(t.TempDir(), "test.db")})
+	if err != nil {
+		t.Fatalf("Failed to create storage: %v", err)
+	}
+	defer st.Close()
+
+	sk := nostr.GeneratePrivateKey()
+	owner, _ := nostr.GetPublicKey(sk)
+	npub, _ := nip19.EncodePublicKey(owner)
+
+	june := time.Date(2024, time.June, 1, 0, 0, 0, 0, time.UTC)
+	july := june.AddDate(0, 1, 0)
+	posts := []struct {
+		at   time.Time
+		kind int
+	}{
+		{june, 1},                         // First second of June
+		{june.Add(48 * time.Hour), 30023}, // An article
+		{july.Add(-time.Second), 1},       // Last second of June
+		{july, 1},                         // July, not listed
+	}
+	for _, post := range posts {
+		event := &nostr.Event{CreatedAt: nostr.Timestamp(post.at.Unix()), Kind: post.kind, Content: post.at.String()}
+		if post.kind == 30023 {
+			event.Tags = nostr.Tags{{"d", "article"}}
+		}
+		event.Sign(sk)
+		if err := st.StoreEvent(ctx, event); err != nil {
+			t.Fatalf("Failed to store event: %v", err)
+		}
+	}
+
+	cfg := config.Default()
+	cfg.Identity.Npub = npub
+	qh := NewQueryHelper(st, cfg, NewManager(st, cfg))
+
+	page, err := qh.GetArchivePage(ctx, june, july, nil, 2)
+	if err != nil {
+		t.Fatalf("GetArchivePage failed: %v", err)
+	}
+	if len(page.Events) != 2 || page.Next == nil {
+		t.Fatalf("Expected 2 events and an older page, got %d", len(page.Events))
+	}
+	if page.Events[0].Event.CreatedAt != nostr.Timestamp(july.Unix()-1) || page.Events[1].Event.Kind != 30023 {
+		t.Errorf("Expected the last note of June then the article, got kinds %d, %d", page.Events[0].Event.Kind, page.Events[1].Event.Kind)
+	}
+
+	older, err := qh.GetArchivePage(ctx, june, july, page.Next, 2)
+	if err != nil {
+		t.Fatalf("GetArchivePage failed: %v", err)
+	}
+	if len(older.Events) != 1 || older.Next != nil || older.Events[0].Event.CreatedAt != nostr.Timestamp(june.Unix()) {
+		t.Errorf("Expected only the first note of June on the last page, got %d events", len(older.Events))
+	}
+}
+
+func TestGetMentionsPagePinsAuthors(t *testing.T) {
+	ctx := context.Background()
+	st, err := storage.New(ctx, &config.Storage{Driver: "sqlite", SQLitePath: filepath.Join(t.TempDir(), "test.db")})
+	if err != nil {
+		t.Fatalf("Failed to create storage: %v", err)
+	}
+	defer st.Close()
+
+	owner, _ := nostr.GetPublicKey(nostr.GeneratePrivateKey())
+	npub, _ := nip19.EncodePublicKey(owner)
+	aliceKey, bobKey := nostr.GeneratePrivateKey(), nostr.GeneratePrivateKey()
+	bob, _ := nostr.GetPublicKey(bobKey)
+	bobNpub, _ := nip19.EncodePublicKey(bob)
+
+	// Bob's mention is the oldest, so it would come last without the pin
+	mentions := []struct {
+		sk string
+		at nostr.Timestamp
+	}{{bobKey, 1000}, {aliceKey, 2000}, {aliceKey, 3000}}
+	for _, m := range mentions {
+		event := &nostr.Event{Kind: 1, CreatedAt: m.at, Content: "hey", Tags: nostr.Tags{{"p", owner}}}
+		event.Sign(m.sk)
+		if err := st.StoreEvent(ctx, event); err != nil {
+			t.Fatalf("Failed to store event: %v", err)
+		}
+	}
+
+	cfg := config.Default()
+	cfg.Identity.Npub = npub
+	cfg.Rendering.Authors = []config.AuthorOverride{{Pubkey: bobNpub, Pin: true}}
+	qh := NewQueryHelper(st, cfg, NewManager(st, cfg))
+
+	page, err := qh.GetMentionsPage(ctx, nil, 10)
+	if err != nil {
+		t.Fatalf("GetMentionsPage failed: %v", err)
+	}
+	if len(page.Events) != 3 {
+		t.Fatalf("Expected 3 mentions, got %d", len(page.Events))
+	}
+	if page.Events[0].Event.PubKey != bob || page.Events[1].Event.CreatedAt != 3000 || page.Events[2].Event.CreatedAt != 2000 {
+		t.Errorf("Expected the pinned author first, then the rest newest first")
+	}
+}
+
+func TestGetRepliesPageMergesQueries(t *testing.T) {
+	ctx := context.Background()
+	st, err := storage.New(ctx, &config.Storage{Driver: "sqlite", SQLitePath: filepath.Join(t.TempDir(), "test.db")})
+	if err != nil {
+		t.Fatalf("Failed to create storage: %v", err)
+	}
+	defer st.Close()
+
+	ownerKey, aliceKey := nostr.GeneratePrivateKey(), nostr.GeneratePrivateKey()
+	owner, _ := nostr.GetPublicKey(ownerKey)
+	npub, _ := nip19.EncodePublicKey(owner)
+	store := func(sk string, event *nostr.Event) *nostr.Event {
+		event.Sign(sk)
+		if err := st.StoreEvent(ctx, event); err != nil {
+			t.Fatalf("Failed to store event: %v", err)
+		}
+		return event
+	}
+
+	note := store(ownerKey, &nostr.Event{Kind: 1, CreatedAt: 1000, Content: "first post", Tags: nostr.Tags{}})
+	other := store(aliceKey, &nostr.Event{Kind: 1, CreatedAt: 1000, Content: "unrelated", Tags: nostr.Tags{}})
+	tagged := store(aliceKey, &nostr.Event{Kind: 1, CreatedAt: 2000, Content: "tagged", Tags: nostr.Tags{
+		{"e", other.ID, "", "root"}, {"p", owner},
+	}})
+	untagged := store(aliceKey, &nostr.Event{Kind: 1, CreatedAt: 3000, Content: "untagged", Tags: nostr.Tags{
+		{"e", note.ID, "", "root"},
+	}})
+	both := store(aliceKey, &nostr.Event{Kind: 1, CreatedAt: 4000, Content: "both", Tags: nostr.Tags{
+		{"e", note.ID, "", "root"}, {"p", owner},
+	}})
+	store(aliceKey, &nostr.Event{Kind: 1, CreatedAt: 5000, Content: "mention, not a reply", Tags: nostr.Tags{{"p", owner}}})
+
+	cfg := config.Default()
+	cfg.Identity.Npub = npub
+	qh := NewQueryHelper(st, cfg, NewManager(st, cfg))
+
+	// Replies found by either query are listed once, newest first, across pages
+	var ids []string
+	var before *Cursor
+	for range 3 {
+		page, err := qh.GetRepliesPage(ctx, before, 2)
+		if err != nil {
+			t.Fatalf("GetRepliesPage failed: %v", err)
+		}
+		for _, event := range page.Events {
+			ids = append(ids, event.Event.ID)
+		}
+		if before = page.Next; before == nil {
+			break
+		}
+	}
+	want := []string{both.ID, untagged.ID, tagged.ID}
+	if len(ids) != len(want) || ids[0] != want[0] || ids[1] != want[1] || ids[2] != want[2] {
+		t.Errorf("GetRepliesPage() listed %v, want %v", ids, want)
+	}
+}
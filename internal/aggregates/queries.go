@@ -8,22 +8,29 @@ import (
 	"github.com/nbd-wtf/go-nostr"
 	"github.com/nbd-wtf/go-nostr/nip19"
 	"github.com/sandwich/nophr/internal/config"
+	"github.com/sandwich/nophr/internal/security"
 	"github.com/sandwich/nophr/internal/storage"
 )
 
 // QueryHelper provides helper methods for inbox/outbox queries
 type QueryHelper struct {
-	storage *storage.Storage
-	config  *config.Config
-	manager *Manager
+	storage   *storage.Storage
+	executor  *storage.QueryExecutor
+	config    *config.Config
+	manager   *Manager
+	validator *security.Validator
+	authors   config.AuthorOverrides // rendering.authors
 }
 
 // NewQueryHelper creates a new query helper
 func NewQueryHelper(st *storage.Storage, cfg *config.Config, mgr *Manager) *QueryHelper {
 	return &QueryHelper{
-		storage: st,
-		config:  cfg,
-		manager: mgr,
+		storage:   st,
+		executor:  storage.NewQueryExecutor(st, storage.DefaultQueryWorkers),
+		config:    cfg,
+		manager:   mgr,
+		validator: security.NewValidator(),
+		authors:   cfg.Rendering.AuthorOverrides(),
 	}
 }
 
@@ -36,6 +43,11 @@ func (qh *QueryHelper) getOwnerHex() (string, error) {
 	}
 }
 
+// OwnerHex returns the owner's pubkey in hex
+func (qh *QueryHelper) OwnerHex() (string, error) {
+	return qh.getOwnerHex()
+}
+
 // GetOutboxNotes returns notes authored by the owner
 func (qh *QueryHelper) GetOutboxNotes(ctx context.Context, limit int) ([]*EnrichedEvent, error) {
 	ownerHex, err := qh.getOwnerHex()
@@ -70,7 +82,7 @@ func (qh *QueryHelper) GetInboxReplies(ctx context.Context, limit int) ([]*Enric
 		Tags: nostr.TagMap{
 			"p": []string{ownerHex},
 		},
-		Limit: limit * 2, // Get more since we'll filter
+		Limit: limit,
 	}
 
 	events, err := qh.storage.QueryEvents(ctx, filter)
@@ -78,24 +90,16 @@ func (qh *QueryHelper) GetInboxReplies(ctx context.Context, limit int) ([]*Enric
 		return nil, err
 	}
 
-	// Filter to only actual replies (not just mentions)
-	replies := make([]*nostr.Event, 0)
-	for _, event := range events {
-		if qh.manager.IsMentioning(ctx, event, ownerHex) {
-			replies = append(replies, event)
-		}
-	}
-
-	// Apply limit
-	if len(replies) > limit {
-		replies = replies[:limit]
-	}
-
-	return qh.enrichEvents(ctx, replies)
+	return qh.enrichEvents(ctx, qh.withoutNoise(events, ownerHex))
 }
 
-// GetInboxReactions returns reactions to the owner's posts
+// GetInboxReactions returns reactions to the owner's posts that pass the noise filters
 func (qh *QueryHelper) GetInboxReactions(ctx context.Context, limit int) ([]*EnrichedEvent, error) {
+	ownerHex, err := qh.getOwnerHex()
+	if err != nil {
+		return nil, err
+	}
+
 	// First get owner's notes
 	ownerNotes, err := qh.GetOutboxNotes(ctx, 100)
 	if err != nil {
@@ -126,7 +130,7 @@ func (qh *QueryHelper) GetInboxReactions(ctx context.Context, limit int) ([]*Enr
 		return nil, err
 	}
 
-	return qh.enrichEvents(ctx, events)
+	return qh.enrichEvents(ctx, qh.withoutNoise(events, ownerHex))
 }
 
 // GetThreadReplies returns all replies in a thread
@@ -170,32 +174,41 @@ func (qh *QueryHelper) GetThreadByEvent(ctx context.Context, eventID string) (*T
 		rootID = eventID // Use event itself as root
 	}
 
-	// Get root event
-	rootFilter := nostr.Filter{
-		IDs: []string{rootID},
-	}
-
-	rootEvents, err := qh.storage.QueryEvents(ctx, rootFilter)
-	if err != nil {
-		return nil, err
+	// Fetch the root event and its replies concurrently.
+	// Replies are capped so a huge thread cannot tie up storage.
+	replyLimit := qh.validator.CapResultLimit(qh.config.Display.Limits.MaxThreadReplies, security.MaxResultLimit)
+	results := qh.executor.Execute(ctx, []nostr.Filter{
+		{IDs: []string{rootID}},
+		{Kinds: []int{1}, Tags: nostr.TagMap{"e": []string{rootID}}, Limit: replyLimit},
+	})
+	for _, result := range results {
+		if result.Err != nil {
+			return nil, result.Err
+		}
 	}
 
 	var root *nostr.Event
-	if len(rootEvents) > 0 {
-		root = rootEvents[0]
+	if len(results[0].Events) > 0 {
+		root = results[0].Events[0]
 	} else {
 		root = event // Fallback
 	}
 
-	// Get all replies in thread
-	replies, err := qh.GetThreadReplies(ctx, rootID)
+	replies, err := qh.enrichEvents(ctx, results[1].Events)
 	if err != nil {
 		return nil, err
 	}
 
+	// Nest replies by their NIP-10 parent, then order each level as configured
+	tree := BuildReplyTree(root.ID, replies)
+	if rank := qh.replyRank(ctx, qh.config.Behavior.SortPreferences.Threads); rank != nil {
+		OrderReplyTree(tree, rank)
+	}
+
 	return &ThreadView{
 		Root:    qh.enrichEvent(ctx, root),
 		Replies: replies,
+		Tree:    tree,
 	}, nil
 }
 
@@ -221,7 +234,11 @@ func (qh *QueryHelper) filterAndSortEvents(enriched []*EnrichedEvent, sortMode s
 		enriched = filtered
 	}
 
-	// Apply sorting
+	return qh.sortEnriched(enriched, sortMode)
+}
+
+// sortEnriched sorts events in place according to a sort preference
+func (qh *QueryHelper) sortEnriched(enriched []*EnrichedEvent, sortMode string) []*EnrichedEvent {
 	switch sortMode {
 	case "engagement":
 		sort.Slice(enriched, func(i, j int) bool {
@@ -290,13 +307,16 @@ func (qh *QueryHelper) enrichEvent(ctx context.Context, event *nostr.Event) *Enr
 
 // GetPopularNotes returns notes sorted by interaction score
 func (qh *QueryHelper) GetPopularNotes(ctx context.Context, limit int) ([]*EnrichedEvent, error) {
-	// Get recent notes
-	filter := nostr.Filter{
-		Kinds: []int{1},
-		Limit: limit * 10, // Get more to sort
+	// Rank in storage so the limit applies to the most popular notes, not recent ones
+	ids, err := qh.storage.GetMostInteracted(ctx, 1, limit)
+	if err != nil {
+		return nil, err
+	}
+	if len(ids) == 0 {
+		return []*EnrichedEvent{}, nil
 	}
 
-	events, err := qh.storage.QueryEvents(ctx, filter)
+	events, err := qh.storage.QueryEvents(ctx, nostr.Filter{IDs: ids, Limit: len(ids)})
 	if err != nil {
 		return nil, err
 	}
@@ -322,13 +342,16 @@ func (qh *QueryHelper) GetPopularNotes(ctx context.Context, limit int) ([]*Enric
 // EnrichedEvent contains an event with its aggregate data
 type EnrichedEvent struct {
 	Event      *nostr.Event
-	Aggregates *EventAggregates
+	Aggregates *EventAggregates // For reposts, the reposted event's aggregates
+	Reposted   *nostr.Event     // For reposts, the reposted event if available
+	Reposters  []string         // For reposts, everyone who reposted it (newest first) when collapsed
 }
 
 // ThreadView represents a full thread with root and replies
 type ThreadView struct {
 	Root    *EnrichedEvent
 	Replies []*EnrichedEvent
+	Tree    []*ThreadNode // Replies nested by parent, siblings in the configured thread order
 }
 
 // === Public Section-Based Query Methods ===
@@ -341,28 +364,44 @@ func (qh *QueryHelper) GetNotes(ctx context.Context, limit int) ([]*EnrichedEven
 		return nil, err
 	}
 
-	// Get all owner's kind 1 events
-	filter := nostr.Filter{
-		Kinds:   []int{1},
-		Authors: []string{ownerHex},
-		Limit:   limit * 2, // Get more since we'll filter out replies
-	}
+	return qh.getAuthorPosts(ctx, ownerHex, []int{1}, limit)
+}
 
-	events, err := qh.storage.QueryEvents(ctx, filter)
-	if err != nil {
-		return nil, err
+// GetNotesByAuthor returns an author's root notes and long-form articles,
+// filtered and sorted the same way as GetNotes
+func (qh *QueryHelper) GetNotesByAuthor(ctx context.Context, pubkey string, limit int) ([]*EnrichedEvent, error) {
+	return qh.getAuthorPosts(ctx, pubkey, []int{1, 30023}, limit)
+}
+
+// getAuthorPosts returns an author's events of the given kinds, leaving out
+// kind 1 replies, then applies content filtering and the notes sort preference
+func (qh *QueryHelper) getAuthorPosts(ctx context.Context, pubkey string, kinds []int, limit int) ([]*EnrichedEvent, error) {
+	var notes []*nostr.Event
+	var otherKinds []int
+	for _, kind := range kinds {
+		if kind == 1 {
+			// Replies are filtered out in storage so the limit counts only root notes
+			roots, err := qh.storage.QueryNotes(ctx, nostr.Filter{Authors: []string{pubkey}, Limit: limit}, false)
+			if err != nil {
+				return nil, err
+			}
+			notes = append(notes, roots...)
+		} else {
+			otherKinds = append(otherKinds, kind)
+		}
 	}
 
-	// Filter out replies - only root notes
-	notes := make([]*nostr.Event, 0)
-	for _, event := range events {
-		threadInfo, err := ParseThreadInfo(event)
+	if len(otherKinds) > 0 {
+		events, err := qh.storage.QueryEvents(ctx, nostr.Filter{Kinds: otherKinds, Authors: []string{pubkey}, Limit: limit})
 		if err != nil {
-			continue
-		}
-		if !threadInfo.IsReply() {
-			notes = append(notes, event)
+			return nil, err
 		}
+		notes = append(notes, events...)
+	}
+
+	sortNewestFirst(notes)
+	if len(notes) > limit {
+		notes = notes[:limit]
 	}
 
 	enriched, err := qh.enrichEvents(ctx, notes)
@@ -429,28 +468,16 @@ func (qh *QueryHelper) GetReplies(ctx context.Context, limit int) ([]*EnrichedEv
 		Tags: nostr.TagMap{
 			"p": []string{ownerHex},
 		},
-		Limit: limit * 2, // Get more since we'll filter
+		Limit: limit,
 	}
 
-	events, err := qh.storage.QueryEvents(ctx, filter)
+	// Only actual replies (have a reply e tag), filtered in storage
+	replies, err := qh.storage.QueryNotes(ctx, filter, true)
 	if err != nil {
 		return nil, err
 	}
 
-	// Filter to only actual replies (have e tags)
-	replies := make([]*nostr.Event, 0)
-	for _, event := range events {
-		threadInfo, err := ParseThreadInfo(event)
-		if err != nil {
-			continue
-		}
-		// A reply must have a ReplyToID (e tag)
-		if threadInfo.IsReply() && qh.manager.IsMentioning(ctx, event, ownerHex) {
-			replies = append(replies, event)
-		}
-	}
-
-	enriched, err := qh.enrichEvents(ctx, replies)
+	enriched, err := qh.enrichEvents(ctx, qh.withoutNoise(replies, ownerHex))
 	if err != nil {
 		return nil, err
 	}
@@ -487,7 +514,7 @@ func (qh *QueryHelper) GetMentions(ctx context.Context, limit int) ([]*EnrichedE
 		return nil, err
 	}
 
-	enriched, err := qh.enrichEvents(ctx, events)
+	enriched, err := qh.enrichEvents(ctx, qh.withoutNoise(events, ownerHex))
 	if err != nil {
 		return nil, err
 	}
@@ -503,3 +530,12 @@ func (qh *QueryHelper) GetMentions(ctx context.Context, limit int) ([]*EnrichedE
 	// Return all mentions (both replies and non-reply mentions)
 	return enriched, nil
 }
+
+// GetMutuals returns the pubkeys the owner follows that follow them back
+func (qh *QueryHelper) GetMutuals(ctx context.Context) ([]string, error) {
+	ownerHex, err := qh.getOwnerHex()
+	if err != nil {
+		return nil, err
+	}
+	return qh.storage.GetMutuals(ctx, ownerHex)
+}
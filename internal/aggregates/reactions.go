@@ -53,24 +53,24 @@ func (rp *ReactionProcessor) ProcessReaction(ctx context.Context, event *nostr.E
 		return nil // Silently ignore filtered reactions
 	}
 
+	// Count each reaction only once, however often it is fetched
+	if seen, err := rp.storage.IsInteractionSeen(ctx, event.ID); err != nil || seen {
+		return err
+	}
+
 	// Update aggregate
-	return rp.storage.IncrementReaction(ctx, targetEventID, reaction, int64(event.CreatedAt))
+	if err := rp.storage.IncrementReaction(ctx, targetEventID, reaction, int64(event.CreatedAt)); err != nil {
+		return err
+	}
+	return rp.storage.MarkInteractionsSeen(ctx, map[string]string{event.ID: targetEventID})
 }
 
 // isAllowedReaction checks if a reaction passes noise filters
 func (rp *ReactionProcessor) isAllowedReaction(reaction string) bool {
-	if rp.config == nil || len(rp.config.NoiseFilters.AllowedReactionChars) == 0 {
+	if rp.config == nil {
 		return true // No filter configured, allow all
 	}
-
-	// Check if reaction is in allowed list
-	for _, allowed := range rp.config.NoiseFilters.AllowedReactionChars {
-		if reaction == allowed {
-			return true
-		}
-	}
-
-	return false
+	return reactionAllowed(rp.config.NoiseFilters, reaction)
 }
 
 // GetReactionStats returns reaction statistics for an event
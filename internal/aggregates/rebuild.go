@@ -0,0 +1,203 @@
+package aggregates
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"sort"
+	"time"
+
+	"github.com/nbd-wtf/go-nostr"
+	"github.com/sandwich/nophr/internal/storage"
+)
+
+// RebuildOptions controls a rebuild
+type RebuildOptions struct {
+	EventID   string        // Rebuild only this event's aggregate; empty rebuilds every one
+	BatchSize int           // Aggregates compared and written per transaction
+	Pause     time.Duration // Wait between batches, leaving write slots for a running server
+	DryRun    bool          // Report discrepancies without writing
+}
+
+// Discrepancy is an aggregate whose stored counts differ from the stored interactions
+type Discrepancy struct {
+	EventID string
+	Stored  *storage.Aggregate // nil if the event had no aggregate
+	Rebuilt *storage.Aggregate // Empty if the event has no interactions left
+}
+
+// Changes describes each count that differs, e.g. "replies 3 -> 4"
+func (d *Discrepancy) Changes() []string {
+	stored := d.Stored
+	if stored == nil {
+		stored = &storage.Aggregate{}
+	}
+	rebuilt := d.Rebuilt
+
+	var changes []string
+	if stored.ReplyCount != rebuilt.ReplyCount {
+		changes = append(changes, fmt.Sprintf("replies %d -> %d", stored.ReplyCount, rebuilt.ReplyCount))
+	}
+	if stored.ReactionTotal != rebuilt.ReactionTotal || !maps.Equal(stored.ReactionCounts, rebuilt.ReactionCounts) {
+		changes = append(changes, fmt.Sprintf("reactions %d -> %d", stored.ReactionTotal, rebuilt.ReactionTotal))
+	}
+	if stored.ZapSatsTotal != rebuilt.ZapSatsTotal {
+		changes = append(changes, fmt.Sprintf("zaps %d -> %d sats", stored.ZapSatsTotal, rebuilt.ZapSatsTotal))
+	}
+	if stored.LastInteractionAt != rebuilt.LastInteractionAt {
+		changes = append(changes, fmt.Sprintf("last interaction %d -> %d", stored.LastInteractionAt, rebuilt.LastInteractionAt))
+	}
+	return changes
+}
+
+// RebuildReport summarizes a rebuild
+type RebuildReport struct {
+	Interactions  int // Replies, reactions and zaps counted
+	Checked       int // Aggregates compared
+	Discrepancies []*Discrepancy
+}
+
+// Rebuild recomputes aggregates from the replies, reactions and zaps in storage
+// and overwrites those that have drifted. Writes go out in batches with a pause
+// between them, so it is safe to run while nophr is serving. An interaction
+// ingested while its target's batch is being written may be missed; running
+// the rebuild again picks it up.
+func (r *Reconciler) Rebuild(ctx context.Context, opts RebuildOptions) (*RebuildReport, error) {
+	if opts.BatchSize <= 0 {
+		opts.BatchSize = 100
+	}
+
+	report := &RebuildReport{}
+	rebuilt := make(map[string]*storage.Aggregate)
+	sources := make(map[string]map[string]string) // Target -> interaction ID -> target
+
+	err := r.storage.IterateEvents(ctx, 1000, func(event *nostr.Event) error {
+		target := InteractionTarget(event)
+		if target == "" || (opts.EventID != "" && target != opts.EventID) {
+			return nil
+		}
+
+		agg, ok := rebuilt[target]
+		if !ok {
+			agg = &storage.Aggregate{EventID: target, ReactionCounts: make(map[string]int)}
+			rebuilt[target] = agg
+			sources[target] = make(map[string]string)
+		}
+		countInteraction(agg, event)
+		sources[target][event.ID] = target
+		report.Interactions++
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan interactions: %w", err)
+	}
+
+	// Stored aggregates with no interactions left are discrepancies too
+	targets := make(map[string]bool)
+	if opts.EventID != "" {
+		targets[opts.EventID] = true
+	} else {
+		ids, err := r.storage.ListAggregateIDs(ctx)
+		if err != nil {
+			return nil, err
+		}
+		for _, id := range ids {
+			targets[id] = true
+		}
+	}
+	for id := range rebuilt {
+		targets[id] = true
+	}
+	ids := make([]string, 0, len(targets))
+	for id := range targets {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	for start := 0; start < len(ids); start += opts.BatchSize {
+		if start > 0 && opts.Pause > 0 && !opts.DryRun {
+			select {
+			case <-ctx.Done():
+				return report, ctx.Err()
+			case <-time.After(opts.Pause):
+			}
+		}
+
+		batch := ids[start:min(start+opts.BatchSize, len(ids))]
+		stored, err := r.storage.GetAggregates(ctx, batch)
+		if err != nil {
+			return report, err
+		}
+
+		var changed []*storage.Aggregate
+		seen := make(map[string]string)
+		for _, id := range batch {
+			agg, ok := rebuilt[id]
+			if !ok {
+				agg = &storage.Aggregate{EventID: id, ReactionCounts: make(map[string]int)}
+			}
+			report.Checked++
+			maps.Copy(seen, sources[id])
+
+			d := &Discrepancy{EventID: id, Stored: stored[id], Rebuilt: agg}
+			if len(d.Changes()) > 0 {
+				report.Discrepancies = append(report.Discrepancies, d)
+				changed = append(changed, agg)
+			}
+		}
+
+		if opts.DryRun {
+			continue
+		}
+		if err := r.storage.ReplaceAggregates(ctx, changed); err != nil {
+			return report, err
+		}
+		// Counted interactions must not be counted again if they are re-ingested
+		if err := r.storage.MarkInteractionsSeen(ctx, seen); err != nil {
+			return report, err
+		}
+	}
+
+	return report, nil
+}
+
+// InteractionTarget returns the event a reply, reaction or zap counts toward,
+// or "" for other events
+func InteractionTarget(event *nostr.Event) string {
+	switch event.Kind {
+	case 1:
+		info, err := ParseThreadInfo(event)
+		if err != nil || !info.IsReply() {
+			return ""
+		}
+		return info.ReplyToID
+	case 7:
+		if tag := event.Tags.Find("e"); tag != nil {
+			return tag[1]
+		}
+	case 9735:
+		info, err := (&ZapProcessor{}).parseZapEvent(event)
+		if err == nil {
+			return info.TargetEventID
+		}
+	}
+	return ""
+}
+
+// countInteraction adds one interaction to an aggregate, the way ingestion does
+func countInteraction(agg *storage.Aggregate, event *nostr.Event) {
+	switch event.Kind {
+	case 1:
+		agg.ReplyCount++
+	case 7:
+		reaction := event.Content
+		if reaction == "" {
+			reaction = "+"
+		}
+		agg.ReactionCounts[reaction]++
+		agg.ReactionTotal++
+	case 9735:
+		agg.ZapSatsTotal += ZapAmount(event)
+	}
+	agg.LastInteractionAt = max(agg.LastInteractionAt, int64(event.CreatedAt))
+}
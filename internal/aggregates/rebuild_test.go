@@ -0,0 +1,92 @@
+package aggregates
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/nbd-wtf/go-nostr"
+	"github.com/sandwich/nophr/internal/config"
+	"github.com/sandwich/nophr/internal/storage"
+)
+
+func TestRebuild(t *testing.T) {
+	ctx := context.Background()
+	st, err := storage.New(ctx, &config.Storage{Driver: "sqlite", SQLitePath: filepath.Join(t.TempDir(), "test.db")})
+	if err != nil {
+		t.Fatalf("Failed to create storage: %v", err)
+	}
+	defer st.Close()
+
+	sk := nostr.GeneratePrivateKey()
+	sign := func(event *nostr.Event) *nostr.Event {
+		event.Sign(sk)
+		if err := st.StoreEvent(ctx, event); err != nil {
+			t.Fatalf("Failed to store event: %v", err)
+		}
+		return event
+	}
+
+	root := sign(&nostr.Event{CreatedAt: 100, Kind: 1, Content: "root"})
+	reply := sign(&nostr.Event{CreatedAt: 101, Kind: 1, Tags: nostr.Tags{{"e", root.ID, "", "root"}}, Content: "reply"})
+	sign(&nostr.Event{CreatedAt: 102, Kind: 1, Tags: nostr.Tags{{"e", root.ID, "", "root"}, {"e", reply.ID, "", "reply"}}, Content: "nested"})
+	sign(&nostr.Event{CreatedAt: 103, Kind: 7, Tags: nostr.Tags{{"e", root.ID}}, Content: ""})
+	sign(&nostr.Event{CreatedAt: 104, Kind: 7, Tags: nostr.Tags{{"e", root.ID}}, Content: "🔥"})
+	sign(&nostr.Event{CreatedAt: 105, Kind: 9735, Tags: nostr.Tags{{"e", root.ID}, {"bolt11", "lnbc210n1pexample"}}})
+
+	// Drifted counts for the root, and an aggregate whose interactions are gone
+	stale := strings.Repeat("f", 64)
+	for _, agg := range []*storage.Aggregate{
+		{EventID: root.ID, ReplyCount: 3, ReactionTotal: 1, ReactionCounts: map[string]int{"+": 1}, ZapSatsTotal: 1000, LastInteractionAt: 105},
+		{EventID: stale, ReplyCount: 1, LastInteractionAt: 50},
+	} {
+		if err := st.SaveAggregate(ctx, agg); err != nil {
+			t.Fatalf("Failed to save aggregate: %v", err)
+		}
+	}
+
+	cfg := config.Default()
+	r := NewReconciler(st, NewManager(st, cfg))
+
+	report, err := r.Rebuild(ctx, RebuildOptions{DryRun: true})
+	if err != nil {
+		t.Fatalf("Rebuild failed: %v", err)
+	}
+	if report.Interactions != 5 || report.Checked != 3 || len(report.Discrepancies) != 3 {
+		t.Fatalf("Expected 5 interactions and 3 discrepancies among 3 aggregates, got %d, %d, %d",
+			report.Interactions, len(report.Discrepancies), report.Checked)
+	}
+	if agg, _ := st.GetAggregate(ctx, root.ID); agg.ReplyCount != 3 {
+		t.Errorf("A dry run should not write, got reply count %d", agg.ReplyCount)
+	}
+
+	if _, err := r.Rebuild(ctx, RebuildOptions{BatchSize: 2}); err != nil {
+		t.Fatalf("Rebuild failed: %v", err)
+	}
+	agg, err := st.GetAggregate(ctx, root.ID)
+	if err != nil {
+		t.Fatalf("Failed to get aggregate: %v", err)
+	}
+	if agg.ReplyCount != 1 || agg.ReactionTotal != 2 || agg.ReactionCounts["🔥"] != 1 || agg.ZapSatsTotal != 21 {
+		t.Errorf("Expected 1 direct reply, 2 reactions and 21 sats, got %+v", agg)
+	}
+	if agg, err := st.GetAggregate(ctx, reply.ID); err != nil || agg.ReplyCount != 1 {
+		t.Errorf("Expected the nested reply to count toward its parent, got %+v, %v", agg, err)
+	}
+	if _, err := st.GetAggregate(ctx, stale); err == nil {
+		t.Error("Expected the stale aggregate to be removed")
+	}
+	if seen, _ := st.IsInteractionSeen(ctx, reply.ID); !seen {
+		t.Error("Expected counted interactions to be marked seen")
+	}
+
+	// Repeating the rebuild finds nothing, and --event limits it to one aggregate
+	report, err = r.Rebuild(ctx, RebuildOptions{EventID: root.ID})
+	if err != nil {
+		t.Fatalf("Rebuild failed: %v", err)
+	}
+	if report.Checked != 1 || len(report.Discrepancies) != 0 {
+		t.Errorf("Expected one clean aggregate, got %d checked and %d discrepancies", report.Checked, len(report.Discrepancies))
+	}
+}
@@ -0,0 +1,59 @@
+package aggregates
+
+import "context"
+
+// Thread reply orders, set by behavior.sort_preferences.threads
+const (
+	ThreadOrderChronological = "chronological" // Oldest first
+	ThreadOrderSocial        = "social"        // The owner, then followed authors, then everyone else
+	ThreadOrderEngagement    = "engagement"    // Most interactions first
+)
+
+// Social ranks: lower ranks are listed first among siblings
+const (
+	rankOwner int64 = iota
+	rankFollowed
+	rankStranger
+)
+
+// replyRank returns the sibling ranking for a thread order, or nil to keep
+// replies oldest first. Social ordering reads the owner's follows from the
+// social graph once per thread; if the owner is unknown it falls back to
+// chronological.
+func (qh *QueryHelper) replyRank(ctx context.Context, order string) func(node *ThreadNode) int64 {
+	switch order {
+	case ThreadOrderSocial:
+		ownerHex, err := qh.getOwnerHex()
+		if err != nil {
+			return nil
+		}
+
+		// A graph lookup failure only costs the ordering, not the thread
+		following, _ := qh.storage.GetFollowingPubkeys(ctx, ownerHex)
+		followed := make(map[string]bool, len(following))
+		for _, pubkey := range following {
+			followed[pubkey] = true
+		}
+
+		return func(node *ThreadNode) int64 {
+			switch pubkey := node.Event.Event.PubKey; {
+			case pubkey == ownerHex:
+				return rankOwner
+			case followed[pubkey]:
+				return rankFollowed
+			default:
+				return rankStranger
+			}
+		}
+
+	case ThreadOrderEngagement:
+		return func(node *ThreadNode) int64 {
+			if node.Event.Aggregates == nil {
+				return 0
+			}
+			return -node.Event.Aggregates.InteractionScore()
+		}
+	}
+
+	return nil
+}
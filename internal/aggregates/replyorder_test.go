@@ -0,0 +1,80 @@
+package aggregates
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/nbd-wtf/go-nostr"
+	"github.com/nbd-wtf/go-nostr/nip19"
+	"github.com/sandwich/nophr/internal/config"
+	"github.com/sandwich/nophr/internal/storage"
+)
+
+func TestGetThreadByEventOrder(t *testing.T) {
+	ctx := context.Background()
+	st, err := storage.New(ctx, &config.Storage{Driver: "sqlite", SQLitePath: filepath.Join(t.TempDir(), "test.db")})
+	if err != nil {
+		t.Fatalf("Failed to create storage: %v", err)
+	}
+	defer st.Close()
+
+	ownerSK := nostr.GeneratePrivateKey()
+	owner, _ := nostr.GetPublicKey(ownerSK)
+	npub, _ := nip19.EncodePublicKey(owner)
+	friendSK := nostr.GeneratePrivateKey()
+	friend, _ := nostr.GetPublicKey(friendSK)
+	strangerSK := nostr.GeneratePrivateKey()
+
+	if err := st.SaveGraphNode(ctx, &storage.GraphNode{RootPubkey: owner, Pubkey: friend, Depth: 1}); err != nil {
+		t.Fatalf("Failed to save graph node: %v", err)
+	}
+
+	root := &nostr.Event{CreatedAt: 100, Kind: 1, Content: "root"}
+	root.Sign(ownerSK)
+	events := []*nostr.Event{root}
+
+	// Replies arrive stranger, friend, owner; social order reverses them
+	for i, sk := range []string{strangerSK, friendSK, ownerSK} {
+		reply := &nostr.Event{
+			CreatedAt: nostr.Timestamp(101 + i),
+			Kind:      1,
+			Tags:      nostr.Tags{{"e", root.ID, "", "root"}},
+			Content:   "reply",
+		}
+		reply.Sign(sk)
+		events = append(events, reply)
+	}
+	for _, event := range events {
+		if err := st.StoreEvent(ctx, event); err != nil {
+			t.Fatalf("Failed to store event: %v", err)
+		}
+	}
+
+	tests := []struct {
+		order string
+		want  []string
+	}{
+		{"chronological", []string{events[1].ID, events[2].ID, events[3].ID}},
+		{"social", []string{events[3].ID, events[2].ID, events[1].ID}},
+	}
+	for _, tt := range tests {
+		cfg := config.Default()
+		cfg.Identity.Npub = npub
+		cfg.Behavior.SortPreferences.Threads = tt.order
+		qh := NewQueryHelper(st, cfg, NewManager(st, cfg))
+
+		thread, err := qh.GetThreadByEvent(ctx, root.ID)
+		if err != nil {
+			t.Fatalf("GetThreadByEvent failed: %v", err)
+		}
+		if len(thread.Tree) != len(tt.want) {
+			t.Fatalf("%s: expected %d top-level replies, got %d", tt.order, len(tt.want), len(thread.Tree))
+		}
+		for i, node := range thread.Tree {
+			if node.Event.Event.ID != tt.want[i] {
+				t.Errorf("%s: reply %d is %s, want %s", tt.order, i, node.Event.Event.ID, tt.want[i])
+			}
+		}
+	}
+}
@@ -0,0 +1,139 @@
+package aggregates
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/nbd-wtf/go-nostr"
+)
+
+// Repost kinds (NIP-18): kind 6 reposts a kind 1 note, kind 16 any other kind
+const (
+	KindRepost        = 6
+	KindGenericRepost = 16
+)
+
+// IsRepost reports whether an event is a repost
+func IsRepost(event *nostr.Event) bool {
+	return event.Kind == KindRepost || event.Kind == KindGenericRepost
+}
+
+// RepostTarget returns the ID of the event a repost points at, or "" if it has no e tag
+func RepostTarget(event *nostr.Event) string {
+	for _, tag := range event.Tags {
+		if len(tag) >= 2 && tag[0] == "e" {
+			return tag[1]
+		}
+	}
+	return ""
+}
+
+// embeddedRepost returns the reposted event carried in a repost's content, or nil
+// if there is none or it isn't a validly signed copy of the target
+func embeddedRepost(event *nostr.Event) *nostr.Event {
+	target := RepostTarget(event)
+	if target == "" || event.Content == "" {
+		return nil
+	}
+
+	var embedded nostr.Event
+	if err := json.Unmarshal([]byte(event.Content), &embedded); err != nil {
+		return nil
+	}
+	if embedded.ID != target || embedded.GetID() != target {
+		return nil
+	}
+	if ok, err := embedded.CheckSignature(); err != nil || !ok {
+		return nil
+	}
+	return &embedded
+}
+
+// withReposts extends a notes fetcher with reposts matching the same filter, merged
+// newest first and cut to the filter's limit so batches stay keyset-consistent
+func (qh *QueryHelper) withReposts(fetch pageFetcher) pageFetcher {
+	return func(ctx context.Context, filter nostr.Filter) ([]*nostr.Event, error) {
+		events, err := fetch(ctx, filter)
+		if err != nil {
+			return nil, err
+		}
+
+		repostFilter := filter
+		repostFilter.Kinds = []int{KindRepost, KindGenericRepost}
+		reposts, err := qh.storage.QueryEvents(ctx, repostFilter)
+		if err != nil {
+			return nil, err
+		}
+
+		events = append(events, reposts...)
+		sortNewestFirst(events)
+		if filter.Limit > 0 && len(events) > filter.Limit {
+			events = events[:filter.Limit]
+		}
+		return events, nil
+	}
+}
+
+// resolveReposts attaches the reposted event to every repost in the list, from the
+// repost's embedded copy or from storage. Reposts of events we don't have keep a nil
+// Reposted and are rendered by target ID.
+func (qh *QueryHelper) resolveReposts(ctx context.Context, events []*EnrichedEvent) error {
+	missing := make(map[string][]*EnrichedEvent)
+	for _, e := range events {
+		if !IsRepost(e.Event) {
+			continue
+		}
+		if embedded := embeddedRepost(e.Event); embedded != nil {
+			e.Reposted = embedded
+			continue
+		}
+		if target := RepostTarget(e.Event); target != "" {
+			missing[target] = append(missing[target], e)
+		}
+	}
+	if len(missing) == 0 {
+		return nil
+	}
+
+	ids := make([]string, 0, len(missing))
+	for id := range missing {
+		ids = append(ids, id)
+	}
+
+	stored, err := qh.storage.QueryEvents(ctx, nostr.Filter{IDs: ids, Limit: len(ids)})
+	if err != nil {
+		return err
+	}
+	for _, event := range stored {
+		for _, e := range missing[event.ID] {
+			e.Reposted = event
+		}
+	}
+	return nil
+}
+
+// collapseRepost folds a repost into an earlier repost of the same event in the
+// page, returning true if it was folded. seen maps target IDs to their entries.
+func collapseRepost(seen map[string]*EnrichedEvent, e *EnrichedEvent) bool {
+	if !IsRepost(e.Event) {
+		return false
+	}
+	target := RepostTarget(e.Event)
+	if target == "" {
+		return false
+	}
+
+	first, ok := seen[target]
+	if !ok {
+		seen[target] = e
+		return false
+	}
+
+	for _, pubkey := range first.Reposters {
+		if pubkey == e.Event.PubKey {
+			return true
+		}
+	}
+	first.Reposters = append(first.Reposters, e.Event.PubKey)
+	return true
+}
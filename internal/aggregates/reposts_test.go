@@ -0,0 +1,111 @@
+package aggregates
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/nbd-wtf/go-nostr"
+	"github.com/nbd-wtf/go-nostr/nip19"
+	"github.com/sandwich/nophr/internal/config"
+	"github.com/sandwich/nophr/internal/storage"
+)
+
+func TestRepostsInFeeds(t *testing.T) {
+	ctx := context.Background()
+	st, err := storage.New(ctx, &config.Storage{Driver: "sqlite", SQLitePath: filepath.Join(t.TempDir(), "test.db")})
+	if err != nil {
+		t.Fatalf("Failed to create storage: %v", err)
+	}
+	defer st.Close()
+
+	ownerSK := nostr.GeneratePrivateKey()
+	owner, _ := nostr.GetPublicKey(ownerSK)
+	npub, _ := nip19.EncodePublicKey(owner)
+
+	store := func(sk string, event *nostr.Event) *nostr.Event {
+		event.Sign(sk)
+		if err := st.StoreEvent(ctx, event); err != nil {
+			t.Fatalf("Failed to store event: %v", err)
+		}
+		return event
+	}
+
+	// The owner reposts someone else's note, embedding it
+	otherSK := nostr.GeneratePrivateKey()
+	other, _ := nostr.GetPublicKey(otherSK)
+	theirs := &nostr.Event{PubKey: other, CreatedAt: 1000, Kind: 1, Content: "their note"}
+	theirs.Sign(otherSK)
+	store(ownerSK, &nostr.Event{
+		CreatedAt: 1100,
+		Kind:      KindRepost,
+		Tags:      nostr.Tags{{"e", theirs.ID}, {"p", other}},
+		Content:   theirs.String(),
+	})
+	store(ownerSK, &nostr.Event{CreatedAt: 1050, Kind: 1, Content: "my note"})
+	mine := store(ownerSK, &nostr.Event{CreatedAt: 900, Kind: 1, Content: "popular note"})
+
+	// Three others repost the owner's note, without embedding it
+	for i := 0; i < 3; i++ {
+		sk := nostr.GeneratePrivateKey()
+		store(sk, &nostr.Event{
+			CreatedAt: nostr.Timestamp(2000 + i),
+			Kind:      KindRepost,
+			Tags:      nostr.Tags{{"e", mine.ID}, {"p", owner}},
+		})
+	}
+
+	cfg := config.Default()
+	cfg.Identity.Npub = npub
+	qh := NewQueryHelper(st, cfg, NewManager(st, cfg))
+
+	notes, err := qh.GetNotesPage(ctx, nil, 10)
+	if err != nil {
+		t.Fatalf("GetNotesPage failed: %v", err)
+	}
+	if len(notes.Events) != 3 || !IsRepost(notes.Events[0].Event) {
+		t.Fatalf("Expected the owner's repost first among 3 items, got %d", len(notes.Events))
+	}
+	if notes.Events[0].Reposted == nil || notes.Events[0].Reposted.ID != theirs.ID {
+		t.Errorf("Expected the embedded note to be resolved, got %v", notes.Events[0].Reposted)
+	}
+
+	mentions, err := qh.GetMentionsPage(ctx, nil, 10)
+	if err != nil {
+		t.Fatalf("GetMentionsPage failed: %v", err)
+	}
+	if len(mentions.Events) != 1 {
+		t.Fatalf("Expected reposts of one note to collapse into one item, got %d", len(mentions.Events))
+	}
+	if collapsed := mentions.Events[0]; len(collapsed.Reposters) != 3 || collapsed.Reposted == nil || collapsed.Reposted.ID != mine.ID {
+		t.Errorf("Expected 3 reposters of the owner's note from storage, got %+v", collapsed)
+	}
+
+	cfg.Inbox.CollapseReposts = false
+	mentions, err = qh.GetMentionsPage(ctx, nil, 2)
+	if err != nil {
+		t.Fatalf("GetMentionsPage failed: %v", err)
+	}
+	if len(mentions.Events) != 2 || mentions.Next == nil {
+		t.Errorf("Expected separate reposts with an older page, got %d", len(mentions.Events))
+	}
+}
+
+func TestEmbeddedRepostRequiresValidCopy(t *testing.T) {
+	sk := nostr.GeneratePrivateKey()
+	pk, _ := nostr.GetPublicKey(sk)
+	note := &nostr.Event{PubKey: pk, CreatedAt: 1000, Kind: 1, Content: "original"}
+	note.Sign(sk)
+
+	repost := &nostr.Event{Kind: KindRepost, Tags: nostr.Tags{{"e", note.ID}}, Content: note.String()}
+	if embedded := embeddedRepost(repost); embedded == nil || embedded.Content != "original" {
+		t.Fatalf("Expected the embedded note, got %v", embedded)
+	}
+
+	forged := *note
+	forged.Content = "forged"
+	repost.Content = forged.String()
+	if embedded := embeddedRepost(repost); embedded != nil {
+		t.Errorf("A tampered embedded note should be ignored, got %v", embedded)
+	}
+}
@@ -0,0 +1,24 @@
+package aggregates
+
+import (
+	"context"
+
+	"github.com/nbd-wtf/go-nostr"
+)
+
+// snapshotKey is the context key holding a request's snapshot time
+type snapshotKey struct{}
+
+// WithSnapshot pins listings queried with ctx to events created at or before at,
+// so a page rendered while sync is ingesting reflects a single point in time
+func WithSnapshot(ctx context.Context, at nostr.Timestamp) context.Context {
+	return context.WithValue(ctx, snapshotKey{}, at)
+}
+
+// SnapshotFrom returns the snapshot time pinned on ctx, or the current time if none is set
+func SnapshotFrom(ctx context.Context) nostr.Timestamp {
+	if at, ok := ctx.Value(snapshotKey{}).(nostr.Timestamp); ok {
+		return at
+	}
+	return nostr.Now()
+}
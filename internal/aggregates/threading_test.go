@@ -4,6 +4,7 @@ import (
 	"testing"
 
 	"github.com/nbd-wtf/go-nostr"
+	"github.com/sandwich/nophr/internal/storage"
 )
 
 func TestParseThreadInfo_MarkedFormat(t *testing.T) {
@@ -239,3 +240,27 @@ func TestIsMentioningPubkey(t *testing.T) {
 		t.Error("Did not expect to find pubkey3")
 	}
 }
+
+func TestStorageReplyIndexMatchesThreadInfo(t *testing.T) {
+	tagSets := []nostr.Tags{
+		{},
+		{{"p", "pubkey1"}},
+		{{"e", "root1"}},
+		{{"e", "root1"}, {"e", "reply1"}},
+		{{"e", "root1", "", "root"}},
+		{{"e", "root1", "", "root"}, {"e", "reply1", "", "reply"}},
+		{{"e", "quoted1", "", "mention"}},
+		{{"e", "root1", "", "root"}, {"e", "other1"}},
+	}
+
+	for _, tags := range tagSets {
+		event := &nostr.Event{Kind: 1, Tags: tags}
+		info, err := ParseThreadInfo(event)
+		if err != nil {
+			t.Fatalf("ParseThreadInfo failed: %v", err)
+		}
+		if got := storage.IsReplyNote(event); got != info.IsReply() {
+			t.Errorf("Tags %v: storage index says reply=%v, ParseThreadInfo says %v", tags, got, info.IsReply())
+		}
+	}
+}
@@ -0,0 +1,151 @@
+package aggregates
+
+import (
+	"context"
+	"sort"
+)
+
+// ThreadNode is one reply in a reconstructed thread tree
+type ThreadNode struct {
+	Event    *EnrichedEvent
+	ParentID string // Event this reply answers (the root for top-level replies)
+	Depth    int    // 1 for direct replies to the root
+	Children []*ThreadNode
+}
+
+// anchorLength is how many hex characters of the event ID make up a reply anchor
+const anchorLength = 8
+
+// Anchor returns a stable short label for the reply ("#" plus the start of its
+// event ID) that readers can search for in a pager. It does not change as
+// other replies arrive, unlike the reply's position in the thread.
+func (n *ThreadNode) Anchor() string {
+	id := n.Event.Event.ID
+	if len(id) > anchorLength {
+		id = id[:anchorLength]
+	}
+	return "#" + id
+}
+
+// BuildReplyTree reconstructs the reply tree under rootID from NIP-10 root/reply markers.
+// Replies whose parent is missing from the set (not synced, deleted or outside the
+// reply cap) are attached directly to the root so nothing is dropped.
+// Siblings are ordered oldest first.
+func BuildReplyTree(rootID string, replies []*EnrichedEvent) []*ThreadNode {
+	nodes := make(map[string]*ThreadNode, len(replies))
+	ordered := make([]*ThreadNode, 0, len(replies))
+	for _, reply := range replies {
+		if reply == nil || reply.Event == nil || reply.Event.ID == rootID {
+			continue
+		}
+		if _, dup := nodes[reply.Event.ID]; dup {
+			continue
+		}
+
+		parentID := rootID
+		if info, err := ParseThreadInfo(reply.Event); err == nil && info.ReplyToID != "" {
+			parentID = info.ReplyToID
+		}
+		node := &ThreadNode{Event: reply, ParentID: parentID}
+		nodes[reply.Event.ID] = node
+		ordered = append(ordered, node)
+	}
+	sortNodes(ordered)
+
+	children := make(map[string][]*ThreadNode)
+	for _, node := range ordered {
+		if _, ok := nodes[node.ParentID]; !ok {
+			node.ParentID = rootID
+		}
+		children[node.ParentID] = append(children[node.ParentID], node)
+	}
+
+	// Walk from the root; anything left unvisited sits on a reply cycle and is
+	// promoted to the top level
+	visited := make(map[string]bool, len(nodes))
+	top := attachChildren(rootID, 1, children, visited)
+	for _, node := range ordered {
+		if visited[node.Event.Event.ID] {
+			continue
+		}
+		node.ParentID = rootID
+		visited[node.Event.Event.ID] = true
+		node.Depth = 1
+		node.Children = attachChildren(node.Event.Event.ID, 2, children, visited)
+		top = append(top, node)
+	}
+
+	return top
+}
+
+// attachChildren links the children of parentID, depth-first
+func attachChildren(parentID string, depth int, children map[string][]*ThreadNode, visited map[string]bool) []*ThreadNode {
+	result := make([]*ThreadNode, 0, len(children[parentID]))
+	for _, node := range children[parentID] {
+		id := node.Event.Event.ID
+		if visited[id] {
+			continue
+		}
+		visited[id] = true
+		node.Depth = depth
+		node.Children = attachChildren(id, depth+1, children, visited)
+		result = append(result, node)
+	}
+	return result
+}
+
+// sortNodes orders siblings oldest first, by ID on ties for a stable layout
+func sortNodes(nodes []*ThreadNode) {
+	sort.Slice(nodes, func(i, j int) bool {
+		a, b := nodes[i].Event.Event, nodes[j].Event.Event
+		if a.CreatedAt != b.CreatedAt {
+			return a.CreatedAt < b.CreatedAt
+		}
+		return a.ID < b.ID
+	})
+}
+
+// OrderReplyTree reorders the siblings at every level of a reply tree by rank,
+// lowest first. Replies of equal rank keep their oldest-first order, so the
+// tree only moves replies within their own level.
+func OrderReplyTree(tree []*ThreadNode, rank func(node *ThreadNode) int64) {
+	sort.SliceStable(tree, func(i, j int) bool {
+		return rank(tree[i]) < rank(tree[j])
+	})
+	for _, node := range tree {
+		OrderReplyTree(node.Children, rank)
+	}
+}
+
+// FlattenThread lists a reply tree in reading order (each reply followed by its
+// answers). Depths beyond maxDepth are clamped so deep chains stay readable;
+// maxDepth < 1 disables clamping.
+func FlattenThread(tree []*ThreadNode, maxDepth int) []*ThreadNode {
+	flat := make([]*ThreadNode, 0)
+	var walk func(nodes []*ThreadNode)
+	walk = func(nodes []*ThreadNode) {
+		for _, node := range nodes {
+			entry := *node
+			if maxDepth > 0 && entry.Depth > maxDepth {
+				entry.Depth = maxDepth
+			}
+			flat = append(flat, &entry)
+			walk(node.Children)
+		}
+	}
+	walk(tree)
+	return flat
+}
+
+// ThreadAuthors labels every reply author in nodes once, with label, a
+// renderer's author label for its protocol
+func ThreadAuthors(ctx context.Context, nodes []*ThreadNode, label func(context.Context, string) string) map[string]string {
+	names := make(map[string]string)
+	for _, node := range nodes {
+		pubkey := node.Event.Event.PubKey
+		if _, ok := names[pubkey]; !ok {
+			names[pubkey] = label(ctx, pubkey)
+		}
+	}
+	return names
+}
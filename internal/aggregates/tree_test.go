@@ -0,0 +1,134 @@
+package aggregates
+
+import (
+	"context"
+	"testing"
+
+	"github.com/nbd-wtf/go-nostr"
+)
+
+func treeReply(id, parent string, createdAt nostr.Timestamp) *EnrichedEvent {
+	return &EnrichedEvent{Event: &nostr.Event{
+		ID:        id,
+		Kind:      1,
+		CreatedAt: createdAt,
+		Tags: nostr.Tags{
+			{"e", "root", "", "root"},
+			{"e", parent, "", "reply"},
+		},
+	}}
+}
+
+func TestBuildReplyTree(t *testing.T) {
+	replies := []*EnrichedEvent{
+		treeReply("c", "b", 3),
+		treeReply("b", "root", 2),
+		treeReply("a", "root", 1),
+		treeReply("d", "missing", 4), // Parent not synced
+		treeReply("x", "y", 5),       // Reply cycle
+		treeReply("y", "x", 6),
+	}
+
+	tree := BuildReplyTree("root", replies)
+
+	var order []string
+	var depths []int
+	for _, node := range FlattenThread(tree, 0) {
+		order = append(order, node.Event.Event.ID)
+		depths = append(depths, node.Depth)
+	}
+
+	wantOrder := []string{"a", "b", "c", "d", "x", "y"}
+	wantDepths := []int{1, 1, 2, 1, 1, 2}
+	if len(order) != len(wantOrder) {
+		t.Fatalf("FlattenThread() = %v, want %v", order, wantOrder)
+	}
+	for i := range wantOrder {
+		if order[i] != wantOrder[i] || depths[i] != wantDepths[i] {
+			t.Errorf("position %d = %s at depth %d, want %s at depth %d", i, order[i], depths[i], wantOrder[i], wantDepths[i])
+		}
+	}
+}
+
+func TestFlattenThreadMaxDepth(t *testing.T) {
+	replies := []*EnrichedEvent{
+		treeReply("a", "root", 1),
+		treeReply("b", "a", 2),
+		treeReply("c", "b", 3),
+	}
+
+	flat := FlattenThread(BuildReplyTree("root", replies), 2)
+	if len(flat) != 3 {
+		t.Fatalf("Expected 3 replies, got %d", len(flat))
+	}
+	if flat[2].Depth != 2 {
+		t.Errorf("Expected depth clamped to 2, got %d", flat[2].Depth)
+	}
+	if flat[2].ParentID != "b" {
+		t.Errorf("Expected clamped reply to keep parent b, got %s", flat[2].ParentID)
+	}
+}
+
+func TestThreadNodeAnchor(t *testing.T) {
+	node := &ThreadNode{Event: &EnrichedEvent{Event: &nostr.Event{ID: "1a2b3c4d5e6f"}}}
+	if got := node.Anchor(); got != "#1a2b3c4d" {
+		t.Errorf("Anchor() = %q, want %q", got, "#1a2b3c4d")
+	}
+
+	short := &ThreadNode{Event: &EnrichedEvent{Event: &nostr.Event{ID: "abc"}}}
+	if got := short.Anchor(); got != "#abc" {
+		t.Errorf("Anchor() = %q, want %q", got, "#abc")
+	}
+}
+
+func TestOrderReplyTree(t *testing.T) {
+	replies := []*EnrichedEvent{
+		treeReply("a", "root", 1),
+		treeReply("b", "root", 2),
+		treeReply("c", "root", 3),
+		treeReply("a1", "a", 4),
+		treeReply("a2", "a", 5),
+	}
+	tree := BuildReplyTree("root", replies)
+
+	// Rank c and a2 first; the rest keep their oldest-first order within each level
+	OrderReplyTree(tree, func(node *ThreadNode) int64 {
+		if id := node.Event.Event.ID; id == "c" || id == "a2" {
+			return 0
+		}
+		return 1
+	})
+
+	var order []string
+	for _, node := range FlattenThread(tree, 0) {
+		order = append(order, node.Event.Event.ID)
+	}
+	want := []string{"c", "a", "a2", "a1", "b"}
+	for i := range want {
+		if i >= len(order) || order[i] != want[i] {
+			t.Fatalf("OrderReplyTree() = %v, want %v", order, want)
+		}
+	}
+}
+
+func TestThreadAuthors(t *testing.T) {
+	type key struct{}
+	ctx := context.WithValue(context.Background(), key{}, "request")
+
+	var nodes []*ThreadNode
+	for _, pubkey := range []string{"alice", "bob", "alice"} {
+		nodes = append(nodes, &ThreadNode{Event: &EnrichedEvent{Event: &nostr.Event{PubKey: pubkey}}})
+	}
+	calls := 0
+	names := ThreadAuthors(ctx, nodes, func(ctx context.Context, pubkey string) string {
+		calls++
+		if ctx.Value(key{}) != "request" {
+			t.Error("Expected the label to get the request's context")
+		}
+		return "@" + pubkey
+	})
+
+	if calls != 2 || names["alice"] != "@alice" || names["bob"] != "@bob" {
+		t.Errorf("ThreadAuthors() = %v with %d lookups, want each author labelled once", names, calls)
+	}
+}
@@ -54,7 +54,13 @@ func (zp *ZapProcessor) ProcessZap(ctx context.Context, event *nostr.Event) erro
 
 	// Update aggregate if targeting an event
 	if info.TargetEventID != "" {
-		return zp.storage.AddZapAmount(ctx, info.TargetEventID, info.Amount, int64(event.CreatedAt))
+		if seen, err := zp.storage.IsInteractionSeen(ctx, event.ID); err != nil || seen {
+			return err
+		}
+		if err := zp.storage.AddZapAmount(ctx, info.TargetEventID, info.Amount, int64(event.CreatedAt)); err != nil {
+			return err
+		}
+		return zp.storage.MarkInteractionsSeen(ctx, map[string]string{event.ID: info.TargetEventID})
 	}
 
 	// For profile zaps, we could track separately but for now just ignore
@@ -164,6 +170,15 @@ func (zp *ZapProcessor) parseInvoiceAmount(invoice string) (int64, error) {
 	return amount, nil
 }
 
+// ZapAmount returns the sats paid by a kind 9735 zap receipt, or 0 if the amount is unknown
+func ZapAmount(event *nostr.Event) int64 {
+	info, err := (&ZapProcessor{}).parseZapEvent(event)
+	if err != nil {
+		return 0
+	}
+	return info.Amount
+}
+
 // GetZapStats returns zap statistics for an event
 func (zp *ZapProcessor) GetZapStats(ctx context.Context, eventID string) (int64, error) {
 	agg, err := zp.storage.GetAggregate(ctx, eventID)
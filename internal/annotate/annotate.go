@@ -0,0 +1,216 @@
+// Package annotate classifies notes and articles as they are stored: the
+// language they are written in, their hashtags and the configured topics they
+// mention. Annotations are computed once at ingest so tag listings, language
+// filters and search qualifiers are lookups rather than per-request analysis.
+package annotate
+
+import (
+	"slices"
+	"strings"
+	"unicode"
+
+	"github.com/nbd-wtf/go-nostr"
+)
+
+// Annotation namespaces
+const (
+	NamespaceLanguage = "lang"  // ISO 639-1 code, e.g. "en"
+	NamespaceHashtag  = "t"     // Lowercased hashtag without the '#'
+	NamespaceTopic    = "topic" // Name of a configured topic
+)
+
+// maxHashtags bounds how many hashtags one event is annotated with, so
+// hashtag-stuffed spam can't flood the tag index
+const maxHashtags = 20
+
+// maxHashtagLength drops hashtags longer than this, in runes
+const maxHashtagLength = 64
+
+// Annotation is one classification of an event
+type Annotation struct {
+	Namespace string
+	Value     string
+}
+
+// Annotates reports whether events of kind are classified: notes and articles
+func Annotates(kind int) bool {
+	return kind == 1 || kind == 30023
+}
+
+// Classifier annotates events with their language, hashtags and topics
+type Classifier struct {
+	topics map[string][]string // Topic name to normalized keywords
+	names  []string            // Topic names, sorted
+}
+
+// New creates a classifier for the given topics, each a name and the
+// keywords that place an event in it. Keywords match whole words or
+// hashtags, ignoring case.
+func New(topics map[string][]string) *Classifier {
+	c := &Classifier{topics: make(map[string][]string, len(topics))}
+	for name, keywords := range topics {
+		name = TopicName(name)
+		if name == "" {
+			continue
+		}
+		for _, keyword := range keywords {
+			if normalized := strings.Join(words(keyword), " "); normalized != "" {
+				c.topics[name] = append(c.topics[name], normalized)
+			}
+		}
+		if len(c.topics[name]) > 0 && !slices.Contains(c.names, name) {
+			c.names = append(c.names, name)
+		}
+	}
+	slices.Sort(c.names)
+	return c
+}
+
+// TopicName normalizes a topic name as annotations store it: lowercased, with
+// dashes for spaces so it stays one word in search qualifiers
+func TopicName(name string) string {
+	return strings.Join(strings.Fields(strings.ToLower(name)), "-")
+}
+
+// Annotate classifies an event. Kinds that aren't annotated return nil.
+func (c *Classifier) Annotate(event *nostr.Event) []Annotation {
+	if !Annotates(event.Kind) {
+		return nil
+	}
+
+	var annotations []Annotation
+	if lang := Language(event); lang != "" {
+		annotations = append(annotations, Annotation{NamespaceLanguage, lang})
+	}
+
+	hashtags := Hashtags(event)
+	for _, tag := range hashtags {
+		annotations = append(annotations, Annotation{NamespaceHashtag, tag})
+	}
+
+	for _, topic := range c.Topics(event.Content, hashtags) {
+		annotations = append(annotations, Annotation{NamespaceTopic, topic})
+	}
+	return annotations
+}
+
+// Topics returns the topics whose keywords appear in content as whole words,
+// or among hashtags
+func (c *Classifier) Topics(content string, hashtags []string) []string {
+	if len(c.names) == 0 {
+		return nil
+	}
+
+	// Padded so keywords only match at word boundaries
+	text := " " + strings.Join(words(content), " ") + " "
+
+	var matched []string
+	for _, name := range c.names {
+		for _, keyword := range c.topics[name] {
+			if strings.Contains(text, " "+keyword+" ") || slices.Contains(hashtags, keyword) {
+				matched = append(matched, name)
+				break
+			}
+		}
+	}
+	return matched
+}
+
+// Hashtags returns an event's hashtags, from its t tags and #words in its
+// content, lowercased and without duplicates
+func Hashtags(event *nostr.Event) []string {
+	var hashtags []string
+	add := func(tag string) {
+		tag = strings.ToLower(strings.TrimPrefix(strings.TrimSpace(tag), "#"))
+		if tag == "" || len([]rune(tag)) > maxHashtagLength || len(hashtags) >= maxHashtags || slices.Contains(hashtags, tag) {
+			return
+		}
+		hashtags = append(hashtags, tag)
+	}
+
+	for _, tag := range event.Tags {
+		if len(tag) >= 2 && tag[0] == "t" {
+			add(tag[1])
+		}
+	}
+	for _, field := range strings.Fields(event.Content) {
+		if tag, ok := contentHashtag(field); ok {
+			add(tag)
+		}
+	}
+	return hashtags
+}
+
+// contentHashtag extracts the hashtag a whitespace-separated word starts
+// with, e.g. "#nostr," is "nostr". Hashtags need a letter, so "#1" isn't one.
+func contentHashtag(field string) (string, bool) {
+	if !strings.HasPrefix(field, "#") {
+		return "", false
+	}
+	tag := strings.TrimLeftFunc(field[1:], func(r rune) bool { return r == '#' })
+	end := strings.IndexFunc(tag, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsNumber(r) && r != '_'
+	})
+	if end >= 0 {
+		tag = tag[:end]
+	}
+	if !strings.ContainsFunc(tag, unicode.IsLetter) {
+		return "", false
+	}
+	return tag, true
+}
+
+// words splits text into lowercased runs of letters and digits
+func words(text string) []string {
+	return strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsNumber(r)
+	})
+}
+
+// ParsePath reads an entry of the /tags pages: "topic:<name>", "lang:<code>"
+// or a hashtag, with or without its '#'
+func ParsePath(segment string) (Annotation, bool) {
+	segment = strings.ToLower(strings.TrimSpace(segment))
+	a := Annotation{Namespace: NamespaceHashtag, Value: strings.TrimPrefix(segment, "#")}
+	if value, ok := strings.CutPrefix(segment, "topic:"); ok {
+		a = Annotation{Namespace: NamespaceTopic, Value: value}
+	} else if value, ok := strings.CutPrefix(segment, "lang:"); ok {
+		a = Annotation{Namespace: NamespaceLanguage, Value: value}
+	}
+	return a, a.Value != ""
+}
+
+// Path formats an annotation as an entry of the /tags pages
+func (a Annotation) Path() string {
+	switch a.Namespace {
+	case NamespaceTopic:
+		return "topic:" + a.Value
+	case NamespaceLanguage:
+		return "lang:" + a.Value
+	}
+	return a.Value
+}
+
+// Name is an annotation's value as shown under its heading, e.g. "#nostr",
+// "bitcoin" or "Spanish"
+func (a Annotation) Name() string {
+	switch a.Namespace {
+	case NamespaceTopic:
+		return a.Value
+	case NamespaceLanguage:
+		return LanguageName(a.Value)
+	}
+	return "#" + a.Value
+}
+
+// Label describes an annotation for titles, e.g. "#nostr", "Topic: bitcoin"
+// or "Language: Spanish"
+func (a Annotation) Label() string {
+	switch a.Namespace {
+	case NamespaceTopic:
+		return "Topic: " + a.Name()
+	case NamespaceLanguage:
+		return "Language: " + a.Name()
+	}
+	return a.Name()
+}
@@ -0,0 +1,153 @@
+package annotate
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/nbd-wtf/go-nostr"
+)
+
+func TestDetectLanguage(t *testing.T) {
+	tests := []struct {
+		text string
+		want string
+	}{
+		{"Just finished reading the new spec and it is better than I expected", "en"},
+		{"El perro está en la casa y no quiere salir porque hace frío", "es"},
+		{"Eu não sei se isso é uma boa ideia, mas vou tentar com você", "pt"},
+		{"Je ne sais pas si nous sommes prêts pour la sortie de demain", "fr"},
+		{"Ich habe heute keine Zeit, aber morgen ist es auch noch schön", "de"},
+		{"Oggi sono molto stanco ma questo libro è bellissimo", "it"},
+		{"Ik weet niet wat ik met dit weekend moet doen, maar het wordt leuk", "nl"},
+		{"今日はとても良い天気ですね。散歩に行きましょう", "ja"},
+		{"今天天气很好我们去公园散步吧", "zh"},
+		{"오늘 날씨가 정말 좋네요 산책하러 가요", "ko"},
+		{"Сегодня отличная погода, пойдём гулять в парк", "ru"},
+		{"gm", ""},
+		{"🤙🤙🤙 https://example.com/a-very-long-link-that-is-not-prose nostr:npub1abc", ""},
+		{"#bitcoin #nostr #plebchain #zapathon #grownostr", ""},
+	}
+
+	for _, tt := range tests {
+		if got := DetectLanguage(tt.text); got != tt.want {
+			t.Errorf("DetectLanguage(%q) = %q, want %q", tt.text, got, tt.want)
+		}
+	}
+}
+
+func TestLanguageLabel(t *testing.T) {
+	event := &nostr.Event{
+		Kind:    1,
+		Content: "Just finished reading the new spec and it is better than I expected",
+		Tags:    nostr.Tags{{"L", "ISO-639-1"}, {"l", "DE", "ISO-639-1"}},
+	}
+	if got := Language(event); got != "de" {
+		t.Errorf("Language() with a NIP-32 label = %q, want de", got)
+	}
+}
+
+func TestHashtags(t *testing.T) {
+	event := &nostr.Event{
+		Kind:    1,
+		Content: "Stacking #Bitcoin, reading #nostr... #1 fan of ##zaps and#not this one",
+		Tags:    nostr.Tags{{"t", "bitcoin"}, {"t", "#Photography"}, {"t", ""}},
+	}
+
+	got := Hashtags(event)
+	want := []string{"bitcoin", "photography", "nostr", "zaps"}
+	if fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("Hashtags() = %v, want %v", got, want)
+	}
+}
+
+func TestAnnotate(t *testing.T) {
+	c := New(map[string][]string{
+		"Bitcoin":   {"bitcoin", "sats", "Lightning Network"},
+		"gardening": {"garden", "tomatoes"},
+		"empty":     {" "},
+	})
+
+	event := &nostr.Event{
+		Kind:    1,
+		Content: "Paid for the seeds over the lightning network and it is the best way to do it #plants",
+	}
+	got := c.Annotate(event)
+	want := []Annotation{
+		{NamespaceLanguage, "en"},
+		{NamespaceHashtag, "plants"},
+		{NamespaceTopic, "bitcoin"},
+	}
+	if fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("Annotate() = %v, want %v", got, want)
+	}
+
+	// Keywords match whole words or hashtags, not parts of words
+	if topics := c.Topics("my gardener grows tomatoes", nil); fmt.Sprint(topics) != "[gardening]" {
+		t.Errorf("Topics() = %v, want [gardening]", topics)
+	}
+	if topics := c.Topics("gardeners and satsuma", []string{"sats"}); fmt.Sprint(topics) != "[bitcoin]" {
+		t.Errorf("Topics() with hashtag = %v, want [bitcoin]", topics)
+	}
+
+	// Only notes and articles are annotated
+	if got := c.Annotate(&nostr.Event{Kind: 0, Content: `{"about":"#bitcoin"}`}); got != nil {
+		t.Errorf("Annotate(kind 0) = %v, want nil", got)
+	}
+}
+
+func TestParsePath(t *testing.T) {
+	tests := []struct {
+		segment string
+		want    Annotation
+		label   string
+	}{
+		{"nostr", Annotation{NamespaceHashtag, "nostr"}, "#nostr"},
+		{"#Nostr", Annotation{NamespaceHashtag, "nostr"}, "#nostr"},
+		{"topic:bitcoin", Annotation{NamespaceTopic, "bitcoin"}, "Topic: bitcoin"},
+		{"lang:es", Annotation{NamespaceLanguage, "es"}, "Language: Spanish"},
+	}
+	for _, tt := range tests {
+		got, ok := ParsePath(tt.segment)
+		if !ok || got != tt.want {
+			t.Errorf("ParsePath(%q) = %v, %v; want %v", tt.segment, got, ok, tt.want)
+			continue
+		}
+		if got.Label() != tt.label {
+			t.Errorf("Label() = %q, want %q", got.Label(), tt.label)
+		}
+		if again, _ := ParsePath(got.Path()); again != got {
+			t.Errorf("ParsePath(Path()) = %v, want %v", again, got)
+		}
+	}
+
+	for _, segment := range []string{"", "#", "topic:"} {
+		if _, ok := ParsePath(segment); ok {
+			t.Errorf("ParsePath(%q) = ok, want rejected", segment)
+		}
+	}
+}
+
+func TestFormat(t *testing.T) {
+	article := &nostr.Event{Kind: 30023, Content: strings.Repeat("word ", 201)}
+	values := Values{
+		NamespaceReadTime: ReadTime(article),
+		"unregistered":    {"key": "value"},
+	}
+	if got := Format(values); fmt.Sprint(got) != "[Reading time: 2 min]" {
+		t.Errorf("Format() = %v", got)
+	}
+
+	RegisterFormatter("spam", func(values map[string]string) []string {
+		return []string{"Spam score: " + values["score"]}
+	})
+	defer RegisterFormatter("spam", nil)
+	values["spam"] = map[string]string{"score": "0.2"}
+	if got := Format(values); fmt.Sprint(got) != "[Reading time: 2 min Spam score: 0.2]" {
+		t.Errorf("Format() = %v", got)
+	}
+
+	if ReadTime(&nostr.Event{Kind: 1, Content: "gm"}) != nil {
+		t.Error("Expected no reading time for a note")
+	}
+}
@@ -0,0 +1,173 @@
+package annotate
+
+import (
+	"strings"
+	"unicode"
+
+	"github.com/nbd-wtf/go-nostr"
+)
+
+// minLetters is the least text, in letters, a language is guessed from
+const minLetters = 12
+
+// minStopwords is how many common words a Latin-script language needs to be
+// recognised, so a single shared word like "no" doesn't decide it
+const minStopwords = 2
+
+// scripts maps writing systems used by a single common language to it.
+// Japanese and Chinese share Han characters and are told apart by kana.
+var scripts = []struct {
+	table *unicode.RangeTable
+	lang  string
+}{
+	{unicode.Hangul, "ko"},
+	{unicode.Cyrillic, "ru"},
+	{unicode.Arabic, "ar"},
+	{unicode.Hebrew, "he"},
+	{unicode.Greek, "el"},
+	{unicode.Thai, "th"},
+	{unicode.Devanagari, "hi"},
+}
+
+// stopwords lists frequent short words of languages written in Latin script.
+// Words shared between languages count for each; the distinctive ones decide.
+var stopwords = map[string][]string{
+	"en": {"the", "and", "is", "are", "was", "of", "to", "that", "it", "this", "with", "for", "you", "not", "have", "be", "on", "what", "just", "but", "my", "they", "we", "about", "from", "will", "can", "your", "if", "so"},
+	"es": {"el", "la", "los", "las", "de", "que", "y", "en", "un", "una", "es", "por", "con", "para", "lo", "se", "del", "al", "pero", "más", "como", "está", "muy", "también", "yo", "esto", "hay"},
+	"pt": {"o", "os", "as", "de", "que", "e", "do", "da", "em", "um", "uma", "é", "não", "com", "para", "por", "mais", "no", "na", "dos", "das", "isso", "muito", "você", "eu", "também", "está"},
+	"fr": {"le", "la", "les", "de", "des", "et", "est", "un", "une", "du", "en", "que", "qui", "pas", "pour", "dans", "sur", "avec", "ce", "je", "il", "nous", "vous", "mais", "très", "sont"},
+	"de": {"der", "die", "das", "und", "ist", "nicht", "ein", "eine", "ich", "zu", "mit", "den", "auf", "es", "sich", "auch", "für", "von", "dem", "sie", "wir", "aber", "noch", "wie", "sind"},
+	"it": {"il", "la", "di", "che", "e", "è", "un", "una", "per", "non", "con", "del", "della", "sono", "mi", "ma", "anche", "questo", "come", "più", "gli", "ho", "io", "molto"},
+	"nl": {"de", "het", "een", "en", "van", "is", "niet", "dat", "ik", "je", "op", "te", "met", "voor", "zijn", "maar", "ook", "wat", "er", "dit", "naar", "hebben", "wij"},
+}
+
+// languageNames are the English names of the languages DetectLanguage returns
+var languageNames = map[string]string{
+	"ar": "Arabic", "de": "German", "el": "Greek", "en": "English", "es": "Spanish",
+	"fr": "French", "he": "Hebrew", "hi": "Hindi", "it": "Italian", "ja": "Japanese",
+	"ko": "Korean", "nl": "Dutch", "pt": "Portuguese", "ru": "Russian", "th": "Thai",
+	"zh": "Chinese",
+}
+
+// LanguageName returns the English name of an ISO 639-1 code, or the code
+// itself for languages that are only known from NIP-32 labels
+func LanguageName(code string) string {
+	if name, ok := languageNames[code]; ok {
+		return name
+	}
+	return code
+}
+
+// stopwordLanguages indexes stopwords by word
+var stopwordLanguages = func() map[string][]string {
+	index := make(map[string][]string)
+	for lang, list := range stopwords {
+		for _, word := range list {
+			index[word] = append(index[word], lang)
+		}
+	}
+	return index
+}()
+
+// Language returns the ISO 639-1 code of the language an event is written in,
+// or "" when it can't be told. A NIP-32 ISO-639-1 label on the event wins over
+// detection.
+func Language(event *nostr.Event) string {
+	for _, tag := range event.Tags {
+		if len(tag) >= 3 && tag[0] == "l" && tag[2] == "ISO-639-1" && len(tag[1]) == 2 {
+			return strings.ToLower(tag[1])
+		}
+	}
+	return DetectLanguage(event.Content)
+}
+
+// DetectLanguage guesses the language of text from its script, and for Latin
+// script from its common words. Links, mentions and hashtags are ignored.
+func DetectLanguage(text string) string {
+	text = stripReferences(text)
+
+	var latin, kana, han, total int
+	counts := make([]int, len(scripts))
+	for _, r := range text {
+		if !unicode.IsLetter(r) {
+			continue
+		}
+		total++
+		switch {
+		case unicode.Is(unicode.Latin, r):
+			latin++
+		case unicode.In(r, unicode.Hiragana, unicode.Katakana):
+			kana++
+		case unicode.Is(unicode.Han, r):
+			han++
+		default:
+			for i, script := range scripts {
+				if unicode.Is(script.table, r) {
+					counts[i]++
+					break
+				}
+			}
+		}
+	}
+
+	// CJK packs a word into a character or two, so needs fewer letters
+	if kana+han >= total/2 && kana+han >= minLetters/3 {
+		if kana > 0 {
+			return "ja"
+		}
+		return "zh"
+	}
+	if total < minLetters {
+		return ""
+	}
+	for i, script := range scripts {
+		if counts[i] > total/2 {
+			return script.lang
+		}
+	}
+	if latin > total/2 {
+		return detectLatin(text)
+	}
+	return ""
+}
+
+// detectLatin picks the Latin-script language with the most common words in
+// text, or "" when none has enough or two tie
+func detectLatin(text string) string {
+	scores := make(map[string]int)
+	for _, word := range words(text) {
+		for _, lang := range stopwordLanguages[word] {
+			scores[lang]++
+		}
+	}
+
+	best, bestScore, tied := "", 0, false
+	for lang, score := range scores {
+		switch {
+		case score > bestScore:
+			best, bestScore, tied = lang, score, false
+		case score == bestScore:
+			tied = true
+		}
+	}
+	if bestScore < minStopwords || tied {
+		return ""
+	}
+	return best
+}
+
+// stripReferences drops the parts of a note that aren't prose: links, nostr:
+// references, mentions and hashtags
+func stripReferences(text string) string {
+	fields := strings.Fields(text)
+	kept := fields[:0]
+	for _, field := range fields {
+		lower := strings.ToLower(field)
+		if strings.HasPrefix(lower, "http://") || strings.HasPrefix(lower, "https://") ||
+			strings.HasPrefix(lower, "nostr:") || strings.HasPrefix(field, "#") || strings.HasPrefix(field, "@") {
+			continue
+		}
+		kept = append(kept, field)
+	}
+	return strings.Join(kept, " ")
+}
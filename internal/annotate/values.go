@@ -0,0 +1,80 @@
+package annotate
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/nbd-wtf/go-nostr"
+)
+
+// Values are the keyed annotations of one event, by namespace and key. Unlike
+// the classifications above, any subsystem or plugin can attach them through
+// storage, e.g. a spam score or the titles of linked pages, without a schema
+// change for each kind of data.
+type Values map[string]map[string]string
+
+// FormatFunc renders one namespace's values of an event as lines for its
+// detail page, or nothing to hide them
+type FormatFunc func(values map[string]string) []string
+
+var (
+	formattersMu sync.RWMutex
+	formatters   = make(map[string]FormatFunc)
+)
+
+// RegisterFormatter sets how the values in namespace are shown on note and
+// article pages, replacing any earlier formatter. Namespaces without one are
+// stored but not shown.
+func RegisterFormatter(namespace string, format FormatFunc) {
+	formattersMu.Lock()
+	defer formattersMu.Unlock()
+	formatters[namespace] = format
+}
+
+// Format renders values with the registered formatters, namespaces in
+// alphabetical order
+func Format(values Values) []string {
+	formattersMu.RLock()
+	defer formattersMu.RUnlock()
+
+	namespaces := make([]string, 0, len(values))
+	for namespace := range values {
+		if formatters[namespace] != nil {
+			namespaces = append(namespaces, namespace)
+		}
+	}
+	sort.Strings(namespaces)
+
+	var lines []string
+	for _, namespace := range namespaces {
+		lines = append(lines, formatters[namespace](values[namespace])...)
+	}
+	return lines
+}
+
+// NamespaceReadTime holds an article's estimated reading time in "minutes"
+const NamespaceReadTime = "readtime"
+
+// readingSpeed is the words per minute reading times assume
+const readingSpeed = 200
+
+// ReadTime returns the reading time values of an article, or nil for other kinds
+func ReadTime(event *nostr.Event) map[string]string {
+	if event.Kind != 30023 {
+		return nil
+	}
+	minutes := (len(strings.Fields(event.Content)) + readingSpeed - 1) / readingSpeed
+	return map[string]string{"minutes": strconv.Itoa(max(minutes, 1))}
+}
+
+func init() {
+	RegisterFormatter(NamespaceReadTime, func(values map[string]string) []string {
+		if values["minutes"] == "" {
+			return nil
+		}
+		return []string{fmt.Sprintf("Reading time: %s min", values["minutes"])}
+	})
+}
@@ -2,8 +2,12 @@ package cache
 
 import (
 	"context"
+	"fmt"
+	"sync"
 	"testing"
 	"time"
+
+	"github.com/nbd-wtf/go-nostr"
 )
 
 func TestMemoryCache(t *testing.T) {
@@ -221,6 +225,76 @@ func TestMemoryCacheEviction(t *testing.T) {
 	}
 }
 
+func TestMemoryCacheSetMaxSize(t *testing.T) {
+	config := DefaultConfig()
+	config.MaxSize = 1000
+	cache := NewMemoryCache(config)
+	defer cache.Close()
+
+	ctx := context.Background()
+	for i := 0; i < 20; i++ {
+		cache.Set(ctx, string(rune('a'+i)), make([]byte, 10), time.Minute)
+	}
+	cache.Get(ctx, "t") // Most recently used
+
+	// Shrinking evicts right away, oldest first
+	cache.SetMaxSize(50)
+	stats, _ := cache.Stats(ctx)
+	if stats.SizeBytes > 50 || stats.Keys != 5 {
+		t.Errorf("after shrinking: %d bytes in %d keys, want 50 in 5", stats.SizeBytes, stats.Keys)
+	}
+	if found, _ := cache.Has(ctx, "t"); !found {
+		t.Error("most recently used entry was evicted")
+	}
+	if found, _ := cache.Has(ctx, "a"); found {
+		t.Error("least recently used entry was kept")
+	}
+
+	// Growing it back lets the cache fill up again
+	cache.SetMaxSize(config.MaxSize)
+	for i := 0; i < 20; i++ {
+		cache.Set(ctx, string(rune('a'+i)), make([]byte, 10), time.Minute)
+	}
+	if stats, _ = cache.Stats(ctx); stats.Keys != 20 {
+		t.Errorf("after growing: %d keys, want 20", stats.Keys)
+	}
+}
+
+func TestMemoryCacheConcurrentEviction(t *testing.T) {
+	config := DefaultConfig()
+	config.MaxSize = 1000
+
+	cache := NewMemoryCache(config)
+	defer cache.Close()
+
+	ctx := context.Background()
+
+	var wg sync.WaitGroup
+	for w := 0; w < 8; w++ {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			for i := 0; i < 500; i++ {
+				key := fmt.Sprintf("%d/%d", w, i%50)
+				cache.Set(ctx, key, make([]byte, 10), time.Minute)
+				cache.Get(ctx, key)
+			}
+		}(w)
+	}
+	wg.Wait()
+
+	stats, _ := cache.Stats(ctx)
+	if stats.SizeBytes > config.MaxSize {
+		t.Errorf("cache size %d exceeds max %d", stats.SizeBytes, config.MaxSize)
+	}
+	if stats.SizeBytes != stats.Keys*10 {
+		t.Errorf("size %d doesn't match %d keys of 10 bytes", stats.SizeBytes, stats.Keys)
+	}
+	if stats.Hits+stats.Misses != 8*500 {
+		t.Errorf("expected %d lookups, got %d", 8*500, stats.Hits+stats.Misses)
+	}
+}
+
 func TestMemoryCacheCleanup(t *testing.T) {
 	config := DefaultConfig()
 	config.CleanupInterval = 50 * time.Millisecond
@@ -245,3 +319,156 @@ func TestMemoryCacheCleanup(t *testing.T) {
 		t.Errorf("expected 0 keys after cleanup, got %d", stats.Keys)
 	}
 }
+
+func TestWarmer(t *testing.T) {
+	cache := NewMemoryCache(DefaultConfig())
+	defer cache.Close()
+
+	ctx := context.Background()
+	warmer := NewWarmer(cache)
+
+	warmer.Register("gopher", func(ctx context.Context, w *Warmer) error {
+		return w.WarmGopher(ctx, "/notes", []byte("notes"), time.Minute)
+	})
+	warmer.Register("finger", func(ctx context.Context, w *Warmer) error {
+		return w.WarmFinger(ctx, "owner", []byte("owner"), time.Minute)
+	})
+
+	if err := warmer.WarmAll(ctx); err != nil {
+		t.Fatalf("WarmAll failed: %v", err)
+	}
+
+	for _, key := range []string{GopherKey("/notes"), FingerKey("owner")} {
+		if _, hit, _ := cache.Get(ctx, key); !hit {
+			t.Errorf("expected %s to be warmed", key)
+		}
+	}
+}
+
+func TestInvalidationStorm(t *testing.T) {
+	cache := NewMemoryCache(DefaultConfig())
+	defer cache.Close()
+
+	ctx := context.Background()
+	inv := NewInvalidator(cache)
+
+	storms := 0
+	inv.SetStormHandler(3, time.Minute, func() { storms++ })
+
+	for i := 0; i < 5; i++ {
+		inv.InvalidatePattern(ctx, "gopher:/notes")
+	}
+	if storms != 1 {
+		t.Errorf("expected one storm after crossing the threshold, got %d", storms)
+	}
+
+	inv.InvalidateAll(ctx)
+	if storms != 2 {
+		t.Errorf("expected clearing the cache to count as a storm, got %d", storms)
+	}
+}
+
+func TestInvalidateEventResponses(t *testing.T) {
+	cache := NewMemoryCache(DefaultConfig())
+	defer cache.Close()
+
+	ctx := context.Background()
+	inv := NewInvalidator(cache)
+
+	keys := map[string]bool{
+		GopherKey("/note/root"):            true,  // referenced by the reply
+		GeminiKey("/thread/root", ""):      true,  // referenced by the reply
+		GopherKey("/author/alice/until/1"): true,  // reply author's posts
+		GopherKey("/notes"):                false, // left to its TTL
+		GeminiKey("/note/other", ""):       false,
+	}
+	for key := range keys {
+		cache.Set(ctx, key, []byte("page"), time.Minute)
+	}
+
+	reply := &nostr.Event{ID: "reply", Kind: 1, PubKey: "alice", Tags: nostr.Tags{{"e", "root", "", "root"}}}
+	if err := inv.OnEventIngested(ctx, reply); err != nil {
+		t.Fatalf("OnEventIngested() error = %v", err)
+	}
+
+	for key, invalidated := range keys {
+		if found, _ := cache.Has(ctx, key); found == invalidated {
+			t.Errorf("key %s: found = %v, want invalidated = %v", key, found, invalidated)
+		}
+	}
+
+	// The owner's posts also refresh the listings that show them
+	inv.SetOwner("owner")
+	ownerKeys := map[string]bool{
+		GopherKey("/"):                    true,
+		GeminiKey("/", ""):                true,
+		GopherKey("/notes/until/1_abc"):   true,
+		GeminiKey("/articles", ""):        true,
+		GeminiKey("/feed.gmi", ""):        true,
+		GopherKey("/notes/feed.xml"):      true,
+		FingerKey("owner"):                true,
+		GopherKey("/profile/alice/notes"): false,
+	}
+	for key := range ownerKeys {
+		cache.Set(ctx, key, []byte("page"), time.Minute)
+	}
+
+	note := &nostr.Event{ID: "note", Kind: 1, PubKey: "owner"}
+	if err := inv.OnEventIngested(ctx, note); err != nil {
+		t.Fatalf("OnEventIngested() error = %v", err)
+	}
+
+	for key, invalidated := range ownerKeys {
+		if found, _ := cache.Has(ctx, key); found == invalidated {
+			t.Errorf("owner key %s: found = %v, want invalidated = %v", key, found, invalidated)
+		}
+	}
+}
+
+func BenchmarkMemoryCacheGetParallel(b *testing.B) {
+	cache := NewMemoryCache(DefaultConfig())
+	defer cache.Close()
+
+	ctx := context.Background()
+	keys := make([]string, 1024)
+	for i := range keys {
+		keys[i] = GeminiKey(fmt.Sprintf("/note/%d", i), "")
+		cache.Set(ctx, keys[i], []byte("page"), time.Hour)
+	}
+
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		i := 0
+		for pb.Next() {
+			cache.Get(ctx, keys[i%len(keys)])
+			i++
+		}
+	})
+}
+
+func BenchmarkMemoryCacheMixedParallel(b *testing.B) {
+	cache := NewMemoryCache(DefaultConfig())
+	defer cache.Close()
+
+	ctx := context.Background()
+	keys := make([]string, 1024)
+	for i := range keys {
+		keys[i] = GeminiKey(fmt.Sprintf("/note/%d", i), "")
+		cache.Set(ctx, keys[i], []byte("page"), time.Hour)
+	}
+
+	// One write per ten reads, roughly a cache warming under load
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		i := 0
+		for pb.Next() {
+			key := keys[i%len(keys)]
+			if i%10 == 0 {
+				cache.Set(ctx, key, []byte("page"), time.Hour)
+			} else {
+				cache.Get(ctx, key)
+			}
+			i++
+		}
+	})
+}
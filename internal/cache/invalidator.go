@@ -4,14 +4,30 @@ import (
 	"context"
 	"fmt"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/nbd-wtf/go-nostr"
 )
 
+// Invalidation storm defaults: this many invalidations inside the window count as a storm
+const (
+	DefaultStormThreshold = 200
+	DefaultStormWindow    = 30 * time.Second
+)
+
 // Invalidator handles cache invalidation
 type Invalidator struct {
 	cache Cache
+	owner string // Hex pubkey whose events also refresh the owner listings
+
+	// Storm detection, see SetStormHandler
+	stormMu        sync.Mutex
+	stormThreshold int
+	stormWindow    time.Duration
+	stormStart     time.Time
+	stormCount     int
+	onStorm        func()
 }
 
 // NewInvalidator creates a new cache invalidator
@@ -21,10 +37,64 @@ func NewInvalidator(cache Cache) *Invalidator {
 	}
 }
 
+// SetOwner sets the owner's hex pubkey; their events also invalidate the home page,
+// the notes and articles listings and Finger responses
+func (inv *Invalidator) SetOwner(pubkey string) {
+	inv.owner = pubkey
+}
+
+// SetStormHandler registers fn to run when threshold invalidations happen within window,
+// or when the whole cache is cleared. It fires at most once per window; fn should not block.
+func (inv *Invalidator) SetStormHandler(threshold int, window time.Duration, fn func()) {
+	inv.stormMu.Lock()
+	defer inv.stormMu.Unlock()
+
+	inv.stormThreshold = threshold
+	inv.stormWindow = window
+	inv.onStorm = fn
+}
+
+// recordInvalidation counts one invalidation toward storm detection
+func (inv *Invalidator) recordInvalidation() {
+	inv.stormMu.Lock()
+
+	if inv.onStorm == nil {
+		inv.stormMu.Unlock()
+		return
+	}
+
+	now := time.Now()
+	if now.Sub(inv.stormStart) > inv.stormWindow {
+		inv.stormStart = now
+		inv.stormCount = 0
+	}
+	inv.stormCount++
+
+	// Fire exactly once when the threshold is crossed in this window
+	fire := inv.stormCount == inv.stormThreshold
+	fn := inv.onStorm
+	inv.stormMu.Unlock()
+
+	if fire {
+		fn()
+	}
+}
+
 // InvalidateEvent invalidates cache entries related to an event
 func (inv *Invalidator) InvalidateEvent(ctx context.Context, event *nostr.Event) error {
-	// Get invalidation patterns for this event
+	// Get invalidation patterns for this event and the pages that render it
 	patterns := InvalidationPatterns(event.ID, event.Kind, event.PubKey)
+	patterns = append(patterns, ResponsePatterns(event.ID, event.Kind, event.PubKey)...)
+	if inv.owner != "" && event.PubKey == inv.owner {
+		patterns = append(patterns, OwnerResponsePatterns()...)
+	}
+
+	// Replies, reactions and zaps change the note and thread pages of the events they reference
+	for _, tag := range event.Tags {
+		if len(tag) >= 2 && tag[0] == "e" && tag[1] != event.ID {
+			patterns = append(patterns, ResponsePatterns(tag[1], -1, "")...)
+		}
+	}
 
 	// Invalidate each pattern
 	for _, pattern := range patterns {
@@ -38,6 +108,8 @@ func (inv *Invalidator) InvalidateEvent(ctx context.Context, event *nostr.Event)
 
 // InvalidatePattern invalidates all keys matching a pattern
 func (inv *Invalidator) InvalidatePattern(ctx context.Context, pattern string) error {
+	inv.recordInvalidation()
+
 	// For patterns with wildcards, we need to handle differently
 	// based on the cache implementation
 
@@ -63,18 +135,8 @@ func (inv *Invalidator) InvalidatePattern(ctx context.Context, pattern string) e
 
 // invalidateMemoryPattern invalidates memory cache keys matching pattern
 func (inv *Invalidator) invalidateMemoryPattern(ctx context.Context, mc *MemoryCache, pattern string) error {
-	mc.mu.Lock()
-	defer mc.mu.Unlock()
-
 	// Convert glob pattern to regex-like matching
-	prefix := strings.TrimSuffix(pattern, "*")
-
-	for key := range mc.entries {
-		if strings.HasPrefix(key, prefix) {
-			delete(mc.entries, key)
-		}
-	}
-
+	mc.deletePrefix(strings.TrimSuffix(pattern, "*"))
 	return nil
 }
 
@@ -125,9 +187,20 @@ func (inv *Invalidator) InvalidateFinger(ctx context.Context) error {
 	return inv.InvalidatePattern(ctx, FingerPattern())
 }
 
-// InvalidateAll invalidates all cache entries
+// InvalidateAll invalidates all cache entries and triggers the storm handler
 func (inv *Invalidator) InvalidateAll(ctx context.Context) error {
-	return inv.cache.Clear(ctx)
+	if err := inv.cache.Clear(ctx); err != nil {
+		return err
+	}
+
+	inv.stormMu.Lock()
+	fn := inv.onStorm
+	inv.stormMu.Unlock()
+
+	if fn != nil {
+		fn()
+	}
+	return nil
 }
 
 // InvalidateProfile invalidates profile cache for a pubkey
@@ -170,33 +243,3 @@ func (inv *Invalidator) InvalidateSection(ctx context.Context, sectionName strin
 func (inv *Invalidator) OnEventIngested(ctx context.Context, event *nostr.Event) error {
 	return inv.InvalidateEvent(ctx, event)
 }
-
-// Warmer handles cache warming (pre-populating cache)
-type Warmer struct {
-	cache Cache
-}
-
-// NewWarmer creates a new cache warmer
-func NewWarmer(cache Cache) *Warmer {
-	return &Warmer{
-		cache: cache,
-	}
-}
-
-// WarmGopherHome pre-populates the Gopher home page
-func (w *Warmer) WarmGopherHome(ctx context.Context, content []byte, ttl time.Duration) error {
-	key := GopherKey("/")
-	return w.cache.Set(ctx, key, content, ttl)
-}
-
-// WarmGeminiHome pre-populates the Gemini home page
-func (w *Warmer) WarmGeminiHome(ctx context.Context, content []byte, ttl time.Duration) error {
-	key := GeminiKey("/", "")
-	return w.cache.Set(ctx, key, content, ttl)
-}
-
-// WarmProfile pre-populates a profile
-func (w *Warmer) WarmProfile(ctx context.Context, pubkey string, protocol string, content []byte, ttl time.Duration) error {
-	key := ProfileKey(pubkey, protocol)
-	return w.cache.Set(ctx, key, content, ttl)
-}
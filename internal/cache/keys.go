@@ -188,6 +188,46 @@ func ProfilePattern(pubkey string) string {
 	return fmt.Sprintf("profile:%s:*", pubkey)
 }
 
+// ResponsePatterns returns patterns for the rendered Gopher and Gemini pages
+// that show an event or its author's profile and posts
+func ResponsePatterns(eventID string, kind int, pubkey string) []string {
+	patterns := make([]string, 0, 8)
+	for _, path := range []string{"/note/" + eventID, "/thread/" + eventID} {
+		patterns = append(patterns, GopherKey(path)+"*", GeminiKey(path, "")+"*")
+	}
+
+	switch kind {
+	case 0: // Profile metadata shows on profile pages and finger responses
+		patterns = append(patterns,
+			GopherKey("/profile/"+pubkey)+"*",
+			GeminiKey("/profile/"+pubkey, "")+"*",
+			FingerPattern(),
+		)
+	case 1, 30023: // Posts are listed on the author's pages
+		for _, path := range []string{"/profile/" + pubkey + "/notes", "/author/" + pubkey} {
+			patterns = append(patterns, GopherKey(path)+"*", GeminiKey(path, "")+"*")
+		}
+	}
+
+	return patterns
+}
+
+// OwnerResponsePatterns returns patterns for the pages that list the owner's posts:
+// the home page, the notes and articles listings, their feeds and every Finger response
+func OwnerResponsePatterns() []string {
+	patterns := []string{GopherKey("/"), GeminiKey("/", ""), FingerPattern()}
+
+	// Site-wide feeds; section feeds sit under the section paths below
+	for _, path := range []string{"/feed.xml", "/feed.gmi"} {
+		patterns = append(patterns, GopherKey(path), GeminiKey(path, ""))
+	}
+
+	for _, path := range []string{"/notes", "/articles"} {
+		patterns = append(patterns, GopherKey(path)+"*", GeminiKey(path, "")+"*")
+	}
+	return patterns
+}
+
 // InvalidationPatterns returns all patterns that should be invalidated
 // for a given event
 func InvalidationPatterns(eventID string, kind int, pubkey string) []string {
@@ -2,29 +2,69 @@ package cache
 
 import (
 	"context"
+	"hash/fnv"
+	"sort"
+	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 )
 
+// memoryShards is the number of independently locked maps in a MemoryCache.
+// Keys are spread over the shards by hash so concurrent requests for
+// different pages rarely wait on the same lock.
+const memoryShards = 32
+
+// memoryEntry is a cached value. Access time and hit count are atomics so a
+// hit only needs its shard's read lock.
+type memoryEntry struct {
+	value      []byte
+	size       int64
+	expiresAt  time.Time
+	accessedAt atomic.Int64 // UnixNano
+	hits       atomic.Int64
+}
+
+// expired reports whether the entry is past its TTL
+func (e *memoryEntry) expired(now time.Time) bool {
+	return now.After(e.expiresAt)
+}
+
+// memoryShard is one lock and the keys that hash to it
+type memoryShard struct {
+	mu      sync.RWMutex
+	entries map[string]*memoryEntry
+}
+
 // MemoryCache is an in-memory cache implementation
 type MemoryCache struct {
-	entries         map[string]*Entry
-	mu              sync.RWMutex
-	config          *Config
-	stats           Stats
-	statsMu         sync.RWMutex
-	stopCleanup     chan struct{}
-	cleanupDone     chan struct{}
+	shards      [memoryShards]memoryShard
+	config      *Config
+	size        atomic.Int64
+	maxSize     atomic.Int64 // config.MaxSize until SetMaxSize changes it
+	evictMu     sync.Mutex   // serializes eviction passes
+	stopCleanup chan struct{}
+	cleanupDone chan struct{}
+
+	hits      atomic.Int64
+	misses    atomic.Int64
+	evictions atomic.Int64
+	getNanos  atomic.Int64
+	sets      atomic.Int64
+	setNanos  atomic.Int64
 }
 
 // NewMemoryCache creates a new in-memory cache
 func NewMemoryCache(config *Config) *MemoryCache {
 	mc := &MemoryCache{
-		entries:     make(map[string]*Entry),
 		config:      config,
 		stopCleanup: make(chan struct{}),
 		cleanupDone: make(chan struct{}),
 	}
+	for i := range mc.shards {
+		mc.shards[i].entries = make(map[string]*memoryEntry)
+	}
+	mc.maxSize.Store(config.MaxSize)
 
 	// Start cleanup goroutine
 	go mc.cleanupLoop()
@@ -32,108 +72,113 @@ func NewMemoryCache(config *Config) *MemoryCache {
 	return mc
 }
 
+// shard returns the shard that holds key
+func (m *MemoryCache) shard(key string) *memoryShard {
+	h := fnv.New32a()
+	h.Write([]byte(key))
+	return &m.shards[h.Sum32()%memoryShards]
+}
+
 // Get retrieves a value from cache
 func (m *MemoryCache) Get(ctx context.Context, key string) ([]byte, bool, error) {
 	start := time.Now()
 	defer func() {
-		m.recordGetTime(time.Since(start))
+		m.getNanos.Add(int64(time.Since(start)))
 	}()
 
-	m.mu.RLock()
-	entry, exists := m.entries[key]
-	m.mu.RUnlock()
+	shard := m.shard(key)
+	shard.mu.RLock()
+	entry, exists := shard.entries[key]
+	shard.mu.RUnlock()
 
 	if !exists {
-		m.recordMiss()
+		m.misses.Add(1)
 		return nil, false, nil
 	}
 
 	// Check if expired
-	if entry.IsExpired() {
-		m.mu.Lock()
-		delete(m.entries, key)
-		m.mu.Unlock()
-		m.recordMiss()
+	if entry.expired(start) {
+		m.remove(shard, key, entry)
+		m.misses.Add(1)
 		return nil, false, nil
 	}
 
 	// Update access time and hit count
-	m.mu.Lock()
-	entry.AccessedAt = time.Now()
-	entry.HitCount++
-	m.mu.Unlock()
+	entry.accessedAt.Store(start.UnixNano())
+	entry.hits.Add(1)
 
-	m.recordHit()
-	return entry.Value, true, nil
+	m.hits.Add(1)
+	return entry.value, true, nil
 }
 
 // Set stores a value in cache with TTL
 func (m *MemoryCache) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
 	start := time.Now()
 	defer func() {
-		m.recordSetTime(time.Since(start))
+		m.sets.Add(1)
+		m.setNanos.Add(int64(time.Since(start)))
 	}()
 
 	if ttl == 0 {
 		ttl = m.config.DefaultTTL
 	}
 
-	now := time.Now()
-	entry := &Entry{
-		Key:        key,
-		Value:      value,
-		Size:       int64(len(value)),
-		CreatedAt:  now,
-		ExpiresAt:  now.Add(ttl),
-		AccessedAt: now,
-		HitCount:   0,
+	entry := &memoryEntry{
+		value:     value,
+		size:      int64(len(value)),
+		expiresAt: start.Add(ttl),
 	}
+	entry.accessedAt.Store(start.UnixNano())
 
-	m.mu.Lock()
-	defer m.mu.Unlock()
+	shard := m.shard(key)
+	shard.mu.Lock()
+	if old, exists := shard.entries[key]; exists {
+		m.size.Add(-old.size)
+	}
+	shard.entries[key] = entry
+	m.size.Add(entry.size)
+	shard.mu.Unlock()
 
 	// Check if we need to evict entries
-	newSize := m.calculateSizeWithoutLock() + entry.Size
-	if m.config.MaxSize > 0 && newSize > m.config.MaxSize {
-		m.evictLRUWithoutLock(entry.Size)
+	if maxSize := m.maxSize.Load(); maxSize > 0 && m.size.Load() > maxSize {
+		m.evictLRU()
 	}
 
-	m.entries[key] = entry
 	return nil
 }
 
 // Delete removes a value from cache
 func (m *MemoryCache) Delete(ctx context.Context, key string) error {
-	m.mu.Lock()
-	defer m.mu.Unlock()
+	shard := m.shard(key)
+	shard.mu.Lock()
+	defer shard.mu.Unlock()
 
-	delete(m.entries, key)
+	if entry, exists := shard.entries[key]; exists {
+		delete(shard.entries, key)
+		m.size.Add(-entry.size)
+	}
 	return nil
 }
 
 // Clear removes all values from cache
 func (m *MemoryCache) Clear(ctx context.Context) error {
-	m.mu.Lock()
-	defer m.mu.Unlock()
-
-	m.entries = make(map[string]*Entry)
+	m.deleteMatching(func(string, *memoryEntry) bool { return true })
 	return nil
 }
 
 // Has checks if a key exists in cache
 func (m *MemoryCache) Has(ctx context.Context, key string) (bool, error) {
-	m.mu.RLock()
-	entry, exists := m.entries[key]
-	m.mu.RUnlock()
+	shard := m.shard(key)
+	shard.mu.RLock()
+	entry, exists := shard.entries[key]
+	shard.mu.RUnlock()
 
 	if !exists {
 		return false, nil
 	}
 
-	if entry.IsExpired() {
-		m.mu.Lock()
-		delete(m.entries, key)
-		m.mu.Unlock()
+	if entry.expired(time.Now()) {
+		m.remove(shard, key, entry)
 		return false, nil
 	}
 
@@ -142,25 +187,48 @@ func (m *MemoryCache) Has(ctx context.Context, key string) (bool, error) {
 
 // Stats returns cache statistics
 func (m *MemoryCache) Stats(ctx context.Context) (*Stats, error) {
-	m.statsMu.RLock()
-	defer m.statsMu.RUnlock()
-
-	m.mu.RLock()
-	keys := int64(len(m.entries))
-	sizeBytes := m.calculateSizeWithoutLock()
-	m.mu.RUnlock()
+	var keys int64
+	for i := range m.shards {
+		shard := &m.shards[i]
+		shard.mu.RLock()
+		keys += int64(len(shard.entries))
+		shard.mu.RUnlock()
+	}
 
-	stats := m.stats
-	stats.Keys = keys
-	stats.SizeBytes = sizeBytes
+	stats := &Stats{
+		Hits:      m.hits.Load(),
+		Misses:    m.misses.Load(),
+		Keys:      keys,
+		SizeBytes: m.size.Load(),
+		Evictions: m.evictions.Load(),
+	}
 
 	// Calculate hit rate
 	total := stats.Hits + stats.Misses
 	if total > 0 {
 		stats.HitRate = float64(stats.Hits) / float64(total)
+		stats.AvgGetTimeMs = float64(m.getNanos.Load()) / float64(total) / float64(time.Millisecond)
+	}
+	if sets := m.sets.Load(); sets > 0 {
+		stats.AvgSetTimeMs = float64(m.setNanos.Load()) / float64(sets) / float64(time.Millisecond)
 	}
 
-	return &stats, nil
+	return stats, nil
+}
+
+// MaxSize returns how many bytes the cache may hold, 0 for no limit
+func (m *MemoryCache) MaxSize() int64 {
+	return m.maxSize.Load()
+}
+
+// SetMaxSize changes how many bytes the cache may hold. Shrinking it evicts
+// the least recently used entries at once, so the memory is freed before the
+// next Set.
+func (m *MemoryCache) SetMaxSize(size int64) {
+	m.maxSize.Store(size)
+	if size > 0 && m.size.Load() > size {
+		m.evictLRU()
+	}
 }
 
 // Close closes the cache and stops cleanup
@@ -170,6 +238,41 @@ func (m *MemoryCache) Close() error {
 	return nil
 }
 
+// deletePrefix removes every key starting with prefix
+func (m *MemoryCache) deletePrefix(prefix string) {
+	m.deleteMatching(func(key string, _ *memoryEntry) bool {
+		return strings.HasPrefix(key, prefix)
+	})
+}
+
+// deleteMatching removes the entries match selects, one shard at a time
+func (m *MemoryCache) deleteMatching(match func(key string, entry *memoryEntry) bool) {
+	for i := range m.shards {
+		shard := &m.shards[i]
+		shard.mu.Lock()
+		for key, entry := range shard.entries {
+			if match(key, entry) {
+				delete(shard.entries, key)
+				m.size.Add(-entry.size)
+			}
+		}
+		shard.mu.Unlock()
+	}
+}
+
+// remove deletes key if it still maps to entry, so a concurrent Set isn't undone
+func (m *MemoryCache) remove(shard *memoryShard, key string, entry *memoryEntry) bool {
+	shard.mu.Lock()
+	defer shard.mu.Unlock()
+
+	if shard.entries[key] != entry {
+		return false
+	}
+	delete(shard.entries, key)
+	m.size.Add(-entry.size)
+	return true
+}
+
 // cleanupLoop periodically removes expired entries
 func (m *MemoryCache) cleanupLoop() {
 	ticker := time.NewTicker(m.config.CleanupInterval)
@@ -188,108 +291,53 @@ func (m *MemoryCache) cleanupLoop() {
 
 // cleanup removes expired entries
 func (m *MemoryCache) cleanup() {
-	m.mu.Lock()
-	defer m.mu.Unlock()
-
 	now := time.Now()
-	for key, entry := range m.entries {
-		if now.After(entry.ExpiresAt) {
-			delete(m.entries, key)
-		}
-	}
+	m.deleteMatching(func(_ string, entry *memoryEntry) bool {
+		return entry.expired(now)
+	})
 }
 
-// evictLRUWithoutLock evicts least recently used entries to make room
-// Must be called with m.mu locked
-func (m *MemoryCache) evictLRUWithoutLock(needed int64) {
-	// Find least recently used entries
-	type entryWithKey struct {
-		key   string
-		entry *Entry
+// evictLRU evicts least recently used entries until the cache fits MaxSize.
+// Shards are snapshotted under their read locks, so readers keep going while
+// the candidates are sorted.
+func (m *MemoryCache) evictLRU() {
+	m.evictMu.Lock()
+	defer m.evictMu.Unlock()
+
+	// Another Set may already have made room
+	maxSize := m.maxSize.Load()
+	if m.size.Load() <= maxSize {
+		return
 	}
 
-	var entries []entryWithKey
-	for key, entry := range m.entries {
-		entries = append(entries, entryWithKey{key, entry})
+	type candidate struct {
+		shard      *memoryShard
+		key        string
+		entry      *memoryEntry
+		accessedAt int64
 	}
 
-	// Sort by access time (oldest first)
-	for i := 0; i < len(entries)-1; i++ {
-		for j := i + 1; j < len(entries); j++ {
-			if entries[i].entry.AccessedAt.After(entries[j].entry.AccessedAt) {
-				entries[i], entries[j] = entries[j], entries[i]
-			}
+	var candidates []candidate
+	for i := range m.shards {
+		shard := &m.shards[i]
+		shard.mu.RLock()
+		for key, entry := range shard.entries {
+			candidates = append(candidates, candidate{shard, key, entry, entry.accessedAt.Load()})
 		}
+		shard.mu.RUnlock()
 	}
 
-	// Evict until we have enough space
-	freed := int64(0)
-	evicted := 0
-	for _, e := range entries {
-		if freed >= needed {
+	// Sort by access time (oldest first)
+	sort.Slice(candidates, func(i, j int) bool {
+		return candidates[i].accessedAt < candidates[j].accessedAt
+	})
+
+	for _, c := range candidates {
+		if m.size.Load() <= maxSize {
 			break
 		}
-		freed += e.entry.Size
-		delete(m.entries, e.key)
-		evicted++
-	}
-
-	m.statsMu.Lock()
-	m.stats.Evictions += int64(evicted)
-	m.statsMu.Unlock()
-}
-
-// calculateSizeWithoutLock calculates total cache size
-// Must be called with m.mu locked (read or write)
-func (m *MemoryCache) calculateSizeWithoutLock() int64 {
-	var size int64
-	for _, entry := range m.entries {
-		size += entry.Size
-	}
-	return size
-}
-
-// recordHit increments hit counter
-func (m *MemoryCache) recordHit() {
-	m.statsMu.Lock()
-	m.stats.Hits++
-	m.statsMu.Unlock()
-}
-
-// recordMiss increments miss counter
-func (m *MemoryCache) recordMiss() {
-	m.statsMu.Lock()
-	m.stats.Misses++
-	m.statsMu.Unlock()
-}
-
-// recordGetTime records get operation time
-func (m *MemoryCache) recordGetTime(duration time.Duration) {
-	m.statsMu.Lock()
-	defer m.statsMu.Unlock()
-
-	ms := float64(duration.Microseconds()) / 1000.0
-	total := m.stats.Hits + m.stats.Misses
-
-	if total == 0 {
-		m.stats.AvgGetTimeMs = ms
-	} else {
-		// Running average
-		m.stats.AvgGetTimeMs = (m.stats.AvgGetTimeMs*float64(total-1) + ms) / float64(total)
-	}
-}
-
-// recordSetTime records set operation time
-func (m *MemoryCache) recordSetTime(duration time.Duration) {
-	m.statsMu.Lock()
-	defer m.statsMu.Unlock()
-
-	ms := float64(duration.Microseconds()) / 1000.0
-
-	if m.stats.AvgSetTimeMs == 0 {
-		m.stats.AvgSetTimeMs = ms
-	} else {
-		// Simple running average (approximate)
-		m.stats.AvgSetTimeMs = (m.stats.AvgSetTimeMs + ms) / 2.0
+		if m.remove(c.shard, c.key, c.entry) {
+			m.evictions.Add(1)
+		}
 	}
 }
@@ -0,0 +1,90 @@
+package cache
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// WarmFunc renders one protocol's hot pages into the cache via the warmer
+type WarmFunc func(ctx context.Context, w *Warmer) error
+
+// Warmer handles cache warming (pre-populating cache)
+type Warmer struct {
+	cache   Cache
+	sources map[string]WarmFunc
+	order   []string
+	running sync.Mutex // Serializes warm runs so storms don't stack them
+}
+
+// NewWarmer creates a new cache warmer
+func NewWarmer(cache Cache) *Warmer {
+	return &Warmer{
+		cache:   cache,
+		sources: make(map[string]WarmFunc),
+	}
+}
+
+// WarmGopherHome pre-populates the Gopher home page
+func (w *Warmer) WarmGopherHome(ctx context.Context, content []byte, ttl time.Duration) error {
+	key := GopherKey("/")
+	return w.cache.Set(ctx, key, content, ttl)
+}
+
+// WarmGeminiHome pre-populates the Gemini home page
+func (w *Warmer) WarmGeminiHome(ctx context.Context, content []byte, ttl time.Duration) error {
+	key := GeminiKey("/", "")
+	return w.cache.Set(ctx, key, content, ttl)
+}
+
+// WarmProfile pre-populates a profile
+func (w *Warmer) WarmProfile(ctx context.Context, pubkey string, protocol string, content []byte, ttl time.Duration) error {
+	key := ProfileKey(pubkey, protocol)
+	return w.cache.Set(ctx, key, content, ttl)
+}
+
+// WarmGopher pre-populates a Gopher selector
+func (w *Warmer) WarmGopher(ctx context.Context, selector string, content []byte, ttl time.Duration) error {
+	return w.cache.Set(ctx, GopherKey(selector), content, ttl)
+}
+
+// WarmGemini pre-populates a Gemini path
+func (w *Warmer) WarmGemini(ctx context.Context, path string, content []byte, ttl time.Duration) error {
+	return w.cache.Set(ctx, GeminiKey(path, ""), content, ttl)
+}
+
+// WarmFinger pre-populates a Finger query response
+func (w *Warmer) WarmFinger(ctx context.Context, username string, content []byte, ttl time.Duration) error {
+	return w.cache.Set(ctx, FingerKey(username), content, ttl)
+}
+
+// Register adds a source of hot pages, replacing any source with the same name
+func (w *Warmer) Register(name string, fn WarmFunc) {
+	w.running.Lock()
+	defer w.running.Unlock()
+
+	if _, exists := w.sources[name]; !exists {
+		w.order = append(w.order, name)
+	}
+	w.sources[name] = fn
+}
+
+// WarmAll runs every registered source in registration order.
+// A failing source doesn't stop the others; their errors are combined.
+func (w *Warmer) WarmAll(ctx context.Context) error {
+	w.running.Lock()
+	defer w.running.Unlock()
+
+	var failed []string
+	for _, name := range w.order {
+		if err := w.sources[name](ctx, w); err != nil {
+			failed = append(failed, fmt.Sprintf("%s: %v", name, err))
+		}
+	}
+
+	if len(failed) > 0 {
+		return fmt.Errorf("failed to warm cache: %v", failed)
+	}
+	return nil
+}
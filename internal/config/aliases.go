@@ -0,0 +1,105 @@
+package config
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// AliasTargetOwner points an alias at the site owner
+const AliasTargetOwner = "owner"
+
+// builtinAliasTargets are the listings every protocol serves without section config
+var builtinAliasTargets = []string{"notes", "articles", "replies", "mentions"}
+
+// reservedAliasNames are finger usernames with a fixed meaning
+var reservedAliasNames = []string{"owner", "status", "mutuals"}
+
+var aliasNamePattern = regexp.MustCompile(`^[a-z0-9._-]+$`)
+
+// Alias maps a short name to the owner or to a content listing, so
+// `finger blog@host` or the Gopher selector /~blog can stand in for a longer path
+type Alias struct {
+	Name   string `yaml:"name"`
+	Target string `yaml:"target"` // "owner" (default), notes, articles, replies, mentions, or a section name
+}
+
+// IsOwner returns true if the alias points at the owner rather than a listing
+func (a *Alias) IsOwner() bool {
+	return a.Target == "" || a.Target == AliasTargetOwner
+}
+
+// IsBuiltin returns true if the alias points at one of the built-in listings
+func (a *Alias) IsBuiltin() bool {
+	for _, target := range builtinAliasTargets {
+		if a.Target == target {
+			return true
+		}
+	}
+	return false
+}
+
+// ResolveAlias looks up an alias by name, ignoring case
+func (c *Config) ResolveAlias(name string) (*Alias, bool) {
+	name = strings.ToLower(name)
+	for i := range c.Aliases {
+		if c.Aliases[i].Name == name {
+			return &c.Aliases[i], true
+		}
+	}
+	return nil, false
+}
+
+// AliasSelector returns the path an alias stands for in Gopher and Gemini.
+// Owner aliases map to the owner's notes.
+func (c *Config) AliasSelector(alias *Alias) string {
+	if alias.IsOwner() {
+		return "/notes"
+	}
+	if alias.IsBuiltin() {
+		return "/" + alias.Target
+	}
+	for _, section := range c.Sections {
+		if section.Name == alias.Target {
+			return section.Path
+		}
+	}
+	return "/"
+}
+
+// validateAliases checks alias names are usable as finger usernames and targets exist
+func validateAliases(cfg *Config) error {
+	seen := make(map[string]bool)
+
+	for _, alias := range cfg.Aliases {
+		if !aliasNamePattern.MatchString(alias.Name) {
+			return fmt.Errorf("alias name %q must be lowercase letters, digits, '.', '_' or '-'", alias.Name)
+		}
+		for _, reserved := range reservedAliasNames {
+			if alias.Name == reserved {
+				return fmt.Errorf("alias name %q is reserved", alias.Name)
+			}
+		}
+		if seen[alias.Name] {
+			return fmt.Errorf("duplicate alias name: %s", alias.Name)
+		}
+		seen[alias.Name] = true
+
+		if alias.IsOwner() || alias.IsBuiltin() {
+			continue
+		}
+
+		found := false
+		for _, section := range cfg.Sections {
+			if section.Name == alias.Target {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return fmt.Errorf("alias %q targets unknown section: %s", alias.Name, alias.Target)
+		}
+	}
+
+	return nil
+}
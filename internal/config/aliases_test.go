@@ -0,0 +1,70 @@
+package config
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestResolveAlias(t *testing.T) {
+	cfg := &Config{
+		Sections: []SectionConfig{{Name: "diy", Path: "/diy"}},
+		Aliases: []Alias{
+			{Name: "me"},
+			{Name: "blog", Target: "articles"},
+			{Name: "projects", Target: "diy"},
+		},
+	}
+
+	if err := validateAliases(cfg); err != nil {
+		t.Fatalf("valid aliases rejected: %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		selector string
+	}{
+		{"me", "/notes"},
+		{"BLOG", "/articles"},
+		{"projects", "/diy"},
+	}
+
+	for _, tt := range tests {
+		alias, ok := cfg.ResolveAlias(tt.name)
+		if !ok {
+			t.Errorf("alias %q not resolved", tt.name)
+			continue
+		}
+		if got := cfg.AliasSelector(alias); got != tt.selector {
+			t.Errorf("alias %q: expected selector %s, got %s", tt.name, tt.selector, got)
+		}
+	}
+
+	if _, ok := cfg.ResolveAlias("unknown"); ok {
+		t.Error("unknown alias should not resolve")
+	}
+}
+
+func TestValidateAliases(t *testing.T) {
+	tests := []struct {
+		name    string
+		aliases []Alias
+		errMsg  string
+	}{
+		{"reserved name", []Alias{{Name: "status"}}, "reserved"},
+		{"invalid name", []Alias{{Name: "Alice Smith"}}, "must be lowercase"},
+		{"duplicate name", []Alias{{Name: "me"}, {Name: "me", Target: "notes"}}, "duplicate"},
+		{"unknown section", []Alias{{Name: "blog", Target: "missing"}}, "unknown section"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateAliases(&Config{Aliases: tt.aliases})
+			if err == nil {
+				t.Fatalf("expected error containing %q", tt.errMsg)
+			}
+			if !strings.Contains(err.Error(), tt.errMsg) {
+				t.Errorf("expected error containing %q, got %v", tt.errMsg, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,76 @@
+package config
+
+import (
+	"fmt"
+
+	"github.com/nbd-wtf/go-nostr"
+	"github.com/nbd-wtf/go-nostr/nip19"
+)
+
+// AuthorOverride changes how one author's events are listed and rendered
+type AuthorOverride struct {
+	Pubkey        string `yaml:"pubkey"`         // npub or hex
+	Collapse      bool   `yaml:"collapse"`       // Show their replies in threads as one-line summaries
+	HideReactions bool   `yaml:"hide_reactions"` // Leave their reactions out of the inbox
+	Pin           bool   `yaml:"pin"`            // List their posts first on each page of the mentions view
+}
+
+// AuthorOverrides are rendering.authors keyed by hex pubkey. A nil map has
+// no overrides.
+type AuthorOverrides map[string]AuthorOverride
+
+// AuthorOverrides returns rendering.authors keyed by hex pubkey, skipping
+// entries whose pubkey doesn't decode
+func (r *Rendering) AuthorOverrides() AuthorOverrides {
+	overrides := make(AuthorOverrides, len(r.Authors))
+	for _, override := range r.Authors {
+		if pubkey, ok := authorHex(override.Pubkey); ok {
+			overrides[pubkey] = override
+		}
+	}
+	return overrides
+}
+
+// Collapsed reports whether an author's replies are shown as summaries
+func (o AuthorOverrides) Collapsed(pubkey string) bool {
+	return o[pubkey].Collapse
+}
+
+// HidesReactions reports whether an author's reactions are left out of the inbox
+func (o AuthorOverrides) HidesReactions(pubkey string) bool {
+	return o[pubkey].HideReactions
+}
+
+// Pinned reports whether an author's posts are listed first among mentions
+func (o AuthorOverrides) Pinned(pubkey string) bool {
+	return o[pubkey].Pin
+}
+
+// authorHex decodes an npub or checks a hex pubkey
+func authorHex(pubkey string) (string, bool) {
+	if nostr.IsValidPublicKey(pubkey) {
+		return pubkey, true
+	}
+	if prefix, value, err := nip19.Decode(pubkey); err == nil && prefix == "npub" {
+		if hex, ok := value.(string); ok {
+			return hex, true
+		}
+	}
+	return "", false
+}
+
+// validateAuthorOverrides checks every override names a valid pubkey, once
+func validateAuthorOverrides(r *Rendering) error {
+	seen := make(map[string]bool)
+	for _, override := range r.Authors {
+		pubkey, ok := authorHex(override.Pubkey)
+		if !ok {
+			return fmt.Errorf("rendering.authors: %q must be an npub or hex pubkey", override.Pubkey)
+		}
+		if seen[pubkey] {
+			return fmt.Errorf("rendering.authors: duplicate entry for %s", override.Pubkey)
+		}
+		seen[pubkey] = true
+	}
+	return nil
+}
@@ -0,0 +1,60 @@
+package config
+
+import (
+	"fmt"
+	"net/url"
+)
+
+// Bridge configures syndicating the owner's RSS and Atom feeds into Nostr:
+// new feed items are published as notes or articles signed with NOPHR_NSEC
+type Bridge struct {
+	Enabled         bool         `yaml:"enabled"`
+	IntervalMinutes int          `yaml:"interval_minutes"` // How often the feeds are polled
+	Feeds           []BridgeFeed `yaml:"feeds"`
+}
+
+// BridgeFeed is one feed bridged into Nostr
+type BridgeFeed struct {
+	URL      string `yaml:"url"`
+	Kind     int    `yaml:"kind"`     // 1 announces each item in a note, 30023 republishes it as an article
+	Backfill bool   `yaml:"backfill"` // Publish the items already in the feed on the first poll, not only newer ones
+}
+
+// DefaultBridge returns the default bridge settings
+func DefaultBridge() Bridge {
+	return Bridge{
+		Enabled:         false,
+		IntervalMinutes: 60,
+	}
+}
+
+// Validate checks if the bridge settings are valid
+func (b *Bridge) Validate() error {
+	if !b.Enabled {
+		return nil
+	}
+
+	if b.IntervalMinutes < 5 {
+		return fmt.Errorf("outbox.bridge.interval_minutes must be at least 5")
+	}
+	if len(b.Feeds) == 0 {
+		return fmt.Errorf("outbox.bridge.feeds must list at least one feed when the bridge is enabled")
+	}
+
+	seen := make(map[string]bool, len(b.Feeds))
+	for i, feed := range b.Feeds {
+		u, err := url.Parse(feed.URL)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return fmt.Errorf("outbox.bridge.feeds[%d].url must be an http or https URL", i)
+		}
+		if seen[feed.URL] {
+			return fmt.Errorf("outbox.bridge.feeds[%d].url is listed twice", i)
+		}
+		seen[feed.URL] = true
+		if feed.Kind != 1 && feed.Kind != 30023 {
+			return fmt.Errorf("outbox.bridge.feeds[%d].kind must be 1 or 30023", i)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,29 @@
+package config
+
+import "testing"
+
+func TestBridgeValidate(t *testing.T) {
+	blog := BridgeFeed{URL: "https://blog.example.com/feed.xml", Kind: 30023}
+
+	tests := []struct {
+		name    string
+		bridge  Bridge
+		wantErr bool
+	}{
+		{"disabled ignores fields", Bridge{Enabled: false}, false},
+		{"one feed", Bridge{Enabled: true, IntervalMinutes: 60, Feeds: []BridgeFeed{blog}}, false},
+		{"no feeds", Bridge{Enabled: true, IntervalMinutes: 60}, true},
+		{"polled too often", Bridge{Enabled: true, IntervalMinutes: 1, Feeds: []BridgeFeed{blog}}, true},
+		{"not http", Bridge{Enabled: true, IntervalMinutes: 60, Feeds: []BridgeFeed{{URL: "gopher://example.com/", Kind: 1}}}, true},
+		{"unsupported kind", Bridge{Enabled: true, IntervalMinutes: 60, Feeds: []BridgeFeed{{URL: blog.URL, Kind: 6}}}, true},
+		{"listed twice", Bridge{Enabled: true, IntervalMinutes: 60, Feeds: []BridgeFeed{blog, blog}}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.bridge.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
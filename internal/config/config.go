@@ -4,6 +4,7 @@ import (
 	"embed"
 	"fmt"
 	"os"
+	"reflect"
 	"strings"
 
 	"gopkg.in/yaml.v3"
@@ -14,37 +15,66 @@ var exampleConfig embed.FS
 
 // Config represents the complete nophr configuration
 type Config struct {
-	Site       Site       `yaml:"site"`
-	Identity   Identity   `yaml:"identity"`
-	Protocols  Protocols  `yaml:"protocols"`
-	Relays     Relays     `yaml:"relays"`
-	Discovery  Discovery  `yaml:"discovery"`
-	Sync       Sync       `yaml:"sync"`
-	Inbox      Inbox      `yaml:"inbox"`
-	Outbox     Outbox     `yaml:"outbox"`
-	Storage    Storage    `yaml:"storage"`
-	Rendering     Rendering     `yaml:"rendering"`
-	Caching       Caching       `yaml:"caching"`
-	Logging       Logging       `yaml:"logging"`
-	Layout        Layout        `yaml:"layout"`
-	Display       Display       `yaml:"display"`
-	Presentation  Presentation  `yaml:"presentation"`
-	Behavior      Behavior      `yaml:"behavior"`
-	Sections      []SectionConfig `yaml:"sections"`
+	Site         Site               `yaml:"site"`
+	Identity     Identity           `yaml:"identity"`
+	Protocols    Protocols          `yaml:"protocols"`
+	Relays       Relays             `yaml:"relays"`
+	Discovery    Discovery          `yaml:"discovery"`
+	Sync         Sync               `yaml:"sync"`
+	Inbox        Inbox              `yaml:"inbox"`
+	Outbox       Outbox             `yaml:"outbox"`
+	Storage      Storage            `yaml:"storage"`
+	Rendering    Rendering          `yaml:"rendering"`
+	Caching      Caching            `yaml:"caching"`
+	Logging      Logging            `yaml:"logging"`
+	Layout       Layout             `yaml:"layout"`
+	Display      Display            `yaml:"display"`
+	Presentation Presentation       `yaml:"presentation"`
+	Behavior     Behavior           `yaml:"behavior"`
+	Security     Security           `yaml:"security"`
+	Idle         Idle               `yaml:"idle"`
+	Sections     []SectionConfig    `yaml:"sections"`
+	Aliases      []Alias            `yaml:"aliases"`
+	Pages        []Page             `yaml:"pages"`
+	PagesDir     string             `yaml:"pages_dir"` // Markdown and gemtext files served as pages named after the file
+	Profiles     map[string]Profile `yaml:"profiles"`  // Display and behavior settings for listeners that name them
 }
 
 // Site contains site metadata
 type Site struct {
-	Title       string `yaml:"title"`
-	Description string `yaml:"description"`
-	Operator    string `yaml:"operator"`
+	Title       string  `yaml:"title"`
+	Description string  `yaml:"description"`
+	Operator    string  `yaml:"operator"`
+	AdminEmail  string  `yaml:"admin_email"` // Optional contact address, advertised in Gopher caps.txt and /about
+	Author      string  `yaml:"author"`      // Canonical attribution for the owner's posts, defaults to operator
+	AuthorURL   string  `yaml:"author_url"`  // Optional link for the attribution
+	License     License `yaml:"license"`     // Content license appended to the owner's posts and feeds
+}
+
+// License names the license the owner's posts are published under
+type License struct {
+	Name string `yaml:"name"` // e.g. "CC BY 4.0"
+	URL  string `yaml:"url"`  // Canonical license text
+}
+
+// AuthorName returns the name posts are attributed to: author, or operator if unset
+func (s Site) AuthorName() string {
+	if s.Author != "" {
+		return s.Author
+	}
+	return s.Operator
+}
+
+// HasAttribution reports whether detail pages carry an attribution footer,
+// which needs an explicit author or license
+func (s Site) HasAttribution() bool {
+	return s.Author != "" || s.License.Name != ""
 }
 
 // Identity contains Nostr identity information
 type Identity struct {
 	Npub string `yaml:"npub"` // Public key from file
-	// Note: Nsec removed - not needed since Publisher (Phase 13) is not implemented
-	// If Publisher is implemented in the future, add: Nsec string `yaml:"-"` and load from NOPHR_NSEC env var
+	Nsec string `yaml:"-"`    // Secret key for publishing, only ever loaded from NOPHR_NSEC
 }
 
 // Protocols contains protocol server configurations
@@ -52,23 +82,57 @@ type Protocols struct {
 	Gopher GopherProtocol `yaml:"gopher"`
 	Gemini GeminiProtocol `yaml:"gemini"`
 	Finger FingerProtocol `yaml:"finger"`
+	NNTP   NNTPProtocol   `yaml:"nntp"`
+	Telnet TelnetProtocol `yaml:"telnet"`
+	QOTD   QOTDProtocol   `yaml:"qotd"`
+	Relay  RelayProtocol  `yaml:"relay"`
 }
 
 // GopherProtocol contains Gopher server settings
 type GopherProtocol struct {
-	Enabled bool   `yaml:"enabled"`
-	Host    string `yaml:"host"`
-	Port    int    `yaml:"port"`
-	Bind    string `yaml:"bind"`
+	Enabled    bool       `yaml:"enabled"`
+	Host       string     `yaml:"host"`
+	Port       int        `yaml:"port"`
+	Bind       string     `yaml:"bind"`
+	GopherPlus bool       `yaml:"gopher_plus"` // Mark items as Gopher+ and answer +INFO/+ADMIN/+VIEWS requests
+	Profile    string     `yaml:"profile"`     // Name in profiles, or empty for the top-level settings
+	Listeners  []Listener `yaml:"listeners"`   // More addresses, each with its own profile
 }
 
 // GeminiProtocol contains Gemini server settings
 type GeminiProtocol struct {
-	Enabled bool      `yaml:"enabled"`
-	Host    string    `yaml:"host"`
-	Port    int       `yaml:"port"`
-	Bind    string    `yaml:"bind"`
-	TLS     GeminiTLS `yaml:"tls"`
+	Enabled   bool         `yaml:"enabled"`
+	Host      string       `yaml:"host"`
+	Port      int          `yaml:"port"`
+	Bind      string       `yaml:"bind"`
+	TLS       GeminiTLS    `yaml:"tls"`
+	Access    GeminiAccess `yaml:"access"`    // Client certificate protected paths
+	Titan     GeminiTitan  `yaml:"titan"`     // Uploads for publishing notes
+	Wallet    GeminiWallet `yaml:"wallet"`    // Nostr Wallet Connect admin page
+	Profile   string       `yaml:"profile"`   // Name in profiles, or empty for the top-level settings
+	Listeners []Listener   `yaml:"listeners"` // More addresses, each with its own profile; TLS, Titan and wallet are shared
+}
+
+// GeminiTitan contains settings for Titan uploads on the Gemini listener.
+// Uploads to /publish are signed with NOPHR_NSEC and published as kind 1 notes;
+// they always require a client certificate with owner access.
+type GeminiTitan struct {
+	Enabled            bool `yaml:"enabled"`
+	MaxSize            int  `yaml:"max_size"`             // Largest accepted upload in bytes
+	RequireNonce       bool `yaml:"require_nonce"`        // Refuse uploads without nonce and ts parameters
+	NonceWindowSeconds int  `yaml:"nonce_window_seconds"` // How far ts may be from the server clock
+	UploadsPerHour     int  `yaml:"uploads_per_hour"`     // Uploads accepted per client certificate per hour
+}
+
+// GeminiWallet contains settings for the Nostr Wallet Connect (NIP-47) admin page.
+// The page at /wallet always requires a client certificate with owner access;
+// the connection URI is only ever loaded from NOPHR_NWC_URI.
+type GeminiWallet struct {
+	Enabled        bool   `yaml:"enabled"`
+	URI            string `yaml:"-"`                // nostr+walletconnect:// URI from NOPHR_NWC_URI
+	DefaultZapSats int    `yaml:"default_zap_sats"` // Amount suggested when zapping a note
+	MaxZapSats     int    `yaml:"max_zap_sats"`     // Largest zap the page will pay
+	TimeoutSeconds int    `yaml:"timeout_seconds"`  // How long to wait for the wallet to answer
 }
 
 // GeminiTLS contains TLS configuration for Gemini
@@ -86,6 +150,43 @@ type FingerProtocol struct {
 	MaxUsers int    `yaml:"max_users"`
 }
 
+// NNTPProtocol contains settings for the read-only NNTP (RFC 3977) server,
+// which serves listings and threads as newsgroups
+type NNTPProtocol struct {
+	Enabled     bool   `yaml:"enabled"`
+	Host        string `yaml:"host"` // Domain in Message-IDs and From addresses
+	Port        int    `yaml:"port"`
+	Bind        string `yaml:"bind"`
+	GroupPrefix string `yaml:"group_prefix"` // First component of every group name, e.g. "nophr" for nophr.notes
+	MaxArticles int    `yaml:"max_articles"` // Newest posts carried by each group
+}
+
+// TelnetProtocol contains settings for the line-mode telnet BBS, which presents
+// the Gopher menus as numbered choices
+type TelnetProtocol struct {
+	Enabled   bool   `yaml:"enabled"`
+	Port      int    `yaml:"port"`
+	Bind      string `yaml:"bind"`
+	PageLines int    `yaml:"page_lines"` // Lines shown before a --More-- prompt; negative turns paging off
+}
+
+// QOTDProtocol contains settings for the Quote of the Day listener (RFC 865),
+// which answers every TCP connection and UDP datagram with the daily digest
+type QOTDProtocol struct {
+	Enabled bool   `yaml:"enabled"`
+	Port    int    `yaml:"port"`
+	Bind    string `yaml:"bind"`
+}
+
+// RelayProtocol contains settings for the Nostr relay endpoint (NIP-01 over
+// WebSocket), which serves stored events to Nostr clients
+type RelayProtocol struct {
+	Enabled     bool   `yaml:"enabled"`
+	Port        int    `yaml:"port"`
+	Bind        string `yaml:"bind"`
+	AllowWrites bool   `yaml:"allow_writes"` // Accept EVENTs from authors in sync scope; off makes the relay read-only
+}
+
 // Relays contains relay configuration
 type Relays struct {
 	Seeds  []string    `yaml:"seeds"`
@@ -94,18 +195,30 @@ type Relays struct {
 
 // RelayPolicy contains relay connection policies
 type RelayPolicy struct {
-	ConnectTimeoutMs   int   `yaml:"connect_timeout_ms"`
-	MaxConcurrentSubs  int   `yaml:"max_concurrent_subs"`
-	BackoffMs          []int `yaml:"backoff_ms"`
+	ConnectTimeoutMs  int   `yaml:"connect_timeout_ms"`
+	MaxConcurrentSubs int   `yaml:"max_concurrent_subs"`
+	BackoffMs         []int `yaml:"backoff_ms"`
+	SlowThresholdMs   int   `yaml:"slow_threshold_ms"` // Connect or first-event latency a relay is slow over (-1 turns slow-relay warnings off)
+	SlowStrikes       int   `yaml:"slow_strikes"`      // Consecutive slow observations before a relay is flagged
 }
 
 // Discovery contains relay discovery settings
 type Discovery struct {
-	RefreshSeconds      int  `yaml:"refresh_seconds"`
-	UseOwnerHints       bool `yaml:"use_owner_hints"`
-	UseAuthorHints      bool `yaml:"use_author_hints"`
-	FallbackToSeeds     bool `yaml:"fallback_to_seeds"`
-	MaxRelaysPerAuthor  int  `yaml:"max_relays_per_author"`
+	RefreshSeconds     int            `yaml:"refresh_seconds"`
+	UseOwnerHints      bool           `yaml:"use_owner_hints"`
+	UseAuthorHints     bool           `yaml:"use_author_hints"`
+	FallbackToSeeds    bool           `yaml:"fallback_to_seeds"`
+	MaxRelaysPerAuthor int            `yaml:"max_relays_per_author"`
+	HintConfidence     HintConfidence `yaml:"hint_confidence"`
+}
+
+// HintConfidence weighs each source of relay hints when choosing the relays
+// to use for an author. A negative weight ignores that source.
+type HintConfidence struct {
+	RelayList float64 `yaml:"relay_list"` // Author's own kind 10002 list (default: 1.0)
+	NIP05     float64 `yaml:"nip05"`      // relays field of the author's NIP-05 document (default: 0.7)
+	Tags      float64 `yaml:"tags"`       // Per e/p/a tag naming a relay for the author, counting up to 3 (default: 0.3)
+	Min       float64 `yaml:"min"`        // Relays scoring below this are not used (default: 0.5)
 }
 
 // Sync contains synchronization settings
@@ -115,12 +228,16 @@ type Sync struct {
 	Scope       SyncScope       `yaml:"scope"`
 	Retention   Retention       `yaml:"retention"`
 	Performance SyncPerformance `yaml:"performance"`
+	Limits      IngestLimits    `yaml:"limits"`
 }
 
 // SyncPerformance contains performance tuning options
 type SyncPerformance struct {
-	Workers       int  `yaml:"workers"`        // Number of parallel event processing workers (default: 4)
-	UseNegentropy bool `yaml:"use_negentropy"` // Enable NIP-77 negentropy sync (default: true); always falls back to REQ if unsupported
+	Workers        int  `yaml:"workers"`          // Number of parallel event processing workers (default: 4)
+	UseNegentropy  bool `yaml:"use_negentropy"`   // Enable NIP-77 negentropy sync (default: true); always falls back to REQ if unsupported
+	RelayQueueSize int  `yaml:"relay_queue_size"` // Events buffered per relay before new ones are dropped (default: 1000)
+	BatchSize      int  `yaml:"batch_size"`       // Most events a worker stores in one transaction (default: 100)
+	BatchWindowMs  int  `yaml:"batch_window_ms"`  // How long a worker waits for a batch to fill (default: 50, -1 stores what is queued without waiting)
 }
 
 // SyncKinds defines granular control over which event kinds to sync
@@ -176,10 +293,12 @@ type SyncScope struct {
 	Mode                  string   `yaml:"mode"` // self|following|mutual|foaf
 	Depth                 int      `yaml:"depth"`
 	IncludeDirectMentions bool     `yaml:"include_direct_mentions"`
+	InboxRelays           []string `yaml:"inbox_relays"` // Where mentions of the owner are watched; empty uses their kind 10002 read relays
 	IncludeThreadsOfMine  bool     `yaml:"include_threads_of_mine"`
 	MaxAuthors            int      `yaml:"max_authors"`
 	AllowlistPubkeys      []string `yaml:"allowlist_pubkeys"`
 	DenylistPubkeys       []string `yaml:"denylist_pubkeys"`
+	PruneUnfollowedHours  int      `yaml:"prune_unfollowed_hours"` // 0 = keep events from unfollowed authors
 }
 
 // Retention defines data retention policies
@@ -187,30 +306,33 @@ type Retention struct {
 	KeepDays           int                `yaml:"keep_days"`
 	PruneOnStart       bool               `yaml:"prune_on_start"`
 	PruneIntervalHours int                `yaml:"prune_interval_hours"` // 0 = disabled, >0 = prune every N hours
+	TrashGraceDays     int                `yaml:"trash_grace_days"`     // Keep deleted events restorable for N days (-1 deletes immediately)
 	Advanced           *AdvancedRetention `yaml:"advanced,omitempty"`   // Phase 20: Advanced retention
 }
 
 // Inbox contains inbox aggregation settings
 type Inbox struct {
-	IncludeReplies    bool          `yaml:"include_replies"`
-	IncludeReactions  bool          `yaml:"include_reactions"`
-	IncludeZaps       bool          `yaml:"include_zaps"`
-	GroupByThread     bool          `yaml:"group_by_thread"`
-	CollapseReposts   bool          `yaml:"collapse_reposts"`
-	NoiseFilters      NoiseFilters  `yaml:"noise_filters"`
+	IncludeReplies   bool         `yaml:"include_replies"`
+	IncludeReactions bool         `yaml:"include_reactions"`
+	IncludeZaps      bool         `yaml:"include_zaps"`
+	GroupByThread    bool         `yaml:"group_by_thread"`
+	CollapseReposts  bool         `yaml:"collapse_reposts"`
+	NoiseFilters     NoiseFilters `yaml:"noise_filters"`
 }
 
 // NoiseFilters defines filtering rules for inbox
 type NoiseFilters struct {
-	MinZapSats            int      `yaml:"min_zap_sats"`
-	AllowedReactionChars  []string `yaml:"allowed_reaction_chars"`
+	MinZapSats           int      `yaml:"min_zap_sats"`
+	AllowedReactionChars []string `yaml:"allowed_reaction_chars"`
 }
 
 // Outbox contains outbox/publishing settings
 type Outbox struct {
-	Publish   PublishSettings `yaml:"publish"`
-	DraftDir  string          `yaml:"draft_dir"`
-	AutoSign  bool            `yaml:"auto_sign"`
+	Publish  PublishSettings `yaml:"publish"`
+	DraftDir string          `yaml:"draft_dir"`
+	AutoSign bool            `yaml:"auto_sign"`
+	Digest   Digest          `yaml:"digest"`
+	Bridge   Bridge          `yaml:"bridge"` // RSS and Atom feeds syndicated into Nostr
 }
 
 // PublishSettings defines what to publish
@@ -226,13 +348,32 @@ type Storage struct {
 	SQLitePath    string `yaml:"sqlite_path"`
 	LMDBPath      string `yaml:"lmdb_path"`
 	LMDBMaxSizeMB int    `yaml:"lmdb_max_size_mb"`
+
+	Annotations Annotations `yaml:"annotations"`
+}
+
+// Annotations configures how notes and articles are classified as they are
+// stored. Language and hashtags are always recorded.
+type Annotations struct {
+	// Topics maps a topic name to keywords that place a note in it, matched
+	// as whole words or hashtags, e.g. bitcoin: [bitcoin, sats, lightning]
+	Topics map[string][]string `yaml:"topics"`
 }
 
 // Rendering contains protocol-specific rendering options
 type Rendering struct {
-	Gopher GopherRendering `yaml:"gopher"`
-	Gemini GeminiRendering `yaml:"gemini"`
-	Finger FingerRendering `yaml:"finger"`
+	Gopher      GopherRendering `yaml:"gopher"`
+	Gemini      GeminiRendering `yaml:"gemini"`
+	Finger      FingerRendering `yaml:"finger"`
+	MediaProxy  MediaProxy      `yaml:"media_proxy"`
+	Translation Translation     `yaml:"translation"`
+
+	// BlockedDomains lists domains whose links, including their subdomains',
+	// are removed from rendered notes and media link lists
+	BlockedDomains []string `yaml:"blocked_domains"`
+
+	// Authors overrides listing and rendering for specific authors
+	Authors []AuthorOverride `yaml:"authors"`
 }
 
 // GopherRendering contains Gopher rendering options
@@ -241,6 +382,7 @@ type GopherRendering struct {
 	ShowTimestamps bool   `yaml:"show_timestamps"`
 	DateFormat     string `yaml:"date_format"`
 	ThreadIndent   string `yaml:"thread_indent"`
+	MediaLinks     string `yaml:"media_links"` // items (type h menu lines) or text; text suits clients that mangle type h
 }
 
 // GeminiRendering contains Gemini rendering options
@@ -248,21 +390,41 @@ type GeminiRendering struct {
 	MaxLineLength  int    `yaml:"max_line_length"`
 	ShowTimestamps bool   `yaml:"show_timestamps"`
 	Emoji          bool   `yaml:"emoji"`
+	ThreadIndent   string `yaml:"thread_indent"`
+	MediaLinks     string `yaml:"media_links"` // items (=> link lines) or text
+}
+
+// Media link styles for rendering.<protocol>.media_links
+const (
+	MediaLinksItems = "items" // Selectable menu items or link lines
+	MediaLinksText  = "text"  // Plain text lines showing the URL
+)
+
+// MediaProxy downloads images, video and audio linked from events and
+// serves the local copies over Gopher and Gemini in place of the web links
+type MediaProxy struct {
+	Enabled        bool   `yaml:"enabled"`
+	Dir            string `yaml:"dir"`             // Where downloaded files are kept
+	MaxFileMB      int    `yaml:"max_file_mb"`     // Larger files stay web links
+	MaxTotalMB     int    `yaml:"max_total_mb"`    // The least recently fetched files are removed past this
+	TimeoutSeconds int    `yaml:"timeout_seconds"` // How long a download may take
 }
 
 // FingerRendering contains Finger rendering options
 type FingerRendering struct {
 	PlanSource       string `yaml:"plan_source"`
 	RecentNotesCount int    `yaml:"recent_notes_count"`
+	Width            int    `yaml:"width"`   // Wrap output at N characters
+	Project          string `yaml:"project"` // Owner's Project line; latest status (kind 30315) when empty
 }
 
 // Caching contains caching configuration
 type Caching struct {
-	Enabled    bool              `yaml:"enabled"`
-	Engine     string            `yaml:"engine"` // memory|redis
-	RedisURL   string            `yaml:"redis_url"`
-	TTL        CacheTTL          `yaml:"ttl"`
-	Aggregates AggregatesCaching `yaml:"aggregates"`
+	Enabled    bool                   `yaml:"enabled"`
+	Engine     string                 `yaml:"engine"` // memory|redis
+	RedisURL   string                 `yaml:"redis_url"`
+	TTL        CacheTTL               `yaml:"ttl"`
+	Aggregates AggregatesCaching      `yaml:"aggregates"`
 	Overrides  map[string]interface{} `yaml:"overrides,omitempty"`
 }
 
@@ -274,9 +436,9 @@ type CacheTTL struct {
 
 // AggregatesCaching contains aggregate caching settings
 type AggregatesCaching struct {
-	Enabled                    bool `yaml:"enabled"`
-	UpdateOnIngest             bool `yaml:"update_on_ingest"`
-	ReconcilerIntervalSeconds  int  `yaml:"reconciler_interval_seconds"`
+	Enabled                   bool `yaml:"enabled"`
+	UpdateOnIngest            bool `yaml:"update_on_ingest"`
+	ReconcilerIntervalSeconds int  `yaml:"reconciler_interval_seconds"`
 }
 
 // Logging contains logging configuration
@@ -293,9 +455,33 @@ type Layout struct {
 
 // Display contains display and rendering control options
 type Display struct {
-	Feed   FeedDisplay   `yaml:"feed"`
-	Detail DetailDisplay `yaml:"detail"`
-	Limits DisplayLimits `yaml:"limits"`
+	Feed      FeedDisplay   `yaml:"feed"`
+	Detail    DetailDisplay `yaml:"detail"`
+	Limits    DisplayLimits `yaml:"limits"`
+	Digest    DailyDigest   `yaml:"digest"`     // The /digest page and QOTD summary
+	HideInbox bool          `yaml:"hide_inbox"` // Leave replies and mentions of the owner out of Gopher and Gemini
+	Authors   string        `yaml:"authors"`    // How authors are named in listings and threads, one of the Authors* styles
+}
+
+// Author name styles for display.authors
+const (
+	AuthorsNameNpub = "name_npub" // Alice (npub1abcd...wxyz), or the short npub without a profile
+	AuthorsName     = "name"      // Alice, or the short npub without a profile
+	AuthorsNpub     = "npub"      // npub1abcd...wxyz
+	AuthorsPubkey   = "pubkey"    // 9822242c...1f0a33b7, the shortened hex key
+)
+
+// HidesSection reports whether a built-in top-level path section is left out
+// because hide_inbox is set
+func (d Display) HidesSection(section string) bool {
+	if !d.HideInbox {
+		return false
+	}
+	switch section {
+	case "replies", "mentions", "inbox":
+		return true
+	}
+	return false
 }
 
 // FeedDisplay controls what appears in feed/list views
@@ -313,15 +499,56 @@ type DetailDisplay struct {
 	ShowZaps         bool `yaml:"show_zaps"`
 	ShowReplies      bool `yaml:"show_replies"`
 	ShowThread       bool `yaml:"show_thread"`
+	ShowQR           bool `yaml:"show_qr"` // QR code of the note's nevent URI, for phones to scan
 }
 
 // DisplayLimits controls length and truncation
 type DisplayLimits struct {
-	SummaryLength       int `yaml:"summary_length"`
-	MaxContentLength    int `yaml:"max_content_length"`
-	MaxThreadDepth      int `yaml:"max_thread_depth"`
-	MaxRepliesInFeed    int `yaml:"max_replies_in_feed"`
-	TruncateIndicator   string `yaml:"truncate_indicator"`
+	SummaryLength     int    `yaml:"summary_length"`
+	MaxContentLength  int    `yaml:"max_content_length"`
+	MaxThreadDepth    int    `yaml:"max_thread_depth"`
+	MaxRepliesInFeed  int    `yaml:"max_replies_in_feed"`
+	TruncateIndicator string `yaml:"truncate_indicator"`
+
+	// Hard caps on events loaded per request, so a crafted selector cannot
+	// pull an unbounded thread, search or archive out of storage
+	MaxThreadReplies   int `yaml:"max_thread_replies"`
+	MaxSearchResults   int `yaml:"max_search_results"`
+	MaxArchivePageSize int `yaml:"max_archive_page_size"`
+
+	// Items per page for each listing section
+	PageSizes PageSizes `yaml:"page_sizes"`
+}
+
+// MaxPageSize is the hard maximum for any configured section page size
+const MaxPageSize = 200
+
+// PageSizes sets how many items each listing section shows per page
+type PageSizes struct {
+	Notes    int `yaml:"notes"`
+	Articles int `yaml:"articles"`
+	Replies  int `yaml:"replies"`
+	Mentions int `yaml:"mentions"`
+	Outbox   int `yaml:"outbox"`
+	Profile  int `yaml:"profile"`  // An author's notes on their profile page
+	Contacts int `yaml:"contacts"` // Following and followers lists
+	Archive  int `yaml:"archive"`  // One month of the owner's notes and articles
+	Tags     int `yaml:"tags"`     // Notes and articles with a hashtag, topic or language
+}
+
+// sections returns each page size keyed by its YAML name
+func (p *PageSizes) sections() map[string]*int {
+	return map[string]*int{
+		"notes":    &p.Notes,
+		"articles": &p.Articles,
+		"replies":  &p.Replies,
+		"mentions": &p.Mentions,
+		"outbox":   &p.Outbox,
+		"profile":  &p.Profile,
+		"contacts": &p.Contacts,
+		"archive":  &p.Archive,
+		"tags":     &p.Tags,
+	}
 }
 
 // Presentation contains visual presentation and layout options
@@ -329,32 +556,65 @@ type Presentation struct {
 	Headers    Headers    `yaml:"headers"`
 	Footers    Footers    `yaml:"footers"`
 	Separators Separators `yaml:"separators"`
+	Templates  Templates  `yaml:"templates"`
 }
 
 // Headers defines header content for pages
 type Headers struct {
-	Global    HeaderConfig            `yaml:"global"`
-	PerPage   map[string]HeaderConfig `yaml:"per_page,omitempty"`
+	Global  HeaderConfig            `yaml:"global"`
+	PerPage map[string]HeaderConfig `yaml:"per_page,omitempty"`
 }
 
 // HeaderConfig defines a single header configuration
 type HeaderConfig struct {
-	Enabled   bool   `yaml:"enabled"`
-	Content   string `yaml:"content"`
-	FilePath  string `yaml:"file_path"`
+	Enabled  bool   `yaml:"enabled"`
+	Content  string `yaml:"content"`
+	FilePath string `yaml:"file_path"`
 }
 
 // Footers defines footer content for pages
 type Footers struct {
-	Global    FooterConfig            `yaml:"global"`
-	PerPage   map[string]FooterConfig `yaml:"per_page,omitempty"`
+	Global  FooterConfig            `yaml:"global"`
+	PerPage map[string]FooterConfig `yaml:"per_page,omitempty"`
 }
 
 // FooterConfig defines a single footer configuration
 type FooterConfig struct {
-	Enabled   bool   `yaml:"enabled"`
-	Content   string `yaml:"content"`
-	FilePath  string `yaml:"file_path"`
+	Enabled  bool   `yaml:"enabled"`
+	Content  string `yaml:"content"`
+	FilePath string `yaml:"file_path"`
+}
+
+// Templates names Go text/template files that replace built-in page
+// layouts, per protocol
+type Templates struct {
+	Gopher TemplateSet `yaml:"gopher"`
+	Gemini TemplateSet `yaml:"gemini"`
+}
+
+// TemplateSet is one protocol's template files by layout. An empty path
+// keeps the built-in layout.
+type TemplateSet struct {
+	Home     string `yaml:"home"`
+	Note     string `yaml:"note"`
+	Profile  string `yaml:"profile"`
+	ListItem string `yaml:"list_item"`
+}
+
+// Files returns the configured template files keyed by layout name
+func (s TemplateSet) Files() map[string]string {
+	files := make(map[string]string)
+	for layout, path := range map[string]string{
+		"home":      s.Home,
+		"note":      s.Note,
+		"profile":   s.Profile,
+		"list_item": s.ListItem,
+	} {
+		if path != "" {
+			files[layout] = path
+		}
+	}
+	return files
 }
 
 // Separators defines visual separators
@@ -372,34 +632,36 @@ type SeparatorConfig struct {
 
 // Behavior contains behavioral settings for queries and filtering
 type Behavior struct {
-	ContentFiltering ContentFiltering  `yaml:"content_filtering"`
-	SortPreferences  SortPreferences   `yaml:"sort_preferences"`
-	Pagination       PaginationConfig  `yaml:"pagination"`
+	ContentFiltering ContentFiltering `yaml:"content_filtering"`
+	SortPreferences  SortPreferences  `yaml:"sort_preferences"`
+	Pagination       PaginationConfig `yaml:"pagination"`
+	FetchOnMiss      bool             `yaml:"fetch_on_miss"` // Look up notes that aren't stored on the seed relays
 }
 
 // ContentFiltering defines content filtering rules
 type ContentFiltering struct {
-	Enabled              bool     `yaml:"enabled"`
-	MinReactions         int      `yaml:"min_reactions"`
-	MinZapSats           int      `yaml:"min_zap_sats"`
-	MinEngagement        int      `yaml:"min_engagement"` // Combined score
-	HideNoInteractions   bool     `yaml:"hide_no_interactions"`
-	AllowedContentTypes  []string `yaml:"allowed_content_types"`
+	Enabled             bool     `yaml:"enabled"`
+	MinReactions        int      `yaml:"min_reactions"`
+	MinZapSats          int      `yaml:"min_zap_sats"`
+	MinEngagement       int      `yaml:"min_engagement"` // Combined score
+	HideNoInteractions  bool     `yaml:"hide_no_interactions"`
+	AllowedContentTypes []string `yaml:"allowed_content_types"`
 }
 
 // SortPreferences defines sorting options
 type SortPreferences struct {
-	Notes    string `yaml:"notes"`    // chronological|engagement|zaps|reactions
+	Notes    string `yaml:"notes"` // chronological|engagement|zaps|reactions
 	Articles string `yaml:"articles"`
 	Replies  string `yaml:"replies"`
 	Mentions string `yaml:"mentions"`
+	Threads  string `yaml:"threads"` // Reply order within a thread: chronological|social|engagement
 }
 
 // PaginationConfig defines pagination settings
 type PaginationConfig struct {
-	Enabled          bool `yaml:"enabled"`
-	ItemsPerPage     int  `yaml:"items_per_page"`
-	MaxPages         int  `yaml:"max_pages"`
+	Enabled      bool `yaml:"enabled"`
+	ItemsPerPage int  `yaml:"items_per_page"`
+	MaxPages     int  `yaml:"max_pages"`
 }
 
 // applyDefaults fills in missing configuration fields with sensible defaults
@@ -407,6 +669,9 @@ func applyDefaults(cfg *Config) {
 	defaults := Default()
 
 	// Apply Display defaults if missing
+	if cfg.Display.Authors == "" {
+		cfg.Display.Authors = defaults.Display.Authors
+	}
 	if cfg.Display.Limits.SummaryLength == 0 {
 		cfg.Display.Limits.SummaryLength = defaults.Display.Limits.SummaryLength
 	}
@@ -422,6 +687,131 @@ func applyDefaults(cfg *Config) {
 	if cfg.Display.Limits.TruncateIndicator == "" {
 		cfg.Display.Limits.TruncateIndicator = defaults.Display.Limits.TruncateIndicator
 	}
+	if cfg.Display.Limits.MaxThreadReplies == 0 {
+		cfg.Display.Limits.MaxThreadReplies = defaults.Display.Limits.MaxThreadReplies
+	}
+	if cfg.Display.Limits.MaxSearchResults == 0 {
+		cfg.Display.Limits.MaxSearchResults = defaults.Display.Limits.MaxSearchResults
+	}
+	if cfg.Display.Limits.MaxArchivePageSize == 0 {
+		cfg.Display.Limits.MaxArchivePageSize = defaults.Display.Limits.MaxArchivePageSize
+	}
+	defaultSizes := defaults.Display.Limits.PageSizes.sections()
+	for name, size := range cfg.Display.Limits.PageSizes.sections() {
+		if *size == 0 {
+			*size = *defaultSizes[name]
+		}
+	}
+
+	// NNTP postdates most config files, so enabling it is enough
+	applyNNTPDefaults(&cfg.Protocols.NNTP, &defaults.Protocols.NNTP)
+
+	// Same for telnet
+	if cfg.Protocols.Telnet.Port == 0 {
+		cfg.Protocols.Telnet.Port = defaults.Protocols.Telnet.Port
+	}
+	if cfg.Protocols.Telnet.Bind == "" {
+		cfg.Protocols.Telnet.Bind = defaults.Protocols.Telnet.Bind
+	}
+	if cfg.Protocols.Telnet.PageLines == 0 {
+		cfg.Protocols.Telnet.PageLines = defaults.Protocols.Telnet.PageLines
+	}
+	if cfg.Protocols.QOTD.Port == 0 {
+		cfg.Protocols.QOTD.Port = defaults.Protocols.QOTD.Port
+	}
+	if cfg.Protocols.QOTD.Bind == "" {
+		cfg.Protocols.QOTD.Bind = defaults.Protocols.QOTD.Bind
+	}
+	if cfg.Protocols.Relay.Port == 0 {
+		cfg.Protocols.Relay.Port = defaults.Protocols.Relay.Port
+	}
+	if cfg.Protocols.Relay.Bind == "" {
+		cfg.Protocols.Relay.Bind = defaults.Protocols.Relay.Bind
+	}
+
+	// Keep deleted events restorable unless the config says otherwise
+	if cfg.Sync.Retention.TrashGraceDays == 0 {
+		cfg.Sync.Retention.TrashGraceDays = defaults.Sync.Retention.TrashGraceDays
+	}
+
+	// Apply relay policy defaults for slow-relay detection
+	if cfg.Relays.Policy.SlowThresholdMs == 0 {
+		cfg.Relays.Policy.SlowThresholdMs = defaults.Relays.Policy.SlowThresholdMs
+	}
+	if cfg.Relays.Policy.SlowStrikes == 0 {
+		cfg.Relays.Policy.SlowStrikes = defaults.Relays.Policy.SlowStrikes
+	}
+
+	// Apply relay selection defaults
+	if cfg.Discovery.MaxRelaysPerAuthor == 0 {
+		cfg.Discovery.MaxRelaysPerAuthor = defaults.Discovery.MaxRelaysPerAuthor
+	}
+	confidence := &cfg.Discovery.HintConfidence
+	if confidence.RelayList == 0 {
+		confidence.RelayList = defaults.Discovery.HintConfidence.RelayList
+	}
+	if confidence.NIP05 == 0 {
+		confidence.NIP05 = defaults.Discovery.HintConfidence.NIP05
+	}
+	if confidence.Tags == 0 {
+		confidence.Tags = defaults.Discovery.HintConfidence.Tags
+	}
+	if confidence.Min == 0 {
+		confidence.Min = defaults.Discovery.HintConfidence.Min
+	}
+
+	// Apply Rendering defaults for thread indentation
+	if cfg.Rendering.Gopher.ThreadIndent == "" {
+		cfg.Rendering.Gopher.ThreadIndent = defaults.Rendering.Gopher.ThreadIndent
+	}
+	if cfg.Rendering.Gemini.ThreadIndent == "" {
+		cfg.Rendering.Gemini.ThreadIndent = defaults.Rendering.Gemini.ThreadIndent
+	}
+	if cfg.Rendering.Gopher.MediaLinks == "" {
+		cfg.Rendering.Gopher.MediaLinks = defaults.Rendering.Gopher.MediaLinks
+	}
+	if cfg.Rendering.Gemini.MediaLinks == "" {
+		cfg.Rendering.Gemini.MediaLinks = defaults.Rendering.Gemini.MediaLinks
+	}
+	if cfg.Rendering.Finger.PlanSource == "" {
+		cfg.Rendering.Finger.PlanSource = defaults.Rendering.Finger.PlanSource
+	}
+	if cfg.Rendering.Finger.RecentNotesCount == 0 {
+		cfg.Rendering.Finger.RecentNotesCount = defaults.Rendering.Finger.RecentNotesCount
+	}
+	if cfg.Rendering.Finger.Width == 0 {
+		cfg.Rendering.Finger.Width = defaults.Rendering.Finger.Width
+	}
+	if cfg.Rendering.MediaProxy.Dir == "" {
+		cfg.Rendering.MediaProxy.Dir = defaults.Rendering.MediaProxy.Dir
+	}
+	if cfg.Rendering.MediaProxy.MaxFileMB == 0 {
+		cfg.Rendering.MediaProxy.MaxFileMB = defaults.Rendering.MediaProxy.MaxFileMB
+	}
+	if cfg.Rendering.MediaProxy.MaxTotalMB == 0 {
+		cfg.Rendering.MediaProxy.MaxTotalMB = defaults.Rendering.MediaProxy.MaxTotalMB
+	}
+	if cfg.Rendering.MediaProxy.TimeoutSeconds == 0 {
+		cfg.Rendering.MediaProxy.TimeoutSeconds = defaults.Rendering.MediaProxy.TimeoutSeconds
+	}
+	if cfg.Rendering.Translation.Provider == "" {
+		cfg.Rendering.Translation.Provider = defaults.Rendering.Translation.Provider
+	}
+	if len(cfg.Rendering.Translation.Languages) == 0 {
+		cfg.Rendering.Translation.Languages = defaults.Rendering.Translation.Languages
+	}
+	if cfg.Rendering.Translation.MaxChars == 0 {
+		cfg.Rendering.Translation.MaxChars = defaults.Rendering.Translation.MaxChars
+	}
+	if cfg.Rendering.Translation.TimeoutSeconds == 0 {
+		cfg.Rendering.Translation.TimeoutSeconds = defaults.Rendering.Translation.TimeoutSeconds
+	}
+	if cfg.Rendering.Translation.MaxConcurrent == 0 {
+		cfg.Rendering.Translation.MaxConcurrent = defaults.Rendering.Translation.MaxConcurrent
+	}
+	if cfg.Rendering.Translation.RequestsPerMinute == 0 {
+		cfg.Rendering.Translation.RequestsPerMinute = defaults.Rendering.Translation.RequestsPerMinute
+	}
 
 	// Apply Behavior defaults for sort preferences
 	if cfg.Behavior.SortPreferences.Notes == "" {
@@ -436,6 +826,9 @@ func applyDefaults(cfg *Config) {
 	if cfg.Behavior.SortPreferences.Mentions == "" {
 		cfg.Behavior.SortPreferences.Mentions = defaults.Behavior.SortPreferences.Mentions
 	}
+	if cfg.Behavior.SortPreferences.Threads == "" {
+		cfg.Behavior.SortPreferences.Threads = defaults.Behavior.SortPreferences.Threads
+	}
 
 	// Apply Presentation defaults for separators if empty maps
 	if cfg.Presentation.Headers.PerPage == nil {
@@ -453,10 +846,100 @@ func applyDefaults(cfg *Config) {
 		cfg.Layout.Pages = make(map[string]interface{})
 	}
 
+	// Apply Titan upload size default
+	if cfg.Protocols.Gemini.Titan.MaxSize == 0 {
+		cfg.Protocols.Gemini.Titan.MaxSize = defaults.Protocols.Gemini.Titan.MaxSize
+	}
+	if cfg.Protocols.Gemini.Titan.NonceWindowSeconds <= 0 {
+		cfg.Protocols.Gemini.Titan.NonceWindowSeconds = defaults.Protocols.Gemini.Titan.NonceWindowSeconds
+	}
+	if cfg.Protocols.Gemini.Titan.UploadsPerHour <= 0 {
+		cfg.Protocols.Gemini.Titan.UploadsPerHour = defaults.Protocols.Gemini.Titan.UploadsPerHour
+	}
+
+	// Apply wallet defaults
+	if cfg.Protocols.Gemini.Wallet.TimeoutSeconds <= 0 {
+		cfg.Protocols.Gemini.Wallet.TimeoutSeconds = defaults.Protocols.Gemini.Wallet.TimeoutSeconds
+	}
+	if cfg.Protocols.Gemini.Wallet.MaxZapSats == 0 {
+		cfg.Protocols.Gemini.Wallet.MaxZapSats = defaults.Protocols.Gemini.Wallet.MaxZapSats
+	}
+
 	// Apply Sync performance defaults
 	if cfg.Sync.Performance.Workers == 0 {
 		cfg.Sync.Performance.Workers = defaults.Sync.Performance.Workers
 	}
+	if cfg.Sync.Performance.RelayQueueSize == 0 {
+		cfg.Sync.Performance.RelayQueueSize = defaults.Sync.Performance.RelayQueueSize
+	}
+	if cfg.Sync.Performance.BatchSize == 0 {
+		cfg.Sync.Performance.BatchSize = defaults.Sync.Performance.BatchSize
+	}
+	if cfg.Sync.Performance.BatchWindowMs == 0 {
+		cfg.Sync.Performance.BatchWindowMs = defaults.Sync.Performance.BatchWindowMs
+	}
+
+	// Apply ingest limit defaults when the section is absent; explicit zeros mean unlimited
+	if cfg.Sync.Limits.MaxContentBytes == 0 && cfg.Sync.Limits.MaxTags == 0 && cfg.Sync.Limits.Kinds == nil {
+		cfg.Sync.Limits.MaxContentBytes = defaults.Sync.Limits.MaxContentBytes
+		cfg.Sync.Limits.MaxTags = defaults.Sync.Limits.MaxTags
+		cfg.Sync.Limits.Kinds = defaults.Sync.Limits.Kinds
+	}
+	if cfg.Sync.Limits.Action == "" {
+		cfg.Sync.Limits.Action = defaults.Sync.Limits.Action
+	}
+
+	// Apply rate limit defaults when enabled without explicit limits
+	if cfg.Security.RateLimit.RequestsPerMinute == 0 {
+		cfg.Security.RateLimit.RequestsPerMinute = defaults.Security.RateLimit.RequestsPerMinute
+	}
+	if cfg.Security.RateLimit.Burst == 0 {
+		cfg.Security.RateLimit.Burst = defaults.Security.RateLimit.Burst
+	}
+	if cfg.Security.Admin.Socket == "" {
+		cfg.Security.Admin.Socket = defaults.Security.Admin.Socket
+	}
+
+	// Apply daily digest defaults
+	if cfg.Display.Digest.RegenerateAt == "" {
+		cfg.Display.Digest.RegenerateAt = defaults.Display.Digest.RegenerateAt
+	}
+	if cfg.Display.Digest.TopPosts == 0 {
+		cfg.Display.Digest.TopPosts = defaults.Display.Digest.TopPosts
+	}
+	if cfg.Display.Digest.MaxPosts == 0 {
+		cfg.Display.Digest.MaxPosts = defaults.Display.Digest.MaxPosts
+	}
+
+	// Apply weekly digest defaults
+	if cfg.Outbox.Digest.Kind == 0 {
+		cfg.Outbox.Digest.Kind = defaults.Outbox.Digest.Kind
+	}
+	if cfg.Outbox.Digest.Weekday == "" {
+		cfg.Outbox.Digest.Weekday = defaults.Outbox.Digest.Weekday
+	}
+	if cfg.Outbox.Digest.TopPosts == 0 {
+		cfg.Outbox.Digest.TopPosts = defaults.Outbox.Digest.TopPosts
+	}
+	if cfg.Outbox.Bridge.IntervalMinutes == 0 {
+		cfg.Outbox.Bridge.IntervalMinutes = defaults.Outbox.Bridge.IntervalMinutes
+	}
+	for i := range cfg.Outbox.Bridge.Feeds {
+		if cfg.Outbox.Bridge.Feeds[i].Kind == 0 {
+			cfg.Outbox.Bridge.Feeds[i].Kind = 1
+		}
+	}
+
+	// Apply idle shedding defaults
+	if cfg.Idle.AfterMinutes == 0 {
+		cfg.Idle.AfterMinutes = defaults.Idle.AfterMinutes
+	}
+	if cfg.Idle.CacheMaxMB == 0 {
+		cfg.Idle.CacheMaxMB = defaults.Idle.CacheMaxMB
+	}
+	if cfg.Idle.SyncIntervalFactor == 0 {
+		cfg.Idle.SyncIntervalFactor = defaults.Idle.SyncIntervalFactor
+	}
 }
 
 // Load reads and parses a configuration file
@@ -489,20 +972,30 @@ func Load(path string) (*Config, error) {
 
 // applyEnvOverrides applies environment variable overrides to config
 func applyEnvOverrides(cfg *Config) error {
-	// Note: NOPHR_NSEC removed - not needed since Publisher (Phase 13) is not implemented
-	// If Publisher is implemented in the future, uncomment:
-	// if nsec := os.Getenv("NOPHR_NSEC"); nsec != "" {
-	//     cfg.Identity.Nsec = nsec
-	// }
+	// Secret key is never read from the config file
+	if nsec := os.Getenv("NOPHR_NSEC"); nsec != "" {
+		cfg.Identity.Nsec = nsec
+	}
+
+	// Wallet connection URIs carry a spending secret, so they never live in the file either
+	if uri := os.Getenv("NOPHR_NWC_URI"); uri != "" {
+		cfg.Protocols.Gemini.Wallet.URI = uri
+	}
 
-	// Redis URL from env if using redis
+	// Translation API keys are secrets too
+	if key := os.Getenv("NOPHR_TRANSLATE_API_KEY"); key != "" {
+		cfg.Rendering.Translation.APIKey = key
+	}
+
+	// Redis URL from env if using redis (shorthand for NOPHR_CACHING_REDIS_URL)
 	if redisURL := os.Getenv("NOPHR_REDIS_URL"); redisURL != "" {
 		cfg.Caching.RedisURL = redisURL
 	}
 
-	// Allow overriding any config via NOPHR_ prefix
-	// This is a simplified implementation - full version would use reflection
-	// to handle all nested fields automatically
+	// Allow overriding any config field via its NOPHR_ path, e.g. NOPHR_PROTOCOLS_GOPHER_PORT
+	if _, err := applyEnvFields(reflect.ValueOf(cfg).Elem(), envPrefix); err != nil {
+		return err
+	}
 
 	return nil
 }
@@ -540,6 +1033,18 @@ func Default() *Config {
 					KeyPath:      "./certs/key.pem",
 					AutoGenerate: true,
 				},
+				Titan: GeminiTitan{
+					Enabled:            false,
+					MaxSize:            16384,
+					NonceWindowSeconds: 300,
+					UploadsPerHour:     30,
+				},
+				Wallet: GeminiWallet{
+					Enabled:        false,
+					DefaultZapSats: 21,
+					MaxZapSats:     1000,
+					TimeoutSeconds: 30,
+				},
 			},
 			Finger: FingerProtocol{
 				Enabled:  true,
@@ -547,6 +1052,31 @@ func Default() *Config {
 				Bind:     "0.0.0.0",
 				MaxUsers: 100,
 			},
+			NNTP: NNTPProtocol{
+				Enabled:     false,
+				Host:        "localhost",
+				Port:        119,
+				Bind:        "0.0.0.0",
+				GroupPrefix: "nophr",
+				MaxArticles: 100,
+			},
+			Telnet: TelnetProtocol{
+				Enabled:   false,
+				Port:      23,
+				Bind:      "0.0.0.0",
+				PageLines: 23,
+			},
+			QOTD: QOTDProtocol{
+				Enabled: false,
+				Port:    17,
+				Bind:    "0.0.0.0",
+			},
+			Relay: RelayProtocol{
+				Enabled:     false,
+				Port:        7777,
+				Bind:        "0.0.0.0",
+				AllowWrites: false,
+			},
 		},
 		Relays: Relays{
 			Seeds: []string{
@@ -558,6 +1088,8 @@ func Default() *Config {
 				ConnectTimeoutMs:  5000,
 				MaxConcurrentSubs: 8,
 				BackoffMs:         []int{500, 1500, 5000},
+				SlowThresholdMs:   2000,
+				SlowStrikes:       5,
 			},
 		},
 		Discovery: Discovery{
@@ -566,6 +1098,12 @@ func Default() *Config {
 			UseAuthorHints:     true,
 			FallbackToSeeds:    true,
 			MaxRelaysPerAuthor: 8,
+			HintConfidence: HintConfidence{
+				RelayList: 1.0,
+				NIP05:     0.7,
+				Tags:      0.3,
+				Min:       0.5,
+			},
 		},
 		Sync: Sync{
 			Kinds: SyncKinds{
@@ -589,13 +1127,18 @@ func Default() *Config {
 				DenylistPubkeys:       []string{},
 			},
 			Retention: Retention{
-				KeepDays:     365,
-				PruneOnStart: true,
+				KeepDays:       365,
+				PruneOnStart:   true,
+				TrashGraceDays: 7,
 			},
 			Performance: SyncPerformance{
-				Workers:       4,    // Default: 4 parallel event processing workers
-				UseNegentropy: true, // Default: enable NIP-77 negentropy (always falls back to REQ if unsupported)
+				Workers:        4,    // Default: 4 parallel event processing workers
+				UseNegentropy:  true, // Default: enable NIP-77 negentropy (always falls back to REQ if unsupported)
+				RelayQueueSize: 1000, // Default: 1000 events buffered per relay
+				BatchSize:      100,  // Default: up to 100 events per write transaction
+				BatchWindowMs:  50,   // Default: wait up to 50ms for a batch to fill
 			},
+			Limits: DefaultIngestLimits(),
 		},
 		Inbox: Inbox{
 			IncludeReplies:   true,
@@ -616,6 +1159,8 @@ func Default() *Config {
 			},
 			DraftDir: "./content",
 			AutoSign: false,
+			Digest:   DefaultDigest(),
+			Bridge:   DefaultBridge(),
 		},
 		Storage: Storage{
 			Driver:        "sqlite",
@@ -629,16 +1174,28 @@ func Default() *Config {
 				ShowTimestamps: true,
 				DateFormat:     "2006-01-02 15:04 MST",
 				ThreadIndent:   "  ",
+				MediaLinks:     MediaLinksItems,
 			},
 			Gemini: GeminiRendering{
 				MaxLineLength:  80,
 				ShowTimestamps: true,
 				Emoji:          true,
+				ThreadIndent:   "  ",
+				MediaLinks:     MediaLinksItems,
 			},
 			Finger: FingerRendering{
 				PlanSource:       "kind_0",
 				RecentNotesCount: 5,
+				Width:            80,
+			},
+			MediaProxy: MediaProxy{
+				Enabled:        false,
+				Dir:            "./data/media",
+				MaxFileMB:      5,
+				MaxTotalMB:     500,
+				TimeoutSeconds: 15,
 			},
+			Translation: DefaultTranslation(),
 		},
 		Caching: Caching{
 			Enabled:  true,
@@ -690,12 +1247,28 @@ func Default() *Config {
 				ShowThread:       true,
 			},
 			Limits: DisplayLimits{
-				SummaryLength:     100,
-				MaxContentLength:  5000,
-				MaxThreadDepth:    10,
-				MaxRepliesInFeed:  3,
-				TruncateIndicator: "...",
+				SummaryLength:      100,
+				MaxContentLength:   5000,
+				MaxThreadDepth:     10,
+				MaxRepliesInFeed:   3,
+				TruncateIndicator:  "...",
+				MaxThreadReplies:   200,
+				MaxSearchResults:   50,
+				MaxArchivePageSize: 100,
+				PageSizes: PageSizes{
+					Notes:    50,
+					Articles: 50,
+					Replies:  50,
+					Mentions: 50,
+					Outbox:   50,
+					Profile:  50,
+					Contacts: 50,
+					Archive:  50,
+					Tags:     50,
+				},
 			},
+			Digest:  DefaultDailyDigest(),
+			Authors: AuthorsNameNpub,
 		},
 		Presentation: Presentation{
 			Headers: Headers{
@@ -741,6 +1314,7 @@ func Default() *Config {
 				Articles: "chronological",
 				Replies:  "chronological",
 				Mentions: "chronological",
+				Threads:  "social",
 			},
 			Pagination: PaginationConfig{
 				Enabled:      false,
@@ -748,6 +1322,8 @@ func Default() *Config {
 				MaxPages:     10,
 			},
 		},
+		Security: DefaultSecurity(),
+		Idle:     DefaultIdle(),
 	}
 }
 
@@ -790,7 +1366,7 @@ func Validate(cfg *Config) error {
 	}
 
 	// Validate at least one protocol is enabled
-	if !cfg.Protocols.Gopher.Enabled && !cfg.Protocols.Gemini.Enabled && !cfg.Protocols.Finger.Enabled {
+	if !cfg.Protocols.Gopher.Enabled && !cfg.Protocols.Gemini.Enabled && !cfg.Protocols.Finger.Enabled && !cfg.Protocols.NNTP.Enabled && !cfg.Protocols.Telnet.Enabled && !cfg.Protocols.QOTD.Enabled && !cfg.Protocols.Relay.Enabled {
 		return fmt.Errorf("at least one protocol must be enabled")
 	}
 
@@ -804,6 +1380,20 @@ func Validate(cfg *Config) error {
 	if cfg.Protocols.Finger.Enabled && (cfg.Protocols.Finger.Port < 1 || cfg.Protocols.Finger.Port > 65535) {
 		return fmt.Errorf("finger port must be between 1 and 65535")
 	}
+	if cfg.Protocols.NNTP.Enabled {
+		if err := validateNNTP(&cfg.Protocols.NNTP); err != nil {
+			return err
+		}
+	}
+	if cfg.Protocols.Telnet.Enabled && (cfg.Protocols.Telnet.Port < 1 || cfg.Protocols.Telnet.Port > 65535) {
+		return fmt.Errorf("telnet port must be between 1 and 65535")
+	}
+	if cfg.Protocols.QOTD.Enabled && (cfg.Protocols.QOTD.Port < 1 || cfg.Protocols.QOTD.Port > 65535) {
+		return fmt.Errorf("qotd port must be between 1 and 65535")
+	}
+	if cfg.Protocols.Relay.Enabled && (cfg.Protocols.Relay.Port < 1 || cfg.Protocols.Relay.Port > 65535) {
+		return fmt.Errorf("relay port must be between 1 and 65535")
+	}
 
 	// Validate relay seeds
 	if len(cfg.Relays.Seeds) == 0 {
@@ -815,6 +1405,12 @@ func Validate(cfg *Config) error {
 		}
 	}
 
+	for _, relay := range cfg.Sync.Scope.InboxRelays {
+		if !strings.HasPrefix(relay, "wss://") && !strings.HasPrefix(relay, "ws://") {
+			return fmt.Errorf("sync.scope.inbox_relays entry must start with ws:// or wss://: %s", relay)
+		}
+	}
+
 	// Validate sync mode
 	if !validSyncModes[cfg.Sync.Scope.Mode] {
 		return fmt.Errorf("invalid sync mode: %s (must be one of: self, following, mutual, foaf)", cfg.Sync.Scope.Mode)
@@ -845,6 +1441,63 @@ func Validate(cfg *Config) error {
 	if cfg.Display.Limits.MaxThreadDepth < 1 || cfg.Display.Limits.MaxThreadDepth > 100 {
 		return fmt.Errorf("display.limits.max_thread_depth must be between 1 and 100")
 	}
+	if cfg.Display.Limits.MaxThreadReplies < 1 || cfg.Display.Limits.MaxThreadReplies > 1000 {
+		return fmt.Errorf("display.limits.max_thread_replies must be between 1 and 1000")
+	}
+	if cfg.Display.Limits.MaxSearchResults < 1 || cfg.Display.Limits.MaxSearchResults > 1000 {
+		return fmt.Errorf("display.limits.max_search_results must be between 1 and 1000")
+	}
+	if cfg.Display.Limits.MaxArchivePageSize < 1 || cfg.Display.Limits.MaxArchivePageSize > 1000 {
+		return fmt.Errorf("display.limits.max_archive_page_size must be between 1 and 1000")
+	}
+	for name, size := range cfg.Display.Limits.PageSizes.sections() {
+		if *size < 1 || *size > MaxPageSize {
+			return fmt.Errorf("display.limits.page_sizes.%s must be between 1 and %d", name, MaxPageSize)
+		}
+	}
+	switch cfg.Display.Authors {
+	case "", AuthorsNameNpub, AuthorsName, AuthorsNpub, AuthorsPubkey: // Empty falls back to name_npub
+	default:
+		return fmt.Errorf("invalid display.authors: %s (must be one of: name_npub, name, npub, pubkey)", cfg.Display.Authors)
+	}
+
+	// Validate media link styles (empty falls back to the default)
+	for name, style := range map[string]string{"gopher": cfg.Rendering.Gopher.MediaLinks, "gemini": cfg.Rendering.Gemini.MediaLinks} {
+		if style != "" && style != MediaLinksItems && style != MediaLinksText {
+			return fmt.Errorf("invalid rendering.%s.media_links: %s (must be one of: items, text)", name, style)
+		}
+	}
+
+	// Validate finger rendering (zero values fall back to defaults)
+	if source := cfg.Rendering.Finger.PlanSource; source != "" && source != "kind_0" && source != "kind_1" {
+		return fmt.Errorf("invalid finger plan source: %s (must be one of: kind_0, kind_1)", source)
+	}
+	if width := cfg.Rendering.Finger.Width; width != 0 && (width < 20 || width > 1000) {
+		return fmt.Errorf("rendering.finger.width must be between 20 and 1000")
+	}
+
+	// Validate the media proxy (zero values fall back to defaults)
+	if proxy := cfg.Rendering.MediaProxy; proxy.MaxFileMB < 0 || proxy.MaxTotalMB < 0 || proxy.TimeoutSeconds < 0 {
+		return fmt.Errorf("rendering.media_proxy sizes and timeout must not be negative")
+	}
+	if proxy := cfg.Rendering.MediaProxy; proxy.MaxTotalMB > 0 && proxy.MaxFileMB > proxy.MaxTotalMB {
+		return fmt.Errorf("rendering.media_proxy.max_file_mb must not exceed max_total_mb")
+	}
+
+	for _, domain := range cfg.Rendering.BlockedDomains {
+		if domain == "" || strings.ContainsAny(domain, "/: ") {
+			return fmt.Errorf("rendering.blocked_domains: %q must be a bare domain like example.com", domain)
+		}
+	}
+
+	// Validate on-demand translation
+	if err := cfg.Rendering.Translation.Validate(); err != nil {
+		return err
+	}
+
+	if err := validateAuthorOverrides(&cfg.Rendering); err != nil {
+		return err
+	}
 
 	// Validate sort preferences
 	validSortModes := map[string]bool{
@@ -865,6 +1518,15 @@ func Validate(cfg *Config) error {
 	if !validSortModes[cfg.Behavior.SortPreferences.Mentions] {
 		return fmt.Errorf("invalid sort mode for mentions: %s", cfg.Behavior.SortPreferences.Mentions)
 	}
+	validThreadOrders := map[string]bool{
+		"":              true, // Unset outside Load, replies stay oldest first
+		"chronological": true,
+		"social":        true,
+		"engagement":    true,
+	}
+	if !validThreadOrders[cfg.Behavior.SortPreferences.Threads] {
+		return fmt.Errorf("invalid sort mode for threads: %s", cfg.Behavior.SortPreferences.Threads)
+	}
 
 	// Validate pagination
 	if cfg.Behavior.Pagination.Enabled {
@@ -883,35 +1545,117 @@ func Validate(cfg *Config) error {
 		}
 	}
 
+	if cfg.Sync.Scope.PruneUnfollowedHours < 0 {
+		return fmt.Errorf("sync.scope.prune_unfollowed_hours must be >= 0")
+	}
+
+	if cfg.Sync.Retention.TrashGraceDays < -1 {
+		return fmt.Errorf("sync.retention.trash_grace_days must be -1 (delete immediately) or a number of days")
+	}
+
+	if err := cfg.Sync.Limits.Validate(); err != nil {
+		return err
+	}
+
+	// Validate rate limiting
+	if err := cfg.Security.RateLimit.Validate(); err != nil {
+		return err
+	}
+
+	// Validate Gemini client certificate access rules
+	if err := cfg.Protocols.Gemini.Access.Validate(); err != nil {
+		return err
+	}
+	if cfg.Protocols.Gemini.Titan.Enabled && cfg.Protocols.Gemini.Titan.MaxSize < 1 {
+		return fmt.Errorf("protocols.gemini.titan.max_size must be at least 1")
+	}
+	if cfg.Protocols.Gemini.Wallet.DefaultZapSats < 0 {
+		return fmt.Errorf("protocols.gemini.wallet.default_zap_sats must be >= 0")
+	}
+	if cfg.Protocols.Gemini.Wallet.MaxZapSats < 0 {
+		return fmt.Errorf("protocols.gemini.wallet.max_zap_sats must be >= 0")
+	}
+	if cfg.Protocols.Gemini.Wallet.MaxZapSats > 0 && cfg.Protocols.Gemini.Wallet.DefaultZapSats > cfg.Protocols.Gemini.Wallet.MaxZapSats {
+		return fmt.Errorf("protocols.gemini.wallet.default_zap_sats must not exceed max_zap_sats")
+	}
+
+	// Validate the daily digest page
+	if err := cfg.Display.Digest.Validate(); err != nil {
+		return err
+	}
+
+	// Validate the weekly digest job
+	if err := cfg.Outbox.Digest.Validate(); err != nil {
+		return err
+	}
+
+	// Validate the RSS and Atom bridge
+	if err := cfg.Outbox.Bridge.Validate(); err != nil {
+		return err
+	}
+
+	// Validate idle resource shedding
+	if err := cfg.Idle.Validate(); err != nil {
+		return err
+	}
+
+	// Validate owner and section aliases
+	if err := validateAliases(cfg); err != nil {
+		return err
+	}
+
+	// Validate custom sections
+	if err := validateSections(cfg); err != nil {
+		return err
+	}
+
+	// Validate static pages
+	if err := validatePages(cfg); err != nil {
+		return err
+	}
+
+	if err := validateProfiles(cfg); err != nil {
+		return err
+	}
+
 	return nil
 }
 
 // SectionConfig represents a section definition in YAML
 type SectionConfig struct {
-	Name        string               `yaml:"name"`
-	Path        string               `yaml:"path"`
-	Title       string               `yaml:"title"`
-	Description string               `yaml:"description"`
-	Filters     SectionFilterConfig  `yaml:"filters"`
-	SortBy      string               `yaml:"sort_by"`
-	SortOrder   string               `yaml:"sort_order"`
-	Limit       int                  `yaml:"limit"`
-	ShowDates   bool                 `yaml:"show_dates"`
-	ShowAuthors bool                 `yaml:"show_authors"`
-	GroupBy     string               `yaml:"group_by"`
+	Name        string                 `yaml:"name"`
+	Type        string                 `yaml:"type"` // "" for filtered events, planet for articles from a list of authors, list for the events lists reference
+	Path        string                 `yaml:"path"`
+	Title       string                 `yaml:"title"`
+	Description string                 `yaml:"description"`
+	Filters     SectionFilterConfig    `yaml:"filters"`
+	SortBy      string                 `yaml:"sort_by"`
+	SortOrder   string                 `yaml:"sort_order"`
+	Limit       int                    `yaml:"limit"`
+	ShowDates   bool                   `yaml:"show_dates"`
+	ShowAuthors bool                   `yaml:"show_authors"`
+	GroupBy     string                 `yaml:"group_by"`
 	MoreLink    *SectionMoreLinkConfig `yaml:"more_link"`
-	Order       int                  `yaml:"order"`
+	Order       int                    `yaml:"order"`
+	MediaLinks  bool                   `yaml:"media_links"` // List each entry's media and links, styled by rendering.<protocol>.media_links
+	Hidden      bool                   `yaml:"hidden"`      // Leave out of the home menus
 }
 
 // SectionFilterConfig represents section filters in YAML
 type SectionFilterConfig struct {
-	Kinds   []int               `yaml:"kinds"`
-	Authors []string            `yaml:"authors"`
-	Tags    map[string][]string `yaml:"tags"`
-	Since   string              `yaml:"since"`   // RFC3339 or duration like "-24h"
-	Until   string              `yaml:"until"`   // RFC3339 or duration
-	Search  string              `yaml:"search"`
-	Scope   string              `yaml:"scope"`   // self, following, mutual, foaf, all
+	Kinds    []int               `yaml:"kinds"`
+	Authors  []string            `yaml:"authors"`
+	Tags     map[string][]string `yaml:"tags"`
+	Hashtags []string            `yaml:"hashtags"` // Shorthand for tags.t, e.g. [photography]
+	Since    string              `yaml:"since"`    // RFC3339 or duration like "-24h"
+	Until    string              `yaml:"until"`    // RFC3339 or duration
+	Search   string              `yaml:"search"`
+	Scope    string              `yaml:"scope"` // self, following, mutual, foaf, all
+
+	// Languages and Topics match the annotations notes and articles get as
+	// they are stored, e.g. languages: [es] or topics: [bitcoin]
+	Languages []string `yaml:"languages"`
+	Topics    []string `yaml:"topics"`
 }
 
 // SectionMoreLinkConfig represents a "more" link configuration
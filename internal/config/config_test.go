@@ -101,6 +101,21 @@ func TestValidate(t *testing.T) {
 			wantErr: true,
 			errMsg:  "must start with ws://",
 		},
+		{
+			name: "invalid inbox relay protocol",
+			cfg: &Config{
+				Identity: Identity{Npub: "npub1nq3zgtqruwhnz0xx40gh4a4fkamlr2sc7ke5wqs2s3nyv2fpy9esg4hdwq"},
+				Protocols: Protocols{
+					Gopher: GopherProtocol{Enabled: true, Port: 70},
+				},
+				Relays: Relays{Seeds: []string{"wss://relay.test"}},
+				Sync: Sync{
+					Scope: SyncScope{Mode: "self", InboxRelays: []string{"relay.test"}},
+				},
+			},
+			wantErr: true,
+			errMsg:  "inbox_relays entry must start with ws://",
+		},
 		{
 			name: "invalid sync mode",
 			cfg: &Config{
@@ -175,12 +190,17 @@ func TestValidate(t *testing.T) {
 				Logging: Logging{Level: "info"},
 				Display: Display{
 					Limits: DisplayLimits{
-						SummaryLength:     100,
-						MaxContentLength:  5000,
-						MaxThreadDepth:    10,
-						MaxRepliesInFeed:  3,
-						TruncateIndicator: "...",
+						SummaryLength:      100,
+						MaxContentLength:   5000,
+						MaxThreadDepth:     10,
+						MaxRepliesInFeed:   3,
+						TruncateIndicator:  "...",
+						MaxThreadReplies:   200,
+						MaxSearchResults:   50,
+						MaxArchivePageSize: 100,
+						PageSizes:          Default().Display.Limits.PageSizes,
 					},
+					Digest: DefaultDailyDigest(),
 				},
 				Behavior: Behavior{
 					SortPreferences: SortPreferences{
@@ -193,6 +213,101 @@ func TestValidate(t *testing.T) {
 			},
 			wantErr: false,
 		},
+		{
+			name: "search cap above query ceiling",
+			cfg: &Config{
+				Identity: Identity{Npub: "npub1nq3zgtqruwhnz0xx40gh4a4fkamlr2sc7ke5wqs2s3nyv2fpy9esg4hdwq"},
+				Protocols: Protocols{
+					Gopher: GopherProtocol{Enabled: true, Port: 70},
+				},
+				Relays:  Relays{Seeds: []string{"wss://relay.test"}},
+				Sync:    Sync{Scope: SyncScope{Mode: "self"}},
+				Storage: Storage{Driver: "sqlite"},
+				Caching: Caching{Enabled: false},
+				Logging: Logging{Level: "info"},
+				Display: Display{
+					Limits: DisplayLimits{
+						SummaryLength:      100,
+						MaxContentLength:   5000,
+						MaxThreadDepth:     10,
+						MaxRepliesInFeed:   3,
+						TruncateIndicator:  "...",
+						MaxThreadReplies:   200,
+						MaxSearchResults:   5000,
+						MaxArchivePageSize: 100,
+					},
+				},
+			},
+			wantErr: true,
+			errMsg:  "max_search_results",
+		},
+		{
+			name: "page size above hard maximum",
+			cfg: &Config{
+				Identity: Identity{Npub: "npub1nq3zgtqruwhnz0xx40gh4a4fkamlr2sc7ke5wqs2s3nyv2fpy9esg4hdwq"},
+				Protocols: Protocols{
+					Gopher: GopherProtocol{Enabled: true, Port: 70},
+				},
+				Relays:  Relays{Seeds: []string{"wss://relay.test"}},
+				Sync:    Sync{Scope: SyncScope{Mode: "self"}},
+				Storage: Storage{Driver: "sqlite"},
+				Caching: Caching{Enabled: false},
+				Logging: Logging{Level: "info"},
+				Display: Display{
+					Limits: DisplayLimits{
+						SummaryLength:      100,
+						MaxContentLength:   5000,
+						MaxThreadDepth:     10,
+						MaxRepliesInFeed:   3,
+						TruncateIndicator:  "...",
+						MaxThreadReplies:   200,
+						MaxSearchResults:   50,
+						MaxArchivePageSize: 100,
+						PageSizes: PageSizes{
+							Notes: MaxPageSize + 1, Articles: 50, Replies: 50, Mentions: 50,
+							Outbox: 50, Profile: 50, Contacts: 50, Archive: 50, Tags: 50,
+						},
+					},
+				},
+			},
+			wantErr: true,
+			errMsg:  "page_sizes.notes",
+		},
+		{
+			name: "translation without a server",
+			cfg: func() *Config {
+				cfg := Default()
+				cfg.Identity.Npub = "npub1nq3zgtqruwhnz0xx40gh4a4fkamlr2sc7ke5wqs2s3nyv2fpy9esg4hdwq"
+				cfg.Rendering.Translation.Enabled = true
+				return cfg
+			}(),
+			wantErr: true,
+			errMsg:  "rendering.translation.url",
+		},
+		{
+			name: "negative translation rate limit",
+			cfg: func() *Config {
+				cfg := Default()
+				cfg.Identity.Npub = "npub1nq3zgtqruwhnz0xx40gh4a4fkamlr2sc7ke5wqs2s3nyv2fpy9esg4hdwq"
+				cfg.Rendering.Translation.Enabled = true
+				cfg.Rendering.Translation.URL = "https://libretranslate.example.com"
+				cfg.Rendering.Translation.RequestsPerMinute = -1
+				return cfg
+			}(),
+			wantErr: true,
+			errMsg:  "requests_per_minute",
+		},
+		{
+			name: "author override without a pubkey",
+			cfg: func() *Config {
+				cfg := Default()
+				cfg.Identity.Npub = "npub1nq3zgtqruwhnz0xx40gh4a4fkamlr2sc7ke5wqs2s3nyv2fpy9esg4hdwq"
+				cfg.Rendering.Authors = []AuthorOverride{{Pubkey: "alice", Collapse: true}}
+				return cfg
+			}(),
+			wantErr: true,
+			errMsg:  "rendering.authors",
+		},
 	}
 
 	for _, tt := range tests {
@@ -209,6 +324,39 @@ func TestValidate(t *testing.T) {
 	}
 }
 
+func TestValidateFingerRendering(t *testing.T) {
+	cfg := Default()
+	cfg.Identity.Npub = "npub1nq3zgtqruwhnz0xx40gh4a4fkamlr2sc7ke5wqs2s3nyv2fpy9esg4hdwq"
+	if err := Validate(cfg); err != nil {
+		t.Fatalf("Default config should validate: %v", err)
+	}
+
+	cfg.Rendering.Finger.Width = 10
+	if err := Validate(cfg); err == nil || !strings.Contains(err.Error(), "rendering.finger.width") {
+		t.Errorf("Expected a finger width error, got %v", err)
+	}
+
+	cfg.Rendering.Finger.Width = 80
+	cfg.Rendering.Finger.PlanSource = "kind_3"
+	if err := Validate(cfg); err == nil || !strings.Contains(err.Error(), "plan source") {
+		t.Errorf("Expected a plan source error, got %v", err)
+	}
+}
+
+func TestValidateMediaLinks(t *testing.T) {
+	cfg := Default()
+	cfg.Identity.Npub = "npub1nq3zgtqruwhnz0xx40gh4a4fkamlr2sc7ke5wqs2s3nyv2fpy9esg4hdwq"
+	cfg.Rendering.Gopher.MediaLinks = MediaLinksText
+	if err := Validate(cfg); err != nil {
+		t.Fatalf("The text style should validate: %v", err)
+	}
+
+	cfg.Rendering.Gemini.MediaLinks = "buttons"
+	if err := Validate(cfg); err == nil || !strings.Contains(err.Error(), "rendering.gemini.media_links") {
+		t.Errorf("Expected a media_links error, got %v", err)
+	}
+}
+
 func TestLoad(t *testing.T) {
 	// Create a temporary directory for test files
 	tmpDir := t.TempDir()
@@ -266,6 +414,9 @@ logging:
 				if !cfg.Protocols.Gopher.Enabled {
 					t.Error("Expected Gopher to be enabled")
 				}
+				if cfg.Sync.Retention.TrashGraceDays != 7 {
+					t.Errorf("Expected trash_grace_days to default to 7, got %d", cfg.Sync.Retention.TrashGraceDays)
+				}
 			},
 		},
 		{
@@ -397,3 +548,41 @@ func TestGetExampleConfig(t *testing.T) {
 		t.Error("Example config doesn't contain expected YAML structure")
 	}
 }
+
+func TestEnvFieldOverrides(t *testing.T) {
+	t.Setenv("NOPHR_PROTOCOLS_GOPHER_PORT", "7070")
+	t.Setenv("NOPHR_PROTOCOLS_FINGER_ENABLED", "true")
+	t.Setenv("NOPHR_SITE_TITLE", "From Env")
+	t.Setenv("NOPHR_RELAYS_SEEDS", "wss://a.test, wss://b.test")
+	t.Setenv("NOPHR_SYNC_RETENTION_ADVANCED_ENABLED", "true")
+
+	cfg := Default()
+	if err := applyEnvOverrides(cfg); err != nil {
+		t.Fatalf("applyEnvOverrides() failed: %v", err)
+	}
+
+	if cfg.Protocols.Gopher.Port != 7070 {
+		t.Errorf("Expected Gopher port 7070, got %d", cfg.Protocols.Gopher.Port)
+	}
+	if !cfg.Protocols.Finger.Enabled {
+		t.Error("Expected Finger to be enabled from env")
+	}
+	if cfg.Site.Title != "From Env" {
+		t.Errorf("Expected site title from env, got %s", cfg.Site.Title)
+	}
+	if len(cfg.Relays.Seeds) != 2 || cfg.Relays.Seeds[1] != "wss://b.test" {
+		t.Errorf("Expected two seeds from env, got %v", cfg.Relays.Seeds)
+	}
+	if cfg.Sync.Retention.Advanced == nil || !cfg.Sync.Retention.Advanced.Enabled {
+		t.Error("Expected advanced retention to be allocated and enabled from env")
+	}
+
+	t.Setenv("NOPHR_PROTOCOLS_GOPHER_PORT", "seventy")
+	err := applyEnvOverrides(Default())
+	if err == nil {
+		t.Fatal("Expected error for non-numeric port")
+	}
+	if !strings.Contains(err.Error(), "NOPHR_PROTOCOLS_GOPHER_PORT") {
+		t.Errorf("Expected error to name the variable, got %v", err)
+	}
+}
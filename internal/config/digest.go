@@ -0,0 +1,101 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Digest configures the weekly summary the owner publishes to Nostr
+type Digest struct {
+	Enabled  bool   `yaml:"enabled"`
+	Kind     int    `yaml:"kind"`      // 1 (note) or 30023 (long-form article)
+	Weekday  string `yaml:"weekday"`   // Day the digest is published, e.g. "monday"
+	TopPosts int    `yaml:"top_posts"` // Number of top posts by engagement to list
+}
+
+// DefaultDigest returns the default digest settings
+func DefaultDigest() Digest {
+	return Digest{
+		Enabled:  false,
+		Kind:     1,
+		Weekday:  "monday",
+		TopPosts: 5,
+	}
+}
+
+// PublishDay returns the configured weekday
+func (d *Digest) PublishDay() (time.Weekday, error) {
+	for day := time.Sunday; day <= time.Saturday; day++ {
+		if strings.EqualFold(d.Weekday, day.String()) {
+			return day, nil
+		}
+	}
+	return time.Sunday, fmt.Errorf("outbox.digest.weekday must be a day of the week, got %q", d.Weekday)
+}
+
+// Validate checks if the digest settings are valid
+func (d *Digest) Validate() error {
+	if !d.Enabled {
+		return nil
+	}
+
+	if d.Kind != 1 && d.Kind != 30023 {
+		return fmt.Errorf("outbox.digest.kind must be 1 or 30023")
+	}
+	if _, err := d.PublishDay(); err != nil {
+		return err
+	}
+	if d.TopPosts < 1 || d.TopPosts > 20 {
+		return fmt.Errorf("outbox.digest.top_posts must be between 1 and 20")
+	}
+
+	return nil
+}
+
+// DailyDigest configures the daily summary served at /digest over Gopher and
+// Gemini and, shortened, by the QOTD listener
+type DailyDigest struct {
+	RegenerateAt string `yaml:"regenerate_at"` // UTC time of day, "HH:MM", when a new day's digest is built
+	TopPosts     int    `yaml:"top_posts"`     // Posts listed by interactions received during the day
+	MaxPosts     int    `yaml:"max_posts"`     // New posts listed
+}
+
+// DefaultDailyDigest returns the default daily digest settings
+func DefaultDailyDigest() DailyDigest {
+	return DailyDigest{
+		RegenerateAt: "00:00",
+		TopPosts:     5,
+		MaxPosts:     20,
+	}
+}
+
+// LatestRun returns the most recent regeneration time at or before now. The
+// digest built then covers the 24 hours before it.
+func (d *DailyDigest) LatestRun(now time.Time) time.Time {
+	at, err := time.Parse("15:04", d.RegenerateAt)
+	if err != nil {
+		at = time.Time{}
+	}
+
+	now = now.UTC()
+	run := time.Date(now.Year(), now.Month(), now.Day(), at.Hour(), at.Minute(), 0, 0, time.UTC)
+	if run.After(now) {
+		run = run.AddDate(0, 0, -1)
+	}
+	return run
+}
+
+// Validate checks if the daily digest settings are valid
+func (d *DailyDigest) Validate() error {
+	if _, err := time.Parse("15:04", d.RegenerateAt); err != nil {
+		return fmt.Errorf("display.digest.regenerate_at must be a UTC time like 06:00, got %q", d.RegenerateAt)
+	}
+	if d.TopPosts < 1 || d.TopPosts > 20 {
+		return fmt.Errorf("display.digest.top_posts must be between 1 and 20")
+	}
+	if d.MaxPosts < 1 || d.MaxPosts > 100 {
+		return fmt.Errorf("display.digest.max_posts must be between 1 and 100")
+	}
+	return nil
+}
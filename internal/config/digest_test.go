@@ -0,0 +1,60 @@
+package config
+
+import (
+	"testing"
+	"time"
+)
+
+func TestDigestValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		digest  Digest
+		wantErr bool
+	}{
+		{"disabled ignores fields", Digest{Enabled: false, Kind: 7}, false},
+		{"note on monday", Digest{Enabled: true, Kind: 1, Weekday: "monday", TopPosts: 5}, false},
+		{"article, mixed case day", Digest{Enabled: true, Kind: 30023, Weekday: "Friday", TopPosts: 1}, false},
+		{"unsupported kind", Digest{Enabled: true, Kind: 7, Weekday: "monday", TopPosts: 5}, true},
+		{"unknown weekday", Digest{Enabled: true, Kind: 1, Weekday: "someday", TopPosts: 5}, true},
+		{"too many top posts", Digest{Enabled: true, Kind: 1, Weekday: "monday", TopPosts: 21}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.digest.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+
+	d := Digest{Weekday: "Sunday"}
+	if day, err := d.PublishDay(); err != nil || day != time.Sunday {
+		t.Errorf("PublishDay() = %v, %v, want Sunday", day, err)
+	}
+}
+
+func TestDailyDigest(t *testing.T) {
+	d := DefaultDailyDigest()
+	if err := d.Validate(); err != nil {
+		t.Fatalf("Defaults should validate: %v", err)
+	}
+
+	d.RegenerateAt = "06:30"
+	before := time.Date(2026, time.March, 2, 6, 29, 0, 0, time.UTC)
+	if run := d.LatestRun(before); !run.Equal(time.Date(2026, time.March, 1, 6, 30, 0, 0, time.UTC)) {
+		t.Errorf("LatestRun() before the time of day = %v, want the day before", run)
+	}
+	if run := d.LatestRun(before.Add(time.Minute)); !run.Equal(time.Date(2026, time.March, 2, 6, 30, 0, 0, time.UTC)) {
+		t.Errorf("LatestRun() at the time of day = %v, want that day", run)
+	}
+
+	for _, bad := range []DailyDigest{
+		{RegenerateAt: "25:00", TopPosts: 5, MaxPosts: 20},
+		{RegenerateAt: "06:00", TopPosts: 0, MaxPosts: 20},
+		{RegenerateAt: "06:00", TopPosts: 5, MaxPosts: 101},
+	} {
+		if err := bad.Validate(); err == nil {
+			t.Errorf("Expected %+v to be rejected", bad)
+		}
+	}
+}
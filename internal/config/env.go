@@ -0,0 +1,139 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// envPrefix is prepended to every environment override name
+const envPrefix = "NOPHR"
+
+// applyEnvFields walks a config struct and applies NOPHR_* environment variables to its fields.
+// Variable names are the upper-cased yaml tag path joined by underscores, so
+// protocols.gopher.port is set by NOPHR_PROTOCOLS_GOPHER_PORT. Scalars and slices of
+// scalars (comma separated) are supported; maps and slices of structs are left to the file.
+// Returns true if any field was set.
+func applyEnvFields(v reflect.Value, prefix string) (bool, error) {
+	applied := false
+	t := v.Type()
+
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+
+		tag, _, _ := strings.Cut(field.Tag.Get("yaml"), ",")
+		if tag == "-" {
+			continue
+		}
+		if tag == "" {
+			tag = field.Name
+		}
+
+		name := prefix + "_" + strings.ToUpper(tag)
+		fv := v.Field(i)
+
+		switch {
+		case fv.Kind() == reflect.Struct:
+			set, err := applyEnvFields(fv, name)
+			if err != nil {
+				return false, err
+			}
+			applied = applied || set
+
+		case fv.Kind() == reflect.Ptr && fv.Type().Elem().Kind() == reflect.Struct:
+			// Only allocate optional blocks when a variable actually targets them
+			target := fv
+			if fv.IsNil() {
+				target = reflect.New(fv.Type().Elem())
+			}
+			set, err := applyEnvFields(target.Elem(), name)
+			if err != nil {
+				return false, err
+			}
+			if set && fv.IsNil() {
+				fv.Set(target)
+			}
+			applied = applied || set
+
+		default:
+			value, ok := os.LookupEnv(name)
+			if !ok || value == "" {
+				continue
+			}
+			if err := setEnvValue(fv, name, value); err != nil {
+				return false, err
+			}
+			applied = true
+		}
+	}
+
+	return applied, nil
+}
+
+// setEnvValue parses value into a scalar or scalar slice field
+func setEnvValue(fv reflect.Value, name, value string) error {
+	if fv.Kind() == reflect.Slice {
+		elemKind := fv.Type().Elem().Kind()
+		if elemKind == reflect.Struct || elemKind == reflect.Map || elemKind == reflect.Slice || elemKind == reflect.Interface {
+			return fmt.Errorf("%s: cannot override a list of %s from the environment", name, fv.Type().Elem())
+		}
+
+		parts := strings.Split(value, ",")
+		slice := reflect.MakeSlice(fv.Type(), len(parts), len(parts))
+		for i, part := range parts {
+			if err := setScalar(slice.Index(i), name, strings.TrimSpace(part)); err != nil {
+				return err
+			}
+		}
+		fv.Set(slice)
+		return nil
+	}
+
+	return setScalar(fv, name, value)
+}
+
+// setScalar parses a single value into a string, bool or numeric field
+func setScalar(fv reflect.Value, name, value string) error {
+	switch fv.Kind() {
+	case reflect.String:
+		fv.SetString(value)
+
+	case reflect.Bool:
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return fmt.Errorf("%s: %q is not a valid boolean", name, value)
+		}
+		fv.SetBool(b)
+
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(value, 10, fv.Type().Bits())
+		if err != nil {
+			return fmt.Errorf("%s: %q is not a valid integer", name, value)
+		}
+		fv.SetInt(n)
+
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, err := strconv.ParseUint(value, 10, fv.Type().Bits())
+		if err != nil {
+			return fmt.Errorf("%s: %q is not a valid unsigned integer", name, value)
+		}
+		fv.SetUint(n)
+
+	case reflect.Float32, reflect.Float64:
+		f, err := strconv.ParseFloat(value, fv.Type().Bits())
+		if err != nil {
+			return fmt.Errorf("%s: %q is not a valid number", name, value)
+		}
+		fv.SetFloat(f)
+
+	default:
+		return fmt.Errorf("%s: cannot override a %s from the environment", name, fv.Type())
+	}
+
+	return nil
+}
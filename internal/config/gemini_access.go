@@ -0,0 +1,74 @@
+package config
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// Gemini client certificate access levels, lowest first
+const (
+	AccessLevelAny    = "any"    // Any client certificate, known or not
+	AccessLevelReader = "reader" // A certificate listed in identities
+	AccessLevelOwner  = "owner"  // A certificate listed with owner access
+)
+
+var accessLevelRanks = map[string]int{
+	AccessLevelAny:    1,
+	AccessLevelReader: 2,
+	AccessLevelOwner:  3,
+}
+
+var fingerprintPattern = regexp.MustCompile(`^[0-9a-f]{64}$`)
+
+// GeminiAccess restricts Gemini paths to clients presenting a certificate
+type GeminiAccess struct {
+	Identities []ClientIdentity `yaml:"identities"` // Known client certificates
+	Paths      []PathAccess     `yaml:"paths"`      // Paths that need a certificate
+}
+
+// ClientIdentity maps a client certificate fingerprint to an access level
+type ClientIdentity struct {
+	Name        string `yaml:"name"`
+	Fingerprint string `yaml:"fingerprint"` // SHA-256 of the DER certificate, hex (colons allowed)
+	Level       string `yaml:"level"`       // reader|owner
+}
+
+// PathAccess requires a minimum access level for a path and everything below it
+type PathAccess struct {
+	Prefix string `yaml:"prefix"` // e.g. "/inbox"
+	Level  string `yaml:"level"`  // any|reader|owner
+}
+
+// AccessRank returns the rank of an access level, 0 if unknown
+func AccessRank(level string) int {
+	return accessLevelRanks[level]
+}
+
+// NormalizeFingerprint lowercases a fingerprint and strips colon separators
+func NormalizeFingerprint(fingerprint string) string {
+	return strings.ToLower(strings.ReplaceAll(fingerprint, ":", ""))
+}
+
+// Validate checks identities and path rules
+func (a *GeminiAccess) Validate() error {
+	for _, identity := range a.Identities {
+		if !fingerprintPattern.MatchString(NormalizeFingerprint(identity.Fingerprint)) {
+			return fmt.Errorf("protocols.gemini.access: identity %q fingerprint must be a SHA-256 hex digest", identity.Name)
+		}
+		if identity.Level != AccessLevelReader && identity.Level != AccessLevelOwner {
+			return fmt.Errorf("protocols.gemini.access: identity %q level must be reader or owner", identity.Name)
+		}
+	}
+
+	for _, rule := range a.Paths {
+		if !strings.HasPrefix(rule.Prefix, "/") {
+			return fmt.Errorf("protocols.gemini.access: path prefix %q must start with /", rule.Prefix)
+		}
+		if AccessRank(rule.Level) == 0 {
+			return fmt.Errorf("protocols.gemini.access: path %s level must be any, reader or owner", rule.Prefix)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,44 @@
+package config
+
+import "fmt"
+
+// Idle configures shedding resources while no requests arrive, for small
+// hosts: the memory cache shrinks, sync slows down and relay connections
+// that aren't in use are closed, until the next request wakes everything
+type Idle struct {
+	Enabled            bool `yaml:"enabled"`
+	AfterMinutes       int  `yaml:"after_minutes"`        // Minutes without a request before shedding
+	CacheMaxMB         int  `yaml:"cache_max_mb"`         // Memory cache target while idle
+	SyncIntervalFactor int  `yaml:"sync_interval_factor"` // Sync intervals are multiplied by this while idle
+	KeepRelays         bool `yaml:"keep_relays"`          // Leave idle relay connections open
+}
+
+// DefaultIdle returns the default idle settings
+func DefaultIdle() Idle {
+	return Idle{
+		Enabled:            false,
+		AfterMinutes:       15,
+		CacheMaxMB:         8,
+		SyncIntervalFactor: 6,
+		KeepRelays:         false,
+	}
+}
+
+// Validate checks if the idle settings are valid
+func (i *Idle) Validate() error {
+	if !i.Enabled {
+		return nil
+	}
+
+	if i.AfterMinutes < 1 {
+		return fmt.Errorf("idle.after_minutes must be at least 1")
+	}
+	if i.CacheMaxMB < 1 {
+		return fmt.Errorf("idle.cache_max_mb must be at least 1")
+	}
+	if i.SyncIntervalFactor < 1 {
+		return fmt.Errorf("idle.sync_interval_factor must be at least 1")
+	}
+
+	return nil
+}
@@ -0,0 +1,26 @@
+package config
+
+import "testing"
+
+func TestIdleValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		idle    Idle
+		wantErr bool
+	}{
+		{"disabled ignores fields", Idle{Enabled: false}, false},
+		{"defaults", func() Idle { i := DefaultIdle(); i.Enabled = true; return i }(), false},
+		{"sync unchanged", Idle{Enabled: true, AfterMinutes: 10, CacheMaxMB: 4, SyncIntervalFactor: 1}, false},
+		{"no timeout", Idle{Enabled: true, CacheMaxMB: 4, SyncIntervalFactor: 4}, true},
+		{"no cache", Idle{Enabled: true, AfterMinutes: 10, SyncIntervalFactor: 4}, true},
+		{"no sync factor", Idle{Enabled: true, AfterMinutes: 10, CacheMaxMB: 4}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.idle.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
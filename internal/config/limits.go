@@ -0,0 +1,55 @@
+package config
+
+import "fmt"
+
+// IngestLimits caps the size of events stored during sync. Events authored by the
+// owner are never limited.
+type IngestLimits struct {
+	MaxContentBytes int               `yaml:"max_content_bytes"` // 0 = unlimited
+	MaxTags         int               `yaml:"max_tags"`          // 0 = unlimited
+	Kinds           map[int]KindLimit `yaml:"kinds"`             // Per-kind limits, replacing the two above
+	Action          string            `yaml:"action"`            // "reject" (default) or "truncate"
+}
+
+// KindLimit caps the size of events of one kind (0 = unlimited)
+type KindLimit struct {
+	MaxContentBytes int `yaml:"max_content_bytes"`
+	MaxTags         int `yaml:"max_tags"`
+}
+
+// DefaultIngestLimits returns the default ingest limits
+func DefaultIngestLimits() IngestLimits {
+	return IngestLimits{
+		MaxContentBytes: 32 * 1024,
+		MaxTags:         500,
+		Kinds: map[int]KindLimit{
+			3:     {MaxContentBytes: 32 * 1024}, // Contact lists carry one tag per follow
+			30023: {MaxContentBytes: 256 * 1024, MaxTags: 500},
+		},
+		Action: "reject",
+	}
+}
+
+// For returns the limits that apply to events of a kind
+func (l *IngestLimits) For(kind int) KindLimit {
+	if limit, ok := l.Kinds[kind]; ok {
+		return limit
+	}
+	return KindLimit{MaxContentBytes: l.MaxContentBytes, MaxTags: l.MaxTags}
+}
+
+// Validate checks if the ingest limits are valid
+func (l *IngestLimits) Validate() error {
+	if l.Action != "" && l.Action != "reject" && l.Action != "truncate" {
+		return fmt.Errorf("sync.limits.action must be reject or truncate, got %q", l.Action)
+	}
+	if l.MaxContentBytes < 0 || l.MaxTags < 0 {
+		return fmt.Errorf("sync.limits.max_content_bytes and max_tags must be >= 0")
+	}
+	for kind, limit := range l.Kinds {
+		if limit.MaxContentBytes < 0 || limit.MaxTags < 0 {
+			return fmt.Errorf("sync.limits.kinds.%d limits must be >= 0", kind)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,94 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestIngestLimitsValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		limits  IngestLimits
+		wantErr bool
+	}{
+		{"zero values", IngestLimits{}, false},
+		{"defaults", DefaultIngestLimits(), false},
+		{"truncate", IngestLimits{MaxContentBytes: 1024, Action: "truncate"}, false},
+		{"unknown action", IngestLimits{Action: "drop"}, true},
+		{"negative size", IngestLimits{MaxContentBytes: -1}, true},
+		{"negative kind tags", IngestLimits{Kinds: map[int]KindLimit{1: {MaxTags: -1}}}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.limits.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestIngestLimitsFor(t *testing.T) {
+	limits := DefaultIngestLimits()
+
+	if got := limits.For(1); got.MaxContentBytes != limits.MaxContentBytes || got.MaxTags != limits.MaxTags {
+		t.Errorf("For(1) = %+v, want the global limits", got)
+	}
+	if got := limits.For(3); got.MaxTags != 0 {
+		t.Errorf("For(3) should not limit contact list tags, got %+v", got)
+	}
+	if got := limits.For(30023); got.MaxContentBytes <= limits.MaxContentBytes {
+		t.Errorf("For(30023) should allow longer articles, got %+v", got)
+	}
+}
+
+func TestIngestLimitsDefaults(t *testing.T) {
+	dir := t.TempDir()
+	load := func(yaml string) *IngestLimits {
+		t.Helper()
+		path := filepath.Join(dir, "nophr.yaml")
+		if err := os.WriteFile(path, []byte(yaml), 0644); err != nil {
+			t.Fatalf("Failed to write config: %v", err)
+		}
+		cfg, err := Load(path)
+		if err != nil {
+			t.Fatalf("Load() error = %v", err)
+		}
+		return &cfg.Sync.Limits
+	}
+
+	base := `
+identity:
+  npub: "npub1nq3zgtqruwhnz0xx40gh4a4fkamlr2sc7ke5wqs2s3nyv2fpy9esg4hdwq"
+
+protocols:
+  gopher:
+    enabled: true
+    port: 70
+
+relays:
+  seeds:
+    - "wss://relay.test"
+
+storage:
+  driver: "sqlite"
+
+logging:
+  level: "info"
+
+sync:
+  scope:
+    mode: "self"
+`
+
+	limits := load(base)
+	if limits.MaxContentBytes != DefaultIngestLimits().MaxContentBytes || limits.Action != "reject" {
+		t.Errorf("Expected default limits when the section is absent, got %+v", limits)
+	}
+
+	limits = load(base + "  limits:\n    max_tags: 50\n")
+	if limits.MaxContentBytes != 0 || limits.MaxTags != 50 || limits.Action != "reject" {
+		t.Errorf("Expected only max_tags to be limited, got %+v", limits)
+	}
+}
@@ -0,0 +1,57 @@
+package config
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// groupComponent is one dot-separated part of a newsgroup name (RFC 3977 section 4.1)
+var groupComponent = regexp.MustCompile(`^[a-z0-9+_-]+$`)
+
+// applyNNTPDefaults fills in every NNTP setting but enabled
+func applyNNTPDefaults(nntp, defaults *NNTPProtocol) {
+	if nntp.Host == "" {
+		nntp.Host = defaults.Host
+	}
+	if nntp.Port == 0 {
+		nntp.Port = defaults.Port
+	}
+	if nntp.Bind == "" {
+		nntp.Bind = defaults.Bind
+	}
+	if nntp.GroupPrefix == "" {
+		nntp.GroupPrefix = defaults.GroupPrefix
+	}
+	if nntp.MaxArticles == 0 {
+		nntp.MaxArticles = defaults.MaxArticles
+	}
+}
+
+// validateNNTP checks the settings of an enabled NNTP server
+func validateNNTP(nntp *NNTPProtocol) error {
+	if nntp.Port < 1 || nntp.Port > 65535 {
+		return fmt.Errorf("nntp port must be between 1 and 65535")
+	}
+	if nntp.Host == "" {
+		return fmt.Errorf("protocols.nntp.host is required for Message-IDs")
+	}
+	if !ValidGroupName(nntp.GroupPrefix) {
+		return fmt.Errorf("protocols.nntp.group_prefix %q must be lowercase letters, digits, '+', '_' or '-', separated by dots", nntp.GroupPrefix)
+	}
+	if nntp.MaxArticles < 1 || nntp.MaxArticles > 1000 {
+		return fmt.Errorf("protocols.nntp.max_articles must be between 1 and 1000")
+	}
+	return nil
+}
+
+// ValidGroupName reports whether name is a newsgroup name that newsreaders accept:
+// lowercase dot-separated components with no empty parts
+func ValidGroupName(name string) bool {
+	for _, part := range strings.Split(name, ".") {
+		if !groupComponent.MatchString(part) {
+			return false
+		}
+	}
+	return true
+}
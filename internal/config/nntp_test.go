@@ -0,0 +1,57 @@
+package config
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestValidGroupName(t *testing.T) {
+	tests := []struct {
+		name string
+		want bool
+	}{
+		{"nophr", true},
+		{"alice.nostr", true},
+		{"my-site+news_1", true},
+		{"", false},
+		{"Nophr", false},
+		{"nophr.", false},
+		{"nophr..notes", false},
+		{"no phr", false},
+	}
+
+	for _, tt := range tests {
+		if got := ValidGroupName(tt.name); got != tt.want {
+			t.Errorf("ValidGroupName(%q) = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestValidateNNTP(t *testing.T) {
+	valid := Default().Protocols.NNTP
+	if err := validateNNTP(&valid); err != nil {
+		t.Fatalf("default NNTP settings rejected: %v", err)
+	}
+
+	tests := []struct {
+		name   string
+		modify func(*NNTPProtocol)
+		errMsg string
+	}{
+		{"bad port", func(n *NNTPProtocol) { n.Port = 70000 }, "port"},
+		{"no host", func(n *NNTPProtocol) { n.Host = "" }, "host"},
+		{"bad prefix", func(n *NNTPProtocol) { n.GroupPrefix = "My Site" }, "group_prefix"},
+		{"too many articles", func(n *NNTPProtocol) { n.MaxArticles = 5000 }, "max_articles"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			nntp := Default().Protocols.NNTP
+			tt.modify(&nntp)
+			err := validateNNTP(&nntp)
+			if err == nil || !strings.Contains(err.Error(), tt.errMsg) {
+				t.Errorf("expected error containing %q, got %v", tt.errMsg, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,74 @@
+package config
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// Page source formats for pages[].format
+const (
+	PageFormatMarkdown = "markdown"
+	PageFormatGemtext  = "gemtext"
+)
+
+var pagePathPattern = regexp.MustCompile(`^/[a-z0-9._-]+(/[a-z0-9._-]+)*$`)
+
+// Page is a static page, like /about or /now, served over Gopher and Gemini
+// and listed on the home menu. Its source is inline content or a file.
+type Page struct {
+	Path    string `yaml:"path"`    // e.g. "/now"
+	Title   string `yaml:"title"`   // Home menu entry; the source's first heading if empty
+	Content string `yaml:"content"` // Inline source
+	File    string `yaml:"file"`    // Source file, read on every request
+	Format  string `yaml:"format"`  // markdown (default) or gemtext; .gmi files default to gemtext
+}
+
+// SourceFormat returns the page's format, guessing from the file extension if unset
+func (p *Page) SourceFormat() string {
+	if p.Format != "" {
+		return p.Format
+	}
+	return PageFormatFor(p.File)
+}
+
+// PageFormatFor returns the format of a page source file by its extension
+func PageFormatFor(file string) string {
+	switch strings.ToLower(filepath.Ext(file)) {
+	case ".gmi", ".gemini":
+		return PageFormatGemtext
+	}
+	return PageFormatMarkdown
+}
+
+// validatePages checks page paths are unique, don't shadow sections and have one source
+func validatePages(cfg *Config) error {
+	seen := make(map[string]bool)
+	sectionPaths := make(map[string]bool)
+	for _, section := range cfg.Sections {
+		sectionPaths[section.Path] = true
+	}
+
+	for _, page := range cfg.Pages {
+		if !pagePathPattern.MatchString(page.Path) {
+			return fmt.Errorf("page path %q must start with '/' and use lowercase letters, digits, '.', '_' or '-'", page.Path)
+		}
+		if seen[page.Path] {
+			return fmt.Errorf("duplicate page path: %s", page.Path)
+		}
+		seen[page.Path] = true
+
+		if sectionPaths[page.Path] {
+			return fmt.Errorf("page %s conflicts with a section at the same path", page.Path)
+		}
+		if (page.Content == "") == (page.File == "") {
+			return fmt.Errorf("page %s needs either content or file", page.Path)
+		}
+		if format := page.Format; format != "" && format != PageFormatMarkdown && format != PageFormatGemtext {
+			return fmt.Errorf("invalid format for page %s: %s (must be markdown or gemtext)", page.Path, format)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,52 @@
+package config
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestValidatePages(t *testing.T) {
+	valid := &Config{Pages: []Page{
+		{Path: "/now", Content: "# Now\n\nBuilding things."},
+		{Path: "/uses", File: "pages/uses.gmi"},
+	}}
+	if err := validatePages(valid); err != nil {
+		t.Fatalf("valid pages rejected: %v", err)
+	}
+	if got := valid.Pages[1].SourceFormat(); got != PageFormatGemtext {
+		t.Errorf("expected .gmi file to default to gemtext, got %s", got)
+	}
+
+	tests := []struct {
+		name   string
+		pages  []Page
+		errMsg string
+	}{
+		{"root path", []Page{{Path: "/", Content: "x"}}, "must start with '/'"},
+		{"relative path", []Page{{Path: "now", Content: "x"}}, "must start with '/'"},
+		{"duplicate path", []Page{{Path: "/now", Content: "x"}, {Path: "/now", Content: "y"}}, "duplicate"},
+		{"no source", []Page{{Path: "/now"}}, "either content or file"},
+		{"two sources", []Page{{Path: "/now", Content: "x", File: "now.md"}}, "either content or file"},
+		{"bad format", []Page{{Path: "/now", Content: "x", Format: "html"}}, "invalid format"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validatePages(&Config{Pages: tt.pages})
+			if err == nil {
+				t.Fatalf("expected error containing %q", tt.errMsg)
+			}
+			if !strings.Contains(err.Error(), tt.errMsg) {
+				t.Errorf("expected error containing %q, got %v", tt.errMsg, err)
+			}
+		})
+	}
+
+	conflict := &Config{
+		Sections: []SectionConfig{{Name: "diy", Path: "/diy"}},
+		Pages:    []Page{{Path: "/diy", Content: "x"}},
+	}
+	if err := validatePages(conflict); err == nil || !strings.Contains(err.Error(), "conflicts with a section") {
+		t.Errorf("expected section conflict, got %v", err)
+	}
+}
@@ -0,0 +1,229 @@
+package config
+
+import (
+	"fmt"
+	"sort"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Profile is a named set of display, behavior and section settings that
+// listeners can use instead of the top-level ones, so one process can serve
+// e.g. a LAN Gopher listener showing the inbox and drafts next to a public one
+// that hides them. Any setting a profile leaves out keeps its top-level value.
+type Profile = yaml.Node
+
+// profileSettings are the sections a profile may set
+type profileSettings struct {
+	Display      Display         `yaml:"display"`
+	Behavior     Behavior        `yaml:"behavior"`
+	Presentation Presentation    `yaml:"presentation"`
+	Pages        []Page          `yaml:"pages"`
+	PagesDir     string          `yaml:"pages_dir"`
+	Sections     []SectionConfig `yaml:"sections"`
+}
+
+// profileKeys are the keys allowed in a profile
+var profileKeys = map[string]bool{
+	"display":      true,
+	"behavior":     true,
+	"presentation": true,
+	"pages":        true,
+	"pages_dir":    true,
+	"sections":     true,
+}
+
+// Listener is an additional listener for a protocol, serving on its own
+// address with its own profile
+type Listener struct {
+	Host    string `yaml:"host"` // Hostname in generated links, defaults to the protocol's host
+	Port    int    `yaml:"port"`
+	Bind    string `yaml:"bind"`    // Defaults to the protocol's bind
+	Profile string `yaml:"profile"` // Name in profiles, or empty for the top-level settings
+}
+
+// ProfileNames returns the configured profile names, sorted
+func (c *Config) ProfileNames() []string {
+	names := make([]string, 0, len(c.Profiles))
+	for name := range c.Profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// ProfileSets reports whether a profile sets a top-level key, e.g. "sections"
+func (c *Config) ProfileSets(name, key string) bool {
+	node, ok := c.Profiles[name]
+	if !ok || node.Kind != yaml.MappingNode {
+		return false
+	}
+	for i := 0; i < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			return true
+		}
+	}
+	return false
+}
+
+// WithProfile returns a copy of the configuration with a profile's settings
+// applied over the top-level ones, or c itself for the empty name. The copy
+// has no profiles or additional listeners of its own.
+func (c *Config) WithProfile(name string) (*Config, error) {
+	if name == "" {
+		return c, nil
+	}
+	node, ok := c.Profiles[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown profile: %s", name)
+	}
+	if node.Kind != yaml.MappingNode {
+		return nil, fmt.Errorf("profile %s must be a mapping", name)
+	}
+	for i := 0; i < len(node.Content); i += 2 {
+		if key := node.Content[i].Value; !profileKeys[key] {
+			return nil, fmt.Errorf("profile %s: %s can't be set per profile (only display, behavior, presentation, pages, pages_dir and sections)", name, key)
+		}
+	}
+
+	// Round trip the top-level settings so the profile's maps and slices
+	// don't share memory with them
+	data, err := yaml.Marshal(profileSettings{
+		Display:      c.Display,
+		Behavior:     c.Behavior,
+		Presentation: c.Presentation,
+		Pages:        c.Pages,
+		PagesDir:     c.PagesDir,
+		Sections:     c.Sections,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("profile %s: %w", name, err)
+	}
+	var settings profileSettings
+	if err := yaml.Unmarshal(data, &settings); err != nil {
+		return nil, fmt.Errorf("profile %s: %w", name, err)
+	}
+	if err := node.Decode(&settings); err != nil {
+		return nil, fmt.Errorf("profile %s: %w", name, err)
+	}
+
+	profiled := *c
+	profiled.Display = settings.Display
+	profiled.Behavior = settings.Behavior
+	profiled.Presentation = settings.Presentation
+	profiled.Pages = settings.Pages
+	profiled.PagesDir = settings.PagesDir
+	profiled.Sections = settings.Sections
+	profiled.Profiles = nil
+	profiled.Protocols.Gopher.Profile, profiled.Protocols.Gopher.Listeners = "", nil
+	profiled.Protocols.Gemini.Profile, profiled.Protocols.Gemini.Listeners = "", nil
+	return &profiled, nil
+}
+
+// ForGopherListener returns the configuration a Gopher listener serves with:
+// its profile's settings, and its address in protocols.gopher with
+// protocols.gopher.listeners cleared
+func (c *Config) ForGopherListener(l Listener) (*Config, error) {
+	profiled, err := c.WithProfile(l.Profile)
+	if err != nil {
+		return nil, err
+	}
+	if profiled == c {
+		copied := *c
+		profiled = &copied
+	}
+	profiled.Protocols.Gopher.Host = listenerValue(l.Host, c.Protocols.Gopher.Host)
+	profiled.Protocols.Gopher.Bind = listenerValue(l.Bind, listenerValue(c.Protocols.Gopher.Bind, c.Protocols.Gopher.Host))
+	profiled.Protocols.Gopher.Port = l.Port
+	profiled.Protocols.Gopher.Profile = l.Profile
+	profiled.Protocols.Gopher.Listeners = nil
+	profiled.Protocols.Gemini.Listeners = nil
+	return profiled, nil
+}
+
+// ForGeminiListener returns the configuration a Gemini listener serves with,
+// like ForGopherListener. TLS, Titan and wallet settings are shared.
+func (c *Config) ForGeminiListener(l Listener) (*Config, error) {
+	profiled, err := c.WithProfile(l.Profile)
+	if err != nil {
+		return nil, err
+	}
+	if profiled == c {
+		copied := *c
+		profiled = &copied
+	}
+	profiled.Protocols.Gemini.Host = listenerValue(l.Host, c.Protocols.Gemini.Host)
+	profiled.Protocols.Gemini.Bind = listenerValue(l.Bind, listenerValue(c.Protocols.Gemini.Bind, c.Protocols.Gemini.Host))
+	profiled.Protocols.Gemini.Port = l.Port
+	profiled.Protocols.Gemini.Profile = l.Profile
+	profiled.Protocols.Gopher.Listeners = nil
+	profiled.Protocols.Gemini.Listeners = nil
+	return profiled, nil
+}
+
+// EnabledListeners returns the main Gopher listener followed by the additional
+// ones, or nothing if Gopher is disabled
+func (g GopherProtocol) EnabledListeners() []Listener {
+	if !g.Enabled {
+		return nil
+	}
+	main := Listener{Host: g.Host, Port: g.Port, Bind: g.Bind, Profile: g.Profile}
+	return append([]Listener{main}, g.Listeners...)
+}
+
+// EnabledListeners returns the main Gemini listener followed by the additional
+// ones, or nothing if Gemini is disabled
+func (g GeminiProtocol) EnabledListeners() []Listener {
+	if !g.Enabled {
+		return nil
+	}
+	main := Listener{Host: g.Host, Port: g.Port, Bind: g.Bind, Profile: g.Profile}
+	return append([]Listener{main}, g.Listeners...)
+}
+
+func listenerValue(value, fallback string) string {
+	if value != "" {
+		return value
+	}
+	return fallback
+}
+
+// validateProfiles checks that every profile applies cleanly and is valid,
+// and that listeners name existing profiles on free ports
+func validateProfiles(cfg *Config) error {
+	for _, name := range cfg.ProfileNames() {
+		profiled, err := cfg.WithProfile(name)
+		if err != nil {
+			return err
+		}
+		if err := Validate(profiled); err != nil {
+			return fmt.Errorf("profile %s: %w", name, err)
+		}
+	}
+
+	check := func(protocol, profile, bind string, main int, listeners []Listener) error {
+		if _, ok := cfg.Profiles[profile]; profile != "" && !ok {
+			return fmt.Errorf("protocols.%s.profile: unknown profile %s", protocol, profile)
+		}
+		addrs := map[string]bool{fmt.Sprintf("%s:%d", bind, main): true}
+		for _, l := range listeners {
+			if l.Port < 1 || l.Port > 65535 {
+				return fmt.Errorf("protocols.%s.listeners: port must be between 1 and 65535", protocol)
+			}
+			addr := fmt.Sprintf("%s:%d", listenerValue(l.Bind, bind), l.Port)
+			if addrs[addr] {
+				return fmt.Errorf("protocols.%s.listeners: %s is used twice", protocol, addr)
+			}
+			addrs[addr] = true
+			if _, ok := cfg.Profiles[l.Profile]; l.Profile != "" && !ok {
+				return fmt.Errorf("protocols.%s.listeners: unknown profile %s", protocol, l.Profile)
+			}
+		}
+		return nil
+	}
+	gopher, gemini := cfg.Protocols.Gopher, cfg.Protocols.Gemini
+	if err := check("gopher", gopher.Profile, listenerValue(gopher.Bind, gopher.Host), gopher.Port, gopher.Listeners); err != nil {
+		return err
+	}
+	return check("gemini", gemini.Profile, listenerValue(gemini.Bind, gemini.Host), gemini.Port, gemini.Listeners)
+}
@@ -0,0 +1,155 @@
+package config
+
+import (
+	"strings"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+)
+
+func loadProfileConfig(t *testing.T, data string) (*Config, error) {
+	t.Helper()
+	cfg := Default()
+	if err := yaml.Unmarshal([]byte(data), cfg); err != nil {
+		t.Fatalf("yaml.Unmarshal() error = %v", err)
+	}
+	applyDefaults(cfg)
+	return cfg, Validate(cfg)
+}
+
+const profileBase = `
+identity:
+  npub: npub1nq3zgtqruwhnz0xx40gh4a4fkamlr2sc7ke5wqs2s3nyv2fpy9esg4hdwq
+relays:
+  seeds: [wss://relay.test]
+display:
+  hide_inbox: true
+  limits:
+    summary_length: 80
+sections:
+  - name: drafts
+    path: /drafts
+    filters:
+      kinds: [30024]
+    hidden: true
+`
+
+func TestWithProfile(t *testing.T) {
+	cfg, err := loadProfileConfig(t, profileBase+`
+profiles:
+  lan:
+    display:
+      hide_inbox: false
+    sections:
+      - name: drafts
+        path: /drafts
+        filters:
+          kinds: [30024]
+protocols:
+  gopher:
+    enabled: true
+    host: gopher.example
+    port: 70
+    listeners:
+      - bind: 192.168.1.2
+        port: 70
+        profile: lan
+`)
+	if err != nil {
+		t.Fatalf("Validate() error = %v", err)
+	}
+
+	listeners := cfg.Protocols.Gopher.EnabledListeners()
+	if len(listeners) != 2 || listeners[0].Profile != "" || listeners[1].Profile != "lan" {
+		t.Fatalf("EnabledListeners() = %+v", listeners)
+	}
+
+	lan, err := cfg.ForGopherListener(listeners[1])
+	if err != nil {
+		t.Fatalf("ForGopherListener() error = %v", err)
+	}
+	if lan.Display.HideInbox {
+		t.Error("lan profile didn't show the inbox")
+	}
+	if lan.Display.Limits.SummaryLength != 80 {
+		t.Errorf("SummaryLength = %d, want 80 inherited from the top level", lan.Display.Limits.SummaryLength)
+	}
+	if len(lan.Sections) != 1 || lan.Sections[0].Hidden {
+		t.Errorf("lan sections = %+v, want drafts listed", lan.Sections)
+	}
+	if gopher := lan.Protocols.Gopher; gopher.Bind != "192.168.1.2" || gopher.Host != "gopher.example" || gopher.Listeners != nil {
+		t.Errorf("lan protocols.gopher = %+v", gopher)
+	}
+	if !cfg.ProfileSets("lan", "sections") || cfg.ProfileSets("lan", "pages") {
+		t.Error("ProfileSets() didn't report the keys the profile sets")
+	}
+
+	// The top-level settings are left alone
+	if !cfg.Display.HideInbox || !cfg.Sections[0].Hidden || len(cfg.Protocols.Gopher.Listeners) != 1 {
+		t.Error("applying a profile changed the top-level configuration")
+	}
+}
+
+func TestValidateProfiles(t *testing.T) {
+	tests := []struct {
+		name   string
+		extra  string
+		errMsg string
+	}{
+		{
+			name: "unknown listener profile",
+			extra: `
+protocols:
+  gopher:
+    enabled: true
+    listeners:
+      - port: 7070
+        profile: lan
+`,
+			errMsg: "unknown profile lan",
+		},
+		{
+			name: "key a profile can't set",
+			extra: `
+profiles:
+  lan:
+    storage:
+      driver: lmdb
+`,
+			errMsg: "storage can't be set per profile",
+		},
+		{
+			name: "invalid profile setting",
+			extra: `
+profiles:
+  lan:
+    behavior:
+      sort_preferences:
+        notes: random
+`,
+			errMsg: "profile lan",
+		},
+		{
+			name: "listener on the main address",
+			extra: `
+protocols:
+  gopher:
+    enabled: true
+    bind: 127.0.0.1
+    port: 70
+    listeners:
+      - port: 70
+`,
+			errMsg: "127.0.0.1:70 is used twice",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := loadProfileConfig(t, profileBase+tt.extra)
+			if err == nil || !strings.Contains(err.Error(), tt.errMsg) {
+				t.Errorf("Validate() error = %v, want %q", err, tt.errMsg)
+			}
+		})
+	}
+}
@@ -9,6 +9,30 @@ type AdvancedRetention struct {
 	GlobalCaps GlobalCaps        `yaml:"global_caps"`
 	Rules      []RetentionRule   `yaml:"rules"`
 	Evaluation EvaluationConfig  `yaml:"evaluation"`
+	Scoring    *ScoringWeights   `yaml:"scoring,omitempty"` // nil = DefaultScoringWeights
+}
+
+// ScoringWeights controls how the cap enforcement score is computed.
+// When the scoring block is present every weight must be set explicitly;
+// omitted weights count as zero.
+type ScoringWeights struct {
+	RulePriority   int         `yaml:"rule_priority"`   // Points per rule priority unit
+	Owner          int         `yaml:"owner"`           // Flat bonus for owner content
+	SocialDistance int         `yaml:"social_distance"` // Points per step closer than 10 hops
+	Age            int         `yaml:"age"`             // Points per month younger than 10 months
+	Interactions   int         `yaml:"interactions"`    // Points per interaction unit (max 10 units)
+	Kinds          map[int]int `yaml:"kinds"`           // Flat bonus (or penalty) per kind
+}
+
+// DefaultScoringWeights returns the built-in scoring weights
+func DefaultScoringWeights() *ScoringWeights {
+	return &ScoringWeights{
+		RulePriority:   100,
+		Owner:          1000,
+		SocialDistance: 100,
+		Age:            10,
+		Interactions:   5,
+	}
 }
 
 // GlobalCaps defines hard limits on storage
@@ -117,6 +141,12 @@ func (a *AdvancedRetention) Validate() error {
 		}
 	}
 
+	if a.Scoring != nil {
+		if err := a.Scoring.Validate(); err != nil {
+			return err
+		}
+	}
+
 	// Set defaults for evaluation
 	if a.Evaluation.OnIngest {
 		if a.Evaluation.BatchSize == 0 {
@@ -144,3 +174,11 @@ func (a *RetentionAction) Validate() error {
 
 	return nil
 }
+
+// Validate checks if scoring weights are valid
+func (w *ScoringWeights) Validate() error {
+	if w.RulePriority < 0 || w.Owner < 0 || w.SocialDistance < 0 || w.Age < 0 || w.Interactions < 0 {
+		return fmt.Errorf("advanced.scoring weights must be >= 0 (use kinds for penalties)")
+	}
+	return nil
+}
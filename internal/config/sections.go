@@ -0,0 +1,74 @@
+package config
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+var (
+	sectionSortFields  = []string{"created_at", "published_at", "reactions", "zaps", "replies"}
+	sectionSortOrders  = []string{"asc", "desc"}
+	sectionScopes      = []string{"self", "following", "mutual", "foaf", "all"}
+	sectionGroupFields = []string{"day", "week", "month", "year", "author", "kind"}
+	sectionTypes       = []string{"planet", "list", "mirror"}
+)
+
+// validateSections checks section names are unique, paths are absolute, the
+// sort, scope and grouping are known and more links point at a section
+func validateSections(cfg *Config) error {
+	names := make(map[string]bool)
+	for _, section := range cfg.Sections {
+		if section.Name == "" {
+			return fmt.Errorf("section at %s needs a name", section.Path)
+		}
+		if names[section.Name] {
+			return fmt.Errorf("duplicate section name: %s", section.Name)
+		}
+		names[section.Name] = true
+
+		if !strings.HasPrefix(section.Path, "/") {
+			return fmt.Errorf("path of section %s must start with '/': %q", section.Name, section.Path)
+		}
+		if section.SortBy != "" && !slices.Contains(sectionSortFields, section.SortBy) {
+			return fmt.Errorf("invalid sort_by for section %s: %s (must be one of %s)", section.Name, section.SortBy, strings.Join(sectionSortFields, ", "))
+		}
+		if section.SortOrder != "" && !slices.Contains(sectionSortOrders, section.SortOrder) {
+			return fmt.Errorf("invalid sort_order for section %s: %s (must be asc or desc)", section.Name, section.SortOrder)
+		}
+		if scope := section.Filters.Scope; scope != "" && !slices.Contains(sectionScopes, scope) {
+			return fmt.Errorf("invalid scope for section %s: %s (must be one of %s)", section.Name, scope, strings.Join(sectionScopes, ", "))
+		}
+		if section.GroupBy != "" && !slices.Contains(sectionGroupFields, section.GroupBy) {
+			return fmt.Errorf("invalid group_by for section %s: %s (must be one of %s)", section.Name, section.GroupBy, strings.Join(sectionGroupFields, ", "))
+		}
+		if section.Type != "" && !slices.Contains(sectionTypes, section.Type) {
+			return fmt.Errorf("invalid type for section %s: %s (must be one of %s, or empty)", section.Name, section.Type, strings.Join(sectionTypes, ", "))
+		}
+		if section.Type == "list" && len(section.Filters.Kinds) == 0 {
+			return fmt.Errorf("list section %s needs the kinds of its lists, e.g. [10003, 30001]", section.Name)
+		}
+		if section.Type == "planet" && len(section.Filters.Authors) == 0 && section.Filters.Scope == "" {
+			return fmt.Errorf("planet section %s needs authors or a scope", section.Name)
+		}
+		if section.Type == "mirror" {
+			if len(section.Filters.Authors) != 1 || section.Filters.Scope != "" {
+				return fmt.Errorf("mirror section %s needs exactly one author and no scope", section.Name)
+			}
+			if strings.TrimSuffix(section.Path, "/") == "" {
+				return fmt.Errorf("mirror section %s needs a path of its own, e.g. /mirror/alice", section.Name)
+			}
+		}
+		if section.Limit < 0 {
+			return fmt.Errorf("limit of section %s must be positive", section.Name)
+		}
+	}
+
+	for _, section := range cfg.Sections {
+		if section.MoreLink != nil && !names[section.MoreLink.SectionRef] {
+			return fmt.Errorf("more_link of section %s points at unknown section: %s", section.Name, section.MoreLink.SectionRef)
+		}
+	}
+
+	return nil
+}
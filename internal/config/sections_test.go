@@ -0,0 +1,47 @@
+package config
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestValidateSections(t *testing.T) {
+	valid := &Config{Sections: []SectionConfig{
+		{Name: "diy-preview", Path: "/", MoreLink: &SectionMoreLinkConfig{Text: "More", SectionRef: "diy"}},
+		{Name: "diy", Path: "/diy", SortBy: "reactions", SortOrder: "desc", Filters: SectionFilterConfig{Scope: "following"}},
+	}}
+	if err := validateSections(valid); err != nil {
+		t.Fatalf("valid sections rejected: %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		sections []SectionConfig
+		errMsg   string
+	}{
+		{"missing name", []SectionConfig{{Path: "/diy"}}, "needs a name"},
+		{"duplicate name", []SectionConfig{{Name: "diy", Path: "/diy"}, {Name: "diy", Path: "/make"}}, "duplicate"},
+		{"relative path", []SectionConfig{{Name: "diy", Path: "diy"}}, "must start with '/'"},
+		{"unknown sort", []SectionConfig{{Name: "diy", Path: "/diy", SortBy: "likes"}}, "invalid sort_by"},
+		{"unknown order", []SectionConfig{{Name: "diy", Path: "/diy", SortOrder: "newest"}}, "invalid sort_order"},
+		{"unknown scope", []SectionConfig{{Name: "diy", Path: "/diy", Filters: SectionFilterConfig{Scope: "friends"}}}, "invalid scope"},
+		{"unknown type", []SectionConfig{{Name: "diy", Path: "/diy", Type: "blogroll"}}, "invalid type"},
+		{"list without kinds", []SectionConfig{{Name: "bookmarks", Path: "/bookmarks", Type: "list"}}, "needs the kinds"},
+		{"planet without authors", []SectionConfig{{Name: "planet", Path: "/planet", Type: "planet"}}, "needs authors"},
+		{"mirror of two authors", []SectionConfig{{Name: "alice", Path: "/mirror/alice", Type: "mirror", Filters: SectionFilterConfig{Authors: []string{"npub1a", "npub1b"}}}}, "exactly one author"},
+		{"mirror at the root", []SectionConfig{{Name: "alice", Path: "/", Type: "mirror", Filters: SectionFilterConfig{Authors: []string{"npub1a"}}}}, "path of its own"},
+		{"dangling more link", []SectionConfig{{Name: "diy", Path: "/", MoreLink: &SectionMoreLinkConfig{SectionRef: "missing"}}}, "unknown section"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateSections(&Config{Sections: tt.sections})
+			if err == nil {
+				t.Fatalf("expected error containing %q", tt.errMsg)
+			}
+			if !strings.Contains(err.Error(), tt.errMsg) {
+				t.Errorf("expected error containing %q, got %v", tt.errMsg, err)
+			}
+		})
+	}
+}
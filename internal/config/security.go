@@ -0,0 +1,58 @@
+package config
+
+import "fmt"
+
+// Security contains protocol server hardening settings
+type Security struct {
+	RateLimit RateLimit `yaml:"rate_limit"`
+	Admin     Admin     `yaml:"admin"`
+}
+
+// RateLimit configures per-IP request limiting for the protocol servers
+type RateLimit struct {
+	Enabled            bool `yaml:"enabled"`
+	RequestsPerMinute  int  `yaml:"requests_per_minute"`  // Sustained request rate per client IP
+	Burst              int  `yaml:"burst"`                // Requests allowed in a short burst before limiting
+	BanDurationSeconds int  `yaml:"ban_duration_seconds"` // 0 = no ban, just reject until tokens refill
+}
+
+// Admin configures the control API for managing a running instance: HTTP over
+// a unix socket that only the user running nophr can connect to
+type Admin struct {
+	Enabled bool   `yaml:"enabled"`
+	Socket  string `yaml:"socket"` // Path of the unix socket, created with 0600 permissions
+}
+
+// DefaultSecurity returns the default security settings
+func DefaultSecurity() Security {
+	return Security{
+		RateLimit: RateLimit{
+			Enabled:            true,
+			RequestsPerMinute:  60,
+			Burst:              20,
+			BanDurationSeconds: 300,
+		},
+		Admin: Admin{
+			Socket: "./data/nophr.sock",
+		},
+	}
+}
+
+// Validate checks if the rate limit settings are valid
+func (r *RateLimit) Validate() error {
+	if !r.Enabled {
+		return nil
+	}
+
+	if r.RequestsPerMinute < 1 {
+		return fmt.Errorf("security.rate_limit.requests_per_minute must be at least 1")
+	}
+	if r.Burst < 1 {
+		return fmt.Errorf("security.rate_limit.burst must be at least 1")
+	}
+	if r.BanDurationSeconds < 0 {
+		return fmt.Errorf("security.rate_limit.ban_duration_seconds must be >= 0")
+	}
+
+	return nil
+}
@@ -0,0 +1,77 @@
+package config
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+)
+
+// Translation providers for rendering.translation.provider
+const (
+	TranslationLibreTranslate = "libretranslate"
+	TranslationCommand        = "command"
+)
+
+var translationLangPattern = regexp.MustCompile(`^[a-z]{2,3}(-[A-Za-z]{2,4})?$`)
+
+// Translation translates notes on demand, from a "Translate" link on the
+// Gemini note page, through a LibreTranslate server or a local command
+type Translation struct {
+	Enabled           bool     `yaml:"enabled"`
+	Provider          string   `yaml:"provider"`            // libretranslate (default) or command
+	URL               string   `yaml:"url"`                 // LibreTranslate server, e.g. https://libretranslate.example.com
+	APIKey            string   `yaml:"-"`                   // LibreTranslate API key from NOPHR_TRANSLATE_API_KEY
+	Command           []string `yaml:"command"`             // Program and arguments; the note is on stdin and {lang} is the target
+	Languages         []string `yaml:"languages"`           // Target languages offered on note pages
+	MaxChars          int      `yaml:"max_chars"`           // Longer notes aren't translated
+	TimeoutSeconds    int      `yaml:"timeout_seconds"`     // How long a translation may take
+	MaxConcurrent     int      `yaml:"max_concurrent"`      // Translations run at once; the rest wait their turn
+	RequestsPerMinute int      `yaml:"requests_per_minute"` // Translation pages each client may open per minute
+}
+
+// DefaultTranslation returns the default translation settings
+func DefaultTranslation() Translation {
+	return Translation{
+		Enabled:           false,
+		Provider:          TranslationLibreTranslate,
+		Languages:         []string{"en"},
+		MaxChars:          5000,
+		TimeoutSeconds:    20,
+		MaxConcurrent:     2,
+		RequestsPerMinute: 10,
+	}
+}
+
+// Validate checks if the translation settings are valid
+func (t *Translation) Validate() error {
+	if !t.Enabled {
+		return nil
+	}
+
+	switch t.Provider {
+	case TranslationLibreTranslate:
+		u, err := url.Parse(t.URL)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return fmt.Errorf("rendering.translation.url must be an http(s) URL for the libretranslate provider")
+		}
+	case TranslationCommand:
+		if len(t.Command) == 0 || t.Command[0] == "" {
+			return fmt.Errorf("rendering.translation.command is required for the command provider")
+		}
+	default:
+		return fmt.Errorf("rendering.translation.provider must be libretranslate or command, got %q", t.Provider)
+	}
+
+	if len(t.Languages) == 0 {
+		return fmt.Errorf("rendering.translation.languages must list at least one language")
+	}
+	for _, lang := range t.Languages {
+		if !translationLangPattern.MatchString(lang) {
+			return fmt.Errorf("rendering.translation.languages: invalid language code %q", lang)
+		}
+	}
+	if t.MaxChars < 0 || t.TimeoutSeconds < 0 || t.MaxConcurrent < 0 || t.RequestsPerMinute < 0 {
+		return fmt.Errorf("rendering.translation max_chars, timeout_seconds, max_concurrent and requests_per_minute must not be negative")
+	}
+	return nil
+}
@@ -0,0 +1,70 @@
+package conformance
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// UpdateEnv rewrites golden files with the current output when set
+const UpdateEnv = "NOPHR_UPDATE_GOLDEN"
+
+// GoldenDir is where golden files live, relative to the test's package
+var GoldenDir = filepath.Join("testdata", "golden")
+
+// Golden compares got byte for byte with the named golden file. It does nothing
+// when rendering with an operator config, and rewrites the file when UpdateEnv
+// is set.
+func Golden(t testing.TB, name string, got []byte) {
+	t.Helper()
+
+	if OperatorConfig() {
+		return
+	}
+
+	path := filepath.Join(GoldenDir, name)
+	if os.Getenv(UpdateEnv) != "" {
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatalf("Failed to create golden directory: %v", err)
+		}
+		if err := os.WriteFile(path, got, 0644); err != nil {
+			t.Fatalf("Failed to write golden file: %v", err)
+		}
+		return
+	}
+
+	want, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Failed to read golden file (run with %s=1 to create it): %v", UpdateEnv, err)
+	}
+	if bytes.Equal(got, want) {
+		return
+	}
+
+	gotLines := strings.SplitAfter(string(got), "\n")
+	wantLines := strings.SplitAfter(string(want), "\n")
+	for i := 0; i < len(gotLines) || i < len(wantLines); i++ {
+		var g, w string
+		if i < len(gotLines) {
+			g = gotLines[i]
+		}
+		if i < len(wantLines) {
+			w = wantLines[i]
+		}
+		if g != w {
+			t.Errorf("%s differs from golden file at line %d:\n got: %q\nwant: %q\n(run with %s=1 to accept the new output)", name, i+1, g, w, UpdateEnv)
+			return
+		}
+	}
+}
+
+// Check reports each protocol rule a response breaks
+func Check(t testing.TB, name string, violations []Violation) {
+	t.Helper()
+
+	for _, v := range violations {
+		t.Errorf("%s: %s", name, v)
+	}
+}
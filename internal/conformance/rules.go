@@ -0,0 +1,226 @@
+package conformance
+
+import (
+	"bytes"
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// Gopher limits per RFC 1436 and common client practice
+const maxGopherSelector = 255
+
+// Gemini limits per the Gemini specification
+const maxGeminiMeta = 1024
+
+// Violation is one broken protocol rule in a response
+type Violation struct {
+	Line   int    // 1-based line number, 0 for the response as a whole
+	Rule   string // Short rule name, e.g. "crlf"
+	Detail string
+}
+
+func (v Violation) String() string {
+	if v.Line == 0 {
+		return fmt.Sprintf("%s: %s", v.Rule, v.Detail)
+	}
+	return fmt.Sprintf("line %d: %s: %s", v.Line, v.Rule, v.Detail)
+}
+
+// CheckGophermap checks a Gopher menu (RFC 1436): CRLF-terminated item lines of
+// a type character and four tab-separated fields, ending with a lone "."
+func CheckGophermap(data []byte) []Violation {
+	var violations []Violation
+
+	for i, line := range splitTerminated(data, &violations) {
+		n := i + 1
+		if !strings.HasSuffix(line, "\r\n") {
+			violations = append(violations, Violation{n, "crlf", "item line must end with CRLF"})
+		}
+		line = strings.TrimSuffix(strings.TrimSuffix(line, "\n"), "\r")
+		violations = append(violations, checkLineBytes(n, line)...)
+
+		if line == "." {
+			// Already reported by splitTerminated
+			continue
+		}
+		if line == "" {
+			violations = append(violations, Violation{n, "item", "empty line in menu"})
+			continue
+		}
+
+		fields := strings.Split(line[1:], "\t")
+		// Gopher+ servers append a fifth "+" or "?" field
+		if len(fields) == 5 && (fields[4] == "+" || fields[4] == "?") {
+			fields = fields[:4]
+		}
+		if len(fields) != 4 {
+			violations = append(violations, Violation{n, "fields", fmt.Sprintf("want 4 tab-separated fields, got %d", len(fields))})
+			continue
+		}
+		if len(fields[1]) > maxGopherSelector {
+			violations = append(violations, Violation{n, "selector", fmt.Sprintf("selector is %d bytes, limit %d", len(fields[1]), maxGopherSelector)})
+		}
+		if fields[2] == "" {
+			violations = append(violations, Violation{n, "host", "empty host"})
+		}
+		if port, err := strconv.Atoi(fields[3]); err != nil || port < 0 || port > 65535 {
+			violations = append(violations, Violation{n, "port", fmt.Sprintf("invalid port %q", fields[3])})
+		}
+	}
+
+	return violations
+}
+
+// CheckGopherText checks a Gopher text file: no lone "." line before the
+// terminating one, which would end the transfer early
+func CheckGopherText(data []byte) []Violation {
+	var violations []Violation
+
+	for i, line := range splitTerminated(data, &violations) {
+		line = strings.TrimSuffix(strings.TrimSuffix(line, "\n"), "\r")
+		violations = append(violations, checkLineBytes(i+1, line)...)
+	}
+	return violations
+}
+
+// splitTerminated splits a Gopher response into lines, checks that it ends with
+// a lone "." and returns the lines before it
+func splitTerminated(data []byte, violations *[]Violation) []string {
+	lines := splitLines(string(data))
+	if !bytes.HasSuffix(data, []byte("\n.\r\n")) && string(data) != ".\r\n" {
+		*violations = append(*violations, Violation{0, "terminator", "response must end with a lone \".\" line"})
+		return lines
+	}
+
+	body := lines[:len(lines)-1]
+	for i, line := range body {
+		if strings.TrimRight(line, "\r\n") == "." {
+			*violations = append(*violations, Violation{i + 1, "terminator", "lone \".\" before the end of the response"})
+		}
+	}
+	return body
+}
+
+// CheckGeminiResponse checks a Gemini response: a "<status> <meta>" CRLF header
+// and, for text/gemini success responses, a well-formed gemtext body
+func CheckGeminiResponse(data []byte) []Violation {
+	var violations []Violation
+
+	end := bytes.Index(data, []byte("\r\n"))
+	if end < 0 {
+		return append(violations, Violation{1, "header", "header must end with CRLF"})
+	}
+	header := string(data[:end])
+	body := data[end+2:]
+
+	if len(header) < 3 || header[2] != ' ' {
+		return append(violations, Violation{1, "header", fmt.Sprintf("want \"<status> <meta>\", got %q", header)})
+	}
+	status, err := strconv.Atoi(header[:2])
+	if err != nil || status < 10 || status > 69 {
+		violations = append(violations, Violation{1, "status", fmt.Sprintf("invalid status %q", header[:2])})
+	}
+	meta := header[3:]
+	if len(meta) > maxGeminiMeta {
+		violations = append(violations, Violation{1, "meta", fmt.Sprintf("meta is %d bytes, limit %d", len(meta), maxGeminiMeta)})
+	}
+	if !utf8.ValidString(meta) {
+		violations = append(violations, Violation{1, "utf8", "meta is not valid UTF-8"})
+	}
+
+	if status/10 != 2 {
+		if len(body) > 0 {
+			violations = append(violations, Violation{2, "body", fmt.Sprintf("status %d must not have a body", status)})
+		}
+		return violations
+	}
+	if meta == "" {
+		violations = append(violations, Violation{1, "meta", "success response needs a MIME type"})
+	}
+	if !strings.HasPrefix(meta, "text/gemini") {
+		return violations
+	}
+
+	return append(violations, CheckGemtext(body, 2)...)
+}
+
+// CheckGemtext checks a gemtext document whose first line is numbered first:
+// valid UTF-8, no stray carriage returns, link lines with a URL, and balanced
+// preformatting toggles
+func CheckGemtext(data []byte, first int) []Violation {
+	var violations []Violation
+
+	preformatted := false
+	toggleLine := 0
+	for i, line := range splitLines(string(data)) {
+		n := first + i
+		line = strings.TrimSuffix(strings.TrimSuffix(line, "\n"), "\r")
+		violations = append(violations, checkLineBytes(n, line)...)
+
+		if strings.HasPrefix(line, "```") {
+			preformatted = !preformatted
+			toggleLine = n
+			continue
+		}
+		if preformatted {
+			continue
+		}
+
+		if strings.HasPrefix(line, "=>") {
+			fields := strings.Fields(line[2:])
+			if len(fields) == 0 {
+				violations = append(violations, Violation{n, "link", "link line without a URL"})
+			}
+		}
+	}
+
+	if preformatted {
+		violations = append(violations, Violation{toggleLine, "preformat", "preformatted block is never closed"})
+	}
+	return violations
+}
+
+// CheckFinger checks a Finger response (RFC 1288): CRLF line endings and no
+// control characters other than tabs
+func CheckFinger(data []byte) []Violation {
+	var violations []Violation
+
+	if len(data) > 0 && !bytes.HasSuffix(data, []byte("\r\n")) {
+		violations = append(violations, Violation{0, "crlf", "response must end with CRLF"})
+	}
+	for i, line := range splitLines(string(data)) {
+		n := i + 1
+		if strings.HasSuffix(line, "\n") && !strings.HasSuffix(line, "\r\n") {
+			violations = append(violations, Violation{n, "crlf", "line ends with a bare LF"})
+		}
+		violations = append(violations, checkLineBytes(n, strings.TrimSuffix(strings.TrimSuffix(line, "\n"), "\r"))...)
+	}
+	return violations
+}
+
+// checkLineBytes checks one line without its terminator: valid UTF-8, and no
+// control characters other than tabs (a stray CR shows up here)
+func checkLineBytes(n int, line string) []Violation {
+	var violations []Violation
+	if !utf8.ValidString(line) {
+		violations = append(violations, Violation{n, "utf8", "line is not valid UTF-8"})
+	}
+	for _, r := range line {
+		if r < 0x20 && r != '\t' || r == 0x7f {
+			violations = append(violations, Violation{n, "control", fmt.Sprintf("control character %U", r)})
+			break
+		}
+	}
+	return violations
+}
+
+// splitLines splits s after each LF, keeping the terminators
+func splitLines(s string) []string {
+	lines := strings.SplitAfter(s, "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
@@ -0,0 +1,98 @@
+package conformance
+
+import (
+	"testing"
+)
+
+func TestCheckGophermap(t *testing.T) {
+	tests := []struct {
+		name  string
+		data  string
+		rules []string
+	}{
+		{"valid menu", "iHello\tfake\tlocalhost\t70\r\n1Notes\t/notes\tlocalhost\t70\r\n.\r\n", nil},
+		{"empty menu", ".\r\n", nil},
+		{"gopher+ field", "1Notes\t/notes\tlocalhost\t70\t+\r\n.\r\n", nil},
+		{"missing terminator", "iHello\tfake\tlocalhost\t70\r\n", []string{"terminator"}},
+		{"bare LF", "iHello\tfake\tlocalhost\t70\n.\r\n", []string{"crlf"}},
+		{"tab in display", "iHello\tworld\tfake\tlocalhost\t70\r\n.\r\n", []string{"fields"}},
+		{"stray CR", "iHello\r\tfake\tlocalhost\t70\r\n.\r\n", []string{"control"}},
+		{"empty line", "\r\n.\r\n", []string{"item"}},
+		{"bad port", "1Notes\t/notes\tlocalhost\tseventy\r\n.\r\n", []string{"port"}},
+		{"early terminator", ".\r\n1Notes\t/notes\tlocalhost\t70\r\n.\r\n", []string{"terminator"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assertRules(t, CheckGophermap([]byte(tt.data)), tt.rules)
+		})
+	}
+}
+
+func TestCheckGeminiResponse(t *testing.T) {
+	tests := []struct {
+		name  string
+		data  string
+		rules []string
+	}{
+		{"valid page", "20 text/gemini\r\n# Title\n=> /notes Notes\n```\ncode\n```\n", nil},
+		{"input prompt", "10 Enter search query:\r\n", nil},
+		{"missing CRLF", "20 text/gemini\n# Title\n", []string{"header"}},
+		{"bad status", "99 text/gemini\r\n", []string{"status"}},
+		{"error with body", "51 Not found\r\nsorry\n", []string{"body"}},
+		{"empty link", "20 text/gemini\r\n=>\n", []string{"link"}},
+		{"unclosed preformat", "20 text/gemini\r\n```\ncode\n", []string{"preformat"}},
+		{"invalid utf8", "20 text/gemini\r\n\xff\n", []string{"utf8"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assertRules(t, CheckGeminiResponse([]byte(tt.data)), tt.rules)
+		})
+	}
+}
+
+func TestCheckFinger(t *testing.T) {
+	tests := []struct {
+		name  string
+		data  string
+		rules []string
+	}{
+		{"valid response", "Login: alice\r\nPlan:\r\nHello\r\n", nil},
+		{"bare LF", "Login: alice\nPlan:\r\n", []string{"crlf"}},
+		{"doubled CR", "User not found\r\r\n", []string{"control"}},
+		{"no final CRLF", "Login: alice", []string{"crlf"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assertRules(t, CheckFinger([]byte(tt.data)), tt.rules)
+		})
+	}
+}
+
+func TestFixtureIsStable(t *testing.T) {
+	a, b := NewFixture(), NewFixture()
+	for i, event := range a.Events() {
+		if event.ID != b.Events()[i].ID {
+			t.Errorf("Fixture event %d has a different ID on each build", i)
+		}
+		if ok, err := event.CheckSignature(); !ok || err != nil {
+			t.Errorf("Fixture event %d has an invalid signature: %v", i, err)
+		}
+	}
+}
+
+// assertRules checks that violations break exactly the given rules, in order
+func assertRules(t *testing.T, violations []Violation, rules []string) {
+	t.Helper()
+
+	if len(violations) != len(rules) {
+		t.Fatalf("Expected rules %v, got %v", rules, violations)
+	}
+	for i, v := range violations {
+		if v.Rule != rules[i] {
+			t.Errorf("Expected rule %q, got %v", rules[i], v)
+		}
+	}
+}
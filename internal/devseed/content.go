@@ -0,0 +1,130 @@
+package devseed
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Words and phrases the synthetic text is assembled from. The mix covers what
+// renderers have to cope with: plain sentences, markdown, hashtags, links,
+// mentions, emoji and multi-paragraph posts.
+var (
+	names = []string{
+		"alice", "bob", "carol", "dave", "erin", "frank", "grace", "heidi",
+		"ivan", "judy", "mallory", "niaj", "olivia", "peggy", "rupert", "sybil",
+		"trent", "ursula", "victor", "wendy", "xavier", "yolanda", "zed", "quinn",
+	}
+
+	interests = []string{
+		"gopher holes", "gemini capsules", "nostr relays", "ham radio", "sourdough",
+		"trail running", "film photography", "mechanical keyboards", "retro computing",
+		"bitcoin", "self-hosting", "birdwatching", "chess", "synthesizers", "gardening",
+	}
+
+	topics = []string{
+		"nostr", "gopher", "gemini", "smolweb", "selfhosting", "bitcoin",
+		"retrocomputing", "radio", "coffee", "photography", "music", "books",
+	}
+
+	sentences = []string{
+		"Spent the morning tidying up my %s setup.",
+		"Anyone else still excited about %s after all these years?",
+		"Hot take: %s is better when it stays small.",
+		"Finally wrote down my notes on %s.",
+		"Reading old mailing list threads about %s tonight.",
+		"The best part of %s is the people you meet along the way.",
+		"Trying a new approach to %s this week, will report back.",
+		"Is there a good guide to %s for complete beginners?",
+		"Quiet day. Just me, a pot of tea and %s.",
+		"Three things I learned about %s today, in no particular order.",
+		"Small tools, plain text and %s. That's the whole plan.",
+		"Not sure why %s clicked for me now and not ten years ago.",
+	}
+
+	replies = []string{
+		"Totally agree.",
+		"Interesting, I had the opposite experience.",
+		"Do you have a link where I can read more?",
+		"This is the way.",
+		"Ha, same here!",
+		"Bookmarking this for later.",
+		"Thanks for writing this up.",
+		"I tried that once and it went badly, but maybe I was doing it wrong.",
+		"Could you say more about the second part?",
+		"Great point about keeping things small.",
+		"Welcome back!",
+		"💯",
+	}
+
+	sites = []string{
+		"https://example.com/notes",
+		"https://example.org/blog/plain-text",
+		"https://example.net/wiki/Gopher_(protocol)",
+		"https://example.com/photos/2024",
+	}
+
+	reactions = []string{"+", "+", "+", "+", "🤙", "🔥", "❤️", "😂", "-"}
+
+	zapAmounts = []int64{21, 21, 100, 210, 500, 1000, 2100, 5000, 21000}
+)
+
+// note writes the body of a top-level note
+func (g *generator) note() (string, []string) {
+	var hashtags []string
+	paragraphs := []string{g.sentence()}
+
+	if g.chance(0.4) {
+		paragraphs[0] += " " + g.sentence()
+	}
+	if g.chance(0.25) {
+		paragraphs = append(paragraphs, g.sentence())
+	}
+	if g.chance(0.15) {
+		paragraphs = append(paragraphs, "Some **bold** thoughts and a bit of `code` for good measure.")
+	}
+	if g.chance(0.2) {
+		paragraphs = append(paragraphs, "More here: "+g.pick(sites))
+	}
+	if g.chance(0.35) {
+		tag := g.pick(topics)
+		hashtags = append(hashtags, tag)
+		paragraphs[len(paragraphs)-1] += " #" + tag
+	}
+
+	return strings.Join(paragraphs, "\n\n"), hashtags
+}
+
+// reply writes the body of a reply
+func (g *generator) reply() string {
+	text := g.pick(replies)
+	if g.chance(0.3) {
+		text += " " + g.sentence()
+	}
+	return text
+}
+
+// articleText writes a long-form article, returning its title, summary and body
+func (g *generator) articleText() (string, string, string) {
+	interest := g.pick(interests)
+	title := fmt.Sprintf("Notes on %s", interest)
+	summary := fmt.Sprintf("What a year of %s taught me.", interest)
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "# %s\n\n", title)
+	fmt.Fprintf(&b, "%s %s\n\n", g.sentence(), g.sentence())
+	b.WriteString("## Getting started\n\n")
+	b.WriteString("- Start small\n- Write things down\n- Share what you learn\n\n")
+	fmt.Fprintf(&b, "%s\n\n", g.sentence())
+	b.WriteString("## A worked example\n\n")
+	b.WriteString("```\n$ echo hello | nc example.com 70\n```\n\n")
+	fmt.Fprintf(&b, "> %s\n\n", g.sentence())
+	b.WriteString("## Further reading\n\n")
+	fmt.Fprintf(&b, "See [this page](%s) for more.\n", g.pick(sites))
+
+	return title, summary, b.String()
+}
+
+// sentence fills a sentence template with an interest
+func (g *generator) sentence() string {
+	return fmt.Sprintf(g.pick(sentences), g.pick(interests))
+}
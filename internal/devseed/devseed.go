@@ -0,0 +1,428 @@
+// Package devseed generates a synthetic dataset for development.
+//
+// Profiles, notes, threads, reactions, zaps, long-form articles and a contact
+// graph are derived from a numeric seed, so the same seed and end time produce
+// the same events, IDs and signatures on every run. Contributors can fill a
+// fresh database and exercise every renderer and section without syncing from
+// live relays.
+package devseed
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"math/rand/v2"
+	"strconv"
+	"time"
+
+	"github.com/nbd-wtf/go-nostr"
+	"github.com/nbd-wtf/go-nostr/nip19"
+	"github.com/sandwich/nophr/internal/aggregates"
+	"github.com/sandwich/nophr/internal/config"
+	"github.com/sandwich/nophr/internal/storage"
+)
+
+// Options controls the size and shape of the dataset
+type Options struct {
+	Seed        int64
+	Users       int       // Accounts besides the owner (default 20)
+	Days        int       // Days of activity (default 30)
+	End         time.Time // Latest timestamp; activity spreads over the Days before it
+	OwnerSecret string    // Hex secret key of the owner; derived from the seed when empty
+}
+
+// Dataset is a generated set of events and the social graph they imply
+type Dataset struct {
+	Owner  string   // Hex pubkey of the owner
+	Users  []string // Hex pubkeys of the other accounts
+	Events []*nostr.Event
+	Graph  []*storage.GraphNode // The owner's follows and follows of follows
+}
+
+// generator holds the state of one Generate run
+type generator struct {
+	rng     *rand.Rand
+	start   int64
+	end     int64
+	secrets map[string]string // Pubkey to secret key
+	zapper  string            // Secret key of the LNURL service that signs zap receipts
+	ds      *Dataset
+}
+
+// Generate builds a dataset from opts. It writes nothing.
+func Generate(opts Options) (*Dataset, error) {
+	if opts.Users <= 0 {
+		opts.Users = 20
+	}
+	if opts.Days <= 0 {
+		opts.Days = 30
+	}
+	if opts.End.IsZero() {
+		return nil, fmt.Errorf("an end time is required")
+	}
+
+	g := &generator{
+		rng:     rand.New(rand.NewPCG(uint64(opts.Seed), 0x6e6f706872)),
+		end:     opts.End.Unix(),
+		secrets: make(map[string]string),
+		zapper:  deriveSecret(opts.Seed, "zapper"),
+		ds:      &Dataset{},
+	}
+	g.start = g.end - int64(opts.Days)*24*60*60
+
+	ownerSecret := opts.OwnerSecret
+	if ownerSecret == "" {
+		ownerSecret = deriveSecret(opts.Seed, "owner")
+	}
+	owner, err := g.addKey(ownerSecret)
+	if err != nil {
+		return nil, fmt.Errorf("invalid owner secret key: %w", err)
+	}
+	g.ds.Owner = owner
+
+	for i := 0; i < opts.Users; i++ {
+		pubkey, err := g.addKey(deriveSecret(opts.Seed, "user-"+strconv.Itoa(i)))
+		if err != nil {
+			return nil, err
+		}
+		g.ds.Users = append(g.ds.Users, pubkey)
+	}
+
+	g.profiles()
+	g.contacts()
+	for _, note := range g.notes(owner, opts.Days*2) {
+		g.engage(note, 5, 8, 0.4)
+	}
+	for _, user := range g.ds.Users {
+		for _, note := range g.notes(user, opts.Days/5+1) {
+			g.engage(note, 2, 3, 0.1)
+		}
+	}
+	for i := 0; i < opts.Days/7+1; i++ {
+		g.engage(g.article(owner), 0, 6, 0.6)
+	}
+	for _, user := range g.ds.Users {
+		if g.chance(0.2) {
+			g.engage(g.article(user), 0, 2, 0.2)
+		}
+	}
+
+	return g.ds, nil
+}
+
+// Seed stores the dataset, its social graph and the aggregates it implies
+func (ds *Dataset) Seed(ctx context.Context, st *storage.Storage, cfg *config.Config) error {
+	for _, event := range ds.Events {
+		if err := st.StoreEvent(ctx, event); err != nil {
+			return fmt.Errorf("failed to store event %s: %w", event.ID, err)
+		}
+	}
+
+	for _, node := range ds.Graph {
+		if err := st.SaveGraphNode(ctx, node); err != nil {
+			return err
+		}
+	}
+
+	r := aggregates.NewReconciler(st, aggregates.NewManager(st, cfg))
+	if _, err := r.Rebuild(ctx, aggregates.RebuildOptions{}); err != nil {
+		return fmt.Errorf("failed to compute aggregates: %w", err)
+	}
+	return nil
+}
+
+// Npub returns the owner's npub, for identity.npub
+func (ds *Dataset) Npub() string {
+	npub, _ := nip19.EncodePublicKey(ds.Owner)
+	return npub
+}
+
+// deriveSecret derives a stable secret key from the seed and a name
+func deriveSecret(seed int64, name string) string {
+	sum := sha256.Sum256([]byte(fmt.Sprintf("nophr-devseed/%d/%s", seed, name)))
+	return hex.EncodeToString(sum[:])
+}
+
+// addKey remembers a secret key and returns its pubkey
+func (g *generator) addKey(secret string) (string, error) {
+	pubkey, err := nostr.GetPublicKey(secret)
+	if err != nil {
+		return "", err
+	}
+	g.secrets[pubkey] = secret
+	return pubkey, nil
+}
+
+// profiles publishes a kind 0 profile for every account
+func (g *generator) profiles() {
+	type profile struct {
+		Name        string `json:"name"`
+		DisplayName string `json:"display_name"`
+		About       string `json:"about"`
+		Website     string `json:"website,omitempty"`
+		Nip05       string `json:"nip05,omitempty"`
+		Lud16       string `json:"lud16,omitempty"`
+	}
+
+	accounts := append([]string{g.ds.Owner}, g.ds.Users...)
+	for i, pubkey := range accounts {
+		name := "owner"
+		if i > 0 {
+			name = names[(i-1)%len(names)]
+			if i > len(names) {
+				name += strconv.Itoa((i - 1) / len(names))
+			}
+		}
+
+		p := profile{
+			Name:        name,
+			DisplayName: fmt.Sprintf("%s%s", string(name[0]-'a'+'A'), name[1:]),
+			About:       fmt.Sprintf("Into %s and %s.", g.pick(interests), g.pick(interests)),
+		}
+		if i == 0 || g.chance(0.5) {
+			p.Website = "https://example.com/~" + name
+			p.Nip05 = name + "@example.com"
+			p.Lud16 = name + "@example.com"
+		}
+
+		content, _ := json.Marshal(p)
+		g.publish(pubkey, &nostr.Event{Kind: 0, CreatedAt: nostr.Timestamp(g.start), Content: string(content)})
+	}
+}
+
+// contacts publishes contact lists and records the owner's graph the way sync
+// would compute it: direct follows at depth 1, marked mutual when they follow
+// back, and their follows at depth 2
+func (g *generator) contacts() {
+	follows := make(map[string][]string)
+
+	for _, user := range g.ds.Users {
+		if g.chance(0.65) {
+			follows[g.ds.Owner] = append(follows[g.ds.Owner], user)
+		}
+	}
+	for _, user := range g.ds.Users {
+		if g.chance(0.5) {
+			follows[user] = append(follows[user], g.ds.Owner)
+		}
+		for _, other := range g.ds.Users {
+			if other != user && g.chance(0.25) {
+				follows[user] = append(follows[user], other)
+			}
+		}
+	}
+
+	accounts := append([]string{g.ds.Owner}, g.ds.Users...)
+	seenAt := make(map[string]int64)
+	for _, pubkey := range accounts {
+		tags := nostr.Tags{}
+		for _, followed := range follows[pubkey] {
+			tags = append(tags, nostr.Tag{"p", followed})
+		}
+		event := g.publish(pubkey, &nostr.Event{Kind: 3, CreatedAt: g.between(g.start, g.start+24*60*60), Tags: tags})
+		seenAt[pubkey] = int64(event.CreatedAt)
+	}
+
+	depth := make(map[string]int)
+	for _, followed := range follows[g.ds.Owner] {
+		depth[followed] = 1
+		mutual := false
+		for _, back := range follows[followed] {
+			mutual = mutual || back == g.ds.Owner
+		}
+		g.ds.Graph = append(g.ds.Graph, &storage.GraphNode{
+			RootPubkey: g.ds.Owner, Pubkey: followed, Depth: 1, Mutual: mutual, LastSeen: seenAt[g.ds.Owner],
+		})
+	}
+	for _, followed := range follows[g.ds.Owner] {
+		for _, foaf := range follows[followed] {
+			if foaf == g.ds.Owner || depth[foaf] != 0 {
+				continue
+			}
+			depth[foaf] = 2
+			g.ds.Graph = append(g.ds.Graph, &storage.GraphNode{
+				RootPubkey: g.ds.Owner, Pubkey: foaf, Depth: 2, LastSeen: seenAt[followed],
+			})
+		}
+	}
+}
+
+// notes publishes count top-level notes by author
+func (g *generator) notes(author string, count int) []*nostr.Event {
+	events := make([]*nostr.Event, 0, count)
+	for i := 0; i < count; i++ {
+		content, hashtags := g.note()
+		tags := nostr.Tags{}
+		for _, tag := range hashtags {
+			tags = append(tags, nostr.Tag{"t", tag})
+		}
+		if g.chance(0.1) {
+			mentioned := g.pick(g.ds.Users)
+			if npub, err := nip19.EncodePublicKey(mentioned); err == nil && mentioned != author {
+				content += "\n\ncc nostr:" + npub
+				tags = append(tags, nostr.Tag{"p", mentioned})
+			}
+		}
+
+		events = append(events, g.publish(author, &nostr.Event{
+			Kind:      1,
+			CreatedAt: g.between(g.start+24*60*60, g.end),
+			Tags:      tags,
+			Content:   content,
+		}))
+	}
+	return events
+}
+
+// article publishes a long-form article by author
+func (g *generator) article(author string) *nostr.Event {
+	title, summary, content := g.articleText()
+	createdAt := g.between(g.start+24*60*60, g.end)
+
+	return g.publish(author, &nostr.Event{
+		Kind:      30023,
+		CreatedAt: createdAt,
+		Tags: nostr.Tags{
+			{"d", fmt.Sprintf("devseed-%d", createdAt)},
+			{"title", title},
+			{"summary", summary},
+			{"published_at", strconv.FormatInt(int64(createdAt), 10)},
+			{"t", g.pick(topics)},
+		},
+		Content: content,
+	})
+}
+
+// engage adds a thread of up to maxReplies direct replies, up to maxReactions
+// reactions and, with probability zapChance, a few zaps to event
+func (g *generator) engage(event *nostr.Event, maxReplies, maxReactions int, zapChance float64) {
+	for i := g.rng.IntN(maxReplies + 1); i > 0; i-- {
+		g.replyTo(event, event, 1)
+	}
+	g.react(event, maxReactions)
+	if g.chance(zapChance) {
+		for i := g.rng.IntN(3) + 1; i > 0; i-- {
+			g.zap(event)
+		}
+	}
+}
+
+// replyTo publishes a reply to parent in root's thread, sometimes replying to
+// the reply in turn. The owner answers now and then, as owners do.
+func (g *generator) replyTo(root, parent *nostr.Event, depth int) {
+	author := g.pick(g.ds.Users)
+	if parent.PubKey != g.ds.Owner && g.chance(0.3) {
+		author = g.ds.Owner
+	}
+	if author == parent.PubKey {
+		return
+	}
+
+	tags := nostr.Tags{{"e", root.ID, "", "root"}}
+	if parent.ID != root.ID {
+		tags = append(tags, nostr.Tag{"e", parent.ID, "", "reply"})
+	}
+	tags = append(tags, nostr.Tag{"p", parent.PubKey})
+	if root.PubKey != parent.PubKey && root.PubKey != author {
+		tags = append(tags, nostr.Tag{"p", root.PubKey})
+	}
+
+	reply := g.publish(author, &nostr.Event{
+		Kind:      1,
+		CreatedAt: g.after(parent.CreatedAt, 2*24*60*60),
+		Tags:      tags,
+		Content:   g.reply(),
+	})
+
+	g.react(reply, 2)
+	if depth < 4 && g.chance(0.35) {
+		g.replyTo(root, reply, depth+1)
+	}
+}
+
+// react publishes up to max reactions to event from distinct accounts
+func (g *generator) react(event *nostr.Event, max int) {
+	for _, i := range g.rng.Perm(len(g.ds.Users))[:g.rng.IntN(min(max, len(g.ds.Users))+1)] {
+		g.publish(g.ds.Users[i], &nostr.Event{
+			Kind:      7,
+			CreatedAt: g.after(event.CreatedAt, 3*24*60*60),
+			Tags:      nostr.Tags{{"e", event.ID}, {"p", event.PubKey}, {"k", strconv.Itoa(event.Kind)}},
+			Content:   g.pick(reactions),
+		})
+	}
+}
+
+// zap publishes a zap receipt for event, signed by the LNURL service and
+// carrying the sender's signed zap request as NIP-57 describes
+func (g *generator) zap(event *nostr.Event) {
+	sender := g.pick(g.ds.Users)
+	sats := zapAmounts[g.rng.IntN(len(zapAmounts))]
+	createdAt := g.after(event.CreatedAt, 3*24*60*60)
+
+	request := &nostr.Event{
+		Kind:      9734,
+		CreatedAt: createdAt,
+		Tags: nostr.Tags{
+			{"relays", "wss://relay.example.com"},
+			{"amount", strconv.FormatInt(sats*1000, 10)},
+			{"p", event.PubKey},
+			{"e", event.ID},
+		},
+		Content: g.pick([]string{"", "", "Great post!", "⚡"}),
+	}
+	request.Sign(g.secrets[sender])
+	description, _ := json.Marshal(request)
+
+	receipt := &nostr.Event{
+		Kind:      9735,
+		CreatedAt: createdAt,
+		Tags: nostr.Tags{
+			{"p", event.PubKey},
+			{"e", event.ID},
+			{"P", sender},
+			{"bolt11", fmt.Sprintf("lnbc%dn1devseed%s", sats*10, request.ID[:16])},
+			{"description", string(description)},
+		},
+	}
+	receipt.Sign(g.zapper)
+	g.ds.Events = append(g.ds.Events, receipt)
+}
+
+// publish signs event as pubkey and adds it to the dataset
+func (g *generator) publish(pubkey string, event *nostr.Event) *nostr.Event {
+	if event.Tags == nil {
+		event.Tags = nostr.Tags{}
+	}
+	event.Sign(g.secrets[pubkey])
+	g.ds.Events = append(g.ds.Events, event)
+	return event
+}
+
+// between returns a timestamp in [from, to)
+func (g *generator) between(from, to int64) nostr.Timestamp {
+	if to <= from {
+		return nostr.Timestamp(from)
+	}
+	return nostr.Timestamp(from + g.rng.Int64N(to-from))
+}
+
+// after returns a timestamp up to within seconds after t, never past the end
+func (g *generator) after(t nostr.Timestamp, within int64) nostr.Timestamp {
+	from := int64(t) + 60
+	if from >= g.end {
+		return nostr.Timestamp(g.end)
+	}
+	return g.between(from, min(from+within, g.end))
+}
+
+// chance reports true with probability p
+func (g *generator) chance(p float64) bool {
+	return g.rng.Float64() < p
+}
+
+// pick returns a random element of items
+func (g *generator) pick(items []string) string {
+	return items[g.rng.IntN(len(items))]
+}
@@ -0,0 +1,101 @@
+package devseed
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/sandwich/nophr/internal/config"
+	"github.com/sandwich/nophr/internal/storage"
+)
+
+func TestGenerateDeterministic(t *testing.T) {
+	opts := Options{Seed: 7, Users: 6, Days: 14, End: time.Date(2025, time.March, 1, 0, 0, 0, 0, time.UTC)}
+
+	first, err := Generate(opts)
+	if err != nil {
+		t.Fatalf("Generate failed: %v", err)
+	}
+	second, err := Generate(opts)
+	if err != nil {
+		t.Fatalf("Generate failed: %v", err)
+	}
+
+	if len(first.Events) != len(second.Events) {
+		t.Fatalf("Expected the same number of events, got %d and %d", len(first.Events), len(second.Events))
+	}
+	for i := range first.Events {
+		if first.Events[i].ID != second.Events[i].ID || first.Events[i].Sig != second.Events[i].Sig {
+			t.Fatalf("Event %d differs between runs", i)
+		}
+	}
+
+	kinds := make(map[int]int)
+	for _, event := range first.Events {
+		if ok, err := event.CheckSignature(); !ok || err != nil {
+			t.Fatalf("Event %s has a bad signature: %v", event.ID, err)
+		}
+		if event.CreatedAt.Time().After(opts.End) {
+			t.Errorf("Event %s is dated after the end", event.ID)
+		}
+		kinds[event.Kind]++
+	}
+	for _, kind := range []int{0, 1, 3, 7, 9735, 30023} {
+		if kinds[kind] == 0 {
+			t.Errorf("Expected events of kind %d", kind)
+		}
+	}
+
+	opts.Seed = 8
+	other, err := Generate(opts)
+	if err != nil {
+		t.Fatalf("Generate failed: %v", err)
+	}
+	if other.Owner == first.Owner {
+		t.Error("Expected a different seed to give a different owner")
+	}
+}
+
+func TestSeed(t *testing.T) {
+	ctx := context.Background()
+	cfg := config.Default()
+	cfg.Storage = config.Storage{Driver: "sqlite", SQLitePath: filepath.Join(t.TempDir(), "test.db")}
+
+	st, err := storage.New(ctx, &cfg.Storage)
+	if err != nil {
+		t.Fatalf("Failed to create storage: %v", err)
+	}
+	defer st.Close()
+
+	ds, err := Generate(Options{Seed: 1, Users: 5, Days: 7, End: time.Date(2025, time.March, 1, 0, 0, 0, 0, time.UTC)})
+	if err != nil {
+		t.Fatalf("Generate failed: %v", err)
+	}
+	cfg.Identity.Npub = ds.Npub()
+	if err := ds.Seed(ctx, st, cfg); err != nil {
+		t.Fatalf("Seed failed: %v", err)
+	}
+
+	count, err := st.CountEvents(ctx)
+	if err != nil || count != int64(len(ds.Events)) {
+		t.Errorf("Expected %d stored events, got %d (%v)", len(ds.Events), count, err)
+	}
+	if aggs, err := st.CountAggregates(ctx); err != nil || aggs == 0 {
+		t.Errorf("Expected aggregates to be computed, got %d (%v)", aggs, err)
+	}
+
+	following, err := st.GetFollowingPubkeys(ctx, ds.Owner)
+	if err != nil {
+		t.Fatalf("Failed to get following: %v", err)
+	}
+	direct := 0
+	for _, node := range ds.Graph {
+		if node.Depth == 1 {
+			direct++
+		}
+	}
+	if len(following) != direct {
+		t.Errorf("Expected %d follows in the graph, got %d", direct, len(following))
+	}
+}
@@ -0,0 +1,258 @@
+package digest
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/nbd-wtf/go-nostr"
+	"github.com/sandwich/nophr/internal/aggregates"
+	"github.com/sandwich/nophr/internal/config"
+	"github.com/sandwich/nophr/internal/storage"
+)
+
+// period is the window one digest covers
+const period = 24 * time.Hour
+
+// Digest summarises one day of the owner's activity
+type Digest struct {
+	Since        time.Time
+	Until        time.Time
+	NewPosts     []*nostr.Event              // Root notes and articles published during the day, newest first
+	TopPosts     []*aggregates.EnrichedEvent // Owner posts by interactions received during the day
+	Followers    int                         // Followers known when the digest was built
+	NewFollowers []*aggregates.Contact       // Accounts whose contact list gained the owner during the day
+}
+
+// Builder builds the daily digest and keeps the current one until the next
+// configured regeneration time
+type Builder struct {
+	storage *storage.Storage
+	config  *config.Config
+	queries *aggregates.QueryHelper
+
+	mu      sync.Mutex
+	current *Digest
+}
+
+// NewBuilder creates a digest builder
+func NewBuilder(cfg *config.Config, st *storage.Storage, queries *aggregates.QueryHelper) *Builder {
+	return &Builder{
+		storage: st,
+		config:  cfg,
+		queries: queries,
+	}
+}
+
+// Current returns the digest for the day ending at the latest regeneration
+// time, building it on the first request after that time
+func (b *Builder) Current(ctx context.Context, now time.Time) (*Digest, error) {
+	until := b.config.Display.Digest.LatestRun(now)
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.current != nil && b.current.Until.Equal(until) {
+		return b.current, nil
+	}
+
+	digest, err := b.Build(ctx, until.Add(-period), until)
+	if err != nil {
+		return nil, err
+	}
+	b.current = digest
+	return digest, nil
+}
+
+// Build gathers the digest for the window [since, until) from stored events.
+// Each query is bounded by the store's result limit, so on very busy days the
+// counts cover the most recent interactions only.
+func (b *Builder) Build(ctx context.Context, since, until time.Time) (*Digest, error) {
+	ownerHex, err := b.queries.OwnerHex()
+	if err != nil {
+		return nil, err
+	}
+
+	digest := &Digest{Since: since, Until: until}
+	sinceTs, untilTs := nostr.Timestamp(since.Unix()), nostr.Timestamp(until.Unix()-1)
+
+	if digest.NewPosts, err = b.newPosts(ctx, ownerHex, sinceTs, untilTs); err != nil {
+		return nil, err
+	}
+	if digest.TopPosts, err = b.topPosts(ctx, ownerHex, sinceTs, untilTs); err != nil {
+		return nil, err
+	}
+
+	followers, err := b.queries.GetFollowers(ctx, 1, 1<<20)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load followers: %w", err)
+	}
+	digest.Followers = followers.TotalItems
+
+	gained, err := b.gainedFollowers(ctx, ownerHex, sinceTs, untilTs)
+	if err != nil {
+		return nil, err
+	}
+	for _, contact := range followers.Contacts {
+		if gained[contact.Pubkey] {
+			digest.NewFollowers = append(digest.NewFollowers, contact)
+		}
+	}
+
+	return digest, nil
+}
+
+// newPosts returns the owner's root notes and articles published in the window
+func (b *Builder) newPosts(ctx context.Context, ownerHex string, since, until nostr.Timestamp) ([]*nostr.Event, error) {
+	events, err := b.storage.QueryEvents(ctx, nostr.Filter{
+		Kinds:   []int{nostr.KindTextNote, nostr.KindArticle},
+		Authors: []string{ownerHex},
+		Since:   &since,
+		Until:   &until,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to query posts: %w", err)
+	}
+
+	var posts []*nostr.Event
+	for _, event := range events {
+		if event.Kind == nostr.KindTextNote {
+			if info, err := aggregates.ParseThreadInfo(event); err == nil && info.IsReply() {
+				continue
+			}
+		}
+		posts = append(posts, event)
+	}
+
+	sort.Slice(posts, func(i, j int) bool { return posts[i].CreatedAt > posts[j].CreatedAt })
+	if limit := b.config.Display.Digest.MaxPosts; len(posts) > limit {
+		posts = posts[:limit]
+	}
+	return posts, nil
+}
+
+// topPosts counts the replies, reactions and zaps the owner's posts received in
+// the window and returns the posts with the most
+func (b *Builder) topPosts(ctx context.Context, ownerHex string, since, until nostr.Timestamp) ([]*aggregates.EnrichedEvent, error) {
+	counts := make(map[string]*aggregates.EventAggregates)
+	for _, kind := range []int{nostr.KindTextNote, nostr.KindReaction, nostr.KindZap} {
+		events, err := b.storage.QueryEvents(ctx, nostr.Filter{
+			Kinds: []int{kind},
+			Tags:  nostr.TagMap{"p": []string{ownerHex}},
+			Since: &since,
+			Until: &until,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to query interactions: %w", err)
+		}
+
+		for _, event := range events {
+			// The owner replying in their own threads is not an interaction
+			if event.PubKey == ownerHex && kind != nostr.KindZap {
+				continue
+			}
+			target := aggregates.InteractionTarget(event)
+			if target == "" {
+				continue
+			}
+
+			agg, ok := counts[target]
+			if !ok {
+				agg = &aggregates.EventAggregates{EventID: target, ReactionCounts: make(map[string]int)}
+				counts[target] = agg
+			}
+			switch kind {
+			case nostr.KindTextNote:
+				agg.ReplyCount++
+			case nostr.KindReaction:
+				agg.ReactionTotal++
+			case nostr.KindZap:
+				agg.ZapSatsTotal += aggregates.ZapAmount(event)
+			}
+			agg.LastInteraction = max(agg.LastInteraction, int64(event.CreatedAt))
+		}
+	}
+	if len(counts) == 0 {
+		return nil, nil
+	}
+
+	ids := make([]string, 0, len(counts))
+	for id := range counts {
+		ids = append(ids, id)
+	}
+	targets, err := b.storage.QueryEvents(ctx, nostr.Filter{IDs: ids, Authors: []string{ownerHex}})
+	if err != nil {
+		return nil, fmt.Errorf("failed to load posts: %w", err)
+	}
+
+	var posts []*aggregates.EnrichedEvent
+	for _, event := range targets {
+		if agg := counts[event.ID]; agg.HasInteractions() {
+			posts = append(posts, &aggregates.EnrichedEvent{Event: event, Aggregates: agg})
+		}
+	}
+	sort.Slice(posts, func(i, j int) bool {
+		a, b := posts[i], posts[j]
+		if a.Aggregates.InteractionScore() != b.Aggregates.InteractionScore() {
+			return a.Aggregates.InteractionScore() > b.Aggregates.InteractionScore()
+		}
+		return a.Event.CreatedAt > b.Event.CreatedAt
+	})
+	if limit := b.config.Display.Digest.TopPosts; len(posts) > limit {
+		posts = posts[:limit]
+	}
+	return posts, nil
+}
+
+// gainedFollowers diffs contact lists: it returns the authors whose newest list
+// from the window includes the owner while their newest list from before the
+// window, if any was stored, did not
+func (b *Builder) gainedFollowers(ctx context.Context, ownerHex string, since, until nostr.Timestamp) (map[string]bool, error) {
+	lists, err := b.storage.QueryEvents(ctx, nostr.Filter{
+		Kinds: []int{nostr.KindFollowList},
+		Tags:  nostr.TagMap{"p": []string{ownerHex}},
+		Since: &since,
+		Until: &until,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to query contact lists: %w", err)
+	}
+
+	gained := make(map[string]bool)
+	checked := make(map[string]bool)
+	for _, list := range lists {
+		if list.PubKey == ownerHex || checked[list.PubKey] {
+			continue
+		}
+		checked[list.PubKey] = true
+
+		history, err := b.storage.QueryEvents(ctx, nostr.Filter{
+			Kinds:   []int{nostr.KindFollowList},
+			Authors: []string{list.PubKey},
+			Until:   &until,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to query contact lists: %w", err)
+		}
+
+		var newest, before *nostr.Event
+		for _, event := range history {
+			if newest == nil || event.CreatedAt > newest.CreatedAt {
+				newest = event
+			}
+			if event.CreatedAt < since && (before == nil || event.CreatedAt > before.CreatedAt) {
+				before = event
+			}
+		}
+		if newest == nil || !aggregates.IsMentioningPubkey(newest, ownerHex) {
+			continue
+		}
+		if before == nil || !aggregates.IsMentioningPubkey(before, ownerHex) {
+			gained[list.PubKey] = true
+		}
+	}
+
+	return gained, nil
+}
@@ -0,0 +1,122 @@
+package digest
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/nbd-wtf/go-nostr"
+	"github.com/nbd-wtf/go-nostr/nip19"
+	"github.com/sandwich/nophr/internal/aggregates"
+	"github.com/sandwich/nophr/internal/config"
+	"github.com/sandwich/nophr/internal/storage"
+)
+
+func TestBuild(t *testing.T) {
+	ctx := context.Background()
+	ownerSecret := nostr.GeneratePrivateKey()
+	ownerHex, _ := nostr.GetPublicKey(ownerSecret)
+	npub, _ := nip19.EncodePublicKey(ownerHex)
+
+	cfg := config.Default()
+	cfg.Identity.Npub = npub
+	cfg.Storage.SQLitePath = filepath.Join(t.TempDir(), "test.db")
+	cfg.Display.Digest.RegenerateAt = "06:00"
+
+	st, err := storage.New(ctx, &cfg.Storage)
+	if err != nil {
+		t.Fatalf("Failed to create storage: %v", err)
+	}
+	defer st.Close()
+
+	until := time.Date(2026, time.October, 16, 6, 0, 0, 0, time.UTC)
+	inDay := nostr.Timestamp(until.Add(-2 * time.Hour).Unix())
+	beforeDay := nostr.Timestamp(until.Add(-48 * time.Hour).Unix())
+
+	store := func(secret string, event *nostr.Event) *nostr.Event {
+		t.Helper()
+		if err := event.Sign(secret); err != nil {
+			t.Fatalf("Failed to sign event: %v", err)
+		}
+		if err := st.StoreEvent(ctx, event); err != nil {
+			t.Fatalf("Failed to store event: %v", err)
+		}
+		return event
+	}
+
+	fan, follower, oldFollower := nostr.GeneratePrivateKey(), nostr.GeneratePrivateKey(), nostr.GeneratePrivateKey()
+	followerHex, _ := nostr.GetPublicKey(follower)
+
+	newNote := store(ownerSecret, &nostr.Event{Kind: 1, CreatedAt: inDay, Content: "fresh gopherhole\nmore"})
+	store(ownerSecret, &nostr.Event{Kind: 1, CreatedAt: inDay, Content: "self reply", Tags: nostr.Tags{{"e", newNote.ID, "", "reply"}}})
+	oldNote := store(ownerSecret, &nostr.Event{Kind: 1, CreatedAt: beforeDay, Content: "old but loved"})
+
+	store(fan, &nostr.Event{Kind: 7, CreatedAt: inDay, Content: "+", Tags: nostr.Tags{{"e", oldNote.ID}, {"p", ownerHex}}})
+	store(fan, &nostr.Event{Kind: 1, CreatedAt: inDay, Content: "agreed", Tags: nostr.Tags{{"e", oldNote.ID, "", "reply"}, {"p", ownerHex}}})
+	store(fan, &nostr.Event{Kind: 7, CreatedAt: inDay, Content: "+", Tags: nostr.Tags{{"e", newNote.ID}, {"p", ownerHex}}})
+	store(fan, &nostr.Event{Kind: 7, CreatedAt: beforeDay, Content: "+", Tags: nostr.Tags{{"e", newNote.ID}, {"p", ownerHex}}})
+
+	// One account adds the owner during the day; another re-publishes a list that already had them
+	store(follower, &nostr.Event{Kind: 3, CreatedAt: beforeDay})
+	store(follower, &nostr.Event{Kind: 3, CreatedAt: inDay, Tags: nostr.Tags{{"p", ownerHex}}})
+	store(oldFollower, &nostr.Event{Kind: 3, CreatedAt: beforeDay, Tags: nostr.Tags{{"p", ownerHex}}})
+	store(oldFollower, &nostr.Event{Kind: 3, CreatedAt: inDay, Tags: nostr.Tags{{"p", ownerHex}, {"p", followerHex}}})
+
+	builder := NewBuilder(cfg, st, aggregates.NewQueryHelper(st, cfg, aggregates.NewManager(st, cfg)))
+	digest, err := builder.Current(ctx, until.Add(time.Hour))
+	if err != nil {
+		t.Fatalf("Current() error = %v", err)
+	}
+
+	if !digest.Since.Equal(until.Add(-24*time.Hour)) || !digest.Until.Equal(until) {
+		t.Errorf("Window = %v – %v, want the day before %v", digest.Since, digest.Until, until)
+	}
+	if len(digest.NewPosts) != 1 || digest.NewPosts[0].ID != newNote.ID {
+		t.Errorf("NewPosts = %v, want only the new root note", digest.NewPosts)
+	}
+	if len(digest.TopPosts) != 2 || digest.TopPosts[0].Event.ID != oldNote.ID {
+		t.Fatalf("TopPosts = %v, want the old note first", digest.TopPosts)
+	}
+	if got := Engagement(digest.TopPosts[1].Aggregates); got != "1 reaction" {
+		t.Errorf("Interactions outside the day should not count, got %q", got)
+	}
+	if digest.Followers != 2 || len(digest.NewFollowers) != 1 || digest.NewFollowers[0].Pubkey != followerHex {
+		t.Errorf("Followers = %d, new %v; want 2 with one new", digest.Followers, digest.NewFollowers)
+	}
+
+	quote := digest.Quote()
+	for _, want := range []string{"Daily digest: Oct 15 06:00 – Oct 16 06:00, 2026 UTC", "New posts: 1", "Top post: old but loved (1 reply, 1 reaction)", "2 followers (+1 today)"} {
+		if !strings.Contains(quote, want) {
+			t.Errorf("Quote() missing %q:\n%s", want, quote)
+		}
+	}
+
+	// The digest is kept until the next regeneration time
+	if again, _ := builder.Current(ctx, until.Add(23*time.Hour)); again != digest {
+		t.Error("Expected the cached digest before the next regeneration time")
+	}
+	if next, _ := builder.Current(ctx, until.Add(24*time.Hour)); next == digest {
+		t.Error("Expected a new digest after the regeneration time")
+	}
+}
+
+func TestQuoteLength(t *testing.T) {
+	digest := &Digest{
+		Since: time.Date(2026, time.October, 15, 0, 0, 0, 0, time.UTC),
+		Until: time.Date(2026, time.October, 16, 0, 0, 0, 0, time.UTC),
+		TopPosts: []*aggregates.EnrichedEvent{{
+			Event:      &nostr.Event{Content: strings.Repeat("é", 600)},
+			Aggregates: &aggregates.EventAggregates{ReplyCount: 1},
+		}},
+	}
+
+	quote := digest.Quote()
+	if !strings.HasPrefix(quote, "Daily digest: Oct 15, 2026\r\n") {
+		t.Errorf("Expected a date-only period for a midnight digest, got %q", quote)
+	}
+	if len(quote) > maxQuoteLength {
+		t.Errorf("Quote() is %d bytes, want at most %d", len(quote), maxQuoteLength)
+	}
+}
@@ -0,0 +1,84 @@
+package digest
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/nbd-wtf/go-nostr"
+	"github.com/sandwich/nophr/internal/aggregates"
+)
+
+// maxQuoteLength is the RFC 865 limit on a quote's length
+const maxQuoteLength = 512
+
+// Period formats the window the digest covers: the date alone when it runs
+// midnight to midnight, e.g. "Oct 15, 2026", or both ends otherwise
+func (d *Digest) Period() string {
+	since, until := d.Since.UTC(), d.Until.UTC()
+	if since.Hour() == 0 && since.Minute() == 0 {
+		return since.Format("Jan 2, 2006")
+	}
+	return fmt.Sprintf("%s – %s UTC", since.Format("Jan 2 15:04"), until.Format("Jan 2 15:04, 2006"))
+}
+
+// FollowerSummary reports the follower count and the day's gain, e.g. "42 followers (+2 today)"
+func (d *Digest) FollowerSummary() string {
+	summary := plural(d.Followers, "follower", "followers")
+	if len(d.NewFollowers) == 0 {
+		return summary
+	}
+	return fmt.Sprintf("%s (+%d today)", summary, len(d.NewFollowers))
+}
+
+// Quote renders the digest as a short plain-text quote for the QOTD listener
+func (d *Digest) Quote() string {
+	var sb strings.Builder
+
+	sb.WriteString(fmt.Sprintf("Daily digest: %s\r\n", d.Period()))
+	sb.WriteString(fmt.Sprintf("New posts: %d\r\n", len(d.NewPosts)))
+	if len(d.TopPosts) > 0 {
+		top := d.TopPosts[0]
+		sb.WriteString(fmt.Sprintf("Top post: %s (%s)\r\n", Summary(top.Event, 80), Engagement(top.Aggregates)))
+	}
+	sb.WriteString(d.FollowerSummary() + "\r\n")
+
+	quote := sb.String()
+	if len(quote) > maxQuoteLength {
+		quote = strings.ToValidUTF8(quote[:maxQuoteLength-5], "") + "...\r\n"
+	}
+	return quote
+}
+
+// Summary returns the first line of an event's content, cut to length characters
+func Summary(event *nostr.Event, length int) string {
+	line := []rune(strings.TrimSpace(strings.SplitN(strings.TrimSpace(event.Content), "\n", 2)[0]))
+	if len(line) > length {
+		line = append(line[:length-3], []rune("...")...)
+	}
+	if len(line) == 0 {
+		return "(no text)"
+	}
+	return string(line)
+}
+
+// Engagement summarises the interactions a post received, e.g. "3 replies, 12 reactions, 2.1K sats"
+func Engagement(agg *aggregates.EventAggregates) string {
+	parts := make([]string, 0, 3)
+	if agg.ReplyCount > 0 {
+		parts = append(parts, plural(agg.ReplyCount, "reply", "replies"))
+	}
+	if agg.ReactionTotal > 0 {
+		parts = append(parts, plural(agg.ReactionTotal, "reaction", "reactions"))
+	}
+	if agg.ZapSatsTotal > 0 {
+		parts = append(parts, aggregates.FormatSats(agg.ZapSatsTotal))
+	}
+	return strings.Join(parts, ", ")
+}
+
+func plural(n int, one, many string) string {
+	if n == 1 {
+		return "1 " + one
+	}
+	return fmt.Sprintf("%d %s", n, many)
+}
@@ -0,0 +1,110 @@
+package entities
+
+import (
+	"net/url"
+	"regexp"
+	"strings"
+
+	"github.com/sandwich/nophr/internal/idn"
+)
+
+// markdownLinkPattern matches [text](url) links in note content
+var markdownLinkPattern = regexp.MustCompile(`\[([^\]]*)\]\((https?://[^)\s]+)\)`)
+
+// DomainBlocklist removes links to blocked domains, and their subdomains,
+// from rendered content
+type DomainBlocklist struct {
+	domains map[string]bool // ASCII and lowercase
+}
+
+// NewDomainBlocklist creates a blocklist from rendering.blocked_domains
+func NewDomainBlocklist(domains []string) *DomainBlocklist {
+	b := &DomainBlocklist{domains: make(map[string]bool, len(domains))}
+	for _, domain := range domains {
+		if host := normalizeHost(domain); host != "" {
+			b.domains[host] = true
+		}
+	}
+	return b
+}
+
+// normalizeHost lowercases a host and converts it to its ASCII form, so
+// internationalized domains match however they're written
+func normalizeHost(host string) string {
+	host = strings.TrimSuffix(strings.ToLower(strings.TrimSpace(host)), ".")
+	if ascii, err := idn.ToASCII(host); err == nil {
+		return ascii
+	}
+	return host
+}
+
+// Blocked returns the blocked domain a URL points into, and false if it isn't blocked
+func (b *DomainBlocklist) Blocked(rawURL string) (string, bool) {
+	if b == nil || len(b.domains) == 0 {
+		return "", false
+	}
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return "", false
+	}
+
+	host := normalizeHost(u.Hostname())
+	for host != "" {
+		if b.domains[host] {
+			return host, true
+		}
+		_, parent, found := strings.Cut(host, ".")
+		if !found {
+			break
+		}
+		host = parent
+	}
+	return "", false
+}
+
+// Scrub replaces links to blocked domains in text with a
+// "[link removed: domain]" marker, keeping the text of markdown links
+func (b *DomainBlocklist) Scrub(text string) string {
+	if b == nil || len(b.domains) == 0 {
+		return text
+	}
+
+	text = markdownLinkPattern.ReplaceAllStringFunc(text, func(match string) string {
+		parts := markdownLinkPattern.FindStringSubmatch(match)
+		domain, blocked := b.Blocked(parts[2])
+		if !blocked {
+			return match
+		}
+		if strings.TrimSpace(parts[1]) == "" {
+			return removedMarker(domain)
+		}
+		return parts[1] + " " + removedMarker(domain)
+	})
+
+	return urlPattern.ReplaceAllStringFunc(text, func(match string) string {
+		link := strings.TrimRight(match, ".,;:!?")
+		if domain, blocked := b.Blocked(link); blocked {
+			return removedMarker(domain) + match[len(link):]
+		}
+		return match
+	})
+}
+
+// Filter drops links to blocked domains
+func (b *DomainBlocklist) Filter(links []MediaLink) []MediaLink {
+	if b == nil || len(b.domains) == 0 {
+		return links
+	}
+	kept := links[:0:0]
+	for _, link := range links {
+		if _, blocked := b.Blocked(link.URL); !blocked {
+			kept = append(kept, link)
+		}
+	}
+	return kept
+}
+
+// removedMarker is shown in place of a blocked link
+func removedMarker(domain string) string {
+	return "[link removed: " + domain + "]"
+}
@@ -0,0 +1,39 @@
+package entities
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestDomainBlocklist(t *testing.T) {
+	blocklist := NewDomainBlocklist([]string{"Tracker.example", "bücher.example"})
+
+	scrubbed := map[string]string{
+		"see https://tracker.example/x?id=1.":         "see [link removed: tracker.example].",
+		"sub https://ads.tracker.example/pixel":       "sub [link removed: tracker.example]",
+		"idn https://xn--bcher-kva.example/":          "idn [link removed: xn--bcher-kva.example]",
+		"md [click here](https://tracker.example/go)": "md click here [link removed: tracker.example]",
+		"bare [](https://tracker.example/go)":         "bare [link removed: tracker.example]",
+		"kept https://example.com/tracker.example":    "kept https://example.com/tracker.example",
+		"lookalike https://nottracker.example/":       "lookalike https://nottracker.example/",
+		"markdown [ok](https://example.com/)":         "markdown [ok](https://example.com/)",
+	}
+	for input, want := range scrubbed {
+		if got := blocklist.Scrub(input); got != want {
+			t.Errorf("Scrub(%q) = %q, want %q", input, got, want)
+		}
+	}
+
+	links := []MediaLink{
+		{URL: "https://tracker.example/a.png", Label: "Image"},
+		{URL: "https://example.com/b.png", Label: "Image"},
+	}
+	if got := blocklist.Filter(links); !reflect.DeepEqual(got, links[1:]) {
+		t.Errorf("Filter() = %+v", got)
+	}
+
+	var none *DomainBlocklist
+	if got := none.Scrub("https://tracker.example/"); got != "https://tracker.example/" {
+		t.Errorf("A nil blocklist changed text: %q", got)
+	}
+}
@@ -0,0 +1,105 @@
+package entities
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/nbd-wtf/go-nostr"
+	"github.com/nbd-wtf/go-nostr/nip19"
+)
+
+// entityPrefixes are the NIP-19 entities that can appear as a selector or URL path component
+var entityPrefixes = []string{"npub1", "nprofile1", "note1", "nevent1", "naddr1"}
+
+// IsEntity reports whether s looks like a NIP-19 entity (without the nostr: prefix)
+func IsEntity(s string) bool {
+	for _, prefix := range entityPrefixes {
+		if strings.HasPrefix(s, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// ParseURI extracts the NIP-19 entity from a NIP-21 nostr: URI as shared by
+// clients ("nostr:npub1..."), also accepting the bare entity. The scheme is
+// matched case-insensitively and surrounding whitespace is ignored; nsec and
+// anything else that isn't a public entity is rejected.
+func ParseURI(s string) (string, bool) {
+	s = strings.TrimSpace(s)
+	if len(s) > len("nostr:") && strings.EqualFold(s[:len("nostr:")], "nostr:") {
+		s = s[len("nostr:"):]
+	}
+	if !IsEntity(s) {
+		return "", false
+	}
+	return s, true
+}
+
+// DecodeEntity decodes a NIP-19 entity into its internal link, relay hints and a
+// filter for the event it points at, without any storage lookups.
+// Routers use it to serve deep links like /note1... or /naddr1...
+func (r *Resolver) DecodeEntity(nip19Entity string) (*Entity, error) {
+	nip19Entity = strings.TrimPrefix(nip19Entity, "nostr:")
+
+	prefix, decoded, err := nip19.Decode(nip19Entity)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode NIP-19: %w", err)
+	}
+
+	entity := &Entity{
+		Type:         prefix,
+		OriginalText: "nostr:" + nip19Entity,
+	}
+
+	switch prefix {
+	case "npub":
+		pubkey := decoded.(string)
+		entity.Link = "/profile/" + pubkey
+		entity.Filter = nostr.Filter{Authors: []string{pubkey}, Limit: 1}
+
+	case "nprofile":
+		profileData := decoded.(nostr.ProfilePointer)
+		entity.Link = "/profile/" + profileData.PublicKey
+		entity.Relays = profileData.Relays
+		entity.Filter = nostr.Filter{Authors: []string{profileData.PublicKey}, Limit: 1}
+
+	case "note":
+		eventID := decoded.(string)
+		entity.Link = "/note/" + eventID
+		entity.Filter = nostr.Filter{IDs: []string{eventID}, Limit: 1}
+
+	case "nevent":
+		eventPointer := decoded.(nostr.EventPointer)
+		entity.Link = "/note/" + eventPointer.ID
+		entity.Relays = eventPointer.Relays
+		entity.Filter = nostr.Filter{IDs: []string{eventPointer.ID}, Limit: 1}
+
+	case "naddr":
+		addrPointer := decoded.(nostr.EntityPointer)
+		entity.Link = fmt.Sprintf("/addr/%d/%s/%s", addrPointer.Kind, addrPointer.PublicKey, addrPointer.Identifier)
+		entity.Relays = addrPointer.Relays
+		entity.Filter = nostr.Filter{
+			Authors: []string{addrPointer.PublicKey},
+			Kinds:   []int{addrPointer.Kind},
+			Tags:    nostr.TagMap{"d": []string{addrPointer.Identifier}},
+			Limit:   1,
+		}
+
+	default:
+		return nil, fmt.Errorf("unsupported NIP-19 type: %s", prefix)
+	}
+
+	entity.URL = r.links.URL(entity.Link)
+
+	return entity, nil
+}
+
+// Exists reports whether the event an entity points at is in storage. A
+// profile exists once any of the author's events is, since their kind 0 may
+// not have been synced.
+func (r *Resolver) Exists(ctx context.Context, entity *Entity) bool {
+	events, err := r.storage.QueryEvents(ctx, entity.Filter)
+	return err == nil && len(events) > 0
+}
@@ -0,0 +1,134 @@
+package entities
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/nbd-wtf/go-nostr"
+	"github.com/nbd-wtf/go-nostr/nip19"
+	"github.com/sandwich/nophr/internal/config"
+	"github.com/sandwich/nophr/internal/storage"
+)
+
+func TestIsEntity(t *testing.T) {
+	for _, s := range []string{"npub1abc", "nprofile1abc", "note1abc", "nevent1abc", "naddr1abc"} {
+		if !IsEntity(s) {
+			t.Errorf("IsEntity(%s) = false, want true", s)
+		}
+	}
+	for _, s := range []string{"notes", "nsec1abc", "profile", ""} {
+		if IsEntity(s) {
+			t.Errorf("IsEntity(%s) = true, want false", s)
+		}
+	}
+}
+
+func TestParseURI(t *testing.T) {
+	tests := []struct {
+		input  string
+		want   string
+		wantOK bool
+	}{
+		{"nostr:npub1abc", "npub1abc", true},
+		{"  NOSTR:nevent1abc\n", "nevent1abc", true},
+		{"naddr1abc", "naddr1abc", true},
+		{"nostr:nsec1abc", "", false},
+		{"nostr:", "", false},
+		{"bitcoin", "", false},
+	}
+
+	for _, tt := range tests {
+		got, ok := ParseURI(tt.input)
+		if got != tt.want || ok != tt.wantOK {
+			t.Errorf("ParseURI(%q) = %q, %v, want %q, %v", tt.input, got, ok, tt.want, tt.wantOK)
+		}
+	}
+}
+
+func TestDecodeEntity(t *testing.T) {
+	pubkey, _ := nostr.GetPublicKey(nostr.GeneratePrivateKey())
+	eventID := "0000000000000000000000000000000000000000000000000000000000000001"
+	relays := []string{"wss://relay.example.com"}
+
+	npub, _ := nip19.EncodePublicKey(pubkey)
+	note, _ := nip19.EncodeNote(eventID)
+	nevent, _ := nip19.EncodeEvent(eventID, relays, pubkey)
+	nprofile, _ := nip19.EncodeProfile(pubkey, relays)
+	naddr, _ := nip19.EncodeEntity(pubkey, 30023, "my-article", relays)
+
+	r := NewResolver(nil, LinkContext{Protocol: ProtocolGemini, Host: "example.com"})
+
+	tests := []struct {
+		input      string
+		wantLink   string
+		wantRelays int
+	}{
+		{npub, "/profile/" + pubkey, 0},
+		{"nostr:" + note, "/note/" + eventID, 0},
+		{nevent, "/note/" + eventID, 1},
+		{nprofile, "/profile/" + pubkey, 1},
+		{naddr, "/addr/30023/" + pubkey + "/my-article", 1},
+	}
+
+	for _, tt := range tests {
+		entity, err := r.DecodeEntity(tt.input)
+		if err != nil {
+			t.Fatalf("DecodeEntity(%s) error = %v", tt.input, err)
+		}
+		if entity.Link != tt.wantLink {
+			t.Errorf("DecodeEntity(%s).Link = %s, want %s", entity.Type, entity.Link, tt.wantLink)
+		}
+		if entity.URL != "gemini://example.com"+tt.wantLink {
+			t.Errorf("DecodeEntity(%s).URL = %s", entity.Type, entity.URL)
+		}
+		if len(entity.Relays) != tt.wantRelays {
+			t.Errorf("DecodeEntity(%s) relays = %v, want %d", entity.Type, entity.Relays, tt.wantRelays)
+		}
+	}
+
+	entity, _ := r.DecodeEntity(naddr)
+	if entity.Filter.Kinds[0] != 30023 || entity.Filter.Tags["d"][0] != "my-article" {
+		t.Errorf("Expected naddr filter on kind and d tag, got %+v", entity.Filter)
+	}
+
+	if _, err := r.DecodeEntity("note1invalid"); err == nil {
+		t.Error("Expected error for invalid entity")
+	}
+}
+
+func TestExists(t *testing.T) {
+	ctx := context.Background()
+	st, err := storage.New(ctx, &config.Storage{Driver: "sqlite", SQLitePath: filepath.Join(t.TempDir(), "test.db")})
+	if err != nil {
+		t.Fatalf("storage.New() error = %v", err)
+	}
+	defer st.Close()
+
+	// An author with a note but no kind 0
+	secretKey := nostr.GeneratePrivateKey()
+	pubkey, _ := nostr.GetPublicKey(secretKey)
+	note := &nostr.Event{Kind: 1, Content: "hello", CreatedAt: nostr.Now(), Tags: nostr.Tags{}}
+	note.Sign(secretKey)
+	if err := st.StoreEvent(ctx, note); err != nil {
+		t.Fatal(err)
+	}
+	stranger, _ := nostr.GetPublicKey(nostr.GeneratePrivateKey())
+
+	npub, _ := nip19.EncodePublicKey(pubkey)
+	nprofile, _ := nip19.EncodeProfile(pubkey, nil)
+	strangerNpub, _ := nip19.EncodePublicKey(stranger)
+	noteID, _ := nip19.EncodeNote(note.ID)
+	missing, _ := nip19.EncodeNote("0000000000000000000000000000000000000000000000000000000000000001")
+
+	r := NewResolver(st, LinkContext{Protocol: ProtocolGopher})
+	for input, want := range map[string]bool{npub: true, nprofile: true, noteID: true, strangerNpub: false, missing: false} {
+		entity, err := r.DecodeEntity(input)
+		if err != nil {
+			t.Fatalf("DecodeEntity(%s) error = %v", input, err)
+		}
+		if got := r.Exists(ctx, entity); got != want {
+			t.Errorf("Exists(%s) = %v, want %v", entity.Type, got, want)
+		}
+	}
+}
@@ -1,35 +1,40 @@
 package entities
 
-import "fmt"
+import (
+	"fmt"
+	"html"
+	"strings"
+)
+
+// linkTextReplacer keeps brackets in a note preview from ending the
+// Markdown link text it is placed in
+var linkTextReplacer = strings.NewReplacer("[", "(", "]", ")")
 
 // GopherFormatter formats an entity for Gopher protocol
-// Returns inline text representation (Gopher doesn't support inline links)
+// Returns inline text with the selector to follow (Gopher doesn't support inline links)
 func GopherFormatter(entity *Entity) string {
 	// For Gopher, we can't embed clickable links inline
-	// So we just show the display name with a reference marker
-	return fmt.Sprintf("@%s", entity.DisplayName)
+	// So we show the label ("@Alice" or a quoted note preview) followed by the selector
+	return fmt.Sprintf("%s (%s)", entity.Label(), entity.Href())
 }
 
 // GeminiFormatter formats an entity for Gemini protocol
-// Returns a Gemini-style link
+// Returns a Markdown-style link that the Gemini renderer moves onto its own => line
 func GeminiFormatter(entity *Entity) string {
-	// Gemini supports inline links like => URL text
-	// But inline links within paragraphs aren't standard
-	// So we use a similar approach to Gopher but with the link path shown
-	return fmt.Sprintf("[%s](gemini://HOSTNAME%s)", entity.DisplayName, entity.Link)
+	return fmt.Sprintf("[%s](%s)", linkTextReplacer.Replace(entity.Label()), entity.Href())
 }
 
-// PlainTextFormatter formats an entity as plain text with display name
+// PlainTextFormatter formats an entity as plain text with its label
 func PlainTextFormatter(entity *Entity) string {
-	return entity.DisplayName
+	return entity.Label()
 }
 
 // MarkdownFormatter formats an entity as Markdown link
 func MarkdownFormatter(entity *Entity) string {
-	return fmt.Sprintf("[%s](%s)", entity.DisplayName, entity.Link)
+	return fmt.Sprintf("[%s](%s)", linkTextReplacer.Replace(entity.Label()), entity.Href())
 }
 
 // HTMLFormatter formats an entity as HTML link
 func HTMLFormatter(entity *Entity) string {
-	return fmt.Sprintf(`<a href="%s">%s</a>`, entity.Link, entity.DisplayName)
+	return fmt.Sprintf(`<a href="%s">%s</a>`, entity.Href(), html.EscapeString(entity.Label()))
 }
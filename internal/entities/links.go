@@ -0,0 +1,42 @@
+package entities
+
+import "fmt"
+
+// Protocol identifies which protocol server is rendering entities
+type Protocol string
+
+const (
+	ProtocolGopher Protocol = "gopher"
+	ProtocolGemini Protocol = "gemini"
+)
+
+// defaultGeminiPort is left out of generated gemini:// URLs
+const defaultGeminiPort = 1965
+
+// LinkContext tells the resolver which protocol is rendering and where it is served,
+// so entity links point back into the same capsule
+type LinkContext struct {
+	Protocol Protocol
+	Host     string
+	Port     int
+}
+
+// URL rewrites an internal link path for the rendering protocol.
+// Gopher links are selectors; Gemini links are absolute gemini:// URLs.
+// Without a protocol or host the path is returned unchanged.
+func (lc LinkContext) URL(path string) string {
+	switch lc.Protocol {
+	case ProtocolGemini:
+		if lc.Host == "" {
+			return path
+		}
+		if lc.Port == 0 || lc.Port == defaultGeminiPort {
+			return fmt.Sprintf("gemini://%s%s", lc.Host, path)
+		}
+		return fmt.Sprintf("gemini://%s:%d%s", lc.Host, lc.Port, path)
+
+	default:
+		// Gopher selectors are host-relative
+		return path
+	}
+}
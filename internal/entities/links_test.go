@@ -0,0 +1,49 @@
+package entities
+
+import "testing"
+
+func TestLinkContextURL(t *testing.T) {
+	tests := []struct {
+		name  string
+		links LinkContext
+		want  string
+	}{
+		{"gopher selector", LinkContext{Protocol: ProtocolGopher, Host: "example.com", Port: 7070}, "/note/abc"},
+		{"gemini default port", LinkContext{Protocol: ProtocolGemini, Host: "example.com", Port: 1965}, "gemini://example.com/note/abc"},
+		{"gemini custom port", LinkContext{Protocol: ProtocolGemini, Host: "example.com", Port: 1966}, "gemini://example.com:1966/note/abc"},
+		{"gemini without host", LinkContext{Protocol: ProtocolGemini}, "/note/abc"},
+		{"no protocol", LinkContext{}, "/note/abc"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.links.URL("/note/abc"); got != tt.want {
+				t.Errorf("URL() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestProtocolFormatters(t *testing.T) {
+	entity := &Entity{
+		Type:        "npub",
+		DisplayName: "alice",
+		Link:        "/profile/abc",
+		URL:         LinkContext{Protocol: ProtocolGemini, Host: "example.com", Port: 1966}.URL("/profile/abc"),
+	}
+
+	if got := GeminiFormatter(entity); got != "[@alice](gemini://example.com:1966/profile/abc)" {
+		t.Errorf("GeminiFormatter() = %q", got)
+	}
+
+	entity.URL = LinkContext{Protocol: ProtocolGopher}.URL(entity.Link)
+	if got := GopherFormatter(entity); got != "@alice (/profile/abc)" {
+		t.Errorf("GopherFormatter() = %q", got)
+	}
+
+	// Entities resolved without a protocol fall back to the internal link
+	entity.URL = ""
+	if got := MarkdownFormatter(entity); got != "[@alice](/profile/abc)" {
+		t.Errorf("MarkdownFormatter() = %q", got)
+	}
+}
@@ -0,0 +1,82 @@
+package entities
+
+import (
+	"path"
+	"regexp"
+	"strings"
+
+	"github.com/nbd-wtf/go-nostr"
+)
+
+// MediaLink is a link to media or a web page found in an event
+type MediaLink struct {
+	URL   string
+	Label string // Image, Video, Audio or Link
+}
+
+// urlPattern matches bare http(s) URLs in note content
+var urlPattern = regexp.MustCompile(`https?://[^\s<>"'\[\]()]+`)
+
+// mediaExtensions maps file extensions to the label a link is shown with
+var mediaExtensions = map[string]string{
+	".jpg": "Image", ".jpeg": "Image", ".png": "Image", ".gif": "Image", ".webp": "Image", ".avif": "Image",
+	".mp4": "Video", ".webm": "Video", ".mov": "Video",
+	".mp3": "Audio", ".ogg": "Audio", ".m4a": "Audio", ".wav": "Audio", ".flac": "Audio",
+}
+
+// MediaLinks returns the URLs attached to an event by NIP-92 imeta tags and
+// written in its content, in order and without duplicates
+func MediaLinks(event *nostr.Event) []MediaLink {
+	var links []MediaLink
+	seen := make(map[string]bool)
+	add := func(url, mimeType string) {
+		if seen[url] {
+			return
+		}
+		seen[url] = true
+		links = append(links, MediaLink{URL: url, Label: mediaLabel(url, mimeType)})
+	}
+
+	for _, tag := range event.Tags {
+		if len(tag) < 2 || tag[0] != "imeta" {
+			continue
+		}
+		var url, mimeType string
+		for _, field := range tag[1:] {
+			key, value, _ := strings.Cut(field, " ")
+			switch key {
+			case "url":
+				url = value
+			case "m":
+				mimeType = value
+			}
+		}
+		if strings.HasPrefix(url, "http://") || strings.HasPrefix(url, "https://") {
+			add(url, mimeType)
+		}
+	}
+
+	for _, url := range urlPattern.FindAllString(event.Content, -1) {
+		add(strings.TrimRight(url, ".,;:!?"), "")
+	}
+
+	return links
+}
+
+// mediaLabel names a link by its MIME type, or failing that its extension
+func mediaLabel(url, mimeType string) string {
+	switch {
+	case strings.HasPrefix(mimeType, "image/"):
+		return "Image"
+	case strings.HasPrefix(mimeType, "video/"):
+		return "Video"
+	case strings.HasPrefix(mimeType, "audio/"):
+		return "Audio"
+	}
+
+	url, _, _ = strings.Cut(url, "?")
+	if label, ok := mediaExtensions[strings.ToLower(path.Ext(url))]; ok {
+		return label
+	}
+	return "Link"
+}
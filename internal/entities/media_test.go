@@ -0,0 +1,32 @@
+package entities
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/nbd-wtf/go-nostr"
+)
+
+func TestMediaLinks(t *testing.T) {
+	event := &nostr.Event{
+		Tags: nostr.Tags{
+			{"imeta", "url https://cdn.example.com/a1b2", "m image/png", "dim 640x480"},
+			{"imeta", "url ftp://example.com/skip.png"},
+		},
+		Content: "Look https://cdn.example.com/a1b2 and https://example.com/clip.MP4?t=1, " +
+			"or read (https://example.org/post). Again: https://example.org/post.",
+	}
+
+	want := []MediaLink{
+		{URL: "https://cdn.example.com/a1b2", Label: "Image"},
+		{URL: "https://example.com/clip.MP4?t=1", Label: "Video"},
+		{URL: "https://example.org/post", Label: "Link"},
+	}
+	if got := MediaLinks(event); !reflect.DeepEqual(got, want) {
+		t.Errorf("MediaLinks() = %+v, want %+v", got, want)
+	}
+
+	if got := MediaLinks(&nostr.Event{Content: "no links here"}); len(got) != 0 {
+		t.Errorf("Expected no links, got %+v", got)
+	}
+}
@@ -8,7 +8,6 @@ import (
 	"strings"
 
 	"github.com/nbd-wtf/go-nostr"
-	"github.com/nbd-wtf/go-nostr/nip19"
 	"github.com/sandwich/nophr/internal/storage"
 )
 
@@ -16,22 +15,60 @@ import (
 type Entity struct {
 	Type         string // "npub", "nprofile", "note", "nevent", "naddr"
 	DisplayName  string // Human-readable name
+	Preview      string // First line of a stored note, or an article's title; "" if not stored
 	Link         string // Internal link path
+	URL          string // Link rewritten for the rendering protocol
 	OriginalText string // Original nostr: string
+
+	Relays []string     // Relay hints carried by nprofile, nevent and naddr
+	Filter nostr.Filter // Matches the event the entity points at, or any of a profile's events
+}
+
+// IsProfile reports whether the entity points at a profile
+func (e *Entity) IsProfile() bool {
+	return e.Type == "npub" || e.Type == "nprofile"
+}
+
+// Label is how the entity reads inline: "@Alice" for a profile, the quoted
+// preview of a stored note or article, otherwise its display name
+func (e *Entity) Label() string {
+	switch {
+	case e.IsProfile():
+		return "@" + e.DisplayName
+	case e.Preview != "":
+		return `"` + e.Preview + `"`
+	}
+	return e.DisplayName
+}
+
+// Href returns the protocol URL if one was resolved, otherwise the internal link
+func (e *Entity) Href() string {
+	if e.URL != "" {
+		return e.URL
+	}
+	return e.Link
 }
 
 // Resolver handles NIP-19 entity resolution
 type Resolver struct {
-	storage *storage.Storage
+	storage   *storage.Storage
+	links     LinkContext
+	blocklist *DomainBlocklist
 }
 
-// NewResolver creates a new entity resolver
-func NewResolver(st *storage.Storage) *Resolver {
+// NewResolver creates a new entity resolver whose links target the given protocol
+func NewResolver(st *storage.Storage, links LinkContext) *Resolver {
 	return &Resolver{
 		storage: st,
+		links:   links,
 	}
 }
 
+// SetBlocklist makes ReplaceEntities remove links to the blocklist's domains
+func (r *Resolver) SetBlocklist(blocklist *DomainBlocklist) {
+	r.blocklist = blocklist
+}
+
 // Regular expression to match nostr: URIs
 var nostrEntityRegex = regexp.MustCompile(`nostr:(npub1[a-z0-9]+|nprofile1[a-z0-9]+|note1[a-z0-9]+|nevent1[a-z0-9]+|naddr1[a-z0-9]+)`)
 
@@ -46,51 +83,40 @@ func (r *Resolver) FindEntities(text string) []string {
 	return entities
 }
 
-// ResolveEntity resolves a single NIP-19 entity
+// ResolveEntity resolves a single NIP-19 entity, including its display name
 func (r *Resolver) ResolveEntity(ctx context.Context, nip19Entity string) (*Entity, error) {
-	prefix, decoded, err := nip19.Decode(nip19Entity)
+	entity, err := r.DecodeEntity(nip19Entity)
 	if err != nil {
-		return nil, fmt.Errorf("failed to decode NIP-19: %w", err)
+		return nil, err
 	}
 
-	entity := &Entity{
-		Type:         prefix,
-		OriginalText: "nostr:" + nip19Entity,
-	}
-
-	switch prefix {
-	case "npub":
-		pubkey := decoded.(string)
-		entity.Link = "/profile/" + pubkey
-		entity.DisplayName = r.resolvePubkeyName(ctx, pubkey)
-
-	case "nprofile":
-		profileData := decoded.(nostr.ProfilePointer)
-		entity.Link = "/profile/" + profileData.PublicKey
-		entity.DisplayName = r.resolvePubkeyName(ctx, profileData.PublicKey)
+	switch entity.Type {
+	case "npub", "nprofile":
+		entity.DisplayName = r.resolvePubkeyName(ctx, entity.Filter.Authors[0])
 
-	case "note":
-		eventID := decoded.(string)
-		entity.Link = "/note/" + eventID
-		entity.DisplayName = r.resolveNoteTitle(ctx, eventID)
-
-	case "nevent":
-		eventPointer := decoded.(nostr.EventPointer)
-		entity.Link = "/note/" + eventPointer.ID
-		entity.DisplayName = r.resolveNoteTitle(ctx, eventPointer.ID)
+	case "note", "nevent":
+		entity.DisplayName, entity.Preview = r.resolveNoteTitle(ctx, entity.Filter.IDs[0])
 
 	case "naddr":
-		addrPointer := decoded.(nostr.EntityPointer)
-		entity.Link = fmt.Sprintf("/addr/%d/%s/%s", addrPointer.Kind, addrPointer.PublicKey, addrPointer.Identifier)
-		entity.DisplayName = r.resolveAddrTitle(ctx, &addrPointer)
-
-	default:
-		return nil, fmt.Errorf("unsupported NIP-19 type: %s", prefix)
+		addr := &nostr.EntityPointer{
+			PublicKey:  entity.Filter.Authors[0],
+			Kind:       entity.Filter.Kinds[0],
+			Identifier: entity.Filter.Tags["d"][0],
+		}
+		entity.DisplayName, entity.Preview = r.resolveAddrTitle(ctx, addr)
 	}
 
 	return entity, nil
 }
 
+// AuthorName returns the display name for a pubkey, falling back to a truncated pubkey
+func (r *Resolver) AuthorName(ctx context.Context, pubkey string) string {
+	if r.storage == nil {
+		return truncatePubkey(pubkey)
+	}
+	return r.resolvePubkeyName(ctx, pubkey)
+}
+
 // resolvePubkeyName fetches the display name for a pubkey
 func (r *Resolver) resolvePubkeyName(ctx context.Context, pubkey string) string {
 	// Try to get profile from storage
@@ -131,8 +157,13 @@ func (r *Resolver) resolvePubkeyName(ctx context.Context, pubkey string) string
 	return truncatePubkey(pubkey)
 }
 
-// resolveNoteTitle fetches the title/preview for a note
-func (r *Resolver) resolveNoteTitle(ctx context.Context, eventID string) string {
+// previewLength is the most characters of a note shown where it is mentioned
+const previewLength = 60
+
+// resolveNoteTitle returns the display name and preview of a note: its first
+// non-empty line, or its title tag for an article. Notes that aren't stored
+// have no preview.
+func (r *Resolver) resolveNoteTitle(ctx context.Context, eventID string) (string, string) {
 	filter := nostr.Filter{
 		IDs:   []string{eventID},
 		Limit: 1,
@@ -140,33 +171,23 @@ func (r *Resolver) resolveNoteTitle(ctx context.Context, eventID string) string
 
 	events, err := r.storage.QueryEvents(ctx, filter)
 	if err != nil || len(events) == 0 {
-		return fmt.Sprintf("Note %s...", truncate(eventID, 8))
+		return fmt.Sprintf("Note %s...", eventID[:min(len(eventID), 8)]), ""
 	}
 
 	event := events[0]
-
-	// For kind 1 (notes), use first line
-	if event.Kind == 1 {
-		lines := strings.Split(event.Content, "\n")
-		if len(lines) > 0 && lines[0] != "" {
-			return truncate(lines[0], 40)
-		}
+	if title := titleTag(event); title != "" {
+		return title, truncate(title, previewLength)
 	}
-
-	// For kind 30023 (articles), check for title tag
-	if event.Kind == 30023 {
-		for _, tag := range event.Tags {
-			if len(tag) >= 2 && tag[0] == "title" {
-				return tag[1]
-			}
-		}
+	if preview := firstLine(event.Content); preview != "" {
+		preview = truncate(preview, previewLength)
+		return preview, preview
 	}
-
-	return fmt.Sprintf("Event %s...", truncate(eventID, 8))
+	return fmt.Sprintf("Event %s...", eventID[:min(len(eventID), 8)]), ""
 }
 
-// resolveAddrTitle fetches the title for a parameterized replaceable event
-func (r *Resolver) resolveAddrTitle(ctx context.Context, addr *nostr.EntityPointer) string {
+// resolveAddrTitle returns the display name and preview of a parameterized
+// replaceable event: its title tag, or its identifier
+func (r *Resolver) resolveAddrTitle(ctx context.Context, addr *nostr.EntityPointer) (string, string) {
 	filter := nostr.Filter{
 		Authors: []string{addr.PublicKey},
 		Kinds:   []int{addr.Kind},
@@ -178,29 +199,23 @@ func (r *Resolver) resolveAddrTitle(ctx context.Context, addr *nostr.EntityPoint
 
 	events, err := r.storage.QueryEvents(ctx, filter)
 	if err != nil || len(events) == 0 {
-		return fmt.Sprintf("%s by %s", addr.Identifier, truncatePubkey(addr.PublicKey))
+		return fmt.Sprintf("%s by %s", addr.Identifier, truncatePubkey(addr.PublicKey)), ""
 	}
 
-	event := events[0]
-
-	// Check for title tag (common in articles)
-	for _, tag := range event.Tags {
-		if len(tag) >= 2 && tag[0] == "title" {
-			return tag[1]
-		}
+	if title := titleTag(events[0]); title != "" {
+		return title, truncate(title, previewLength)
 	}
-
-	// Fallback to identifier
 	if addr.Identifier != "" {
-		return addr.Identifier
+		return addr.Identifier, truncate(addr.Identifier, previewLength)
 	}
 
-	return fmt.Sprintf("Article by %s", truncatePubkey(addr.PublicKey))
+	return fmt.Sprintf("Article by %s", truncatePubkey(addr.PublicKey)), ""
 }
 
-// ReplaceEntities replaces all NIP-19 entities in text with their resolved forms
-// Returns the modified text
+// ReplaceEntities replaces all NIP-19 entities in text with their resolved forms,
+// after removing links to blocked domains. Returns the modified text
 func (r *Resolver) ReplaceEntities(ctx context.Context, text string, formatter func(*Entity) string) string {
+	text = r.blocklist.Scrub(text)
 	return nostrEntityRegex.ReplaceAllStringFunc(text, func(match string) string {
 		entityStr := strings.TrimPrefix(match, "nostr:")
 		entity, err := r.ResolveEntity(ctx, entityStr)
@@ -222,8 +237,29 @@ func truncatePubkey(pubkey string) string {
 }
 
 func truncate(text string, maxLen int) string {
-	if len(text) <= maxLen {
+	runes := []rune(text)
+	if len(runes) <= maxLen {
 		return text
 	}
-	return text[:maxLen-3] + "..."
+	return string(runes[:maxLen-3]) + "..."
+}
+
+// titleTag returns the value of an event's title tag, if any
+func titleTag(event *nostr.Event) string {
+	for _, tag := range event.Tags {
+		if len(tag) >= 2 && tag[0] == "title" && strings.TrimSpace(tag[1]) != "" {
+			return strings.Join(strings.Fields(tag[1]), " ")
+		}
+	}
+	return ""
+}
+
+// firstLine returns the first non-empty line of text with its whitespace collapsed
+func firstLine(text string) string {
+	for _, line := range strings.Split(text, "\n") {
+		if line = strings.Join(strings.Fields(line), " "); line != "" {
+			return line
+		}
+	}
+	return ""
 }
@@ -0,0 +1,61 @@
+package entities
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/nbd-wtf/go-nostr"
+	"github.com/nbd-wtf/go-nostr/nip19"
+	"github.com/sandwich/nophr/internal/config"
+	"github.com/sandwich/nophr/internal/storage"
+)
+
+func TestReplaceEntitiesPreviews(t *testing.T) {
+	ctx := context.Background()
+	st, err := storage.New(ctx, &config.Storage{Driver: "sqlite", SQLitePath: filepath.Join(t.TempDir(), "test.db")})
+	if err != nil {
+		t.Fatalf("storage.New() error = %v", err)
+	}
+	defer st.Close()
+
+	secretKey := nostr.GeneratePrivateKey()
+	pubkey, _ := nostr.GetPublicKey(secretKey)
+	store := func(event *nostr.Event) *nostr.Event {
+		event.CreatedAt = nostr.Now()
+		event.Sign(secretKey)
+		if err := st.StoreEvent(ctx, event); err != nil {
+			t.Fatal(err)
+		}
+		return event
+	}
+	store(&nostr.Event{Kind: 0, Content: `{"name":"alice","display_name":"Alice"}`, Tags: nostr.Tags{}})
+	note := store(&nostr.Event{Kind: 1, Content: "\n  Hello   [world], this is the first line\nand the second", Tags: nostr.Tags{}})
+
+	npub, _ := nip19.EncodePublicKey(pubkey)
+	nevent, _ := nip19.EncodeEvent(note.ID, nil, pubkey)
+	missing, _ := nip19.EncodeNote("0000000000000000000000000000000000000000000000000000000000000001")
+	text := "cc nostr:" + npub + " re nostr:" + nevent + " and nostr:" + missing
+
+	gemini := NewResolver(st, LinkContext{Protocol: ProtocolGemini, Host: "example.com"})
+	want := "cc [@Alice](gemini://example.com/profile/" + pubkey + ") re " +
+		`["Hello (world), this is the first line"](gemini://example.com/note/` + note.ID + ") and " +
+		"[Note 00000000...](gemini://example.com/note/0000000000000000000000000000000000000000000000000000000000000001)"
+	if got := gemini.ReplaceEntities(ctx, text, GeminiFormatter); got != want {
+		t.Errorf("ReplaceEntities(Gemini) =\n%s\nwant\n%s", got, want)
+	}
+
+	gopher := NewResolver(st, LinkContext{Protocol: ProtocolGopher})
+	want = "cc @Alice (/profile/" + pubkey + ") re " +
+		`"Hello [world], this is the first line" (/note/` + note.ID + ") and " +
+		"Note 00000000... (/note/0000000000000000000000000000000000000000000000000000000000000001)"
+	if got := gopher.ReplaceEntities(ctx, text, GopherFormatter); got != want {
+		t.Errorf("ReplaceEntities(Gopher) =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestTruncateRunes(t *testing.T) {
+	if got := truncate("héllo wörld", 8); got != "héllo..." {
+		t.Errorf("truncate() = %q, want %q", got, "héllo...")
+	}
+}
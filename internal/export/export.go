@@ -0,0 +1,58 @@
+// Package export renders the site to static files, so it can be hosted by an
+// existing Gopher or Gemini server without running nophr continuously.
+//
+// Exporters crawl the same routes the live servers answer, starting at the
+// root and following every local link, and write each response to disk.
+package export
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Formats lists the supported export formats
+var Formats = []string{"gopher", "gemini"}
+
+// RouteFunc renders the response for a selector or path, as the live server would
+type RouteFunc func(selector string) []byte
+
+// Result summarises an export
+type Result struct {
+	Menus   int // Gophermaps or gemtext index pages written
+	Files   int // Text files or gemtext pages written
+	Skipped int // Links not exported (search prompts, errors, unsafe paths)
+}
+
+// outputPath maps a selector to a path under out. It returns false for
+// selectors that would escape out, such as ones with ".." segments.
+func outputPath(out, selector string) (string, bool) {
+	for _, segment := range strings.Split(selector, "/") {
+		if segment == ".." || strings.ContainsAny(segment, "\\\x00") {
+			return "", false
+		}
+	}
+	return filepath.Join(out, filepath.FromSlash(strings.TrimPrefix(selector, "/"))), true
+}
+
+// isLiveOnly reports whether a selector is, or is below, one of prefixes
+func isLiveOnly(selector string, prefixes []string) bool {
+	for _, prefix := range prefixes {
+		if selector == prefix || strings.HasPrefix(selector, prefix+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+// writeFile writes data to path, creating parent directories
+func writeFile(path string, data []byte) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create directory for %s: %w", path, err)
+	}
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	return nil
+}
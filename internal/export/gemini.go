@@ -0,0 +1,268 @@
+package export
+
+import (
+	"fmt"
+	"net/url"
+	"path"
+	"strconv"
+	"strings"
+
+	"github.com/nbd-wtf/go-nostr"
+	"github.com/nbd-wtf/go-nostr/nip19"
+)
+
+// geminiIndexName is the directory index served by molly-brown and agate
+const geminiIndexName = "index.gmi"
+
+// geminiLiveOnly are paths that only make sense on a running server
+var geminiLiveOnly = []string{"/search", "/goto", "/diagnostics", "/wallet", "/trash"}
+
+// EventLookup returns the stored event with the given ID, or nil
+type EventLookup func(id string) *nostr.Event
+
+// Gemini writes a static capsule to out: the home page becomes index.gmi and
+// every other page <path>.gmi, with links rewritten to match. Notes, threads
+// and profiles are named by their nevent, naddr or npub rather than hex IDs,
+// and articles by naddr so an edited article keeps its filename.
+// Search, goto, diagnostics, wallet, trash and query links are dropped since a
+// static server can't answer them. lookup may be nil, in which case articles
+// are named by nevent like notes.
+func Gemini(route RouteFunc, lookup EventLookup, host string, port int, out string) (*Result, error) {
+	origin := "gemini://" + host
+	if port != 1965 {
+		origin = fmt.Sprintf("%s:%d", origin, port)
+	}
+
+	e := &geminiExporter{
+		route:  route,
+		lookup: lookup,
+		origin: origin,
+		out:    out,
+		files:  make(map[string]string),
+		result: &Result{},
+	}
+
+	e.visit("/")
+	for len(e.queue) > 0 {
+		page := e.queue[0]
+		e.queue = e.queue[1:]
+
+		if err := e.writePage(page); err != nil {
+			return e.result, err
+		}
+	}
+
+	return e.result, nil
+}
+
+// geminiPage is a rendered page waiting to be written
+type geminiPage struct {
+	file   string
+	entity bool // a note, thread or profile rather than an index page
+	body   string
+}
+
+type geminiExporter struct {
+	route  RouteFunc
+	lookup EventLookup
+	origin string
+	out    string
+	files  map[string]string // path -> static file, "" if it isn't exported
+	queue  []geminiPage
+	result *Result
+}
+
+// visit renders a path the first time it's linked, queues it for writing and
+// returns its static file, or "" if it can't be exported. Redirects, such as
+// NIP-19 deep links and aliases, resolve to the file of the page they target.
+func (e *geminiExporter) visit(p string) string {
+	if file, ok := e.files[p]; ok {
+		return file
+	}
+	e.files[p] = ""
+
+	if isLiveOnly(p, geminiLiveOnly) {
+		e.result.Skipped++
+		return ""
+	}
+
+	header, body, _ := strings.Cut(string(e.route(p)), "\r\n")
+	status, meta, _ := strings.Cut(header, " ")
+
+	switch {
+	case strings.HasPrefix(status, "3"):
+		target := e.localURL(meta)
+		if target == nil || target.RawQuery != "" {
+			e.result.Skipped++
+			return ""
+		}
+		file := e.visit(target.Path)
+		e.files[p] = file
+		return file
+	case !strings.HasPrefix(status, "2") || !strings.HasPrefix(meta, "text/gemini"):
+		e.result.Skipped++
+		return ""
+	}
+
+	file, entity := e.fileName(p)
+	if _, ok := outputPath(e.out, file); !ok {
+		e.result.Skipped++
+		return ""
+	}
+
+	e.files[p] = file
+	e.queue = append(e.queue, geminiPage{file: file, entity: entity, body: body})
+	return file
+}
+
+// writePage rewrites a page's local links to their static files and writes it
+func (e *geminiExporter) writePage(page geminiPage) error {
+	var gemtext strings.Builder
+	preformatted := false
+	for _, line := range strings.SplitAfter(page.body, "\n") {
+		if strings.HasPrefix(line, "```") {
+			preformatted = !preformatted
+		}
+		if preformatted || !strings.HasPrefix(line, "=>") {
+			gemtext.WriteString(line)
+			continue
+		}
+
+		link, label := splitLink(line)
+		target := e.localURL(link)
+		if target == nil {
+			gemtext.WriteString(line)
+			continue
+		}
+		if target.RawQuery != "" {
+			// Input prompts need a running server
+			e.result.Skipped++
+			continue
+		}
+
+		file := e.visit(target.Path)
+		if file == "" {
+			continue
+		}
+		if target.Scheme != "" {
+			file = e.origin + file
+		}
+		if label != "" {
+			file += " " + label
+		}
+		gemtext.WriteString("=> " + file + "\n")
+	}
+
+	dest, _ := outputPath(e.out, page.file)
+	if err := writeFile(dest, []byte(gemtext.String())); err != nil {
+		return err
+	}
+	if page.entity {
+		e.result.Files++
+	} else {
+		e.result.Menus++
+	}
+	return nil
+}
+
+// localURL parses a link and returns it if it points at this capsule
+func (e *geminiExporter) localURL(link string) *url.URL {
+	u, err := url.Parse(link)
+	if err != nil {
+		return nil
+	}
+
+	switch {
+	case u.Scheme == "" && u.Host == "" && strings.HasPrefix(u.Path, "/"):
+	case u.Scheme == "gemini" && "gemini://"+u.Host == e.origin:
+	default:
+		return nil
+	}
+
+	if u.Path == "" {
+		u.Path = "/"
+	}
+	return u
+}
+
+// fileName maps a page path to its static file, naming note, thread and
+// profile pages by their NIP-19 encoding
+func (e *geminiExporter) fileName(p string) (string, bool) {
+	p = path.Clean(p)
+	if p == "/" {
+		return "/" + geminiIndexName, false
+	}
+
+	parts := strings.Split(strings.TrimPrefix(p, "/"), "/")
+	if name := e.entityName(parts); name != "" {
+		return name + ".gmi", true
+	}
+	return p + ".gmi", false
+}
+
+// entityName returns the stable path for an entity page, or "" if parts isn't one
+func (e *geminiExporter) entityName(parts []string) string {
+	if len(parts) < 2 {
+		return ""
+	}
+
+	switch parts[0] {
+	case "note", "thread":
+		if len(parts) != 2 || !nostr.IsValid32ByteHex(parts[1]) {
+			return ""
+		}
+		if parts[0] == "note" && e.lookup != nil {
+			if event := e.lookup(parts[1]); event != nil && nostr.IsAddressableKind(event.Kind) {
+				return addrName(event.Kind, event.PubKey, event.Tags.GetD())
+			}
+		}
+		nevent, err := nip19.EncodeEvent(parts[1], nil, "")
+		if err != nil {
+			return ""
+		}
+		return "/" + parts[0] + "/" + nevent
+
+	case "profile", "author":
+		if !nostr.IsValid32ByteHex(parts[1]) {
+			return ""
+		}
+		npub, err := nip19.EncodePublicKey(parts[1])
+		if err != nil {
+			return ""
+		}
+		parts[1] = npub
+		return "/" + strings.Join(parts, "/")
+
+	case "addr":
+		// /addr/<kind>/<pubkey>/<d> renders the same page as /note/<id>
+		if len(parts) < 3 || !nostr.IsValid32ByteHex(parts[2]) {
+			return ""
+		}
+		kind, err := strconv.Atoi(parts[1])
+		if err != nil {
+			return ""
+		}
+		return addrName(kind, parts[2], strings.Join(parts[3:], "/"))
+	}
+
+	return ""
+}
+
+// addrName is the stable path for an addressable event
+func addrName(kind int, pubkey, identifier string) string {
+	naddr, err := nip19.EncodeEntity(pubkey, kind, identifier, nil)
+	if err != nil {
+		return ""
+	}
+	return "/note/" + naddr
+}
+
+// splitLink splits a "=> URL label" line into its URL and label
+func splitLink(line string) (string, string) {
+	fields := strings.TrimSpace(strings.TrimPrefix(line, "=>"))
+	i := strings.IndexAny(fields, " \t")
+	if i < 0 {
+		return fields, ""
+	}
+	return fields[:i], strings.TrimSpace(fields[i+1:])
+}
@@ -0,0 +1,95 @@
+package export
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/nbd-wtf/go-nostr"
+	"github.com/nbd-wtf/go-nostr/nip19"
+)
+
+func TestGemini(t *testing.T) {
+	noteID := strings.Repeat("a", 64)
+	articleID := strings.Repeat("b", 64)
+	pubkey := strings.Repeat("c", 64)
+
+	pages := map[string]string{
+		"/": "20 text/gemini\r\n# Home\n" +
+			"=> /notes Notes\n" +
+			"=> gemini://localhost:1966/profile/" + pubkey + " Me\n" +
+			"=> /search Search\n" +
+			"=> /~blog Blog\n" +
+			"=> gemini://other.example/ Elsewhere\n",
+		"/notes": "20 text/gemini\r\n# Notes\n" +
+			"=> /note/" + noteID + " A note\n" +
+			"=> /note/" + articleID + "\tAn article\n" +
+			"=> /notes?q=x Filter\n" +
+			"```\n=> /not/a/link\n```\n",
+		"/~blog":                        "31 /notes\r\n",
+		"/note/" + noteID:               "20 text/gemini\r\nHello\n=> / Home\n",
+		"/note/" + articleID:            "20 text/gemini\r\n# Article\n",
+		"/profile/" + pubkey:            "20 text/gemini\r\n# Me\n=> /profile/" + pubkey + "/notes Notes\n",
+		"/profile/" + pubkey + "/notes": "20 text/gemini\r\n# My notes\n",
+	}
+	route := func(path string) []byte {
+		if page, ok := pages[path]; ok {
+			return []byte(page)
+		}
+		return []byte("51 Not found\r\n")
+	}
+	lookup := func(id string) *nostr.Event {
+		if id == articleID {
+			return &nostr.Event{ID: id, PubKey: pubkey, Kind: 30023, Tags: nostr.Tags{{"d", "post"}}}
+		}
+		return nil
+	}
+
+	out := t.TempDir()
+	result, err := Gemini(route, lookup, "localhost", 1966, out)
+	if err != nil {
+		t.Fatalf("Gemini export failed: %v", err)
+	}
+	if result.Menus != 2 || result.Files != 4 || result.Skipped != 2 {
+		t.Errorf("Expected 2 menus, 4 files and 2 skipped, got %+v", result)
+	}
+
+	nevent, _ := nip19.EncodeEvent(noteID, nil, "")
+	naddr, _ := nip19.EncodeEntity(pubkey, 30023, "post", nil)
+	npub, _ := nip19.EncodePublicKey(pubkey)
+
+	home := readFile(t, filepath.Join(out, "index.gmi"))
+	for _, want := range []string{
+		"=> /notes.gmi Notes\n",
+		"=> gemini://localhost:1966/profile/" + npub + ".gmi Me\n",
+		"=> /notes.gmi Blog\n",
+		"=> gemini://other.example/ Elsewhere\n",
+	} {
+		if !strings.Contains(home, want) {
+			t.Errorf("Expected %q in index.gmi, got:\n%s", want, home)
+		}
+	}
+	if strings.Contains(home, "Search") {
+		t.Error("Search links should be dropped from a static capsule")
+	}
+
+	notes := readFile(t, filepath.Join(out, "notes.gmi"))
+	if !strings.Contains(notes, "=> /note/"+nevent+".gmi A note\n") {
+		t.Errorf("Notes should be named by nevent, got:\n%s", notes)
+	}
+	if !strings.Contains(notes, "=> /note/"+naddr+".gmi An article\n") {
+		t.Errorf("Articles should be named by naddr, got:\n%s", notes)
+	}
+	if strings.Contains(notes, "Filter") {
+		t.Error("Links with queries should be dropped")
+	}
+	if !strings.Contains(notes, "```\n=> /not/a/link\n```\n") {
+		t.Error("Preformatted text should be left alone")
+	}
+
+	if got := readFile(t, filepath.Join(out, "note", nevent+".gmi")); got != "Hello\n=> /index.gmi Home\n" {
+		t.Errorf("Unexpected note page %q", got)
+	}
+	readFile(t, filepath.Join(out, "note", naddr+".gmi"))
+	readFile(t, filepath.Join(out, "profile", npub, "notes.gmi"))
+}
@@ -0,0 +1,169 @@
+package export
+
+import (
+	"bytes"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// gopherMapName is the menu file read by gophernicus and pygopherd
+const gopherMapName = "gophermap"
+
+// gopherLiveOnly are selectors that only make sense on a running server
+var gopherLiveOnly = []string{"/search", "/diagnostics"}
+
+// Gopher writes a static gopherhole to out: each menu becomes <selector>/gophermap
+// and each text item becomes a .txt file, with selectors rewritten to match.
+// Only links to host:port are followed; search and diagnostics links are dropped
+// since a static server can't answer them.
+func Gopher(route RouteFunc, host string, port int, out string) (*Result, error) {
+	e := &gopherExporter{
+		route:   route,
+		host:    host,
+		port:    strconv.Itoa(port),
+		out:     out,
+		visited: make(map[string]bool),
+		result:  &Result{},
+	}
+
+	queue := []string{"/"}
+	for len(queue) > 0 {
+		selector := queue[0]
+		queue = queue[1:]
+
+		links, err := e.exportMenu(selector)
+		if err != nil {
+			return e.result, err
+		}
+		queue = append(queue, links...)
+	}
+
+	return e.result, nil
+}
+
+type gopherExporter struct {
+	route   RouteFunc
+	host    string
+	port    string
+	out     string
+	visited map[string]bool
+	result  *Result
+}
+
+// exportMenu renders a menu, exports the text files it links to and returns
+// the local menus it links to that haven't been visited yet
+func (e *gopherExporter) exportMenu(selector string) ([]string, error) {
+	e.visited[selector] = true
+
+	dir, ok := outputPath(e.out, selector)
+	if !ok {
+		e.result.Skipped++
+		return nil, nil
+	}
+
+	response := e.route(selector)
+	if isGopherError(response) {
+		e.result.Skipped++
+		return nil, nil
+	}
+
+	var menu bytes.Buffer
+	var links []string
+	for _, line := range gopherLines(response) {
+		fields := strings.Split(line, "\t")
+		if len(fields) < 4 || fields[2] != e.host || fields[3] != e.port {
+			menu.WriteString(line + "\n")
+			continue
+		}
+
+		target := fields[1]
+		if isLiveOnly(target, gopherLiveOnly) {
+			e.result.Skipped++
+			continue
+		}
+
+		switch line[0] {
+		case '1':
+			if !e.visited[target] {
+				e.visited[target] = true
+				links = append(links, target)
+			}
+		case '0':
+			file, err := e.exportText(target)
+			if err != nil {
+				return nil, err
+			}
+			if file == "" {
+				continue
+			}
+			fields[1] = file
+			line = strings.Join(fields, "\t")
+		case '7':
+			// Static servers can't run searches
+			e.result.Skipped++
+			continue
+		}
+		menu.WriteString(line + "\n")
+	}
+
+	if err := writeFile(filepath.Join(dir, gopherMapName), menu.Bytes()); err != nil {
+		return nil, err
+	}
+	e.result.Menus++
+	return links, nil
+}
+
+// exportText writes a text item once and returns its static selector, or ""
+// if it couldn't be exported
+func (e *gopherExporter) exportText(selector string) (string, error) {
+	file := selector
+	if !strings.HasSuffix(file, ".txt") {
+		file += ".txt"
+	}
+	if e.visited[selector] {
+		return file, nil
+	}
+	e.visited[selector] = true
+
+	path, ok := outputPath(e.out, file)
+	if !ok {
+		e.result.Skipped++
+		return "", nil
+	}
+
+	response := e.route(selector)
+	if isGopherError(response) {
+		e.result.Skipped++
+		return "", nil
+	}
+
+	// The static server sends its own terminator
+	body := bytes.TrimSuffix(response, []byte(".\r\n"))
+	if err := writeFile(path, body); err != nil {
+		return "", err
+	}
+	e.result.Files++
+	return file, nil
+}
+
+// gopherLines splits a menu response into lines without terminators, stopping
+// at the lone "." that ends it
+func gopherLines(response []byte) []string {
+	var lines []string
+	for _, line := range strings.Split(string(response), "\n") {
+		line = strings.TrimSuffix(line, "\r")
+		if line == "." {
+			break
+		}
+		if line != "" {
+			lines = append(lines, line)
+		}
+	}
+	return lines
+}
+
+// isGopherError reports whether a response is an error menu
+func isGopherError(response []byte) bool {
+	return len(response) == 0 || response[0] == '3'
+}
@@ -0,0 +1,76 @@
+package export
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestGopher(t *testing.T) {
+	pages := map[string]string{
+		"/": "iWelcome\tfake\tlocalhost\t70\r\n" +
+			"1Notes\t/notes\tlocalhost\t70\r\n" +
+			"7Search\t/search\tlocalhost\t70\r\n" +
+			"1Elsewhere\t/\tother.example\t70\r\n" +
+			".\r\n",
+		"/notes": "0First note\t/note/abc\tlocalhost\t70\r\n" +
+			"0Article\t/articles/def/title.txt\tlocalhost\t70\r\n" +
+			"1Escape\t/../etc\tlocalhost\t70\r\n" +
+			"1Home\t/\tlocalhost\t70\r\n" +
+			".\r\n",
+		"/note/abc":               "Hello\r\n.\r\n",
+		"/articles/def/title.txt": "Title\r\n.\r\n",
+	}
+	route := func(selector string) []byte {
+		if page, ok := pages[selector]; ok {
+			return []byte(page)
+		}
+		return []byte("3Not found\tfake\tlocalhost\t70\r\n.\r\n")
+	}
+
+	out := t.TempDir()
+	result, err := Gopher(route, "localhost", 70, out)
+	if err != nil {
+		t.Fatalf("Gopher export failed: %v", err)
+	}
+	if result.Menus != 2 || result.Files != 2 || result.Skipped != 2 {
+		t.Errorf("Expected 2 menus, 2 files and 2 skipped, got %+v", result)
+	}
+
+	root := readFile(t, filepath.Join(out, "gophermap"))
+	if strings.Contains(root, "Search") {
+		t.Error("Search items should be dropped from static menus")
+	}
+	if !strings.Contains(root, "1Elsewhere\t/\tother.example\t70\n") {
+		t.Error("Links to other hosts should be kept unchanged")
+	}
+
+	notes := readFile(t, filepath.Join(out, "notes", "gophermap"))
+	if !strings.Contains(notes, "0First note\t/note/abc.txt\tlocalhost\t70\n") {
+		t.Errorf("Text selectors should point at the exported .txt file, got:\n%s", notes)
+	}
+	if strings.Contains(notes, ".\r\n") || strings.HasSuffix(notes, ".\n") {
+		t.Error("Static gophermaps should not carry the wire terminator")
+	}
+
+	if got := readFile(t, filepath.Join(out, "note", "abc.txt")); got != "Hello\r\n" {
+		t.Errorf("Unexpected note file %q", got)
+	}
+	if _, err := os.Stat(filepath.Join(out, "articles", "def", "title.txt")); err != nil {
+		t.Errorf("Article text file should keep its .txt selector: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(filepath.Dir(out), "etc")); err == nil {
+		t.Error("Selectors with .. must not write outside the output directory")
+	}
+}
+
+func readFile(t *testing.T, path string) string {
+	t.Helper()
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Failed to read %s: %v", path, err)
+	}
+	return string(data)
+}
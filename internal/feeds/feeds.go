@@ -0,0 +1,259 @@
+// Package feeds builds Atom and gemini subscription feeds of the owner's posts.
+//
+// Feeds mirror the listing pages: /feed.xml covers notes and articles, and
+// <listing>/feed.xml covers /notes, /articles or a custom section. Entries come
+// from the same QueryHelper and section queries the listings use, so content
+// filters, thread settings and sort order carry over. Routers only supply the
+// URLs, which differ per protocol.
+package feeds
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/nbd-wtf/go-nostr"
+	"github.com/sandwich/nophr/internal/aggregates"
+	"github.com/sandwich/nophr/internal/config"
+	"github.com/sandwich/nophr/internal/entities"
+	"github.com/sandwich/nophr/internal/sections"
+)
+
+// Format is a feed document type, named by its file in the listing
+type Format string
+
+const (
+	FormatAtom   Format = "feed.xml" // Atom 1.0
+	FormatGemini Format = "feed.gmi" // Gemini subscription page (gmisub)
+)
+
+// entryLimit is how many posts a feed carries
+const entryLimit = 50
+
+// ErrNoFeed is returned for listings that have no feed
+var ErrNoFeed = errors.New("no feed for this path")
+
+// Feed is a listing's newest posts, ready to render in either format
+type Feed struct {
+	Title       string
+	Description string
+	Author      string
+	AuthorURL   string
+	License     config.License
+	Attributed  bool // The site sets an explicit author or license for gemtext to show
+	Updated     time.Time
+	Entries     []*Entry
+}
+
+// Entry is one post in a feed
+type Entry struct {
+	Event     *nostr.Event
+	Content   string // Event content without links to rendering.blocked_domains
+	Title     string
+	Summary   string // Article summary tag, if any
+	Published time.Time
+	Updated   time.Time
+}
+
+// Links turns a feed into URLs for the protocol serving it
+type Links struct {
+	Self  string // The feed document itself
+	Page  string // The listing the feed mirrors
+	Entry func(event *nostr.Event) string
+}
+
+// Parse splits a feed path such as /notes/feed.xml into the listing it
+// covers ("/notes") and its format. ok is false for other paths.
+func Parse(path string) (listing string, format Format, ok bool) {
+	for _, f := range []Format{FormatAtom, FormatGemini} {
+		if path == "/"+string(f) {
+			return "/", f, true
+		}
+		if listing, found := strings.CutSuffix(path, "/"+string(f)); found && listing != "" {
+			return listing, f, true
+		}
+	}
+	return "", "", false
+}
+
+// Builder assembles feeds from the queries behind the listing pages
+type Builder struct {
+	config    *config.Config
+	queries   *aggregates.QueryHelper
+	sections  *sections.Manager
+	blocklist *entities.DomainBlocklist
+}
+
+// NewBuilder creates a feed builder
+func NewBuilder(cfg *config.Config, queries *aggregates.QueryHelper, sectionManager *sections.Manager) *Builder {
+	return &Builder{
+		config:    cfg,
+		queries:   queries,
+		sections:  sectionManager,
+		blocklist: entities.NewDomainBlocklist(cfg.Rendering.BlockedDomains),
+	}
+}
+
+// Build returns the feed for a listing: "/" for notes and articles together,
+// "/notes", "/articles", or the path of a custom section. It returns ErrNoFeed
+// for any other listing.
+func (b *Builder) Build(ctx context.Context, listing string) (*Feed, error) {
+	var events []*nostr.Event
+	var title string
+
+	switch listing {
+	case "/":
+		notes, err := b.queries.GetNotesPage(ctx, nil, entryLimit)
+		if err != nil {
+			return nil, err
+		}
+		articles, err := b.queries.GetArticlesPage(ctx, nil, entryLimit)
+		if err != nil {
+			return nil, err
+		}
+		events = newestFirst(append(pageEvents(notes), pageEvents(articles)...))
+
+	case "/notes":
+		notes, err := b.queries.GetNotesPage(ctx, nil, entryLimit)
+		if err != nil {
+			return nil, err
+		}
+		events, title = pageEvents(notes), "Notes"
+
+	case "/articles":
+		articles, err := b.queries.GetArticlesPage(ctx, nil, entryLimit)
+		if err != nil {
+			return nil, err
+		}
+		events, title = pageEvents(articles), "Articles"
+
+	default:
+		var err error
+		events, title, err = b.sectionEvents(ctx, listing)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if len(events) > entryLimit {
+		events = events[:entryLimit]
+	}
+
+	feed := &Feed{
+		Title:       b.config.Site.Title,
+		Description: b.config.Site.Description,
+		Author:      b.config.Site.AuthorName(),
+		AuthorURL:   b.config.Site.AuthorURL,
+		License:     b.config.Site.License,
+		Attributed:  b.config.Site.HasAttribution(),
+	}
+	if feed.Title == "" {
+		feed.Title = "nophr"
+	}
+	if title != "" {
+		feed.Title += " - " + title
+	}
+	for _, event := range events {
+		entry := newEntry(event)
+		entry.Content = b.blocklist.Scrub(event.Content)
+		feed.Entries = append(feed.Entries, entry)
+	}
+
+	// A feed is as fresh as its newest post, so unchanged feeds keep their timestamp
+	for _, entry := range feed.Entries {
+		if entry.Updated.After(feed.Updated) {
+			feed.Updated = entry.Updated
+		}
+	}
+	if feed.Updated.IsZero() {
+		feed.Updated = time.Unix(0, 0).UTC()
+	}
+
+	return feed, nil
+}
+
+// sectionEvents returns the newest events of the custom sections at path
+func (b *Builder) sectionEvents(ctx context.Context, path string) ([]*nostr.Event, string, error) {
+	if b.sections == nil {
+		return nil, "", ErrNoFeed
+	}
+
+	matched := b.sections.GetSectionsByPath(path)
+	if len(matched) == 0 {
+		return nil, "", ErrNoFeed
+	}
+
+	var events []*nostr.Event
+	for _, section := range matched {
+		page, err := b.sections.GetPage(ctx, section.Name, nil)
+		if err != nil {
+			return nil, "", err
+		}
+		events = append(events, page.Events...)
+	}
+
+	return newestFirst(events), matched[0].Title, nil
+}
+
+// pageEvents returns a listing page's posts, leaving out reposts of other people's notes
+func pageEvents(page *aggregates.EventPage) []*nostr.Event {
+	var events []*nostr.Event
+	for _, enriched := range page.Events {
+		if enriched.Event.Kind != nostr.KindRepost {
+			events = append(events, enriched.Event)
+		}
+	}
+	return events
+}
+
+// newestFirst sorts events by created_at, newest first
+func newestFirst(events []*nostr.Event) []*nostr.Event {
+	sort.SliceStable(events, func(i, j int) bool {
+		return events[i].CreatedAt > events[j].CreatedAt
+	})
+	return events
+}
+
+// newEntry describes an event as a feed entry
+func newEntry(event *nostr.Event) *Entry {
+	entry := &Entry{
+		Event:     event,
+		Content:   event.Content,
+		Title:     entryTitle(event),
+		Updated:   event.CreatedAt.Time().UTC(),
+		Published: event.CreatedAt.Time().UTC(),
+	}
+
+	if summary := event.Tags.Find("summary"); len(summary) >= 2 {
+		entry.Summary = strings.TrimSpace(summary[1])
+	}
+
+	// Edited articles keep their original publication time in published_at
+	if published := event.Tags.Find("published_at"); len(published) >= 2 {
+		if ts, err := strconv.ParseInt(published[1], 10, 64); err == nil && ts > 0 {
+			entry.Published = time.Unix(ts, 0).UTC()
+		}
+	}
+
+	return entry
+}
+
+// entryTitle returns an article's title tag, or the first line of a note
+func entryTitle(event *nostr.Event) string {
+	if title := event.Tags.Find("title"); len(title) >= 2 && strings.TrimSpace(title[1]) != "" {
+		return strings.TrimSpace(title[1])
+	}
+
+	line := strings.Join(strings.Fields(strings.Split(strings.TrimSpace(event.Content), "\n")[0]), " ")
+	runes := []rune(line)
+	switch {
+	case len(runes) == 0:
+		return "Untitled note"
+	case len(runes) > 80:
+		return string(runes[:79]) + "…"
+	}
+	return line
+}
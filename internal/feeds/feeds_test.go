@@ -0,0 +1,141 @@
+package feeds
+
+import (
+	"encoding/xml"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/nbd-wtf/go-nostr"
+	"github.com/sandwich/nophr/internal/config"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		path    string
+		listing string
+		format  Format
+		ok      bool
+	}{
+		{"/feed.xml", "/", FormatAtom, true},
+		{"/feed.gmi", "/", FormatGemini, true},
+		{"/notes/feed.xml", "/notes", FormatAtom, true},
+		{"/diy/feed.gmi", "/diy", FormatGemini, true},
+		{"/notes", "", "", false},
+		{"/notes/feed.xml.bak", "", "", false},
+		{"/myfeed.xml", "", "", false},
+	}
+
+	for _, tt := range tests {
+		listing, format, ok := Parse(tt.path)
+		if listing != tt.listing || format != tt.format || ok != tt.ok {
+			t.Errorf("Parse(%s) = %q, %q, %v; want %q, %q, %v", tt.path, listing, format, ok, tt.listing, tt.format, tt.ok)
+		}
+	}
+}
+
+func TestEntry(t *testing.T) {
+	note := &nostr.Event{
+		ID:        strings.Repeat("a", 64),
+		CreatedAt: 1718020800,
+		Kind:      1,
+		Content:   "  First   line\nsecond line",
+	}
+	entry := newEntry(note)
+	if entry.Title != "First line" {
+		t.Errorf("Expected the note's first line as title, got %q", entry.Title)
+	}
+
+	article := &nostr.Event{
+		ID:        strings.Repeat("b", 64),
+		PubKey:    strings.Repeat("c", 64),
+		CreatedAt: 1718020800,
+		Kind:      30023,
+		Tags:      nostr.Tags{{"d", "post"}, {"title", "An Article"}, {"published_at", "1700000000"}},
+	}
+	entry = newEntry(article)
+	if entry.Title != "An Article" {
+		t.Errorf("Expected the title tag, got %q", entry.Title)
+	}
+	if !entry.Published.Equal(time.Unix(1700000000, 0)) || !entry.Updated.Equal(time.Unix(1718020800, 0)) {
+		t.Errorf("Edited articles should keep published_at, got published %v updated %v", entry.Published, entry.Updated)
+	}
+	if id := entryID(article); !strings.HasPrefix(id, "nostr:naddr1") {
+		t.Errorf("Articles should be identified by naddr so edits update the entry, got %s", id)
+	}
+
+	if title := entryTitle(&nostr.Event{Content: strings.Repeat("x", 100)}); len([]rune(title)) != 80 {
+		t.Errorf("Long first lines should be truncated to 80 runes, got %d", len([]rune(title)))
+	}
+}
+
+func TestRender(t *testing.T) {
+	note := &nostr.Event{ID: strings.Repeat("a", 64), CreatedAt: 1718020800, Kind: 1, Content: "Hello <world> & more"}
+	feed := &Feed{
+		Title:   "Site - Notes",
+		Author:  "Operator",
+		Updated: note.CreatedAt.Time().UTC(),
+		Entries: []*Entry{newEntry(note)},
+	}
+	links := Links{
+		Self:  "gemini://example.com/notes/feed.xml",
+		Page:  "gemini://example.com/notes",
+		Entry: func(event *nostr.Event) string { return "gemini://example.com/note/" + event.ID },
+	}
+
+	var doc atomFeed
+	if err := xml.Unmarshal(feed.Atom(links), &doc); err != nil {
+		t.Fatalf("Atom feed is not valid XML: %v", err)
+	}
+	if len(doc.Entries) != 1 || doc.Entries[0].Content.Body != note.Content {
+		t.Errorf("Expected the note content to round-trip, got %+v", doc.Entries)
+	}
+	if doc.Updated != "2024-06-10T12:00:00Z" {
+		t.Errorf("Unexpected feed updated time %s", doc.Updated)
+	}
+
+	gemtext := feed.Gemini(links)
+	if !strings.Contains(gemtext, "=> gemini://example.com/note/"+note.ID+" 2024-06-10 - Hello <world> & more\n") {
+		t.Errorf("Entries should be dated links for gmisub readers, got:\n%s", gemtext)
+	}
+	if strings.Contains(gemtext, "Written by") {
+		t.Errorf("Feeds without an explicit author or license should not carry an attribution, got:\n%s", gemtext)
+	}
+}
+
+func TestRenderLicense(t *testing.T) {
+	feed := &Feed{
+		Title:      "Site",
+		Author:     "Alice",
+		AuthorURL:  "https://alice.example/",
+		License:    config.License{Name: "CC BY-SA 4.0", URL: "https://creativecommons.org/licenses/by-sa/4.0/"},
+		Attributed: true,
+		Updated:    time.Unix(0, 0).UTC(),
+	}
+	links := Links{Self: "gemini://example.com/feed.xml", Page: "gemini://example.com/"}
+
+	var doc atomFeed
+	if err := xml.Unmarshal(feed.Atom(links), &doc); err != nil {
+		t.Fatalf("Atom feed is not valid XML: %v", err)
+	}
+	if doc.Rights != "CC BY-SA 4.0" || doc.Author.URI != "https://alice.example/" {
+		t.Errorf("Expected rights and author URI, got %q and %q", doc.Rights, doc.Author.URI)
+	}
+	found := false
+	for _, link := range doc.Links {
+		found = found || (link.Rel == "license" && link.Href == feed.License.URL)
+	}
+	if !found {
+		t.Errorf("Expected a rel=license link, got %+v", doc.Links)
+	}
+
+	gemtext := feed.Gemini(links)
+	for _, want := range []string{
+		"=> https://alice.example/ Written by Alice\n",
+		"=> https://creativecommons.org/licenses/by-sa/4.0/ Licensed under CC BY-SA 4.0\n",
+	} {
+		if !strings.Contains(gemtext, want) {
+			t.Errorf("Expected %q in the gemini feed, got:\n%s", want, gemtext)
+		}
+	}
+}
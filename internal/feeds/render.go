@@ -0,0 +1,142 @@
+package feeds
+
+import (
+	"encoding/xml"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/nbd-wtf/go-nostr"
+	"github.com/nbd-wtf/go-nostr/nip19"
+)
+
+type atomFeed struct {
+	XMLName   xml.Name    `xml:"http://www.w3.org/2005/Atom feed"`
+	Title     string      `xml:"title"`
+	Subtitle  string      `xml:"subtitle,omitempty"`
+	ID        string      `xml:"id"`
+	Updated   string      `xml:"updated"`
+	Links     []atomLink  `xml:"link"`
+	Author    atomPerson  `xml:"author"`
+	Rights    string      `xml:"rights,omitempty"`
+	Generator string      `xml:"generator"`
+	Entries   []atomEntry `xml:"entry"`
+}
+
+type atomLink struct {
+	Rel  string `xml:"rel,attr,omitempty"`
+	Type string `xml:"type,attr,omitempty"`
+	Href string `xml:"href,attr"`
+}
+
+type atomPerson struct {
+	Name string `xml:"name"`
+	URI  string `xml:"uri,omitempty"`
+}
+
+type atomEntry struct {
+	Title     string    `xml:"title"`
+	ID        string    `xml:"id"`
+	Link      atomLink  `xml:"link"`
+	Published string    `xml:"published"`
+	Updated   string    `xml:"updated"`
+	Summary   *atomText `xml:"summary,omitempty"`
+	Content   atomText  `xml:"content"`
+}
+
+type atomText struct {
+	Type string `xml:"type,attr"`
+	Body string `xml:",chardata"`
+}
+
+// Atom renders the feed as an Atom 1.0 document
+func (f *Feed) Atom(links Links) []byte {
+	doc := atomFeed{
+		Title:    f.Title,
+		Subtitle: f.Description,
+		ID:       links.Self,
+		Updated:  f.Updated.Format(time.RFC3339),
+		Links: []atomLink{
+			{Rel: "self", Type: "application/atom+xml", Href: links.Self},
+			{Rel: "alternate", Href: links.Page},
+		},
+		Author:    atomPerson{Name: f.Author, URI: f.AuthorURL},
+		Rights:    f.License.Name,
+		Generator: "nophr",
+	}
+	if doc.Author.Name == "" {
+		doc.Author.Name = f.Title
+	}
+	if f.License.URL != "" {
+		// RFC 4946 license link, for aggregators that check reuse terms
+		doc.Links = append(doc.Links, atomLink{Rel: "license", Href: f.License.URL})
+	}
+
+	for _, entry := range f.Entries {
+		item := atomEntry{
+			Title:     entry.Title,
+			ID:        entryID(entry.Event),
+			Link:      atomLink{Rel: "alternate", Href: links.Entry(entry.Event)},
+			Published: entry.Published.Format(time.RFC3339),
+			Updated:   entry.Updated.Format(time.RFC3339),
+			Content:   atomText{Type: "text", Body: entry.Content},
+		}
+		if entry.Summary != "" {
+			item.Summary = &atomText{Type: "text", Body: entry.Summary}
+		}
+		doc.Entries = append(doc.Entries, item)
+	}
+
+	// Marshalling only fails for unsupported types, which the structs above don't use
+	body, _ := xml.MarshalIndent(doc, "", "  ")
+	return append([]byte(xml.Header), append(body, '\n')...)
+}
+
+// Gemini renders the feed as a gemini subscription page: a heading, then one
+// link per entry whose label starts with its date, as gmisub readers expect
+func (f *Feed) Gemini(links Links) string {
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("# %s\n\n", f.Title))
+	if f.Description != "" {
+		sb.WriteString(fmt.Sprintf("## %s\n\n", strings.Join(strings.Fields(f.Description), " ")))
+	}
+
+	for _, entry := range f.Entries {
+		sb.WriteString(fmt.Sprintf("=> %s %s - %s\n", links.Entry(entry.Event), entry.Published.Format("2006-01-02"), entry.Title))
+	}
+
+	if f.Attributed {
+		sb.WriteString("\n")
+		if f.Author != "" {
+			sb.WriteString(gemtextLine(f.AuthorURL, "Written by "+f.Author))
+		}
+		if f.License.Name != "" {
+			sb.WriteString(gemtextLine(f.License.URL, "Licensed under "+f.License.Name))
+		}
+	}
+
+	sb.WriteString(fmt.Sprintf("\n=> %s Back\n", links.Page))
+	return sb.String()
+}
+
+// gemtextLine returns text as a link line to url, or a plain line without one
+func gemtextLine(url, text string) string {
+	if url == "" {
+		return text + "\n"
+	}
+	return fmt.Sprintf("=> %s %s\n", url, text)
+}
+
+// entryID is a stable Atom ID for an event. Articles use their naddr so an
+// edit updates the entry rather than adding a new one.
+func entryID(event *nostr.Event) string {
+	if nostr.IsAddressableKind(event.Kind) {
+		if naddr, err := nip19.EncodeEntity(event.PubKey, event.Kind, event.Tags.GetD(), nil); err == nil {
+			return "nostr:" + naddr
+		}
+	}
+	if nevent, err := nip19.EncodeEvent(event.ID, nil, ""); err == nil {
+		return "nostr:" + nevent
+	}
+	return "nostr:" + event.ID
+}
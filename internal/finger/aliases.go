@@ -0,0 +1,77 @@
+package finger
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/nbd-wtf/go-nostr"
+	"github.com/sandwich/nophr/internal/aggregates"
+	"github.com/sandwich/nophr/internal/config"
+)
+
+// aliasListingSize is how many entries an alias listing shows
+const aliasListingSize = 10
+
+// renderAlias renders the owner or listing an alias points at
+func (h *Handler) renderAlias(ctx context.Context, alias *config.Alias, verbose bool) string {
+	if alias.IsOwner() {
+		return h.renderOwnerInfo(ctx, verbose)
+	}
+
+	if alias.IsBuiltin() {
+		page, err := h.builtinListing(ctx, alias.Target)
+		if err != nil {
+			return fmt.Sprintf("Failed to load %s: %v\n", alias.Target, err)
+		}
+
+		events := make([]*nostr.Event, 0, len(page.Events))
+		for _, enriched := range page.Events {
+			event := enriched.Event
+			if aggregates.IsRepost(event) {
+				if enriched.Reposted == nil {
+					continue
+				}
+				// Show the reposted note at the time it was reposted
+				shown := *enriched.Reposted
+				shown.CreatedAt = event.CreatedAt
+				shown.Content = "Reposted: " + shown.Content
+				event = &shown
+			}
+			events = append(events, event)
+		}
+		return h.renderer.RenderListing(strings.ToUpper(alias.Target[:1])+alias.Target[1:], events)
+	}
+
+	return h.renderSection(ctx, alias.Target)
+}
+
+// renderSection renders the newest entries of a custom section
+func (h *Handler) renderSection(ctx context.Context, name string) string {
+	page, err := h.server.GetSectionManager().GetPage(ctx, name, nil)
+	if err != nil {
+		return fmt.Sprintf("Failed to load %s: %v\n", name, err)
+	}
+
+	events := page.Events
+	if len(events) > aliasListingSize {
+		events = events[:aliasListingSize]
+	}
+	return h.renderer.RenderListing(page.Section.MenuTitle(), events)
+}
+
+// builtinListing fetches the newest page of a built-in listing
+func (h *Handler) builtinListing(ctx context.Context, target string) (*aggregates.EventPage, error) {
+	queryHelper := h.server.GetQueryHelper()
+
+	switch target {
+	case "articles":
+		return queryHelper.GetArticlesPage(ctx, nil, aliasListingSize)
+	case "replies":
+		return queryHelper.GetRepliesPage(ctx, nil, aliasListingSize)
+	case "mentions":
+		return queryHelper.GetMentionsPage(ctx, nil, aliasListingSize)
+	default:
+		return queryHelper.GetNotesPage(ctx, nil, aliasListingSize)
+	}
+}
@@ -0,0 +1,37 @@
+package finger
+
+import (
+	"testing"
+
+	"github.com/sandwich/nophr/internal/aggregates"
+	"github.com/sandwich/nophr/internal/conformance"
+)
+
+func TestConformance(t *testing.T) {
+	fixture := conformance.NewFixture()
+	cfg := conformance.Config(t, fixture)
+	st := conformance.Setup(t, fixture, cfg)
+
+	server := New(&cfg.Protocols.Finger, cfg, st, aggregates.NewManager(st, cfg))
+
+	tests := []struct {
+		golden string
+		query  string
+	}{
+		{"list.txt", ""},
+		{"owner.txt", "owner"},
+		{"owner-verbose.txt", "/W owner"},
+		{"follower.txt", fixture.Follower},
+		{"unknown-user.txt", "0000000000000000000000000000000000000000000000000000000000000000"},
+		{"forwarding.txt", "owner@example.com"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.golden, func(t *testing.T) {
+			response := wireFormat(server.handler.Handle(tt.query))
+
+			conformance.Check(t, tt.golden, conformance.CheckFinger(response))
+			conformance.Golden(t, tt.golden, response)
+		})
+	}
+}
@@ -3,10 +3,15 @@ package finger
 import (
 	"context"
 	"fmt"
+	"slices"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/nbd-wtf/go-nostr"
 	"github.com/sandwich/nophr/internal/config"
+	nostrclient "github.com/sandwich/nophr/internal/nostr"
+	"github.com/sandwich/nophr/internal/nostr/helpers"
 )
 
 // Handler handles Finger protocol queries
@@ -18,10 +23,15 @@ type Handler struct {
 
 // NewHandler creates a new query handler
 func NewHandler(server *Server, cfg *config.Config) *Handler {
+	renderer := NewRenderer(cfg.Rendering.Finger.Width)
+	if owner, err := server.GetQueryHelper().OwnerHex(); err == nil {
+		renderer.SetNames(nostrclient.NewNameResolver(server.GetStorage(), cfg.Display.Authors), owner)
+	}
+
 	return &Handler{
 		server:   server,
 		config:   cfg,
-		renderer: NewRenderer(),
+		renderer: renderer,
 	}
 }
 
@@ -72,6 +82,10 @@ func (h *Handler) Handle(queryStr string) string {
 		return h.handleListUsers(ctx, query.Verbose)
 	}
 
+	if err := h.server.GetSanitizer().ValidateFingerUsername(query.Username); err != nil {
+		return fmt.Sprintf("Invalid username: %v\n", err)
+	}
+
 	// User query
 	return h.handleUserQuery(ctx, query.Username, query.Verbose)
 }
@@ -92,6 +106,26 @@ func (h *Handler) handleUserQuery(ctx context.Context, username string, verbose
 	// Normalize username
 	username = strings.ToLower(username)
 
+	// Server status
+	if username == "status" {
+		return h.renderStatus(ctx)
+	}
+
+	// The owner's mutual follows, each fingerable by npub
+	if username == "mutuals" {
+		return h.renderMutuals(ctx)
+	}
+
+	// Configured aliases for the owner or a listing
+	if alias, ok := h.config.ResolveAlias(username); ok {
+		return h.renderAlias(ctx, alias, verbose)
+	}
+
+	// Custom sections answer by name, e.g. finger diy@host
+	if _, err := h.server.GetSectionManager().GetSection(username); err == nil {
+		return h.renderSection(ctx, username)
+	}
+
 	// Check if querying owner
 	if username == "" || username == "owner" || username == h.server.GetOwnerPubkey() {
 		return h.renderOwnerInfo(ctx, verbose)
@@ -103,8 +137,11 @@ func (h *Handler) handleUserQuery(ctx context.Context, username string, verbose
 
 // renderOwnerInfo renders information about the server owner
 func (h *Handler) renderOwnerInfo(ctx context.Context, verbose bool) string {
-	queryHelper := h.server.GetQueryHelper()
+	// Events are stored under hex pubkeys, the owner is configured as an npub
 	ownerPubkey := h.server.GetOwnerPubkey()
+	if hexPubkey, err := helpers.NormalizePubkey(ownerPubkey); err == nil {
+		ownerPubkey = hexPubkey
+	}
 
 	// Get owner's profile
 	profile, err := h.server.GetStorage().QueryEvents(ctx, nostr.Filter{
@@ -118,18 +155,54 @@ func (h *Handler) renderOwnerInfo(ctx context.Context, verbose bool) string {
 		profileEvent = profile[0]
 	}
 
-	// Get recent notes
-	notes, err := queryHelper.GetOutboxNotes(ctx, 5)
+	// Render
+	return h.renderer.RenderUser(h.userInfo(ctx, ownerPubkey, profileEvent, h.config.Rendering.Finger.Project), verbose)
+}
+
+// renderStatus renders server diagnostics
+func (h *Handler) renderStatus(ctx context.Context) string {
+	collector := h.server.GetDiagnostics()
+	if collector == nil {
+		return "Status not available.\n"
+	}
+
+	diag, err := collector.CollectAll(ctx)
 	if err != nil {
-		notes = nil
+		return fmt.Sprintf("Failed to collect status: %v\n", err)
 	}
 
-	// Render
-	return h.renderer.RenderUser(ownerPubkey, profileEvent, notes, verbose)
+	return diag.FormatAsText()
 }
 
-// renderUserInfo renders information about a followed user
+// renderMutuals lists the owner's mutual follows with their display names
+func (h *Handler) renderMutuals(ctx context.Context) string {
+	mutuals, err := h.server.GetQueryHelper().GetMutuals(ctx)
+	if err != nil {
+		return fmt.Sprintf("Failed to load mutuals: %v\n", err)
+	}
+
+	names := make(map[string]string)
+	for batch := range slices.Chunk(mutuals, 50) {
+		profiles, err := h.server.GetStorage().QueryEvents(ctx, nostr.Filter{Kinds: []int{0}, Authors: batch})
+		if err != nil {
+			continue
+		}
+		for _, profile := range profiles {
+			if meta := nostrclient.ParseProfile(profile); meta != nil && meta.GetDisplayName() != "" {
+				names[profile.PubKey] = meta.GetDisplayName()
+			}
+		}
+	}
+
+	return h.renderer.RenderPeople("Mutuals", mutuals, names)
+}
+
+// renderUserInfo renders information about a followed user, by hex pubkey or npub
 func (h *Handler) renderUserInfo(ctx context.Context, pubkey string, verbose bool) string {
+	if hexPubkey, err := helpers.NormalizePubkey(pubkey); err == nil {
+		pubkey = hexPubkey
+	}
+
 	// Query profile
 	profile, err := h.server.GetStorage().QueryEvents(ctx, nostr.Filter{
 		Kinds:   []int{0},
@@ -141,27 +214,70 @@ func (h *Handler) renderUserInfo(ctx context.Context, pubkey string, verbose boo
 		return fmt.Sprintf("User not found: %s\r\n", pubkey)
 	}
 
-	profileEvent := profile[0]
+	// Render
+	return h.renderer.RenderUser(h.userInfo(ctx, pubkey, profile[0], ""), verbose)
+}
+
+// userInfo gathers a user's recent notes, Project and Plan. An empty project
+// falls back to the user's latest general status.
+func (h *Handler) userInfo(ctx context.Context, pubkey string, profile *nostr.Event, project string) *UserInfo {
+	rendering := h.config.Rendering.Finger
+	info := &UserInfo{
+		Pubkey:  pubkey,
+		Profile: profile,
+		Project: project,
+	}
 
-	// Get recent notes
 	notes, err := h.server.GetStorage().QueryEvents(ctx, nostr.Filter{
 		Kinds:   []int{1},
 		Authors: []string{pubkey},
-		Limit:   5,
+		Limit:   max(rendering.RecentNotesCount, 1),
 	})
-
-	var enrichedNotes []*enrichedNote
 	if err == nil {
-		for _, note := range notes {
-			enrichedNotes = append(enrichedNotes, &enrichedNote{Event: note})
+		info.Notes = notes
+	}
+
+	switch rendering.PlanSource {
+	case "kind_1":
+		if len(info.Notes) > 0 {
+			info.Plan = info.Notes[0].Content
+		}
+	default:
+		if profile != nil {
+			if meta := nostrclient.ParseProfile(profile); meta != nil {
+				info.Plan = meta.About
+			}
 		}
 	}
 
-	// Render
-	return h.renderer.RenderUser(pubkey, profileEvent, enrichedNotes, verbose)
+	if info.Project == "" {
+		info.Project = h.latestStatus(ctx, pubkey)
+	}
+
+	return info
 }
 
-// enrichedNote is a simplified version for finger output
-type enrichedNote struct {
-	Event *nostr.Event
+// latestStatus returns the content of a user's current general status (NIP-38,
+// kind 30315), or "" if they have none or it has expired
+func (h *Handler) latestStatus(ctx context.Context, pubkey string) string {
+	statuses, err := h.server.GetStorage().QueryEvents(ctx, nostr.Filter{
+		Kinds:   []int{30315},
+		Authors: []string{pubkey},
+		Tags:    nostr.TagMap{"d": []string{"general"}},
+		Limit:   1,
+	})
+	if err != nil || len(statuses) == 0 {
+		return ""
+	}
+
+	status := statuses[0]
+	for _, tag := range status.Tags {
+		if len(tag) >= 2 && tag[0] == "expiration" {
+			if expires, err := strconv.ParseInt(tag[1], 10, 64); err == nil && expires < time.Now().Unix() {
+				return ""
+			}
+		}
+	}
+
+	return strings.TrimSpace(status.Content)
 }
@@ -1,137 +1,268 @@
 package finger
 
 import (
+	"context"
 	"fmt"
 	"strings"
 	"time"
+	"unicode/utf8"
 
 	"github.com/nbd-wtf/go-nostr"
+	"github.com/nbd-wtf/go-nostr/nip19"
+	"github.com/sandwich/nophr/internal/idn"
 	"github.com/sandwich/nophr/internal/markdown"
 	nostrclient "github.com/sandwich/nophr/internal/nostr"
 )
 
+// defaultWidth is the output width when none is configured
+const defaultWidth = 80
+
 // Renderer renders Finger protocol responses
 type Renderer struct {
 	parser *markdown.Parser
+	width  int
+	names  *nostrclient.NameResolver // Labels other authors in listings (display.authors)
+	owner  string                    // Hex pubkey whose events are listed without a label
 }
 
-// NewRenderer creates a new renderer
-func NewRenderer() *Renderer {
+// NewRenderer creates a new renderer that wraps output at width characters
+func NewRenderer(width int) *Renderer {
+	if width <= 0 {
+		width = defaultWidth
+	}
 	return &Renderer{
 		parser: markdown.NewParser(),
+		width:  width,
 	}
 }
 
-// RenderUser renders user information in Finger format
-func (r *Renderer) RenderUser(pubkey string, profile *nostr.Event, notes interface{}, verbose bool) string {
+// SetNames makes listings label events not written by owner with their
+// author's name
+func (r *Renderer) SetNames(names *nostrclient.NameResolver, owner string) {
+	r.names = names
+	r.owner = owner
+}
+
+// UserInfo holds what a Finger response shows about one user
+type UserInfo struct {
+	Pubkey  string
+	Profile *nostr.Event   // Kind 0, may be nil
+	Project string         // Configured project or latest status
+	Plan    string         // Profile about or latest note, per plan_source
+	Notes   []*nostr.Event // Recent notes, newest first
+}
+
+// RenderUser renders user information in the classic Finger layout:
+// Login and Name, then contact details, Project and Plan
+func (r *Renderer) RenderUser(info *UserInfo, verbose bool) string {
 	var sb strings.Builder
 
 	// Parse profile metadata using proper parser
 	var meta *nostrclient.ProfileMetadata
-	if profile != nil {
-		meta = nostrclient.ParseProfile(profile)
+	if info.Profile != nil {
+		meta = nostrclient.ParseProfile(info.Profile)
 	}
 	if meta == nil {
 		meta = &nostrclient.ProfileMetadata{} // Empty profile
 	}
 
-	// Header line with display name
 	displayName := meta.GetDisplayName()
 	if displayName == "" {
-		displayName = truncatePubkey(pubkey)
+		displayName = truncatePubkey(info.Pubkey)
 	}
 
-	sb.WriteString(fmt.Sprintf("User: %s\n", displayName))
+	login := fmt.Sprintf("Login: %s", shortNpub(info.Pubkey))
+	sb.WriteString(fmt.Sprintf("%-40s Name: %s\n", login, displayName))
 
-	// Basic info (always shown)
 	if meta.NIP05 != "" {
 		sb.WriteString(fmt.Sprintf("NIP-05: %s\n", meta.NIP05))
 	}
-	if meta.Name != "" && meta.DisplayName != "" && meta.Name != meta.DisplayName {
-		sb.WriteString(fmt.Sprintf("Name: %s\n", meta.Name))
+	if lightningAddr := meta.GetLightningAddress(); lightningAddr != "" {
+		sb.WriteString(fmt.Sprintf("Lightning: %s\n", lightningAddr))
+	}
+	if verbose && meta.Website != "" {
+		sb.WriteString(fmt.Sprintf("Website: %s\n", idn.DisplayURL(meta.Website)))
+	}
+	if len(info.Notes) > 0 {
+		sb.WriteString(fmt.Sprintf("Last post: %s\n", formatTimestamp(info.Notes[0].CreatedAt)))
 	}
-	sb.WriteString(fmt.Sprintf("Pubkey: %s\n", truncatePubkey(pubkey)))
 
-	// Lightning address (basic info)
-	lightningAddr := meta.GetLightningAddress()
-	if lightningAddr != "" {
-		sb.WriteString(fmt.Sprintf("Lightning: %s\n", lightningAddr))
+	if info.Project != "" {
+		sb.WriteString(r.wrap("Project: "+info.Project, ""))
 	}
 
-	// Verbose mode shows more details
-	if verbose {
-		if meta.About != "" {
-			// Render about text compactly
-			about, _ := r.parser.RenderFinger([]byte(meta.About), &markdown.RenderOptions{
-				Width:       80,
-				CompactMode: true,
-			})
-			sb.WriteString(fmt.Sprintf("\nAbout:\n%s\n", about))
-		}
+	if strings.TrimSpace(info.Plan) == "" {
+		sb.WriteString("No Plan.\n")
+	} else {
+		sb.WriteString("Plan:\n")
+		sb.WriteString(r.wrap(info.Plan, ""))
+	}
 
-		// Additional contact info in verbose mode
-		if meta.Website != "" {
-			sb.WriteString(fmt.Sprintf("Website: %s\n", meta.Website))
+	// Verbose mode adds the profile about (when it isn't already the plan)
+	// and recent activity
+	if verbose {
+		if meta.About != "" && strings.TrimSpace(meta.About) != strings.TrimSpace(info.Plan) {
+			sb.WriteString("\nAbout:\n")
+			sb.WriteString(r.wrap(meta.About, ""))
 		}
 
-		// Show recent activity
 		sb.WriteString("\nRecent Activity:\n")
-		sb.WriteString(strings.Repeat("-", 70))
+		sb.WriteString(strings.Repeat("-", min(r.width, 70)))
 		sb.WriteString("\n")
 
-		switch n := notes.(type) {
-		case []*enrichedNote:
-			if len(n) == 0 {
-				sb.WriteString("No recent notes\n")
-			} else {
-				for i, note := range n {
-					if i >= 5 {
-						break
-					}
-					sb.WriteString(r.renderNoteCompact(note.Event))
-					sb.WriteString("\n")
-				}
-			}
-		default:
+		if len(info.Notes) == 0 {
 			sb.WriteString("No recent notes\n")
 		}
-	} else {
-		// Non-verbose: just show summary
-		switch n := notes.(type) {
-		case []*enrichedNote:
-			if len(n) > 0 {
-				sb.WriteString(fmt.Sprintf("\nLast post: %s\n", formatTimestamp(n[0].Event.CreatedAt)))
+		for _, note := range info.Notes {
+			sb.WriteString(r.renderNoteCompact(note))
+			sb.WriteString("\n")
+		}
+	}
+
+	return sb.String()
+}
+
+// wrap word-wraps text to the renderer's width, keeping the text's own line
+// breaks. Continuation lines get indent; words longer than a line are kept whole.
+func (r *Renderer) wrap(text, indent string) string {
+	var sb strings.Builder
+
+	for _, line := range strings.Split(strings.ReplaceAll(text, "\r\n", "\n"), "\n") {
+		words := strings.Fields(line)
+		if len(words) == 0 {
+			sb.WriteString("\n")
+			continue
+		}
+
+		lineLen := 0
+		for i, word := range words {
+			wordLen := utf8.RuneCountInString(word)
+			switch {
+			case i == 0:
+				sb.WriteString(word)
+				lineLen = wordLen
+			case lineLen+1+wordLen > r.width:
+				sb.WriteString("\n")
+				sb.WriteString(indent)
+				sb.WriteString(word)
+				lineLen = len(indent) + wordLen
+			default:
+				sb.WriteString(" ")
+				sb.WriteString(word)
+				lineLen += 1 + wordLen
 			}
 		}
+		sb.WriteString("\n")
 	}
 
 	return sb.String()
 }
 
-// renderNoteCompact renders a note in compact format
-func (r *Renderer) renderNoteCompact(event *nostr.Event) string {
+// RenderListing renders a titled list of recent events, one per line.
+// Articles are shown by their title tag rather than their body.
+func (r *Renderer) RenderListing(title string, events []*nostr.Event) string {
+	var sb strings.Builder
+
+	sb.WriteString(fmt.Sprintf("%s\n", title))
+	sb.WriteString(strings.Repeat("-", min(r.width, 70)))
+	sb.WriteString("\n")
+
+	if len(events) == 0 {
+		sb.WriteString("Nothing here yet\n")
+		return sb.String()
+	}
+
+	for _, event := range events {
+		if title := eventTitle(event); title != "" {
+			sb.WriteString(fmt.Sprintf("[%s] %s%s\n", formatTimestamp(event.CreatedAt), r.byline(event), title))
+			continue
+		}
+		sb.WriteString(r.renderNoteCompact(event))
+		sb.WriteString("\n")
+	}
+
+	return sb.String()
+}
+
+// RenderPeople renders a titled list of pubkeys as npubs with display names
+func (r *Renderer) RenderPeople(title string, pubkeys []string, names map[string]string) string {
 	var sb strings.Builder
 
-	// Timestamp
-	sb.WriteString(fmt.Sprintf("[%s] ", formatTimestamp(event.CreatedAt)))
+	sb.WriteString(fmt.Sprintf("%s\n", title))
+	sb.WriteString(strings.Repeat("-", min(r.width, 70)))
+	sb.WriteString("\n")
+
+	if len(pubkeys) == 0 {
+		sb.WriteString("Nobody here yet\n")
+		return sb.String()
+	}
 
-	// Content (first line, max 60 chars)
-	content := event.Content
-	if len(content) > 60 {
-		content = content[:57] + "..."
+	for _, pubkey := range pubkeys {
+		npub, err := nip19.EncodePublicKey(pubkey)
+		if err != nil {
+			npub = pubkey
+		}
+		if name := names[pubkey]; name != "" {
+			sb.WriteString(fmt.Sprintf("%s  %s\n", npub, name))
+		} else {
+			sb.WriteString(npub + "\n")
+		}
 	}
-	firstLine := strings.Split(content, "\n")[0]
+
+	return sb.String()
+}
+
+// eventTitle returns the value of an event's title tag, if any
+func eventTitle(event *nostr.Event) string {
+	for _, tag := range event.Tags {
+		if len(tag) >= 2 && tag[0] == "title" {
+			return tag[1]
+		}
+	}
+	return ""
+}
+
+// byline returns "Alice (npub1abcd...wxyz): " for an event not written by
+// the owner, or "" when names aren't set
+func (r *Renderer) byline(event *nostr.Event) string {
+	if r.names == nil || event.PubKey == r.owner {
+		return ""
+	}
+	label := strings.Join(strings.Fields(r.names.Label(context.Background(), event.PubKey)), " ")
+	return label + ": "
+}
+
+// renderNoteCompact renders a note's first line after its timestamp,
+// wrapped to the renderer's width
+func (r *Renderer) renderNoteCompact(event *nostr.Event) string {
+	stamp := fmt.Sprintf("[%s] ", formatTimestamp(event.CreatedAt))
 
 	// Render markdown compactly
+	firstLine := strings.Split(event.Content, "\n")[0]
 	rendered, _ := r.parser.RenderFinger([]byte(firstLine), &markdown.RenderOptions{
-		Width:           60,
+		Width:           2 * r.width,
 		CompactMode:     true,
 		StripFormatting: true,
 	})
 
-	sb.WriteString(strings.TrimSpace(rendered))
+	wrapped := r.wrap(stamp+r.byline(event)+strings.TrimSpace(rendered), strings.Repeat(" ", len(stamp)))
+	return strings.TrimSuffix(wrapped, "\n")
+}
 
-	return sb.String()
+// shortNpub returns a shortened npub for the Login field, e.g. npub1abcd...wxyz
+func shortNpub(pubkey string) string {
+	npub := pubkey
+	if !strings.HasPrefix(npub, "npub1") {
+		encoded, err := nip19.EncodePublicKey(pubkey)
+		if err != nil {
+			return truncatePubkey(pubkey)
+		}
+		npub = encoded
+	}
+	if len(npub) <= 21 {
+		return npub
+	}
+	return npub[:12] + "..." + npub[len(npub)-6:]
 }
 
 // truncatePubkey truncates a pubkey for display
@@ -10,17 +10,31 @@ import (
 	"time"
 
 	"github.com/sandwich/nophr/internal/aggregates"
+	"github.com/sandwich/nophr/internal/cache"
 	"github.com/sandwich/nophr/internal/config"
+	"github.com/sandwich/nophr/internal/idle"
+	"github.com/sandwich/nophr/internal/ops"
+	"github.com/sandwich/nophr/internal/sections"
+	"github.com/sandwich/nophr/internal/security"
 	"github.com/sandwich/nophr/internal/storage"
 )
 
 // Server implements a Finger protocol server (RFC 1288)
 type Server struct {
 	config      *config.FingerProtocol
+	fullConfig  *config.Config
 	storage     *storage.Storage
 	handler     *Handler
 	queryHelper *aggregates.QueryHelper
 	ownerPubkey string
+	diagnostics *ops.DiagnosticsCollector
+	rateLimiter *security.ClientLimiter
+	idle        *idle.Monitor
+	sanitizer   *security.InputSanitizer
+	cache       cache.Cache
+	cacheTTL    time.Duration
+
+	sectionManager *sections.Manager
 
 	listener net.Listener
 	wg       sync.WaitGroup
@@ -34,13 +48,18 @@ func New(cfg *config.FingerProtocol, fullCfg *config.Config, st *storage.Storage
 
 	s := &Server{
 		config:      cfg,
+		fullConfig:  fullCfg,
 		storage:     st,
 		ownerPubkey: fullCfg.Identity.Npub,
 		ctx:         ctx,
 		cancel:      cancel,
 		queryHelper: aggregates.NewQueryHelper(st, fullCfg, aggMgr),
+		sanitizer:   security.NewInputSanitizer(),
 	}
 
+	// Sections back aliases that target a configured section
+	s.sectionManager = sections.NewManager(st)
+
 	// Initialize handler
 	s.handler = NewHandler(s, fullCfg)
 
@@ -116,25 +135,80 @@ func (s *Server) handleConnection(conn net.Conn) {
 		return
 	}
 
-	// Clean query (remove CRLF and trim)
-	query := strings.TrimSpace(line)
+	// Strip the CRLF terminator and reject control characters in the rest
+	query, err := s.sanitizer.SanitizeAndValidateFingerQuery(strings.TrimRight(line, "\r\n"))
+	if err != nil {
+		fmt.Printf("Finger rejected query from %s: %v\n", conn.RemoteAddr(), err)
+		s.sendResponse(conn, fmt.Sprintf("Invalid query: %v\n", err))
+		return
+	}
 
 	// Log request
 	fmt.Printf("Finger request: %q from %s\n", query, conn.RemoteAddr())
+	s.idle.Touch()
+
+	// Finger has no status codes, so limited clients get a one-line message
+	if allowed, retryAfter := s.checkRateLimit(conn); !allowed {
+		s.sendResponse(conn, fmt.Sprintf("Rate limit exceeded, try again in %d seconds\n", int(retryAfter.Seconds()+0.5)))
+		return
+	}
 
 	// Handle query
-	response := s.handler.Handle(query)
+	response := s.cachedHandle(query)
 
 	// Write response
 	conn.SetWriteDeadline(time.Now().Add(30 * time.Second))
 	s.sendResponse(conn, response)
 }
 
+// cachedHandle serves a query from the response cache, handling and storing it on a miss
+func (s *Server) cachedHandle(query string) string {
+	// The status report reflects live sync state, so it is never cached
+	if s.cache == nil || isStatusQuery(query) {
+		return s.handler.Handle(query)
+	}
+
+	key := cache.FingerKey(query)
+	if data, found, err := s.cache.Get(s.ctx, key); err == nil && found {
+		return string(data)
+	}
+
+	response := s.handler.Handle(query)
+	if err := s.cache.Set(s.ctx, key, []byte(response), s.cacheTTL); err != nil {
+		fmt.Printf("Finger cache write failed: %v\n", err)
+	}
+	return response
+}
+
+// isStatusQuery reports whether a query asks for the server status
+func isStatusQuery(query string) bool {
+	return strings.ToLower(ParseQuery(query).Username) == "status"
+}
+
 // sendResponse sends a response and ensures proper formatting
 func (s *Server) sendResponse(conn net.Conn, response string) {
-	// Ensure CRLF line endings per RFC 1288
-	response = strings.ReplaceAll(response, "\n", "\r\n")
-	conn.Write([]byte(response))
+	conn.Write(wireFormat(response))
+}
+
+// wireFormat converts a response to CRLF line endings per RFC 1288. Responses
+// may already use CRLF in places, so those are not doubled.
+func wireFormat(response string) []byte {
+	response = strings.ReplaceAll(response, "\r\n", "\n")
+	return []byte(strings.ReplaceAll(response, "\n", "\r\n"))
+}
+
+// checkRateLimit reports whether the client may be served
+func (s *Server) checkRateLimit(conn net.Conn) (bool, time.Duration) {
+	if s.rateLimiter == nil {
+		return true, 0
+	}
+
+	ip := security.ClientIP(conn.RemoteAddr())
+	allowed, retryAfter := s.rateLimiter.Check(ip)
+	if !allowed {
+		fmt.Printf("Finger rate limit exceeded for %s\n", ip)
+	}
+	return allowed, retryAfter
 }
 
 // GetStorage returns the storage instance
@@ -156,3 +230,45 @@ func (s *Server) GetQueryHelper() *aggregates.QueryHelper {
 func (s *Server) GetOwnerPubkey() string {
 	return s.ownerPubkey
 }
+
+// SetDiagnostics sets the diagnostics collector used for the diagnostics page
+func (s *Server) SetDiagnostics(dc *ops.DiagnosticsCollector) {
+	s.diagnostics = dc
+}
+
+// GetDiagnostics returns the diagnostics collector (nil if not configured)
+func (s *Server) GetDiagnostics() *ops.DiagnosticsCollector {
+	return s.diagnostics
+}
+
+// GetSectionManager returns the section manager instance
+func (s *Server) GetSectionManager() *sections.Manager {
+	return s.sectionManager
+}
+
+// SetSectionManager replaces the section manager, so every protocol can share
+// the one loaded from config
+func (s *Server) SetSectionManager(m *sections.Manager) {
+	s.sectionManager = m
+}
+
+// GetSanitizer returns the request input sanitizer
+func (s *Server) GetSanitizer() *security.InputSanitizer {
+	return s.sanitizer
+}
+
+// SetRateLimiter sets the per-IP rate limiter (nil disables rate limiting)
+func (s *Server) SetRateLimiter(rl *security.ClientLimiter) {
+	s.rateLimiter = rl
+}
+
+// SetIdleMonitor sets the monitor told about each request (nil disables idle shedding)
+func (s *Server) SetIdleMonitor(m *idle.Monitor) {
+	s.idle = m
+}
+
+// SetCache sets the response cache and how long responses are kept (nil disables caching)
+func (s *Server) SetCache(c cache.Cache, ttl time.Duration) {
+	s.cache = c
+	s.cacheTTL = ttl
+}
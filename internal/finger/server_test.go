@@ -5,12 +5,16 @@ import (
 	"context"
 	"fmt"
 	"net"
+	"path/filepath"
 	"strings"
 	"testing"
 	"time"
 
+	"github.com/nbd-wtf/go-nostr"
+	"github.com/nbd-wtf/go-nostr/nip19"
 	"github.com/sandwich/nophr/internal/aggregates"
 	"github.com/sandwich/nophr/internal/config"
+	"github.com/sandwich/nophr/internal/sections"
 	"github.com/sandwich/nophr/internal/storage"
 )
 
@@ -46,6 +50,7 @@ func TestFingerProtocol(t *testing.T) {
 
 	// Create server
 	server := New(fingerCfg, cfg, st, aggMgr)
+	server.GetSectionManager().RegisterSection(&sections.Section{Name: "diy", Path: "/diy", Title: "DIY projects"})
 
 	// Start server
 	if err := server.Start(); err != nil {
@@ -59,18 +64,18 @@ func TestFingerProtocol(t *testing.T) {
 	// Test 1: Query owner by "owner" username
 	t.Run("QueryOwner", func(t *testing.T) {
 		response := sendFingerRequest(t, fingerCfg.Port, "owner")
-		if !strings.Contains(response, "User:") {
-			t.Errorf("Owner query should contain 'User:', got: %s", response)
+		if !strings.Contains(response, "Login:") {
+			t.Errorf("Owner query should contain 'Login:', got: %s", response)
 		}
-		if !strings.Contains(response, "Pubkey:") {
-			t.Errorf("Owner query should contain 'Pubkey:'")
+		if !strings.Contains(response, "Name:") {
+			t.Errorf("Owner query should contain 'Name:'")
 		}
 	})
 
 	// Test 2: Empty query (list users)
 	t.Run("EmptyQuery", func(t *testing.T) {
 		response := sendFingerRequest(t, fingerCfg.Port, "")
-		if !strings.Contains(response, "User:") {
+		if !strings.Contains(response, "Login:") {
 			t.Errorf("Empty query should return owner info, got: %s", response)
 		}
 	})
@@ -78,8 +83,8 @@ func TestFingerProtocol(t *testing.T) {
 	// Test 3: Verbose query with /W flag
 	t.Run("VerboseQuery", func(t *testing.T) {
 		response := sendFingerRequest(t, fingerCfg.Port, "/W owner")
-		if !strings.Contains(response, "User:") {
-			t.Errorf("Verbose query should contain 'User:'")
+		if !strings.Contains(response, "Login:") {
+			t.Errorf("Verbose query should contain 'Login:'")
 		}
 		if !strings.Contains(response, "Recent Activity") {
 			t.Errorf("Verbose query should show recent activity")
@@ -102,7 +107,15 @@ func TestFingerProtocol(t *testing.T) {
 		}
 	})
 
-	// Test 6: CRLF line endings
+	// Test 6: Custom sections answer by name
+	t.Run("QuerySection", func(t *testing.T) {
+		response := sendFingerRequest(t, fingerCfg.Port, "diy")
+		if !strings.Contains(response, "DIY projects") {
+			t.Errorf("Section query should list the section, got: %s", response)
+		}
+	})
+
+	// Test 7: CRLF line endings
 	t.Run("CRLFLineEndings", func(t *testing.T) {
 		response := sendFingerRequest(t, fingerCfg.Port, "owner")
 		if !strings.Contains(response, "\r\n") {
@@ -141,25 +154,34 @@ func TestQueryParsing(t *testing.T) {
 			}
 		})
 	}
+
+	for query, want := range map[string]bool{"status": true, "/W STATUS": true, "owner": false, "": false} {
+		if got := isStatusQuery(query); got != want {
+			t.Errorf("isStatusQuery(%q) = %v, want %v", query, got, want)
+		}
+	}
 }
 
 func TestRenderer(t *testing.T) {
-	renderer := NewRenderer()
+	renderer := NewRenderer(0)
 
 	// Test basic rendering
 	t.Run("BasicRendering", func(t *testing.T) {
-		result := renderer.RenderUser("pubkey123", nil, []*enrichedNote{}, false)
-		if !strings.Contains(result, "User:") {
-			t.Errorf("Render should contain 'User:'")
+		result := renderer.RenderUser(&UserInfo{Pubkey: "pubkey123"}, false)
+		if !strings.Contains(result, "Login:") {
+			t.Errorf("Render should contain 'Login:'")
+		}
+		if !strings.Contains(result, "Name:") {
+			t.Errorf("Render should contain 'Name:'")
 		}
-		if !strings.Contains(result, "Pubkey:") {
-			t.Errorf("Render should contain 'Pubkey:'")
+		if !strings.Contains(result, "No Plan.") {
+			t.Errorf("Render without a plan should say 'No Plan.'")
 		}
 	})
 
 	// Test verbose rendering
 	t.Run("VerboseRendering", func(t *testing.T) {
-		result := renderer.RenderUser("pubkey123", nil, []*enrichedNote{}, true)
+		result := renderer.RenderUser(&UserInfo{Pubkey: "pubkey123"}, true)
 		if !strings.Contains(result, "Recent Activity") {
 			t.Errorf("Verbose render should show recent activity")
 		}
@@ -182,6 +204,136 @@ func TestRenderer(t *testing.T) {
 	})
 }
 
+func TestRenderUserClassicLayout(t *testing.T) {
+	pubkey := strings.Repeat("ab", 32)
+	profile := &nostr.Event{
+		Kind:    0,
+		PubKey:  pubkey,
+		Content: `{"name":"alice","display_name":"Alice","about":"ignored"}`,
+	}
+	plan := "I am building a gateway between Nostr and the small internet protocols of old.\n\nSay hi!"
+
+	renderer := NewRenderer(30)
+	result := renderer.RenderUser(&UserInfo{
+		Pubkey:  pubkey,
+		Profile: profile,
+		Project: "nophr",
+		Plan:    plan,
+	}, false)
+
+	lines := strings.Split(result, "\n")
+	if !strings.HasPrefix(lines[0], "Login: npub1") || !strings.HasSuffix(lines[0], "Name: Alice") {
+		t.Errorf("First line should show Login and Name, got %q", lines[0])
+	}
+	if !strings.Contains(result, "Project: nophr\n") {
+		t.Errorf("Render should contain the project, got: %s", result)
+	}
+	if !strings.Contains(result, "Plan:\nI am building a gateway\n") || !strings.Contains(result, "\n\nSay hi!\n") {
+		t.Errorf("Plan should be wrapped with its paragraphs kept, got: %s", result)
+	}
+	for _, line := range lines[1:] {
+		if len(line) > 30 {
+			t.Errorf("Line exceeds width 30: %q", line)
+		}
+	}
+
+	// The about is shown in verbose mode when it isn't the plan
+	if verbose := renderer.RenderUser(&UserInfo{Pubkey: pubkey, Profile: profile, Plan: plan}, true); !strings.Contains(verbose, "About:\nignored\n") {
+		t.Errorf("Verbose render should contain the about, got: %s", verbose)
+	}
+}
+
+func TestUserInfoPlanAndProject(t *testing.T) {
+	ctx := context.Background()
+	st, err := storage.New(ctx, &config.Storage{Driver: "sqlite", SQLitePath: filepath.Join(t.TempDir(), "test.db")})
+	if err != nil {
+		t.Fatalf("Failed to create storage: %v", err)
+	}
+	defer st.Close()
+
+	sk := nostr.GeneratePrivateKey()
+	pk, _ := nostr.GetPublicKey(sk)
+	npub, _ := nip19.EncodePublicKey(pk)
+
+	events := []*nostr.Event{
+		{Kind: 0, CreatedAt: 100, Content: `{"name":"alice","about":"About me"}`},
+		{Kind: 1, CreatedAt: 200, Content: "Latest note"},
+		{Kind: 30315, CreatedAt: 300, Tags: nostr.Tags{{"d", "general"}}, Content: "Hacking on finger"},
+	}
+	for _, event := range events {
+		event.PubKey = pk
+		event.Sign(sk)
+		if err := st.StoreEvent(ctx, event); err != nil {
+			t.Fatalf("Failed to store event: %v", err)
+		}
+	}
+
+	cfg := config.Default()
+	cfg.Identity.Npub = npub
+	server := New(&cfg.Protocols.Finger, cfg, st, aggregates.NewManager(st, cfg))
+
+	response := server.handler.Handle("owner")
+	for _, want := range []string{"Name: alice", "Project: Hacking on finger", "Plan:\nAbout me", "Last post:"} {
+		if !strings.Contains(response, want) {
+			t.Errorf("Owner response should contain %q, got: %s", want, response)
+		}
+	}
+
+	cfg.Rendering.Finger.PlanSource = "kind_1"
+	cfg.Rendering.Finger.Project = "Configured project"
+	response = server.handler.Handle("owner")
+	for _, want := range []string{"Project: Configured project", "Plan:\nLatest note"} {
+		if !strings.Contains(response, want) {
+			t.Errorf("Owner response should contain %q, got: %s", want, response)
+		}
+	}
+}
+
+func TestMutualsListing(t *testing.T) {
+	ctx := context.Background()
+	st, err := storage.New(ctx, &config.Storage{Driver: "sqlite", SQLitePath: filepath.Join(t.TempDir(), "test.db")})
+	if err != nil {
+		t.Fatalf("Failed to create storage: %v", err)
+	}
+	defer st.Close()
+
+	ownerSK, friendSK, fanSK := nostr.GeneratePrivateKey(), nostr.GeneratePrivateKey(), nostr.GeneratePrivateKey()
+	owner, _ := nostr.GetPublicKey(ownerSK)
+	friend, _ := nostr.GetPublicKey(friendSK)
+	fan, _ := nostr.GetPublicKey(fanSK)
+
+	for _, spec := range []struct {
+		sk    string
+		event *nostr.Event
+	}{
+		{ownerSK, &nostr.Event{Kind: 3, Tags: nostr.Tags{{"p", friend}}}},
+		{friendSK, &nostr.Event{Kind: 3, Tags: nostr.Tags{{"p", owner}}}},
+		{friendSK, &nostr.Event{Kind: 0, Content: `{"name":"friend"}`}},
+		{fanSK, &nostr.Event{Kind: 3, Tags: nostr.Tags{{"p", owner}}}},
+	} {
+		spec.event.CreatedAt = nostr.Now()
+		spec.event.Sign(spec.sk)
+		if err := st.StoreEvent(ctx, spec.event); err != nil {
+			t.Fatalf("Failed to store event: %v", err)
+		}
+	}
+
+	cfg := config.Default()
+	cfg.Identity.Npub, _ = nip19.EncodePublicKey(owner)
+	server := New(&cfg.Protocols.Finger, cfg, st, aggregates.NewManager(st, cfg))
+
+	friendNpub, _ := nip19.EncodePublicKey(friend)
+	fanNpub, _ := nip19.EncodePublicKey(fan)
+	response := server.handler.Handle("mutuals")
+	if !strings.Contains(response, friendNpub+"  friend") || strings.Contains(response, fanNpub) {
+		t.Errorf("Expected only the mutual follow, by name, got: %s", response)
+	}
+
+	if response := server.handler.Handle(friendNpub); !strings.Contains(response, "Name: friend") {
+		t.Errorf("Expected listed npubs to be fingerable, got: %s", response)
+	}
+}
+
 // Helper function to send a Finger request
 func sendFingerRequest(t *testing.T, port int, query string) string {
 	// Connect to server
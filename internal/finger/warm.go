@@ -0,0 +1,13 @@
+package finger
+
+import (
+	"context"
+	"time"
+
+	"github.com/sandwich/nophr/internal/cache"
+)
+
+// WarmCache renders the owner query and stores it through the warmer
+func (s *Server) WarmCache(ctx context.Context, w *cache.Warmer, ttl time.Duration) error {
+	return w.WarmFinger(ctx, "owner", []byte(s.handler.Handle("owner")), ttl)
+}
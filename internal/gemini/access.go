@@ -0,0 +1,95 @@
+package gemini
+
+import (
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/hex"
+	"net"
+	"strings"
+	"time"
+
+	"github.com/sandwich/nophr/internal/config"
+)
+
+// CertFingerprint returns the SHA-256 fingerprint of a certificate as lowercase hex
+func CertFingerprint(cert *x509.Certificate) string {
+	sum := sha256.Sum256(cert.Raw)
+	return hex.EncodeToString(sum[:])
+}
+
+// peerCertificate returns the client certificate presented on a connection, or nil
+func peerCertificate(conn net.Conn) *x509.Certificate {
+	if tlsConn, isTLS := conn.(*tls.Conn); isTLS {
+		if certs := tlsConn.ConnectionState().PeerCertificates; len(certs) > 0 {
+			return certs[0]
+		}
+	}
+	return nil
+}
+
+// requiredLevel returns the access level a path needs, or "" if it is public.
+// The longest matching prefix wins so a public subtree can sit under a private one.
+func requiredLevel(access *config.GeminiAccess, path string) string {
+	level := ""
+	longest := -1
+
+	for _, rule := range access.Paths {
+		prefix := strings.TrimSuffix(rule.Prefix, "/")
+		if path != prefix && !strings.HasPrefix(path, prefix+"/") {
+			continue
+		}
+		if len(prefix) > longest {
+			longest = len(prefix)
+			level = rule.Level
+		}
+	}
+
+	return level
+}
+
+// clientLevel returns the access level granted to a client certificate.
+// Unknown certificates get AccessLevelAny.
+func clientLevel(access *config.GeminiAccess, cert *x509.Certificate) string {
+	fingerprint := CertFingerprint(cert)
+	for _, identity := range access.Identities {
+		if config.NormalizeFingerprint(identity.Fingerprint) == fingerprint {
+			return identity.Level
+		}
+	}
+	return config.AccessLevelAny
+}
+
+// authorize checks the client certificate against the path's access rule.
+// It returns ok=false with the status and meta to send when access is denied.
+func (s *Server) authorize(conn net.Conn, path string) (status Status, meta string, ok bool) {
+	access := &s.config.Access
+
+	required := requiredLevel(access, path)
+	if required == "" {
+		return StatusSuccess, "", true
+	}
+
+	return s.authorizeLevel(conn, required)
+}
+
+// authorizeLevel checks the client certificate grants at least the required level
+func (s *Server) authorizeLevel(conn net.Conn, required string) (status Status, meta string, ok bool) {
+	access := &s.config.Access
+
+	cert := peerCertificate(conn)
+	if cert == nil {
+		return StatusClientCertRequired, "Client certificate required", false
+	}
+
+	now := time.Now()
+	if now.Before(cert.NotBefore) || now.After(cert.NotAfter) {
+		return StatusCertNotValid, "Certificate expired or not yet valid", false
+	}
+
+	if config.AccessRank(clientLevel(access, cert)) < config.AccessRank(required) {
+		return StatusCertNotAuthorized, "Certificate not authorised for this path", false
+	}
+
+	return StatusSuccess, "", true
+}
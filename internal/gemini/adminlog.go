@@ -0,0 +1,81 @@
+package gemini
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+
+	"github.com/sandwich/nophr/internal/config"
+)
+
+// adminLogPerPage is how many entries one /admin/log page lists
+const adminLogPerPage = 100
+
+// adminLogPath lists the admin log; /admin/log/before/<id> pages back
+const adminLogPath = "/admin/log"
+
+// isAdminLogPath reports whether path is the admin log or one of its pages
+func isAdminLogPath(path string) bool {
+	return path == adminLogPath || strings.HasPrefix(path, adminLogPath+"/")
+}
+
+// handleAdminLog serves the owner-only admin log, newest entries first. Like
+// the trash it bypasses the response cache.
+func (s *Server) handleAdminLog(conn net.Conn, path string) []byte {
+	if status, meta, ok := s.authorizeLevel(conn, config.AccessLevelOwner); !ok {
+		return FormatResponse(status, meta, "")
+	}
+
+	var before int64
+	if rest := strings.Trim(strings.TrimPrefix(path, adminLogPath), "/"); rest != "" {
+		id, found := strings.CutPrefix(rest, "before/")
+		n, err := strconv.ParseInt(id, 10, 64)
+		if !found || err != nil || n < 1 {
+			return FormatErrorResponse(StatusNotFound, "Not found")
+		}
+		before = n
+	}
+	return s.router.handleAdminLogPage(context.Background(), before)
+}
+
+// handleAdminLogPage lists a page of admin log entries older than before,
+// or the newest ones for 0
+func (r *Router) handleAdminLogPage(ctx context.Context, before int64) []byte {
+	entries, err := r.server.GetStorage().GetAdminLog(ctx, adminLogPerPage, before)
+	if err != nil {
+		return FormatErrorResponse(StatusTemporaryFailure, fmt.Sprintf("Error loading admin log: %v", err))
+	}
+
+	var sb strings.Builder
+	sb.WriteString("# Admin Log\n\n")
+	sb.WriteString("Startups, subsystems starting and stopping, unclean shutdowns and migrations, newest first. Times are UTC.\n\n")
+	if len(entries) == 0 {
+		sb.WriteString("No entries.\n\n")
+	}
+
+	if len(entries) > 0 {
+		// Each line starts with its time, so none can end the block
+		sb.WriteString("```Admin log entries\n")
+		for _, entry := range entries {
+			line := fmt.Sprintf("%s %-9s", entry.Time.UTC().Format("2006-01-02 15:04:05"), entry.Type)
+			if entry.Subsystem != "" {
+				line += " [" + entry.Subsystem + "]"
+			}
+			sb.WriteString(line + " " + lineTextReplacer.Replace(entry.Message) + "\n")
+		}
+		sb.WriteString("```\n\n")
+	}
+
+	if len(entries) == adminLogPerPage {
+		oldest := entries[len(entries)-1].ID
+		sb.WriteString(fmt.Sprintf("=> %s Older entries\n", r.geminiURL(fmt.Sprintf("%s/before/%d", adminLogPath, oldest))))
+	}
+	if before > 0 {
+		sb.WriteString(fmt.Sprintf("=> %s Newest entries\n", r.geminiURL(adminLogPath)))
+	}
+	sb.WriteString(fmt.Sprintf("=> %s Back to Home\n", r.geminiURL("/")))
+
+	return FormatSuccessResponse(sb.String())
+}
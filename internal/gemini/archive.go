@@ -0,0 +1,89 @@
+package gemini
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/sandwich/nophr/internal/aggregates"
+	"github.com/sandwich/nophr/internal/sections"
+)
+
+// archiveKinds are the owner's posts listed in the archive: notes and articles
+var archiveKinds = []int{1, 30023}
+
+// handleArchive serves the owner's archive: /archive lists years, /archive/<year>
+// lists months and /archive/<year>/<month> lists that month's notes and articles
+func (r *Router) handleArchive(ctx context.Context, parts []string) []byte {
+	before, remaining, err := aggregates.CursorFromParts(parts)
+	if err != nil {
+		return FormatErrorResponse(StatusBadRequest, err.Error())
+	}
+
+	year, month, err := sections.ParseArchivePath(remaining)
+	if err != nil {
+		return FormatErrorResponse(StatusBadRequest, err.Error())
+	}
+
+	if month == 0 {
+		return r.handleArchiveIndex(ctx, year)
+	}
+	return r.handleArchiveMonth(ctx, year, month, before)
+}
+
+// handleArchiveIndex lists the years with posts, or the months of one year
+func (r *Router) handleArchiveIndex(ctx context.Context, year int) []byte {
+	ownerHex, err := r.server.GetQueryHelper().OwnerHex()
+	if err != nil {
+		return FormatErrorResponse(StatusTemporaryFailure, fmt.Sprintf("Error loading archive: %v", err))
+	}
+
+	archives, err := r.archives.ListAuthorArchives(ctx, ownerHex, archiveKinds, year)
+	if err != nil {
+		return FormatErrorResponse(StatusTemporaryFailure, fmt.Sprintf("Error loading archive: %v", err))
+	}
+
+	var sb strings.Builder
+	if year == 0 {
+		sb.WriteString("# Archive\n\n")
+	} else {
+		sb.WriteString(fmt.Sprintf("# Archive: %d\n\n", year))
+	}
+
+	if len(archives) == 0 {
+		sb.WriteString("No posts yet.\n\n")
+	}
+	for _, archive := range archives {
+		sb.WriteString(fmt.Sprintf("=> %s %s (%s)\n", r.geminiURL(archive.Selector()), archive.FormatTitle(), postCount(archive.EventCount)))
+	}
+	sb.WriteString("\n")
+
+	if year != 0 {
+		sb.WriteString(fmt.Sprintf("=> %s All years\n", r.geminiURL("/archive")))
+	}
+	sb.WriteString(fmt.Sprintf("=> %s Back to Home\n", r.geminiURL("/")))
+
+	return FormatSuccessResponse(r.renderer.applyHeadersFooters(sb.String(), "archive"))
+}
+
+// handleArchiveMonth lists one page of the owner's notes and articles from a month
+func (r *Router) handleArchiveMonth(ctx context.Context, year int, month time.Month, before *aggregates.Cursor) []byte {
+	rng := sections.MonthRange(year, month)
+	limits := r.server.fullConfig.Display.Limits
+	page, err := r.server.GetQueryHelper().GetArchivePage(ctx, rng.Start, rng.End, before,
+		min(r.pageSize(limits.PageSizes.Archive), limits.MaxArchivePageSize))
+	if err != nil {
+		return FormatErrorResponse(StatusTemporaryFailure, fmt.Sprintf("Error loading archive: %v", err))
+	}
+
+	return r.renderListPage(page, fmt.Sprintf("Archive: %s %d", month, year), fmt.Sprintf("/archive/%04d/%02d", year, month))
+}
+
+// postCount formats a number of posts, e.g. "1 post" or "12 posts"
+func postCount(n int64) string {
+	if n == 1 {
+		return "1 post"
+	}
+	return fmt.Sprintf("%d posts", n)
+}
@@ -0,0 +1,55 @@
+package gemini
+
+import (
+	"net/url"
+	"testing"
+
+	"github.com/sandwich/nophr/internal/aggregates"
+	"github.com/sandwich/nophr/internal/conformance"
+)
+
+func TestConformance(t *testing.T) {
+	fixture := conformance.NewFixture()
+	cfg := conformance.Config(t, fixture)
+	st := conformance.Setup(t, fixture, cfg)
+
+	server, err := New(&cfg.Protocols.Gemini, cfg, st, "localhost", aggregates.NewManager(st, cfg))
+	if err != nil {
+		t.Fatalf("Failed to create server: %v", err)
+	}
+
+	tests := []struct {
+		golden string
+		path   string
+	}{
+		{"root.gmi", "/"},
+		{"notes.gmi", "/notes"},
+		{"articles.gmi", "/articles"},
+		{"replies.gmi", "/replies"},
+		{"archive.gmi", "/archive"},
+		{"archive-month.gmi", "/archive/2024/06"},
+		{"followers.gmi", "/followers"},
+		{"tags.gmi", "/tags"},
+		{"tag.gmi", "/tags/lang:en"},
+		{"note.gmi", "/note/" + fixture.Note.ID},
+		{"special-note.gmi", "/note/" + fixture.SpecialNote.ID},
+		{"article.gmi", "/note/" + fixture.Article.ID},
+		{"thread.gmi", "/thread/" + fixture.Note.ID},
+		{"profile.gmi", "/profile/" + fixture.Owner},
+		{"search-prompt.gmi", "/search"},
+		{"goto-prompt.gmi", "/goto"},
+		{"feed.xml", "/feed.xml"},
+		{"notes-feed.gmi", "/notes/feed.gmi"},
+		{"not-found.gmi", "/no-such-path"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			u := &url.URL{Scheme: "gemini", Host: "localhost", Path: tt.path}
+			response := server.router.Route(u)
+
+			conformance.Check(t, tt.path, conformance.CheckGeminiResponse(response))
+			conformance.Golden(t, tt.golden, response)
+		})
+	}
+}
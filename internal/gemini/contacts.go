@@ -0,0 +1,129 @@
+package gemini
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/sandwich/nophr/internal/aggregates"
+)
+
+// lineTextReplacer keeps profile-supplied names on a single gemtext line
+var lineTextReplacer = strings.NewReplacer("\r", " ", "\n", " ")
+
+// parsePageFromParts extracts a page number from URL parts like ["page", "2"]
+func parsePageFromParts(parts []string) (int, error) {
+	if len(parts) >= 2 && parts[0] == "page" {
+		return strconv.Atoi(parts[1])
+	}
+	return 1, nil
+}
+
+// handleFollowing lists the accounts the owner follows
+func (r *Router) handleFollowing(ctx context.Context, parts []string) []byte {
+	pageNum, err := parsePageFromParts(parts)
+	if err == nil {
+		err = r.server.GetSanitizer().ValidatePageNumber(pageNum)
+	}
+	if err != nil {
+		return FormatErrorResponse(StatusBadRequest, fmt.Sprintf("Invalid page: %v", err))
+	}
+
+	page, err := r.server.GetQueryHelper().GetFollowing(ctx, pageNum, r.pageSize(r.server.fullConfig.Display.Limits.PageSizes.Contacts))
+	if err != nil {
+		return FormatErrorResponse(StatusTemporaryFailure, fmt.Sprintf("Error loading following: %v", err))
+	}
+
+	return r.renderContactPage(page, "Following", "/following", "Not following anyone yet.")
+}
+
+// handleFollowers lists the accounts whose stored contact lists include the owner
+func (r *Router) handleFollowers(ctx context.Context, parts []string) []byte {
+	pageNum, err := parsePageFromParts(parts)
+	if err == nil {
+		err = r.server.GetSanitizer().ValidatePageNumber(pageNum)
+	}
+	if err != nil {
+		return FormatErrorResponse(StatusBadRequest, fmt.Sprintf("Invalid page: %v", err))
+	}
+
+	page, err := r.server.GetQueryHelper().GetFollowers(ctx, pageNum, r.pageSize(r.server.fullConfig.Display.Limits.PageSizes.Contacts))
+	if err != nil {
+		return FormatErrorResponse(StatusTemporaryFailure, fmt.Sprintf("Error loading followers: %v", err))
+	}
+
+	return r.renderContactPage(page, "Followers", "/followers", "No followers known yet. Followers are found from synced contact lists.")
+}
+
+// renderContactPage renders one page of contacts, each linking to their profile and notes
+func (r *Router) renderContactPage(page *aggregates.ContactPage, title, basePath, empty string) []byte {
+	var sb strings.Builder
+
+	sb.WriteString(fmt.Sprintf("# %s (%d)\n\n", title, page.TotalItems))
+
+	if len(page.Contacts) == 0 {
+		sb.WriteString(empty + "\n\n")
+	}
+
+	for _, contact := range page.Contacts {
+		name := lineTextReplacer.Replace(contact.Name)
+		if name == "" {
+			name = r.renderer.authorLabel(context.Background(), contact.Pubkey)
+		}
+		sb.WriteString(fmt.Sprintf("=> %s %s\n", r.geminiURL("/profile/"+contact.Pubkey), name))
+		sb.WriteString(fmt.Sprintf("=> %s Notes by %s\n", r.geminiURL("/author/"+contact.Pubkey), name))
+	}
+	sb.WriteString("\n")
+
+	if page.HasNext {
+		sb.WriteString(fmt.Sprintf("=> %s Next page\n", r.geminiURL(fmt.Sprintf("%s/page/%d", basePath, page.PageNumber+1))))
+	}
+	if page.PageNumber > 1 {
+		sb.WriteString(fmt.Sprintf("=> %s Previous page\n", r.geminiURL(fmt.Sprintf("%s/page/%d", basePath, page.PageNumber-1))))
+	}
+	sb.WriteString(fmt.Sprintf("=> %s Back to Home\n", r.geminiURL("/")))
+
+	return FormatSuccessResponse(sb.String())
+}
+
+// handleAuthor lists one author's root notes, newest first
+func (r *Router) handleAuthor(ctx context.Context, parts []string) []byte {
+	if len(parts) == 0 || parts[0] == "" {
+		return FormatErrorResponse(StatusBadRequest, "Missing pubkey")
+	}
+
+	pubkey := parts[0]
+	if err := r.server.GetSanitizer().ValidatePubkey(pubkey); err != nil {
+		return FormatErrorResponse(StatusBadRequest, fmt.Sprintf("Invalid pubkey: %v", err))
+	}
+
+	before, _, err := aggregates.CursorFromParts(parts[1:])
+	if err != nil {
+		return FormatErrorResponse(StatusBadRequest, err.Error())
+	}
+
+	page, err := r.server.GetQueryHelper().GetAuthorNotesPage(ctx, pubkey, before, r.pageSize(r.server.fullConfig.Display.Limits.PageSizes.Profile))
+	if err != nil {
+		return FormatErrorResponse(StatusTemporaryFailure, fmt.Sprintf("Error loading notes: %v", err))
+	}
+
+	name := lineTextReplacer.Replace(r.renderer.resolver.AuthorName(ctx, pubkey))
+	return r.renderListPage(page, "Notes by "+name, "/author/"+pubkey)
+}
+
+// handleProfileNotes lists an author's recent root notes and articles with their aggregates
+func (r *Router) handleProfileNotes(ctx context.Context, pubkey string) []byte {
+	if err := r.server.GetSanitizer().ValidatePubkey(pubkey); err != nil {
+		return FormatErrorResponse(StatusBadRequest, fmt.Sprintf("Invalid pubkey: %v", err))
+	}
+
+	notes, err := r.server.GetQueryHelper().GetNotesByAuthor(ctx, pubkey, r.pageSize(r.server.fullConfig.Display.Limits.PageSizes.Profile))
+	if err != nil {
+		return FormatErrorResponse(StatusTemporaryFailure, fmt.Sprintf("Error loading notes: %v", err))
+	}
+
+	name := lineTextReplacer.Replace(r.renderer.resolver.AuthorName(ctx, pubkey))
+	gemtext := r.renderer.RenderNoteList(notes, "Posts by "+name, r.geminiURL("/"))
+	return FormatSuccessResponse(gemtext)
+}
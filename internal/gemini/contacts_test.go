@@ -0,0 +1,98 @@
+package gemini
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/nbd-wtf/go-nostr"
+	"github.com/nbd-wtf/go-nostr/nip19"
+	"github.com/sandwich/nophr/internal/aggregates"
+	"github.com/sandwich/nophr/internal/config"
+	"github.com/sandwich/nophr/internal/storage"
+)
+
+func TestFollowersPages(t *testing.T) {
+	cfg := config.Default()
+	cfg.Identity.Npub = "npub1nq3zgtqruwhnz0xx40gh4a4fkamlr2sc7ke5wqs2s3nyv2fpy9esg4hdwq"
+	cfg.Storage.SQLitePath = ":memory:"
+	dir := t.TempDir()
+	cfg.Protocols.Gemini.TLS.CertPath = filepath.Join(dir, "cert.pem")
+	cfg.Protocols.Gemini.TLS.KeyPath = filepath.Join(dir, "key.pem")
+	cfg.Display.Limits.PageSizes.Contacts = 2
+
+	_, decoded, err := nip19.Decode(cfg.Identity.Npub)
+	if err != nil {
+		t.Fatalf("Failed to decode npub: %v", err)
+	}
+	ownerHex := decoded.(string)
+
+	ctx := context.Background()
+	st, err := storage.New(ctx, &cfg.Storage)
+	if err != nil {
+		t.Fatalf("Failed to create storage: %v", err)
+	}
+	defer st.Close()
+
+	// Three followers, and one author whose newer list dropped the owner
+	var events []*nostr.Event
+	for i, name := range []string{"alice", "bob", "carol"} {
+		sk := nostr.GeneratePrivateKey()
+		events = append(events,
+			signed(t, sk, &nostr.Event{Kind: 3, CreatedAt: 100, Tags: nostr.Tags{{"p", ownerHex}}}),
+			signed(t, sk, &nostr.Event{Kind: 0, CreatedAt: nostr.Timestamp(100 + i), Content: fmt.Sprintf(`{"name":%q}`, name)}),
+		)
+	}
+	unfollower := nostr.GeneratePrivateKey()
+	events = append(events,
+		signed(t, unfollower, &nostr.Event{Kind: 3, CreatedAt: 100, Tags: nostr.Tags{{"p", ownerHex}}}),
+		signed(t, unfollower, &nostr.Event{Kind: 3, CreatedAt: 200}),
+	)
+	for _, event := range events {
+		if err := st.StoreEvent(ctx, event); err != nil {
+			t.Fatalf("Failed to store event: %v", err)
+		}
+	}
+
+	server, err := New(&cfg.Protocols.Gemini, cfg, st, "localhost", aggregates.NewManager(st, cfg))
+	if err != nil {
+		t.Fatalf("Failed to create server: %v", err)
+	}
+	route := func(path string) string {
+		return string(server.router.Route(&url.URL{Scheme: "gemini", Host: "localhost", Path: path}))
+	}
+
+	first := route("/followers")
+	for _, want := range []string{"# Followers (3)", "alice", "bob", "/followers/page/2 Next page"} {
+		if !strings.Contains(first, want) {
+			t.Errorf("First followers page missing %q:\n%s", want, first)
+		}
+	}
+	if strings.Contains(first, "carol") {
+		t.Errorf("Expected carol on the second page:\n%s", first)
+	}
+
+	second := route("/followers/page/2")
+	if !strings.Contains(second, "carol") || strings.Contains(second, "Next page") || !strings.Contains(second, "Previous page") {
+		t.Errorf("Second followers page should list carol only:\n%s", second)
+	}
+
+	if bad := route("/followers/page/0"); !strings.HasPrefix(bad, "59") {
+		t.Errorf("Page 0 should be rejected, got: %s", bad)
+	}
+}
+
+// signed signs event with sk
+func signed(t *testing.T, sk string, event *nostr.Event) *nostr.Event {
+	t.Helper()
+	if event.Tags == nil {
+		event.Tags = nostr.Tags{}
+	}
+	if err := event.Sign(sk); err != nil {
+		t.Fatalf("Failed to sign event: %v", err)
+	}
+	return event
+}
@@ -0,0 +1,82 @@
+package gemini
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/nbd-wtf/go-nostr"
+	"github.com/sandwich/nophr/internal/entities"
+)
+
+// routeEntity serves a NIP-19 deep link (note1..., nevent1..., npub1..., nprofile1..., naddr1...)
+// by routing to the handler for what it points at. Content that is not stored locally
+// gets a not-found response naming the relay hints carried by the entity.
+func (r *Router) routeEntity(ctx context.Context, component string) []byte {
+	entity, err := r.renderer.resolver.DecodeEntity(component)
+	if err != nil {
+		return FormatErrorResponse(StatusBadRequest, fmt.Sprintf("Invalid NIP-19 entity: %v", err))
+	}
+
+	if !r.renderer.resolver.Exists(ctx, entity) {
+		message := fmt.Sprintf("This %s is not stored here", entity.Type)
+		if len(entity.Relays) > 0 {
+			message += "; it may be found on " + strings.Join(entity.Relays, ", ")
+		}
+		return FormatErrorResponse(StatusNotFound, message)
+	}
+
+	return r.Route(&url.URL{Path: entity.Link})
+}
+
+// handleGoto prompts for a nostr: URI or bare NIP-19 entity, as shared from
+// mobile clients, and redirects to its deep link
+func (r *Router) handleGoto(rawQuery string) []byte {
+	if rawQuery == "" {
+		return FormatInputResponse("Paste a nostr: link (npub, nprofile, note, nevent or naddr):", false)
+	}
+
+	input, err := url.QueryUnescape(rawQuery)
+	if err != nil {
+		return FormatErrorResponse(StatusBadRequest, "Invalid input")
+	}
+
+	entity, ok := entities.ParseURI(input)
+	if !ok {
+		return FormatErrorResponse(StatusBadRequest, "Not a nostr: link")
+	}
+
+	return FormatRedirectResponse("/"+entity, false)
+}
+
+// handleAddr displays a parameterized replaceable event (e.g. an article) by
+// kind, author and d tag: /addr/<kind>/<pubkey>/<identifier>
+func (r *Router) handleAddr(ctx context.Context, parts []string) []byte {
+	if len(parts) < 2 {
+		return FormatErrorResponse(StatusNotFound, "Missing address")
+	}
+
+	kind, err := strconv.Atoi(parts[0])
+	if err != nil {
+		return FormatErrorResponse(StatusBadRequest, "Invalid address kind")
+	}
+	pubkey := parts[1]
+	if err := r.server.GetSanitizer().ValidatePubkey(pubkey); err != nil {
+		return FormatErrorResponse(StatusBadRequest, fmt.Sprintf("Invalid pubkey: %v", err))
+	}
+	identifier := strings.Join(parts[2:], "/")
+
+	events, err := r.server.GetStorage().QueryEvents(ctx, nostr.Filter{
+		Authors: []string{pubkey},
+		Kinds:   []int{kind},
+		Tags:    nostr.TagMap{"d": []string{identifier}},
+		Limit:   1,
+	})
+	if err != nil || len(events) == 0 {
+		return FormatErrorResponse(StatusNotFound, fmt.Sprintf("Not found: %s", identifier))
+	}
+
+	return r.handleNote(ctx, events[0].ID)
+}
@@ -0,0 +1,56 @@
+package gemini
+
+import (
+	"context"
+	"net/url"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/nbd-wtf/go-nostr"
+	"github.com/nbd-wtf/go-nostr/nip19"
+	"github.com/sandwich/nophr/internal/aggregates"
+	"github.com/sandwich/nophr/internal/config"
+	"github.com/sandwich/nophr/internal/storage"
+)
+
+func TestProfileDeepLinkWithoutMetadata(t *testing.T) {
+	cfg := config.Default()
+	cfg.Identity.Npub = "npub1nq3zgtqruwhnz0xx40gh4a4fkamlr2sc7ke5wqs2s3nyv2fpy9esg4hdwq"
+	cfg.Storage.SQLitePath = ":memory:"
+	dir := t.TempDir()
+	cfg.Protocols.Gemini.TLS.CertPath = filepath.Join(dir, "cert.pem")
+	cfg.Protocols.Gemini.TLS.KeyPath = filepath.Join(dir, "key.pem")
+
+	ctx := context.Background()
+	st, err := storage.New(ctx, &cfg.Storage)
+	if err != nil {
+		t.Fatalf("Failed to create storage: %v", err)
+	}
+	defer st.Close()
+
+	// An author whose notes are stored but whose kind 0 isn't
+	sk := nostr.GeneratePrivateKey()
+	note := signed(t, sk, &nostr.Event{Kind: 1, CreatedAt: 100, Content: "hello"})
+	if err := st.StoreEvent(ctx, note); err != nil {
+		t.Fatalf("Failed to store event: %v", err)
+	}
+	npub, _ := nip19.EncodePublicKey(note.PubKey)
+	stranger, _ := nostr.GetPublicKey(nostr.GeneratePrivateKey())
+	strangerNpub, _ := nip19.EncodePublicKey(stranger)
+
+	server, err := New(&cfg.Protocols.Gemini, cfg, st, "localhost", aggregates.NewManager(st, cfg))
+	if err != nil {
+		t.Fatalf("Failed to create server: %v", err)
+	}
+	route := func(path string) string {
+		return string(server.router.Route(&url.URL{Scheme: "gemini", Host: "localhost", Path: path}))
+	}
+
+	if page := route("/" + npub); !strings.HasPrefix(page, "20 ") || !strings.Contains(page, "/profile/"+note.PubKey+"/notes") {
+		t.Errorf("Expected the npub to open the author's profile, got:\n%s", page)
+	}
+	if page := route("/" + strangerNpub); !strings.HasPrefix(page, "51 ") {
+		t.Errorf("Expected an author with nothing stored not to be found, got:\n%s", page)
+	}
+}
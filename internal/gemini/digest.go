@@ -0,0 +1,60 @@
+package gemini
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/sandwich/nophr/internal/digest"
+)
+
+// handleDigest renders the daily digest: new posts, the posts with the most
+// interactions during the day and the followers gained
+func (r *Router) handleDigest(ctx context.Context) []byte {
+	d, err := r.digests.Current(ctx, time.Now())
+	if err != nil {
+		return FormatErrorResponse(StatusTemporaryFailure, fmt.Sprintf("Error building digest: %v", err))
+	}
+
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("# Daily digest: %s\n\n", d.Period()))
+
+	sb.WriteString(fmt.Sprintf("## New posts (%d)\n\n", len(d.NewPosts)))
+	if len(d.NewPosts) == 0 {
+		sb.WriteString("Nothing new posted.\n")
+	}
+	for _, post := range d.NewPosts {
+		title := digest.Summary(post, 80)
+		if post.Kind == 30023 {
+			title = "[Article] " + title
+		}
+		sb.WriteString(fmt.Sprintf("=> %s %s\n", r.geminiURL("/note/"+post.ID), title))
+	}
+	sb.WriteString("\n")
+
+	sb.WriteString("## Top interactions\n\n")
+	if len(d.TopPosts) == 0 {
+		sb.WriteString("No interactions this day.\n")
+	}
+	for _, post := range d.TopPosts {
+		sb.WriteString(fmt.Sprintf("=> %s %s (%s)\n", r.geminiURL("/note/"+post.Event.ID), digest.Summary(post.Event, 80), digest.Engagement(post.Aggregates)))
+	}
+	sb.WriteString("\n")
+
+	sb.WriteString("## Followers\n\n")
+	sb.WriteString(d.FollowerSummary() + "\n")
+	for _, contact := range d.NewFollowers {
+		name := lineTextReplacer.Replace(contact.Name)
+		if name == "" {
+			name = r.renderer.authorLabel(ctx, contact.Pubkey)
+		}
+		sb.WriteString(fmt.Sprintf("=> %s New: %s\n", r.geminiURL("/profile/"+contact.Pubkey), name))
+	}
+	sb.WriteString("\n")
+
+	sb.WriteString(fmt.Sprintf("=> %s Followers\n", r.geminiURL("/followers")))
+	sb.WriteString(fmt.Sprintf("=> %s Back to Home\n", r.geminiURL("/")))
+
+	return FormatSuccessResponse(sb.String())
+}
@@ -0,0 +1,143 @@
+package gemini
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"strings"
+
+	"github.com/nbd-wtf/go-nostr"
+	"github.com/sandwich/nophr/internal/config"
+	"github.com/sandwich/nophr/internal/gopher"
+	"github.com/sandwich/nophr/internal/outbox"
+)
+
+// draftsPath lists the Markdown drafts in outbox.draft_dir; /drafts/<name>
+// previews one and /drafts/<name>/publish publishes it
+const draftsPath = "/drafts"
+
+// isDraftsPath reports whether path is the drafts view or one of its pages
+func isDraftsPath(path string) bool {
+	return path == draftsPath || strings.HasPrefix(path, draftsPath+"/")
+}
+
+// handleDrafts serves the owner-only drafts view. Like the trash it bypasses
+// the response cache, so edits to a draft show up on the next request.
+func (s *Server) handleDrafts(conn net.Conn, u *url.URL) []byte {
+	if status, meta, ok := s.authorizeLevel(conn, config.AccessLevelOwner); !ok {
+		return FormatResponse(status, meta, "")
+	}
+
+	parts := strings.Split(strings.Trim(strings.TrimPrefix(u.Path, draftsPath), "/"), "/")
+	ctx := context.Background()
+	switch {
+	case parts[0] == "":
+		return s.router.handleDraftList()
+	case len(parts) == 1:
+		return s.router.handleDraftPreview(ctx, parts[0])
+	case len(parts) == 2 && parts[1] == "publish":
+		return s.handleDraftPublish(ctx, parts[0], u.RawQuery)
+	}
+	return FormatErrorResponse(StatusNotFound, "Not found")
+}
+
+// handleDraftList lists the drafts, most recently changed first
+func (r *Router) handleDraftList() []byte {
+	dir := r.server.fullConfig.Outbox.DraftDir
+	drafts, err := outbox.ListDrafts(dir)
+	if err != nil {
+		return FormatErrorResponse(StatusTemporaryFailure, fmt.Sprintf("Error loading drafts: %v", err))
+	}
+
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("# Drafts (%d)\n\n", len(drafts)))
+	sb.WriteString(fmt.Sprintf("Markdown files in %s, previewed as they will appear once published as notes.\n\n", dir))
+	if len(drafts) == 0 {
+		sb.WriteString("There are no drafts.\n\n")
+	}
+	for _, draft := range drafts {
+		summary := lineTextReplacer.Replace(r.renderer.GetSummary(draft.Content, 60))
+		sb.WriteString(fmt.Sprintf("=> %s %s (changed %s) %s\n", r.geminiURL(draftsPath+"/"+draft.Name), draft.Name, draft.Modified.UTC().Format("2006-01-02 15:04"), summary))
+	}
+	sb.WriteString(fmt.Sprintf("\n=> %s Back to Home\n", r.geminiURL("/")))
+
+	return FormatSuccessResponse(sb.String())
+}
+
+// handleDraftPreview renders a draft as its note page on Gemini and on Gopher
+func (r *Router) handleDraftPreview(ctx context.Context, name string) []byte {
+	draft, err := outbox.ReadDraft(r.server.fullConfig.Outbox.DraftDir, name)
+	if errors.Is(err, outbox.ErrDraftNotFound) {
+		return FormatErrorResponse(StatusNotFound, "Draft not found")
+	}
+	if err != nil {
+		return FormatErrorResponse(StatusTemporaryFailure, fmt.Sprintf("Error loading draft: %v", err))
+	}
+
+	owner, err := r.server.GetQueryHelper().OwnerHex()
+	if err != nil {
+		return FormatErrorResponse(StatusTemporaryFailure, fmt.Sprintf("Error loading owner: %v", err))
+	}
+	// The note the draft becomes, unsigned and dated now
+	note := &nostr.Event{PubKey: owner, CreatedAt: nostr.Now(), Kind: nostr.KindTextNote, Tags: nostr.Tags{}, Content: strings.TrimSpace(draft.Content)}
+	note.ID = note.GetID()
+
+	publishURL := r.geminiURL(draftsPath + "/" + name + "/publish")
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("# Draft: %s\n\n", name))
+	sb.WriteString(fmt.Sprintf("Changed %s\n", draft.Modified.UTC().Format("2006-01-02 15:04")))
+	sb.WriteString(fmt.Sprintf("=> %s Publish this draft\n\n", publishURL))
+
+	sb.WriteString("## On Gemini\n\n")
+	sb.WriteString(r.renderer.RenderNote(note, nil, r.isOwner(note.PubKey), r.geminiURL("/thread/"+note.ID), "", r.geminiURL("/"), nil, nil))
+	sb.WriteString("\n## On Gopher\n\n")
+	sb.WriteString("```Gopher rendering of the draft\n")
+	gopherText := gopher.NewRenderer(r.server.fullConfig, r.server.GetStorage()).RenderNote(note, nil, r.isOwner(note.PubKey))
+	for _, line := range strings.Split(strings.TrimRight(gopherText, "\n"), "\n") {
+		if strings.HasPrefix(line, "```") {
+			line = " " + line // Would end the preformatted block
+		}
+		sb.WriteString(line + "\n")
+	}
+	sb.WriteString("```\n\n")
+
+	sb.WriteString(fmt.Sprintf("=> %s Publish this draft\n", publishURL))
+	sb.WriteString(fmt.Sprintf("=> %s All drafts\n", r.geminiURL(draftsPath)))
+	return FormatSuccessResponse(sb.String())
+}
+
+// handleDraftPublish publishes a draft as a note once the owner confirms,
+// moves it to the published folder and redirects to the note
+func (s *Server) handleDraftPublish(ctx context.Context, name, query string) []byte {
+	if s.publisher == nil {
+		return FormatErrorResponse(StatusTemporaryFailure, "Publishing is unavailable: NOPHR_NSEC is not set")
+	}
+	dir := s.fullConfig.Outbox.DraftDir
+	draft, err := outbox.ReadDraft(dir, name)
+	if errors.Is(err, outbox.ErrDraftNotFound) {
+		return FormatErrorResponse(StatusNotFound, "Draft not found")
+	}
+	if err != nil {
+		return FormatErrorResponse(StatusTemporaryFailure, fmt.Sprintf("Error loading draft: %v", err))
+	}
+
+	if answer, _ := url.QueryUnescape(query); !strings.EqualFold(strings.TrimSpace(answer), "yes") {
+		return FormatInputResponse(fmt.Sprintf("Type yes to publish %s as a note", name), false)
+	}
+
+	event, err := s.publisher.PublishNote(ctx, draft.Content)
+	if event == nil {
+		return FormatErrorResponse(StatusTemporaryFailure, fmt.Sprintf("Publish failed: %v", err))
+	}
+	if err != nil {
+		// Stored locally; relays can catch up later
+		fmt.Printf("Draft publish: %v\n", err)
+	}
+	if err := outbox.ArchiveDraft(dir, name); err != nil {
+		fmt.Printf("Draft publish: %v\n", err)
+	}
+
+	return FormatRedirectResponse(s.router.geminiURL("/note/"+event.ID), false)
+}
@@ -0,0 +1,37 @@
+package gemini
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/nbd-wtf/go-nostr"
+	"github.com/sandwich/nophr/internal/feeds"
+)
+
+// atomMIMEType is the media type of Atom feed responses
+const atomMIMEType = "application/atom+xml"
+
+// handleFeed serves a listing's Atom feed or gemini subscription page
+func (r *Router) handleFeed(ctx context.Context, path, listing string, format feeds.Format) []byte {
+	feed, err := r.feeds.Build(ctx, listing)
+	if errors.Is(err, feeds.ErrNoFeed) {
+		return FormatErrorResponse(StatusNotFound, fmt.Sprintf("No feed for %s", listing))
+	}
+	if err != nil {
+		return FormatErrorResponse(StatusTemporaryFailure, fmt.Sprintf("Error building feed: %v", err))
+	}
+
+	links := feeds.Links{
+		Self: r.geminiURL(path),
+		Page: r.geminiURL(listing),
+		Entry: func(event *nostr.Event) string {
+			return r.geminiURL("/note/" + event.ID)
+		},
+	}
+
+	if format == feeds.FormatGemini {
+		return FormatSuccessResponse(feed.Gemini(links))
+	}
+	return FormatResponse(StatusSuccess, atomMIMEType, string(feed.Atom(links)))
+}
@@ -0,0 +1,43 @@
+package gemini
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/nbd-wtf/go-nostr"
+	"github.com/sandwich/nophr/internal/config"
+	"github.com/sandwich/nophr/internal/entities"
+	"github.com/sandwich/nophr/internal/idn"
+	"github.com/sandwich/nophr/internal/mediaproxy"
+)
+
+// writeMediaLinks lists an entry's media and links below it, as link lines
+// unless rendering.gemini.media_links is text. With the media proxy on, media
+// links point at the local copy under /media/.
+func (r *Router) writeMediaLinks(sb *strings.Builder, event *nostr.Event) {
+	for _, link := range r.renderer.blocklist.Filter(entities.MediaLinks(event)) {
+		if r.server.fullConfig.Rendering.Gemini.MediaLinks == config.MediaLinksText {
+			sb.WriteString(fmt.Sprintf("%s: %s\n", link.Label, idn.DisplayURL(link.URL)))
+			continue
+		}
+		target := idn.LinkURL(link.URL)
+		if path, ok := r.server.media.Path(link); ok {
+			target = r.geminiURL(path)
+		}
+		sb.WriteString(fmt.Sprintf("=> %s %s: %s\n", target, link.Label, idn.DisplayURL(link.URL)))
+	}
+}
+
+// handleMedia serves a proxied media file with its MIME type
+func (r *Router) handleMedia(ctx context.Context, key string) []byte {
+	media, err := r.server.media.Fetch(ctx, key)
+	if errors.Is(err, mediaproxy.ErrNotFound) {
+		return FormatErrorResponse(StatusNotFound, "Media not found")
+	}
+	if err != nil {
+		return FormatErrorResponse(StatusTemporaryFailure, fmt.Sprintf("Media unavailable: %v", err))
+	}
+	return FormatResponse(StatusSuccess, media.MIMEType, string(media.Data))
+}
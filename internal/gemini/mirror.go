@@ -0,0 +1,185 @@
+package gemini
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/nbd-wtf/go-nostr"
+	"github.com/sandwich/nophr/internal/aggregates"
+	nostrclient "github.com/sandwich/nophr/internal/nostr"
+	"github.com/sandwich/nophr/internal/sections"
+)
+
+// handleMirror serves the subtree of a mirror section: a home page, the
+// author's notes, articles and profile, and each of their notes under
+// <path>/note/<id>
+func (r *Router) handleMirror(ctx context.Context, section *sections.Section, parts []string) []byte {
+	base := strings.TrimSuffix(section.Path, "/")
+	author := section.MirrorAuthor()
+
+	before, remaining, err := aggregates.CursorFromParts(parts)
+	if err != nil {
+		return FormatErrorResponse(StatusBadRequest, err.Error())
+	}
+	if len(remaining) == 0 {
+		return r.handleMirrorHome(ctx, section, base)
+	}
+
+	switch remaining[0] {
+	case "notes":
+		page, err := r.server.GetQueryHelper().GetAuthorNotesPage(ctx, author, before, section.Limit)
+		if err != nil {
+			return FormatErrorResponse(StatusTemporaryFailure, fmt.Sprintf("Error loading notes: %v", err))
+		}
+		return r.renderMirrorListing(ctx, section, base, "Notes", "/notes", page)
+
+	case "articles":
+		page, err := r.server.GetQueryHelper().GetAuthorArticlesPage(ctx, author, before, section.Limit)
+		if err != nil {
+			return FormatErrorResponse(StatusTemporaryFailure, fmt.Sprintf("Error loading articles: %v", err))
+		}
+		return r.renderMirrorListing(ctx, section, base, "Articles", "/articles", page)
+
+	case "profile":
+		profile := r.mirrorProfile(ctx, author)
+		if profile == nil {
+			return FormatErrorResponse(StatusNotFound, fmt.Sprintf("Profile not found: %s", author))
+		}
+		gemtext := r.renderer.RenderProfile(profile, r.geminiURL(base+"/notes"), r.geminiURL(base))
+		return FormatSuccessResponse(gemtext)
+
+	case "note":
+		if len(remaining) < 2 {
+			return FormatErrorResponse(StatusNotFound, "Missing note ID")
+		}
+		return r.handleMirrorNote(ctx, author, remaining[1])
+	}
+
+	return FormatErrorResponse(StatusNotFound, fmt.Sprintf("Not found: %s/%s", base, strings.Join(parts, "/")))
+}
+
+// handleMirrorHome renders a mirror's home page: the author's name and bio,
+// links to their notes, articles and profile, and their latest posts
+func (r *Router) handleMirrorHome(ctx context.Context, section *sections.Section, base string) []byte {
+	author := section.MirrorAuthor()
+	name := lineTextReplacer.Replace(r.renderer.resolver.AuthorName(ctx, author))
+
+	var sb strings.Builder
+	title := section.Title
+	if title == "" {
+		title = name
+	}
+	sb.WriteString(fmt.Sprintf("# %s\n\n", lineTextReplacer.Replace(title)))
+
+	description := section.Description
+	if description == "" {
+		if profile := nostrclient.ParseProfile(r.mirrorProfile(ctx, author)); profile != nil {
+			description = profile.About
+		}
+	}
+	if description = strings.TrimSpace(description); description != "" {
+		sb.WriteString(description + "\n\n")
+	}
+
+	sb.WriteString(fmt.Sprintf("=> %s Notes\n", r.geminiURL(base+"/notes")))
+	sb.WriteString(fmt.Sprintf("=> %s Articles\n", r.geminiURL(base+"/articles")))
+	sb.WriteString(fmt.Sprintf("=> %s Profile\n\n", r.geminiURL(base+"/profile")))
+
+	posts, err := r.server.GetQueryHelper().GetNotesByAuthor(ctx, author, section.Limit)
+	switch {
+	case err != nil:
+		sb.WriteString(fmt.Sprintf("Error loading posts: %v\n\n", err))
+	case len(posts) == 0:
+		sb.WriteString(fmt.Sprintf("No notes or articles stored for %s yet.\n\n", name))
+	default:
+		sb.WriteString("## Latest posts\n\n")
+		for _, post := range posts {
+			r.writeMirrorEntry(&sb, base, post)
+		}
+	}
+
+	sb.WriteString(fmt.Sprintf("=> %s ⌂ Home\n", r.geminiURL("/")))
+
+	return FormatSuccessResponse(r.renderer.applyHeadersFooters(sb.String(), section.Name))
+}
+
+// renderMirrorListing renders one page of a mirrored author's notes or articles
+func (r *Router) renderMirrorListing(ctx context.Context, section *sections.Section, base, title, view string, page *aggregates.EventPage) []byte {
+	name := lineTextReplacer.Replace(r.renderer.resolver.AuthorName(ctx, section.MirrorAuthor()))
+
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("# %s by %s\n\n", title, name))
+
+	if len(page.Events) == 0 {
+		sb.WriteString("Nothing stored yet.\n\n")
+	}
+	for i, item := range page.Events {
+		if aggregates.IsRepost(item.Event) {
+			sb.WriteString(r.renderer.renderRepostItem(i+1, item))
+			continue
+		}
+		r.writeMirrorEntry(&sb, base, item)
+	}
+
+	if page.Next != nil {
+		sb.WriteString(fmt.Sprintf("=> %s Older →\n", r.geminiURL(fmt.Sprintf("%s%s/until/%s", base, view, page.Next))))
+	}
+	if page.Before != nil {
+		sb.WriteString(fmt.Sprintf("=> %s ↑ Newest\n", r.geminiURL(base+view)))
+	}
+	sb.WriteString(fmt.Sprintf("=> %s ← %s\n", r.geminiURL(base), name))
+	sb.WriteString(fmt.Sprintf("=> %s ⌂ Home\n", r.geminiURL("/")))
+
+	return FormatSuccessResponse(r.renderer.applyHeadersFooters(sb.String(), section.Name))
+}
+
+// writeMirrorEntry writes a note or article, linked below the mirror's path
+func (r *Router) writeMirrorEntry(sb *strings.Builder, base string, item *aggregates.EnrichedEvent) {
+	var text string
+	if item.Event.Kind == 30023 {
+		text = "[Article] " + sections.ArticleTitle(item.Event)
+	} else {
+		text = strings.Split(item.Event.Content, "\n")[0]
+	}
+	if len(text) > 80 {
+		text = text[:77] + "..."
+	}
+
+	sb.WriteString(formatTimestamp(item.Event.CreatedAt) + "\n")
+	if item.Aggregates != nil && item.Aggregates.HasInteractions() {
+		sb.WriteString(r.renderer.renderAggregates(item.Aggregates))
+	}
+	sb.WriteString(fmt.Sprintf("=> %s %s\n\n", r.geminiURL(base+"/note/"+item.Event.ID), lineTextReplacer.Replace(text)))
+}
+
+// handleMirrorNote renders one of the mirrored author's notes. Other authors'
+// events aren't served under the mirror's path.
+func (r *Router) handleMirrorNote(ctx context.Context, author, noteID string) []byte {
+	if err := r.server.GetSanitizer().ValidateEventID(noteID); err != nil {
+		return FormatErrorResponse(StatusBadRequest, fmt.Sprintf("Invalid note ID: %v", err))
+	}
+
+	events, err := r.server.GetStorage().QueryEvents(ctx, nostr.Filter{
+		IDs:     []string{noteID},
+		Authors: []string{author},
+	})
+	if err != nil || len(events) == 0 {
+		return FormatErrorResponse(StatusNotFound, fmt.Sprintf("Note not found: %s", noteID))
+	}
+
+	return r.handleNote(ctx, noteID)
+}
+
+// mirrorProfile returns the author's stored profile event, or nil
+func (r *Router) mirrorProfile(ctx context.Context, pubkey string) *nostr.Event {
+	events, err := r.server.GetStorage().QueryEvents(ctx, nostr.Filter{
+		Kinds:   []int{0},
+		Authors: []string{pubkey},
+		Limit:   1,
+	})
+	if err != nil || len(events) == 0 {
+		return nil
+	}
+	return events[0]
+}
@@ -0,0 +1,31 @@
+package gemini
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/sandwich/nophr/internal/config"
+	"github.com/sandwich/nophr/internal/pages"
+)
+
+// handlePage renders a static page as gemtext, converting markdown sources
+func (r *Router) handlePage(page *pages.Page) []byte {
+	source, err := page.Source()
+	if err != nil {
+		return FormatErrorResponse(StatusTemporaryFailure, "Error loading page")
+	}
+
+	body := source
+	if page.Format != config.PageFormatGemtext {
+		body, _ = r.renderer.parser.RenderGemini([]byte(source), nil)
+	}
+
+	var sb strings.Builder
+	if !strings.HasPrefix(strings.TrimSpace(body), "# ") {
+		sb.WriteString("# " + page.Title() + "\n\n")
+	}
+	sb.WriteString(strings.TrimRight(body, "\n"))
+	sb.WriteString(fmt.Sprintf("\n\n=> %s Back to Home\n", r.geminiURL("/")))
+
+	return FormatSuccessResponse(r.renderer.applyHeadersFooters(sb.String(), strings.TrimPrefix(page.Path, "/")))
+}
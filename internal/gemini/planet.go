@@ -0,0 +1,41 @@
+package gemini
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/sandwich/nophr/internal/sections"
+)
+
+// writePlanetEntries lists a planet section's articles, crediting each author
+// by name and naming anyone else who posted the same article
+func (r *Router) writePlanetEntries(sb *strings.Builder, page *sections.Page) {
+	for _, event := range page.Events {
+		byline := "By " + planetAuthor(page, event.PubKey)
+		if page.Section.ShowDates {
+			byline += " - " + formatTimestamp(event.CreatedAt)
+		}
+		sb.WriteString(byline + "\n")
+		if others := page.CrossPosts[event.ID]; len(others) > 0 {
+			names := make([]string, len(others))
+			for i, pubkey := range others {
+				names[i] = planetAuthor(page, pubkey)
+			}
+			sb.WriteString("Also posted by " + strings.Join(names, ", ") + "\n")
+		}
+
+		sb.WriteString(fmt.Sprintf("=> %s %s\n", r.geminiURL("/note/"+event.ID), sections.ArticleTitle(event)))
+		if page.Section.MediaLinks {
+			r.writeMediaLinks(sb, event)
+		}
+		sb.WriteString("\n")
+	}
+}
+
+// planetAuthor returns an author's display name, or their shortened pubkey
+func planetAuthor(page *sections.Page, pubkey string) string {
+	if name := page.AuthorName(pubkey); name != "" {
+		return name
+	}
+	return truncatePubkey(pubkey)
+}
@@ -7,69 +7,166 @@ import (
 	"time"
 
 	"github.com/nbd-wtf/go-nostr"
+	"github.com/nbd-wtf/go-nostr/nip19"
 	"github.com/sandwich/nophr/internal/aggregates"
+	"github.com/sandwich/nophr/internal/annotate"
 	"github.com/sandwich/nophr/internal/config"
 	"github.com/sandwich/nophr/internal/entities"
+	"github.com/sandwich/nophr/internal/idn"
 	"github.com/sandwich/nophr/internal/markdown"
 	nostrclient "github.com/sandwich/nophr/internal/nostr"
+	"github.com/sandwich/nophr/internal/pages"
 	"github.com/sandwich/nophr/internal/presentation"
+	"github.com/sandwich/nophr/internal/qr"
+	"github.com/sandwich/nophr/internal/sections"
 	"github.com/sandwich/nophr/internal/storage"
 )
 
 // Renderer renders Nostr events as Gemtext
 type Renderer struct {
-	parser   *markdown.Parser
-	config   *config.Config
-	loader   *presentation.Loader
-	resolver *entities.Resolver
+	parser    *markdown.Parser
+	config    *config.Config
+	loader    *presentation.Loader
+	templates *presentation.Templates // presentation.templates.gemini
+	resolver  *entities.Resolver
+	blocklist *entities.DomainBlocklist // rendering.blocked_domains
+	authors   config.AuthorOverrides    // rendering.authors
+	names     *nostrclient.NameResolver // display.authors
+	storage   *storage.Storage
 }
 
 // NewRenderer creates a new event renderer
 func NewRenderer(cfg *config.Config, st *storage.Storage) *Renderer {
+	// Entity links point back into this Gemini server
+	links := entities.LinkContext{
+		Protocol: entities.ProtocolGemini,
+		Host:     cfg.Protocols.Gemini.Host,
+		Port:     cfg.Protocols.Gemini.Port,
+	}
+
+	blocklist := entities.NewDomainBlocklist(cfg.Rendering.BlockedDomains)
+	resolver := entities.NewResolver(st, links)
+	resolver.SetBlocklist(blocklist)
+
 	return &Renderer{
-		parser:   markdown.NewParser(),
-		config:   cfg,
-		loader:   presentation.NewLoader(cfg),
-		resolver: entities.NewResolver(st),
+		parser:    markdown.NewParser(),
+		config:    cfg,
+		loader:    presentation.NewLoader(cfg),
+		templates: presentation.NewTemplates(cfg, "gemini"),
+		resolver:  resolver,
+		blocklist: blocklist,
+		authors:   cfg.Rendering.AuthorOverrides(),
+		names:     nostrclient.NewNameResolver(st, cfg.Display.Authors),
+		storage:   st,
 	}
 }
 
-// RenderHome renders the home page
-func (r *Renderer) RenderHome() string {
-	var sb strings.Builder
+// authorLabel names an author in a listing or thread, e.g. "Alice
+// (npub1abcd...wxyz)", in the display.authors style
+func (r *Renderer) authorLabel(ctx context.Context, pubkey string) string {
+	return lineTextReplacer.Replace(r.names.Label(ctx, pubkey))
+}
+
+// annotationLines renders the event's keyed annotations with the formatters
+// registered in the annotate package, e.g. an article's reading time
+func (r *Renderer) annotationLines(ctx context.Context, eventID string) []string {
+	if r.storage == nil {
+		return nil
+	}
+	values, err := r.storage.GetAnnotationValues(ctx, eventID)
+	if err != nil {
+		return nil
+	}
+	return annotate.Format(values)
+}
+
+// RenderHome renders the home page, listing the site's custom sections and static pages
+func (r *Renderer) RenderHome(sectionList []*sections.Section, pageList []*pages.Page) string {
+	links := []presentation.Link{
+		{Title: "Notes", URL: "/notes"},
+		{Title: "Articles", URL: "/articles"},
+	}
+	if !r.config.Display.HideInbox {
+		links = append(links,
+			presentation.Link{Title: "Replies", URL: "/replies"},
+			presentation.Link{Title: "Mentions", URL: "/mentions"})
+	}
+	links = append(links,
+		presentation.Link{Title: "Archive", URL: "/archive"},
+		presentation.Link{Title: "Following", URL: "/following"},
+		presentation.Link{Title: "Followers", URL: "/followers"},
+		presentation.Link{Title: "Daily digest", URL: "/digest"},
+		presentation.Link{Title: "Tags", URL: "/tags"})
+	for _, section := range sectionList {
+		links = append(links, presentation.Link{Title: section.MenuTitle(), URL: section.Path})
+	}
+	for _, page := range pageList {
+		links = append(links, presentation.Link{Title: page.Title(), URL: page.Path})
+	}
+	links = append(links,
+		presentation.Link{Title: "Search", URL: "/search"},
+		presentation.Link{Title: "Open a nostr: link", URL: "/goto"},
+		presentation.Link{Title: "Stats", URL: "/stats"},
+		presentation.Link{Title: "Diagnostics", URL: "/diagnostics"})
 
+	var sb strings.Builder
 	sb.WriteString("# nophr - Nostr Gateway\n\n")
 	sb.WriteString("Browse Nostr content via Gemini protocol\n\n")
 	sb.WriteString("## Navigation\n\n")
-	sb.WriteString("=> /notes Notes\n")
-	sb.WriteString("=> /articles Articles\n")
-	sb.WriteString("=> /replies Replies\n")
-	sb.WriteString("=> /mentions Mentions\n")
-	sb.WriteString("=> /search Search\n")
-	sb.WriteString("=> /diagnostics Diagnostics\n")
+	for _, link := range links {
+		sb.WriteString(fmt.Sprintf("=> %s %s\n", link.URL, link.Title))
+	}
 	sb.WriteString("\n")
 	sb.WriteString("Powered by nophr\n")
 
-	return r.applyHeadersFooters(sb.String(), "home")
+	home := r.applyTemplate(presentation.LayoutHome, sb.String(), presentation.PageData{Links: links})
+	return r.applyHeadersFooters(home, "home")
 }
 
-// RenderNote renders a note event as gemtext
-func (r *Renderer) RenderNote(event *nostr.Event, agg *aggregates.EventAggregates, threadURL, homeURL string) string {
+// applyTemplate renders layout's template, if one is configured, with data
+// and the built-in rendering; otherwise, or if it fails, it returns builtin
+func (r *Renderer) applyTemplate(layout, builtin string, data presentation.PageData) string {
+	if !r.templates.Has(layout) {
+		return builtin
+	}
+	data.Builtin = builtin
+	if rendered, ok := r.templates.Render(layout, data); ok {
+		return rendered
+	}
+	return builtin
+}
+
+// RenderNote renders a note event as gemtext. An empty zapURL leaves out the zap
+// link, and attributed adds the site's author and license lines. A translation
+// is shown below the content, and translateLinks are listed with the actions.
+func (r *Renderer) RenderNote(event *nostr.Event, agg *aggregates.EventAggregates, attributed bool, threadURL, zapURL, homeURL string, translateLinks []translateLink, translation *noteTranslation) string {
 	var sb strings.Builder
 
 	// Header
-	sb.WriteString(fmt.Sprintf("# Note by %s\n", truncatePubkey(event.PubKey)))
-	sb.WriteString(fmt.Sprintf("Posted: %s\n\n", formatTimestamp(event.CreatedAt)))
+	ctx := context.Background()
+	sb.WriteString(fmt.Sprintf("# Note by %s\n", r.authorLabel(ctx, event.PubKey)))
+	sb.WriteString(fmt.Sprintf("Posted: %s\n", formatTimestamp(event.CreatedAt)))
+	for _, line := range r.annotationLines(ctx, event.ID) {
+		sb.WriteString(line + "\n")
+	}
+	sb.WriteString("\n")
 
-	// Content (resolve NIP-19 entities, then render markdown as gemtext)
+	// Content (resolve NIP-19 entities as links, then render markdown as gemtext)
 	content := event.Content
-	ctx := context.Background()
-	content = r.resolver.ReplaceEntities(ctx, content, entities.PlainTextFormatter)
+	content = r.resolver.ReplaceEntities(ctx, content, entities.GeminiFormatter)
 
 	rendered, _ := r.parser.RenderGemini([]byte(content), nil)
 	sb.WriteString(rendered)
 	sb.WriteString("\n")
 
+	if translation != nil {
+		sb.WriteString(fmt.Sprintf("## Translation (%s)\n\n", translation.Lang))
+		translated := r.resolver.ReplaceEntities(ctx, translation.Text, entities.GeminiFormatter)
+		rendered, _ := r.parser.RenderGemini([]byte(translated), nil)
+		sb.WriteString(rendered)
+		sb.WriteString("\n")
+	}
+
 	// Aggregates
 	if agg != nil && agg.HasInteractions() {
 		sb.WriteString("## Interactions\n\n")
@@ -77,26 +174,99 @@ func (r *Renderer) RenderNote(event *nostr.Event, agg *aggregates.EventAggregate
 		sb.WriteString("\n")
 	}
 
+	if attributed {
+		sb.WriteString(r.RenderAttribution())
+	}
+
+	if r.config.Display.Detail.ShowQR {
+		sb.WriteString(r.renderQR(event))
+	}
+
 	// Navigation
+	actions := []presentation.Link{{Title: "View Thread", URL: threadURL}}
+	if zapURL != "" {
+		actions = append(actions, presentation.Link{Title: "⚡ Zap this note", URL: zapURL})
+	}
+	for _, link := range translateLinks {
+		if translation == nil || link.Lang != translation.Lang {
+			actions = append(actions, presentation.Link{Title: fmt.Sprintf("Translate (%s)", link.Lang), URL: link.URL})
+		}
+	}
+	actions = append(actions, presentation.Link{Title: "Back to Home", URL: homeURL})
 	sb.WriteString("## Actions\n\n")
-	sb.WriteString(fmt.Sprintf("=> %s View Thread\n", threadURL))
-	sb.WriteString(fmt.Sprintf("=> %s Back to Home\n", homeURL))
+	for _, link := range actions {
+		sb.WriteString(fmt.Sprintf("=> %s %s\n", link.URL, link.Title))
+	}
+
+	return r.applyTemplate(presentation.LayoutNote, sb.String(), presentation.PageData{
+		Event:      presentation.NewEventData(event, r.authorLabel(ctx, event.PubKey), rendered, "/note/"+event.ID),
+		Aggregates: r.aggregateData(agg),
+		Links:      actions,
+	})
+}
+
+// renderQR renders the note's nevent URI as a preformatted QR code that a
+// phone can scan to open it in a Nostr app, followed by a link to the URI
+func (r *Renderer) renderQR(event *nostr.Event) string {
+	nevent, err := nip19.EncodeEvent(event.ID, nil, event.PubKey)
+	if err != nil {
+		return ""
+	}
+	uri := "nostr:" + nevent
+	code, err := qr.Encode([]byte(uri))
+	if err != nil {
+		return ""
+	}
+	return "## Open in a Nostr app\n\n```QR code of " + uri + "\n" + code.HalfBlocks() + "```\n=> " + uri + " " + uri + "\n\n"
+}
 
+// RenderAttribution renders the configured author and license as gemtext,
+// using link lines where a URL is set. It's empty if neither is configured.
+func (r *Renderer) RenderAttribution() string {
+	site := r.config.Site
+	if !site.HasAttribution() {
+		return ""
+	}
+
+	var sb strings.Builder
+	if author := site.AuthorName(); author != "" {
+		sb.WriteString(gemtextLine(site.AuthorURL, "Written by "+author))
+	}
+	if site.License.Name != "" {
+		sb.WriteString(gemtextLine(site.License.URL, "Licensed under "+site.License.Name))
+	}
+	sb.WriteString("\n")
 	return sb.String()
 }
 
+// gemtextLine returns text as a link line to url, or a plain line without one
+func gemtextLine(url, text string) string {
+	if url == "" {
+		return text + "\n"
+	}
+	return fmt.Sprintf("=> %s %s\n", url, text)
+}
+
 // RenderProfile renders a profile event
-func (r *Renderer) RenderProfile(profileEvent *nostr.Event, homeURL string) string {
+func (r *Renderer) RenderProfile(profileEvent *nostr.Event, notesURL, homeURL string) string {
 	var sb strings.Builder
 
 	// Parse profile metadata
 	profile := nostrclient.ParseProfile(profileEvent)
+	data := presentation.PageData{
+		Profile: presentation.NewProfileData(profileEvent.PubKey, profile),
+		Links: []presentation.Link{
+			{Title: "Notes & articles", URL: notesURL},
+			{Title: "Back to Home", URL: homeURL},
+		},
+	}
 	if profile == nil {
 		// Fallback for invalid profile
 		sb.WriteString(fmt.Sprintf("# Profile: %s\n\n", truncatePubkey(profileEvent.PubKey)))
 		sb.WriteString("Invalid profile data\n\n")
+		sb.WriteString(fmt.Sprintf("=> %s Notes & articles\n", notesURL))
 		sb.WriteString(fmt.Sprintf("=> %s Back to Home\n", homeURL))
-		return sb.String()
+		return r.applyTemplate(presentation.LayoutProfile, sb.String(), data)
 	}
 
 	// Header with display name
@@ -133,7 +303,7 @@ func (r *Renderer) RenderProfile(profileEvent *nostr.Event, homeURL string) stri
 	if hasContact {
 		sb.WriteString("## Contact & Links\n\n")
 		if profile.Website != "" {
-			sb.WriteString(fmt.Sprintf("=> %s Website\n", profile.Website))
+			sb.WriteString(fmt.Sprintf("=> %s Website: %s\n", idn.LinkURL(profile.Website), idn.DisplayURL(profile.Website)))
 		}
 		if profile.NIP05 != "" {
 			sb.WriteString(fmt.Sprintf("**NIP-05:** %s\n", profile.NIP05))
@@ -159,20 +329,23 @@ func (r *Renderer) RenderProfile(profileEvent *nostr.Event, homeURL string) stri
 	}
 
 	// Navigation
+	sb.WriteString(fmt.Sprintf("=> %s Notes & articles\n", notesURL))
 	sb.WriteString(fmt.Sprintf("=> %s Back to Home\n", homeURL))
 
-	return sb.String()
+	return r.applyTemplate(presentation.LayoutProfile, sb.String(), data)
 }
 
-// RenderThread renders a thread with replies
-func (r *Renderer) RenderThread(root *aggregates.EnrichedEvent, replies []*aggregates.EnrichedEvent, homeURL string) string {
+// RenderThread renders a thread as a nested reply tree, indenting each level
+// by rendering.gemini.thread_indent
+func (r *Renderer) RenderThread(ctx context.Context, thread *aggregates.ThreadView, homeURL string) string {
 	var sb strings.Builder
+	root, replies := thread.Root, thread.Replies
 
 	sb.WriteString("# Thread\n\n")
 
 	// Root post
 	sb.WriteString("## Root Post\n\n")
-	sb.WriteString(fmt.Sprintf("By %s - %s\n\n", truncatePubkey(root.Event.PubKey), formatTimestamp(root.Event.CreatedAt)))
+	sb.WriteString(fmt.Sprintf("By %s - %s\n\n", r.authorLabel(ctx, root.Event.PubKey), formatTimestamp(root.Event.CreatedAt)))
 
 	// Render content
 	content, _ := r.parser.RenderGemini([]byte(root.Event.Content), nil)
@@ -189,14 +362,39 @@ func (r *Renderer) RenderThread(root *aggregates.EnrichedEvent, replies []*aggre
 	if len(replies) > 0 {
 		sb.WriteString(fmt.Sprintf("## Replies (%d)\n\n", len(replies)))
 
-		for i, reply := range replies {
-			sb.WriteString(fmt.Sprintf("### Reply %d\n\n", i+1))
-			sb.WriteString(fmt.Sprintf("By %s - %s\n\n", truncatePubkey(reply.Event.PubKey), formatTimestamp(reply.Event.CreatedAt)))
+		// Nest replies by their NIP-10 parent, clamped to the configured depth.
+		// Only headings and bylines are indented: indenting content would turn
+		// its links and headings into plain text.
+		nodes := aggregates.FlattenThread(thread.Tree, r.config.Display.Limits.MaxThreadDepth)
+		authors := aggregates.ThreadAuthors(ctx, nodes, r.authorLabel)
+
+		// Quick index of heading anchors, for clients that list headings as an outline
+		for i, node := range nodes {
+			sb.WriteString(fmt.Sprintf("* Reply %d by %s → %s\n", i+1, authors[node.Event.Event.PubKey], node.Anchor()))
+		}
+		sb.WriteString("\n")
 
-			// Reply content
-			replyContent, _ := r.parser.RenderGemini([]byte(reply.Event.Content), nil)
-			sb.WriteString(replyContent)
-			sb.WriteString("\n")
+		labels := make(map[string]string)
+		for i, node := range nodes {
+			reply := node.Event
+			labels[reply.Event.ID] = fmt.Sprintf("Reply %d %s", i+1, node.Anchor())
+			indent := strings.Repeat(r.config.Rendering.Gemini.ThreadIndent, node.Depth-1)
+
+			sb.WriteString(fmt.Sprintf("### %s↳ %s\n\n", indent, labels[reply.Event.ID]))
+			byline := fmt.Sprintf("By %s - %s", authors[reply.Event.PubKey], formatTimestamp(reply.Event.CreatedAt))
+			if parent, ok := labels[node.ParentID]; ok {
+				byline += " - in reply to " + parent
+			}
+			sb.WriteString(indent + byline + "\n\n")
+
+			// Reply content, or a one-line summary for collapsed authors
+			if r.authors.Collapsed(reply.Event.PubKey) {
+				sb.WriteString("> " + r.GetSummary(reply.Event.Content, r.config.Display.Limits.SummaryLength) + "\n\n")
+			} else {
+				replyContent, _ := r.parser.RenderGemini([]byte(reply.Event.Content), nil)
+				sb.WriteString(replyContent)
+				sb.WriteString("\n")
+			}
 
 			// Reply link
 			sb.WriteString(fmt.Sprintf("=> /note/%s View Reply\n\n", reply.Event.ID))
@@ -212,18 +410,28 @@ func (r *Renderer) RenderThread(root *aggregates.EnrichedEvent, replies []*aggre
 
 // RenderNoteList renders a list of notes with summaries
 func (r *Renderer) RenderNoteList(notes []*aggregates.EnrichedEvent, title, homeURL string) string {
+	return r.RenderNoteListPage(notes, title, homeURL, "", "")
+}
+
+// RenderNoteListPage renders one page of a paginated note list.
+// olderURL and newestURL are omitted when empty.
+func (r *Renderer) RenderNoteListPage(notes []*aggregates.EnrichedEvent, title, homeURL, olderURL, newestURL string) string {
 	var sb strings.Builder
 
 	// Determine page name from title for headers/footers
 	// Map common titles to page names
 	pageName := "notes" // default
 	titleLower := strings.ToLower(title)
-	if strings.Contains(titleLower, "article") {
+	if strings.HasPrefix(title, "#") || strings.HasPrefix(title, "Topic: ") || strings.HasPrefix(title, "Language: ") {
+		pageName = "tags"
+	} else if strings.Contains(titleLower, "article") {
 		pageName = "articles"
 	} else if strings.Contains(titleLower, "repl") {
 		pageName = "replies"
 	} else if strings.Contains(titleLower, "mention") {
 		pageName = "mentions"
+	} else if strings.HasPrefix(titleLower, "archive") {
+		pageName = "archive"
 	}
 
 	sb.WriteString(fmt.Sprintf("# %s\n\n", title))
@@ -235,6 +443,11 @@ func (r *Renderer) RenderNoteList(notes []*aggregates.EnrichedEvent, title, home
 	}
 
 	for i, note := range notes {
+		if aggregates.IsRepost(note.Event) {
+			sb.WriteString(r.renderRepostItem(i+1, note))
+			continue
+		}
+
 		// Extract first line of content as summary
 		content := note.Event.Content
 		if len(content) > 100 {
@@ -242,21 +455,84 @@ func (r *Renderer) RenderNoteList(notes []*aggregates.EnrichedEvent, title, home
 		}
 		firstLine := strings.Split(content, "\n")[0]
 
-		sb.WriteString(fmt.Sprintf("## %d. %s\n\n", i+1, firstLine))
-		sb.WriteString(fmt.Sprintf("By %s - %s\n", truncatePubkey(note.Event.PubKey), formatTimestamp(note.Event.CreatedAt)))
+		var item strings.Builder
+		author := r.authorLabel(context.Background(), note.Event.PubKey)
+		item.WriteString(fmt.Sprintf("## %d. %s\n\n", i+1, firstLine))
+		item.WriteString(fmt.Sprintf("By %s - %s\n", author, formatTimestamp(note.Event.CreatedAt)))
 
 		if note.Aggregates != nil && note.Aggregates.HasInteractions() {
-			sb.WriteString(r.renderAggregates(note.Aggregates))
+			item.WriteString(r.renderAggregates(note.Aggregates))
 		}
 
-		sb.WriteString(fmt.Sprintf("\n=> /note/%s Read Full Note\n\n", note.Event.ID))
+		url := "/note/" + note.Event.ID
+		item.WriteString(fmt.Sprintf("\n=> %s Read Full Note\n\n", url))
+		sb.WriteString(r.applyTemplate(presentation.LayoutListItem, item.String(), presentation.PageData{
+			Event:      presentation.NewEventData(note.Event, author, "", url),
+			Aggregates: r.aggregateData(note.Aggregates),
+			Number:     i + 1,
+		}))
 	}
 
+	if olderURL != "" {
+		sb.WriteString(fmt.Sprintf("=> %s Older →\n", olderURL))
+	}
+	if newestURL != "" {
+		sb.WriteString(fmt.Sprintf("=> %s ↑ Newest\n", newestURL))
+	}
 	sb.WriteString(fmt.Sprintf("=> %s Back to Home\n", homeURL))
 
 	return r.applyHeadersFooters(sb.String(), pageName)
 }
 
+// renderRepostItem renders a repost in a note list as "X reposted:" with the
+// reposted note inline, linking to the note itself rather than the repost
+func (r *Renderer) renderRepostItem(number int, item *aggregates.EnrichedEvent) string {
+	var sb strings.Builder
+
+	label := "Reposted"
+	if len(item.Reposters) > 0 {
+		name := r.authorLabel(context.Background(), item.Reposters[0])
+		switch len(item.Reposters) {
+		case 1:
+			label = name + " reposted"
+		case 2:
+			label = fmt.Sprintf("%s and 1 other reposted (2 reposts)", name)
+		default:
+			label = fmt.Sprintf("%s and %d others reposted (%d reposts)", name, len(item.Reposters)-1, len(item.Reposters))
+		}
+	}
+	sb.WriteString(fmt.Sprintf("## %d. %s:\n\n", number, label))
+
+	target := item.Reposted
+	if target == nil {
+		if targetID := aggregates.RepostTarget(item.Event); targetID != "" {
+			sb.WriteString(fmt.Sprintf("%s - the reposted note isn't stored\n", formatTimestamp(item.Event.CreatedAt)))
+			sb.WriteString(fmt.Sprintf("\n=> /note/%s Reposted Note\n\n", targetID))
+		} else {
+			sb.WriteString("The reposted note is unavailable\n\n")
+		}
+		return sb.String()
+	}
+
+	// Quote the note's opening like a feed summary
+	content := target.Content
+	if len(content) > 100 {
+		content = content[:97] + "..."
+	}
+	sb.WriteString(fmt.Sprintf("> %s\n", strings.Split(content, "\n")[0]))
+	sb.WriteString(fmt.Sprintf("By %s - %s, reposted %s\n",
+		r.authorLabel(context.Background(), target.PubKey),
+		formatTimestamp(target.CreatedAt),
+		formatTimestamp(item.Event.CreatedAt)))
+
+	if item.Aggregates != nil && item.Aggregates.HasInteractions() {
+		sb.WriteString(r.renderAggregates(item.Aggregates))
+	}
+
+	sb.WriteString(fmt.Sprintf("\n=> /note/%s Read Full Note\n\n", target.ID))
+	return sb.String()
+}
+
 // renderAggregates renders interaction stats (for feed view)
 func (r *Renderer) renderAggregates(agg *aggregates.EventAggregates) string {
 	if !r.config.Display.Feed.ShowInteractions {
@@ -265,6 +541,15 @@ func (r *Renderer) renderAggregates(agg *aggregates.EventAggregates) string {
 	return r.buildAggregatesString(agg, r.config.Display.Feed.ShowReplies, r.config.Display.Feed.ShowReactions, r.config.Display.Feed.ShowZaps)
 }
 
+// aggregateData describes agg for a template, with the summary the built-in
+// layouts show
+func (r *Renderer) aggregateData(agg *aggregates.EventAggregates) *presentation.AggregateData {
+	if agg == nil || !agg.HasInteractions() {
+		return nil
+	}
+	return presentation.NewAggregateData(agg, strings.TrimSpace(r.renderAggregates(agg)))
+}
+
 // renderAggregatesForDetail renders interaction stats for detail view
 func (r *Renderer) renderAggregatesForDetail(agg *aggregates.EventAggregates) string {
 	return r.buildAggregatesString(agg, r.config.Display.Detail.ShowReplies, r.config.Display.Detail.ShowReactions, r.config.Display.Detail.ShowZaps)
@@ -2,36 +2,67 @@ package gemini
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"net/url"
 	"strings"
 
 	"github.com/nbd-wtf/go-nostr"
+	"github.com/sandwich/nophr/internal/activity"
 	"github.com/sandwich/nophr/internal/aggregates"
+	"github.com/sandwich/nophr/internal/digest"
+	"github.com/sandwich/nophr/internal/entities"
+	"github.com/sandwich/nophr/internal/feeds"
+	"github.com/sandwich/nophr/internal/pages"
 	"github.com/sandwich/nophr/internal/sections"
+	"github.com/sandwich/nophr/internal/translate"
 )
 
+// defaultPageSize applies when a section's page size is unset
+const defaultPageSize = 50
+
 // Router handles URL routing for Gemini requests
 type Router struct {
 	server   *Server
 	host     string
 	port     int
 	renderer *Renderer
+	archives *sections.ArchiveManager
+	feeds    *feeds.Builder
+	digests  *digest.Builder
+	activity *activity.Builder
+	pages    *pages.Set
 }
 
 // NewRouter creates a new router
 func NewRouter(server *Server, host string, port int) *Router {
-	return &Router{
+	r := &Router{
 		server:   server,
 		host:     host,
 		port:     port,
 		renderer: NewRenderer(server.fullConfig, server.storage),
+		archives: sections.NewArchiveManager(server.storage),
+		feeds:    feeds.NewBuilder(server.fullConfig, server.queryHelper, server.sectionManager),
+		digests:  digest.NewBuilder(server.fullConfig, server.storage, server.queryHelper),
+		activity: activity.NewBuilder(server.storage, server.queryHelper),
+		pages:    pages.Load(server.fullConfig),
+	}
+	r.archives.SetMaxPageSize(server.fullConfig.Display.Limits.MaxArchivePageSize)
+	return r
+}
+
+// pageSize returns a section's configured page size, or defaultPageSize if unset
+func (r *Router) pageSize(size int) int {
+	if size < 1 {
+		return defaultPageSize
 	}
+	return size
 }
 
 // Route routes a URL to the appropriate handler
 func (r *Router) Route(u *url.URL) []byte {
-	ctx := context.Background()
+	// Pin the whole render to one point in time so concurrent sync can't shift listings
+	ctx := aggregates.WithSnapshot(context.Background(), nostr.Now())
 
 	// Extract path
 	path := u.Path
@@ -39,8 +70,28 @@ func (r *Router) Route(u *url.URL) []byte {
 		path = "/"
 	}
 
+	// Aliases like /~blog redirect to the path they target
+	if strings.HasPrefix(path, "/~") {
+		return r.redirectAlias(u)
+	}
+
+	// Feeds sit beside the listing they mirror, e.g. /notes/feed.xml
+	if listing, format, ok := feeds.Parse(path); ok {
+		return r.handleFeed(ctx, path, listing, format)
+	}
+
+	// Static pages like /about or /now override sections and defaults
+	if page, ok := r.pages.Lookup(path); ok {
+		return r.handlePage(page)
+	}
+
 	// Check if sections are registered for this path (sections override defaults)
 	if r.server.GetSectionManager() != nil {
+		// A mirror section owns everything below its path
+		if section, parts, ok := r.server.GetSectionManager().MirrorFor(path); ok {
+			return r.handleMirror(ctx, section, parts)
+		}
+
 		sectionsList := r.server.GetSectionManager().GetSectionsByPath(path)
 		if len(sectionsList) > 0 {
 			return r.handleSections(ctx, sectionsList, path, u.Query())
@@ -55,6 +106,15 @@ func (r *Router) Route(u *url.URL) []byte {
 
 	section := parts[0]
 
+	// NIP-19 deep links route to the profile, note or article they point at
+	if entities.IsEntity(section) {
+		return r.routeEntity(ctx, section)
+	}
+
+	if r.server.fullConfig.Display.HidesSection(section) {
+		return FormatErrorResponse(StatusNotFound, fmt.Sprintf("Unknown path: %s", path))
+	}
+
 	switch section {
 	case "notes":
 		return r.handleNotes(ctx, parts[1:], u.Query())
@@ -68,12 +128,21 @@ func (r *Router) Route(u *url.URL) []byte {
 	case "mentions":
 		return r.handleMentions(ctx, parts[1:], u.Query())
 
+	case "archive":
+		return r.handleArchive(ctx, parts[1:])
+
 	case "note":
 		if len(parts) >= 2 {
 			return r.handleNote(ctx, parts[1])
 		}
 		return FormatErrorResponse(StatusNotFound, "Missing note ID")
 
+	case "translate":
+		if len(parts) >= 3 {
+			return r.renderNotePage(ctx, parts[1], parts[2])
+		}
+		return FormatErrorResponse(StatusNotFound, "Missing note ID or language")
+
 	case "thread":
 		if len(parts) >= 2 {
 			return r.handleThread(ctx, parts[1])
@@ -81,17 +150,56 @@ func (r *Router) Route(u *url.URL) []byte {
 		return FormatErrorResponse(StatusNotFound, "Missing thread ID")
 
 	case "profile":
+		if len(parts) >= 3 && parts[2] == "notes" {
+			return r.handleProfileNotes(ctx, parts[1])
+		}
 		if len(parts) >= 2 {
 			return r.handleProfile(ctx, parts[1])
 		}
 		return FormatErrorResponse(StatusNotFound, "Missing pubkey")
 
+	case "addr":
+		return r.handleAddr(ctx, parts[1:])
+
+	case "following":
+		return r.handleFollowing(ctx, parts[1:])
+
+	case "followers":
+		return r.handleFollowers(ctx, parts[1:])
+
+	case "author":
+		return r.handleAuthor(ctx, parts[1:])
+
+	case "tags":
+		return r.handleTags(ctx, parts[1:])
+
+	case "digest":
+		return r.handleDigest(ctx)
+
+	case "stats":
+		return r.handleStats(ctx)
+
+	case "media":
+		if len(parts) >= 2 {
+			return r.handleMedia(ctx, parts[1])
+		}
+		return FormatErrorResponse(StatusNotFound, "Missing media key")
+
 	case "search":
 		return r.handleSearch(ctx, u.Query())
 
+	case "goto":
+		return r.handleGoto(u.RawQuery)
+
 	case "diagnostics":
+		if len(parts) >= 2 && parts[1] == "status" {
+			return r.handleDiagnosticsStatus(ctx)
+		}
 		return r.handleDiagnostics(ctx)
 
+	case "wallet":
+		return r.handleWallet(ctx, parts[1:], u)
+
 	// Legacy support - redirect to new endpoints
 	case "outbox":
 		return r.handleNotes(ctx, parts[1:], u.Query())
@@ -104,9 +212,30 @@ func (r *Router) Route(u *url.URL) []byte {
 	}
 }
 
+// redirectAlias resolves a /~name path and redirects to the path it stands for,
+// keeping any trailing path and query so pagination links still work
+func (r *Router) redirectAlias(u *url.URL) []byte {
+	name, rest, _ := strings.Cut(strings.TrimPrefix(u.Path, "/~"), "/")
+
+	alias, ok := r.server.fullConfig.ResolveAlias(name)
+	if !ok {
+		return FormatErrorResponse(StatusNotFound, fmt.Sprintf("Unknown alias: %s", name))
+	}
+
+	target := r.server.fullConfig.AliasSelector(alias)
+	if rest != "" {
+		target = strings.TrimSuffix(target, "/") + "/" + rest
+	}
+	if u.RawQuery != "" {
+		target += "?" + u.RawQuery
+	}
+
+	return FormatRedirectResponse(target, false)
+}
+
 // handleRoot handles the root/home page
 func (r *Router) handleRoot(ctx context.Context, query url.Values) []byte {
-	gemtext := r.renderer.RenderHome()
+	gemtext := r.renderer.RenderHome(r.server.GetSectionManager().MenuSections(), r.pages.All())
 	return FormatSuccessResponse(gemtext)
 }
 
@@ -119,7 +248,7 @@ func (r *Router) handleOutbox(ctx context.Context, parts []string, query url.Val
 
 	// Query outbox notes
 	queryHelper := r.server.GetQueryHelper()
-	notes, err := queryHelper.GetOutboxNotes(ctx, 50)
+	notes, err := queryHelper.GetOutboxNotes(ctx, r.pageSize(r.server.fullConfig.Display.Limits.PageSizes.Outbox))
 	if err != nil {
 		return FormatErrorResponse(StatusTemporaryFailure, fmt.Sprintf("Error loading outbox: %v", err))
 	}
@@ -136,71 +265,123 @@ func (r *Router) handleInbox(ctx context.Context, parts []string, query url.Valu
 
 // handleNotes handles notes listing (kind 1, non-replies)
 func (r *Router) handleNotes(ctx context.Context, parts []string, query url.Values) []byte {
+	before, remaining, err := aggregates.CursorFromParts(parts)
+	if err != nil {
+		return FormatErrorResponse(StatusBadRequest, err.Error())
+	}
+
 	// Check if viewing a specific note
-	if len(parts) > 0 && parts[0] != "" {
-		return r.handleNote(ctx, parts[0])
+	if len(remaining) > 0 && remaining[0] != "" {
+		return r.handleNote(ctx, remaining[0])
 	}
 
 	// Query notes
 	queryHelper := r.server.GetQueryHelper()
-	notes, err := queryHelper.GetNotes(ctx, 50)
+	page, err := queryHelper.GetNotesPage(ctx, before, r.pageSize(r.server.fullConfig.Display.Limits.PageSizes.Notes))
 	if err != nil {
 		return FormatErrorResponse(StatusTemporaryFailure, fmt.Sprintf("Error loading notes: %v", err))
 	}
 
-	// Render note list
-	gemtext := r.renderer.RenderNoteList(notes, "Notes", r.geminiURL("/"))
-	return FormatSuccessResponse(gemtext)
+	return r.renderListPage(page, "Notes", "/notes")
 }
 
 // handleArticles handles articles listing (kind 30023)
 func (r *Router) handleArticles(ctx context.Context, parts []string, query url.Values) []byte {
+	before, _, err := aggregates.CursorFromParts(parts)
+	if err != nil {
+		return FormatErrorResponse(StatusBadRequest, err.Error())
+	}
+
 	// Query articles
 	queryHelper := r.server.GetQueryHelper()
-	articles, err := queryHelper.GetArticles(ctx, 50)
+	page, err := queryHelper.GetArticlesPage(ctx, before, r.pageSize(r.server.fullConfig.Display.Limits.PageSizes.Articles))
 	if err != nil {
 		return FormatErrorResponse(StatusTemporaryFailure, fmt.Sprintf("Error loading articles: %v", err))
 	}
 
-	// Render article list
-	gemtext := r.renderer.RenderNoteList(articles, "Articles", r.geminiURL("/"))
-	return FormatSuccessResponse(gemtext)
+	return r.renderListPage(page, "Articles", "/articles")
 }
 
 // handleReplies handles replies listing
 func (r *Router) handleReplies(ctx context.Context, parts []string, query url.Values) []byte {
+	before, _, err := aggregates.CursorFromParts(parts)
+	if err != nil {
+		return FormatErrorResponse(StatusBadRequest, err.Error())
+	}
+
 	// Query replies
 	queryHelper := r.server.GetQueryHelper()
-	replies, err := queryHelper.GetReplies(ctx, 50)
+	page, err := queryHelper.GetRepliesPage(ctx, before, r.pageSize(r.server.fullConfig.Display.Limits.PageSizes.Replies))
 	if err != nil {
 		return FormatErrorResponse(StatusTemporaryFailure, fmt.Sprintf("Error loading replies: %v", err))
 	}
 
-	// Render reply list
-	gemtext := r.renderer.RenderNoteList(replies, "Replies", r.geminiURL("/"))
-	return FormatSuccessResponse(gemtext)
+	return r.renderListPage(page, "Replies", "/replies")
 }
 
 // handleMentions handles mentions listing
 func (r *Router) handleMentions(ctx context.Context, parts []string, query url.Values) []byte {
+	before, _, err := aggregates.CursorFromParts(parts)
+	if err != nil {
+		return FormatErrorResponse(StatusBadRequest, err.Error())
+	}
+
 	// Query mentions
 	queryHelper := r.server.GetQueryHelper()
-	mentions, err := queryHelper.GetMentions(ctx, 50)
+	page, err := queryHelper.GetMentionsPage(ctx, before, r.pageSize(r.server.fullConfig.Display.Limits.PageSizes.Mentions))
 	if err != nil {
 		return FormatErrorResponse(StatusTemporaryFailure, fmt.Sprintf("Error loading mentions: %v", err))
 	}
 
-	// Render mention list
-	gemtext := r.renderer.RenderNoteList(mentions, "Mentions", r.geminiURL("/"))
+	return r.renderListPage(page, "Mentions", "/mentions")
+}
+
+// renderListPage renders a keyset-paginated list with Older/Newest links
+func (r *Router) renderListPage(page *aggregates.EventPage, title, basePath string) []byte {
+	var olderURL, newestURL string
+	if page.Next != nil {
+		olderURL = fmt.Sprintf("%s/until/%s", basePath, page.Next)
+	}
+	if page.Before != nil {
+		newestURL = basePath
+	}
+
+	gemtext := r.renderer.RenderNoteListPage(page.Events, title, r.geminiURL("/"), olderURL, newestURL)
 	return FormatSuccessResponse(gemtext)
 }
 
 // handleNote handles displaying a single note
 func (r *Router) handleNote(ctx context.Context, noteID string) []byte {
+	return r.renderNotePage(ctx, noteID, "")
+}
+
+// fetchMissing looks up an event that isn't stored on the relays when
+// behavior.fetch_on_miss is set, and returns it from storage once stored
+func (r *Router) fetchMissing(ctx context.Context, id string) []*nostr.Event {
+	if r.server.fetcher == nil || !r.server.fullConfig.Behavior.FetchOnMiss {
+		return nil
+	}
+	if err := r.server.fetcher.Fetch(ctx, id); err != nil {
+		return nil
+	}
+	events, _ := r.server.GetStorage().QueryEvents(ctx, nostr.Filter{IDs: []string{id}})
+	return events
+}
+
+// renderNotePage renders a note's page, with its translation into lang below
+// the content when lang is set
+func (r *Router) renderNotePage(ctx context.Context, noteID, lang string) []byte {
+	if err := r.server.GetSanitizer().ValidateEventID(noteID); err != nil {
+		return FormatErrorResponse(StatusBadRequest, fmt.Sprintf("Invalid note ID: %v", err))
+	}
+
 	// Query the note
 	events, err := r.server.GetStorage().QueryEvents(ctx, nostr.Filter{
 		IDs: []string{noteID},
 	})
+	if err == nil && len(events) == 0 {
+		events = r.fetchMissing(ctx, noteID)
+	}
 	if err != nil || len(events) == 0 {
 		return FormatErrorResponse(StatusNotFound, fmt.Sprintf("Note not found: %s", noteID))
 	}
@@ -221,13 +402,35 @@ func (r *Router) handleNote(ctx context.Context, noteID string) []byte {
 		}
 	}
 
+	var translation *noteTranslation
+	if lang != "" {
+		text, err := r.server.translator.Translate(ctx, note, lang)
+		if errors.Is(err, translate.ErrUnsupportedLanguage) {
+			return FormatErrorResponse(StatusNotFound, fmt.Sprintf("Translation into %s is not offered", lang))
+		}
+		if err != nil {
+			return FormatErrorResponse(StatusTemporaryFailure, fmt.Sprintf("Translation failed: %v", err))
+		}
+		translation = &noteTranslation{Lang: lang, Text: text}
+	}
+
 	// Render the note
-	gemtext := r.renderer.RenderNote(note, agg, r.geminiURL("/thread/"+noteID), r.geminiURL("/"))
+	gemtext := r.renderer.RenderNote(note, agg, r.isOwner(note.PubKey), r.geminiURL("/thread/"+noteID), r.zapURL(noteID), r.geminiURL("/"), r.translateLinks(noteID), translation)
 	return FormatSuccessResponse(gemtext)
 }
 
+// isOwner reports whether pubkey is the site owner's
+func (r *Router) isOwner(pubkey string) bool {
+	ownerHex, err := r.server.GetQueryHelper().OwnerHex()
+	return err == nil && pubkey == ownerHex
+}
+
 // handleThread handles displaying a thread
 func (r *Router) handleThread(ctx context.Context, rootID string) []byte {
+	if err := r.server.GetSanitizer().ValidateEventID(rootID); err != nil {
+		return FormatErrorResponse(StatusBadRequest, fmt.Sprintf("Invalid thread ID: %v", err))
+	}
+
 	queryHelper := r.server.GetQueryHelper()
 
 	// Query the thread
@@ -237,18 +440,25 @@ func (r *Router) handleThread(ctx context.Context, rootID string) []byte {
 	}
 
 	// Render the thread
-	gemtext := r.renderer.RenderThread(thread.Root, thread.Replies, r.geminiURL("/"))
+	gemtext := r.renderer.RenderThread(ctx, thread, r.geminiURL("/"))
 	return FormatSuccessResponse(gemtext)
 }
 
 // handleProfile handles displaying a profile
 func (r *Router) handleProfile(ctx context.Context, pubkey string) []byte {
+	if err := r.server.GetSanitizer().ValidatePubkey(pubkey); err != nil {
+		return FormatErrorResponse(StatusBadRequest, fmt.Sprintf("Invalid pubkey: %v", err))
+	}
+
 	// Query profile metadata (kind 0)
 	events, err := r.server.GetStorage().QueryEvents(ctx, nostr.Filter{
 		Kinds:   []int{0},
 		Authors: []string{pubkey},
 		Limit:   1,
 	})
+	if err == nil && len(events) == 0 {
+		events = r.placeholderProfile(ctx, pubkey)
+	}
 	if err != nil || len(events) == 0 {
 		return FormatErrorResponse(StatusNotFound, fmt.Sprintf("Profile not found: %s", pubkey))
 	}
@@ -256,10 +466,21 @@ func (r *Router) handleProfile(ctx context.Context, pubkey string) []byte {
 	profile := events[0]
 
 	// Render the profile
-	gemtext := r.renderer.RenderProfile(profile, r.geminiURL("/"))
+	gemtext := r.renderer.RenderProfile(profile, r.geminiURL("/profile/"+pubkey+"/notes"), r.geminiURL("/"))
 	return FormatSuccessResponse(gemtext)
 }
 
+// placeholderProfile stands in an empty kind 0 for an author whose profile
+// hasn't been synced but who has other events stored, so their profile page
+// and deep links work; it returns nil for an author with nothing stored
+func (r *Router) placeholderProfile(ctx context.Context, pubkey string) []*nostr.Event {
+	events, err := r.server.GetStorage().QueryEvents(ctx, nostr.Filter{Authors: []string{pubkey}, Limit: 1})
+	if err != nil || len(events) == 0 {
+		return nil
+	}
+	return []*nostr.Event{{Kind: 0, PubKey: pubkey, Content: "{}", CreatedAt: events[0].CreatedAt}}
+}
+
 // handleSearch handles search functionality
 func (r *Router) handleSearch(ctx context.Context, query url.Values) []byte {
 	searchQuery := query.Get("q")
@@ -269,11 +490,17 @@ func (r *Router) handleSearch(ctx context.Context, query url.Values) []byte {
 		return FormatInputResponse("Enter search query:", false)
 	}
 
+	// A pasted nostr: URI opens what it points at rather than searching for it
+	if entity, ok := entities.ParseURI(searchQuery); ok {
+		return FormatRedirectResponse("/"+entity, false)
+	}
+
 	// Perform NIP-50 search
+	limit := r.server.GetSanitizer().CapResultLimit(50, r.server.fullConfig.Display.Limits.MaxSearchResults)
 	events, err := r.server.GetStorage().QueryEventsWithSearch(ctx, nostr.Filter{
 		Search: searchQuery,
 		Kinds:  []int{0, 1, 30023}, // Profiles, notes, articles
-		Limit:  50,
+		Limit:  limit,
 	})
 
 	gemtext := "# Search Results\n\n"
@@ -300,7 +527,7 @@ func (r *Router) handleSearch(ctx context.Context, query url.Values) []byte {
 		case 0: // Profile
 			gemtext += fmt.Sprintf("=> %s [Profile] %s\n",
 				r.geminiURL(fmt.Sprintf("/profile/%s", event.PubKey)),
-				truncatePubkey(event.PubKey))
+				r.renderer.authorLabel(ctx, event.PubKey))
 
 		case 1: // Note
 			summary := r.renderer.GetSummary(event.Content, 100)
@@ -325,19 +552,41 @@ func (r *Router) handleSearch(ctx context.Context, query url.Values) []byte {
 
 // handleDiagnostics handles the diagnostics page
 func (r *Router) handleDiagnostics(ctx context.Context) []byte {
-	gemtext := "# Diagnostics\n\n"
-	gemtext += "## Server Status\n\n"
-	gemtext += "* Server: Running\n"
-	gemtext += fmt.Sprintf("* Host: %s\n", r.host)
-	gemtext += fmt.Sprintf("* Port: %d\n", r.port)
-	gemtext += "\n## Storage\n\n"
-	gemtext += "* Status: Connected\n"
+	collector := r.server.GetDiagnostics()
+	if collector == nil {
+		gemtext := "# Diagnostics\n\n"
+		gemtext += "Diagnostics are not available.\n\n"
+		gemtext += fmt.Sprintf("=> %s Back to Home\n", r.geminiURL("/"))
+		return FormatSuccessResponse(gemtext)
+	}
+
+	diag, err := collector.CollectAll(ctx)
+	if err != nil {
+		return FormatErrorResponse(StatusTemporaryFailure, fmt.Sprintf("Failed to collect diagnostics: %v", err))
+	}
+
+	gemtext := diag.FormatAsGemtext()
 	gemtext += "\n"
+	gemtext += fmt.Sprintf("=> %s Machine-readable status\n", r.geminiURL("/diagnostics/status"))
 	gemtext += fmt.Sprintf("=> %s Back to Home\n", r.geminiURL("/"))
 
 	return FormatSuccessResponse(gemtext)
 }
 
+// handleDiagnosticsStatus serves capabilities as "key: value" text for monitoring scripts
+func (r *Router) handleDiagnosticsStatus(ctx context.Context) []byte {
+	collector := r.server.GetDiagnostics()
+	if collector == nil {
+		return FormatErrorResponse(StatusNotFound, "Diagnostics are not available")
+	}
+
+	caps, err := collector.CollectCapabilities(ctx)
+	if err != nil {
+		return FormatErrorResponse(StatusTemporaryFailure, fmt.Sprintf("Failed to collect diagnostics: %v", err))
+	}
+	return FormatResponse(StatusSuccess, "text/plain; charset=utf-8", caps.FormatAsKeyValue())
+}
+
 // geminiURL constructs a gemini:// URL for the given path
 func (r *Router) geminiURL(path string) string {
 	if r.port == 1965 {
@@ -350,10 +599,16 @@ func (r *Router) geminiURL(path string) string {
 func (r *Router) handleSections(ctx context.Context, sectionsList []*sections.Section, path string, query url.Values) []byte {
 	var gemtext strings.Builder
 
+	// Query all sections concurrently (always the newest page for multi-section views)
+	names := make([]string, len(sectionsList))
+	for i, section := range sectionsList {
+		names[i] = section.Name
+	}
+	pages, errs := r.server.GetSectionManager().GetPages(ctx, names)
+
 	// Render each section in order
 	for i, section := range sectionsList {
-		// Get section page (always page 1 for multi-section views)
-		sectionPage, err := r.server.GetSectionManager().GetPage(ctx, section.Name, 1)
+		sectionPage, err := pages[i], errs[i]
 		if err != nil {
 			gemtext.WriteString(fmt.Sprintf("# Error loading section %s\n\n", section.Name))
 			gemtext.WriteString(fmt.Sprintf("Error: %v\n\n", err))
@@ -369,7 +624,9 @@ func (r *Router) handleSections(ctx context.Context, sectionsList []*sections.Se
 		}
 
 		// Render events from section
-		if len(sectionPage.Events) > 0 {
+		if section.IsPlanet() && len(sectionPage.Events) > 0 {
+			r.writePlanetEntries(&gemtext, sectionPage)
+		} else if len(sectionPage.Events) > 0 {
 			for _, event := range sectionPage.Events {
 				// Extract first line for display
 				content := event.Content
@@ -383,16 +640,20 @@ func (r *Router) handleSections(ctx context.Context, sectionsList []*sections.Se
 				// Add author and timestamp if configured
 				if section.ShowAuthors && section.ShowDates {
 					gemtext.WriteString(fmt.Sprintf("%s - %s\n",
-						truncatePubkey(event.PubKey),
+						r.renderer.authorLabel(ctx, event.PubKey),
 						formatTimestamp(event.CreatedAt)))
 				} else if section.ShowAuthors {
-					gemtext.WriteString(fmt.Sprintf("%s\n", truncatePubkey(event.PubKey)))
+					gemtext.WriteString(fmt.Sprintf("%s\n", r.renderer.authorLabel(ctx, event.PubKey)))
 				} else if section.ShowDates {
 					gemtext.WriteString(fmt.Sprintf("%s\n", formatTimestamp(event.CreatedAt)))
 				}
 
 				// Add the clickable link
-				gemtext.WriteString(fmt.Sprintf("=> %s %s\n\n", r.geminiURL(fmt.Sprintf("/note/%s", event.ID)), linkText))
+				gemtext.WriteString(fmt.Sprintf("=> %s %s\n", r.geminiURL(fmt.Sprintf("/note/%s", event.ID)), linkText))
+				if section.MediaLinks {
+					r.writeMediaLinks(&gemtext, event)
+				}
+				gemtext.WriteString("\n")
 			}
 		} else {
 			gemtext.WriteString("No content yet.\n\n")
@@ -12,9 +12,17 @@ import (
 	"time"
 
 	"github.com/sandwich/nophr/internal/aggregates"
+	"github.com/sandwich/nophr/internal/cache"
 	"github.com/sandwich/nophr/internal/config"
+	"github.com/sandwich/nophr/internal/idle"
+	"github.com/sandwich/nophr/internal/mediaproxy"
+	nostrclient "github.com/sandwich/nophr/internal/nostr"
+	"github.com/sandwich/nophr/internal/nwc"
+	"github.com/sandwich/nophr/internal/ops"
 	"github.com/sandwich/nophr/internal/sections"
+	"github.com/sandwich/nophr/internal/security"
 	"github.com/sandwich/nophr/internal/storage"
+	"github.com/sandwich/nophr/internal/translate"
 )
 
 // Server implements a Gemini protocol server
@@ -27,6 +35,21 @@ type Server struct {
 	queryHelper    *aggregates.QueryHelper
 	sectionManager *sections.Manager
 	tlsConfig      *tls.Config
+	diagnostics    *ops.DiagnosticsCollector
+	rateLimiter    *security.ClientLimiter
+	idle           *idle.Monitor
+	fetcher        *nostrclient.Fetcher
+	media          *mediaproxy.Proxy
+	translator     *translate.Translator
+	translateLimit *security.ClientLimiter // Per-IP limit on translation pages, nil when translation is off
+	sanitizer      *security.InputSanitizer
+	publisher      NotePublisher
+	wallet         *nwc.Client
+	zapSigner      ZapSigner
+	pendingZaps    map[string]*pendingZap // Checked invoices by confirmation token
+	zapsMu         sync.Mutex
+	cache          cache.Cache
+	cacheTTL       time.Duration
 
 	listener net.Listener
 	wg       sync.WaitGroup
@@ -46,6 +69,12 @@ func New(cfg *config.GeminiProtocol, fullCfg *config.Config, st *storage.Storage
 		ctx:         ctx,
 		cancel:      cancel,
 		queryHelper: aggregates.NewQueryHelper(st, fullCfg, aggMgr),
+		sanitizer:   security.NewInputSanitizer(),
+		media:       mediaproxy.New(&fullCfg.Rendering.MediaProxy),
+		translator:  translate.New(&fullCfg.Rendering.Translation, st),
+	}
+	if translation := fullCfg.Rendering.Translation; translation.Enabled && translation.RequestsPerMinute > 0 {
+		s.translateLimit = security.NewClientLimiter(translation.RequestsPerMinute, translation.RequestsPerMinute, 0)
 	}
 
 	// Initialize sections manager (opt-in for custom filtered views)
@@ -96,6 +125,9 @@ func (s *Server) Stop() error {
 	}
 
 	s.wg.Wait()
+	if s.translateLimit != nil {
+		s.translateLimit.Close()
+	}
 	return nil
 }
 
@@ -153,17 +185,82 @@ func (s *Server) handleConnection(conn net.Conn) {
 		return
 	}
 
-	// Validate scheme
-	if parsedURL.Scheme != "gemini" {
+	// Validate scheme; Titan uploads carry their parameters in the path
+	var titan *titanRequest
+	if parsedURL.Scheme == "titan" && s.config.Titan.Enabled {
+		titan, err = parseTitanPath(parsedURL.Path)
+		if err != nil {
+			s.sendResponse(conn, StatusBadRequest, fmt.Sprintf("Invalid Titan request: %v", err), "")
+			return
+		}
+		parsedURL.Path = titan.Path
+	} else if parsedURL.Scheme != "gemini" {
 		s.sendResponse(conn, StatusProxyRequestRefused, "Only gemini:// URLs supported", "")
 		return
 	}
 
+	// Reject malformed paths and queries before they reach the router
+	if _, err := s.sanitizer.SanitizeAndValidatePath(parsedURL.Path); err != nil {
+		s.sendResponse(conn, StatusBadRequest, fmt.Sprintf("Invalid path: %v", err), "")
+		return
+	}
+	if parsedURL.RawQuery != "" {
+		query, err := url.QueryUnescape(parsedURL.RawQuery)
+		if err == nil {
+			_, err = s.sanitizer.SanitizeAndValidateQuery(query)
+		}
+		if err != nil {
+			s.sendResponse(conn, StatusBadRequest, fmt.Sprintf("Invalid query: %v", err), "")
+			return
+		}
+	}
+
 	// Log request
 	fmt.Printf("Gemini request: %s from %s\n", request, conn.RemoteAddr())
+	s.idle.Touch()
+
+	// Ask rate limited clients to slow down (meta is the wait in seconds)
+	if allowed, retryAfter := s.checkRateLimit(conn); !allowed {
+		s.sendResponse(conn, StatusSlowDown, fmt.Sprintf("%d", int(retryAfter.Seconds()+0.5)), "")
+		return
+	}
 
-	// Route request
-	response := s.router.Route(parsedURL)
+	// Translations call out to the provider, so each client gets a few a minute
+	if allowed, retryAfter := s.checkTranslateLimit(conn, parsedURL.Path); !allowed {
+		s.sendResponse(conn, StatusSlowDown, fmt.Sprintf("%d", int(retryAfter.Seconds()+0.5)), "")
+		return
+	}
+
+	if titan != nil {
+		s.handleTitan(conn, reader, titan)
+		return
+	}
+
+	// Protected paths need a recognised client certificate
+	if status, meta, ok := s.authorize(conn, parsedURL.Path); !ok {
+		s.sendResponse(conn, status, meta, "")
+		return
+	}
+
+	// The wallet spends the owner's sats, so it always needs an owner certificate
+	if isWalletPath(parsedURL.Path) {
+		if status, meta, ok := s.authorizeLevel(conn, config.AccessLevelOwner); !ok {
+			s.sendResponse(conn, status, meta, "")
+			return
+		}
+	}
+
+	// Route request; the owner-only trash, drafts and admin log are never cached
+	var response []byte
+	if isTrashPath(parsedURL.Path) {
+		response = s.handleTrash(conn, parsedURL.Path)
+	} else if isDraftsPath(parsedURL.Path) {
+		response = s.handleDrafts(conn, parsedURL)
+	} else if isAdminLogPath(parsedURL.Path) {
+		response = s.handleAdminLog(conn, parsedURL.Path)
+	} else {
+		response = s.cachedRoute(parsedURL)
+	}
 
 	// Write response
 	conn.SetWriteDeadline(time.Now().Add(30 * time.Second))
@@ -173,12 +270,62 @@ func (s *Server) handleConnection(conn net.Conn) {
 	}
 }
 
+// cachedRoute serves a request from the response cache, routing and storing it on a miss.
+// Only successful responses are cached, and diagnostics and proxied media never are.
+func (s *Server) cachedRoute(u *url.URL) []byte {
+	path := u.Path
+	if path == "" {
+		path = "/"
+	}
+	if s.cache == nil || strings.HasPrefix(path, "/diagnostics") || isWalletPath(path) || strings.HasPrefix(path, mediaproxy.PathPrefix) {
+		return s.router.Route(u)
+	}
+
+	key := cache.GeminiKey(path, u.RawQuery)
+	if data, found, err := s.cache.Get(s.ctx, key); err == nil && found {
+		return data
+	}
+
+	response := s.router.Route(u)
+	if strings.HasPrefix(string(response), "20") {
+		if err := s.cache.Set(s.ctx, key, response, s.cacheTTL); err != nil {
+			fmt.Printf("Gemini cache write failed: %v\n", err)
+		}
+	}
+	return response
+}
+
+// Render returns the response for a path without a client connection,
+// bypassing the cache (used by the static export)
+func (s *Server) Render(path string) []byte {
+	return s.router.Route(&url.URL{Path: path})
+}
+
 // sendResponse sends a Gemini response
 func (s *Server) sendResponse(conn net.Conn, status Status, meta string, body string) {
 	response := FormatResponse(status, meta, body)
 	conn.Write(response)
 }
 
+// checkRateLimit reports whether the client may be served
+func (s *Server) checkRateLimit(conn net.Conn) (bool, time.Duration) {
+	if s.rateLimiter == nil {
+		return true, 0
+	}
+
+	ip := security.ClientIP(conn.RemoteAddr())
+	allowed, retryAfter := s.rateLimiter.Check(ip)
+	if !allowed {
+		fmt.Printf("Gemini rate limit exceeded for %s\n", ip)
+	}
+	return allowed, retryAfter
+}
+
+// GetSanitizer returns the request input sanitizer
+func (s *Server) GetSanitizer() *security.InputSanitizer {
+	return s.sanitizer
+}
+
 // GetStorage returns the storage instance
 func (s *Server) GetStorage() *storage.Storage {
 	return s.storage
@@ -203,3 +350,48 @@ func (s *Server) GetQueryHelper() *aggregates.QueryHelper {
 func (s *Server) GetSectionManager() *sections.Manager {
 	return s.sectionManager
 }
+
+// SetSectionManager replaces the section manager, so every protocol can share
+// the one loaded from config
+func (s *Server) SetSectionManager(m *sections.Manager) {
+	s.sectionManager = m
+	s.router = NewRouter(s, s.host, s.config.Port)
+}
+
+// SetDiagnostics sets the diagnostics collector used for the diagnostics page
+func (s *Server) SetDiagnostics(dc *ops.DiagnosticsCollector) {
+	s.diagnostics = dc
+}
+
+// GetDiagnostics returns the diagnostics collector (nil if not configured)
+func (s *Server) GetDiagnostics() *ops.DiagnosticsCollector {
+	return s.diagnostics
+}
+
+// SetRateLimiter sets the per-IP rate limiter (nil disables rate limiting)
+func (s *Server) SetRateLimiter(rl *security.ClientLimiter) {
+	s.rateLimiter = rl
+}
+
+// SetFetcher sets the fetcher that looks up notes missing from storage when
+// behavior.fetch_on_miss is set (nil disables it)
+func (s *Server) SetFetcher(f *nostrclient.Fetcher) {
+	s.fetcher = f
+}
+
+// SetMediaProxy replaces the server's own media proxy with one shared by
+// every server, so media linked on any of them can be fetched from all
+func (s *Server) SetMediaProxy(p *mediaproxy.Proxy) {
+	s.media = p
+}
+
+// SetIdleMonitor sets the monitor told about each request (nil disables idle shedding)
+func (s *Server) SetIdleMonitor(m *idle.Monitor) {
+	s.idle = m
+}
+
+// SetCache sets the response cache and how long rendered pages are kept (nil disables caching)
+func (s *Server) SetCache(c cache.Cache, ttl time.Duration) {
+	s.cache = c
+	s.cacheTTL = ttl
+}
@@ -4,14 +4,20 @@ import (
 	"bufio"
 	"context"
 	"crypto/tls"
+	"crypto/x509"
 	"fmt"
 	"net"
+	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 	"time"
 
+	"github.com/nbd-wtf/go-nostr"
 	"github.com/sandwich/nophr/internal/aggregates"
 	"github.com/sandwich/nophr/internal/config"
+	"github.com/sandwich/nophr/internal/conformance"
+	"github.com/sandwich/nophr/internal/sections"
 	"github.com/sandwich/nophr/internal/storage"
 )
 
@@ -210,6 +216,232 @@ func TestGeminiResponseFormat(t *testing.T) {
 	})
 }
 
+func TestClientCertAccess(t *testing.T) {
+	readerCert := &x509.Certificate{Raw: []byte("reader")}
+	ownerCert := &x509.Certificate{Raw: []byte("owner")}
+
+	access := &config.GeminiAccess{
+		Identities: []config.ClientIdentity{
+			{Name: "friend", Fingerprint: CertFingerprint(readerCert), Level: config.AccessLevelReader},
+			{Name: "me", Fingerprint: strings.ToUpper(CertFingerprint(ownerCert)), Level: config.AccessLevelOwner},
+		},
+		Paths: []config.PathAccess{
+			{Prefix: "/inbox", Level: config.AccessLevelReader},
+			{Prefix: "/drafts", Level: config.AccessLevelOwner},
+			{Prefix: "/drafts/public", Level: config.AccessLevelAny},
+		},
+	}
+
+	levels := map[string]string{
+		"/":                  "",
+		"/notes":             "",
+		"/inboxes":           "",
+		"/inbox":             config.AccessLevelReader,
+		"/inbox/page":        config.AccessLevelReader,
+		"/drafts/1":          config.AccessLevelOwner,
+		"/drafts/public/faq": config.AccessLevelAny,
+	}
+	for path, want := range levels {
+		if got := requiredLevel(access, path); got != want {
+			t.Errorf("requiredLevel(%s) = %q, want %q", path, got, want)
+		}
+	}
+
+	if got := clientLevel(access, readerCert); got != config.AccessLevelReader {
+		t.Errorf("Expected reader level, got %s", got)
+	}
+	if got := clientLevel(access, ownerCert); got != config.AccessLevelOwner {
+		t.Errorf("Expected owner level (fingerprint case-insensitive), got %s", got)
+	}
+	if got := clientLevel(access, &x509.Certificate{Raw: []byte("stranger")}); got != config.AccessLevelAny {
+		t.Errorf("Expected unknown cert to get any level, got %s", got)
+	}
+}
+
+func TestTitanUpload(t *testing.T) {
+	req, err := parseTitanPath("/publish;mime=text/plain;size=12;token=secret")
+	if err != nil {
+		t.Fatalf("parseTitanPath() error = %v", err)
+	}
+	if req.Path != "/publish" || req.Mime != "text/plain" || req.Size != 12 || req.Token != "secret" {
+		t.Errorf("Unexpected Titan request: %+v", req)
+	}
+
+	req, err = parseTitanPath("/publish;size=5")
+	if err != nil {
+		t.Fatalf("parseTitanPath() error = %v", err)
+	}
+	if req.Mime != "text/gemini" {
+		t.Errorf("Expected default mime text/gemini, got %s", req.Mime)
+	}
+
+	req, err = parseTitanPath("/publish;size=5;nonce=abc123;ts=1700000000")
+	if err != nil || req.Nonce != "abc123" || req.Timestamp != 1700000000 {
+		t.Errorf("parseTitanPath() = %+v, %v; want nonce and ts", req, err)
+	}
+
+	for _, bad := range []string{"/publish", "/publish;mime=text/plain", "/publish;size=-1", "/publish;size=abc", "/publish;size=5;ts=soon", "/publish;size=5;nonce="} {
+		if _, err := parseTitanPath(bad); err == nil {
+			t.Errorf("parseTitanPath(%s) expected error", bad)
+		}
+	}
+
+	// Uploads without an owner certificate are refused before the body is read
+	s := &Server{config: &config.GeminiProtocol{Titan: config.GeminiTitan{Enabled: true, MaxSize: 100}}}
+	responses := map[string]Status{
+		"/publish": StatusClientCertRequired,
+		"/notes":   StatusNotFound,
+	}
+	for path, want := range responses {
+		client, server := net.Pipe()
+		go func() {
+			s.handleTitan(server, bufio.NewReader(server), &titanRequest{Path: path, Mime: "text/plain", Size: 5})
+			server.Close()
+		}()
+
+		line, _ := bufio.NewReader(client).ReadString('\n')
+		client.Close()
+		if !strings.HasPrefix(line, fmt.Sprintf("%d ", want)) {
+			t.Errorf("Titan upload to %s: expected status %d, got %q", path, want, line)
+		}
+	}
+}
+
+func TestSubmissionReplay(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	st, err := storage.New(ctx, &config.Storage{Driver: "sqlite", SQLitePath: filepath.Join(t.TempDir(), "test.db")})
+	if err != nil {
+		t.Fatalf("Failed to create storage: %v", err)
+	}
+	defer st.Close()
+
+	s := &Server{
+		config:  &config.GeminiProtocol{Titan: config.GeminiTitan{NonceWindowSeconds: 300, UploadsPerHour: 3}},
+		storage: st,
+		ctx:     ctx,
+	}
+	now := time.Now().Unix()
+
+	admit := func(fingerprint, nonce string, ts int64, body string) Status {
+		_, status, _, _ := s.admitSubmission(fingerprint, nonce, ts, []byte(body))
+		return status
+	}
+
+	steps := []struct {
+		name        string
+		fingerprint string
+		nonce       string
+		ts          int64
+		body        string
+		want        Status
+	}{
+		{"fresh nonce", "alice", "n1", now, "hello", StatusSuccess},
+		{"replayed nonce", "alice", "n1", now, "hello", StatusBadRequest},
+		{"nonces are per certificate", "bob", "n1", now, "hello", StatusSuccess},
+		{"stale timestamp", "alice", "n2", now - 600, "hello", StatusBadRequest},
+		{"future timestamp", "alice", "n2", now + 600, "hello", StatusBadRequest},
+		{"body hash stands in for a nonce", "alice", "", 0, "gm", StatusSuccess},
+		{"identical upload replayed", "alice", "", 0, "gm", StatusBadRequest},
+		{"last upload under quota", "alice", "n3", now, "third", StatusSuccess},
+		{"over quota", "alice", "n4", now, "fourth", StatusSlowDown},
+	}
+	for _, step := range steps {
+		if got := admit(step.fingerprint, step.nonce, step.ts, step.body); got != step.want {
+			t.Errorf("%s: status %d, want %d", step.name, got, step.want)
+		}
+	}
+
+	// A failed publish releases its nonce for a retry
+	nonce, _, _, ok := s.admitSubmission("carol", "retry", now, []byte("x"))
+	if !ok {
+		t.Fatal("Expected first submission to be admitted")
+	}
+	s.forgetSubmission("carol", nonce)
+	if got := admit("carol", "retry", now, "x"); got != StatusSuccess {
+		t.Errorf("Retry after a failed publish: status %d, want %d", got, StatusSuccess)
+	}
+
+	s.config.Titan.RequireNonce = true
+	if got := admit("dave", "", 0, "no nonce"); got != StatusBadRequest {
+		t.Errorf("Upload without nonce when required: status %d, want %d", got, StatusBadRequest)
+	}
+}
+
+func TestWalletHelpers(t *testing.T) {
+	for path, want := range map[string]bool{
+		"/wallet":          true,
+		"/wallet/zap/abcd": true,
+		"/wallets":         false,
+		"/notes":           false,
+	} {
+		if got := isWalletPath(path); got != want {
+			t.Errorf("isWalletPath(%s) = %v, want %v", path, got, want)
+		}
+	}
+
+	amounts := map[string]int64{"": 21, " 500 ": 500, "1": 1}
+	for input, want := range amounts {
+		got, err := parseZapAmount(input, 21, 1000)
+		if err != nil || got != want {
+			t.Errorf("parseZapAmount(%q) = %d, %v; want %d", input, got, err, want)
+		}
+	}
+	for _, bad := range []string{"0", "-5", "lots", "1.5", "1001"} {
+		if _, err := parseZapAmount(bad, 21, 1000); err == nil {
+			t.Errorf("parseZapAmount(%q) expected error", bad)
+		}
+	}
+
+	for sats, want := range map[int64]string{0: "0", 999: "999", 1000: "1,000", 1234567: "1,234,567"} {
+		if got := formatSats(sats); got != want {
+			t.Errorf("formatSats(%d) = %s, want %s", sats, got, want)
+		}
+	}
+}
+
+func TestPendingZaps(t *testing.T) {
+	s := &Server{}
+	s.SetWallet(nil, nil)
+
+	token, err := s.holdZap(&pendingZap{noteID: "abcd", sats: 21, expires: time.Now().Add(zapConfirmWindow)})
+	if err != nil {
+		t.Fatalf("holdZap failed: %v", err)
+	}
+	if zap := s.takeZap(token); zap == nil || zap.sats != 21 {
+		t.Fatalf("takeZap(%s) = %+v, want the held zap", token, zap)
+	}
+	if zap := s.takeZap(token); zap != nil {
+		t.Error("A confirmation token paid twice")
+	}
+
+	expired, _ := s.holdZap(&pendingZap{noteID: "abcd", sats: 21, expires: time.Now().Add(-time.Second)})
+	if zap := s.takeZap(expired); zap != nil {
+		t.Error("An expired zap was confirmed")
+	}
+}
+
+func TestGoto(t *testing.T) {
+	r := &Router{}
+
+	tests := map[string]string{
+		"nostr:npub1abc":            "30 /npub1abc\r\n",
+		"nostr%3Anevent1abc":        "30 /nevent1abc\r\n",
+		"%20NOSTR:naddr1abc%20":     "30 /naddr1abc\r\n",
+		"nostr:nsec1abc":            "59 Not a nostr: link\r\n",
+		"https%3A%2F%2Fexample.com": "59 Not a nostr: link\r\n",
+	}
+	for input, want := range tests {
+		if got := string(r.handleGoto(input)); got != want {
+			t.Errorf("handleGoto(%q) = %q, want %q", input, got, want)
+		}
+	}
+
+	if got := string(r.handleGoto("")); !strings.HasPrefix(got, "10 ") {
+		t.Errorf("Expected an input prompt without a query, got %q", got)
+	}
+}
+
 func TestRendererOutput(t *testing.T) {
 	cfg := &config.Config{
 		Storage: config.Storage{
@@ -238,7 +470,7 @@ func TestRendererOutput(t *testing.T) {
 
 	// Test home rendering
 	t.Run("HomeRendering", func(t *testing.T) {
-		home := renderer.RenderHome()
+		home := renderer.RenderHome(nil, nil)
 
 		if !strings.Contains(home, "# nophr") {
 			t.Errorf("Home should contain title")
@@ -265,6 +497,34 @@ func TestRendererOutput(t *testing.T) {
 	})
 }
 
+func TestTemplates(t *testing.T) {
+	dir := t.TempDir()
+	homeFile := filepath.Join(dir, "home.gmi")
+	os.WriteFile(homeFile, []byte("# {{.Site.Title}}\n{{range .Links}}=> {{.URL}} {{.Title}}\n{{end}}"), 0644)
+	itemFile := filepath.Join(dir, "item.gmi")
+	os.WriteFile(itemFile, []byte("=> {{.Event.URL}} {{firstLine .Event.Content}} ({{.Event.Author}}){{with .Aggregates}} {{.Replies}} replies{{end}}\n"), 0644)
+
+	cfg := config.Default()
+	cfg.Site.Title = "My capsule"
+	cfg.Presentation.Templates.Gemini = config.TemplateSet{Home: homeFile, ListItem: itemFile}
+	renderer := NewRenderer(cfg, nil)
+
+	home := renderer.RenderHome(nil, nil)
+	if !strings.HasPrefix(home, "# My capsule\n=> /notes Notes\n") || strings.Contains(home, "Powered by nophr") {
+		t.Errorf("home not rendered by the template:\n%s", home)
+	}
+
+	event := &nostr.Event{CreatedAt: nostr.Now(), Kind: 1, Content: "\nFirst line\nsecond", Tags: nostr.Tags{}}
+	event.Sign(nostr.GeneratePrivateKey())
+	list := renderer.RenderNoteList([]*aggregates.EnrichedEvent{{
+		Event:      event,
+		Aggregates: &aggregates.EventAggregates{ReplyCount: 2},
+	}}, "Notes", "/")
+	if want := "=> /note/" + event.ID + " First line (" + event.PubKey[:8]; !strings.Contains(list, want) || !strings.Contains(list, ") 2 replies\n") {
+		t.Errorf("list item not rendered by the template, want %q:\n%s", want, list)
+	}
+}
+
 // Helper function to send a Gemini request
 func sendGeminiRequest(t *testing.T, port int, url string) string {
 	// Create TLS config that accepts self-signed certs
@@ -306,3 +566,62 @@ func sendGeminiRequest(t *testing.T, port int, url string) string {
 
 	return response.String()
 }
+
+func TestArchivePageSizeCap(t *testing.T) {
+	fixture := conformance.NewFixture()
+	cfg := conformance.Config(t, fixture)
+	cfg.Display.Limits.MaxArchivePageSize = 1
+	st := conformance.Setup(t, fixture, cfg)
+
+	server, err := New(&cfg.Protocols.Gemini, cfg, st, "localhost", aggregates.NewManager(st, cfg))
+	if err != nil {
+		t.Fatalf("Failed to create server: %v", err)
+	}
+	// Routers are rebuilt with the section manager and keep the cap
+	server.SetSectionManager(sections.NewManager(st))
+
+	section := &sections.Section{Filters: sections.FilterSet{Kinds: []int{1}, Authors: []string{fixture.Owner}}, Limit: 20}
+	page, err := server.router.archives.GetArchivePage(context.Background(), section, 2024, time.June, 0, 1)
+	if err != nil {
+		t.Fatalf("GetArchivePage() error = %v", err)
+	}
+	if len(page.Events) != 1 || page.TotalItems < 2 {
+		t.Errorf("Expected 1 of several events per page with max_archive_page_size 1, got %d of %d", len(page.Events), page.TotalItems)
+	}
+}
+
+func TestTranslateLimit(t *testing.T) {
+	fixture := conformance.NewFixture()
+	cfg := conformance.Config(t, fixture)
+	cfg.Rendering.Translation.Enabled = true
+	cfg.Rendering.Translation.Provider = config.TranslationCommand
+	cfg.Rendering.Translation.Command = []string{"cat"}
+	cfg.Rendering.Translation.RequestsPerMinute = 2
+	st := conformance.Setup(t, fixture, cfg)
+
+	server, err := New(&cfg.Protocols.Gemini, cfg, st, "localhost", aggregates.NewManager(st, cfg))
+	if err != nil {
+		t.Fatalf("Failed to create server: %v", err)
+	}
+	defer server.Stop()
+
+	conn, other := net.Pipe()
+	defer conn.Close()
+	defer other.Close()
+
+	// Other pages don't count towards the limit
+	for range 5 {
+		if allowed, _ := server.checkTranslateLimit(conn, "/note/"+fixture.Note.ID); !allowed {
+			t.Fatal("Expected note pages not to be limited")
+		}
+	}
+	for i := range 3 {
+		allowed, retryAfter := server.checkTranslateLimit(conn, translatePath+"/"+fixture.Note.ID+"/en")
+		if want := i < 2; allowed != want {
+			t.Errorf("Translation %d allowed = %v, want %v", i+1, allowed, want)
+		}
+		if !allowed && retryAfter <= 0 {
+			t.Error("Expected a wait for the limited client")
+		}
+	}
+}
@@ -0,0 +1,32 @@
+package gemini
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/sandwich/nophr/internal/activity"
+)
+
+// handleStats renders the owner's posting activity as a heatmap of weeks by weekdays
+func (r *Router) handleStats(ctx context.Context) []byte {
+	heatmap, err := r.activity.Current(ctx, time.Now())
+	if err != nil {
+		return FormatErrorResponse(StatusTemporaryFailure, fmt.Sprintf("Error loading activity: %v", err))
+	}
+
+	var sb strings.Builder
+	sb.WriteString("# Stats\n\n")
+	sb.WriteString("## Posting activity\n\n")
+	sb.WriteString("```Posting activity heatmap, one column per week\n")
+	for _, line := range heatmap.Render(activity.BlockCells) {
+		sb.WriteString(line + "\n")
+	}
+	sb.WriteString("```\n\n")
+	sb.WriteString(heatmap.Summary() + "\n\n")
+	sb.WriteString(fmt.Sprintf("=> %s Archive\n", r.geminiURL("/archive")))
+	sb.WriteString(fmt.Sprintf("=> %s Back to Home\n", r.geminiURL("/")))
+
+	return FormatSuccessResponse(sb.String())
+}
@@ -0,0 +1,82 @@
+package gemini
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"time"
+)
+
+// maxNonceLength is the longest nonce a client may send
+const maxNonceLength = 128
+
+// submissionQuotaWindow is the period uploads_per_hour is counted over
+const submissionQuotaWindow = time.Hour
+
+// admitSubmission guards write endpoints against replayed and bulk content
+// from one client certificate. A timestamp must be within the nonce window of
+// the server clock, the certificate must be under its hourly quota, and the
+// nonce must not have been used before. Without a nonce the body's hash
+// stands in for one, so an identical upload can't be replayed either. It
+// returns the nonce that was recorded.
+func (s *Server) admitSubmission(fingerprint, nonce string, ts int64, body []byte) (string, Status, string, bool) {
+	titan := &s.config.Titan
+	window := time.Duration(titan.NonceWindowSeconds) * time.Second
+	now := time.Now()
+
+	if titan.RequireNonce && (nonce == "" || ts == 0) {
+		return "", StatusBadRequest, "Uploads need nonce and ts parameters", false
+	}
+	if ts != 0 {
+		if drift := now.Sub(time.Unix(ts, 0)); drift > window || drift < -window {
+			return "", StatusBadRequest, "Upload timestamp outside the accepted window", false
+		}
+	}
+
+	if nonce == "" {
+		sum := sha256.Sum256(body)
+		nonce = "sha256:" + hex.EncodeToString(sum[:])
+	} else {
+		nonce = "nonce:" + nonce
+	}
+
+	if s.storage == nil {
+		return nonce, StatusSuccess, "", true
+	}
+
+	// Nonces are kept until a timestamp that far in the future has expired
+	retain := max(2*window, submissionQuotaWindow)
+	if _, err := s.storage.PruneSubmissions(s.ctx, now.Add(-retain)); err != nil {
+		fmt.Printf("Failed to prune submissions: %v\n", err)
+	}
+
+	count, err := s.storage.CountSubmissions(s.ctx, fingerprint, now.Add(-submissionQuotaWindow))
+	if err != nil {
+		fmt.Printf("Failed to count submissions: %v\n", err)
+		return "", StatusTemporaryFailure, "Could not check upload quota", false
+	}
+	if count >= titan.UploadsPerHour {
+		return "", StatusSlowDown, fmt.Sprintf("%d", int(submissionQuotaWindow.Seconds())), false
+	}
+
+	recorded, err := s.storage.RecordSubmission(s.ctx, fingerprint, nonce, now)
+	if err != nil {
+		fmt.Printf("Failed to record submission: %v\n", err)
+		return "", StatusTemporaryFailure, "Could not record upload", false
+	}
+	if !recorded {
+		return "", StatusBadRequest, "Upload already received", false
+	}
+
+	return nonce, StatusSuccess, "", true
+}
+
+// forgetSubmission releases a recorded nonce after the submission failed
+func (s *Server) forgetSubmission(fingerprint, nonce string) {
+	if s.storage == nil {
+		return
+	}
+	if err := s.storage.ForgetSubmission(s.ctx, fingerprint, nonce); err != nil {
+		fmt.Printf("Failed to forget submission: %v\n", err)
+	}
+}
@@ -0,0 +1,85 @@
+package gemini
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/sandwich/nophr/internal/aggregates"
+	"github.com/sandwich/nophr/internal/annotate"
+)
+
+// tagIndexGroups are the headings of the /tags index and how many entries each lists
+var tagIndexGroups = []struct {
+	namespace string
+	title     string
+	limit     int
+}{
+	{annotate.NamespaceHashtag, "Hashtags", 50},
+	{annotate.NamespaceTopic, "Topics", 50},
+	{annotate.NamespaceLanguage, "Languages", 20},
+}
+
+// handleTags serves the tag pages: /tags lists the most used hashtags, topics
+// and languages, and /tags/<entry> lists the notes and articles under one
+func (r *Router) handleTags(ctx context.Context, parts []string) []byte {
+	before, remaining, err := aggregates.CursorFromParts(parts)
+	if err != nil {
+		return FormatErrorResponse(StatusBadRequest, err.Error())
+	}
+	if len(remaining) == 0 || remaining[0] == "" {
+		return r.handleTagIndex(ctx)
+	}
+
+	entry, ok := annotate.ParsePath(remaining[0])
+	if !ok {
+		return FormatErrorResponse(StatusBadRequest, "Invalid tag")
+	}
+
+	page, err := r.server.GetQueryHelper().GetAnnotatedPage(ctx,
+		map[string][]string{entry.Namespace: {entry.Value}}, before,
+		r.pageSize(r.server.fullConfig.Display.Limits.PageSizes.Tags))
+	if err != nil {
+		return FormatErrorResponse(StatusTemporaryFailure, fmt.Sprintf("Error loading tag: %v", err))
+	}
+
+	return r.renderListPage(page, entry.Label(), tagPath(entry))
+}
+
+// handleTagIndex lists the hashtags, topics and languages notes are annotated with
+func (r *Router) handleTagIndex(ctx context.Context) []byte {
+	var sb strings.Builder
+	sb.WriteString("# Tags\n\n")
+
+	listed := false
+	for _, group := range tagIndexGroups {
+		counts, err := r.server.GetStorage().TopAnnotations(ctx, group.namespace, 0, group.limit)
+		if err != nil {
+			return FormatErrorResponse(StatusTemporaryFailure, fmt.Sprintf("Error loading tags: %v", err))
+		}
+		if len(counts) == 0 {
+			continue
+		}
+
+		listed = true
+		sb.WriteString(fmt.Sprintf("## %s\n\n", group.title))
+		for _, count := range counts {
+			entry := annotate.Annotation{Namespace: group.namespace, Value: count.Value}
+			sb.WriteString(fmt.Sprintf("=> %s %s (%s)\n", r.geminiURL(tagPath(entry)), entry.Name(), postCount(int64(count.Events))))
+		}
+		sb.WriteString("\n")
+	}
+	if !listed {
+		sb.WriteString("No tagged posts yet.\n\n")
+	}
+
+	sb.WriteString(fmt.Sprintf("=> %s Back to Home\n", r.geminiURL("/")))
+
+	return FormatSuccessResponse(r.renderer.applyHeadersFooters(sb.String(), "tags"))
+}
+
+// tagPath is the path of an entry's listing, escaped for use in links
+func tagPath(entry annotate.Annotation) string {
+	return "/tags/" + url.PathEscape(entry.Path())
+}
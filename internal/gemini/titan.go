@@ -0,0 +1,153 @@
+package gemini
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/nbd-wtf/go-nostr"
+	"github.com/sandwich/nophr/internal/config"
+)
+
+// titanPublishPath is the only path that accepts Titan uploads
+const titanPublishPath = "/publish"
+
+// NotePublisher publishes uploaded text as a note
+type NotePublisher interface {
+	PublishNote(ctx context.Context, content string) (*nostr.Event, error)
+}
+
+// titanRequest holds the parameters of a Titan upload.
+// titan://host/path;mime=text/plain;size=123;token=abc;nonce=x;ts=1700000000
+type titanRequest struct {
+	Path      string
+	Mime      string
+	Size      int
+	Token     string
+	Nonce     string // Client-chosen value that may only be used once
+	Timestamp int64  // Unix time the client made the upload, 0 if absent
+}
+
+// parseTitanPath splits the ;-separated parameters off a Titan URL path
+func parseTitanPath(rawPath string) (*titanRequest, error) {
+	path, rawParams, _ := strings.Cut(rawPath, ";")
+
+	req := &titanRequest{
+		Path: path,
+		Mime: "text/gemini",
+		Size: -1,
+	}
+
+	for _, param := range strings.Split(rawParams, ";") {
+		if param == "" {
+			continue
+		}
+		key, value, _ := strings.Cut(param, "=")
+		switch strings.ToLower(key) {
+		case "mime":
+			req.Mime = strings.ToLower(value)
+		case "size":
+			size, err := strconv.Atoi(value)
+			if err != nil || size < 0 {
+				return nil, fmt.Errorf("invalid size: %s", value)
+			}
+			req.Size = size
+		case "token":
+			req.Token = value
+		case "nonce":
+			if value == "" || len(value) > maxNonceLength {
+				return nil, fmt.Errorf("invalid nonce")
+			}
+			req.Nonce = value
+		case "ts":
+			ts, err := strconv.ParseInt(value, 10, 64)
+			if err != nil || ts <= 0 {
+				return nil, fmt.Errorf("invalid ts: %s", value)
+			}
+			req.Timestamp = ts
+		}
+	}
+
+	if req.Size < 0 {
+		return nil, fmt.Errorf("missing size parameter")
+	}
+
+	return req, nil
+}
+
+// SetPublisher sets the publisher for Titan uploads and drafts (nil disables publishing)
+func (s *Server) SetPublisher(p NotePublisher) {
+	s.publisher = p
+}
+
+// handleTitan reads a Titan upload and publishes it as a note, redirecting to the new note
+func (s *Server) handleTitan(conn net.Conn, reader *bufio.Reader, req *titanRequest) {
+	if req.Path != titanPublishPath {
+		s.sendResponse(conn, StatusNotFound, "Uploads are only accepted at "+titanPublishPath, "")
+		return
+	}
+
+	// Publishing as the owner always needs an owner certificate
+	if status, meta, ok := s.authorizeLevel(conn, config.AccessLevelOwner); !ok {
+		s.sendResponse(conn, status, meta, "")
+		return
+	}
+	fingerprint := CertFingerprint(peerCertificate(conn))
+
+	if s.publisher == nil {
+		s.sendResponse(conn, StatusTemporaryFailure, "Publishing is not configured", "")
+		return
+	}
+
+	mime, _, _ := strings.Cut(req.Mime, ";")
+	if mime != "text/plain" && mime != "text/gemini" {
+		s.sendResponse(conn, StatusBadRequest, "Only text uploads are supported", "")
+		return
+	}
+	if req.Size == 0 {
+		s.sendResponse(conn, StatusBadRequest, "Upload is empty", "")
+		return
+	}
+	if req.Size > s.config.Titan.MaxSize {
+		s.sendResponse(conn, StatusBadRequest, fmt.Sprintf("Upload exceeds %d bytes", s.config.Titan.MaxSize), "")
+		return
+	}
+
+	conn.SetReadDeadline(time.Now().Add(30 * time.Second))
+	body := make([]byte, req.Size)
+	if _, err := io.ReadFull(reader, body); err != nil {
+		s.sendResponse(conn, StatusBadRequest, "Upload shorter than size", "")
+		return
+	}
+	if !utf8.Valid(body) {
+		s.sendResponse(conn, StatusBadRequest, "Upload is not valid UTF-8", "")
+		return
+	}
+
+	nonce, status, meta, ok := s.admitSubmission(fingerprint, req.Nonce, req.Timestamp, body)
+	if !ok {
+		s.sendResponse(conn, status, meta, "")
+		return
+	}
+
+	event, err := s.publisher.PublishNote(s.ctx, string(body))
+	if event == nil {
+		// Nothing was published, so the same upload may be retried
+		s.forgetSubmission(fingerprint, nonce)
+		fmt.Printf("Titan publish failed from %s: %v\n", conn.RemoteAddr(), err)
+		s.sendResponse(conn, StatusTemporaryFailure, fmt.Sprintf("Publish failed: %v", err), "")
+		return
+	}
+	if err != nil {
+		// Stored locally; relays can catch up later
+		fmt.Printf("Titan publish: %v\n", err)
+	}
+
+	s.sendResponse(conn, StatusRedirectTemporary, s.router.geminiURL("/note/"+event.ID), "")
+}
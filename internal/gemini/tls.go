@@ -37,6 +37,9 @@ func (s *Server) initTLS() error {
 	s.tlsConfig = &tls.Config{
 		Certificates: []tls.Certificate{cert},
 		MinVersion:   tls.VersionTLS12,
+		// Ask for client certificates without verifying them; Gemini client
+		// certs are self-signed and identified by fingerprint
+		ClientAuth: tls.RequestClientCert,
 		CipherSuites: []uint16{
 			tls.TLS_ECDHE_ECDSA_WITH_AES_256_GCM_SHA384,
 			tls.TLS_ECDHE_RSA_WITH_AES_256_GCM_SHA384,
@@ -134,6 +137,9 @@ func (s *Server) generateSelfSignedCert() error {
 	s.tlsConfig = &tls.Config{
 		Certificates: []tls.Certificate{cert},
 		MinVersion:   tls.VersionTLS12,
+		// Ask for client certificates without verifying them; Gemini client
+		// certs are self-signed and identified by fingerprint
+		ClientAuth: tls.RequestClientCert,
 		CipherSuites: []uint16{
 			tls.TLS_ECDHE_ECDSA_WITH_AES_256_GCM_SHA384,
 			tls.TLS_ECDHE_RSA_WITH_AES_256_GCM_SHA384,
@@ -0,0 +1,58 @@
+package gemini
+
+import (
+	"fmt"
+	"net"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/sandwich/nophr/internal/security"
+)
+
+// translatePath is where a note's translation into a language is shown, as
+// /translate/<id>/<lang>
+const translatePath = "/translate"
+
+// translateLink offers a note's translation into one language
+type translateLink struct {
+	Lang string
+	URL  string
+}
+
+// noteTranslation is a note's content in another language, shown below the original
+type noteTranslation struct {
+	Lang string
+	Text string
+}
+
+// translateLinks returns the translation links for a note page, or nil when
+// translation is off
+func (r *Router) translateLinks(noteID string) []translateLink {
+	if !r.server.translator.Enabled() {
+		return nil
+	}
+	links := make([]translateLink, 0, len(r.server.translator.Languages()))
+	for _, lang := range r.server.translator.Languages() {
+		links = append(links, translateLink{
+			Lang: lang,
+			URL:  r.geminiURL(translatePath + "/" + noteID + "/" + url.PathEscape(lang)),
+		})
+	}
+	return links
+}
+
+// checkTranslateLimit reports whether the client may open a translation
+// page at path; other paths are always allowed
+func (s *Server) checkTranslateLimit(conn net.Conn, path string) (bool, time.Duration) {
+	if s.translateLimit == nil || !strings.HasPrefix(path, translatePath+"/") {
+		return true, 0
+	}
+
+	ip := security.ClientIP(conn.RemoteAddr())
+	allowed, retryAfter := s.translateLimit.Check(ip)
+	if !allowed {
+		fmt.Printf("Gemini translation limit exceeded for %s\n", ip)
+	}
+	return allowed, retryAfter
+}
@@ -0,0 +1,114 @@
+package gemini
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strings"
+
+	"github.com/sandwich/nophr/internal/config"
+)
+
+// trashPerPage is how many trashed events one /trash page lists
+const trashPerPage = 50
+
+// trashPath lists soft-deleted events; /trash/restore/<id> moves one back
+const trashPath = "/trash"
+
+// isTrashPath reports whether path is the trash view or one of its actions
+func isTrashPath(path string) bool {
+	return path == trashPath || strings.HasPrefix(path, trashPath+"/")
+}
+
+// handleTrash serves the owner-only trash view. It bypasses the response cache
+// so restores show up immediately.
+func (s *Server) handleTrash(conn net.Conn, path string) []byte {
+	if status, meta, ok := s.authorizeLevel(conn, config.AccessLevelOwner); !ok {
+		return FormatResponse(status, meta, "")
+	}
+
+	parts := strings.Split(strings.TrimPrefix(path, trashPath), "/")
+	if len(parts) > 0 && parts[0] == "" {
+		parts = parts[1:]
+	}
+
+	ctx := context.Background()
+	if len(parts) >= 1 && parts[0] == "restore" {
+		if len(parts) < 2 || parts[1] == "" {
+			return FormatErrorResponse(StatusBadRequest, "Missing event ID")
+		}
+		return s.router.handleTrashRestore(ctx, parts[1])
+	}
+
+	return s.router.handleTrashList(ctx, parts)
+}
+
+// handleTrashList lists trashed events, most recently deleted first
+func (r *Router) handleTrashList(ctx context.Context, parts []string) []byte {
+	pageNum, err := parsePageFromParts(parts)
+	if err == nil {
+		err = r.server.GetSanitizer().ValidatePageNumber(pageNum)
+	}
+	if err != nil {
+		return FormatErrorResponse(StatusBadRequest, fmt.Sprintf("Invalid page: %v", err))
+	}
+
+	st := r.server.GetStorage()
+	total, err := st.CountTrash(ctx)
+	if err != nil {
+		return FormatErrorResponse(StatusTemporaryFailure, fmt.Sprintf("Error loading trash: %v", err))
+	}
+	trashed, err := st.ListTrash(ctx, trashPerPage, (pageNum-1)*trashPerPage)
+	if err != nil {
+		return FormatErrorResponse(StatusTemporaryFailure, fmt.Sprintf("Error loading trash: %v", err))
+	}
+
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("# Trash (%d)\n\n", total))
+	sb.WriteString("Events removed by retention or deletion requests are kept here until they are purged.\n\n")
+
+	if len(trashed) == 0 {
+		sb.WriteString("The trash is empty.\n\n")
+	}
+
+	for _, item := range trashed {
+		event := item.Event
+		summary := lineTextReplacer.Replace(r.renderer.GetSummary(event.Content, 80))
+		if summary == "" {
+			summary = fmt.Sprintf("(kind %d)", event.Kind)
+		}
+		author := lineTextReplacer.Replace(r.renderer.resolver.AuthorName(ctx, event.PubKey))
+
+		sb.WriteString(fmt.Sprintf("## %s\n", summary))
+		sb.WriteString(fmt.Sprintf("Kind %d by %s, removed by %s on %s\n", event.Kind, author, item.Reason, item.DeletedAt.UTC().Format("2006-01-02 15:04")))
+		sb.WriteString(fmt.Sprintf("Purged after %s\n", item.PurgeAfter.UTC().Format("2006-01-02 15:04")))
+		sb.WriteString(fmt.Sprintf("=> %s Restore\n\n", r.geminiURL(trashPath+"/restore/"+event.ID)))
+	}
+
+	if int64(pageNum*trashPerPage) < total {
+		sb.WriteString(fmt.Sprintf("=> %s Next page\n", r.geminiURL(fmt.Sprintf("%s/page/%d", trashPath, pageNum+1))))
+	}
+	if pageNum > 1 {
+		sb.WriteString(fmt.Sprintf("=> %s Previous page\n", r.geminiURL(fmt.Sprintf("%s/page/%d", trashPath, pageNum-1))))
+	}
+	sb.WriteString(fmt.Sprintf("=> %s Back to Home\n", r.geminiURL("/")))
+
+	return FormatSuccessResponse(sb.String())
+}
+
+// handleTrashRestore moves an event out of the trash and redirects back to the list
+func (r *Router) handleTrashRestore(ctx context.Context, eventID string) []byte {
+	if err := r.server.GetSanitizer().ValidateEventID(eventID); err != nil {
+		return FormatErrorResponse(StatusBadRequest, fmt.Sprintf("Invalid event ID: %v", err))
+	}
+
+	restored, err := r.server.GetStorage().RestoreEvent(ctx, eventID)
+	if err != nil {
+		return FormatErrorResponse(StatusTemporaryFailure, fmt.Sprintf("Error restoring event: %v", err))
+	}
+	if !restored {
+		return FormatErrorResponse(StatusNotFound, "Event not in trash")
+	}
+
+	return FormatRedirectResponse(r.geminiURL(trashPath), false)
+}
@@ -0,0 +1,331 @@
+package gemini
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/nbd-wtf/go-nostr"
+	nostrclient "github.com/sandwich/nophr/internal/nostr"
+	"github.com/sandwich/nophr/internal/nwc"
+)
+
+// walletPath is the owner-only wallet admin page
+const walletPath = "/wallet"
+
+// recentPaymentsLimit is how many outgoing payments the wallet page lists
+const recentPaymentsLimit = 10
+
+// zapConfirmWindow is how long a checked invoice waits for the owner to
+// confirm the zap
+const zapConfirmWindow = 5 * time.Minute
+
+// pendingZap is a checked zap invoice waiting for confirmation
+type pendingZap struct {
+	noteID  string
+	sats    int64
+	address string
+	invoice string
+	expires time.Time
+}
+
+// ZapSigner signs zap requests as the owner
+type ZapSigner interface {
+	ZapRequest(ctx context.Context, target *nostr.Event, msats int64) (*nostr.Event, error)
+}
+
+// SetWallet sets the NWC wallet for the /wallet page (nil disables it).
+// A nil signer shows the balance and payments but disables zapping.
+func (s *Server) SetWallet(wallet *nwc.Client, signer ZapSigner) {
+	s.wallet = wallet
+	s.zapSigner = signer
+	s.pendingZaps = make(map[string]*pendingZap)
+}
+
+// holdZap keeps zap until it is confirmed and returns the token that
+// confirms it. Expired zaps are dropped.
+func (s *Server) holdZap(zap *pendingZap) (string, error) {
+	token := make([]byte, 16)
+	if _, err := rand.Read(token); err != nil {
+		return "", err
+	}
+
+	s.zapsMu.Lock()
+	defer s.zapsMu.Unlock()
+	for key, pending := range s.pendingZaps {
+		if time.Now().After(pending.expires) {
+			delete(s.pendingZaps, key)
+		}
+	}
+	s.pendingZaps[hex.EncodeToString(token)] = zap
+	return hex.EncodeToString(token), nil
+}
+
+// takeZap removes and returns the unexpired zap token confirms, or nil
+func (s *Server) takeZap(token string) *pendingZap {
+	s.zapsMu.Lock()
+	defer s.zapsMu.Unlock()
+	zap := s.pendingZaps[token]
+	delete(s.pendingZaps, token)
+	if zap == nil || time.Now().After(zap.expires) {
+		return nil
+	}
+	return zap
+}
+
+// isWalletPath reports whether path is the wallet page or below it
+func isWalletPath(path string) bool {
+	return path == walletPath || strings.HasPrefix(path, walletPath+"/")
+}
+
+// handleWallet routes /wallet, /wallet/zap/<note-id> and
+// /wallet/zap/<note-id>/<token>
+func (r *Router) handleWallet(ctx context.Context, parts []string, u *url.URL) []byte {
+	if r.server.wallet == nil {
+		return FormatErrorResponse(StatusNotFound, "Wallet is not configured")
+	}
+
+	if len(parts) == 0 || parts[0] == "" {
+		return r.handleWalletOverview(ctx)
+	}
+	if parts[0] == "zap" && len(parts) == 2 {
+		return r.handleZap(ctx, parts[1], u.RawQuery)
+	}
+	if parts[0] == "zap" && len(parts) == 3 {
+		return r.handleZapConfirm(ctx, parts[1], parts[2])
+	}
+
+	return FormatErrorResponse(StatusNotFound, fmt.Sprintf("Unknown path: %s", u.Path))
+}
+
+// handleWalletOverview shows the wallet balance and recent outgoing payments
+func (r *Router) handleWalletOverview(ctx context.Context) []byte {
+	wallet := r.server.wallet
+
+	gemtext := "# Wallet\n\n"
+
+	balance, err := wallet.GetBalance(ctx)
+	if err != nil {
+		gemtext += fmt.Sprintf("Balance unavailable: %v\n\n", err)
+	} else {
+		gemtext += fmt.Sprintf("Balance: %s sats\n\n", formatSats(balance/1000))
+	}
+
+	gemtext += "## Recent Payments\n\n"
+
+	payments, err := wallet.ListPayments(ctx, recentPaymentsLimit)
+	switch {
+	case err != nil:
+		gemtext += fmt.Sprintf("Payments unavailable: %v\n\n", err)
+	case len(payments) == 0:
+		gemtext += "No payments yet.\n\n"
+	default:
+		for _, payment := range payments {
+			gemtext += formatPayment(payment)
+		}
+		gemtext += "\n"
+	}
+
+	if r.server.zapSigner != nil {
+		gemtext += "Zap a note from its page with the ⚡ link.\n\n"
+	}
+	gemtext += fmt.Sprintf("=> %s Back to Home\n", r.geminiURL("/"))
+
+	return FormatSuccessResponse(gemtext)
+}
+
+// handleZap prompts for an amount, then fetches and checks the invoice for
+// the zap and asks the owner to confirm paying it
+func (r *Router) handleZap(ctx context.Context, noteID, rawQuery string) []byte {
+	if r.server.zapSigner == nil {
+		return FormatErrorResponse(StatusNotFound, "Zapping is not configured")
+	}
+	if err := r.server.GetSanitizer().ValidateEventID(noteID); err != nil {
+		return FormatErrorResponse(StatusBadRequest, fmt.Sprintf("Invalid note ID: %v", err))
+	}
+
+	walletCfg := r.server.config.Wallet
+	if rawQuery == "" {
+		return FormatInputResponse(fmt.Sprintf("Zap amount in sats, up to %d (blank for %d):", walletCfg.MaxZapSats, walletCfg.DefaultZapSats), false)
+	}
+
+	input, err := url.QueryUnescape(rawQuery)
+	if err != nil {
+		return FormatErrorResponse(StatusBadRequest, "Invalid amount")
+	}
+	sats, err := parseZapAmount(input, walletCfg.DefaultZapSats, walletCfg.MaxZapSats)
+	if err != nil {
+		return FormatErrorResponse(StatusBadRequest, fmt.Sprintf("Invalid amount: %v", err))
+	}
+
+	events, err := r.server.GetStorage().QueryEvents(ctx, nostr.Filter{IDs: []string{noteID}})
+	if err != nil || len(events) == 0 {
+		return FormatErrorResponse(StatusNotFound, fmt.Sprintf("Note not found: %s", noteID))
+	}
+	note := events[0]
+
+	address := r.lightningAddress(ctx, note.PubKey)
+	if address == "" {
+		return FormatErrorResponse(StatusPermanentFailure, "Author has no lightning address")
+	}
+
+	zapRequest, err := r.server.zapSigner.ZapRequest(ctx, note, sats*1000)
+	if err != nil {
+		return FormatErrorResponse(StatusTemporaryFailure, fmt.Sprintf("Zap failed: %v", err))
+	}
+
+	lnurlCtx, cancel := context.WithTimeout(ctx, time.Duration(walletCfg.TimeoutSeconds)*time.Second)
+	defer cancel()
+
+	invoice, err := nwc.FetchZapInvoice(lnurlCtx, address, zapRequest)
+	if err != nil {
+		return FormatErrorResponse(StatusTemporaryFailure, fmt.Sprintf("Zap failed: %v", err))
+	}
+
+	// Never pay an invoice other than the one asked for
+	if err := nwc.CheckZapInvoice(invoice, sats*1000, zapRequest); err != nil {
+		fmt.Printf("Zap of %s refused: %s returned a bad invoice: %v\n", noteID, address, err)
+		return FormatErrorResponse(StatusPermanentFailure, fmt.Sprintf("Zap refused: %v", err))
+	}
+
+	token, err := r.server.holdZap(&pendingZap{
+		noteID:  noteID,
+		sats:    sats,
+		address: address,
+		invoice: invoice,
+		expires: time.Now().Add(zapConfirmWindow),
+	})
+	if err != nil {
+		return FormatErrorResponse(StatusTemporaryFailure, fmt.Sprintf("Zap failed: %v", err))
+	}
+
+	gemtext := "# Confirm Zap\n\n"
+	gemtext += fmt.Sprintf("Zap %s sats to %s for this note?\n\n", formatSats(sats), address)
+	gemtext += fmt.Sprintf("> %s\n\n", truncateLine(note.Content, 80))
+	gemtext += fmt.Sprintf("=> %s ⚡ Pay %s sats\n", r.geminiURL(walletPath+"/zap/"+noteID+"/"+token), formatSats(sats))
+	gemtext += fmt.Sprintf("=> %s Cancel\n\n", r.geminiURL("/note/"+noteID))
+	gemtext += fmt.Sprintf("The link pays once and expires in %d minutes.\n", int(zapConfirmWindow.Minutes()))
+
+	return FormatSuccessResponse(gemtext)
+}
+
+// handleZapConfirm pays the zap invoice token confirms
+func (r *Router) handleZapConfirm(ctx context.Context, noteID, token string) []byte {
+	if r.server.zapSigner == nil {
+		return FormatErrorResponse(StatusNotFound, "Zapping is not configured")
+	}
+
+	zap := r.server.takeZap(token)
+	if zap == nil || zap.noteID != noteID {
+		return FormatErrorResponse(StatusBadRequest, "Zap expired or already paid; start again from the note")
+	}
+
+	if _, err := r.server.wallet.PayInvoice(ctx, zap.invoice); err != nil {
+		fmt.Printf("Zap of %s failed: %v\n", noteID, err)
+		return FormatErrorResponse(StatusTemporaryFailure, fmt.Sprintf("Payment failed: %v", err))
+	}
+
+	fmt.Printf("[WALLET] Zapped %d sats to note %s\n", zap.sats, noteID)
+
+	gemtext := "# Zap Sent\n\n"
+	gemtext += fmt.Sprintf("Zapped %s sats to %s.\n\n", formatSats(zap.sats), zap.address)
+	gemtext += "The receipt shows on the note once the recipient's server publishes it.\n\n"
+	gemtext += fmt.Sprintf("=> %s Back to Note\n", r.geminiURL("/note/"+noteID))
+	gemtext += fmt.Sprintf("=> %s Wallet\n", r.geminiURL(walletPath))
+
+	return FormatSuccessResponse(gemtext)
+}
+
+// lightningAddress returns the lud16 from the author's stored profile
+func (r *Router) lightningAddress(ctx context.Context, pubkey string) string {
+	events, err := r.server.GetStorage().QueryEvents(ctx, nostr.Filter{
+		Kinds:   []int{0},
+		Authors: []string{pubkey},
+		Limit:   1,
+	})
+	if err != nil || len(events) == 0 {
+		return ""
+	}
+
+	profile := nostrclient.ParseProfile(events[0])
+	if profile == nil {
+		return ""
+	}
+	// LNURL bech32 strings (lud06) aren't supported, only name@domain addresses
+	return profile.LUD16
+}
+
+// zapURL returns the zap prompt URL for a note, or "" when zapping is off
+func (r *Router) zapURL(noteID string) string {
+	if r.server.wallet == nil || r.server.zapSigner == nil {
+		return ""
+	}
+	return r.geminiURL(walletPath + "/zap/" + noteID)
+}
+
+// parseZapAmount parses the sats typed into the zap prompt, refusing more
+// than maxSats
+func parseZapAmount(input string, defaultSats, maxSats int) (int64, error) {
+	input = strings.TrimSpace(input)
+	if input == "" {
+		input = strconv.Itoa(defaultSats)
+	}
+
+	sats, err := strconv.ParseInt(input, 10, 64)
+	if err != nil || sats <= 0 {
+		return 0, fmt.Errorf("must be a positive number of sats")
+	}
+	if sats > int64(maxSats) {
+		return 0, fmt.Errorf("zaps are limited to %d sats", maxSats)
+	}
+	return sats, nil
+}
+
+// formatPayment renders one outgoing payment as a list line
+func formatPayment(payment nwc.Transaction) string {
+	when := payment.SettledAt
+	if when == 0 {
+		when = payment.CreatedAt
+	}
+
+	line := fmt.Sprintf("* %s · %s sats", time.Unix(when, 0).Format("2006-01-02 15:04"), formatSats(payment.Amount/1000))
+	if payment.Description != "" {
+		line += " · " + truncateLine(payment.Description, 60)
+	}
+	return line + "\n"
+}
+
+// formatSats adds thousands separators to a sat amount
+func formatSats(sats int64) string {
+	s := strconv.FormatInt(sats, 10)
+	negative := strings.HasPrefix(s, "-")
+	s = strings.TrimPrefix(s, "-")
+
+	var b strings.Builder
+	for i, c := range s {
+		if i > 0 && (len(s)-i)%3 == 0 {
+			b.WriteByte(',')
+		}
+		b.WriteRune(c)
+	}
+
+	if negative {
+		return "-" + b.String()
+	}
+	return b.String()
+}
+
+// truncateLine shortens text to max runes on a single line
+func truncateLine(text string, max int) string {
+	text = strings.Join(strings.Fields(text), " ")
+	runes := []rune(text)
+	if len(runes) <= max {
+		return text
+	}
+	return string(runes[:max-1]) + "…"
+}
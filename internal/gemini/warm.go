@@ -0,0 +1,34 @@
+package gemini
+
+import (
+	"context"
+	"net/url"
+	"time"
+
+	"github.com/sandwich/nophr/internal/cache"
+)
+
+// hotPaths are rendered into the cache at startup and after invalidation storms
+var hotPaths = []string{"/", "/notes", "/articles"}
+
+// WarmCache renders the home page, first pages of notes and articles, and the
+// owner profile, and stores them through the warmer
+func (s *Server) WarmCache(ctx context.Context, w *cache.Warmer, ttl time.Duration) error {
+	for _, path := range hotPaths {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if err := w.WarmGemini(ctx, path, s.router.Route(&url.URL{Path: path}), ttl); err != nil {
+			return err
+		}
+	}
+
+	ownerHex, err := s.queryHelper.OwnerHex()
+	if err != nil {
+		return err
+	}
+
+	// Stored under the path key so request lookups find it
+	path := "/profile/" + ownerHex
+	return w.WarmGemini(ctx, path, s.router.Route(&url.URL{Path: path}), ttl)
+}
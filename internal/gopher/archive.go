@@ -0,0 +1,143 @@
+package gopher
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/sandwich/nophr/internal/aggregates"
+	"github.com/sandwich/nophr/internal/sections"
+)
+
+// archiveKinds are the owner's posts listed in the archive: notes and articles
+var archiveKinds = []int{1, 30023}
+
+// handleArchive serves the owner's archive: /archive lists years, /archive/<year>
+// lists months and /archive/<year>/<month> lists that month's notes and articles
+func (r *Router) handleArchive(ctx context.Context, parts []string) []byte {
+	before, remaining, err := aggregates.CursorFromParts(parts)
+	if err != nil {
+		return r.errorResponse(ErrorBadRequest, "Invalid page cursor", err)
+	}
+
+	year, month, err := sections.ParseArchivePath(remaining)
+	if err != nil {
+		return r.errorResponse(ErrorBadRequest, "Invalid archive date", err)
+	}
+
+	if month == 0 {
+		return r.handleArchiveIndex(ctx, year)
+	}
+	return r.handleArchiveMonth(ctx, year, month, before)
+}
+
+// handleArchiveIndex lists the years with posts, or the months of one year
+func (r *Router) handleArchiveIndex(ctx context.Context, year int) []byte {
+	gmap := NewGophermap(r.host, r.port)
+	r.addHeaderToGophermap(gmap, "archive")
+
+	ownerHex, err := r.server.GetQueryHelper().OwnerHex()
+	if err != nil {
+		return r.errorResponse(ErrorInternal, "Error loading archive", err)
+	}
+
+	archives, err := r.archives.ListAuthorArchives(ctx, ownerHex, archiveKinds, year)
+	if err != nil {
+		r.server.reportError(gmap, ErrorInternal, "Error loading archive", err)
+		gmap.AddSpacer()
+		gmap.AddDirectory("⌂ Home", "/")
+		return gmap.Bytes()
+	}
+
+	if year == 0 {
+		gmap.AddInfo("Archive")
+	} else {
+		gmap.AddInfo(fmt.Sprintf("Archive: %d", year))
+	}
+	gmap.AddSpacer()
+
+	if len(archives) == 0 {
+		gmap.AddInfo("No posts yet.")
+	}
+	for _, archive := range archives {
+		gmap.AddDirectory(fmt.Sprintf("%s (%s)", archive.FormatTitle(), postCount(archive.EventCount)), archive.Selector())
+	}
+
+	gmap.AddSpacer()
+	if year != 0 {
+		gmap.AddDirectory("↑ All years", "/archive")
+	}
+	gmap.AddDirectory("⌂ Home", "/")
+
+	r.addFooterToGophermap(gmap, "archive")
+	return gmap.Bytes()
+}
+
+// handleArchiveMonth lists one page of the owner's notes and articles from a month
+func (r *Router) handleArchiveMonth(ctx context.Context, year int, month time.Month, before *aggregates.Cursor) []byte {
+	gmap := NewGophermap(r.host, r.port)
+	r.addHeaderToGophermap(gmap, "archive")
+
+	rng := sections.MonthRange(year, month)
+	limits := r.server.fullConfig.Display.Limits
+	page, err := r.server.GetQueryHelper().GetArchivePage(ctx, rng.Start, rng.End, before,
+		min(r.pageSize(limits.PageSizes.Archive), limits.MaxArchivePageSize))
+	if err != nil {
+		r.server.reportError(gmap, ErrorInternal, "Error loading archive", err)
+		gmap.AddSpacer()
+		gmap.AddDirectory("⌂ Home", "/")
+		return gmap.Bytes()
+	}
+
+	gmap.AddInfo(fmt.Sprintf("Archive: %s %d", month, year))
+	gmap.AddSpacer()
+
+	if len(page.Events) == 0 {
+		gmap.AddInfo("No posts this month.")
+		gmap.AddSpacer()
+	}
+	for _, item := range page.Events {
+		event := item.Event
+		gmap.AddInfo(fmt.Sprintf("   %s", formatTimestamp(event.CreatedAt)))
+		if item.Aggregates != nil && item.Aggregates.HasInteractions() {
+			if aggText := r.renderer.renderAggregates(item.Aggregates); aggText != "" {
+				gmap.AddInfo("   " + aggText)
+			}
+		}
+
+		if event.Kind == 30023 {
+			gmap.AddTextFile("Article: "+menuTextReplacer.Replace(articleTitle(event)), fmt.Sprintf("/note/%s", event.ID))
+			gmap.AddTextFile("   Plain text: "+articleFilename(event), articleTextSelector(event))
+		} else {
+			content := event.Content
+			if len(content) > 60 {
+				content = content[:57] + "..."
+			}
+			gmap.AddTextFile(strings.Split(content, "\n")[0], fmt.Sprintf("/note/%s", event.ID))
+		}
+		gmap.AddSpacer()
+	}
+
+	basePath := fmt.Sprintf("/archive/%04d/%02d", year, month)
+	if page.Next != nil {
+		gmap.AddDirectory("→ Older", fmt.Sprintf("%s/until/%s", basePath, page.Next))
+	}
+	if page.Before != nil {
+		gmap.AddDirectory("↑ Newest", basePath)
+	}
+	gmap.AddDirectory(fmt.Sprintf("↑ %d", year), fmt.Sprintf("/archive/%04d", year))
+	gmap.AddSpacer()
+	gmap.AddDirectory("⌂ Home", "/")
+
+	r.addFooterToGophermap(gmap, "archive")
+	return gmap.Bytes()
+}
+
+// postCount formats a number of posts, e.g. "1 post" or "12 posts"
+func postCount(n int64) string {
+	if n == 1 {
+		return "1 post"
+	}
+	return fmt.Sprintf("%d posts", n)
+}
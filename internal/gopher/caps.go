@@ -0,0 +1,122 @@
+package gopher
+
+import (
+	"fmt"
+	"runtime"
+	"strings"
+)
+
+// capsExpireSeconds tells clients how long they may cache caps.txt
+const capsExpireSeconds = 3600
+
+// adminContact formats the operator and admin email, e.g. "Alice <alice@example.com>"
+func (s *Server) adminContact() string {
+	site := s.fullConfig.Site
+	switch {
+	case site.Operator != "" && site.AdminEmail != "":
+		return fmt.Sprintf("%s <%s>", site.Operator, site.AdminEmail)
+	case site.AdminEmail != "":
+		return site.AdminEmail
+	case site.Operator != "":
+		return site.Operator
+	default:
+		return "nophr"
+	}
+}
+
+// version returns the running nophr version, if known
+func (s *Server) version() string {
+	if s.diagnostics == nil {
+		return ""
+	}
+	return s.diagnostics.Version()
+}
+
+// handleCaps serves caps.txt, the de-facto standard file gopher clients probe
+// to learn how selectors are structured and who runs the server
+func (r *Router) handleCaps() []byte {
+	site := r.server.fullConfig.Site
+
+	var sb strings.Builder
+	sb.WriteString("CAPS\n\n")
+	sb.WriteString("# Generated by nophr from the site configuration\n\n")
+	sb.WriteString("CapsVersion=1\n")
+	sb.WriteString(fmt.Sprintf("ExpireCapsAfter=%d\n\n", capsExpireSeconds))
+
+	// Selectors are slash-separated paths; ".." has no special meaning
+	sb.WriteString("PathDelimeter=/\n")
+	sb.WriteString("PathIdentity=.\n")
+	sb.WriteString("PathParent=..\n")
+	sb.WriteString("PathParentDouble=FALSE\n")
+	sb.WriteString("PathEscapeCharacter=\\\n")
+	sb.WriteString("PathKeepPreDelimeter=FALSE\n\n")
+
+	sb.WriteString("ServerSoftware=nophr\n")
+	if version := r.server.version(); version != "" {
+		sb.WriteString(fmt.Sprintf("ServerSoftwareVersion=%s\n", version))
+	}
+	sb.WriteString(fmt.Sprintf("ServerArchitecture=%s-%s\n", runtime.GOOS, runtime.GOARCH))
+	if site.Description != "" {
+		sb.WriteString(fmt.Sprintf("ServerDescription=%s\n", strings.Join(strings.Fields(site.Description), " ")))
+	}
+	sb.WriteString("ServerDefaultEncoding=utf-8\n")
+	if site.AdminEmail != "" {
+		sb.WriteString(fmt.Sprintf("ServerAdmin=%s\n", site.AdminEmail))
+	}
+
+	return append([]byte(sb.String()), []byte(".\r\n")...)
+}
+
+// handleAbout describes the site, its operator and where else it is served
+func (r *Router) handleAbout() []byte {
+	cfg := r.server.fullConfig
+	gmap := NewGophermap(r.host, r.port)
+
+	title := cfg.Site.Title
+	if title == "" {
+		title = "About"
+	}
+	gmap.AddWelcome(title, cfg.Site.Description)
+
+	if cfg.Site.Operator != "" {
+		gmap.AddInfo("Operator: " + cfg.Site.Operator)
+	}
+	if cfg.Site.AdminEmail != "" {
+		gmap.AddInfo("Contact:  " + cfg.Site.AdminEmail)
+	}
+	if cfg.Identity.Npub != "" {
+		gmap.AddInfo("Nostr:    " + cfg.Identity.Npub)
+	}
+	software := "nophr"
+	if version := r.server.version(); version != "" {
+		software += " " + version
+	}
+	gmap.AddInfo("Software: " + software)
+	gmap.AddSpacer()
+
+	// Other protocols serving the same content
+	if gemini := cfg.Protocols.Gemini; gemini.Enabled {
+		host := gemini.Host
+		if host == "" {
+			host = r.host
+		}
+		if gemini.Port == 0 || gemini.Port == 1965 {
+			gmap.AddInfo(fmt.Sprintf("Also on Gemini: gemini://%s/", host))
+		} else {
+			gmap.AddInfo(fmt.Sprintf("Also on Gemini: gemini://%s:%d/", host, gemini.Port))
+		}
+	}
+	if cfg.Protocols.Finger.Enabled {
+		gmap.AddInfo(fmt.Sprintf("Also on Finger: finger @%s", r.host))
+	}
+	gmap.AddSpacer()
+
+	if ownerHex, err := r.server.GetQueryHelper().OwnerHex(); err == nil {
+		gmap.AddDirectory("Owner profile", "/profile/"+ownerHex)
+	}
+	gmap.AddTextFile("Server capabilities (caps.txt)", "/caps.txt")
+	gmap.AddSpacer()
+	gmap.AddDirectory("← Back to Home", "/")
+
+	return gmap.Bytes()
+}
@@ -0,0 +1,55 @@
+package gopher
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/sandwich/nophr/internal/aggregates"
+	"github.com/sandwich/nophr/internal/config"
+	"github.com/sandwich/nophr/internal/ops"
+	"github.com/sandwich/nophr/internal/storage"
+)
+
+func TestCapsAndAbout(t *testing.T) {
+	cfg := config.Default()
+	cfg.Identity.Npub = "npub1nq3zgtqruwhnz0xx40gh4a4fkamlr2sc7ke5wqs2s3nyv2fpy9esg4hdwq"
+	cfg.Storage.SQLitePath = ":memory:"
+	cfg.Site.Title = "Alice's Hole"
+	cfg.Site.Description = "Notes from\nAlice"
+	cfg.Site.Operator = "Alice"
+	cfg.Site.AdminEmail = "alice@example.com"
+
+	ctx := context.Background()
+	st, err := storage.New(ctx, &cfg.Storage)
+	if err != nil {
+		t.Fatalf("Failed to create storage: %v", err)
+	}
+	defer st.Close()
+
+	server := New(&cfg.Protocols.Gopher, cfg, st, "localhost", aggregates.NewManager(st, cfg))
+	server.SetDiagnostics(ops.NewDiagnosticsCollector("1.2.3", "abc", st, nil))
+
+	caps := string(server.router.Route("/caps.txt"))
+	for _, want := range []string{"CAPS\n", "PathDelimeter=/\n", "ServerSoftware=nophr\n", "ServerSoftwareVersion=1.2.3\n", "ServerDescription=Notes from Alice\n", "ServerAdmin=alice@example.com\n"} {
+		if !strings.Contains(caps, want) {
+			t.Errorf("caps.txt should contain %q, got: %s", want, caps)
+		}
+	}
+
+	// Clients often request it without the leading slash
+	if bare := string(server.router.Route("caps.txt")); bare != caps {
+		t.Errorf("caps.txt without a slash should match, got: %s", bare)
+	}
+
+	about := string(server.router.Route("/about"))
+	for _, want := range []string{"Alice's Hole", "Operator: Alice", "alice@example.com", cfg.Identity.Npub, "nophr 1.2.3", "/caps.txt"} {
+		if !strings.Contains(about, want) {
+			t.Errorf("About page should contain %q, got: %s", want, about)
+		}
+	}
+
+	if admin := server.adminContact(); admin != "Alice <alice@example.com>" {
+		t.Errorf("Expected admin contact with email, got %q", admin)
+	}
+}
@@ -0,0 +1,59 @@
+package gopher
+
+import (
+	"testing"
+
+	"github.com/nbd-wtf/go-nostr/nip19"
+	"github.com/sandwich/nophr/internal/aggregates"
+	"github.com/sandwich/nophr/internal/conformance"
+)
+
+func TestConformance(t *testing.T) {
+	fixture := conformance.NewFixture()
+	cfg := conformance.Config(t, fixture)
+	st := conformance.Setup(t, fixture, cfg)
+
+	server := New(&cfg.Protocols.Gopher, cfg, st, "localhost", aggregates.NewManager(st, cfg))
+	nevent, _ := nip19.EncodeEvent(fixture.Note.ID, nil, "")
+
+	tests := []struct {
+		golden   string // Empty for responses that carry a random reference code
+		selector string
+		menu     bool
+	}{
+		{"root.gophermap", "/", true},
+		{"notes.gophermap", "/notes", true},
+		{"articles.gophermap", "/articles", true},
+		{"replies.gophermap", "/replies", true},
+		{"archive.gophermap", "/archive", true},
+		{"archive-month.gophermap", "/archive/2024/06", true},
+		{"followers.gophermap", "/followers", true},
+		{"tags.gophermap", "/tags", true},
+		{"tag.gophermap", "/tags/lang:en", true},
+		{"author.gophermap", "/author/" + fixture.Follower, true},
+		{"note.txt", "/note/" + fixture.Note.ID, false},
+		{"note.txt", "/search/nostr:" + nevent, false},
+		{"special-note.txt", "/note/" + fixture.SpecialNote.ID, false},
+		{"thread.txt", "/thread/" + fixture.Note.ID, false},
+		{"profile.txt", "/profile/" + fixture.Owner, false},
+		{"article.txt", articleTextSelector(fixture.Article), false},
+		{"feed.xml", "/feed.xml", false},
+		{"", "/no-such-selector", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.selector, func(t *testing.T) {
+			response := server.router.Route(tt.selector)
+
+			if tt.menu {
+				conformance.Check(t, tt.selector, conformance.CheckGophermap(response))
+			} else {
+				conformance.Check(t, tt.selector, conformance.CheckGopherText(response))
+			}
+
+			if tt.golden != "" {
+				conformance.Golden(t, tt.golden, response)
+			}
+		})
+	}
+}
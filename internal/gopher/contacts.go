@@ -0,0 +1,189 @@
+package gopher
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/sandwich/nophr/internal/aggregates"
+)
+
+// menuTextReplacer keeps profile-supplied names from breaking menu lines
+var menuTextReplacer = strings.NewReplacer("\t", " ", "\r", " ", "\n", " ")
+
+// parsePageFromParts extracts a page number from selector parts like ["page", "2"]
+func parsePageFromParts(parts []string) (int, error) {
+	if len(parts) >= 2 && parts[0] == "page" {
+		return strconv.Atoi(parts[1])
+	}
+	return 1, nil
+}
+
+// handleFollowing lists the accounts the owner follows
+func (r *Router) handleFollowing(ctx context.Context, parts []string) []byte {
+	pageNum, err := parsePageFromParts(parts)
+	if err == nil {
+		err = r.server.GetSanitizer().ValidatePageNumber(pageNum)
+	}
+	if err != nil {
+		return r.errorResponse(ErrorBadRequest, "Invalid page", err)
+	}
+
+	page, err := r.server.GetQueryHelper().GetFollowing(ctx, pageNum, r.listSize(r.server.fullConfig.Display.Limits.PageSizes.Contacts))
+	if err != nil {
+		return r.errorResponse(ErrorInternal, "Error loading following", err)
+	}
+
+	return r.renderContactPage(page, "Following", "/following", "Not following anyone yet.")
+}
+
+// handleFollowers lists the accounts whose stored contact lists include the owner
+func (r *Router) handleFollowers(ctx context.Context, parts []string) []byte {
+	pageNum, err := parsePageFromParts(parts)
+	if err == nil {
+		err = r.server.GetSanitizer().ValidatePageNumber(pageNum)
+	}
+	if err != nil {
+		return r.errorResponse(ErrorBadRequest, "Invalid page", err)
+	}
+
+	page, err := r.server.GetQueryHelper().GetFollowers(ctx, pageNum, r.listSize(r.server.fullConfig.Display.Limits.PageSizes.Contacts))
+	if err != nil {
+		return r.errorResponse(ErrorInternal, "Error loading followers", err)
+	}
+
+	return r.renderContactPage(page, "Followers", "/followers", "No followers known yet. Followers are found from synced contact lists.")
+}
+
+// renderContactPage renders one page of contacts, each linking to their profile and notes
+func (r *Router) renderContactPage(page *aggregates.ContactPage, title, basePath, empty string) []byte {
+	gmap := NewGophermap(r.host, r.port)
+
+	gmap.AddInfo(fmt.Sprintf("%s (%d)", title, page.TotalItems))
+	gmap.AddSpacer()
+
+	if len(page.Contacts) == 0 {
+		gmap.AddInfo(empty)
+		gmap.AddSpacer()
+	}
+
+	for _, contact := range page.Contacts {
+		name := menuTextReplacer.Replace(contact.Name)
+		if name == "" {
+			name = r.renderer.authorLabel(context.Background(), contact.Pubkey)
+		}
+		gmap.AddTextFile(name, "/profile/"+contact.Pubkey)
+		gmap.AddDirectory("   Notes by "+name, "/author/"+contact.Pubkey)
+	}
+
+	gmap.AddSpacer()
+	if page.HasNext {
+		gmap.AddDirectory("→ Next page", fmt.Sprintf("%s/page/%d", basePath, page.PageNumber+1))
+	}
+	if page.PageNumber > 1 {
+		gmap.AddDirectory("← Previous page", fmt.Sprintf("%s/page/%d", basePath, page.PageNumber-1))
+	}
+	gmap.AddDirectory("⌂ Home", "/")
+
+	return gmap.Bytes()
+}
+
+// handleAuthor lists one author's root notes, newest first
+func (r *Router) handleAuthor(ctx context.Context, parts []string) []byte {
+	if len(parts) == 0 || parts[0] == "" {
+		return r.errorResponse(ErrorBadRequest, "Missing pubkey", nil)
+	}
+
+	pubkey := parts[0]
+	if err := r.server.GetSanitizer().ValidatePubkey(pubkey); err != nil {
+		return r.errorResponse(ErrorBadRequest, "Invalid pubkey", err)
+	}
+
+	before, _, err := aggregates.CursorFromParts(parts[1:])
+	if err != nil {
+		return r.errorResponse(ErrorBadRequest, "Invalid page cursor", err)
+	}
+
+	page, err := r.server.GetQueryHelper().GetAuthorNotesPage(ctx, pubkey, before, r.pageSize(r.server.fullConfig.Display.Limits.PageSizes.Profile))
+	if err != nil {
+		return r.errorResponse(ErrorInternal, "Error loading notes", err)
+	}
+
+	gmap := NewGophermap(r.host, r.port)
+	gmap.AddInfo("Notes by " + menuTextReplacer.Replace(r.renderer.resolver.AuthorName(ctx, pubkey)))
+	gmap.AddSpacer()
+	gmap.AddTextFile("Profile", "/profile/"+pubkey)
+	gmap.AddDirectory("Notes & articles", "/profile/"+pubkey+"/notes")
+	gmap.AddSpacer()
+
+	if len(page.Events) == 0 {
+		gmap.AddInfo("No notes stored for this author.")
+		gmap.AddSpacer()
+	}
+
+	for _, note := range page.Events {
+		if aggregates.IsRepost(note.Event) {
+			r.addRepostItem(ctx, gmap, note)
+			continue
+		}
+
+		firstLine := menuTextReplacer.Replace(strings.Split(note.Event.Content, "\n")[0])
+		if len(firstLine) > 60 {
+			firstLine = firstLine[:57] + "..."
+		}
+		gmap.AddInfo("   " + formatTimestamp(note.Event.CreatedAt))
+		gmap.AddTextFile(firstLine, "/note/"+note.Event.ID)
+		gmap.AddSpacer()
+	}
+
+	r.addPaginationLinks(gmap, "/author/"+pubkey, page)
+
+	return gmap.Bytes()
+}
+
+// handleProfileNotes lists an author's recent root notes and articles with their aggregates
+func (r *Router) handleProfileNotes(ctx context.Context, pubkey string) []byte {
+	if err := r.server.GetSanitizer().ValidatePubkey(pubkey); err != nil {
+		return r.errorResponse(ErrorBadRequest, "Invalid pubkey", err)
+	}
+
+	notes, err := r.server.GetQueryHelper().GetNotesByAuthor(ctx, pubkey, r.pageSize(r.server.fullConfig.Display.Limits.PageSizes.Profile))
+	if err != nil {
+		return r.errorResponse(ErrorInternal, "Error loading notes", err)
+	}
+
+	gmap := NewGophermap(r.host, r.port)
+	gmap.AddInfo("Posts by " + menuTextReplacer.Replace(r.renderer.resolver.AuthorName(ctx, pubkey)))
+	gmap.AddSpacer()
+	gmap.AddTextFile("Profile", "/profile/"+pubkey)
+	gmap.AddSpacer()
+
+	if len(notes) == 0 {
+		gmap.AddInfo("No notes or articles stored for this author.")
+		gmap.AddSpacer()
+	}
+
+	for _, note := range notes {
+		firstLine := menuTextReplacer.Replace(strings.Split(note.Event.Content, "\n")[0])
+		if len(firstLine) > 60 {
+			firstLine = firstLine[:57] + "..."
+		}
+		if note.Event.Kind == 30023 {
+			firstLine = "[Article] " + firstLine
+		}
+
+		gmap.AddInfo("   " + formatTimestamp(note.Event.CreatedAt))
+		if note.Aggregates != nil && note.Aggregates.HasInteractions() {
+			if aggText := r.renderer.renderAggregates(note.Aggregates); aggText != "" {
+				gmap.AddInfo("   " + aggText)
+			}
+		}
+		gmap.AddTextFile(firstLine, "/note/"+note.Event.ID)
+		gmap.AddSpacer()
+	}
+
+	gmap.AddDirectory("⌂ Home", "/")
+
+	return gmap.Bytes()
+}
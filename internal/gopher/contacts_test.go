@@ -0,0 +1,80 @@
+package gopher
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/nbd-wtf/go-nostr"
+	"github.com/nbd-wtf/go-nostr/nip19"
+	"github.com/sandwich/nophr/internal/aggregates"
+	"github.com/sandwich/nophr/internal/config"
+	"github.com/sandwich/nophr/internal/storage"
+)
+
+func TestContactRoutes(t *testing.T) {
+	cfg := config.Default()
+	cfg.Identity.Npub = "npub1nq3zgtqruwhnz0xx40gh4a4fkamlr2sc7ke5wqs2s3nyv2fpy9esg4hdwq"
+	cfg.Storage.SQLitePath = ":memory:"
+
+	_, decoded, err := nip19.Decode(cfg.Identity.Npub)
+	if err != nil {
+		t.Fatalf("Failed to decode npub: %v", err)
+	}
+	ownerHex := decoded.(string)
+
+	ctx := context.Background()
+	st, err := storage.New(ctx, &cfg.Storage)
+	if err != nil {
+		t.Fatalf("Failed to create storage: %v", err)
+	}
+	defer st.Close()
+
+	follower, _ := nostr.GetPublicKey(nostr.GeneratePrivateKey())
+	events := []*nostr.Event{
+		{Kind: 3, PubKey: follower, CreatedAt: 100, Tags: nostr.Tags{{"p", ownerHex}}},
+		{Kind: 0, PubKey: follower, CreatedAt: 100, Content: `{"name":"alice"}`},
+		{Kind: 1, PubKey: follower, CreatedAt: 200, Content: "hello from alice"},
+		{Kind: 30023, PubKey: follower, CreatedAt: 300, Content: "a long read", Tags: nostr.Tags{{"d", "long-read"}}},
+		{Kind: 1, PubKey: follower, CreatedAt: 400, Content: "replying to someone", Tags: nostr.Tags{{"e", strings.Repeat("a", 64), "", "root"}}},
+	}
+	for _, event := range events {
+		event.ID = event.GetID()
+		if err := st.StoreEvent(ctx, event); err != nil {
+			t.Fatalf("Failed to store event: %v", err)
+		}
+	}
+
+	server := New(&cfg.Protocols.Gopher, cfg, st, "localhost", aggregates.NewManager(st, cfg))
+	router := server.router
+
+	followers := string(router.Route("/followers"))
+	if !strings.Contains(followers, "Followers (1)") || !strings.Contains(followers, "alice") {
+		t.Errorf("Followers should list alice, got: %s", followers)
+	}
+	if !strings.Contains(followers, "/author/"+follower) || !strings.Contains(followers, "/profile/"+follower) {
+		t.Errorf("Followers should link to profile and notes, got: %s", followers)
+	}
+
+	author := string(router.Route("/author/" + follower))
+	if !strings.Contains(author, "Notes by alice") || !strings.Contains(author, "hello from alice") {
+		t.Errorf("Author feed should list alice's notes, got: %s", author)
+	}
+
+	posts := string(router.Route("/profile/" + follower + "/notes"))
+	if !strings.Contains(posts, "Posts by alice") || !strings.Contains(posts, "hello from alice") || !strings.Contains(posts, "[Article] a long read") {
+		t.Errorf("Profile notes should list alice's notes and articles, got: %s", posts)
+	}
+	if strings.Contains(posts, "replying to someone") {
+		t.Errorf("Profile notes should leave out replies, got: %s", posts)
+	}
+
+	following := string(router.Route("/following"))
+	if !strings.Contains(following, "Following (0)") {
+		t.Errorf("Following should be empty, got: %s", following)
+	}
+
+	if bad := string(router.Route("/followers/page/0")); !strings.HasPrefix(bad, "3") {
+		t.Errorf("Page 0 should be rejected, got: %s", bad)
+	}
+}
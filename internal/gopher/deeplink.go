@@ -0,0 +1,67 @@
+package gopher
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/nbd-wtf/go-nostr"
+)
+
+// routeEntity serves a NIP-19 deep link (note1..., nevent1..., npub1..., nprofile1..., naddr1...)
+// by routing to the handler for what it points at. Content that is not stored locally
+// gets a not-found page listing the relay hints carried by the entity.
+func (r *Router) routeEntity(ctx context.Context, component string) []byte {
+	entity, err := r.renderer.resolver.DecodeEntity(component)
+	if err != nil {
+		return r.errorResponse(ErrorBadRequest, "Invalid NIP-19 entity", err)
+	}
+
+	if !r.renderer.resolver.Exists(ctx, entity) {
+		gmap := NewGophermap(r.host, r.port)
+		r.server.reportError(gmap, ErrorNotFound, fmt.Sprintf("This %s is not stored here", entity.Type), nil)
+		if len(entity.Relays) > 0 {
+			gmap.AddSpacer()
+			gmap.AddInfo("It may be found on:")
+			for _, relay := range entity.Relays {
+				gmap.AddInfo("  " + relay)
+			}
+		}
+		gmap.AddSpacer()
+		gmap.AddDirectory("← Back to Home", "/")
+		return gmap.Bytes()
+	}
+
+	return r.Route(entity.Link)
+}
+
+// handleAddr displays a parameterized replaceable event (e.g. an article) by
+// kind, author and d tag: /addr/<kind>/<pubkey>/<identifier>
+func (r *Router) handleAddr(ctx context.Context, parts []string) []byte {
+	if len(parts) < 2 {
+		return r.errorResponse(ErrorBadRequest, "Missing address", nil)
+	}
+
+	kind, err := strconv.Atoi(parts[0])
+	if err != nil {
+		return r.errorResponse(ErrorBadRequest, "Invalid address kind", err)
+	}
+	pubkey := parts[1]
+	if err := r.server.GetSanitizer().ValidatePubkey(pubkey); err != nil {
+		return r.errorResponse(ErrorBadRequest, "Invalid pubkey", err)
+	}
+	identifier := strings.Join(parts[2:], "/")
+
+	events, err := r.server.GetStorage().QueryEvents(ctx, nostr.Filter{
+		Authors: []string{pubkey},
+		Kinds:   []int{kind},
+		Tags:    nostr.TagMap{"d": []string{identifier}},
+		Limit:   1,
+	})
+	if err != nil || len(events) == 0 {
+		return r.errorResponse(ErrorNotFound, fmt.Sprintf("Not found: %s", identifier), err)
+	}
+
+	return r.handleNote(ctx, events[0].ID)
+}
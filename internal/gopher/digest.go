@@ -0,0 +1,61 @@
+package gopher
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/sandwich/nophr/internal/digest"
+)
+
+// handleDigest renders the daily digest: new posts, the posts with the most
+// interactions during the day and the followers gained
+func (r *Router) handleDigest(ctx context.Context) []byte {
+	d, err := r.digests.Current(ctx, time.Now())
+	if err != nil {
+		return r.errorResponse(ErrorInternal, "Error building digest", err)
+	}
+
+	gmap := NewGophermap(r.host, r.port)
+	gmap.AddInfo("Daily digest: " + d.Period())
+	gmap.AddSpacer()
+
+	gmap.AddInfo(fmt.Sprintf("New posts (%d)", len(d.NewPosts)))
+	if len(d.NewPosts) == 0 {
+		gmap.AddInfo("   Nothing new posted.")
+	}
+	for _, post := range d.NewPosts {
+		title := menuTextReplacer.Replace(digest.Summary(post, 60))
+		if post.Kind == 30023 {
+			title = "[Article] " + title
+		}
+		gmap.AddTextFile(title, "/note/"+post.ID)
+	}
+	gmap.AddSpacer()
+
+	gmap.AddInfo("Top interactions")
+	if len(d.TopPosts) == 0 {
+		gmap.AddInfo("   No interactions this day.")
+	}
+	for _, post := range d.TopPosts {
+		gmap.AddInfo("   " + digest.Engagement(post.Aggregates))
+		gmap.AddTextFile(menuTextReplacer.Replace(digest.Summary(post.Event, 60)), "/note/"+post.Event.ID)
+	}
+	gmap.AddSpacer()
+
+	gmap.AddInfo("Followers")
+	gmap.AddInfo("   " + d.FollowerSummary())
+	for _, contact := range d.NewFollowers {
+		name := menuTextReplacer.Replace(contact.Name)
+		if name == "" {
+			name = r.renderer.authorLabel(ctx, contact.Pubkey)
+		}
+		gmap.AddTextFile("New: "+name, "/profile/"+contact.Pubkey)
+	}
+	gmap.AddSpacer()
+
+	gmap.AddDirectory("Followers", "/followers")
+	gmap.AddDirectory("⌂ Home", "/")
+
+	return gmap.Bytes()
+}
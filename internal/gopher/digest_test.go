@@ -0,0 +1,67 @@
+package gopher
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/nbd-wtf/go-nostr"
+	"github.com/nbd-wtf/go-nostr/nip19"
+	"github.com/sandwich/nophr/internal/aggregates"
+	"github.com/sandwich/nophr/internal/config"
+	"github.com/sandwich/nophr/internal/storage"
+)
+
+func TestDigestRoute(t *testing.T) {
+	cfg := config.Default()
+	cfg.Identity.Npub = "npub1nq3zgtqruwhnz0xx40gh4a4fkamlr2sc7ke5wqs2s3nyv2fpy9esg4hdwq"
+	cfg.Storage.SQLitePath = filepath.Join(t.TempDir(), "test.db")
+
+	_, decoded, err := nip19.Decode(cfg.Identity.Npub)
+	if err != nil {
+		t.Fatalf("Failed to decode npub: %v", err)
+	}
+	ownerHex := decoded.(string)
+
+	ctx := context.Background()
+	st, err := storage.New(ctx, &cfg.Storage)
+	if err != nil {
+		t.Fatalf("Failed to create storage: %v", err)
+	}
+	defer st.Close()
+
+	// An hour before the current digest's cutoff falls inside its day
+	inDay := nostr.Timestamp(cfg.Display.Digest.LatestRun(time.Now()).Add(-time.Hour).Unix())
+	follower, _ := nostr.GetPublicKey(nostr.GeneratePrivateKey())
+	note := &nostr.Event{Kind: 1, PubKey: ownerHex, CreatedAt: inDay, Content: "digest me"}
+	note.ID = note.GetID()
+	events := []*nostr.Event{
+		note,
+		{Kind: 0, PubKey: follower, CreatedAt: 100, Content: `{"name":"alice"}`},
+		{Kind: 3, PubKey: follower, CreatedAt: inDay, Tags: nostr.Tags{{"p", ownerHex}}},
+		{Kind: 7, PubKey: follower, CreatedAt: inDay, Content: "+", Tags: nostr.Tags{{"e", note.ID}, {"p", ownerHex}}},
+	}
+	for _, event := range events {
+		event.ID = event.GetID()
+		if err := st.StoreEvent(ctx, event); err != nil {
+			t.Fatalf("Failed to store event: %v", err)
+		}
+	}
+
+	server := New(&cfg.Protocols.Gopher, cfg, st, "localhost", aggregates.NewManager(st, cfg))
+	page := string(server.router.Route("/digest"))
+
+	for _, want := range []string{
+		"New posts (1)",
+		"0digest me\t/note/" + note.ID,
+		"i   1 reaction\t",
+		"i   1 follower (+1 today)\t",
+		"0New: alice\t/profile/" + follower,
+	} {
+		if !strings.Contains(page, want) {
+			t.Errorf("Digest missing %q, got: %s", want, page)
+		}
+	}
+}
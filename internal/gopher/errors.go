@@ -0,0 +1,47 @@
+package gopher
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+)
+
+// ErrorKind classifies a Gopher error; it prefixes the error's reference code
+type ErrorKind string
+
+const (
+	ErrorBadRequest  ErrorKind = "BAD"
+	ErrorNotFound    ErrorKind = "NOTFOUND"
+	ErrorInternal    ErrorKind = "INTERNAL"
+	ErrorRateLimited ErrorKind = "RATE"
+)
+
+// newErrorCode returns a short reference code like "NOTFOUND-3f9a1c" that
+// appears both in the error item and the server log
+func newErrorCode(kind ErrorKind) string {
+	buf := make([]byte, 3)
+	if _, err := rand.Read(buf); err != nil {
+		return string(kind)
+	}
+	return fmt.Sprintf("%s-%s", kind, hex.EncodeToString(buf))
+}
+
+// reportError logs an error under a fresh reference code and adds a '3' item for it,
+// followed by the operator contact if one is configured. Internal error details
+// only go to the log; the client sees message and the code.
+func (s *Server) reportError(gmap *Gophermap, kind ErrorKind, message string, err error) string {
+	code := newErrorCode(kind)
+
+	if err != nil {
+		fmt.Printf("Gopher error %s: %s: %v\n", code, message, err)
+	} else {
+		fmt.Printf("Gopher error %s: %s\n", code, message)
+	}
+
+	gmap.AddError(fmt.Sprintf("%s [%s]", message, code))
+	if operator := s.fullConfig.Site.Operator; operator != "" {
+		gmap.AddInfo(fmt.Sprintf("Report problems to %s, quoting %s", operator, code))
+	}
+
+	return code
+}
@@ -0,0 +1,48 @@
+package gopher
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/nbd-wtf/go-nostr"
+	"github.com/sandwich/nophr/internal/feeds"
+)
+
+// handleFeed serves the Atom feed for a listing as a text item.
+// Gemini subscription pages are left to the Gemini server.
+func (r *Router) handleFeed(ctx context.Context, path, listing string) []byte {
+	feed, err := r.feeds.Build(ctx, listing)
+	if errors.Is(err, feeds.ErrNoFeed) {
+		return r.errorResponse(ErrorNotFound, fmt.Sprintf("No feed for %s", listing), nil)
+	}
+	if err != nil {
+		return r.errorResponse(ErrorInternal, "Failed to build feed", err)
+	}
+
+	links := feeds.Links{
+		Self: r.gopherURL(ItemTypeTextFile, path),
+		Page: r.gopherURL(ItemTypeDirectory, listing),
+		Entry: func(event *nostr.Event) string {
+			if event.Kind == 30023 {
+				return r.gopherURL(ItemTypeTextFile, articleTextSelector(event))
+			}
+			return r.gopherURL(ItemTypeTextFile, "/note/"+event.ID)
+		},
+	}
+
+	return append(feed.Atom(links), []byte(".\r\n")...)
+}
+
+// gopherURL returns the gopher:// URL (RFC 4266) of a selector on this server
+func (r *Router) gopherURL(itemType ItemType, selector string) string {
+	host := r.host
+	if r.port != 70 {
+		host = fmt.Sprintf("%s:%d", r.host, r.port)
+	}
+	if !strings.HasPrefix(selector, "/") {
+		selector = "/" + selector
+	}
+	return fmt.Sprintf("gopher://%s/%c%s", host, itemType, selector)
+}
@@ -40,10 +40,11 @@ type Item struct {
 
 // String formats an Item as a gophermap line per RFC 1436
 // Format: Type + Display + TAB + Selector + TAB + Host + TAB + Port + CRLF
+// Tabs and line breaks in the display string are replaced so they can't split the line.
 func (i *Item) String() string {
 	return fmt.Sprintf("%c%s\t%s\t%s\t%d\r\n",
 		i.Type,
-		i.Display,
+		menuTextReplacer.Replace(i.Display),
 		i.Selector,
 		i.Host,
 		i.Port,
@@ -92,6 +93,11 @@ func (g *Gophermap) AddTextFile(display, selector string) {
 	g.AddItem(ItemTypeTextFile, display, selector)
 }
 
+// AddURL adds a link to a web resource, using the "URL:" selector convention
+func (g *Gophermap) AddURL(display, url string) {
+	g.AddItem(ItemTypeHTML, display, "URL:"+url)
+}
+
 // AddError adds an error item
 func (g *Gophermap) AddError(message string) {
 	g.AddItem(ItemTypeError, message, "error")
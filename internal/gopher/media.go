@@ -0,0 +1,48 @@
+package gopher
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/nbd-wtf/go-nostr"
+	"github.com/sandwich/nophr/internal/config"
+	"github.com/sandwich/nophr/internal/entities"
+	"github.com/sandwich/nophr/internal/idn"
+	"github.com/sandwich/nophr/internal/mediaproxy"
+)
+
+// addMediaLinks lists an entry's media and links below it. They are type h
+// items unless rendering.gopher.media_links is text, for clients that mangle
+// type h; then they are info lines showing the URL. With the media proxy on,
+// images are type I items and video and audio type 9, served from /media/.
+func (r *Router) addMediaLinks(gmap *Gophermap, event *nostr.Event) {
+	for _, link := range r.renderer.blocklist.Filter(entities.MediaLinks(event)) {
+		display := menuTextReplacer.Replace(fmt.Sprintf("   %s: %s", link.Label, idn.DisplayURL(link.URL)))
+		if r.server.fullConfig.Rendering.Gopher.MediaLinks == config.MediaLinksText {
+			gmap.AddInfo(display)
+			continue
+		}
+		if selector, ok := r.server.media.Path(link); ok {
+			itemType := ItemTypeBinary
+			if link.Label == "Image" {
+				itemType = ItemTypeImage
+			}
+			gmap.AddItem(itemType, display, selector)
+			continue
+		}
+		gmap.AddURL(display, idn.LinkURL(link.URL))
+	}
+}
+
+// handleMedia serves a proxied media file as-is, with no gophermap around it
+func (r *Router) handleMedia(ctx context.Context, key string) []byte {
+	media, err := r.server.media.Fetch(ctx, key)
+	if errors.Is(err, mediaproxy.ErrNotFound) {
+		return r.errorResponse(ErrorNotFound, "Media not found", nil)
+	}
+	if err != nil {
+		return r.errorResponse(ErrorInternal, "Media unavailable", err)
+	}
+	return media.Data
+}
@@ -0,0 +1,68 @@
+package gopher
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/nbd-wtf/go-nostr"
+	"github.com/sandwich/nophr/internal/aggregates"
+	"github.com/sandwich/nophr/internal/config"
+	"github.com/sandwich/nophr/internal/mediaproxy"
+	"github.com/sandwich/nophr/internal/sections"
+	"github.com/sandwich/nophr/internal/storage"
+)
+
+func TestSectionMediaLinks(t *testing.T) {
+	cfg := config.Default()
+	cfg.Identity.Npub = "npub1nq3zgtqruwhnz0xx40gh4a4fkamlr2sc7ke5wqs2s3nyv2fpy9esg4hdwq"
+	cfg.Storage.SQLitePath = filepath.Join(t.TempDir(), "test.db")
+	cfg.Sections = []config.SectionConfig{
+		{Name: "photos", Path: "/photos", Title: "Photos", Filters: config.SectionFilterConfig{Kinds: []int{1}}, MediaLinks: true},
+		{Name: "plain", Path: "/plain", Title: "Plain", Filters: config.SectionFilterConfig{Kinds: []int{1}}},
+	}
+
+	ctx := context.Background()
+	st, err := storage.New(ctx, &cfg.Storage)
+	if err != nil {
+		t.Fatalf("Failed to create storage: %v", err)
+	}
+	defer st.Close()
+
+	note := &nostr.Event{Kind: 1, CreatedAt: 100, Content: "sunset https://example.com/sunset.jpg"}
+	if err := note.Sign(nostr.GeneratePrivateKey()); err != nil {
+		t.Fatalf("Failed to sign note: %v", err)
+	}
+	if err := st.StoreEvent(ctx, note); err != nil {
+		t.Fatalf("Failed to store note: %v", err)
+	}
+
+	server := New(&cfg.Protocols.Gopher, cfg, st, "localhost", aggregates.NewManager(st, cfg))
+	if err := sections.LoadFromConfig(server.GetSectionManager(), cfg.Sections); err != nil {
+		t.Fatalf("Failed to load sections: %v", err)
+	}
+
+	item := "h   Image: https://example.com/sunset.jpg\tURL:https://example.com/sunset.jpg\t"
+	if photos := string(server.router.Route("/photos")); !strings.Contains(photos, item) {
+		t.Errorf("Expected a type h item for the image, got: %s", photos)
+	}
+	if plain := string(server.router.Route("/plain")); strings.Contains(plain, "Image:") {
+		t.Errorf("Sections without media_links should not list links, got: %s", plain)
+	}
+
+	// The media proxy serves images as type I items from its own selectors
+	cfg.Rendering.MediaProxy.Enabled = true
+	proxied := "I   Image: https://example.com/sunset.jpg\t/media/" + mediaproxy.Key("https://example.com/sunset.jpg") + "\t"
+	if photos := string(server.router.Route("/photos")); !strings.Contains(photos, proxied) {
+		t.Errorf("Expected a type I item for the proxied image, got: %s", photos)
+	}
+	cfg.Rendering.MediaProxy.Enabled = false
+
+	// The plain-text fallback shows the URL on an info line instead
+	cfg.Rendering.Gopher.MediaLinks = config.MediaLinksText
+	photos := string(server.router.Route("/photos"))
+	if strings.Contains(photos, "\nh") || !strings.Contains(photos, "i   Image: https://example.com/sunset.jpg\tfake\t") {
+		t.Errorf("Expected an info line for the image, got: %s", photos)
+	}
+}
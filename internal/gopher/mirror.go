@@ -0,0 +1,191 @@
+package gopher
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/nbd-wtf/go-nostr"
+	"github.com/sandwich/nophr/internal/aggregates"
+	nostrclient "github.com/sandwich/nophr/internal/nostr"
+	"github.com/sandwich/nophr/internal/sections"
+)
+
+// handleMirror serves the subtree of a mirror section: a home page, the
+// author's notes, articles and profile, and each of their notes under
+// <path>/note/<id>
+func (r *Router) handleMirror(ctx context.Context, section *sections.Section, parts []string) []byte {
+	base := strings.TrimSuffix(section.Path, "/")
+	author := section.MirrorAuthor()
+
+	before, remaining, err := aggregates.CursorFromParts(parts)
+	if err != nil {
+		return r.errorResponse(ErrorBadRequest, "Invalid page cursor", err)
+	}
+	if len(remaining) == 0 {
+		return r.handleMirrorHome(ctx, section, base)
+	}
+
+	switch remaining[0] {
+	case "notes":
+		page, err := r.server.GetQueryHelper().GetAuthorNotesPage(ctx, author, before, section.Limit)
+		if err != nil {
+			return r.errorResponse(ErrorInternal, "Error loading notes", err)
+		}
+		return r.renderMirrorListing(ctx, section, base, "Notes", "/notes", page)
+
+	case "articles":
+		page, err := r.server.GetQueryHelper().GetAuthorArticlesPage(ctx, author, before, section.Limit)
+		if err != nil {
+			return r.errorResponse(ErrorInternal, "Error loading articles", err)
+		}
+		return r.renderMirrorListing(ctx, section, base, "Articles", "/articles", page)
+
+	case "profile":
+		return r.handleProfile(ctx, author)
+
+	case "note":
+		if len(remaining) < 2 {
+			return r.errorResponse(ErrorBadRequest, "Missing note ID", nil)
+		}
+		return r.handleMirrorNote(ctx, author, remaining[1])
+	}
+
+	return r.errorResponse(ErrorNotFound, fmt.Sprintf("Unknown selector: %s/%s", base, strings.Join(parts, "/")), nil)
+}
+
+// handleMirrorHome renders a mirror's home page: the author's name and bio,
+// links to their notes, articles and profile, and their latest posts
+func (r *Router) handleMirrorHome(ctx context.Context, section *sections.Section, base string) []byte {
+	author := section.MirrorAuthor()
+	name := menuTextReplacer.Replace(r.renderer.resolver.AuthorName(ctx, author))
+
+	gmap := NewGophermap(r.host, r.port)
+	r.addHeaderToGophermap(gmap, section.Name)
+
+	title := section.Title
+	if title == "" {
+		title = name
+	}
+	gmap.AddInfo(menuTextReplacer.Replace(title))
+	description := section.Description
+	if description == "" {
+		description = r.mirrorAbout(ctx, author)
+	}
+	for _, line := range strings.Split(description, "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			gmap.AddInfo(menuTextReplacer.Replace(line))
+		}
+	}
+	gmap.AddSpacer()
+
+	gmap.AddDirectory("Notes", base+"/notes")
+	gmap.AddDirectory("Articles", base+"/articles")
+	gmap.AddTextFile("Profile", base+"/profile")
+	gmap.AddSpacer()
+
+	posts, err := r.server.GetQueryHelper().GetNotesByAuthor(ctx, author, section.Limit)
+	if err != nil {
+		r.server.reportError(gmap, ErrorInternal, "Error loading posts", err)
+		gmap.AddSpacer()
+	} else if len(posts) == 0 {
+		gmap.AddInfo("No notes or articles stored for " + name + " yet.")
+		gmap.AddSpacer()
+	} else {
+		gmap.AddInfo("Latest posts")
+		gmap.AddSpacer()
+		for _, post := range posts {
+			r.addMirrorEntry(gmap, base, post)
+		}
+	}
+
+	gmap.AddDirectory("⌂ Home", "/")
+	r.addFooterToGophermap(gmap, section.Name)
+
+	return gmap.Bytes()
+}
+
+// renderMirrorListing renders one page of a mirrored author's notes or articles
+func (r *Router) renderMirrorListing(ctx context.Context, section *sections.Section, base, title, view string, page *aggregates.EventPage) []byte {
+	name := menuTextReplacer.Replace(r.renderer.resolver.AuthorName(ctx, section.MirrorAuthor()))
+
+	gmap := NewGophermap(r.host, r.port)
+	r.addHeaderToGophermap(gmap, section.Name)
+	gmap.AddInfo(title + " by " + name)
+	gmap.AddSpacer()
+
+	if len(page.Events) == 0 {
+		gmap.AddInfo("Nothing stored yet.")
+		gmap.AddSpacer()
+	}
+	for _, item := range page.Events {
+		if aggregates.IsRepost(item.Event) {
+			r.addRepostItem(ctx, gmap, item)
+			continue
+		}
+		r.addMirrorEntry(gmap, base, item)
+	}
+
+	gmap.AddDirectory("← "+name, base)
+	r.addPaginationLinks(gmap, base+view, page)
+	r.addFooterToGophermap(gmap, section.Name)
+
+	return gmap.Bytes()
+}
+
+// addMirrorEntry lists a note or article, linked below the mirror's path
+func (r *Router) addMirrorEntry(gmap *Gophermap, base string, item *aggregates.EnrichedEvent) {
+	var text string
+	if item.Event.Kind == 30023 {
+		text = "[Article] " + sections.ArticleTitle(item.Event)
+	} else {
+		text = strings.Split(item.Event.Content, "\n")[0]
+	}
+	text = menuTextReplacer.Replace(text)
+	if len(text) > 60 {
+		text = text[:57] + "..."
+	}
+
+	gmap.AddInfo("   " + formatTimestamp(item.Event.CreatedAt))
+	if item.Aggregates != nil && item.Aggregates.HasInteractions() {
+		if aggText := r.renderer.renderAggregates(item.Aggregates); aggText != "" {
+			gmap.AddInfo("   " + aggText)
+		}
+	}
+	gmap.AddTextFile(text, base+"/note/"+item.Event.ID)
+	gmap.AddSpacer()
+}
+
+// handleMirrorNote renders one of the mirrored author's notes. Other authors'
+// events aren't served under the mirror's path.
+func (r *Router) handleMirrorNote(ctx context.Context, author, noteID string) []byte {
+	if err := r.server.GetSanitizer().ValidateEventID(noteID); err != nil {
+		return r.errorResponse(ErrorBadRequest, "Invalid note ID", err)
+	}
+
+	events, err := r.server.GetStorage().QueryEvents(ctx, nostr.Filter{
+		IDs:     []string{noteID},
+		Authors: []string{author},
+	})
+	if err != nil || len(events) == 0 {
+		return r.errorResponse(ErrorNotFound, fmt.Sprintf("Note not found: %s", noteID), err)
+	}
+
+	return r.handleNote(ctx, noteID)
+}
+
+// mirrorAbout returns the bio from the author's stored profile, or ""
+func (r *Router) mirrorAbout(ctx context.Context, pubkey string) string {
+	events, err := r.server.GetStorage().QueryEvents(ctx, nostr.Filter{
+		Kinds:   []int{0},
+		Authors: []string{pubkey},
+		Limit:   1,
+	})
+	if err != nil || len(events) == 0 {
+		return ""
+	}
+	if profile := nostrclient.ParseProfile(events[0]); profile != nil {
+		return profile.About
+	}
+	return ""
+}
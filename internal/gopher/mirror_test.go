@@ -0,0 +1,91 @@
+package gopher
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/nbd-wtf/go-nostr"
+	"github.com/nbd-wtf/go-nostr/nip19"
+	"github.com/sandwich/nophr/internal/aggregates"
+	"github.com/sandwich/nophr/internal/config"
+	"github.com/sandwich/nophr/internal/sections"
+	"github.com/sandwich/nophr/internal/storage"
+)
+
+func TestMirrorSection(t *testing.T) {
+	aliceKey, bobKey := nostr.GeneratePrivateKey(), nostr.GeneratePrivateKey()
+	alice, _ := nostr.GetPublicKey(aliceKey)
+	aliceNpub, _ := nip19.EncodePublicKey(alice)
+
+	cfg := config.Default()
+	cfg.Identity.Npub = "npub1nq3zgtqruwhnz0xx40gh4a4fkamlr2sc7ke5wqs2s3nyv2fpy9esg4hdwq"
+	cfg.Storage.SQLitePath = filepath.Join(t.TempDir(), "test.db")
+	cfg.Sections = []config.SectionConfig{
+		{Name: "alice", Path: "/mirror/alice", Type: "mirror", Filters: config.SectionFilterConfig{Authors: []string{aliceNpub}}},
+	}
+
+	ctx := context.Background()
+	st, err := storage.New(ctx, &cfg.Storage)
+	if err != nil {
+		t.Fatalf("Failed to create storage: %v", err)
+	}
+	defer st.Close()
+
+	profile := &nostr.Event{Kind: 0, CreatedAt: 50, Content: `{"name":"alice","about":"Gardener and writer"}`}
+	note := &nostr.Event{Kind: 1, CreatedAt: 100, Content: "Tomatoes are in"}
+	article := &nostr.Event{Kind: 30023, CreatedAt: 200, Content: "Long text", Tags: nostr.Tags{{"d", "soil"}, {"title", "On soil"}}}
+	for _, event := range []*nostr.Event{profile, note, article} {
+		if err := event.Sign(aliceKey); err != nil {
+			t.Fatalf("Failed to sign event: %v", err)
+		}
+	}
+	other := &nostr.Event{Kind: 1, CreatedAt: 150, Content: "Not alice"}
+	if err := other.Sign(bobKey); err != nil {
+		t.Fatalf("Failed to sign event: %v", err)
+	}
+	for _, event := range []*nostr.Event{profile, note, article, other} {
+		if err := st.StoreEvent(ctx, event); err != nil {
+			t.Fatalf("Failed to store event: %v", err)
+		}
+	}
+
+	server := New(&cfg.Protocols.Gopher, cfg, st, "localhost", aggregates.NewManager(st, cfg))
+	if err := sections.LoadFromConfig(server.GetSectionManager(), cfg.Sections); err != nil {
+		t.Fatalf("Failed to load sections: %v", err)
+	}
+
+	home := string(server.router.Route("/mirror/alice"))
+	for _, want := range []string{
+		"Gardener and writer",
+		"1Notes\t/mirror/alice/notes\t",
+		"1Articles\t/mirror/alice/articles\t",
+		"0Profile\t/mirror/alice/profile\t",
+		"0[Article] On soil\t/mirror/alice/note/" + article.ID + "\t",
+		"0Tomatoes are in\t/mirror/alice/note/" + note.ID + "\t",
+	} {
+		if !strings.Contains(home, want) {
+			t.Errorf("home page missing %q, got: %s", want, home)
+		}
+	}
+	if strings.Contains(home, "Not alice") {
+		t.Errorf("home page lists another author's note: %s", home)
+	}
+
+	if notes := string(server.router.Route("/mirror/alice/notes")); !strings.Contains(notes, "/mirror/alice/note/"+note.ID) || strings.Contains(notes, "On soil") {
+		t.Errorf("notes page should list only notes, got: %s", notes)
+	}
+	if articles := string(server.router.Route("/mirror/alice/articles")); !strings.Contains(articles, "[Article] On soil") || strings.Contains(articles, "Tomatoes") {
+		t.Errorf("articles page should list only articles, got: %s", articles)
+	}
+	if page := string(server.router.Route("/mirror/alice/profile")); !strings.Contains(page, "Gardener and writer") {
+		t.Errorf("profile page = %s", page)
+	}
+	if page := string(server.router.Route("/mirror/alice/note/" + note.ID)); !strings.Contains(page, "Tomatoes are in") {
+		t.Errorf("note page = %s", page)
+	}
+	if page := string(server.router.Route("/mirror/alice/note/" + other.ID)); !strings.HasPrefix(page, "3") {
+		t.Errorf("another author's note should not be served under the mirror, got: %s", page)
+	}
+}
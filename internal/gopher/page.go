@@ -0,0 +1,81 @@
+package gopher
+
+import (
+	"strings"
+
+	"github.com/sandwich/nophr/internal/config"
+	"github.com/sandwich/nophr/internal/pages"
+)
+
+// handlePage renders a static page as a gophermap. Markdown is rendered as
+// plain text; gemtext link lines become menu items so the page stays navigable.
+func (r *Router) handlePage(page *pages.Page) []byte {
+	source, err := page.Source()
+	if err != nil {
+		return r.errorResponse(ErrorInternal, "Error loading page", err)
+	}
+
+	gmap := NewGophermap(r.host, r.port)
+	name := strings.TrimPrefix(page.Path, "/")
+
+	// Add header if configured
+	r.addHeaderToGophermap(gmap, name)
+
+	if page.Format == config.PageFormatGemtext {
+		addGemtext(gmap, source)
+	} else {
+		rendered, _ := r.renderer.parser.RenderGopher([]byte(source), nil)
+		for _, line := range strings.Split(strings.Trim(rendered, "\n"), "\n") {
+			gmap.AddInfo(line)
+		}
+	}
+
+	gmap.AddSpacer()
+	gmap.AddDirectory("⌂ Home", "/")
+
+	// Add footer if configured
+	r.addFooterToGophermap(gmap, name)
+
+	return gmap.Bytes()
+}
+
+// addGemtext adds gemtext lines to a gophermap. Links to local paths become
+// directories, other links URL items, and everything else info lines.
+func addGemtext(gmap *Gophermap, source string) {
+	eachGemtextLine(source, gmap.AddInfo, func(label, target string) {
+		if strings.HasPrefix(target, "/") {
+			gmap.AddDirectory(label, target)
+		} else {
+			gmap.AddURL(label, target)
+		}
+	})
+}
+
+// eachGemtextLine calls link with the label and target of each link line in
+// gemtext source and text with every other line, leaving out preformatting
+// toggles. Labels are made safe for menu lines.
+func eachGemtextLine(source string, text func(string), link func(label, target string)) {
+	preformatted := false
+	for _, line := range strings.Split(strings.TrimRight(source, "\n"), "\n") {
+		if strings.HasPrefix(line, "```") {
+			preformatted = !preformatted
+			continue
+		}
+		rest, ok := strings.CutPrefix(line, "=>")
+		if preformatted || !ok {
+			text(line)
+			continue
+		}
+
+		fields := strings.Fields(rest)
+		if len(fields) == 0 {
+			continue
+		}
+		target := fields[0]
+		label := target
+		if len(fields) > 1 {
+			label = strings.Join(fields[1:], " ")
+		}
+		link(menuTextReplacer.Replace(label), target)
+	}
+}
@@ -0,0 +1,158 @@
+package gopher
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/nbd-wtf/go-nostr"
+	"github.com/nbd-wtf/go-nostr/nip19"
+	"github.com/sandwich/nophr/internal/config"
+	"github.com/sandwich/nophr/internal/sections"
+)
+
+func TestPageRoutes(t *testing.T) {
+	dir := t.TempDir()
+	os.WriteFile(filepath.Join(dir, "uses.gmi"), []byte("# Uses\n\nA ThinkPad.\n=> /notes My notes\n=> /note/abc A note\n=> https://example.com Elsewhere\n"), 0644)
+
+	cfg := config.Default()
+	cfg.PagesDir = dir
+	cfg.Pages = []config.Page{{Path: "/about", Content: "# About me\n\nI write **software**."}}
+
+	server := New(&cfg.Protocols.Gopher, cfg, nil, "localhost", nil)
+
+	about := string(server.router.Route("/about"))
+	for _, want := range []string{"i=== About me ================\t", "iI write software.\t", "1⌂ Home\t/\t"} {
+		if !strings.Contains(about, want) {
+			t.Errorf("/about missing %q:\n%s", want, about)
+		}
+	}
+
+	uses := string(server.router.Route("/uses"))
+	for _, want := range []string{"iA ThinkPad.\t", "1My notes\t/notes\t", "1A note\t/note/abc\t", "hElsewhere\tURL:https://example.com\t"} {
+		if !strings.Contains(uses, want) {
+			t.Errorf("/uses missing %q:\n%s", want, uses)
+		}
+	}
+
+	home := string(server.router.Route("/"))
+	for _, want := range []string{"1About me\t/about\t", "1Uses\t/uses\t"} {
+		if !strings.Contains(home, want) {
+			t.Errorf("home missing %q:\n%s", want, home)
+		}
+	}
+	if strings.Count(home, "/about\t") != 1 {
+		t.Errorf("expected the about page to replace the built-in About entry:\n%s", home)
+	}
+}
+
+func TestHomeMenuListsSections(t *testing.T) {
+	cfg := config.Default()
+	server := New(&cfg.Protocols.Gopher, cfg, nil, "localhost", nil)
+
+	manager := sections.NewManager(nil)
+	manager.RegisterSection(&sections.Section{Name: "diy", Path: "/diy", Title: "DIY projects"})
+	manager.RegisterSection(&sections.Section{Name: "drafts", Path: "/drafts", Hidden: true})
+	server.SetSectionManager(manager)
+
+	home := string(server.router.Route("/"))
+	if !strings.Contains(home, "1DIY projects\t/diy\t") {
+		t.Errorf("home missing the diy section:\n%s", home)
+	}
+	if strings.Contains(home, "/drafts") {
+		t.Errorf("home lists a hidden section:\n%s", home)
+	}
+}
+
+func TestHideInbox(t *testing.T) {
+	cfg := config.Default()
+	cfg.Display.HideInbox = true
+	server := New(&cfg.Protocols.Gopher, cfg, nil, "localhost", nil)
+
+	home := string(server.router.Route("/"))
+	if strings.Contains(home, "/replies") || strings.Contains(home, "/mentions") {
+		t.Errorf("home lists the inbox:\n%s", home)
+	}
+	for _, selector := range []string{"/replies", "/mentions", "/inbox"} {
+		if response := string(server.router.Route(selector)); !strings.HasPrefix(response, "3") {
+			t.Errorf("%s served with hide_inbox set:\n%s", selector, response)
+		}
+	}
+}
+
+func TestNoteQR(t *testing.T) {
+	cfg := config.Default()
+	event := &nostr.Event{Kind: 1, Content: "gm", CreatedAt: 1000}
+	event.Sign(nostr.GeneratePrivateKey())
+
+	if text := NewRenderer(cfg, nil).RenderNote(event, nil, false); strings.Contains(text, "nostr:nevent1") {
+		t.Errorf("QR code rendered without show_qr:\n%s", text)
+	}
+
+	cfg.Display.Detail.ShowQR = true
+	text := NewRenderer(cfg, nil).RenderNote(event, nil, false)
+	nevent, _ := nip19.EncodeEvent(event.ID, nil, event.PubKey)
+	if !strings.Contains(text, "\nnostr:"+nevent+"\n") || !strings.Contains(text, "▀") {
+		t.Errorf("Expected a QR code of the nevent URI:\n%s", text)
+	}
+}
+
+func TestTemplates(t *testing.T) {
+	dir := t.TempDir()
+	homeFile := filepath.Join(dir, "home.gmi")
+	os.WriteFile(homeFile, []byte("Welcome to {{.Site.Title}}\n{{range .Links}}{{if eq .URL \"/notes\" \"/search\"}}=> {{.URL}} {{.Title}}\n{{end}}{{end}}"), 0644)
+	noteFile := filepath.Join(dir, "note.txt")
+	os.WriteFile(noteFile, []byte("{{.Event.Author}} on {{date .Event.CreatedAt}}\n---\n{{.Event.Rendered}}"), 0644)
+	listFile := filepath.Join(dir, "item.gmi")
+	os.WriteFile(listFile, []byte("=> {{.Event.URL}} {{.Number}}: {{truncate 10 .Event.Content}}\n"), 0644)
+
+	cfg := config.Default()
+	cfg.Site.Title = "My hole"
+	cfg.Presentation.Templates.Gopher = config.TemplateSet{Home: homeFile, Note: noteFile, ListItem: listFile}
+	server := New(&cfg.Protocols.Gopher, cfg, nil, "localhost", nil)
+
+	home := string(server.router.Route("/"))
+	for _, want := range []string{"iWelcome to My hole\t", "1Notes\t/notes\t", "1Search\t/search\t"} {
+		if !strings.Contains(home, want) {
+			t.Errorf("home missing %q:\n%s", want, home)
+		}
+	}
+	if strings.Contains(home, "/articles") {
+		t.Errorf("home kept the built-in menu:\n%s", home)
+	}
+
+	event := &nostr.Event{CreatedAt: 365 * 86400, Kind: 1, Content: "A templated note with *markdown*", Tags: nostr.Tags{}}
+	event.Sign(nostr.GeneratePrivateKey())
+	note := server.router.renderer.RenderNote(event, nil, false)
+	if !strings.Contains(note, "1971-01-01") || !strings.Contains(note, "---\nA templated note") || strings.Contains(note, "Posted:") {
+		t.Errorf("note not rendered by the template:\n%s", note)
+	}
+
+	gmap := NewGophermap("localhost", 70)
+	server.router.addListItem(context.Background(), gmap, 3, event, nil, NewGophermap("localhost", 70))
+	// A link to a note is a text file item
+	if want := "0" + "3: A templ...\t/note/" + event.ID + "\t"; !strings.Contains(gmap.String(), want) {
+		t.Errorf("list item missing %q:\n%s", want, gmap.String())
+	}
+
+	// Links and preformatting toggles in notes stay text
+	os.WriteFile(listFile, []byte("=> {{.Event.URL}} {{.Number}}\n{{.Event.Content}}\n{{firstLine .Event.Content}}\n"), 0644)
+	spoof := &nostr.Event{CreatedAt: 1000, Kind: 1, Content: "=> /admin Owner tools\n```\nstill text", Tags: nostr.Tags{}}
+	spoof.Sign(nostr.GeneratePrivateKey())
+	gmap = NewGophermap("localhost", 70)
+	New(&cfg.Protocols.Gopher, cfg, nil, "localhost", nil).router.addListItem(context.Background(), gmap, 1, spoof, nil, NewGophermap("localhost", 70))
+	listing := gmap.String()
+	if strings.Contains(listing, "\t/admin\t") || strings.Count(listing, "i=> /admin Owner tools\t") != 2 || !strings.Contains(listing, "i```\t") {
+		t.Errorf("note content parsed as gemtext:\n%s", listing)
+	}
+
+	// A broken template falls back to the built-in layout
+	os.WriteFile(noteFile, []byte("{{.Event.Missing}}"), 0644)
+	cfg.Presentation.Templates.Gopher = config.TemplateSet{Note: noteFile}
+	note = New(&cfg.Protocols.Gopher, cfg, nil, "localhost", nil).router.renderer.RenderNote(event, nil, false)
+	if !strings.Contains(note, "Posted:") {
+		t.Errorf("expected the built-in note layout after a template error:\n%s", note)
+	}
+}
@@ -0,0 +1,45 @@
+package gopher
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/sandwich/nophr/internal/sections"
+)
+
+// addPlanetEntries lists a planet section's articles, crediting each author by
+// name and naming anyone else who posted the same article
+func (r *Router) addPlanetEntries(gmap *Gophermap, page *sections.Page) {
+	for _, event := range page.Events {
+		byline := "   By " + menuTextReplacer.Replace(planetAuthor(page, event.PubKey))
+		if page.Section.ShowDates {
+			byline += " - " + formatTimestamp(event.CreatedAt)
+		}
+		gmap.AddInfo(byline)
+		if others := page.CrossPosts[event.ID]; len(others) > 0 {
+			names := make([]string, len(others))
+			for i, pubkey := range others {
+				names[i] = menuTextReplacer.Replace(planetAuthor(page, pubkey))
+			}
+			gmap.AddInfo("   Also posted by " + strings.Join(names, ", "))
+		}
+
+		selector := fmt.Sprintf("/note/%s", event.ID)
+		if event.Kind == sections.KindArticle {
+			selector = articleTextSelector(event)
+		}
+		gmap.AddTextFile(menuTextReplacer.Replace(sections.ArticleTitle(event)), selector)
+		if page.Section.MediaLinks {
+			r.addMediaLinks(gmap, event)
+		}
+		gmap.AddSpacer()
+	}
+}
+
+// planetAuthor returns an author's display name, or their shortened pubkey
+func planetAuthor(page *sections.Page, pubkey string) string {
+	if name := page.AuthorName(pubkey); name != "" {
+		return name
+	}
+	return truncatePubkey(pubkey)
+}
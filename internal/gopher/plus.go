@@ -0,0 +1,177 @@
+package gopher
+
+import (
+	"bytes"
+	"fmt"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Gopher+ request markers, sent in a tab-separated field after the selector
+const (
+	plusData       = '+' // Item data preceded by a Gopher+ length header
+	plusAttributes = '!' // Attribute block for one item
+	plusDirectory  = '$' // Attribute blocks for every item in a menu
+)
+
+// plusHeader announces data terminated by a lone period line
+const plusHeader = "+-1\r\n"
+
+// splitGopherPlus separates a Gopher+ request marker from the selector.
+// Recognised markers are "+", "+<view>" (e.g. "+text/plain"), "!" and "$",
+// optionally followed by an attribute name ("!+ADMIN").
+func splitGopherPlus(line string) (string, byte) {
+	i := strings.LastIndex(line, "\t")
+	if i < 0 {
+		return line, 0
+	}
+
+	field := line[i+1:]
+	switch {
+	case field == "+" || (strings.HasPrefix(field, "+") && strings.Contains(field, "/")):
+		return line[:i], plusData
+	case field == "!" || strings.HasPrefix(field, "!+"):
+		return line[:i], plusAttributes
+	case field == "$" || strings.HasPrefix(field, "$+"):
+		return line[:i], plusDirectory
+	}
+
+	return line, 0
+}
+
+// plusResponse adapts a rendered response for Gopher+: menu items are marked as
+// Gopher+ items, and marked requests get a length header or attribute blocks
+func (s *Server) plusResponse(selector string, marker byte, body []byte) []byte {
+	items, isMenu := ParseMenu(body)
+	if isMenu {
+		body = markPlusItems(body)
+	}
+
+	switch marker {
+	case plusData:
+		return append([]byte(plusHeader), body...)
+
+	case plusAttributes:
+		item := s.describeItem(selector, isMenu)
+		return []byte(plusHeader + s.attributeBlock(item, len(body)) + ".\r\n")
+
+	case plusDirectory:
+		var sb strings.Builder
+		sb.WriteString(plusHeader)
+		for _, item := range items {
+			if item.Type == ItemTypeInfo || item.Type == ItemTypeError {
+				continue
+			}
+			sb.WriteString(s.attributeBlock(item, -1))
+		}
+		sb.WriteString(".\r\n")
+		return []byte(sb.String())
+	}
+
+	return body
+}
+
+// describeItem builds the menu item a selector would appear as
+func (s *Server) describeItem(selector string, isMenu bool) Item {
+	itemType := ItemTypeTextFile
+	if isMenu {
+		itemType = ItemTypeDirectory
+	}
+
+	display := path.Base(selector)
+	if selector == "" || selector == "/" {
+		display = s.fullConfig.Site.Title
+	}
+
+	return Item{Type: itemType, Display: display, Selector: selector, Host: s.host, Port: s.config.Port}
+}
+
+// attributeBlock renders the +INFO, +ADMIN and +VIEWS attributes of an item.
+// size is the rendered length in bytes, or -1 if it is not known.
+func (s *Server) attributeBlock(item Item, size int) string {
+	var sb strings.Builder
+
+	sb.WriteString(fmt.Sprintf("+INFO: %c%s\t%s\t%s\t%d\t+\r\n", item.Type, item.Display, item.Selector, item.Host, item.Port))
+
+	sb.WriteString("+ADMIN:\r\n")
+	sb.WriteString(fmt.Sprintf(" Admin: %s\r\n", s.adminContact()))
+	sb.WriteString(fmt.Sprintf(" Mod-Date: %s\r\n", modDate(time.Now().UTC())))
+
+	sb.WriteString("+VIEWS:\r\n")
+	if size >= 0 {
+		sb.WriteString(fmt.Sprintf(" %s: <%dk>\r\n", viewType(item.Type), (size+1023)/1024))
+	} else {
+		sb.WriteString(fmt.Sprintf(" %s\r\n", viewType(item.Type)))
+	}
+
+	return sb.String()
+}
+
+// modDate formats a time as Gopher+ expects: "Wed Jul 28 17:02:01 1993 <19930728170201>"
+func modDate(t time.Time) string {
+	return t.Format("Mon Jan 2 15:04:05 2006 <20060102150405>")
+}
+
+// viewType returns the Gopher+ view type for an item type
+func viewType(itemType ItemType) string {
+	switch itemType {
+	case ItemTypeDirectory, ItemTypeSearch:
+		return "application/gopher-menu"
+	case ItemTypeTextFile:
+		return "text/plain"
+	case ItemTypeHTML:
+		return "text/html"
+	default:
+		return "application/octet-stream"
+	}
+}
+
+// ParseMenu reads the items of a rendered gophermap.
+// It returns false if the body is not a menu (e.g. a text file).
+func ParseMenu(body []byte) ([]Item, bool) {
+	items := make([]Item, 0)
+
+	for _, line := range strings.Split(string(body), "\r\n") {
+		if line == "." {
+			return items, true
+		}
+		if line == "" {
+			continue
+		}
+
+		fields := strings.Split(line[1:], "\t")
+		if len(fields) < 4 {
+			return nil, false
+		}
+		port, err := strconv.Atoi(fields[3])
+		if err != nil {
+			return nil, false
+		}
+
+		items = append(items, Item{
+			Type:     ItemType(line[0]),
+			Display:  fields[0],
+			Selector: fields[1],
+			Host:     fields[2],
+			Port:     port,
+		})
+	}
+
+	return nil, false
+}
+
+// markPlusItems adds the Gopher+ field to selectable menu items
+func markPlusItems(body []byte) []byte {
+	lines := bytes.Split(body, []byte("\r\n"))
+	for i, line := range lines {
+		if len(line) == 0 || line[0] == byte(ItemTypeInfo) || line[0] == byte(ItemTypeError) || bytes.Equal(line, []byte(".")) {
+			continue
+		}
+		if bytes.Count(line, []byte("\t")) == 3 {
+			lines[i] = append(append([]byte{}, line...), "\t+"...)
+		}
+	}
+	return bytes.Join(lines, []byte("\r\n"))
+}
@@ -7,40 +7,89 @@ import (
 	"time"
 
 	"github.com/nbd-wtf/go-nostr"
+	"github.com/nbd-wtf/go-nostr/nip19"
 	"github.com/sandwich/nophr/internal/aggregates"
+	"github.com/sandwich/nophr/internal/annotate"
 	"github.com/sandwich/nophr/internal/config"
 	"github.com/sandwich/nophr/internal/entities"
+	"github.com/sandwich/nophr/internal/idn"
 	"github.com/sandwich/nophr/internal/markdown"
 	nostrclient "github.com/sandwich/nophr/internal/nostr"
 	"github.com/sandwich/nophr/internal/presentation"
+	"github.com/sandwich/nophr/internal/qr"
 	"github.com/sandwich/nophr/internal/storage"
 )
 
 // Renderer renders Nostr events as Gopher text
 type Renderer struct {
-	parser   *markdown.Parser
-	config   *config.Config
-	loader   *presentation.Loader
-	resolver *entities.Resolver
+	parser    *markdown.Parser
+	config    *config.Config
+	loader    *presentation.Loader
+	templates *presentation.Templates // presentation.templates.gopher
+	resolver  *entities.Resolver
+	blocklist *entities.DomainBlocklist // rendering.blocked_domains
+	authors   config.AuthorOverrides    // rendering.authors
+	names     *nostrclient.NameResolver // display.authors
+	storage   *storage.Storage
 }
 
 // NewRenderer creates a new event renderer
 func NewRenderer(cfg *config.Config, st *storage.Storage) *Renderer {
+	// Entity links point back into this Gopher server
+	links := entities.LinkContext{
+		Protocol: entities.ProtocolGopher,
+		Host:     cfg.Protocols.Gopher.Host,
+		Port:     cfg.Protocols.Gopher.Port,
+	}
+
+	blocklist := entities.NewDomainBlocklist(cfg.Rendering.BlockedDomains)
+	resolver := entities.NewResolver(st, links)
+	resolver.SetBlocklist(blocklist)
+
 	return &Renderer{
-		parser:   markdown.NewParser(),
-		config:   cfg,
-		loader:   presentation.NewLoader(cfg),
-		resolver: entities.NewResolver(st),
+		parser:    markdown.NewParser(),
+		config:    cfg,
+		loader:    presentation.NewLoader(cfg),
+		templates: presentation.NewTemplates(cfg, "gopher"),
+		resolver:  resolver,
+		blocklist: blocklist,
+		authors:   cfg.Rendering.AuthorOverrides(),
+		names:     nostrclient.NewNameResolver(st, cfg.Display.Authors),
+		storage:   st,
+	}
+}
+
+// authorLabel names an author in a listing or thread, e.g. "Alice
+// (npub1abcd...wxyz)", in the display.authors style
+func (r *Renderer) authorLabel(ctx context.Context, pubkey string) string {
+	return menuTextReplacer.Replace(r.names.Label(ctx, pubkey))
+}
+
+// annotationLines renders the event's keyed annotations with the formatters
+// registered in the annotate package, e.g. an article's reading time
+func (r *Renderer) annotationLines(ctx context.Context, eventID string) []string {
+	if r.storage == nil {
+		return nil
+	}
+	values, err := r.storage.GetAnnotationValues(ctx, eventID)
+	if err != nil {
+		return nil
 	}
+	return annotate.Format(values)
 }
 
-// RenderNote renders a note event as plain text
-func (r *Renderer) RenderNote(event *nostr.Event, agg *aggregates.EventAggregates) string {
+// RenderNote renders a note event as plain text. attributed adds the site's
+// author and license lines.
+func (r *Renderer) RenderNote(event *nostr.Event, agg *aggregates.EventAggregates, attributed bool) string {
 	var sb strings.Builder
 
 	// Header
-	sb.WriteString(fmt.Sprintf("Note by %s\n", truncatePubkey(event.PubKey)))
+	ctx := context.Background()
+	sb.WriteString(fmt.Sprintf("Note by %s\n", r.authorLabel(ctx, event.PubKey)))
 	sb.WriteString(fmt.Sprintf("Posted: %s\n", formatTimestamp(event.CreatedAt)))
+	for _, line := range r.annotationLines(ctx, event.ID) {
+		sb.WriteString(line + "\n")
+	}
 	sb.WriteString(strings.Repeat("=", 70))
 	sb.WriteString("\n\n")
 
@@ -48,7 +97,6 @@ func (r *Renderer) RenderNote(event *nostr.Event, agg *aggregates.EventAggregate
 	content := event.Content
 
 	// Resolve NIP-19 entities
-	ctx := context.Background()
 	content = r.resolver.ReplaceEntities(ctx, content, entities.GopherFormatter)
 
 	// Apply max content length if configured
@@ -67,21 +115,76 @@ func (r *Renderer) RenderNote(event *nostr.Event, agg *aggregates.EventAggregate
 		sb.WriteString(r.renderAggregatesForDetail(agg))
 	}
 
+	if r.config.Display.Detail.ShowQR {
+		sb.WriteString(r.renderQR(event))
+	}
+
+	if attributed {
+		sb.WriteString(r.RenderAttribution())
+	}
+
+	return r.applyTemplate(presentation.LayoutNote, sb.String(), presentation.PageData{
+		Event:      presentation.NewEventData(event, r.authorLabel(ctx, event.PubKey), rendered, "/note/"+event.ID),
+		Aggregates: r.aggregateData(agg),
+	})
+}
+
+// renderQR renders the note's nevent URI as a QR code that a phone can scan to
+// open it in a Nostr app, followed by the URI itself
+func (r *Renderer) renderQR(event *nostr.Event) string {
+	nevent, err := nip19.EncodeEvent(event.ID, nil, event.PubKey)
+	if err != nil {
+		return ""
+	}
+	code, err := qr.Encode([]byte("nostr:" + nevent))
+	if err != nil {
+		return ""
+	}
+	return "\nScan to open in a Nostr app:\n\n" + code.HalfBlocks() + "nostr:" + nevent + "\n"
+}
+
+// RenderAttribution renders the configured author and license as a text
+// footer, with URLs in angle brackets. It's empty if neither is configured.
+func (r *Renderer) RenderAttribution() string {
+	site := r.config.Site
+	if !site.HasAttribution() {
+		return ""
+	}
+
+	var sb strings.Builder
+	sb.WriteString("\n")
+	sb.WriteString(r.applyConfigSeparator("section"))
+	sb.WriteString("\n")
+	if author := site.AuthorName(); author != "" {
+		sb.WriteString(textLine("Written by "+author, site.AuthorURL))
+	}
+	if site.License.Name != "" {
+		sb.WriteString(textLine("Licensed under "+site.License.Name, site.License.URL))
+	}
 	return sb.String()
 }
 
+// textLine returns text followed by url in angle brackets, if there is one
+func textLine(text, url string) string {
+	if url == "" {
+		return text + "\n"
+	}
+	return fmt.Sprintf("%s <%s>\n", text, url)
+}
+
 // RenderProfile renders a profile event
 func (r *Renderer) RenderProfile(profileEvent *nostr.Event) string {
 	var sb strings.Builder
 
 	// Parse profile metadata
 	profile := nostrclient.ParseProfile(profileEvent)
+	data := presentation.PageData{Profile: presentation.NewProfileData(profileEvent.PubKey, profile)}
 	if profile == nil {
 		// Fallback for invalid profile
 		sb.WriteString(fmt.Sprintf("Profile: %s\n", truncatePubkey(profileEvent.PubKey)))
 		sb.WriteString(strings.Repeat("=", 70))
 		sb.WriteString("\n\nInvalid profile data\n")
-		return sb.String()
+		return r.applyTemplate(presentation.LayoutProfile, sb.String(), data)
 	}
 
 	// Header with display name
@@ -115,7 +218,7 @@ func (r *Renderer) RenderProfile(profileEvent *nostr.Event) string {
 
 	// Contact information
 	if profile.Website != "" {
-		sb.WriteString(fmt.Sprintf("\nWebsite: %s\n", profile.Website))
+		sb.WriteString(fmt.Sprintf("\nWebsite: %s\n", idn.DisplayURL(profile.Website)))
 	}
 	if profile.NIP05 != "" {
 		sb.WriteString(fmt.Sprintf("NIP-05: %s\n", profile.NIP05))
@@ -135,12 +238,14 @@ func (r *Renderer) RenderProfile(profileEvent *nostr.Event) string {
 		sb.WriteString(fmt.Sprintf("Banner: %s\n", profile.Banner))
 	}
 
-	return sb.String()
+	return r.applyTemplate(presentation.LayoutProfile, sb.String(), data)
 }
 
-// RenderThread renders a thread with indentation
-func (r *Renderer) RenderThread(root *aggregates.EnrichedEvent, replies []*aggregates.EnrichedEvent) string {
+// RenderThread renders a thread as a nested reply tree, indenting each level
+// by rendering.gopher.thread_indent
+func (r *Renderer) RenderThread(ctx context.Context, thread *aggregates.ThreadView) string {
 	var sb strings.Builder
+	root, replies := thread.Root, thread.Replies
 
 	sb.WriteString("Thread\n")
 	sb.WriteString(strings.Repeat("=", 70))
@@ -150,7 +255,7 @@ func (r *Renderer) RenderThread(root *aggregates.EnrichedEvent, replies []*aggre
 	sb.WriteString("● Root Post\n")
 	sb.WriteString(strings.Repeat("-", 70))
 	sb.WriteString("\n")
-	sb.WriteString(r.RenderNote(root.Event, root.Aggregates))
+	sb.WriteString(r.RenderNote(root.Event, root.Aggregates, false))
 	sb.WriteString("\n\n")
 
 	// Replies
@@ -159,13 +264,33 @@ func (r *Renderer) RenderThread(root *aggregates.EnrichedEvent, replies []*aggre
 		sb.WriteString(strings.Repeat("-", 70))
 		sb.WriteString("\n\n")
 
-		for i, reply := range replies {
-			sb.WriteString(fmt.Sprintf("  ↳ Reply %d by %s\n", i+1, truncatePubkey(reply.Event.PubKey)))
-			sb.WriteString(fmt.Sprintf("    %s\n\n", formatTimestamp(reply.Event.CreatedAt)))
+		// Nest replies by their NIP-10 parent, clamped to the configured depth
+		nodes := aggregates.FlattenThread(thread.Tree, r.config.Display.Limits.MaxThreadDepth)
+		authors := aggregates.ThreadAuthors(ctx, nodes, r.authorLabel)
+
+		// Quick index: each reply is prefixed with its anchor, so long threads
+		// can be navigated by searching for it in a terminal pager
+		sb.WriteString("Index\n")
+		for i, node := range nodes {
+			sb.WriteString(fmt.Sprintf("  Reply %d by %s → %s\n", i+1, authors[node.Event.Event.PubKey], node.Anchor()))
+		}
+		sb.WriteString("\n")
+
+		for i, node := range nodes {
+			reply := node.Event
+			indent := strings.Repeat(r.config.Rendering.Gopher.ThreadIndent, node.Depth)
+			sb.WriteString(fmt.Sprintf("%s%s ↳ Reply %d by %s\n", indent, node.Anchor(), i+1, authors[reply.Event.PubKey]))
+			sb.WriteString(fmt.Sprintf("%s  %s\n\n", indent, formatTimestamp(reply.Event.CreatedAt)))
+
+			// Collapsed authors get their first line only
+			if r.authors.Collapsed(reply.Event.PubKey) {
+				sb.WriteString(fmt.Sprintf("%s  %s [collapsed]\n\n", indent, r.summary(reply.Event.Content)))
+				continue
+			}
 
-			// Indent reply content
+			// Indent reply content under its header
 			content, _ := r.parser.RenderGopher([]byte(reply.Event.Content), nil)
-			indented := indentText(content, "    ")
+			indented := indentText(content, indent+"  ")
 			sb.WriteString(indented)
 			sb.WriteString("\n")
 		}
@@ -308,6 +433,18 @@ func indentText(text, indent string) string {
 	return strings.Join(lines, "\n")
 }
 
+// summary returns the first line of content, cut to display.limits.summary_length
+func (r *Renderer) summary(content string) string {
+	summaryLength := r.config.Display.Limits.SummaryLength
+	if summaryLength <= 0 {
+		summaryLength = 70 // Default fallback
+	}
+	if len(content) > summaryLength {
+		content = content[:summaryLength-len(r.config.Display.Limits.TruncateIndicator)] + r.config.Display.Limits.TruncateIndicator
+	}
+	return strings.Split(content, "\n")[0]
+}
+
 // RenderNoteList renders a list of notes with summaries
 func (r *Renderer) RenderNoteList(notes []*aggregates.EnrichedEvent, title string) []string {
 	lines := make([]string, 0)
@@ -321,22 +458,10 @@ func (r *Renderer) RenderNoteList(notes []*aggregates.EnrichedEvent, title strin
 		return lines
 	}
 
-	summaryLength := r.config.Display.Limits.SummaryLength
-	if summaryLength <= 0 {
-		summaryLength = 70 // Default fallback
-	}
-
 	for i, note := range notes {
-		// Extract first line of content as summary
-		content := note.Event.Content
-		if len(content) > summaryLength {
-			content = content[:summaryLength-len(r.config.Display.Limits.TruncateIndicator)] + r.config.Display.Limits.TruncateIndicator
-		}
-		firstLine := strings.Split(content, "\n")[0]
-
-		lines = append(lines, fmt.Sprintf("%d. %s", i+1, firstLine))
+		lines = append(lines, fmt.Sprintf("%d. %s", i+1, r.summary(note.Event.Content)))
 		lines = append(lines, fmt.Sprintf("   by %s - %s",
-			truncatePubkey(note.Event.PubKey),
+			r.authorLabel(context.Background(), note.Event.PubKey),
 			formatTimestamp(note.Event.CreatedAt)))
 
 		// Only show aggregates if configured for feed view
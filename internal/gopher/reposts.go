@@ -0,0 +1,65 @@
+package gopher
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/sandwich/nophr/internal/aggregates"
+)
+
+// repostLabel describes who reposted an event, e.g. "alice and 2 others reposted (3 reposts)"
+func (r *Renderer) repostLabel(ctx context.Context, reposters []string) string {
+	if len(reposters) == 0 {
+		return "Reposted"
+	}
+
+	name := r.authorLabel(ctx, reposters[0])
+	if len(reposters) == 1 {
+		return name + " reposted"
+	}
+	others := "others"
+	if len(reposters) == 2 {
+		others = "other"
+	}
+	return fmt.Sprintf("%s and %d %s reposted (%d reposts)", name, len(reposters)-1, others, len(reposters))
+}
+
+// addRepostItem adds a repost to a feed listing as "X reposted:" followed by the
+// reposted note, linking to the note itself rather than the repost
+func (r *Router) addRepostItem(ctx context.Context, gmap *Gophermap, item *aggregates.EnrichedEvent) {
+	gmap.AddInfo(fmt.Sprintf("   %s: - %s",
+		r.renderer.repostLabel(ctx, item.Reposters),
+		formatTimestamp(item.Event.CreatedAt)))
+
+	target := item.Reposted
+	if target == nil {
+		// The reposted note isn't stored; link its ID in case it arrives later
+		targetID := aggregates.RepostTarget(item.Event)
+		if targetID == "" {
+			gmap.AddInfo("   (reposted note unavailable)")
+			gmap.AddSpacer()
+			return
+		}
+		gmap.AddTextFile("(note not stored) "+truncatePubkey(targetID), fmt.Sprintf("/note/%s", targetID))
+		gmap.AddSpacer()
+		return
+	}
+
+	gmap.AddInfo(fmt.Sprintf("   By %s - %s",
+		r.renderer.authorLabel(ctx, target.PubKey),
+		formatTimestamp(target.CreatedAt)))
+
+	if item.Aggregates != nil && item.Aggregates.HasInteractions() {
+		if aggText := r.renderer.renderAggregates(item.Aggregates); aggText != "" {
+			gmap.AddInfo("   " + aggText)
+		}
+	}
+
+	content := target.Content
+	if len(content) > 60 {
+		content = content[:57] + "..."
+	}
+	gmap.AddTextFile(strings.Split(content, "\n")[0], fmt.Sprintf("/note/%s", target.ID))
+	gmap.AddSpacer()
+}
@@ -3,15 +3,40 @@ package gopher
 import (
 	"context"
 	"fmt"
-	"strconv"
 	"strings"
 
 	"github.com/nbd-wtf/go-nostr"
+	"github.com/sandwich/nophr/internal/activity"
 	"github.com/sandwich/nophr/internal/aggregates"
+	"github.com/sandwich/nophr/internal/digest"
+	"github.com/sandwich/nophr/internal/entities"
+	"github.com/sandwich/nophr/internal/feeds"
+	"github.com/sandwich/nophr/internal/pages"
+	"github.com/sandwich/nophr/internal/presentation"
 	"github.com/sandwich/nophr/internal/sections"
 )
 
-const itemsPerPage = 9 // Gopher clients use single-digit hotkeys (1-9)
+// maxMenuItems caps event listings so each item keeps a single-digit hotkey (1-9)
+const maxMenuItems = 9
+
+// pageSize returns the configured page size for a listing section, clamped to maxMenuItems
+func (r *Router) pageSize(size int) int {
+	if size < 1 {
+		return maxMenuItems
+	}
+	return min(size, maxMenuItems)
+}
+
+// defaultListSize applies to unclamped listings whose page size is unset
+const defaultListSize = 50
+
+// listSize returns the configured size of a listing that is not limited to hotkeys
+func (r *Router) listSize(size int) int {
+	if size < 1 {
+		return defaultListSize
+	}
+	return size
+}
 
 // Router handles selector routing for Gopher requests
 type Router struct {
@@ -19,85 +44,52 @@ type Router struct {
 	host     string
 	port     int
 	renderer *Renderer
+	archives *sections.ArchiveManager
+	feeds    *feeds.Builder
+	digests  *digest.Builder
+	activity *activity.Builder
+	pages    *pages.Set
 }
 
 // NewRouter creates a new router
 func NewRouter(server *Server, host string, port int) *Router {
-	return &Router{
+	r := &Router{
 		server:   server,
 		host:     host,
 		port:     port,
 		renderer: NewRenderer(server.fullConfig, server.storage),
+		archives: sections.NewArchiveManager(server.storage),
+		feeds:    feeds.NewBuilder(server.fullConfig, server.queryHelper, server.sectionManager),
+		digests:  digest.NewBuilder(server.fullConfig, server.storage, server.queryHelper),
+		activity: activity.NewBuilder(server.storage, server.queryHelper),
+		pages:    pages.Load(server.fullConfig),
 	}
+	r.archives.SetMaxPageSize(server.fullConfig.Display.Limits.MaxArchivePageSize)
+	return r
 }
 
-// parsePageFromParts extracts page number from URL parts like ["page", "2"]
-// Returns page number (1-indexed) and remaining parts
-func parsePageFromParts(parts []string) (int, []string) {
-	page := 1 // Default to page 1
-	remaining := parts
-
-	// Check for /page/N pattern
-	for i := 0; i < len(parts)-1; i++ {
-		if parts[i] == "page" {
-			if pageNum, err := strconv.Atoi(parts[i+1]); err == nil && pageNum > 0 {
-				page = pageNum
-				// Remove "page" and number from parts
-				remaining = append(parts[:i], parts[i+2:]...)
-				break
-			}
-		}
-	}
-
-	return page, remaining
-}
-
-// addPaginationLinks adds Next/Previous/Home navigation to gophermap
-func (r *Router) addPaginationLinks(gmap *Gophermap, basePath string, page, totalItems int) {
-	totalPages := (totalItems + itemsPerPage - 1) / itemsPerPage
-
+// addPaginationLinks adds Older/Newest/Home navigation to gophermap
+func (r *Router) addPaginationLinks(gmap *Gophermap, basePath string, page *aggregates.EventPage) {
 	gmap.AddSpacer()
 
-	// Previous page link
-	if page > 1 {
-		prevPath := fmt.Sprintf("%s/page/%d", basePath, page-1)
-		gmap.AddDirectory("← Previous Page", prevPath)
+	// Older page link
+	if page.Next != nil {
+		gmap.AddDirectory("→ Older", fmt.Sprintf("%s/until/%s", strings.TrimSuffix(basePath, "/"), page.Next))
 	}
 
-	// Next page link
-	if page < totalPages {
-		nextPath := fmt.Sprintf("%s/page/%d", basePath, page+1)
-		gmap.AddDirectory("→ Next Page", nextPath)
-	}
-
-	// Page info
-	if totalPages > 1 {
-		gmap.AddInfo(fmt.Sprintf("Page %d of %d", page, totalPages))
+	// Back to the newest page
+	if page.Before != nil {
+		gmap.AddDirectory("↑ Newest", basePath)
 	}
 
 	gmap.AddSpacer()
 	gmap.AddDirectory("⌂ Home", "/")
 }
 
-// paginateItems returns a subset of items for the current page
-func paginateItems[T any](items []T, page int) []T {
-	start := (page - 1) * itemsPerPage
-	end := start + itemsPerPage
-
-	if start >= len(items) {
-		return []T{}
-	}
-
-	if end > len(items) {
-		end = len(items)
-	}
-
-	return items[start:end]
-}
-
 // Route routes a selector to the appropriate handler
 func (r *Router) Route(selector string) []byte {
-	ctx := context.Background()
+	// Pin the whole render to one point in time so concurrent sync can't shift listings
+	ctx := aggregates.WithSnapshot(context.Background(), nostr.Now())
 
 	// Normalize path
 	path := selector
@@ -105,11 +97,25 @@ func (r *Router) Route(selector string) []byte {
 		path = "/"
 	}
 
+	// Aliases like /~blog stand in for the path they target
+	if strings.HasPrefix(path, "/~") {
+		return r.routeAlias(path)
+	}
+
+	// Feeds sit beside the listing they mirror, e.g. /notes/feed.xml
+	if listing, format, ok := feeds.Parse(path); ok && format == feeds.FormatAtom {
+		return r.handleFeed(ctx, path, listing)
+	}
+
+	// Static pages like /about or /now override sections and defaults
+	if page, ok := r.pages.Lookup(path); ok {
+		return r.handlePage(page)
+	}
+
 	// Check if sections are registered for this path (sections override defaults)
 	if r.server.GetSectionManager() != nil {
-		sections := r.server.GetSectionManager().GetSectionsByPath(path)
-		if len(sections) > 0 {
-			return r.handleSections(ctx, sections, path)
+		if response, ok := r.routeSections(ctx, path); ok {
+			return response
 		}
 	}
 
@@ -126,6 +132,15 @@ func (r *Router) Route(selector string) []byte {
 
 	section := parts[0]
 
+	// NIP-19 deep links route to the profile, note or article they point at
+	if entities.IsEntity(section) {
+		return r.routeEntity(ctx, section)
+	}
+
+	if r.server.fullConfig.Display.HidesSection(section) {
+		return r.errorResponse(ErrorNotFound, fmt.Sprintf("Unknown selector: %s", selector), nil)
+	}
+
 	switch section {
 	case "notes":
 		return r.handleNotes(ctx, parts[1:])
@@ -139,27 +154,69 @@ func (r *Router) Route(selector string) []byte {
 	case "mentions":
 		return r.handleMentions(ctx, parts[1:])
 
+	case "archive":
+		return r.handleArchive(ctx, parts[1:])
+
 	case "note":
 		if len(parts) >= 2 {
 			return r.handleNote(ctx, parts[1])
 		}
-		return r.errorResponse("Missing note ID")
+		return r.errorResponse(ErrorBadRequest, "Missing note ID", nil)
 
 	case "thread":
 		if len(parts) >= 2 {
 			return r.handleThread(ctx, parts[1])
 		}
-		return r.errorResponse("Missing thread ID")
+		return r.errorResponse(ErrorBadRequest, "Missing thread ID", nil)
 
 	case "profile":
+		if len(parts) >= 3 && parts[2] == "notes" {
+			return r.handleProfileNotes(ctx, parts[1])
+		}
 		if len(parts) >= 2 {
 			return r.handleProfile(ctx, parts[1])
 		}
-		return r.errorResponse("Missing pubkey")
+		return r.errorResponse(ErrorBadRequest, "Missing pubkey", nil)
+
+	case "addr":
+		return r.handleAddr(ctx, parts[1:])
+
+	case "following":
+		return r.handleFollowing(ctx, parts[1:])
+
+	case "followers":
+		return r.handleFollowers(ctx, parts[1:])
+
+	case "author":
+		return r.handleAuthor(ctx, parts[1:])
+
+	case "tags":
+		return r.handleTags(ctx, parts[1:])
+
+	case "digest":
+		return r.handleDigest(ctx)
+
+	case "stats":
+		return r.handleStats(ctx)
+
+	case "media":
+		if len(parts) >= 2 {
+			return r.handleMedia(ctx, parts[1])
+		}
+		return r.errorResponse(ErrorBadRequest, "Missing media key", nil)
 
 	case "diagnostics":
+		if len(parts) >= 2 && parts[1] == "status" {
+			return r.handleDiagnosticsStatus(ctx)
+		}
 		return r.handleDiagnostics(ctx)
 
+	case "caps.txt":
+		return r.handleCaps()
+
+	case "about":
+		return r.handleAbout()
+
 	case "search":
 		return r.handleSearch(ctx, parts[1:])
 
@@ -171,33 +228,78 @@ func (r *Router) Route(selector string) []byte {
 		return r.handleReplies(ctx, parts[1:])
 
 	default:
-		return r.errorResponse(fmt.Sprintf("Unknown selector: %s", selector))
+		return r.errorResponse(ErrorNotFound, fmt.Sprintf("Unknown selector: %s", selector), nil)
 	}
 }
 
+// routeAlias resolves a /~name selector and routes the path it stands for.
+// Anything after the alias name (e.g. an until cursor) is kept.
+func (r *Router) routeAlias(path string) []byte {
+	name, rest, _ := strings.Cut(strings.TrimPrefix(path, "/~"), "/")
+
+	alias, ok := r.server.fullConfig.ResolveAlias(name)
+	if !ok {
+		return r.errorResponse(ErrorNotFound, fmt.Sprintf("Unknown alias: %s", name), nil)
+	}
+
+	target := r.server.fullConfig.AliasSelector(alias)
+	if rest != "" {
+		target = strings.TrimSuffix(target, "/") + "/" + rest
+	}
+
+	return r.Route(target)
+}
+
 // handleRoot handles the root/home page
 func (r *Router) handleRoot(ctx context.Context) []byte {
 	gmap := NewGophermap(r.host, r.port)
 
-	// Add header if configured
-	r.addHeaderToGophermap(gmap, "home")
-
 	gmap.AddWelcome("nophr - Nostr Gateway", "Browse Nostr content via Gopher protocol")
 
 	gmap.AddDirectory("Notes", "/notes")
 	gmap.AddDirectory("Articles", "/articles")
-	gmap.AddDirectory("Replies", "/replies")
-	gmap.AddDirectory("Mentions", "/mentions")
+	if !r.server.fullConfig.Display.HideInbox {
+		gmap.AddDirectory("Replies", "/replies")
+		gmap.AddDirectory("Mentions", "/mentions")
+	}
+	gmap.AddDirectory("Archive", "/archive")
+	gmap.AddDirectory("Following", "/following")
+	gmap.AddDirectory("Followers", "/followers")
+	gmap.AddDirectory("Daily digest", "/digest")
+	gmap.AddDirectory("Tags", "/tags")
+	for _, section := range r.server.GetSectionManager().MenuSections() {
+		gmap.AddDirectory(menuTextReplacer.Replace(section.MenuTitle()), section.Path)
+	}
 	gmap.AddSpacer()
+	if pageList := r.pages.All(); len(pageList) > 0 {
+		for _, page := range pageList {
+			gmap.AddDirectory(menuTextReplacer.Replace(page.Title()), page.Path)
+		}
+		gmap.AddSpacer()
+	}
 	gmap.AddDirectory("Search", "/search")
+	if _, ok := r.pages.Lookup("/about"); !ok {
+		gmap.AddDirectory("About", "/about")
+	}
+	gmap.AddDirectory("Stats", "/stats")
 	gmap.AddDirectory("Diagnostics", "/diagnostics")
 	gmap.AddSpacer()
 	gmap.AddInfo("Powered by nophr")
 
+	home := NewGophermap(r.host, r.port)
+
+	// Add header if configured
+	r.addHeaderToGophermap(home, "home")
+
+	data := presentation.PageData{Links: menuLinks(gmap.Items)}
+	if !r.addTemplatedMenu(home, presentation.LayoutHome, data, gmap.Items) {
+		home.Items = append(home.Items, gmap.Items...)
+	}
+
 	// Add footer if configured
-	r.addFooterToGophermap(gmap, "home")
+	r.addFooterToGophermap(home, "home")
 
-	return gmap.Bytes()
+	return home.Bytes()
 }
 
 // handleOutbox handles outbox listing
@@ -211,9 +313,9 @@ func (r *Router) handleOutbox(ctx context.Context, parts []string) []byte {
 
 	// Query outbox notes
 	queryHelper := r.server.GetQueryHelper()
-	notes, err := queryHelper.GetOutboxNotes(ctx, 50)
+	notes, err := queryHelper.GetOutboxNotes(ctx, r.listSize(r.server.fullConfig.Display.Limits.PageSizes.Outbox))
 	if err != nil {
-		gmap.AddError(fmt.Sprintf("Error loading outbox: %v", err))
+		r.server.reportError(gmap, ErrorInternal, "Error loading outbox", err)
 		gmap.AddSpacer()
 		gmap.AddDirectory("← Back to Home", "/")
 		return gmap.Bytes()
@@ -236,7 +338,7 @@ func (r *Router) handleOutbox(ctx context.Context, parts []string) []byte {
 
 			// Add author and timestamp
 			gmap.AddInfo(fmt.Sprintf("   By %s - %s",
-				truncatePubkey(note.Event.PubKey),
+				r.renderer.authorLabel(ctx, note.Event.PubKey),
 				formatTimestamp(note.Event.CreatedAt)))
 
 			// Add aggregates if available
@@ -270,11 +372,14 @@ func (r *Router) handleInbox(ctx context.Context, parts []string) []byte {
 func (r *Router) handleNotes(ctx context.Context, parts []string) []byte {
 	gmap := NewGophermap(r.host, r.port)
 
-	// Parse page number from parts
-	page, remaining := parsePageFromParts(parts)
+	// Parse cursor from parts
+	before, remaining, err := aggregates.CursorFromParts(parts)
+	if err != nil {
+		return r.errorResponse(ErrorBadRequest, "Invalid page cursor", err)
+	}
 
-	// Check if viewing a specific note (not "page")
-	if len(remaining) > 0 && remaining[0] != "" && remaining[0] != "page" {
+	// Check if viewing a specific note
+	if len(remaining) > 0 && remaining[0] != "" {
 		return r.handleNote(ctx, remaining[0])
 	}
 
@@ -283,9 +388,9 @@ func (r *Router) handleNotes(ctx context.Context, parts []string) []byte {
 
 	// Query notes
 	queryHelper := r.server.GetQueryHelper()
-	notes, err := queryHelper.GetNotes(ctx, 100) // Get more for pagination
+	page, err := queryHelper.GetNotesPage(ctx, before, r.pageSize(r.server.fullConfig.Display.Limits.PageSizes.Notes))
 	if err != nil {
-		gmap.AddError(fmt.Sprintf("Error loading notes: %v", err))
+		r.server.reportError(gmap, ErrorInternal, "Error loading notes", err)
 		gmap.AddSpacer()
 		gmap.AddDirectory("⌂ Home", "/")
 		return gmap.Bytes()
@@ -294,13 +399,18 @@ func (r *Router) handleNotes(ctx context.Context, parts []string) []byte {
 	gmap.AddInfo("Notes")
 	gmap.AddSpacer()
 
-	// Paginate notes
-	totalNotes := len(notes)
-	paginatedNotes := paginateItems(notes, page)
+	paginatedNotes := page.Events
 
 	// Add clickable note links with aggregates
 	if len(paginatedNotes) > 0 {
-		for _, note := range paginatedNotes {
+		for i, note := range paginatedNotes {
+			if aggregates.IsRepost(note.Event) {
+				r.addRepostItem(ctx, gmap, note)
+				continue
+			}
+
+			item := NewGophermap(r.host, r.port)
+
 			// Extract first line for display
 			content := note.Event.Content
 			if len(content) > 60 {
@@ -312,21 +422,22 @@ func (r *Router) handleNotes(ctx context.Context, parts []string) []byte {
 			linkText := firstLine
 
 			// Add author and timestamp info line
-			gmap.AddInfo(fmt.Sprintf("   By %s - %s",
-				truncatePubkey(note.Event.PubKey),
+			item.AddInfo(fmt.Sprintf("   By %s - %s",
+				r.renderer.authorLabel(ctx, note.Event.PubKey),
 				formatTimestamp(note.Event.CreatedAt)))
 
 			// Add aggregate info if available
 			if note.Aggregates != nil && note.Aggregates.HasInteractions() {
 				aggText := r.renderer.renderAggregates(note.Aggregates)
 				if aggText != "" {
-					gmap.AddInfo("   " + aggText)
+					item.AddInfo("   " + aggText)
 				}
 			}
 
 			// Add the clickable link
-			gmap.AddTextFile(linkText, fmt.Sprintf("/note/%s", note.Event.ID))
-			gmap.AddSpacer()
+			item.AddTextFile(linkText, fmt.Sprintf("/note/%s", note.Event.ID))
+			item.AddSpacer()
+			r.addListItem(ctx, gmap, i+1, note.Event, note.Aggregates, item)
 		}
 	} else {
 		gmap.AddInfo("No notes yet.")
@@ -334,7 +445,7 @@ func (r *Router) handleNotes(ctx context.Context, parts []string) []byte {
 	}
 
 	// Add pagination links
-	r.addPaginationLinks(gmap, "/notes", page, totalNotes)
+	r.addPaginationLinks(gmap, "/notes", page)
 
 	// Add footer if configured
 	r.addFooterToGophermap(gmap, "notes")
@@ -346,17 +457,25 @@ func (r *Router) handleNotes(ctx context.Context, parts []string) []byte {
 func (r *Router) handleArticles(ctx context.Context, parts []string) []byte {
 	gmap := NewGophermap(r.host, r.port)
 
-	// Parse page number from parts
-	page, _ := parsePageFromParts(parts)
+	// Parse cursor from parts
+	before, remaining, err := aggregates.CursorFromParts(parts)
+	if err != nil {
+		return r.errorResponse(ErrorBadRequest, "Invalid page cursor", err)
+	}
+
+	// /articles/<id>/<name>.txt serves one article as a plain text file
+	if len(remaining) == 2 && strings.HasSuffix(remaining[1], ".txt") {
+		return r.handleArticleText(ctx, remaining[0])
+	}
 
 	// Add header if configured
 	r.addHeaderToGophermap(gmap, "articles")
 
 	// Query articles
 	queryHelper := r.server.GetQueryHelper()
-	articles, err := queryHelper.GetArticles(ctx, 100) // Get more for pagination
+	page, err := queryHelper.GetArticlesPage(ctx, before, r.pageSize(r.server.fullConfig.Display.Limits.PageSizes.Articles))
 	if err != nil {
-		gmap.AddError(fmt.Sprintf("Error loading articles: %v", err))
+		r.server.reportError(gmap, ErrorInternal, "Error loading articles", err)
 		gmap.AddSpacer()
 		gmap.AddDirectory("⌂ Home", "/")
 		return gmap.Bytes()
@@ -365,13 +484,13 @@ func (r *Router) handleArticles(ctx context.Context, parts []string) []byte {
 	gmap.AddInfo("Articles")
 	gmap.AddSpacer()
 
-	// Paginate articles
-	totalArticles := len(articles)
-	paginatedArticles := paginateItems(articles, page)
+	paginatedArticles := page.Events
 
 	// Add article links with aggregates
 	if len(paginatedArticles) > 0 {
-		for _, article := range paginatedArticles {
+		for i, article := range paginatedArticles {
+			item := NewGophermap(r.host, r.port)
+
 			// Extract title or first line for display
 			content := article.Event.Content
 			if len(content) > 60 {
@@ -382,20 +501,22 @@ func (r *Router) handleArticles(ctx context.Context, parts []string) []byte {
 			linkText := firstLine
 
 			// Add author and timestamp
-			gmap.AddInfo(fmt.Sprintf("   By %s - %s",
-				truncatePubkey(article.Event.PubKey),
+			item.AddInfo(fmt.Sprintf("   By %s - %s",
+				r.renderer.authorLabel(ctx, article.Event.PubKey),
 				formatTimestamp(article.Event.CreatedAt)))
 
 			// Add aggregates if available
 			if article.Aggregates != nil && article.Aggregates.HasInteractions() {
 				aggText := r.renderer.renderAggregates(article.Aggregates)
 				if aggText != "" {
-					gmap.AddInfo("   " + aggText)
+					item.AddInfo("   " + aggText)
 				}
 			}
 
-			gmap.AddTextFile(linkText, fmt.Sprintf("/note/%s", article.Event.ID))
-			gmap.AddSpacer()
+			item.AddTextFile(linkText, fmt.Sprintf("/note/%s", article.Event.ID))
+			item.AddTextFile("   Plain text: "+articleFilename(article.Event), articleTextSelector(article.Event))
+			item.AddSpacer()
+			r.addListItem(ctx, gmap, i+1, article.Event, article.Aggregates, item)
 		}
 	} else {
 		gmap.AddInfo("No articles yet.")
@@ -403,7 +524,7 @@ func (r *Router) handleArticles(ctx context.Context, parts []string) []byte {
 	}
 
 	// Add pagination links
-	r.addPaginationLinks(gmap, "/articles", page, totalArticles)
+	r.addPaginationLinks(gmap, "/articles", page)
 
 	// Add footer if configured
 	r.addFooterToGophermap(gmap, "articles")
@@ -411,21 +532,43 @@ func (r *Router) handleArticles(ctx context.Context, parts []string) []byte {
 	return gmap.Bytes()
 }
 
+// handleArticleText serves an article as a plain ASCII text file, so clients
+// that save downloads by selector name get a sensibly named .txt file
+func (r *Router) handleArticleText(ctx context.Context, articleID string) []byte {
+	if err := r.server.GetSanitizer().ValidateEventID(articleID); err != nil {
+		return r.errorResponse(ErrorBadRequest, "Invalid article ID", err)
+	}
+
+	events, err := r.server.GetStorage().QueryEvents(ctx, nostr.Filter{
+		IDs:   []string{articleID},
+		Kinds: []int{30023},
+	})
+	if err != nil || len(events) == 0 {
+		return r.errorResponse(ErrorNotFound, fmt.Sprintf("Article not found: %s", articleID), err)
+	}
+
+	text := r.renderer.RenderArticleText(events[0], r.isOwner(events[0].PubKey))
+	return append([]byte(text), []byte(".\r\n")...)
+}
+
 // handleReplies handles replies listing
 func (r *Router) handleReplies(ctx context.Context, parts []string) []byte {
 	gmap := NewGophermap(r.host, r.port)
 
-	// Parse page number from parts
-	page, _ := parsePageFromParts(parts)
+	// Parse cursor from parts
+	before, _, err := aggregates.CursorFromParts(parts)
+	if err != nil {
+		return r.errorResponse(ErrorBadRequest, "Invalid page cursor", err)
+	}
 
 	// Add header if configured
 	r.addHeaderToGophermap(gmap, "replies")
 
 	// Query replies
 	queryHelper := r.server.GetQueryHelper()
-	replies, err := queryHelper.GetReplies(ctx, 100) // Get more for pagination
+	page, err := queryHelper.GetRepliesPage(ctx, before, r.pageSize(r.server.fullConfig.Display.Limits.PageSizes.Replies))
 	if err != nil {
-		gmap.AddError(fmt.Sprintf("Error loading replies: %v", err))
+		r.server.reportError(gmap, ErrorInternal, "Error loading replies", err)
 		gmap.AddSpacer()
 		gmap.AddDirectory("⌂ Home", "/")
 		return gmap.Bytes()
@@ -434,13 +577,13 @@ func (r *Router) handleReplies(ctx context.Context, parts []string) []byte {
 	gmap.AddInfo("Replies")
 	gmap.AddSpacer()
 
-	// Paginate replies
-	totalReplies := len(replies)
-	paginatedReplies := paginateItems(replies, page)
+	paginatedReplies := page.Events
 
 	// Add reply links with aggregates
 	if len(paginatedReplies) > 0 {
-		for _, reply := range paginatedReplies {
+		for i, reply := range paginatedReplies {
+			item := NewGophermap(r.host, r.port)
+
 			// Extract first line for display
 			content := reply.Event.Content
 			if len(content) > 60 {
@@ -451,20 +594,21 @@ func (r *Router) handleReplies(ctx context.Context, parts []string) []byte {
 			linkText := firstLine
 
 			// Add author and timestamp
-			gmap.AddInfo(fmt.Sprintf("   By %s - %s",
-				truncatePubkey(reply.Event.PubKey),
+			item.AddInfo(fmt.Sprintf("   By %s - %s",
+				r.renderer.authorLabel(ctx, reply.Event.PubKey),
 				formatTimestamp(reply.Event.CreatedAt)))
 
 			// Add aggregates if available
 			if reply.Aggregates != nil && reply.Aggregates.HasInteractions() {
 				aggText := r.renderer.renderAggregates(reply.Aggregates)
 				if aggText != "" {
-					gmap.AddInfo("   " + aggText)
+					item.AddInfo("   " + aggText)
 				}
 			}
 
-			gmap.AddTextFile(linkText, fmt.Sprintf("/note/%s", reply.Event.ID))
-			gmap.AddSpacer()
+			item.AddTextFile(linkText, fmt.Sprintf("/note/%s", reply.Event.ID))
+			item.AddSpacer()
+			r.addListItem(ctx, gmap, i+1, reply.Event, reply.Aggregates, item)
 		}
 	} else {
 		gmap.AddInfo("No replies yet.")
@@ -472,7 +616,7 @@ func (r *Router) handleReplies(ctx context.Context, parts []string) []byte {
 	}
 
 	// Add pagination links
-	r.addPaginationLinks(gmap, "/replies", page, totalReplies)
+	r.addPaginationLinks(gmap, "/replies", page)
 
 	// Add footer if configured
 	r.addFooterToGophermap(gmap, "replies")
@@ -484,17 +628,20 @@ func (r *Router) handleReplies(ctx context.Context, parts []string) []byte {
 func (r *Router) handleMentions(ctx context.Context, parts []string) []byte {
 	gmap := NewGophermap(r.host, r.port)
 
-	// Parse page number from parts
-	page, _ := parsePageFromParts(parts)
+	// Parse cursor from parts
+	before, _, err := aggregates.CursorFromParts(parts)
+	if err != nil {
+		return r.errorResponse(ErrorBadRequest, "Invalid page cursor", err)
+	}
 
 	// Add header if configured
 	r.addHeaderToGophermap(gmap, "mentions")
 
 	// Query mentions
 	queryHelper := r.server.GetQueryHelper()
-	mentions, err := queryHelper.GetMentions(ctx, 100) // Get more for pagination
+	page, err := queryHelper.GetMentionsPage(ctx, before, r.pageSize(r.server.fullConfig.Display.Limits.PageSizes.Mentions))
 	if err != nil {
-		gmap.AddError(fmt.Sprintf("Error loading mentions: %v", err))
+		r.server.reportError(gmap, ErrorInternal, "Error loading mentions", err)
 		gmap.AddSpacer()
 		gmap.AddDirectory("⌂ Home", "/")
 		return gmap.Bytes()
@@ -503,13 +650,18 @@ func (r *Router) handleMentions(ctx context.Context, parts []string) []byte {
 	gmap.AddInfo("Mentions")
 	gmap.AddSpacer()
 
-	// Paginate mentions
-	totalMentions := len(mentions)
-	paginatedMentions := paginateItems(mentions, page)
+	paginatedMentions := page.Events
 
 	// Add mention links with aggregates
 	if len(paginatedMentions) > 0 {
-		for _, mention := range paginatedMentions {
+		for i, mention := range paginatedMentions {
+			if aggregates.IsRepost(mention.Event) {
+				r.addRepostItem(ctx, gmap, mention)
+				continue
+			}
+
+			item := NewGophermap(r.host, r.port)
+
 			// Extract first line for display
 			content := mention.Event.Content
 			if len(content) > 60 {
@@ -520,20 +672,21 @@ func (r *Router) handleMentions(ctx context.Context, parts []string) []byte {
 			linkText := firstLine
 
 			// Add author and timestamp
-			gmap.AddInfo(fmt.Sprintf("   By %s - %s",
-				truncatePubkey(mention.Event.PubKey),
+			item.AddInfo(fmt.Sprintf("   By %s - %s",
+				r.renderer.authorLabel(ctx, mention.Event.PubKey),
 				formatTimestamp(mention.Event.CreatedAt)))
 
 			// Add aggregates if available
 			if mention.Aggregates != nil && mention.Aggregates.HasInteractions() {
 				aggText := r.renderer.renderAggregates(mention.Aggregates)
 				if aggText != "" {
-					gmap.AddInfo("   " + aggText)
+					item.AddInfo("   " + aggText)
 				}
 			}
 
-			gmap.AddTextFile(linkText, fmt.Sprintf("/note/%s", mention.Event.ID))
-			gmap.AddSpacer()
+			item.AddTextFile(linkText, fmt.Sprintf("/note/%s", mention.Event.ID))
+			item.AddSpacer()
+			r.addListItem(ctx, gmap, i+1, mention.Event, mention.Aggregates, item)
 		}
 	} else {
 		gmap.AddInfo("No mentions yet.")
@@ -541,7 +694,7 @@ func (r *Router) handleMentions(ctx context.Context, parts []string) []byte {
 	}
 
 	// Add pagination links
-	r.addPaginationLinks(gmap, "/mentions", page, totalMentions)
+	r.addPaginationLinks(gmap, "/mentions", page)
 
 	// Add footer if configured
 	r.addFooterToGophermap(gmap, "mentions")
@@ -551,13 +704,20 @@ func (r *Router) handleMentions(ctx context.Context, parts []string) []byte {
 
 // handleNote handles displaying a single note
 func (r *Router) handleNote(ctx context.Context, noteID string) []byte {
+	if err := r.server.GetSanitizer().ValidateEventID(noteID); err != nil {
+		return r.errorResponse(ErrorBadRequest, "Invalid note ID", err)
+	}
+
 	// Query the note
 	events, err := r.server.GetStorage().QueryEvents(ctx, nostr.Filter{
 		IDs: []string{noteID},
 	})
+	if err == nil && len(events) == 0 {
+		events = r.fetchMissing(ctx, noteID)
+	}
 	if err != nil || len(events) == 0 {
 		gmap := NewGophermap(r.host, r.port)
-		gmap.AddError(fmt.Sprintf("Note not found: %s", noteID))
+		r.server.reportError(gmap, ErrorNotFound, fmt.Sprintf("Note not found: %s", noteID), err)
 		gmap.AddSpacer()
 		gmap.AddDirectory("← Back to Home", "/")
 		return gmap.Bytes()
@@ -580,28 +740,51 @@ func (r *Router) handleNote(ctx context.Context, noteID string) []byte {
 	}
 
 	// Render the note as plain text
-	text := r.renderer.RenderNote(note, agg)
+	text := r.renderer.RenderNote(note, agg, r.isOwner(note.PubKey))
 
 	// Return as plain text with gopher terminator (not gophermap)
 	return append([]byte(text), []byte(".\r\n")...)
 }
 
+// fetchMissing looks up an event that isn't stored on the relays when
+// behavior.fetch_on_miss is set, and returns it from storage once stored
+func (r *Router) fetchMissing(ctx context.Context, id string) []*nostr.Event {
+	if r.server.fetcher == nil || !r.server.fullConfig.Behavior.FetchOnMiss {
+		return nil
+	}
+	if err := r.server.fetcher.Fetch(ctx, id); err != nil {
+		return nil
+	}
+	events, _ := r.server.GetStorage().QueryEvents(ctx, nostr.Filter{IDs: []string{id}})
+	return events
+}
+
+// isOwner reports whether pubkey is the site owner's
+func (r *Router) isOwner(pubkey string) bool {
+	ownerHex, err := r.server.GetQueryHelper().OwnerHex()
+	return err == nil && pubkey == ownerHex
+}
+
 // handleThread handles displaying a thread
 func (r *Router) handleThread(ctx context.Context, rootID string) []byte {
+	if err := r.server.GetSanitizer().ValidateEventID(rootID); err != nil {
+		return r.errorResponse(ErrorBadRequest, "Invalid thread ID", err)
+	}
+
 	queryHelper := r.server.GetQueryHelper()
 
 	// Query the thread
 	thread, err := queryHelper.GetThreadByEvent(ctx, rootID)
 	if err != nil || thread == nil {
 		gmap := NewGophermap(r.host, r.port)
-		gmap.AddError(fmt.Sprintf("Thread not found: %s", rootID))
+		r.server.reportError(gmap, ErrorNotFound, fmt.Sprintf("Thread not found: %s", rootID), err)
 		gmap.AddSpacer()
 		gmap.AddDirectory("← Back to Home", "/")
 		return gmap.Bytes()
 	}
 
 	// Render the thread
-	text := r.renderer.RenderThread(thread.Root, thread.Replies)
+	text := r.renderer.RenderThread(ctx, thread)
 
 	// Return as plain text with gopher terminator
 	return append([]byte(text), []byte(".\r\n")...)
@@ -609,15 +792,22 @@ func (r *Router) handleThread(ctx context.Context, rootID string) []byte {
 
 // handleProfile handles displaying a profile
 func (r *Router) handleProfile(ctx context.Context, pubkey string) []byte {
+	if err := r.server.GetSanitizer().ValidatePubkey(pubkey); err != nil {
+		return r.errorResponse(ErrorBadRequest, "Invalid pubkey", err)
+	}
+
 	// Query profile metadata (kind 0)
 	events, err := r.server.GetStorage().QueryEvents(ctx, nostr.Filter{
 		Kinds:   []int{0},
 		Authors: []string{pubkey},
 		Limit:   1,
 	})
+	if err == nil && len(events) == 0 {
+		events = r.placeholderProfile(ctx, pubkey)
+	}
 	if err != nil || len(events) == 0 {
 		gmap := NewGophermap(r.host, r.port)
-		gmap.AddError(fmt.Sprintf("Profile not found: %s", pubkey))
+		r.server.reportError(gmap, ErrorNotFound, fmt.Sprintf("Profile not found: %s", pubkey), err)
 		gmap.AddSpacer()
 		gmap.AddDirectory("← Back to Home", "/")
 		return gmap.Bytes()
@@ -632,26 +822,56 @@ func (r *Router) handleProfile(ctx context.Context, pubkey string) []byte {
 	return append([]byte(text), []byte(".\r\n")...)
 }
 
+// placeholderProfile stands in an empty kind 0 for an author whose profile
+// hasn't been synced but who has other events stored, so their profile page
+// and deep links work; it returns nil for an author with nothing stored
+func (r *Router) placeholderProfile(ctx context.Context, pubkey string) []*nostr.Event {
+	events, err := r.server.GetStorage().QueryEvents(ctx, nostr.Filter{Authors: []string{pubkey}, Limit: 1})
+	if err != nil || len(events) == 0 {
+		return nil
+	}
+	return []*nostr.Event{{Kind: 0, PubKey: pubkey, Content: "{}", CreatedAt: events[0].CreatedAt}}
+}
+
 // handleDiagnostics handles the diagnostics page
 func (r *Router) handleDiagnostics(ctx context.Context) []byte {
 	gmap := NewGophermap(r.host, r.port)
 
-	gmap.AddInfo("Diagnostics")
-	gmap.AddInfo(strings.Repeat("=", 15))
-	gmap.AddSpacer()
+	collector := r.server.GetDiagnostics()
+	if collector == nil {
+		gmap.AddInfo("Diagnostics")
+		gmap.AddInfo(strings.Repeat("=", 15))
+		gmap.AddSpacer()
+		gmap.AddInfo("Diagnostics are not available")
+		gmap.AddSpacer()
+		gmap.AddDirectory("← Back to Home", "/")
+		return gmap.Bytes()
+	}
 
-	gmap.AddInfo("Server Status: Running")
-	gmap.AddInfo(fmt.Sprintf("Host: %s", r.host))
-	gmap.AddInfo(fmt.Sprintf("Port: %d", r.port))
-	gmap.AddSpacer()
+	diag, err := collector.CollectAll(ctx)
+	if err != nil {
+		return r.errorResponse(ErrorInternal, "Failed to collect diagnostics", err)
+	}
 
-	// TODO: Add storage stats, sync status, etc.
-	gmap.AddInfo("Storage: Connected")
 	gmap.AddSpacer()
-
+	gmap.AddTextFile("Machine-readable status", "/diagnostics/status")
 	gmap.AddDirectory("← Back to Home", "/")
 
-	return gmap.Bytes()
+	return append([]byte(diag.FormatAsGophermap(r.host, r.port)), gmap.Bytes()...)
+}
+
+// handleDiagnosticsStatus serves capabilities as "key: value" text for monitoring scripts
+func (r *Router) handleDiagnosticsStatus(ctx context.Context) []byte {
+	collector := r.server.GetDiagnostics()
+	if collector == nil {
+		return []byte("error: diagnostics are not available\r\n.\r\n")
+	}
+
+	caps, err := collector.CollectCapabilities(ctx)
+	if err != nil {
+		return []byte(fmt.Sprintf("error: %v\r\n.\r\n", err))
+	}
+	return append([]byte(caps.FormatAsKeyValue()), []byte(".\r\n")...)
 }
 
 // handleSearch handles search requests
@@ -669,11 +889,17 @@ func (r *Router) handleSearch(ctx context.Context, params []string) []byte {
 		gmap.AddInfo("Examples:")
 		gmap.AddInfo("  /search/nostr+protocol")
 		gmap.AddInfo("  /search/bitcoin")
+		gmap.AddInfo("  /search/nostr:npub1... (opens the profile, note or article)")
 		gmap.AddSpacer()
 		gmap.AddDirectory("← Back to Home", "/")
 		return gmap.Bytes()
 	}
 
+	// nostr: URIs shared from clients go straight to the page they point at
+	if entity, ok := entities.ParseURI(params[0]); ok {
+		return r.routeEntity(ctx, entity)
+	}
+
 	// Decode search query (URL encoded, replace + with space)
 	query := strings.ReplaceAll(params[0], "+", " ")
 
@@ -682,14 +908,15 @@ func (r *Router) handleSearch(ctx context.Context, params []string) []byte {
 	gmap.AddSpacer()
 
 	// Perform search using NIP-50
+	limit := r.server.GetSanitizer().CapResultLimit(20, r.server.fullConfig.Display.Limits.MaxSearchResults)
 	events, err := r.server.storage.QueryEventsWithSearch(ctx, nostr.Filter{
 		Search: query,
 		Kinds:  []int{0, 1, 30023}, // Profiles, notes, articles
-		Limit:  20,
+		Limit:  limit,
 	})
 
 	if err != nil {
-		gmap.AddError(fmt.Sprintf("Search failed: %v", err))
+		r.server.reportError(gmap, ErrorInternal, "Search failed", err)
 		gmap.AddSpacer()
 		gmap.AddDirectory("← Back to Search", "/search")
 		return gmap.Bytes()
@@ -708,8 +935,10 @@ func (r *Router) handleSearch(ctx context.Context, params []string) []byte {
 	for _, event := range events {
 		switch event.Kind {
 		case 0: // Profile
-			gmap.AddTextFile(fmt.Sprintf("[Profile] %s", truncatePubkey(event.PubKey)),
+			gmap.AddTextFile(fmt.Sprintf("[Profile] %s", r.renderer.authorLabel(ctx, event.PubKey)),
 				fmt.Sprintf("/profile/%s", event.PubKey))
+			gmap.AddDirectory(fmt.Sprintf("   Posts by %s", r.renderer.authorLabel(ctx, event.PubKey)),
+				fmt.Sprintf("/profile/%s/notes", event.PubKey))
 
 		case 1: // Note
 			summary := getSummary(event.Content, 80)
@@ -731,9 +960,9 @@ func (r *Router) handleSearch(ctx context.Context, params []string) []byte {
 }
 
 // errorResponse returns an error gophermap
-func (r *Router) errorResponse(message string) []byte {
+func (r *Router) errorResponse(kind ErrorKind, message string, err error) []byte {
 	gmap := NewGophermap(r.host, r.port)
-	gmap.AddError(message)
+	r.server.reportError(gmap, kind, message, err)
 	gmap.AddSpacer()
 	gmap.AddDirectory("← Back to Home", "/")
 	return gmap.Bytes()
@@ -786,21 +1015,55 @@ func getSummary(content string, maxLen int) string {
 	return summary
 }
 
-// handleSection renders a custom section
-func (r *Router) handleSection(ctx context.Context, section *sections.Section, path string) []byte {
-	gmap := NewGophermap(r.host, r.port)
+// routeSections renders the sections registered for a path. A path with a single
+// section also serves its older pages at <path>/until/<cursor>, and a mirror
+// section serves everything below its path.
+func (r *Router) routeSections(ctx context.Context, path string) ([]byte, bool) {
+	manager := r.server.GetSectionManager()
+
+	// A mirror section owns everything below its path
+	if section, parts, ok := manager.MirrorFor(path); ok {
+		return r.handleMirror(ctx, section, parts), true
+	}
+
+	if matched := manager.GetSectionsByPath(path); len(matched) == 1 {
+		return r.handleSection(ctx, matched[0], path, nil), true
+	} else if len(matched) > 1 {
+		return r.handleSections(ctx, matched, path), true
+	}
+
+	base, cursor, found := strings.Cut(path, "/until/")
+	if !found {
+		return nil, false
+	}
+	if base == "" {
+		base = "/"
+	}
+
+	matched := manager.GetSectionsByPath(base)
+	if len(matched) != 1 {
+		return nil, false
+	}
 
-	// Parse page number from path
-	page := 1
-	// TODO: Extract page from path if needed
+	before, err := aggregates.ParseCursor(cursor)
+	if err != nil {
+		return r.errorResponse(ErrorBadRequest, "Invalid page cursor", err), true
+	}
+
+	return r.handleSection(ctx, matched[0], base, before), true
+}
+
+// handleSection renders one page of a custom section
+func (r *Router) handleSection(ctx context.Context, section *sections.Section, path string, before *aggregates.Cursor) []byte {
+	gmap := NewGophermap(r.host, r.port)
 
 	// Add header if configured
 	r.addHeaderToGophermap(gmap, section.Name)
 
 	// Get section page
-	sectionPage, err := r.server.GetSectionManager().GetPage(ctx, section.Name, page)
+	sectionPage, err := r.server.GetSectionManager().GetPage(ctx, section.Name, before)
 	if err != nil {
-		gmap.AddError(fmt.Sprintf("Error loading section: %v", err))
+		r.server.reportError(gmap, ErrorInternal, "Error loading section", err)
 		gmap.AddSpacer()
 		gmap.AddDirectory("⌂ Home", "/")
 		return gmap.Bytes()
@@ -814,8 +1077,12 @@ func (r *Router) handleSection(ctx context.Context, section *sections.Section, p
 	gmap.AddSpacer()
 
 	// Render events
-	if len(sectionPage.Events) > 0 {
-		for _, event := range sectionPage.Events {
+	if section.IsPlanet() && len(sectionPage.Events) > 0 {
+		r.addPlanetEntries(gmap, sectionPage)
+	} else if len(sectionPage.Events) > 0 {
+		for i, event := range sectionPage.Events {
+			item := NewGophermap(r.host, r.port)
+
 			// Extract first line for display
 			content := event.Content
 			if len(content) > 60 {
@@ -827,18 +1094,22 @@ func (r *Router) handleSection(ctx context.Context, section *sections.Section, p
 
 			// Add author and timestamp if configured
 			if section.ShowAuthors && section.ShowDates {
-				gmap.AddInfo(fmt.Sprintf("   By %s - %s",
-					truncatePubkey(event.PubKey),
+				item.AddInfo(fmt.Sprintf("   By %s - %s",
+					r.renderer.authorLabel(ctx, event.PubKey),
 					formatTimestamp(event.CreatedAt)))
 			} else if section.ShowAuthors {
-				gmap.AddInfo(fmt.Sprintf("   By %s", truncatePubkey(event.PubKey)))
+				item.AddInfo(fmt.Sprintf("   By %s", r.renderer.authorLabel(ctx, event.PubKey)))
 			} else if section.ShowDates {
-				gmap.AddInfo(fmt.Sprintf("   %s", formatTimestamp(event.CreatedAt)))
+				item.AddInfo(fmt.Sprintf("   %s", formatTimestamp(event.CreatedAt)))
 			}
 
 			// Add the clickable link
-			gmap.AddTextFile(linkText, fmt.Sprintf("/note/%s", event.ID))
-			gmap.AddSpacer()
+			item.AddTextFile(linkText, fmt.Sprintf("/note/%s", event.ID))
+			if section.MediaLinks {
+				r.addMediaLinks(item, event)
+			}
+			item.AddSpacer()
+			r.addListItem(ctx, gmap, i+1, event, nil, item)
 		}
 	} else {
 		gmap.AddInfo("No content yet.")
@@ -856,12 +1127,7 @@ func (r *Router) handleSection(ctx context.Context, section *sections.Section, p
 	}
 
 	// Add pagination links
-	if sectionPage.TotalPages > 1 {
-		r.addPaginationLinks(gmap, path, page, int(sectionPage.TotalItems))
-	} else {
-		gmap.AddSpacer()
-		gmap.AddDirectory("⌂ Home", "/")
-	}
+	r.addPaginationLinks(gmap, path, &aggregates.EventPage{Before: sectionPage.Before, Next: sectionPage.Next})
 
 	// Add footer if configured
 	r.addFooterToGophermap(gmap, section.Name)
@@ -878,12 +1144,18 @@ func (r *Router) handleSections(ctx context.Context, sections []*sections.Sectio
 		r.addHeaderToGophermap(gmap, sections[0].Name)
 	}
 
+	// Query all sections concurrently (always the newest page for multi-section views)
+	names := make([]string, len(sections))
+	for i, section := range sections {
+		names[i] = section.Name
+	}
+	pages, errs := r.server.GetSectionManager().GetPages(ctx, names)
+
 	// Render each section in order
 	for i, section := range sections {
-		// Get section page (always page 1 for multi-section views)
-		sectionPage, err := r.server.GetSectionManager().GetPage(ctx, section.Name, 1)
+		sectionPage, err := pages[i], errs[i]
 		if err != nil {
-			gmap.AddError(fmt.Sprintf("Error loading section %s: %v", section.Name, err))
+			r.server.reportError(gmap, ErrorInternal, fmt.Sprintf("Error loading section %s", section.Name), err)
 			gmap.AddSpacer()
 			continue
 		}
@@ -898,8 +1170,12 @@ func (r *Router) handleSections(ctx context.Context, sections []*sections.Sectio
 		gmap.AddSpacer()
 
 		// Render events from section
-		if len(sectionPage.Events) > 0 {
-			for _, event := range sectionPage.Events {
+		if section.IsPlanet() && len(sectionPage.Events) > 0 {
+			r.addPlanetEntries(gmap, sectionPage)
+		} else if len(sectionPage.Events) > 0 {
+			for i, event := range sectionPage.Events {
+				item := NewGophermap(r.host, r.port)
+
 				// Extract first line for display
 				content := event.Content
 				if len(content) > 60 {
@@ -911,18 +1187,22 @@ func (r *Router) handleSections(ctx context.Context, sections []*sections.Sectio
 
 				// Add author and timestamp if configured
 				if section.ShowAuthors && section.ShowDates {
-					gmap.AddInfo(fmt.Sprintf("   By %s - %s",
-						truncatePubkey(event.PubKey),
+					item.AddInfo(fmt.Sprintf("   By %s - %s",
+						r.renderer.authorLabel(ctx, event.PubKey),
 						formatTimestamp(event.CreatedAt)))
 				} else if section.ShowAuthors {
-					gmap.AddInfo(fmt.Sprintf("   By %s", truncatePubkey(event.PubKey)))
+					item.AddInfo(fmt.Sprintf("   By %s", r.renderer.authorLabel(ctx, event.PubKey)))
 				} else if section.ShowDates {
-					gmap.AddInfo(fmt.Sprintf("   %s", formatTimestamp(event.CreatedAt)))
+					item.AddInfo(fmt.Sprintf("   %s", formatTimestamp(event.CreatedAt)))
 				}
 
 				// Add the clickable link
-				gmap.AddTextFile(linkText, fmt.Sprintf("/note/%s", event.ID))
-				gmap.AddSpacer()
+				item.AddTextFile(linkText, fmt.Sprintf("/note/%s", event.ID))
+				if section.MediaLinks {
+					r.addMediaLinks(item, event)
+				}
+				item.AddSpacer()
+				r.addListItem(ctx, gmap, i+1, event, nil, item)
 			}
 		} else {
 			gmap.AddInfo("No content yet.")
@@ -10,8 +10,14 @@ import (
 	"time"
 
 	"github.com/sandwich/nophr/internal/aggregates"
+	"github.com/sandwich/nophr/internal/cache"
 	"github.com/sandwich/nophr/internal/config"
+	"github.com/sandwich/nophr/internal/idle"
+	"github.com/sandwich/nophr/internal/mediaproxy"
+	nostrclient "github.com/sandwich/nophr/internal/nostr"
+	"github.com/sandwich/nophr/internal/ops"
 	"github.com/sandwich/nophr/internal/sections"
+	"github.com/sandwich/nophr/internal/security"
 	"github.com/sandwich/nophr/internal/storage"
 )
 
@@ -24,6 +30,14 @@ type Server struct {
 	host           string
 	queryHelper    *aggregates.QueryHelper
 	sectionManager *sections.Manager
+	diagnostics    *ops.DiagnosticsCollector
+	rateLimiter    *security.ClientLimiter
+	idle           *idle.Monitor
+	fetcher        *nostrclient.Fetcher
+	media          *mediaproxy.Proxy
+	sanitizer      *security.InputSanitizer
+	cache          cache.Cache
+	cacheTTL       time.Duration
 
 	listener net.Listener
 	wg       sync.WaitGroup
@@ -43,6 +57,8 @@ func New(cfg *config.GopherProtocol, fullCfg *config.Config, st *storage.Storage
 		ctx:         ctx,
 		cancel:      cancel,
 		queryHelper: aggregates.NewQueryHelper(st, fullCfg, aggMgr),
+		sanitizer:   security.NewInputSanitizer(),
+		media:       mediaproxy.New(&fullCfg.Rendering.MediaProxy),
 	}
 
 	// Initialize sections manager (opt-in for custom filtered views)
@@ -130,14 +146,36 @@ func (s *Server) handleConnection(conn net.Conn) {
 		return
 	}
 
-	// Clean selector (remove CRLF and trim)
-	selector := strings.TrimSpace(line)
+	// Strip the CRLF terminator; anything else is validated below
+	selector := strings.TrimRight(line, "\r\n")
+
+	// Gopher+ clients append a request marker after a tab
+	var plusMarker byte
+	if s.config.GopherPlus {
+		selector, plusMarker = splitGopherPlus(selector)
+	}
 
 	// Log request
 	fmt.Printf("Gopher request: %q from %s\n", selector, conn.RemoteAddr())
+	s.idle.Touch()
+
+	// Route request unless the client is rate limited or the selector is malformed
+	var response []byte
+	if allowed, retryAfter := s.checkRateLimit(conn); !allowed {
+		gmap := NewGophermap(s.host, s.config.Port)
+		s.reportError(gmap, ErrorRateLimited, fmt.Sprintf("Rate limit exceeded, try again in %d seconds", int(retryAfter.Seconds()+0.5)), nil)
+		response = gmap.Bytes()
+	} else if clean, err := s.sanitizer.SanitizeAndValidateSelector(selector); err != nil {
+		gmap := NewGophermap(s.host, s.config.Port)
+		s.reportError(gmap, ErrorBadRequest, "Invalid selector", err)
+		response = gmap.Bytes()
+	} else {
+		response = s.cachedRoute(clean)
+	}
 
-	// Route request
-	response := s.router.Route(selector)
+	if s.config.GopherPlus {
+		response = s.plusResponse(strings.TrimSpace(selector), plusMarker, response)
+	}
 
 	// Write response
 	conn.SetWriteDeadline(time.Now().Add(30 * time.Second))
@@ -147,6 +185,53 @@ func (s *Server) handleConnection(conn net.Conn) {
 	}
 }
 
+// cachedRoute serves a selector from the response cache, routing and storing it on a miss.
+// Error menus and diagnostics are never cached, nor is proxied media, which is kept on disk.
+func (s *Server) cachedRoute(selector string) []byte {
+	if selector == "" {
+		selector = "/"
+	}
+	if s.cache == nil || strings.HasPrefix(selector, "/diagnostics") || strings.HasPrefix(selector, mediaproxy.PathPrefix) {
+		return s.router.Route(selector)
+	}
+
+	key := cache.GopherKey(selector)
+	if data, found, err := s.cache.Get(s.ctx, key); err == nil && found {
+		return data
+	}
+
+	response := s.router.Route(selector)
+	if len(response) > 0 && response[0] != '3' {
+		if err := s.cache.Set(s.ctx, key, response, s.cacheTTL); err != nil {
+			fmt.Printf("Gopher cache write failed: %v\n", err)
+		}
+	}
+	return response
+}
+
+// Render returns the response for a selector without a client connection,
+// bypassing the cache (used by the static export)
+func (s *Server) Render(selector string) []byte {
+	if selector == "" {
+		selector = "/"
+	}
+	return s.router.Route(selector)
+}
+
+// checkRateLimit reports whether the client may be served
+func (s *Server) checkRateLimit(conn net.Conn) (bool, time.Duration) {
+	if s.rateLimiter == nil {
+		return true, 0
+	}
+
+	ip := security.ClientIP(conn.RemoteAddr())
+	allowed, retryAfter := s.rateLimiter.Check(ip)
+	if !allowed {
+		fmt.Printf("Gopher rate limit exceeded for %s\n", ip)
+	}
+	return allowed, retryAfter
+}
+
 // GetStorage returns the storage instance
 func (s *Server) GetStorage() *storage.Storage {
 	return s.storage
@@ -167,7 +252,57 @@ func (s *Server) GetQueryHelper() *aggregates.QueryHelper {
 	return s.queryHelper
 }
 
+// GetSanitizer returns the request input sanitizer
+func (s *Server) GetSanitizer() *security.InputSanitizer {
+	return s.sanitizer
+}
+
 // GetSectionManager returns the section manager instance
 func (s *Server) GetSectionManager() *sections.Manager {
 	return s.sectionManager
 }
+
+// SetSectionManager replaces the section manager, so every protocol can share
+// the one loaded from config
+func (s *Server) SetSectionManager(m *sections.Manager) {
+	s.sectionManager = m
+	s.router = NewRouter(s, s.host, s.config.Port)
+}
+
+// SetDiagnostics sets the diagnostics collector used for the diagnostics page
+func (s *Server) SetDiagnostics(dc *ops.DiagnosticsCollector) {
+	s.diagnostics = dc
+}
+
+// GetDiagnostics returns the diagnostics collector (nil if not configured)
+func (s *Server) GetDiagnostics() *ops.DiagnosticsCollector {
+	return s.diagnostics
+}
+
+// SetRateLimiter sets the per-IP rate limiter (nil disables rate limiting)
+func (s *Server) SetRateLimiter(rl *security.ClientLimiter) {
+	s.rateLimiter = rl
+}
+
+// SetFetcher sets the fetcher that looks up notes missing from storage when
+// behavior.fetch_on_miss is set (nil disables it)
+func (s *Server) SetFetcher(f *nostrclient.Fetcher) {
+	s.fetcher = f
+}
+
+// SetMediaProxy replaces the server's own media proxy with one shared by
+// every server, so media linked on any of them can be fetched from all
+func (s *Server) SetMediaProxy(p *mediaproxy.Proxy) {
+	s.media = p
+}
+
+// SetIdleMonitor sets the monitor told about each request (nil disables idle shedding)
+func (s *Server) SetIdleMonitor(m *idle.Monitor) {
+	s.idle = m
+}
+
+// SetCache sets the response cache and how long rendered selectors are kept (nil disables caching)
+func (s *Server) SetCache(c cache.Cache, ttl time.Duration) {
+	s.cache = c
+	s.cacheTTL = ttl
+}
@@ -9,8 +9,12 @@ import (
 	"testing"
 	"time"
 
+	"github.com/nbd-wtf/go-nostr"
 	"github.com/sandwich/nophr/internal/aggregates"
 	"github.com/sandwich/nophr/internal/config"
+	"github.com/sandwich/nophr/internal/conformance"
+	"github.com/sandwich/nophr/internal/ops"
+	"github.com/sandwich/nophr/internal/sections"
 	"github.com/sandwich/nophr/internal/storage"
 )
 
@@ -145,6 +149,94 @@ func TestGophermapFormat(t *testing.T) {
 	}
 }
 
+func TestErrorItems(t *testing.T) {
+	s := &Server{fullConfig: &config.Config{Site: config.Site{Operator: "alice@example.com"}}}
+	gmap := NewGophermap("localhost", 70)
+
+	code := s.reportError(gmap, ErrorNotFound, "Note not found", fmt.Errorf("storage detail"))
+	result := gmap.String()
+
+	if !strings.HasPrefix(code, "NOTFOUND-") {
+		t.Errorf("Expected NOTFOUND- code, got %s", code)
+	}
+	if !strings.HasPrefix(result, "3Note not found ["+code+"]") {
+		t.Errorf("Error item should carry the code, got: %s", result)
+	}
+	if !strings.Contains(result, "alice@example.com") {
+		t.Errorf("Error should include operator contact, got: %s", result)
+	}
+	if strings.Contains(result, "storage detail") {
+		t.Errorf("Internal error details should not reach the client, got: %s", result)
+	}
+}
+
+func TestGopherPlus(t *testing.T) {
+	markers := map[string]struct {
+		selector string
+		marker   byte
+	}{
+		"/notes":              {"/notes", 0},
+		"/notes\t+":           {"/notes", plusData},
+		"/notes\t+text/plain": {"/notes", plusData},
+		"/notes\t!":           {"/notes", plusAttributes},
+		"/notes\t!+ADMIN":     {"/notes", plusAttributes},
+		"/\t$":                {"/", plusDirectory},
+		"/search\t+bitcoin":   {"/search\t+bitcoin", 0},
+		"/search\tterms\t+":   {"/search\tterms", plusData},
+	}
+	for line, want := range markers {
+		selector, marker := splitGopherPlus(line)
+		if selector != want.selector || marker != want.marker {
+			t.Errorf("splitGopherPlus(%q) = %q, %q; want %q, %q", line, selector, marker, want.selector, want.marker)
+		}
+	}
+
+	s := &Server{
+		config:     &config.GopherProtocol{Port: 70, GopherPlus: true},
+		fullConfig: &config.Config{Site: config.Site{Title: "Test", Operator: "op@example.com"}},
+		host:       "localhost",
+	}
+
+	gmap := NewGophermap("localhost", 70)
+	gmap.AddInfo("Welcome")
+	gmap.AddDirectory("Notes", "/notes")
+	gmap.AddTextFile("A note", "/note/abc")
+	menu := gmap.Bytes()
+
+	plain := string(s.plusResponse("/", 0, menu))
+	if !strings.Contains(plain, "1Notes\t/notes\tlocalhost\t70\t+\r\n") {
+		t.Errorf("Expected directory item to be marked as Gopher+, got %q", plain)
+	}
+	if !strings.Contains(plain, "iWelcome\tfake\tlocalhost\t70\r\n") {
+		t.Errorf("Expected info line to be left alone, got %q", plain)
+	}
+
+	data := string(s.plusResponse("/", plusData, menu))
+	if !strings.HasPrefix(data, "+-1\r\n") || !strings.HasSuffix(data, ".\r\n") {
+		t.Errorf("Expected +-1 header and period terminator, got %q", data)
+	}
+
+	info := string(s.plusResponse("/notes", plusAttributes, menu))
+	for _, want := range []string{"+INFO: 1notes\t/notes\tlocalhost\t70\t+", "+ADMIN:", " Admin: op@example.com", " Mod-Date: ", "+VIEWS:", " application/gopher-menu: <1k>"} {
+		if !strings.Contains(info, want) {
+			t.Errorf("Attribute block missing %q: %q", want, info)
+		}
+	}
+
+	dir := string(s.plusResponse("/", plusDirectory, menu))
+	if strings.Count(dir, "+INFO:") != 2 {
+		t.Errorf("Expected +INFO for the two selectable items, got %q", dir)
+	}
+	if !strings.Contains(dir, "+INFO: 0A note\t/note/abc\tlocalhost\t70\t+") || !strings.Contains(dir, " text/plain") {
+		t.Errorf("Expected text item attributes, got %q", dir)
+	}
+
+	text := []byte("Just a note\r\n.\r\n")
+	if got := s.plusResponse("/note/abc", 0, text); string(got) != string(text) {
+		t.Errorf("Expected text responses to pass through unchanged, got %q", got)
+	}
+}
+
 func TestRendererOutput(t *testing.T) {
 	cfg := &config.Config{
 		Storage: config.Storage{
@@ -222,3 +314,54 @@ func sendGopherRequest(t *testing.T, port int, selector string) string {
 
 	return response.String()
 }
+
+func TestArchivePageSizeCap(t *testing.T) {
+	fixture := conformance.NewFixture()
+	cfg := conformance.Config(t, fixture)
+	cfg.Display.Limits.MaxArchivePageSize = 1
+	st := conformance.Setup(t, fixture, cfg)
+
+	server := New(&cfg.Protocols.Gopher, cfg, st, "localhost", aggregates.NewManager(st, cfg))
+	// Routers are rebuilt with the section manager and keep the cap
+	server.SetSectionManager(sections.NewManager(st))
+
+	section := &sections.Section{Filters: sections.FilterSet{Kinds: []int{1}, Authors: []string{fixture.Owner}}, Limit: 20}
+	page, err := server.router.archives.GetArchivePage(context.Background(), section, 2024, time.June, 0, 1)
+	if err != nil {
+		t.Fatalf("GetArchivePage() error = %v", err)
+	}
+	if len(page.Events) != 1 || page.TotalItems < 2 {
+		t.Errorf("Expected 1 of several events per page with max_archive_page_size 1, got %d of %d", len(page.Events), page.TotalItems)
+	}
+}
+
+func TestDiagnosticsPage(t *testing.T) {
+	cfg := config.Default()
+	cfg.Storage.SQLitePath = ":memory:"
+
+	ctx := context.Background()
+	st, err := storage.New(ctx, &cfg.Storage)
+	if err != nil {
+		t.Fatalf("Failed to create storage: %v", err)
+	}
+	defer st.Close()
+
+	event := &nostr.Event{Kind: 1, CreatedAt: 100, Tags: nostr.Tags{}, Content: "counted"}
+	event.Sign(nostr.GeneratePrivateKey())
+	if err := st.StoreEvent(ctx, event); err != nil {
+		t.Fatalf("Failed to store event: %v", err)
+	}
+
+	server := New(&cfg.Protocols.Gopher, cfg, st, "localhost", aggregates.NewManager(st, cfg))
+	if page := string(server.router.Route("/diagnostics")); !strings.Contains(page, "Diagnostics are not available") {
+		t.Errorf("Expected diagnostics to be unavailable without a collector, got: %s", page)
+	}
+
+	server.SetDiagnostics(ops.NewDiagnosticsCollector("test", "", st, nil))
+	page := string(server.router.Route("/diagnostics"))
+	for _, want := range []string{"iTotal Events: 1\t", "i  Kind 1: 1 events\t", "0Machine-readable status\t/diagnostics/status\t"} {
+		if !strings.Contains(page, want) {
+			t.Errorf("Diagnostics page missing %q:\n%s", want, page)
+		}
+	}
+}
@@ -0,0 +1,28 @@
+package gopher
+
+import (
+	"context"
+	"time"
+
+	"github.com/sandwich/nophr/internal/activity"
+)
+
+// handleStats renders the owner's posting activity as an ASCII heatmap of weeks by weekdays
+func (r *Router) handleStats(ctx context.Context) []byte {
+	heatmap, err := r.activity.Current(ctx, time.Now())
+	if err != nil {
+		return r.errorResponse(ErrorInternal, "Error loading activity", err)
+	}
+
+	gmap := NewGophermap(r.host, r.port)
+	gmap.AddInfo("Posting activity")
+	gmap.AddSpacer()
+	gmap.AddInfoBlock(heatmap.Render(activity.ASCIICells))
+	gmap.AddSpacer()
+	gmap.AddInfo(heatmap.Summary())
+	gmap.AddSpacer()
+	gmap.AddDirectory("Archive", "/archive")
+	gmap.AddDirectory("⌂ Home", "/")
+
+	return gmap.Bytes()
+}
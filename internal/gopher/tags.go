@@ -0,0 +1,137 @@
+package gopher
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/sandwich/nophr/internal/aggregates"
+	"github.com/sandwich/nophr/internal/annotate"
+)
+
+// tagIndexGroups are the headings of the /tags index and how many entries each lists
+var tagIndexGroups = []struct {
+	namespace string
+	title     string
+	limit     int
+}{
+	{annotate.NamespaceHashtag, "Hashtags", 50},
+	{annotate.NamespaceTopic, "Topics", 50},
+	{annotate.NamespaceLanguage, "Languages", 20},
+}
+
+// handleTags serves the tag pages: /tags lists the most used hashtags, topics
+// and languages, and /tags/<entry> lists the notes and articles under one
+func (r *Router) handleTags(ctx context.Context, parts []string) []byte {
+	before, remaining, err := aggregates.CursorFromParts(parts)
+	if err != nil {
+		return r.errorResponse(ErrorBadRequest, "Invalid page cursor", err)
+	}
+	if len(remaining) == 0 || remaining[0] == "" {
+		return r.handleTagIndex(ctx)
+	}
+
+	entry, ok := annotate.ParsePath(remaining[0])
+	if !ok {
+		return r.errorResponse(ErrorBadRequest, "Invalid tag", nil)
+	}
+	return r.handleTagEntry(ctx, entry, before)
+}
+
+// handleTagIndex lists the hashtags, topics and languages notes are annotated with
+func (r *Router) handleTagIndex(ctx context.Context) []byte {
+	gmap := NewGophermap(r.host, r.port)
+	r.addHeaderToGophermap(gmap, "tags")
+
+	gmap.AddInfo("Tags")
+	gmap.AddSpacer()
+
+	listed := false
+	for _, group := range tagIndexGroups {
+		counts, err := r.server.storage.TopAnnotations(ctx, group.namespace, 0, group.limit)
+		if err != nil {
+			r.server.reportError(gmap, ErrorInternal, "Error loading tags", err)
+			gmap.AddSpacer()
+			gmap.AddDirectory("⌂ Home", "/")
+			return gmap.Bytes()
+		}
+		if len(counts) == 0 {
+			continue
+		}
+
+		listed = true
+		gmap.AddInfo(group.title)
+		for _, count := range counts {
+			entry := annotate.Annotation{Namespace: group.namespace, Value: count.Value}
+			gmap.AddDirectory(fmt.Sprintf("%s (%s)", menuTextReplacer.Replace(entry.Name()), postCount(int64(count.Events))), "/tags/"+entry.Path())
+		}
+		gmap.AddSpacer()
+	}
+	if !listed {
+		gmap.AddInfo("No tagged posts yet.")
+		gmap.AddSpacer()
+	}
+
+	gmap.AddDirectory("⌂ Home", "/")
+
+	r.addFooterToGophermap(gmap, "tags")
+	return gmap.Bytes()
+}
+
+// handleTagEntry lists one page of the notes and articles under a hashtag, topic or language
+func (r *Router) handleTagEntry(ctx context.Context, entry annotate.Annotation, before *aggregates.Cursor) []byte {
+	gmap := NewGophermap(r.host, r.port)
+	r.addHeaderToGophermap(gmap, "tags")
+
+	page, err := r.server.GetQueryHelper().GetAnnotatedPage(ctx,
+		map[string][]string{entry.Namespace: {entry.Value}}, before,
+		r.pageSize(r.server.fullConfig.Display.Limits.PageSizes.Tags))
+	if err != nil {
+		r.server.reportError(gmap, ErrorInternal, "Error loading tag", err)
+		gmap.AddSpacer()
+		gmap.AddDirectory("⌂ Home", "/")
+		return gmap.Bytes()
+	}
+
+	gmap.AddInfo(menuTextReplacer.Replace(entry.Label()))
+	gmap.AddSpacer()
+
+	if len(page.Events) == 0 {
+		gmap.AddInfo("No posts yet.")
+		gmap.AddSpacer()
+	}
+	for _, item := range page.Events {
+		event := item.Event
+		gmap.AddInfo(fmt.Sprintf("   By %s - %s", r.renderer.authorLabel(ctx, event.PubKey), formatTimestamp(event.CreatedAt)))
+		if item.Aggregates != nil && item.Aggregates.HasInteractions() {
+			if aggText := r.renderer.renderAggregates(item.Aggregates); aggText != "" {
+				gmap.AddInfo("   " + aggText)
+			}
+		}
+
+		if event.Kind == 30023 {
+			gmap.AddTextFile("Article: "+menuTextReplacer.Replace(articleTitle(event)), fmt.Sprintf("/note/%s", event.ID))
+		} else {
+			content := event.Content
+			if len(content) > 60 {
+				content = content[:57] + "..."
+			}
+			gmap.AddTextFile(strings.Split(content, "\n")[0], fmt.Sprintf("/note/%s", event.ID))
+		}
+		gmap.AddSpacer()
+	}
+
+	basePath := "/tags/" + entry.Path()
+	if page.Next != nil {
+		gmap.AddDirectory("→ Older", fmt.Sprintf("%s/until/%s", basePath, page.Next))
+	}
+	if page.Before != nil {
+		gmap.AddDirectory("↑ Newest", basePath)
+	}
+	gmap.AddDirectory("↑ All tags", "/tags")
+	gmap.AddSpacer()
+	gmap.AddDirectory("⌂ Home", "/")
+
+	r.addFooterToGophermap(gmap, "tags")
+	return gmap.Bytes()
+}
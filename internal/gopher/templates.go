@@ -0,0 +1,186 @@
+package gopher
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/nbd-wtf/go-nostr"
+	"github.com/sandwich/nophr/internal/aggregates"
+	"github.com/sandwich/nophr/internal/presentation"
+)
+
+// Text pages (note and profile) are templated as plain text. Menus (home and
+// list_item) are templated as gemtext, the way static gemtext pages are
+// served: "=> selector Title" lines become menu items and other lines info
+// lines. Only the template itself can write link lines: "=>" and "```" in
+// event and profile text are marked with dataMark before rendering, so the
+// parser reads them as text, and the mark is removed afterwards.
+
+// dataMark breaks up gemtext syntax in template data. It is a control
+// character that never shows up in a menu.
+const dataMark = "\x1a"
+
+var (
+	dataEscaper   = strings.NewReplacer("=>", "="+dataMark+">", "```", "`"+dataMark+"``")
+	dataUnescaper = strings.NewReplacer(dataMark, "")
+)
+
+// applyTemplate renders layout's template, if one is configured, with data
+// and the built-in rendering; otherwise, or if it fails, it returns builtin
+func (r *Renderer) applyTemplate(layout, builtin string, data presentation.PageData) string {
+	if !r.templates.Has(layout) {
+		return builtin
+	}
+	data.Builtin = builtin
+	if rendered, ok := r.templates.Render(layout, data); ok {
+		return rendered
+	}
+	return builtin
+}
+
+// aggregateData describes agg for a template, with the summary listings show
+func (r *Renderer) aggregateData(agg *aggregates.EventAggregates) *presentation.AggregateData {
+	if agg == nil || !agg.HasInteractions() {
+		return nil
+	}
+	return presentation.NewAggregateData(agg, r.renderAggregates(agg))
+}
+
+// addTemplatedMenu adds the output of layout's menu template to gmap. It
+// returns false, adding nothing, when there is no template or it fails, so
+// the caller adds the built-in items instead.
+func (r *Router) addTemplatedMenu(gmap *Gophermap, layout string, data presentation.PageData, builtin []Item) bool {
+	if !r.renderer.templates.Has(layout) {
+		return false
+	}
+	data = escapeData(data)
+	data.Builtin = gemtextOf(builtin)
+	rendered, ok := r.renderer.templates.Render(layout, data)
+	if !ok {
+		return false
+	}
+
+	text := func(line string) { gmap.AddInfo(dataUnescaper.Replace(line)) }
+	eachGemtextLine(rendered, text, func(label, target string) {
+		label = dataUnescaper.Replace(label)
+		switch {
+		case isTextSelector(target):
+			gmap.AddTextFile(label, target)
+		case strings.HasPrefix(target, "/"):
+			gmap.AddDirectory(label, target)
+		default:
+			gmap.AddURL(label, target)
+		}
+	})
+	return true
+}
+
+// escapeData returns a copy of data whose text can't be read as gemtext
+// links or preformatting toggles
+func escapeData(data presentation.PageData) presentation.PageData {
+	esc := dataEscaper.Replace
+	if data.Event != nil {
+		event := *data.Event
+		event.Author = esc(event.Author)
+		event.Content = esc(event.Content)
+		event.Rendered = esc(event.Rendered)
+		event.Tags = make([][]string, len(data.Event.Tags))
+		for i, tag := range data.Event.Tags {
+			event.Tags[i] = make([]string, len(tag))
+			for j, value := range tag {
+				event.Tags[i][j] = esc(value)
+			}
+		}
+		data.Event = &event
+	}
+	if data.Profile != nil {
+		profile := *data.Profile
+		profile.Name = esc(profile.Name)
+		profile.DisplayName = esc(profile.DisplayName)
+		profile.About = esc(profile.About)
+		profile.Website = esc(profile.Website)
+		profile.NIP05 = esc(profile.NIP05)
+		profile.Lightning = esc(profile.Lightning)
+		data.Profile = &profile
+	}
+	if data.Aggregates != nil {
+		agg := *data.Aggregates
+		agg.Summary = esc(agg.Summary)
+		agg.ByEmoji = make(map[string]int, len(data.Aggregates.ByEmoji))
+		for emoji, count := range data.Aggregates.ByEmoji {
+			agg.ByEmoji[esc(emoji)] += count
+		}
+		data.Aggregates = &agg
+	}
+	links := make([]presentation.Link, len(data.Links))
+	for i, link := range data.Links {
+		links[i] = presentation.Link{Title: esc(link.Title), URL: link.URL}
+	}
+	data.Links = links
+	return data
+}
+
+// isTextSelector reports whether a local selector is served as a text file
+// rather than a menu: notes, threads, profiles and .txt files
+func isTextSelector(selector string) bool {
+	parts := strings.Split(strings.TrimPrefix(selector, "/"), "/")
+	switch {
+	case !strings.HasPrefix(selector, "/"):
+		return false
+	case strings.HasSuffix(selector, ".txt"), selector == "/diagnostics/status":
+		return true
+	case len(parts) == 2 && (parts[0] == "note" || parts[0] == "thread" || parts[0] == "profile"):
+		return parts[1] != ""
+	}
+	return false
+}
+
+// addListItem adds one entry of a listing to gmap: the list_item template's
+// output if one is configured, otherwise item, the built-in entry
+func (r *Router) addListItem(ctx context.Context, gmap *Gophermap, number int, event *nostr.Event, agg *aggregates.EventAggregates, item *Gophermap) {
+	data := presentation.PageData{
+		Event:      presentation.NewEventData(event, r.renderer.authorLabel(ctx, event.PubKey), "", "/note/"+event.ID),
+		Aggregates: r.renderer.aggregateData(agg),
+		Number:     number,
+	}
+	if !r.addTemplatedMenu(gmap, presentation.LayoutListItem, data, item.Items) {
+		gmap.Items = append(gmap.Items, item.Items...)
+	}
+}
+
+// menuLinks lists the selectable items of a menu as links
+func menuLinks(items []Item) []presentation.Link {
+	var links []presentation.Link
+	for _, item := range items {
+		switch item.Type {
+		case ItemTypeInfo, ItemTypeError:
+			continue
+		}
+		links = append(links, presentation.Link{Title: item.Display, URL: itemTarget(item)})
+	}
+	return links
+}
+
+// gemtextOf writes menu items as gemtext, the form menu templates write.
+// Info lines are escaped like template data, since they quote events.
+func gemtextOf(items []Item) string {
+	var sb strings.Builder
+	for _, item := range items {
+		switch item.Type {
+		case ItemTypeInfo, ItemTypeError:
+			sb.WriteString(dataEscaper.Replace(item.Display) + "\n")
+		default:
+			sb.WriteString(fmt.Sprintf("=> %s %s\n", itemTarget(item), item.Display))
+		}
+	}
+	return sb.String()
+}
+
+// itemTarget returns an item's selector, or its URL for a "URL:" item
+func itemTarget(item Item) string {
+	if url, ok := strings.CutPrefix(item.Selector, "URL:"); ok {
+		return url
+	}
+	return item.Selector
+}
@@ -0,0 +1,151 @@
+package gopher
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"unicode"
+
+	"github.com/nbd-wtf/go-nostr"
+	"github.com/sandwich/nophr/internal/entities"
+)
+
+// maxFilenameLength bounds the title-derived part of an article's .txt filename
+const maxFilenameLength = 60
+
+// asciiPunctuation maps typographic punctuation to plain ASCII
+var asciiPunctuation = strings.NewReplacer(
+	"‘", "'", "’", "'", "‚", "'", "‛", "'",
+	"“", "\"", "”", "\"", "„", "\"", "‟", "\"",
+	"–", "-", "—", "--", "―", "--", "‐", "-", "‑", "-",
+	"…", "...", "•", "*", "·", "*", "→", "->", "←", "<-",
+	" ", " ", " ", " ", " ", " ", "​", "",
+	"©", "(c)", "®", "(R)", "™", "(TM)", "€", "EUR", "£", "GBP",
+	"×", "x", "÷", "/", "«", "<<", "»", ">>",
+)
+
+// latinFolds maps accented Latin letters to their unaccented ASCII form
+var latinFolds = map[rune]string{}
+
+func init() {
+	for ascii, accented := range map[string]string{
+		"a": "àáâãäåāăą", "A": "ÀÁÂÃÄÅĀĂĄ", "c": "çćĉċč", "C": "ÇĆĈĊČ",
+		"d": "ďđ", "D": "ĎĐ", "e": "èéêëēĕėęě", "E": "ÈÉÊËĒĔĖĘĚ",
+		"g": "ĝğġģ", "G": "ĜĞĠĢ", "h": "ĥħ", "H": "ĤĦ", "i": "ìíîïĩīĭįı", "I": "ÌÍÎÏĨĪĬĮİ",
+		"j": "ĵ", "J": "Ĵ", "k": "ķ", "K": "Ķ", "l": "ĺļľŀł", "L": "ĹĻĽĿŁ",
+		"n": "ñńņňŉ", "N": "ÑŃŅŇ", "o": "òóôõöøōŏő", "O": "ÒÓÔÕÖØŌŎŐ",
+		"r": "ŕŗř", "R": "ŔŖŘ", "s": "śŝşš", "S": "ŚŜŞŠ", "t": "ţťŧ", "T": "ŢŤŦ",
+		"u": "ùúûüũūŭůűų", "U": "ÙÚÛÜŨŪŬŮŰŲ", "w": "ŵ", "W": "Ŵ",
+		"y": "ýÿŷ", "Y": "ÝŸŶ", "z": "źżž", "Z": "ŹŻŽ",
+		"ss": "ß", "ae": "æ", "AE": "Æ", "oe": "œ", "OE": "Œ", "th": "þ", "TH": "Þ",
+	} {
+		for _, r := range accented {
+			latinFolds[r] = ascii
+		}
+	}
+}
+
+// toASCII folds text to plain ASCII for old clients: typographic punctuation and
+// accented Latin letters get ASCII equivalents, anything else becomes "?"
+func toASCII(s string) string {
+	s = asciiPunctuation.Replace(s)
+
+	var sb strings.Builder
+	sb.Grow(len(s))
+	for _, r := range s {
+		switch {
+		case r < unicode.MaxASCII:
+			sb.WriteRune(r)
+		case latinFolds[r] != "":
+			sb.WriteString(latinFolds[r])
+		default:
+			sb.WriteByte('?')
+		}
+	}
+	return sb.String()
+}
+
+// articleTitle returns an article's title tag, or its first line if it has none
+func articleTitle(event *nostr.Event) string {
+	for _, tag := range event.Tags {
+		if len(tag) >= 2 && tag[0] == "title" && strings.TrimSpace(tag[1]) != "" {
+			return strings.TrimSpace(tag[1])
+		}
+	}
+	return strings.TrimSpace(strings.Split(event.Content, "\n")[0])
+}
+
+// articleFilename derives a save-friendly filename from an article's title,
+// e.g. "Hello, Wörld!" becomes "hello-world.txt"
+func articleFilename(event *nostr.Event) string {
+	var sb strings.Builder
+	dash := false
+	for _, r := range strings.ToLower(toASCII(articleTitle(event))) {
+		if (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') {
+			if dash && sb.Len() > 0 {
+				sb.WriteByte('-')
+			}
+			dash = false
+			sb.WriteRune(r)
+			continue
+		}
+		dash = true
+	}
+
+	// Long titles are cut back to the last whole word
+	name := sb.String()
+	if len(name) > maxFilenameLength {
+		name = name[:maxFilenameLength+1]
+		if i := strings.LastIndexByte(name, '-'); i > 0 {
+			name = name[:i]
+		} else {
+			name = name[:maxFilenameLength]
+		}
+	}
+	if name == "" {
+		name = "article-" + event.ID[:8]
+	}
+	return name + ".txt"
+}
+
+// articleTextSelector returns the plain-text selector for an article,
+// ending in its title-derived filename
+func articleTextSelector(event *nostr.Event) string {
+	return fmt.Sprintf("/articles/%s/%s", event.ID, articleFilename(event))
+}
+
+// RenderArticleText renders a long-form article as plain ASCII text for saving.
+// attributed adds the site's author and license lines.
+func (r *Renderer) RenderArticleText(event *nostr.Event, attributed bool) string {
+	var sb strings.Builder
+
+	title := articleTitle(event)
+	sb.WriteString(title)
+	sb.WriteString("\n")
+	sb.WriteString(strings.Repeat("=", min(max(len(toASCII(title)), 10), 70)))
+	sb.WriteString("\n\n")
+
+	ctx := context.Background()
+	sb.WriteString(fmt.Sprintf("By: %s\n", r.authorLabel(ctx, event.PubKey)))
+	sb.WriteString(fmt.Sprintf("Published: %s\n", formatTimestamp(event.CreatedAt)))
+	for _, line := range r.annotationLines(ctx, event.ID) {
+		sb.WriteString(line + "\n")
+	}
+	for _, tag := range event.Tags {
+		if len(tag) >= 2 && tag[0] == "summary" && tag[1] != "" {
+			sb.WriteString(fmt.Sprintf("\n%s\n", tag[1]))
+			break
+		}
+	}
+	sb.WriteString("\n")
+
+	content := r.resolver.ReplaceEntities(ctx, event.Content, entities.GopherFormatter)
+	rendered, _ := r.parser.RenderGopher([]byte(content), nil)
+	sb.WriteString(rendered)
+
+	if attributed {
+		sb.WriteString(r.RenderAttribution())
+	}
+
+	return toASCII(sb.String())
+}
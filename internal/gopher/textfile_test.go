@@ -0,0 +1,139 @@
+package gopher
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/nbd-wtf/go-nostr"
+	"github.com/nbd-wtf/go-nostr/nip19"
+	"github.com/sandwich/nophr/internal/aggregates"
+	"github.com/sandwich/nophr/internal/config"
+	"github.com/sandwich/nophr/internal/storage"
+)
+
+func TestArticleFilename(t *testing.T) {
+	id := strings.Repeat("ab", 32)
+	tests := []struct {
+		tags     nostr.Tags
+		content  string
+		expected string
+	}{
+		{nostr.Tags{{"title", "Hello, Wörld!"}}, "", "hello-world.txt"},
+		{nostr.Tags{{"title", "  Ünïcode — and “quotes”  "}}, "", "unicode-and-quotes.txt"},
+		{nil, "# First line\nbody", "first-line.txt"},
+		{nostr.Tags{{"title", "日本語"}}, "", "article-abababab.txt"},
+		{nostr.Tags{{"title", strings.Repeat("long ", 30)}}, "", strings.TrimSuffix(strings.Repeat("long-", 12), "-") + ".txt"},
+	}
+
+	for _, tt := range tests {
+		event := &nostr.Event{ID: id, Kind: 30023, Tags: tt.tags, Content: tt.content}
+		if got := articleFilename(event); got != tt.expected {
+			t.Errorf("articleFilename(%v) = %q, expected %q", tt.tags, got, tt.expected)
+		}
+	}
+}
+
+func TestToASCII(t *testing.T) {
+	if got := toASCII("“Café” – naïve… 日本"); got != "\"Cafe\" - naive... ??" {
+		t.Errorf("Unexpected ASCII folding: %q", got)
+	}
+}
+
+func TestArticleTextSelector(t *testing.T) {
+	cfg := config.Default()
+	cfg.Identity.Npub = "npub1nq3zgtqruwhnz0xx40gh4a4fkamlr2sc7ke5wqs2s3nyv2fpy9esg4hdwq"
+	cfg.Storage.SQLitePath = filepath.Join(t.TempDir(), "test.db")
+
+	ctx := context.Background()
+	st, err := storage.New(ctx, &cfg.Storage)
+	if err != nil {
+		t.Fatalf("Failed to create storage: %v", err)
+	}
+	defer st.Close()
+
+	sk := nostr.GeneratePrivateKey()
+	pk, _ := nostr.GetPublicKey(sk)
+	article := &nostr.Event{
+		PubKey:    pk,
+		CreatedAt: nostr.Now(),
+		Kind:      30023,
+		Tags:      nostr.Tags{{"d", "post"}, {"title", "Crème Brûlée"}, {"summary", "A dessert"}},
+		Content:   "## Steps\n\nWhisk — then *bake*.",
+	}
+	article.Sign(sk)
+	if err := st.StoreEvent(ctx, article); err != nil {
+		t.Fatalf("Failed to store article: %v", err)
+	}
+
+	server := New(&cfg.Protocols.Gopher, cfg, st, "localhost", aggregates.NewManager(st, cfg))
+
+	selector := articleTextSelector(article)
+	if !strings.HasSuffix(selector, "/creme-brulee.txt") {
+		t.Fatalf("Expected a title-derived filename, got %s", selector)
+	}
+
+	text := string(server.router.Route(selector))
+	for _, want := range []string{"Creme Brulee\n", "A dessert", "Whisk -- then"} {
+		if !strings.Contains(text, want) {
+			t.Errorf("Article text should contain %q, got: %s", want, text)
+		}
+	}
+	for _, r := range text {
+		if r > 127 {
+			t.Fatalf("Article text should be plain ASCII, found %q in: %s", r, text)
+		}
+	}
+
+	// A note ID under the article selector is not an article
+	if resp := string(server.router.Route("/articles/" + strings.Repeat("cd", 32) + "/x.txt")); !strings.Contains(resp, "Article not found") {
+		t.Errorf("Expected not found for unknown article, got: %s", resp)
+	}
+}
+
+func TestArticleTextAttribution(t *testing.T) {
+	ownerSK := nostr.GeneratePrivateKey()
+	ownerPK, _ := nostr.GetPublicKey(ownerSK)
+	npub, _ := nip19.EncodePublicKey(ownerPK)
+
+	cfg := config.Default()
+	cfg.Identity.Npub = npub
+	cfg.Storage.SQLitePath = filepath.Join(t.TempDir(), "test.db")
+	cfg.Site.Author = "Alice"
+	cfg.Site.License = config.License{Name: "CC BY 4.0", URL: "https://creativecommons.org/licenses/by/4.0/"}
+
+	ctx := context.Background()
+	st, err := storage.New(ctx, &cfg.Storage)
+	if err != nil {
+		t.Fatalf("Failed to create storage: %v", err)
+	}
+	defer st.Close()
+
+	var articles []*nostr.Event
+	for _, sk := range []string{ownerSK, nostr.GeneratePrivateKey()} {
+		pk, _ := nostr.GetPublicKey(sk)
+		article := &nostr.Event{
+			PubKey:    pk,
+			CreatedAt: nostr.Now(),
+			Kind:      30023,
+			Tags:      nostr.Tags{{"d", "post"}, {"title", "Post"}},
+			Content:   "Body",
+		}
+		article.Sign(sk)
+		if err := st.StoreEvent(ctx, article); err != nil {
+			t.Fatalf("Failed to store article: %v", err)
+		}
+		articles = append(articles, article)
+	}
+
+	server := New(&cfg.Protocols.Gopher, cfg, st, "localhost", aggregates.NewManager(st, cfg))
+
+	footer := "Written by Alice\nLicensed under CC BY 4.0 <https://creativecommons.org/licenses/by/4.0/>\n"
+	if text := string(server.router.Route(articleTextSelector(articles[0]))); !strings.Contains(text, footer) {
+		t.Errorf("The owner's articles should end with the attribution, got: %s", text)
+	}
+	if text := string(server.router.Route(articleTextSelector(articles[1]))); strings.Contains(text, "CC BY") {
+		t.Errorf("Other authors' articles should not carry the site license, got: %s", text)
+	}
+}
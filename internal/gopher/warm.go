@@ -0,0 +1,33 @@
+package gopher
+
+import (
+	"context"
+	"time"
+
+	"github.com/sandwich/nophr/internal/cache"
+)
+
+// hotSelectors are rendered into the cache at startup and after invalidation storms
+var hotSelectors = []string{"/", "/notes", "/articles"}
+
+// WarmCache renders the home page, first pages of notes and articles, and the
+// owner profile, and stores them through the warmer
+func (s *Server) WarmCache(ctx context.Context, w *cache.Warmer, ttl time.Duration) error {
+	for _, selector := range hotSelectors {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if err := w.WarmGopher(ctx, selector, s.router.Route(selector), ttl); err != nil {
+			return err
+		}
+	}
+
+	ownerHex, err := s.queryHelper.OwnerHex()
+	if err != nil {
+		return err
+	}
+
+	// Stored under the selector key so request lookups find it
+	selector := "/profile/" + ownerHex
+	return w.WarmGopher(ctx, selector, s.router.Route(selector), ttl)
+}
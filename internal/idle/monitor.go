@@ -0,0 +1,142 @@
+// Package idle sheds resources while a capsule isn't being read, for small
+// hosts: once no requests have arrived for a while the registered components
+// are put to sleep, and the next request wakes them again.
+package idle
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// maxCheckInterval caps how long an idle period can go unnoticed
+const maxCheckInterval = time.Minute
+
+// hook is a component that sheds resources while idle
+type hook struct {
+	name  string
+	sleep func()
+	wake  func()
+}
+
+// Monitor tracks when the last request arrived and moves the registered
+// components in and out of idle mode. The protocol servers share one and
+// Touch it for every request; a nil Monitor ignores requests.
+type Monitor struct {
+	after time.Duration
+	last  atomic.Int64 // UnixNano of the latest request
+	idle  atomic.Bool
+
+	mu    sync.Mutex // Held while hooks run, so sleeping and waking never interleave
+	hooks []hook
+
+	stop chan struct{}
+	done chan struct{}
+}
+
+// NewMonitor creates a monitor that goes idle after no requests for after.
+// The clock starts now, so a capsule nobody reads goes idle after startup.
+func NewMonitor(after time.Duration) *Monitor {
+	m := &Monitor{
+		after: after,
+		stop:  make(chan struct{}),
+		done:  make(chan struct{}),
+	}
+	m.last.Store(time.Now().UnixNano())
+	return m
+}
+
+// Register adds a component: sleep sheds its resources on going idle and
+// wake restores them on the next request. Register before Start.
+func (m *Monitor) Register(name string, sleep, wake func()) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.hooks = append(m.hooks, hook{name: name, sleep: sleep, wake: wake})
+}
+
+// Start begins checking for idle periods in the background
+func (m *Monitor) Start() {
+	interval := m.after / 4
+	if interval > maxCheckInterval {
+		interval = maxCheckInterval
+	}
+
+	go func() {
+		defer close(m.done)
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-m.stop:
+				return
+			case now := <-ticker.C:
+				m.check(now)
+			}
+		}
+	}()
+}
+
+// Stop stops checking. Components that are asleep stay asleep.
+func (m *Monitor) Stop() {
+	close(m.stop)
+	<-m.done
+}
+
+// Touch records a request, waking the components if they are asleep
+func (m *Monitor) Touch() {
+	if m == nil {
+		return
+	}
+	m.last.Store(time.Now().UnixNano())
+	if m.idle.Load() {
+		m.setIdle(false, time.Now())
+	}
+}
+
+// Idle reports whether the components are asleep
+func (m *Monitor) Idle() bool {
+	return m != nil && m.idle.Load()
+}
+
+// check puts the components to sleep if no request arrived within after
+func (m *Monitor) check(now time.Time) {
+	if !m.idle.Load() && m.quietFor(now) >= m.after {
+		m.setIdle(true, now)
+	}
+}
+
+// quietFor returns how long before now the last request arrived
+func (m *Monitor) quietFor(now time.Time) time.Duration {
+	return now.Sub(time.Unix(0, m.last.Load()))
+}
+
+// setIdle runs the sleep or wake hooks unless the monitor is already in that
+// mode. Going idle is abandoned if a request arrived in the meantime.
+func (m *Monitor) setIdle(idle bool, now time.Time) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.idle.Load() == idle || (idle && m.quietFor(now) < m.after) {
+		return
+	}
+	m.idle.Store(idle)
+
+	names := make([]string, 0, len(m.hooks))
+	for _, h := range m.hooks {
+		if idle {
+			h.sleep()
+		} else {
+			h.wake()
+		}
+		names = append(names, h.name)
+	}
+
+	if idle {
+		fmt.Printf("[IDLE] No requests for %v, shedding resources (%s)\n", m.after, strings.Join(names, ", "))
+	} else {
+		fmt.Printf("[IDLE] Request received, resuming normal operation\n")
+	}
+}
@@ -0,0 +1,44 @@
+package idle
+
+import (
+	"testing"
+	"time"
+)
+
+func TestMonitor(t *testing.T) {
+	m := NewMonitor(10 * time.Minute)
+	var sleeps, wakes int
+	m.Register("test", func() { sleeps++ }, func() { wakes++ })
+
+	start := time.Now()
+	m.check(start.Add(5 * time.Minute))
+	if m.Idle() || sleeps != 0 {
+		t.Fatalf("went idle after 5 minutes")
+	}
+
+	m.check(start.Add(11 * time.Minute))
+	m.check(start.Add(12 * time.Minute))
+	if !m.Idle() || sleeps != 1 {
+		t.Fatalf("Idle() = %v after %d sleeps, want idle after one", m.Idle(), sleeps)
+	}
+
+	// The first request wakes everything, later ones don't again
+	m.Touch()
+	m.Touch()
+	if m.Idle() || wakes != 1 {
+		t.Fatalf("Idle() = %v after %d wakes, want awake after one", m.Idle(), wakes)
+	}
+
+	// The clock restarts at the request
+	m.check(time.Now().Add(5 * time.Minute))
+	if m.Idle() {
+		t.Error("went idle 5 minutes after a request")
+	}
+
+	// A nil monitor ignores requests
+	var none *Monitor
+	none.Touch()
+	if none.Idle() {
+		t.Error("nil monitor is idle")
+	}
+}
@@ -0,0 +1,110 @@
+// Package idn converts internationalized domain names between their Unicode
+// form, shown to readers, and the Punycode (xn--) form used in links.
+package idn
+
+import (
+	"fmt"
+	"net"
+	"net/url"
+	"strings"
+	"unicode"
+
+	"golang.org/x/net/idna"
+)
+
+// acePrefix marks a Punycode-encoded label
+const acePrefix = "xn--"
+
+// ToASCII converts a hostname to its ASCII form, encoding Unicode labels as Punycode.
+// Hostnames are mapped for lookup first, so they come back lower-cased and with
+// ideographic and fullwidth full stops turned into ASCII dots.
+func ToASCII(host string) (string, error) {
+	ascii, err := idna.Lookup.ToASCII(host)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode host %q: %w", host, err)
+	}
+	return ascii, nil
+}
+
+// ToUnicode converts Punycode labels of a hostname back to Unicode for display.
+// Labels that fail to decode, or that mix Latin with Cyrillic or Greek letters
+// (a common spoofing trick), are left in their xn-- form.
+func ToUnicode(host string) string {
+	labels := strings.Split(host, ".")
+	for i, label := range labels {
+		if len(label) <= len(acePrefix) || !strings.EqualFold(label[:len(acePrefix)], acePrefix) {
+			continue
+		}
+
+		decoded, err := idna.Display.ToUnicode(label)
+		if err != nil || mixesScripts(decoded) {
+			continue
+		}
+		labels[i] = decoded
+	}
+
+	return strings.Join(labels, ".")
+}
+
+// LinkURL returns rawURL with its host in ASCII so clients can follow it.
+// URLs that cannot be parsed or converted are returned unchanged.
+func LinkURL(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil || isASCII(u.Hostname()) {
+		return rawURL
+	}
+
+	ascii, err := ToASCII(u.Hostname())
+	if err != nil {
+		return rawURL
+	}
+	u.Host = withPort(ascii, u.Port())
+	return u.String()
+}
+
+// DisplayURL returns rawURL with a Punycode host shown in Unicode
+func DisplayURL(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil || u.Hostname() == "" {
+		return rawURL
+	}
+
+	display := ToUnicode(u.Hostname())
+	if display == u.Hostname() {
+		return rawURL
+	}
+	return strings.Replace(rawURL, u.Hostname(), display, 1)
+}
+
+// withPort joins a hostname and an optional port
+func withPort(host, port string) string {
+	if port == "" {
+		return host
+	}
+	return net.JoinHostPort(host, port)
+}
+
+// isASCII reports whether s contains only ASCII characters
+func isASCII(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] >= 0x80 {
+			return false
+		}
+	}
+	return true
+}
+
+// mixesScripts reports whether a label combines Latin letters with
+// look-alike Cyrillic or Greek letters
+func mixesScripts(label string) bool {
+	var latin, other bool
+	for _, r := range label {
+		switch {
+		case unicode.Is(unicode.Latin, r):
+			latin = true
+		case unicode.Is(unicode.Cyrillic, r), unicode.Is(unicode.Greek, r):
+			other = true
+		}
+	}
+	return latin && other
+}
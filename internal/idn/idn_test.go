@@ -0,0 +1,64 @@
+package idn
+
+import "testing"
+
+func TestToASCII(t *testing.T) {
+	tests := []struct {
+		host     string
+		expected string
+	}{
+		{"example.com", "example.com"},
+		{"Example.COM", "example.com"},
+		{"bücher.de", "xn--bcher-kva.de"},
+		{"München.de", "xn--mnchen-3ya.de"},
+		{"例え。テスト", "xn--r8jz45g.xn--zckzah"},
+		{"пример.испытание", "xn--e1afmkfd.xn--80akhbyknj4f"},
+	}
+
+	for _, tt := range tests {
+		got, err := ToASCII(tt.host)
+		if err != nil {
+			t.Errorf("ToASCII(%q) failed: %v", tt.host, err)
+			continue
+		}
+		if got != tt.expected {
+			t.Errorf("ToASCII(%q) = %q, expected %q", tt.host, got, tt.expected)
+		}
+	}
+}
+
+func TestToUnicode(t *testing.T) {
+	tests := []struct {
+		host     string
+		expected string
+	}{
+		{"example.com", "example.com"},
+		{"xn--bcher-kva.de", "bücher.de"},
+		{"XN--MNCHEN-3YA.de", "münchen.de"},
+		{"xn--r8jz45g.xn--zckzah", "例え.テスト"},
+		{"xn--invalid!.com", "xn--invalid!.com"},
+		// "pаypal" with a Cyrillic а stays encoded
+		{"xn--pypal-4ve.com", "xn--pypal-4ve.com"},
+	}
+
+	for _, tt := range tests {
+		if got := ToUnicode(tt.host); got != tt.expected {
+			t.Errorf("ToUnicode(%q) = %q, expected %q", tt.host, got, tt.expected)
+		}
+	}
+}
+
+func TestURLs(t *testing.T) {
+	if got := LinkURL("https://bücher.de:8443/katalog?q=ü"); got != "https://xn--bcher-kva.de:8443/katalog?q=ü" {
+		t.Errorf("LinkURL should encode only the host, got %q", got)
+	}
+	if got := LinkURL("https://example.com/ü"); got != "https://example.com/ü" {
+		t.Errorf("LinkURL should leave ASCII hosts alone, got %q", got)
+	}
+	if got := DisplayURL("gemini://xn--bcher-kva.de/"); got != "gemini://bücher.de/" {
+		t.Errorf("DisplayURL should decode the host, got %q", got)
+	}
+	if got := DisplayURL("not a url"); got != "not a url" {
+		t.Errorf("DisplayURL should return unparseable input unchanged, got %q", got)
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"strings"
 
+	"github.com/sandwich/nophr/internal/idn"
 	"github.com/yuin/goldmark/ast"
 )
 
@@ -90,12 +91,20 @@ func (r *GeminiRenderer) renderNodeInternal(n ast.Node, source []byte, entering
 			r.buf.WriteString(linkText)
 		} else {
 			// After the link node, add a gemini link line
-			linkURL := string(node.Destination)
+			linkURL := idn.LinkURL(string(node.Destination))
 			linkText := ExtractText(node, source)
 			r.buf.WriteString(fmt.Sprintf("\n=> %s %s\n", linkURL, linkText))
 		}
 		return ast.WalkSkipChildren
 
+	case *ast.AutoLink:
+		if entering {
+			// Bare URLs get their own link line, labelled with the Unicode host
+			linkURL := string(node.URL(source))
+			r.buf.WriteString(fmt.Sprintf("\n=> %s %s\n", idn.LinkURL(linkURL), idn.DisplayURL(string(node.Label(source)))))
+		}
+		return ast.WalkSkipChildren
+
 	case *ast.List:
 		if entering {
 			r.inList = true
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"strings"
 
+	"github.com/sandwich/nophr/internal/idn"
 	"github.com/yuin/goldmark/ast"
 )
 
@@ -113,7 +114,7 @@ func (r *GopherRenderer) renderNodeInternal(n ast.Node, source []byte, entering
 		} else {
 			// Handle link URL after text
 			if r.opts.PreserveLinks {
-				linkURL := string(node.Destination)
+				linkURL := idn.DisplayURL(string(node.Destination))
 				_ = ExtractText(node, source) // linkText already rendered in entering phase
 
 				switch r.opts.LinkStyle {
@@ -131,6 +132,12 @@ func (r *GopherRenderer) renderNodeInternal(n ast.Node, source []byte, entering
 		}
 		return ast.WalkContinue
 
+	case *ast.AutoLink:
+		if entering {
+			r.buf.WriteString(idn.DisplayURL(string(node.Label(source))))
+		}
+		return ast.WalkSkipChildren
+
 	case *ast.List:
 		if entering {
 			r.listDepth++
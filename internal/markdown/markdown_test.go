@@ -263,3 +263,35 @@ func TestRenderBlockquote(t *testing.T) {
 		t.Error("Gemini output missing blockquote")
 	}
 }
+
+func TestRenderIDNLinks(t *testing.T) {
+	p := NewParser()
+	source := []byte("See [the shop](https://bücher.de/katalog) or https://xn--mnchen-3ya.de/karte for details.")
+
+	gemini, err := p.RenderGemini(source, nil)
+	if err != nil {
+		t.Fatalf("RenderGemini() error = %v", err)
+	}
+	if !strings.Contains(gemini, "=> https://xn--bcher-kva.de/katalog the shop") {
+		t.Errorf("Gemini link should use punycode, got: %s", gemini)
+	}
+	if !strings.Contains(gemini, "=> https://xn--mnchen-3ya.de/karte https://münchen.de/karte") {
+		t.Errorf("Gemini bare URL should link with punycode and display Unicode, got: %s", gemini)
+	}
+
+	gopher, err := p.RenderGopher(source, &RenderOptions{Width: 100, PreserveLinks: true, LinkStyle: "full"})
+	if err != nil {
+		t.Fatalf("RenderGopher() error = %v", err)
+	}
+	if !strings.Contains(gopher, "(https://bücher.de/katalog)") || !strings.Contains(gopher, "https://münchen.de/karte") {
+		t.Errorf("Gopher links should display Unicode hosts, got: %s", gopher)
+	}
+
+	finger, err := p.RenderFinger(source, nil)
+	if err != nil {
+		t.Fatalf("RenderFinger() error = %v", err)
+	}
+	if !strings.Contains(finger, "münchen.de") {
+		t.Errorf("Finger output should keep bare URLs, got: %s", finger)
+	}
+}
@@ -3,6 +3,7 @@ package markdown
 import (
 	"bytes"
 
+	"github.com/sandwich/nophr/internal/idn"
 	"github.com/yuin/goldmark"
 	"github.com/yuin/goldmark/ast"
 	"github.com/yuin/goldmark/extension"
@@ -153,6 +154,8 @@ func ExtractText(node ast.Node, source []byte) string {
 			buf.Write(n.Text(source))
 		case *ast.String:
 			buf.Write(n.Value)
+		case *ast.AutoLink:
+			buf.WriteString(idn.DisplayURL(string(n.Label(source))))
 		}
 
 		return ast.WalkContinue, nil
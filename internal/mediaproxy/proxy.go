@@ -0,0 +1,328 @@
+package mediaproxy
+
+import (
+	"container/list"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/sandwich/nophr/internal/config"
+	"github.com/sandwich/nophr/internal/entities"
+)
+
+// PathPrefix is the selector (Gopher) and path (Gemini) proxied media is served under
+const PathPrefix = "/media/"
+
+// maxURLs caps how many linked URLs the proxy remembers. Past it the least
+// recently linked are forgotten; pages linking them again teach them back.
+const maxURLs = 10000
+
+var (
+	// ErrNotFound is returned for keys the proxy has no URL for
+	ErrNotFound = errors.New("media not found")
+
+	// ErrTooLarge is returned when a download exceeds max_file_mb
+	ErrTooLarge = errors.New("media exceeds the size limit")
+
+	// ErrUnsupported is returned when a download isn't an image, video or audio file
+	ErrUnsupported = errors.New("not an image, video or audio file")
+)
+
+// Media is a downloaded file
+type Media struct {
+	Data     []byte
+	MIMEType string
+}
+
+// meta is stored beside each downloaded file so it can be served after a restart
+type meta struct {
+	URL      string `json:"url"`
+	MIMEType string `json:"mime_type"`
+}
+
+// Proxy downloads media linked from events on first request and serves the
+// local copies, so Gopher and Gemini clients can fetch images without a web
+// browser. Blossom and NIP-96 servers are plain HTTPS hosts to it. One proxy
+// is shared by every server, so a URL linked on a Gopher page can be fetched
+// over Gemini too.
+type Proxy struct {
+	config *config.MediaProxy
+	client *http.Client
+
+	mu     sync.Mutex
+	urls   map[string]*list.Element // Key to its entry in linked
+	linked *list.List               // Remote URLs learned as pages link media, most recent first
+}
+
+// linkedURL is an entry of Proxy.linked
+type linkedURL struct {
+	key string
+	url string
+}
+
+// New creates a media proxy. Downloads refuse private and loopback addresses
+// so links in notes can't reach services on the operator's network.
+func New(cfg *config.MediaProxy) *Proxy {
+	dialer := &net.Dialer{Control: publicOnly}
+	return &Proxy{
+		config: cfg,
+		client: &http.Client{
+			Timeout:   time.Duration(cfg.TimeoutSeconds) * time.Second,
+			Transport: &http.Transport{DialContext: dialer.DialContext, Proxy: http.ProxyFromEnvironment},
+		},
+		urls:   make(map[string]*list.Element),
+		linked: list.New(),
+	}
+}
+
+// Key names a URL's local copy
+func Key(url string) string {
+	sum := sha256.Sum256([]byte(url))
+	return hex.EncodeToString(sum[:16])
+}
+
+// Path returns the local path a media link is served at and remembers its
+// URL. It reports false for web pages and when the proxy is disabled, and the
+// link should stay a web link.
+func (p *Proxy) Path(link entities.MediaLink) (string, bool) {
+	if !p.config.Enabled || link.Label == "Link" {
+		return "", false
+	}
+
+	key := Key(link.URL)
+	p.remember(key, link.URL)
+	return PathPrefix + key, true
+}
+
+// remember records key's URL, forgetting the least recently linked URL once
+// maxURLs are known
+func (p *Proxy) remember(key, url string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if elem, ok := p.urls[key]; ok {
+		p.linked.MoveToFront(elem)
+		return
+	}
+	p.urls[key] = p.linked.PushFront(&linkedURL{key: key, url: url})
+	if p.linked.Len() > maxURLs {
+		oldest := p.linked.Back()
+		p.linked.Remove(oldest)
+		delete(p.urls, oldest.Value.(*linkedURL).key)
+	}
+}
+
+// lookup returns the URL linked as key
+func (p *Proxy) lookup(key string) (string, bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	elem, ok := p.urls[key]
+	if !ok {
+		return "", false
+	}
+	return elem.Value.(*linkedURL).url, true
+}
+
+// Fetch returns the media for a key, downloading it on first request
+func (p *Proxy) Fetch(ctx context.Context, key string) (*Media, error) {
+	if !p.config.Enabled || !validKey(key) {
+		return nil, ErrNotFound
+	}
+
+	dataPath := filepath.Join(p.config.Dir, key)
+	if media, err := p.load(dataPath); err == nil {
+		// Touch the file so pruning removes the least recently fetched first
+		now := time.Now()
+		os.Chtimes(dataPath, now, now)
+		return media, nil
+	}
+
+	url, ok := p.lookup(key)
+	if !ok {
+		return nil, ErrNotFound
+	}
+
+	media, err := p.download(ctx, url)
+	if err != nil {
+		return nil, err
+	}
+	if err := p.save(key, url, media); err != nil {
+		fmt.Printf("Media proxy failed to cache %s: %v\n", url, err)
+	}
+	return media, nil
+}
+
+// load reads a downloaded file and its MIME type
+func (p *Proxy) load(dataPath string) (*Media, error) {
+	raw, err := os.ReadFile(dataPath + ".json")
+	if err != nil {
+		return nil, err
+	}
+	var m meta
+	if err := json.Unmarshal(raw, &m); err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(dataPath)
+	if err != nil {
+		return nil, err
+	}
+	return &Media{Data: data, MIMEType: m.MIMEType}, nil
+}
+
+// download fetches a URL, refusing anything over max_file_mb or that isn't media
+func (p *Proxy) download(ctx context.Context, url string) (*Media, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to download media: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to download media: %s", resp.Status)
+	}
+
+	maxBytes := int64(p.config.MaxFileMB) << 20
+	if resp.ContentLength > maxBytes {
+		return nil, ErrTooLarge
+	}
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxBytes+1))
+	if err != nil {
+		return nil, fmt.Errorf("failed to download media: %w", err)
+	}
+	if int64(len(data)) > maxBytes {
+		return nil, ErrTooLarge
+	}
+
+	mimeType, _, _ := strings.Cut(resp.Header.Get("Content-Type"), ";")
+	mimeType = strings.TrimSpace(mimeType)
+	if mimeType == "" || mimeType == "application/octet-stream" {
+		mimeType, _, _ = strings.Cut(http.DetectContentType(data), ";")
+	}
+	if !isMedia(mimeType) {
+		return nil, ErrUnsupported
+	}
+
+	return &Media{Data: data, MIMEType: mimeType}, nil
+}
+
+// save writes a download and its metadata to the media directory, then prunes it
+func (p *Proxy) save(key, url string, media *Media) error {
+	if err := os.MkdirAll(p.config.Dir, 0755); err != nil {
+		return err
+	}
+
+	raw, err := json.Marshal(meta{URL: url, MIMEType: media.MIMEType})
+	if err != nil {
+		return err
+	}
+	dataPath := filepath.Join(p.config.Dir, key)
+	if err := writeFile(dataPath, media.Data); err != nil {
+		return err
+	}
+	if err := writeFile(dataPath+".json", raw); err != nil {
+		return err
+	}
+
+	return p.prune()
+}
+
+// prune removes the least recently fetched files until the directory fits in max_total_mb
+func (p *Proxy) prune() error {
+	entries, err := os.ReadDir(p.config.Dir)
+	if err != nil {
+		return err
+	}
+
+	type file struct {
+		path    string
+		size    int64
+		modTime time.Time
+	}
+	var files []file
+	var total int64
+	for _, entry := range entries {
+		if !validKey(entry.Name()) {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			continue
+		}
+		files = append(files, file{filepath.Join(p.config.Dir, entry.Name()), info.Size(), info.ModTime()})
+		total += info.Size()
+	}
+
+	sort.Slice(files, func(i, j int) bool { return files[i].modTime.Before(files[j].modTime) })
+	for _, f := range files {
+		if total <= int64(p.config.MaxTotalMB)<<20 {
+			break
+		}
+		os.Remove(f.path)
+		os.Remove(f.path + ".json")
+		total -= f.size
+	}
+	return nil
+}
+
+// writeFile writes data through a temporary file so readers never see a partial file
+func writeFile(path string, data []byte) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".download-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// validKey reports whether s looks like a key, so selectors can't name other files
+func validKey(s string) bool {
+	if len(s) != 32 {
+		return false
+	}
+	_, err := hex.DecodeString(s)
+	return err == nil
+}
+
+// isMedia reports whether a MIME type is an image, video or audio type
+func isMedia(mimeType string) bool {
+	return strings.HasPrefix(mimeType, "image/") || strings.HasPrefix(mimeType, "video/") || strings.HasPrefix(mimeType, "audio/")
+}
+
+// publicOnly refuses connections to loopback, private and link-local addresses
+func publicOnly(network, address string, _ syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+	ip := net.ParseIP(host)
+	if ip == nil || ip.IsLoopback() || ip.IsPrivate() || ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() || ip.IsUnspecified() {
+		return fmt.Errorf("media proxy refuses to connect to %s", host)
+	}
+	return nil
+}
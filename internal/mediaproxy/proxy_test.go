@@ -0,0 +1,142 @@
+package mediaproxy
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/sandwich/nophr/internal/config"
+	"github.com/sandwich/nophr/internal/entities"
+)
+
+// png is the smallest valid PNG header, enough for content sniffing
+var png = []byte("\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR")
+
+func TestProxy(t *testing.T) {
+	ctx := context.Background()
+	downloads := 0
+	web := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		downloads++
+		switch r.URL.Path {
+		case "/cat.png":
+			w.Write(png) // No Content-Type, so it is sniffed
+		case "/huge.jpg":
+			w.Header().Set("Content-Type", "image/jpeg")
+			w.Write(bytes.Repeat([]byte("x"), 2<<20))
+		case "/page.jpg":
+			w.Header().Set("Content-Type", "text/html")
+			w.Write([]byte("<html></html>"))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer web.Close()
+
+	cfg := &config.MediaProxy{Enabled: true, Dir: t.TempDir(), MaxFileMB: 1, MaxTotalMB: 1, TimeoutSeconds: 5}
+	proxy := New(cfg)
+	proxy.client = web.Client() // The test server is on loopback, which New refuses
+
+	if _, ok := proxy.Path(entities.MediaLink{URL: web.URL + "/", Label: "Link"}); ok {
+		t.Error("Expected web pages to stay web links")
+	}
+
+	path, ok := proxy.Path(entities.MediaLink{URL: web.URL + "/cat.png", Label: "Image"})
+	if !ok || path != PathPrefix+Key(web.URL+"/cat.png") {
+		t.Fatalf("Path() = %q, %v", path, ok)
+	}
+
+	media, err := proxy.Fetch(ctx, Key(web.URL+"/cat.png"))
+	if err != nil {
+		t.Fatalf("Fetch() error = %v", err)
+	}
+	if media.MIMEType != "image/png" || !bytes.Equal(media.Data, png) {
+		t.Errorf("Fetch() = %q %q", media.MIMEType, media.Data)
+	}
+
+	// The local copy is served without downloading again, even by a new proxy
+	again, err := New(cfg).Fetch(ctx, Key(web.URL+"/cat.png"))
+	if err != nil || downloads != 1 || !bytes.Equal(again.Data, png) {
+		t.Errorf("Expected the cached copy, got %v after %d downloads", err, downloads)
+	}
+
+	for name, want := range map[string]error{"/huge.jpg": ErrTooLarge, "/page.jpg": ErrUnsupported} {
+		proxy.Path(entities.MediaLink{URL: web.URL + name, Label: "Image"})
+		if _, err := proxy.Fetch(ctx, Key(web.URL+name)); !errors.Is(err, want) {
+			t.Errorf("Fetch(%s) error = %v, want %v", name, err, want)
+		}
+		if _, err := os.Stat(filepath.Join(cfg.Dir, Key(web.URL+name))); err == nil {
+			t.Errorf("Expected %s not to be kept", name)
+		}
+	}
+
+	if _, err := proxy.Fetch(ctx, Key("https://example.com/unlinked.png")); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected ErrNotFound for a URL no page linked, got %v", err)
+	}
+	if _, err := proxy.Fetch(ctx, "../../etc/passwd"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected ErrNotFound for a malformed key, got %v", err)
+	}
+}
+
+func TestProxyRefusesPrivateAddresses(t *testing.T) {
+	web := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write(png)
+	}))
+	defer web.Close()
+
+	proxy := New(&config.MediaProxy{Enabled: true, Dir: t.TempDir(), MaxFileMB: 1, MaxTotalMB: 1, TimeoutSeconds: 5})
+	proxy.Path(entities.MediaLink{URL: web.URL + "/cat.png", Label: "Image"})
+	if _, err := proxy.Fetch(context.Background(), Key(web.URL+"/cat.png")); err == nil {
+		t.Error("Expected the proxy to refuse a loopback address")
+	}
+}
+
+func TestPrune(t *testing.T) {
+	cfg := &config.MediaProxy{Enabled: true, Dir: t.TempDir(), MaxFileMB: 1, MaxTotalMB: 1, TimeoutSeconds: 5}
+	proxy := New(cfg)
+
+	half := &Media{Data: bytes.Repeat([]byte("x"), 600<<10), MIMEType: "image/png"}
+	if err := proxy.save(Key("old"), "https://example.com/old.png", half); err != nil {
+		t.Fatalf("save() error = %v", err)
+	}
+	hourAgo := time.Now().Add(-time.Hour)
+	os.Chtimes(filepath.Join(cfg.Dir, Key("old")), hourAgo, hourAgo)
+	if err := proxy.save(Key("new"), "https://example.com/new.png", half); err != nil {
+		t.Fatalf("save() error = %v", err)
+	}
+
+	if _, err := os.Stat(filepath.Join(cfg.Dir, Key("old"))); !os.IsNotExist(err) {
+		t.Error("Expected the oldest file to be pruned past max_total_mb")
+	}
+	if _, err := os.Stat(filepath.Join(cfg.Dir, Key("new"))); err != nil {
+		t.Errorf("Expected the newest file to be kept: %v", err)
+	}
+}
+
+func TestLinkedURLsAreBounded(t *testing.T) {
+	proxy := New(&config.MediaProxy{Enabled: true, Dir: t.TempDir(), MaxFileMB: 1, MaxTotalMB: 1, TimeoutSeconds: 5})
+
+	first := "https://example.com/0.png"
+	proxy.remember(Key(first), first)
+	for i := 1; i <= maxURLs; i++ {
+		url := fmt.Sprintf("https://example.com/%d.png", i)
+		proxy.remember(Key(url), url)
+	}
+
+	if len(proxy.urls) != maxURLs || proxy.linked.Len() != maxURLs {
+		t.Errorf("Expected %d remembered URLs, got %d", maxURLs, len(proxy.urls))
+	}
+	if _, ok := proxy.lookup(Key(first)); ok {
+		t.Error("Expected the least recently linked URL to be forgotten")
+	}
+	last := fmt.Sprintf("https://example.com/%d.png", maxURLs)
+	if url, ok := proxy.lookup(Key(last)); !ok || url != last {
+		t.Errorf("lookup() = %q, %v, want the newest URL", url, ok)
+	}
+}
@@ -0,0 +1,252 @@
+package nntp
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/nbd-wtf/go-nostr"
+	"github.com/nbd-wtf/go-nostr/nip19"
+	"github.com/sandwich/nophr/internal/aggregates"
+	"github.com/sandwich/nophr/internal/entities"
+)
+
+// subjectLength caps subjects taken from a note's first line
+const subjectLength = 72
+
+// article is one event as a news article
+type article struct {
+	number     int64
+	event      *nostr.Event
+	group      string
+	subject    string
+	from       string
+	references []string // Message-IDs, thread root first
+	body       string   // Rendered on first use
+}
+
+// newArticles numbers events oldest first (see the package doc) and works out
+// the headers that need other events: author names, reply subjects and references
+func (s *Server) newArticles(ctx context.Context, groupName string, events []*nostr.Event) []*article {
+	sorted := make([]*nostr.Event, len(events))
+	copy(sorted, events)
+	sort.Slice(sorted, func(i, j int) bool {
+		if sorted[i].CreatedAt != sorted[j].CreatedAt {
+			return sorted[i].CreatedAt < sorted[j].CreatedAt
+		}
+		return sorted[i].ID < sorted[j].ID
+	})
+
+	roots := s.threadRoots(ctx, sorted)
+	names := make(map[string]string)
+
+	articles := make([]*article, 0, len(sorted))
+	var last int64
+	for _, event := range sorted {
+		number := max(int64(event.CreatedAt), last+1)
+		last = number
+
+		a := &article{
+			number:  number,
+			event:   event,
+			group:   groupName,
+			subject: subject(event),
+		}
+		if _, ok := names[event.PubKey]; !ok {
+			names[event.PubKey] = s.resolver.AuthorName(ctx, event.PubKey)
+		}
+		a.from = s.fromHeader(names[event.PubKey], event.PubKey)
+
+		if info, err := aggregates.ParseThreadInfo(event); err == nil && info.IsReply() {
+			rootID := info.GetRootOrSelf(info.ReplyToID)
+			a.references = append(a.references, s.messageID(rootID))
+			if info.ReplyToID != rootID {
+				a.references = append(a.references, s.messageID(info.ReplyToID))
+			}
+			if root, ok := roots[rootID]; ok {
+				a.subject = "Re: " + strings.TrimPrefix(subject(root), "Re: ")
+			} else {
+				a.subject = "Re: " + a.subject
+			}
+		}
+		articles = append(articles, a)
+	}
+
+	return articles
+}
+
+// threadRoots loads the root notes of the replies among events in one query
+func (s *Server) threadRoots(ctx context.Context, events []*nostr.Event) map[string]*nostr.Event {
+	roots := make(map[string]*nostr.Event)
+	var missing []string
+	for _, event := range events {
+		roots[event.ID] = event
+	}
+	for _, event := range events {
+		info, err := aggregates.ParseThreadInfo(event)
+		if err != nil || !info.IsReply() {
+			continue
+		}
+		rootID := info.GetRootOrSelf(info.ReplyToID)
+		if _, ok := roots[rootID]; !ok && !slices.Contains(missing, rootID) {
+			missing = append(missing, rootID)
+		}
+	}
+
+	if len(missing) > 0 {
+		found, err := s.storage.QueryEvents(ctx, nostr.Filter{IDs: missing, Limit: len(missing)})
+		if err == nil {
+			for _, event := range found {
+				roots[event.ID] = event
+			}
+		}
+	}
+	return roots
+}
+
+// head returns the article's header block, one header per line
+func (s *Server) head(a *article) []string {
+	headers := []string{
+		"Path: " + s.config.Host + "!not-for-mail",
+		"From: " + a.from,
+		"Newsgroups: " + a.group,
+		"Subject: " + a.subject,
+		"Date: " + articleDate(a.event),
+		"Message-ID: " + s.messageID(a.event.ID),
+	}
+	if len(a.references) > 0 {
+		headers = append(headers, "References: "+strings.Join(a.references, " "))
+	}
+	if uri := nostrURI(a.event); uri != "" {
+		// RFC 5064: where the original lives
+		headers = append(headers, "Archived-At: <"+uri+">")
+	}
+	return append(headers,
+		"MIME-Version: 1.0",
+		"Content-Type: text/plain; charset=UTF-8",
+		"Content-Transfer-Encoding: 8bit",
+	)
+}
+
+// renderBody returns the article body as plain text, rendering it once
+func (s *Server) renderBody(ctx context.Context, a *article) string {
+	if a.body != "" {
+		return a.body
+	}
+
+	content := s.resolver.ReplaceEntities(ctx, a.event.Content, newsFormatter)
+	rendered, err := s.parser.RenderGopher([]byte(content), nil)
+	if err != nil {
+		rendered = content
+	}
+	a.body = strings.TrimRight(rendered, "\n") + "\n"
+	return a.body
+}
+
+// overview returns the article's OVER line (RFC 3977 section 8.3), in the
+// order advertised by LIST OVERVIEW.FMT. number is 0 for articles outside the
+// selected group.
+func (s *Server) overview(ctx context.Context, a *article, number int64) string {
+	head := strings.Join(s.head(a), "\r\n") + "\r\n"
+	body := s.renderBody(ctx, a)
+	lines := strings.Count(body, "\n")
+	size := len(head) + len("\r\n") + len(body) + lines // Bodies go out with CRLF
+
+	fields := []string{
+		fmt.Sprint(number),
+		a.subject,
+		a.from,
+		articleDate(a.event),
+		s.messageID(a.event.ID),
+		strings.Join(a.references, " "),
+		fmt.Sprint(size),
+		fmt.Sprint(lines),
+	}
+	for i, field := range fields {
+		fields[i] = strings.NewReplacer("\t", " ", "\r", " ", "\n", " ").Replace(field)
+	}
+	return strings.Join(fields, "\t")
+}
+
+// messageID returns the Message-ID of an event
+func (s *Server) messageID(eventID string) string {
+	return "<" + eventID + "@" + s.config.Host + ">"
+}
+
+// parseMessageID returns the event ID in a Message-ID served by this host
+func (s *Server) parseMessageID(id string) (string, bool) {
+	inner, ok := strings.CutPrefix(id, "<")
+	if !ok {
+		return "", false
+	}
+	inner, ok = strings.CutSuffix(inner, ">")
+	if !ok {
+		return "", false
+	}
+	eventID, host, ok := strings.Cut(inner, "@")
+	if !ok || !strings.EqualFold(host, s.config.Host) || !nostr.IsValid32ByteHex(eventID) {
+		return "", false
+	}
+	return eventID, true
+}
+
+// fromHeader builds an RFC 5322 From header. Authors have no mail address, so
+// their npub stands in as the local part.
+func (s *Server) fromHeader(name, pubkey string) string {
+	npub, err := nip19.EncodePublicKey(pubkey)
+	if err != nil {
+		npub = pubkey
+	}
+	address := npub + "@" + s.config.Host
+
+	name = strings.NewReplacer(`"`, "'", `\`, "/").Replace(oneLine(name))
+	if name == "" {
+		return "<" + address + ">"
+	}
+	return fmt.Sprintf("\"%s\" <%s>", name, address)
+}
+
+// subject is an article's title tag, or the first line of a note
+func subject(event *nostr.Event) string {
+	if title := event.Tags.Find("title"); len(title) >= 2 && strings.TrimSpace(title[1]) != "" {
+		return oneLine(title[1])
+	}
+
+	line := oneLine(strings.Split(strings.TrimSpace(event.Content), "\n")[0])
+	runes := []rune(line)
+	switch {
+	case len(runes) == 0:
+		return "(no subject)"
+	case len(runes) > subjectLength:
+		return string(runes[:subjectLength-3]) + "..."
+	}
+	return line
+}
+
+// articleDate formats an event's creation time as an RFC 5322 date
+func articleDate(event *nostr.Event) string {
+	return time.Unix(int64(event.CreatedAt), 0).UTC().Format(time.RFC1123Z)
+}
+
+// nostrURI returns the NIP-21 URI of an event: naddr for addressable events,
+// nevent for everything else
+func nostrURI(event *nostr.Event) string {
+	if nostr.IsAddressableKind(event.Kind) {
+		if naddr, err := nip19.EncodeEntity(event.PubKey, event.Kind, event.Tags.GetD(), nil); err == nil {
+			return "nostr:" + naddr
+		}
+	}
+	if nevent, err := nip19.EncodeEvent(event.ID, nil, event.PubKey); err == nil {
+		return "nostr:" + nevent
+	}
+	return ""
+}
+
+// newsFormatter writes a mention as its label followed by the nostr: URI, which
+// newsreaders can hand to a Nostr client
+func newsFormatter(entity *entities.Entity) string {
+	return fmt.Sprintf("%s <%s>", entity.Label(), entity.OriginalText)
+}
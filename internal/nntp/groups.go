@@ -0,0 +1,248 @@
+package nntp
+
+import (
+	"context"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/nbd-wtf/go-nostr"
+	"github.com/sandwich/nophr/internal/aggregates"
+	"github.com/sandwich/nophr/internal/sections"
+)
+
+// threadsGroup is the hierarchy holding one group per discussion
+const threadsGroup = "threads"
+
+// listingGroups are the groups backed by a listing, in LIST order
+var listingGroups = []string{"notes", "articles", "replies"}
+
+// group is a snapshot of a newsgroup, taken when a client selects or lists it
+type group struct {
+	name        string
+	description string
+	articles    []*article // Ascending by number
+}
+
+// low returns the lowest article number, or 1 for an empty group
+func (g *group) low() int64 {
+	if len(g.articles) == 0 {
+		return 1
+	}
+	return g.articles[0].number
+}
+
+// high returns the highest article number, or 0 for an empty group
+func (g *group) high() int64 {
+	if len(g.articles) == 0 {
+		return 0
+	}
+	return g.articles[len(g.articles)-1].number
+}
+
+// find returns the index of the article with the given number, or -1
+func (g *group) find(number int64) int {
+	i := sort.Search(len(g.articles), func(i int) bool {
+		return g.articles[i].number >= number
+	})
+	if i < len(g.articles) && g.articles[i].number == number {
+		return i
+	}
+	return -1
+}
+
+// groupName returns the full name of a group under the configured prefix
+func (s *Server) groupName(suffix string) string {
+	return s.config.GroupPrefix + "." + suffix
+}
+
+// sectionSuffix turns a section name into a group name component
+var sectionUnsafe = regexp.MustCompile(`[^a-z0-9+_-]+`)
+
+func sectionSuffix(name string) string {
+	return strings.Trim(sectionUnsafe.ReplaceAllString(strings.ToLower(name), "-"), "-")
+}
+
+// listGroups loads every group a client can subscribe to: the listings, custom
+// sections, and a thread group for each of the owner's notes that has replies
+func (s *Server) listGroups(ctx context.Context) ([]*group, error) {
+	var groups []*group
+	for _, suffix := range listingGroups {
+		g, err := s.loadGroup(ctx, s.groupName(suffix))
+		if err != nil {
+			return nil, err
+		}
+		groups = append(groups, g)
+	}
+
+	for _, section := range s.sectionGroups() {
+		g, err := s.loadGroup(ctx, s.groupName(sectionSuffix(section.Name)))
+		if err != nil {
+			return nil, err
+		}
+		if g != nil {
+			groups = append(groups, g)
+		}
+	}
+
+	notes, err := s.queryHelper.GetNotesPage(ctx, nil, s.config.MaxArticles)
+	if err != nil {
+		return nil, err
+	}
+	for _, note := range notes.Events {
+		if note.Event.Kind != 1 || note.Aggregates == nil || note.Aggregates.ReplyCount == 0 {
+			continue
+		}
+		g, err := s.loadGroup(ctx, s.groupName(threadsGroup+"."+note.Event.ID))
+		if err != nil {
+			return nil, err
+		}
+		if g != nil {
+			groups = append(groups, g)
+		}
+	}
+
+	return groups, nil
+}
+
+// loadGroup snapshots the named group. It returns nil without an error if
+// there is no such group.
+func (s *Server) loadGroup(ctx context.Context, name string) (*group, error) {
+	suffix, ok := strings.CutPrefix(name, s.config.GroupPrefix+".")
+	if !ok {
+		return nil, nil
+	}
+
+	var events []*nostr.Event
+	var description string
+	owner := s.ownerName()
+
+	switch suffix {
+	case "notes":
+		page, err := s.queryHelper.GetNotesPage(ctx, nil, s.config.MaxArticles)
+		if err != nil {
+			return nil, err
+		}
+		events, description = pageEvents(page), "Notes by "+owner
+
+	case "articles":
+		page, err := s.queryHelper.GetArticlesPage(ctx, nil, s.config.MaxArticles)
+		if err != nil {
+			return nil, err
+		}
+		events, description = pageEvents(page), "Long-form articles by "+owner
+
+	case "replies":
+		page, err := s.queryHelper.GetRepliesPage(ctx, nil, s.config.MaxArticles)
+		if err != nil {
+			return nil, err
+		}
+		events, description = pageEvents(page), "Replies to "+owner
+
+	default:
+		if rootID, ok := strings.CutPrefix(suffix, threadsGroup+"."); ok {
+			return s.loadThread(ctx, name, rootID)
+		}
+
+		section := s.findSection(suffix)
+		if section == nil {
+			return nil, nil
+		}
+		page, err := s.sectionManager.GetPage(ctx, section.Name, nil)
+		if err != nil {
+			return nil, err
+		}
+		events, description = page.Events, section.Title
+		if section.Description != "" {
+			description = section.Description
+		}
+	}
+
+	if len(events) > s.config.MaxArticles {
+		events = events[:s.config.MaxArticles]
+	}
+	return &group{
+		name:        name,
+		description: oneLine(description),
+		articles:    s.newArticles(ctx, name, events),
+	}, nil
+}
+
+// loadThread snapshots the discussion under a root note
+func (s *Server) loadThread(ctx context.Context, name, rootID string) (*group, error) {
+	if !nostr.IsValid32ByteHex(rootID) {
+		return nil, nil
+	}
+
+	thread, err := s.queryHelper.GetThreadByEvent(ctx, rootID)
+	if err != nil {
+		return nil, err
+	}
+	// Thread groups are named by their root, not by a reply inside them
+	if thread == nil || thread.Root.Event.ID != rootID {
+		return nil, nil
+	}
+
+	events := []*nostr.Event{thread.Root.Event}
+	for _, node := range aggregates.FlattenThread(thread.Tree, 0) {
+		events = append(events, node.Event.Event)
+	}
+
+	return &group{
+		name:        name,
+		description: oneLine("Thread: " + subject(thread.Root.Event)),
+		articles:    s.newArticles(ctx, name, events),
+	}, nil
+}
+
+// findSection returns the custom section whose group name ends in suffix
+func (s *Server) findSection(suffix string) *sections.Section {
+	for _, section := range s.sectionGroups() {
+		if sectionSuffix(section.Name) == suffix {
+			return section
+		}
+	}
+	return nil
+}
+
+// sectionGroups returns the custom sections that get a group, by name. Sections
+// whose name clashes with a built-in group are left out.
+func (s *Server) sectionGroups() []*sections.Section {
+	var result []*sections.Section
+	for _, section := range s.sectionManager.ListSections() {
+		suffix := sectionSuffix(section.Name)
+		if suffix == "" || suffix == threadsGroup || slices.Contains(listingGroups, suffix) {
+			continue
+		}
+		result = append(result, section)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Name < result[j].Name
+	})
+	return result
+}
+
+// ownerName is how group descriptions refer to the owner
+func (s *Server) ownerName() string {
+	if name := s.fullConfig.Site.AuthorName(); name != "" {
+		return name
+	}
+	return "the owner"
+}
+
+// pageEvents returns a listing page's posts, leaving out reposts
+func pageEvents(page *aggregates.EventPage) []*nostr.Event {
+	var events []*nostr.Event
+	for _, enriched := range page.Events {
+		if !aggregates.IsRepost(enriched.Event) {
+			events = append(events, enriched.Event)
+		}
+	}
+	return events
+}
+
+// oneLine collapses whitespace so text fits in a single response line
+func oneLine(text string) string {
+	return strings.Join(strings.Fields(text), " ")
+}
@@ -0,0 +1,207 @@
+// Package nntp serves the owner's notes, articles, replies, custom sections and
+// threads as read-only newsgroups (RFC 3977).
+//
+// Groups are named under protocols.nntp.group_prefix: <prefix>.notes,
+// <prefix>.articles, <prefix>.replies, one group per custom section, and
+// <prefix>.threads.<event id> for each discussion. Every event is an article
+// whose Message-ID is <event id@host>, and NIP-10 root and reply markers become
+// References headers, so newsreaders such as slrn and tin thread conversations
+// the way Nostr clients do.
+//
+// Article numbers follow created_at, bumped past any earlier article posted in
+// the same second, so they only grow as posts arrive and stay put when old
+// posts are pruned, which keeps .newsrc read marks valid across sessions.
+package nntp
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/sandwich/nophr/internal/aggregates"
+	"github.com/sandwich/nophr/internal/config"
+	"github.com/sandwich/nophr/internal/entities"
+	"github.com/sandwich/nophr/internal/idle"
+	"github.com/sandwich/nophr/internal/markdown"
+	"github.com/sandwich/nophr/internal/sections"
+	"github.com/sandwich/nophr/internal/security"
+	"github.com/sandwich/nophr/internal/storage"
+)
+
+// idleTimeout is how long a session may sit between commands (RFC 3977
+// section 3.1 asks for at least three minutes)
+const idleTimeout = 10 * time.Minute
+
+// Server implements a read-only NNTP server
+type Server struct {
+	config      *config.NNTPProtocol
+	fullConfig  *config.Config
+	storage     *storage.Storage
+	queryHelper *aggregates.QueryHelper
+	resolver    *entities.Resolver
+	parser      *markdown.Parser
+	rateLimiter *security.ClientLimiter
+	idle        *idle.Monitor
+
+	sectionManager *sections.Manager
+
+	listener net.Listener
+	wg       sync.WaitGroup
+	ctx      context.Context
+	cancel   context.CancelFunc
+}
+
+// New creates a new NNTP server
+func New(cfg *config.NNTPProtocol, fullCfg *config.Config, st *storage.Storage, aggMgr *aggregates.Manager) *Server {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	return &Server{
+		config:      cfg,
+		fullConfig:  fullCfg,
+		storage:     st,
+		queryHelper: aggregates.NewQueryHelper(st, fullCfg, aggMgr),
+		// Mentions are written out with their nostr: URI, so no links are needed
+		resolver:       newResolver(st, fullCfg),
+		parser:         markdown.NewParser(),
+		sectionManager: sections.NewManager(st),
+		ctx:            ctx,
+		cancel:         cancel,
+	}
+}
+
+// newResolver creates the resolver for article bodies, which removes links to
+// rendering.blocked_domains
+func newResolver(st *storage.Storage, cfg *config.Config) *entities.Resolver {
+	resolver := entities.NewResolver(st, entities.LinkContext{})
+	resolver.SetBlocklist(entities.NewDomainBlocklist(cfg.Rendering.BlockedDomains))
+	return resolver
+}
+
+// Start starts the NNTP server
+func (s *Server) Start() error {
+	addr := fmt.Sprintf("%s:%d", s.config.Bind, s.config.Port)
+
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return fmt.Errorf("failed to start NNTP server: %w", err)
+	}
+
+	s.listener = listener
+	fmt.Printf("NNTP server listening on %s\n", addr)
+
+	s.wg.Add(1)
+	go s.acceptConnections()
+
+	return nil
+}
+
+// Stop stops the NNTP server
+func (s *Server) Stop() error {
+	s.cancel()
+
+	if s.listener != nil {
+		s.listener.Close()
+	}
+
+	s.wg.Wait()
+	return nil
+}
+
+// acceptConnections accepts and handles incoming connections
+func (s *Server) acceptConnections() {
+	defer s.wg.Done()
+
+	for {
+		conn, err := s.listener.Accept()
+		if err != nil {
+			select {
+			case <-s.ctx.Done():
+				return
+			default:
+				fmt.Printf("Accept error: %v\n", err)
+				continue
+			}
+		}
+
+		s.wg.Add(1)
+		go s.handleConnection(conn)
+	}
+}
+
+// handleConnection runs one reader session until QUIT, a timeout or shutdown
+func (s *Server) handleConnection(conn net.Conn) {
+	defer s.wg.Done()
+	defer conn.Close()
+
+	// Sessions are long-lived, so a shutdown closes them rather than waiting
+	stop := context.AfterFunc(s.ctx, func() { conn.Close() })
+	defer stop()
+
+	writer := bufio.NewWriter(conn)
+	s.idle.Touch()
+	if allowed, retryAfter := s.checkRateLimit(conn); !allowed {
+		fmt.Fprintf(writer, "400 Rate limit exceeded, try again in %d seconds\r\n", int(retryAfter.Seconds()+0.5))
+		writer.Flush()
+		return
+	}
+
+	fmt.Printf("NNTP session from %s\n", conn.RemoteAddr())
+	sess := newSession(s, writer)
+	sess.greet()
+
+	reader := bufio.NewReaderSize(conn, maxLineLength)
+	for {
+		conn.SetReadDeadline(time.Now().Add(idleTimeout))
+		line, err := readLine(reader)
+		if err != nil {
+			if err == errLineTooLong {
+				sess.reply("501 Command line too long")
+				continue
+			}
+			return
+		}
+
+		conn.SetWriteDeadline(time.Now().Add(time.Minute))
+		if quit := sess.handle(s.ctx, line); quit {
+			return
+		}
+	}
+}
+
+// checkRateLimit reports whether the client may open a session
+func (s *Server) checkRateLimit(conn net.Conn) (bool, time.Duration) {
+	if s.rateLimiter == nil {
+		return true, 0
+	}
+
+	ip := security.ClientIP(conn.RemoteAddr())
+	allowed, retryAfter := s.rateLimiter.Check(ip)
+	if !allowed {
+		fmt.Printf("NNTP rate limit exceeded for %s\n", ip)
+	}
+	return allowed, retryAfter
+}
+
+// GetSectionManager returns the section manager instance
+func (s *Server) GetSectionManager() *sections.Manager {
+	return s.sectionManager
+}
+
+// SetSectionManager replaces the section manager, so every protocol can share
+// the one loaded from config
+func (s *Server) SetSectionManager(m *sections.Manager) {
+	s.sectionManager = m
+}
+
+// SetRateLimiter sets the per-IP rate limiter applied to new sessions (nil disables rate limiting)
+func (s *Server) SetRateLimiter(rl *security.ClientLimiter) {
+	s.rateLimiter = rl
+}
+
+// SetIdleMonitor sets the monitor told about each request (nil disables idle shedding)
+func (s *Server) SetIdleMonitor(m *idle.Monitor) {
+	s.idle = m
+}
@@ -0,0 +1,242 @@
+package nntp
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"net"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/nbd-wtf/go-nostr"
+	"github.com/nbd-wtf/go-nostr/nip19"
+	"github.com/sandwich/nophr/internal/aggregates"
+	"github.com/sandwich/nophr/internal/config"
+	"github.com/sandwich/nophr/internal/storage"
+)
+
+func TestNNTPProtocol(t *testing.T) {
+	ctx := context.Background()
+	cfg := config.Default()
+	cfg.Storage = config.Storage{Driver: "sqlite", SQLitePath: filepath.Join(t.TempDir(), "test.db")}
+
+	ownerSK := nostr.GeneratePrivateKey()
+	owner, _ := nostr.GetPublicKey(ownerSK)
+	cfg.Identity.Npub, _ = nip19.EncodePublicKey(owner)
+	readerSK := nostr.GeneratePrivateKey()
+
+	st, err := storage.New(ctx, &cfg.Storage)
+	if err != nil {
+		t.Fatalf("Failed to create storage: %v", err)
+	}
+	defer st.Close()
+	aggMgr := aggregates.NewManager(st, cfg)
+
+	root := &nostr.Event{CreatedAt: 1718020800, Kind: 1, Content: "Hello newsreaders\n\n.dotted line"}
+	root.Sign(ownerSK)
+	reply := &nostr.Event{
+		CreatedAt: 1718020800, // Same second as the root
+		Kind:      1,
+		Tags:      nostr.Tags{{"e", root.ID, "", "root"}, {"p", owner}},
+		Content:   "A reply",
+	}
+	reply.Sign(readerSK)
+	nested := &nostr.Event{
+		CreatedAt: 1718024400,
+		Kind:      1,
+		Tags:      nostr.Tags{{"e", root.ID, "", "root"}, {"e", reply.ID, "", "reply"}},
+		Content:   "A nested reply",
+	}
+	nested.Sign(ownerSK)
+	for _, event := range []*nostr.Event{root, reply, nested} {
+		if err := st.StoreEvent(ctx, event); err != nil {
+			t.Fatalf("Failed to store event: %v", err)
+		}
+		if err := aggMgr.ProcessEvent(ctx, event); err != nil {
+			t.Fatalf("Failed to process event: %v", err)
+		}
+	}
+
+	nntpCfg := &config.NNTPProtocol{
+		Enabled:     true,
+		Host:        "news.example.com",
+		Port:        17119, // Use non-standard port for testing
+		Bind:        "localhost",
+		GroupPrefix: "nophr",
+		MaxArticles: 100,
+	}
+	server := New(nntpCfg, cfg, st, aggMgr)
+	if err := server.Start(); err != nil {
+		t.Fatalf("Failed to start server: %v", err)
+	}
+	defer server.Stop()
+
+	client := dialNNTP(t, nntpCfg.Port)
+	defer client.conn.Close()
+
+	if status := client.status(); !strings.HasPrefix(status, "201 ") {
+		t.Fatalf("Expected a posting-prohibited greeting, got %q", status)
+	}
+
+	threadGroup := "nophr.threads." + root.ID
+	t.Run("ListActive", func(t *testing.T) {
+		lines := client.multi(t, "LIST ACTIVE", "215")
+		for _, want := range []string{"nophr.notes ", "nophr.articles ", "nophr.replies ", threadGroup + " "} {
+			if !containsPrefix(lines, want) {
+				t.Errorf("Expected group %q in LIST ACTIVE, got %v", want, lines)
+			}
+		}
+	})
+
+	t.Run("Group", func(t *testing.T) {
+		client.send("GROUP " + threadGroup)
+		want := fmt.Sprintf("211 3 %d %d %s", root.CreatedAt, nested.CreatedAt, threadGroup)
+		if status := client.status(); status != want {
+			t.Errorf("Expected %q, got %q", want, status)
+		}
+
+		client.send("GROUP nophr.missing")
+		if status := client.status(); !strings.HasPrefix(status, "411 ") {
+			t.Errorf("Expected 411 for an unknown group, got %q", status)
+		}
+	})
+
+	t.Run("Over", func(t *testing.T) {
+		client.send("GROUP " + threadGroup)
+		client.status()
+		lines := client.multi(t, "OVER 1-", "224")
+		if len(lines) != 3 {
+			t.Fatalf("Expected 3 overview lines, got %v", lines)
+		}
+
+		overviews := make(map[string][]string)
+		for _, line := range lines {
+			fields := strings.Split(line, "\t")
+			overviews[fields[4]] = fields
+		}
+		rootOver := overviews["<"+root.ID+"@news.example.com>"]
+		replyOver := overviews["<"+reply.ID+"@news.example.com>"]
+		nestedOver := overviews["<"+nested.ID+"@news.example.com>"]
+		if rootOver == nil || replyOver == nil || nestedOver == nil {
+			t.Fatalf("Expected an overview line per post, got %v", lines)
+		}
+
+		// Posts in the same second get consecutive numbers
+		numbers := rootOver[0] + " " + replyOver[0]
+		if numbers != fmt.Sprintf("%d %d", root.CreatedAt, root.CreatedAt+1) && numbers != fmt.Sprintf("%d %d", root.CreatedAt+1, root.CreatedAt) {
+			t.Errorf("Expected posts in the same second to be numbered apart, got %s", numbers)
+		}
+		if replyOver[1] != "Re: Hello newsreaders" {
+			t.Errorf("Expected the root's subject, got %q", replyOver[1])
+		}
+		refs := "<" + root.ID + "@news.example.com> <" + reply.ID + "@news.example.com>"
+		if nestedOver[5] != refs {
+			t.Errorf("Expected References to the root then the parent, got %q", nestedOver[5])
+		}
+	})
+
+	t.Run("ArticleByMessageID", func(t *testing.T) {
+		lines := client.multi(t, "ARTICLE <"+root.ID+"@news.example.com>", "220")
+		if !containsPrefix(lines, "Message-ID: <"+root.ID+"@news.example.com>") {
+			t.Errorf("Expected the Message-ID header, got %v", lines)
+		}
+		if !containsPrefix(lines, "Archived-At: <nostr:nevent1") {
+			t.Errorf("Expected a nostr: Archived-At header, got %v", lines)
+		}
+		if !containsPrefix(lines, "..dotted line") {
+			t.Errorf("Expected body lines starting with a dot to be stuffed, got %v", lines)
+		}
+
+		client.send("STAT <" + strings.Repeat("0", 64) + "@news.example.com>")
+		if status := client.status(); !strings.HasPrefix(status, "430 ") {
+			t.Errorf("Expected 430 for an unknown Message-ID, got %q", status)
+		}
+	})
+
+	t.Run("Post", func(t *testing.T) {
+		client.send("POST")
+		if status := client.status(); !strings.HasPrefix(status, "440 ") {
+			t.Errorf("Expected posting to be refused, got %q", status)
+		}
+	})
+}
+
+func TestMatchWildmat(t *testing.T) {
+	tests := []struct {
+		pattern string
+		name    string
+		want    bool
+	}{
+		{"*", "nophr.notes", true},
+		{"nophr.*", "nophr.threads.abc", true},
+		{"nophr.*,!nophr.threads.*", "nophr.threads.abc", false},
+		{"nophr.*,!nophr.threads.*", "nophr.notes", true},
+		{"other.*", "nophr.notes", false},
+	}
+
+	for _, tt := range tests {
+		if got := matchWildmat(tt.pattern, tt.name); got != tt.want {
+			t.Errorf("matchWildmat(%q, %q) = %v, want %v", tt.pattern, tt.name, got, tt.want)
+		}
+	}
+}
+
+// nntpClient is a line-oriented test client
+type nntpClient struct {
+	conn   net.Conn
+	reader *bufio.Reader
+}
+
+func dialNNTP(t *testing.T, port int) *nntpClient {
+	t.Helper()
+	conn, err := net.DialTimeout("tcp", fmt.Sprintf("localhost:%d", port), 2*time.Second)
+	if err != nil {
+		t.Fatalf("Failed to connect: %v", err)
+	}
+	conn.SetDeadline(time.Now().Add(10 * time.Second))
+	return &nntpClient{conn: conn, reader: bufio.NewReader(conn)}
+}
+
+func (c *nntpClient) send(command string) {
+	fmt.Fprintf(c.conn, "%s\r\n", command)
+}
+
+// status reads one response line
+func (c *nntpClient) status() string {
+	line, _ := c.reader.ReadString('\n')
+	return strings.TrimRight(line, "\r\n")
+}
+
+// multi sends a command and returns the lines of its multi-line response,
+// still dot-stuffed
+func (c *nntpClient) multi(t *testing.T, command, code string) []string {
+	t.Helper()
+	c.send(command)
+	if status := c.status(); !strings.HasPrefix(status, code+" ") {
+		t.Fatalf("%s: expected %s, got %q", command, code, status)
+	}
+
+	var lines []string
+	for {
+		line, err := c.reader.ReadString('\n')
+		if err != nil {
+			t.Fatalf("%s: response ended early: %v", command, err)
+		}
+		line = strings.TrimRight(line, "\r\n")
+		if line == "." {
+			return lines
+		}
+		lines = append(lines, line)
+	}
+}
+
+func containsPrefix(lines []string, prefix string) bool {
+	for _, line := range lines {
+		if strings.HasPrefix(line, prefix) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,554 @@
+package nntp
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/nbd-wtf/go-nostr"
+	"github.com/sandwich/nophr/internal/aggregates"
+)
+
+// maxLineLength is the longest command line accepted (RFC 3977 section 3.1)
+const maxLineLength = 512
+
+// errLineTooLong is returned by readLine for lines over maxLineLength
+var errLineTooLong = errors.New("command line too long")
+
+// overviewFormat lists the OVER fields after the article number
+var overviewFormat = []string{"Subject:", "From:", "Date:", "Message-ID:", "References:", ":bytes", ":lines"}
+
+// readLine reads one CRLF-terminated command line, discarding overlong lines
+func readLine(reader *bufio.Reader) (string, error) {
+	line, err := reader.ReadSlice('\n')
+	if err == bufio.ErrBufferFull {
+		for err == bufio.ErrBufferFull {
+			_, err = reader.ReadSlice('\n')
+		}
+		if err != nil {
+			return "", err
+		}
+		return "", errLineTooLong
+	}
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimRight(string(line), "\r\n"), nil
+}
+
+// session is one reader connection: the selected group and current article
+type session struct {
+	server  *Server
+	writer  *bufio.Writer
+	group   *group
+	current int // Index of the current article in group, -1 if there is none
+}
+
+func newSession(s *Server, writer *bufio.Writer) *session {
+	return &session{server: s, writer: writer, current: -1}
+}
+
+// greet sends the initial greeting; posting is never allowed
+func (ss *session) greet() {
+	ss.reply(fmt.Sprintf("201 %s nophr NNTP service ready, posting prohibited", ss.server.config.Host))
+	ss.writer.Flush()
+}
+
+// reply writes a single response line
+func (ss *session) reply(line string) {
+	ss.writer.WriteString(line + "\r\n")
+}
+
+// block writes the lines of a multi-line response, dot-stuffed, and its terminator
+func (ss *session) block(lines []string) {
+	for _, line := range lines {
+		if strings.HasPrefix(line, ".") {
+			line = "." + line
+		}
+		ss.reply(line)
+	}
+	ss.reply(".")
+}
+
+// handle runs one command and reports whether the client quit
+func (ss *session) handle(ctx context.Context, line string) bool {
+	defer ss.writer.Flush()
+
+	fields := strings.Fields(line)
+	if len(fields) == 0 {
+		ss.reply("500 Empty command")
+		return false
+	}
+	command, args := strings.ToUpper(fields[0]), fields[1:]
+
+	switch command {
+	case "CAPABILITIES":
+		ss.reply("101 Capability list:")
+		ss.block([]string{"VERSION 2", "READER", "OVER", "LIST ACTIVE NEWSGROUPS OVERVIEW.FMT", "IMPLEMENTATION nophr"})
+	case "MODE":
+		if len(args) == 1 && strings.EqualFold(args[0], "READER") {
+			ss.reply("201 Posting prohibited")
+		} else {
+			ss.reply("501 Unknown MODE variant")
+		}
+	case "QUIT":
+		ss.reply("205 Bye")
+		return true
+	case "DATE":
+		ss.reply("111 " + time.Now().UTC().Format("20060102150405"))
+	case "HELP":
+		ss.reply("100 Help text follows")
+		ss.block([]string{
+			"ARTICLE|HEAD|BODY|STAT [number|<message-id>]",
+			"CAPABILITIES",
+			"DATE",
+			"GROUP newsgroup",
+			"LAST",
+			"LIST [ACTIVE|NEWSGROUPS [wildmat]|OVERVIEW.FMT]",
+			"LISTGROUP [newsgroup [range]]",
+			"MODE READER",
+			"NEWGROUPS yyyymmdd hhmmss [GMT]",
+			"NEWNEWS wildmat yyyymmdd hhmmss [GMT]",
+			"NEXT",
+			"OVER|XOVER [range|<message-id>]",
+			"QUIT",
+		})
+	case "LIST":
+		ss.list(ctx, args)
+	case "GROUP":
+		ss.selectGroup(ctx, args, false)
+	case "LISTGROUP":
+		ss.selectGroup(ctx, args, true)
+	case "ARTICLE", "HEAD", "BODY", "STAT":
+		ss.article(ctx, command, args)
+	case "NEXT", "LAST":
+		ss.step(command)
+	case "OVER", "XOVER":
+		ss.over(ctx, args)
+	case "NEWGROUPS":
+		ss.newGroups(ctx, args)
+	case "NEWNEWS":
+		ss.newNews(ctx, args)
+	case "POST":
+		ss.reply("440 Posting not permitted")
+	case "IHAVE":
+		ss.reply("435 Article not wanted")
+	default:
+		ss.reply("500 Unknown command")
+	}
+	return false
+}
+
+// list answers LIST ACTIVE, LIST NEWSGROUPS and LIST OVERVIEW.FMT
+func (ss *session) list(ctx context.Context, args []string) {
+	keyword := "ACTIVE"
+	if len(args) > 0 {
+		keyword = strings.ToUpper(args[0])
+	}
+	pattern := "*"
+	if len(args) > 1 {
+		pattern = args[1]
+	}
+
+	switch keyword {
+	case "OVERVIEW.FMT":
+		ss.reply("215 Order of fields in overview database")
+		ss.block(overviewFormat)
+		return
+	case "ACTIVE", "NEWSGROUPS":
+	default:
+		ss.reply("501 Unknown LIST keyword")
+		return
+	}
+
+	groups, err := ss.server.listGroups(ctx)
+	if err != nil {
+		ss.reply("403 Failed to list groups")
+		return
+	}
+
+	var lines []string
+	for _, g := range groups {
+		if !matchWildmat(pattern, g.name) {
+			continue
+		}
+		if keyword == "ACTIVE" {
+			lines = append(lines, fmt.Sprintf("%s %d %d n", g.name, g.high(), g.low()))
+		} else {
+			lines = append(lines, g.name+"\t"+g.description)
+		}
+	}
+	ss.reply("215 List follows")
+	ss.block(lines)
+}
+
+// selectGroup answers GROUP and LISTGROUP, making the group current
+func (ss *session) selectGroup(ctx context.Context, args []string, listNumbers bool) {
+	g := ss.group
+	if len(args) > 0 {
+		loaded, err := ss.server.loadGroup(ctx, args[0])
+		if err != nil {
+			ss.reply("403 Failed to load group")
+			return
+		}
+		if loaded == nil {
+			ss.reply("411 No such newsgroup")
+			return
+		}
+		g = loaded
+	}
+	if g == nil {
+		ss.reply("412 No newsgroup selected")
+		return
+	}
+
+	ss.group = g
+	ss.current = -1
+	if len(g.articles) > 0 {
+		ss.current = 0
+	}
+
+	status := fmt.Sprintf("211 %d %d %d %s", len(g.articles), g.low(), g.high(), g.name)
+	if !listNumbers {
+		ss.reply(status)
+		return
+	}
+
+	low, high := g.low(), g.high()
+	if len(args) > 1 {
+		var ok bool
+		if low, high, ok = parseRange(args[1], g.high()); !ok {
+			ss.reply("501 Invalid range")
+			return
+		}
+	}
+	var numbers []string
+	for _, a := range g.articles {
+		if a.number >= low && a.number <= high {
+			numbers = append(numbers, strconv.FormatInt(a.number, 10))
+		}
+	}
+	ss.reply(status + " list follows")
+	ss.block(numbers)
+}
+
+// article answers ARTICLE, HEAD, BODY and STAT
+func (ss *session) article(ctx context.Context, command string, args []string) {
+	a, number, ok := ss.lookup(ctx, args)
+	if !ok {
+		return
+	}
+
+	mid := ss.server.messageID(a.event.ID)
+	switch command {
+	case "ARTICLE":
+		ss.reply(fmt.Sprintf("220 %d %s", number, mid))
+		lines := append(ss.server.head(a), "")
+		ss.block(append(lines, bodyLines(ss.server.renderBody(ctx, a))...))
+	case "HEAD":
+		ss.reply(fmt.Sprintf("221 %d %s", number, mid))
+		ss.block(ss.server.head(a))
+	case "BODY":
+		ss.reply(fmt.Sprintf("222 %d %s", number, mid))
+		ss.block(bodyLines(ss.server.renderBody(ctx, a)))
+	case "STAT":
+		ss.reply(fmt.Sprintf("223 %d %s", number, mid))
+	}
+}
+
+// lookup finds the article named by a command's argument: a Message-ID, an
+// article number in the current group, or the current article. Numbered
+// lookups move the current article. It writes the error response itself.
+func (ss *session) lookup(ctx context.Context, args []string) (*article, int64, bool) {
+	if len(args) > 0 && strings.HasPrefix(args[0], "<") {
+		a, number := ss.byMessageID(ctx, args[0])
+		if a == nil {
+			ss.reply("430 No such article")
+			return nil, 0, false
+		}
+		return a, number, true
+	}
+
+	if ss.group == nil {
+		ss.reply("412 No newsgroup selected")
+		return nil, 0, false
+	}
+
+	if len(args) == 0 {
+		if ss.current < 0 {
+			ss.reply("420 Current article number is invalid")
+			return nil, 0, false
+		}
+		a := ss.group.articles[ss.current]
+		return a, a.number, true
+	}
+
+	number, err := strconv.ParseInt(args[0], 10, 64)
+	if err != nil {
+		ss.reply("501 Invalid article number")
+		return nil, 0, false
+	}
+	i := ss.group.find(number)
+	if i < 0 {
+		ss.reply("423 No article with that number")
+		return nil, 0, false
+	}
+	ss.current = i
+	return ss.group.articles[i], number, true
+}
+
+// byMessageID returns the article with a Message-ID and its number in the
+// current group, or 0 when it's served from outside the group
+func (ss *session) byMessageID(ctx context.Context, mid string) (*article, int64) {
+	eventID, ok := ss.server.parseMessageID(mid)
+	if !ok {
+		return nil, 0
+	}
+
+	if ss.group != nil {
+		for _, a := range ss.group.articles {
+			if a.event.ID == eventID {
+				return a, a.number
+			}
+		}
+	}
+
+	events, err := ss.server.storage.QueryEvents(ctx, nostr.Filter{IDs: []string{eventID}})
+	if err != nil || len(events) == 0 {
+		return nil, 0
+	}
+	articles := ss.server.newArticles(ctx, ss.server.homeGroup(events[0]), events[:1])
+	return articles[0], 0
+}
+
+// step answers NEXT and LAST
+func (ss *session) step(command string) {
+	if ss.group == nil {
+		ss.reply("412 No newsgroup selected")
+		return
+	}
+	if ss.current < 0 {
+		ss.reply("420 Current article number is invalid")
+		return
+	}
+
+	next := ss.current + 1
+	if command == "LAST" {
+		next = ss.current - 1
+	}
+	switch {
+	case next >= len(ss.group.articles):
+		ss.reply("421 No next article in this group")
+		return
+	case next < 0:
+		ss.reply("422 No previous article in this group")
+		return
+	}
+
+	ss.current = next
+	a := ss.group.articles[next]
+	ss.reply(fmt.Sprintf("223 %d %s", a.number, ss.server.messageID(a.event.ID)))
+}
+
+// over answers OVER and XOVER for a Message-ID, a range or the current article
+func (ss *session) over(ctx context.Context, args []string) {
+	if len(args) > 0 && strings.HasPrefix(args[0], "<") {
+		a, number := ss.byMessageID(ctx, args[0])
+		if a == nil {
+			ss.reply("430 No such article")
+			return
+		}
+		ss.reply("224 Overview information follows")
+		ss.block([]string{ss.server.overview(ctx, a, number)})
+		return
+	}
+
+	if ss.group == nil {
+		ss.reply("412 No newsgroup selected")
+		return
+	}
+
+	var selected []*article
+	if len(args) == 0 {
+		if ss.current < 0 {
+			ss.reply("420 Current article number is invalid")
+			return
+		}
+		selected = ss.group.articles[ss.current : ss.current+1]
+	} else {
+		low, high, ok := parseRange(args[0], ss.group.high())
+		if !ok {
+			ss.reply("501 Invalid range")
+			return
+		}
+		for _, a := range ss.group.articles {
+			if a.number >= low && a.number <= high {
+				selected = append(selected, a)
+			}
+		}
+	}
+	if len(selected) == 0 {
+		ss.reply("423 No articles in that range")
+		return
+	}
+
+	lines := make([]string, 0, len(selected))
+	for _, a := range selected {
+		lines = append(lines, ss.server.overview(ctx, a, a.number))
+	}
+	ss.reply("224 Overview information follows")
+	ss.block(lines)
+}
+
+// newGroups answers NEWGROUPS with the thread groups started since the given time
+func (ss *session) newGroups(ctx context.Context, args []string) {
+	since, ok := parseNewsDate(args)
+	if !ok {
+		ss.reply("501 Expected yyyymmdd hhmmss [GMT]")
+		return
+	}
+
+	groups, err := ss.server.listGroups(ctx)
+	if err != nil {
+		ss.reply("403 Failed to list groups")
+		return
+	}
+
+	var lines []string
+	for _, g := range groups {
+		if ss.server.isThreadGroup(g.name) && len(g.articles) > 0 && !g.articles[0].event.CreatedAt.Time().Before(since) {
+			lines = append(lines, fmt.Sprintf("%s %d %d n", g.name, g.high(), g.low()))
+		}
+	}
+	ss.reply("231 List of new newsgroups follows")
+	ss.block(lines)
+}
+
+// newNews answers NEWNEWS with the Message-IDs posted since the given time in
+// groups matching a wildmat
+func (ss *session) newNews(ctx context.Context, args []string) {
+	if len(args) < 3 {
+		ss.reply("501 Expected wildmat yyyymmdd hhmmss [GMT]")
+		return
+	}
+	since, ok := parseNewsDate(args[1:])
+	if !ok {
+		ss.reply("501 Expected wildmat yyyymmdd hhmmss [GMT]")
+		return
+	}
+
+	groups, err := ss.server.listGroups(ctx)
+	if err != nil {
+		ss.reply("403 Failed to list groups")
+		return
+	}
+
+	seen := make(map[string]bool)
+	var lines []string
+	for _, g := range groups {
+		if !matchWildmat(args[0], g.name) {
+			continue
+		}
+		for _, a := range g.articles {
+			if a.event.CreatedAt.Time().Before(since) || seen[a.event.ID] {
+				continue
+			}
+			seen[a.event.ID] = true
+			lines = append(lines, ss.server.messageID(a.event.ID))
+		}
+	}
+	ss.reply("230 List of new articles follows")
+	ss.block(lines)
+}
+
+// homeGroup is the group an article fetched by Message-ID is filed under:
+// notes and replies belong to their thread, everything else to its listing
+func (s *Server) homeGroup(event *nostr.Event) string {
+	if event.Kind == 30023 {
+		return s.groupName("articles")
+	}
+	if info, err := aggregates.ParseThreadInfo(event); err == nil {
+		return s.groupName(threadsGroup + "." + info.GetRootOrSelf(event.ID))
+	}
+	return s.groupName("notes")
+}
+
+// isThreadGroup reports whether name is a per-thread group
+func (s *Server) isThreadGroup(name string) bool {
+	return strings.HasPrefix(name, s.groupName(threadsGroup)+".")
+}
+
+// bodyLines splits a rendered body into lines for the wire
+func bodyLines(body string) []string {
+	body = strings.ReplaceAll(body, "\r", "")
+	return strings.Split(strings.TrimSuffix(body, "\n"), "\n")
+}
+
+// parseRange parses an article range: "n", "n-" (to the end) or "n-m"
+func parseRange(spec string, high int64) (int64, int64, bool) {
+	lowText, highText, isRange := strings.Cut(spec, "-")
+	low, err := strconv.ParseInt(lowText, 10, 64)
+	if err != nil {
+		return 0, 0, false
+	}
+	if !isRange {
+		return low, low, true
+	}
+	if highText == "" {
+		return low, high, true
+	}
+	end, err := strconv.ParseInt(highText, 10, 64)
+	if err != nil {
+		return 0, 0, false
+	}
+	return low, end, true
+}
+
+// parseNewsDate parses the "yyyymmdd hhmmss [GMT]" arguments of NEWGROUPS and
+// NEWNEWS. Two-digit years are the closest to now, per RFC 3977 section 7.3.2.
+func parseNewsDate(args []string) (time.Time, bool) {
+	if len(args) < 2 || len(args[1]) != 6 {
+		return time.Time{}, false
+	}
+	date := args[0]
+	switch len(date) {
+	case 8:
+	case 6:
+		century := time.Now().UTC().Year() / 100 * 100
+		year, err := strconv.Atoi(date[:2])
+		if err != nil {
+			return time.Time{}, false
+		}
+		if century+year > time.Now().UTC().Year()+50 {
+			century -= 100
+		}
+		date = strconv.Itoa(century+year) + date[2:]
+	default:
+		return time.Time{}, false
+	}
+
+	// Times without GMT are server local; the server answers in UTC regardless
+	t, err := time.Parse("20060102150405", date+args[1])
+	if err != nil {
+		return time.Time{}, false
+	}
+	return t, true
+}
+
+// matchWildmat reports whether name matches a wildmat (RFC 3977 section 4):
+// comma-separated patterns where the last match wins and "!" negates
+func matchWildmat(wildmat, name string) bool {
+	matched := false
+	for _, pattern := range strings.Split(wildmat, ",") {
+		negate := strings.HasPrefix(pattern, "!")
+		if ok, err := path.Match(strings.TrimPrefix(pattern, "!"), name); err == nil && ok {
+			matched = !negate
+		}
+	}
+	return matched
+}
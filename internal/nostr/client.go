@@ -3,6 +3,7 @@ package nostr
 import (
 	"context"
 	"fmt"
+	"sync"
 	"time"
 
 	"github.com/nbd-wtf/go-nostr"
@@ -13,15 +14,26 @@ import (
 type Client struct {
 	pool        *nostr.SimplePool
 	relayConfig *config.Relays
+	latency     *LatencyTracker
+	subs        *SubscriptionPool
 	ctx         context.Context
 }
 
 // New creates a new Nostr client with the given configuration
 func New(ctx context.Context, relayConfig *config.Relays) *Client {
 	pool := nostr.NewSimplePool(ctx)
+	var threshold time.Duration
+	var strikes, maxSubs int
+	if relayConfig != nil {
+		threshold = time.Duration(relayConfig.Policy.SlowThresholdMs) * time.Millisecond
+		strikes = relayConfig.Policy.SlowStrikes
+		maxSubs = relayConfig.Policy.MaxConcurrentSubs
+	}
 	return &Client{
 		pool:        pool,
 		relayConfig: relayConfig,
+		latency:     NewLatencyTracker(threshold, strikes),
+		subs:        NewSubscriptionPool(maxSubs),
 		ctx:         ctx,
 	}
 }
@@ -31,13 +43,92 @@ func (c *Client) Pool() *nostr.SimplePool {
 	return c.pool
 }
 
-// FetchEvents fetches events from the given relays matching the filter
+// Latency returns the per-relay connect and first-event latency recorded so far
+func (c *Client) Latency() *LatencyTracker {
+	return c.latency
+}
+
+// Subscriptions returns the pool capping how many subscriptions are open at once
+func (c *Client) Subscriptions() *SubscriptionPool {
+	return c.subs
+}
+
+// connect opens connections to the relays that aren't connected yet, in
+// parallel, recording how long each took. Relays that fail are left for the
+// pool to retry (and report) when subscribing.
+func (c *Client) connect(ctx context.Context, relays []string) {
+	var wg sync.WaitGroup
+	for _, url := range relays {
+		if relay, ok := c.pool.Relays.Load(nostr.NormalizeURL(url)); ok && relay != nil && relay.IsConnected() {
+			continue
+		}
+
+		wg.Add(1)
+		go func(url string) {
+			defer wg.Done()
+			c.Connect(url)
+		}(url)
+	}
+
+	done := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-ctx.Done():
+	}
+}
+
+// Connect opens a connection to a relay unless one is open, recording how
+// long it took. It returns that time, or zero when the open connection was
+// reused.
+func (c *Client) Connect(url string) (time.Duration, error) {
+	if relay, ok := c.pool.Relays.Load(nostr.NormalizeURL(url)); ok && relay != nil && relay.IsConnected() {
+		return 0, nil
+	}
+
+	start := time.Now()
+	if _, err := c.pool.EnsureRelay(url); err != nil {
+		return 0, err
+	}
+	took := time.Since(start)
+	c.latency.ObserveConnect(nostr.NormalizeURL(url), took)
+	return took, nil
+}
+
+// firstEvents records each relay's first-event latency for a REQ sent at start
+type firstEvents struct {
+	latency *LatencyTracker
+	start   time.Time
+	seen    map[string]bool
+}
+
+func (c *Client) newFirstEvents() *firstEvents {
+	return &firstEvents{latency: c.latency, start: time.Now(), seen: make(map[string]bool)}
+}
+
+// observe records the latency the first time a relay sends an event
+func (f *firstEvents) observe(relay *nostr.Relay) {
+	if relay == nil || f.seen[relay.URL] {
+		return
+	}
+	f.seen[relay.URL] = true
+	f.latency.ObserveFirstEvent(relay.URL, time.Since(f.start))
+}
+
+// FetchEvents fetches events from the given relays matching the filter. It
+// returns at EOSE, so it doesn't wait for a subscription slot.
 func (c *Client) FetchEvents(ctx context.Context, relays []string, filter nostr.Filter) ([]*nostr.Event, error) {
 	events := make([]*nostr.Event, 0)
 
 	// Use SubManyEose to get events and wait for EOSE
+	c.connect(ctx, relays)
+	first := c.newFirstEvents()
 	for relayEvent := range c.pool.SubManyEose(ctx, relays, nostr.Filters{filter}) {
 		if relayEvent.Event != nil {
+			first.observe(relayEvent.Relay)
 			events = append(events, relayEvent.Event)
 		}
 	}
@@ -82,6 +173,7 @@ func (c *Client) PublishEvent(ctx context.Context, relays []string, event *nostr
 }
 
 // SubscribeEvents subscribes to events matching the filter on the given relays
+// once a subscription slot is free (relays.policy.max_concurrent_subs).
 // Returns a channel of events that will be closed when the context is cancelled
 func (c *Client) SubscribeEvents(ctx context.Context, relays []string, filters nostr.Filters) <-chan *nostr.Event {
 	eventChan := make(chan *nostr.Event, 100)
@@ -89,14 +181,24 @@ func (c *Client) SubscribeEvents(ctx context.Context, relays []string, filters n
 	go func() {
 		defer close(eventChan)
 
+		ctx, release, err := c.subs.Acquire(ctx)
+		if err != nil {
+			return
+		}
+		defer release()
+
 		fmt.Printf("[NOSTR CLIENT] Starting SubMany for %d relays with %d filters\n", len(relays), len(filters))
 		for i, relay := range relays {
 			fmt.Printf("[NOSTR CLIENT]   Relay %d: %s\n", i+1, relay)
 		}
 
+		c.connect(ctx, relays)
+		first := c.newFirstEvents()
+
 		eventCount := 0
 		for relayEvent := range c.pool.SubMany(ctx, relays, filters) {
 			if relayEvent.Event != nil {
+				first.observe(relayEvent.Relay)
 				eventCount++
 				if eventCount == 1 {
 					fmt.Printf("[NOSTR CLIENT] ✓ First event received from %s\n", relayEvent.Relay.URL)
@@ -128,6 +230,24 @@ func (c *Client) Close() {
 	c.pool.Close("client shutting down")
 }
 
+// CloseIdle closes the relay connections that have no open subscription,
+// returning how many it closed. They are reopened when next used.
+func (c *Client) CloseIdle() int {
+	closed := 0
+	c.pool.Relays.Range(func(url string, relay *nostr.Relay) bool {
+		if relay == nil || (relay.Subscriptions != nil && relay.Subscriptions.Size() > 0) {
+			return true
+		}
+		c.pool.Relays.Delete(url)
+		if relay.IsConnected() {
+			relay.Close()
+			closed++
+		}
+		return true
+	})
+	return closed
+}
+
 // GetSeedRelays returns the configured seed relays
 func (c *Client) GetSeedRelays() []string {
 	if c.relayConfig == nil {
@@ -3,26 +3,38 @@ package nostr
 import (
 	"context"
 	"fmt"
+	"sort"
+	"sync"
 	"time"
 
 	"github.com/nbd-wtf/go-nostr"
+	"github.com/nbd-wtf/go-nostr/nip05"
+	"github.com/sandwich/nophr/internal/config"
 	"github.com/sandwich/nophr/internal/storage"
 )
 
 // Discovery handles relay discovery using seed relays and NIP-65
 type Discovery struct {
-	client  *Client
-	storage *storage.Storage
+	client    *Client
+	storage   *storage.Storage
+	selection config.Discovery
 }
 
 // NewDiscovery creates a new relay discovery instance
 func NewDiscovery(client *Client, storage *storage.Storage) *Discovery {
 	return &Discovery{
-		client:  client,
-		storage: storage,
+		client:    client,
+		storage:   storage,
+		selection: config.Default().Discovery,
 	}
 }
 
+// SetSelection sets how hints from each source are weighed and how many
+// relays are chosen per author
+func (d *Discovery) SetSelection(cfg *config.Discovery) {
+	d.selection = *cfg
+}
+
 // BootstrapFromSeeds fetches relay hints for the operator's pubkey from seed relays
 // This is the initial bootstrap step that discovers the operator's relay list
 func (d *Discovery) BootstrapFromSeeds(ctx context.Context, operatorPubkey string) error {
@@ -59,10 +71,8 @@ func (d *Discovery) BootstrapFromSeeds(ctx context.Context, operatorPubkey strin
 	}
 
 	// Save hints to storage
-	for _, hint := range hints {
-		if err := d.storage.SaveRelayHint(ctx, hint); err != nil {
-			return fmt.Errorf("failed to save relay hint: %w", err)
-		}
+	if err := d.storage.SaveRelayHints(ctx, hints); err != nil {
+		return fmt.Errorf("failed to save relay hints: %w", err)
 	}
 
 	return nil
@@ -105,10 +115,8 @@ func (d *Discovery) DiscoverRelayHintsForPubkey(ctx context.Context, targetPubke
 	}
 
 	// Save hints to storage
-	for _, hint := range hints {
-		if err := d.storage.SaveRelayHint(ctx, hint); err != nil {
-			return fmt.Errorf("failed to save relay hint: %w", err)
-		}
+	if err := d.storage.SaveRelayHints(ctx, hints); err != nil {
+		return fmt.Errorf("failed to save relay hints: %w", err)
 	}
 
 	return nil
@@ -141,20 +149,19 @@ func (d *Discovery) DiscoverRelayHintsForPubkeys(ctx context.Context, pubkeys []
 		return fmt.Errorf("failed to fetch relay hints: %w", err)
 	}
 
-	// Process each event
+	// Parse every relay list, then save all hints in batched transactions
+	var hints []*storage.RelayHint
 	for _, event := range events {
-		hints, err := ParseRelayHints(event)
+		eventHints, err := ParseRelayHints(event)
 		if err != nil {
 			// Log but don't fail on individual parse errors
 			continue
 		}
+		hints = append(hints, eventHints...)
+	}
 
-		// Save hints to storage
-		for _, hint := range hints {
-			if err := d.storage.SaveRelayHint(ctx, hint); err != nil {
-				return fmt.Errorf("failed to save relay hint: %w", err)
-			}
-		}
+	if err := d.storage.SaveRelayHints(ctx, hints); err != nil {
+		return fmt.Errorf("failed to save relay hints: %w", err)
 	}
 
 	return nil
@@ -169,17 +176,15 @@ func (d *Discovery) GetOutboxRelays(ctx context.Context, pubkey string) ([]strin
 		return nil, fmt.Errorf("failed to get write relays: %w", err)
 	}
 
-	if len(relays) > 0 {
-		return relays, nil
-	}
-
-	// Fall back to read relays as backup
-	relays, err = d.storage.GetReadRelays(ctx, pubkey)
-	if err != nil {
-		return nil, fmt.Errorf("failed to get read relays: %w", err)
+	if len(relays) == 0 {
+		// Fall back to read relays as backup
+		relays, err = d.storage.GetReadRelays(ctx, pubkey)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get read relays: %w", err)
+		}
 	}
 
-	return relays, nil
+	return d.selectRelays(ctx, pubkey, relays)
 }
 
 // GetInboxRelays returns where a pubkey RECEIVES interactions (read relays)
@@ -191,17 +196,146 @@ func (d *Discovery) GetInboxRelays(ctx context.Context, pubkey string) ([]string
 		return nil, fmt.Errorf("failed to get read relays: %w", err)
 	}
 
-	if len(relays) > 0 {
-		return relays, nil
+	if len(relays) == 0 {
+		// Fall back to write relays as backup
+		relays, err = d.storage.GetWriteRelays(ctx, pubkey)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get write relays: %w", err)
+		}
 	}
 
-	// Fall back to write relays as backup
-	relays, err = d.storage.GetWriteRelays(ctx, pubkey)
+	return d.selectRelays(ctx, pubkey, relays)
+}
+
+// maxTagSightings caps how many tag sightings of a relay add confidence, so a
+// relay named in many tags can't outrank the author's own list
+const maxTagSightings = 3
+
+// selectRelays combines an author's NIP-65 relays with their NIP-05 and tag
+// hints, scoring each relay by the confidence of the sources naming it.
+// Relays below the minimum confidence are dropped; the rest are returned
+// best first, up to the per-author cap.
+func (d *Discovery) selectRelays(ctx context.Context, pubkey string, listed []string) ([]string, error) {
+	weights := d.selection.HintConfidence
+
+	sourced, err := d.storage.GetSourcedRelayHints(ctx, pubkey)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get write relays: %w", err)
+		return nil, fmt.Errorf("failed to get relay hints: %w", err)
 	}
 
-	return relays, nil
+	// Scored by normalized URL, keeping the first spelling seen
+	var relays []string
+	scores := make(map[string]float64)
+	names := make(map[string]string)
+	add := func(relay string, score float64) {
+		key := nostr.NormalizeURL(relay)
+		if _, ok := names[key]; !ok {
+			names[key] = relay
+			relays = append(relays, key)
+		}
+		scores[key] += score
+	}
+
+	for _, relay := range listed {
+		add(relay, max(weights.RelayList, 0))
+	}
+	for _, hint := range sourced {
+		switch hint.Source {
+		case storage.HintSourceNIP05:
+			add(hint.Relay, max(weights.NIP05, 0))
+		case storage.HintSourceTag:
+			add(hint.Relay, max(weights.Tags, 0)*float64(min(hint.Sightings, maxTagSightings)))
+		}
+	}
+
+	selected := make([]string, 0, len(relays))
+	for _, key := range relays {
+		if scores[key] > 0 && scores[key] >= weights.Min {
+			selected = append(selected, key)
+		}
+	}
+	// Stable, so equal scores keep the NIP-65 list's freshness order
+	sort.SliceStable(selected, func(i, j int) bool {
+		return scores[selected[i]] > scores[selected[j]]
+	})
+	if limit := d.selection.MaxRelaysPerAuthor; limit > 0 && len(selected) > limit {
+		selected = selected[:limit]
+	}
+
+	for i, key := range selected {
+		selected[i] = names[key]
+	}
+	return selected, nil
+}
+
+// nip05Timeout bounds each NIP-05 document lookup
+const nip05Timeout = 5 * time.Second
+
+// nip05Concurrency is how many NIP-05 documents are fetched at once
+const nip05Concurrency = 8
+
+// nip05ProfileChunk is how many authors' profiles are read per query
+const nip05ProfileChunk = 100
+
+// DiscoverNIP05Relays stores the relays listed in the NIP-05 documents of
+// pubkeys whose stored profiles have a NIP-05 identifier. Documents that
+// can't be fetched or name a different pubkey are skipped.
+func (d *Discovery) DiscoverNIP05Relays(ctx context.Context, pubkeys []string) error {
+	if len(pubkeys) == 0 || d.selection.HintConfidence.NIP05 < 0 {
+		return nil
+	}
+
+	// Profiles are replaceable, so each chunk stays under the storage query cap
+	var profiles []*nostr.Event
+	for start := 0; start < len(pubkeys); start += nip05ProfileChunk {
+		chunk := pubkeys[start:min(start+nip05ProfileChunk, len(pubkeys))]
+		events, err := d.storage.QueryEvents(ctx, nostr.Filter{Kinds: []int{0}, Authors: chunk, Limit: len(chunk)})
+		if err != nil {
+			return fmt.Errorf("failed to query profiles: %w", err)
+		}
+		profiles = append(profiles, events...)
+	}
+
+	sem := make(chan struct{}, nip05Concurrency)
+	var wg sync.WaitGroup
+	for _, profile := range profiles {
+		meta := ParseProfile(profile)
+		if meta == nil || !nip05.IsValidIdentifier(meta.NIP05) {
+			continue
+		}
+
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(pubkey, identifier string) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			d.discoverNIP05Relays(ctx, pubkey, identifier)
+		}(profile.PubKey, meta.NIP05)
+	}
+	wg.Wait()
+
+	return ctx.Err()
+}
+
+// discoverNIP05Relays looks up one NIP-05 identifier and stores its relays
+func (d *Discovery) discoverNIP05Relays(ctx context.Context, pubkey, identifier string) {
+	lookupCtx, cancel := context.WithTimeout(ctx, nip05Timeout)
+	defer cancel()
+
+	pointer, err := nip05.QueryIdentifier(lookupCtx, identifier)
+	if err != nil || pointer.PublicKey != pubkey {
+		return
+	}
+
+	var relays []string
+	for _, relay := range pointer.Relays {
+		if ValidateRelayURL(relay) {
+			relays = append(relays, nostr.NormalizeURL(relay))
+		}
+	}
+	if err := d.storage.ReplaceSourcedRelayHints(ctx, pubkey, storage.HintSourceNIP05, relays, time.Now().Unix()); err != nil {
+		fmt.Printf("[RELAY] ⚠ Failed to save NIP-05 relays for %s: %v\n", identifier, err)
+	}
 }
 
 // GetRelaysForPubkey returns relays for a pubkey (backwards compatibility)
@@ -3,6 +3,7 @@ package nostr
 import (
 	"context"
 	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/sandwich/nophr/internal/config"
@@ -141,6 +142,67 @@ func TestGetRelaysForPubkeyFallback(t *testing.T) {
 	}
 }
 
+func TestGetOutboxRelaysConfidence(t *testing.T) {
+	discovery, st, cleanup := setupTestDiscovery(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	pubkey := "test-pubkey"
+
+	if err := st.SaveRelayHint(ctx, &storage.RelayHint{
+		Pubkey: pubkey, Relay: "wss://listed.test", CanWrite: true, Freshness: 100,
+	}); err != nil {
+		t.Fatalf("SaveRelayHint() error = %v", err)
+	}
+	if err := st.ReplaceSourcedRelayHints(ctx, pubkey, storage.HintSourceNIP05,
+		[]string{"wss://nip05.test", "wss://both.test"}, 100); err != nil {
+		t.Fatalf("ReplaceSourcedRelayHints() error = %v", err)
+	}
+	if err := st.RecordRelayHintSightings(ctx, []*storage.SourcedRelayHint{
+		{Pubkey: pubkey, Relay: "wss://both.test", Source: storage.HintSourceTag, Sightings: 1, LastSeen: 100},
+		{Pubkey: pubkey, Relay: "wss://once.test", Source: storage.HintSourceTag, Sightings: 1, LastSeen: 100},
+		{Pubkey: pubkey, Relay: "wss://often.test", Source: storage.HintSourceTag, Sightings: 5, LastSeen: 100},
+	}); err != nil {
+		t.Fatalf("RecordRelayHintSightings() error = %v", err)
+	}
+
+	// NIP-05 plus a tag (1.0) ties the relay list, which keeps its place;
+	// tags seen five times count as three (0.9); one sighting (0.3) is too weak
+	relays, err := discovery.GetOutboxRelays(ctx, pubkey)
+	if err != nil {
+		t.Fatalf("GetOutboxRelays() error = %v", err)
+	}
+	want := []string{"wss://listed.test", "wss://both.test", "wss://often.test", "wss://nip05.test"}
+	if strings.Join(relays, " ") != strings.Join(want, " ") {
+		t.Errorf("GetOutboxRelays() = %v, want %v", relays, want)
+	}
+
+	// The per-author cap keeps the most trusted relays
+	selection := config.Default().Discovery
+	selection.MaxRelaysPerAuthor = 2
+	discovery.SetSelection(&selection)
+	relays, err = discovery.GetOutboxRelays(ctx, pubkey)
+	if err != nil {
+		t.Fatalf("GetOutboxRelays() error = %v", err)
+	}
+	if len(relays) != 2 || relays[0] != "wss://listed.test" {
+		t.Errorf("GetOutboxRelays() with cap 2 = %v", relays)
+	}
+
+	// A negative weight ignores a source
+	selection.MaxRelaysPerAuthor = 8
+	selection.HintConfidence.Tags = -1
+	discovery.SetSelection(&selection)
+	relays, err = discovery.GetOutboxRelays(ctx, pubkey)
+	if err != nil {
+		t.Fatalf("GetOutboxRelays() error = %v", err)
+	}
+	want = []string{"wss://listed.test", "wss://both.test", "wss://nip05.test"}
+	if strings.Join(relays, " ") != strings.Join(want, " ") {
+		t.Errorf("GetOutboxRelays() without tags = %v, want %v", relays, want)
+	}
+}
+
 func TestDiscoverRelayHintsForPubkeys_Empty(t *testing.T) {
 	discovery, _, cleanup := setupTestDiscovery(t)
 	defer cleanup()
@@ -0,0 +1,147 @@
+package nostr
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/nbd-wtf/go-nostr"
+	"github.com/sandwich/nophr/internal/storage"
+)
+
+// Fetcher limits
+const (
+	fetchTimeout      = 5 * time.Second  // One lookup of an event on the seeds
+	fetchMissTTL      = 10 * time.Minute // How long an event that wasn't found isn't looked for again
+	maxFetches        = 4                // Lookups running at once; more are refused
+	maxRememberedMiss = 1000
+)
+
+// ErrFetchBusy is returned when as many lookups as allowed are running
+var ErrFetchBusy = errors.New("too many event lookups in progress")
+
+// Fetcher fetches events that aren't stored, for pages linking to them
+// (behavior.fetch_on_miss). It asks the seed relays with a short timeout and
+// stores what it finds, with its author's profile if that is missing, so the
+// next request is served from storage.
+type Fetcher struct {
+	client  *Client
+	storage *storage.Storage
+	timeout time.Duration
+	running chan struct{}
+
+	mu       sync.Mutex
+	misses   map[string]time.Time // Event IDs not found, until when they aren't looked for
+	inFlight map[string]bool
+}
+
+// NewFetcher creates a fetcher that looks events up with client and stores them in st
+func NewFetcher(client *Client, st *storage.Storage) *Fetcher {
+	return &Fetcher{
+		client:   client,
+		storage:  st,
+		timeout:  fetchTimeout,
+		running:  make(chan struct{}, maxFetches),
+		misses:   make(map[string]time.Time),
+		inFlight: make(map[string]bool),
+	}
+}
+
+// Fetch looks up the event with the given hex ID on the seed relays and
+// stores it. It fails without asking the relays while the event is
+// remembered as not found, is already being looked up, or too many lookups
+// are running.
+func (f *Fetcher) Fetch(ctx context.Context, id string) error {
+	if !f.begin(id) {
+		return fmt.Errorf("event %s not found", id)
+	}
+	defer f.end(id)
+
+	select {
+	case f.running <- struct{}{}:
+		defer func() { <-f.running }()
+	default:
+		return ErrFetchBusy
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, f.timeout)
+	defer cancel()
+
+	relays := f.client.GetSeedRelays()
+	result := f.client.Pool().QuerySingle(ctx, relays, nostr.Filter{IDs: []string{id}})
+	if result == nil || result.Event == nil || result.Event.ID != id {
+		f.miss(id)
+		return fmt.Errorf("event %s not found on %d relays", id, len(relays))
+	}
+	event := result.Event
+	if ok, err := event.CheckSignature(); !ok || err != nil {
+		f.miss(id)
+		return fmt.Errorf("event %s has an invalid signature", id)
+	}
+	if err := f.storage.StoreEvent(ctx, event); err != nil {
+		return err
+	}
+
+	// The page names its author, so fetch their profile too
+	profiles, err := f.storage.QueryEvents(ctx, nostr.Filter{Kinds: []int{0}, Authors: []string{event.PubKey}, Limit: 1})
+	if err == nil && len(profiles) == 0 {
+		found, _ := f.client.FetchEvents(ctx, relays, nostr.Filter{Kinds: []int{0}, Authors: []string{event.PubKey}})
+		var newest *nostr.Event
+		for _, profile := range found {
+			if profile.PubKey == event.PubKey && (newest == nil || profile.CreatedAt > newest.CreatedAt) {
+				newest = profile
+			}
+		}
+		if newest != nil {
+			if ok, _ := newest.CheckSignature(); ok {
+				f.storage.StoreEvent(ctx, newest)
+			}
+		}
+	}
+	return nil
+}
+
+// begin marks id as being looked up, unless it is already or was recently missed
+func (f *Fetcher) begin(id string) bool {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if until, ok := f.misses[id]; ok {
+		if time.Now().Before(until) {
+			return false
+		}
+		delete(f.misses, id)
+	}
+	if f.inFlight[id] {
+		return false
+	}
+	f.inFlight[id] = true
+	return true
+}
+
+func (f *Fetcher) end(id string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	delete(f.inFlight, id)
+}
+
+// miss remembers that id wasn't found
+func (f *Fetcher) miss(id string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if len(f.misses) >= maxRememberedMiss {
+		now := time.Now()
+		for missed, until := range f.misses {
+			if now.After(until) {
+				delete(f.misses, missed)
+			}
+		}
+		if len(f.misses) >= maxRememberedMiss {
+			f.misses = make(map[string]time.Time)
+		}
+	}
+	f.misses[id] = time.Now().Add(fetchMissTTL)
+}
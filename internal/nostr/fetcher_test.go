@@ -0,0 +1,45 @@
+package nostr
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/sandwich/nophr/internal/config"
+)
+
+func TestFetcherRemembersMisses(t *testing.T) {
+	ctx := context.Background()
+	client := New(ctx, &config.Relays{})
+	defer client.Close()
+	f := NewFetcher(client, nil)
+
+	id := "0000000000000000000000000000000000000000000000000000000000000001"
+	if err := f.Fetch(ctx, id); err == nil {
+		t.Fatal("Expected an event no relay has not to be found")
+	}
+	if _, ok := f.misses[id]; !ok {
+		t.Fatal("Expected the miss to be remembered")
+	}
+	if f.begin(id) {
+		t.Error("Expected a remembered miss not to be looked up again")
+	}
+
+	f.misses[id] = time.Now().Add(-time.Second)
+	if !f.begin(id) {
+		t.Error("Expected an expired miss to be looked up again")
+	}
+	if f.begin(id) {
+		t.Error("Expected an event being looked up not to be looked up twice")
+	}
+	f.end(id)
+
+	// Lookups beyond the limit are refused rather than queued
+	for i := 0; i < maxFetches; i++ {
+		f.running <- struct{}{}
+	}
+	if err := f.Fetch(ctx, "0000000000000000000000000000000000000000000000000000000000000002"); !errors.Is(err, ErrFetchBusy) {
+		t.Errorf("Fetch() with every slot taken = %v, want ErrFetchBusy", err)
+	}
+}
@@ -0,0 +1,116 @@
+package helpers
+
+import (
+	"github.com/nbd-wtf/go-nostr"
+)
+
+// ThreadRefs holds the events a kind 1 note references under NIP-10
+type ThreadRefs struct {
+	Root     string   // The root event of the thread
+	ReplyTo  string   // The direct parent event being replied to
+	Mentions []string // Other events mentioned in the note
+}
+
+// IsReply reports whether the note replies to another event
+func (r ThreadRefs) IsReply() bool {
+	return r.ReplyTo != ""
+}
+
+// ParseThreadRefs extracts thread references from a note's e tags (NIP-10).
+// This is the single NIP-10 parser, shared by aggregation and the storage
+// thread index so both agree on what a reply is.
+func ParseThreadRefs(tags nostr.Tags) ThreadRefs {
+	eTags := make([]nostr.Tag, 0)
+	for _, tag := range tags {
+		if len(tag) >= 2 && tag[0] == "e" {
+			eTags = append(eTags, tag)
+		}
+	}
+
+	if len(eTags) == 0 {
+		// Not a reply, it's a root post
+		return ThreadRefs{Mentions: make([]string, 0)}
+	}
+
+	// Try preferred marked format first
+	if hasMarkedTags(eTags) {
+		return parseMarkedFormat(eTags)
+	}
+
+	// Fall back to deprecated positional format
+	return parsePositionalFormat(eTags)
+}
+
+// hasMarkedTags checks if any e tag has a marker (root/reply/mention)
+func hasMarkedTags(eTags []nostr.Tag) bool {
+	for _, tag := range eTags {
+		if len(tag) >= 4 && tag[3] != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// parseMarkedFormat parses NIP-10 marked e tags (preferred format)
+func parseMarkedFormat(eTags []nostr.Tag) ThreadRefs {
+	refs := ThreadRefs{Mentions: make([]string, 0)}
+
+	for _, tag := range eTags {
+		eventID := tag[1]
+		marker := ""
+		if len(tag) >= 4 {
+			marker = tag[3]
+		}
+
+		switch marker {
+		case "root":
+			refs.Root = eventID
+		case "reply":
+			refs.ReplyTo = eventID
+		default:
+			// "mention" or no marker
+			refs.Mentions = append(refs.Mentions, eventID)
+		}
+	}
+
+	// If we have a reply but no root, the reply is also the root
+	if refs.ReplyTo != "" && refs.Root == "" {
+		refs.Root = refs.ReplyTo
+	}
+
+	// A root marker alone is a direct reply to the root
+	if refs.Root != "" && refs.ReplyTo == "" {
+		refs.ReplyTo = refs.Root
+	}
+
+	return refs
+}
+
+// parsePositionalFormat parses deprecated positional e tag format
+func parsePositionalFormat(eTags []nostr.Tag) ThreadRefs {
+	refs := ThreadRefs{Mentions: make([]string, 0)}
+
+	switch len(eTags) {
+	case 1:
+		// Single e tag: reply to this event (which is also the root)
+		refs.Root = eTags[0][1]
+		refs.ReplyTo = eTags[0][1]
+
+	case 2:
+		// Two e tags: [root, reply]
+		refs.Root = eTags[0][1]
+		refs.ReplyTo = eTags[1][1]
+
+	default:
+		// Many e tags: [root, ...mentions, reply]
+		refs.Root = eTags[0][1]
+		refs.ReplyTo = eTags[len(eTags)-1][1]
+
+		// Middle tags are mentions
+		for i := 1; i < len(eTags)-1; i++ {
+			refs.Mentions = append(refs.Mentions, eTags[i][1])
+		}
+	}
+
+	return refs
+}
@@ -0,0 +1,164 @@
+package nostr
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+)
+
+// LatencyBuckets are the upper bounds of the latency histogram buckets. Slower
+// observations fall in a final overflow bucket.
+var LatencyBuckets = []time.Duration{
+	100 * time.Millisecond,
+	250 * time.Millisecond,
+	500 * time.Millisecond,
+	time.Second,
+	2500 * time.Millisecond,
+	5 * time.Second,
+	10 * time.Second,
+}
+
+// Histogram counts latency observations in LatencyBuckets
+type Histogram struct {
+	Counts []int64 // One per bucket, plus the overflow bucket
+	Total  int64
+	Sum    time.Duration
+	Max    time.Duration
+}
+
+// Observe adds a latency to the histogram
+func (h *Histogram) Observe(d time.Duration) {
+	if h.Counts == nil {
+		h.Counts = make([]int64, len(LatencyBuckets)+1)
+	}
+	i := sort.Search(len(LatencyBuckets), func(i int) bool { return d <= LatencyBuckets[i] })
+	h.Counts[i]++
+	h.Total++
+	h.Sum += d
+	h.Max = max(h.Max, d)
+}
+
+// Quantile estimates the q-th quantile (0-1) as the upper bound of the bucket
+// it falls in, or Max for the overflow bucket
+func (h *Histogram) Quantile(q float64) time.Duration {
+	if h.Total == 0 {
+		return 0
+	}
+	rank := int64(q*float64(h.Total) + 0.5)
+	rank = min(max(rank, 1), h.Total)
+
+	var seen int64
+	for i, count := range h.Counts {
+		seen += count
+		if seen >= rank {
+			if i < len(LatencyBuckets) {
+				return min(LatencyBuckets[i], h.Max)
+			}
+			return h.Max
+		}
+	}
+	return h.Max
+}
+
+// Mean returns the average latency
+func (h *Histogram) Mean() time.Duration {
+	if h.Total == 0 {
+		return 0
+	}
+	return h.Sum / time.Duration(h.Total)
+}
+
+// String summarises the histogram, e.g. "p50 250ms, p95 1s (12 samples)"
+func (h *Histogram) String() string {
+	if h.Total == 0 {
+		return "no samples"
+	}
+	return fmt.Sprintf("p50 %s, p95 %s (%d samples)", h.Quantile(0.5), h.Quantile(0.95), h.Total)
+}
+
+// RelayLatency is the latency recorded for one relay
+type RelayLatency struct {
+	URL        string
+	Connect    Histogram // Time to open the websocket
+	FirstEvent Histogram // Time from REQ to the first event
+	SlowStreak int       // Consecutive observations over the threshold
+	Slow       bool      // SlowStreak has reached the configured strikes
+}
+
+// LatencyTracker records per-relay connect and first-event latency and flags
+// relays that are consistently slower than a threshold
+type LatencyTracker struct {
+	threshold time.Duration
+	strikes   int
+
+	mu     sync.Mutex
+	relays map[string]*RelayLatency
+}
+
+// NewLatencyTracker creates a tracker that flags a relay as slow after strikes
+// consecutive observations over threshold. A zero threshold never flags relays.
+func NewLatencyTracker(threshold time.Duration, strikes int) *LatencyTracker {
+	return &LatencyTracker{
+		threshold: threshold,
+		strikes:   max(strikes, 1),
+		relays:    make(map[string]*RelayLatency),
+	}
+}
+
+// ObserveConnect records how long connecting to a relay took
+func (t *LatencyTracker) ObserveConnect(url string, d time.Duration) {
+	t.observe(url, d, func(r *RelayLatency) *Histogram { return &r.Connect })
+}
+
+// ObserveFirstEvent records how long a relay took to send the first event for a REQ
+func (t *LatencyTracker) ObserveFirstEvent(url string, d time.Duration) {
+	t.observe(url, d, func(r *RelayLatency) *Histogram { return &r.FirstEvent })
+}
+
+// observe adds a latency to one of a relay's histograms and updates its slow streak,
+// logging a warning when the relay becomes slow
+func (t *LatencyTracker) observe(url string, d time.Duration, histogram func(*RelayLatency) *Histogram) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	r, ok := t.relays[url]
+	if !ok {
+		r = &RelayLatency{URL: url}
+		t.relays[url] = r
+	}
+	histogram(r).Observe(d)
+
+	if t.threshold <= 0 || d <= t.threshold {
+		r.SlowStreak = 0
+		r.Slow = false
+		return
+	}
+
+	r.SlowStreak++
+	if r.SlowStreak == t.strikes {
+		r.Slow = true
+		fmt.Printf("[NOSTR CLIENT] ⚠ %s exceeded %s %d times in a row (connect %s; first event %s)\n",
+			url, t.threshold, t.strikes, r.Connect.String(), r.FirstEvent.String())
+	}
+}
+
+// Get returns a copy of the latency recorded for a relay
+func (t *LatencyTracker) Get(url string) (RelayLatency, bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	r, ok := t.relays[url]
+	if !ok {
+		return RelayLatency{URL: url}, false
+	}
+	c := *r
+	c.Connect.Counts = append([]int64(nil), r.Connect.Counts...)
+	c.FirstEvent.Counts = append([]int64(nil), r.FirstEvent.Counts...)
+	return c, true
+}
+
+// Threshold returns the latency relays are flagged as slow over
+func (t *LatencyTracker) Threshold() time.Duration {
+	return t.threshold
+}
@@ -0,0 +1,68 @@
+package nostr
+
+import (
+	"testing"
+	"time"
+)
+
+func TestHistogram(t *testing.T) {
+	var h Histogram
+	if h.Quantile(0.5) != 0 || h.String() != "no samples" {
+		t.Errorf("Expected an empty histogram, got %s", h.String())
+	}
+
+	for _, ms := range []int{50, 80, 120, 200, 300, 900, 1200, 12000} {
+		h.Observe(time.Duration(ms) * time.Millisecond)
+	}
+
+	if h.Total != 8 || h.Max != 12*time.Second {
+		t.Errorf("Total = %d, Max = %s", h.Total, h.Max)
+	}
+	if got := h.Quantile(0.5); got != 250*time.Millisecond {
+		t.Errorf("Quantile(0.5) = %s, want 250ms", got)
+	}
+	// The slowest sample is past the last bucket, so it is reported as is
+	if got := h.Quantile(0.99); got != 12*time.Second {
+		t.Errorf("Quantile(0.99) = %s, want 12s", got)
+	}
+	if got := h.Mean(); got != 1856250*time.Microsecond {
+		t.Errorf("Mean() = %s", got)
+	}
+}
+
+func TestLatencyTrackerSlowRelays(t *testing.T) {
+	tracker := NewLatencyTracker(time.Second, 3)
+	const url = "wss://slow.test"
+
+	tracker.ObserveConnect(url, 3*time.Second)
+	tracker.ObserveFirstEvent(url, 2*time.Second)
+	if r, _ := tracker.Get(url); r.Slow || r.SlowStreak != 2 {
+		t.Errorf("Expected a streak of 2 without the slow flag, got %+v", r)
+	}
+
+	tracker.ObserveFirstEvent(url, 4*time.Second)
+	r, ok := tracker.Get(url)
+	if !ok || !r.Slow {
+		t.Fatalf("Expected the relay flagged after 3 slow observations, got %+v", r)
+	}
+	if r.Connect.Total != 1 || r.FirstEvent.Total != 2 {
+		t.Errorf("Connect has %d samples, first event %d; want 1 and 2", r.Connect.Total, r.FirstEvent.Total)
+	}
+
+	// One fast answer clears the flag
+	tracker.ObserveConnect(url, 100*time.Millisecond)
+	if r, _ := tracker.Get(url); r.Slow || r.SlowStreak != 0 {
+		t.Errorf("Expected a fast observation to clear the flag, got %+v", r)
+	}
+
+	if _, ok := tracker.Get("wss://unknown.test"); ok {
+		t.Error("Expected no latency for a relay never observed")
+	}
+
+	// A zero threshold never flags relays
+	off := NewLatencyTracker(0, 1)
+	off.ObserveConnect(url, time.Minute)
+	if r, _ := off.Get(url); r.Slow {
+		t.Error("Expected no slow flag with the threshold off")
+	}
+}
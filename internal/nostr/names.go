@@ -0,0 +1,121 @@
+package nostr
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/nbd-wtf/go-nostr"
+	"github.com/nbd-wtf/go-nostr/nip19"
+	"github.com/sandwich/nophr/internal/config"
+	"github.com/sandwich/nophr/internal/storage"
+)
+
+// Name resolver limits
+const (
+	nameTTL        = 5 * time.Minute // How long a looked up name is used before it is read again
+	maxCachedNames = 5000
+)
+
+type cachedName struct {
+	name    string // Profile display name, "" if there is none
+	expires time.Time
+}
+
+// NameResolver labels pubkeys for listings and threads with the display name
+// of their stored kind 0 profile, in a display.authors style. Names are
+// cached in memory for a few minutes, so a listing of one author's notes
+// reads their profile once.
+type NameResolver struct {
+	storage *storage.Storage
+	style   string
+
+	mu    sync.Mutex
+	names map[string]cachedName
+}
+
+// NewNameResolver creates a resolver that reads profiles from st and names
+// authors in a display.authors style; the empty style is name_npub
+func NewNameResolver(st *storage.Storage, style string) *NameResolver {
+	return &NameResolver{
+		storage: st,
+		style:   style,
+		names:   make(map[string]cachedName),
+	}
+}
+
+// Label returns how an author is shown, e.g. "Alice (npub1abcd...wxyz)". A nil
+// resolver, or one without storage, shows the shortened hex pubkey.
+func (n *NameResolver) Label(ctx context.Context, pubkey string) string {
+	if n == nil || n.storage == nil {
+		return ShortPubkey(pubkey)
+	}
+
+	switch n.style {
+	case config.AuthorsPubkey:
+		return ShortPubkey(pubkey)
+	case config.AuthorsNpub:
+		return ShortNpub(pubkey)
+	}
+
+	name := n.Name(ctx, pubkey)
+	switch {
+	case name == "":
+		return ShortNpub(pubkey)
+	case n.style == config.AuthorsName:
+		return name
+	}
+	return name + " (" + ShortNpub(pubkey) + ")"
+}
+
+// Name returns the display name of an author's stored profile, or "" if
+// there is none
+func (n *NameResolver) Name(ctx context.Context, pubkey string) string {
+	if n == nil || n.storage == nil {
+		return ""
+	}
+
+	n.mu.Lock()
+	cached, ok := n.names[pubkey]
+	n.mu.Unlock()
+	if ok && time.Now().Before(cached.expires) {
+		return cached.name
+	}
+
+	var name string
+	profiles, err := n.storage.QueryEvents(ctx, nostr.Filter{Kinds: []int{0}, Authors: []string{pubkey}, Limit: 1})
+	if err != nil {
+		return "" // Not cached, so it is read again once storage recovers
+	}
+	if len(profiles) > 0 {
+		if meta := ParseProfile(profiles[0]); meta != nil {
+			name = meta.GetDisplayName()
+		}
+	}
+
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	if len(n.names) >= maxCachedNames {
+		n.names = make(map[string]cachedName)
+	}
+	n.names[pubkey] = cachedName{name: name, expires: time.Now().Add(nameTTL)}
+	return name
+}
+
+// ShortPubkey shortens a hex pubkey to its first and last 8 characters
+func ShortPubkey(pubkey string) string {
+	if len(pubkey) <= 16 {
+		return pubkey
+	}
+	return pubkey[:8] + "..." + pubkey[len(pubkey)-8:]
+}
+
+// ShortNpub returns a pubkey's npub shortened to its prefix and last
+// characters, e.g. npub1abcd...wxyz
+func ShortNpub(pubkey string) string {
+	npub, err := nip19.EncodePublicKey(pubkey)
+	if err != nil {
+		return ShortPubkey(pubkey)
+	}
+	return npub[:9] + "..." + npub[len(npub)-4:]
+}
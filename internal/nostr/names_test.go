@@ -0,0 +1,69 @@
+package nostr
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/nbd-wtf/go-nostr"
+	"github.com/nbd-wtf/go-nostr/nip19"
+	"github.com/sandwich/nophr/internal/config"
+	"github.com/sandwich/nophr/internal/storage"
+)
+
+func TestNameResolver(t *testing.T) {
+	ctx := context.Background()
+	st, err := storage.New(ctx, &config.Storage{Driver: "sqlite", SQLitePath: filepath.Join(t.TempDir(), "test.db")})
+	if err != nil {
+		t.Fatalf("storage.New() error = %v", err)
+	}
+	defer st.Close()
+
+	secretKey := nostr.GeneratePrivateKey()
+	alice, _ := nostr.GetPublicKey(secretKey)
+	profile := &nostr.Event{Kind: 0, CreatedAt: nostr.Now(), Content: `{"name":"alice","display_name":"Alice"}`, Tags: nostr.Tags{}}
+	profile.Sign(secretKey)
+	if err := st.StoreEvent(ctx, profile); err != nil {
+		t.Fatal(err)
+	}
+	stranger, _ := nostr.GetPublicKey(nostr.GeneratePrivateKey())
+
+	npub, _ := nip19.EncodePublicKey(alice)
+	short := ShortNpub(alice)
+	if !strings.HasPrefix(short, npub[:9]) || !strings.HasSuffix(short, npub[len(npub)-4:]) {
+		t.Fatalf("ShortNpub() = %q, want a shortened %s", short, npub)
+	}
+
+	tests := []struct {
+		style  string
+		pubkey string
+		want   string
+	}{
+		{config.AuthorsNameNpub, alice, "Alice (" + short + ")"},
+		{"", alice, "Alice (" + short + ")"},
+		{config.AuthorsName, alice, "Alice"},
+		{config.AuthorsNpub, alice, short},
+		{config.AuthorsPubkey, alice, ShortPubkey(alice)},
+		{config.AuthorsNameNpub, stranger, ShortNpub(stranger)},
+		{config.AuthorsName, stranger, ShortNpub(stranger)},
+	}
+	for _, tt := range tests {
+		if got := NewNameResolver(st, tt.style).Label(ctx, tt.pubkey); got != tt.want {
+			t.Errorf("Label(%q) with style %q = %q, want %q", tt.pubkey[:8], tt.style, got, tt.want)
+		}
+	}
+
+	// Names are cached until they expire
+	names := NewNameResolver(st, config.AuthorsName)
+	names.Label(ctx, stranger)
+	names.names[stranger] = cachedName{name: "Cached", expires: names.names[stranger].expires}
+	if got := names.Label(ctx, stranger); got != "Cached" {
+		t.Errorf("Label() = %q, want the cached name", got)
+	}
+
+	var unset *NameResolver
+	if got := unset.Label(ctx, alice); got != ShortPubkey(alice) {
+		t.Errorf("nil resolver Label() = %q, want the short pubkey", got)
+	}
+}
@@ -55,6 +55,55 @@ func ParseRelayHints(event *nostr.Event) ([]*storage.RelayHint, error) {
 	return hints, nil
 }
 
+// ParseTagRelayHints extracts the relays an event's tags name for other
+// authors: p tags, e tags carrying the author's pubkey and a tag addresses
+func ParseTagRelayHints(event *nostr.Event) []*storage.SourcedRelayHint {
+	seen := make(map[[2]string]bool)
+	var hints []*storage.SourcedRelayHint
+
+	for _, tag := range event.Tags {
+		if len(tag) < 3 {
+			continue
+		}
+
+		var pubkey string
+		switch tag[0] {
+		case "p":
+			pubkey = tag[1]
+		case "e":
+			// ["e", <id>, <relay>, <marker>, <pubkey>] per NIP-10
+			if len(tag) >= 5 {
+				pubkey = tag[4]
+			}
+		case "a":
+			// <kind>:<pubkey>:<d-tag>
+			if parts := strings.SplitN(tag[1], ":", 3); len(parts) == 3 {
+				pubkey = parts[1]
+			}
+		}
+		if !nostr.IsValid32ByteHex(pubkey) || !ValidateRelayURL(tag[2]) {
+			continue
+		}
+
+		relay := nostr.NormalizeURL(tag[2])
+		key := [2]string{pubkey, relay}
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+
+		hints = append(hints, &storage.SourcedRelayHint{
+			Pubkey:    pubkey,
+			Relay:     relay,
+			Source:    storage.HintSourceTag,
+			Sightings: 1,
+			LastSeen:  int64(event.CreatedAt),
+		})
+	}
+
+	return hints
+}
+
 // BuildRelayListEvent creates a NIP-65 kind 10002 event
 // Used for publishing your own relay list
 func BuildRelayListEvent(hints []*storage.RelayHint) *nostr.Event {
@@ -1,6 +1,7 @@
 package nostr
 
 import (
+	"strings"
 	"testing"
 
 	"github.com/nbd-wtf/go-nostr"
@@ -131,6 +132,44 @@ func TestParseRelayHintsMarkers(t *testing.T) {
 	}
 }
 
+func TestParseTagRelayHints(t *testing.T) {
+	alice := strings.Repeat("a", 64)
+	bob := strings.Repeat("b", 64)
+	event := &nostr.Event{
+		CreatedAt: 12345,
+		Kind:      1,
+		Tags: nostr.Tags{
+			{"p", alice, "wss://alice.test/"},
+			{"p", alice, "wss://alice.test"}, // Same relay once normalized
+			{"e", strings.Repeat("e", 64), "wss://thread.test", "reply", bob},
+			{"e", strings.Repeat("e", 64), "wss://no-author.test", "root"},
+			{"a", "30023:" + bob + ":post", "wss://article.test"},
+			{"p", bob},
+			{"p", bob, "not a relay"},
+			{"p", "not-a-pubkey", "wss://relay.test"},
+		},
+	}
+
+	hints := ParseTagRelayHints(event)
+
+	want := []struct{ pubkey, relay string }{
+		{alice, "wss://alice.test"},
+		{bob, "wss://thread.test"},
+		{bob, "wss://article.test"},
+	}
+	if len(hints) != len(want) {
+		t.Fatalf("Expected %d hints, got %d", len(want), len(hints))
+	}
+	for i, w := range want {
+		if hints[i].Pubkey != w.pubkey || hints[i].Relay != w.relay {
+			t.Errorf("hint %d = %s at %s, want %s at %s", i, hints[i].Pubkey[:8], hints[i].Relay, w.pubkey[:8], w.relay)
+		}
+		if hints[i].Source != storage.HintSourceTag || hints[i].LastSeen != 12345 {
+			t.Errorf("hint %d: source %q, last seen %d", i, hints[i].Source, hints[i].LastSeen)
+		}
+	}
+}
+
 func TestBuildRelayListEvent(t *testing.T) {
 	hints := []*storage.RelayHint{
 		{
@@ -0,0 +1,86 @@
+package nostr
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+)
+
+// SubscriptionStats is a snapshot of a SubscriptionPool
+type SubscriptionStats struct {
+	Open    int // Subscriptions holding a slot
+	Waiting int // Subscriptions queued for a slot
+	Max     int // Slots, 0 for no limit
+}
+
+// subscriptionSlotKey marks a context whose holder already has a slot
+type subscriptionSlotKey struct{}
+
+// SubscriptionPool caps how many subscriptions are open at once
+// (relays.policy.max_concurrent_subs). Callers past the cap wait in line for
+// a slot to be released.
+type SubscriptionPool struct {
+	slots   chan struct{} // nil for no limit
+	max     int
+	open    atomic.Int64
+	waiting atomic.Int64
+}
+
+// NewSubscriptionPool creates a pool with max slots, unlimited if max <= 0
+func NewSubscriptionPool(max int) *SubscriptionPool {
+	p := &SubscriptionPool{}
+	if max > 0 {
+		p.slots = make(chan struct{}, max)
+		p.max = max
+	}
+	return p
+}
+
+// Acquire waits for a slot and returns a context carrying it with a function
+// that gives it back. A context that already carries a slot from this pool
+// gets no second one, so a holder can subscribe through the client without
+// waiting on itself. If ctx ends first, its error is returned.
+func (p *SubscriptionPool) Acquire(ctx context.Context) (context.Context, func(), error) {
+	if ctx.Value(subscriptionSlotKey{}) == p {
+		return ctx, func() {}, nil
+	}
+
+	if p.slots != nil {
+		p.waiting.Add(1)
+		select {
+		case p.slots <- struct{}{}:
+			p.waiting.Add(-1)
+		case <-ctx.Done():
+			p.waiting.Add(-1)
+			return ctx, func() {}, ctx.Err()
+		}
+	}
+	p.open.Add(1)
+
+	var once sync.Once
+	release := func() {
+		once.Do(func() {
+			p.open.Add(-1)
+			if p.slots != nil {
+				<-p.slots
+			}
+		})
+	}
+	return context.WithValue(ctx, subscriptionSlotKey{}, p), release, nil
+}
+
+// Exempt returns a context whose subscriptions take no slot. It is for
+// long-lived watches, which would otherwise hold slots for good and leave
+// sync waiting; callers keep their number small.
+func (p *SubscriptionPool) Exempt(ctx context.Context) context.Context {
+	return context.WithValue(ctx, subscriptionSlotKey{}, p)
+}
+
+// Stats returns how many slots are in use and how many callers are waiting
+func (p *SubscriptionPool) Stats() SubscriptionStats {
+	return SubscriptionStats{
+		Open:    int(p.open.Load()),
+		Waiting: int(p.waiting.Load()),
+		Max:     p.max,
+	}
+}
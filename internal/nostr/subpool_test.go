@@ -0,0 +1,87 @@
+package nostr
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestSubscriptionPool(t *testing.T) {
+	pool := NewSubscriptionPool(2)
+	ctx := context.Background()
+
+	_, releaseA, err := pool.Acquire(ctx)
+	if err != nil {
+		t.Fatalf("Acquire() error = %v", err)
+	}
+	held, releaseB, _ := pool.Acquire(ctx)
+
+	// A holder subscribing again doesn't take a second slot
+	if _, release, err := pool.Acquire(held); err != nil {
+		t.Fatalf("reentrant Acquire() error = %v", err)
+	} else {
+		release()
+	}
+	if stats := pool.Stats(); stats.Open != 2 || stats.Max != 2 {
+		t.Fatalf("Stats() = %+v, want 2 of 2 open", stats)
+	}
+
+	// A third caller waits in line until a slot is released
+	acquired := make(chan struct{})
+	go func() {
+		_, release, err := pool.Acquire(ctx)
+		if err == nil {
+			defer release()
+		}
+		close(acquired)
+	}()
+	for pool.Stats().Waiting != 1 {
+		time.Sleep(time.Millisecond)
+	}
+	select {
+	case <-acquired:
+		t.Fatal("third subscription opened past the cap")
+	default:
+	}
+
+	releaseA()
+	releaseA() // Releasing twice gives back one slot
+	<-acquired
+	releaseB()
+
+	// Waiting ends with the context
+	_, releaseC, _ := pool.Acquire(ctx)
+	_, releaseD, _ := pool.Acquire(ctx)
+	defer releaseC()
+	defer releaseD()
+	timeout, cancel := context.WithTimeout(ctx, 10*time.Millisecond)
+	defer cancel()
+	if _, _, err := pool.Acquire(timeout); err == nil {
+		t.Error("Acquire() on a full pool succeeded after the context ended")
+	}
+	if stats := pool.Stats(); stats.Waiting != 0 {
+		t.Errorf("Waiting = %d after the context ended, want 0", stats.Waiting)
+	}
+
+	// Exempt subscriptions open on a full pool without taking a slot
+	if _, release, err := pool.Acquire(pool.Exempt(ctx)); err != nil {
+		t.Errorf("Acquire() with an exempt context error = %v", err)
+	} else {
+		release()
+	}
+	if stats := pool.Stats(); stats.Open != 2 {
+		t.Errorf("Open = %d after an exempt subscription, want 2", stats.Open)
+	}
+}
+
+func TestSubscriptionPoolUnlimited(t *testing.T) {
+	pool := NewSubscriptionPool(0)
+	for i := 0; i < 100; i++ {
+		if _, _, err := pool.Acquire(context.Background()); err != nil {
+			t.Fatalf("Acquire() error = %v", err)
+		}
+	}
+	if stats := pool.Stats(); stats.Open != 100 || stats.Max != 0 {
+		t.Errorf("Stats() = %+v, want 100 open without a limit", stats)
+	}
+}
@@ -0,0 +1,108 @@
+package nwc
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/btcsuite/btcd/btcutil/bech32"
+	"github.com/nbd-wtf/go-nostr"
+)
+
+// Invoice is the part of a decoded BOLT 11 invoice a zap is checked against
+type Invoice struct {
+	MSats           int64  // Amount, 0 if the invoice leaves it to the payer
+	DescriptionHash []byte // SHA-256 the invoice commits to, nil if it has a plain description
+}
+
+// invoicePrefix matches the human-readable part: ln, a currency prefix and
+// an optional amount with its multiplier
+var invoicePrefix = regexp.MustCompile(`^ln[a-z]+?(\d*)([munp]?)$`)
+
+// msatsPerUnit is how many msats one unit of each amount multiplier is worth
+var msatsPerUnit = map[string]int64{
+	"":  100_000_000_000,
+	"m": 100_000_000,
+	"u": 100_000,
+	"n": 100,
+}
+
+const (
+	timestampWords = 7   // 35-bit timestamp
+	signatureWords = 104 // 65-byte signature
+	tagDescHash    = 23  // "h"
+)
+
+// DecodeInvoice reads the amount and description hash of a BOLT 11 invoice.
+// The signature isn't verified; the wallet that pays it does that.
+func DecodeInvoice(invoice string) (*Invoice, error) {
+	hrp, data, err := bech32.DecodeNoLimit(strings.ToLower(strings.TrimSpace(invoice)))
+	if err != nil {
+		return nil, fmt.Errorf("invalid invoice: %w", err)
+	}
+
+	match := invoicePrefix.FindStringSubmatch(hrp)
+	if match == nil {
+		return nil, fmt.Errorf("invalid invoice prefix %q", hrp)
+	}
+	decoded := &Invoice{}
+	if match[1] != "" {
+		amount, err := strconv.ParseInt(match[1], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid invoice amount %q", match[1])
+		}
+		if match[2] == "p" {
+			// Pico-bitcoin is a tenth of a msat
+			if amount%10 != 0 {
+				return nil, fmt.Errorf("invoice amount %q is below a msat", match[1]+"p")
+			}
+			decoded.MSats = amount / 10
+		} else {
+			decoded.MSats = amount * msatsPerUnit[match[2]]
+		}
+	}
+
+	if len(data) < timestampWords+signatureWords {
+		return nil, fmt.Errorf("invoice is too short")
+	}
+	fields := data[timestampWords : len(data)-signatureWords]
+	for len(fields) >= 3 {
+		tag := fields[0]
+		length := int(fields[1])<<5 | int(fields[2])
+		if len(fields) < 3+length {
+			return nil, fmt.Errorf("invoice field %d is truncated", tag)
+		}
+		value := fields[3 : 3+length]
+		fields = fields[3+length:]
+
+		if tag == tagDescHash && length == 52 {
+			hash, err := bech32.ConvertBits(value, 5, 8, false)
+			if err != nil {
+				return nil, fmt.Errorf("invalid description hash: %w", err)
+			}
+			decoded.DescriptionHash = hash
+		}
+	}
+	return decoded, nil
+}
+
+// CheckZapInvoice verifies that invoice charges exactly msats and commits
+// to zapRequest, as NIP-57 requires, so a lightning address can't bill a
+// different amount or reuse the payment for something else
+func CheckZapInvoice(invoice string, msats int64, zapRequest *nostr.Event) error {
+	decoded, err := DecodeInvoice(invoice)
+	if err != nil {
+		return err
+	}
+	if decoded.MSats != msats {
+		return fmt.Errorf("invoice is for %d msats, not the %d requested", decoded.MSats, msats)
+	}
+	hash := sha256.Sum256([]byte(zapRequest.String()))
+	if !bytes.Equal(decoded.DescriptionHash, hash[:]) {
+		return fmt.Errorf("invoice description hash doesn't match the zap request")
+	}
+	return nil
+}
@@ -0,0 +1,264 @@
+package nwc
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/nbd-wtf/go-nostr"
+	"github.com/nbd-wtf/go-nostr/nip04"
+)
+
+// NIP-47 event kinds
+const (
+	KindRequest  = 23194
+	KindResponse = 23195
+)
+
+// Connection holds the parts of a nostr+walletconnect:// URI
+type Connection struct {
+	WalletPubkey string // Pubkey of the wallet service
+	Relays       []string
+	Secret       string // Hex secret the client signs and encrypts with
+	LUD16        string // Optional lightning address of the wallet
+}
+
+// ParseURI parses a nostr+walletconnect://<pubkey>?relay=...&secret=... URI
+func ParseURI(uri string) (*Connection, error) {
+	u, err := url.Parse(strings.TrimSpace(uri))
+	if err != nil {
+		return nil, fmt.Errorf("invalid wallet connect URI: %w", err)
+	}
+	if u.Scheme != "nostr+walletconnect" && u.Scheme != "nostrwalletconnect" {
+		return nil, fmt.Errorf("wallet connect URI must start with nostr+walletconnect://")
+	}
+
+	// The pubkey sits in the host for nostr+walletconnect://pk and in the opaque part for nostr+walletconnect:pk
+	walletPubkey := u.Host
+	if walletPubkey == "" {
+		walletPubkey = strings.TrimPrefix(u.Opaque, "//")
+	}
+	if !nostr.IsValid32ByteHex(walletPubkey) {
+		return nil, fmt.Errorf("wallet connect URI has an invalid wallet pubkey")
+	}
+
+	query := u.Query()
+	secret := query.Get("secret")
+	if !nostr.IsValid32ByteHex(secret) {
+		return nil, fmt.Errorf("wallet connect URI has an invalid secret")
+	}
+
+	relays := query["relay"]
+	if len(relays) == 0 {
+		return nil, fmt.Errorf("wallet connect URI has no relay")
+	}
+
+	return &Connection{
+		WalletPubkey: walletPubkey,
+		Relays:       relays,
+		Secret:       secret,
+		LUD16:        query.Get("lud16"),
+	}, nil
+}
+
+// Transaction is an entry from list_transactions
+type Transaction struct {
+	Type        string `json:"type"` // incoming|outgoing
+	Invoice     string `json:"invoice"`
+	Description string `json:"description"`
+	PaymentHash string `json:"payment_hash"`
+	Amount      int64  `json:"amount"`    // msats
+	FeesPaid    int64  `json:"fees_paid"` // msats
+	CreatedAt   int64  `json:"created_at"`
+	SettledAt   int64  `json:"settled_at"`
+}
+
+// Error is an error returned by the wallet service
+type Error struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("wallet error %s: %s", e.Code, e.Message)
+}
+
+// response is the decrypted content of a kind 23195 event
+type response struct {
+	ResultType string          `json:"result_type"`
+	Error      *Error          `json:"error"`
+	Result     json.RawMessage `json:"result"`
+}
+
+// Client sends NIP-47 requests to a wallet service over its relay
+type Client struct {
+	conn      *Connection
+	pubkey    string
+	sharedKey []byte
+	timeout   time.Duration
+}
+
+// NewClient creates a client from a wallet connect URI
+func NewClient(uri string, timeout time.Duration) (*Client, error) {
+	conn, err := ParseURI(uri)
+	if err != nil {
+		return nil, err
+	}
+
+	pubkey, err := nostr.GetPublicKey(conn.Secret)
+	if err != nil {
+		return nil, fmt.Errorf("failed to derive client pubkey: %w", err)
+	}
+
+	sharedKey, err := nip04.ComputeSharedSecret(conn.WalletPubkey, conn.Secret)
+	if err != nil {
+		return nil, fmt.Errorf("failed to compute shared secret: %w", err)
+	}
+
+	return &Client{
+		conn:      conn,
+		pubkey:    pubkey,
+		sharedKey: sharedKey,
+		timeout:   timeout,
+	}, nil
+}
+
+// GetBalance returns the wallet balance in msats
+func (c *Client) GetBalance(ctx context.Context) (int64, error) {
+	var result struct {
+		Balance int64 `json:"balance"`
+	}
+	if err := c.call(ctx, "get_balance", struct{}{}, &result); err != nil {
+		return 0, err
+	}
+	return result.Balance, nil
+}
+
+// ListPayments returns the most recent outgoing payments, newest first
+func (c *Client) ListPayments(ctx context.Context, limit int) ([]Transaction, error) {
+	params := map[string]interface{}{
+		"limit": limit,
+		"type":  "outgoing",
+	}
+
+	var result struct {
+		Transactions []Transaction `json:"transactions"`
+	}
+	if err := c.call(ctx, "list_transactions", params, &result); err != nil {
+		return nil, err
+	}
+	return result.Transactions, nil
+}
+
+// PayInvoice pays a bolt11 invoice and returns the payment preimage
+func (c *Client) PayInvoice(ctx context.Context, invoice string) (string, error) {
+	params := map[string]interface{}{
+		"invoice": invoice,
+	}
+
+	var result struct {
+		Preimage string `json:"preimage"`
+	}
+	if err := c.call(ctx, "pay_invoice", params, &result); err != nil {
+		return "", err
+	}
+	return result.Preimage, nil
+}
+
+// call publishes a request and waits for the wallet's response
+func (c *Client) call(ctx context.Context, method string, params interface{}, result interface{}) error {
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+
+	request, err := c.buildRequest(method, params)
+	if err != nil {
+		return err
+	}
+
+	relay, err := nostr.RelayConnect(ctx, c.conn.Relays[0])
+	if err != nil {
+		return fmt.Errorf("failed to connect to wallet relay: %w", err)
+	}
+	defer relay.Close()
+
+	// Subscribe before publishing so a fast wallet can't answer before we listen
+	sub, err := relay.Subscribe(ctx, nostr.Filters{{
+		Kinds:   []int{KindResponse},
+		Authors: []string{c.conn.WalletPubkey},
+		Tags:    nostr.TagMap{"e": []string{request.ID}},
+	}})
+	if err != nil {
+		return fmt.Errorf("failed to subscribe to wallet relay: %w", err)
+	}
+	defer sub.Unsub()
+
+	if err := relay.Publish(ctx, *request); err != nil {
+		return fmt.Errorf("failed to send %s request: %w", method, err)
+	}
+
+	select {
+	case event, ok := <-sub.Events:
+		if !ok {
+			return fmt.Errorf("wallet relay closed the subscription")
+		}
+		return c.decodeResponse(event, method, result)
+	case <-ctx.Done():
+		return fmt.Errorf("wallet did not answer %s: %w", method, ctx.Err())
+	}
+}
+
+// buildRequest encrypts and signs a kind 23194 request
+func (c *Client) buildRequest(method string, params interface{}) (*nostr.Event, error) {
+	payload, err := json.Marshal(map[string]interface{}{
+		"method": method,
+		"params": params,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode %s request: %w", method, err)
+	}
+
+	content, err := nip04.Encrypt(string(payload), c.sharedKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encrypt %s request: %w", method, err)
+	}
+
+	event := &nostr.Event{
+		PubKey:    c.pubkey,
+		CreatedAt: nostr.Now(),
+		Kind:      KindRequest,
+		Tags:      nostr.Tags{{"p", c.conn.WalletPubkey}},
+		Content:   content,
+	}
+	if err := event.Sign(c.conn.Secret); err != nil {
+		return nil, fmt.Errorf("failed to sign %s request: %w", method, err)
+	}
+
+	return event, nil
+}
+
+// decodeResponse decrypts a kind 23195 response into result
+func (c *Client) decodeResponse(event *nostr.Event, method string, result interface{}) error {
+	plaintext, err := nip04.Decrypt(event.Content, c.sharedKey)
+	if err != nil {
+		return fmt.Errorf("failed to decrypt %s response: %w", method, err)
+	}
+
+	var resp response
+	if err := json.Unmarshal([]byte(plaintext), &resp); err != nil {
+		return fmt.Errorf("invalid %s response: %w", method, err)
+	}
+	if resp.Error != nil {
+		return resp.Error
+	}
+	if resp.ResultType != "" && resp.ResultType != method {
+		return fmt.Errorf("wallet answered %s with %s", method, resp.ResultType)
+	}
+
+	if err := json.Unmarshal(resp.Result, result); err != nil {
+		return fmt.Errorf("invalid %s result: %w", method, err)
+	}
+	return nil
+}
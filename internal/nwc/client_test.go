@@ -0,0 +1,176 @@
+package nwc
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/btcsuite/btcd/btcutil/bech32"
+	"github.com/nbd-wtf/go-nostr"
+	"github.com/nbd-wtf/go-nostr/nip04"
+)
+
+func testURI(t *testing.T) (uri, walletSecret string) {
+	t.Helper()
+	walletSecret = nostr.GeneratePrivateKey()
+	walletPubkey, _ := nostr.GetPublicKey(walletSecret)
+	clientSecret := nostr.GeneratePrivateKey()
+	return "nostr+walletconnect://" + walletPubkey + "?relay=wss%3A%2F%2Frelay.example.com&secret=" + clientSecret, walletSecret
+}
+
+func TestParseURI(t *testing.T) {
+	uri, walletSecret := testURI(t)
+	walletPubkey, _ := nostr.GetPublicKey(walletSecret)
+
+	conn, err := ParseURI(uri + "&lud16=me%40example.com")
+	if err != nil {
+		t.Fatalf("ParseURI() error = %v", err)
+	}
+	if conn.WalletPubkey != walletPubkey {
+		t.Errorf("WalletPubkey = %s, want %s", conn.WalletPubkey, walletPubkey)
+	}
+	if len(conn.Relays) != 1 || conn.Relays[0] != "wss://relay.example.com" {
+		t.Errorf("Relays = %v", conn.Relays)
+	}
+	if conn.LUD16 != "me@example.com" {
+		t.Errorf("LUD16 = %s", conn.LUD16)
+	}
+
+	invalid := map[string]string{
+		"wrong scheme":   "https://example.com",
+		"bad pubkey":     "nostr+walletconnect://abc?relay=wss%3A%2F%2Fr&secret=" + nostr.GeneratePrivateKey(),
+		"missing secret": "nostr+walletconnect://" + walletPubkey + "?relay=wss%3A%2F%2Fr",
+		"missing relay":  "nostr+walletconnect://" + walletPubkey + "?secret=" + nostr.GeneratePrivateKey(),
+	}
+	for name, uri := range invalid {
+		t.Run(name, func(t *testing.T) {
+			if _, err := ParseURI(uri); err == nil {
+				t.Errorf("ParseURI(%q) expected error", uri)
+			}
+		})
+	}
+}
+
+func TestRequestRoundTrip(t *testing.T) {
+	uri, walletSecret := testURI(t)
+	client, err := NewClient(uri, time.Second)
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+
+	request, err := client.buildRequest("pay_invoice", map[string]string{"invoice": "lnbc1"})
+	if err != nil {
+		t.Fatalf("buildRequest() error = %v", err)
+	}
+	if request.Kind != KindRequest {
+		t.Errorf("Kind = %d, want %d", request.Kind, KindRequest)
+	}
+	if ok, err := request.CheckSignature(); err != nil || !ok {
+		t.Errorf("request signature does not verify: %v", err)
+	}
+
+	// The wallet decrypts with its own secret and the client's pubkey
+	walletKey, _ := nip04.ComputeSharedSecret(request.PubKey, walletSecret)
+	plaintext, err := nip04.Decrypt(request.Content, walletKey)
+	if err != nil {
+		t.Fatalf("wallet could not decrypt request: %v", err)
+	}
+	var payload struct {
+		Method string            `json:"method"`
+		Params map[string]string `json:"params"`
+	}
+	if err := json.Unmarshal([]byte(plaintext), &payload); err != nil {
+		t.Fatalf("request payload: %v", err)
+	}
+	if payload.Method != "pay_invoice" || payload.Params["invoice"] != "lnbc1" {
+		t.Errorf("payload = %+v", payload)
+	}
+
+	reply := func(body string) *nostr.Event {
+		content, _ := nip04.Encrypt(body, walletKey)
+		return &nostr.Event{Kind: KindResponse, Content: content}
+	}
+
+	var result struct {
+		Preimage string `json:"preimage"`
+	}
+	if err := client.decodeResponse(reply(`{"result_type":"pay_invoice","result":{"preimage":"ff"}}`), "pay_invoice", &result); err != nil {
+		t.Fatalf("decodeResponse() error = %v", err)
+	}
+	if result.Preimage != "ff" {
+		t.Errorf("Preimage = %s, want ff", result.Preimage)
+	}
+
+	err = client.decodeResponse(reply(`{"result_type":"pay_invoice","error":{"code":"INSUFFICIENT_BALANCE","message":"too poor"}}`), "pay_invoice", &result)
+	if walletErr, ok := err.(*Error); !ok || walletErr.Code != "INSUFFICIENT_BALANCE" {
+		t.Errorf("decodeResponse() error = %v, want INSUFFICIENT_BALANCE", err)
+	}
+}
+
+func TestLightningAddressURL(t *testing.T) {
+	got, err := lightningAddressURL("alice@getalby.com")
+	if err != nil {
+		t.Fatalf("lightningAddressURL() error = %v", err)
+	}
+	if want := "https://getalby.com/.well-known/lnurlp/alice"; got != want {
+		t.Errorf("lightningAddressURL() = %s, want %s", got, want)
+	}
+
+	for _, address := range []string{"", "alice", "@getalby.com", "alice@"} {
+		if _, err := lightningAddressURL(address); err == nil {
+			t.Errorf("lightningAddressURL(%q) expected error", address)
+		}
+	}
+}
+
+// testInvoice encodes an unsigned BOLT 11 invoice with the given
+// human-readable part and description hash
+func testInvoice(t *testing.T, hrp string, descriptionHash []byte) string {
+	t.Helper()
+	data := make([]byte, timestampWords)
+	// A payment hash field, which the decoder skips
+	data = append(data, 1, 1, 20)
+	data = append(data, make([]byte, 52)...)
+	if descriptionHash != nil {
+		hash, _ := bech32.ConvertBits(descriptionHash, 8, 5, true)
+		data = append(data, tagDescHash, byte(len(hash)>>5), byte(len(hash)&31))
+		data = append(data, hash...)
+	}
+	data = append(data, make([]byte, signatureWords)...)
+
+	invoice, err := bech32.Encode(hrp, data)
+	if err != nil {
+		t.Fatalf("Failed to encode invoice: %v", err)
+	}
+	return invoice
+}
+
+func TestCheckZapInvoice(t *testing.T) {
+	zapRequest := &nostr.Event{Kind: 9734, CreatedAt: 1000, Tags: nostr.Tags{{"amount", "21000"}}}
+	zapRequest.Sign(nostr.GeneratePrivateKey())
+	hash := sha256.Sum256([]byte(zapRequest.String()))
+
+	for hrp, want := range map[string]int64{"lnbc210n": 21000, "lnbc2500u": 250_000_000, "lnbc1m": 100_000_000, "lnbc10p": 1, "lnbc": 0} {
+		decoded, err := DecodeInvoice(testInvoice(t, hrp, hash[:]))
+		if err != nil || decoded.MSats != want {
+			t.Errorf("DecodeInvoice(%s...) = %+v, %v; want %d msats", hrp, decoded, err, want)
+		}
+	}
+
+	if err := CheckZapInvoice(testInvoice(t, "lnbc210n", hash[:]), 21000, zapRequest); err != nil {
+		t.Errorf("Expected a matching invoice to pass: %v", err)
+	}
+	other := sha256.Sum256([]byte("something else"))
+	for name, invoice := range map[string]string{
+		"other amount":           testInvoice(t, "lnbc2100n", hash[:]),
+		"no amount":              testInvoice(t, "lnbc", hash[:]),
+		"other description hash": testInvoice(t, "lnbc210n", other[:]),
+		"no description hash":    testInvoice(t, "lnbc210n", nil),
+		"not an invoice":         "lnbc210n1garbage",
+	} {
+		if err := CheckZapInvoice(invoice, 21000, zapRequest); err == nil {
+			t.Errorf("Expected an invoice with %s to be refused", name)
+		}
+	}
+}
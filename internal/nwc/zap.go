@@ -0,0 +1,121 @@
+package nwc
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/nbd-wtf/go-nostr"
+)
+
+// payParams is the LNURL-pay endpoint description (LUD-06, LUD-16, NIP-57)
+type payParams struct {
+	Callback    string `json:"callback"`
+	MinSendable int64  `json:"minSendable"`
+	MaxSendable int64  `json:"maxSendable"`
+	AllowsNostr bool   `json:"allowsNostr"`
+	NostrPubkey string `json:"nostrPubkey"`
+	Status      string `json:"status"`
+	Reason      string `json:"reason"`
+}
+
+// lightningAddressURL returns the LNURL-pay endpoint for a name@domain lightning address
+func lightningAddressURL(address string) (string, error) {
+	name, domain, ok := strings.Cut(strings.TrimSpace(address), "@")
+	if !ok || name == "" || domain == "" {
+		return "", fmt.Errorf("invalid lightning address: %s", address)
+	}
+	return fmt.Sprintf("https://%s/.well-known/lnurlp/%s", domain, url.PathEscape(name)), nil
+}
+
+// FetchZapInvoice asks the recipient's lightning address for an invoice that
+// carries the signed kind 9734 zap request, so their server publishes a zap receipt
+func FetchZapInvoice(ctx context.Context, address string, zapRequest *nostr.Event) (string, error) {
+	endpoint, err := lightningAddressURL(address)
+	if err != nil {
+		return "", err
+	}
+
+	var params payParams
+	if err := getJSON(ctx, endpoint, &params); err != nil {
+		return "", fmt.Errorf("failed to reach %s: %w", address, err)
+	}
+	if params.Status == "ERROR" {
+		return "", fmt.Errorf("%s: %s", address, params.Reason)
+	}
+	if !params.AllowsNostr || params.NostrPubkey == "" {
+		return "", fmt.Errorf("%s does not accept zaps", address)
+	}
+
+	amount, err := zapAmount(zapRequest)
+	if err != nil {
+		return "", err
+	}
+	if amount < params.MinSendable || (params.MaxSendable > 0 && amount > params.MaxSendable) {
+		return "", fmt.Errorf("%s accepts %d-%d sats", address, params.MinSendable/1000, params.MaxSendable/1000)
+	}
+
+	callback, err := url.Parse(params.Callback)
+	if err != nil || callback.Scheme != "https" {
+		return "", fmt.Errorf("%s returned an invalid callback", address)
+	}
+
+	query := callback.Query()
+	query.Set("amount", strconv.FormatInt(amount, 10))
+	query.Set("nostr", zapRequest.String())
+	callback.RawQuery = query.Encode()
+
+	var invoice struct {
+		PR     string `json:"pr"`
+		Status string `json:"status"`
+		Reason string `json:"reason"`
+	}
+	if err := getJSON(ctx, callback.String(), &invoice); err != nil {
+		return "", fmt.Errorf("failed to get invoice from %s: %w", address, err)
+	}
+	if invoice.Status == "ERROR" {
+		return "", fmt.Errorf("%s: %s", address, invoice.Reason)
+	}
+	if invoice.PR == "" {
+		return "", fmt.Errorf("%s returned no invoice", address)
+	}
+
+	return invoice.PR, nil
+}
+
+// zapAmount returns the msats in a zap request's amount tag
+func zapAmount(zapRequest *nostr.Event) (int64, error) {
+	tag := zapRequest.Tags.Find("amount")
+	if len(tag) < 2 {
+		return 0, fmt.Errorf("zap request has no amount")
+	}
+	amount, err := strconv.ParseInt(tag[1], 10, 64)
+	if err != nil || amount <= 0 {
+		return 0, fmt.Errorf("zap request has an invalid amount")
+	}
+	return amount, nil
+}
+
+// getJSON fetches url and decodes its JSON body into v
+func getJSON(ctx context.Context, url string, v interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("HTTP %d", resp.StatusCode)
+	}
+
+	return json.NewDecoder(resp.Body).Decode(v)
+}
@@ -0,0 +1,147 @@
+package ops
+
+import (
+	"fmt"
+	"runtime"
+	"runtime/debug"
+	"sort"
+	"strings"
+)
+
+// BuildInfo describes how the running binary was built, for bug reports. The
+// values set with -ldflags -X win; the rest comes from the metadata the Go
+// toolchain embeds, so a plain "go build" or "go install" still reports its
+// commit.
+type BuildInfo struct {
+	Version       string            `json:"version"`               // main.version, else the module version
+	Module        string            `json:"module"`                // Main module path
+	ModuleVersion string            `json:"module_version"`        // "(devel)" for builds from a checkout
+	Commit        string            `json:"commit"`                // main.commit, else the VCS revision
+	CommitTime    string            `json:"commit_time,omitempty"` // RFC 3339 time of the commit
+	Modified      bool              `json:"modified"`              // Built from a tree with uncommitted changes
+	Date          string            `json:"date,omitempty"`        // main.date
+	BuiltBy       string            `json:"built_by,omitempty"`    // main.builtBy
+	GoVersion     string            `json:"go_version"`
+	Platform      string            `json:"platform"`           // GOOS/GOARCH
+	Flags         map[string]string `json:"flags"`              // Build settings: -trimpath, -tags, CGO_ENABLED, ...
+	Features      []string          `json:"features,omitempty"` // Enabled features, when a configuration is loaded
+}
+
+// NewBuildInfo reads the running binary's build metadata. version, commit,
+// date and builtBy are the values set with -ldflags; "dev", "unknown", "manual"
+// and "" count as unset.
+func NewBuildInfo(version, commit, date, builtBy string) *BuildInfo {
+	info, _ := debug.ReadBuildInfo()
+	return newBuildInfo(info, version, commit, date, builtBy)
+}
+
+// newBuildInfo merges the -ldflags values with the toolchain's metadata,
+// which is nil when the binary was built without module support
+func newBuildInfo(info *debug.BuildInfo, version, commit, date, builtBy string) *BuildInfo {
+	b := &BuildInfo{
+		Version:   version,
+		Commit:    commit,
+		Date:      date,
+		BuiltBy:   builtBy,
+		GoVersion: runtime.Version(),
+		Platform:  runtime.GOOS + "/" + runtime.GOARCH,
+		Flags:     make(map[string]string),
+	}
+	if b.Date == "unknown" {
+		b.Date = ""
+	}
+	if b.BuiltBy == "manual" {
+		b.BuiltBy = ""
+	}
+	if info == nil {
+		return b
+	}
+
+	b.Module = info.Main.Path
+	b.ModuleVersion = info.Main.Version
+	if info.GoVersion != "" {
+		b.GoVersion = info.GoVersion
+	}
+
+	var goos, goarch string
+	for _, setting := range info.Settings {
+		switch setting.Key {
+		case "vcs.revision":
+			if b.Commit == "" || b.Commit == "unknown" {
+				b.Commit = setting.Value
+			}
+		case "vcs.time":
+			b.CommitTime = setting.Value
+		case "vcs.modified":
+			b.Modified = setting.Value == "true"
+		case "GOOS":
+			goos = setting.Value
+		case "GOARCH":
+			goarch = setting.Value
+		default:
+			if !strings.HasPrefix(setting.Key, "vcs") {
+				b.Flags[setting.Key] = setting.Value
+			}
+		}
+	}
+	if goos != "" && goarch != "" {
+		b.Platform = goos + "/" + goarch
+	}
+
+	if (b.Version == "" || b.Version == "dev") && b.ModuleVersion != "" && b.ModuleVersion != "(devel)" {
+		b.Version = b.ModuleVersion
+	}
+	return b
+}
+
+// ShortCommit returns the first 12 characters of the commit, marked with
+// "+dirty" if the tree had uncommitted changes
+func (b *BuildInfo) ShortCommit() string {
+	commit := b.Commit
+	if len(commit) > 12 {
+		commit = commit[:12]
+	}
+	if b.Modified {
+		commit += "+dirty"
+	}
+	return commit
+}
+
+// FlagSummary lists the build settings as "key=value" in key order, e.g.
+// "-trimpath=true CGO_ENABLED=0"
+func (b *BuildInfo) FlagSummary() string {
+	keys := make([]string, 0, len(b.Flags))
+	for key := range b.Flags {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	parts := make([]string, len(keys))
+	for i, key := range keys {
+		parts[i] = key + "=" + b.Flags[key]
+	}
+	return strings.Join(parts, " ")
+}
+
+// FormatAsText formats the build metadata for "nophr --version"
+func (b *BuildInfo) FormatAsText() string {
+	var sb strings.Builder
+	line := func(label, value string) {
+		if value != "" {
+			sb.WriteString(fmt.Sprintf("  %-10s %s\n", label+":", value))
+		}
+	}
+
+	sb.WriteString(fmt.Sprintf("nophr %s\n", b.Version))
+	line("commit", b.ShortCommit())
+	line("committed", b.CommitTime)
+	if b.Module != "" {
+		line("module", b.Module+" "+b.ModuleVersion)
+	}
+	line("built", b.Date)
+	line("by", b.BuiltBy)
+	line("go", b.GoVersion+" "+b.Platform)
+	line("flags", b.FlagSummary())
+	line("features", strings.Join(b.Features, ", "))
+	return sb.String()
+}
@@ -0,0 +1,75 @@
+package ops
+
+import (
+	"runtime/debug"
+	"strings"
+	"testing"
+
+	"github.com/sandwich/nophr/internal/config"
+)
+
+func TestNewBuildInfo(t *testing.T) {
+	info := &debug.BuildInfo{
+		GoVersion: "go1.25.1",
+		Main:      debug.Module{Path: "github.com/sandwich/nophr", Version: "v0.9.0"},
+		Settings: []debug.BuildSetting{
+			{Key: "-trimpath", Value: "true"},
+			{Key: "CGO_ENABLED", Value: "0"},
+			{Key: "GOOS", Value: "linux"},
+			{Key: "GOARCH", Value: "arm64"},
+			{Key: "vcs", Value: "git"},
+			{Key: "vcs.revision", Value: "0123456789abcdef0123"},
+			{Key: "vcs.time", Value: "2026-10-01T09:30:00Z"},
+			{Key: "vcs.modified", Value: "true"},
+		},
+	}
+
+	// Without -ldflags the toolchain's metadata fills in
+	b := newBuildInfo(info, "dev", "unknown", "unknown", "manual")
+	if b.Version != "v0.9.0" || b.Commit != "0123456789abcdef0123" || b.Date != "" || b.BuiltBy != "" {
+		t.Errorf("newBuildInfo() = %+v, want the module version and VCS revision", b)
+	}
+	if b.Platform != "linux/arm64" || b.GoVersion != "go1.25.1" {
+		t.Errorf("Platform, GoVersion = %q, %q", b.Platform, b.GoVersion)
+	}
+	if got := b.ShortCommit(); got != "0123456789ab+dirty" {
+		t.Errorf("ShortCommit() = %q", got)
+	}
+	if got := b.FlagSummary(); got != "-trimpath=true CGO_ENABLED=0" {
+		t.Errorf("FlagSummary() = %q, want only the build flags", got)
+	}
+
+	// Values set with -ldflags win
+	b = newBuildInfo(info, "v1.0.0", "fedcba", "2026-10-02T00:00:00Z", "goreleaser")
+	if b.Version != "v1.0.0" || b.Commit != "fedcba" || b.BuiltBy != "goreleaser" {
+		t.Errorf("newBuildInfo() = %+v, want the -ldflags values", b)
+	}
+
+	// A binary without module support still reports something
+	b = newBuildInfo(nil, "dev", "unknown", "unknown", "manual")
+	if b.Version != "dev" || b.Commit != "unknown" || b.Platform == "" {
+		t.Errorf("newBuildInfo(nil) = %+v", b)
+	}
+	if text := b.FormatAsText(); !strings.HasPrefix(text, "nophr dev\n") || strings.Contains(text, "built:") {
+		t.Errorf("FormatAsText() = %q", text)
+	}
+}
+
+func TestEnabledFeatures(t *testing.T) {
+	cfg := config.Default()
+	cfg.Idle.Enabled = true
+
+	features := EnabledFeatures(cfg)
+	found := false
+	for i, name := range features {
+		if i > 0 && features[i-1] > name {
+			t.Errorf("EnabledFeatures() = %v, not sorted", features)
+		}
+		if name == "idle" {
+			found = true
+		}
+	}
+	if !found {
+		t.Errorf("EnabledFeatures() = %v, want idle listed", features)
+	}
+}
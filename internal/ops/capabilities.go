@@ -0,0 +1,189 @@
+package ops
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/sandwich/nophr/internal/config"
+)
+
+// CapabilitiesFormat is the version of the Capabilities layout. Fields and
+// keys are only ever added; a rename or removal bumps it.
+const CapabilitiesFormat = 1
+
+// Capabilities is a machine-readable summary of a running instance for
+// monitoring scripts. Unlike the diagnostics pages its keys are stable.
+type Capabilities struct {
+	Format        int                 `json:"format"`
+	Version       string              `json:"version"`
+	Commit        string              `json:"commit"`
+	UptimeSeconds int64               `json:"uptime_seconds"`
+	Protocols     map[string]int      `json:"protocols"` // Enabled protocol → port
+	Storage       StorageCapabilities `json:"storage"`
+	Sync          SyncCapabilities    `json:"sync"`
+	Features      map[string]bool     `json:"features"`
+}
+
+// StorageCapabilities reports the storage backend
+type StorageCapabilities struct {
+	Driver string `json:"driver"`
+	Events int64  `json:"events"`
+}
+
+// SyncCapabilities reports sync progress
+type SyncCapabilities struct {
+	Enabled         bool  `json:"enabled"`
+	Relays          int   `json:"relays"`
+	ConnectedRelays int   `json:"connected_relays"`
+	LastSync        int64 `json:"last_sync"`     // Unix seconds, 0 before the first sync
+	QueueDepth      int   `json:"queue_depth"`   // Events waiting for the sync workers
+	QueueDropped    int64 `json:"queue_dropped"` // Events dropped since startup because a relay's queue was full
+}
+
+// protocolNames fixes the order protocols are listed in
+var protocolNames = []string{"gopher", "gemini", "finger", "nntp", "telnet", "qotd", "relay"}
+
+// SetConfig sets the configuration capabilities are read from
+func (d *DiagnosticsCollector) SetConfig(cfg *config.Config) {
+	d.config = cfg
+}
+
+// CollectCapabilities collects the instance's capabilities and status
+func (d *DiagnosticsCollector) CollectCapabilities(ctx context.Context) (*Capabilities, error) {
+	caps := d.capabilities()
+
+	storageStats, err := d.CollectStorageStats(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to collect storage stats: %w", err)
+	}
+	caps.Storage = StorageCapabilities{Driver: storageStats.Driver, Events: storageStats.TotalEvents}
+
+	syncStats, err := d.CollectSyncStats(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to collect sync stats: %w", err)
+	}
+	caps.Sync = SyncCapabilities{
+		Enabled:         syncStats.Enabled,
+		Relays:          syncStats.RelayCount,
+		ConnectedRelays: syncStats.ConnectedRelays,
+		QueueDepth:      syncStats.Ingest.Depth,
+		QueueDropped:    syncStats.Ingest.Dropped,
+	}
+	if syncStats.LastSyncTime != nil {
+		caps.Sync.LastSync = syncStats.LastSyncTime.Unix()
+	}
+
+	return caps, nil
+}
+
+// capabilities fills in what is known without querying storage or sync
+func (d *DiagnosticsCollector) capabilities() *Capabilities {
+	caps := &Capabilities{
+		Format:        CapabilitiesFormat,
+		Version:       d.version,
+		Commit:        d.commit,
+		UptimeSeconds: int64(time.Since(d.startTime).Seconds()),
+		Protocols:     make(map[string]int),
+		Features:      make(map[string]bool),
+	}
+	if d.config == nil {
+		return caps
+	}
+
+	p := d.config.Protocols
+	enable := func(enabled bool, name string, port int) {
+		if enabled {
+			caps.Protocols[name] = port
+		}
+	}
+	enable(p.Gopher.Enabled, "gopher", p.Gopher.Port)
+	enable(p.Gemini.Enabled, "gemini", p.Gemini.Port)
+	enable(p.Finger.Enabled, "finger", p.Finger.Port)
+	enable(p.NNTP.Enabled, "nntp", p.NNTP.Port)
+	enable(p.Telnet.Enabled, "telnet", p.Telnet.Port)
+	enable(p.QOTD.Enabled, "qotd", p.QOTD.Port)
+	enable(p.Relay.Enabled, "relay", p.Relay.Port)
+
+	caps.Features = featureFlags(d.config)
+
+	return caps
+}
+
+// featureFlags reports which optional features cfg turns on
+func featureFlags(cfg *config.Config) map[string]bool {
+	return map[string]bool{
+		"admin":              cfg.Security.Admin.Enabled,
+		"advanced_retention": cfg.Sync.Retention.Advanced != nil && cfg.Sync.Retention.Advanced.Enabled,
+		"bridge":             cfg.Outbox.Bridge.Enabled,
+		"caching":            cfg.Caching.Enabled,
+		"digest":             cfg.Outbox.Digest.Enabled,
+		"gemini_titan":       cfg.Protocols.Gemini.Enabled && cfg.Protocols.Gemini.Titan.Enabled,
+		"gemini_wallet":      cfg.Protocols.Gemini.Enabled && cfg.Protocols.Gemini.Wallet.Enabled,
+		"gopher_plus":        cfg.Protocols.Gopher.Enabled && cfg.Protocols.Gopher.GopherPlus,
+		"idle":               cfg.Idle.Enabled,
+		"media_proxy":        cfg.Rendering.MediaProxy.Enabled,
+		"rate_limit":         cfg.Security.RateLimit.Enabled,
+		"translation":        cfg.Rendering.Translation.Enabled,
+	}
+}
+
+// EnabledFeatures returns the names of the optional features cfg turns on,
+// sorted, from the same set the capabilities report
+func EnabledFeatures(cfg *config.Config) []string {
+	var enabled []string
+	for name, on := range featureFlags(cfg) {
+		if on {
+			enabled = append(enabled, name)
+		}
+	}
+	sort.Strings(enabled)
+	return enabled
+}
+
+// FormatAsKeyValue formats capabilities as "key: value" lines, one per key in
+// a fixed order, for scripts that would rather not parse JSON
+func (c *Capabilities) FormatAsKeyValue() string {
+	var sb strings.Builder
+	line := func(key string, value any) {
+		sb.WriteString(fmt.Sprintf("%s: %v\n", key, value))
+	}
+
+	line("format", c.Format)
+	line("version", c.Version)
+	line("commit", c.Commit)
+	line("uptime_seconds", c.UptimeSeconds)
+
+	var enabled []string
+	for _, name := range protocolNames {
+		if _, ok := c.Protocols[name]; ok {
+			enabled = append(enabled, name)
+		}
+	}
+	line("protocols", strings.Join(enabled, " "))
+	for _, name := range enabled {
+		line("protocol."+name+".port", c.Protocols[name])
+	}
+
+	line("storage.driver", c.Storage.Driver)
+	line("storage.events", c.Storage.Events)
+	line("sync.enabled", c.Sync.Enabled)
+	line("sync.relays", c.Sync.Relays)
+	line("sync.connected_relays", c.Sync.ConnectedRelays)
+	line("sync.last_sync", c.Sync.LastSync)
+	line("sync.queue_depth", c.Sync.QueueDepth)
+	line("sync.queue_dropped", c.Sync.QueueDropped)
+
+	features := make([]string, 0, len(c.Features))
+	for name := range c.Features {
+		features = append(features, name)
+	}
+	sort.Strings(features)
+	for _, name := range features {
+		line("feature."+name, c.Features[name])
+	}
+
+	return sb.String()
+}
@@ -6,6 +6,9 @@ import (
 	"runtime"
 	"time"
 
+	"github.com/sandwich/nophr/internal/cache"
+	"github.com/sandwich/nophr/internal/config"
+	internalnostr "github.com/sandwich/nophr/internal/nostr"
 	"github.com/sandwich/nophr/internal/storage"
 	"github.com/sandwich/nophr/internal/sync"
 )
@@ -17,6 +20,10 @@ type SystemStats struct {
 	Uptime    time.Duration
 	StartTime time.Time
 
+	// How the binary was built and which optional features are enabled
+	Build    *BuildInfo
+	Features []string
+
 	// Runtime stats
 	GoVersion      string
 	NumGoroutines  int
@@ -42,8 +49,19 @@ type SyncStats struct {
 	RelayCount      int
 	ConnectedRelays int
 	TotalSynced     int64
+	EventsPerMinute float64
 	LastSyncTime    *time.Time
-	Cursors         []CursorInfo
+
+	// Events dropped or cut down by sync.limits since startup, by kind
+	OversizedRejected  map[int]int64
+	OversizedTruncated map[int]int64
+	Cursors            []CursorInfo
+
+	// Events waiting for the sync workers, and dropped or deduplicated since startup
+	Ingest sync.IngestStats
+
+	// Relay subscriptions open and waiting under relays.policy.max_concurrent_subs
+	Subscriptions internalnostr.SubscriptionStats
 }
 
 // CursorInfo contains cursor information for a relay/kind pair
@@ -59,8 +77,25 @@ type RelayHealth struct {
 	URL         string
 	Connected   bool
 	LastConnect *time.Time
+	LastEvent   *time.Time
 	LastError   *string
 	EventsSynced int64
+
+	// Latency percentiles from the relay's histograms, zero without samples
+	ConnectP50    time.Duration
+	ConnectP95    time.Duration
+	FirstEventP50 time.Duration
+	FirstEventP95 time.Duration
+	Slow          bool // Consistently over relays.policy.slow_threshold_ms
+	Seed          bool // Listed in relays.seeds
+
+	// Connection record across restarts, zero if the relay was never synced from
+	Successes           int64
+	Failures            int64
+	ConsecutiveFailures int
+	EventYield          int64         // Events received over all syncs
+	AvgConnect          time.Duration // Smoothed connect latency
+	BackingOffUntil     *time.Time    // Set while sync skips the relay after failed connects
 }
 
 // AggregateStats contains aggregate computation statistics
@@ -70,6 +105,18 @@ type AggregateStats struct {
 	LastReconcile   *time.Time
 }
 
+// CacheStats contains response cache statistics
+type CacheStats struct {
+	Enabled   bool
+	Engine    string
+	Keys      int64
+	SizeMB    float64
+	Hits      int64
+	Misses    int64
+	HitRate   float64
+	Evictions int64
+}
+
 // Phase 20: RetentionDiagStats contains retention-related diagnostics
 type RetentionDiagStats struct {
 	Enabled             bool
@@ -92,6 +139,10 @@ type DiagnosticsCollector struct {
 	storage       *storage.Storage
 	syncEngine    *sync.Engine
 	retentionMgr  *RetentionManager // Phase 20
+	cache         cache.Cache
+	cacheEngine   string
+	config        *config.Config // Enabled protocols and features, for capabilities
+	build         *BuildInfo
 }
 
 // NewDiagnosticsCollector creates a new diagnostics collector
@@ -105,21 +156,44 @@ func NewDiagnosticsCollector(version, commit string, st *storage.Storage, syncEn
 	}
 }
 
+// Version returns the running nophr version
+func (d *DiagnosticsCollector) Version() string {
+	return d.version
+}
+
+// SetBuildInfo sets the build metadata shown on the diagnostics page
+func (d *DiagnosticsCollector) SetBuildInfo(b *BuildInfo) {
+	d.build = b
+}
+
 // SetRetentionManager sets the retention manager for diagnostics (Phase 20)
 func (d *DiagnosticsCollector) SetRetentionManager(rm *RetentionManager) {
 	d.retentionMgr = rm
 }
 
+// SetCache sets the response cache for diagnostics
+func (d *DiagnosticsCollector) SetCache(c cache.Cache, engine string) {
+	d.cache = c
+	d.cacheEngine = engine
+}
+
 // CollectSystemStats collects system-level statistics
 func (d *DiagnosticsCollector) CollectSystemStats() *SystemStats {
 	var m runtime.MemStats
 	runtime.ReadMemStats(&m)
 
+	var features []string
+	if d.config != nil {
+		features = EnabledFeatures(d.config)
+	}
+
 	return &SystemStats{
 		Version:   d.version,
 		Commit:    d.commit,
 		Uptime:    time.Since(d.startTime),
 		StartTime: d.startTime,
+		Build:     d.build,
+		Features:  features,
 
 		GoVersion:      runtime.Version(),
 		NumGoroutines:  runtime.NumGoroutine(),
@@ -193,6 +267,11 @@ func (d *DiagnosticsCollector) CollectSyncStats(ctx context.Context) (*SyncStats
 		stats.TotalSynced = total
 	}
 
+	stats.EventsPerMinute = d.syncEngine.EventsPerMinute()
+	stats.OversizedRejected, stats.OversizedTruncated = d.syncEngine.OversizedEvents()
+	stats.Ingest = d.syncEngine.IngestStats()
+	stats.Subscriptions = d.syncEngine.SubscriptionStats()
+
 	// Get last sync time
 	lastSync, err := d.syncEngine.LastSyncTime(ctx)
 	if err == nil && lastSync != nil {
@@ -235,15 +314,33 @@ func (d *DiagnosticsCollector) CollectRelayHealth(ctx context.Context) ([]RelayH
 			h.LastConnect = lastConnect
 		}
 
+		if lastEvent := relay.LastEventTime(); lastEvent != nil {
+			h.LastEvent = lastEvent
+		}
+
 		if lastErr := relay.LastError(); lastErr != nil {
 			errStr := lastErr.Error()
 			h.LastError = &errStr
 		}
 
-		synced, err := d.storage.CountEventsByRelay(ctx, relay.URL())
-		if err == nil {
-			h.EventsSynced = synced
+		h.EventsSynced = relay.EventsReceived()
+
+		latency := relay.Latency()
+		h.ConnectP50, h.ConnectP95 = latency.Connect.Quantile(0.5), latency.Connect.Quantile(0.95)
+		h.FirstEventP50, h.FirstEventP95 = latency.FirstEvent.Quantile(0.5), latency.FirstEvent.Quantile(0.95)
+		h.Slow = latency.Slow
+		h.Seed = relay.IsSeed()
+
+		if record := relay.Health(); record != nil {
+			h.Successes, h.Failures = record.Successes, record.Failures
+			h.ConsecutiveFailures = record.ConsecutiveFailures
+			h.EventYield = record.Events
+			h.AvgConnect = record.Latency
+			if h.LastError == nil && record.LastError != "" && record.ConsecutiveFailures > 0 {
+				h.LastError = &record.LastError
+			}
 		}
+		h.BackingOffUntil = relay.BackingOffUntil()
 
 		health = append(health, h)
 	}
@@ -276,6 +373,29 @@ func (d *DiagnosticsCollector) CollectAggregateStats(ctx context.Context) (*Aggr
 	return stats, nil
 }
 
+// CollectCacheStats collects response cache statistics
+func (d *DiagnosticsCollector) CollectCacheStats(ctx context.Context) (*CacheStats, error) {
+	if d.cache == nil {
+		return &CacheStats{Enabled: false}, nil
+	}
+
+	cs, err := d.cache.Stats(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get cache stats: %w", err)
+	}
+
+	return &CacheStats{
+		Enabled:   true,
+		Engine:    d.cacheEngine,
+		Keys:      cs.Keys,
+		SizeMB:    float64(cs.SizeBytes) / 1024 / 1024,
+		Hits:      cs.Hits,
+		Misses:    cs.Misses,
+		HitRate:   cs.HitRate,
+		Evictions: cs.Evictions,
+	}, nil
+}
+
 // CollectRetentionStats collects retention statistics (Phase 20)
 func (d *DiagnosticsCollector) CollectRetentionStats(ctx context.Context) (*RetentionDiagStats, error) {
 	if d.retentionMgr == nil {
@@ -369,6 +489,12 @@ func (d *DiagnosticsCollector) CollectAll(ctx context.Context) (*Diagnostics, er
 	}
 	diag.Retention = retStats
 
+	// Collect cache stats (non-fatal, the cache may be remote)
+	cacheStats, err := d.CollectCacheStats(ctx)
+	if err == nil {
+		diag.Cache = cacheStats
+	}
+
 	return diag, nil
 }
 
@@ -381,172 +507,5 @@ type Diagnostics struct {
 	Relays      []RelayHealth
 	Aggregates  *AggregateStats
 	Retention   *RetentionDiagStats // Phase 20
-}
-
-// FormatAsText formats diagnostics as plain text
-func (d *Diagnostics) FormatAsText() string {
-	var out string
-
-	// System info
-	out += fmt.Sprintf("=== nophr Diagnostics ===\n")
-	out += fmt.Sprintf("Collected: %s\n\n", d.CollectedAt.Format(time.RFC3339))
-
-	out += fmt.Sprintf("--- System ---\n")
-	out += fmt.Sprintf("Version: %s (%s)\n", d.System.Version, d.System.Commit)
-	out += fmt.Sprintf("Uptime: %s\n", d.System.Uptime.Round(time.Second))
-	out += fmt.Sprintf("Go Version: %s\n", d.System.GoVersion)
-	out += fmt.Sprintf("Goroutines: %d\n", d.System.NumGoroutines)
-	out += fmt.Sprintf("Memory: %.2f MB allocated, %.2f MB system\n", d.System.MemAllocMB, d.System.MemSysMB)
-	out += fmt.Sprintf("GC Runs: %d\n\n", d.System.NumGC)
-
-	// Storage info
-	out += fmt.Sprintf("--- Storage ---\n")
-	out += fmt.Sprintf("Driver: %s\n", d.Storage.Driver)
-	out += fmt.Sprintf("Total Events: %d\n", d.Storage.TotalEvents)
-	out += fmt.Sprintf("Database Size: %.2f MB\n", d.Storage.DatabaseSizeMB)
-	if d.Storage.OldestEventTime != nil {
-		out += fmt.Sprintf("Oldest Event: %s\n", d.Storage.OldestEventTime.Format(time.RFC3339))
-	}
-	if d.Storage.NewestEventTime != nil {
-		out += fmt.Sprintf("Newest Event: %s\n", d.Storage.NewestEventTime.Format(time.RFC3339))
-	}
-	out += fmt.Sprintf("\nEvents by Kind:\n")
-	for kind, count := range d.Storage.EventsByKind {
-		out += fmt.Sprintf("  Kind %d: %d events\n", kind, count)
-	}
-	out += "\n"
-
-	// Sync info
-	out += fmt.Sprintf("--- Sync ---\n")
-	out += fmt.Sprintf("Enabled: %v\n", d.Sync.Enabled)
-	if d.Sync.Enabled {
-		out += fmt.Sprintf("Relays: %d total, %d connected\n", d.Sync.RelayCount, d.Sync.ConnectedRelays)
-		out += fmt.Sprintf("Total Synced: %d events\n", d.Sync.TotalSynced)
-		if d.Sync.LastSyncTime != nil {
-			out += fmt.Sprintf("Last Sync: %s\n", d.Sync.LastSyncTime.Format(time.RFC3339))
-		}
-	}
-	out += "\n"
-
-	// Relay health
-	if len(d.Relays) > 0 {
-		out += fmt.Sprintf("--- Relay Health ---\n")
-		for _, relay := range d.Relays {
-			status := "disconnected"
-			if relay.Connected {
-				status = "connected"
-			}
-			out += fmt.Sprintf("%s: %s\n", relay.URL, status)
-			if relay.LastConnect != nil {
-				out += fmt.Sprintf("  Last Connect: %s\n", relay.LastConnect.Format(time.RFC3339))
-			}
-			if relay.LastError != nil {
-				out += fmt.Sprintf("  Last Error: %s\n", *relay.LastError)
-			}
-			out += fmt.Sprintf("  Events Synced: %d\n", relay.EventsSynced)
-		}
-		out += "\n"
-	}
-
-	// Aggregates
-	out += fmt.Sprintf("--- Aggregates ---\n")
-	out += fmt.Sprintf("Total: %d\n", d.Aggregates.TotalAggregates)
-	if d.Aggregates.LastReconcile != nil {
-		out += fmt.Sprintf("Last Reconcile: %s\n", d.Aggregates.LastReconcile.Format(time.RFC3339))
-	}
-	out += "\n"
-
-	// Phase 20: Retention
-	out += fmt.Sprintf("--- Retention ---\n")
-	if d.Retention != nil {
-		out += fmt.Sprintf("Enabled: %v\n", d.Retention.Enabled)
-		if d.Retention.Enabled {
-			out += fmt.Sprintf("Keep Days: %d\n", d.Retention.KeepDays)
-			if d.Retention.Cutoff != nil {
-				out += fmt.Sprintf("Cutoff Date: %s\n", d.Retention.Cutoff.Format(time.RFC3339))
-			}
-			out += fmt.Sprintf("Total Events: %d\n", d.Retention.TotalEvents)
-			out += fmt.Sprintf("Estimated Prunable: %d\n", d.Retention.EstimatedPrunable)
-			if d.Retention.AdvancedEnabled {
-				out += fmt.Sprintf("Advanced Retention: enabled\n")
-				out += fmt.Sprintf("  Protected Events: %d\n", d.Retention.TotalProtected)
-				out += fmt.Sprintf("  Events with Metadata: %d\n", d.Retention.TotalWithMetadata)
-			}
-		}
-	} else {
-		out += fmt.Sprintf("Not configured\n")
-	}
-
-	return out
-}
-
-// FormatAsGophermap formats diagnostics as a gophermap
-func (d *Diagnostics) FormatAsGophermap(host string, port int) string {
-	var out string
-
-	out += fmt.Sprintf("inophr Diagnostics\t\t%s\t%d\r\n", host, port)
-	out += fmt.Sprintf("i\t\t%s\t%d\r\n", host, port)
-	out += fmt.Sprintf("iCollected: %s\t\t%s\t%d\r\n", d.CollectedAt.Format(time.RFC3339), host, port)
-	out += fmt.Sprintf("i\t\t%s\t%d\r\n", host, port)
-
-	out += fmt.Sprintf("i=== System ===\t\t%s\t%d\r\n", host, port)
-	out += fmt.Sprintf("iVersion: %s\t\t%s\t%d\r\n", d.System.Version, host, port)
-	out += fmt.Sprintf("iUptime: %s\t\t%s\t%d\r\n", d.System.Uptime.Round(time.Second), host, port)
-	out += fmt.Sprintf("iMemory: %.2f MB\t\t%s\t%d\r\n", d.System.MemAllocMB, host, port)
-	out += fmt.Sprintf("i\t\t%s\t%d\r\n", host, port)
-
-	out += fmt.Sprintf("i=== Storage ===\t\t%s\t%d\r\n", host, port)
-	out += fmt.Sprintf("iDriver: %s\t\t%s\t%d\r\n", d.Storage.Driver, host, port)
-	out += fmt.Sprintf("iTotal Events: %d\t\t%s\t%d\r\n", d.Storage.TotalEvents, host, port)
-	out += fmt.Sprintf("iDatabase: %.2f MB\t\t%s\t%d\r\n", d.Storage.DatabaseSizeMB, host, port)
-
-	return out
-}
-
-// FormatAsGemtext formats diagnostics as gemtext
-func (d *Diagnostics) FormatAsGemtext() string {
-	var out string
-
-	out += "# nophr Diagnostics\n\n"
-	out += fmt.Sprintf("Collected: %s\n\n", d.CollectedAt.Format(time.RFC3339))
-
-	out += "## System\n\n"
-	out += fmt.Sprintf("* Version: %s (%s)\n", d.System.Version, d.System.Commit)
-	out += fmt.Sprintf("* Uptime: %s\n", d.System.Uptime.Round(time.Second))
-	out += fmt.Sprintf("* Go Version: %s\n", d.System.GoVersion)
-	out += fmt.Sprintf("* Goroutines: %d\n", d.System.NumGoroutines)
-	out += fmt.Sprintf("* Memory: %.2f MB allocated\n", d.System.MemAllocMB)
-	out += "\n"
-
-	out += "## Storage\n\n"
-	out += fmt.Sprintf("* Driver: %s\n", d.Storage.Driver)
-	out += fmt.Sprintf("* Total Events: %d\n", d.Storage.TotalEvents)
-	out += fmt.Sprintf("* Database Size: %.2f MB\n", d.Storage.DatabaseSizeMB)
-	out += "\n"
-
-	out += "## Sync\n\n"
-	out += fmt.Sprintf("* Enabled: %v\n", d.Sync.Enabled)
-	if d.Sync.Enabled {
-		out += fmt.Sprintf("* Relays: %d total, %d connected\n", d.Sync.RelayCount, d.Sync.ConnectedRelays)
-		out += fmt.Sprintf("* Total Synced: %d events\n", d.Sync.TotalSynced)
-	}
-	out += "\n"
-
-	// Phase 20: Retention
-	out += "## Retention\n\n"
-	if d.Retention != nil {
-		out += fmt.Sprintf("* Enabled: %v\n", d.Retention.Enabled)
-		if d.Retention.Enabled {
-			out += fmt.Sprintf("* Keep Days: %d\n", d.Retention.KeepDays)
-			out += fmt.Sprintf("* Estimated Prunable: %d events\n", d.Retention.EstimatedPrunable)
-			if d.Retention.AdvancedEnabled {
-				out += fmt.Sprintf("* Advanced Retention: enabled\n")
-				out += fmt.Sprintf("* Protected Events: %d\n", d.Retention.TotalProtected)
-			}
-		}
-	} else {
-		out += "* Not configured\n"
-	}
-
-	return out
+	Cache       *CacheStats
 }
@@ -0,0 +1,504 @@
+package ops
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	internalnostr "github.com/sandwich/nophr/internal/nostr"
+	"github.com/sandwich/nophr/internal/sync"
+)
+
+// FormatAsText formats diagnostics as plain text
+func (d *Diagnostics) FormatAsText() string {
+	var out string
+
+	// System info
+	out += fmt.Sprintf("=== nophr Diagnostics ===\n")
+	out += fmt.Sprintf("Collected: %s\n\n", d.CollectedAt.Format(time.RFC3339))
+
+	out += fmt.Sprintf("--- System ---\n")
+	out += fmt.Sprintf("Version: %s (%s)\n", d.System.Version, d.System.Commit)
+	out += fmt.Sprintf("Uptime: %s\n", d.System.Uptime.Round(time.Second))
+	out += fmt.Sprintf("Go Version: %s\n", d.System.GoVersion)
+	for _, line := range d.System.buildLines() {
+		out += line + "\n"
+	}
+	out += fmt.Sprintf("Goroutines: %d\n", d.System.NumGoroutines)
+	out += fmt.Sprintf("Memory: %.2f MB allocated, %.2f MB system\n", d.System.MemAllocMB, d.System.MemSysMB)
+	out += fmt.Sprintf("GC Runs: %d\n\n", d.System.NumGC)
+
+	out += d.formatStorageText()
+	out += d.formatSyncText()
+	out += d.formatRelayHealthText()
+	out += d.formatAggregatesText()
+	out += d.formatCacheText()
+	out += d.formatRetentionText()
+
+	return out
+}
+
+// buildLines describes the build and enabled features for the system section
+func (s *SystemStats) buildLines() []string {
+	var lines []string
+	if b := s.Build; b != nil {
+		lines = append(lines, fmt.Sprintf("Build: %s %s", b.ShortCommit(), b.Platform))
+		if b.CommitTime != "" {
+			lines = append(lines, fmt.Sprintf("Commit Time: %s", b.CommitTime))
+		}
+		if b.Module != "" {
+			lines = append(lines, fmt.Sprintf("Module: %s %s", b.Module, b.ModuleVersion))
+		}
+		if flags := b.FlagSummary(); flags != "" {
+			lines = append(lines, fmt.Sprintf("Build Flags: %s", flags))
+		}
+	}
+	if len(s.Features) > 0 {
+		lines = append(lines, fmt.Sprintf("Features: %s", strings.Join(s.Features, ", ")))
+	}
+	return lines
+}
+
+// formatStorageText formats the storage section
+func (d *Diagnostics) formatStorageText() string {
+	if d.Storage == nil {
+		return ""
+	}
+
+	var out string
+	out += fmt.Sprintf("--- Storage ---\n")
+	out += fmt.Sprintf("Driver: %s\n", d.Storage.Driver)
+	out += fmt.Sprintf("Total Events: %d\n", d.Storage.TotalEvents)
+	out += fmt.Sprintf("Database Size: %.2f MB\n", d.Storage.DatabaseSizeMB)
+	if d.Storage.OldestEventTime != nil {
+		out += fmt.Sprintf("Oldest Event: %s\n", d.Storage.OldestEventTime.Format(time.RFC3339))
+	}
+	if d.Storage.NewestEventTime != nil {
+		out += fmt.Sprintf("Newest Event: %s\n", d.Storage.NewestEventTime.Format(time.RFC3339))
+	}
+	out += fmt.Sprintf("\nEvents by Kind:\n")
+	for _, kind := range sortedKinds(d.Storage.EventsByKind) {
+		out += fmt.Sprintf("  Kind %d: %d events\n", kind, d.Storage.EventsByKind[kind])
+	}
+	out += "\n"
+
+	return out
+}
+
+// formatSyncText formats the sync section
+func (d *Diagnostics) formatSyncText() string {
+	if d.Sync == nil {
+		return ""
+	}
+
+	var out string
+	out += fmt.Sprintf("--- Sync ---\n")
+	out += fmt.Sprintf("Enabled: %v\n", d.Sync.Enabled)
+	if d.Sync.Enabled {
+		out += fmt.Sprintf("Relays: %d total, %d connected\n", d.Sync.RelayCount, d.Sync.ConnectedRelays)
+		out += fmt.Sprintf("Total Synced: %d events\n", d.Sync.TotalSynced)
+		out += fmt.Sprintf("Ingest Rate: %.0f events/min\n", d.Sync.EventsPerMinute)
+		out += fmt.Sprintf("Ingest Queue: %s\n", formatIngestQueue(d.Sync.Ingest))
+		out += fmt.Sprintf("Subscriptions: %s\n", formatSubscriptions(d.Sync.Subscriptions))
+		if oversized := formatOversized(d.Sync); oversized != "" {
+			out += fmt.Sprintf("Oversized Events: %s\n", oversized)
+		}
+		if d.Sync.LastSyncTime != nil {
+			out += fmt.Sprintf("Last Sync: %s\n", d.Sync.LastSyncTime.Format(time.RFC3339))
+		}
+		if len(d.Sync.Cursors) > 0 {
+			out += fmt.Sprintf("\nCursors:\n")
+			for _, c := range d.Sync.Cursors {
+				out += fmt.Sprintf("  %s kind %d: since %s (updated %s)\n",
+					c.Relay, c.Kind,
+					time.Unix(c.Position, 0).Format(time.RFC3339),
+					c.Updated.Format(time.RFC3339))
+			}
+		}
+	}
+	out += "\n"
+
+	return out
+}
+
+// formatIngestQueue summarizes the sync ingest queue, e.g.
+// "120 waiting (wss://a 100, wss://b 20), 3 dropped, 40 duplicates"
+func formatIngestQueue(stats sync.IngestStats) string {
+	out := fmt.Sprintf("%d waiting", stats.Depth)
+	if busiest := stats.BusiestRelays(3); len(busiest) > 0 {
+		parts := make([]string, len(busiest))
+		for i, relay := range busiest {
+			parts[i] = fmt.Sprintf("%s %d", relay, stats.Relays[relay])
+		}
+		out += " (" + strings.Join(parts, ", ") + ")"
+	}
+	return out + fmt.Sprintf(", %d dropped, %d duplicates", stats.Dropped, stats.Duplicates)
+}
+
+// formatSubscriptions summarizes the relay subscription slots, e.g.
+// "8/8 open, 12 waiting", or "3 open" without a limit
+func formatSubscriptions(stats internalnostr.SubscriptionStats) string {
+	if stats.Max <= 0 {
+		return fmt.Sprintf("%d open", stats.Open)
+	}
+	return fmt.Sprintf("%d/%d open, %d waiting", stats.Open, stats.Max, stats.Waiting)
+}
+
+// formatOversized summarizes events rejected or truncated by sync.limits, e.g.
+// "12 rejected (kind 1: 10, kind 7: 2), 0 truncated", or "" if there were none
+func formatOversized(stats *SyncStats) string {
+	byKind := func(counts map[int]int64) (int64, string) {
+		var total int64
+		var parts []string
+		for _, kind := range sortedKinds(counts) {
+			total += counts[kind]
+			parts = append(parts, fmt.Sprintf("kind %d: %d", kind, counts[kind]))
+		}
+		if total == 0 {
+			return 0, ""
+		}
+		return total, " (" + strings.Join(parts, ", ") + ")"
+	}
+
+	rejected, rejectedKinds := byKind(stats.OversizedRejected)
+	truncated, truncatedKinds := byKind(stats.OversizedTruncated)
+	if rejected == 0 && truncated == 0 {
+		return ""
+	}
+	return fmt.Sprintf("%d rejected%s, %d truncated%s", rejected, rejectedKinds, truncated, truncatedKinds)
+}
+
+// formatRelayHealthText formats the relay health section
+func (d *Diagnostics) formatRelayHealthText() string {
+	if len(d.Relays) == 0 {
+		return ""
+	}
+
+	var out string
+	out += fmt.Sprintf("--- Relay Health ---\n")
+	for _, relay := range d.Relays {
+		status := "disconnected"
+		if relay.Connected {
+			status = "connected"
+		}
+		out += fmt.Sprintf("%s: %s\n", relay.URL, status)
+		if relay.LastConnect != nil {
+			out += fmt.Sprintf("  Last Connect: %s\n", relay.LastConnect.Format(time.RFC3339))
+		}
+		if relay.LastEvent != nil {
+			out += fmt.Sprintf("  Last Event: %s\n", relay.LastEvent.Format(time.RFC3339))
+		}
+		if relay.LastError != nil {
+			out += fmt.Sprintf("  Last Error: %s\n", *relay.LastError)
+		}
+		out += fmt.Sprintf("  Events Synced: %d\n", relay.EventsSynced)
+		if latency := formatLatency(relay); latency != "" {
+			out += fmt.Sprintf("  Latency: %s\n", latency)
+		}
+		if record := formatConnectRecord(relay); record != "" {
+			out += fmt.Sprintf("  Connects: %s\n", record)
+		}
+		if relay.BackingOffUntil != nil {
+			out += fmt.Sprintf("  Backing Off Until: %s\n", relay.BackingOffUntil.Format(time.RFC3339))
+		}
+	}
+	out += "\n"
+
+	if advice := d.slowRelayAdvice(); len(advice) > 0 {
+		out += "Slow relays:\n"
+		for _, line := range advice {
+			out += "  " + line + "\n"
+		}
+		out += "\n"
+	}
+
+	return out
+}
+
+// formatLatency summarises a relay's latency percentiles, or "" without samples
+func formatLatency(relay RelayHealth) string {
+	var parts []string
+	if relay.ConnectP50 > 0 {
+		parts = append(parts, fmt.Sprintf("connect p50 %s, p95 %s", relay.ConnectP50, relay.ConnectP95))
+	}
+	if relay.FirstEventP50 > 0 {
+		parts = append(parts, fmt.Sprintf("first event p50 %s, p95 %s", relay.FirstEventP50, relay.FirstEventP95))
+	}
+	return strings.Join(parts, "; ")
+}
+
+// formatConnectRecord summarises a relay's connects across restarts, or "" if
+// it was never synced from
+func formatConnectRecord(relay RelayHealth) string {
+	if relay.Successes == 0 && relay.Failures == 0 {
+		return ""
+	}
+	record := fmt.Sprintf("%d ok, %d failed", relay.Successes, relay.Failures)
+	if relay.ConsecutiveFailures > 1 {
+		record += fmt.Sprintf(" (last %d in a row)", relay.ConsecutiveFailures)
+	}
+	record += fmt.Sprintf(", %d events yielded", relay.EventYield)
+	if relay.AvgConnect > 0 {
+		record += fmt.Sprintf(", avg connect %s", relay.AvgConnect)
+	}
+	return record
+}
+
+// slowRelayAdvice recommends removing slow seed relays and notes slow discovered ones
+func (d *Diagnostics) slowRelayAdvice() []string {
+	var advice []string
+	for _, relay := range d.Relays {
+		switch {
+		case !relay.Slow:
+		case relay.Seed:
+			advice = append(advice, fmt.Sprintf("%s is consistently slow; consider removing it from relays.seeds", relay.URL))
+		default:
+			advice = append(advice, fmt.Sprintf("%s is consistently slow (discovered from relay lists, not a seed)", relay.URL))
+		}
+	}
+	return advice
+}
+
+// formatAggregatesText formats the aggregates section
+func (d *Diagnostics) formatAggregatesText() string {
+	if d.Aggregates == nil {
+		return ""
+	}
+
+	var out string
+	out += fmt.Sprintf("--- Aggregates ---\n")
+	out += fmt.Sprintf("Total: %d\n", d.Aggregates.TotalAggregates)
+	for _, kind := range sortedKinds(d.Aggregates.ByKind) {
+		out += fmt.Sprintf("  Kind %d: %d\n", kind, d.Aggregates.ByKind[kind])
+	}
+	if d.Aggregates.LastReconcile != nil {
+		out += fmt.Sprintf("Last Reconcile: %s\n", d.Aggregates.LastReconcile.Format(time.RFC3339))
+	}
+	out += "\n"
+
+	return out
+}
+
+// formatCacheText formats the cache section
+func (d *Diagnostics) formatCacheText() string {
+	if d.Cache == nil {
+		return ""
+	}
+
+	var out string
+	out += fmt.Sprintf("--- Cache ---\n")
+	out += fmt.Sprintf("Enabled: %v\n", d.Cache.Enabled)
+	if d.Cache.Enabled {
+		out += fmt.Sprintf("Engine: %s\n", d.Cache.Engine)
+		out += fmt.Sprintf("Keys: %d (%.2f MB)\n", d.Cache.Keys, d.Cache.SizeMB)
+		out += fmt.Sprintf("Hits: %d, Misses: %d (%.1f%% hit rate)\n", d.Cache.Hits, d.Cache.Misses, d.Cache.HitRate*100)
+		out += fmt.Sprintf("Evictions: %d\n", d.Cache.Evictions)
+	}
+	out += "\n"
+
+	return out
+}
+
+// formatRetentionText formats the retention section (Phase 20)
+func (d *Diagnostics) formatRetentionText() string {
+	var out string
+
+	out += fmt.Sprintf("--- Retention ---\n")
+	if d.Retention == nil {
+		return out + fmt.Sprintf("Not configured\n")
+	}
+
+	out += fmt.Sprintf("Enabled: %v\n", d.Retention.Enabled)
+	if d.Retention.Enabled {
+		out += fmt.Sprintf("Keep Days: %d\n", d.Retention.KeepDays)
+		if d.Retention.Cutoff != nil {
+			out += fmt.Sprintf("Cutoff Date: %s\n", d.Retention.Cutoff.Format(time.RFC3339))
+		}
+		out += fmt.Sprintf("Total Events: %d\n", d.Retention.TotalEvents)
+		out += fmt.Sprintf("Estimated Prunable: %d\n", d.Retention.EstimatedPrunable)
+		if d.Retention.AdvancedEnabled {
+			out += fmt.Sprintf("Advanced Retention: enabled\n")
+			out += fmt.Sprintf("  Protected Events: %d\n", d.Retention.TotalProtected)
+			out += fmt.Sprintf("  Events with Metadata: %d\n", d.Retention.TotalWithMetadata)
+		}
+	}
+
+	return out
+}
+
+// FormatAsGophermap formats diagnostics as gophermap info lines
+// The result has no end-of-transmission marker so callers can append navigation
+func (d *Diagnostics) FormatAsGophermap(host string, port int) string {
+	var out string
+
+	for _, line := range strings.Split(strings.TrimRight(d.FormatAsText(), "\n"), "\n") {
+		// Tabs would break the gophermap columns
+		line = strings.ReplaceAll(line, "\t", " ")
+		out += fmt.Sprintf("i%s\t\t%s\t%d\r\n", line, host, port)
+	}
+
+	return out
+}
+
+// FormatAsGemtext formats diagnostics as gemtext
+func (d *Diagnostics) FormatAsGemtext() string {
+	var out string
+
+	out += "# nophr Diagnostics\n\n"
+	out += fmt.Sprintf("Collected: %s\n\n", d.CollectedAt.Format(time.RFC3339))
+
+	out += "## System\n\n"
+	out += fmt.Sprintf("* Version: %s (%s)\n", d.System.Version, d.System.Commit)
+	out += fmt.Sprintf("* Uptime: %s\n", d.System.Uptime.Round(time.Second))
+	out += fmt.Sprintf("* Go Version: %s\n", d.System.GoVersion)
+	for _, line := range d.System.buildLines() {
+		out += "* " + line + "\n"
+	}
+	out += fmt.Sprintf("* Goroutines: %d\n", d.System.NumGoroutines)
+	out += fmt.Sprintf("* Memory: %.2f MB allocated\n", d.System.MemAllocMB)
+	out += "\n"
+
+	out += d.formatStorageGemtext()
+	out += d.formatSyncGemtext()
+	out += d.formatCacheGemtext()
+	out += d.formatRetentionGemtext()
+
+	return out
+}
+
+// formatStorageGemtext formats the storage and aggregates sections as gemtext
+func (d *Diagnostics) formatStorageGemtext() string {
+	if d.Storage == nil {
+		return ""
+	}
+
+	var out string
+	out += "## Storage\n\n"
+	out += fmt.Sprintf("* Driver: %s\n", d.Storage.Driver)
+	out += fmt.Sprintf("* Total Events: %d\n", d.Storage.TotalEvents)
+	out += fmt.Sprintf("* Database Size: %.2f MB\n", d.Storage.DatabaseSizeMB)
+	if d.Storage.OldestEventTime != nil && d.Storage.NewestEventTime != nil {
+		out += fmt.Sprintf("* Time Range: %s to %s\n",
+			d.Storage.OldestEventTime.Format("2006-01-02"),
+			d.Storage.NewestEventTime.Format("2006-01-02"))
+	}
+	for _, kind := range sortedKinds(d.Storage.EventsByKind) {
+		out += fmt.Sprintf("* Kind %d: %d events\n", kind, d.Storage.EventsByKind[kind])
+	}
+	if d.Aggregates != nil {
+		out += fmt.Sprintf("* Aggregates: %d\n", d.Aggregates.TotalAggregates)
+	}
+	out += "\n"
+
+	return out
+}
+
+// formatSyncGemtext formats the sync and relay sections as gemtext
+func (d *Diagnostics) formatSyncGemtext() string {
+	if d.Sync == nil {
+		return ""
+	}
+
+	var out string
+	out += "## Sync\n\n"
+	out += fmt.Sprintf("* Enabled: %v\n", d.Sync.Enabled)
+	if d.Sync.Enabled {
+		out += fmt.Sprintf("* Relays: %d total, %d connected\n", d.Sync.RelayCount, d.Sync.ConnectedRelays)
+		out += fmt.Sprintf("* Total Synced: %d events\n", d.Sync.TotalSynced)
+		out += fmt.Sprintf("* Ingest Rate: %.0f events/min\n", d.Sync.EventsPerMinute)
+		out += fmt.Sprintf("* Ingest Queue: %s\n", formatIngestQueue(d.Sync.Ingest))
+		out += fmt.Sprintf("* Subscriptions: %s\n", formatSubscriptions(d.Sync.Subscriptions))
+		if oversized := formatOversized(d.Sync); oversized != "" {
+			out += fmt.Sprintf("* Oversized Events: %s\n", oversized)
+		}
+		if d.Sync.LastSyncTime != nil {
+			out += fmt.Sprintf("* Last Sync: %s\n", d.Sync.LastSyncTime.Format(time.RFC3339))
+		}
+	}
+	out += "\n"
+
+	if len(d.Relays) > 0 {
+		out += "## Relays\n\n"
+		for _, relay := range d.Relays {
+			status := "disconnected"
+			if relay.Connected {
+				status = "connected"
+			}
+			line := fmt.Sprintf("* %s: %s, %d events", relay.URL, status, relay.EventsSynced)
+			if relay.LastEvent != nil {
+				line += fmt.Sprintf(", last event %s", relay.LastEvent.Format(time.RFC3339))
+			}
+			if latency := formatLatency(relay); latency != "" {
+				line += "; " + latency
+			}
+			if record := formatConnectRecord(relay); record != "" {
+				line += "; connects " + record
+			}
+			if relay.BackingOffUntil != nil {
+				line += fmt.Sprintf("; backing off until %s", relay.BackingOffUntil.Format(time.RFC3339))
+			}
+			out += line + "\n"
+		}
+		out += "\n"
+
+		if advice := d.slowRelayAdvice(); len(advice) > 0 {
+			out += "### Slow relays\n\n"
+			for _, line := range advice {
+				out += "* " + line + "\n"
+			}
+			out += "\n"
+		}
+	}
+
+	return out
+}
+
+// formatCacheGemtext formats the cache section as gemtext
+func (d *Diagnostics) formatCacheGemtext() string {
+	if d.Cache == nil {
+		return ""
+	}
+
+	var out string
+	out += "## Cache\n\n"
+	out += fmt.Sprintf("* Enabled: %v\n", d.Cache.Enabled)
+	if d.Cache.Enabled {
+		out += fmt.Sprintf("* Engine: %s\n", d.Cache.Engine)
+		out += fmt.Sprintf("* Keys: %d (%.2f MB)\n", d.Cache.Keys, d.Cache.SizeMB)
+		out += fmt.Sprintf("* Hit Rate: %.1f%%\n", d.Cache.HitRate*100)
+	}
+	out += "\n"
+
+	return out
+}
+
+// formatRetentionGemtext formats the retention section as gemtext (Phase 20)
+func (d *Diagnostics) formatRetentionGemtext() string {
+	out := "## Retention\n\n"
+	if d.Retention == nil {
+		return out + "* Not configured\n"
+	}
+
+	out += fmt.Sprintf("* Enabled: %v\n", d.Retention.Enabled)
+	if d.Retention.Enabled {
+		out += fmt.Sprintf("* Keep Days: %d\n", d.Retention.KeepDays)
+		out += fmt.Sprintf("* Estimated Prunable: %d events\n", d.Retention.EstimatedPrunable)
+		if d.Retention.AdvancedEnabled {
+			out += fmt.Sprintf("* Advanced Retention: enabled\n")
+			out += fmt.Sprintf("* Protected Events: %d\n", d.Retention.TotalProtected)
+		}
+	}
+
+	return out
+}
+
+// sortedKinds returns the kinds of a count map in ascending order
+func sortedKinds(counts map[int]int64) []int {
+	kinds := make([]int, 0, len(counts))
+	for kind := range counts {
+		kinds = append(kinds, kind)
+	}
+	sort.Ints(kinds)
+	return kinds
+}
@@ -1,9 +1,17 @@
 package ops
 
 import (
+	"context"
+	"path/filepath"
 	"strings"
 	"testing"
 	"time"
+
+	"github.com/nbd-wtf/go-nostr"
+	"github.com/sandwich/nophr/internal/cache"
+	"github.com/sandwich/nophr/internal/config"
+	internalnostr "github.com/sandwich/nophr/internal/nostr"
+	"github.com/sandwich/nophr/internal/storage"
 )
 
 func TestSystemStats(t *testing.T) {
@@ -37,6 +45,7 @@ func TestSystemStats(t *testing.T) {
 }
 
 func TestDiagnosticsFormatAsText(t *testing.T) {
+	backoff := time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC)
 	diag := &Diagnostics{
 		CollectedAt: time.Now(),
 		System: &SystemStats{
@@ -48,6 +57,14 @@ func TestDiagnosticsFormatAsText(t *testing.T) {
 			MemAllocMB:    100.5,
 			MemSysMB:      200.0,
 			NumGC:          5,
+			Build: &BuildInfo{
+				Commit:     "abc123def4567890",
+				Modified:   true,
+				CommitTime: "2026-10-01T09:30:00Z",
+				Platform:   "linux/amd64",
+				Flags:      map[string]string{"-trimpath": "true", "CGO_ENABLED": "0"},
+			},
+			Features: []string{"admin", "search"},
 		},
 		Storage: &StorageStats{
 			Driver:         "sqlite",
@@ -60,12 +77,33 @@ func TestDiagnosticsFormatAsText(t *testing.T) {
 			RelayCount:      3,
 			ConnectedRelays: 2,
 			TotalSynced:     1000,
+			Subscriptions:   internalnostr.SubscriptionStats{Open: 8, Waiting: 12, Max: 8},
 		},
 		Relays: []RelayHealth{
 			{
 				URL:          "wss://relay.test",
 				Connected:    true,
 				EventsSynced: 500,
+				Successes:    12,
+				Failures:     1,
+				EventYield:   4200,
+				AvgConnect:   120 * time.Millisecond,
+			},
+			{
+				URL:                 "wss://dead.test",
+				Failures:            4,
+				ConsecutiveFailures: 4,
+				BackingOffUntil:     &backoff,
+			},
+			{
+				URL:           "wss://slow.test",
+				Connected:     true,
+				ConnectP50:    2500 * time.Millisecond,
+				ConnectP95:    5 * time.Second,
+				FirstEventP50: 5 * time.Second,
+				FirstEventP95: 10 * time.Second,
+				Slow:          true,
+				Seed:          true,
 			},
 		},
 		Aggregates: &AggregateStats{
@@ -89,6 +127,16 @@ func TestDiagnosticsFormatAsText(t *testing.T) {
 		"sqlite",
 		"1000",
 		"wss://relay.test",
+		"Latency: connect p50 2.5s, p95 5s; first event p50 5s, p95 10s",
+		"wss://slow.test is consistently slow; consider removing it from relays.seeds",
+		"Connects: 12 ok, 1 failed, 4200 events yielded, avg connect 120ms",
+		"Connects: 0 ok, 4 failed (last 4 in a row), 0 events yielded",
+		"Backing Off Until: 2026-10-16T12:00:00Z",
+		"Subscriptions: 8/8 open, 12 waiting",
+		"Build: abc123def456+dirty linux/amd64",
+		"Commit Time: 2026-10-01T09:30:00Z",
+		"Build Flags: -trimpath=true CGO_ENABLED=0",
+		"Features: admin, search",
 	}
 
 	for _, expected := range expectedSections {
@@ -177,3 +225,92 @@ func TestDiagnosticsFormatAsGophermap(t *testing.T) {
 		t.Error("expected gophermap to contain version")
 	}
 }
+
+func TestCapabilitiesFormatAsKeyValue(t *testing.T) {
+	cfg := &config.Config{}
+	cfg.Protocols.Gemini = config.GeminiProtocol{Enabled: true, Port: 1965}
+	cfg.Protocols.Gopher = config.GopherProtocol{Enabled: true, Port: 70}
+	cfg.Caching.Enabled = true
+
+	collector := &DiagnosticsCollector{version: "v1.0.0", commit: "abc123", startTime: time.Now()}
+	collector.SetConfig(cfg)
+	caps := collector.capabilities()
+	caps.Storage = StorageCapabilities{Driver: "sqlite", Events: 42}
+
+	text := caps.FormatAsKeyValue()
+	for _, want := range []string{
+		"format: 1\n",
+		"version: v1.0.0\n",
+		"protocols: gopher gemini\n",
+		"protocol.gopher.port: 70\n",
+		"protocol.gemini.port: 1965\n",
+		"storage.driver: sqlite\n",
+		"storage.events: 42\n",
+		"sync.enabled: false\n",
+		"feature.caching: true\n",
+		"feature.admin: false\n",
+	} {
+		if !strings.Contains(text, want) {
+			t.Errorf("expected %q in:\n%s", want, text)
+		}
+	}
+
+	for _, line := range strings.Split(strings.TrimSpace(text), "\n") {
+		if key, _, ok := strings.Cut(line, ": "); !ok || strings.ContainsAny(key, " \t") {
+			t.Errorf("line %q is not \"key: value\"", line)
+		}
+	}
+}
+
+func TestCollectAll(t *testing.T) {
+	ctx := context.Background()
+	st, err := storage.New(ctx, &config.Storage{Driver: "sqlite", SQLitePath: filepath.Join(t.TempDir(), "test.db")})
+	if err != nil {
+		t.Fatalf("storage.New() error = %v", err)
+	}
+	defer st.Close()
+
+	sk := nostr.GeneratePrivateKey()
+	for i, kind := range []int{0, 1, 1} {
+		event := &nostr.Event{Kind: kind, CreatedAt: nostr.Timestamp(1700000000 + i), Tags: nostr.Tags{}, Content: "{}"}
+		event.Sign(sk)
+		if err := st.StoreEvent(ctx, event); err != nil {
+			t.Fatalf("StoreEvent() error = %v", err)
+		}
+	}
+
+	responses := cache.NewMemoryCache(&cache.Config{MaxSize: 1 << 20, CleanupInterval: time.Minute})
+	defer responses.Close()
+	responses.Set(ctx, "page", []byte("cached"), time.Minute)
+	responses.Get(ctx, "page")
+	responses.Get(ctx, "missing")
+
+	collector := NewDiagnosticsCollector("test-version", "test-commit", st, nil)
+	collector.SetCache(responses, "memory")
+
+	diag, err := collector.CollectAll(ctx)
+	if err != nil {
+		t.Fatalf("CollectAll() error = %v", err)
+	}
+
+	// The pages show what storage and the cache hold, not placeholders
+	if diag.Storage.TotalEvents != 3 || diag.Storage.EventsByKind[1] != 2 || diag.Storage.EventsByKind[0] != 1 {
+		t.Errorf("storage stats = %d events by kind %v, want 3 with 2 notes", diag.Storage.TotalEvents, diag.Storage.EventsByKind)
+	}
+	if diag.Storage.OldestEventTime == nil || diag.Storage.OldestEventTime.Unix() != 1700000000 {
+		t.Errorf("oldest event = %v, want 1700000000", diag.Storage.OldestEventTime)
+	}
+	if diag.Sync.Enabled {
+		t.Error("expected sync stats to be disabled without a sync engine")
+	}
+	if diag.Cache == nil || !diag.Cache.Enabled || diag.Cache.Keys != 1 || diag.Cache.Hits != 1 || diag.Cache.Misses != 1 {
+		t.Errorf("cache stats = %+v, want 1 key, 1 hit and 1 miss", diag.Cache)
+	}
+
+	text := diag.FormatAsText()
+	for _, want := range []string{"Total Events: 3", "Kind 1: 2 events"} {
+		if !strings.Contains(text, want) {
+			t.Errorf("text missing %q", want)
+		}
+	}
+}
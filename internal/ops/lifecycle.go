@@ -0,0 +1,99 @@
+package ops
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"time"
+
+	"github.com/sandwich/nophr/internal/config"
+	"github.com/sandwich/nophr/internal/storage"
+	"gopkg.in/yaml.v3"
+)
+
+// lifecycleTimeout bounds one write to the admin log
+const lifecycleTimeout = 5 * time.Second
+
+// Lifecycle records what a long-lived instance does in the persistent admin
+// log: each startup with the configuration it ran with, subsystems starting
+// and stopping, and how the run ended. Failing writes are printed rather than
+// returned, so logging never stops the instance.
+type Lifecycle struct {
+	storage *storage.Storage
+}
+
+// NewLifecycle creates a recorder writing to st's admin log
+func NewLifecycle(st *storage.Storage) *Lifecycle {
+	return &Lifecycle{storage: st}
+}
+
+// Startup records that the instance started with cfg. If the previous run
+// didn't record a shutdown, it was killed or lost power, and that is
+// recorded first.
+func (l *Lifecycle) Startup(cfg *config.Config, version string) {
+	ctx, cancel := context.WithTimeout(context.Background(), lifecycleTimeout)
+	defer cancel()
+
+	if last, err := l.storage.GetAdminLog(ctx, 10, 0); err == nil {
+		for _, entry := range last {
+			if entry.Type == storage.AdminLogMigration {
+				continue // Written by this startup's storage initialization
+			}
+			if entry.Type != storage.AdminLogShutdown && entry.Type != storage.AdminLogCrash {
+				l.log(ctx, storage.AdminLogRecovered, "", fmt.Sprintf("Previous run ended without a clean shutdown after %s", entry.Time.UTC().Format(time.RFC3339)))
+			}
+			break
+		}
+	}
+
+	l.log(ctx, storage.AdminLogStartup, "", fmt.Sprintf("nophr %s started, config %s", version, ConfigHash(cfg)))
+}
+
+// Started records that a subsystem started, e.g. ("gopher", "listening on :70")
+func (l *Lifecycle) Started(subsystem, detail string) {
+	l.logNow(storage.AdminLogStart, subsystem, detail)
+}
+
+// Stopped records that a subsystem stopped, with the error it stopped with
+func (l *Lifecycle) Stopped(subsystem string, err error) {
+	message := "Stopped"
+	if err != nil {
+		message = fmt.Sprintf("Stopped with error: %v", err)
+	}
+	l.logNow(storage.AdminLogStop, subsystem, message)
+}
+
+// Shutdown records that the instance stopped cleanly
+func (l *Lifecycle) Shutdown() {
+	l.logNow(storage.AdminLogShutdown, "", "Shutdown complete")
+}
+
+// Crashed records a panic the instance is stopping on
+func (l *Lifecycle) Crashed(recovered any) {
+	l.logNow(storage.AdminLogCrash, "", fmt.Sprintf("Panic: %v", recovered))
+}
+
+func (l *Lifecycle) logNow(entryType, subsystem, message string) {
+	ctx, cancel := context.WithTimeout(context.Background(), lifecycleTimeout)
+	defer cancel()
+	l.log(ctx, entryType, subsystem, message)
+}
+
+func (l *Lifecycle) log(ctx context.Context, entryType, subsystem, message string) {
+	if err := l.storage.LogAdminEvent(ctx, entryType, subsystem, message); err != nil {
+		fmt.Printf("  ⚠ %v\n", err)
+	}
+}
+
+// ConfigHash returns a short hash of the loaded configuration, so the admin
+// log shows when it changed between runs. Secrets loaded from the
+// environment aren't part of it.
+func ConfigHash(cfg *config.Config) string {
+	data, err := yaml.Marshal(cfg)
+	if err != nil {
+		return "unknown"
+	}
+	sum := sha256.Sum256(data)
+	return "sha256:" + hex.EncodeToString(sum[:])[:16]
+}
@@ -0,0 +1,67 @@
+package ops
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/sandwich/nophr/internal/config"
+	"github.com/sandwich/nophr/internal/storage"
+)
+
+func TestLifecycle(t *testing.T) {
+	ctx := context.Background()
+	st, err := storage.New(ctx, &config.Storage{Driver: "sqlite", SQLitePath: filepath.Join(t.TempDir(), "test.db")})
+	if err != nil {
+		t.Fatalf("storage.New() error = %v", err)
+	}
+	defer st.Close()
+
+	types := func() []string {
+		entries, err := st.GetAdminLog(ctx, 100, 0)
+		if err != nil {
+			t.Fatalf("GetAdminLog() error = %v", err)
+		}
+		var got []string
+		for i := len(entries) - 1; i >= 0; i-- {
+			got = append(got, entries[i].Type)
+		}
+		return got
+	}
+
+	cfg := config.Default()
+	lifecycle := NewLifecycle(st)
+	lifecycle.Startup(cfg, "v1")
+	lifecycle.Started("gopher", "Listening on localhost:70")
+	lifecycle.Stopped("gopher", errors.New("boom"))
+	lifecycle.Shutdown()
+	want := "migration migration startup start stop shutdown"
+	if got := strings.Join(types(), " "); got != want {
+		t.Fatalf("admin log = %s, want %s", got, want)
+	}
+
+	// A run that never recorded its shutdown is noted at the next startup
+	lifecycle.Startup(cfg, "v1")
+	lifecycle.Startup(cfg, "v2")
+	want += " startup recovered startup"
+	if got := strings.Join(types(), " "); got != want {
+		t.Fatalf("admin log = %s, want %s", got, want)
+	}
+
+	entries, _ := st.GetAdminLog(ctx, 2, 0)
+	if !strings.Contains(entries[0].Message, "nophr v2") || !strings.Contains(entries[0].Message, ConfigHash(cfg)) {
+		t.Errorf("startup message = %q, want the version and config hash", entries[0].Message)
+	}
+	older, _ := st.GetAdminLog(ctx, 100, entries[1].ID)
+	if len(older) != len(types())-2 {
+		t.Errorf("GetAdminLog(before) returned %d entries, want %d", len(older), len(types())-2)
+	}
+
+	changed := config.Default()
+	changed.Site.Title = "Another title"
+	if ConfigHash(changed) == ConfigHash(cfg) {
+		t.Error("expected a changed configuration to hash differently")
+	}
+}
@@ -3,6 +3,7 @@ package ops
 import (
 	"context"
 	"fmt"
+	"math"
 	"time"
 
 	"github.com/nbd-wtf/go-nostr"
@@ -20,8 +21,9 @@ type RetentionManager struct {
 	ownerPubkey     string
 
 	// Background worker control
-	stopChan chan struct{}
-	doneChan chan struct{}
+	stopChan      chan struct{}
+	doneChan      chan struct{}
+	reEvalStarted bool // doneChan is only closed by a running re-evaluation worker
 }
 
 // NewRetentionManager creates a new retention manager
@@ -57,8 +59,11 @@ func NewRetentionManager(st *storage.Storage, cfg *config.Retention, logger *Log
 }
 
 // PruneOldEvents deletes events based on retention rules
-// Routes to advanced or simple pruning based on configuration
+// Routes to advanced or simple pruning based on configuration,
+// then purges trashed events whose grace period has ended
 func (r *RetentionManager) PruneOldEvents(ctx context.Context) (int64, error) {
+	defer r.purgeTrash(ctx)
+
 	// Check if advanced retention is enabled
 	if r.config.Advanced != nil && r.config.Advanced.Enabled && r.retentionEngine != nil {
 		return r.PruneAdvanced(ctx)
@@ -68,6 +73,31 @@ func (r *RetentionManager) PruneOldEvents(ctx context.Context) (int64, error) {
 	return r.pruneSimple(ctx)
 }
 
+// trashGrace returns how long deleted events stay restorable, negative if they are deleted immediately
+func (r *RetentionManager) trashGrace() time.Duration {
+	return time.Duration(r.config.TrashGraceDays) * 24 * time.Hour
+}
+
+// removeEvent moves an event to the trash, or deletes it when no grace period is configured
+func (r *RetentionManager) removeEvent(ctx context.Context, eventID string) error {
+	if grace := r.trashGrace(); grace > 0 {
+		return r.storage.TrashEvent(ctx, eventID, storage.TrashReasonRetention, time.Now().Add(grace))
+	}
+	return r.storage.DeleteEvent(ctx, eventID)
+}
+
+// purgeTrash permanently removes trashed events whose grace period has ended
+func (r *RetentionManager) purgeTrash(ctx context.Context) {
+	purged, err := r.storage.PurgeTrash(ctx, time.Now())
+	if err != nil {
+		r.logger.Error("failed to purge trash", "error", err)
+		return
+	}
+	if purged > 0 {
+		r.logger.Info("purged trash", "count", purged)
+	}
+}
+
 // pruneSimple performs simple time-based pruning (original implementation)
 func (r *RetentionManager) pruneSimple(ctx context.Context) (int64, error) {
 	start := time.Now()
@@ -79,8 +109,14 @@ func (r *RetentionManager) pruneSimple(ctx context.Context) (int64, error) {
 		"cutoff", cutoff.Format(time.RFC3339),
 		"keep_days", r.config.KeepDays)
 
-	// Delete events before cutoff
-	deleted, err := r.storage.DeleteEventsBefore(ctx, cutoff)
+	// Delete events before cutoff, through the trash if a grace period is configured
+	var deleted int64
+	var err error
+	if grace := r.trashGrace(); grace > 0 {
+		deleted, err = r.storage.TrashEventsBefore(ctx, cutoff, time.Now().Add(grace))
+	} else {
+		deleted, err = r.storage.DeleteEventsBefore(ctx, cutoff)
+	}
 	if err != nil {
 		r.logger.LogRetentionPrune(int(deleted), time.Since(start), err)
 		return 0, fmt.Errorf("failed to prune old events: %w", err)
@@ -96,7 +132,13 @@ func (r *RetentionManager) PruneByKind(ctx context.Context, kind int) (int64, er
 
 	r.logger.Info("pruning events by kind", "kind", kind)
 
-	deleted, err := r.storage.DeleteEventsByKind(ctx, kind)
+	var deleted int64
+	var err error
+	if grace := r.trashGrace(); grace > 0 {
+		deleted, err = r.storage.TrashEventsByKind(ctx, kind, time.Now().Add(grace))
+	} else {
+		deleted, err = r.storage.DeleteEventsByKind(ctx, kind)
+	}
 	if err != nil {
 		r.logger.LogRetentionPrune(int(deleted), time.Since(start), err)
 		return 0, fmt.Errorf("failed to prune events by kind: %w", err)
@@ -231,7 +273,15 @@ func (r *RetentionManager) PruneAdvanced(ctx context.Context) (int64, error) {
 
 	totalDeleted := int64(0)
 
-	// Step 1: Prune expired events (based on retention_metadata)
+	// Step 1: Score events that have no retention metadata yet, so expiry
+	// and caps see them
+	if evaluated, err := r.evaluatePending(ctx, r.evaluationBatchSize()); err != nil {
+		r.logger.Error("failed to evaluate pending events", "error", err)
+	} else if evaluated > 0 {
+		r.logger.Info("evaluated pending events", "count", evaluated)
+	}
+
+	// Step 2: Prune expired events (based on retention_metadata)
 	expired, err := r.pruneExpiredEvents(ctx)
 	if err != nil {
 		r.logger.Error("failed to prune expired events", "error", err)
@@ -240,8 +290,9 @@ func (r *RetentionManager) PruneAdvanced(ctx context.Context) (int64, error) {
 		r.logger.Info("pruned expired events", "count", expired)
 	}
 
-	// Step 2: Enforce global caps
-	if r.config.Advanced.GlobalCaps.MaxTotalEvents > 0 || r.config.Advanced.GlobalCaps.MaxStorageMB > 0 {
+	// Step 3: Enforce per-kind and global caps
+	caps := r.config.Advanced.GlobalCaps
+	if caps.MaxTotalEvents > 0 || caps.MaxStorageMB > 0 || len(caps.MaxEventsPerKind) > 0 {
 		capped, err := r.enforceGlobalCaps(ctx)
 		if err != nil {
 			r.logger.Error("failed to enforce global caps", "error", err)
@@ -258,37 +309,110 @@ func (r *RetentionManager) PruneAdvanced(ctx context.Context) (int64, error) {
 	return totalDeleted, nil
 }
 
-// pruneExpiredEvents deletes events that have passed their retain_until date
+// pruneExpiredEvents deletes events that have passed their retain_until date.
+// Removing an event drops its retention metadata, so batches are fetched until
+// none are left or a batch makes no progress.
 func (r *RetentionManager) pruneExpiredEvents(ctx context.Context) (int64, error) {
-	// Get expired event IDs from retention_metadata
-	expiredIDs, err := r.storage.GetExpiredEvents(ctx, 1000) // Process in batches
-	if err != nil {
-		return 0, fmt.Errorf("failed to get expired events: %w", err)
+	const batchSize = 1000
+
+	deleted := int64(0)
+	for {
+		// Get expired event IDs from retention_metadata
+		expiredIDs, err := r.storage.GetExpiredEvents(ctx, batchSize)
+		if err != nil {
+			return deleted, fmt.Errorf("failed to get expired events: %w", err)
+		}
+
+		batchDeleted := r.removeEvents(ctx, expiredIDs, "expired")
+		deleted += batchDeleted
+
+		if len(expiredIDs) < batchSize || batchDeleted == 0 || ctx.Err() != nil {
+			return deleted, nil
+		}
 	}
+}
 
-	if len(expiredIDs) == 0 {
-		return 0, nil
+// removeEvents removes the given events, logging failures, and returns how many were removed
+func (r *RetentionManager) removeEvents(ctx context.Context, eventIDs []string, reason string) int64 {
+	removed := int64(0)
+	for _, eventID := range eventIDs {
+		if err := r.removeEvent(ctx, eventID); err != nil {
+			r.logger.Error("failed to delete "+reason+" event", "event_id", eventID, "error", err)
+			continue
+		}
+		removed++
+	}
+	return removed
+}
+
+// scoredIDs returns the event IDs of cap enforcement candidates
+func scoredIDs(candidates []*storage.RetentionMetadata) []string {
+	ids := make([]string, 0, len(candidates))
+	for _, meta := range candidates {
+		ids = append(ids, meta.EventID)
 	}
+	return ids
+}
 
-	// Delete events
+// enforceGlobalCaps enforces per-kind caps, then the total event and storage caps,
+// deleting the lowest-scored unprotected events first
+func (r *RetentionManager) enforceGlobalCaps(ctx context.Context) (int64, error) {
+	caps := r.config.Advanced.GlobalCaps
 	deleted := int64(0)
-	for _, eventID := range expiredIDs {
-		// Delete event from storage
-		if err := r.storage.DeleteEvent(ctx, eventID); err != nil {
-			r.logger.Error("failed to delete expired event", "event_id", eventID, "error", err)
+
+	// Per-kind caps
+	for kind, maxEvents := range caps.MaxEventsPerKind {
+		if maxEvents <= 0 {
+			continue
+		}
+
+		count, err := r.storage.CountEventsOfKind(ctx, kind)
+		if err != nil {
+			return deleted, err
+		}
+		if int(count) <= maxEvents {
 			continue
 		}
-		deleted++
+
+		r.logger.Info("kind cap exceeded",
+			"kind", kind,
+			"current", count,
+			"max", maxEvents,
+			"to_delete", int(count)-maxEvents)
+
+		candidates, err := r.storage.GetEventsByScoreForKind(ctx, kind, int(count)-maxEvents)
+		if err != nil {
+			return deleted, fmt.Errorf("failed to get events by score: %w", err)
+		}
+		deleted += r.removeEvents(ctx, scoredIDs(candidates), "low-priority")
+	}
+
+	// Total events and storage caps
+	eventsToDelete, err := r.eventsOverCap(ctx)
+	if err != nil {
+		return deleted, err
+	}
+	if eventsToDelete == 0 {
+		return deleted, nil
 	}
 
+	// Get lowest-priority events by score
+	candidates, err := r.storage.GetEventsByScore(ctx, eventsToDelete)
+	if err != nil {
+		return deleted, fmt.Errorf("failed to get events by score: %w", err)
+	}
+	deleted += r.removeEvents(ctx, scoredIDs(candidates), "low-priority")
+
 	return deleted, nil
 }
 
-// enforceGlobalCaps enforces storage caps by deleting lowest-priority events
-func (r *RetentionManager) enforceGlobalCaps(ctx context.Context) (int64, error) {
+// eventsOverCap returns how many events must go to satisfy max_total_events and
+// max_storage_mb. The storage cap is converted to a number of events using the
+// average stored size per event; events already in the trash count as freed,
+// since the next purge releases their space.
+func (r *RetentionManager) eventsOverCap(ctx context.Context) (int, error) {
 	caps := r.config.Advanced.GlobalCaps
 
-	// Check if we're over the total events cap
 	totalEvents, err := r.storage.CountEvents(ctx)
 	if err != nil {
 		return 0, fmt.Errorf("failed to count events: %w", err)
@@ -303,27 +427,68 @@ func (r *RetentionManager) enforceGlobalCaps(ctx context.Context) (int64, error)
 			"to_delete", eventsToDelete)
 	}
 
-	if eventsToDelete == 0 {
+	if caps.MaxStorageMB > 0 && totalEvents > 0 {
+		sizeMB, err := r.storage.LiveDataSize(ctx)
+		if err != nil {
+			return 0, fmt.Errorf("failed to measure storage: %w", err)
+		}
+		trashed, err := r.storage.CountTrash(ctx)
+		if err != nil {
+			return 0, err
+		}
+
+		if over := sizeMB - float64(caps.MaxStorageMB); over > 0 {
+			perEventMB := sizeMB / float64(totalEvents+trashed)
+			toDelete := int(math.Ceil(over/perEventMB)) - int(trashed)
+
+			r.logger.Info("storage cap exceeded",
+				"current_mb", sizeMB,
+				"max_mb", caps.MaxStorageMB,
+				"to_delete", toDelete)
+
+			if toDelete > eventsToDelete {
+				eventsToDelete = toDelete
+			}
+		}
+	}
+
+	return eventsToDelete, nil
+}
+
+// evaluatePending scores events stored without retention metadata, such as
+// those synced before advanced retention was enabled, so caps can rank them
+func (r *RetentionManager) evaluatePending(ctx context.Context, limit int) (int, error) {
+	eventIDs, err := r.storage.GetEventsNeedingEvaluation(ctx, limit)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get events needing evaluation: %w", err)
+	}
+	if len(eventIDs) == 0 {
 		return 0, nil
 	}
 
-	// Get lowest-priority events by score
-	candidates, err := r.storage.GetEventsByScore(ctx, eventsToDelete)
+	events, err := r.storage.QueryEvents(ctx, nostr.Filter{IDs: eventIDs, Limit: len(eventIDs)})
 	if err != nil {
-		return 0, fmt.Errorf("failed to get events by score: %w", err)
+		return 0, fmt.Errorf("failed to load events: %w", err)
 	}
 
-	// Delete events
-	deleted := int64(0)
-	for _, meta := range candidates {
-		if err := r.storage.DeleteEvent(ctx, meta.EventID); err != nil {
-			r.logger.Error("failed to delete low-priority event", "event_id", meta.EventID, "error", err)
+	evaluated := 0
+	for _, event := range events {
+		if err := r.EvaluateEvent(ctx, event); err != nil {
+			r.logger.Error("failed to evaluate event", "event_id", event.ID, "error", err)
 			continue
 		}
-		deleted++
+		evaluated++
 	}
 
-	return deleted, nil
+	return evaluated, nil
+}
+
+// evaluationBatchSize returns the configured re-evaluation batch size
+func (r *RetentionManager) evaluationBatchSize() int {
+	if r.config.Advanced != nil && r.config.Advanced.Evaluation.BatchSize > 0 {
+		return r.config.Advanced.Evaluation.BatchSize
+	}
+	return 1000
 }
 
 // EvaluateEvent evaluates retention for a single event
@@ -332,6 +497,15 @@ func (r *RetentionManager) EvaluateEvent(ctx context.Context, event *nostr.Event
 		return nil // Advanced retention not enabled, skip
 	}
 
+	// Manual pins override rule evaluation
+	pinned, err := r.storage.IsEventPinned(ctx, event.ID)
+	if err != nil {
+		return fmt.Errorf("failed to check pin: %w", err)
+	}
+	if pinned {
+		return nil
+	}
+
 	decision, err := r.retentionEngine.EvaluateEvent(ctx, event)
 	if err != nil {
 		return fmt.Errorf("failed to evaluate event: %w", err)
@@ -382,13 +556,13 @@ func (a *storageAdapter) GetAggregateByID(eventID string) (*retention.AggregateD
 }
 
 func (a *storageAdapter) CountEventsByAuthor(pubkey string) (int, error) {
-	// This would need a new storage method, for now return 0
-	return 0, nil
+	count, err := a.storage.CountEventsByAuthor(context.Background(), pubkey)
+	return int(count), err
 }
 
 func (a *storageAdapter) CountEventsByKind(kind int) (int, error) {
-	// This would need a new storage method, for now return 0
-	return 0, nil
+	count, err := a.storage.CountEventsOfKind(context.Background(), kind)
+	return int(count), err
 }
 
 // graphAdapter adapts storage.Storage to retention.SocialGraphReader
@@ -439,6 +613,7 @@ func (r *RetentionManager) StartReEvaluationWorker(ctx context.Context) {
 	r.logger.Info("starting re-evaluation worker",
 		"interval_hours", r.config.Advanced.Evaluation.ReEvalIntervalHrs)
 
+	r.reEvalStarted = true
 	go r.reEvaluationLoop(ctx, interval)
 }
 
@@ -474,12 +649,16 @@ func (r *RetentionManager) reEvaluateEvents(ctx context.Context) error {
 		return fmt.Errorf("retention engine not initialized")
 	}
 
+	// Pick up events that were never evaluated
+	if pending, err := r.evaluatePending(ctx, r.evaluationBatchSize()); err != nil {
+		r.logger.Error("failed to evaluate pending events", "error", err)
+	} else if pending > 0 {
+		r.logger.Info("evaluated pending events", "count", pending)
+	}
+
 	// Get events that need re-evaluation
 	cutoff := time.Now().Add(-time.Duration(r.config.Advanced.Evaluation.ReEvalIntervalHrs) * time.Hour)
-	batchSize := r.config.Advanced.Evaluation.BatchSize
-	if batchSize == 0 {
-		batchSize = 1000
-	}
+	batchSize := r.evaluationBatchSize()
 
 	eventIDs, err := r.storage.GetEventsForReEvaluation(ctx, cutoff, batchSize)
 	if err != nil {
@@ -528,10 +707,12 @@ func (r *RetentionManager) reEvaluateEvents(ctx context.Context) error {
 	return nil
 }
 
-// Stop stops the re-evaluation worker gracefully
+// Stop stops the background workers, waiting for the re-evaluation worker if it was started
 func (r *RetentionManager) Stop() {
 	close(r.stopChan)
-	<-r.doneChan
+	if r.reEvalStarted {
+		<-r.doneChan
+	}
 }
 
 // ============================================================================
@@ -0,0 +1,232 @@
+package ops
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/nbd-wtf/go-nostr"
+	"github.com/sandwich/nophr/internal/storage"
+)
+
+// PrunePlan reports what a pruning run would remove, without removing anything
+type PrunePlan struct {
+	GeneratedAt    time.Time
+	Advanced       bool         // Rules and caps were evaluated, rather than keep_days
+	ScannedEvents  int64        // Events examined
+	Groups         []*PlanGroup // Affected events per rule or cap, in pruning order
+	TrashGraceDays int          // Removed events go to the trash for this many days first
+}
+
+// PlanGroup summarizes the events one rule or cap would remove
+type PlanGroup struct {
+	Name   string
+	Events int64
+	Bytes  int64 // Estimated from the events' serialized size
+	Oldest time.Time
+	Newest time.Time
+}
+
+// add counts an event created at created into the group
+func (g *PlanGroup) add(created time.Time, size int64) {
+	if g.Events == 0 || created.Before(g.Oldest) {
+		g.Oldest = created
+	}
+	if g.Events == 0 || created.After(g.Newest) {
+		g.Newest = created
+	}
+	g.Events++
+	g.Bytes += size
+}
+
+// Totals returns the number of events and bytes the whole plan would remove
+func (p *PrunePlan) Totals() (events, bytes int64) {
+	for _, g := range p.Groups {
+		events += g.Events
+		bytes += g.Bytes
+	}
+	return events, bytes
+}
+
+// planCandidate is a surviving event that caps may still remove. Only what
+// the caps look at is kept, not the event, so a plan over a large database
+// holds a few words per event.
+type planCandidate struct {
+	kind      int
+	createdAt nostr.Timestamp
+	size      int64
+	score     int
+	protected bool
+	removed   bool
+}
+
+// PlanPrune evaluates the retention configuration against every stored event and
+// reports what a pruning run would remove. Nothing is deleted and no retention
+// metadata is written; rules are evaluated fresh, so the plan reflects the
+// current configuration even for events scored under older rules.
+func (r *RetentionManager) PlanPrune(ctx context.Context) (*PrunePlan, error) {
+	plan := &PrunePlan{
+		GeneratedAt:    time.Now(),
+		Advanced:       r.retentionEngine != nil,
+		TrashGraceDays: r.config.TrashGraceDays,
+	}
+
+	protectedRules, err := r.storage.ProtectedEventRules(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	groups := make(map[string]*PlanGroup)
+	group := func(name string) *PlanGroup {
+		g, ok := groups[name]
+		if !ok {
+			g = &PlanGroup{Name: name}
+			groups[name] = g
+			plan.Groups = append(plan.Groups, g)
+		}
+		return g
+	}
+
+	var survivors []planCandidate
+	cutoff := plan.GeneratedAt.AddDate(0, 0, -r.config.KeepDays)
+
+	err = r.storage.IterateEvents(ctx, r.evaluationBatchSize(), func(event *nostr.Event) error {
+		plan.ScannedEvents++
+		size := int64(len(event.String()))
+		rule, protected := protectedRules[event.ID]
+
+		if !plan.Advanced {
+			if !protected && event.CreatedAt.Time().Before(cutoff) {
+				group("keep_days").add(event.CreatedAt.Time(), size)
+			}
+			return nil
+		}
+
+		// Manual pins override rule evaluation
+		if rule == storage.ManualPinRuleName {
+			survivors = append(survivors, planCandidate{kind: event.Kind, createdAt: event.CreatedAt, size: size, protected: true})
+			return nil
+		}
+
+		decision, err := r.retentionEngine.EvaluateEvent(ctx, event)
+		if err != nil {
+			return fmt.Errorf("failed to evaluate event %s: %w", event.ID, err)
+		}
+		if decision.RetainUntil != nil && decision.RetainUntil.Before(plan.GeneratedAt) && !decision.Protected {
+			group(decision.RuleName).add(event.CreatedAt.Time(), size)
+			return nil
+		}
+
+		survivors = append(survivors, planCandidate{
+			kind:      event.Kind,
+			createdAt: event.CreatedAt,
+			size:      size,
+			score:     decision.Score,
+			protected: decision.Protected,
+		})
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if plan.Advanced {
+		if err := r.planCaps(ctx, survivors, group); err != nil {
+			return nil, err
+		}
+	}
+
+	return plan, nil
+}
+
+// planCaps simulates per-kind, total event and storage caps on the events that
+// survive rule expiry, removing the lowest-scored unprotected events first
+func (r *RetentionManager) planCaps(ctx context.Context, survivors []planCandidate, group func(string) *PlanGroup) error {
+	caps := r.config.Advanced.GlobalCaps
+
+	// Lowest scores go first, oldest first among equals
+	sort.SliceStable(survivors, func(i, j int) bool {
+		if survivors[i].score != survivors[j].score {
+			return survivors[i].score < survivors[j].score
+		}
+		return survivors[i].createdAt < survivors[j].createdAt
+	})
+
+	removeLowest := func(name string, count int, match func(*planCandidate) bool) {
+		for i := range survivors {
+			c := &survivors[i]
+			if count <= 0 {
+				return
+			}
+			if c.removed || c.protected || (match != nil && !match(c)) {
+				continue
+			}
+			c.removed = true
+			group(name).add(c.createdAt.Time(), c.size)
+			count--
+		}
+	}
+
+	kinds := make([]int, 0, len(caps.MaxEventsPerKind))
+	for kind := range caps.MaxEventsPerKind {
+		kinds = append(kinds, kind)
+	}
+	sort.Ints(kinds)
+
+	for _, kind := range kinds {
+		maxEvents := caps.MaxEventsPerKind[kind]
+		if maxEvents <= 0 {
+			continue
+		}
+		count := 0
+		for _, c := range survivors {
+			if c.kind == kind {
+				count++
+			}
+		}
+		if count > maxEvents {
+			removeLowest(fmt.Sprintf("cap: kind %d", kind), count-maxEvents, func(c *planCandidate) bool {
+				return c.kind == kind
+			})
+		}
+	}
+
+	remaining := 0
+	for _, c := range survivors {
+		if !c.removed {
+			remaining++
+		}
+	}
+
+	if caps.MaxTotalEvents > 0 && remaining > caps.MaxTotalEvents {
+		removeLowest("cap: max_total_events", remaining-caps.MaxTotalEvents, nil)
+		remaining = caps.MaxTotalEvents
+	}
+
+	if caps.MaxStorageMB > 0 && remaining > 0 {
+		sizeMB, err := r.storage.LiveDataSize(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to measure storage: %w", err)
+		}
+		trashed, err := r.storage.CountTrash(ctx)
+		if err != nil {
+			return err
+		}
+		total, err := r.storage.CountEvents(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to count events: %w", err)
+		}
+
+		// Same estimate as eventsOverCap: average stored size per event,
+		// counting events already in the trash as freed
+		if over := sizeMB - float64(caps.MaxStorageMB); over > 0 && total+trashed > 0 {
+			perEventMB := sizeMB / float64(total+trashed)
+			toDelete := int(math.Ceil(over/perEventMB)) - int(trashed) - (int(total) - remaining)
+			removeLowest("cap: max_storage_mb", toDelete, nil)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,71 @@
+package ops
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/nbd-wtf/go-nostr"
+	"github.com/sandwich/nophr/internal/config"
+	"github.com/sandwich/nophr/internal/storage"
+)
+
+func TestPlanPruneSimple(t *testing.T) {
+	ctx := context.Background()
+	st, err := storage.New(ctx, &config.Storage{Driver: "sqlite", SQLitePath: filepath.Join(t.TempDir(), "test.db")})
+	if err != nil {
+		t.Fatalf("Failed to create storage: %v", err)
+	}
+	defer st.Close()
+
+	sk := nostr.GeneratePrivateKey()
+	pk, _ := nostr.GetPublicKey(sk)
+	now := time.Now()
+
+	var events []*nostr.Event
+	for _, age := range []time.Duration{400 * 24 * time.Hour, 200 * 24 * time.Hour, time.Hour} {
+		event := &nostr.Event{
+			PubKey:    pk,
+			CreatedAt: nostr.Timestamp(now.Add(-age).Unix()),
+			Kind:      1,
+			Content:   "note",
+		}
+		event.Sign(sk)
+		if err := st.StoreEvent(ctx, event); err != nil {
+			t.Fatalf("Failed to store event: %v", err)
+		}
+		events = append(events, event)
+	}
+
+	// The oldest event is pinned and must not appear in the plan
+	if err := st.PinEvent(ctx, events[0].ID); err != nil {
+		t.Fatalf("PinEvent failed: %v", err)
+	}
+
+	cfg := &config.Retention{KeepDays: 90, TrashGraceDays: 7}
+	rm := NewRetentionManager(st, cfg, NewLogger(&config.Logging{Level: "error"}), "")
+
+	plan, err := rm.PlanPrune(ctx)
+	if err != nil {
+		t.Fatalf("PlanPrune failed: %v", err)
+	}
+
+	if plan.Advanced || plan.ScannedEvents != 3 || plan.TrashGraceDays != 7 {
+		t.Errorf("Unexpected plan header: %+v", plan)
+	}
+	if len(plan.Groups) != 1 || plan.Groups[0].Name != "keep_days" || plan.Groups[0].Events != 1 {
+		t.Fatalf("Expected one keep_days event, got %+v", plan.Groups)
+	}
+	if plan.Groups[0].Oldest.Unix() != int64(events[1].CreatedAt) {
+		t.Errorf("Expected the 200-day-old event, got %v", plan.Groups[0].Oldest)
+	}
+	if _, bytes := plan.Totals(); bytes != int64(len(events[1].String())) {
+		t.Errorf("Expected bytes of the serialized event, got %d", bytes)
+	}
+
+	// Planning must not delete anything
+	if count, _ := st.CountEvents(ctx); count != 3 {
+		t.Errorf("Expected 3 events after planning, got %d", count)
+	}
+}
@@ -0,0 +1,92 @@
+package ops
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/sandwich/nophr/internal/retention"
+	"github.com/sandwich/nophr/internal/storage"
+)
+
+// ProtectEvent pins an event so it is exempt from retention rules and caps
+func (r *RetentionManager) ProtectEvent(ctx context.Context, eventID string) error {
+	exists, err := r.storage.EventExists(ctx, eventID)
+	if err != nil {
+		return fmt.Errorf("failed to check event: %w", err)
+	}
+	if !exists {
+		return fmt.Errorf("event not found: %s", eventID)
+	}
+
+	if err := r.storage.PinEvent(ctx, eventID); err != nil {
+		return fmt.Errorf("failed to protect event: %w", err)
+	}
+
+	r.logger.Info("event protected", "event_id", eventID)
+	return nil
+}
+
+// UnprotectEvent removes a manual pin and re-evaluates the event against the rules
+func (r *RetentionManager) UnprotectEvent(ctx context.Context, eventID string) error {
+	removed, err := r.storage.UnpinEvent(ctx, eventID)
+	if err != nil {
+		return fmt.Errorf("failed to unprotect event: %w", err)
+	}
+	if !removed {
+		return fmt.Errorf("event is not manually protected: %s", eventID)
+	}
+
+	r.logger.Info("event unprotected", "event_id", eventID)
+
+	// Re-evaluate so the event gets a fresh rule decision
+	if r.retentionEngine != nil {
+		event, err := r.storage.GetEventByID(ctx, eventID)
+		if err != nil {
+			return nil // Event already gone, nothing to evaluate
+		}
+		if err := r.EvaluateEvent(ctx, event); err != nil {
+			return fmt.Errorf("failed to re-evaluate event: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// ListProtectedEvents returns protected events (manual pins and rule-protected)
+func (r *RetentionManager) ListProtectedEvents(ctx context.Context, limit int) ([]*storage.RetentionMetadata, error) {
+	if limit <= 0 {
+		limit = 100
+	}
+
+	protected, err := r.storage.ListProtectedEvents(ctx, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list protected events: %w", err)
+	}
+
+	return protected, nil
+}
+
+// ExplainEvent evaluates an event against the retention rules without storing
+// the result and returns the decision along with the currently stored metadata
+func (r *RetentionManager) ExplainEvent(ctx context.Context, eventID string) (*retention.Explanation, *storage.RetentionMetadata, error) {
+	if r.retentionEngine == nil {
+		return nil, nil, fmt.Errorf("advanced retention is not enabled")
+	}
+
+	event, err := r.storage.GetEventByID(ctx, eventID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	explanation, err := r.retentionEngine.Explain(ctx, event)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to explain event: %w", err)
+	}
+
+	stored, err := r.storage.GetRetentionMetadata(ctx, eventID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return explanation, stored, nil
+}
@@ -0,0 +1,482 @@
+package ops
+
+import (
+	"archive/tar"
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/klauspost/compress/zstd"
+	"github.com/nbd-wtf/go-nostr"
+	"github.com/sandwich/nophr/internal/storage"
+)
+
+// SnapshotFormat is the version of the snapshot layout. Restore refuses
+// snapshots from a newer format.
+const SnapshotFormat = 1
+
+// Snapshot entries, in the order they are written and restored. Events come
+// before retention metadata, which refers to them.
+const (
+	manifestEntry   = "manifest.json"
+	eventsEntry     = "events.jsonl"
+	aggregatesEntry = "aggregates.jsonl"
+	cursorsEntry    = "sync_state.jsonl"
+	retentionEntry  = "retention_metadata.jsonl"
+)
+
+// snapshotBatchSize is how many rows are read or written at a time
+const snapshotBatchSize = 500
+
+// SnapshotManifest describes a snapshot: what wrote it and how much it holds
+type SnapshotManifest struct {
+	Format            int       `json:"format"`
+	Version           string    `json:"nophr_version"`
+	CreatedAt         time.Time `json:"created_at"`
+	Driver            string    `json:"driver"` // Storage driver the snapshot was taken from
+	Events            int64     `json:"events"`
+	Aggregates        int64     `json:"aggregates"`
+	Cursors           int64     `json:"sync_state"`
+	RetentionMetadata int64     `json:"retention_metadata"`
+}
+
+// RestoreResult counts what a restore wrote
+type RestoreResult struct {
+	Events            int64
+	Existing          int64 // Events already in storage
+	Aggregates        int64
+	Cursors           int64
+	RetentionMetadata int64
+	Orphaned          int64 // Retention metadata for events not in storage
+}
+
+// snapshotAggregate is one line of aggregates.jsonl
+type snapshotAggregate struct {
+	EventID           string         `json:"event_id"`
+	ReplyCount        int            `json:"reply_count"`
+	ReactionTotal     int            `json:"reaction_total"`
+	ReactionCounts    map[string]int `json:"reaction_counts,omitempty"`
+	ZapSatsTotal      int64          `json:"zap_sats_total"`
+	LastInteractionAt int64          `json:"last_interaction_at"`
+}
+
+// snapshotCursor is one line of sync_state.jsonl
+type snapshotCursor struct {
+	Relay     string `json:"relay"`
+	Kind      int    `json:"kind"`
+	Since     int64  `json:"since"`
+	UpdatedAt int64  `json:"updated_at"`
+}
+
+// snapshotRetention is one line of retention_metadata.jsonl. Times are Unix
+// seconds; a missing retain_until means the event is kept forever.
+type snapshotRetention struct {
+	EventID         string `json:"event_id"`
+	RuleName        string `json:"rule_name"`
+	RulePriority    int    `json:"rule_priority"`
+	RetainUntil     *int64 `json:"retain_until,omitempty"`
+	LastEvaluatedAt int64  `json:"last_evaluated_at"`
+	Score           int    `json:"score"`
+	Protected       bool   `json:"protected"`
+}
+
+// SnapshotCompression picks the compression for a snapshot path by its
+// extension: "zstd" for .zst, "gzip" for .gz and .tgz, otherwise "none"
+func SnapshotCompression(path string) string {
+	switch {
+	case strings.HasSuffix(path, ".zst") || strings.HasSuffix(path, ".tzst"):
+		return "zstd"
+	case strings.HasSuffix(path, ".gz") || strings.HasSuffix(path, ".tgz"):
+		return "gzip"
+	default:
+		return "none"
+	}
+}
+
+// WriteSnapshot writes every event, aggregate, sync cursor and retention
+// metadata row in st to w as a tar archive of JSON lines. The format doesn't
+// depend on the storage driver, so a snapshot can be restored into any.
+func WriteSnapshot(ctx context.Context, st *storage.Storage, w io.Writer, compression, version string) (*SnapshotManifest, error) {
+	manifest := &SnapshotManifest{
+		Format:    SnapshotFormat,
+		Version:   version,
+		CreatedAt: time.Now().UTC(),
+		Driver:    st.Driver(),
+	}
+
+	// Tar headers need each entry's size, so entries are spooled to
+	// temporary files before the archive is written
+	dir, err := os.MkdirTemp("", "nophr-snapshot-")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create temporary directory: %w", err)
+	}
+	defer os.RemoveAll(dir)
+
+	entries := []struct {
+		name  string
+		count *int64
+		write func(enc *json.Encoder) (int64, error)
+	}{
+		{eventsEntry, &manifest.Events, func(enc *json.Encoder) (int64, error) {
+			var n int64
+			err := st.IterateEvents(ctx, snapshotBatchSize, func(event *nostr.Event) error {
+				n++
+				return enc.Encode(event)
+			})
+			return n, err
+		}},
+		{aggregatesEntry, &manifest.Aggregates, func(enc *json.Encoder) (int64, error) {
+			return writeSnapshotAggregates(ctx, st, enc)
+		}},
+		{cursorsEntry, &manifest.Cursors, func(enc *json.Encoder) (int64, error) {
+			states, err := st.GetAllSyncStates(ctx)
+			if err != nil {
+				return 0, err
+			}
+			for _, s := range states {
+				if err := enc.Encode(snapshotCursor{Relay: s.Relay, Kind: s.Kind, Since: s.Since, UpdatedAt: s.UpdatedAt}); err != nil {
+					return 0, err
+				}
+			}
+			return int64(len(states)), nil
+		}},
+		{retentionEntry, &manifest.RetentionMetadata, func(enc *json.Encoder) (int64, error) {
+			metas, err := st.ListRetentionMetadata(ctx)
+			if err != nil {
+				return 0, err
+			}
+			for _, meta := range metas {
+				if err := enc.Encode(toSnapshotRetention(meta)); err != nil {
+					return 0, err
+				}
+			}
+			return int64(len(metas)), nil
+		}},
+	}
+
+	files := make([]*os.File, len(entries))
+	for i, entry := range entries {
+		f, err := os.CreateTemp(dir, entry.name)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create temporary file: %w", err)
+		}
+		defer f.Close()
+
+		buf := bufio.NewWriter(f)
+		n, err := entry.write(json.NewEncoder(buf))
+		if err != nil {
+			return nil, fmt.Errorf("failed to write %s: %w", entry.name, err)
+		}
+		if err := buf.Flush(); err != nil {
+			return nil, fmt.Errorf("failed to write %s: %w", entry.name, err)
+		}
+		*entry.count = n
+		files[i] = f
+	}
+
+	cw, err := compressWriter(w, compression)
+	if err != nil {
+		return nil, err
+	}
+	tw := tar.NewWriter(cw)
+
+	manifestJSON, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode manifest: %w", err)
+	}
+	if err := writeTarEntry(tw, manifestEntry, int64(len(manifestJSON)), bytes.NewReader(manifestJSON), manifest.CreatedAt); err != nil {
+		return nil, err
+	}
+	for i, entry := range entries {
+		f := files[i]
+		size, err := f.Seek(0, io.SeekCurrent)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", entry.name, err)
+		}
+		if _, err := f.Seek(0, io.SeekStart); err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", entry.name, err)
+		}
+		if err := writeTarEntry(tw, entry.name, size, f, manifest.CreatedAt); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := tw.Close(); err != nil {
+		return nil, fmt.Errorf("failed to finish snapshot: %w", err)
+	}
+	if err := cw.Close(); err != nil {
+		return nil, fmt.Errorf("failed to finish snapshot: %w", err)
+	}
+	return manifest, nil
+}
+
+// writeSnapshotAggregates encodes every aggregate, reading them in batches
+func writeSnapshotAggregates(ctx context.Context, st *storage.Storage, enc *json.Encoder) (int64, error) {
+	ids, err := st.ListAggregateIDs(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	var n int64
+	for start := 0; start < len(ids); start += snapshotBatchSize {
+		batch := ids[start:min(start+snapshotBatchSize, len(ids))]
+		aggs, err := st.GetAggregates(ctx, batch)
+		if err != nil {
+			return 0, err
+		}
+		for _, id := range batch {
+			agg, ok := aggs[id]
+			if !ok {
+				continue
+			}
+			if err := enc.Encode(snapshotAggregate{
+				EventID:           agg.EventID,
+				ReplyCount:        agg.ReplyCount,
+				ReactionTotal:     agg.ReactionTotal,
+				ReactionCounts:    agg.ReactionCounts,
+				ZapSatsTotal:      agg.ZapSatsTotal,
+				LastInteractionAt: agg.LastInteractionAt,
+			}); err != nil {
+				return 0, err
+			}
+			n++
+		}
+	}
+	return n, nil
+}
+
+func toSnapshotRetention(meta *storage.RetentionMetadata) snapshotRetention {
+	r := snapshotRetention{
+		EventID:         meta.EventID,
+		RuleName:        meta.RuleName,
+		RulePriority:    meta.RulePriority,
+		LastEvaluatedAt: meta.LastEvaluatedAt.Unix(),
+		Score:           meta.Score,
+		Protected:       meta.Protected,
+	}
+	if meta.RetainUntil != nil {
+		until := meta.RetainUntil.Unix()
+		r.RetainUntil = &until
+	}
+	return r
+}
+
+func writeTarEntry(tw *tar.Writer, name string, size int64, r io.Reader, modTime time.Time) error {
+	if err := tw.WriteHeader(&tar.Header{Name: name, Mode: 0600, Size: size, ModTime: modTime, Typeflag: tar.TypeReg}); err != nil {
+		return fmt.Errorf("failed to write %s: %w", name, err)
+	}
+	if _, err := io.Copy(tw, r); err != nil {
+		return fmt.Errorf("failed to write %s: %w", name, err)
+	}
+	return nil
+}
+
+// nopWriteCloser leaves closing the underlying writer to its owner
+type nopWriteCloser struct{ io.Writer }
+
+func (nopWriteCloser) Close() error { return nil }
+
+func compressWriter(w io.Writer, compression string) (io.WriteCloser, error) {
+	switch compression {
+	case "zstd":
+		return zstd.NewWriter(w)
+	case "gzip":
+		return gzip.NewWriter(w), nil
+	case "none", "":
+		return nopWriteCloser{w}, nil
+	default:
+		return nil, fmt.Errorf("unknown compression %q (expected zstd, gzip or none)", compression)
+	}
+}
+
+// decompressReader detects zstd and gzip by their magic numbers, so restores
+// don't depend on the file name
+func decompressReader(r io.Reader) (io.Reader, func(), error) {
+	br := bufio.NewReader(r)
+	magic, _ := br.Peek(4)
+
+	switch {
+	case bytes.HasPrefix(magic, []byte{0x28, 0xb5, 0x2f, 0xfd}):
+		zr, err := zstd.NewReader(br)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to open zstd snapshot: %w", err)
+		}
+		return zr, zr.Close, nil
+	case bytes.HasPrefix(magic, []byte{0x1f, 0x8b}):
+		gr, err := gzip.NewReader(br)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to open gzip snapshot: %w", err)
+		}
+		return gr, func() { gr.Close() }, nil
+	default:
+		return br, func() {}, nil
+	}
+}
+
+// RestoreSnapshot reads a snapshot written by WriteSnapshot into st. Events
+// already stored are left alone, while aggregates, sync cursors and retention
+// metadata from the snapshot replace the stored ones.
+func RestoreSnapshot(ctx context.Context, st *storage.Storage, r io.Reader) (*SnapshotManifest, *RestoreResult, error) {
+	dr, closeReader, err := decompressReader(r)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer closeReader()
+
+	tr := tar.NewReader(dr)
+	var manifest *SnapshotManifest
+	result := &RestoreResult{}
+
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to read snapshot: %w", err)
+		}
+
+		if manifest == nil {
+			if hdr.Name != manifestEntry {
+				return nil, nil, fmt.Errorf("not a nophr snapshot: expected %s first, found %s", manifestEntry, hdr.Name)
+			}
+			manifest = &SnapshotManifest{}
+			if err := json.NewDecoder(tr).Decode(manifest); err != nil {
+				return nil, nil, fmt.Errorf("failed to read manifest: %w", err)
+			}
+			if manifest.Format > SnapshotFormat {
+				return nil, nil, fmt.Errorf("snapshot format %d is newer than this nophr supports (%d)", manifest.Format, SnapshotFormat)
+			}
+			continue
+		}
+
+		switch hdr.Name {
+		case eventsEntry:
+			err = restoreEvents(ctx, st, tr, result)
+		case aggregatesEntry:
+			err = restoreAggregates(ctx, st, tr, result)
+		case cursorsEntry:
+			err = eachLine(tr, func(c *snapshotCursor) error {
+				result.Cursors++
+				return st.SaveSyncState(ctx, &storage.SyncState{Relay: c.Relay, Kind: c.Kind, Since: c.Since, UpdatedAt: c.UpdatedAt})
+			})
+		case retentionEntry:
+			err = restoreRetention(ctx, st, tr, result)
+		default:
+			// Entries from a newer nophr are skipped
+			continue
+		}
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to restore %s: %w", hdr.Name, err)
+		}
+	}
+
+	if manifest == nil {
+		return nil, nil, fmt.Errorf("not a nophr snapshot: empty archive")
+	}
+	return manifest, result, nil
+}
+
+func restoreEvents(ctx context.Context, st *storage.Storage, r io.Reader, result *RestoreResult) error {
+	return eachLine(r, func(event *nostr.Event) error {
+		exists, err := st.EventExists(ctx, event.ID)
+		if err != nil {
+			return err
+		}
+		if exists {
+			result.Existing++
+			return nil
+		}
+		if err := st.StoreEvent(ctx, event); err != nil {
+			return err
+		}
+		result.Events++
+		return nil
+	})
+}
+
+func restoreAggregates(ctx context.Context, st *storage.Storage, r io.Reader, result *RestoreResult) error {
+	var batch []*storage.Aggregate
+	flush := func() error {
+		if err := st.ReplaceAggregates(ctx, batch); err != nil {
+			return err
+		}
+		result.Aggregates += int64(len(batch))
+		batch = batch[:0]
+		return nil
+	}
+
+	err := eachLine(r, func(a *snapshotAggregate) error {
+		batch = append(batch, &storage.Aggregate{
+			EventID:           a.EventID,
+			ReplyCount:        a.ReplyCount,
+			ReactionTotal:     a.ReactionTotal,
+			ReactionCounts:    a.ReactionCounts,
+			ZapSatsTotal:      a.ZapSatsTotal,
+			LastInteractionAt: a.LastInteractionAt,
+		})
+		if len(batch) >= snapshotBatchSize {
+			return flush()
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	return flush()
+}
+
+func restoreRetention(ctx context.Context, st *storage.Storage, r io.Reader, result *RestoreResult) error {
+	return eachLine(r, func(m *snapshotRetention) error {
+		// Metadata refers to its event, which may have been left out of the snapshot
+		exists, err := st.EventExists(ctx, m.EventID)
+		if err != nil {
+			return err
+		}
+		if !exists {
+			result.Orphaned++
+			return nil
+		}
+
+		meta := &storage.RetentionMetadata{
+			EventID:         m.EventID,
+			RuleName:        m.RuleName,
+			RulePriority:    m.RulePriority,
+			LastEvaluatedAt: time.Unix(m.LastEvaluatedAt, 0),
+			Score:           m.Score,
+			Protected:       m.Protected,
+		}
+		if m.RetainUntil != nil {
+			until := time.Unix(*m.RetainUntil, 0)
+			meta.RetainUntil = &until
+		}
+		if err := st.StoreRetentionMetadata(ctx, meta); err != nil {
+			return err
+		}
+		result.RetentionMetadata++
+		return nil
+	})
+}
+
+// eachLine decodes r as JSON lines of T, calling fn with each
+func eachLine[T any](r io.Reader, fn func(*T) error) error {
+	dec := json.NewDecoder(r)
+	for line := 1; ; line++ {
+		var v T
+		if err := dec.Decode(&v); err == io.EOF {
+			return nil
+		} else if err != nil {
+			return fmt.Errorf("line %d: %w", line, err)
+		}
+		if err := fn(&v); err != nil {
+			return err
+		}
+	}
+}
@@ -0,0 +1,97 @@
+package ops
+
+import (
+	"bytes"
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/nbd-wtf/go-nostr"
+	"github.com/sandwich/nophr/internal/config"
+	"github.com/sandwich/nophr/internal/storage"
+)
+
+func newSnapshotStorage(t *testing.T) *storage.Storage {
+	t.Helper()
+	st, err := storage.New(context.Background(), &config.Storage{Driver: "sqlite", SQLitePath: filepath.Join(t.TempDir(), "test.db")})
+	if err != nil {
+		t.Fatalf("Failed to create storage: %v", err)
+	}
+	t.Cleanup(func() { st.Close() })
+	return st
+}
+
+func TestSnapshotRoundTrip(t *testing.T) {
+	ctx := context.Background()
+	src := newSnapshotStorage(t)
+
+	sk := nostr.GeneratePrivateKey()
+	pk, _ := nostr.GetPublicKey(sk)
+	var events []*nostr.Event
+	for i := range 3 {
+		event := &nostr.Event{PubKey: pk, CreatedAt: nostr.Timestamp(time.Now().Unix() - int64(i)), Kind: 1, Content: "note"}
+		event.Sign(sk)
+		if err := src.StoreEvent(ctx, event); err != nil {
+			t.Fatalf("Failed to store event: %v", err)
+		}
+		events = append(events, event)
+	}
+	if err := src.SaveAggregate(ctx, &storage.Aggregate{EventID: events[0].ID, ReplyCount: 2, ReactionTotal: 1, ReactionCounts: map[string]int{"+": 1}, ZapSatsTotal: 21}); err != nil {
+		t.Fatalf("SaveAggregate failed: %v", err)
+	}
+	if err := src.UpdateSyncCursor(ctx, "wss://relay.example", 1, 1700000000); err != nil {
+		t.Fatalf("UpdateSyncCursor failed: %v", err)
+	}
+	if err := src.PinEvent(ctx, events[1].ID); err != nil {
+		t.Fatalf("PinEvent failed: %v", err)
+	}
+
+	for _, compression := range []string{"zstd", "gzip", "none"} {
+		t.Run(compression, func(t *testing.T) {
+			var buf bytes.Buffer
+			manifest, err := WriteSnapshot(ctx, src, &buf, compression, "v1.0.0")
+			if err != nil {
+				t.Fatalf("WriteSnapshot failed: %v", err)
+			}
+			if manifest.Events != 3 || manifest.Aggregates != 1 || manifest.Cursors != 1 || manifest.RetentionMetadata != 1 {
+				t.Fatalf("Unexpected manifest: %+v", manifest)
+			}
+
+			dst := newSnapshotStorage(t)
+			restored, result, err := RestoreSnapshot(ctx, dst, bytes.NewReader(buf.Bytes()))
+			if err != nil {
+				t.Fatalf("RestoreSnapshot failed: %v", err)
+			}
+			if restored.Version != "v1.0.0" || restored.Driver != "sqlite" {
+				t.Errorf("Unexpected restored manifest: %+v", restored)
+			}
+			if result.Events != 3 || result.Aggregates != 1 || result.Cursors != 1 || result.RetentionMetadata != 1 {
+				t.Errorf("Unexpected restore result: %+v", result)
+			}
+
+			agg, err := dst.GetAggregate(ctx, events[0].ID)
+			if err != nil || agg.ReplyCount != 2 || agg.ZapSatsTotal != 21 || agg.ReactionCounts["+"] != 1 {
+				t.Errorf("Aggregate not restored: %+v, %v", agg, err)
+			}
+			state, err := dst.GetSyncState(ctx, "wss://relay.example", 1)
+			if err != nil || state.Since != 1700000000 {
+				t.Errorf("Sync cursor not restored: %+v, %v", state, err)
+			}
+			if pinned, _ := dst.IsEventPinned(ctx, events[1].ID); !pinned {
+				t.Error("Expected the pinned event to stay pinned")
+			}
+
+			// A second restore finds every event already stored
+			if _, again, err := RestoreSnapshot(ctx, dst, bytes.NewReader(buf.Bytes())); err != nil || again.Events != 0 || again.Existing != 3 {
+				t.Errorf("Expected a repeated restore to skip stored events, got %+v, %v", again, err)
+			}
+		})
+	}
+}
+
+func TestRestoreSnapshotRejectsOtherArchives(t *testing.T) {
+	if _, _, err := RestoreSnapshot(context.Background(), newSnapshotStorage(t), bytes.NewReader([]byte("not a tar"))); err == nil {
+		t.Error("Expected an error for a file that isn't a snapshot")
+	}
+}
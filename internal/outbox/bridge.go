@@ -0,0 +1,287 @@
+package outbox
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/nbd-wtf/go-nostr"
+	"github.com/sandwich/nophr/internal/config"
+)
+
+const (
+	// bridgeProtocol names feeds as the source of bridged events in NIP-48 proxy tags
+	bridgeProtocol = "rss"
+
+	// maxFeedBytes bounds how much of a feed is downloaded
+	maxFeedBytes = 10 << 20
+
+	// feedTimeout is how long fetching one feed may take
+	feedTimeout = 30 * time.Second
+
+	// noteSummaryLength is how much of an item's summary a note quotes, in runes
+	noteSummaryLength = 280
+
+	// polledMarker is recorded as an item of every polled feed, so a feed that
+	// was empty on its first poll isn't treated as new on the next
+	polledMarker = ""
+)
+
+// BridgeResult counts what one poll of a feed did
+type BridgeResult struct {
+	Feed      string
+	Published int // Items published as events
+	Recorded  int // Items already in the feed on its first poll, recorded without publishing
+	Failed    int // Items that couldn't be stored, retried on the next poll
+}
+
+// Bridge polls the owner's RSS and Atom feeds and publishes their new items as
+// events signed with the owner's key (outbox.bridge)
+type Bridge struct {
+	publisher *Publisher
+	client    *http.Client
+	stopChan  chan struct{}
+}
+
+// NewBridge creates a feed bridge publishing through p
+func NewBridge(p *Publisher) *Bridge {
+	return &Bridge{
+		publisher: p,
+		client:    &http.Client{Timeout: feedTimeout},
+		stopChan:  make(chan struct{}),
+	}
+}
+
+// Start polls every feed now and then every outbox.bridge.interval_minutes
+func (b *Bridge) Start(ctx context.Context) {
+	interval := time.Duration(b.publisher.config.Outbox.Bridge.IntervalMinutes) * time.Minute
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			b.PollAll(ctx)
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-b.stopChan:
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// Stop stops the bridge
+func (b *Bridge) Stop() {
+	close(b.stopChan)
+}
+
+// PollAll polls each configured feed once. A feed that fails is logged and
+// retried on the next poll without holding up the others.
+func (b *Bridge) PollAll(ctx context.Context) []*BridgeResult {
+	var results []*BridgeResult
+	for _, feed := range b.publisher.config.Outbox.Bridge.Feeds {
+		result, err := b.Poll(ctx, feed)
+		if err != nil {
+			fmt.Printf("[BRIDGE] ⚠ %s: %v\n", feed.URL, err)
+			continue
+		}
+		if result.Published > 0 || result.Failed > 0 {
+			fmt.Printf("[BRIDGE] %s: %d published, %d failed\n", feed.URL, result.Published, result.Failed)
+		}
+		results = append(results, result)
+	}
+	return results
+}
+
+// Poll fetches a feed and publishes the items that weren't bridged before
+func (b *Bridge) Poll(ctx context.Context, feed config.BridgeFeed) (*BridgeResult, error) {
+	data, err := b.fetch(ctx, feed.URL)
+	if err != nil {
+		return nil, err
+	}
+
+	items, err := ParseFeed(data)
+	if err != nil {
+		return nil, err
+	}
+
+	return b.bridgeItems(ctx, feed, items)
+}
+
+// fetch downloads a feed
+func (b *Bridge) fetch(ctx context.Context, feedURL string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, feedURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("invalid feed URL: %w", err)
+	}
+	req.Header.Set("Accept", "application/rss+xml, application/atom+xml, application/xml;q=0.9, */*;q=0.8")
+
+	resp, err := b.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch feed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to fetch feed: %s", resp.Status)
+	}
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxFeedBytes+1))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read feed: %w", err)
+	}
+	if len(data) > maxFeedBytes {
+		return nil, fmt.Errorf("feed is larger than %d bytes", maxFeedBytes)
+	}
+	return data, nil
+}
+
+// bridgeItems publishes the items of a feed that weren't bridged before,
+// oldest first. On a feed's first poll the items already in it are only
+// recorded, unless the feed is set to backfill.
+func (b *Bridge) bridgeItems(ctx context.Context, feed config.BridgeFeed, items []FeedItem) (*BridgeResult, error) {
+	st := b.publisher.storage
+	result := &BridgeResult{Feed: feed.URL}
+
+	polled, err := st.CountBridgedItems(ctx, feed.URL)
+	if err != nil {
+		return nil, err
+	}
+	first := polled == 0
+
+	// Feeds list the newest item first
+	items = slices.Clone(items)
+	slices.Reverse(items)
+	sort.SliceStable(items, func(i, j int) bool {
+		return items[i].Published.Before(items[j].Published)
+	})
+
+	for _, item := range items {
+		bridged, err := st.IsItemBridged(ctx, feed.URL, item.ID)
+		if err != nil {
+			return result, err
+		}
+		if bridged {
+			continue
+		}
+
+		if first && !feed.Backfill {
+			if err := st.MarkItemBridged(ctx, feed.URL, item.ID, "", time.Now()); err != nil {
+				return result, err
+			}
+			result.Recorded++
+			continue
+		}
+
+		event, err := b.publisher.PublishFeedItem(ctx, feed, item)
+		if event == nil {
+			fmt.Printf("[BRIDGE] ⚠ %s: %v\n", item.ID, err)
+			result.Failed++
+			continue
+		}
+		if err != nil {
+			// Stored in the archive; relays catch up on the owner's next publish
+			fmt.Printf("[BRIDGE] ⚠ %v\n", err)
+		}
+		if err := st.MarkItemBridged(ctx, feed.URL, item.ID, event.ID, time.Now()); err != nil {
+			return result, err
+		}
+		result.Published++
+	}
+
+	if first {
+		if err := st.MarkItemBridged(ctx, feed.URL, polledMarker, "", time.Now()); err != nil {
+			return result, err
+		}
+	}
+	return result, nil
+}
+
+// PublishFeedItem signs a feed item as the feed's kind, stores it and
+// publishes it. Notes announce the item with its title, summary and link;
+// articles carry its content as Markdown. Both name the item in a NIP-48
+// proxy tag and are dated when the item was published.
+func (p *Publisher) PublishFeedItem(ctx context.Context, feed config.BridgeFeed, item FeedItem) (*nostr.Event, error) {
+	kind, content, tags := feedItemEvent(feed, item)
+
+	createdAt := nostr.Now()
+	if !item.Published.IsZero() && item.Published.Before(time.Now()) {
+		createdAt = nostr.Timestamp(item.Published.Unix())
+	}
+
+	event, err := p.signEventAt(kind, content, tags, createdAt)
+	if err != nil {
+		return nil, err
+	}
+
+	return p.publish(ctx, event, "bridged item")
+}
+
+// feedItemEvent builds the kind, content and tags of the event bridging an item
+func feedItemEvent(feed config.BridgeFeed, item FeedItem) (int, string, nostr.Tags) {
+	summary := HTMLToText(item.Summary)
+	if summary == "" {
+		summary = HTMLToText(item.Content)
+	}
+	summary = truncateRunes(summary, noteSummaryLength)
+
+	tags := nostr.Tags{{"proxy", feed.URL + "#" + url.QueryEscape(item.ID), bridgeProtocol}}
+	if item.Link != "" {
+		tags = append(tags, nostr.Tag{"r", item.Link})
+	}
+
+	if feed.Kind == nostr.KindArticle {
+		body := item.Content
+		if body == "" {
+			body = item.Summary
+		}
+		content := HTMLToMarkdown(body)
+		if item.Link != "" {
+			content += fmt.Sprintf("\n\n---\n\nOriginally published at <%s>", item.Link)
+		}
+
+		sum := sha256.Sum256([]byte(feed.URL + "\n" + item.ID))
+		tags = append(tags, nostr.Tag{"d", "rss-" + hex.EncodeToString(sum[:8])})
+		if item.Title != "" {
+			tags = append(tags, nostr.Tag{"title", HTMLToText(item.Title)})
+		}
+		if summary != "" {
+			tags = append(tags, nostr.Tag{"summary", summary})
+		}
+		if !item.Published.IsZero() {
+			tags = append(tags, nostr.Tag{"published_at", strconv.FormatInt(item.Published.Unix(), 10)})
+		}
+		return nostr.KindArticle, strings.TrimSpace(content), tags
+	}
+
+	var parts []string
+	for _, part := range []string{HTMLToText(item.Title), summary, item.Link} {
+		if part != "" {
+			parts = append(parts, part)
+		}
+	}
+	return nostr.KindTextNote, strings.Join(parts, "\n\n"), tags
+}
+
+// truncateRunes shortens text to at most n runes, ending it with an ellipsis
+// when it was cut
+func truncateRunes(text string, n int) string {
+	runes := []rune(text)
+	if len(runes) <= n {
+		return text
+	}
+	return strings.TrimSpace(string(runes[:n-1])) + "…"
+}
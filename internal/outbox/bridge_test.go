@@ -0,0 +1,108 @@
+package outbox
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/nbd-wtf/go-nostr"
+	"github.com/nbd-wtf/go-nostr/nip19"
+	"github.com/sandwich/nophr/internal/config"
+	internalnostr "github.com/sandwich/nophr/internal/nostr"
+	"github.com/sandwich/nophr/internal/storage"
+)
+
+func TestBridgePoll(t *testing.T) {
+	ctx := context.Background()
+	feedBody := rssFeed
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/rss+xml")
+		w.Write([]byte(feedBody))
+	}))
+	defer server.Close()
+
+	st, err := storage.New(ctx, &config.Storage{Driver: "sqlite", SQLitePath: filepath.Join(t.TempDir(), "test.db")})
+	if err != nil {
+		t.Fatalf("Failed to create storage: %v", err)
+	}
+	defer st.Close()
+
+	secretKey := nostr.GeneratePrivateKey()
+	pubkey, _ := nostr.GetPublicKey(secretKey)
+	cfg := &config.Config{}
+	cfg.Identity.Nsec, _ = nip19.EncodePrivateKey(secretKey)
+	cfg.Identity.Npub, _ = nip19.EncodePublicKey(pubkey)
+	p, err := NewPublisher(cfg, st, internalnostr.New(ctx, &cfg.Relays))
+	if err != nil {
+		t.Fatalf("NewPublisher() error = %v", err)
+	}
+	bridge := NewBridge(p)
+	feed := config.BridgeFeed{URL: server.URL + "/feed.xml", Kind: 1}
+
+	// The items already in the feed on its first poll are only recorded
+	result, err := bridge.Poll(ctx, feed)
+	if err != nil {
+		t.Fatalf("Poll() error = %v", err)
+	}
+	if result.Recorded != 2 || result.Published != 0 {
+		t.Fatalf("first Poll() = %+v, want 2 recorded", result)
+	}
+
+	// A new item is published once
+	feedBody = strings.Replace(rssFeed, "<item>", `<item>
+    <title>Third post</title>
+    <link>https://blog.example.com/third</link>
+    <description>Fresh</description>
+  </item>
+  <item>`, 1)
+	for range 2 {
+		if result, err = bridge.Poll(ctx, feed); err != nil {
+			t.Fatalf("Poll() error = %v", err)
+		}
+	}
+	events, err := st.QueryEvents(ctx, nostr.Filter{Authors: []string{pubkey}})
+	if err != nil || len(events) != 1 {
+		t.Fatalf("published %d events, %v; want the new item once", len(events), err)
+	}
+	note := events[0]
+	if note.Kind != nostr.KindTextNote || note.Content != "Third post\n\nFresh\n\nhttps://blog.example.com/third" {
+		t.Errorf("note = kind %d %q", note.Kind, note.Content)
+	}
+	if proxy := note.Tags.Find("proxy"); proxy == nil || proxy[2] != "rss" || !strings.HasPrefix(proxy[1], feed.URL+"#") {
+		t.Errorf("proxy tag = %v", proxy)
+	}
+}
+
+func TestFeedItemArticle(t *testing.T) {
+	items, err := ParseFeed([]byte(rssFeed))
+	if err != nil {
+		t.Fatalf("ParseFeed() error = %v", err)
+	}
+
+	feed := config.BridgeFeed{URL: "https://blog.example.com/feed.xml", Kind: 30023}
+	kind, content, tags := feedItemEvent(feed, items[0])
+	if kind != nostr.KindArticle {
+		t.Fatalf("kind = %d, want 30023", kind)
+	}
+	if content != "The full *text*.\n\n---\n\nOriginally published at <https://blog.example.com/second>" {
+		t.Errorf("content = %q", content)
+	}
+	if tag := tags.Find("title"); tag == nil || tag[1] != "Second post" {
+		t.Errorf("title tag = %v", tag)
+	}
+	if tag := tags.Find("summary"); tag == nil || tag[1] != "Short summary" {
+		t.Errorf("summary tag = %v", tag)
+	}
+	if tag := tags.Find("published_at"); tag == nil || tag[1] != "1791279000" {
+		t.Errorf("published_at tag = %v", tag)
+	}
+
+	// The d tag is stable, so republishing replaces the article
+	_, _, again := feedItemEvent(feed, items[0])
+	if d := tags.Find("d"); d == nil || again.Find("d")[1] != d[1] {
+		t.Errorf("d tag = %v, not stable", d)
+	}
+}
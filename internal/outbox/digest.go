@@ -0,0 +1,298 @@
+package outbox
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/nbd-wtf/go-nostr"
+	"github.com/nbd-wtf/go-nostr/nip19"
+	"github.com/sandwich/nophr/internal/aggregates"
+)
+
+const (
+	// digestTag marks digest events so the next digest can find the previous one
+	digestTag = "nophr-digest"
+
+	// digestPeriod is the window one digest covers
+	digestPeriod = 7 * 24 * time.Hour
+
+	// digestCheckInterval is how often the scheduler checks whether a digest is due
+	digestCheckInterval = time.Hour
+)
+
+// Digest summarises one week of the owner's activity
+type Digest struct {
+	Since        time.Time
+	Until        time.Time
+	TopPosts     []*aggregates.EnrichedEvent
+	Followers    int
+	NewFollowers int  // Change since the previous digest
+	HasPrevious  bool // Whether a previous digest recorded a follower count
+	ZapSats      int64
+}
+
+// ComposeDigest gathers the owner's top posts by engagement, follower change and
+// zaps received between since and until from stored events and aggregates
+func (p *Publisher) ComposeDigest(ctx context.Context, since, until time.Time) (*Digest, error) {
+	digest := &Digest{Since: since, Until: until}
+	sinceTs, untilTs := nostr.Timestamp(since.Unix()), nostr.Timestamp(until.Unix())
+
+	// Top posts by engagement
+	notes, err := p.storage.QueryEvents(ctx, nostr.Filter{
+		Kinds:   []int{nostr.KindTextNote},
+		Authors: []string{p.pubkey},
+		Since:   &sinceTs,
+		Until:   &untilTs,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to query notes: %w", err)
+	}
+
+	aggMgr := aggregates.NewManager(p.storage, p.config)
+	ids := make([]string, 0, len(notes))
+	for _, note := range notes {
+		ids = append(ids, note.ID)
+	}
+	aggs, err := aggMgr.GetMultipleAggregates(ctx, ids)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load aggregates: %w", err)
+	}
+
+	for _, note := range notes {
+		if agg, ok := aggs[note.ID]; ok && agg.HasInteractions() {
+			digest.TopPosts = append(digest.TopPosts, &aggregates.EnrichedEvent{Event: note, Aggregates: agg})
+		}
+	}
+	sort.Slice(digest.TopPosts, func(i, j int) bool {
+		a, b := digest.TopPosts[i], digest.TopPosts[j]
+		if a.Aggregates.InteractionScore() != b.Aggregates.InteractionScore() {
+			return a.Aggregates.InteractionScore() > b.Aggregates.InteractionScore()
+		}
+		return a.Event.CreatedAt > b.Event.CreatedAt
+	})
+	if limit := p.config.Outbox.Digest.TopPosts; len(digest.TopPosts) > limit {
+		digest.TopPosts = digest.TopPosts[:limit]
+	}
+
+	// Followers, compared with the count recorded on the previous digest
+	followers, err := aggregates.NewQueryHelper(p.storage, p.config, aggMgr).GetFollowers(ctx, 1, 1)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count followers: %w", err)
+	}
+	digest.Followers = followers.TotalItems
+
+	if previous, err := p.lastDigest(ctx); err == nil && previous != nil {
+		if tag := previous.Tags.Find("followers"); tag != nil {
+			if count, err := strconv.Atoi(tag[1]); err == nil {
+				digest.NewFollowers = digest.Followers - count
+				digest.HasPrevious = true
+			}
+		}
+	}
+
+	// Zaps received
+	zaps, err := p.storage.QueryEvents(ctx, nostr.Filter{
+		Kinds: []int{nostr.KindZap},
+		Tags:  nostr.TagMap{"p": []string{p.pubkey}},
+		Since: &sinceTs,
+		Until: &untilTs,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to query zaps: %w", err)
+	}
+	for _, zap := range zaps {
+		digest.ZapSats += aggregates.ZapAmount(zap)
+	}
+
+	return digest, nil
+}
+
+// PublishDigest signs the digest as the configured kind (1 or 30023), stores it and publishes it
+func (p *Publisher) PublishDigest(ctx context.Context, digest *Digest) (*nostr.Event, error) {
+	kind := p.config.Outbox.Digest.Kind
+
+	tags := nostr.Tags{
+		{"t", digestTag},
+		{"followers", strconv.Itoa(digest.Followers)},
+	}
+	if kind == nostr.KindArticle {
+		year, week := digest.Until.ISOWeek()
+		tags = append(tags,
+			nostr.Tag{"d", fmt.Sprintf("%s-%d-W%02d", digestTag, year, week)},
+			nostr.Tag{"title", "Weekly digest: " + digest.period()},
+			nostr.Tag{"published_at", strconv.FormatInt(digest.Until.Unix(), 10)},
+		)
+	}
+
+	event, err := p.signEvent(kind, digest.Content(kind == nostr.KindArticle), tags)
+	if err != nil {
+		return nil, err
+	}
+
+	return p.publish(ctx, event, "digest")
+}
+
+// lastDigest returns the owner's most recent digest event, or nil if none is stored
+func (p *Publisher) lastDigest(ctx context.Context) (*nostr.Event, error) {
+	events, err := p.storage.QueryEvents(ctx, nostr.Filter{
+		Kinds:   []int{nostr.KindTextNote, nostr.KindArticle},
+		Authors: []string{p.pubkey},
+		Tags:    nostr.TagMap{"t": []string{digestTag}},
+		Limit:   1,
+	})
+	if err != nil || len(events) == 0 {
+		return nil, err
+	}
+	return events[0], nil
+}
+
+// period formats the digest window, e.g. "Oct 5 – Oct 12, 2026"
+func (d *Digest) period() string {
+	return fmt.Sprintf("%s – %s", d.Since.UTC().Format("Jan 2"), d.Until.UTC().Format("Jan 2, 2006"))
+}
+
+// Content renders the digest as a plain note, or as Markdown for a long-form article
+func (d *Digest) Content(markdown bool) string {
+	var sb strings.Builder
+
+	heading := func(text string) {
+		if markdown {
+			sb.WriteString("## " + text + "\n\n")
+		} else {
+			sb.WriteString(text + "\n")
+		}
+	}
+
+	if !markdown {
+		sb.WriteString(fmt.Sprintf("Weekly digest: %s\n\n", d.period()))
+	}
+
+	heading("Top posts")
+	if len(d.TopPosts) == 0 {
+		sb.WriteString("No interactions this week.\n")
+	}
+	for i, post := range d.TopPosts {
+		summary := []rune(strings.SplitN(strings.TrimSpace(post.Event.Content), "\n", 2)[0])
+		if len(summary) > 80 {
+			summary = append(summary[:77], []rune("...")...)
+		}
+		sb.WriteString(fmt.Sprintf("%d. %s (%s)\n", i+1, string(summary), engagement(post.Aggregates)))
+		if note, err := nip19.EncodeNote(post.Event.ID); err == nil {
+			sb.WriteString(fmt.Sprintf("   nostr:%s\n", note))
+		}
+	}
+	sb.WriteString("\n")
+
+	heading("Followers")
+	if d.HasPrevious {
+		sb.WriteString(fmt.Sprintf("%d followers (%+d this week)\n\n", d.Followers, d.NewFollowers))
+	} else {
+		sb.WriteString(fmt.Sprintf("%d followers\n\n", d.Followers))
+	}
+
+	heading("Zaps")
+	sb.WriteString(fmt.Sprintf("%s received\n", aggregates.FormatSats(d.ZapSats)))
+
+	return sb.String()
+}
+
+// engagement summarises a post's interactions, e.g. "3 replies, 12 reactions, 2.1K sats"
+func engagement(agg *aggregates.EventAggregates) string {
+	parts := make([]string, 0, 3)
+	if agg.ReplyCount > 0 {
+		parts = append(parts, fmt.Sprintf("%d replies", agg.ReplyCount))
+	}
+	if agg.ReactionTotal > 0 {
+		parts = append(parts, fmt.Sprintf("%d reactions", agg.ReactionTotal))
+	}
+	if agg.ZapSatsTotal > 0 {
+		parts = append(parts, aggregates.FormatSats(agg.ZapSatsTotal))
+	}
+	return strings.Join(parts, ", ")
+}
+
+// DigestScheduler publishes the weekly digest on the configured weekday
+type DigestScheduler struct {
+	publisher *Publisher
+	stopChan  chan struct{}
+}
+
+// NewDigestScheduler creates a digest scheduler
+func NewDigestScheduler(p *Publisher) *DigestScheduler {
+	return &DigestScheduler{
+		publisher: p,
+		stopChan:  make(chan struct{}),
+	}
+}
+
+// Start checks hourly whether a digest is due. The previous digest is read back
+// from storage, so restarts neither skip nor repeat a week.
+func (s *DigestScheduler) Start(ctx context.Context) {
+	go func() {
+		ticker := time.NewTicker(digestCheckInterval)
+		defer ticker.Stop()
+
+		for {
+			s.runIfDue(ctx, time.Now())
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-s.stopChan:
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// Stop stops the scheduler
+func (s *DigestScheduler) Stop() {
+	close(s.stopChan)
+}
+
+// runIfDue publishes a digest covering the past week if one is due at now
+func (s *DigestScheduler) runIfDue(ctx context.Context, now time.Time) {
+	due, err := s.due(ctx, now)
+	if err != nil {
+		fmt.Printf("[OUTBOX] Digest check failed: %v\n", err)
+		return
+	}
+	if !due {
+		return
+	}
+
+	digest, err := s.publisher.ComposeDigest(ctx, now.Add(-digestPeriod), now)
+	if err != nil {
+		fmt.Printf("[OUTBOX] Failed to compose digest: %v\n", err)
+		return
+	}
+	if _, err := s.publisher.PublishDigest(ctx, digest); err != nil {
+		fmt.Printf("[OUTBOX] Digest: %v\n", err)
+	}
+}
+
+// due reports whether now is the publish day and no digest went out in the last six days
+func (s *DigestScheduler) due(ctx context.Context, now time.Time) (bool, error) {
+	day, err := s.publisher.config.Outbox.Digest.PublishDay()
+	if err != nil {
+		return false, err
+	}
+	if now.Weekday() != day {
+		return false, nil
+	}
+
+	last, err := s.publisher.lastDigest(ctx)
+	if err != nil {
+		return false, err
+	}
+	if last == nil {
+		return true, nil
+	}
+
+	return now.Sub(last.CreatedAt.Time()) > digestPeriod-24*time.Hour, nil
+}
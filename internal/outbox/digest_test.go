@@ -0,0 +1,52 @@
+package outbox
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/nbd-wtf/go-nostr"
+	"github.com/sandwich/nophr/internal/aggregates"
+)
+
+func TestDigestContent(t *testing.T) {
+	until := time.Date(2026, time.October, 12, 9, 0, 0, 0, time.UTC)
+	digest := &Digest{
+		Since: until.Add(-digestPeriod),
+		Until: until,
+		TopPosts: []*aggregates.EnrichedEvent{{
+			Event:      &nostr.Event{ID: strings.Repeat("ab", 32), Content: "gopher holes are back\nsecond line"},
+			Aggregates: &aggregates.EventAggregates{ReplyCount: 3, ReactionTotal: 12, ZapSatsTotal: 2100},
+		}},
+		Followers:    42,
+		NewFollowers: 4,
+		HasPrevious:  true,
+		ZapSats:      2100,
+	}
+
+	note := digest.Content(false)
+	for _, want := range []string{
+		"Weekly digest: Oct 5 – Oct 12, 2026",
+		"1. gopher holes are back (3 replies, 12 reactions, 2.1K sats)",
+		"nostr:note1",
+		"42 followers (+4 this week)",
+		"2.1K sats received",
+	} {
+		if !strings.Contains(note, want) {
+			t.Errorf("note content missing %q:\n%s", want, note)
+		}
+	}
+	if strings.Contains(note, "second line") || strings.Contains(note, "## ") {
+		t.Errorf("note content should be plain first lines only:\n%s", note)
+	}
+
+	article := digest.Content(true)
+	if !strings.Contains(article, "## Top posts") || !strings.Contains(article, "## Zaps") {
+		t.Errorf("article content should use Markdown headings:\n%s", article)
+	}
+
+	digest.HasPrevious = false
+	if got := digest.Content(false); !strings.Contains(got, "42 followers\n") || strings.Contains(got, "this week)") {
+		t.Errorf("first digest should not report a follower change:\n%s", got)
+	}
+}
@@ -0,0 +1,107 @@
+package outbox
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// draftExt is the extension of draft files in outbox.draft_dir
+const draftExt = ".md"
+
+// publishedDrafts is the subdirectory of outbox.draft_dir that published
+// drafts are moved into, so they aren't listed or published again
+const publishedDrafts = "published"
+
+// ErrDraftNotFound is returned for a draft name with no file
+var ErrDraftNotFound = errors.New("draft not found")
+
+// Draft is a note waiting in outbox.draft_dir: a Markdown file named by its
+// file name without the .md extension
+type Draft struct {
+	Name     string
+	Content  string
+	Modified time.Time
+}
+
+// ValidDraftName reports whether name can name a draft file: letters,
+// digits, '-', '_' and '.', not starting with '.'
+func ValidDraftName(name string) bool {
+	if name == "" || len(name) > 100 || name[0] == '.' {
+		return false
+	}
+	for _, r := range name {
+		if !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '-' || r == '_' || r == '.') {
+			return false
+		}
+	}
+	return true
+}
+
+// ListDrafts returns the drafts in dir, most recently changed first. A
+// missing directory has no drafts.
+func ListDrafts(dir string) ([]Draft, error) {
+	entries, err := os.ReadDir(dir)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to list drafts: %w", err)
+	}
+
+	var drafts []Draft
+	for _, entry := range entries {
+		name, ok := strings.CutSuffix(entry.Name(), draftExt)
+		if !ok || !entry.Type().IsRegular() || !ValidDraftName(name) {
+			continue
+		}
+		draft, err := ReadDraft(dir, name)
+		if err != nil {
+			return nil, err
+		}
+		drafts = append(drafts, *draft)
+	}
+	sort.SliceStable(drafts, func(i, j int) bool {
+		return drafts[i].Modified.After(drafts[j].Modified)
+	})
+	return drafts, nil
+}
+
+// ReadDraft returns the named draft in dir
+func ReadDraft(dir, name string) (*Draft, error) {
+	if !ValidDraftName(name) {
+		return nil, ErrDraftNotFound
+	}
+	path := filepath.Join(dir, name+draftExt)
+	info, err := os.Stat(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, ErrDraftNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read draft %s: %w", name, err)
+	}
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read draft %s: %w", name, err)
+	}
+	return &Draft{Name: name, Content: string(content), Modified: info.ModTime()}, nil
+}
+
+// ArchiveDraft moves a published draft into dir's published subdirectory
+func ArchiveDraft(dir, name string) error {
+	if !ValidDraftName(name) {
+		return ErrDraftNotFound
+	}
+	archive := filepath.Join(dir, publishedDrafts)
+	if err := os.MkdirAll(archive, 0o755); err != nil {
+		return fmt.Errorf("failed to archive draft %s: %w", name, err)
+	}
+	if err := os.Rename(filepath.Join(dir, name+draftExt), filepath.Join(archive, name+draftExt)); err != nil {
+		return fmt.Errorf("failed to archive draft %s: %w", name, err)
+	}
+	return nil
+}
@@ -0,0 +1,59 @@
+package outbox
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestDrafts(t *testing.T) {
+	dir := t.TempDir()
+	write := func(file, content string, modified time.Time) {
+		path := filepath.Join(dir, file)
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.Chtimes(path, modified, modified); err != nil {
+			t.Fatal(err)
+		}
+	}
+	now := time.Now()
+	write("older.md", "Older draft", now.Add(-time.Hour))
+	write("newer.md", "Newer draft", now)
+	write("notes.txt", "Not a draft", now)
+	write(".hidden.md", "Not a draft", now)
+
+	drafts, err := ListDrafts(dir)
+	if err != nil {
+		t.Fatalf("ListDrafts() error = %v", err)
+	}
+	if len(drafts) != 2 || drafts[0].Name != "newer" || drafts[1].Name != "older" {
+		t.Fatalf("ListDrafts() = %+v, want newer then older", drafts)
+	}
+
+	draft, err := ReadDraft(dir, "older")
+	if err != nil || draft.Content != "Older draft" {
+		t.Errorf("ReadDraft() = %+v, %v", draft, err)
+	}
+	for _, name := range []string{"missing", "../older", ".hidden", ""} {
+		if _, err := ReadDraft(dir, name); !errors.Is(err, ErrDraftNotFound) {
+			t.Errorf("ReadDraft(%q) error = %v, want ErrDraftNotFound", name, err)
+		}
+	}
+
+	if err := ArchiveDraft(dir, "older"); err != nil {
+		t.Fatalf("ArchiveDraft() error = %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, publishedDrafts, "older.md")); err != nil {
+		t.Errorf("archived draft missing: %v", err)
+	}
+	if drafts, _ := ListDrafts(dir); len(drafts) != 1 || drafts[0].Name != "newer" {
+		t.Errorf("ListDrafts() after archive = %+v", drafts)
+	}
+
+	if drafts, err := ListDrafts(filepath.Join(dir, "absent")); err != nil || drafts != nil {
+		t.Errorf("ListDrafts(missing dir) = %v, %v", drafts, err)
+	}
+}
@@ -0,0 +1,132 @@
+package outbox
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"strings"
+	"time"
+
+	"golang.org/x/net/html/charset"
+)
+
+// FeedItem is one entry of an RSS or Atom feed
+type FeedItem struct {
+	ID        string // The guid or id, or the link when the feed has neither
+	Title     string
+	Link      string
+	Summary   string // Description or summary, usually HTML
+	Content   string // Full content, usually HTML; "" when the feed only has summaries
+	Published time.Time
+}
+
+// feedDocument covers the parts of RSS 2.0 and Atom documents the bridge
+// reads. Whichever the root element is, only its fields are filled in.
+type feedDocument struct {
+	Channel struct {
+		Items []rssItem `xml:"item"`
+	} `xml:"channel"`
+	Entries []atomEntry `xml:"entry"`
+}
+
+type rssItem struct {
+	Title       string `xml:"title"`
+	Link        string `xml:"link"`
+	GUID        string `xml:"guid"`
+	Description string `xml:"description"`
+	Encoded     string `xml:"http://purl.org/rss/1.0/modules/content/ encoded"`
+	PubDate     string `xml:"pubDate"`
+}
+
+type atomEntry struct {
+	ID    string `xml:"id"`
+	Title string `xml:"title"`
+	Links []struct {
+		Href string `xml:"href,attr"`
+		Rel  string `xml:"rel,attr"`
+	} `xml:"link"`
+	Summary   string `xml:"summary"`
+	Content   string `xml:"content"`
+	Published string `xml:"published"`
+	Updated   string `xml:"updated"`
+}
+
+// rssDateLayouts are the pubDate formats seen in the wild, RFC 822 first
+var rssDateLayouts = []string{
+	time.RFC1123Z,
+	time.RFC1123,
+	"Mon, 2 Jan 2006 15:04:05 -0700",
+	"Mon, 2 Jan 2006 15:04:05 MST",
+	"2 Jan 2006 15:04:05 -0700",
+	"Mon, 02 Jan 2006 15:04 -0700",
+	time.RFC3339,
+}
+
+// ParseFeed reads the items of an RSS 2.0 or Atom feed, in the order the feed lists them
+func ParseFeed(data []byte) ([]FeedItem, error) {
+	decoder := xml.NewDecoder(bytes.NewReader(data))
+	decoder.CharsetReader = charset.NewReaderLabel
+	decoder.Strict = false
+
+	var doc feedDocument
+	if err := decoder.Decode(&doc); err != nil {
+		return nil, fmt.Errorf("failed to parse feed: %w", err)
+	}
+
+	var items []FeedItem
+	for _, entry := range doc.Channel.Items {
+		item := FeedItem{
+			ID:      strings.TrimSpace(entry.GUID),
+			Title:   strings.TrimSpace(entry.Title),
+			Link:    strings.TrimSpace(entry.Link),
+			Summary: strings.TrimSpace(entry.Description),
+			Content: strings.TrimSpace(entry.Encoded),
+		}
+		item.Published = parseFeedDate(entry.PubDate, rssDateLayouts)
+		items = append(items, item)
+	}
+	for _, entry := range doc.Entries {
+		item := FeedItem{
+			ID:      strings.TrimSpace(entry.ID),
+			Title:   strings.TrimSpace(entry.Title),
+			Summary: strings.TrimSpace(entry.Summary),
+			Content: strings.TrimSpace(entry.Content),
+		}
+		for _, link := range entry.Links {
+			if link.Rel == "" || link.Rel == "alternate" {
+				item.Link = strings.TrimSpace(link.Href)
+				break
+			}
+		}
+		item.Published = parseFeedDate(entry.Published, []string{time.RFC3339})
+		if item.Published.IsZero() {
+			item.Published = parseFeedDate(entry.Updated, []string{time.RFC3339})
+		}
+		items = append(items, item)
+	}
+
+	// Items without an ID are known by their link; those with neither can't be
+	// told apart from one poll to the next
+	kept := items[:0]
+	for _, item := range items {
+		if item.ID == "" {
+			item.ID = item.Link
+		}
+		if item.ID != "" {
+			kept = append(kept, item)
+		}
+	}
+	return kept, nil
+}
+
+// parseFeedDate parses a feed timestamp with the first layout that fits, or
+// returns the zero time
+func parseFeedDate(value string, layouts []string) time.Time {
+	value = strings.TrimSpace(value)
+	for _, layout := range layouts {
+		if t, err := time.Parse(layout, value); err == nil {
+			return t
+		}
+	}
+	return time.Time{}
+}
@@ -0,0 +1,111 @@
+package outbox
+
+import (
+	"testing"
+	"time"
+)
+
+const rssFeed = `<?xml version="1.0" encoding="UTF-8"?>
+<rss version="2.0" xmlns:content="http://purl.org/rss/1.0/modules/content/">
+<channel>
+  <title>My blog</title>
+  <item>
+    <title>Second post</title>
+    <link>https://blog.example.com/second</link>
+    <guid isPermaLink="false">post-2</guid>
+    <description>&lt;p&gt;Short &lt;b&gt;summary&lt;/b&gt;&lt;/p&gt;</description>
+    <content:encoded><![CDATA[<p>The full <em>text</em>.</p>]]></content:encoded>
+    <pubDate>Tue, 06 Oct 2026 09:30:00 +0000</pubDate>
+  </item>
+  <item>
+    <title>First post</title>
+    <link>https://blog.example.com/first</link>
+    <pubDate>Mon, 5 Oct 2026 09:30:00 GMT</pubDate>
+  </item>
+  <item>
+    <title>No way to tell this one apart</title>
+  </item>
+</channel>
+</rss>`
+
+const atomFeed = `<?xml version="1.0" encoding="utf-8"?>
+<feed xmlns="http://www.w3.org/2005/Atom">
+  <title>My blog</title>
+  <entry>
+    <id>tag:blog.example.com,2026:1</id>
+    <title>Atom post</title>
+    <link rel="self" href="https://blog.example.com/feed/1"/>
+    <link rel="alternate" href="https://blog.example.com/atom-post"/>
+    <summary>A summary</summary>
+    <content type="html">&lt;p&gt;Body&lt;/p&gt;</content>
+    <updated>2026-10-07T12:00:00Z</updated>
+  </entry>
+</feed>`
+
+func TestParseFeed(t *testing.T) {
+	items, err := ParseFeed([]byte(rssFeed))
+	if err != nil {
+		t.Fatalf("ParseFeed(rss) error = %v", err)
+	}
+	if len(items) != 2 {
+		t.Fatalf("ParseFeed(rss) = %d items, want 2 (items without guid or link are skipped)", len(items))
+	}
+	second, first := items[0], items[1]
+	if second.ID != "post-2" || second.Title != "Second post" || second.Link != "https://blog.example.com/second" {
+		t.Errorf("first item = %+v", second)
+	}
+	if second.Content != "<p>The full <em>text</em>.</p>" || second.Summary != "<p>Short <b>summary</b></p>" {
+		t.Errorf("content = %q, summary = %q", second.Content, second.Summary)
+	}
+	if !second.Published.Equal(time.Date(2026, 10, 6, 9, 30, 0, 0, time.UTC)) {
+		t.Errorf("Published = %v", second.Published)
+	}
+	if first.ID != "https://blog.example.com/first" || first.Published.IsZero() {
+		t.Errorf("item without guid = %+v, want its link as ID", first)
+	}
+
+	items, err = ParseFeed([]byte(atomFeed))
+	if err != nil {
+		t.Fatalf("ParseFeed(atom) error = %v", err)
+	}
+	if len(items) != 1 {
+		t.Fatalf("ParseFeed(atom) = %d items, want 1", len(items))
+	}
+	entry := items[0]
+	if entry.ID != "tag:blog.example.com,2026:1" || entry.Link != "https://blog.example.com/atom-post" {
+		t.Errorf("entry = %+v, want the alternate link", entry)
+	}
+	if entry.Content != "<p>Body</p>" || entry.Published.IsZero() {
+		t.Errorf("entry content = %q, published = %v", entry.Content, entry.Published)
+	}
+
+	if _, err := ParseFeed([]byte("not a feed")); err == nil {
+		t.Error("ParseFeed(garbage) expected error")
+	}
+}
+
+func TestHTMLToMarkdown(t *testing.T) {
+	source := `<h2>Intro</h2>
+<p>Some <strong>bold</strong> and <em>italic</em> text with a <a href="https://example.com">link</a>.<br>New line.</p>
+<script>alert(1)</script>
+<ul><li>One</li><li>Two <ol><li>Nested</li></ol></li></ul>
+<blockquote><p>Quoted</p><p>Twice</p></blockquote>
+<pre><code>func main() {
+	fmt.Println("hi")
+}</code></pre>
+<p><img src="https://example.com/a.png" alt="A picture"></p>`
+
+	want := "## Intro\n\n" +
+		"Some **bold** and *italic* text with a [link](https://example.com).\nNew line.\n\n" +
+		"- One\n- Two\n\n  1. Nested\n\n" +
+		"> Quoted\n>\n> Twice\n\n" +
+		"```\nfunc main() {\n    fmt.Println(\"hi\")\n}\n```\n\n" +
+		"![A picture](https://example.com/a.png)"
+	if got := HTMLToMarkdown(source); got != want {
+		t.Errorf("HTMLToMarkdown() =\n%s\nwant\n%s", got, want)
+	}
+
+	if got := HTMLToText(`<p>Some <strong>bold</strong> <a href="https://example.com">link</a></p><p>Next</p>`); got != "Some bold link\n\nNext" {
+		t.Errorf("HTMLToText() = %q", got)
+	}
+}
@@ -0,0 +1,228 @@
+package outbox
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+// indent stands in for leading spaces that must survive the whitespace
+// cleanup, in list items and preformatted text, until the output is finished
+const indent = "\x00"
+
+var (
+	spaceRun      = regexp.MustCompile(`[ \t\r\n]+`)
+	lineLeading   = regexp.MustCompile(`\n[ \t]+`)
+	lineTrailing  = regexp.MustCompile(`[ \t]+\n`)
+	extraNewlines = regexp.MustCompile(`\n{3,}`)
+)
+
+// HTMLToMarkdown converts the HTML of a feed item to Markdown for a long-form
+// article: paragraphs, headings, links, emphasis, lists, quotes, code and
+// images. Scripts, styles and embedded frames are dropped.
+func HTMLToMarkdown(source string) string {
+	return convertHTML(source, true)
+}
+
+// HTMLToText converts HTML to plain text, keeping paragraphs and list items
+// on their own lines
+func HTMLToText(source string) string {
+	return convertHTML(source, false)
+}
+
+func convertHTML(source string, markdown bool) string {
+	nodes, err := html.ParseFragment(strings.NewReader(source), &html.Node{Type: html.ElementNode, Data: "div", DataAtom: atom.Div})
+	if err != nil {
+		return strings.TrimSpace(source)
+	}
+
+	r := htmlConverter{markdown: markdown}
+	var sb strings.Builder
+	for _, node := range nodes {
+		sb.WriteString(r.render(node))
+	}
+
+	return strings.ReplaceAll(tidy(sb.String()), indent, " ")
+}
+
+// tidy drops the whitespace around lines and collapses runs of blank lines
+func tidy(text string) string {
+	text = lineLeading.ReplaceAllString(text, "\n")
+	text = lineTrailing.ReplaceAllString(text, "\n")
+	text = extraNewlines.ReplaceAllString(text, "\n\n")
+	return strings.TrimSpace(text)
+}
+
+// prefixLines starts the first line of text with first and every other
+// non-blank line with rest. Blank lines get rest without trailing spaces.
+func prefixLines(text, first, rest string) string {
+	lines := strings.Split(text, "\n")
+	for i, line := range lines {
+		switch {
+		case i == 0:
+			lines[i] = first + line
+		case line == "":
+			lines[i] = strings.TrimRight(rest, " "+indent)
+		default:
+			lines[i] = rest + line
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// htmlConverter renders HTML nodes as Markdown, or as plain text
+type htmlConverter struct {
+	markdown bool
+}
+
+// render converts a node and its children. Blocks are separated by blank lines.
+func (r htmlConverter) render(n *html.Node) string {
+	switch n.Type {
+	case html.TextNode:
+		return spaceRun.ReplaceAllString(n.Data, " ")
+	case html.ElementNode:
+	default:
+		return r.children(n)
+	}
+
+	switch n.DataAtom {
+	case atom.Script, atom.Style, atom.Iframe, atom.Noscript, atom.Template:
+		return ""
+
+	case atom.Br:
+		return "\n"
+
+	case atom.Hr:
+		return r.block("---")
+
+	case atom.H1, atom.H2, atom.H3, atom.H4, atom.H5, atom.H6:
+		text := strings.TrimSpace(r.children(n))
+		if r.markdown {
+			level := int(n.Data[1] - '0')
+			text = strings.Repeat("#", level) + " " + text
+		}
+		return r.block(text)
+
+	case atom.P, atom.Div, atom.Section, atom.Article, atom.Header, atom.Footer,
+		atom.Figure, atom.Figcaption, atom.Table, atom.Tr, atom.Dl, atom.Dt, atom.Dd:
+		return r.block(tidy(r.children(n)))
+
+	case atom.Blockquote:
+		text := tidy(r.children(n))
+		if r.markdown {
+			text = prefixLines(text, "> ", "> ")
+		}
+		return r.block(text)
+
+	case atom.Pre:
+		code := strings.Trim(textContent(n), "\n")
+		code = strings.NewReplacer(" ", indent, "\t", strings.Repeat(indent, 4)).Replace(code)
+		if r.markdown {
+			code = "```\n" + code + "\n```"
+		}
+		return r.block(code)
+
+	case atom.Ul, atom.Ol:
+		var items []string
+		number := 0
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			if c.Type != html.ElementNode || c.DataAtom != atom.Li {
+				continue
+			}
+			number++
+			marker := "- "
+			if n.DataAtom == atom.Ol && r.markdown {
+				marker = strconv.Itoa(number) + ". "
+			}
+			items = append(items, prefixLines(tidy(r.children(c)), marker, strings.Repeat(indent, len(marker))))
+		}
+		return r.block(strings.Join(items, "\n"))
+
+	case atom.A:
+		text := strings.TrimSpace(r.children(n))
+		href := attr(n, "href")
+		if !r.markdown || href == "" || strings.HasPrefix(href, "#") {
+			if text == "" {
+				return href
+			}
+			return text
+		}
+		if text == "" || text == href {
+			return "<" + href + ">"
+		}
+		return "[" + text + "](" + href + ")"
+
+	case atom.Img:
+		src := attr(n, "src")
+		if !r.markdown || src == "" {
+			return attr(n, "alt")
+		}
+		return "![" + attr(n, "alt") + "](" + src + ")"
+
+	case atom.Strong, atom.B:
+		return r.wrap(n, "**")
+
+	case atom.Em, atom.I:
+		return r.wrap(n, "*")
+
+	case atom.Code:
+		if !r.markdown {
+			return textContent(n)
+		}
+		return "`" + textContent(n) + "`"
+	}
+
+	return r.children(n)
+}
+
+// children converts a node's children
+func (r htmlConverter) children(n *html.Node) string {
+	var sb strings.Builder
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		sb.WriteString(r.render(c))
+	}
+	return sb.String()
+}
+
+// block sets text apart from what surrounds it with blank lines
+func (r htmlConverter) block(text string) string {
+	if text == "" {
+		return ""
+	}
+	return "\n\n" + text + "\n\n"
+}
+
+// wrap surrounds inline text with Markdown emphasis markers
+func (r htmlConverter) wrap(n *html.Node, marker string) string {
+	text := r.children(n)
+	trimmed := strings.TrimSpace(text)
+	if !r.markdown || trimmed == "" {
+		return text
+	}
+	return marker + trimmed + marker
+}
+
+// textContent returns the text under a node as it is, for code
+func textContent(n *html.Node) string {
+	if n.Type == html.TextNode {
+		return n.Data
+	}
+	var sb strings.Builder
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		sb.WriteString(textContent(c))
+	}
+	return sb.String()
+}
+
+// attr returns the value of an element's attribute, or ""
+func attr(n *html.Node, name string) string {
+	for _, a := range n.Attr {
+		if a.Key == name {
+			return strings.TrimSpace(a.Val)
+		}
+	}
+	return ""
+}
@@ -0,0 +1,188 @@
+package outbox
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/nbd-wtf/go-nostr"
+	"github.com/nbd-wtf/go-nostr/nip19"
+	"github.com/sandwich/nophr/internal/config"
+	internalnostr "github.com/sandwich/nophr/internal/nostr"
+	"github.com/sandwich/nophr/internal/storage"
+)
+
+// Publisher signs events with the owner's key, stores them locally and
+// publishes them to the owner's outbox relays
+type Publisher struct {
+	config    *config.Config
+	storage   *storage.Storage
+	client    *internalnostr.Client
+	discovery *internalnostr.Discovery
+	secretKey string
+	pubkey    string
+}
+
+// NewPublisher creates a publisher from the NOPHR_NSEC secret key.
+// The key must belong to the configured npub.
+func NewPublisher(cfg *config.Config, st *storage.Storage, client *internalnostr.Client) (*Publisher, error) {
+	secretKey, pubkey, err := decodeKeys(cfg.Identity.Nsec, cfg.Identity.Npub)
+	if err != nil {
+		return nil, err
+	}
+
+	discovery := internalnostr.NewDiscovery(client, st)
+	discovery.SetSelection(&cfg.Discovery)
+
+	return &Publisher{
+		config:    cfg,
+		storage:   st,
+		client:    client,
+		discovery: discovery,
+		secretKey: secretKey,
+		pubkey:    pubkey,
+	}, nil
+}
+
+// decodeKeys decodes an nsec and checks it matches the npub, returning hex keys
+func decodeKeys(nsec, npub string) (string, string, error) {
+	if nsec == "" {
+		return "", "", fmt.Errorf("NOPHR_NSEC is not set")
+	}
+
+	prefix, value, err := nip19.Decode(nsec)
+	if err != nil || prefix != "nsec" {
+		return "", "", fmt.Errorf("NOPHR_NSEC is not a valid nsec")
+	}
+	secretKey := value.(string)
+
+	pubkey, err := nostr.GetPublicKey(secretKey)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to derive public key: %w", err)
+	}
+
+	prefix, value, err = nip19.Decode(npub)
+	if err != nil || prefix != "npub" {
+		return "", "", fmt.Errorf("identity.npub is not a valid npub")
+	}
+	if value.(string) != pubkey {
+		return "", "", fmt.Errorf("NOPHR_NSEC does not belong to identity.npub")
+	}
+
+	return secretKey, pubkey, nil
+}
+
+// Pubkey returns the hex public key events are signed with
+func (p *Publisher) Pubkey() string {
+	return p.pubkey
+}
+
+// PublishNote signs content as a kind 1 note, stores it and publishes it.
+// The note is returned once stored locally, even if no relay accepted it.
+func (p *Publisher) PublishNote(ctx context.Context, content string) (*nostr.Event, error) {
+	if !p.config.Outbox.Publish.Notes {
+		return nil, fmt.Errorf("publishing notes is disabled (outbox.publish.notes)")
+	}
+
+	content = strings.TrimSpace(content)
+	if content == "" {
+		return nil, fmt.Errorf("note is empty")
+	}
+
+	event, err := p.signNote(content)
+	if err != nil {
+		return nil, err
+	}
+
+	return p.publish(ctx, event, "note")
+}
+
+// publish stores a signed event locally and sends it to the owner's outbox relays.
+// The event is returned once stored, even if no relay accepted it; what names
+// the event in errors and logs.
+func (p *Publisher) publish(ctx context.Context, event *nostr.Event, what string) (*nostr.Event, error) {
+	if err := p.storage.StoreEvent(ctx, event); err != nil {
+		return nil, fmt.Errorf("failed to store %s: %w", what, err)
+	}
+
+	relays := p.relays(ctx)
+	if err := p.client.PublishEvent(ctx, relays, event); err != nil {
+		return event, fmt.Errorf("%s %s stored but not published: %w", what, event.ID, err)
+	}
+
+	fmt.Printf("[OUTBOX] Published %s %s to %d relays\n", what, event.ID, len(relays))
+	return event, nil
+}
+
+// signNote builds and signs a kind 1 note
+func (p *Publisher) signNote(content string) (*nostr.Event, error) {
+	return p.signEvent(nostr.KindTextNote, content, nostr.Tags{})
+}
+
+// signEvent builds and signs an event of any kind with the owner's key
+func (p *Publisher) signEvent(kind int, content string, tags nostr.Tags) (*nostr.Event, error) {
+	return p.signEventAt(kind, content, tags, nostr.Now())
+}
+
+// signEventAt builds and signs an event dated createdAt
+func (p *Publisher) signEventAt(kind int, content string, tags nostr.Tags, createdAt nostr.Timestamp) (*nostr.Event, error) {
+	event := &nostr.Event{
+		PubKey:    p.pubkey,
+		CreatedAt: createdAt,
+		Kind:      kind,
+		Tags:      tags,
+		Content:   content,
+	}
+
+	if err := event.Sign(p.secretKey); err != nil {
+		return nil, fmt.Errorf("failed to sign event: %w", err)
+	}
+
+	return event, nil
+}
+
+// ZapRequest signs a kind 9734 zap request for target worth msats.
+// It is handed to the recipient's lightning address, not published.
+func (p *Publisher) ZapRequest(ctx context.Context, target *nostr.Event, msats int64) (*nostr.Event, error) {
+	if !p.config.Outbox.Publish.Zaps {
+		return nil, fmt.Errorf("zapping is disabled (outbox.publish.zaps)")
+	}
+	if msats <= 0 {
+		return nil, fmt.Errorf("zap amount must be positive")
+	}
+
+	return p.signZapRequest(target, msats, p.relays(ctx))
+}
+
+// signZapRequest builds and signs a zap request asking for the receipt on relays
+func (p *Publisher) signZapRequest(target *nostr.Event, msats int64, relays []string) (*nostr.Event, error) {
+	relayTag := append(nostr.Tag{"relays"}, relays...)
+
+	event := &nostr.Event{
+		PubKey:    p.pubkey,
+		CreatedAt: nostr.Now(),
+		Kind:      nostr.KindZapRequest,
+		Tags: nostr.Tags{
+			relayTag,
+			{"amount", strconv.FormatInt(msats, 10)},
+			{"p", target.PubKey},
+			{"e", target.ID},
+		},
+	}
+
+	if err := event.Sign(p.secretKey); err != nil {
+		return nil, fmt.Errorf("failed to sign zap request: %w", err)
+	}
+
+	return event, nil
+}
+
+// relays returns the owner's write relays, falling back to the seed relays
+func (p *Publisher) relays(ctx context.Context) []string {
+	relays, err := p.discovery.GetOutboxRelays(ctx, p.pubkey)
+	if err != nil || len(relays) == 0 {
+		return p.config.Relays.Seeds
+	}
+	return relays
+}
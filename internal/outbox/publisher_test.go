@@ -0,0 +1,99 @@
+package outbox
+
+import (
+	"testing"
+
+	"github.com/nbd-wtf/go-nostr"
+	"github.com/nbd-wtf/go-nostr/nip19"
+)
+
+func TestDecodeKeys(t *testing.T) {
+	secretKey := nostr.GeneratePrivateKey()
+	pubkey, _ := nostr.GetPublicKey(secretKey)
+	nsec, _ := nip19.EncodePrivateKey(secretKey)
+	npub, _ := nip19.EncodePublicKey(pubkey)
+
+	otherPubkey, _ := nostr.GetPublicKey(nostr.GeneratePrivateKey())
+	otherNpub, _ := nip19.EncodePublicKey(otherPubkey)
+
+	gotSecret, gotPubkey, err := decodeKeys(nsec, npub)
+	if err != nil {
+		t.Fatalf("decodeKeys() error = %v", err)
+	}
+	if gotSecret != secretKey || gotPubkey != pubkey {
+		t.Errorf("decodeKeys() returned wrong keys")
+	}
+
+	tests := []struct {
+		name string
+		nsec string
+		npub string
+	}{
+		{"missing nsec", "", npub},
+		{"npub as nsec", npub, npub},
+		{"invalid npub", nsec, "npub1invalid"},
+		{"mismatched npub", nsec, otherNpub},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, _, err := decodeKeys(tt.nsec, tt.npub); err == nil {
+				t.Errorf("decodeKeys() expected error")
+			}
+		})
+	}
+}
+
+func TestSignNote(t *testing.T) {
+	secretKey := nostr.GeneratePrivateKey()
+	pubkey, _ := nostr.GetPublicKey(secretKey)
+
+	p := &Publisher{secretKey: secretKey, pubkey: pubkey}
+
+	event, err := p.signNote("hello from titan")
+	if err != nil {
+		t.Fatalf("signNote() error = %v", err)
+	}
+
+	if event.Kind != nostr.KindTextNote {
+		t.Errorf("Kind = %d, want %d", event.Kind, nostr.KindTextNote)
+	}
+	if event.PubKey != pubkey {
+		t.Errorf("PubKey = %s, want %s", event.PubKey, pubkey)
+	}
+	if ok, err := event.CheckSignature(); err != nil || !ok {
+		t.Errorf("signature does not verify: %v", err)
+	}
+}
+
+func TestSignZapRequest(t *testing.T) {
+	secretKey := nostr.GeneratePrivateKey()
+	pubkey, _ := nostr.GetPublicKey(secretKey)
+
+	p := &Publisher{secretKey: secretKey, pubkey: pubkey}
+	target := &nostr.Event{ID: "abc123", PubKey: "def456"}
+
+	event, err := p.signZapRequest(target, 21000, []string{"wss://relay.example.com"})
+	if err != nil {
+		t.Fatalf("signZapRequest() error = %v", err)
+	}
+
+	if event.Kind != nostr.KindZapRequest {
+		t.Errorf("Kind = %d, want %d", event.Kind, nostr.KindZapRequest)
+	}
+	if tag := event.Tags.Find("amount"); len(tag) < 2 || tag[1] != "21000" {
+		t.Errorf("amount tag = %v, want 21000", tag)
+	}
+	if tag := event.Tags.Find("e"); len(tag) < 2 || tag[1] != target.ID {
+		t.Errorf("e tag = %v, want %s", tag, target.ID)
+	}
+	if tag := event.Tags.Find("p"); len(tag) < 2 || tag[1] != target.PubKey {
+		t.Errorf("p tag = %v, want %s", tag, target.PubKey)
+	}
+	if tag := event.Tags.Find("relays"); len(tag) != 2 || tag[1] != "wss://relay.example.com" {
+		t.Errorf("relays tag = %v", tag)
+	}
+	if ok, err := event.CheckSignature(); err != nil || !ok {
+		t.Errorf("signature does not verify: %v", err)
+	}
+}
@@ -0,0 +1,133 @@
+package pages
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/sandwich/nophr/internal/config"
+)
+
+// Page is a static page served at a fixed path
+type Page struct {
+	Path   string // e.g. "/now"
+	Format string // config.PageFormatMarkdown or config.PageFormatGemtext
+
+	title   string
+	content string
+	file    string
+}
+
+// Title returns the page's home menu entry: the configured title, the
+// source's first heading, or the path
+func (p *Page) Title() string {
+	if p.title != "" {
+		return p.title
+	}
+	if source, err := p.Source(); err == nil {
+		for _, line := range strings.Split(source, "\n") {
+			if heading, ok := strings.CutPrefix(strings.TrimSpace(line), "# "); ok {
+				return strings.TrimSpace(heading)
+			}
+		}
+	}
+	name := strings.TrimPrefix(p.Path, "/")
+	return strings.ToUpper(name[:1]) + name[1:]
+}
+
+// Source returns the page's markdown or gemtext. File pages are read on every
+// call so edits show up without a restart.
+func (p *Page) Source() (string, error) {
+	if p.file == "" {
+		return p.content, nil
+	}
+	data, err := os.ReadFile(p.file)
+	if err != nil {
+		return "", fmt.Errorf("failed to read page %s: %w", p.Path, err)
+	}
+	return string(data), nil
+}
+
+// Set is the static pages from pages and pages_dir
+type Set struct {
+	pages  []*Page
+	byPath map[string]*Page
+}
+
+// Load collects the configured pages. Files in pages_dir are served at
+// /<name without extension>, and entries in pages override them.
+func Load(cfg *config.Config) *Set {
+	s := &Set{byPath: make(map[string]*Page)}
+
+	for _, page := range loadDir(cfg.PagesDir) {
+		s.add(page)
+	}
+	for i := range cfg.Pages {
+		p := &cfg.Pages[i]
+		s.add(&Page{
+			Path:    p.Path,
+			Format:  p.SourceFormat(),
+			title:   p.Title,
+			content: p.Content,
+			file:    p.File,
+		})
+	}
+
+	sort.Slice(s.pages, func(i, j int) bool { return s.pages[i].Path < s.pages[j].Path })
+	return s
+}
+
+// loadDir lists the markdown and gemtext files in a pages directory
+func loadDir(dir string) []*Page {
+	if dir == "" {
+		return nil
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		fmt.Printf("Failed to read pages_dir %s: %v\n", dir, err)
+		return nil
+	}
+
+	var pages []*Page
+	for _, entry := range entries {
+		name := entry.Name()
+		ext := strings.ToLower(filepath.Ext(name))
+		if entry.IsDir() || strings.HasPrefix(name, ".") {
+			continue
+		}
+		switch ext {
+		case ".md", ".markdown", ".gmi", ".gemini":
+		default:
+			continue
+		}
+		pages = append(pages, &Page{
+			Path:   "/" + strings.ToLower(strings.TrimSuffix(name, filepath.Ext(name))),
+			Format: config.PageFormatFor(name),
+			file:   filepath.Join(dir, name),
+		})
+	}
+	return pages
+}
+
+// add registers a page, replacing any page already at its path
+func (s *Set) add(page *Page) {
+	if old, ok := s.byPath[page.Path]; ok {
+		*old = *page
+		return
+	}
+	s.byPath[page.Path] = page
+	s.pages = append(s.pages, page)
+}
+
+// Lookup returns the page served at a path
+func (s *Set) Lookup(path string) (*Page, bool) {
+	page, ok := s.byPath[path]
+	return page, ok
+}
+
+// All returns the pages sorted by path
+func (s *Set) All() []*Page {
+	return s.pages
+}
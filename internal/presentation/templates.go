@@ -0,0 +1,266 @@
+package presentation
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"text/template"
+	"time"
+	"unicode/utf8"
+
+	"github.com/nbd-wtf/go-nostr"
+	"github.com/sandwich/nophr/internal/aggregates"
+	"github.com/sandwich/nophr/internal/config"
+	nostrclient "github.com/sandwich/nophr/internal/nostr"
+)
+
+// Layouts an operator can override with a template
+const (
+	LayoutHome     = "home"
+	LayoutNote     = "note"
+	LayoutProfile  = "profile"
+	LayoutListItem = "list_item"
+)
+
+// PageData is what a page template is executed with. Fields a layout has
+// no use for are nil or empty.
+type PageData struct {
+	Site       SiteData
+	Event      *EventData     // note and list_item
+	Profile    *ProfileData   // profile
+	Aggregates *AggregateData // note and list_item, nil without interactions
+	Links      []Link         // home: menu entries; note and profile: actions
+	Number     int            // list_item: position in the list, from 1
+	Builtin    string         // What nophr renders without the template
+}
+
+// SiteData describes the site, from the site config
+type SiteData struct {
+	Title       string
+	Description string
+	Operator    string
+}
+
+// EventData describes a note or article
+type EventData struct {
+	ID        string
+	Pubkey    string
+	Author    string // Labelled in the display.authors style
+	Kind      int
+	CreatedAt time.Time
+	Content   string // As published
+	Rendered  string // As gemtext or plain text, with nostr: links resolved
+	URL       string // The event's page
+	Tags      [][]string
+}
+
+// ProfileData describes an author's kind 0 profile
+type ProfileData struct {
+	Pubkey      string
+	Name        string
+	DisplayName string
+	About       string
+	Website     string
+	NIP05       string
+	Lightning   string
+	Picture     string
+	Banner      string
+}
+
+// AggregateData counts an event's interactions
+type AggregateData struct {
+	Replies   int
+	Reactions int
+	Zaps      int64          // Sats
+	ByEmoji   map[string]int // Reactions per emoji
+	Summary   string         // As the built-in layouts show them
+}
+
+// Link is a menu entry or action
+type Link struct {
+	Title string
+	URL   string
+}
+
+// NewEventData describes event for a template
+func NewEventData(event *nostr.Event, author, rendered, url string) *EventData {
+	tags := make([][]string, len(event.Tags))
+	for i, tag := range event.Tags {
+		tags[i] = tag
+	}
+	return &EventData{
+		ID:        event.ID,
+		Pubkey:    event.PubKey,
+		Author:    author,
+		Kind:      event.Kind,
+		CreatedAt: event.CreatedAt.Time(),
+		Content:   event.Content,
+		Rendered:  rendered,
+		URL:       url,
+		Tags:      tags,
+	}
+}
+
+// NewProfileData describes a profile for a template; nil metadata leaves
+// everything but the pubkey empty
+func NewProfileData(pubkey string, profile *nostrclient.ProfileMetadata) *ProfileData {
+	data := &ProfileData{Pubkey: pubkey}
+	if profile != nil {
+		data.Name = profile.Name
+		data.DisplayName = profile.DisplayName
+		data.About = profile.About
+		data.Website = profile.Website
+		data.NIP05 = profile.NIP05
+		data.Lightning = profile.GetLightningAddress()
+		data.Picture = profile.Picture
+		data.Banner = profile.Banner
+	}
+	return data
+}
+
+// NewAggregateData describes agg for a template, with summary as the
+// built-in layouts show the counts
+func NewAggregateData(agg *aggregates.EventAggregates, summary string) *AggregateData {
+	return &AggregateData{
+		Replies:   agg.ReplyCount,
+		Reactions: agg.ReactionTotal,
+		Zaps:      agg.ZapSatsTotal,
+		ByEmoji:   agg.ReactionCounts,
+		Summary:   summary,
+	}
+}
+
+// templateFuncs are available to every template
+var templateFuncs = template.FuncMap{
+	// truncate cuts s to n characters, ending in "..." when it was longer
+	"truncate": func(n int, s string) string {
+		if utf8.RuneCountInString(s) <= n {
+			return s
+		}
+		runes := []rune(s)
+		return string(runes[:max(n-3, 0)]) + "..."
+	},
+	// firstLine returns the first non-blank line of s
+	"firstLine": func(s string) string {
+		for _, line := range strings.Split(s, "\n") {
+			if line = strings.TrimSpace(line); line != "" {
+				return line
+			}
+		}
+		return ""
+	},
+	// date formats t as 2006-01-02 15:04, in local time
+	"date": func(t time.Time) string {
+		return t.Format("2006-01-02 15:04")
+	},
+	"repeat": strings.Repeat,
+}
+
+// Templates renders one protocol's page templates. Like headers and
+// footers, files are read again after five minutes, so edits show up
+// without a restart.
+type Templates struct {
+	files map[string]string // Layout to template file
+	site  SiteData
+	cache map[string]*cachedTemplate
+	mu    sync.Mutex
+}
+
+// cachedTemplate is a parsed template file
+type cachedTemplate struct {
+	tmpl     *template.Template
+	loadedAt time.Time
+}
+
+// NewTemplates creates the templates configured for protocol ("gopher" or
+// "gemini")
+func NewTemplates(cfg *config.Config, protocol string) *Templates {
+	set := cfg.Presentation.Templates.Gopher
+	if protocol == "gemini" {
+		set = cfg.Presentation.Templates.Gemini
+	}
+	return &Templates{
+		files: set.Files(),
+		site: SiteData{
+			Title:       cfg.Site.Title,
+			Description: cfg.Site.Description,
+			Operator:    cfg.Site.Operator,
+		},
+		cache: make(map[string]*cachedTemplate),
+	}
+}
+
+// Has reports whether layout has a template
+func (t *Templates) Has(layout string) bool {
+	_, ok := t.files[layout]
+	return ok
+}
+
+// Render executes layout's template with data. It returns false when the
+// layout has no template or the template fails, so the caller falls back to
+// the built-in layout; failures are printed.
+func (t *Templates) Render(layout string, data PageData) (string, bool) {
+	if !t.Has(layout) {
+		return "", false
+	}
+	tmpl, err := t.load(layout)
+	if err != nil {
+		fmt.Printf("Template %s: %v\n", layout, err)
+		return "", false
+	}
+
+	data.Site = t.site
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		fmt.Printf("Template %s: %v\n", layout, err)
+		return "", false
+	}
+	return buf.String(), true
+}
+
+// load returns layout's parsed template, reading the file if it isn't cached
+func (t *Templates) load(layout string) (*template.Template, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if cached, ok := t.cache[layout]; ok && time.Since(cached.loadedAt) < 5*time.Minute {
+		return cached.tmpl, nil
+	}
+	tmpl, err := parseTemplate(layout, t.files[layout])
+	if err != nil {
+		return nil, err
+	}
+	t.cache[layout] = &cachedTemplate{tmpl: tmpl, loadedAt: time.Now()}
+	return tmpl, nil
+}
+
+// parseTemplate reads and parses a template file
+func parseTemplate(layout, path string) (*template.Template, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read template %s: %w", path, err)
+	}
+	tmpl, err := template.New(layout).Funcs(templateFuncs).Parse(string(data))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse template %s: %w", path, err)
+	}
+	return tmpl, nil
+}
+
+// CheckTemplates reads and parses every configured template, returning the
+// first one that fails
+func CheckTemplates(cfg *config.Config) error {
+	for protocol, set := range map[string]config.TemplateSet{
+		"gopher": cfg.Presentation.Templates.Gopher,
+		"gemini": cfg.Presentation.Templates.Gemini,
+	} {
+		for layout, path := range set.Files() {
+			if _, err := parseTemplate(layout, path); err != nil {
+				return fmt.Errorf("%s %s: %w", protocol, layout, err)
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,46 @@
+package presentation
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/nbd-wtf/go-nostr"
+	"github.com/sandwich/nophr/internal/config"
+)
+
+func TestTemplates(t *testing.T) {
+	dir := t.TempDir()
+	note := filepath.Join(dir, "note.txt")
+	os.WriteFile(note, []byte("{{.Site.Title}}: {{truncate 8 .Event.Content}}{{with .Aggregates}} ({{.Summary}}){{end}}\n{{.Builtin}}"), 0644)
+
+	cfg := config.Default()
+	cfg.Site.Title = "Site"
+	cfg.Presentation.Templates.Gemini.Note = note
+
+	templates := NewTemplates(cfg, "gemini")
+	if !templates.Has(LayoutNote) || templates.Has(LayoutHome) {
+		t.Fatal("expected only the note layout to have a template")
+	}
+	if NewTemplates(cfg, "gopher").Has(LayoutNote) {
+		t.Error("expected gemini templates not to apply to gopher")
+	}
+
+	event := &nostr.Event{Content: "héllo wörld"}
+	got, ok := templates.Render(LayoutNote, PageData{Event: NewEventData(event, "", "", ""), Builtin: "built-in"})
+	if !ok || got != "Site: héllo...\nbuilt-in" {
+		t.Errorf("Render() = %q, %v", got, ok)
+	}
+	if _, ok := templates.Render(LayoutHome, PageData{}); ok {
+		t.Error("expected Render to report a layout without a template")
+	}
+
+	if err := CheckTemplates(cfg); err != nil {
+		t.Errorf("CheckTemplates() error = %v", err)
+	}
+	os.WriteFile(note, []byte("{{.Event.Content"), 0644)
+	if err := CheckTemplates(cfg); err == nil || !strings.Contains(err.Error(), "gemini note") {
+		t.Errorf("CheckTemplates() error = %v, want a parse error for the gemini note", err)
+	}
+}
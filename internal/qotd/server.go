@@ -0,0 +1,179 @@
+package qotd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/sandwich/nophr/internal/aggregates"
+	"github.com/sandwich/nophr/internal/config"
+	"github.com/sandwich/nophr/internal/digest"
+	"github.com/sandwich/nophr/internal/idle"
+	"github.com/sandwich/nophr/internal/security"
+	"github.com/sandwich/nophr/internal/storage"
+)
+
+// Server implements a Quote of the Day server (RFC 865). Every TCP connection
+// and UDP datagram is answered with a short summary of the daily digest.
+type Server struct {
+	config      *config.QOTDProtocol
+	fullConfig  *config.Config
+	digests     *digest.Builder
+	rateLimiter *security.ClientLimiter
+	idle        *idle.Monitor
+
+	listener   net.Listener
+	packetConn net.PacketConn
+	wg         sync.WaitGroup
+	ctx        context.Context
+	cancel     context.CancelFunc
+}
+
+// New creates a new QOTD server
+func New(cfg *config.QOTDProtocol, fullCfg *config.Config, st *storage.Storage, aggMgr *aggregates.Manager) *Server {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	return &Server{
+		config:     cfg,
+		fullConfig: fullCfg,
+		digests:    digest.NewBuilder(fullCfg, st, aggregates.NewQueryHelper(st, fullCfg, aggMgr)),
+		ctx:        ctx,
+		cancel:     cancel,
+	}
+}
+
+// Start starts the TCP and UDP listeners
+func (s *Server) Start() error {
+	addr := fmt.Sprintf("%s:%d", s.config.Bind, s.config.Port)
+
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return fmt.Errorf("failed to start QOTD server: %w", err)
+	}
+
+	packetConn, err := net.ListenPacket("udp", addr)
+	if err != nil {
+		listener.Close()
+		return fmt.Errorf("failed to start QOTD server: %w", err)
+	}
+
+	s.listener = listener
+	s.packetConn = packetConn
+	fmt.Printf("QOTD server listening on %s (tcp, udp)\n", addr)
+
+	s.wg.Add(2)
+	go s.acceptConnections()
+	go s.servePackets()
+
+	return nil
+}
+
+// Stop stops the QOTD server
+func (s *Server) Stop() error {
+	s.cancel()
+
+	if s.listener != nil {
+		s.listener.Close()
+	}
+	if s.packetConn != nil {
+		s.packetConn.Close()
+	}
+
+	s.wg.Wait()
+	return nil
+}
+
+// acceptConnections answers each TCP connection with the quote and closes it,
+// ignoring anything the client sends
+func (s *Server) acceptConnections() {
+	defer s.wg.Done()
+
+	for {
+		conn, err := s.listener.Accept()
+		if err != nil {
+			select {
+			case <-s.ctx.Done():
+				return
+			default:
+				fmt.Printf("Accept error: %v\n", err)
+				continue
+			}
+		}
+
+		s.wg.Add(1)
+		go func() {
+			defer s.wg.Done()
+			defer conn.Close()
+
+			s.idle.Touch()
+			if !s.checkRateLimit(conn.RemoteAddr()) {
+				return
+			}
+			conn.SetWriteDeadline(time.Now().Add(10 * time.Second))
+			conn.Write([]byte(s.Quote()))
+		}()
+	}
+}
+
+// servePackets answers each UDP datagram with the quote in one datagram
+func (s *Server) servePackets() {
+	defer s.wg.Done()
+
+	buf := make([]byte, 512)
+	for {
+		_, addr, err := s.packetConn.ReadFrom(buf)
+		if err != nil {
+			if s.ctx.Err() != nil || errors.Is(err, net.ErrClosed) {
+				return
+			}
+			fmt.Printf("QOTD read error: %v\n", err)
+			continue
+		}
+
+		// UDP sources are spoofable, so the limiter also keeps the listener
+		// from being used to reflect traffic at a victim
+		s.idle.Touch()
+		if !s.checkRateLimit(addr) {
+			continue
+		}
+		s.packetConn.WriteTo([]byte(s.Quote()), addr)
+	}
+}
+
+// Quote returns the current digest as a quote, or the site's title and
+// description if the digest cannot be built
+func (s *Server) Quote() string {
+	d, err := s.digests.Current(s.ctx, time.Now())
+	if err != nil {
+		fmt.Printf("QOTD digest failed: %v\n", err)
+		return fmt.Sprintf("%s\r\n%s\r\n", s.fullConfig.Site.Title, s.fullConfig.Site.Description)
+	}
+	return d.Quote()
+}
+
+// checkRateLimit reports whether the client may be served
+func (s *Server) checkRateLimit(addr net.Addr) bool {
+	if s.rateLimiter == nil {
+		return true
+	}
+
+	ip := security.ClientIP(addr)
+	allowed, _ := s.rateLimiter.Check(ip)
+	if !allowed {
+		fmt.Printf("QOTD rate limit exceeded for %s\n", ip)
+	}
+	return allowed
+}
+
+// SetRateLimiter sets the per-IP rate limiter (nil disables rate limiting)
+func (s *Server) SetRateLimiter(rl *security.ClientLimiter) {
+	s.rateLimiter = rl
+}
+
+// SetIdleMonitor sets the monitor told about each request (nil disables idle shedding)
+func (s *Server) SetIdleMonitor(m *idle.Monitor) {
+	s.idle = m
+}
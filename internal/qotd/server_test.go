@@ -0,0 +1,92 @@
+package qotd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/nbd-wtf/go-nostr"
+	"github.com/nbd-wtf/go-nostr/nip19"
+	"github.com/sandwich/nophr/internal/aggregates"
+	"github.com/sandwich/nophr/internal/config"
+	"github.com/sandwich/nophr/internal/storage"
+)
+
+func TestQOTD(t *testing.T) {
+	ctx := context.Background()
+	cfg := config.Default()
+	cfg.Storage = config.Storage{Driver: "sqlite", SQLitePath: filepath.Join(t.TempDir(), "test.db")}
+
+	sk := nostr.GeneratePrivateKey()
+	pubkey, _ := nostr.GetPublicKey(sk)
+	cfg.Identity.Npub, _ = nip19.EncodePublicKey(pubkey)
+
+	st, err := storage.New(ctx, &cfg.Storage)
+	if err != nil {
+		t.Fatalf("Failed to create storage: %v", err)
+	}
+	defer st.Close()
+
+	// A note from an hour before the current digest's cutoff is in its day
+	note := &nostr.Event{CreatedAt: nostr.Timestamp(cfg.Display.Digest.LatestRun(time.Now()).Add(-time.Hour).Unix()), Kind: 1, Content: "quotable"}
+	note.Sign(sk)
+	if err := st.StoreEvent(ctx, note); err != nil {
+		t.Fatalf("Failed to store event: %v", err)
+	}
+
+	qotdCfg := &config.QOTDProtocol{
+		Enabled: true,
+		Port:    17017, // Use non-standard port for testing
+		Bind:    "localhost",
+	}
+	server := New(qotdCfg, cfg, st, aggregates.NewManager(st, cfg))
+	if err := server.Start(); err != nil {
+		t.Fatalf("Failed to start server: %v", err)
+	}
+	defer server.Stop()
+
+	addr := fmt.Sprintf("localhost:%d", qotdCfg.Port)
+
+	conn, err := net.DialTimeout("tcp", addr, 2*time.Second)
+	if err != nil {
+		t.Fatalf("Failed to connect: %v", err)
+	}
+	defer conn.Close()
+	conn.SetDeadline(time.Now().Add(5 * time.Second))
+
+	// The server sends the quote and closes without waiting for input
+	quote, err := io.ReadAll(conn)
+	if err != nil {
+		t.Fatalf("Failed to read quote: %v", err)
+	}
+	if !strings.HasPrefix(string(quote), "Daily digest: ") || !strings.Contains(string(quote), "New posts: 1\r\n") {
+		t.Errorf("Unexpected TCP quote: %q", quote)
+	}
+	if len(quote) > 512 {
+		t.Errorf("Quote is %d bytes, RFC 865 allows 512", len(quote))
+	}
+
+	udp, err := net.Dial("udp", addr)
+	if err != nil {
+		t.Fatalf("Failed to dial UDP: %v", err)
+	}
+	defer udp.Close()
+	udp.SetDeadline(time.Now().Add(5 * time.Second))
+
+	if _, err := udp.Write([]byte("\n")); err != nil {
+		t.Fatalf("Failed to send datagram: %v", err)
+	}
+	buf := make([]byte, 1024)
+	n, err := udp.Read(buf)
+	if err != nil {
+		t.Fatalf("Failed to read datagram: %v", err)
+	}
+	if string(buf[:n]) != string(quote) {
+		t.Errorf("UDP quote = %q, want the TCP quote %q", buf[:n], quote)
+	}
+}
@@ -0,0 +1,78 @@
+package qr
+
+import (
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestReedSolomon(t *testing.T) {
+	// "HELLO WORLD" at version 1-M, from the worked example of the standard
+	data := []byte{32, 91, 11, 120, 209, 114, 220, 77, 67, 64, 236, 17, 236, 17, 236, 17}
+	want := []byte{196, 35, 39, 119, 235, 215, 231, 226, 93, 23}
+	if got := rsRemainder(data, rsDivisor(10)); !slices.Equal(got, want) {
+		t.Errorf("rsRemainder() = %v, want %v", got, want)
+	}
+}
+
+func TestFormatAndVersionBits(t *testing.T) {
+	if got := formatBits(0); got != 0x77C4 {
+		t.Errorf("formatBits(0) = %#x, want 0x77c4", got)
+	}
+	if got := versionBits(7); got != 0x07C94 {
+		t.Errorf("versionBits(7) = %#x, want 0x7c94", got)
+	}
+	if got := alignmentPositions(7); !slices.Equal(got, []int{6, 22, 38}) {
+		t.Errorf("alignmentPositions(7) = %v", got)
+	}
+	if got := alignmentPositions(32); !slices.Equal(got, []int{6, 34, 60, 86, 112, 138}) {
+		t.Errorf("alignmentPositions(32) = %v", got)
+	}
+}
+
+func TestEncode(t *testing.T) {
+	tests := []struct {
+		length  int
+		version int
+	}{
+		{17, 1},
+		{18, 2},
+		{134, 6},
+		{135, 7},
+		{2953, 40},
+	}
+	for _, tt := range tests {
+		code, err := Encode([]byte(strings.Repeat("a", tt.length)))
+		if err != nil {
+			t.Fatalf("Encode(%d bytes) error = %v", tt.length, err)
+		}
+		if code.Version != tt.version || code.Size != tt.version*4+17 {
+			t.Errorf("Encode(%d bytes) = version %d size %d, want version %d", tt.length, code.Version, code.Size, tt.version)
+		}
+	}
+	if _, err := Encode(make([]byte, 2954)); err == nil {
+		t.Error("Expected 2954 bytes not to fit")
+	}
+
+	code, _ := Encode([]byte("nostr:note1"))
+	// Finder patterns in three corners, with their light separators
+	for _, corner := range [][2]int{{0, 0}, {code.Size - 7, 0}, {0, code.Size - 7}} {
+		if !code.Dark(corner[0], corner[1]) || !code.Dark(corner[0]+3, corner[1]+3) || code.Dark(corner[0]+1, corner[1]+1) {
+			t.Errorf("Expected a finder pattern at %v", corner)
+		}
+	}
+	if code.Dark(7, 7) || !code.Dark(8, code.Size-8) {
+		t.Error("Expected a light separator corner and the dark module")
+	}
+
+	lines := strings.Split(strings.TrimSuffix(code.HalfBlocks(), "\n"), "\n")
+	if len(lines) != (code.Size+2*QuietZone+1)/2 {
+		t.Errorf("HalfBlocks() has %d lines for size %d", len(lines), code.Size)
+	}
+	if width := len([]rune(lines[0])); width != code.Size+2*QuietZone {
+		t.Errorf("HalfBlocks() is %d wide for size %d", width, code.Size)
+	}
+	if lines[0] != strings.Repeat("█", code.Size+2*QuietZone) {
+		t.Errorf("Expected the quiet zone on top, got %q", lines[0])
+	}
+}
@@ -0,0 +1,82 @@
+package relay
+
+import (
+	"context"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/nbd-wtf/go-nostr"
+	"github.com/sandwich/nophr/internal/config"
+	"github.com/sandwich/nophr/internal/storage"
+	nophrsync "github.com/sandwich/nophr/internal/sync"
+)
+
+// scopeRefresh is how long the set of authors in scope is reused before it is
+// recomputed from the social graph
+const scopeRefresh = time.Minute
+
+// policy decides which events the relay serves and accepts, following the
+// sync scope: authors in scope, minus the denylist, plus events addressed to
+// the owner when mentions or threads are synced
+type policy struct {
+	scope    *config.SyncScope
+	graph    *nophrsync.Graph
+	ownerHex string
+
+	mu       sync.Mutex
+	authors  map[string]bool
+	loadedAt time.Time
+}
+
+// newPolicy creates a policy for the owner's sync scope
+func newPolicy(st *storage.Storage, scope *config.SyncScope, ownerHex string) *policy {
+	return &policy{
+		scope:    scope,
+		graph:    nophrsync.NewGraph(st, scope),
+		ownerHex: ownerHex,
+	}
+}
+
+// allows reports whether an event is in scope
+func (p *policy) allows(ctx context.Context, event *nostr.Event) bool {
+	if slices.Contains(p.scope.DenylistPubkeys, event.PubKey) {
+		return false
+	}
+
+	authors, err := p.authorsInScope(ctx)
+	if err != nil {
+		return false
+	}
+	if authors[event.PubKey] {
+		return true
+	}
+
+	// Replies, reactions and zaps addressed to the owner are synced from outside the scope
+	if p.scope.IncludeDirectMentions || p.scope.IncludeThreadsOfMine {
+		return event.Tags.FindWithValue("p", p.ownerHex) != nil
+	}
+	return false
+}
+
+// authorsInScope returns the authors in sync scope, recomputing them at most every scopeRefresh
+func (p *policy) authorsInScope(ctx context.Context) (map[string]bool, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.authors != nil && time.Since(p.loadedAt) < scopeRefresh {
+		return p.authors, nil
+	}
+
+	pubkeys, err := p.graph.GetAuthorsInScope(ctx, p.ownerHex)
+	if err != nil {
+		return nil, err
+	}
+
+	p.authors = make(map[string]bool, len(pubkeys))
+	for _, pubkey := range pubkeys {
+		p.authors[pubkey] = true
+	}
+	p.loadedAt = time.Now()
+	return p.authors, nil
+}
@@ -0,0 +1,181 @@
+package relay
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/fiatjaf/khatru"
+	"github.com/nbd-wtf/go-nostr"
+	"github.com/nbd-wtf/go-nostr/nip19"
+	"github.com/sandwich/nophr/internal/aggregates"
+	"github.com/sandwich/nophr/internal/config"
+	"github.com/sandwich/nophr/internal/idle"
+	"github.com/sandwich/nophr/internal/security"
+	"github.com/sandwich/nophr/internal/storage"
+)
+
+// Server implements a NIP-01 relay over WebSocket that serves the mirrored
+// events to other Nostr clients. It is read-only unless writes are enabled.
+type Server struct {
+	config      *config.RelayProtocol
+	fullConfig  *config.Config
+	storage     *storage.Storage
+	aggMgr      *aggregates.Manager
+	policy      *policy
+	relay       *khatru.Relay
+	rateLimiter *security.ClientLimiter
+	idle        *idle.Monitor
+	ingest      func(*nostr.Event) error
+}
+
+// New creates a new relay server
+func New(cfg *config.RelayProtocol, fullCfg *config.Config, st *storage.Storage, aggMgr *aggregates.Manager) *Server {
+	var ownerHex string
+	if _, hex, err := nip19.Decode(fullCfg.Identity.Npub); err == nil {
+		ownerHex, _ = hex.(string)
+	}
+
+	s := &Server{
+		config:     cfg,
+		fullConfig: fullCfg,
+		storage:    st,
+		aggMgr:     aggMgr,
+		policy:     newPolicy(st, &fullCfg.Sync.Scope, ownerHex),
+		relay:      khatru.NewRelay(),
+	}
+
+	s.relay.Info.Name = fullCfg.Site.Title
+	s.relay.Info.Description = fullCfg.Site.Description
+	s.relay.Info.PubKey = ownerHex
+	s.relay.Info.Software = "https://github.com/sandwich/nophr"
+	s.relay.Info.SupportedNIPs = []any{1, 11}
+
+	s.relay.RejectConnection = append(s.relay.RejectConnection, s.rejectConnection)
+	s.relay.QueryEvents = append(s.relay.QueryEvents, s.queryEvents)
+	s.relay.RejectEvent = append(s.relay.RejectEvent, s.rejectEvent)
+	s.relay.StoreEvent = append(s.relay.StoreEvent, s.storeEvent)
+	s.relay.ReplaceEvent = append(s.relay.ReplaceEvent, s.storeEvent)
+
+	return s
+}
+
+// Start starts the relay's HTTP listener
+func (s *Server) Start() error {
+	started := make(chan bool)
+	errs := make(chan error, 1)
+	go func() {
+		errs <- s.relay.Start(s.config.Bind, s.config.Port, started)
+	}()
+
+	select {
+	case <-started:
+	case err := <-errs:
+		return fmt.Errorf("failed to start relay: %w", err)
+	}
+
+	mode := "read-only"
+	if s.config.AllowWrites {
+		mode = "accepting writes"
+	}
+	fmt.Printf("Relay listening on ws://%s:%d (%s)\n", s.config.Bind, s.config.Port, mode)
+	return nil
+}
+
+// Stop closes the listener and all client connections
+func (s *Server) Stop() error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	s.relay.Shutdown(ctx)
+	return nil
+}
+
+// queryEvents answers REQs from storage, leaving out events outside the sync scope
+func (s *Server) queryEvents(ctx context.Context, filter nostr.Filter) (chan *nostr.Event, error) {
+	events, err := s.storage.QueryEvents(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	ch := make(chan *nostr.Event)
+	go func() {
+		defer close(ch)
+		for _, event := range events {
+			if !s.policy.allows(ctx, event) {
+				continue
+			}
+			select {
+			case ch <- event:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return ch, nil
+}
+
+// rejectEvent refuses EVENT messages unless writes are allowed, and then only
+// from authors the sync scope would have mirrored anyway
+func (s *Server) rejectEvent(ctx context.Context, event *nostr.Event) (bool, string) {
+	if !s.config.AllowWrites {
+		return true, "blocked: this relay is read-only"
+	}
+	if !s.policy.allows(ctx, event) {
+		return true, "blocked: author is not in this relay's scope"
+	}
+	return false, ""
+}
+
+// storeEvent saves an accepted event through the sync pipeline when one is
+// set, so it is aggregated and indexed like a synced event
+func (s *Server) storeEvent(ctx context.Context, event *nostr.Event) error {
+	if s.ingest != nil {
+		return s.ingest(event)
+	}
+
+	if err := s.storage.StoreEvent(ctx, event); err != nil {
+		return err
+	}
+	if s.aggMgr != nil {
+		return s.aggMgr.ProcessEvent(ctx, event)
+	}
+	return nil
+}
+
+// rejectConnection applies the per-IP rate limit to new connections, which
+// also count as requests for idle shedding
+func (s *Server) rejectConnection(r *http.Request) bool {
+	s.idle.Touch()
+	if s.rateLimiter == nil {
+		return false
+	}
+
+	ip, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		ip = r.RemoteAddr
+	}
+	allowed, _ := s.rateLimiter.Check(ip)
+	if !allowed {
+		fmt.Printf("Relay rate limit exceeded for %s\n", ip)
+	}
+	return !allowed
+}
+
+// SetRateLimiter sets the per-IP rate limiter (nil disables rate limiting)
+func (s *Server) SetRateLimiter(rl *security.ClientLimiter) {
+	s.rateLimiter = rl
+}
+
+// SetIdleMonitor sets the monitor told about each request (nil disables idle shedding)
+func (s *Server) SetIdleMonitor(m *idle.Monitor) {
+	s.idle = m
+}
+
+// SetIngester sets the function that stores published events when writes are
+// allowed, normally the sync engine's Ingest
+func (s *Server) SetIngester(fn func(*nostr.Event) error) {
+	s.ingest = fn
+}
@@ -0,0 +1,105 @@
+package relay
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/nbd-wtf/go-nostr"
+	"github.com/nbd-wtf/go-nostr/nip19"
+	"github.com/sandwich/nophr/internal/aggregates"
+	"github.com/sandwich/nophr/internal/config"
+	"github.com/sandwich/nophr/internal/storage"
+)
+
+func TestRelay(t *testing.T) {
+	ctx := context.Background()
+	cfg := config.Default()
+	cfg.Storage = config.Storage{Driver: "sqlite", SQLitePath: filepath.Join(t.TempDir(), "test.db")}
+
+	ownerSK := nostr.GeneratePrivateKey()
+	owner, _ := nostr.GetPublicKey(ownerSK)
+	cfg.Identity.Npub, _ = nip19.EncodePublicKey(owner)
+
+	strangerSK := nostr.GeneratePrivateKey()
+	deniedSK := nostr.GeneratePrivateKey()
+	denied, _ := nostr.GetPublicKey(deniedSK)
+
+	cfg.Sync.Scope.Mode = "self"
+	cfg.Sync.Scope.IncludeDirectMentions = true
+	cfg.Sync.Scope.DenylistPubkeys = []string{denied}
+
+	st, err := storage.New(ctx, &cfg.Storage)
+	if err != nil {
+		t.Fatalf("Failed to create storage: %v", err)
+	}
+	defer st.Close()
+
+	store := func(sk, content string, tags nostr.Tags) *nostr.Event {
+		event := &nostr.Event{CreatedAt: nostr.Now(), Kind: nostr.KindTextNote, Content: content, Tags: tags}
+		event.Sign(sk)
+		if err := st.StoreEvent(ctx, event); err != nil {
+			t.Fatalf("Failed to store event: %v", err)
+		}
+		return event
+	}
+	mine := store(ownerSK, "mine", nil)
+	mention := store(strangerSK, "reply", nostr.Tags{{"p", owner}})
+	store(strangerSK, "unrelated", nil)
+	store(deniedSK, "denied reply", nostr.Tags{{"p", owner}})
+
+	relayCfg := &config.RelayProtocol{
+		Enabled: true,
+		Port:    17777, // Use non-standard port for testing
+		Bind:    "localhost",
+	}
+	server := New(relayCfg, cfg, st, aggregates.NewManager(st, cfg))
+	if err := server.Start(); err != nil {
+		t.Fatalf("Failed to start server: %v", err)
+	}
+	defer server.Stop()
+
+	connectCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+	client, err := nostr.RelayConnect(connectCtx, "ws://localhost:17777")
+	if err != nil {
+		t.Fatalf("Failed to connect: %v", err)
+	}
+	defer client.Close()
+
+	// Only events in the sync scope are served
+	events, err := client.QuerySync(connectCtx, nostr.Filter{Kinds: []int{nostr.KindTextNote}})
+	if err != nil {
+		t.Fatalf("QuerySync() error = %v", err)
+	}
+	served := make(map[string]bool)
+	for _, event := range events {
+		served[event.ID] = true
+	}
+	if len(events) != 2 || !served[mine.ID] || !served[mention.ID] {
+		t.Errorf("Expected the owner's note and the mention only, got %d events", len(events))
+	}
+
+	publish := &nostr.Event{CreatedAt: nostr.Now(), Kind: nostr.KindTextNote, Content: "published"}
+	publish.Sign(ownerSK)
+	if err := client.Publish(connectCtx, *publish); err == nil || !strings.Contains(err.Error(), "read-only") {
+		t.Errorf("Expected the read-only relay to reject EVENT, got %v", err)
+	}
+
+	// With writes allowed, events in scope are stored and others still rejected
+	relayCfg.AllowWrites = true
+	if err := client.Publish(connectCtx, *publish); err != nil {
+		t.Fatalf("Publish() error = %v", err)
+	}
+	if stored, _ := st.QueryEvents(ctx, nostr.Filter{IDs: []string{publish.ID}}); len(stored) != 1 {
+		t.Error("Expected the published event to be stored")
+	}
+
+	outside := &nostr.Event{CreatedAt: nostr.Now(), Kind: nostr.KindTextNote, Content: "spam"}
+	outside.Sign(strangerSK)
+	if err := client.Publish(connectCtx, *outside); err == nil {
+		t.Error("Expected an event from outside the scope to be rejected")
+	}
+}
@@ -17,6 +17,7 @@ type Engine struct {
 	socialGraph SocialGraphReader
 	ownerPubkey string
 	sortedRules []config.RetentionRule // Cached sorted rules (performance optimization)
+	scorer      Scorer
 }
 
 // NewEngine creates a new retention engine
@@ -26,6 +27,7 @@ func NewEngine(cfg *config.AdvancedRetention, storage StorageReader, graph Socia
 		storage:     storage,
 		socialGraph: graph,
 		ownerPubkey: ownerPubkey,
+		scorer:      NewWeightedScorer(cfg.Scoring, storage, graph, ownerPubkey),
 	}
 
 	// Pre-sort rules once at initialization for performance
@@ -38,13 +40,39 @@ func NewEngine(cfg *config.AdvancedRetention, storage StorageReader, graph Socia
 	return e
 }
 
+// SetScorer replaces the scoring function used for cap enforcement
+func (e *Engine) SetScorer(s Scorer) {
+	e.scorer = s
+}
+
 // EvaluateEvent evaluates a single event against retention rules
 func (e *Engine) EvaluateEvent(ctx context.Context, event *nostr.Event) (*RetentionDecision, error) {
 	if e.config == nil || !e.config.Enabled {
 		return nil, fmt.Errorf("advanced retention not enabled")
 	}
 
-	// Create evaluation context
+	if rule := e.matchRule(event); rule != nil {
+		// Apply action from matching rule
+		decision, err := e.applyAction(event, *rule)
+		if err != nil {
+			return nil, fmt.Errorf("failed to apply action for rule %s: %w", rule.Name, err)
+		}
+		return decision, nil
+	}
+
+	// No rule matched - delete by default (safe default)
+	return &RetentionDecision{
+		EventID:      event.ID,
+		RuleName:     "default_delete",
+		RulePriority: 0,
+		RetainUntil:  timePtr(time.Now()), // Immediate deletion
+		Protected:    false,
+		Score:        0,
+	}, nil
+}
+
+// matchRule returns the highest priority rule matching the event, or nil
+func (e *Engine) matchRule(event *nostr.Event) *config.RetentionRule {
 	evalCtx := &EvalContext{
 		Event:       event,
 		Storage:     e.storage,
@@ -53,32 +81,18 @@ func (e *Engine) EvaluateEvent(ctx context.Context, event *nostr.Event) (*Retent
 	}
 
 	// Evaluate rules in order until one matches (use pre-sorted rules)
-	for _, rule := range e.sortedRules {
-		matches, err := e.evaluateConditions(evalCtx, rule.Conditions)
+	for i := range e.sortedRules {
+		matches, err := e.evaluateConditions(evalCtx, e.sortedRules[i].Conditions)
 		if err != nil {
 			// Log error but continue to next rule
 			continue
 		}
-
 		if matches {
-			// Apply action from matching rule
-			decision, err := e.applyAction(event, rule)
-			if err != nil {
-				return nil, fmt.Errorf("failed to apply action for rule %s: %w", rule.Name, err)
-			}
-			return decision, nil
+			return &e.sortedRules[i]
 		}
 	}
 
-	// No rule matched - delete by default (safe default)
-	return &RetentionDecision{
-		EventID:      event.ID,
-		RuleName:     "default_delete",
-		RulePriority: 0,
-		RetainUntil:  timePtr(time.Now()), // Immediate deletion
-		Protected:    false,
-		Score:        0,
-	}, nil
+	return nil
 }
 
 // EvaluateBatch evaluates multiple events in a batch (optimized version)
@@ -311,33 +325,7 @@ func (e *Engine) applyAction(event *nostr.Event, rule config.RetentionRule) (*Re
 
 // calculateScore calculates an event's priority score for cap enforcement
 func (e *Engine) calculateScore(event *nostr.Event, rulePriority int) int {
-	score := rulePriority * 100
-
-	// Bonus for owner content
-	if event.PubKey == e.ownerPubkey {
-		score += 1000
-	}
-
-	// Bonus for close social distance
-	distance := e.socialGraph.GetDistance(e.ownerPubkey, event.PubKey)
-	if distance >= 0 {
-		socialWeight := max(0, 10-distance)
-		score += socialWeight * 100
-	}
-
-	// Age weight (newer is better)
-	eventTime := time.Unix(int64(event.CreatedAt), 0)
-	ageMonths := int(time.Since(eventTime).Hours() / 24 / 30)
-	ageWeight := max(0, 10-ageMonths)
-	score += ageWeight * 10
-
-	// Interaction weight (from aggregates)
-	if agg, err := e.storage.GetAggregateByID(event.ID); err == nil {
-		interactionWeight := min(10, agg.ReplyCount+agg.ReactionTotal/10+int(agg.ZapSatsTotal/1000))
-		score += interactionWeight * 5
-	}
-
-	return score
+	return e.scorer.Score(event, rulePriority).Total
 }
 
 // Helper functions
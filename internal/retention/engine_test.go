@@ -430,6 +430,53 @@ func TestScoreCalculation(t *testing.T) {
 	}
 }
 
+func TestCustomScoringWeights(t *testing.T) {
+	cfg := &config.AdvancedRetention{
+		Enabled: true,
+		Mode:    "rules",
+		Scoring: &config.ScoringWeights{
+			RulePriority: 1,
+			Kinds:        map[int]int{30023: 500},
+		},
+		Rules: []config.RetentionRule{
+			{
+				Name:       "all",
+				Priority:   100,
+				Conditions: config.RuleConditions{All: true},
+				Action:     config.RetentionAction{RetainDays: 90},
+			},
+		},
+	}
+
+	engine := NewEngine(cfg, &mockStorage{}, &mockGraph{}, "owner123")
+
+	article := &nostr.Event{
+		ID:        "article",
+		PubKey:    "someone",
+		CreatedAt: nostr.Timestamp(time.Now().Unix()),
+		Kind:      30023,
+	}
+
+	explanation, err := engine.Explain(context.Background(), article)
+	if err != nil {
+		t.Fatalf("Explain failed: %v", err)
+	}
+
+	if explanation.MatchedRule == nil || explanation.MatchedRule.Name != "all" {
+		t.Fatalf("Expected rule 'all' to match, got %+v", explanation.MatchedRule)
+	}
+
+	// 100 * 1 (rule priority) + 500 (kind bonus); all other weights are zero
+	if explanation.Score.Total != 600 {
+		t.Errorf("Expected score 600, got %d (%s)", explanation.Score.Total, explanation.Score)
+	}
+
+	if explanation.Decision.Score != explanation.Score.Total {
+		t.Errorf("Expected decision score %d to match breakdown total %d",
+			explanation.Decision.Score, explanation.Score.Total)
+	}
+}
+
 func TestRetainDaysCalculation(t *testing.T) {
 	now := time.Now()
 	createdAt := now.Add(-10 * 24 * time.Hour) // 10 days ago
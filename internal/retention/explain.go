@@ -0,0 +1,33 @@
+package retention
+
+import (
+	"context"
+
+	"github.com/nbd-wtf/go-nostr"
+	"github.com/sandwich/nophr/internal/config"
+)
+
+// Explanation describes how the engine reached a retention decision
+type Explanation struct {
+	Decision    *RetentionDecision
+	MatchedRule *config.RetentionRule // nil when no rule matched (default_delete)
+	Score       *ScoreBreakdown       // nil when no rule matched
+}
+
+// Explain evaluates an event like EvaluateEvent and also reports the matched rule
+// and the score breakdown, for debugging rule sets
+func (e *Engine) Explain(ctx context.Context, event *nostr.Event) (*Explanation, error) {
+	decision, err := e.EvaluateEvent(ctx, event)
+	if err != nil {
+		return nil, err
+	}
+
+	explanation := &Explanation{Decision: decision}
+
+	if rule := e.matchRule(event); rule != nil {
+		explanation.MatchedRule = rule
+		explanation.Score = e.scorer.Score(event, rule.Priority)
+	}
+
+	return explanation, nil
+}
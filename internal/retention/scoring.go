@@ -0,0 +1,87 @@
+package retention
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/nbd-wtf/go-nostr"
+	"github.com/sandwich/nophr/internal/config"
+)
+
+// Scorer computes the cap enforcement score for an event.
+// Lower scores are pruned first when caps are exceeded.
+type Scorer interface {
+	Score(event *nostr.Event, rulePriority int) *ScoreBreakdown
+}
+
+// ScoreBreakdown shows how each factor contributed to a score
+type ScoreBreakdown struct {
+	RulePriority   int
+	Owner          int
+	SocialDistance int
+	Age            int
+	Interactions   int
+	Kind           int
+	Total          int
+}
+
+// String formats the breakdown for display
+func (b *ScoreBreakdown) String() string {
+	return fmt.Sprintf("%d = rule %d + owner %d + social %d + age %d + interactions %d + kind %d",
+		b.Total, b.RulePriority, b.Owner, b.SocialDistance, b.Age, b.Interactions, b.Kind)
+}
+
+// WeightedScorer scores events using configurable weights
+type WeightedScorer struct {
+	weights     *config.ScoringWeights
+	storage     StorageReader
+	socialGraph SocialGraphReader
+	ownerPubkey string
+}
+
+// NewWeightedScorer creates a scorer; nil weights use the defaults
+func NewWeightedScorer(weights *config.ScoringWeights, storage StorageReader, graph SocialGraphReader, ownerPubkey string) *WeightedScorer {
+	if weights == nil {
+		weights = config.DefaultScoringWeights()
+	}
+
+	return &WeightedScorer{
+		weights:     weights,
+		storage:     storage,
+		socialGraph: graph,
+		ownerPubkey: ownerPubkey,
+	}
+}
+
+// Score calculates an event's priority score for cap enforcement
+func (s *WeightedScorer) Score(event *nostr.Event, rulePriority int) *ScoreBreakdown {
+	b := &ScoreBreakdown{
+		RulePriority: rulePriority * s.weights.RulePriority,
+		Kind:         s.weights.Kinds[event.Kind],
+	}
+
+	// Bonus for owner content
+	if event.PubKey == s.ownerPubkey {
+		b.Owner = s.weights.Owner
+	}
+
+	// Bonus for close social distance
+	distance := s.socialGraph.GetDistance(s.ownerPubkey, event.PubKey)
+	if distance >= 0 {
+		b.SocialDistance = max(0, 10-distance) * s.weights.SocialDistance
+	}
+
+	// Age weight (newer is better)
+	eventTime := time.Unix(int64(event.CreatedAt), 0)
+	ageMonths := int(time.Since(eventTime).Hours() / 24 / 30)
+	b.Age = max(0, 10-ageMonths) * s.weights.Age
+
+	// Interaction weight (from aggregates)
+	if agg, err := s.storage.GetAggregateByID(event.ID); err == nil && agg != nil {
+		units := min(10, agg.ReplyCount+agg.ReactionTotal/10+int(agg.ZapSatsTotal/1000))
+		b.Interactions = units * s.weights.Interactions
+	}
+
+	b.Total = b.RulePriority + b.Owner + b.SocialDistance + b.Age + b.Interactions + b.Kind
+	return b
+}
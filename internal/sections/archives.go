@@ -4,9 +4,12 @@ import (
 	"context"
 	"fmt"
 	"sort"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/nbd-wtf/go-nostr"
+	"github.com/sandwich/nophr/internal/security"
 	"github.com/sandwich/nophr/internal/storage"
 )
 
@@ -32,16 +35,24 @@ const (
 
 // ArchiveManager manages time-based archives
 type ArchiveManager struct {
-	storage *storage.Storage
+	storage     *storage.Storage
+	validator   *security.Validator
+	maxPageSize int
 }
 
 // NewArchiveManager creates a new archive manager
 func NewArchiveManager(st *storage.Storage) *ArchiveManager {
 	return &ArchiveManager{
-		storage: st,
+		storage:   st,
+		validator: security.NewValidator(),
 	}
 }
 
+// SetMaxPageSize sets the hard cap on events per archive page (display.limits.max_archive_page_size)
+func (am *ArchiveManager) SetMaxPageSize(size int) {
+	am.maxPageSize = size
+}
+
 // ListArchives returns available archives for a section
 func (am *ArchiveManager) ListArchives(ctx context.Context, section *Section, period ArchivePeriod) ([]*Archive, error) {
 	// Get all events for the section
@@ -102,8 +113,70 @@ func (am *ArchiveManager) ListArchives(ctx context.Context, section *Section, pe
 	return archives, nil
 }
 
+// ListAuthorArchives returns an author's archives of the given kinds, newest first:
+// one per year when year is 0, otherwise one per month of that year. Counts come
+// from storage, so archives cover every stored event, not just one query's worth.
+func (am *ArchiveManager) ListAuthorArchives(ctx context.Context, pubkey string, kinds []int, year int) ([]*Archive, error) {
+	counts, err := am.storage.CountEventsByMonth(ctx, pubkey, kinds)
+	if err != nil {
+		return nil, err
+	}
+
+	archiveMap := make(map[string]*Archive)
+	for key, count := range counts {
+		month, err := time.Parse("2006-01", key)
+		if err != nil {
+			continue
+		}
+		if year > 0 && month.Year() != year {
+			continue
+		}
+
+		period := ArchiveByMonth
+		rng := MonthRange(month.Year(), month.Month())
+		if year == 0 {
+			period = ArchiveByYear
+			rng = YearRange(month.Year())
+			key = fmt.Sprintf("%04d", month.Year())
+		}
+
+		archive, exists := archiveMap[key]
+		if !exists {
+			archive = &Archive{
+				Period:     period,
+				Year:       month.Year(),
+				FirstEvent: rng.Start,
+				LastEvent:  rng.End,
+			}
+			if period == ArchiveByMonth {
+				archive.Month = month.Month()
+			}
+			archiveMap[key] = archive
+		}
+		archive.EventCount += count
+	}
+
+	archives := make([]*Archive, 0, len(archiveMap))
+	for _, archive := range archiveMap {
+		archives = append(archives, archive)
+	}
+	sort.Slice(archives, func(i, j int) bool {
+		return archives[i].FirstEvent.After(archives[j].FirstEvent)
+	})
+
+	return archives, nil
+}
+
 // GetArchivePage returns events for a specific archive period
 func (am *ArchiveManager) GetArchivePage(ctx context.Context, section *Section, year int, month time.Month, day int, pageNum int) (*Page, error) {
+	// Reject crafted page numbers before touching storage
+	if pageNum < 1 {
+		pageNum = 1
+	}
+	if err := am.validator.ValidatePageNumber(pageNum); err != nil {
+		return nil, fmt.Errorf("invalid archive page: %w", err)
+	}
+
 	// Build time range based on archive period
 	var start, end time.Time
 
@@ -147,14 +220,11 @@ func (am *ArchiveManager) GetArchivePage(ctx context.Context, section *Section,
 	})
 
 	// Paginate
-	if pageNum < 1 {
-		pageNum = 1
-	}
-
 	limit := section.Limit
 	if limit == 0 {
 		limit = 20
 	}
+	limit = am.validator.CapResultLimit(limit, am.maxPageSize)
 
 	offset := (pageNum - 1) * limit
 	totalItems := int64(len(events))
@@ -222,6 +292,44 @@ func (a *Archive) FormatArchiveSelector(sectionName string) string {
 	}
 }
 
+// Selector returns the path of an owner archive, e.g. /archive/2024 or /archive/2024/06
+func (a *Archive) Selector() string {
+	if a.Period == ArchiveByYear {
+		return fmt.Sprintf("/archive/%04d", a.Year)
+	}
+	return fmt.Sprintf("/archive/%04d/%02d", a.Year, a.Month)
+}
+
+// ParseArchivePath parses the parts of an owner archive path after /archive:
+// [] for all years, ["2024"] for one year or ["2024", "06"] for one month.
+// Year and month are 0 when absent.
+func ParseArchivePath(parts []string) (int, time.Month, error) {
+	// Ignore a trailing slash
+	if len(parts) > 0 && parts[len(parts)-1] == "" {
+		parts = parts[:len(parts)-1]
+	}
+	if len(parts) == 0 {
+		return 0, 0, nil
+	}
+	if len(parts) > 2 {
+		return 0, 0, fmt.Errorf("invalid archive path: %s", strings.Join(parts, "/"))
+	}
+
+	year, err := strconv.Atoi(parts[0])
+	if err != nil || len(parts[0]) != 4 || year < 1970 {
+		return 0, 0, fmt.Errorf("invalid archive year: %s", parts[0])
+	}
+	if len(parts) == 1 {
+		return year, 0, nil
+	}
+
+	month, err := strconv.Atoi(parts[1])
+	if err != nil || len(parts[1]) > 2 || month < 1 || month > 12 {
+		return 0, 0, fmt.Errorf("invalid archive month: %s", parts[1])
+	}
+	return year, time.Month(month), nil
+}
+
 // MonthlyArchiveCalendar generates a calendar view of monthly archives
 type MonthlyArchiveCalendar struct {
 	Year   int
@@ -1,10 +1,12 @@
 package sections
 
 import (
+	"context"
 	"fmt"
 	"time"
 
 	"github.com/nbd-wtf/go-nostr"
+	"github.com/sandwich/nophr/internal/storage"
 )
 
 // FilterBuilder helps construct complex filters for sections
@@ -66,48 +68,78 @@ func (fb *FilterBuilder) Limit(limit int) *FilterBuilder {
 	return fb
 }
 
+// Search sets the search text, which may hold qualifiers like lang:es (see storage.ParseSearch)
+func (fb *FilterBuilder) Search(text string) *FilterBuilder {
+	fb.filter.Search = text
+	return fb
+}
+
 // Build returns the constructed filter
 func (fb *FilterBuilder) Build() nostr.Filter {
 	return fb.filter
 }
 
-// ScopeFilterBuilder builds filters based on social graph scope
+// ScopeFilterBuilder resolves a scope to authors by walking the contact lists
+// (kind 3) in storage outward from the owner
 type ScopeFilterBuilder struct {
+	storage     *storage.Storage
 	ownerPubkey string
 	scope       Scope
 	depth       int
+	maxAuthors  int
+	allowlist   []string
+	denylist    []string
 }
 
-// NewScopeFilterBuilder creates a new scope filter builder
-func NewScopeFilterBuilder(ownerPubkey string, scope Scope, depth int) *ScopeFilterBuilder {
+// NewScopeFilterBuilder creates a new scope filter builder. depth is how many
+// hops foaf follows out from the owner (2 if unset).
+func NewScopeFilterBuilder(st *storage.Storage, ownerPubkey string, scope Scope, depth int) *ScopeFilterBuilder {
 	return &ScopeFilterBuilder{
+		storage:     st,
 		ownerPubkey: ownerPubkey,
 		scope:       scope,
 		depth:       depth,
 	}
 }
 
-// BuildAuthors returns the list of authors based on scope
-// This is a simplified version - full implementation would query the graph
-func (sfb *ScopeFilterBuilder) BuildAuthors() ([]string, error) {
+// Limits caps the number of authors and applies allow and deny lists of hex
+// pubkeys. Denied authors are left out along with the follows only reachable
+// through them; the owner is always in scope.
+func (sfb *ScopeFilterBuilder) Limits(maxAuthors int, allowlist, denylist []string) *ScopeFilterBuilder {
+	sfb.maxAuthors = maxAuthors
+	sfb.allowlist = allowlist
+	sfb.denylist = denylist
+	return sfb
+}
+
+// BuildAuthors returns the authors in scope, nearest first: the owner, their
+// follows in contact list order, then follows of follows. ScopeAll returns an
+// empty list, meaning no author filter.
+func (sfb *ScopeFilterBuilder) BuildAuthors(ctx context.Context) ([]string, error) {
+	var authors []string
+	var err error
 	switch sfb.scope {
 	case ScopeSelf:
 		return []string{sfb.ownerPubkey}, nil
 	case ScopeFollowing:
-		// Would query graph for following list
-		return []string{sfb.ownerPubkey}, nil
+		authors, err = sfb.traverse(ctx, 1)
 	case ScopeMutual:
-		// Would query graph for mutual follows
-		return []string{sfb.ownerPubkey}, nil
+		authors, err = sfb.mutuals(ctx)
 	case ScopeFoaf:
-		// Would query graph for friends-of-friends
-		return []string{sfb.ownerPubkey}, nil
+		depth := sfb.depth
+		if depth <= 0 {
+			depth = 2
+		}
+		authors, err = sfb.traverse(ctx, depth)
 	case ScopeAll:
-		// No author filter - return all
 		return []string{}, nil
 	default:
 		return nil, fmt.Errorf("unknown scope: %s", sfb.scope)
 	}
+	if err != nil {
+		return nil, err
+	}
+	return sfb.applyLimits(authors), nil
 }
 
 // TimeRangeFilter creates a filter for a specific time range
@@ -177,6 +209,18 @@ func ThisYear() *TimeRangeFilter {
 	return NewTimeRangeFilter(start, end)
 }
 
+// YearRange returns the time range of a calendar year in UTC
+func YearRange(year int) *TimeRangeFilter {
+	start := time.Date(year, 1, 1, 0, 0, 0, 0, time.UTC)
+	return NewTimeRangeFilter(start, start.AddDate(1, 0, 0))
+}
+
+// MonthRange returns the time range of a calendar month in UTC
+func MonthRange(year int, month time.Month) *TimeRangeFilter {
+	start := time.Date(year, month, 1, 0, 0, 0, 0, time.UTC)
+	return NewTimeRangeFilter(start, start.AddDate(0, 1, 0))
+}
+
 // LastNDays returns a time range for the last N days
 func LastNDays(n int) *TimeRangeFilter {
 	end := time.Now()
@@ -1,6 +1,7 @@
 package sections
 
 import (
+	"context"
 	"testing"
 	"time"
 )
@@ -97,6 +98,22 @@ func TestTimeRangeFilters(t *testing.T) {
 		}
 	})
 
+	t.Run("Month range", func(t *testing.T) {
+		trf := MonthRange(2024, time.February)
+
+		if !trf.Start.Equal(time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC)) || !trf.End.Equal(time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)) {
+			t.Errorf("unexpected February 2024 range: %v - %v", trf.Start, trf.End)
+		}
+	})
+
+	t.Run("Year range", func(t *testing.T) {
+		trf := YearRange(2024)
+
+		if trf.End.Sub(trf.Start) != 366*24*time.Hour {
+			t.Errorf("expected a 366 day leap year, got %v", trf.End.Sub(trf.Start))
+		}
+	})
+
 	t.Run("Last N days", func(t *testing.T) {
 		trf := LastNDays(7)
 
@@ -203,14 +220,14 @@ func TestScopeFilterBuilder(t *testing.T) {
 		expectedCount int
 	}{
 		{"Self", ScopeSelf, 1},
-		{"Following", ScopeFollowing, 1}, // Simplified - would be more in real implementation
-		{"All", ScopeAll, 0},              // No author filter for "all"
+		{"Following", ScopeFollowing, 1}, // No contact lists stored
+		{"All", ScopeAll, 0},             // No author filter for "all"
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			sfb := NewScopeFilterBuilder(pubkey, tt.scope, 2)
-			authors, err := sfb.BuildAuthors()
+			sfb := NewScopeFilterBuilder(nil, pubkey, tt.scope, 2)
+			authors, err := sfb.BuildAuthors(context.Background())
 
 			if err != nil {
 				t.Fatalf("unexpected error: %v", err)
@@ -0,0 +1,95 @@
+package sections
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/nbd-wtf/go-nostr"
+)
+
+// TypeList shows the events referenced by the lists matching the section's
+// filters, such as the owner's bookmarks (kinds 10003 and 30001), newest first
+const TypeList SectionType = "list"
+
+// listPage resolves the e and a tags of the newest matching lists into the
+// events they point at. References are listed in reverse tag order, since
+// clients append new items, and items that aren't stored are skipped.
+func (m *Manager) listPage(ctx context.Context, section *Section, filter nostr.Filter) (*Page, error) {
+	lists, err := m.storage.QueryEvents(ctx, filter)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query lists: %w", err)
+	}
+
+	var refs []nostr.Tag
+	seen := make(map[string]bool)
+	for _, list := range lists {
+		for i := len(list.Tags) - 1; i >= 0; i-- {
+			tag := list.Tags[i]
+			if len(tag) < 2 || (tag[0] != "e" && tag[0] != "a") || seen[tag[1]] {
+				continue
+			}
+			seen[tag[1]] = true
+			refs = append(refs, tag)
+		}
+	}
+
+	var ids []string
+	for _, ref := range refs {
+		if ref[0] == "e" {
+			ids = append(ids, ref[1])
+		}
+	}
+	found := make(map[string]*nostr.Event)
+	if len(ids) > 0 {
+		events, err := m.storage.QueryEvents(ctx, nostr.Filter{IDs: ids})
+		if err != nil {
+			return nil, fmt.Errorf("failed to query listed events: %w", err)
+		}
+		for _, event := range events {
+			found[event.ID] = event
+		}
+	}
+
+	page := &Page{Section: section}
+	for _, ref := range refs {
+		if len(page.Events) == section.Limit {
+			break
+		}
+		if ref[0] == "e" {
+			if event, ok := found[ref[1]]; ok {
+				page.Events = append(page.Events, event)
+			}
+			continue
+		}
+		if event := m.lookupAddress(ctx, ref[1]); event != nil {
+			page.Events = append(page.Events, event)
+		}
+	}
+	page.TotalItems = int64(len(page.Events))
+	return page, nil
+}
+
+// lookupAddress returns the stored event at a "kind:pubkey:d" address
+func (m *Manager) lookupAddress(ctx context.Context, address string) *nostr.Event {
+	parts := strings.SplitN(address, ":", 3)
+	if len(parts) != 3 {
+		return nil
+	}
+	kind, err := strconv.Atoi(parts[0])
+	if err != nil {
+		return nil
+	}
+
+	events, err := m.storage.QueryEvents(ctx, NewFilterBuilder().
+		Kinds(kind).
+		Authors(parts[1]).
+		Tag("d", parts[2]).
+		Limit(1).
+		Build())
+	if err != nil || len(events) == 0 {
+		return nil
+	}
+	return events[0]
+}
@@ -0,0 +1,114 @@
+package sections
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/nbd-wtf/go-nostr"
+	"github.com/sandwich/nophr/internal/config"
+	"github.com/sandwich/nophr/internal/storage"
+)
+
+func TestListSection(t *testing.T) {
+	ctx := context.Background()
+	st, err := storage.New(ctx, &config.Storage{Driver: "sqlite", SQLitePath: filepath.Join(t.TempDir(), "test.db")})
+	if err != nil {
+		t.Fatalf("Failed to create storage: %v", err)
+	}
+	defer st.Close()
+
+	owner, _ := nostr.GetPublicKey(nostr.GeneratePrivateKey())
+	author, _ := nostr.GetPublicKey(nostr.GeneratePrivateKey())
+
+	older := &nostr.Event{Kind: 1, PubKey: author, CreatedAt: 100, Content: "bookmarked first"}
+	newer := &nostr.Event{Kind: 1, PubKey: author, CreatedAt: 50, Content: "bookmarked second"}
+	article := &nostr.Event{Kind: 30023, PubKey: author, CreatedAt: 200, Content: "an article", Tags: nostr.Tags{{"d", "essay"}}}
+	for _, event := range []*nostr.Event{older, newer, article} {
+		event.ID = event.GetID()
+	}
+	bookmarks := &nostr.Event{Kind: 10003, PubKey: owner, CreatedAt: 300, Tags: nostr.Tags{
+		{"e", older.ID},
+		{"e", "0000000000000000000000000000000000000000000000000000000000000000"}, // Not stored
+		{"a", "30023:" + author + ":essay"},
+		{"e", newer.ID},
+	}}
+	bookmarks.ID = bookmarks.GetID()
+	for _, event := range []*nostr.Event{older, newer, article, bookmarks} {
+		if err := st.StoreEvent(ctx, event); err != nil {
+			t.Fatalf("Failed to store event: %v", err)
+		}
+	}
+
+	manager := NewManager(st)
+	if err := LoadFromConfig(manager, []config.SectionConfig{
+		{Name: "bookmarks", Path: "/bookmarks", Type: "list", Filters: config.SectionFilterConfig{Kinds: []int{10003, 30001}, Authors: []string{owner}}},
+	}); err != nil {
+		t.Fatalf("Failed to load sections: %v", err)
+	}
+
+	page, err := manager.GetPage(ctx, "bookmarks", nil)
+	if err != nil {
+		t.Fatalf("GetPage() error = %v", err)
+	}
+
+	want := []string{"bookmarked second", "an article", "bookmarked first"}
+	if len(page.Events) != len(want) {
+		t.Fatalf("Expected %d bookmarked events, got %d", len(want), len(page.Events))
+	}
+	for i, content := range want {
+		if page.Events[i].Content != content {
+			t.Errorf("Event %d = %q, want %q", i, page.Events[i].Content, content)
+		}
+	}
+	if page.Section.Pageable() {
+		t.Error("Expected list sections not to page by cursor")
+	}
+}
+
+func TestHashtagAndTagKeys(t *testing.T) {
+	section, err := convertConfigToSection(config.SectionConfig{
+		Name: "photography",
+		Path: "/photography",
+		Filters: config.SectionFilterConfig{
+			Kinds:    []int{1},
+			Hashtags: []string{"#Photography"},
+			Tags:     map[string][]string{"#t": {"film"}},
+		},
+	})
+	if err != nil {
+		t.Fatalf("convertConfigToSection() error = %v", err)
+	}
+
+	filter, err := NewManager(nil).buildFilter(context.Background(), section, nil)
+	if err != nil {
+		t.Fatalf("buildFilter() error = %v", err)
+	}
+	if got := filter.Tags["t"]; len(got) != 2 || got[0] != "film" || got[1] != "photography" {
+		t.Errorf("Expected t tags [film photography], got %v", got)
+	}
+}
+
+func TestLanguageAndTopicFilters(t *testing.T) {
+	section, err := convertConfigToSection(config.SectionConfig{
+		Name: "cocina",
+		Path: "/cocina",
+		Filters: config.SectionFilterConfig{
+			Kinds:     []int{1},
+			Search:    "receta",
+			Languages: []string{"ES"},
+			Topics:    []string{"Home Cooking"},
+		},
+	})
+	if err != nil {
+		t.Fatalf("convertConfigToSection() error = %v", err)
+	}
+
+	filter, err := NewManager(nil).buildFilter(context.Background(), section, nil)
+	if err != nil {
+		t.Fatalf("buildFilter() error = %v", err)
+	}
+	if filter.Search != "receta lang:es topic:home-cooking" {
+		t.Errorf("Expected search with qualifiers, got %q", filter.Search)
+	}
+}
@@ -2,8 +2,11 @@ package sections
 
 import (
 	"fmt"
+	"strings"
 	"time"
 
+	"github.com/nbd-wtf/go-nostr/nip19"
+	"github.com/sandwich/nophr/internal/annotate"
 	"github.com/sandwich/nophr/internal/config"
 )
 
@@ -24,16 +27,33 @@ func LoadFromConfig(manager *Manager, sectionConfigs []config.SectionConfig) err
 	return nil
 }
 
+// ReloadFromConfig replaces the Manager's sections with sectionConfigs. If any
+// entry fails to convert, the current sections are kept.
+func ReloadFromConfig(manager *Manager, sectionConfigs []config.SectionConfig) error {
+	sections := make([]*Section, 0, len(sectionConfigs))
+	for _, cfg := range sectionConfigs {
+		section, err := convertConfigToSection(cfg)
+		if err != nil {
+			return fmt.Errorf("failed to convert section %s: %w", cfg.Name, err)
+		}
+		sections = append(sections, section)
+	}
+	return manager.ReplaceSections(sections)
+}
+
 // convertConfigToSection converts a config.SectionConfig to a Section
 func convertConfigToSection(cfg config.SectionConfig) (*Section, error) {
 	section := &Section{
 		Name:        cfg.Name,
+		Type:        SectionType(cfg.Type),
 		Path:        cfg.Path,
 		Title:       cfg.Title,
 		Description: cfg.Description,
 		ShowDates:   cfg.ShowDates,
 		ShowAuthors: cfg.ShowAuthors,
 		Order:       cfg.Order,
+		MediaLinks:  cfg.MediaLinks,
+		Hidden:      cfg.Hidden,
 	}
 
 	// Set limit (default to 20 if not specified)
@@ -67,6 +87,9 @@ func convertConfigToSection(cfg config.SectionConfig) (*Section, error) {
 	if err != nil {
 		return nil, fmt.Errorf("failed to convert filters: %w", err)
 	}
+	if section.Type == TypePlanet && len(filterSet.Kinds) == 0 {
+		filterSet.Kinds = []int{KindArticle}
+	}
 	section.Filters = filterSet
 
 	// Convert more link
@@ -83,10 +106,43 @@ func convertConfigToSection(cfg config.SectionConfig) (*Section, error) {
 // convertFilterConfig converts a config.SectionFilterConfig to FilterSet
 func convertFilterConfig(cfg config.SectionFilterConfig) (FilterSet, error) {
 	filterSet := FilterSet{
-		Kinds:   cfg.Kinds,
-		Authors: cfg.Authors,
-		Tags:    cfg.Tags,
-		Search:  cfg.Search,
+		Kinds:  cfg.Kinds,
+		Search: cfg.Search,
+	}
+	for _, lang := range cfg.Languages {
+		filterSet.Languages = append(filterSet.Languages, strings.ToLower(strings.TrimSpace(lang)))
+	}
+	for _, topic := range cfg.Topics {
+		filterSet.Topics = append(filterSet.Topics, annotate.TopicName(topic))
+	}
+
+	// Tag keys may be written as in NIP-01 filters, e.g. "#t"
+	for key, values := range cfg.Tags {
+		if filterSet.Tags == nil {
+			filterSet.Tags = make(map[string][]string)
+		}
+		key = strings.TrimPrefix(key, "#")
+		filterSet.Tags[key] = append(filterSet.Tags[key], values...)
+	}
+
+	// Hashtags are t tags, which clients store lowercased without the '#'
+	for _, hashtag := range cfg.Hashtags {
+		if filterSet.Tags == nil {
+			filterSet.Tags = make(map[string][]string)
+		}
+		filterSet.Tags["t"] = append(filterSet.Tags["t"], strings.ToLower(strings.TrimPrefix(hashtag, "#")))
+	}
+
+	// Authors may be given as npubs; events are stored under hex pubkeys
+	for _, author := range cfg.Authors {
+		if strings.HasPrefix(author, "npub1") {
+			_, decoded, err := nip19.Decode(author)
+			if err != nil {
+				return filterSet, fmt.Errorf("invalid author %s: %w", author, err)
+			}
+			author = decoded.(string)
+		}
+		filterSet.Authors = append(filterSet.Authors, author)
 	}
 
 	// Convert scope
@@ -96,31 +152,31 @@ func convertFilterConfig(cfg config.SectionFilterConfig) (FilterSet, error) {
 
 	// Parse time ranges
 	if cfg.Since != "" {
-		sinceTime, err := parseTimeOrDuration(cfg.Since)
+		sinceTime, sinceOffset, err := parseTimeOrDuration(cfg.Since)
 		if err != nil {
 			return filterSet, fmt.Errorf("invalid since time: %w", err)
 		}
-		filterSet.Since = &sinceTime
+		filterSet.Since, filterSet.SinceOffset = sinceTime, sinceOffset
 	}
 
 	if cfg.Until != "" {
-		untilTime, err := parseTimeOrDuration(cfg.Until)
+		untilTime, untilOffset, err := parseTimeOrDuration(cfg.Until)
 		if err != nil {
 			return filterSet, fmt.Errorf("invalid until time: %w", err)
 		}
-		filterSet.Until = &untilTime
+		filterSet.Until, filterSet.UntilOffset = untilTime, untilOffset
 	}
 
 	return filterSet, nil
 }
 
 // parseTimeOrDuration parses a time string that can be either:
-// - RFC3339 timestamp (e.g., "2024-01-01T00:00:00Z")
-// - Relative duration (e.g., "-24h", "-7d")
-func parseTimeOrDuration(s string) (time.Time, error) {
+// - RFC3339 timestamp (e.g., "2024-01-01T00:00:00Z"), returned as a time
+// - Relative duration (e.g., "-24h", "-7d"), returned as an offset from the query time
+func parseTimeOrDuration(s string) (*time.Time, *time.Duration, error) {
 	// Try parsing as RFC3339 first
 	if t, err := time.Parse(time.RFC3339, s); err == nil {
-		return t, nil
+		return &t, nil, nil
 	}
 
 	// Try parsing as duration (must start with - or +)
@@ -135,18 +191,18 @@ func parseTimeOrDuration(s string) (time.Time, error) {
 			days := s[:len(s)-1]
 			var daysInt int
 			if _, err := fmt.Sscanf(days, "%d", &daysInt); err != nil {
-				return time.Time{}, fmt.Errorf("invalid day duration: %w", err)
+				return nil, nil, fmt.Errorf("invalid day duration: %w", err)
 			}
 			duration = time.Duration(daysInt) * 24 * time.Hour
 		} else {
 			duration, err = time.ParseDuration(s)
 			if err != nil {
-				return time.Time{}, fmt.Errorf("invalid duration: %w", err)
+				return nil, nil, fmt.Errorf("invalid duration: %w", err)
 			}
 		}
 
-		return time.Now().Add(duration), nil
+		return nil, &duration, nil
 	}
 
-	return time.Time{}, fmt.Errorf("invalid time format (expected RFC3339 or duration like '-24h')")
+	return nil, nil, fmt.Errorf("invalid time format (expected RFC3339 or duration like '-24h')")
 }
@@ -0,0 +1,54 @@
+package sections
+
+import "strings"
+
+// TypeMirror dedicates the subtree under the section's path to the one author
+// in its filters, served as if it were their own capsule: a home page, their
+// notes, articles and profile, and each of their notes below the path
+const TypeMirror SectionType = "mirror"
+
+// IsMirror reports whether the section mirrors a single author
+func (s *Section) IsMirror() bool {
+	return s.Type == TypeMirror
+}
+
+// MirrorAuthor returns the hex pubkey a mirror section is dedicated to
+func (s *Section) MirrorAuthor() string {
+	if len(s.Filters.Authors) == 0 {
+		return ""
+	}
+	return s.Filters.Authors[0]
+}
+
+// MirrorFor finds the mirror section whose subtree holds path and returns it
+// with the parts of path below the section's own, e.g. ["notes"] for
+// /mirror/alice/notes. The deepest matching mirror wins.
+func (m *Manager) MirrorFor(path string) (*Section, []string, bool) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	var found *Section
+	var foundBase, rest string
+	for _, section := range m.sections {
+		base := strings.TrimSuffix(section.Path, "/")
+		if !section.IsMirror() || base == "" || len(base) <= len(foundBase) {
+			continue
+		}
+		if path == base {
+			found, foundBase, rest = section, base, ""
+			continue
+		}
+		if below, ok := strings.CutPrefix(path, base+"/"); ok {
+			found, foundBase, rest = section, base, below
+		}
+	}
+	if found == nil {
+		return nil, nil, false
+	}
+
+	rest = strings.Trim(rest, "/")
+	if rest == "" {
+		return found, nil, true
+	}
+	return found, strings.Split(rest, "/"), true
+}
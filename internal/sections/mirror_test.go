@@ -0,0 +1,48 @@
+package sections
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestMirrorFor(t *testing.T) {
+	manager := NewManager(nil)
+	for _, section := range []*Section{
+		{Name: "alice", Type: TypeMirror, Path: "/mirror/alice", Filters: FilterSet{Authors: []string{"alice"}}},
+		{Name: "alice-art", Type: TypeMirror, Path: "/mirror/alice/art/", Filters: FilterSet{Authors: []string{"bob"}}},
+		{Name: "mirrors", Path: "/mirror"},
+	} {
+		if err := manager.RegisterSection(section); err != nil {
+			t.Fatalf("RegisterSection() error = %v", err)
+		}
+	}
+
+	tests := []struct {
+		path    string
+		section string
+		rest    []string
+	}{
+		{"/mirror/alice", "alice", nil},
+		{"/mirror/alice/", "alice", nil},
+		{"/mirror/alice/notes/until/100_abc", "alice", []string{"notes", "until", "100_abc"}},
+		{"/mirror/alice/art/profile", "alice-art", []string{"profile"}},
+		{"/mirror/alicia", "", nil},
+		{"/mirror", "", nil},
+	}
+	for _, tt := range tests {
+		section, rest, ok := manager.MirrorFor(tt.path)
+		if tt.section == "" {
+			if ok {
+				t.Errorf("MirrorFor(%q) = %s, want no mirror", tt.path, section.Name)
+			}
+			continue
+		}
+		if !ok || section.Name != tt.section || !slices.Equal(rest, tt.rest) {
+			t.Errorf("MirrorFor(%q) = %v %v %v, want %s %v", tt.path, section, rest, ok, tt.section, tt.rest)
+		}
+	}
+
+	if author := manager.sections["alice"].MirrorAuthor(); author != "alice" {
+		t.Errorf("MirrorAuthor() = %q", author)
+	}
+}
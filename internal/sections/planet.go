@@ -0,0 +1,118 @@
+package sections
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/nbd-wtf/go-nostr"
+	nostrclient "github.com/sandwich/nophr/internal/nostr"
+)
+
+// SectionType selects how a section gathers and presents its events
+type SectionType string
+
+const (
+	// TypeEvents lists the events matching the section's filters
+	TypeEvents SectionType = ""
+
+	// TypePlanet aggregates long-form articles from a list of authors, each
+	// credited by name, with the same article posted by several authors shown once
+	TypePlanet SectionType = "planet"
+)
+
+// KindArticle is the long-form article kind planet sections list by default
+const KindArticle = 30023
+
+// IsPlanet reports whether the section aggregates articles from a list of authors
+func (s *Section) IsPlanet() bool {
+	return s.Type == TypePlanet
+}
+
+// AuthorName returns the display name of an author on the page, or "" if their
+// profile isn't stored
+func (p *Page) AuthorName(pubkey string) string {
+	return p.Names[pubkey]
+}
+
+// ArticleTitle returns an article's title tag, or its first line if it has none
+func ArticleTitle(event *nostr.Event) string {
+	if tag := event.Tags.Find("title"); tag != nil && strings.TrimSpace(tag[1]) != "" {
+		return strings.TrimSpace(tag[1])
+	}
+	return strings.TrimSpace(strings.Split(event.Content, "\n")[0])
+}
+
+// dedupeCrossPosts keeps the earliest copy of each article and records the
+// authors of the later copies against it
+func dedupeCrossPosts(events []*nostr.Event) ([]*nostr.Event, map[string][]string) {
+	byAge := append([]*nostr.Event(nil), events...)
+	sort.SliceStable(byAge, func(i, j int) bool { return byAge[i].CreatedAt < byAge[j].CreatedAt })
+
+	original := make(map[string]*nostr.Event)
+	crossPosts := make(map[string][]string)
+	drop := make(map[string]bool)
+	for _, event := range byAge {
+		key := articleKey(event)
+		first, ok := original[key]
+		if !ok {
+			original[key] = event
+			continue
+		}
+		drop[event.ID] = true
+		if event.PubKey != first.PubKey && !slices.Contains(crossPosts[first.ID], event.PubKey) {
+			crossPosts[first.ID] = append(crossPosts[first.ID], event.PubKey)
+		}
+	}
+
+	kept := make([]*nostr.Event, 0, len(events)-len(drop))
+	for _, event := range events {
+		if !drop[event.ID] {
+			kept = append(kept, event)
+		}
+	}
+	return kept, crossPosts
+}
+
+// articleKey identifies an article by its title and text, ignoring case and
+// whitespace, so copies posted by different authors match
+func articleKey(event *nostr.Event) string {
+	normalized := strings.ToLower(strings.Join(strings.Fields(ArticleTitle(event)+" "+event.Content), " "))
+	sum := sha256.Sum256([]byte(normalized))
+	return hex.EncodeToString(sum[:])
+}
+
+// attribute looks up the display names of the page's authors and cross-posters
+func (m *Manager) attribute(ctx context.Context, page *Page) {
+	page.Names = make(map[string]string)
+	if m.storage == nil {
+		return
+	}
+
+	var pubkeys []string
+	for _, event := range page.Events {
+		pubkeys = append(pubkeys, event.PubKey)
+		pubkeys = append(pubkeys, page.CrossPosts[event.ID]...)
+	}
+	if len(pubkeys) == 0 {
+		return
+	}
+
+	profiles, err := m.storage.QueryEvents(ctx, nostr.Filter{Kinds: []int{0}, Authors: pubkeys})
+	if err != nil {
+		return
+	}
+	latest := make(map[string]nostr.Timestamp)
+	for _, profile := range profiles {
+		if ts, ok := latest[profile.PubKey]; ok && ts >= profile.CreatedAt {
+			continue
+		}
+		latest[profile.PubKey] = profile.CreatedAt
+		if meta := nostrclient.ParseProfile(profile); meta != nil && meta.GetDisplayName() != "" {
+			page.Names[profile.PubKey] = meta.GetDisplayName()
+		}
+	}
+}
@@ -0,0 +1,70 @@
+package sections
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/nbd-wtf/go-nostr"
+	"github.com/nbd-wtf/go-nostr/nip19"
+	"github.com/sandwich/nophr/internal/config"
+	"github.com/sandwich/nophr/internal/storage"
+)
+
+func TestPlanetSection(t *testing.T) {
+	ctx := context.Background()
+	st, err := storage.New(ctx, &config.Storage{Driver: "sqlite", SQLitePath: filepath.Join(t.TempDir(), "test.db")})
+	if err != nil {
+		t.Fatalf("Failed to create storage: %v", err)
+	}
+	defer st.Close()
+
+	alice, _ := nostr.GetPublicKey(nostr.GeneratePrivateKey())
+	bob, _ := nostr.GetPublicKey(nostr.GeneratePrivateKey())
+	carol, _ := nostr.GetPublicKey(nostr.GeneratePrivateKey())
+	aliceNpub, _ := nip19.EncodePublicKey(alice)
+
+	article := func(author string, at nostr.Timestamp, d, title, content string) *nostr.Event {
+		return &nostr.Event{Kind: 30023, PubKey: author, CreatedAt: at, Content: content,
+			Tags: nostr.Tags{{"d", d}, {"title", title}}}
+	}
+	events := []*nostr.Event{
+		{Kind: 0, PubKey: alice, CreatedAt: 1, Content: `{"name":"alice"}`},
+		article(alice, 1000, "gardens", "Gardens", "On gardens."),
+		article(bob, 2000, "gardens-copy", "gardens", "On   gardens."), // Cross-post of alice's article
+		article(bob, 3000, "tools", "Tools", "On tools."),
+		article(carol, 4000, "elsewhere", "Elsewhere", "Not on the list."),
+		{Kind: 1, PubKey: alice, CreatedAt: 5000, Content: "A note, not an article"},
+	}
+	for _, event := range events {
+		event.ID = event.GetID()
+		if err := st.StoreEvent(ctx, event); err != nil {
+			t.Fatalf("Failed to store event: %v", err)
+		}
+	}
+
+	manager := NewManager(st)
+	if err := LoadFromConfig(manager, []config.SectionConfig{
+		{Name: "planet", Path: "/planet", Type: "planet", Filters: config.SectionFilterConfig{Authors: []string{aliceNpub, bob}}},
+	}); err != nil {
+		t.Fatalf("Failed to load sections: %v", err)
+	}
+
+	page, err := manager.GetPage(ctx, "planet", nil)
+	if err != nil {
+		t.Fatalf("GetPage() error = %v", err)
+	}
+
+	if len(page.Events) != 2 {
+		t.Fatalf("Expected 2 articles after merging the cross-post, got %d", len(page.Events))
+	}
+	if ArticleTitle(page.Events[0]) != "Tools" || page.Events[1].PubKey != alice {
+		t.Errorf("Expected Tools then alice's original Gardens, got %q by %s", ArticleTitle(page.Events[1]), page.Events[1].PubKey)
+	}
+	if others := page.CrossPosts[page.Events[1].ID]; len(others) != 1 || others[0] != bob {
+		t.Errorf("Expected bob's copy credited as a cross-post, got %v", others)
+	}
+	if page.AuthorName(alice) != "alice" || page.AuthorName(bob) != "" {
+		t.Errorf("Expected alice's name from her profile and none for bob, got %q and %q", page.AuthorName(alice), page.AuthorName(bob))
+	}
+}
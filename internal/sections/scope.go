@@ -0,0 +1,170 @@
+package sections
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/nbd-wtf/go-nostr"
+	"github.com/sandwich/nophr/internal/config"
+)
+
+// scopeCacheTTL is how long a scope's computed authors are reused before the
+// contact lists are walked again
+const scopeCacheTTL = 5 * time.Minute
+
+// contactBatch is how many authors' contact lists are queried at once, kept
+// under the storage query cap since each author has one current list
+const contactBatch = 50
+
+// scopedAuthors is a cached author set
+type scopedAuthors struct {
+	authors []string
+	expires time.Time
+}
+
+// SetScopeLimits applies sync.scope's depth, max_authors, allowlist_pubkeys
+// and denylist_pubkeys to section scopes
+func (m *Manager) SetScopeLimits(limits *config.SyncScope) {
+	m.scopeMu.Lock()
+	defer m.scopeMu.Unlock()
+	m.scopeLimits = limits
+	m.scopeCache = nil
+}
+
+// scopeAuthors returns the authors in a scope from the owner's social graph,
+// or nil when the scope doesn't restrict authors. Results are cached for
+// scopeCacheTTL.
+func (m *Manager) scopeAuthors(ctx context.Context, scope Scope) ([]string, error) {
+	if scope == "" || scope == ScopeAll || m.owner == "" {
+		return nil, nil
+	}
+
+	m.scopeMu.Lock()
+	cached, ok := m.scopeCache[scope]
+	limits := m.scopeLimits
+	m.scopeMu.Unlock()
+	if ok && time.Now().Before(cached.expires) {
+		return slices.Clone(cached.authors), nil
+	}
+
+	sfb := NewScopeFilterBuilder(m.storage, m.owner, scope, 0)
+	if limits != nil {
+		sfb.depth = limits.Depth
+		sfb.Limits(limits.MaxAuthors, limits.AllowlistPubkeys, limits.DenylistPubkeys)
+	}
+	authors, err := sfb.BuildAuthors(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	m.scopeMu.Lock()
+	if m.scopeCache == nil {
+		m.scopeCache = make(map[Scope]scopedAuthors)
+	}
+	m.scopeCache[scope] = scopedAuthors{authors: authors, expires: time.Now().Add(scopeCacheTTL)}
+	m.scopeMu.Unlock()
+
+	// Callers narrow the list in place, so they get their own copy
+	return slices.Clone(authors), nil
+}
+
+// traverse walks contact lists breadth first from the owner, up to depth hops
+func (sfb *ScopeFilterBuilder) traverse(ctx context.Context, depth int) ([]string, error) {
+	authors := []string{sfb.ownerPubkey}
+	seen := map[string]bool{sfb.ownerPubkey: true}
+	frontier := []string{sfb.ownerPubkey}
+
+	for hop := 1; hop <= depth && len(frontier) > 0; hop++ {
+		follows, err := sfb.contactLists(ctx, frontier)
+		if err != nil {
+			return nil, err
+		}
+
+		var next []string
+		for _, pubkey := range frontier {
+			for _, followed := range follows[pubkey] {
+				if seen[followed] || slices.Contains(sfb.denylist, followed) {
+					continue
+				}
+				seen[followed] = true
+				next = append(next, followed)
+			}
+		}
+		authors = append(authors, next...)
+		frontier = next
+
+		// Without an allowlist the nearest authors fill the cap, so stop walking
+		if sfb.maxAuthors > 0 && len(sfb.allowlist) == 0 && len(authors) >= sfb.maxAuthors {
+			break
+		}
+	}
+	return authors, nil
+}
+
+// mutuals returns the owner and the follows who follow them back, from the
+// storage follows index
+func (sfb *ScopeFilterBuilder) mutuals(ctx context.Context) ([]string, error) {
+	authors := []string{sfb.ownerPubkey}
+	if sfb.storage == nil {
+		return authors, nil
+	}
+	mutuals, err := sfb.storage.GetMutuals(ctx, sfb.ownerPubkey)
+	if err != nil {
+		return nil, err
+	}
+	for _, pubkey := range mutuals {
+		if !slices.Contains(sfb.denylist, pubkey) {
+			authors = append(authors, pubkey)
+		}
+	}
+	return authors, nil
+}
+
+// contactLists returns the followed pubkeys in the newest stored contact list
+// of each author
+func (sfb *ScopeFilterBuilder) contactLists(ctx context.Context, pubkeys []string) (map[string][]string, error) {
+	follows := make(map[string][]string)
+	if sfb.storage == nil {
+		return follows, nil
+	}
+
+	newest := make(map[string]nostr.Timestamp)
+	for batch := range slices.Chunk(pubkeys, contactBatch) {
+		events, err := sfb.storage.QueryEvents(ctx, nostr.Filter{Kinds: []int{3}, Authors: batch})
+		if err != nil {
+			return nil, fmt.Errorf("failed to query contact lists: %w", err)
+		}
+		for _, event := range events {
+			if ts, ok := newest[event.PubKey]; ok && ts >= event.CreatedAt {
+				continue
+			}
+			newest[event.PubKey] = event.CreatedAt
+
+			var followed []string
+			for _, tag := range event.Tags {
+				if len(tag) >= 2 && tag[0] == "p" && nostr.IsValidPublicKey(tag[1]) {
+					followed = append(followed, tag[1])
+				}
+			}
+			follows[event.PubKey] = followed
+		}
+	}
+	return follows, nil
+}
+
+// applyLimits keeps the owner and the allowed authors, up to maxAuthors
+func (sfb *ScopeFilterBuilder) applyLimits(authors []string) []string {
+	filtered := authors[:0]
+	for _, author := range authors {
+		if author != sfb.ownerPubkey && len(sfb.allowlist) > 0 && !slices.Contains(sfb.allowlist, author) {
+			continue
+		}
+		filtered = append(filtered, author)
+		if sfb.maxAuthors > 0 && len(filtered) == sfb.maxAuthors {
+			break
+		}
+	}
+	return filtered
+}
@@ -0,0 +1,99 @@
+package sections
+
+import (
+	"context"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/nbd-wtf/go-nostr"
+	"github.com/sandwich/nophr/internal/config"
+	"github.com/sandwich/nophr/internal/storage"
+)
+
+func TestScopeTraversal(t *testing.T) {
+	ctx := context.Background()
+	st, err := storage.New(ctx, &config.Storage{Driver: "sqlite", SQLitePath: filepath.Join(t.TempDir(), "test.db")})
+	if err != nil {
+		t.Fatalf("Failed to create storage: %v", err)
+	}
+	defer st.Close()
+
+	key := func() string {
+		pubkey, _ := nostr.GetPublicKey(nostr.GeneratePrivateKey())
+		return pubkey
+	}
+	owner, alice, bob, carol, dave, erin := key(), key(), key(), key(), key(), key()
+
+	// owner follows alice and bob; alice follows back and follows carol;
+	// bob follows dave; carol follows erin
+	follow := func(author string, at nostr.Timestamp, followed ...string) {
+		event := &nostr.Event{Kind: 3, PubKey: author, CreatedAt: at}
+		for _, pubkey := range followed {
+			event.Tags = append(event.Tags, nostr.Tag{"p", pubkey})
+		}
+		event.ID = event.GetID()
+		if err := st.StoreEvent(ctx, event); err != nil {
+			t.Fatalf("Failed to store contact list: %v", err)
+		}
+	}
+	follow(owner, 1, alice, bob)
+	follow(alice, 1, owner, carol)
+	follow(bob, 1, dave)
+	follow(carol, 1, erin)
+
+	tests := []struct {
+		name      string
+		scope     Scope
+		depth     int
+		max       int
+		allowlist []string
+		denylist  []string
+		want      []string
+	}{
+		{"following", ScopeFollowing, 0, 0, nil, nil, []string{owner, alice, bob}},
+		{"mutual", ScopeMutual, 0, 0, nil, nil, []string{owner, alice}},
+		{"foaf defaults to two hops", ScopeFoaf, 0, 0, nil, nil, []string{owner, alice, bob, carol, dave}},
+		{"foaf three hops", ScopeFoaf, 3, 0, nil, nil, []string{owner, alice, bob, carol, dave, erin}},
+		{"max authors keeps the nearest", ScopeFoaf, 3, 4, nil, nil, []string{owner, alice, bob, carol}},
+		{"denied authors aren't walked through", ScopeFoaf, 3, 0, nil, []string{alice}, []string{owner, bob, dave}},
+		{"allowlist", ScopeFoaf, 3, 0, []string{erin, bob}, nil, []string{owner, bob, erin}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			authors, err := NewScopeFilterBuilder(st, owner, tt.scope, tt.depth).
+				Limits(tt.max, tt.allowlist, tt.denylist).
+				BuildAuthors(ctx)
+			if err != nil {
+				t.Fatalf("BuildAuthors() error = %v", err)
+			}
+			if !slices.Equal(authors, tt.want) {
+				t.Errorf("BuildAuthors() returned %d authors, want %d in order", len(authors), len(tt.want))
+			}
+		})
+	}
+
+	t.Run("manager caches the author set", func(t *testing.T) {
+		manager := NewManager(st)
+		manager.SetOwner(owner)
+		manager.SetScopeLimits(&config.SyncScope{})
+
+		first, err := manager.scopeAuthors(ctx, ScopeFollowing)
+		if err != nil || len(first) != 3 {
+			t.Fatalf("scopeAuthors() = %d authors, %v", len(first), err)
+		}
+
+		follow(owner, 2, alice, bob, carol)
+		cached, _ := manager.scopeAuthors(ctx, ScopeFollowing)
+		if len(cached) != 3 {
+			t.Errorf("Expected the cached author set, got %d authors", len(cached))
+		}
+
+		manager.SetScopeLimits(&config.SyncScope{})
+		fresh, _ := manager.scopeAuthors(ctx, ScopeFollowing)
+		if len(fresh) != 4 {
+			t.Errorf("Expected the new contact list after the cache was reset, got %d authors", len(fresh))
+		}
+	})
+}
@@ -2,16 +2,32 @@ package sections
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/nbd-wtf/go-nostr"
+	"github.com/sandwich/nophr/internal/aggregates"
+	"github.com/sandwich/nophr/internal/config"
 	"github.com/sandwich/nophr/internal/storage"
 )
 
+// rankPool is how many of a section's newest events are ranked when it sorts
+// by interactions rather than time
+const rankPool = 100
+
+// errEmptyScope is returned by buildFilter when no author is both in the
+// section's scope and its authors filter
+var errEmptyScope = errors.New("no authors in scope")
+
 // Section defines a content section with filtering and pagination
 type Section struct {
 	Name        string
+	Type        SectionType
 	Path        string // URL path (e.g., "/diy", "/philosophy", "/" for homepage)
 	Title       string
 	Description string
@@ -24,6 +40,8 @@ type Section struct {
 	GroupBy     GroupField
 	MoreLink    *MoreLink // Optional link to full paginated view
 	Order       int       // Display order when multiple sections share a path (lower numbers first)
+	MediaLinks  bool      // List each entry's media and links below it
+	Hidden      bool      // Left out of the home menus
 }
 
 // MoreLink defines a "more" link to a full paginated section view
@@ -39,8 +57,12 @@ type FilterSet struct {
 	Tags        map[string][]string
 	Since       *time.Time
 	Until       *time.Time
+	SinceOffset *time.Duration // Since relative to the time of each query, e.g. -7 days
+	UntilOffset *time.Duration
 	Search      string
 	Scope       Scope
+	Languages   []string // ISO 639-1 codes, matched against each event's detected language
+	Topics      []string // Topics from storage.annotations.topics
 }
 
 // SortField defines how to sort events
@@ -86,31 +108,51 @@ const (
 	ScopeAll       Scope = "all"
 )
 
-// Page represents a paginated section result
+// Page represents a paginated section result.
+// Section listings page by cursor (Before/Next); archive pages are numbered.
 type Page struct {
 	Section    *Section
 	Events     []*nostr.Event
+	Before     *aggregates.Cursor // Cursor this page was requested with (nil for the newest page)
+	Next       *aggregates.Cursor // Cursor for the next (older) page, nil if this is the last page
 	PageNumber int
 	TotalPages int
 	TotalItems int64
 	HasNext    bool
 	HasPrev    bool
+	Names      map[string]string   // Display names of the page's authors (planet sections)
+	CrossPosts map[string][]string // Event ID to the other authors who posted the same article (planet sections)
 }
 
 // Manager manages sections and their content
 type Manager struct {
 	storage  *storage.Storage
+	executor *storage.QueryExecutor
+	owner    string // Hex pubkey scopes are resolved from
+
+	mu       sync.RWMutex // Guards sections, which ReplaceSections swaps while serving
 	sections map[string]*Section
+
+	scopeMu     sync.Mutex
+	scopeLimits *config.SyncScope
+	scopeCache  map[Scope]scopedAuthors
 }
 
 // NewManager creates a new section manager
 func NewManager(st *storage.Storage) *Manager {
 	return &Manager{
 		storage:  st,
+		executor: storage.NewQueryExecutor(st, storage.DefaultQueryWorkers),
 		sections: make(map[string]*Section),
 	}
 }
 
+// SetOwner sets the pubkey section scopes are resolved from. Without an owner,
+// scopes other than all are ignored.
+func (m *Manager) SetOwner(pubkey string) {
+	m.owner = pubkey
+}
+
 // RegisterSection registers a section definition
 func (m *Manager) RegisterSection(section *Section) error {
 	if section.Name == "" {
@@ -121,12 +163,36 @@ func (m *Manager) RegisterSection(section *Section) error {
 		section.Limit = 20 // Default limit
 	}
 
+	m.mu.Lock()
+	defer m.mu.Unlock()
 	m.sections[section.Name] = section
 	return nil
 }
 
+// ReplaceSections swaps every registered section for sections at once, so
+// requests see either the old set or the new one
+func (m *Manager) ReplaceSections(sections []*Section) error {
+	replaced := make(map[string]*Section, len(sections))
+	for _, section := range sections {
+		if section.Name == "" {
+			return fmt.Errorf("section name is required")
+		}
+		if section.Limit == 0 {
+			section.Limit = 20 // Default limit
+		}
+		replaced[section.Name] = section
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.sections = replaced
+	return nil
+}
+
 // GetSection retrieves a section by name
 func (m *Manager) GetSection(name string) (*Section, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
 	section, exists := m.sections[name]
 	if !exists {
 		return nil, fmt.Errorf("section not found: %s", name)
@@ -136,6 +202,8 @@ func (m *Manager) GetSection(name string) (*Section, error) {
 
 // GetSectionByPath retrieves a section by its URL path (deprecated - use GetSectionsByPath for multiple sections)
 func (m *Manager) GetSectionByPath(path string) (*Section, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
 	for _, section := range m.sections {
 		if section.Path == path {
 			return section, nil
@@ -147,11 +215,13 @@ func (m *Manager) GetSectionByPath(path string) (*Section, error) {
 // GetSectionsByPath retrieves all sections for a given path, sorted by Order field
 func (m *Manager) GetSectionsByPath(path string) []*Section {
 	var matched []*Section
+	m.mu.RLock()
 	for _, section := range m.sections {
 		if section.Path == path {
 			matched = append(matched, section)
 		}
 	}
+	m.mu.RUnlock()
 
 	// Sort by Order field (lower numbers first)
 	for i := 0; i < len(matched)-1; i++ {
@@ -167,6 +237,8 @@ func (m *Manager) GetSectionsByPath(path string) []*Section {
 
 // ListSections returns all registered sections
 func (m *Manager) ListSections() []*Section {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
 	sections := make([]*Section, 0, len(m.sections))
 	for _, section := range m.sections {
 		sections = append(sections, section)
@@ -174,19 +246,61 @@ func (m *Manager) ListSections() []*Section {
 	return sections
 }
 
-// GetPage retrieves a page of events for a section
-func (m *Manager) GetPage(ctx context.Context, sectionName string, pageNum int) (*Page, error) {
+// MenuSections returns the sections to list on home menus: one per path other
+// than the home page, skipping hidden sections, by Order then path
+func (m *Manager) MenuSections() []*Section {
+	byPath := make(map[string]*Section)
+	m.mu.RLock()
+	for _, section := range m.sections {
+		if section.Path == "" || section.Path == "/" || section.Hidden {
+			continue
+		}
+		if first, ok := byPath[section.Path]; !ok || section.Order < first.Order ||
+			(section.Order == first.Order && section.Name < first.Name) {
+			byPath[section.Path] = section
+		}
+	}
+	m.mu.RUnlock()
+
+	menu := make([]*Section, 0, len(byPath))
+	for _, section := range byPath {
+		menu = append(menu, section)
+	}
+	sort.Slice(menu, func(i, j int) bool {
+		if menu[i].Order != menu[j].Order {
+			return menu[i].Order < menu[j].Order
+		}
+		return menu[i].Path < menu[j].Path
+	})
+	return menu
+}
+
+// MenuTitle returns the section's home menu entry
+func (s *Section) MenuTitle() string {
+	if s.Title != "" {
+		return s.Title
+	}
+	return s.Name
+}
+
+// GetPage retrieves the page of a section's events older than before (nil = newest)
+func (m *Manager) GetPage(ctx context.Context, sectionName string, before *aggregates.Cursor) (*Page, error) {
 	section, err := m.GetSection(sectionName)
 	if err != nil {
 		return nil, err
 	}
 
-	if pageNum < 1 {
-		pageNum = 1
-	}
-
 	// Build Nostr filter from section filters
-	filter := m.buildFilter(section, pageNum)
+	filter, err := m.buildFilter(ctx, section, before)
+	if errors.Is(err, errEmptyScope) {
+		return m.paginate(ctx, section, nil, before), nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if section.Type == TypeList {
+		return m.listPage(ctx, section, filter)
+	}
 
 	// Query events
 	events, err := m.storage.QueryEvents(ctx, filter)
@@ -194,94 +308,207 @@ func (m *Manager) GetPage(ctx context.Context, sectionName string, pageNum int)
 		return nil, fmt.Errorf("failed to query events: %w", err)
 	}
 
-	// Sort events
-	m.sortEvents(events, section.SortBy, section.SortOrder)
+	return m.paginate(ctx, section, events, before), nil
+}
+
+// GetPages retrieves the newest page of several sections, querying them concurrently.
+// Results and errors are returned in the order of sectionNames.
+func (m *Manager) GetPages(ctx context.Context, sectionNames []string) ([]*Page, []error) {
+	pages := make([]*Page, len(sectionNames))
+	errs := make([]error, len(sectionNames))
+
+	// Resolve sections first so unknown names don't take a worker slot
+	resolved := make([]*Section, 0, len(sectionNames))
+	indexes := make([]int, 0, len(sectionNames))
+	filters := make([]nostr.Filter, 0, len(sectionNames))
+	for i, name := range sectionNames {
+		section, err := m.GetSection(name)
+		if err != nil {
+			errs[i] = err
+			continue
+		}
+		if section.Type == TypeList {
+			pages[i], errs[i] = m.GetPage(ctx, name, nil)
+			continue
+		}
+		filter, err := m.buildFilter(ctx, section, nil)
+		if errors.Is(err, errEmptyScope) {
+			pages[i] = m.paginate(ctx, section, nil, nil)
+			continue
+		}
+		if err != nil {
+			errs[i] = err
+			continue
+		}
+		resolved = append(resolved, section)
+		indexes = append(indexes, i)
+		filters = append(filters, filter)
+	}
+
+	results := m.executor.Execute(ctx, filters)
+	for j, result := range results {
+		i := indexes[j]
+		if result.Err != nil {
+			errs[i] = fmt.Errorf("failed to query events: %w", result.Err)
+			continue
+		}
+		pages[i] = m.paginate(ctx, resolved[j], result.Events, nil)
+	}
+
+	return pages, errs
+}
+
+// Pageable reports whether a section pages by cursor. Cursors follow created_at
+// newest first, so sections sorted oldest first or by interactions, and list
+// sections, show a single page.
+func (s *Section) Pageable() bool {
+	return s.SortOrder != SortAsc && !s.SortBy.ranked() && s.Type != TypeList
+}
 
-	// Calculate pagination
-	offset := (pageNum - 1) * section.Limit
-	totalItems := int64(len(events))
-	totalPages := int((totalItems + int64(section.Limit) - 1) / int64(section.Limit))
+// ranked reports whether the field sorts by interactions rather than time
+func (f SortField) ranked() bool {
+	return f == SortByReactions || f == SortByZaps || f == SortByReplies
+}
 
-	// Extract page events
-	var pageEvents []*nostr.Event
-	if offset < len(events) {
-		end := offset + section.Limit
-		if end > len(events) {
-			end = len(events)
+// paginate sorts the queried events and extracts the page after before
+func (m *Manager) paginate(ctx context.Context, section *Section, events []*nostr.Event, before *aggregates.Cursor) *Page {
+	page := &Page{
+		Section: section,
+		Before:  before,
+	}
+	if section.Type == TypePlanet {
+		events, page.CrossPosts = dedupeCrossPosts(events)
+	}
+	page.TotalItems = int64(len(events))
+
+	if section.Pageable() {
+		page.Events, page.Next = aggregates.PageEvents(events, before, section.Limit)
+	} else {
+		m.sortEvents(ctx, events, section.SortBy, section.SortOrder)
+		if len(events) > section.Limit {
+			events = events[:section.Limit]
 		}
-		pageEvents = events[offset:end]
-	}
-
-	return &Page{
-		Section:    section,
-		Events:     pageEvents,
-		PageNumber: pageNum,
-		TotalPages: totalPages,
-		TotalItems: totalItems,
-		HasNext:    pageNum < totalPages,
-		HasPrev:    pageNum > 1,
-	}, nil
+		page.Events = events
+	}
+
+	page.HasNext = page.Next != nil
+	page.HasPrev = before != nil
+	if section.Type == TypePlanet {
+		m.attribute(ctx, page)
+	}
+	return page
 }
 
 // buildFilter converts section filters to Nostr filter
-func (m *Manager) buildFilter(section *Section, pageNum int) nostr.Filter {
-	filter := nostr.Filter{
-		Limit: section.Limit * pageNum, // Get all up to this page
+func (m *Manager) buildFilter(ctx context.Context, section *Section, before *aggregates.Cursor) (nostr.Filter, error) {
+	limit := section.Limit*2 + 1 // Room for events sharing the cursor's timestamp, plus one to detect an older page
+	if section.SortBy.ranked() {
+		limit = max(limit, rankPool)
 	}
+	fb := NewFilterBuilder().
+		Kinds(section.Filters.Kinds...).
+		Limit(limit)
 
-	if len(section.Filters.Kinds) > 0 {
-		filter.Kinds = section.Filters.Kinds
+	// Narrow to the authors in scope, keeping only those also listed in authors
+	authors := section.Filters.Authors
+	inScope, err := m.scopeAuthors(ctx, section.Filters.Scope)
+	if err != nil {
+		return fb.Build(), fmt.Errorf("failed to resolve scope of section %s: %w", section.Name, err)
 	}
-
-	if len(section.Filters.Authors) > 0 {
-		filter.Authors = section.Filters.Authors
+	if inScope != nil {
+		if len(authors) > 0 {
+			inScope = slices.DeleteFunc(inScope, func(pubkey string) bool {
+				return !slices.Contains(authors, pubkey)
+			})
+		}
+		if len(inScope) == 0 {
+			return fb.Build(), errEmptyScope
+		}
+		authors = inScope
 	}
+	fb.Authors(authors...)
 
+	// Relative times are resolved now so "-7d" keeps meaning the last week
+	now := time.Now()
 	if section.Filters.Since != nil {
-		since := nostr.Timestamp(section.Filters.Since.Unix())
-		filter.Since = &since
+		fb.Since(*section.Filters.Since)
+	} else if section.Filters.SinceOffset != nil {
+		fb.Since(now.Add(*section.Filters.SinceOffset))
 	}
 
+	var until *time.Time
 	if section.Filters.Until != nil {
-		until := nostr.Timestamp(section.Filters.Until.Unix())
-		filter.Until = &until
+		until = section.Filters.Until
+	} else if section.Filters.UntilOffset != nil {
+		t := now.Add(*section.Filters.UntilOffset)
+		until = &t
 	}
 
-	// Add tag filters
-	if len(section.Filters.Tags) > 0 {
-		filter.Tags = make(nostr.TagMap)
-		for key, values := range section.Filters.Tags {
-			filter.Tags[key] = values
-		}
+	// Until is inclusive; paginate drops events at or before the cursor itself
+	if before != nil && section.Pageable() && (until == nil || before.CreatedAt.Time().Before(*until)) {
+		t := before.CreatedAt.Time()
+		until = &t
+	}
+	if until != nil {
+		fb.Until(*until)
 	}
 
-	return filter
+	// Add tag filters, e.g. t: [photography]
+	for key, values := range section.Filters.Tags {
+		fb.Tag(key, values...)
+	}
+
+	// Languages and topics are search qualifiers, answered from annotations
+	search := []string{section.Filters.Search}
+	for _, lang := range section.Filters.Languages {
+		search = append(search, "lang:"+lang)
+	}
+	for _, topic := range section.Filters.Topics {
+		search = append(search, "topic:"+topic)
+	}
+	fb.Search(strings.TrimSpace(strings.Join(search, " ")))
+
+	return fb.Build(), nil
 }
 
-// sortEvents sorts events based on field and order
-func (m *Manager) sortEvents(events []*nostr.Event, field SortField, order SortOrder) {
-	// Simple bubble sort for now (can optimize later)
-	n := len(events)
-	for i := 0; i < n-1; i++ {
-		for j := 0; j < n-i-1; j++ {
-			shouldSwap := false
-
-			switch field {
-			case SortByCreatedAt, SortByPublishedAt:
-				if order == SortDesc {
-					shouldSwap = events[j].CreatedAt < events[j+1].CreatedAt
-				} else {
-					shouldSwap = events[j].CreatedAt > events[j+1].CreatedAt
-				}
-			// For other sort fields, would need aggregate data
-			// This is simplified for now
-			}
+// sortEvents sorts events based on field and order. Interaction sorts use the
+// stored aggregates and break ties newest first.
+func (m *Manager) sortEvents(ctx context.Context, events []*nostr.Event, field SortField, order SortOrder) {
+	var aggs map[string]*storage.Aggregate
+	if field.ranked() && m.storage != nil {
+		ids := make([]string, len(events))
+		for i, event := range events {
+			ids[i] = event.ID
+		}
+		aggs, _ = m.storage.GetAggregates(ctx, ids)
+	}
 
-			if shouldSwap {
-				events[j], events[j+1] = events[j+1], events[j]
-			}
+	score := func(event *nostr.Event) int64 {
+		agg := aggs[event.ID]
+		switch {
+		case !field.ranked():
+			return int64(event.CreatedAt)
+		case agg == nil:
+			return 0
+		case field == SortByReactions:
+			return int64(agg.ReactionTotal)
+		case field == SortByZaps:
+			return agg.ZapSatsTotal
+		default:
+			return int64(agg.ReplyCount)
 		}
 	}
+
+	sort.SliceStable(events, func(i, j int) bool {
+		a, b := score(events[i]), score(events[j])
+		if a == b {
+			return events[i].CreatedAt > events[j].CreatedAt
+		}
+		if order == SortAsc {
+			return a < b
+		}
+		return a > b
+	})
 }
 
 // DefaultSections returns commonly used section definitions
@@ -1,7 +1,14 @@
 package sections
 
 import (
+	"context"
+	"path/filepath"
 	"testing"
+	"time"
+
+	"github.com/nbd-wtf/go-nostr"
+	"github.com/sandwich/nophr/internal/config"
+	"github.com/sandwich/nophr/internal/storage"
 )
 
 func TestDefaultSections(t *testing.T) {
@@ -157,3 +164,140 @@ func TestArchiveFormatting(t *testing.T) {
 		}
 	})
 }
+
+func TestOwnerArchiveSelector(t *testing.T) {
+	month := &Archive{Period: ArchiveByMonth, Year: 2024, Month: 6}
+	if got := month.Selector(); got != "/archive/2024/06" {
+		t.Errorf("expected /archive/2024/06, got %s", got)
+	}
+
+	year := &Archive{Period: ArchiveByYear, Year: 2024}
+	if got := year.Selector(); got != "/archive/2024" {
+		t.Errorf("expected /archive/2024, got %s", got)
+	}
+}
+
+func TestParseArchivePath(t *testing.T) {
+	tests := []struct {
+		parts     []string
+		wantYear  int
+		wantMonth time.Month
+		wantErr   bool
+	}{
+		{nil, 0, 0, false},
+		{[]string{""}, 0, 0, false},
+		{[]string{"2024"}, 2024, 0, false},
+		{[]string{"2024", "06"}, 2024, time.June, false},
+		{[]string{"2024", "06", ""}, 2024, time.June, false},
+		{[]string{"24"}, 0, 0, true},
+		{[]string{"2024", "13"}, 0, 0, true},
+		{[]string{"2024", "june"}, 0, 0, true},
+		{[]string{"2024", "06", "01"}, 0, 0, true},
+	}
+
+	for _, tt := range tests {
+		year, month, err := ParseArchivePath(tt.parts)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ParseArchivePath(%q) error = %v, wantErr %v", tt.parts, err, tt.wantErr)
+			continue
+		}
+		if year != tt.wantYear || month != tt.wantMonth {
+			t.Errorf("ParseArchivePath(%q) = %d, %v, want %d, %v", tt.parts, year, month, tt.wantYear, tt.wantMonth)
+		}
+	}
+}
+
+func TestSectionScopeSortAndTimeRange(t *testing.T) {
+	ctx := context.Background()
+	st, err := storage.New(ctx, &config.Storage{Driver: "sqlite", SQLitePath: filepath.Join(t.TempDir(), "test.db")})
+	if err != nil {
+		t.Fatalf("Failed to create storage: %v", err)
+	}
+	defer st.Close()
+
+	owner, _ := nostr.GetPublicKey(nostr.GeneratePrivateKey())
+	friend, _ := nostr.GetPublicKey(nostr.GeneratePrivateKey())
+	stranger, _ := nostr.GetPublicKey(nostr.GeneratePrivateKey())
+
+	contacts := &nostr.Event{Kind: 3, PubKey: owner, CreatedAt: nostr.Now(), Tags: nostr.Tags{{"p", friend}}}
+	contacts.ID = contacts.GetID()
+	if err := st.StoreEvent(ctx, contacts); err != nil {
+		t.Fatalf("Failed to store contact list: %v", err)
+	}
+
+	now := time.Now()
+	notes := map[string]*nostr.Event{}
+	for name, spec := range map[string]struct {
+		author string
+		age    time.Duration
+	}{
+		"mine":     {owner, time.Hour},
+		"friends":  {friend, 2 * time.Hour},
+		"stranger": {stranger, 3 * time.Hour},
+		"old":      {owner, 10 * 24 * time.Hour},
+	} {
+		note := &nostr.Event{Kind: 1, PubKey: spec.author, CreatedAt: nostr.Timestamp(now.Add(-spec.age).Unix()), Content: name}
+		note.ID = note.GetID()
+		if err := st.StoreEvent(ctx, note); err != nil {
+			t.Fatalf("Failed to store event: %v", err)
+		}
+		notes[name] = note
+	}
+	st.SaveAggregate(ctx, &storage.Aggregate{EventID: notes["friends"].ID, ReactionTotal: 5})
+
+	manager := NewManager(st)
+	manager.SetOwner(owner)
+	if err := LoadFromConfig(manager, []config.SectionConfig{
+		{Name: "circle", Path: "/circle", Filters: config.SectionFilterConfig{Kinds: []int{1}, Scope: "following", Since: "-7d"}},
+		{Name: "popular", Path: "/popular", SortBy: "reactions", Filters: config.SectionFilterConfig{Kinds: []int{1}}},
+		{Name: "nobody", Path: "/nobody", Filters: config.SectionFilterConfig{Scope: "self", Authors: []string{stranger}}},
+	}); err != nil {
+		t.Fatalf("Failed to load sections: %v", err)
+	}
+
+	circle, err := manager.GetPage(ctx, "circle", nil)
+	if err != nil {
+		t.Fatalf("GetPage(circle) error = %v", err)
+	}
+	if len(circle.Events) != 2 || circle.Events[0].Content != "mine" || circle.Events[1].Content != "friends" {
+		t.Errorf("Expected the last week's notes from the owner and follows, got %d events", len(circle.Events))
+	}
+
+	popular, err := manager.GetPage(ctx, "popular", nil)
+	if err != nil {
+		t.Fatalf("GetPage(popular) error = %v", err)
+	}
+	if len(popular.Events) != 4 || popular.Events[0].Content != "friends" || popular.Events[1].Content != "mine" {
+		t.Errorf("Expected the most reacted note first, then newest first")
+	}
+	if popular.Section.Pageable() {
+		t.Error("Expected a section sorted by reactions not to page by cursor")
+	}
+
+	nobody, err := manager.GetPage(ctx, "nobody", nil)
+	if err != nil || len(nobody.Events) != 0 {
+		t.Errorf("Expected no events when authors and scope don't overlap, got %v, %v", nobody, err)
+	}
+}
+
+func TestMenuSections(t *testing.T) {
+	manager := NewManager(nil)
+	for _, section := range []*Section{
+		{Name: "preview", Path: "/", Title: "Preview"},
+		{Name: "diy-2", Path: "/diy", Title: "DIY (more)", Order: 2},
+		{Name: "diy", Path: "/diy", Title: "DIY", Order: 1},
+		{Name: "art", Path: "/art"},
+		{Name: "drafts", Path: "/drafts", Hidden: true},
+	} {
+		manager.RegisterSection(section)
+	}
+
+	menu := manager.MenuSections()
+	if len(menu) != 2 || menu[0].MenuTitle() != "art" || menu[1].MenuTitle() != "DIY" {
+		titles := []string{}
+		for _, section := range menu {
+			titles = append(titles, section.MenuTitle())
+		}
+		t.Errorf("MenuSections() = %v, want [art DIY]", titles)
+	}
+}
@@ -0,0 +1,89 @@
+package security
+
+import (
+	"net"
+	"sync"
+	"time"
+)
+
+// ClientLimiter enforces per-IP rate limits for the protocol servers,
+// temporarily banning clients that exceed them
+type ClientLimiter struct {
+	limiter     *RateLimiter
+	refill      time.Duration // Time for one token to refill
+	banDuration time.Duration
+	bans        map[string]time.Time // IP -> ban expiry
+	mu          sync.Mutex
+}
+
+// NewClientLimiter creates a per-IP limiter. A banDuration of 0 disables bans,
+// so limited clients are only rejected until their bucket refills.
+func NewClientLimiter(requestsPerMinute, burst int, banDuration time.Duration) *ClientLimiter {
+	return &ClientLimiter{
+		limiter:     NewBurstRateLimiter(requestsPerMinute, burst, time.Minute),
+		refill:      time.Minute / time.Duration(requestsPerMinute),
+		banDuration: banDuration,
+		bans:        make(map[string]time.Time),
+	}
+}
+
+// Check reports whether a request from ip is allowed.
+// When it is not, retryAfter says how long the client should wait.
+func (cl *ClientLimiter) Check(ip string) (allowed bool, retryAfter time.Duration) {
+	now := time.Now()
+
+	cl.mu.Lock()
+	defer cl.mu.Unlock()
+
+	if until, banned := cl.bans[ip]; banned {
+		if now.Before(until) {
+			return false, until.Sub(now)
+		}
+		delete(cl.bans, ip)
+	}
+
+	if cl.limiter.Allow(ip) {
+		return true, 0
+	}
+
+	if cl.banDuration <= 0 {
+		return false, cl.refill
+	}
+
+	cl.bans[ip] = now.Add(cl.banDuration)
+	cl.pruneBans(now)
+
+	return false, cl.banDuration
+}
+
+// IsBanned returns true if ip is currently banned
+func (cl *ClientLimiter) IsBanned(ip string) bool {
+	cl.mu.Lock()
+	defer cl.mu.Unlock()
+
+	until, banned := cl.bans[ip]
+	return banned && time.Now().Before(until)
+}
+
+// pruneBans drops expired bans; caller must hold cl.mu
+func (cl *ClientLimiter) pruneBans(now time.Time) {
+	for ip, until := range cl.bans {
+		if !now.Before(until) {
+			delete(cl.bans, ip)
+		}
+	}
+}
+
+// Close stops the underlying rate limiter
+func (cl *ClientLimiter) Close() {
+	cl.limiter.Close()
+}
+
+// ClientIP extracts the IP address from a connection's remote address
+func ClientIP(addr net.Addr) string {
+	host, _, err := net.SplitHostPort(addr.String())
+	if err != nil {
+		return addr.String()
+	}
+	return host
+}
@@ -10,6 +10,7 @@ import (
 // RateLimiter implements token bucket rate limiting
 type RateLimiter struct {
 	rate     int           // Requests per window
+	burst    int           // Bucket capacity
 	window   time.Duration // Time window
 	buckets  map[string]*bucket
 	mu       sync.RWMutex
@@ -26,8 +27,15 @@ type bucket struct {
 
 // NewRateLimiter creates a new rate limiter
 func NewRateLimiter(rate int, window time.Duration) *RateLimiter {
+	return NewBurstRateLimiter(rate, rate, window)
+}
+
+// NewBurstRateLimiter creates a rate limiter whose bucket holds burst tokens
+// and refills at rate tokens per window
+func NewBurstRateLimiter(rate, burst int, window time.Duration) *RateLimiter {
 	rl := &RateLimiter{
 		rate:            rate,
+		burst:           burst,
 		window:          window,
 		buckets:         make(map[string]*bucket),
 		cleanupInterval: 5 * time.Minute,
@@ -49,7 +57,7 @@ func (rl *RateLimiter) Allow(clientID string) bool {
 	if !exists {
 		// Create new bucket
 		b = &bucket{
-			tokens:     rl.rate,
+			tokens:     rl.burst,
 			lastRefill: time.Now(),
 		}
 
@@ -67,14 +75,14 @@ func (rl *RateLimiter) Allow(clientID string) bool {
 
 	if elapsed >= rl.window {
 		// Full refill
-		b.tokens = rl.rate
+		b.tokens = rl.burst
 		b.lastRefill = now
 	} else {
 		// Partial refill
 		tokensToAdd := int(float64(rl.rate) * (float64(elapsed) / float64(rl.window)))
 		b.tokens += tokensToAdd
-		if b.tokens > rl.rate {
-			b.tokens = rl.rate
+		if b.tokens > rl.burst {
+			b.tokens = rl.burst
 		}
 		if tokensToAdd > 0 {
 			b.lastRefill = now
@@ -105,7 +113,7 @@ func (rl *RateLimiter) GetLimit(clientID string) (remaining int, resetTime time.
 	rl.mu.RUnlock()
 
 	if !exists {
-		return rl.rate, time.Now()
+		return rl.burst, time.Now()
 	}
 
 	b.mu.Lock()
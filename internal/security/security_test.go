@@ -116,6 +116,51 @@ func TestRateLimiter(t *testing.T) {
 	})
 }
 
+func TestClientLimiter(t *testing.T) {
+	t.Run("Burst then ban", func(t *testing.T) {
+		cl := NewClientLimiter(60, 3, time.Minute)
+		defer cl.Close()
+
+		for i := 0; i < 3; i++ {
+			if allowed, _ := cl.Check("1.2.3.4"); !allowed {
+				t.Errorf("request %d should be allowed within burst", i+1)
+			}
+		}
+
+		allowed, retryAfter := cl.Check("1.2.3.4")
+		if allowed {
+			t.Error("request beyond burst should be denied")
+		}
+		if retryAfter != time.Minute {
+			t.Errorf("expected retry after ban duration, got %v", retryAfter)
+		}
+		if !cl.IsBanned("1.2.3.4") {
+			t.Error("client should be banned after exceeding limit")
+		}
+
+		if allowed, _ := cl.Check("5.6.7.8"); !allowed {
+			t.Error("other clients should not be affected by a ban")
+		}
+	})
+
+	t.Run("No ban", func(t *testing.T) {
+		cl := NewClientLimiter(60, 1, 0)
+		defer cl.Close()
+
+		cl.Check("1.2.3.4")
+		allowed, retryAfter := cl.Check("1.2.3.4")
+		if allowed {
+			t.Error("second request should be denied")
+		}
+		if retryAfter != time.Second {
+			t.Errorf("expected retry after one refill interval, got %v", retryAfter)
+		}
+		if cl.IsBanned("1.2.3.4") {
+			t.Error("client should not be banned when bans are disabled")
+		}
+	})
+}
+
 func TestValidator(t *testing.T) {
 	v := NewValidator()
 
@@ -125,9 +170,11 @@ func TestValidator(t *testing.T) {
 			valid    bool
 		}{
 			{"/valid/selector", true},
-			{"/selector\r\n", false},      // CRLF injection
-			{"/../etc/passwd", false},     // Directory traversal
-			{"/selector\x00", false},      // Null byte
+			{"/selector\r\n", false},  // CRLF injection
+			{"/../etc/passwd", false}, // Directory traversal
+			{"/notes/..", false},      // Traversal as the last segment
+			{"/search/wait...", true}, // Dots inside a segment
+			{"/selector\x00", false},  // Null byte
 			{"/normal", true},
 		}
 
@@ -197,6 +244,55 @@ func TestValidator(t *testing.T) {
 		}
 	})
 
+	t.Run("Result limits", func(t *testing.T) {
+		if err := v.ValidateResultLimit(50, 100); err != nil {
+			t.Errorf("50 should be within cap 100: %v", err)
+		}
+		if err := v.ValidateResultLimit(500, 100); err == nil {
+			t.Error("500 should exceed cap 100")
+		}
+		if got := v.CapResultLimit(500, 100); got != 100 {
+			t.Errorf("CapResultLimit(500, 100) = %d, want 100", got)
+		}
+		if got := v.CapResultLimit(0, 100); got != 100 {
+			t.Errorf("CapResultLimit(0, 100) = %d, want 100", got)
+		}
+		if got := v.CapResultLimit(20, 100); got != 20 {
+			t.Errorf("CapResultLimit(20, 100) = %d, want 20", got)
+		}
+		if got := v.CapResultLimit(0, 0); got != MaxResultLimit {
+			t.Errorf("CapResultLimit(0, 0) = %d, want %d", got, MaxResultLimit)
+		}
+	})
+
+	t.Run("URL validation", func(t *testing.T) {
+		tests := []struct {
+			url   string
+			valid bool
+		}{
+			{"https://example.com/page", true},
+			{"https://bücher.de/katalog", true}, // IDN host
+			{"gemini://xn--bcher-kva.de/", true}, // Punycode host
+			{"https://[::1]:8080/", true},
+			{"ftp://example.com", false},
+			{"https://exa mple.com", false},
+		}
+
+		for _, tt := range tests {
+			err := v.ValidateURL(tt.url)
+			if tt.valid && err != nil {
+				t.Errorf("URL '%s' should be valid, got error: %v", tt.url, err)
+			}
+			if !tt.valid && err == nil {
+				t.Errorf("URL '%s' should be invalid", tt.url)
+			}
+		}
+
+		if err := v.ValidateHost("例え.テスト"); err != nil {
+			t.Errorf("IDN host should be valid, got error: %v", err)
+		}
+	})
+
 	t.Run("Sanitization", func(t *testing.T) {
 		input := "test\r\n\x00"
 		sanitized := v.SanitizeInput(input)
@@ -207,6 +303,39 @@ func TestValidator(t *testing.T) {
 	})
 }
 
+func TestInputSanitizer(t *testing.T) {
+	is := NewInputSanitizer()
+
+	selector, err := is.SanitizeAndValidateSelector(" /notes ")
+	if err != nil {
+		t.Fatalf("valid selector rejected: %v", err)
+	}
+	if selector != "/notes" {
+		t.Errorf("expected '/notes', got '%s'", selector)
+	}
+
+	// Control characters must be rejected, not stripped
+	if _, err := is.SanitizeAndValidateSelector("/notes\r\n/evil"); err == nil {
+		t.Error("selector with CRLF should be rejected")
+	}
+	if _, err := is.SanitizeAndValidatePath("/note/\x00"); err == nil {
+		t.Error("path with null byte should be rejected")
+	}
+	if _, err := is.SanitizeAndValidateQuery("search\nterm"); err == nil {
+		t.Error("query with newline should be rejected")
+	}
+	if _, err := is.SanitizeAndValidateFingerQuery("alice\x00"); err == nil {
+		t.Error("finger query with null byte should be rejected")
+	}
+
+	if err := is.ValidateFingerUsername("alice;rm"); err == nil {
+		t.Error("username with shell characters should be rejected")
+	}
+	if err := is.ValidateEventID("not-an-id"); err == nil {
+		t.Error("malformed event ID should be rejected")
+	}
+}
+
 func TestSecretManager(t *testing.T) {
 	t.Run("Basic secret management", func(t *testing.T) {
 		sm := NewSecretManager()
@@ -2,9 +2,12 @@ package security
 
 import (
 	"fmt"
+	"net"
 	"net/url"
 	"regexp"
 	"strings"
+
+	"github.com/sandwich/nophr/internal/idn"
 )
 
 // Validator provides input validation functions
@@ -40,7 +43,7 @@ func (v *Validator) ValidateGopherSelector(selector string) error {
 	}
 
 	// Check for directory traversal
-	if strings.Contains(selector, "..") {
+	if hasTraversal(selector) {
 		return fmt.Errorf("selector contains directory traversal")
 	}
 
@@ -60,13 +63,24 @@ func (v *Validator) ValidateGeminiPath(path string) error {
 	}
 
 	// Check for directory traversal
-	if strings.Contains(path, "..") {
+	if hasTraversal(path) {
 		return fmt.Errorf("path contains directory traversal")
 	}
 
 	return nil
 }
 
+// hasTraversal reports whether a path has a ".." segment. Dots elsewhere, such as
+// "..." in a search query, are allowed.
+func hasTraversal(path string) bool {
+	for _, segment := range strings.FieldsFunc(path, func(r rune) bool { return r == '/' || r == '\\' }) {
+		if segment == ".." {
+			return true
+		}
+	}
+	return false
+}
+
 // ValidateGeminiQuery validates a Gemini query string
 func (v *Validator) ValidateGeminiQuery(query string) error {
 	if len(query) > v.maxQueryLength {
@@ -192,9 +206,33 @@ func (v *Validator) ValidatePageNumber(page int) error {
 	return v.ValidateInteger(page, 1, 10000)
 }
 
+// MaxResultLimit is the ceiling for any single query limit
+const MaxResultLimit = 1000
+
 // ValidateLimit validates a query limit
 func (v *Validator) ValidateLimit(limit int) error {
-	return v.ValidateInteger(limit, 1, 1000)
+	return v.ValidateInteger(limit, 1, MaxResultLimit)
+}
+
+// ValidateResultLimit validates how many events one request may load against a route's hard cap
+func (v *Validator) ValidateResultLimit(limit, max int) error {
+	if err := v.ValidateInteger(limit, 1, max); err != nil {
+		return fmt.Errorf("result limit exceeds route cap: %w", err)
+	}
+	return nil
+}
+
+// CapResultLimit clamps a route's result limit to its hard cap.
+// Limits that are unset or out of range fall back to the cap, and an unset
+// cap falls back to MaxResultLimit so a query is never left unbounded.
+func (v *Validator) CapResultLimit(limit, max int) int {
+	if max < 1 || max > MaxResultLimit {
+		max = MaxResultLimit
+	}
+	if v.ValidateResultLimit(limit, max) != nil {
+		return max
+	}
+	return limit
 }
 
 // IsSafeHTML checks if HTML contains no script tags
@@ -230,11 +268,24 @@ func (v *Validator) ValidateURL(rawURL string) error {
 		return fmt.Errorf("invalid URL scheme: %s", u.Scheme)
 	}
 
+	// Check host; IP literals are left to url.Parse
+	if host := u.Hostname(); host != "" && net.ParseIP(host) == nil {
+		if err := v.ValidateHost(host); err != nil {
+			return fmt.Errorf("invalid URL host: %w", err)
+		}
+	}
+
 	return nil
 }
 
-// ValidateHost validates a hostname
+// ValidateHost validates a hostname. Internationalized names are checked
+// in their punycode form.
 func (v *Validator) ValidateHost(host string) error {
+	host, err := idn.ToASCII(host)
+	if err != nil {
+		return fmt.Errorf("invalid hostname: %w", err)
+	}
+
 	if len(host) > 253 {
 		return fmt.Errorf("hostname too long")
 	}
@@ -265,20 +316,21 @@ func NewInputSanitizer() *InputSanitizer {
 	}
 }
 
-// SanitizeAndValidateSelector sanitizes and validates a Gopher selector
+// SanitizeAndValidateSelector validates a Gopher selector and trims surrounding whitespace.
+// Control characters are rejected rather than stripped, so injection attempts fail loudly.
 func (is *InputSanitizer) SanitizeAndValidateSelector(selector string) (string, error) {
-	selector = is.validator.SanitizeInput(selector)
-
 	if err := is.validator.ValidateGopherSelector(selector); err != nil {
 		return "", err
 	}
 
-	return selector, nil
+	return strings.TrimSpace(selector), nil
 }
 
-// SanitizeAndValidatePath sanitizes and validates a Gemini path
+// SanitizeAndValidatePath validates a Gemini path, keeping the leading slash for routing
 func (is *InputSanitizer) SanitizeAndValidatePath(path string) (string, error) {
-	path = is.validator.SanitizePath(path)
+	if strings.Contains(path, "\x00") {
+		return "", fmt.Errorf("path contains null bytes")
+	}
 
 	if err := is.validator.ValidateGeminiPath(path); err != nil {
 		return "", err
@@ -287,13 +339,53 @@ func (is *InputSanitizer) SanitizeAndValidatePath(path string) (string, error) {
 	return path, nil
 }
 
-// SanitizeAndValidateQuery sanitizes and validates a query string
+// SanitizeAndValidateQuery validates a query string and trims surrounding whitespace
 func (is *InputSanitizer) SanitizeAndValidateQuery(query string) (string, error) {
-	query = is.validator.SanitizeInput(query)
+	if strings.Contains(query, "\x00") {
+		return "", fmt.Errorf("query contains null bytes")
+	}
 
 	if err := is.validator.ValidateGeminiQuery(query); err != nil {
 		return "", err
 	}
 
-	return query, nil
+	return strings.TrimSpace(query), nil
+}
+
+// SanitizeAndValidateFingerQuery validates a raw Finger query line
+func (is *InputSanitizer) SanitizeAndValidateFingerQuery(query string) (string, error) {
+	if len(query) > is.validator.maxSelectorLength {
+		return "", fmt.Errorf("query too long: %d > %d", len(query), is.validator.maxSelectorLength)
+	}
+
+	if strings.ContainsAny(query, "\x00\r\n") {
+		return "", fmt.Errorf("query contains control characters")
+	}
+
+	return strings.TrimSpace(query), nil
+}
+
+// ValidateFingerUsername validates the user part of a Finger query
+func (is *InputSanitizer) ValidateFingerUsername(username string) error {
+	return is.validator.ValidateFingerUsername(username)
+}
+
+// ValidateEventID validates an event ID taken from a request
+func (is *InputSanitizer) ValidateEventID(eventID string) error {
+	return is.validator.ValidateEventID(eventID)
+}
+
+// ValidatePubkey validates a pubkey taken from a request
+func (is *InputSanitizer) ValidatePubkey(pubkey string) error {
+	return is.validator.ValidatePubkey(pubkey)
+}
+
+// ValidatePageNumber validates a page number taken from a request
+func (is *InputSanitizer) ValidatePageNumber(page int) error {
+	return is.validator.ValidatePageNumber(page)
+}
+
+// CapResultLimit clamps a route's result limit to its hard cap
+func (is *InputSanitizer) CapResultLimit(limit, max int) int {
+	return is.validator.CapResultLimit(limit, max)
 }
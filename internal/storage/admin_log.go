@@ -0,0 +1,79 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// Admin log entry types
+const (
+	AdminLogStartup   = "startup"   // The instance started, with its version and configuration hash
+	AdminLogShutdown  = "shutdown"  // The instance stopped cleanly
+	AdminLogStart     = "start"     // A subsystem started
+	AdminLogStop      = "stop"      // A subsystem stopped
+	AdminLogRecovered = "recovered" // The previous run ended without a clean shutdown
+	AdminLogCrash     = "crash"     // The instance stopped on a panic
+	AdminLogMigration = "migration" // A schema migration ran
+)
+
+// maxAdminLogEntries is how many admin log entries are kept; older ones are
+// dropped as new ones are written
+const maxAdminLogEntries = 10000
+
+// AdminLogEntry is one lifecycle event of the instance
+type AdminLogEntry struct {
+	ID        int64     `json:"id"`
+	Time      time.Time `json:"time"`
+	Type      string    `json:"type"`
+	Subsystem string    `json:"subsystem,omitempty"`
+	Message   string    `json:"message"`
+}
+
+// LogAdminEvent appends an entry to the admin log
+func (s *Storage) LogAdminEvent(ctx context.Context, entryType, subsystem, message string) error {
+	result, err := s.db.ExecContext(ctx, `
+		INSERT INTO admin_log (created_at, type, subsystem, message)
+		VALUES (?, ?, ?, ?)`,
+		time.Now().Unix(), entryType, subsystem, message)
+	if err != nil {
+		return fmt.Errorf("failed to write admin log: %w", err)
+	}
+	if id, err := result.LastInsertId(); err == nil && id > maxAdminLogEntries {
+		if _, err := s.db.ExecContext(ctx, `DELETE FROM admin_log WHERE id <= ?`, id-maxAdminLogEntries); err != nil {
+			return fmt.Errorf("failed to trim admin log: %w", err)
+		}
+	}
+	return nil
+}
+
+// GetAdminLog returns up to limit admin log entries, newest first, older than
+// the entry with ID before when before is positive
+func (s *Storage) GetAdminLog(ctx context.Context, limit int, before int64) ([]AdminLogEntry, error) {
+	query := `SELECT id, created_at, type, subsystem, message FROM admin_log`
+	args := []any{}
+	if before > 0 {
+		query += ` WHERE id < ?`
+		args = append(args, before)
+	}
+	query += ` ORDER BY id DESC LIMIT ?`
+	args = append(args, limit)
+
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query admin log: %w", err)
+	}
+	defer rows.Close()
+
+	var entries []AdminLogEntry
+	for rows.Next() {
+		var entry AdminLogEntry
+		var createdAt int64
+		if err := rows.Scan(&entry.ID, &createdAt, &entry.Type, &entry.Subsystem, &entry.Message); err != nil {
+			return nil, fmt.Errorf("failed to scan admin log: %w", err)
+		}
+		entry.Time = time.Unix(createdAt, 0)
+		entries = append(entries, entry)
+	}
+	return entries, rows.Err()
+}
@@ -2,8 +2,10 @@ package storage
 
 import (
 	"context"
+	"database/sql"
 	"encoding/json"
 	"fmt"
+	"time"
 )
 
 // Aggregate represents interaction rollups for an event
@@ -50,7 +52,7 @@ func (s *Storage) SaveAggregate(ctx context.Context, agg *Aggregate) error {
 // GetAggregate retrieves an aggregate for a given event ID
 func (s *Storage) GetAggregate(ctx context.Context, eventID string) (*Aggregate, error) {
 	query := `
-		SELECT event_id, reply_count, reaction_total, reaction_counts_json,
+		SELECT event_id, reply_count, reaction_total, COALESCE(reaction_counts_json, ''),
 		       zap_sats_total, last_interaction_at
 		FROM aggregates
 		WHERE event_id = ?
@@ -96,7 +98,7 @@ func (s *Storage) GetAggregates(ctx context.Context, eventIDs []string) (map[str
 	}
 
 	query := fmt.Sprintf(`
-		SELECT event_id, reply_count, reaction_total, reaction_counts_json,
+		SELECT event_id, reply_count, reaction_total, COALESCE(reaction_counts_json, ''),
 		       zap_sats_total, last_interaction_at
 		FROM aggregates
 		WHERE event_id IN (%s)
@@ -138,6 +140,54 @@ func (s *Storage) GetAggregates(ctx context.Context, eventIDs []string) (map[str
 	return aggregates, nil
 }
 
+// IsInteractionSeen reports whether an interaction event (reply, reaction or
+// zap) has already been counted toward its target's aggregate
+func (s *Storage) IsInteractionSeen(ctx context.Context, interactionID string) (bool, error) {
+	var exists int
+	err := s.db.QueryRowContext(ctx,
+		`SELECT 1 FROM aggregates_seen WHERE interaction_id = ?`, interactionID).Scan(&exists)
+	if err == sql.ErrNoRows {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to check seen interaction: %w", err)
+	}
+	return true, nil
+}
+
+// MarkInteractionsSeen records interactions (interaction ID -> target event ID)
+// as counted. Call it only after their counts were saved, so a failed update
+// is counted again when the interaction is re-ingested.
+func (s *Storage) MarkInteractionsSeen(ctx context.Context, interactions map[string]string) error {
+	if len(interactions) == 0 {
+		return nil
+	}
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	stmt, err := tx.PrepareContext(ctx, `
+		INSERT OR IGNORE INTO aggregates_seen (interaction_id, target_id, seen_at)
+		VALUES (?, ?, ?)
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to prepare statement: %w", err)
+	}
+	defer stmt.Close()
+
+	now := time.Now().Unix()
+	for interactionID, targetID := range interactions {
+		if _, err := stmt.ExecContext(ctx, interactionID, targetID, now); err != nil {
+			return fmt.Errorf("failed to mark interaction seen: %w", err)
+		}
+	}
+
+	return tx.Commit()
+}
+
 // IncrementReplyCount increments the reply count for an event
 func (s *Storage) IncrementReplyCount(ctx context.Context, eventID string, interactionAt int64) error {
 	query := `
@@ -197,13 +247,18 @@ func (s *Storage) AddZapAmount(ctx context.Context, eventID string, sats int64,
 	return nil
 }
 
-// DeleteAggregate removes an aggregate
+// DeleteAggregate removes an aggregate and forgets which interactions were counted
+// toward it, so they are counted again if re-ingested
 func (s *Storage) DeleteAggregate(ctx context.Context, eventID string) error {
 	query := `DELETE FROM aggregates WHERE event_id = ?`
 	_, err := s.db.ExecContext(ctx, query, eventID)
 	if err != nil {
 		return fmt.Errorf("failed to delete aggregate: %w", err)
 	}
+
+	if _, err := s.db.ExecContext(ctx, `DELETE FROM aggregates_seen WHERE target_id = ?`, eventID); err != nil {
+		return fmt.Errorf("failed to delete seen interactions: %w", err)
+	}
 	return nil
 }
 
@@ -316,3 +371,77 @@ func (s *Storage) BatchIncrementReactions(ctx context.Context, updates map[strin
 
 	return tx.Commit()
 }
+
+// ListAggregateIDs returns the IDs of all events that have an aggregate
+func (s *Storage) ListAggregateIDs(ctx context.Context) ([]string, error) {
+	rows, err := s.db.QueryContext(ctx, `SELECT event_id FROM aggregates ORDER BY event_id`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list aggregates: %w", err)
+	}
+	defer rows.Close()
+
+	var ids []string
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("failed to scan aggregate ID: %w", err)
+		}
+		ids = append(ids, id)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("row iteration error: %w", err)
+	}
+
+	return ids, nil
+}
+
+// ReplaceAggregates overwrites aggregates in one transaction. An aggregate with
+// no replies, reactions or zaps is deleted, along with its seen interactions.
+func (s *Storage) ReplaceAggregates(ctx context.Context, aggs []*Aggregate) error {
+	if len(aggs) == 0 {
+		return nil
+	}
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	for _, agg := range aggs {
+		if agg.ReplyCount == 0 && agg.ReactionTotal == 0 && agg.ZapSatsTotal == 0 {
+			if _, err := tx.ExecContext(ctx, `DELETE FROM aggregates WHERE event_id = ?`, agg.EventID); err != nil {
+				return fmt.Errorf("failed to delete aggregate for %s: %w", agg.EventID, err)
+			}
+			if _, err := tx.ExecContext(ctx, `DELETE FROM aggregates_seen WHERE target_id = ?`, agg.EventID); err != nil {
+				return fmt.Errorf("failed to delete seen interactions for %s: %w", agg.EventID, err)
+			}
+			continue
+		}
+
+		reactionCountsJSON, err := json.Marshal(agg.ReactionCounts)
+		if err != nil {
+			return fmt.Errorf("failed to marshal reaction counts: %w", err)
+		}
+		_, err = tx.ExecContext(ctx, `
+			INSERT INTO aggregates (
+				event_id, reply_count, reaction_total, reaction_counts_json,
+				zap_sats_total, last_interaction_at
+			)
+			VALUES (?, ?, ?, ?, ?, ?)
+			ON CONFLICT(event_id) DO UPDATE SET
+				reply_count = excluded.reply_count,
+				reaction_total = excluded.reaction_total,
+				reaction_counts_json = excluded.reaction_counts_json,
+				zap_sats_total = excluded.zap_sats_total,
+				last_interaction_at = excluded.last_interaction_at
+		`, agg.EventID, agg.ReplyCount, agg.ReactionTotal, string(reactionCountsJSON),
+			agg.ZapSatsTotal, agg.LastInteractionAt)
+		if err != nil {
+			return fmt.Errorf("failed to save aggregate for %s: %w", agg.EventID, err)
+		}
+	}
+
+	return tx.Commit()
+}
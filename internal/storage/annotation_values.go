@@ -0,0 +1,101 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/sandwich/nophr/internal/annotate"
+)
+
+// SetAnnotation sets the value of one keyed annotation of a stored event,
+// replacing its previous value
+func (s *Storage) SetAnnotation(ctx context.Context, eventID, namespace, key, value string) error {
+	if namespace == "" || key == "" {
+		return fmt.Errorf("annotation namespace and key are required")
+	}
+	_, err := s.db.ExecContext(ctx, `
+		INSERT INTO annotation_values (event_id, namespace, key, value, updated_at)
+		VALUES (?, ?, ?, ?, ?)
+		ON CONFLICT (event_id, namespace, key) DO UPDATE SET
+			value = excluded.value,
+			updated_at = excluded.updated_at`,
+		eventID, namespace, key, value, time.Now().Unix())
+	if err != nil {
+		return fmt.Errorf("failed to set annotation %s.%s of %s: %w", namespace, key, eventID, err)
+	}
+	return nil
+}
+
+// SetAnnotations replaces all of a stored event's keyed annotations in namespace
+func (s *Storage) SetAnnotations(ctx context.Context, eventID, namespace string, values map[string]string) error {
+	if namespace == "" {
+		return fmt.Errorf("annotation namespace is required")
+	}
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if err := replaceAnnotationValues(ctx, tx, eventID, namespace, values); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// DeleteAnnotations removes an event's keyed annotations in namespace
+func (s *Storage) DeleteAnnotations(ctx context.Context, eventID, namespace string) error {
+	if _, err := s.db.ExecContext(ctx,
+		"DELETE FROM annotation_values WHERE event_id = ? AND namespace = ?",
+		eventID, namespace); err != nil {
+		return fmt.Errorf("failed to delete annotations %s of %s: %w", namespace, eventID, err)
+	}
+	return nil
+}
+
+// GetAnnotationValues returns all of an event's keyed annotations
+func (s *Storage) GetAnnotationValues(ctx context.Context, eventID string) (annotate.Values, error) {
+	rows, err := s.db.QueryContext(ctx,
+		"SELECT namespace, key, value FROM annotation_values WHERE event_id = ?", eventID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get annotation values: %w", err)
+	}
+	defer rows.Close()
+
+	values := make(annotate.Values)
+	for rows.Next() {
+		var namespace, key, value string
+		if err := rows.Scan(&namespace, &key, &value); err != nil {
+			return nil, fmt.Errorf("failed to scan annotation value: %w", err)
+		}
+		if values[namespace] == nil {
+			values[namespace] = make(map[string]string)
+		}
+		values[namespace][key] = value
+	}
+	return values, rows.Err()
+}
+
+// replaceAnnotationValues replaces an event's keyed annotations in namespace within tx
+func replaceAnnotationValues(ctx context.Context, tx *sql.Tx, eventID, namespace string, values map[string]string) error {
+	if _, err := tx.ExecContext(ctx,
+		"DELETE FROM annotation_values WHERE event_id = ? AND namespace = ?",
+		eventID, namespace); err != nil {
+		return fmt.Errorf("failed to clear annotations %s of %s: %w", namespace, eventID, err)
+	}
+	now := time.Now().Unix()
+	for key, value := range values {
+		if key == "" {
+			return fmt.Errorf("annotation key is required")
+		}
+		if _, err := tx.ExecContext(ctx, `
+			INSERT INTO annotation_values (event_id, namespace, key, value, updated_at)
+			VALUES (?, ?, ?, ?, ?)`,
+			eventID, namespace, key, value, now); err != nil {
+			return fmt.Errorf("failed to set annotation %s.%s of %s: %w", namespace, key, eventID, err)
+		}
+	}
+	return nil
+}
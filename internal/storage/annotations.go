@@ -0,0 +1,283 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/nbd-wtf/go-nostr"
+	"github.com/sandwich/nophr/internal/annotate"
+)
+
+// annotationBatch is how many events are classified per transaction when rebuilding
+const annotationBatch = 500
+
+// AnnotationCount is how many events carry one annotation value
+type AnnotationCount struct {
+	Value  string
+	Events int
+}
+
+// indexAnnotations classifies a newly stored note or article. It runs as a
+// relay StoreEvent handler after the eventstore, so events from sync, imports
+// and relay writes are annotated as they are ingested.
+func (s *Storage) indexAnnotations(ctx context.Context, event *nostr.Event) error {
+	if !annotate.Annotates(event.Kind) {
+		return nil
+	}
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if err := s.saveAnnotations(ctx, tx, event); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// saveAnnotations replaces an event's annotations, and an article's reading
+// time, within tx
+func (s *Storage) saveAnnotations(ctx context.Context, tx *sql.Tx, event *nostr.Event) error {
+	if _, err := tx.ExecContext(ctx, "DELETE FROM event_annotations WHERE event_id = ?", event.ID); err != nil {
+		return fmt.Errorf("failed to clear annotations of %s: %w", event.ID, err)
+	}
+	for _, a := range s.annotator.Annotate(event) {
+		if _, err := tx.ExecContext(ctx, `
+			INSERT OR IGNORE INTO event_annotations (event_id, namespace, value, created_at)
+			VALUES (?, ?, ?, ?)`,
+			event.ID, a.Namespace, a.Value, int64(event.CreatedAt)); err != nil {
+			return fmt.Errorf("failed to annotate %s: %w", event.ID, err)
+		}
+	}
+	if readTime := annotate.ReadTime(event); readTime != nil {
+		return replaceAnnotationValues(ctx, tx, event.ID, annotate.NamespaceReadTime, readTime)
+	}
+	return nil
+}
+
+// RebuildAnnotations reclassifies every stored note and article. Migrations
+// call it when the schema version changes, and "nophr annotate" after topics
+// change, since events are only classified as they are stored.
+func (s *Storage) RebuildAnnotations(ctx context.Context) error {
+	after := ""
+	for {
+		rows, err := s.db.QueryContext(ctx, `
+			SELECT id, created_at, kind, tags, content
+			FROM event
+			WHERE kind IN (1, 30023) AND id > ?
+			ORDER BY id
+			LIMIT ?`, after, annotationBatch)
+		if err != nil {
+			return fmt.Errorf("failed to query events to annotate: %w", err)
+		}
+
+		var events []*nostr.Event
+		for rows.Next() {
+			var event nostr.Event
+			var createdAt int64
+			var tags string
+			if err := rows.Scan(&event.ID, &createdAt, &event.Kind, &tags, &event.Content); err != nil {
+				rows.Close()
+				return fmt.Errorf("failed to scan event: %w", err)
+			}
+			if err := json.Unmarshal([]byte(tags), &event.Tags); err != nil {
+				rows.Close()
+				return fmt.Errorf("failed to decode tags of %s: %w", event.ID, err)
+			}
+			event.CreatedAt = nostr.Timestamp(createdAt)
+			events = append(events, &event)
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return fmt.Errorf("failed to read events to annotate: %w", err)
+		}
+
+		if len(events) == 0 {
+			return nil
+		}
+
+		tx, err := s.db.BeginTx(ctx, nil)
+		if err != nil {
+			return fmt.Errorf("failed to begin transaction: %w", err)
+		}
+		for _, event := range events {
+			if err := s.saveAnnotations(ctx, tx, event); err != nil {
+				tx.Rollback()
+				return err
+			}
+		}
+		if err := tx.Commit(); err != nil {
+			return fmt.Errorf("failed to commit annotations: %w", err)
+		}
+
+		if len(events) < annotationBatch {
+			return nil
+		}
+		after = events[len(events)-1].ID
+	}
+}
+
+// restoreAnnotations classifies an event restored from the trash within the restore transaction
+func (s *Storage) restoreAnnotations(ctx context.Context, tx *sql.Tx, eventID string) error {
+	var event nostr.Event
+	var createdAt int64
+	var tags string
+	err := tx.QueryRowContext(ctx, "SELECT id, created_at, kind, tags, content FROM event WHERE id = ?", eventID).
+		Scan(&event.ID, &createdAt, &event.Kind, &tags, &event.Content)
+	if err == sql.ErrNoRows {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read restored event: %w", err)
+	}
+	if !annotate.Annotates(event.Kind) {
+		return nil
+	}
+	if err := json.Unmarshal([]byte(tags), &event.Tags); err != nil {
+		return fmt.Errorf("failed to decode tags of %s: %w", eventID, err)
+	}
+	event.CreatedAt = nostr.Timestamp(createdAt)
+	return s.saveAnnotations(ctx, tx, &event)
+}
+
+// GetAnnotations returns an event's annotation values in namespace
+func (s *Storage) GetAnnotations(ctx context.Context, eventID, namespace string) ([]string, error) {
+	rows, err := s.db.QueryContext(ctx,
+		"SELECT value FROM event_annotations WHERE event_id = ? AND namespace = ? ORDER BY value",
+		eventID, namespace)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get annotations: %w", err)
+	}
+	defer rows.Close()
+
+	var values []string
+	for rows.Next() {
+		var value string
+		if err := rows.Scan(&value); err != nil {
+			return nil, fmt.Errorf("failed to scan annotation: %w", err)
+		}
+		values = append(values, value)
+	}
+	return values, rows.Err()
+}
+
+// TopAnnotations returns the values in namespace carried by the most events
+// created at or after since (0 = all time), most first
+func (s *Storage) TopAnnotations(ctx context.Context, namespace string, since nostr.Timestamp, limit int) ([]AnnotationCount, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT value, COUNT(*) AS events
+		FROM event_annotations
+		WHERE namespace = ? AND created_at >= ?
+		GROUP BY value
+		ORDER BY events DESC, value
+		LIMIT ?`, namespace, int64(since), limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count annotations: %w", err)
+	}
+	defer rows.Close()
+
+	var counts []AnnotationCount
+	for rows.Next() {
+		var count AnnotationCount
+		if err := rows.Scan(&count.Value, &count.Events); err != nil {
+			return nil, fmt.Errorf("failed to scan annotation count: %w", err)
+		}
+		counts = append(counts, count)
+	}
+	return counts, rows.Err()
+}
+
+// QueryAnnotated returns events matching filter that carry, in every namespace
+// of annotations, one of its values, newest first. Kinds, Authors, Tags,
+// Since, Until, Search (as a substring of content) and Limit are honoured.
+func (s *Storage) QueryAnnotated(ctx context.Context, filter nostr.Filter, annotations map[string][]string) ([]*nostr.Event, error) {
+	var conditions []string
+	var args []interface{}
+
+	for namespace, values := range annotations {
+		if len(values) == 0 {
+			continue
+		}
+		conditions = append(conditions, `e.id IN (
+			SELECT event_id FROM event_annotations
+			WHERE namespace = ? AND value IN (`+placeholders(len(values))+`))`)
+		args = append(args, namespace)
+		for _, value := range values {
+			args = append(args, value)
+		}
+	}
+
+	if len(filter.Kinds) > 0 {
+		conditions = append(conditions, "e.kind IN ("+placeholders(len(filter.Kinds))+")")
+		for _, kind := range filter.Kinds {
+			args = append(args, kind)
+		}
+	}
+	if len(filter.Authors) > 0 {
+		conditions = append(conditions, "e.pubkey IN ("+placeholders(len(filter.Authors))+")")
+		for _, author := range filter.Authors {
+			args = append(args, author)
+		}
+	}
+	for name, values := range filter.Tags {
+		if len(values) == 0 {
+			continue
+		}
+		conditions = append(conditions, `EXISTS (
+			SELECT 1 FROM json_each(e.tags) tag
+			WHERE json_extract(tag.value, '$[0]') = ?
+			AND json_extract(tag.value, '$[1]') IN (`+placeholders(len(values))+`))`)
+		args = append(args, name)
+		for _, value := range values {
+			args = append(args, value)
+		}
+	}
+	if filter.Since != nil {
+		conditions = append(conditions, "e.created_at >= ?")
+		args = append(args, int64(*filter.Since))
+	}
+	if filter.Until != nil {
+		conditions = append(conditions, "e.created_at <= ?")
+		args = append(args, int64(*filter.Until))
+	}
+	if filter.Search != "" {
+		conditions = append(conditions, `e.content LIKE ? ESCAPE '\'`)
+		args = append(args, "%"+strings.ReplaceAll(filter.Search, "%", `\%`)+"%")
+	}
+	if len(conditions) == 0 {
+		conditions = append(conditions, "1")
+	}
+
+	query := `
+		SELECT e.id, e.pubkey, e.created_at, e.kind, e.tags, e.content, e.sig
+		FROM event e
+		WHERE ` + strings.Join(conditions, " AND ") + `
+		ORDER BY e.created_at DESC, e.id`
+	if filter.Limit > 0 {
+		query += " LIMIT ?"
+		args = append(args, filter.Limit)
+	}
+
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query annotated events: %w", err)
+	}
+	defer rows.Close()
+
+	var events []*nostr.Event
+	for rows.Next() {
+		event, err := scanEvent(rows, nil)
+		if err != nil {
+			return nil, err
+		}
+		if s.visible(event) {
+			events = append(events, event)
+		}
+	}
+	return events, rows.Err()
+}
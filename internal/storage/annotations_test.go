@@ -0,0 +1,177 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/nbd-wtf/go-nostr"
+	"github.com/sandwich/nophr/internal/config"
+)
+
+func TestAnnotatedQueries(t *testing.T) {
+	ctx := context.Background()
+	storage, err := New(ctx, &config.Storage{
+		Driver:      "sqlite",
+		SQLitePath:  filepath.Join(t.TempDir(), "test.db"),
+		Annotations: config.Annotations{Topics: map[string][]string{"bitcoin": {"sats", "lightning"}}},
+	})
+	if err != nil {
+		t.Fatalf("Failed to create storage: %v", err)
+	}
+	defer storage.Close()
+
+	sk := nostr.GeneratePrivateKey()
+	contents := []string{
+		"Stacking sats every day and it is the best thing I have done #bitcoin",
+		"Hoy es un buen día para salir con los amigos y tomar el sol #nostr",
+		"Reading the new spec and it is better than I expected #nostr",
+	}
+	stored := make([]*nostr.Event, len(contents))
+	for i, content := range contents {
+		event := &nostr.Event{CreatedAt: nostr.Timestamp(1000 + i), Kind: 1, Content: content}
+		event.Sign(sk)
+		if err := storage.StoreEvent(ctx, event); err != nil {
+			t.Fatalf("Failed to store event: %v", err)
+		}
+		stored[i] = event
+	}
+
+	tests := []struct {
+		search string
+		want   []*nostr.Event
+	}{
+		{"language:en", []*nostr.Event{stored[2], stored[0]}},
+		{"lang:es #nostr", []*nostr.Event{stored[1]}},
+		{"#nostr", []*nostr.Event{stored[2], stored[1]}},
+		{"tag:NOSTR lang:en", []*nostr.Event{stored[2]}},
+		{"topic:bitcoin", []*nostr.Event{stored[0]}},
+		{"language:en spec", []*nostr.Event{stored[2]}},
+		{"language:de", nil},
+	}
+	for _, tt := range tests {
+		events, err := storage.QueryEvents(ctx, nostr.Filter{Kinds: []int{1}, Search: tt.search})
+		if err != nil {
+			t.Fatalf("QueryEvents(%q) failed: %v", tt.search, err)
+		}
+		if fmt.Sprint(eventIDs(events)) != fmt.Sprint(eventIDs(tt.want)) {
+			t.Errorf("QueryEvents(%q) = %d events, want %d", tt.search, len(events), len(tt.want))
+		}
+	}
+
+	// Free text narrows a qualified search, ranked as before
+	results, err := storage.QueryEventsWithSearch(ctx, nostr.Filter{Search: "#nostr amigos"})
+	if err != nil {
+		t.Fatalf("QueryEventsWithSearch failed: %v", err)
+	}
+	if len(results) != 1 || results[0].ID != stored[1].ID {
+		t.Errorf("QueryEventsWithSearch(#nostr amigos) = %d events, want the Spanish note", len(results))
+	}
+
+	top, err := storage.TopAnnotations(ctx, "t", 0, 10)
+	if err != nil {
+		t.Fatalf("TopAnnotations failed: %v", err)
+	}
+	if fmt.Sprint(top) != "[{nostr 2} {bitcoin 1}]" {
+		t.Errorf("TopAnnotations(t) = %v", top)
+	}
+	if top, _ := storage.TopAnnotations(ctx, "t", 1002, 10); fmt.Sprint(top) != "[{nostr 1}]" {
+		t.Errorf("TopAnnotations(t, since) = %v", top)
+	}
+}
+
+func TestRebuildAnnotations(t *testing.T) {
+	storage, cleanup := setupTestStorage(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	event := &nostr.Event{CreatedAt: 1000, Kind: 1, Content: "gm #coffee"}
+	event.Sign(nostr.GeneratePrivateKey())
+	if err := storage.StoreEvent(ctx, event); err != nil {
+		t.Fatalf("Failed to store event: %v", err)
+	}
+
+	// Simulate a database from before annotations existed
+	if _, err := storage.DB().ExecContext(ctx, "DELETE FROM event_annotations"); err != nil {
+		t.Fatalf("Failed to clear annotations: %v", err)
+	}
+	if _, err := storage.DB().ExecContext(ctx, "PRAGMA user_version = 2"); err != nil {
+		t.Fatalf("Failed to reset schema version: %v", err)
+	}
+
+	if err := storage.runMigrations(ctx); err != nil {
+		t.Fatalf("Failed to run migrations: %v", err)
+	}
+
+	tags, err := storage.GetAnnotations(ctx, event.ID, "t")
+	if err != nil {
+		t.Fatalf("GetAnnotations failed: %v", err)
+	}
+	if fmt.Sprint(tags) != "[coffee]" {
+		t.Errorf("Expected the rebuild to annotate the note, got %v", tags)
+	}
+}
+
+func TestAnnotationValues(t *testing.T) {
+	storage, cleanup := setupTestStorage(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	article := &nostr.Event{CreatedAt: 1000, Kind: 30023, Content: strings.Repeat("word ", 450)}
+	article.Sign(nostr.GeneratePrivateKey())
+	if err := storage.StoreEvent(ctx, article); err != nil {
+		t.Fatalf("Failed to store event: %v", err)
+	}
+
+	if err := storage.SetAnnotation(ctx, article.ID, "spam", "score", "0.1"); err != nil {
+		t.Fatalf("SetAnnotation failed: %v", err)
+	}
+	if err := storage.SetAnnotation(ctx, article.ID, "spam", "score", "0.9"); err != nil {
+		t.Fatalf("SetAnnotation failed: %v", err)
+	}
+	if err := storage.SetAnnotations(ctx, article.ID, "links", map[string]string{"https://a.example": "A"}); err != nil {
+		t.Fatalf("SetAnnotations failed: %v", err)
+	}
+	if err := storage.SetAnnotations(ctx, article.ID, "links", map[string]string{"https://b.example": "B"}); err != nil {
+		t.Fatalf("SetAnnotations failed: %v", err)
+	}
+	if err := storage.SetAnnotation(ctx, "missing", "spam", "score", "1"); err == nil {
+		t.Error("Expected annotating an unknown event to fail")
+	}
+
+	values, err := storage.GetAnnotationValues(ctx, article.ID)
+	if err != nil {
+		t.Fatalf("GetAnnotationValues failed: %v", err)
+	}
+	want := "map[links:map[https://b.example:B] readtime:map[minutes:3] spam:map[score:0.9]]"
+	if fmt.Sprint(values) != want {
+		t.Errorf("Expected %s, got %v", want, values)
+	}
+
+	if err := storage.DeleteAnnotations(ctx, article.ID, "spam"); err != nil {
+		t.Fatalf("DeleteAnnotations failed: %v", err)
+	}
+	values, _ = storage.GetAnnotationValues(ctx, article.ID)
+	if _, ok := values["spam"]; ok {
+		t.Errorf("Expected the spam namespace to be deleted, got %v", values)
+	}
+
+	// Values go with their event
+	if _, err := storage.DB().ExecContext(ctx, "DELETE FROM event WHERE id = ?", article.ID); err != nil {
+		t.Fatalf("Failed to delete event: %v", err)
+	}
+	values, _ = storage.GetAnnotationValues(ctx, article.ID)
+	if len(values) != 0 {
+		t.Errorf("Expected no values after deleting the event, got %v", values)
+	}
+}
+
+func eventIDs(events []*nostr.Event) []string {
+	ids := make([]string, len(events))
+	for i, event := range events {
+		ids[i] = event.ID
+	}
+	return ids
+}
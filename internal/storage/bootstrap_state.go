@@ -0,0 +1,53 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+)
+
+// BootstrapState records how far the sync bootstrap for an owner got, so a
+// restart resumes it instead of starting over
+type BootstrapState struct {
+	Owner      string
+	Phase      string // Last phase completed, "" before the first
+	HintsAfter string // Relay hints are discovered for authors up to this pubkey, in sorted order
+	StartedAt  int64
+	UpdatedAt  int64
+}
+
+// SaveBootstrapState stores or replaces the bootstrap state of its owner
+func (s *Storage) SaveBootstrapState(ctx context.Context, state *BootstrapState) error {
+	_, err := s.db.ExecContext(ctx, `
+		INSERT INTO bootstrap_state (owner, phase, hints_after, started_at, updated_at)
+		VALUES (?, ?, ?, ?, ?)
+		ON CONFLICT(owner) DO UPDATE SET
+			phase = excluded.phase,
+			hints_after = excluded.hints_after,
+			started_at = excluded.started_at,
+			updated_at = excluded.updated_at
+	`, state.Owner, state.Phase, state.HintsAfter, state.StartedAt, state.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to save bootstrap state: %w", err)
+	}
+	return nil
+}
+
+// GetBootstrapState returns the bootstrap state of an owner, or nil if no
+// bootstrap has started
+func (s *Storage) GetBootstrapState(ctx context.Context, owner string) (*BootstrapState, error) {
+	state := BootstrapState{Owner: owner}
+	err := s.db.QueryRowContext(ctx, `
+		SELECT phase, hints_after, started_at, updated_at
+		FROM bootstrap_state
+		WHERE owner = ?
+	`, owner).Scan(&state.Phase, &state.HintsAfter, &state.StartedAt, &state.UpdatedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get bootstrap state: %w", err)
+	}
+	return &state, nil
+}
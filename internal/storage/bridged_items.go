@@ -0,0 +1,40 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// IsItemBridged reports whether the bridge already handled a feed item
+func (s *Storage) IsItemBridged(ctx context.Context, feed, item string) (bool, error) {
+	var count int
+	if err := s.db.QueryRowContext(ctx,
+		"SELECT COUNT(*) FROM bridged_items WHERE feed = ? AND item = ?",
+		feed, item).Scan(&count); err != nil {
+		return false, fmt.Errorf("failed to check bridged item: %w", err)
+	}
+	return count > 0, nil
+}
+
+// MarkItemBridged records that the bridge handled a feed item, with the ID of
+// the event published for it, or "" when the item was only recorded
+func (s *Storage) MarkItemBridged(ctx context.Context, feed, item, eventID string, at time.Time) error {
+	if _, err := s.db.ExecContext(ctx,
+		"INSERT OR IGNORE INTO bridged_items (feed, item, event_id, bridged_at) VALUES (?, ?, ?, ?)",
+		feed, item, eventID, at.Unix()); err != nil {
+		return fmt.Errorf("failed to record bridged item: %w", err)
+	}
+	return nil
+}
+
+// CountBridgedItems returns how many items of a feed the bridge has handled,
+// so a feed it has never polled can be told apart
+func (s *Storage) CountBridgedItems(ctx context.Context, feed string) (int, error) {
+	var count int
+	if err := s.db.QueryRowContext(ctx,
+		"SELECT COUNT(*) FROM bridged_items WHERE feed = ?", feed).Scan(&count); err != nil {
+		return 0, fmt.Errorf("failed to count bridged items: %w", err)
+	}
+	return count, nil
+}
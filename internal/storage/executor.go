@@ -0,0 +1,123 @@
+package storage
+
+import (
+	"context"
+	"sort"
+	"sync"
+
+	"github.com/nbd-wtf/go-nostr"
+)
+
+// DefaultQueryWorkers is the default number of queries run concurrently.
+// Kept below the SQLite connection pool size so page rendering never starves sync.
+const DefaultQueryWorkers = 4
+
+// EventQuerier runs a single filter against an event store
+type EventQuerier interface {
+	QueryEvents(ctx context.Context, filter nostr.Filter) ([]*nostr.Event, error)
+}
+
+// QuerierFunc adapts a query function, such as one that also filters in
+// storage, to an EventQuerier
+type QuerierFunc func(ctx context.Context, filter nostr.Filter) ([]*nostr.Event, error)
+
+// QueryEvents calls f
+func (f QuerierFunc) QueryEvents(ctx context.Context, filter nostr.Filter) ([]*nostr.Event, error) {
+	return f(ctx, filter)
+}
+
+// QueryExecutor runs independent filters concurrently with a bounded worker count
+type QueryExecutor struct {
+	querier EventQuerier
+	workers int
+}
+
+// NewQueryExecutor creates a query executor; workers <= 0 uses DefaultQueryWorkers
+func NewQueryExecutor(querier EventQuerier, workers int) *QueryExecutor {
+	if workers <= 0 {
+		workers = DefaultQueryWorkers
+	}
+
+	return &QueryExecutor{
+		querier: querier,
+		workers: workers,
+	}
+}
+
+// QueryResult holds the outcome of one filter
+type QueryResult struct {
+	Events []*nostr.Event
+	Err    error
+}
+
+// Execute runs all filters and returns one result per filter, in filter order.
+// A failing filter does not cancel the others; callers decide how to handle partial failure.
+func (qe *QueryExecutor) Execute(ctx context.Context, filters []nostr.Filter) []QueryResult {
+	results := make([]QueryResult, len(filters))
+	if len(filters) == 0 {
+		return results
+	}
+
+	// Skip goroutine overhead for the common single-filter case
+	if len(filters) == 1 {
+		results[0].Events, results[0].Err = qe.querier.QueryEvents(ctx, filters[0])
+		return results
+	}
+
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+
+	for w := 0; w < min(qe.workers, len(filters)); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				if err := ctx.Err(); err != nil {
+					results[i].Err = err
+					continue
+				}
+				results[i].Events, results[i].Err = qe.querier.QueryEvents(ctx, filters[i])
+			}
+		}()
+	}
+
+	for i := range filters {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+
+	return results
+}
+
+// Merge runs filters like Execute and returns the union of their events,
+// newest first without duplicates, cut to limit (0 for all of them). Since
+// each filter returns its own newest events, the newest limit of the union
+// are the same as one query for all of them would return. It fails if any
+// filter fails.
+func (qe *QueryExecutor) Merge(ctx context.Context, filters []nostr.Filter, limit int) ([]*nostr.Event, error) {
+	seen := make(map[string]bool)
+	var merged []*nostr.Event
+	for _, result := range qe.Execute(ctx, filters) {
+		if result.Err != nil {
+			return nil, result.Err
+		}
+		for _, event := range result.Events {
+			if !seen[event.ID] {
+				seen[event.ID] = true
+				merged = append(merged, event)
+			}
+		}
+	}
+
+	sort.Slice(merged, func(i, j int) bool {
+		if merged[i].CreatedAt != merged[j].CreatedAt {
+			return merged[i].CreatedAt > merged[j].CreatedAt
+		}
+		return merged[i].ID < merged[j].ID
+	})
+	if limit > 0 && len(merged) > limit {
+		merged = merged[:limit]
+	}
+	return merged, nil
+}
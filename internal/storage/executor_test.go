@@ -0,0 +1,107 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/nbd-wtf/go-nostr"
+)
+
+// fakeQuerier returns one event per filter ID and tracks peak concurrency
+type fakeQuerier struct {
+	mu       sync.Mutex
+	inFlight int
+	peak     int
+	calls    atomic.Int32
+}
+
+func (f *fakeQuerier) QueryEvents(ctx context.Context, filter nostr.Filter) ([]*nostr.Event, error) {
+	f.calls.Add(1)
+	f.mu.Lock()
+	f.inFlight++
+	f.peak = max(f.peak, f.inFlight)
+	f.mu.Unlock()
+
+	time.Sleep(10 * time.Millisecond)
+
+	f.mu.Lock()
+	f.inFlight--
+	f.mu.Unlock()
+
+	if filter.Search == "fail" {
+		return nil, fmt.Errorf("query failed")
+	}
+
+	var events []*nostr.Event
+	for _, id := range filter.IDs {
+		events = append(events, &nostr.Event{ID: id, CreatedAt: *filter.Since})
+	}
+	return events, nil
+}
+
+func TestQueryExecutor(t *testing.T) {
+	ctx := context.Background()
+	querier := &fakeQuerier{}
+	executor := NewQueryExecutor(querier, 2)
+
+	ts := func(n int64) *nostr.Timestamp {
+		t := nostr.Timestamp(n)
+		return &t
+	}
+
+	filters := []nostr.Filter{
+		{IDs: []string{"a"}, Since: ts(100)},
+		{IDs: []string{"b"}, Since: ts(300)},
+		{Search: "fail", Since: ts(0)},
+		{IDs: []string{"a"}, Since: ts(100)},
+	}
+
+	results := executor.Execute(ctx, filters)
+	if len(results) != len(filters) {
+		t.Fatalf("Expected %d results, got %d", len(filters), len(results))
+	}
+	if results[0].Err != nil || results[0].Events[0].ID != "a" {
+		t.Errorf("Expected result 0 to contain event a, got %+v", results[0])
+	}
+	if results[1].Err != nil || results[1].Events[0].ID != "b" {
+		t.Errorf("Expected result 1 to contain event b, got %+v", results[1])
+	}
+	if results[2].Err == nil {
+		t.Error("Expected result 2 to carry the query error")
+	}
+	if querier.peak > 2 {
+		t.Errorf("Expected at most 2 concurrent queries, saw %d", querier.peak)
+	}
+}
+
+func TestQueryExecutorMerge(t *testing.T) {
+	ctx := context.Background()
+	executor := NewQueryExecutor(&fakeQuerier{}, 2)
+
+	ts := func(n int64) *nostr.Timestamp {
+		t := nostr.Timestamp(n)
+		return &t
+	}
+
+	// The same event from two filters is listed once, newest first
+	merged, err := executor.Merge(ctx, []nostr.Filter{
+		{IDs: []string{"a"}, Since: ts(100)},
+		{IDs: []string{"b"}, Since: ts(300)},
+		{IDs: []string{"a"}, Since: ts(100)},
+		{IDs: []string{"c"}, Since: ts(200)},
+	}, 2)
+	if err != nil {
+		t.Fatalf("Merge() error = %v", err)
+	}
+	if len(merged) != 2 || merged[0].ID != "b" || merged[1].ID != "c" {
+		t.Errorf("Expected b then c, got %v", merged)
+	}
+
+	if _, err := executor.Merge(ctx, []nostr.Filter{{IDs: []string{"a"}, Since: ts(100)}, {Search: "fail", Since: ts(0)}}, 0); err == nil {
+		t.Error("Expected Merge to fail when a filter fails")
+	}
+}
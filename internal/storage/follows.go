@@ -0,0 +1,210 @@
+package storage
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/nbd-wtf/go-nostr"
+)
+
+// FollowedPubkeys returns the unique pubkeys a contact list (kind 3) follows
+func FollowedPubkeys(event *nostr.Event) []string {
+	seen := make(map[string]bool)
+	pubkeys := make([]string, 0, len(event.Tags))
+	for _, tag := range event.Tags {
+		if len(tag) >= 2 && tag[0] == "p" && len(tag[1]) == 64 && !seen[tag[1]] {
+			seen[tag[1]] = true
+			pubkeys = append(pubkeys, tag[1])
+		}
+	}
+	return pubkeys
+}
+
+// indexFollows records a newly stored contact list in the follows table,
+// applying only the pairs added or removed since the author's previous list.
+// It runs as a relay StoreEvent handler after the eventstore, so lists from
+// sync, relay writes and imports are indexed as they are ingested.
+func (s *Storage) indexFollows(ctx context.Context, event *nostr.Event) error {
+	if event.Kind != 3 {
+		return nil
+	}
+	return s.IndexContactList(ctx, event)
+}
+
+// IndexContactList updates the follows table from an author's contact list,
+// unless a newer list of theirs is already stored
+func (s *Storage) IndexContactList(ctx context.Context, event *nostr.Event) error {
+	var newest int64
+	if err := s.db.QueryRowContext(ctx,
+		"SELECT COALESCE(MAX(created_at), 0) FROM event WHERE kind = 3 AND pubkey = ?",
+		event.PubKey).Scan(&newest); err != nil {
+		return fmt.Errorf("failed to find newest contact list: %w", err)
+	}
+	if newest > int64(event.CreatedAt) {
+		return nil
+	}
+
+	previous, err := s.GetFollows(ctx, event.PubKey)
+	if err != nil {
+		return err
+	}
+	current := FollowedPubkeys(event)
+
+	before := make(map[string]bool, len(previous))
+	for _, pubkey := range previous {
+		before[pubkey] = true
+	}
+	after := make(map[string]bool, len(current))
+	for _, pubkey := range current {
+		after[pubkey] = true
+	}
+
+	var added, removed []string
+	for _, pubkey := range current {
+		if !before[pubkey] {
+			added = append(added, pubkey)
+		}
+	}
+	for _, pubkey := range previous {
+		if !after[pubkey] {
+			removed = append(removed, pubkey)
+		}
+	}
+	if len(added) == 0 && len(removed) == 0 {
+		return nil
+	}
+
+	return s.applyFollowDiff(ctx, event.PubKey, added, removed)
+}
+
+// applyFollowDiff adds and removes one follower's pairs in a single transaction
+func (s *Storage) applyFollowDiff(ctx context.Context, follower string, added, removed []string) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	for _, followed := range added {
+		if _, err := tx.ExecContext(ctx,
+			"INSERT OR IGNORE INTO follows (follower, followed) VALUES (?, ?)",
+			follower, followed); err != nil {
+			return fmt.Errorf("failed to add follow: %w", err)
+		}
+	}
+	for _, followed := range removed {
+		if _, err := tx.ExecContext(ctx,
+			"DELETE FROM follows WHERE follower = ? AND followed = ?",
+			follower, followed); err != nil {
+			return fmt.Errorf("failed to remove follow: %w", err)
+		}
+	}
+
+	return tx.Commit()
+}
+
+// rebuildFollowIndex indexes the newest stored contact list of every author.
+// Migrations call it when the schema version changes, so lists stored before
+// the index existed are indexed once.
+func (s *Storage) rebuildFollowIndex(ctx context.Context) error {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT e.pubkey, e.tags
+		FROM event e
+		WHERE e.kind = 3 AND e.created_at = (
+			SELECT MAX(created_at) FROM event WHERE kind = 3 AND pubkey = e.pubkey
+		)`)
+	if err != nil {
+		return fmt.Errorf("failed to query contact lists: %w", err)
+	}
+
+	follows := make(map[string][]string)
+	for rows.Next() {
+		var event nostr.Event
+		var tags string
+		if err := rows.Scan(&event.PubKey, &tags); err != nil {
+			rows.Close()
+			return fmt.Errorf("failed to scan contact list: %w", err)
+		}
+		if err := json.Unmarshal([]byte(tags), &event.Tags); err != nil {
+			rows.Close()
+			return fmt.Errorf("failed to decode tags of %s's contact list: %w", event.PubKey, err)
+		}
+		follows[event.PubKey] = FollowedPubkeys(&event)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("failed to read contact lists: %w", err)
+	}
+
+	if _, err := s.db.ExecContext(ctx, "DELETE FROM follows"); err != nil {
+		return fmt.Errorf("failed to clear follow index: %w", err)
+	}
+	for follower, followed := range follows {
+		if err := s.applyFollowDiff(ctx, follower, followed, nil); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// GetFollows returns the pubkeys in an author's newest indexed contact list
+func (s *Storage) GetFollows(ctx context.Context, pubkey string) ([]string, error) {
+	return s.queryPubkeys(ctx, `
+		SELECT followed FROM follows
+		WHERE follower = ?
+		ORDER BY followed`, pubkey)
+}
+
+// GetFollowers returns the authors whose newest indexed contact list follows
+// pubkey, not counting pubkey itself
+func (s *Storage) GetFollowers(ctx context.Context, pubkey string) ([]string, error) {
+	return s.queryPubkeys(ctx, `
+		SELECT follower FROM follows
+		WHERE followed = ? AND follower != followed
+		ORDER BY follower`, pubkey)
+}
+
+// GetMutuals returns the pubkeys that an author follows and that follow them
+// back, according to their newest contact lists
+func (s *Storage) GetMutuals(ctx context.Context, pubkey string) ([]string, error) {
+	return s.queryPubkeys(ctx, `
+		SELECT a.followed FROM follows a
+		JOIN follows b ON b.follower = a.followed AND b.followed = a.follower
+		WHERE a.follower = ?
+		ORDER BY a.followed`, pubkey)
+}
+
+// IsMutual reports whether two authors follow each other
+func (s *Storage) IsMutual(ctx context.Context, a, b string) (bool, error) {
+	var count int
+	if err := s.db.QueryRowContext(ctx, `
+		SELECT COUNT(*) FROM follows
+		WHERE (follower = ? AND followed = ?) OR (follower = ? AND followed = ?)`,
+		a, b, b, a).Scan(&count); err != nil {
+		return false, fmt.Errorf("failed to check mutual follow: %w", err)
+	}
+	return count == 2, nil
+}
+
+// queryPubkeys runs a query selecting one pubkey column
+func (s *Storage) queryPubkeys(ctx context.Context, query string, args ...interface{}) ([]string, error) {
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query follows: %w", err)
+	}
+	defer rows.Close()
+
+	var pubkeys []string
+	for rows.Next() {
+		var pubkey string
+		if err := rows.Scan(&pubkey); err != nil {
+			return nil, fmt.Errorf("failed to scan pubkey: %w", err)
+		}
+		pubkeys = append(pubkeys, pubkey)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("row iteration error: %w", err)
+	}
+	return pubkeys, nil
+}
@@ -0,0 +1,89 @@
+package storage
+
+import (
+	"context"
+	"slices"
+	"testing"
+
+	"github.com/nbd-wtf/go-nostr"
+)
+
+func TestFollowIndex(t *testing.T) {
+	storage, cleanup := setupTestStorage(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	keys := make(map[string]string)
+	pubkey := func(name string) string {
+		if keys[name] == "" {
+			keys[name] = nostr.GeneratePrivateKey()
+		}
+		pk, _ := nostr.GetPublicKey(keys[name])
+		return pk
+	}
+	follow := func(author string, at nostr.Timestamp, followed ...string) {
+		t.Helper()
+		event := &nostr.Event{Kind: 3, CreatedAt: at}
+		for _, name := range followed {
+			event.Tags = append(event.Tags, nostr.Tag{"p", pubkey(name)})
+		}
+		event.Sign(keys[author])
+		if err := storage.StoreEvent(ctx, event); err != nil {
+			t.Fatalf("Failed to store contact list: %v", err)
+		}
+	}
+	mutuals := func(name string) []string {
+		t.Helper()
+		got, err := storage.GetMutuals(ctx, pubkey(name))
+		if err != nil {
+			t.Fatalf("GetMutuals failed: %v", err)
+		}
+		return got
+	}
+
+	owner, alice, bob := pubkey("owner"), pubkey("alice"), pubkey("bob")
+	follow("owner", 100, "alice", "bob")
+	follow("alice", 100, "owner")
+	follow("bob", 100, "alice")
+
+	if got := mutuals("owner"); !slices.Equal(got, []string{alice}) {
+		t.Errorf("Expected alice as the only mutual, got %d", len(got))
+	}
+	if ok, _ := storage.IsMutual(ctx, owner, bob); ok {
+		t.Error("Expected bob not to be a mutual before following back")
+	}
+
+	// bob's new list replaces the old one rather than adding to it
+	follow("bob", 200, "owner")
+	if ok, _ := storage.IsMutual(ctx, owner, bob); !ok {
+		t.Error("Expected bob to be a mutual after following back")
+	}
+	if follows, _ := storage.GetFollows(ctx, bob); !slices.Equal(follows, []string{owner}) {
+		t.Errorf("Expected bob's follows to be replaced, got %d", len(follows))
+	}
+
+	followers, _ := storage.GetFollowers(ctx, owner)
+	if want := []string{alice, bob}; !slices.Equal(followers, slices.Sorted(slices.Values(want))) {
+		t.Errorf("Expected alice and bob to follow the owner, got %d", len(followers))
+	}
+
+	// A list older than the stored one arriving late is ignored
+	follow("bob", 150, "alice")
+	if ok, _ := storage.IsMutual(ctx, owner, bob); !ok {
+		t.Error("Expected an older contact list not to replace the newer one")
+	}
+
+	// Databases from before the index existed are indexed by the migration
+	if _, err := storage.DB().ExecContext(ctx, "DELETE FROM follows"); err != nil {
+		t.Fatalf("Failed to clear follow index: %v", err)
+	}
+	if _, err := storage.DB().ExecContext(ctx, "PRAGMA user_version = 1"); err != nil {
+		t.Fatalf("Failed to reset schema version: %v", err)
+	}
+	if err := storage.runMigrations(ctx); err != nil {
+		t.Fatalf("Failed to run migrations: %v", err)
+	}
+	if got := mutuals("owner"); len(got) != 2 {
+		t.Errorf("Expected the rebuilt index to list both mutuals, got %d", len(got))
+	}
+}
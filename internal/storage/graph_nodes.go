@@ -160,6 +160,16 @@ func (s *Storage) GetMutualPubkeys(ctx context.Context, rootPubkey string) ([]st
 	return pubkeys, nil
 }
 
+// DeleteGraphNode removes a single graph node for a root-target pair
+func (s *Storage) DeleteGraphNode(ctx context.Context, rootPubkey, targetPubkey string) error {
+	query := `DELETE FROM graph_nodes WHERE root_pubkey = ? AND pubkey = ?`
+	_, err := s.db.ExecContext(ctx, query, rootPubkey, targetPubkey)
+	if err != nil {
+		return fmt.Errorf("failed to delete graph node: %w", err)
+	}
+	return nil
+}
+
 // DeleteGraphNodes removes all graph nodes for a given root pubkey
 func (s *Storage) DeleteGraphNodes(ctx context.Context, rootPubkey string) error {
 	query := `DELETE FROM graph_nodes WHERE root_pubkey = ?`
@@ -2,96 +2,289 @@ package storage
 
 import (
 	"context"
+	"embed"
+	"errors"
 	"fmt"
+	"io/fs"
+	"path"
+	"regexp"
+	"strconv"
+	"time"
 )
 
-// runMigrations creates the custom tables for nophr
+// indexVersion is stored in PRAGMA user_version. Bump it when derived tables
+// must be rebuilt from stored events; migrateIndexVersion does the rebuild.
+// Schema changes are migrations in migrations/<driver> instead.
+//
+// 1: event_threads indexes every kind 1 note
+// 2: follows indexes every author's newest contact list
+// 3: event_annotations classifies every note and article
+// 4: annotation_values gives every article its reading time
+const indexVersion = 4
+
+// ErrSchemaTooNew is returned when the database was migrated by a newer
+// nophr than this one
+var ErrSchemaTooNew = errors.New("database schema is newer than this nophr")
+
+// ErrBaselineMigration is returned when asked to roll back migration 1, the
+// baseline schema: its tables hold state (trash, sync cursors, submissions,
+// the admin log) that can't be rebuilt from stored events
+var ErrBaselineMigration = errors.New("migration 1 is the baseline schema and can't be rolled back")
+
+//go:embed migrations
+var migrationFiles embed.FS
+
+// migrationFile matches NNNN_name.up.sql and NNNN_name.down.sql
+var migrationFile = regexp.MustCompile(`^(\d+)_(\w+)\.(up|down)\.sql$`)
+
+// Migration is one numbered schema change, read from
+// migrations/<driver>/NNNN_name.up.sql and its .down.sql
+type Migration struct {
+	Version   int
+	Name      string
+	AppliedAt time.Time // Zero while the migration is pending
+
+	up, down string
+}
+
+// loadMigrations reads the embedded migrations for driver, ordered by
+// version. Versions must run from 1 without gaps and each needs both files.
+func loadMigrations(driver string) ([]Migration, error) {
+	dir := path.Join("migrations", driver)
+	entries, err := fs.ReadDir(migrationFiles, dir)
+	if err != nil {
+		return nil, fmt.Errorf("no migrations for driver %s", driver)
+	}
+
+	byVersion := make(map[int]*Migration)
+	for _, entry := range entries {
+		match := migrationFile.FindStringSubmatch(entry.Name())
+		if match == nil {
+			return nil, fmt.Errorf("unexpected migration file %s", entry.Name())
+		}
+		version, _ := strconv.Atoi(match[1])
+		data, err := fs.ReadFile(migrationFiles, path.Join(dir, entry.Name()))
+		if err != nil {
+			return nil, err
+		}
+
+		m := byVersion[version]
+		if m == nil {
+			m = &Migration{Version: version, Name: match[2]}
+			byVersion[version] = m
+		}
+		if m.Name != match[2] {
+			return nil, fmt.Errorf("migration %d is named both %s and %s", version, m.Name, match[2])
+		}
+		if match[3] == "up" {
+			m.up = string(data)
+		} else {
+			m.down = string(data)
+		}
+	}
+
+	migrations := make([]Migration, 0, len(byVersion))
+	for version := 1; version <= len(byVersion); version++ {
+		m := byVersion[version]
+		if m == nil {
+			return nil, fmt.Errorf("migration %d is missing", version)
+		}
+		if m.up == "" || m.down == "" {
+			return nil, fmt.Errorf("migration %d (%s) needs both an up and a down file", version, m.Name)
+		}
+		migrations = append(migrations, *m)
+	}
+	return migrations, nil
+}
+
+// runMigrations brings the schema up to date: it applies each pending
+// migration, repairs tables older versions got wrong and rebuilds derived
+// tables. It refuses databases migrated past the newest migration it knows.
 func (s *Storage) runMigrations(ctx context.Context) error {
 	if s.db == nil {
 		return fmt.Errorf("database not initialized")
 	}
 
-	migrations := []string{
-		// relay_hints: Track which relays to use for each author (from NIP-65)
-		`CREATE TABLE IF NOT EXISTS relay_hints (
-			pubkey TEXT NOT NULL,
-			relay TEXT NOT NULL,
-			can_read INTEGER NOT NULL DEFAULT 1,
-			can_write INTEGER NOT NULL DEFAULT 1,
-			freshness INTEGER NOT NULL,
-			last_seen_event_id TEXT NOT NULL,
-			PRIMARY KEY (pubkey, relay)
-		)`,
-		`CREATE INDEX IF NOT EXISTS idx_relay_hints_pubkey_freshness
-		 ON relay_hints(pubkey, freshness DESC)`,
-
-		// graph_nodes: Owner-centric social graph cache
-		`CREATE TABLE IF NOT EXISTS graph_nodes (
-			root_pubkey TEXT NOT NULL,
-			pubkey TEXT NOT NULL,
-			depth INTEGER NOT NULL,
-			mutual INTEGER NOT NULL DEFAULT 0,
-			last_seen INTEGER NOT NULL,
-			PRIMARY KEY (root_pubkey, pubkey)
-		)`,
-		`CREATE INDEX IF NOT EXISTS idx_graph_nodes_root_depth_mutual
-		 ON graph_nodes(root_pubkey, depth, mutual)`,
-
-		// sync_state: Cursor tracking per relay/kind
-		`CREATE TABLE IF NOT EXISTS sync_state (
-			relay TEXT NOT NULL,
-			kind INTEGER NOT NULL,
-			since INTEGER NOT NULL,
-			updated_at INTEGER NOT NULL,
-			PRIMARY KEY (relay, kind)
-		)`,
-
-		// aggregates: Interaction rollups (reply counts, reactions, zaps)
-		`CREATE TABLE IF NOT EXISTS aggregates (
-			event_id TEXT PRIMARY KEY,
-			reply_count INTEGER NOT NULL DEFAULT 0,
-			reaction_total INTEGER NOT NULL DEFAULT 0,
-			reaction_counts_json TEXT,
-			zap_sats_total INTEGER NOT NULL DEFAULT 0,
-			last_interaction_at INTEGER NOT NULL
-		)`,
-
-		// retention_metadata: Advanced retention metadata (Phase 20)
-		`CREATE TABLE IF NOT EXISTS retention_metadata (
-			event_id TEXT PRIMARY KEY,
-			rule_name TEXT NOT NULL,
-			rule_priority INTEGER NOT NULL,
-			retain_until INTEGER,
-			last_evaluated_at INTEGER NOT NULL,
-			score INTEGER,
-			protected BOOLEAN DEFAULT 0,
-			FOREIGN KEY (event_id) REFERENCES events(id) ON DELETE CASCADE
-		)`,
-		`CREATE INDEX IF NOT EXISTS idx_retention_metadata_retain_until
-		 ON retention_metadata(retain_until)`,
-		`CREATE INDEX IF NOT EXISTS idx_retention_metadata_score
-		 ON retention_metadata(score)`,
-		`CREATE INDEX IF NOT EXISTS idx_retention_metadata_protected
-		 ON retention_metadata(protected)`,
-
-		// relay_capabilities: Track relay feature support (NIP-77, etc.)
-		`CREATE TABLE IF NOT EXISTS relay_capabilities (
-			url TEXT PRIMARY KEY,
-			supports_negentropy INTEGER NOT NULL DEFAULT 0,
-			nip11_software TEXT,
-			nip11_version TEXT,
-			last_checked INTEGER NOT NULL,
-			check_expiry INTEGER NOT NULL
-		)`,
-		`CREATE INDEX IF NOT EXISTS idx_relay_capabilities_expiry
-		 ON relay_capabilities(check_expiry)`,
-	}
-
-	for i, migration := range migrations {
-		if _, err := s.db.ExecContext(ctx, migration); err != nil {
-			return fmt.Errorf("migration %d failed: %w", i+1, err)
+	migrations, err := s.Migrations(ctx)
+	if err != nil {
+		return err
+	}
+
+	from := appliedVersion(migrations)
+	for _, m := range migrations {
+		if !m.AppliedAt.IsZero() {
+			continue
+		}
+		if err := s.applyMigration(ctx, m, true); err != nil {
+			return fmt.Errorf("migration %d (%s) failed: %w", m.Version, m.Name, err)
 		}
 	}
+	if to := len(migrations); to > from {
+		message := fmt.Sprintf("Schema migrated from version %d to %d", from, to)
+		if err := s.LogAdminEvent(ctx, AdminLogMigration, "storage", message); err != nil {
+			fmt.Printf("  ⚠ %v\n", err)
+		}
+	}
+
+	if err := s.repairRetentionMetadataFK(ctx); err != nil {
+		return fmt.Errorf("failed to repair retention_metadata: %w", err)
+	}
+
+	if err := s.migrateIndexVersion(ctx); err != nil {
+		return err
+	}
 
 	return nil
 }
+
+// Migrations lists the known migrations, oldest first, with when each was
+// applied to this database. It returns ErrSchemaTooNew if the database has
+// migrations this nophr doesn't know.
+func (s *Storage) Migrations(ctx context.Context) ([]Migration, error) {
+	migrations, err := loadMigrations(s.config.Driver)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := s.db.ExecContext(ctx, `CREATE TABLE IF NOT EXISTS schema_migrations (
+		version INTEGER PRIMARY KEY,
+		name TEXT NOT NULL,
+		applied_at INTEGER NOT NULL
+	)`); err != nil {
+		return nil, fmt.Errorf("failed to create schema_migrations: %w", err)
+	}
+
+	rows, err := s.db.QueryContext(ctx, "SELECT version, applied_at FROM schema_migrations ORDER BY version")
+	if err != nil {
+		return nil, fmt.Errorf("failed to read schema version: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var version int
+		var appliedAt int64
+		if err := rows.Scan(&version, &appliedAt); err != nil {
+			return nil, fmt.Errorf("failed to read schema version: %w", err)
+		}
+		if version > len(migrations) {
+			return nil, fmt.Errorf("%w: the database is at version %d, but this nophr only knows versions up to %d; "+
+				"run a newer nophr, or roll back with the newer nophr's \"nophr migrate down --to %d\"",
+				ErrSchemaTooNew, version, len(migrations), len(migrations))
+		}
+		if version >= 1 {
+			migrations[version-1].AppliedAt = time.Unix(appliedAt, 0)
+		}
+	}
+	return migrations, rows.Err()
+}
+
+// MigrateDown rolls the schema back to version target by running the down
+// migrations above it, newest first. The index version is reset, so derived
+// tables are rebuilt once the schema is migrated up again. Targets below 1
+// return ErrBaselineMigration.
+func (s *Storage) MigrateDown(ctx context.Context, target int) error {
+	if target < 1 {
+		return ErrBaselineMigration
+	}
+	migrations, err := s.Migrations(ctx)
+	if err != nil {
+		return err
+	}
+
+	from := appliedVersion(migrations)
+	if target >= from {
+		return nil
+	}
+	for i := from; i > target; i-- {
+		m := migrations[i-1]
+		if err := s.applyMigration(ctx, m, false); err != nil {
+			return fmt.Errorf("down migration %d (%s) failed: %w", m.Version, m.Name, err)
+		}
+	}
+
+	if _, err := s.db.ExecContext(ctx, "PRAGMA user_version = 0"); err != nil {
+		return fmt.Errorf("failed to reset index version: %w", err)
+	}
+	message := fmt.Sprintf("Schema rolled back from version %d to %d", from, target)
+	if err := s.LogAdminEvent(ctx, AdminLogMigration, "storage", message); err != nil {
+		fmt.Printf("  ⚠ %v\n", err)
+	}
+	return nil
+}
+
+// appliedVersion returns the newest applied migration's version, 0 for none
+func appliedVersion(migrations []Migration) int {
+	version := 0
+	for _, m := range migrations {
+		if !m.AppliedAt.IsZero() {
+			version = m.Version
+		}
+	}
+	return version
+}
+
+// applyMigration runs one migration's SQL and records it as applied (up) or
+// removes its record (down), in one transaction
+func (s *Storage) applyMigration(ctx context.Context, m Migration, up bool) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	script := m.down
+	if up {
+		script = m.up
+	}
+	if _, err := tx.ExecContext(ctx, script); err != nil {
+		return err
+	}
+	if up {
+		_, err = tx.ExecContext(ctx,
+			"INSERT INTO schema_migrations (version, name, applied_at) VALUES (?, ?, ?)",
+			m.Version, m.Name, time.Now().Unix())
+	} else {
+		_, err = tx.ExecContext(ctx, "DELETE FROM schema_migrations WHERE version = ?", m.Version)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to record migration: %w", err)
+	}
+	return tx.Commit()
+}
+
+// migrateIndexVersion rebuilds derived tables for databases indexed by an
+// older version and records the current version
+func (s *Storage) migrateIndexVersion(ctx context.Context) error {
+	var version int
+	if err := s.db.QueryRowContext(ctx, "PRAGMA user_version").Scan(&version); err != nil {
+		return fmt.Errorf("failed to read index version: %w", err)
+	}
+	if version >= indexVersion {
+		return nil
+	}
+
+	if version < 1 {
+		if err := s.rebuildThreadIndex(ctx); err != nil {
+			return fmt.Errorf("failed to rebuild thread index: %w", err)
+		}
+	}
+	if version < 2 {
+		if err := s.rebuildFollowIndex(ctx); err != nil {
+			return fmt.Errorf("failed to rebuild follow index: %w", err)
+		}
+	}
+	if version < 4 {
+		if err := s.RebuildAnnotations(ctx); err != nil {
+			return fmt.Errorf("failed to rebuild annotations: %w", err)
+		}
+	}
+
+	if _, err := s.db.ExecContext(ctx, fmt.Sprintf("PRAGMA user_version = %d", indexVersion)); err != nil {
+		return fmt.Errorf("failed to record index version: %w", err)
+	}
+	message := fmt.Sprintf("Derived tables rebuilt from index version %d to %d", version, indexVersion)
+	if err := s.LogAdminEvent(ctx, AdminLogMigration, "storage", message); err != nil {
+		fmt.Printf("  ⚠ %v\n", err)
+	}
+	return nil
+}
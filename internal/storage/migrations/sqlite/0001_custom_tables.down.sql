@@ -0,0 +1,3 @@
+-- Migration 1 is the baseline schema. Its tables hold state that can't be
+-- rebuilt from stored events (the trash, sync cursors, submissions, the admin
+-- log), so it is never rolled back: MigrateDown refuses targets below 1.
@@ -0,0 +1,228 @@
+-- Tables nophr keeps beside the events: relay hints, the social graph,
+-- sync cursors, interaction rollups, indexes derived from events and
+-- operational state. IF NOT EXISTS adopts the tables of databases created
+-- before migrations were versioned.
+
+-- relay_hints: Track which relays to use for each author (from NIP-65)
+CREATE TABLE IF NOT EXISTS relay_hints (
+  pubkey TEXT NOT NULL,
+  relay TEXT NOT NULL,
+  can_read INTEGER NOT NULL DEFAULT 1,
+  can_write INTEGER NOT NULL DEFAULT 1,
+  freshness INTEGER NOT NULL,
+  last_seen_event_id TEXT NOT NULL,
+  PRIMARY KEY (pubkey, relay)
+);
+CREATE INDEX IF NOT EXISTS idx_relay_hints_pubkey_freshness
+  ON relay_hints(pubkey, freshness DESC);
+
+-- graph_nodes: Owner-centric social graph cache
+CREATE TABLE IF NOT EXISTS graph_nodes (
+  root_pubkey TEXT NOT NULL,
+  pubkey TEXT NOT NULL,
+  depth INTEGER NOT NULL,
+  mutual INTEGER NOT NULL DEFAULT 0,
+  last_seen INTEGER NOT NULL,
+  PRIMARY KEY (root_pubkey, pubkey)
+);
+CREATE INDEX IF NOT EXISTS idx_graph_nodes_root_depth_mutual
+  ON graph_nodes(root_pubkey, depth, mutual);
+
+-- sync_state: Cursor tracking per relay/kind
+CREATE TABLE IF NOT EXISTS sync_state (
+  relay TEXT NOT NULL,
+  kind INTEGER NOT NULL,
+  since INTEGER NOT NULL,
+  updated_at INTEGER NOT NULL,
+  PRIMARY KEY (relay, kind)
+);
+
+-- aggregates: Interaction rollups (reply counts, reactions, zaps)
+CREATE TABLE IF NOT EXISTS aggregates (
+  event_id TEXT PRIMARY KEY,
+  reply_count INTEGER NOT NULL DEFAULT 0,
+  reaction_total INTEGER NOT NULL DEFAULT 0,
+  reaction_counts_json TEXT,
+  zap_sats_total INTEGER NOT NULL DEFAULT 0,
+  last_interaction_at INTEGER NOT NULL
+);
+
+-- aggregates_seen: Interaction events already counted into aggregates
+CREATE TABLE IF NOT EXISTS aggregates_seen (
+  interaction_id TEXT PRIMARY KEY,
+  target_id TEXT NOT NULL,
+  seen_at INTEGER NOT NULL
+);
+CREATE INDEX IF NOT EXISTS idx_aggregates_seen_target
+  ON aggregates_seen(target_id);
+
+-- retention_metadata: Advanced retention metadata (Phase 20)
+CREATE TABLE IF NOT EXISTS retention_metadata (
+  event_id TEXT PRIMARY KEY,
+  rule_name TEXT NOT NULL,
+  rule_priority INTEGER NOT NULL,
+  retain_until INTEGER,
+  last_evaluated_at INTEGER NOT NULL,
+  score INTEGER,
+  protected BOOLEAN DEFAULT 0,
+  FOREIGN KEY (event_id) REFERENCES event(id) ON DELETE CASCADE
+);
+CREATE INDEX IF NOT EXISTS idx_retention_metadata_retain_until
+  ON retention_metadata(retain_until);
+CREATE INDEX IF NOT EXISTS idx_retention_metadata_score
+  ON retention_metadata(score);
+CREATE INDEX IF NOT EXISTS idx_retention_metadata_protected
+  ON retention_metadata(protected);
+
+-- relay_capabilities: Track relay feature support (NIP-77, etc.)
+CREATE TABLE IF NOT EXISTS relay_capabilities (
+  url TEXT PRIMARY KEY,
+  supports_negentropy INTEGER NOT NULL DEFAULT 0,
+  nip11_software TEXT,
+  nip11_version TEXT,
+  last_checked INTEGER NOT NULL,
+  check_expiry INTEGER NOT NULL
+);
+CREATE INDEX IF NOT EXISTS idx_relay_capabilities_expiry
+  ON relay_capabilities(check_expiry);
+
+-- trash: Soft-deleted events kept restorable until purge_after
+CREATE TABLE IF NOT EXISTS trash (
+  id TEXT PRIMARY KEY,
+  pubkey TEXT NOT NULL,
+  created_at INTEGER NOT NULL,
+  kind INTEGER NOT NULL,
+  tags TEXT NOT NULL,
+  content TEXT NOT NULL,
+  sig TEXT NOT NULL,
+  reason TEXT NOT NULL,
+  deleted_at INTEGER NOT NULL,
+  purge_after INTEGER NOT NULL
+);
+CREATE INDEX IF NOT EXISTS idx_trash_purge_after
+  ON trash(purge_after);
+
+-- event_threads: Whether each kind 1 note replies to another event (NIP-10),
+-- so note and reply listings can be filtered in SQL instead of over-fetching
+CREATE TABLE IF NOT EXISTS event_threads (
+  event_id TEXT PRIMARY KEY,
+  is_reply INTEGER NOT NULL,
+  FOREIGN KEY (event_id) REFERENCES event(id) ON DELETE CASCADE
+);
+CREATE INDEX IF NOT EXISTS idx_event_threads_is_reply
+  ON event_threads(is_reply);
+
+-- follows: Follower/followed pairs from each author's newest contact
+-- list, so mutual follows are a join rather than a scan of kind 3 events
+CREATE TABLE IF NOT EXISTS follows (
+  follower TEXT NOT NULL,
+  followed TEXT NOT NULL,
+  PRIMARY KEY (follower, followed)
+);
+CREATE INDEX IF NOT EXISTS idx_follows_followed
+  ON follows(followed, follower);
+
+-- submissions: Recent uploads per client certificate, so replayed
+-- nonces are refused and per-certificate quotas can be counted
+CREATE TABLE IF NOT EXISTS submissions (
+  fingerprint TEXT NOT NULL,
+  nonce TEXT NOT NULL,
+  seen_at INTEGER NOT NULL,
+  PRIMARY KEY (fingerprint, nonce)
+);
+CREATE INDEX IF NOT EXISTS idx_submissions_fingerprint_seen
+  ON submissions(fingerprint, seen_at);
+
+-- translations: Note content translated on demand, one per target
+-- language, so a note is only sent to the translation provider once
+CREATE TABLE IF NOT EXISTS translations (
+  event_id TEXT NOT NULL,
+  lang TEXT NOT NULL,
+  content TEXT NOT NULL,
+  translated_at INTEGER NOT NULL,
+  PRIMARY KEY (event_id, lang),
+  FOREIGN KEY (event_id) REFERENCES event(id) ON DELETE CASCADE
+);
+
+-- bootstrap_state: How far the sync bootstrap got, per owner
+CREATE TABLE IF NOT EXISTS bootstrap_state (
+  owner TEXT PRIMARY KEY,
+  phase TEXT NOT NULL,
+  hints_after TEXT NOT NULL DEFAULT '',
+  started_at INTEGER NOT NULL,
+  updated_at INTEGER NOT NULL
+);
+
+-- relay_hint_sources: Relays seen for an author outside their NIP-65 list
+CREATE TABLE IF NOT EXISTS relay_hint_sources (
+  pubkey TEXT NOT NULL,
+  relay TEXT NOT NULL,
+  source TEXT NOT NULL,
+  sightings INTEGER NOT NULL DEFAULT 1,
+  last_seen INTEGER NOT NULL,
+  PRIMARY KEY (pubkey, relay, source)
+);
+
+-- event_annotations: Language, hashtags and topics of each note and
+-- article, classified at ingest so listings and search filter on them
+-- without analysing content per request
+CREATE TABLE IF NOT EXISTS event_annotations (
+  event_id TEXT NOT NULL,
+  namespace TEXT NOT NULL,
+  value TEXT NOT NULL,
+  created_at INTEGER NOT NULL,
+  PRIMARY KEY (event_id, namespace, value),
+  FOREIGN KEY (event_id) REFERENCES event(id) ON DELETE CASCADE
+);
+CREATE INDEX IF NOT EXISTS idx_event_annotations_value
+  ON event_annotations(namespace, value, created_at DESC);
+
+-- annotation_values: Keyed data any subsystem attaches to an event
+-- (reading time, spam scores, link titles, ...) under its own
+-- namespace, so new kinds of data need no migration
+CREATE TABLE IF NOT EXISTS annotation_values (
+  event_id TEXT NOT NULL,
+  namespace TEXT NOT NULL,
+  key TEXT NOT NULL,
+  value TEXT NOT NULL,
+  updated_at INTEGER NOT NULL,
+  PRIMARY KEY (event_id, namespace, key),
+  FOREIGN KEY (event_id) REFERENCES event(id) ON DELETE CASCADE
+);
+CREATE INDEX IF NOT EXISTS idx_annotation_values_key
+  ON annotation_values(namespace, key);
+
+-- bridged_items: Feed items the RSS and Atom bridge has handled, with
+-- the event published for each ('' for items it only recorded), so an
+-- item is never published twice, even once its event is deleted
+CREATE TABLE IF NOT EXISTS bridged_items (
+  feed TEXT NOT NULL,
+  item TEXT NOT NULL,
+  event_id TEXT NOT NULL,
+  bridged_at INTEGER NOT NULL,
+  PRIMARY KEY (feed, item)
+);
+
+-- admin_log: Lifecycle events of the instance (startups, subsystems
+-- starting and stopping, unclean shutdowns, migrations), newest kept
+CREATE TABLE IF NOT EXISTS admin_log (
+  id INTEGER PRIMARY KEY AUTOINCREMENT,
+  created_at INTEGER NOT NULL,
+  type TEXT NOT NULL,
+  subsystem TEXT NOT NULL DEFAULT '',
+  message TEXT NOT NULL
+);
+
+-- relay_health: Connection outcomes, latency and event yield per relay
+-- across restarts, so sync can back off from relays that keep failing
+CREATE TABLE IF NOT EXISTS relay_health (
+  url TEXT PRIMARY KEY,
+  successes INTEGER NOT NULL DEFAULT 0,
+  failures INTEGER NOT NULL DEFAULT 0,
+  consecutive_failures INTEGER NOT NULL DEFAULT 0,
+  events INTEGER NOT NULL DEFAULT 0,
+  latency_ms INTEGER NOT NULL DEFAULT 0,
+  last_success_at INTEGER NOT NULL DEFAULT 0,
+  last_failure_at INTEGER NOT NULL DEFAULT 0,
+  last_error TEXT NOT NULL DEFAULT ''
+);
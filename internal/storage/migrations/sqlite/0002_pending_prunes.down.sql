@@ -0,0 +1,3 @@
+-- Pending prunes are forgotten: their authors' events are kept
+DROP INDEX IF EXISTS idx_pending_prunes_due;
+DROP TABLE IF EXISTS pending_prunes;
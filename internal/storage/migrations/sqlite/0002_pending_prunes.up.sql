@@ -0,0 +1,10 @@
+-- pending_prunes: Unfollowed authors whose events are deleted once due,
+-- unless they are back in scope by then, kept so a restart doesn't forget them
+CREATE TABLE IF NOT EXISTS pending_prunes (
+  root_pubkey TEXT NOT NULL,
+  pubkey TEXT NOT NULL,
+  due_at INTEGER NOT NULL,
+  PRIMARY KEY (root_pubkey, pubkey)
+);
+CREATE INDEX IF NOT EXISTS idx_pending_prunes_due
+  ON pending_prunes(due_at);
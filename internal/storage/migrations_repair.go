@@ -0,0 +1,69 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+)
+
+// repairRetentionMetadataFK rebuilds retention_metadata tables created by older
+// versions, whose foreign key pointed at a nonexistent "events" table.
+// With foreign_keys enabled every insert into such a table fails.
+func (s *Storage) repairRetentionMetadataFK(ctx context.Context) error {
+	var ddl string
+	err := s.db.QueryRowContext(ctx,
+		"SELECT sql FROM sqlite_master WHERE type = 'table' AND name = 'retention_metadata'",
+	).Scan(&ddl)
+	if err == sql.ErrNoRows {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read schema: %w", err)
+	}
+
+	if !strings.Contains(ddl, "REFERENCES events(") {
+		return nil
+	}
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	statements := []string{
+		`CREATE TABLE retention_metadata_new (
+			event_id TEXT PRIMARY KEY,
+			rule_name TEXT NOT NULL,
+			rule_priority INTEGER NOT NULL,
+			retain_until INTEGER,
+			last_evaluated_at INTEGER NOT NULL,
+			score INTEGER,
+			protected BOOLEAN DEFAULT 0,
+			FOREIGN KEY (event_id) REFERENCES event(id) ON DELETE CASCADE
+		)`,
+		// Drop rows for events that no longer exist so the new foreign key holds
+		`INSERT INTO retention_metadata_new
+		 SELECT rm.event_id, rm.rule_name, rm.rule_priority, rm.retain_until,
+		        rm.last_evaluated_at, rm.score, rm.protected
+		 FROM retention_metadata rm
+		 WHERE rm.event_id IN (SELECT id FROM event)`,
+		`DROP TABLE retention_metadata`,
+		`ALTER TABLE retention_metadata_new RENAME TO retention_metadata`,
+		`CREATE INDEX IF NOT EXISTS idx_retention_metadata_retain_until
+		 ON retention_metadata(retain_until)`,
+		`CREATE INDEX IF NOT EXISTS idx_retention_metadata_score
+		 ON retention_metadata(score)`,
+		`CREATE INDEX IF NOT EXISTS idx_retention_metadata_protected
+		 ON retention_metadata(protected)`,
+	}
+
+	for _, stmt := range statements {
+		if _, err := tx.ExecContext(ctx, stmt); err != nil {
+			return fmt.Errorf("failed to rebuild retention_metadata: %w", err)
+		}
+	}
+
+	return tx.Commit()
+}
@@ -0,0 +1,100 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"github.com/nbd-wtf/go-nostr"
+	"github.com/sandwich/nophr/internal/config"
+)
+
+func TestMigrations(t *testing.T) {
+	storage, cleanup := setupTestStorage(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	migrations, err := storage.Migrations(ctx)
+	if err != nil {
+		t.Fatalf("Migrations failed: %v", err)
+	}
+	if len(migrations) == 0 {
+		t.Fatal("Expected embedded migrations")
+	}
+	for _, m := range migrations {
+		if m.AppliedAt.IsZero() {
+			t.Errorf("Expected migration %d (%s) to be applied", m.Version, m.Name)
+		}
+	}
+
+	sk := nostr.GeneratePrivateKey()
+	event := &nostr.Event{CreatedAt: 1000, Kind: 1, Tags: nostr.Tags{}, Content: "kept"}
+	event.Sign(sk)
+	if err := storage.StoreEvent(ctx, event); err != nil {
+		t.Fatalf("Failed to store event: %v", err)
+	}
+
+	// The baseline can't be rolled back: its tables aren't derived
+	if err := storage.MigrateDown(ctx, 0); !errors.Is(err, ErrBaselineMigration) {
+		t.Errorf("Expected ErrBaselineMigration, got %v", err)
+	}
+	migrations, _ = storage.Migrations(ctx)
+	if appliedVersion(migrations) != len(migrations) {
+		t.Errorf("Expected version %d after a refused rollback, got %d", len(migrations), appliedVersion(migrations))
+	}
+
+	// Resetting the index version rebuilds derived tables
+	if _, err := storage.DB().ExecContext(ctx, "DELETE FROM event_threads"); err != nil {
+		t.Fatalf("Failed to clear thread index: %v", err)
+	}
+	if _, err := storage.DB().ExecContext(ctx, "PRAGMA user_version = 0"); err != nil {
+		t.Fatalf("Failed to reset index version: %v", err)
+	}
+	if err := storage.runMigrations(ctx); err != nil {
+		t.Fatalf("Failed to run migrations: %v", err)
+	}
+	if n := countThreadRows(t, storage); n != 1 {
+		t.Errorf("Expected the rebuilt thread index to have 1 row, got %d", n)
+	}
+
+	// A database migrated by a newer nophr is refused
+	if _, err := storage.DB().ExecContext(ctx,
+		"INSERT INTO schema_migrations (version, name, applied_at) VALUES (?, 'future', 0)", len(migrations)+1); err != nil {
+		t.Fatalf("Failed to record future migration: %v", err)
+	}
+	if err := storage.runMigrations(ctx); !errors.Is(err, ErrSchemaTooNew) {
+		t.Errorf("Expected ErrSchemaTooNew, got %v", err)
+	}
+}
+
+func TestOpenDoesNotMigrate(t *testing.T) {
+	ctx := context.Background()
+	cfg := &config.Storage{
+		Driver:     "sqlite",
+		SQLitePath: filepath.Join(t.TempDir(), "nophr.db"),
+	}
+
+	storage, err := Open(ctx, cfg)
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+	migrations, err := storage.Migrations(ctx)
+	if err != nil {
+		t.Fatalf("Migrations failed: %v", err)
+	}
+	if appliedVersion(migrations) != 0 {
+		t.Errorf("Expected a fresh database opened without migrating to be at version 0, got %d", appliedVersion(migrations))
+	}
+	storage.Close()
+
+	storage, err = New(ctx, cfg)
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	defer storage.Close()
+	migrations, _ = storage.Migrations(ctx)
+	if appliedVersion(migrations) != len(migrations) {
+		t.Errorf("Expected New to migrate to version %d, got %d", len(migrations), appliedVersion(migrations))
+	}
+}
@@ -0,0 +1,69 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+)
+
+// PendingPrune is an unfollowed author whose events are deleted at DueAt
+type PendingPrune struct {
+	RootPubkey string
+	Pubkey     string
+	DueAt      int64
+}
+
+// SchedulePrunes records that the events of pubkeys are to be deleted at
+// dueAt. An author already scheduled keeps the later of the two times.
+func (s *Storage) SchedulePrunes(ctx context.Context, rootPubkey string, pubkeys []string, dueAt int64) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	for _, pubkey := range pubkeys {
+		if _, err := tx.ExecContext(ctx, `
+			INSERT INTO pending_prunes (root_pubkey, pubkey, due_at)
+			VALUES (?, ?, ?)
+			ON CONFLICT(root_pubkey, pubkey) DO UPDATE SET
+				due_at = MAX(due_at, excluded.due_at)
+		`, rootPubkey, pubkey, dueAt); err != nil {
+			return fmt.Errorf("failed to schedule prune: %w", err)
+		}
+	}
+	return tx.Commit()
+}
+
+// DuePrunes returns the pending prunes due at or before now, oldest first
+func (s *Storage) DuePrunes(ctx context.Context, now int64) ([]PendingPrune, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT root_pubkey, pubkey, due_at
+		FROM pending_prunes
+		WHERE due_at <= ?
+		ORDER BY due_at
+	`, now)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query pending prunes: %w", err)
+	}
+	defer rows.Close()
+
+	var prunes []PendingPrune
+	for rows.Next() {
+		var p PendingPrune
+		if err := rows.Scan(&p.RootPubkey, &p.Pubkey, &p.DueAt); err != nil {
+			return nil, fmt.Errorf("failed to scan pending prune: %w", err)
+		}
+		prunes = append(prunes, p)
+	}
+	return prunes, rows.Err()
+}
+
+// DeletePendingPrune removes an author's pending prune, once it has run or
+// is no longer wanted
+func (s *Storage) DeletePendingPrune(ctx context.Context, rootPubkey, pubkey string) error {
+	_, err := s.db.ExecContext(ctx, `DELETE FROM pending_prunes WHERE root_pubkey = ? AND pubkey = ?`, rootPubkey, pubkey)
+	if err != nil {
+		return fmt.Errorf("failed to delete pending prune: %w", err)
+	}
+	return nil
+}
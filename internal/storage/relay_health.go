@@ -0,0 +1,98 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// RelayHealth is the connection record of a relay, kept across restarts
+type RelayHealth struct {
+	URL                 string
+	Successes           int64         // Syncs that connected
+	Failures            int64         // Syncs that couldn't connect
+	ConsecutiveFailures int           // Failures since the last success
+	Events              int64         // Events received over all syncs
+	Latency             time.Duration // Connect latency, smoothed over recent connects
+	LastSuccess         *time.Time
+	LastFailure         *time.Time
+	LastError           string
+}
+
+// RecordRelaySuccess records a sync that connected to a relay and received
+// events from it. A latency of zero (an existing connection was reused)
+// leaves the smoothed latency as it was.
+func (s *Storage) RecordRelaySuccess(ctx context.Context, url string, latency time.Duration, events int64, at time.Time) error {
+	ms := latency.Milliseconds()
+	if _, err := s.db.ExecContext(ctx, `
+		INSERT INTO relay_health (url, successes, events, latency_ms, last_success_at)
+		VALUES (?, 1, ?, ?, ?)
+		ON CONFLICT(url) DO UPDATE SET
+			successes = successes + 1,
+			consecutive_failures = 0,
+			events = events + excluded.events,
+			latency_ms = CASE
+				WHEN excluded.latency_ms = 0 THEN latency_ms
+				WHEN latency_ms = 0 THEN excluded.latency_ms
+				ELSE (latency_ms * 3 + excluded.latency_ms) / 4
+			END,
+			last_success_at = excluded.last_success_at`,
+		url, events, ms, at.Unix()); err != nil {
+		return fmt.Errorf("failed to record relay success: %w", err)
+	}
+	return nil
+}
+
+// RecordRelayFailure records a sync that couldn't connect to a relay
+func (s *Storage) RecordRelayFailure(ctx context.Context, url, message string, at time.Time) error {
+	if _, err := s.db.ExecContext(ctx, `
+		INSERT INTO relay_health (url, failures, consecutive_failures, last_failure_at, last_error)
+		VALUES (?, 1, 1, ?, ?)
+		ON CONFLICT(url) DO UPDATE SET
+			failures = failures + 1,
+			consecutive_failures = consecutive_failures + 1,
+			last_failure_at = excluded.last_failure_at,
+			last_error = excluded.last_error`,
+		url, at.Unix(), message); err != nil {
+		return fmt.Errorf("failed to record relay failure: %w", err)
+	}
+	return nil
+}
+
+// GetRelayHealth returns the connection records of every relay synced from,
+// keyed by URL
+func (s *Storage) GetRelayHealth(ctx context.Context) (map[string]*RelayHealth, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT url, successes, failures, consecutive_failures, events, latency_ms,
+		       last_success_at, last_failure_at, last_error
+		FROM relay_health`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query relay health: %w", err)
+	}
+	defer rows.Close()
+
+	health := make(map[string]*RelayHealth)
+	for rows.Next() {
+		var h RelayHealth
+		var latencyMs, lastSuccess, lastFailure int64
+		if err := rows.Scan(&h.URL, &h.Successes, &h.Failures, &h.ConsecutiveFailures, &h.Events, &latencyMs,
+			&lastSuccess, &lastFailure, &h.LastError); err != nil {
+			return nil, fmt.Errorf("failed to scan relay health: %w", err)
+		}
+		h.Latency = time.Duration(latencyMs) * time.Millisecond
+		if lastSuccess > 0 {
+			t := time.Unix(lastSuccess, 0)
+			h.LastSuccess = &t
+		}
+		if lastFailure > 0 {
+			t := time.Unix(lastFailure, 0)
+			h.LastFailure = &t
+		}
+		health[h.URL] = &h
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating relay health: %w", err)
+	}
+
+	return health, nil
+}
@@ -0,0 +1,59 @@
+package storage
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestRelayHealth(t *testing.T) {
+	storage, cleanup := setupTestStorage(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	url := "wss://relay.test"
+	at := time.Unix(1000, 0)
+
+	if err := storage.RecordRelaySuccess(ctx, url, 100*time.Millisecond, 20, at); err != nil {
+		t.Fatalf("RecordRelaySuccess failed: %v", err)
+	}
+	if err := storage.RecordRelaySuccess(ctx, url, 500*time.Millisecond, 5, at); err != nil {
+		t.Fatalf("RecordRelaySuccess failed: %v", err)
+	}
+	// A reused connection doesn't count as a latency sample
+	if err := storage.RecordRelaySuccess(ctx, url, 0, 0, at); err != nil {
+		t.Fatalf("RecordRelaySuccess failed: %v", err)
+	}
+	for range 2 {
+		if err := storage.RecordRelayFailure(ctx, url, "connection refused", at.Add(time.Minute)); err != nil {
+			t.Fatalf("RecordRelayFailure failed: %v", err)
+		}
+	}
+
+	health, err := storage.GetRelayHealth(ctx)
+	if err != nil {
+		t.Fatalf("GetRelayHealth failed: %v", err)
+	}
+	h := health[url]
+	if h == nil {
+		t.Fatalf("no health recorded for %s", url)
+	}
+	if h.Successes != 3 || h.Failures != 2 || h.ConsecutiveFailures != 2 || h.Events != 25 {
+		t.Errorf("health = %+v", h)
+	}
+	if h.Latency != 200*time.Millisecond {
+		t.Errorf("Latency = %v, want 200ms smoothed", h.Latency)
+	}
+	if h.LastError != "connection refused" || h.LastFailure == nil || !h.LastFailure.Equal(at.Add(time.Minute)) {
+		t.Errorf("last failure = %v %q", h.LastFailure, h.LastError)
+	}
+
+	// A success clears the run of failures
+	if err := storage.RecordRelaySuccess(ctx, url, 0, 1, at.Add(2*time.Minute)); err != nil {
+		t.Fatalf("RecordRelaySuccess failed: %v", err)
+	}
+	health, _ = storage.GetRelayHealth(ctx)
+	if h := health[url]; h.ConsecutiveFailures != 0 || h.Failures != 2 {
+		t.Errorf("after success: %+v", h)
+	}
+}
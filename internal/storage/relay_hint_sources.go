@@ -0,0 +1,111 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+)
+
+// Relay hint sources besides the author's own NIP-65 list
+const (
+	HintSourceNIP05 = "nip05" // relays field of the author's NIP-05 document
+	HintSourceTag   = "tag"   // relay field of an e, p or a tag pointing at the author
+)
+
+// SourcedRelayHint is a relay an author was seen at outside their NIP-65
+// list. Sightings counts the tags naming the relay; NIP-05 hints have one.
+type SourcedRelayHint struct {
+	Pubkey    string
+	Relay     string
+	Source    string
+	Sightings int
+	LastSeen  int64
+}
+
+// RecordRelayHintSightings adds tag sightings of relays, counting repeats
+func (s *Storage) RecordRelayHintSightings(ctx context.Context, hints []*SourcedRelayHint) error {
+	if len(hints) == 0 {
+		return nil
+	}
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	stmt, err := tx.PrepareContext(ctx, `
+		INSERT INTO relay_hint_sources (pubkey, relay, source, sightings, last_seen)
+		VALUES (?, ?, ?, ?, ?)
+		ON CONFLICT(pubkey, relay, source) DO UPDATE SET
+			sightings = sightings + excluded.sightings,
+			last_seen = MAX(last_seen, excluded.last_seen)
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to prepare relay hint sighting: %w", err)
+	}
+	defer stmt.Close()
+
+	for _, hint := range hints {
+		if _, err := stmt.ExecContext(ctx, hint.Pubkey, hint.Relay, hint.Source, max(hint.Sightings, 1), hint.LastSeen); err != nil {
+			return fmt.Errorf("failed to record relay hint for %s: %w", hint.Pubkey, err)
+		}
+	}
+
+	return tx.Commit()
+}
+
+// ReplaceSourcedRelayHints replaces every hint an author has from one source,
+// for sources that list all of an author's relays at once, like NIP-05
+func (s *Storage) ReplaceSourcedRelayHints(ctx context.Context, pubkey, source string, relays []string, seenAt int64) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx,
+		`DELETE FROM relay_hint_sources WHERE pubkey = ? AND source = ?`, pubkey, source,
+	); err != nil {
+		return fmt.Errorf("failed to clear %s relay hints: %w", source, err)
+	}
+	for _, relay := range relays {
+		if _, err := tx.ExecContext(ctx, `
+			INSERT OR IGNORE INTO relay_hint_sources (pubkey, relay, source, sightings, last_seen)
+			VALUES (?, ?, ?, 1, ?)
+		`, pubkey, relay, source, seenAt); err != nil {
+			return fmt.Errorf("failed to save %s relay hint: %w", source, err)
+		}
+	}
+
+	return tx.Commit()
+}
+
+// GetSourcedRelayHints returns every hint for an author outside their
+// NIP-65 list, most seen first
+func (s *Storage) GetSourcedRelayHints(ctx context.Context, pubkey string) ([]*SourcedRelayHint, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT pubkey, relay, source, sightings, last_seen
+		FROM relay_hint_sources
+		WHERE pubkey = ?
+		ORDER BY sightings DESC, last_seen DESC
+	`, pubkey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query relay hints: %w", err)
+	}
+	defer rows.Close()
+
+	var hints []*SourcedRelayHint
+	for rows.Next() {
+		var hint SourcedRelayHint
+		if err := rows.Scan(&hint.Pubkey, &hint.Relay, &hint.Source, &hint.Sightings, &hint.LastSeen); err != nil {
+			return nil, fmt.Errorf("failed to scan relay hint: %w", err)
+		}
+		hints = append(hints, &hint)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("row iteration error: %w", err)
+	}
+
+	return hints, nil
+}
@@ -15,19 +15,23 @@ type RelayHint struct {
 	LastSeenEventID string
 }
 
-// SaveRelayHint stores or updates a relay hint
-func (s *Storage) SaveRelayHint(ctx context.Context, hint *RelayHint) error {
-	query := `
-		INSERT INTO relay_hints (pubkey, relay, can_read, can_write, freshness, last_seen_event_id)
-		VALUES (?, ?, ?, ?, ?, ?)
-		ON CONFLICT(pubkey, relay) DO UPDATE SET
-			can_read = excluded.can_read,
-			can_write = excluded.can_write,
-			freshness = excluded.freshness,
-			last_seen_event_id = excluded.last_seen_event_id
-		WHERE excluded.freshness > freshness
-	`
-
+// upsertRelayHint stores a relay hint unless a fresher one is already stored
+const upsertRelayHint = `
+	INSERT INTO relay_hints (pubkey, relay, can_read, can_write, freshness, last_seen_event_id)
+	VALUES (?, ?, ?, ?, ?, ?)
+	ON CONFLICT(pubkey, relay) DO UPDATE SET
+		can_read = excluded.can_read,
+		can_write = excluded.can_write,
+		freshness = excluded.freshness,
+		last_seen_event_id = excluded.last_seen_event_id
+	WHERE excluded.freshness > freshness
+`
+
+// relayHintBatchSize bounds how many hints one SaveRelayHints transaction writes
+const relayHintBatchSize = 500
+
+// relayHintArgs returns the upsertRelayHint arguments for a hint
+func relayHintArgs(hint *RelayHint) []interface{} {
 	canRead := 0
 	if hint.CanRead {
 		canRead = 1
@@ -36,16 +40,54 @@ func (s *Storage) SaveRelayHint(ctx context.Context, hint *RelayHint) error {
 	if hint.CanWrite {
 		canWrite = 1
 	}
+	return []interface{}{hint.Pubkey, hint.Relay, canRead, canWrite, hint.Freshness, hint.LastSeenEventID}
+}
 
-	_, err := s.db.ExecContext(ctx, query,
-		hint.Pubkey, hint.Relay, canRead, canWrite, hint.Freshness, hint.LastSeenEventID)
-	if err != nil {
+// SaveRelayHint stores or updates a relay hint
+func (s *Storage) SaveRelayHint(ctx context.Context, hint *RelayHint) error {
+	if _, err := s.db.ExecContext(ctx, upsertRelayHint, relayHintArgs(hint)...); err != nil {
 		return fmt.Errorf("failed to save relay hint: %w", err)
 	}
 
 	return nil
 }
 
+// SaveRelayHints stores or updates many relay hints, one transaction per
+// relayHintBatchSize hints rather than one write per hint
+func (s *Storage) SaveRelayHints(ctx context.Context, hints []*RelayHint) error {
+	for start := 0; start < len(hints); start += relayHintBatchSize {
+		end := min(start+relayHintBatchSize, len(hints))
+		if err := s.saveRelayHintBatch(ctx, hints[start:end]); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// saveRelayHintBatch writes one batch of relay hints in a single transaction
+func (s *Storage) saveRelayHintBatch(ctx context.Context, hints []*RelayHint) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	stmt, err := tx.PrepareContext(ctx, upsertRelayHint)
+	if err != nil {
+		return fmt.Errorf("failed to prepare relay hint upsert: %w", err)
+	}
+	defer stmt.Close()
+
+	for _, hint := range hints {
+		if _, err := stmt.ExecContext(ctx, relayHintArgs(hint)...); err != nil {
+			return fmt.Errorf("failed to save relay hint for %s: %w", hint.Pubkey, err)
+		}
+	}
+
+	return tx.Commit()
+}
+
 // GetRelayHints retrieves relay hints for a given pubkey
 func (s *Storage) GetRelayHints(ctx context.Context, pubkey string) ([]*RelayHint, error) {
 	query := `
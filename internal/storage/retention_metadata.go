@@ -119,15 +119,40 @@ func (s *Storage) GetExpiredEvents(ctx context.Context, limit int) ([]string, er
 // GetEventsByScore returns events sorted by score (ascending - lowest priority first)
 // Used for cap enforcement
 func (s *Storage) GetEventsByScore(ctx context.Context, limit int) ([]*RetentionMetadata, error) {
-	query := `
+	return s.queryEventsByScore(ctx, `
 		SELECT event_id, rule_name, rule_priority, retain_until, last_evaluated_at, score, protected
 		FROM retention_metadata
 		WHERE protected = 0
 		ORDER BY score ASC
 		LIMIT ?
-	`
+	`, limit)
+}
 
-	rows, err := s.db.QueryContext(ctx, query, limit)
+// GetEventsByScoreForKind returns events of one kind sorted by score (lowest first)
+// Used for per-kind cap enforcement
+func (s *Storage) GetEventsByScoreForKind(ctx context.Context, kind, limit int) ([]*RetentionMetadata, error) {
+	return s.queryEventsByScore(ctx, `
+		SELECT rm.event_id, rm.rule_name, rm.rule_priority, rm.retain_until, rm.last_evaluated_at, rm.score, rm.protected
+		FROM retention_metadata rm
+		JOIN event e ON e.id = rm.event_id
+		WHERE rm.protected = 0 AND e.kind = ?
+		ORDER BY rm.score ASC
+		LIMIT ?
+	`, kind, limit)
+}
+
+// ListRetentionMetadata returns the retention metadata of every event, for backups
+func (s *Storage) ListRetentionMetadata(ctx context.Context) ([]*RetentionMetadata, error) {
+	return s.queryEventsByScore(ctx, `
+		SELECT event_id, rule_name, rule_priority, retain_until, last_evaluated_at, score, protected
+		FROM retention_metadata
+		ORDER BY event_id
+	`)
+}
+
+// queryEventsByScore scans retention metadata rows returned by a score query
+func (s *Storage) queryEventsByScore(ctx context.Context, query string, args ...interface{}) ([]*RetentionMetadata, error) {
+	rows, err := s.db.QueryContext(ctx, query, args...)
 	if err != nil {
 		return nil, fmt.Errorf("failed to query events by score: %w", err)
 	}
@@ -168,7 +193,7 @@ func (s *Storage) GetEventsByScore(ctx context.Context, limit int) ([]*Retention
 func (s *Storage) GetEventsNeedingEvaluation(ctx context.Context, limit int) ([]string, error) {
 	query := `
 		SELECT e.id
-		FROM events e
+		FROM event e
 		LEFT JOIN retention_metadata rm ON e.id = rm.event_id
 		WHERE rm.event_id IS NULL
 		ORDER BY e.created_at DESC
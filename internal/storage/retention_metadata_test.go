@@ -5,7 +5,9 @@ import (
 	"database/sql"
 	"os"
 	"testing"
+	"time"
 
+	"github.com/nbd-wtf/go-nostr"
 	"github.com/sandwich/nophr/internal/config"
 )
 
@@ -140,3 +142,111 @@ func TestRetentionMetadataMigration(t *testing.T) {
 
 	t.Log("✅ Database migration test PASSED!")
 }
+
+func TestPinnedEvents(t *testing.T) {
+	s, cleanup := setupTestStorage(t)
+	defer cleanup()
+
+	ctx := context.Background()
+
+	old := nostr.Timestamp(time.Now().AddDate(-1, 0, 0).Unix())
+	pinned := &nostr.Event{ID: "pinned-event", PubKey: "test-pubkey", CreatedAt: old, Kind: 1, Tags: nostr.Tags{}, Content: "keep me", Sig: "sig"}
+	other := &nostr.Event{ID: "other-event", PubKey: "test-pubkey", CreatedAt: old, Kind: 1, Tags: nostr.Tags{}, Content: "prune me", Sig: "sig"}
+
+	for _, ev := range []*nostr.Event{pinned, other} {
+		if err := s.StoreEvent(ctx, ev); err != nil {
+			t.Fatalf("Failed to store event: %v", err)
+		}
+	}
+
+	if err := s.PinEvent(ctx, pinned.ID); err != nil {
+		t.Fatalf("Failed to pin event: %v", err)
+	}
+
+	isPinned, err := s.IsEventPinned(ctx, pinned.ID)
+	if err != nil || !isPinned {
+		t.Fatalf("Expected event to be pinned (err: %v)", err)
+	}
+
+	protected, err := s.ListProtectedEvents(ctx, 10)
+	if err != nil {
+		t.Fatalf("Failed to list protected events: %v", err)
+	}
+	if len(protected) != 1 || protected[0].EventID != pinned.ID {
+		t.Fatalf("Expected only the pinned event to be listed, got %d", len(protected))
+	}
+
+	// Simple pruning must skip protected events
+	deleted, err := s.DeleteEventsBefore(ctx, time.Now())
+	if err != nil {
+		t.Fatalf("Failed to delete events: %v", err)
+	}
+	if deleted != 1 {
+		t.Errorf("Expected 1 deleted event, got %d", deleted)
+	}
+
+	exists, err := s.EventExists(ctx, pinned.ID)
+	if err != nil || !exists {
+		t.Errorf("Expected pinned event to survive pruning (err: %v)", err)
+	}
+
+	removed, err := s.UnpinEvent(ctx, pinned.ID)
+	if err != nil || !removed {
+		t.Fatalf("Expected pin to be removed (err: %v)", err)
+	}
+
+	removed, err = s.UnpinEvent(ctx, pinned.ID)
+	if err != nil || removed {
+		t.Errorf("Expected second unpin to be a no-op (err: %v)", err)
+	}
+}
+
+func TestEventsByScoreForKind(t *testing.T) {
+	s, cleanup := setupTestStorage(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	now := time.Now()
+
+	events := []struct {
+		id        string
+		kind      int
+		score     int
+		protected bool
+	}{
+		{"note-low", 1, 10, false},
+		{"note-high", 1, 90, false},
+		{"note-protected", 1, 0, true},
+		{"reaction-low", 7, 5, false},
+	}
+	for _, e := range events {
+		ev := &nostr.Event{ID: e.id, PubKey: "test-pubkey", CreatedAt: nostr.Timestamp(now.Unix()), Kind: e.kind, Tags: nostr.Tags{}, Content: e.id, Sig: "sig"}
+		if err := s.StoreEvent(ctx, ev); err != nil {
+			t.Fatalf("Failed to store event: %v", err)
+		}
+		meta := &RetentionMetadata{EventID: e.id, RuleName: "test", LastEvaluatedAt: now, Score: e.score, Protected: e.protected}
+		if err := s.StoreRetentionMetadata(ctx, meta); err != nil {
+			t.Fatalf("Failed to store retention metadata: %v", err)
+		}
+	}
+
+	notes, err := s.GetEventsByScoreForKind(ctx, 1, 10)
+	if err != nil {
+		t.Fatalf("Failed to get events by score: %v", err)
+	}
+	if len(notes) != 2 || notes[0].EventID != "note-low" || notes[1].EventID != "note-high" {
+		t.Errorf("Expected unprotected notes lowest score first, got %d results", len(notes))
+	}
+
+	if count, err := s.CountEventsOfKind(ctx, 1); err != nil || count != 3 {
+		t.Errorf("Expected 3 kind 1 events, got %d (err: %v)", count, err)
+	}
+	if count, err := s.CountEventsByAuthor(ctx, "test-pubkey"); err != nil || count != 4 {
+		t.Errorf("Expected 4 events by author, got %d (err: %v)", count, err)
+	}
+
+	size, err := s.LiveDataSize(ctx)
+	if err != nil || size <= 0 {
+		t.Errorf("Expected a positive live data size, got %f (err: %v)", size, err)
+	}
+}
@@ -0,0 +1,141 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// ManualPinRuleName is the rule name recorded for events pinned by the operator
+const ManualPinRuleName = "manual_pin"
+
+// manualPinPriority ranks manual pins above any configured rule
+const manualPinPriority = 1 << 30
+
+// PinEvent marks an event as protected so it is never pruned by rules or caps
+func (s *Storage) PinEvent(ctx context.Context, eventID string) error {
+	// Keep the existing score for reporting if the event was already evaluated
+	score := 0
+	existing, err := s.GetRetentionMetadata(ctx, eventID)
+	if err != nil {
+		return err
+	}
+	if existing != nil {
+		score = existing.Score
+	}
+
+	return s.StoreRetentionMetadata(ctx, &RetentionMetadata{
+		EventID:         eventID,
+		RuleName:        ManualPinRuleName,
+		RulePriority:    manualPinPriority,
+		RetainUntil:     nil,
+		LastEvaluatedAt: time.Now(),
+		Score:           score,
+		Protected:       true,
+	})
+}
+
+// UnpinEvent removes a manual pin from an event
+// Returns false if the event was not manually pinned
+func (s *Storage) UnpinEvent(ctx context.Context, eventID string) (bool, error) {
+	result, err := s.db.ExecContext(ctx,
+		"DELETE FROM retention_metadata WHERE event_id = ? AND rule_name = ?",
+		eventID, ManualPinRuleName)
+	if err != nil {
+		return false, fmt.Errorf("failed to unpin event: %w", err)
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	return affected > 0, nil
+}
+
+// IsEventPinned returns true if the event was pinned by the operator
+func (s *Storage) IsEventPinned(ctx context.Context, eventID string) (bool, error) {
+	var ruleName string
+	err := s.db.QueryRowContext(ctx,
+		"SELECT rule_name FROM retention_metadata WHERE event_id = ?",
+		eventID).Scan(&ruleName)
+	if err == sql.ErrNoRows {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to check pin: %w", err)
+	}
+
+	return ruleName == ManualPinRuleName, nil
+}
+
+// ListProtectedEvents returns protected events, most recently evaluated first
+// This includes both manual pins and events protected by retention rules
+func (s *Storage) ListProtectedEvents(ctx context.Context, limit int) ([]*RetentionMetadata, error) {
+	query := `
+		SELECT event_id, rule_name, rule_priority, retain_until, last_evaluated_at, score, protected
+		FROM retention_metadata
+		WHERE protected = 1
+		ORDER BY last_evaluated_at DESC
+		LIMIT ?
+	`
+
+	rows, err := s.db.QueryContext(ctx, query, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query protected events: %w", err)
+	}
+	defer rows.Close()
+
+	var results []*RetentionMetadata
+	for rows.Next() {
+		var meta RetentionMetadata
+		var retainUntil *int64
+		var lastEvaluatedAt int64
+
+		err := rows.Scan(
+			&meta.EventID,
+			&meta.RuleName,
+			&meta.RulePriority,
+			&retainUntil,
+			&lastEvaluatedAt,
+			&meta.Score,
+			&meta.Protected,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan retention metadata: %w", err)
+		}
+
+		if retainUntil != nil {
+			t := time.Unix(*retainUntil, 0)
+			meta.RetainUntil = &t
+		}
+		meta.LastEvaluatedAt = time.Unix(lastEvaluatedAt, 0)
+
+		results = append(results, &meta)
+	}
+
+	return results, rows.Err()
+}
+
+// ProtectedEventRules returns the rule name of every protected event, keyed by event ID
+// Manual pins are recorded under ManualPinRuleName
+func (s *Storage) ProtectedEventRules(ctx context.Context) (map[string]string, error) {
+	rows, err := s.db.QueryContext(ctx,
+		"SELECT event_id, rule_name FROM retention_metadata WHERE protected = 1")
+	if err != nil {
+		return nil, fmt.Errorf("failed to query protected events: %w", err)
+	}
+	defer rows.Close()
+
+	rules := make(map[string]string)
+	for rows.Next() {
+		var eventID, ruleName string
+		if err := rows.Scan(&eventID, &ruleName); err != nil {
+			return nil, fmt.Errorf("failed to scan protected event: %w", err)
+		}
+		rules[eventID] = ruleName
+	}
+
+	return rules, rows.Err()
+}
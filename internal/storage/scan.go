@@ -0,0 +1,79 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+
+	"github.com/nbd-wtf/go-nostr"
+)
+
+// IterateEvents calls fn for every stored event in insertion order, reading
+// batchSize rows at a time so large stores are not loaded into memory at once.
+// Iteration stops at the first error returned by fn.
+func (s *Storage) IterateEvents(ctx context.Context, batchSize int, fn func(*nostr.Event) error) error {
+	if batchSize <= 0 {
+		batchSize = 1000
+	}
+
+	var lastRowID int64
+	for {
+		rows, err := s.db.QueryContext(ctx, `
+			SELECT rowid, id, pubkey, created_at, kind, tags, content, sig
+			FROM event
+			WHERE rowid > ?
+			ORDER BY rowid
+			LIMIT ?`, lastRowID, batchSize)
+		if err != nil {
+			return fmt.Errorf("failed to query events: %w", err)
+		}
+
+		var batch []*nostr.Event
+		for rows.Next() {
+			event, err := scanEvent(rows, &lastRowID)
+			if err != nil {
+				rows.Close()
+				return err
+			}
+			batch = append(batch, event)
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return fmt.Errorf("failed to read events: %w", err)
+		}
+
+		for _, event := range batch {
+			if err := fn(event); err != nil {
+				return err
+			}
+		}
+
+		if len(batch) < batchSize {
+			return nil
+		}
+	}
+}
+
+// scanEvent reads one event row selected as (id, pubkey, created_at, kind, tags, content, sig),
+// optionally preceded by a rowid column when rowID is non-nil
+func scanEvent(rows *sql.Rows, rowID *int64) (*nostr.Event, error) {
+	var event nostr.Event
+	var tags string
+	var createdAt int64
+
+	dest := []interface{}{&event.ID, &event.PubKey, &createdAt, &event.Kind, &tags, &event.Content, &event.Sig}
+	if rowID != nil {
+		dest = append([]interface{}{rowID}, dest...)
+	}
+	if err := rows.Scan(dest...); err != nil {
+		return nil, fmt.Errorf("failed to scan event: %w", err)
+	}
+
+	if err := json.Unmarshal([]byte(tags), &event.Tags); err != nil {
+		return nil, fmt.Errorf("failed to decode tags of %s: %w", event.ID, err)
+	}
+	event.CreatedAt = nostr.Timestamp(createdAt)
+
+	return &event, nil
+}
@@ -2,25 +2,33 @@ package storage
 
 import (
 	"context"
+	"slices"
 	"strings"
 
 	"github.com/nbd-wtf/go-nostr"
+	"github.com/sandwich/nophr/internal/annotate"
 )
 
 // QueryEventsWithSearch performs a NIP-50 compliant search when the Search field is present
 // Falls back to regular QueryEvents if no search term is provided
 func (s *Storage) QueryEventsWithSearch(ctx context.Context, filter nostr.Filter) ([]*nostr.Event, error) {
-	// If no search term, use regular query
-	if filter.Search == "" {
+	// Qualifiers alone are an annotation lookup, which QueryEvents handles
+	query := ParseSearch(filter.Search)
+	if query.Terms == "" {
 		return s.QueryEvents(ctx, filter)
 	}
 
 	// Get all matching events (without search filter first)
-	// Store the search term and clear it temporarily
-	searchTerm := filter.Search
+	searchTerm := query.Terms
 	filter.Search = ""
 
-	events, err := s.QueryEvents(ctx, filter)
+	var events []*nostr.Event
+	var err error
+	if len(query.Annotations) > 0 {
+		events, err = s.QueryAnnotated(ctx, filter, query.Annotations)
+	} else {
+		events, err = s.QueryEvents(ctx, filter)
+	}
 	if err != nil {
 		return nil, err
 	}
@@ -46,6 +54,58 @@ func (s *Storage) QueryEventsWithSearch(ctx context.Context, filter nostr.Filter
 	return results, nil
 }
 
+// SearchQuery is a search split into the text matched against content and
+// the qualifiers answered from event annotations
+type SearchQuery struct {
+	Terms       string
+	Annotations map[string][]string // Values by annotation namespace
+}
+
+// searchQualifiers maps qualifier keys to the annotation namespace they
+// select. "language:" is the NIP-50 extension; the others are nophr's.
+var searchQualifiers = map[string]string{
+	"language": annotate.NamespaceLanguage,
+	"lang":     annotate.NamespaceLanguage,
+	"topic":    annotate.NamespaceTopic,
+	"tag":      annotate.NamespaceHashtag,
+}
+
+// ParseSearch splits qualifiers out of a search: "language:es", "lang:es",
+// "topic:bitcoin", "tag:nostr" and "#nostr". Several values for one qualifier
+// match any of them; different qualifiers must all match.
+func ParseSearch(search string) SearchQuery {
+	var query SearchQuery
+	var terms []string
+	add := func(namespace, value string) {
+		if value = strings.ToLower(value); value == "" {
+			return
+		}
+		if query.Annotations == nil {
+			query.Annotations = make(map[string][]string)
+		}
+		if !slices.Contains(query.Annotations[namespace], value) {
+			query.Annotations[namespace] = append(query.Annotations[namespace], value)
+		}
+	}
+
+	for _, field := range strings.Fields(search) {
+		if tag, ok := strings.CutPrefix(field, "#"); ok && tag != "" {
+			add(annotate.NamespaceHashtag, tag)
+			continue
+		}
+		if key, value, ok := strings.Cut(field, ":"); ok {
+			if namespace, known := searchQualifiers[strings.ToLower(key)]; known {
+				add(namespace, value)
+				continue
+			}
+		}
+		terms = append(terms, field)
+	}
+
+	query.Terms = strings.Join(terms, " ")
+	return query
+}
+
 // matchesSearch checks if an event matches the search term
 func matchesSearch(event *nostr.Event, searchLower string) bool {
 	// Search in content (primary field per NIP-50)
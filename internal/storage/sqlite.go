@@ -6,18 +6,30 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"sync/atomic"
 
 	"github.com/fiatjaf/eventstore/sqlite3"
 	"github.com/fiatjaf/khatru"
 	_ "github.com/mattn/go-sqlite3"
 )
 
+// memoryDatabases numbers the shared in-memory databases opened for
+// ":memory:" paths, so each Storage gets its own
+var memoryDatabases atomic.Int64
+
 // initSQLite initializes the SQLite backend with Khatru
 func (s *Storage) initSQLite(ctx context.Context) error {
-	// Ensure the directory exists
 	dbPath := s.config.SQLitePath
-	if err := os.MkdirAll(filepath.Dir(dbPath), 0755); err != nil {
-		return fmt.Errorf("failed to create database directory: %w", err)
+	if isInMemoryPath(dbPath) {
+		// The eventstore and the custom tables open separate connection
+		// pools, and every plain in-memory connection gets its own empty
+		// database; a named shared-cache database is seen by both
+		dbPath = fmt.Sprintf("file:nophr-memory-%d?mode=memory&cache=shared", memoryDatabases.Add(1))
+	} else {
+		// Ensure the directory exists
+		if err := os.MkdirAll(filepath.Dir(dbPath), 0755); err != nil {
+			return fmt.Errorf("failed to create database directory: %w", err)
+		}
 	}
 
 	// Initialize SQLite eventstore for Khatru
@@ -31,9 +43,9 @@ func (s *Storage) initSQLite(ctx context.Context) error {
 
 	// Create Khatru relay instance
 	relay := khatru.NewRelay()
-	relay.StoreEvent = append(relay.StoreEvent, db.SaveEvent)
+	relay.StoreEvent = append(relay.StoreEvent, db.SaveEvent, s.indexThread, s.indexFollows, s.indexAnnotations)
 	relay.QueryEvents = append(relay.QueryEvents, db.QueryEvents)
-	relay.DeleteEvent = append(relay.DeleteEvent, db.DeleteEvent)
+	relay.DeleteEvent = append(relay.DeleteEvent, db.DeleteEvent, s.deleteDerived)
 
 	s.relay = relay
 
@@ -64,3 +76,9 @@ func (s *Storage) initSQLite(ctx context.Context) error {
 	s.db = sqlDB
 	return nil
 }
+
+// isInMemoryPath reports whether an SQLite path names a private in-memory
+// database. Shared-cache DSNs (mode=memory&cache=shared) are used as given.
+func isInMemoryPath(path string) bool {
+	return path == ":memory:" || path == "file::memory:"
+}
@@ -5,6 +5,7 @@ import (
 	"database/sql"
 	"fmt"
 	"os"
+	"strings"
 	"time"
 
 	"github.com/nbd-wtf/go-nostr"
@@ -55,6 +56,120 @@ func (s *Storage) CountEventsByKind(ctx context.Context) (map[int]int64, error)
 	return counts, nil
 }
 
+// CountEventsOfKind returns the number of stored events of one kind
+func (s *Storage) CountEventsOfKind(ctx context.Context, kind int) (int64, error) {
+	var count int64
+	if err := s.db.QueryRowContext(ctx, "SELECT COUNT(*) FROM event WHERE kind = ?", kind).Scan(&count); err != nil {
+		return 0, fmt.Errorf("failed to count events of kind %d: %w", kind, err)
+	}
+	return count, nil
+}
+
+// CountEventsByAuthor returns the number of stored events by one pubkey
+func (s *Storage) CountEventsByAuthor(ctx context.Context, pubkey string) (int64, error) {
+	var count int64
+	if err := s.db.QueryRowContext(ctx, "SELECT COUNT(*) FROM event WHERE pubkey = ?", pubkey).Scan(&count); err != nil {
+		return 0, fmt.Errorf("failed to count events by author: %w", err)
+	}
+	return count, nil
+}
+
+// CountEventsByMonth returns the number of events of the given kinds by one pubkey,
+// keyed by UTC month as "2006-01"
+func (s *Storage) CountEventsByMonth(ctx context.Context, pubkey string, kinds []int) (map[string]int64, error) {
+	counts := make(map[string]int64)
+	if len(kinds) == 0 {
+		return counts, nil
+	}
+
+	args := []interface{}{pubkey}
+	for _, kind := range kinds {
+		args = append(args, kind)
+	}
+	query := "SELECT strftime('%Y-%m', created_at, 'unixepoch') AS month, COUNT(*) FROM event" +
+		" WHERE pubkey = ? AND kind IN (?" + strings.Repeat(", ?", len(kinds)-1) + ") GROUP BY month"
+
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query event counts by month: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var month string
+		var count int64
+		if err := rows.Scan(&month, &count); err != nil {
+			return nil, fmt.Errorf("failed to scan row: %w", err)
+		}
+		counts[month] = count
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating rows: %w", err)
+	}
+
+	return counts, nil
+}
+
+// CountEventsByDay returns the number of events of the given kinds by one pubkey
+// created at or after since, keyed by UTC day as "2006-01-02"
+func (s *Storage) CountEventsByDay(ctx context.Context, pubkey string, kinds []int, since time.Time) (map[string]int64, error) {
+	counts := make(map[string]int64)
+	if len(kinds) == 0 {
+		return counts, nil
+	}
+
+	args := []interface{}{pubkey, since.Unix()}
+	for _, kind := range kinds {
+		args = append(args, kind)
+	}
+	query := "SELECT strftime('%Y-%m-%d', created_at, 'unixepoch') AS day, COUNT(*) FROM event" +
+		" WHERE pubkey = ? AND created_at >= ? AND kind IN (?" + strings.Repeat(", ?", len(kinds)-1) + ") GROUP BY day"
+
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query event counts by day: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var day string
+		var count int64
+		if err := rows.Scan(&day, &count); err != nil {
+			return nil, fmt.Errorf("failed to scan row: %w", err)
+		}
+		counts[day] = count
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating rows: %w", err)
+	}
+
+	return counts, nil
+}
+
+// LiveDataSize returns the size in MB of the pages holding data. Unlike the
+// file size it shrinks as events are deleted, since SQLite reuses freed pages
+// rather than truncating the file.
+func (s *Storage) LiveDataSize(ctx context.Context) (float64, error) {
+	if s.config.Driver != "sqlite" {
+		return s.DatabaseSize(ctx)
+	}
+
+	var pageCount, freePages, pageSize int64
+	for pragma, dest := range map[string]*int64{
+		"page_count":     &pageCount,
+		"freelist_count": &freePages,
+		"page_size":      &pageSize,
+	} {
+		if err := s.db.QueryRowContext(ctx, "PRAGMA "+pragma).Scan(dest); err != nil {
+			return 0, fmt.Errorf("failed to read %s: %w", pragma, err)
+		}
+	}
+
+	return float64((pageCount-freePages)*pageSize) / 1024 / 1024, nil
+}
+
 // DatabaseSize returns the database size in MB
 func (s *Storage) DatabaseSize(ctx context.Context) (float64, error) {
 	var path string
@@ -126,7 +241,7 @@ func (s *Storage) GetAllCursors(ctx context.Context) ([]CursorInfo, error) {
 	var cursors []CursorInfo
 
 	query := `
-		SELECT relay, kind, cursor, updated_at
+		SELECT relay, kind, since, updated_at
 		FROM sync_state
 		ORDER BY relay, kind
 	`
@@ -173,7 +288,13 @@ func (s *Storage) CountAggregates(ctx context.Context) (int64, error) {
 func (s *Storage) CountAggregatesByKind(ctx context.Context) (map[int]int64, error) {
 	counts := make(map[int]int64)
 
-	query := "SELECT kind, COUNT(*) FROM aggregates GROUP BY kind"
+	// Aggregates don't store the kind, so join against the event table
+	query := `
+		SELECT e.kind, COUNT(*)
+		FROM aggregates a
+		JOIN event e ON e.id = a.event_id
+		GROUP BY e.kind
+	`
 	rows, err := s.db.QueryContext(ctx, query)
 	if err != nil {
 		return nil, fmt.Errorf("failed to query aggregate counts by kind: %w", err)
@@ -200,7 +321,8 @@ func (s *Storage) CountAggregatesByKind(ctx context.Context) (map[int]int64, err
 func (s *Storage) LastReconcileTime(ctx context.Context) (*time.Time, error) {
 	var lastReconcileUnix sql.NullInt64
 
-	query := "SELECT MAX(last_reconciled_at) FROM aggregates"
+	// Reconciliation isn't tracked separately; the newest aggregate update is the best proxy
+	query := "SELECT MAX(last_interaction_at) FROM aggregates"
 	err := s.db.QueryRowContext(ctx, query).Scan(&lastReconcileUnix)
 	if err != nil {
 		return nil, fmt.Errorf("failed to query last reconcile time: %w", err)
@@ -252,10 +374,14 @@ func (s *Storage) GetEventsByKind(ctx context.Context, kind int, limit int) ([]*
 	return s.QueryEvents(ctx, filter)
 }
 
+// protectedEventsClause excludes events protected in retention_metadata
+const protectedEventsClause = "id NOT IN (SELECT event_id FROM retention_metadata WHERE protected = 1)"
+
 // DeleteEventsBefore deletes events created before the given timestamp
+// Protected events are never deleted
 func (s *Storage) DeleteEventsBefore(ctx context.Context, before time.Time) (int64, error) {
 	result, err := s.db.ExecContext(ctx,
-		"DELETE FROM event WHERE created_at < ?",
+		"DELETE FROM event WHERE created_at < ? AND "+protectedEventsClause,
 		before.Unix())
 	if err != nil {
 		return 0, fmt.Errorf("failed to delete events: %w", err)
@@ -266,13 +392,18 @@ func (s *Storage) DeleteEventsBefore(ctx context.Context, before time.Time) (int
 		return 0, fmt.Errorf("failed to get rows affected: %w", err)
 	}
 
+	if err := deleteOrphans(ctx, s.db); err != nil {
+		return deleted, err
+	}
+
 	return deleted, nil
 }
 
 // DeleteEventsByKind deletes all events of a specific kind
+// Protected events are never deleted
 func (s *Storage) DeleteEventsByKind(ctx context.Context, kind int) (int64, error) {
 	result, err := s.db.ExecContext(ctx,
-		"DELETE FROM event WHERE kind = ?",
+		"DELETE FROM event WHERE kind = ? AND "+protectedEventsClause,
 		kind)
 	if err != nil {
 		return 0, fmt.Errorf("failed to delete events: %w", err)
@@ -283,5 +414,31 @@ func (s *Storage) DeleteEventsByKind(ctx context.Context, kind int) (int64, erro
 		return 0, fmt.Errorf("failed to get rows affected: %w", err)
 	}
 
+	if err := deleteOrphans(ctx, s.db); err != nil {
+		return deleted, err
+	}
+
+	return deleted, nil
+}
+
+// DeleteEventsByAuthor deletes all events by a pubkey
+// Protected events are never deleted
+func (s *Storage) DeleteEventsByAuthor(ctx context.Context, pubkey string) (int64, error) {
+	result, err := s.db.ExecContext(ctx,
+		"DELETE FROM event WHERE pubkey = ? AND "+protectedEventsClause,
+		pubkey)
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete events: %w", err)
+	}
+
+	deleted, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if err := deleteOrphans(ctx, s.db); err != nil {
+		return deleted, err
+	}
+
 	return deleted, nil
 }
@@ -3,10 +3,12 @@ package storage
 import (
 	"context"
 	"database/sql"
+	"encoding/json"
 	"fmt"
 
 	"github.com/fiatjaf/khatru"
 	"github.com/nbd-wtf/go-nostr"
+	"github.com/sandwich/nophr/internal/annotate"
 	"github.com/sandwich/nophr/internal/config"
 )
 
@@ -15,12 +17,36 @@ type Storage struct {
 	relay  *khatru.Relay
 	db     *sql.DB
 	config *config.Storage
+
+	// allowEvent hides events from queries when it returns false
+	allowEvent func(*nostr.Event) bool
+
+	// annotator classifies notes and articles as they are stored
+	annotator *annotate.Classifier
 }
 
-// New creates a new Storage instance with the given configuration
+// New creates a new Storage instance with the given configuration, applying
+// pending schema migrations
 func New(ctx context.Context, cfg *config.Storage) (*Storage, error) {
+	s, err := Open(ctx, cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	// Run migrations for custom tables
+	if err := s.runMigrations(ctx); err != nil {
+		return nil, fmt.Errorf("failed to run migrations: %w", err)
+	}
+
+	return s, nil
+}
+
+// Open opens storage without migrating its schema, so "nophr migrate" can
+// inspect and roll back the schema as it is
+func Open(ctx context.Context, cfg *config.Storage) (*Storage, error) {
 	s := &Storage{
-		config: cfg,
+		config:    cfg,
+		annotator: annotate.New(cfg.Annotations.Topics),
 	}
 
 	// Initialize the appropriate backend
@@ -37,11 +63,6 @@ func New(ctx context.Context, cfg *config.Storage) (*Storage, error) {
 		return nil, fmt.Errorf("unsupported storage driver: %s", cfg.Driver)
 	}
 
-	// Run migrations for custom tables
-	if err := s.runMigrations(ctx); err != nil {
-		return nil, fmt.Errorf("failed to run migrations: %w", err)
-	}
-
 	return s, nil
 }
 
@@ -55,6 +76,18 @@ func (s *Storage) DB() *sql.DB {
 	return s.db
 }
 
+// SetEventFilter hides events for which allow returns false from QueryEvents
+// and QueryNotes, so every protocol renders the same filtered view. Events are
+// still stored; nil shows everything.
+func (s *Storage) SetEventFilter(allow func(*nostr.Event) bool) {
+	s.allowEvent = allow
+}
+
+// visible reports whether the event filter lets an event through
+func (s *Storage) visible(event *nostr.Event) bool {
+	return s.allowEvent == nil || s.allowEvent(event)
+}
+
 // StoreEvent stores an event in the Khatru relay
 func (s *Storage) StoreEvent(ctx context.Context, event *nostr.Event) error {
 	if s.relay == nil {
@@ -71,40 +104,76 @@ func (s *Storage) StoreEvent(ctx context.Context, event *nostr.Event) error {
 	return nil
 }
 
-// StoreEventBatch stores multiple events in a single transaction (Performance optimization)
-func (s *Storage) StoreEventBatch(ctx context.Context, events []*nostr.Event) error {
+// StoreEventBatch stores events in a single transaction and returns the ones
+// that weren't stored already. It writes what the relay's StoreEvent handlers
+// would, the event and its thread and annotation indexes, in one commit
+// instead of several per event; contact lists update the follows table once
+// the batch is committed.
+func (s *Storage) StoreEventBatch(ctx context.Context, events []*nostr.Event) ([]*nostr.Event, error) {
 	if s.relay == nil {
-		return fmt.Errorf("relay not initialized")
+		return nil, fmt.Errorf("relay not initialized")
 	}
 
 	if len(events) == 0 {
-		return nil
+		return nil, nil
 	}
 
-	// Start transaction for batch insert
 	tx, err := s.db.BeginTx(ctx, nil)
 	if err != nil {
-		return fmt.Errorf("failed to begin transaction: %w", err)
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
 	}
 	defer tx.Rollback()
 
-	// Store each event within the transaction
-	// Note: Khatru's StoreEvent handlers need to be transaction-aware
-	// For now, we'll call them individually but within a transaction context
+	// Same row as the eventstore's SaveEvent writes
+	stmt, err := tx.PrepareContext(ctx, `
+		INSERT OR IGNORE INTO event (id, pubkey, created_at, kind, tags, content, sig)
+		VALUES (?, ?, ?, ?, ?, ?, ?)`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to prepare event insert: %w", err)
+	}
+	defer stmt.Close()
+
+	var stored []*nostr.Event
 	for _, event := range events {
-		for _, handler := range s.relay.StoreEvent {
-			if err := handler(ctx, event); err != nil {
-				return fmt.Errorf("failed to store event in batch: %w", err)
+		tags, err := json.Marshal(event.Tags)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode tags of %s: %w", event.ID, err)
+		}
+		result, err := stmt.ExecContext(ctx, event.ID, event.PubKey, event.CreatedAt, event.Kind, tags, event.Content, event.Sig)
+		if err != nil {
+			return nil, fmt.Errorf("failed to store event %s: %w", event.ID, err)
+		}
+		if n, err := result.RowsAffected(); err != nil || n == 0 {
+			continue // Already stored
+		}
+
+		if event.Kind == 1 {
+			if _, err := tx.ExecContext(ctx,
+				"INSERT OR REPLACE INTO event_threads (event_id, is_reply) VALUES (?, ?)",
+				event.ID, IsReplyNote(event)); err != nil {
+				return nil, fmt.Errorf("failed to index note %s: %w", event.ID, err)
 			}
 		}
+		if annotate.Annotates(event.Kind) {
+			if err := s.saveAnnotations(ctx, tx, event); err != nil {
+				return nil, err
+			}
+		}
+		stored = append(stored, event)
 	}
 
-	// Commit transaction
 	if err := tx.Commit(); err != nil {
-		return fmt.Errorf("failed to commit batch: %w", err)
+		return nil, fmt.Errorf("failed to commit batch: %w", err)
 	}
 
-	return nil
+	// Contact lists are compared with the stored ones, so are indexed after the commit
+	for _, event := range stored {
+		if err := s.indexFollows(ctx, event); err != nil {
+			return stored, err
+		}
+	}
+
+	return stored, nil
 }
 
 // EventExists checks if an event already exists in storage (for deduplication)
@@ -153,12 +222,50 @@ func (s *Storage) DeleteEvent(ctx context.Context, eventID string) error {
 	return nil
 }
 
+// derivedTables hold rows keyed by event_id that go when their event does.
+// Their foreign keys cascade only on connections with foreign_keys on, which
+// the eventstore's are not, so deletes clean them up explicitly.
+var derivedTables = []string{"retention_metadata", "event_threads", "translations", "event_annotations", "annotation_values"}
+
+// execer is a *sql.DB or *sql.Tx
+type execer interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+}
+
+// deleteDerived removes the rows derived from a deleted event
+func (s *Storage) deleteDerived(ctx context.Context, event *nostr.Event) error {
+	for _, table := range derivedTables {
+		if _, err := s.db.ExecContext(ctx, "DELETE FROM "+table+" WHERE event_id = ?", event.ID); err != nil {
+			return fmt.Errorf("failed to clean up %s: %w", table, err)
+		}
+	}
+	return nil
+}
+
+// deleteOrphans removes derived rows whose event is gone, after events are
+// deleted in bulk
+func deleteOrphans(ctx context.Context, db execer) error {
+	for _, table := range derivedTables {
+		if _, err := db.ExecContext(ctx, "DELETE FROM "+table+" WHERE event_id NOT IN (SELECT id FROM event)"); err != nil {
+			return fmt.Errorf("failed to clean up %s: %w", table, err)
+		}
+	}
+	return nil
+}
+
 // QueryEvents queries events from the Khatru relay using Nostr filters
 func (s *Storage) QueryEvents(ctx context.Context, filter nostr.Filter) ([]*nostr.Event, error) {
 	if s.relay == nil {
 		return nil, fmt.Errorf("relay not initialized")
 	}
 
+	// Search qualifiers such as language:es are answered from annotations,
+	// which the eventstore doesn't know about
+	if query := ParseSearch(filter.Search); len(query.Annotations) > 0 {
+		filter.Search = query.Terms
+		return s.QueryAnnotated(ctx, filter, query.Annotations)
+	}
+
 	// Use the first QueryEvents handler (eventstore)
 	if len(s.relay.QueryEvents) == 0 {
 		return nil, fmt.Errorf("no query handlers configured")
@@ -172,7 +279,9 @@ func (s *Storage) QueryEvents(ctx context.Context, filter nostr.Filter) ([]*nost
 	// Collect events from channel
 	var events []*nostr.Event
 	for event := range ch {
-		events = append(events, event)
+		if s.visible(event) {
+			events = append(events, event)
+		}
 	}
 
 	return events, nil
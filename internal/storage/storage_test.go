@@ -2,9 +2,12 @@ package storage
 
 import (
 	"context"
+	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/nbd-wtf/go-nostr"
 	"github.com/sandwich/nophr/internal/config"
@@ -123,6 +126,86 @@ func TestStoreAndQueryEvents(t *testing.T) {
 	}
 }
 
+func TestStoreEventBatch(t *testing.T) {
+	s, cleanup := setupTestStorage(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	sk := nostr.GeneratePrivateKey()
+	followed := strings.Repeat("b", 64)
+
+	root := &nostr.Event{CreatedAt: 1000, Kind: 1, Content: "gm #coffee"}
+	reply := &nostr.Event{CreatedAt: 1001, Kind: 1, Content: "gm", Tags: nostr.Tags{{"e", strings.Repeat("c", 64), "", "root"}}}
+	contacts := &nostr.Event{CreatedAt: 1002, Kind: 3, Tags: nostr.Tags{{"p", followed}}}
+	for _, event := range []*nostr.Event{root, reply, contacts} {
+		event.Sign(sk)
+	}
+
+	if err := s.StoreEvent(ctx, root); err != nil {
+		t.Fatalf("Failed to store event: %v", err)
+	}
+
+	// The already stored note is skipped, the rest stored and indexed
+	stored, err := s.StoreEventBatch(ctx, []*nostr.Event{root, reply, contacts})
+	if err != nil {
+		t.Fatalf("StoreEventBatch failed: %v", err)
+	}
+	if len(stored) != 2 || stored[0].ID != reply.ID || stored[1].ID != contacts.ID {
+		t.Fatalf("StoreEventBatch stored %d events, want the reply and the contact list", len(stored))
+	}
+
+	events, err := s.QueryEvents(ctx, nostr.Filter{IDs: []string{reply.ID}})
+	if err != nil || len(events) != 1 || events[0].Tags[0][3] != "root" {
+		t.Fatalf("QueryEvents(reply) = %v, %v", events, err)
+	}
+	replies, err := s.QueryNotes(ctx, nostr.Filter{Kinds: []int{1}}, true)
+	if err != nil || len(replies) != 1 || replies[0].ID != reply.ID {
+		t.Errorf("QueryNotes(replies) = %d events, %v; want the batched reply", len(replies), err)
+	}
+	if follows, err := s.GetFollows(ctx, contacts.PubKey); err != nil || len(follows) != 1 || follows[0] != followed {
+		t.Errorf("GetFollows() = %v, %v", follows, err)
+	}
+	if tags, err := s.GetAnnotations(ctx, root.ID, "t"); err != nil || len(tags) != 1 {
+		t.Errorf("GetAnnotations(root) = %v, %v", tags, err)
+	}
+}
+
+func TestEventFilter(t *testing.T) {
+	s, cleanup := setupTestStorage(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	for i, pubkey := range []string{"allowed", "denied"} {
+		event := &nostr.Event{ID: fmt.Sprintf("event-%d", i), PubKey: pubkey, CreatedAt: nostr.Now(), Kind: 1, Tags: nostr.Tags{}}
+		if err := s.StoreEvent(ctx, event); err != nil {
+			t.Fatalf("Failed to store event: %v", err)
+		}
+	}
+
+	s.SetEventFilter(func(event *nostr.Event) bool { return event.PubKey != "denied" })
+
+	events, err := s.QueryEvents(ctx, nostr.Filter{Kinds: []int{1}})
+	if err != nil {
+		t.Fatalf("QueryEvents failed: %v", err)
+	}
+	if len(events) != 1 || events[0].PubKey != "allowed" {
+		t.Errorf("Expected only the allowed author's event, got %d events", len(events))
+	}
+
+	notes, err := s.QueryNotes(ctx, nostr.Filter{}, false)
+	if err != nil {
+		t.Fatalf("QueryNotes failed: %v", err)
+	}
+	if len(notes) != 1 || notes[0].PubKey != "allowed" {
+		t.Errorf("Expected only the allowed author's note, got %d notes", len(notes))
+	}
+
+	s.SetEventFilter(nil)
+	if events, _ := s.QueryEvents(ctx, nostr.Filter{Kinds: []int{1}}); len(events) != 2 {
+		t.Errorf("Expected both events without a filter, got %d", len(events))
+	}
+}
+
 func TestRelayHints(t *testing.T) {
 	s, cleanup := setupTestStorage(t)
 	defer cleanup()
@@ -192,6 +275,88 @@ func TestRelayHints(t *testing.T) {
 	}
 }
 
+func TestSaveRelayHints(t *testing.T) {
+	s, cleanup := setupTestStorage(t)
+	defer cleanup()
+
+	ctx := context.Background()
+
+	// More hints than fit in one batch, for two pubkeys
+	var hints []*RelayHint
+	for i := 0; i < relayHintBatchSize+10; i++ {
+		hints = append(hints, &RelayHint{
+			Pubkey:    fmt.Sprintf("pubkey-%d", i%2),
+			Relay:     fmt.Sprintf("wss://relay%d.test", i),
+			CanRead:   true,
+			Freshness: 100,
+		})
+	}
+
+	if err := s.SaveRelayHints(ctx, hints); err != nil {
+		t.Fatalf("Failed to save relay hints: %v", err)
+	}
+
+	stored, err := s.GetRelayHints(ctx, "pubkey-0")
+	if err != nil {
+		t.Fatalf("Failed to get relay hints: %v", err)
+	}
+	if len(stored) != (relayHintBatchSize+10)/2 {
+		t.Errorf("Expected %d hints, got %d", (relayHintBatchSize+10)/2, len(stored))
+	}
+
+	// Older hints in a batch don't overwrite fresher ones
+	stale := &RelayHint{Pubkey: "pubkey-0", Relay: "wss://relay0.test", CanWrite: true, Freshness: 50}
+	if err := s.SaveRelayHints(ctx, []*RelayHint{stale}); err != nil {
+		t.Fatalf("Failed to save relay hints: %v", err)
+	}
+	writeRelays, err := s.GetWriteRelays(ctx, "pubkey-0")
+	if err != nil {
+		t.Fatalf("Failed to get write relays: %v", err)
+	}
+	if len(writeRelays) != 0 {
+		t.Errorf("Expected the stale hint to be ignored, got write relays %v", writeRelays)
+	}
+
+	if err := s.SaveRelayHints(ctx, nil); err != nil {
+		t.Errorf("Saving no hints should succeed: %v", err)
+	}
+}
+
+func TestSourcedRelayHints(t *testing.T) {
+	s, cleanup := setupTestStorage(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	pubkey := "test-pubkey"
+
+	sighting := &SourcedRelayHint{Pubkey: pubkey, Relay: "wss://tag.test", Source: HintSourceTag, Sightings: 1, LastSeen: 100}
+	for range 2 {
+		if err := s.RecordRelayHintSightings(ctx, []*SourcedRelayHint{sighting}); err != nil {
+			t.Fatalf("Failed to record sighting: %v", err)
+		}
+	}
+	if err := s.ReplaceSourcedRelayHints(ctx, pubkey, HintSourceNIP05, []string{"wss://old.test"}, 100); err != nil {
+		t.Fatalf("Failed to save NIP-05 hints: %v", err)
+	}
+	if err := s.ReplaceSourcedRelayHints(ctx, pubkey, HintSourceNIP05, []string{"wss://new.test"}, 200); err != nil {
+		t.Fatalf("Failed to replace NIP-05 hints: %v", err)
+	}
+
+	hints, err := s.GetSourcedRelayHints(ctx, pubkey)
+	if err != nil {
+		t.Fatalf("Failed to get hints: %v", err)
+	}
+	if len(hints) != 2 {
+		t.Fatalf("Expected 2 hints, got %d", len(hints))
+	}
+	if hints[0].Relay != "wss://tag.test" || hints[0].Sightings != 2 {
+		t.Errorf("Expected the tag hint seen twice first, got %s x%d", hints[0].Relay, hints[0].Sightings)
+	}
+	if hints[1].Relay != "wss://new.test" || hints[1].Source != HintSourceNIP05 {
+		t.Errorf("Expected the replaced NIP-05 hint, got %s (%s)", hints[1].Relay, hints[1].Source)
+	}
+}
+
 func TestGraphNodes(t *testing.T) {
 	s, cleanup := setupTestStorage(t)
 	defer cleanup()
@@ -328,6 +493,40 @@ func TestSyncState(t *testing.T) {
 	}
 }
 
+func TestBootstrapState(t *testing.T) {
+	s, cleanup := setupTestStorage(t)
+	defer cleanup()
+
+	ctx := context.Background()
+
+	state, err := s.GetBootstrapState(ctx, "owner")
+	if err != nil || state != nil {
+		t.Fatalf("Expected no state before a bootstrap, got %+v, %v", state, err)
+	}
+
+	saved := &BootstrapState{Owner: "owner", Phase: "contacts", StartedAt: 100, UpdatedAt: 100}
+	if err := s.SaveBootstrapState(ctx, saved); err != nil {
+		t.Fatalf("Failed to save bootstrap state: %v", err)
+	}
+	saved.HintsAfter = "abc"
+	saved.UpdatedAt = 200
+	if err := s.SaveBootstrapState(ctx, saved); err != nil {
+		t.Fatalf("Failed to update bootstrap state: %v", err)
+	}
+
+	state, err = s.GetBootstrapState(ctx, "owner")
+	if err != nil {
+		t.Fatalf("Failed to get bootstrap state: %v", err)
+	}
+	if *state != *saved {
+		t.Errorf("Expected %+v, got %+v", saved, state)
+	}
+
+	if other, _ := s.GetBootstrapState(ctx, "someone-else"); other != nil {
+		t.Errorf("Expected no state for another owner, got %+v", other)
+	}
+}
+
 func TestAggregates(t *testing.T) {
 	s, cleanup := setupTestStorage(t)
 	defer cleanup()
@@ -414,6 +613,20 @@ func TestAggregates(t *testing.T) {
 		t.Errorf("Expected 1 aggregate, got %d", len(aggregates))
 	}
 
+	// A reply to an event with no aggregate yet creates one without reaction counts
+	if err := s.IncrementReplyCount(ctx, "event-456", 12349); err != nil {
+		t.Fatalf("Failed to increment reply count: %v", err)
+	}
+
+	aggregates, err = s.GetAggregates(ctx, []string{"event-456"})
+	if err != nil {
+		t.Fatalf("Failed to get reply-only aggregate: %v", err)
+	}
+
+	if aggregates["event-456"] == nil || aggregates["event-456"].ReplyCount != 1 {
+		t.Errorf("Expected reply count 1 for a reply-only aggregate, got %+v", aggregates["event-456"])
+	}
+
 	// Delete aggregate
 	if err := s.DeleteAggregate(ctx, agg.EventID); err != nil {
 		t.Fatalf("Failed to delete aggregate: %v", err)
@@ -424,3 +637,95 @@ func TestAggregates(t *testing.T) {
 		t.Error("Expected error when getting deleted aggregate, got nil")
 	}
 }
+
+func TestInteractionSeen(t *testing.T) {
+	s, cleanup := setupTestStorage(t)
+	defer cleanup()
+
+	ctx := context.Background()
+
+	seen, err := s.IsInteractionSeen(ctx, "reaction1")
+	if err != nil {
+		t.Fatalf("Failed to check seen interaction: %v", err)
+	}
+	if seen {
+		t.Error("Expected reaction1 to be unseen before it is marked")
+	}
+
+	if err := s.MarkInteractionsSeen(ctx, map[string]string{"reaction1": "note1"}); err != nil {
+		t.Fatalf("Failed to mark interaction seen: %v", err)
+	}
+	// Marking again is harmless
+	if err := s.MarkInteractionsSeen(ctx, map[string]string{"reaction1": "note1"}); err != nil {
+		t.Fatalf("Failed to re-mark interaction seen: %v", err)
+	}
+
+	if seen, _ := s.IsInteractionSeen(ctx, "reaction1"); !seen {
+		t.Error("Expected re-ingested reaction1 to be reported as seen")
+	}
+
+	// Deleting the aggregate forgets what was counted toward it
+	if err := s.DeleteAggregate(ctx, "note1"); err != nil {
+		t.Fatalf("Failed to delete aggregate: %v", err)
+	}
+	if seen, _ := s.IsInteractionSeen(ctx, "reaction1"); seen {
+		t.Error("Expected reaction1 to count again after the aggregate was deleted")
+	}
+}
+
+func TestCountEventsByMonth(t *testing.T) {
+	s, cleanup := setupTestStorage(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	sk := nostr.GeneratePrivateKey()
+	pk, _ := nostr.GetPublicKey(sk)
+
+	// 2024-06-01, 2024-06-30, 2024-07-01 (UTC), plus a reaction that isn't counted
+	for i, ts := range []int64{1717200000, 1719705600, 1719792000} {
+		event := &nostr.Event{CreatedAt: nostr.Timestamp(ts), Kind: 1, Content: fmt.Sprintf("note %d", i)}
+		event.Sign(sk)
+		if err := s.StoreEvent(ctx, event); err != nil {
+			t.Fatalf("Failed to store event: %v", err)
+		}
+	}
+	reaction := &nostr.Event{CreatedAt: 1717200000, Kind: 7, Content: "+"}
+	reaction.Sign(sk)
+	if err := s.StoreEvent(ctx, reaction); err != nil {
+		t.Fatalf("Failed to store event: %v", err)
+	}
+
+	counts, err := s.CountEventsByMonth(ctx, pk, []int{1, 30023})
+	if err != nil {
+		t.Fatalf("CountEventsByMonth failed: %v", err)
+	}
+	if len(counts) != 2 || counts["2024-06"] != 2 || counts["2024-07"] != 1 {
+		t.Errorf("Unexpected monthly counts: %v", counts)
+	}
+}
+
+func TestCountEventsByDay(t *testing.T) {
+	s, cleanup := setupTestStorage(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	sk := nostr.GeneratePrivateKey()
+	pk, _ := nostr.GetPublicKey(sk)
+
+	// 2024-06-01 00:00 and 23:59, 2024-06-02 (UTC), and one from before since
+	for i, ts := range []int64{1717200000, 1717286399, 1717286400, 1717113600} {
+		event := &nostr.Event{CreatedAt: nostr.Timestamp(ts), Kind: 1, Content: fmt.Sprintf("note %d", i)}
+		event.Sign(sk)
+		if err := s.StoreEvent(ctx, event); err != nil {
+			t.Fatalf("Failed to store event: %v", err)
+		}
+	}
+
+	counts, err := s.CountEventsByDay(ctx, pk, []int{1}, time.Unix(1717200000, 0))
+	if err != nil {
+		t.Fatalf("CountEventsByDay failed: %v", err)
+	}
+	if len(counts) != 2 || counts["2024-06-01"] != 2 || counts["2024-06-02"] != 1 {
+		t.Errorf("Unexpected daily counts: %v", counts)
+	}
+}
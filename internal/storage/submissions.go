@@ -0,0 +1,55 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// RecordSubmission records a client certificate's use of a nonce. It returns
+// false, without recording anything, when the nonce was already used by that
+// certificate and hasn't been pruned yet.
+func (s *Storage) RecordSubmission(ctx context.Context, fingerprint, nonce string, at time.Time) (bool, error) {
+	result, err := s.db.ExecContext(ctx,
+		"INSERT OR IGNORE INTO submissions (fingerprint, nonce, seen_at) VALUES (?, ?, ?)",
+		fingerprint, nonce, at.Unix())
+	if err != nil {
+		return false, fmt.Errorf("failed to record submission: %w", err)
+	}
+	recorded, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	return recorded == 1, nil
+}
+
+// ForgetSubmission removes a recorded nonce, so a submission that failed can
+// be retried with it
+func (s *Storage) ForgetSubmission(ctx context.Context, fingerprint, nonce string) error {
+	if _, err := s.db.ExecContext(ctx,
+		"DELETE FROM submissions WHERE fingerprint = ? AND nonce = ?",
+		fingerprint, nonce); err != nil {
+		return fmt.Errorf("failed to forget submission: %w", err)
+	}
+	return nil
+}
+
+// CountSubmissions returns how many submissions a client certificate made since a time
+func (s *Storage) CountSubmissions(ctx context.Context, fingerprint string, since time.Time) (int, error) {
+	var count int
+	if err := s.db.QueryRowContext(ctx,
+		"SELECT COUNT(*) FROM submissions WHERE fingerprint = ? AND seen_at >= ?",
+		fingerprint, since.Unix()).Scan(&count); err != nil {
+		return 0, fmt.Errorf("failed to count submissions: %w", err)
+	}
+	return count, nil
+}
+
+// PruneSubmissions removes submissions recorded before a time
+func (s *Storage) PruneSubmissions(ctx context.Context, before time.Time) (int64, error) {
+	result, err := s.db.ExecContext(ctx, "DELETE FROM submissions WHERE seen_at < ?", before.Unix())
+	if err != nil {
+		return 0, fmt.Errorf("failed to prune submissions: %w", err)
+	}
+	return result.RowsAffected()
+}
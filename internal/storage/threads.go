@@ -0,0 +1,238 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/nbd-wtf/go-nostr"
+	"github.com/sandwich/nophr/internal/nostr/helpers"
+)
+
+// threadIndexBatch is how many notes are classified per pass when rebuilding
+const threadIndexBatch = 500
+
+// IsReplyNote reports whether a kind 1 note replies to another event under NIP-10,
+// using the same parser as aggregates.ParseThreadInfo
+func IsReplyNote(event *nostr.Event) bool {
+	return helpers.ParseThreadRefs(event.Tags).IsReply()
+}
+
+// indexThread records whether a newly stored kind 1 note is a reply. It runs as a
+// relay StoreEvent handler after the eventstore, so notes from sync and relay
+// writes are indexed as they are ingested.
+func (s *Storage) indexThread(ctx context.Context, event *nostr.Event) error {
+	if event.Kind != 1 {
+		return nil
+	}
+	if _, err := s.db.ExecContext(ctx,
+		"INSERT OR REPLACE INTO event_threads (event_id, is_reply) VALUES (?, ?)",
+		event.ID, IsReplyNote(event)); err != nil {
+		return fmt.Errorf("failed to index note %s: %w", event.ID, err)
+	}
+	return nil
+}
+
+// rebuildThreadIndex reclassifies every stored kind 1 note. Migrations call it
+// when the schema version changes, so notes stored before the index existed (or
+// classified by an older parser) are indexed once rather than on every query.
+func (s *Storage) rebuildThreadIndex(ctx context.Context) error {
+	if _, err := s.db.ExecContext(ctx, "DELETE FROM event_threads"); err != nil {
+		return fmt.Errorf("failed to clear thread index: %w", err)
+	}
+
+	for {
+		rows, err := s.db.QueryContext(ctx, `
+			SELECT e.id, e.tags
+			FROM event e
+			LEFT JOIN event_threads t ON t.event_id = e.id
+			WHERE e.kind = 1 AND t.event_id IS NULL
+			LIMIT ?`, threadIndexBatch)
+		if err != nil {
+			return fmt.Errorf("failed to query unindexed notes: %w", err)
+		}
+
+		var events []*nostr.Event
+		for rows.Next() {
+			var event nostr.Event
+			var tags string
+			if err := rows.Scan(&event.ID, &tags); err != nil {
+				rows.Close()
+				return fmt.Errorf("failed to scan note: %w", err)
+			}
+			if err := json.Unmarshal([]byte(tags), &event.Tags); err != nil {
+				rows.Close()
+				return fmt.Errorf("failed to decode tags of %s: %w", event.ID, err)
+			}
+			events = append(events, &event)
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return fmt.Errorf("failed to read unindexed notes: %w", err)
+		}
+
+		if len(events) == 0 {
+			return nil
+		}
+
+		if err := s.saveThreadFlags(ctx, events); err != nil {
+			return err
+		}
+
+		if len(events) < threadIndexBatch {
+			return nil
+		}
+	}
+}
+
+// saveThreadFlags records the reply flag for a batch of notes in one transaction
+func (s *Storage) saveThreadFlags(ctx context.Context, events []*nostr.Event) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	stmt, err := tx.PrepareContext(ctx, "INSERT OR REPLACE INTO event_threads (event_id, is_reply) VALUES (?, ?)")
+	if err != nil {
+		return fmt.Errorf("failed to prepare thread index insert: %w", err)
+	}
+	defer stmt.Close()
+
+	for _, event := range events {
+		if _, err := stmt.ExecContext(ctx, event.ID, IsReplyNote(event)); err != nil {
+			return fmt.Errorf("failed to index note %s: %w", event.ID, err)
+		}
+	}
+
+	return tx.Commit()
+}
+
+// restoreThreadFlag indexes a note restored from the trash within the restore transaction
+func restoreThreadFlag(ctx context.Context, tx *sql.Tx, eventID string) error {
+	var event nostr.Event
+	var tags string
+	err := tx.QueryRowContext(ctx, "SELECT kind, tags FROM event WHERE id = ?", eventID).Scan(&event.Kind, &tags)
+	if err == sql.ErrNoRows {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read restored event: %w", err)
+	}
+	if event.Kind != 1 {
+		return nil
+	}
+	if err := json.Unmarshal([]byte(tags), &event.Tags); err != nil {
+		return fmt.Errorf("failed to decode tags of %s: %w", eventID, err)
+	}
+
+	if _, err := tx.ExecContext(ctx,
+		"INSERT OR REPLACE INTO event_threads (event_id, is_reply) VALUES (?, ?)",
+		eventID, IsReplyNote(&event)); err != nil {
+		return fmt.Errorf("failed to index note %s: %w", eventID, err)
+	}
+	return nil
+}
+
+// QueryNotes returns kind 1 notes matching filter that are replies (replies=true)
+// or root notes (replies=false), newest first. Authors, Tags, Since, Until and
+// Limit are honoured; Kinds is ignored.
+func (s *Storage) QueryNotes(ctx context.Context, filter nostr.Filter, replies bool) ([]*nostr.Event, error) {
+	conditions := []string{"e.kind = 1", "t.is_reply = ?"}
+	args := []interface{}{replies}
+
+	if len(filter.Authors) > 0 {
+		conditions = append(conditions, "e.pubkey IN ("+placeholders(len(filter.Authors))+")")
+		for _, author := range filter.Authors {
+			args = append(args, author)
+		}
+	}
+
+	for name, values := range filter.Tags {
+		if len(values) == 0 {
+			continue
+		}
+		// Match the tag name and value exactly rather than by substring
+		conditions = append(conditions, `EXISTS (
+			SELECT 1 FROM json_each(e.tags) tag
+			WHERE json_extract(tag.value, '$[0]') = ?
+			AND json_extract(tag.value, '$[1]') IN (`+placeholders(len(values))+`))`)
+		args = append(args, name)
+		for _, value := range values {
+			args = append(args, value)
+		}
+	}
+
+	if filter.Since != nil {
+		conditions = append(conditions, "e.created_at >= ?")
+		args = append(args, int64(*filter.Since))
+	}
+	if filter.Until != nil {
+		conditions = append(conditions, "e.created_at <= ?")
+		args = append(args, int64(*filter.Until))
+	}
+
+	query := `
+		SELECT e.id, e.pubkey, e.created_at, e.kind, e.tags, e.content, e.sig
+		FROM event e
+		JOIN event_threads t ON t.event_id = e.id
+		WHERE ` + strings.Join(conditions, " AND ") + `
+		ORDER BY e.created_at DESC, e.id`
+	if filter.Limit > 0 {
+		query += " LIMIT ?"
+		args = append(args, filter.Limit)
+	}
+
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query notes: %w", err)
+	}
+	defer rows.Close()
+
+	var events []*nostr.Event
+	for rows.Next() {
+		event, err := scanEvent(rows, nil)
+		if err != nil {
+			return nil, err
+		}
+		if s.visible(event) {
+			events = append(events, event)
+		}
+	}
+
+	return events, rows.Err()
+}
+
+// GetMostInteracted returns IDs of events of a kind ordered by interaction score
+// (replies + reactions + zapped sats / 1000), highest first
+func (s *Storage) GetMostInteracted(ctx context.Context, kind, limit int) ([]string, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT a.event_id
+		FROM aggregates a
+		JOIN event e ON e.id = a.event_id
+		WHERE e.kind = ?
+		ORDER BY (a.reply_count + a.reaction_total + a.zap_sats_total / 1000) DESC, e.created_at DESC
+		LIMIT ?`, kind, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query most interacted events: %w", err)
+	}
+	defer rows.Close()
+
+	var ids []string
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("failed to scan event ID: %w", err)
+		}
+		ids = append(ids, id)
+	}
+
+	return ids, rows.Err()
+}
+
+// placeholders returns n comma-separated SQL parameter markers
+func placeholders(n int) string {
+	return strings.TrimSuffix(strings.Repeat("?, ", n), ", ")
+}
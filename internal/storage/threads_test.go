@@ -0,0 +1,136 @@
+package storage
+
+import (
+	"context"
+	"testing"
+
+	"github.com/nbd-wtf/go-nostr"
+)
+
+func TestQueryNotes(t *testing.T) {
+	storage, cleanup := setupTestStorage(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	sk := nostr.GeneratePrivateKey()
+	pk, _ := nostr.GetPublicKey(sk)
+	other, _ := nostr.GetPublicKey(nostr.GeneratePrivateKey())
+	parent := "0000000000000000000000000000000000000000000000000000000000000001"
+
+	tagSets := []nostr.Tags{
+		{},                             // root note
+		{{"e", parent, "", "mention"}}, // root note quoting another
+		{{"e", parent}},                // positional reply
+		{{"e", parent, "", "reply"}, {"p", other}}, // marked reply to other
+		{{"e", parent, "", "root"}},                // marked direct reply to a root
+	}
+	var stored []*nostr.Event
+	for i, tags := range tagSets {
+		event := &nostr.Event{
+			PubKey:    pk,
+			CreatedAt: nostr.Timestamp(1000 + i),
+			Kind:      1,
+			Tags:      tags,
+			Content:   "note",
+		}
+		event.Sign(sk)
+		if err := storage.StoreEvent(ctx, event); err != nil {
+			t.Fatalf("Failed to store event: %v", err)
+		}
+		stored = append(stored, event)
+	}
+
+	roots, err := storage.QueryNotes(ctx, nostr.Filter{Authors: []string{pk}}, false)
+	if err != nil {
+		t.Fatalf("QueryNotes failed: %v", err)
+	}
+	if len(roots) != 2 || roots[0].CreatedAt != 1001 || roots[1].CreatedAt != 1000 {
+		t.Fatalf("Expected the two root notes newest first, got %d", len(roots))
+	}
+
+	replies, err := storage.QueryNotes(ctx, nostr.Filter{Authors: []string{pk}, Limit: 1}, true)
+	if err != nil {
+		t.Fatalf("QueryNotes failed: %v", err)
+	}
+	if len(replies) != 1 || replies[0].CreatedAt != 1004 {
+		t.Fatalf("Expected the newest reply only, got %d", len(replies))
+	}
+
+	tagged, err := storage.QueryNotes(ctx, nostr.Filter{Tags: nostr.TagMap{"p": []string{other}}}, true)
+	if err != nil {
+		t.Fatalf("QueryNotes failed: %v", err)
+	}
+	if len(tagged) != 1 || len(tagged[0].Tags) != 2 {
+		t.Errorf("Expected one reply tagging the other pubkey, got %d", len(tagged))
+	}
+
+	until := nostr.Timestamp(1002)
+	older, err := storage.QueryNotes(ctx, nostr.Filter{Until: &until}, true)
+	if err != nil {
+		t.Fatalf("QueryNotes failed: %v", err)
+	}
+	if len(older) != 1 || older[0].CreatedAt != 1002 {
+		t.Errorf("Expected Until to bound replies, got %d", len(older))
+	}
+
+	// Notes are indexed as they are stored
+	if count := countThreadRows(t, storage); count != len(tagSets) {
+		t.Errorf("Expected %d indexed notes, got %d", len(tagSets), count)
+	}
+
+	// Deleting a note removes its index row through the foreign key cascade
+	if err := storage.DeleteEvent(ctx, stored[0].ID); err != nil {
+		t.Fatalf("Failed to delete event: %v", err)
+	}
+	if count := countThreadRows(t, storage); count != len(tagSets)-1 {
+		t.Errorf("Expected the deleted note's index row to cascade, got %d rows", count)
+	}
+}
+
+func TestRebuildThreadIndex(t *testing.T) {
+	storage, cleanup := setupTestStorage(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	sk := nostr.GeneratePrivateKey()
+	event := &nostr.Event{
+		CreatedAt: 1000,
+		Kind:      1,
+		Tags:      nostr.Tags{{"e", "0000000000000000000000000000000000000000000000000000000000000001"}},
+		Content:   "reply",
+	}
+	event.Sign(sk)
+	if err := storage.StoreEvent(ctx, event); err != nil {
+		t.Fatalf("Failed to store event: %v", err)
+	}
+
+	// Simulate a database from before the thread index existed
+	if _, err := storage.DB().ExecContext(ctx, "DELETE FROM event_threads"); err != nil {
+		t.Fatalf("Failed to clear thread index: %v", err)
+	}
+	if _, err := storage.DB().ExecContext(ctx, "PRAGMA user_version = 0"); err != nil {
+		t.Fatalf("Failed to reset schema version: %v", err)
+	}
+
+	if err := storage.runMigrations(ctx); err != nil {
+		t.Fatalf("Failed to run migrations: %v", err)
+	}
+
+	replies, err := storage.QueryNotes(ctx, nostr.Filter{}, true)
+	if err != nil {
+		t.Fatalf("QueryNotes failed: %v", err)
+	}
+	if len(replies) != 1 || replies[0].ID != event.ID {
+		t.Errorf("Expected the rebuilt index to list the reply, got %d", len(replies))
+	}
+}
+
+func countThreadRows(t *testing.T, storage *Storage) int {
+	t.Helper()
+
+	var count int
+	if err := storage.DB().QueryRow("SELECT COUNT(*) FROM event_threads").Scan(&count); err != nil {
+		t.Fatalf("Failed to count thread index rows: %v", err)
+	}
+	return count
+}
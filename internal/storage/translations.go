@@ -0,0 +1,36 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// GetTranslation returns an event's stored translation into lang, and false
+// if it hasn't been translated into that language yet
+func (s *Storage) GetTranslation(ctx context.Context, eventID, lang string) (string, bool, error) {
+	var content string
+	err := s.db.QueryRowContext(ctx,
+		"SELECT content FROM translations WHERE event_id = ? AND lang = ?",
+		eventID, lang).Scan(&content)
+	if errors.Is(err, sql.ErrNoRows) {
+		return "", false, nil
+	}
+	if err != nil {
+		return "", false, fmt.Errorf("failed to get translation: %w", err)
+	}
+	return content, true, nil
+}
+
+// SaveTranslation stores an event's translation into lang, replacing any earlier one
+func (s *Storage) SaveTranslation(ctx context.Context, eventID, lang, content string) error {
+	if _, err := s.db.ExecContext(ctx, `
+		INSERT OR REPLACE INTO translations (event_id, lang, content, translated_at)
+		VALUES (?, ?, ?, ?)`,
+		eventID, lang, content, time.Now().Unix()); err != nil {
+		return fmt.Errorf("failed to save translation: %w", err)
+	}
+	return nil
+}
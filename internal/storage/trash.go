@@ -0,0 +1,202 @@
+package storage
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/nbd-wtf/go-nostr"
+)
+
+// Reasons recorded when events are moved to the trash
+const (
+	TrashReasonRetention = "retention"
+	TrashReasonDeletion  = "deletion" // NIP-09 kind 5 request
+)
+
+// TrashedEvent is a soft-deleted event waiting to be purged or restored
+type TrashedEvent struct {
+	Event      *nostr.Event
+	Reason     string
+	DeletedAt  time.Time
+	PurgeAfter time.Time
+}
+
+// trashWhere moves events matching the clause from the event table to the trash.
+// Protected events are never trashed.
+func (s *Storage) trashWhere(ctx context.Context, reason string, purgeAfter time.Time, clause string, args ...interface{}) (int64, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	where := clause + " AND " + protectedEventsClause
+
+	insertArgs := append([]interface{}{reason, time.Now().Unix(), purgeAfter.Unix()}, args...)
+	if _, err := tx.ExecContext(ctx, `
+		INSERT OR REPLACE INTO trash (id, pubkey, created_at, kind, tags, content, sig, reason, deleted_at, purge_after)
+		SELECT id, pubkey, created_at, kind, tags, content, sig, ?, ?, ?
+		FROM event WHERE `+where, insertArgs...); err != nil {
+		return 0, fmt.Errorf("failed to copy events to trash: %w", err)
+	}
+
+	result, err := tx.ExecContext(ctx, "DELETE FROM event WHERE "+where, args...)
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete events: %w", err)
+	}
+
+	trashed, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if err := deleteOrphans(ctx, tx); err != nil {
+		return 0, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("failed to commit trash: %w", err)
+	}
+
+	return trashed, nil
+}
+
+// TrashEvent moves a single event to the trash until purgeAfter
+func (s *Storage) TrashEvent(ctx context.Context, eventID, reason string, purgeAfter time.Time) error {
+	_, err := s.trashWhere(ctx, reason, purgeAfter, "id = ?", eventID)
+	return err
+}
+
+// TrashEventsBefore moves events created before the given timestamp to the trash
+func (s *Storage) TrashEventsBefore(ctx context.Context, before, purgeAfter time.Time) (int64, error) {
+	return s.trashWhere(ctx, TrashReasonRetention, purgeAfter, "created_at < ?", before.Unix())
+}
+
+// TrashEventsByKind moves all events of a specific kind to the trash
+func (s *Storage) TrashEventsByKind(ctx context.Context, kind int, purgeAfter time.Time) (int64, error) {
+	return s.trashWhere(ctx, TrashReasonRetention, purgeAfter, "kind = ?", kind)
+}
+
+// RestoreEvent moves an event from the trash back into the event table
+// Returns false if the event is not in the trash
+func (s *Storage) RestoreEvent(ctx context.Context, eventID string) (bool, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return false, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, `
+		INSERT OR IGNORE INTO event (id, pubkey, created_at, kind, tags, content, sig)
+		SELECT id, pubkey, created_at, kind, tags, content, sig
+		FROM trash WHERE id = ?`, eventID); err != nil {
+		return false, fmt.Errorf("failed to restore event: %w", err)
+	}
+
+	// Restores bypass the relay's StoreEvent handlers, so index the event here
+	if err := restoreThreadFlag(ctx, tx, eventID); err != nil {
+		return false, err
+	}
+	if err := s.restoreAnnotations(ctx, tx, eventID); err != nil {
+		return false, err
+	}
+
+	result, err := tx.ExecContext(ctx, "DELETE FROM trash WHERE id = ?", eventID)
+	if err != nil {
+		return false, fmt.Errorf("failed to remove event from trash: %w", err)
+	}
+
+	restored, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return false, fmt.Errorf("failed to commit restore: %w", err)
+	}
+
+	return restored > 0, nil
+}
+
+// ListTrash returns soft-deleted events, most recently deleted first
+func (s *Storage) ListTrash(ctx context.Context, limit, offset int) ([]*TrashedEvent, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT id, pubkey, created_at, kind, tags, content, sig, reason, deleted_at, purge_after
+		FROM trash
+		ORDER BY deleted_at DESC, id
+		LIMIT ? OFFSET ?`, limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query trash: %w", err)
+	}
+	defer rows.Close()
+
+	var results []*TrashedEvent
+	for rows.Next() {
+		var event nostr.Event
+		var tags string
+		var createdAt, deletedAt, purgeAfter int64
+		trashed := &TrashedEvent{Event: &event}
+
+		err := rows.Scan(
+			&event.ID,
+			&event.PubKey,
+			&createdAt,
+			&event.Kind,
+			&tags,
+			&event.Content,
+			&event.Sig,
+			&trashed.Reason,
+			&deletedAt,
+			&purgeAfter,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan trashed event: %w", err)
+		}
+
+		if err := json.Unmarshal([]byte(tags), &event.Tags); err != nil {
+			return nil, fmt.Errorf("failed to decode tags of %s: %w", event.ID, err)
+		}
+		event.CreatedAt = nostr.Timestamp(createdAt)
+		trashed.DeletedAt = time.Unix(deletedAt, 0)
+		trashed.PurgeAfter = time.Unix(purgeAfter, 0)
+
+		results = append(results, trashed)
+	}
+
+	return results, rows.Err()
+}
+
+// CountTrash returns the number of soft-deleted events
+func (s *Storage) CountTrash(ctx context.Context) (int64, error) {
+	var count int64
+	if err := s.db.QueryRowContext(ctx, "SELECT COUNT(*) FROM trash").Scan(&count); err != nil {
+		return 0, fmt.Errorf("failed to count trash: %w", err)
+	}
+	return count, nil
+}
+
+// PurgeTrash permanently removes trashed events whose grace period ended before now
+func (s *Storage) PurgeTrash(ctx context.Context, now time.Time) (int64, error) {
+	result, err := s.db.ExecContext(ctx, "DELETE FROM trash WHERE purge_after <= ?", now.Unix())
+	if err != nil {
+		return 0, fmt.Errorf("failed to purge trash: %w", err)
+	}
+
+	purged, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	return purged, nil
+}
+
+// IsEventTrashed returns true if the event is waiting in the trash
+func (s *Storage) IsEventTrashed(ctx context.Context, eventID string) (bool, error) {
+	var count int
+	if err := s.db.QueryRowContext(ctx, "SELECT COUNT(*) FROM trash WHERE id = ?", eventID).Scan(&count); err != nil {
+		return false, fmt.Errorf("failed to check trash: %w", err)
+	}
+	return count > 0, nil
+}
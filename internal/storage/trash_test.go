@@ -0,0 +1,96 @@
+package storage
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/nbd-wtf/go-nostr"
+)
+
+func TestTrashRestoreAndPurge(t *testing.T) {
+	storage, cleanup := setupTestStorage(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	sk := nostr.GeneratePrivateKey()
+	pk, _ := nostr.GetPublicKey(sk)
+
+	var events []*nostr.Event
+	for i, content := range []string{"old note", "new note"} {
+		event := &nostr.Event{
+			PubKey:    pk,
+			CreatedAt: nostr.Timestamp(1000 + i*1000),
+			Kind:      1,
+			Tags:      nostr.Tags{{"t", "test"}},
+			Content:   content,
+		}
+		event.Sign(sk)
+		if err := storage.StoreEvent(ctx, event); err != nil {
+			t.Fatalf("Failed to store event: %v", err)
+		}
+		events = append(events, event)
+	}
+
+	now := time.Now()
+	trashed, err := storage.TrashEventsBefore(ctx, time.Unix(1500, 0), now.Add(time.Hour))
+	if err != nil {
+		t.Fatalf("TrashEventsBefore failed: %v", err)
+	}
+	if trashed != 1 {
+		t.Fatalf("Expected 1 trashed event, got %d", trashed)
+	}
+
+	if exists, _ := storage.EventExists(ctx, events[0].ID); exists {
+		t.Error("Trashed event should no longer be stored")
+	}
+	if ok, err := storage.IsEventTrashed(ctx, events[0].ID); err != nil || !ok {
+		t.Errorf("Expected event to be in trash, got %v (err: %v)", ok, err)
+	}
+
+	list, err := storage.ListTrash(ctx, 10, 0)
+	if err != nil {
+		t.Fatalf("ListTrash failed: %v", err)
+	}
+	if len(list) != 1 || list[0].Event.Content != "old note" || list[0].Reason != TrashReasonRetention {
+		t.Fatalf("Unexpected trash listing: %+v", list)
+	}
+	if len(list[0].Event.Tags) != 1 || list[0].Event.Tags[0][1] != "test" {
+		t.Errorf("Trashed event should keep its tags, got %v", list[0].Event.Tags)
+	}
+
+	// Restore brings the event back unchanged
+	restored, err := storage.RestoreEvent(ctx, events[0].ID)
+	if err != nil || !restored {
+		t.Fatalf("RestoreEvent failed: %v (restored: %v)", err, restored)
+	}
+	results, err := storage.QueryEvents(ctx, nostr.Filter{IDs: []string{events[0].ID}})
+	if err != nil || len(results) != 1 {
+		t.Fatalf("Restored event should be queryable: %v", err)
+	}
+	if ok, _ := results[0].CheckSignature(); !ok {
+		t.Error("Restored event should keep a valid signature")
+	}
+	if restored, _ := storage.RestoreEvent(ctx, events[0].ID); restored {
+		t.Error("Restoring an event that is not in the trash should report false")
+	}
+
+	// Purge only removes events whose grace period has ended
+	if err := storage.TrashEvent(ctx, events[1].ID, TrashReasonDeletion, now.Add(-time.Minute)); err != nil {
+		t.Fatalf("TrashEvent failed: %v", err)
+	}
+	if err := storage.TrashEvent(ctx, events[0].ID, TrashReasonDeletion, now.Add(time.Hour)); err != nil {
+		t.Fatalf("TrashEvent failed: %v", err)
+	}
+
+	purged, err := storage.PurgeTrash(ctx, now)
+	if err != nil {
+		t.Fatalf("PurgeTrash failed: %v", err)
+	}
+	if purged != 1 {
+		t.Errorf("Expected 1 purged event, got %d", purged)
+	}
+	if count, _ := storage.CountTrash(ctx); count != 1 {
+		t.Errorf("Expected 1 event left in trash, got %d", count)
+	}
+}
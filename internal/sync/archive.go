@@ -0,0 +1,199 @@
+package sync
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/nbd-wtf/go-nostr"
+)
+
+// Archive formats accepted by Import
+const (
+	FormatAuto        = "auto"         // JSON array or one event per line, detected
+	FormatJSONL       = "jsonl"        // One event per line
+	FormatNostrTools  = "nostr-tools"  // JSON array of events, as nostr-tools serializes them
+	FormatPrimal      = "primal"       // JSON array of events, mixed with Primal metadata entries
+	FormatNostrudel   = "nostrudel"    // JSON array or one event per line
+	FormatStrfryJSONL = "strfry-jsonl" // One event per line (strfry export)
+)
+
+// ArchiveFormats lists the supported archive formats
+var ArchiveFormats = []string{FormatAuto, FormatJSONL, FormatNostrTools, FormatPrimal, FormatNostrudel, FormatStrfryJSONL}
+
+// ExportFormats lists the formats ArchiveWriter writes
+var ExportFormats = []string{FormatJSONL, FormatNostrTools}
+
+// primalMetaKindFloor is where Primal's own non-Nostr kinds (stats, metadata) start
+const primalMetaKindFloor = 10000000
+
+// maxArchiveLine bounds a single JSON line; long-form articles can be large
+const maxArchiveLine = 16 * 1024 * 1024
+
+// readArchive decodes events from an export and calls fn for each one.
+// Entries that are not events (e.g. Primal metadata) are skipped and counted.
+func readArchive(r io.Reader, format string, fn func(*nostr.Event) error) (skipped int, err error) {
+	br := bufio.NewReader(r)
+
+	switch format {
+	case FormatPrimal, FormatNostrTools:
+		return readJSONArray(br, fn)
+
+	case FormatAuto, FormatNostrudel:
+		if startsWithArray(br) {
+			return readJSONArray(br, fn)
+		}
+		return readJSONLines(br, fn)
+
+	case FormatJSONL, FormatStrfryJSONL:
+		return readJSONLines(br, fn)
+
+	default:
+		return 0, fmt.Errorf("unknown archive format %q (expected one of: %s)", format, strings.Join(ArchiveFormats, ", "))
+	}
+}
+
+// startsWithArray peeks past leading whitespace for a '['
+func startsWithArray(br *bufio.Reader) bool {
+	for {
+		b, err := br.Peek(1)
+		if err != nil {
+			return false
+		}
+		switch b[0] {
+		case ' ', '\t', '\r', '\n':
+			br.ReadByte()
+		default:
+			return b[0] == '['
+		}
+	}
+}
+
+// readJSONArray streams the elements of a top-level JSON array
+func readJSONArray(r io.Reader, fn func(*nostr.Event) error) (int, error) {
+	dec := json.NewDecoder(r)
+
+	tok, err := dec.Token()
+	if err != nil {
+		return 0, fmt.Errorf("failed to read archive: %w", err)
+	}
+	if delim, ok := tok.(json.Delim); !ok || delim != '[' {
+		return 0, fmt.Errorf("archive is not a JSON array")
+	}
+
+	skipped := 0
+	for dec.More() {
+		var raw json.RawMessage
+		if err := dec.Decode(&raw); err != nil {
+			return skipped, fmt.Errorf("failed to read archive: %w", err)
+		}
+
+		event, ok := decodeArchiveEvent(raw)
+		if !ok {
+			skipped++
+			continue
+		}
+		if err := fn(event); err != nil {
+			return skipped, err
+		}
+	}
+
+	return skipped, nil
+}
+
+// readJSONLines reads one JSON event per line, skipping blank lines
+func readJSONLines(r io.Reader, fn func(*nostr.Event) error) (int, error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), maxArchiveLine)
+
+	skipped := 0
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+
+		event, ok := decodeArchiveEvent([]byte(line))
+		if !ok {
+			skipped++
+			continue
+		}
+		if err := fn(event); err != nil {
+			return skipped, err
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return skipped, fmt.Errorf("failed to read archive: %w", err)
+	}
+	return skipped, nil
+}
+
+// decodeArchiveEvent decodes a single entry, returning false for anything that is not a Nostr event
+func decodeArchiveEvent(raw []byte) (*nostr.Event, bool) {
+	var event nostr.Event
+	if err := json.Unmarshal(raw, &event); err != nil {
+		return nil, false
+	}
+	if event.ID == "" || event.PubKey == "" || event.Sig == "" || event.Kind >= primalMetaKindFloor {
+		return nil, false
+	}
+	return &event, true
+}
+
+// ArchiveWriter streams events in one of ExportFormats
+type ArchiveWriter struct {
+	w      *bufio.Writer
+	format string
+	count  int
+}
+
+// NewArchiveWriter starts an archive in format on w
+func NewArchiveWriter(w io.Writer, format string) (*ArchiveWriter, error) {
+	switch format {
+	case FormatJSONL, FormatNostrTools:
+	default:
+		return nil, fmt.Errorf("unknown export format %q (expected one of: %s)", format, strings.Join(ExportFormats, ", "))
+	}
+	return &ArchiveWriter{w: bufio.NewWriter(w), format: format}, nil
+}
+
+// Write appends an event to the archive
+func (a *ArchiveWriter) Write(event *nostr.Event) error {
+	data, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("failed to encode event %s: %w", event.ID, err)
+	}
+
+	if a.format == FormatNostrTools {
+		sep := ",\n"
+		if a.count == 0 {
+			sep = "[\n"
+		}
+		a.w.WriteString(sep)
+		a.w.Write(data)
+	} else {
+		a.w.Write(data)
+		a.w.WriteByte('\n')
+	}
+	a.count++
+	return nil
+}
+
+// Count returns how many events were written
+func (a *ArchiveWriter) Count() int {
+	return a.count
+}
+
+// Close ends the archive and flushes it. It doesn't close the underlying writer.
+func (a *ArchiveWriter) Close() error {
+	if a.format == FormatNostrTools {
+		if a.count == 0 {
+			a.w.WriteString("[")
+		}
+		a.w.WriteString("\n]\n")
+	}
+	return a.w.Flush()
+}
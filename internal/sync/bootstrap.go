@@ -0,0 +1,232 @@
+package sync
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/nbd-wtf/go-nostr"
+	"github.com/sandwich/nophr/internal/storage"
+)
+
+// Bootstrap phases, stored as the last one completed. A restart resumes
+// after it, and a completed bootstrap starts over to pick up changes.
+const (
+	phaseSeeds    = "seeds"    // Owner's profile, contacts and relay list fetched from seeds
+	phaseContacts = "contacts" // Owner's contact list applied to the graph
+	phaseHints    = "hints"    // Relay hints discovered for every author in scope
+)
+
+// bootstrapResumeWindow is how long an interrupted bootstrap may be resumed.
+// Older progress is stale and the bootstrap starts over.
+const bootstrapResumeWindow = 24 * time.Hour
+
+// hintBatchSize is how many authors' relay lists are requested at a time.
+// Progress is saved after each batch.
+const hintBatchSize = 200
+
+// Delays between bootstrap attempts in the background
+const (
+	bootstrapRetryMin = time.Minute
+	bootstrapRetryMax = 30 * time.Minute
+)
+
+// DescribeBootstrap summarizes bootstrap progress, as saved in storage
+func DescribeBootstrap(state *storage.BootstrapState) string {
+	switch {
+	case state == nil:
+		return "not started"
+	case state.Phase == phaseHints:
+		return "complete"
+	case state.Phase == "":
+		return "started"
+	default:
+		return fmt.Sprintf("incomplete, %s phase done", state.Phase)
+	}
+}
+
+// bootstrapWithRetry runs bootstrap until it succeeds, backing off between
+// attempts. It returns false if the engine stopped first.
+func (e *Engine) bootstrapWithRetry() bool {
+	delay := bootstrapRetryMin
+	for {
+		err := e.bootstrap()
+		if err == nil {
+			return true
+		}
+		if e.ctx.Err() != nil {
+			return false
+		}
+
+		fmt.Printf("[SYNC] ⚠ Bootstrap failed, retrying in %s: %v\n", delay, err)
+		select {
+		case <-e.ctx.Done():
+			return false
+		case <-time.After(delay):
+		}
+		delay = min(delay*2, bootstrapRetryMax)
+	}
+}
+
+// bootstrap performs initial discovery and graph building, saving progress
+// after each phase and each batch of relay hints
+func (e *Engine) bootstrap() error {
+	fmt.Printf("[SYNC] Starting bootstrap process...\n")
+	ownerPubkey, err := e.getOwnerPubkey()
+	if err != nil {
+		return err
+	}
+	fmt.Printf("[SYNC] Owner pubkey (hex): %s\n", ownerPubkey)
+
+	state := e.loadBootstrapState(ownerPubkey)
+	seedRelays := e.nostrClient.GetSeedRelays()
+
+	// Step 1: Fetch owner's profile, contacts, and relay hints from seeds
+	if state.Phase == "" {
+		fmt.Printf("[SYNC] Step 1: Bootstrapping from seed relays...\n")
+		if err := e.discovery.BootstrapFromSeeds(e.ctx, ownerPubkey); err != nil {
+			return fmt.Errorf("failed to bootstrap from seeds: %w", err)
+		}
+		fmt.Printf("[SYNC] ✓ Bootstrap from seeds complete\n")
+		if err := e.saveBootstrapPhase(state, phaseSeeds); err != nil {
+			return err
+		}
+	}
+
+	// Step 2: Fetch owner's contact list (kind 3) to build initial graph
+	if state.Phase == phaseSeeds {
+		if err := e.bootstrapContacts(ownerPubkey, seedRelays); err != nil {
+			return err
+		}
+		if err := e.saveBootstrapPhase(state, phaseContacts); err != nil {
+			return err
+		}
+	}
+
+	// Steps 3 and 4: Discover relay hints for all authors in scope
+	if state.Phase == phaseContacts {
+		if err := e.bootstrapHints(state, ownerPubkey, seedRelays); err != nil {
+			return err
+		}
+		if err := e.saveBootstrapPhase(state, phaseHints); err != nil {
+			return err
+		}
+	}
+
+	fmt.Printf("[SYNC] ✓ Bootstrap complete!\n\n")
+	return nil
+}
+
+// loadBootstrapState returns the state to resume from, or a fresh one when the
+// last bootstrap completed, is too old to resume or can't be read
+func (e *Engine) loadBootstrapState(ownerPubkey string) *storage.BootstrapState {
+	now := time.Now()
+	fresh := &storage.BootstrapState{Owner: ownerPubkey, StartedAt: now.Unix(), UpdatedAt: now.Unix()}
+
+	state, err := e.storage.GetBootstrapState(e.ctx, ownerPubkey)
+	if err != nil {
+		fmt.Printf("[SYNC] ⚠ Failed to read bootstrap progress, starting over: %v\n", err)
+		return fresh
+	}
+	if state == nil || state.Phase == phaseHints || now.Sub(time.Unix(state.StartedAt, 0)) > bootstrapResumeWindow {
+		return fresh
+	}
+
+	fmt.Printf("[SYNC] Resuming bootstrap after the %s phase\n", state.Phase)
+	return state
+}
+
+// saveBootstrapPhase records that a phase completed
+func (e *Engine) saveBootstrapPhase(state *storage.BootstrapState, phase string) error {
+	state.Phase = phase
+	state.UpdatedAt = time.Now().Unix()
+	if err := e.storage.SaveBootstrapState(e.ctx, state); err != nil {
+		return fmt.Errorf("failed to save bootstrap progress: %w", err)
+	}
+	return nil
+}
+
+// bootstrapContacts applies the owner's newest contact list from the seeds
+func (e *Engine) bootstrapContacts(ownerPubkey string, seedRelays []string) error {
+	fmt.Printf("[SYNC] Step 2: Fetching contact list from %d seed relays\n", len(seedRelays))
+	for i, relay := range seedRelays {
+		fmt.Printf("[SYNC]   Seed relay %d: %s\n", i+1, relay)
+	}
+
+	filter := nostr.Filter{
+		Kinds:   []int{3},
+		Authors: []string{ownerPubkey},
+		Limit:   1,
+	}
+
+	events, err := e.nostrClient.FetchEvents(e.ctx, seedRelays, filter)
+	if err != nil {
+		return fmt.Errorf("failed to fetch contact list: %w", err)
+	}
+	fmt.Printf("[SYNC] Fetched %d contact list events\n", len(events))
+
+	if len(events) == 0 {
+		fmt.Printf("[SYNC] ⚠ No contact list found - will sync owner events only\n")
+		return nil
+	}
+
+	// Apply changes since the last run; on first run every follow is new and the
+	// regular sync fetches full history, so only backfill when a graph already exists
+	fmt.Printf("[SYNC] Processing contact list (event ID: %s)\n", events[0].ID)
+	previous, err := e.storage.GetFollowingPubkeys(e.ctx, ownerPubkey)
+	if err != nil {
+		return fmt.Errorf("failed to get current follows: %w", err)
+	}
+	if _, err := e.applyOwnerContactList(events[0], len(previous) > 0); err != nil {
+		return fmt.Errorf("failed to process contact list: %w", err)
+	}
+	fmt.Printf("[SYNC] ✓ Contact list processed\n")
+	return nil
+}
+
+// bootstrapHints discovers relay hints for the authors in scope in batches,
+// skipping authors a previous attempt already covered
+func (e *Engine) bootstrapHints(state *storage.BootstrapState, ownerPubkey string, seedRelays []string) error {
+	fmt.Printf("[SYNC] Step 3: Getting authors in scope...\n")
+	authors, err := e.graph.GetAuthorsInScope(e.ctx, ownerPubkey)
+	if err != nil {
+		return fmt.Errorf("failed to get authors in scope: %w", err)
+	}
+	fmt.Printf("[SYNC] Authors in scope: %d\n", len(authors))
+
+	// Sorted so the saved position means the same thing after a restart
+	sort.Strings(authors)
+	pending := authors[sort.SearchStrings(authors, state.HintsAfter):]
+	if len(pending) > 0 && pending[0] == state.HintsAfter {
+		pending = pending[1:]
+	}
+	if done := len(authors) - len(pending); done > 0 {
+		fmt.Printf("[SYNC] Relay hints already discovered for %d authors\n", done)
+	}
+
+	fmt.Printf("[SYNC] Step 4: Discovering relay hints...\n")
+	// Get owner's outbox relays to search for authors' relay hints
+	ownerRelays, err := e.discovery.GetOutboxRelays(e.ctx, ownerPubkey)
+	if err != nil || len(ownerRelays) == 0 {
+		ownerRelays = seedRelays // Fallback to seeds
+		fmt.Printf("[SYNC] Using seed relays as fallback (%d relays)\n", len(ownerRelays))
+	} else {
+		fmt.Printf("[SYNC] Using owner's outbox relays (%d relays)\n", len(ownerRelays))
+	}
+
+	for start := 0; start < len(pending); start += hintBatchSize {
+		batch := pending[start:min(start+hintBatchSize, len(pending))]
+		if err := e.discovery.DiscoverRelayHintsForPubkeys(e.ctx, batch, ownerRelays); err != nil {
+			return fmt.Errorf("failed to discover relay hints: %w", err)
+		}
+
+		state.HintsAfter = batch[len(batch)-1]
+		state.UpdatedAt = time.Now().Unix()
+		if err := e.storage.SaveBootstrapState(e.ctx, state); err != nil {
+			return fmt.Errorf("failed to save bootstrap progress: %w", err)
+		}
+		fmt.Printf("[SYNC]   Relay hints: %d/%d authors\n", len(authors)-len(pending)+start+len(batch), len(authors))
+	}
+	fmt.Printf("[SYNC] ✓ Relay hints discovered\n")
+	return nil
+}
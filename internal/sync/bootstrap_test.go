@@ -0,0 +1,62 @@
+package sync
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/sandwich/nophr/internal/config"
+	"github.com/sandwich/nophr/internal/storage"
+)
+
+func TestLoadBootstrapState(t *testing.T) {
+	ctx := context.Background()
+
+	st, err := storage.New(ctx, &config.Storage{
+		Driver:     "sqlite",
+		SQLitePath: filepath.Join(t.TempDir(), "test.db"),
+	})
+	if err != nil {
+		t.Fatalf("Failed to create storage: %v", err)
+	}
+	defer st.Close()
+
+	e := NewEngine(st, config.Default())
+	defer e.cancel()
+
+	if state := e.loadBootstrapState("owner"); state.Phase != "" || state.Owner != "owner" {
+		t.Errorf("Expected a fresh state before any bootstrap, got %+v", state)
+	}
+
+	now := time.Now().Unix()
+	tests := []struct {
+		name      string
+		saved     storage.BootstrapState
+		wantPhase string
+	}{
+		{"interrupted during hints", storage.BootstrapState{Phase: phaseContacts, HintsAfter: "abc", StartedAt: now}, phaseContacts},
+		{"interrupted after seeds", storage.BootstrapState{Phase: phaseSeeds, StartedAt: now}, phaseSeeds},
+		{"completed", storage.BootstrapState{Phase: phaseHints, StartedAt: now}, ""},
+		{"stale", storage.BootstrapState{Phase: phaseContacts, StartedAt: now - int64(2*bootstrapResumeWindow/time.Second)}, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.saved.Owner = "owner"
+			if err := st.SaveBootstrapState(ctx, &tt.saved); err != nil {
+				t.Fatalf("SaveBootstrapState failed: %v", err)
+			}
+
+			state := e.loadBootstrapState("owner")
+			if state.Phase != tt.wantPhase {
+				t.Errorf("Expected phase %q, got %q", tt.wantPhase, state.Phase)
+			}
+			if tt.wantPhase == "" && state.HintsAfter != "" {
+				t.Errorf("Expected a fresh state to start hints over, got %q", state.HintsAfter)
+			}
+			if tt.wantPhase == phaseContacts && state.HintsAfter != "abc" {
+				t.Errorf("Expected hints to resume after abc, got %q", state.HintsAfter)
+			}
+		})
+	}
+}
@@ -0,0 +1,247 @@
+package sync
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/nbd-wtf/go-nostr"
+	"github.com/sandwich/nophr/internal/storage"
+)
+
+// ContactDiff is the change between two versions of the owner's contact list
+type ContactDiff struct {
+	Added   []string
+	Removed []string
+}
+
+// Empty returns true if the contact list did not change
+func (d *ContactDiff) Empty() bool {
+	return len(d.Added) == 0 && len(d.Removed) == 0
+}
+
+// contactPubkeys extracts the unique followed pubkeys from a kind 3 event
+func contactPubkeys(event *nostr.Event) []string {
+	return storage.FollowedPubkeys(event)
+}
+
+// diffContacts compares the previous and current follow sets
+func diffContacts(previous, current []string) *ContactDiff {
+	before := make(map[string]bool, len(previous))
+	for _, pubkey := range previous {
+		before[pubkey] = true
+	}
+	after := make(map[string]bool, len(current))
+	for _, pubkey := range current {
+		after[pubkey] = true
+	}
+
+	diff := &ContactDiff{}
+	for pubkey := range after {
+		if !before[pubkey] {
+			diff.Added = append(diff.Added, pubkey)
+		}
+	}
+	for pubkey := range before {
+		if !after[pubkey] {
+			diff.Removed = append(diff.Removed, pubkey)
+		}
+	}
+
+	sort.Strings(diff.Added)
+	sort.Strings(diff.Removed)
+	return diff
+}
+
+// handleContactList applies a kind 3 event to the graph without reprocessing it from scratch.
+// The owner's list is diffed against the stored follows; other lists only touch the
+// author's own mutual flag and, in FOAF mode, their follows one level further out.
+func (e *Engine) handleContactList(event *nostr.Event) error {
+	ownerPubkey, err := e.getOwnerPubkey()
+	if err != nil {
+		return err
+	}
+
+	if event.PubKey == ownerPubkey {
+		_, err := e.applyOwnerContactList(event, !e.importing)
+		return err
+	}
+
+	// Lists from authors outside the graph don't affect it
+	node, err := e.storage.GetGraphNode(e.ctx, ownerPubkey, event.PubKey)
+	if err != nil {
+		return nil
+	}
+
+	if e.config.Sync.Scope.Mode == "foaf" && node.Depth < e.foafDepth() {
+		if err := e.graph.ProcessContactList(e.ctx, event, ownerPubkey); err != nil {
+			return fmt.Errorf("failed to process contact list: %w", err)
+		}
+	}
+
+	return e.graph.UpdateMutual(e.ctx, ownerPubkey, event.PubKey)
+}
+
+// applyOwnerContactList diffs the owner's contact list against the graph and applies the change.
+// Newly followed authors get relay discovery and a history backfill when onboard is set;
+// unfollowed authors are scheduled for pruning if configured.
+func (e *Engine) applyOwnerContactList(event *nostr.Event, onboard bool) (*ContactDiff, error) {
+	ownerPubkey := event.PubKey
+
+	// Ignore lists older than the one already stored
+	latest, err := e.storage.QueryEvents(e.ctx, nostr.Filter{
+		Kinds:   []int{3},
+		Authors: []string{ownerPubkey},
+		Limit:   1,
+	})
+	if err == nil && len(latest) > 0 && latest[0].CreatedAt > event.CreatedAt {
+		return &ContactDiff{}, nil
+	}
+
+	// Bootstrap applies a fetched list that may not be stored yet, and mutual
+	// flags are read from the follows index
+	if err := e.storage.IndexContactList(e.ctx, event); err != nil {
+		return nil, fmt.Errorf("failed to index contact list: %w", err)
+	}
+
+	previous, err := e.storage.GetFollowingPubkeys(e.ctx, ownerPubkey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get current follows: %w", err)
+	}
+
+	diff := diffContacts(previous, contactPubkeys(event))
+	if diff.Empty() {
+		return diff, nil
+	}
+
+	if err := e.graph.ApplyContactDiff(e.ctx, ownerPubkey, diff, int64(event.CreatedAt)); err != nil {
+		return nil, fmt.Errorf("failed to apply contact diff: %w", err)
+	}
+	fmt.Printf("[SYNC] Contact list changed: +%d follows, -%d follows\n", len(diff.Added), len(diff.Removed))
+
+	if onboard && len(diff.Added) > 0 {
+		e.goRelaySync(func() { e.onboardAuthors(ownerPubkey, diff.Added) })
+	}
+
+	if hours := e.config.Sync.Scope.PruneUnfollowedHours; hours > 0 && len(diff.Removed) > 0 {
+		e.schedulePrune(ownerPubkey, diff.Removed, time.Duration(hours)*time.Hour)
+	}
+
+	return diff, nil
+}
+
+// onboardAuthors discovers relay hints for newly followed authors and backfills their history.
+// Relay cursors are already past their old events, so a regular sync iteration would miss them.
+func (e *Engine) onboardAuthors(ownerPubkey string, added []string) {
+	inScope, err := e.graph.GetAuthorsInScope(e.ctx, ownerPubkey)
+	if err != nil {
+		fmt.Printf("[SYNC] ⚠ Failed to get authors in scope: %v\n", err)
+		return
+	}
+	scope := make(map[string]bool, len(inScope))
+	for _, pubkey := range inScope {
+		scope[pubkey] = true
+	}
+
+	authors := make([]string, 0, len(added))
+	for _, pubkey := range added {
+		if scope[pubkey] {
+			authors = append(authors, pubkey)
+		}
+	}
+	if len(authors) == 0 {
+		return
+	}
+
+	searchRelays, err := e.discovery.GetOutboxRelays(e.ctx, ownerPubkey)
+	if err != nil || len(searchRelays) == 0 {
+		searchRelays = e.nostrClient.GetSeedRelays()
+	}
+	if err := e.discovery.DiscoverRelayHintsForPubkeys(e.ctx, authors, searchRelays); err != nil {
+		fmt.Printf("[SYNC] ⚠ Relay discovery for new follows failed: %v\n", err)
+	}
+
+	fmt.Printf("[SYNC] Backfilling history for %d new follows\n", len(authors))
+	filters := e.filterBuilder.BuildFilters(authors, 0)
+	for _, relay := range e.getActiveRelays(authors) {
+		e.goRelaySync(func() { e.subscribeRelay(e.ctx, relay, filters) })
+	}
+}
+
+// pruneCheckInterval is how often pending prunes of unfollowed authors are
+// checked for ones that are due
+const pruneCheckInterval = 5 * time.Minute
+
+// schedulePrune records that events from unfollowed authors are deleted after
+// delay. Pending prunes are stored, so they still run after a restart.
+func (e *Engine) schedulePrune(ownerPubkey string, removed []string, delay time.Duration) {
+	fmt.Printf("[SYNC] Scheduling prune of %d unfollowed authors in %v\n", len(removed), delay)
+	if err := e.storage.SchedulePrunes(e.ctx, ownerPubkey, removed, time.Now().Add(delay).Unix()); err != nil {
+		fmt.Printf("[SYNC] ⚠ Failed to schedule prune of unfollowed authors: %v\n", err)
+	}
+}
+
+// pruneUnfollowed runs pending prunes as they fall due, starting with any
+// that came due while nophr was stopped
+func (e *Engine) pruneUnfollowed() {
+	defer e.wg.Done()
+
+	ticker := time.NewTicker(pruneCheckInterval)
+	defer ticker.Stop()
+
+	for {
+		e.runDuePrunes()
+		select {
+		case <-e.ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// runDuePrunes deletes events from the authors whose prune is due, unless
+// they are back in scope (re-followed, or still reachable through FOAF)
+func (e *Engine) runDuePrunes() {
+	due, err := e.storage.DuePrunes(e.ctx, time.Now().Unix())
+	if err != nil {
+		fmt.Printf("[SYNC] ⚠ Failed to read pending prunes: %v\n", err)
+		return
+	}
+
+	scopes := make(map[string]map[string]bool)
+	for _, prune := range due {
+		scope, ok := scopes[prune.RootPubkey]
+		if !ok {
+			inScope, err := e.graph.GetAuthorsInScope(e.ctx, prune.RootPubkey)
+			if err != nil {
+				fmt.Printf("[SYNC] ⚠ Prune of unfollowed authors skipped: %v\n", err)
+				return
+			}
+			scope = make(map[string]bool, len(inScope))
+			for _, pubkey := range inScope {
+				scope[pubkey] = true
+			}
+			scopes[prune.RootPubkey] = scope
+		}
+
+		if !scope[prune.Pubkey] {
+			deleted, err := e.storage.DeleteEventsByAuthor(e.ctx, prune.Pubkey)
+			if err != nil {
+				fmt.Printf("[SYNC] ⚠ Failed to prune events from %s: %v\n", prune.Pubkey[:16]+"...", err)
+				continue
+			}
+			fmt.Printf("[SYNC] Pruned %d events from unfollowed author %s\n", deleted, prune.Pubkey[:16]+"...")
+		}
+		if err := e.storage.DeletePendingPrune(e.ctx, prune.RootPubkey, prune.Pubkey); err != nil {
+			fmt.Printf("[SYNC] ⚠ %v\n", err)
+		}
+	}
+}
+
+// foafDepth returns the configured FOAF depth
+func (e *Engine) foafDepth() int {
+	if e.config.Sync.Scope.Depth > 0 {
+		return e.config.Sync.Scope.Depth
+	}
+	return 2 // Default FOAF depth, matches GetAuthorsInScope
+}
@@ -0,0 +1,117 @@
+package sync
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/nbd-wtf/go-nostr"
+)
+
+func TestDiffContacts(t *testing.T) {
+	diff := diffContacts([]string{"a", "b", "c"}, []string{"b", "c", "d", "e"})
+
+	if strings.Join(diff.Added, ",") != "d,e" {
+		t.Errorf("Expected added d,e, got %v", diff.Added)
+	}
+	if strings.Join(diff.Removed, ",") != "a" {
+		t.Errorf("Expected removed a, got %v", diff.Removed)
+	}
+
+	if !diffContacts([]string{"a"}, []string{"a"}).Empty() {
+		t.Error("Expected no diff for identical lists")
+	}
+}
+
+func TestContactPubkeys(t *testing.T) {
+	alice := strings.Repeat("a", 64)
+	bob := strings.Repeat("b", 64)
+
+	event := &nostr.Event{
+		Kind: 3,
+		Tags: nostr.Tags{
+			{"p", alice},
+			{"p", bob},
+			{"p", alice},       // Duplicate
+			{"p", "malformed"}, // Not a pubkey
+			{"e", strings.Repeat("c", 64)},
+		},
+	}
+
+	pubkeys := contactPubkeys(event)
+	if len(pubkeys) != 2 || pubkeys[0] != alice || pubkeys[1] != bob {
+		t.Errorf("Expected [alice bob], got %v", pubkeys)
+	}
+}
+
+func TestApplyContactDiff(t *testing.T) {
+	graph, st, cleanup := setupTestGraph(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	rootPubkey := "root-pubkey"
+
+	if err := graph.ApplyContactDiff(ctx, rootPubkey, &ContactDiff{Added: []string{"a", "b"}}, 100); err != nil {
+		t.Fatalf("ApplyContactDiff failed: %v", err)
+	}
+	if err := graph.ApplyContactDiff(ctx, rootPubkey, &ContactDiff{Added: []string{"c"}, Removed: []string{"a"}}, 200); err != nil {
+		t.Fatalf("ApplyContactDiff failed: %v", err)
+	}
+
+	following, err := st.GetFollowingPubkeys(ctx, rootPubkey)
+	if err != nil {
+		t.Fatalf("GetFollowingPubkeys failed: %v", err)
+	}
+	if strings.Join(following, ",") != "b,c" {
+		t.Errorf("Expected following b,c, got %v", following)
+	}
+}
+
+func TestRunDuePrunes(t *testing.T) {
+	graph, st, cleanup := setupTestGraph(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	rootPubkey := "root-pubkey"
+	unfollowedKey, refollowedKey := nostr.GeneratePrivateKey(), nostr.GeneratePrivateKey()
+	unfollowed, _ := nostr.GetPublicKey(unfollowedKey)
+	refollowed, _ := nostr.GetPublicKey(refollowedKey)
+	later := strings.Repeat("c", 64)
+
+	for _, event := range []*nostr.Event{signedNote(t, unfollowedKey, "gone"), signedNote(t, refollowedKey, "kept")} {
+		if err := st.StoreEvent(ctx, event); err != nil {
+			t.Fatalf("StoreEvent failed: %v", err)
+		}
+	}
+	if err := graph.ApplyContactDiff(ctx, rootPubkey, &ContactDiff{Added: []string{refollowed}}, 100); err != nil {
+		t.Fatalf("ApplyContactDiff failed: %v", err)
+	}
+
+	// Prunes are stored, so an engine started later runs them
+	now := time.Now().Unix()
+	if err := st.SchedulePrunes(ctx, rootPubkey, []string{unfollowed, refollowed}, now-60); err != nil {
+		t.Fatalf("SchedulePrunes failed: %v", err)
+	}
+	if err := st.SchedulePrunes(ctx, rootPubkey, []string{later}, now+3600); err != nil {
+		t.Fatalf("SchedulePrunes failed: %v", err)
+	}
+
+	engine := &Engine{ctx: ctx, storage: st, graph: graph}
+	engine.runDuePrunes()
+
+	if count, _ := st.CountEventsByAuthor(ctx, unfollowed); count != 0 {
+		t.Errorf("Expected the unfollowed author's events to be pruned, %d left", count)
+	}
+	if count, _ := st.CountEventsByAuthor(ctx, refollowed); count != 1 {
+		t.Errorf("Expected the re-followed author's events to be kept, got %d", count)
+	}
+
+	due, err := st.DuePrunes(ctx, now+7200)
+	if err != nil {
+		t.Fatalf("DuePrunes failed: %v", err)
+	}
+	if len(due) != 1 || due[0].Pubkey != later {
+		t.Errorf("Expected only the prune not yet due to remain, got %+v", due)
+	}
+}
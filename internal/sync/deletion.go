@@ -0,0 +1,56 @@
+package sync
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/nbd-wtf/go-nostr"
+	"github.com/sandwich/nophr/internal/storage"
+)
+
+// deletionTargets returns the event IDs a kind 5 deletion request references
+func deletionTargets(event *nostr.Event) []string {
+	ids := make([]string, 0, len(event.Tags))
+	for _, tag := range event.Tags {
+		if len(tag) >= 2 && tag[0] == "e" && len(tag[1]) == 64 {
+			ids = append(ids, tag[1])
+		}
+	}
+	return ids
+}
+
+// handleDeletion applies a NIP-09 deletion request to stored events by the same author.
+// With sync.retention.trash_grace_days set, the events go to the trash and stay
+// restorable until the prune worker purges them.
+func (e *Engine) handleDeletion(event *nostr.Event) error {
+	ids := deletionTargets(event)
+	if len(ids) == 0 {
+		return nil
+	}
+
+	targets, err := e.storage.QueryEvents(e.ctx, nostr.Filter{IDs: ids})
+	if err != nil {
+		return fmt.Errorf("failed to query deletion targets: %w", err)
+	}
+
+	grace := time.Duration(e.config.Sync.Retention.TrashGraceDays) * 24 * time.Hour
+	for _, target := range targets {
+		// Only the author may delete their own events
+		if target.PubKey != event.PubKey {
+			continue
+		}
+
+		if grace > 0 {
+			err = e.storage.TrashEvent(e.ctx, target.ID, storage.TrashReasonDeletion, time.Now().Add(grace))
+		} else {
+			err = e.storage.DeleteEvent(e.ctx, target.ID)
+		}
+		if err != nil {
+			return fmt.Errorf("failed to delete %s: %w", target.ID, err)
+		}
+
+		fmt.Printf("[SYNC]   ✗ Deleted event %s (kind 5 request)\n", target.ID[:16]+"...")
+	}
+
+	return nil
+}
@@ -2,14 +2,17 @@ package sync
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/nbd-wtf/go-nostr"
 	"github.com/nbd-wtf/go-nostr/nip19"
 	"github.com/sandwich/nophr/internal/config"
 	internalnostr "github.com/sandwich/nophr/internal/nostr"
+	"github.com/sandwich/nophr/internal/security"
 	"github.com/sandwich/nophr/internal/storage"
 )
 
@@ -27,23 +30,65 @@ type Engine struct {
 	cancel context.CancelFunc
 	wg     sync.WaitGroup
 
-	// Channels for coordination
-	eventChan chan *nostr.Event
+	// Relay syncs and backfills in flight, waited on by RunOnce
+	relaySyncs sync.WaitGroup
+
+	// Recurring relay syncs running or waiting for a subscription slot, by key
+	pendingSyncs sync.Map
+
+	// Events from relay subscriptions waiting for the workers
+	queue   *ingestQueue
+	workers *sync.WaitGroup
 
 	// Performance optimizations (Balanced Plan - Tier 1)
 	eventCache *EventCache // LRU cache for fast deduplication
 
 	// Performance optimizations (Balanced Plan - Tier 2)
-	aggregateChan chan *AggregateUpdate // Async aggregate processing
+	aggregateChan   chan *AggregateUpdate // Async aggregate processing
+	aggregateMu     sync.RWMutex          // Held to send on aggregateChan, or to close it
+	aggregateClosed bool
 
 	// Phase 20: Optional retention evaluation callback
 	evaluateRetention func(context.Context, *nostr.Event) error
+
+	// Optional response cache invalidation callback, run for each stored event
+	invalidateCache func(context.Context, *nostr.Event) error
+
+	// Per-relay activity for diagnostics
+	relayStats *RelayTracker
+
+	// Rejects or truncates oversized events before they are stored (sync.limits)
+	limiter *IngestLimiter
+
+	// When authors' NIP-05 relays were last looked up, by periodicRefresh
+	nip05CheckedAt time.Time
+
+	// Set while no requests arrive (idle.*): sync intervals are stretched and
+	// relay connections left open between syncs are closed. idleChanged wakes
+	// the sync loop to apply a new interval.
+	idle        atomic.Bool
+	idleChanged chan struct{}
+
+	// Set while watchInbox keeps mention subscriptions open on the inbox
+	// relays, so sync iterations leave the inbox to it
+	inboxWatched atomic.Bool
+
+	// Replies in the owner's threads whose missing root and parent
+	// completeThreads fetches (sync.scope.include_threads_of_mine), nil when
+	// it isn't running, and the event IDs it has already looked for
+	threadQueue   chan *nostr.Event
+	threadLookups *EventCache
+
+	// Set while Import or RunOnce runs: aggregate updates wait for room instead of
+	// being dropped and new follows seen by the workers are not backfilled from relays
+	importing bool
 }
 
 // AggregateUpdate represents a pending aggregate update
 type AggregateUpdate struct {
 	Type          string // "reply", "reaction", "zap"
 	EventID       string
+	SourceID      string // Interaction event that produced the update
 	Reaction      string // For reactions
 	Sats          int64  // For zaps
 	InteractionAt int64
@@ -54,6 +99,7 @@ func New(ctx context.Context, cfg *config.Config, st *storage.Storage, client *i
 	engineCtx, cancel := context.WithCancel(ctx)
 
 	discovery := internalnostr.NewDiscovery(client, st)
+	discovery.SetSelection(&cfg.Discovery)
 	filterBuilder := NewFilterBuilder(&cfg.Sync)
 	graph := NewGraph(st, &cfg.Sync.Scope)
 	cursors := NewCursorManager(st)
@@ -68,9 +114,12 @@ func New(ctx context.Context, cfg *config.Config, st *storage.Storage, client *i
 		cursors:       cursors,
 		ctx:           engineCtx,
 		cancel:        cancel,
-		eventChan:     make(chan *nostr.Event, 5000), // Tier 2: Larger buffer for burst handling
-		eventCache:    NewEventCache(5000),        // Tier 1: Cache last 5000 event IDs
+		queue:         newIngestQueue(cfg.Sync.Performance.RelayQueueSize),
+		eventCache:    NewEventCache(5000),               // Tier 1: Cache last 5000 event IDs
 		aggregateChan: make(chan *AggregateUpdate, 1000), // Tier 2: Async aggregate queue
+		relayStats:    NewRelayTracker(),
+		limiter:       NewIngestLimiter(&cfg.Sync.Limits, ownerHex(cfg)),
+		idleChanged:   make(chan struct{}, 1),
 	}
 }
 
@@ -83,6 +132,7 @@ func NewEngine(st *storage.Storage, cfg *config.Config) *Engine {
 	nostrClient := internalnostr.New(ctx, &cfg.Relays)
 
 	discovery := internalnostr.NewDiscovery(nostrClient, st)
+	discovery.SetSelection(&cfg.Discovery)
 	filterBuilder := NewFilterBuilder(&cfg.Sync)
 	graph := NewGraph(st, &cfg.Sync.Scope)
 	cursors := NewCursorManager(st)
@@ -97,50 +147,73 @@ func NewEngine(st *storage.Storage, cfg *config.Config) *Engine {
 		cursors:       cursors,
 		ctx:           engineCtx,
 		cancel:        cancel,
-		eventChan:     make(chan *nostr.Event, 5000), // Tier 2: Larger buffer for burst handling
-		eventCache:    NewEventCache(5000),        // Tier 1: Cache last 5000 event IDs
+		queue:         newIngestQueue(cfg.Sync.Performance.RelayQueueSize),
+		eventCache:    NewEventCache(5000),               // Tier 1: Cache last 5000 event IDs
 		aggregateChan: make(chan *AggregateUpdate, 1000), // Tier 2: Async aggregate queue
+		relayStats:    NewRelayTracker(),
+		limiter:       NewIngestLimiter(&cfg.Sync.Limits, ownerHex(cfg)),
+		idleChanged:   make(chan struct{}, 1),
 	}
 }
 
-// Start begins the sync process
+// Start begins the sync process. Bootstrap runs in the background, so stored
+// content can be served at once; syncing starts when it completes.
 func (e *Engine) Start() error {
-	// Bootstrap from seed relays
-	if err := e.bootstrap(); err != nil {
+	if _, err := e.getOwnerPubkey(); err != nil {
 		return fmt.Errorf("bootstrap failed: %w", err)
 	}
 
-	// Tier 2 Optimization: Start event ingestion workers for parallel processing
-	workerCount := e.config.Sync.Performance.Workers
-	if workerCount <= 0 {
-		workerCount = 4 // Safety fallback
+	if e.config.Sync.Scope.IncludeThreadsOfMine {
+		e.threadQueue = make(chan *nostr.Event, threadQueueSize)
+		e.threadLookups = NewEventCache(5000)
+		e.wg.Add(1)
+		go e.completeThreads()
 	}
-	fmt.Printf("[SYNC] Starting %d event processing workers\n", workerCount)
-	for i := 0; i < workerCount; i++ {
+
+	if e.config.Sync.Scope.PruneUnfollowedHours > 0 {
 		e.wg.Add(1)
-		go e.eventWorker(i + 1)
+		go e.pruneUnfollowed()
 	}
 
+	// Tier 2 Optimization: Start event ingestion workers for parallel processing
+	e.workers = e.startWorkers()
+
 	// Tier 2 Optimization: Start async aggregate worker
 	e.wg.Add(1)
 	go e.processAggregates()
 
-	// Start continuous sync
+	// Bootstrap, then start continuous sync and the periodic refresh of replaceables
 	e.wg.Add(1)
-	go e.continuousSync()
+	go func() {
+		defer e.wg.Done()
+		if !e.bootstrapWithRetry() {
+			return
+		}
 
-	// Start periodic refresh of replaceables
-	e.wg.Add(1)
-	go e.periodicRefresh()
+		if e.config.Sync.Scope.IncludeDirectMentions {
+			e.inboxWatched.Store(true)
+			e.wg.Add(1)
+			go e.watchInbox()
+		}
+
+		e.wg.Add(2)
+		go e.continuousSync()
+		go e.periodicRefresh()
+	}()
 
 	return nil
 }
 
-// Stop gracefully stops the sync engine
+// Stop gracefully stops the sync engine. Subscriptions are cancelled and
+// events still queued are discarded; the workers finish the events they hold
+// before the aggregate worker stops.
 func (e *Engine) Stop() {
 	e.cancel()
-	close(e.eventChan)
-	close(e.aggregateChan) // Tier 2: Close aggregate channel
+	e.queue.close()
+	if e.workers != nil {
+		e.workers.Wait()
+	}
+	e.closeAggregates()
 	e.wg.Wait()
 }
 
@@ -149,6 +222,32 @@ func (e *Engine) SetRetentionEvaluator(fn func(context.Context, *nostr.Event) er
 	e.evaluateRetention = fn
 }
 
+// SetCacheInvalidator sets the callback that drops cached responses showing a newly stored event
+func (e *Engine) SetCacheInvalidator(fn func(context.Context, *nostr.Event) error) {
+	e.invalidateCache = fn
+}
+
+// SetDenyList replaces the authors the engine never requests or stores,
+// sync.scope.denylist_pubkeys by default, so a list shared with storage and
+// the admin API takes effect here when an author is added at runtime
+func (e *Engine) SetDenyList(denied *security.DenyList) {
+	e.filterBuilder.denied = denied
+}
+
+// SyncNow starts a sync iteration without waiting for the next tick. The
+// relays are synced in the background.
+func (e *Engine) SyncNow() error {
+	return e.syncOnce()
+}
+
+// Ingest runs an event received outside relay subscriptions, such as one
+// published to nophr's own relay endpoint, through the sync pipeline: storage,
+// graph updates, aggregates, cache invalidation and retention. The engine must
+// be running.
+func (e *Engine) Ingest(event *nostr.Event) error {
+	return e.processEvent(event)
+}
+
 // getOwnerPubkey decodes the npub to hex pubkey
 func (e *Engine) getOwnerPubkey() (string, error) {
 	if _, hex, err := nip19.Decode(e.config.Identity.Npub); err != nil {
@@ -158,88 +257,14 @@ func (e *Engine) getOwnerPubkey() (string, error) {
 	}
 }
 
-// bootstrap performs initial discovery and graph building
-func (e *Engine) bootstrap() error {
-	fmt.Printf("[SYNC] Starting bootstrap process...\n")
-	ownerPubkey, err := e.getOwnerPubkey()
-	if err != nil {
-		return err
-	}
-	fmt.Printf("[SYNC] Owner pubkey (hex): %s\n", ownerPubkey)
-
-	// Step 1: Fetch owner's profile, contacts, and relay hints from seeds
-	fmt.Printf("[SYNC] Step 1: Bootstrapping from seed relays...\n")
-	if err := e.discovery.BootstrapFromSeeds(e.ctx, ownerPubkey); err != nil {
-		return fmt.Errorf("failed to bootstrap from seeds: %w", err)
-	}
-	fmt.Printf("[SYNC] ✓ Bootstrap from seeds complete\n")
-
-	// Step 2: Fetch owner's contact list (kind 3) to build initial graph
-	seedRelays := e.nostrClient.GetSeedRelays()
-	fmt.Printf("[SYNC] Step 2: Fetching contact list from %d seed relays\n", len(seedRelays))
-	for i, relay := range seedRelays {
-		fmt.Printf("[SYNC]   Seed relay %d: %s\n", i+1, relay)
-	}
-
-	filter := nostr.Filter{
-		Kinds:   []int{3},
-		Authors: []string{ownerPubkey},
-		Limit:   1,
-	}
-
-	events, err := e.nostrClient.FetchEvents(e.ctx, seedRelays, filter)
-	if err != nil {
-		return fmt.Errorf("failed to fetch contact list: %w", err)
-	}
-	fmt.Printf("[SYNC] Fetched %d contact list events\n", len(events))
-
-	if len(events) > 0 {
-		// Process the contact list to build the graph
-		fmt.Printf("[SYNC] Processing contact list (event ID: %s)\n", events[0].ID)
-		if err := e.graph.ProcessContactList(e.ctx, events[0], ownerPubkey); err != nil {
-			return fmt.Errorf("failed to process contact list: %w", err)
-		}
-		fmt.Printf("[SYNC] ✓ Contact list processed\n")
-	} else {
-		fmt.Printf("[SYNC] ⚠ No contact list found - will sync owner events only\n")
-	}
-
-	// Step 3: Get authors in scope
-	fmt.Printf("[SYNC] Step 3: Getting authors in scope...\n")
-	authors, err := e.graph.GetAuthorsInScope(e.ctx, ownerPubkey)
-	if err != nil {
-		return fmt.Errorf("failed to get authors in scope: %w", err)
-	}
-	fmt.Printf("[SYNC] Authors in scope: %d\n", len(authors))
-	if len(authors) <= 5 {
-		for i, author := range authors {
-			fmt.Printf("[SYNC]   Author %d: %s\n", i+1, author[:16]+"...")
-		}
-	} else {
-		fmt.Printf("[SYNC]   (First 5 authors shown)\n")
-		for i := 0; i < 5; i++ {
-			fmt.Printf("[SYNC]   Author %d: %s\n", i+1, authors[i][:16]+"...")
+// ownerHex decodes the owner's npub, returning "" if it is invalid
+func ownerHex(cfg *config.Config) string {
+	if _, hex, err := nip19.Decode(cfg.Identity.Npub); err == nil {
+		if s, ok := hex.(string); ok {
+			return s
 		}
 	}
-
-	// Step 4: Discover relay hints for all authors in scope
-	fmt.Printf("[SYNC] Step 4: Discovering relay hints...\n")
-	// Get owner's outbox relays to search for authors' relay hints
-	ownerRelays, err := e.discovery.GetOutboxRelays(e.ctx, ownerPubkey)
-	if err != nil || len(ownerRelays) == 0 {
-		ownerRelays = seedRelays // Fallback to seeds
-		fmt.Printf("[SYNC] Using seed relays as fallback (%d relays)\n", len(ownerRelays))
-	} else {
-		fmt.Printf("[SYNC] Using owner's outbox relays (%d relays)\n", len(ownerRelays))
-	}
-
-	if err := e.discovery.DiscoverRelayHintsForPubkeys(e.ctx, authors, ownerRelays); err != nil {
-		return fmt.Errorf("failed to discover relay hints: %w", err)
-	}
-	fmt.Printf("[SYNC] ✓ Relay hints discovered\n")
-	fmt.Printf("[SYNC] ✓ Bootstrap complete!\n\n")
-
-	return nil
+	return ""
 }
 
 // continuousSync runs the main sync loop with adaptive intervals
@@ -248,7 +273,7 @@ func (e *Engine) continuousSync() {
 
 	// Tier 1 Optimization: Smart adaptive sync intervals
 	interval := 10 * time.Second
-	ticker := time.NewTicker(interval)
+	ticker := time.NewTicker(e.idleInterval(interval))
 	defer ticker.Stop()
 
 	eventsInLastSync := 0
@@ -257,7 +282,14 @@ func (e *Engine) continuousSync() {
 		select {
 		case <-e.ctx.Done():
 			return
+		case <-e.idleChanged:
+			ticker.Reset(e.idleInterval(interval))
 		case <-ticker.C:
+			// Connections the last sync left open aren't kept between idle syncs
+			if e.idle.Load() {
+				e.closeIdleRelays()
+			}
+
 			// Track events before sync
 			sizeBefore := e.eventCache.Size()
 
@@ -286,7 +318,7 @@ func (e *Engine) continuousSync() {
 			// Only reset ticker if interval changed
 			if newInterval != interval {
 				interval = newInterval
-				ticker.Reset(interval)
+				ticker.Reset(e.idleInterval(interval))
 				fmt.Printf("[SYNC] Adaptive interval: %v (received %d events)\n", interval, eventsInLastSync)
 			}
 		}
@@ -341,11 +373,14 @@ func (e *Engine) syncOnce() error {
 		fmt.Printf("[SYNC]   Built %d filters for outbox\n", len(filters))
 
 		// Try negentropy sync first, fall back to REQ if unsupported
-		go e.syncRelayWithFallback(relay, filters)
+		if !e.goRelaySyncOnce(relay, func() { e.syncRelayWithFallback(relay, filters) }) {
+			fmt.Printf("[SYNC]   Previous sync still running or queued, skipping\n")
+		}
 	}
 
-	// STEP 2: Sync interactions TO US from OUR INBOX (read relays)
-	if e.config.Sync.Scope.IncludeDirectMentions {
+	// STEP 2: Sync interactions TO US from OUR INBOX (read relays), unless
+	// watchInbox keeps subscriptions open there
+	if e.config.Sync.Scope.IncludeDirectMentions && !e.inboxWatched.Load() {
 		if err := e.syncOwnerInbox(ownerPubkey, kinds); err != nil {
 			fmt.Printf("[SYNC] ⚠ Inbox sync failed: %v\n", err)
 			// Don't fail the whole sync if inbox fails
@@ -356,12 +391,42 @@ func (e *Engine) syncOnce() error {
 	return nil
 }
 
-// syncRelayWithFallback tries negentropy sync first, falls back to REQ if unsupported
+// goRelaySync runs a relay sync or backfill in the background
+func (e *Engine) goRelaySync(fn func()) {
+	e.relaySyncs.Add(1)
+	go func() {
+		defer e.relaySyncs.Done()
+		fn()
+	}()
+}
+
+// goRelaySyncOnce runs a recurring relay sync in the background unless the
+// previous one for key is still running or waiting for a subscription slot,
+// so relays slower than the sync interval don't pile up queued syncs
+func (e *Engine) goRelaySyncOnce(key string, fn func()) bool {
+	if _, busy := e.pendingSyncs.LoadOrStore(key, true); busy {
+		return false
+	}
+	e.goRelaySync(func() {
+		defer e.pendingSyncs.Delete(key)
+		fn()
+	})
+	return true
+}
+
+// syncRelayWithFallback tries negentropy sync first, falls back to REQ if
+// unsupported. Both run in one subscription slot.
 func (e *Engine) syncRelayWithFallback(relay string, filters []nostr.Filter) {
+	ctx, release, err := e.nostrClient.Subscriptions().Acquire(e.ctx)
+	if err != nil {
+		return // Engine stopping
+	}
+	defer release()
+
 	// Check if negentropy is enabled
 	if !e.config.Sync.Performance.UseNegentropy {
 		// Negentropy disabled, use traditional REQ
-		e.subscribeRelay(relay, filters)
+		e.subscribeRelay(ctx, relay, filters)
 		return
 	}
 
@@ -400,20 +465,22 @@ func (e *Engine) syncRelayWithFallback(relay string, filters []nostr.Filter) {
 	fmt.Printf("[SYNC] Trying negentropy for %s (%d authors, %d kinds, complete set)\n", relay, len(authors), len(kinds))
 
 	// Try negentropy with the optimized complete-set filter
-	success, err := e.NegentropySync(e.ctx, relay, negentropyFilter)
+	success, err := e.NegentropySync(ctx, relay, negentropyFilter)
 	if err != nil {
 		// Hard error - log and fall back to REQ
 		fmt.Printf("[SYNC] ⚠ Negentropy error for %s: %v (falling back to REQ)\n", relay, err)
+		e.relayStats.RecordError(relay, err)
 	} else if success {
 		// Negentropy succeeded - we're done!
 		fmt.Printf("[SYNC] ✓ Negentropy sync complete for %s\n", relay)
+		e.recordRelaySuccess(relay, 0, 0)
 		return
 	}
 
 	// Fall back to traditional REQ-based sync (always enabled for reliability)
 	// REQ uses cursor-based incremental sync (efficient for traditional subscriptions)
 	fmt.Printf("[SYNC] Using traditional REQ for %s\n", relay)
-	e.subscribeRelay(relay, filters)
+	e.subscribeRelay(ctx, relay, filters)
 }
 
 // syncOwnerInbox syncs interactions directed at the owner from their INBOX (read relays)
@@ -421,16 +488,11 @@ func (e *Engine) syncRelayWithFallback(relay string, filters []nostr.Filter) {
 func (e *Engine) syncOwnerInbox(ownerPubkey string, kinds []int) error {
 	fmt.Printf("[SYNC] Starting inbox sync for owner...\n")
 
-	// Get owner's INBOX relays (read relays where they receive interactions)
-	inboxRelays, err := e.discovery.GetInboxRelays(e.ctx, ownerPubkey)
+	inboxRelays, err := e.inboxRelays(ownerPubkey)
 	if err != nil {
-		return fmt.Errorf("failed to get inbox relays: %w", err)
-	}
-
-	if len(inboxRelays) == 0 {
-		fmt.Printf("[SYNC] ⚠ No inbox relays found for owner, using seed relays as fallback\n")
-		inboxRelays = e.nostrClient.GetSeedRelays()
+		return err
 	}
+	inboxRelays = e.skipBackingOff(inboxRelays)
 
 	fmt.Printf("[SYNC] Owner inbox relays: %d\n", len(inboxRelays))
 
@@ -461,33 +523,54 @@ func (e *Engine) syncOwnerInbox(ownerPubkey string, kinds []int) error {
 	// Sync from each inbox relay
 	for i, relay := range inboxRelays {
 		fmt.Printf("[SYNC] Processing inbox relay %d/%d: %s\n", i+1, len(inboxRelays), relay)
-		go e.syncRelayWithFallback(relay, []nostr.Filter{inboxFilter})
+		e.goRelaySyncOnce("inbox "+relay, func() { e.syncRelayWithFallback(relay, []nostr.Filter{inboxFilter}) })
 	}
 
 	return nil
 }
 
-// subscribeRelay subscribes to a relay with the given filters (traditional REQ-based sync)
-func (e *Engine) subscribeRelay(relay string, filters []nostr.Filter) {
-	ctx, cancel := context.WithTimeout(e.ctx, 30*time.Second)
+// subscribeRelay subscribes to a relay with the given filters (traditional
+// REQ-based sync). It waits for a subscription slot unless ctx carries one,
+// and the subscription stays open for 30s once it has one.
+func (e *Engine) subscribeRelay(ctx context.Context, relay string, filters []nostr.Filter) {
+	ctx, release, err := e.nostrClient.Subscriptions().Acquire(ctx)
+	if err != nil {
+		return // Engine stopping
+	}
+	defer release()
+
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
 	defer cancel()
 
+	// Connect first so relays that can't be reached are recorded and backed off from
+	latency, err := e.nostrClient.Connect(relay)
+	if err != nil {
+		if e.ctx.Err() == nil {
+			fmt.Printf("[SYNC] ⚠ Failed to connect to %s: %v\n", relay, err)
+			e.recordRelayFailure(relay, err)
+		}
+		return
+	}
+
 	fmt.Printf("[SYNC] Subscribing to %s...\n", relay)
 	eventChan := e.nostrClient.SubscribeEvents(ctx, []string{relay}, filters)
 
+	e.relayStats.SubscriptionStarted(relay)
+	defer e.relayStats.SubscriptionEnded(relay)
+
 	eventCount := 0
 	for event := range eventChan {
 		eventCount++
+		e.relayStats.EventReceived(relay)
 		if eventCount == 1 {
 			fmt.Printf("[SYNC] ✓ Receiving events from %s\n", relay)
 		}
-		select {
-		case e.eventChan <- event:
-		case <-e.ctx.Done():
-			fmt.Printf("[SYNC] Subscription to %s cancelled (context done)\n", relay)
+		if !e.queue.push(relay, event, e.importing) {
+			fmt.Printf("[SYNC] Subscription to %s cancelled (engine stopping)\n", relay)
 			return
 		}
 	}
+	e.recordRelaySuccess(relay, latency, int64(eventCount))
 
 	if eventCount > 0 {
 		fmt.Printf("[SYNC] ✓ Received %d events from %s\n", eventCount, relay)
@@ -496,20 +579,56 @@ func (e *Engine) subscribeRelay(relay string, filters []nostr.Filter) {
 	}
 }
 
-// eventWorker processes events from the event channel (Tier 2: parallel processing)
+// startWorkers starts the event processing workers, returning a group that is
+// done once they have drained the closed ingest queue
+func (e *Engine) startWorkers() *sync.WaitGroup {
+	workerCount := e.config.Sync.Performance.Workers
+	if workerCount <= 0 {
+		workerCount = 4 // Safety fallback
+	}
+	fmt.Printf("[SYNC] Starting %d event processing workers\n", workerCount)
+
+	var workers sync.WaitGroup
+	for i := 0; i < workerCount; i++ {
+		e.wg.Add(1)
+		workers.Add(1)
+		go func(id int) {
+			defer workers.Done()
+			e.eventWorker(id)
+		}(i + 1)
+	}
+	return &workers
+}
+
+// eventWorker processes events from the ingest queue in batches, each stored
+// in one transaction (Tier 2: parallel processing)
 func (e *Engine) eventWorker(workerID int) {
 	defer e.wg.Done()
 
 	fmt.Printf("[SYNC] Worker %d started\n", workerID)
 	eventCount := 0
 
-	for event := range e.eventChan {
-		eventCount++
-		if eventCount%10 == 1 {
-			fmt.Printf("[SYNC] Worker %d: Processing event %d (kind %d, author: %s)\n", workerID, eventCount, event.Kind, event.PubKey[:16]+"...")
+	batchSize := e.config.Sync.Performance.BatchSize
+	if batchSize <= 0 {
+		batchSize = 100 // Safety fallback
+	}
+	window := time.Duration(max(e.config.Sync.Performance.BatchWindowMs, 0)) * time.Millisecond
+
+	for {
+		batch, ok := e.queue.popBatch(batchSize, window)
+		if !ok {
+			break
+		}
+		if e.ctx.Err() != nil {
+			continue // Stopping: discard what is left
 		}
 
-		if err := e.processEvent(event); err != nil {
+		eventCount += len(batch)
+		if len(batch) > 1 {
+			fmt.Printf("[SYNC] Worker %d: Processing batch of %d events (%d so far)\n", workerID, len(batch), eventCount)
+		}
+
+		if err := e.processBatch(batch); err != nil {
 			// Log error but continue
 			fmt.Printf("[SYNC] ⚠ Worker %d: Event processing error: %v\n", workerID, err)
 		}
@@ -529,27 +648,104 @@ func (e *Engine) processEvent(event *nostr.Event) error {
 		}
 	}
 
+	if !e.admit(event) {
+		return nil
+	}
+	return e.store(event)
+}
+
+// processBatch handles events taken from the ingest queue together: they are
+// stored in one transaction, then each newly stored one is handled like
+// processEvent does. Events already stored are skipped by the insert.
+func (e *Engine) processBatch(events []*nostr.Event) error {
+	admitted := make([]*nostr.Event, 0, len(events))
+	for _, event := range events {
+		if e.admit(event) {
+			admitted = append(admitted, event)
+		}
+	}
+	if len(admitted) == 0 {
+		return nil
+	}
+
+	stored, err := e.storage.StoreEventBatch(e.ctx, admitted)
+	if err != nil && stored == nil {
+		// The transaction was rolled back, so one bad event doesn't cost the
+		// batch: store them one at a time instead
+		fmt.Printf("[SYNC] ⚠ Failed to store batch of %d events, storing them one by one: %v\n", len(admitted), err)
+		var errs []error
+		for _, event := range admitted {
+			if err := e.store(event); err != nil {
+				errs = append(errs, err)
+			}
+		}
+		return errors.Join(errs...)
+	}
+
+	var errs []error
+	if err != nil {
+		errs = append(errs, fmt.Errorf("failed to index batch: %w", err))
+	}
+	for _, event := range stored {
+		if err := e.handleStored(event); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	e.recordTagHints(stored)
+	return errors.Join(errs...)
+}
+
+// admit reports whether an event may be stored
+func (e *Engine) admit(event *nostr.Event) bool {
+	// Events waiting in the trash stay deleted until purged or restored
+	if trashed, err := e.storage.IsEventTrashed(e.ctx, event.ID); err == nil && trashed {
+		return false
+	}
+
+	// Denied authors can still reach us through mentions and threads
+	if e.filterBuilder.IsAuthorDenied(event.PubKey) {
+		return false
+	}
+
+	// Oversized events from other authors are rejected or truncated (sync.limits)
+	return e.limiter.Allow(event)
+}
+
+// store saves an admitted event on its own and handles it
+func (e *Engine) store(event *nostr.Event) error {
 	// Store event in Khatru
 	if err := e.storage.StoreEvent(e.ctx, event); err != nil {
 		return fmt.Errorf("failed to store event: %w", err)
 	}
 
+	if err := e.handleStored(event); err != nil {
+		return err
+	}
+	e.recordTagHints([]*nostr.Event{event})
+	return nil
+}
+
+// handleStored runs what follows storing an event: the graph, relay hint and
+// aggregate updates its kind calls for, cache invalidation and retention
+func (e *Engine) handleStored(event *nostr.Event) error {
 	// Add to cache after successful storage
 	e.eventCache.Add(event.ID)
+	e.relayStats.EventStored()
 
 	fmt.Printf("[SYNC]   ✓ Stored event %s (kind %d)\n", event.ID[:16]+"...", event.Kind)
 
 	// Handle special event kinds
 	switch event.Kind {
 	case 3:
-		// Contact list - update graph
-		if err := e.graph.ProcessContactList(e.ctx, event, e.config.Identity.Npub); err != nil {
-			return fmt.Errorf("failed to process contact list: %w", err)
+		// Contact list - apply only what changed to the graph
+		if err := e.handleContactList(event); err != nil {
+			return err
 		}
 
-		// Recompute mutuals
-		if err := e.graph.ComputeMutuals(e.ctx, e.config.Identity.Npub); err != nil {
-			return fmt.Errorf("failed to compute mutuals: %w", err)
+	case 5:
+		// Deletion request - remove the author's referenced events
+		if err := e.handleDeletion(event); err != nil {
+			return err
 		}
 
 	case 10002:
@@ -559,10 +755,8 @@ func (e *Engine) processEvent(event *nostr.Event) error {
 			return fmt.Errorf("failed to parse relay hints: %w", err)
 		}
 
-		for _, hint := range hints {
-			if err := e.storage.SaveRelayHint(e.ctx, hint); err != nil {
-				return fmt.Errorf("failed to save relay hint: %w", err)
-			}
+		if err := e.storage.SaveRelayHints(e.ctx, hints); err != nil {
+			return fmt.Errorf("failed to save relay hints: %w", err)
 		}
 
 	case 7:
@@ -572,12 +766,20 @@ func (e *Engine) processEvent(event *nostr.Event) error {
 	case 1:
 		// Tier 2 Optimization: Queue reply aggregate update (async, non-blocking)
 		e.queueReplyUpdate(event)
+		e.queueThreadCompletion(event)
 
 	case 9735:
 		// Tier 2 Optimization: Queue zap aggregate update (async, non-blocking)
 		e.queueZapUpdate(event)
 	}
 
+	// Drop cached pages that render this event
+	if e.invalidateCache != nil {
+		if err := e.invalidateCache(e.ctx, event); err != nil {
+			fmt.Printf("[SYNC]   ⚠ Cache invalidation error: %v\n", err)
+		}
+	}
+
 	// Phase 20: Evaluate retention if enabled
 	if e.evaluateRetention != nil {
 		if err := e.evaluateRetention(e.ctx, event); err != nil {
@@ -589,6 +791,22 @@ func (e *Engine) processEvent(event *nostr.Event) error {
 	return nil
 }
 
+// recordTagHints records the relays named in stored events' tags as hints of
+// where the tagged authors can be found, in one write
+func (e *Engine) recordTagHints(events []*nostr.Event) {
+	if e.config.Discovery.HintConfidence.Tags < 0 {
+		return
+	}
+
+	var sightings []*storage.SourcedRelayHint
+	for _, event := range events {
+		sightings = append(sightings, internalnostr.ParseTagRelayHints(event)...)
+	}
+	if err := e.storage.RecordRelayHintSightings(e.ctx, sightings); err != nil {
+		fmt.Printf("[SYNC]   ⚠ Failed to record tag relay hints: %v\n", err)
+	}
+}
+
 // periodicRefresh refreshes replaceable events periodically
 func (e *Engine) periodicRefresh() {
 	defer e.wg.Done()
@@ -604,6 +822,9 @@ func (e *Engine) periodicRefresh() {
 			if err := e.refreshReplaceables(); err != nil {
 				fmt.Printf("Refresh error: %v\n", err)
 			}
+			if err := e.refreshNIP05Relays(); err != nil {
+				fmt.Printf("[SYNC] ⚠ NIP-05 relay refresh error: %v\n", err)
+			}
 		}
 	}
 }
@@ -629,6 +850,9 @@ func (e *Engine) refreshReplaceables() error {
 
 	// Build replaceable filter (no since cursor)
 	filter := e.filterBuilder.BuildReplaceableFilter(authors)
+	if len(filter.Authors) == 0 {
+		return nil
+	}
 
 	// Fetch events
 	events, err := e.nostrClient.FetchEvents(e.ctx, relays, filter)
@@ -646,6 +870,31 @@ func (e *Engine) refreshReplaceables() error {
 	return nil
 }
 
+// nip05RefreshInterval is how often authors' NIP-05 documents are fetched
+// for relay hints. The first refresh runs an hour after start, once profiles
+// have synced.
+const nip05RefreshInterval = 24 * time.Hour
+
+// refreshNIP05Relays looks up the NIP-05 relays of authors in scope when the
+// last lookup is older than nip05RefreshInterval
+func (e *Engine) refreshNIP05Relays() error {
+	if time.Since(e.nip05CheckedAt) < nip05RefreshInterval {
+		return nil
+	}
+
+	ownerPubkey, err := e.getOwnerPubkey()
+	if err != nil {
+		return err
+	}
+	authors, err := e.graph.GetAuthorsInScope(e.ctx, ownerPubkey)
+	if err != nil {
+		return err
+	}
+
+	e.nip05CheckedAt = time.Now()
+	return e.discovery.DiscoverNIP05Relays(e.ctx, authors)
+}
+
 // getActiveRelays returns the list of active OUTBOX relays to sync authors' posts from
 func (e *Engine) getActiveRelays(authors []string) []string {
 	relaySet := make(map[string]bool)
@@ -683,7 +932,7 @@ func (e *Engine) getActiveRelays(authors []string) []string {
 		}
 	}
 
-	return relays
+	return e.skipBackingOff(relays)
 }
 
 // Tier 2: Async aggregate queueing methods (non-blocking)
@@ -707,18 +956,13 @@ func (e *Engine) queueReactionUpdate(event *nostr.Event) {
 		reaction = "+" // Default like
 	}
 
-	// Queue update (non-blocking)
-	select {
-	case e.aggregateChan <- &AggregateUpdate{
+	e.enqueueAggregate(&AggregateUpdate{
 		Type:          "reaction",
 		EventID:       targetEventID,
+		SourceID:      event.ID,
 		Reaction:      reaction,
 		InteractionAt: int64(event.CreatedAt),
-	}:
-	default:
-		// Channel full, log and drop (graceful degradation)
-		fmt.Printf("[SYNC] ⚠ Aggregate queue full, dropped reaction update\n")
-	}
+	})
 }
 
 func (e *Engine) queueReplyUpdate(event *nostr.Event) {
@@ -735,16 +979,12 @@ func (e *Engine) queueReplyUpdate(event *nostr.Event) {
 		return // Not a reply
 	}
 
-	// Queue update (non-blocking)
-	select {
-	case e.aggregateChan <- &AggregateUpdate{
+	e.enqueueAggregate(&AggregateUpdate{
 		Type:          "reply",
 		EventID:       targetEventID,
+		SourceID:      event.ID,
 		InteractionAt: int64(event.CreatedAt),
-	}:
-	default:
-		fmt.Printf("[SYNC] ⚠ Aggregate queue full, dropped reply update\n")
-	}
+	})
 }
 
 func (e *Engine) queueZapUpdate(event *nostr.Event) {
@@ -764,16 +1004,48 @@ func (e *Engine) queueZapUpdate(event *nostr.Event) {
 		return
 	}
 
-	// Queue update (non-blocking)
-	select {
-	case e.aggregateChan <- &AggregateUpdate{
+	e.enqueueAggregate(&AggregateUpdate{
 		Type:          "zap",
 		EventID:       targetEventID,
+		SourceID:      event.ID,
 		Sats:          amount,
 		InteractionAt: int64(event.CreatedAt),
-	}:
+	})
+}
+
+// enqueueAggregate queues an aggregate update without blocking live sync.
+// When the queue is full the update is dropped (graceful degradation), except
+// during an import where it waits for the aggregate worker to catch up.
+func (e *Engine) enqueueAggregate(update *AggregateUpdate) {
+	e.aggregateMu.RLock()
+	defer e.aggregateMu.RUnlock()
+
+	// Events ingested while the engine stops arrive after the aggregate worker
+	if e.aggregateClosed {
+		return
+	}
+
+	if e.importing {
+		e.aggregateChan <- update
+		return
+	}
+
+	select {
+	case e.aggregateChan <- update:
 	default:
-		fmt.Printf("[SYNC] ⚠ Aggregate queue full, dropped zap update\n")
+		fmt.Printf("[SYNC] ⚠ Aggregate queue full, dropped %s update\n", update.Type)
+	}
+}
+
+// closeAggregates closes the aggregate queue, once, so the aggregate worker
+// flushes and exits. Updates queued afterwards are ignored.
+func (e *Engine) closeAggregates() {
+	e.aggregateMu.Lock()
+	defer e.aggregateMu.Unlock()
+
+	if !e.aggregateClosed {
+		e.aggregateClosed = true
+		close(e.aggregateChan)
 	}
 }
 
@@ -792,32 +1064,53 @@ func (e *Engine) processAggregates() {
 		InteractionAt int64
 	})
 
+	// Interactions in each pending batch (interaction ID -> target), marked
+	// seen only once their batch is saved so a failed batch isn't lost
+	replySources := make(map[string]string)
+	reactionSources := make(map[string]string)
+	zapSources := make(map[string]string)
+
+	markSeen := func(sources map[string]string) {
+		if err := e.storage.MarkInteractionsSeen(e.ctx, sources); err != nil {
+			fmt.Printf("[SYNC] ⚠ Failed to mark interactions seen: %v\n", err)
+		}
+	}
+
 	flush := func() {
 		// Process batched replies
 		if len(replies) > 0 {
 			if err := e.storage.BatchIncrementReplies(e.ctx, replies); err != nil {
 				fmt.Printf("[SYNC] ⚠ Failed to batch update replies: %v\n", err)
+			} else {
+				markSeen(replySources)
 			}
 			replies = make(map[string]int64)
+			replySources = make(map[string]string)
 		}
 
 		// Process batched reactions
 		if len(reactions) > 0 {
 			if err := e.storage.BatchIncrementReactions(e.ctx, reactions); err != nil {
 				fmt.Printf("[SYNC] ⚠ Failed to batch update reactions: %v\n", err)
+			} else {
+				markSeen(reactionSources)
 			}
 			reactions = make(map[string]map[string]int64)
+			reactionSources = make(map[string]string)
 		}
 
 		// Process batched zaps
 		if len(zaps) > 0 {
 			if err := e.storage.BatchAddZaps(e.ctx, zaps); err != nil {
 				fmt.Printf("[SYNC] ⚠ Failed to batch update zaps: %v\n", err)
+			} else {
+				markSeen(zapSources)
 			}
 			zaps = make(map[string]struct {
 				Sats          int64
 				InteractionAt int64
 			})
+			zapSources = make(map[string]string)
 		}
 	}
 
@@ -833,22 +1126,53 @@ func (e *Engine) processAggregates() {
 				return
 			}
 
-			// Accumulate updates by type
+			// Re-fetched interactions are already counted or pending
+			if _, pending := replySources[update.SourceID]; pending {
+				continue
+			}
+			if _, pending := reactionSources[update.SourceID]; pending {
+				continue
+			}
+			if _, pending := zapSources[update.SourceID]; pending {
+				continue
+			}
+			seen, err := e.storage.IsInteractionSeen(e.ctx, update.SourceID)
+			if err != nil {
+				fmt.Printf("[SYNC] ⚠ Failed to check %s update: %v\n", update.Type, err)
+				continue
+			}
+			if seen {
+				continue
+			}
+
+			// Accumulate updates by type. A batch holds one reply or reaction
+			// per target, so flush before a second one would overwrite it.
 			switch update.Type {
 			case "reply":
+				if _, pending := replies[update.EventID]; pending {
+					flush()
+				}
 				replies[update.EventID] = update.InteractionAt
+				replySources[update.SourceID] = update.EventID
 
 			case "reaction":
+				if _, pending := reactions[update.EventID][update.Reaction]; pending {
+					flush()
+				}
 				if reactions[update.EventID] == nil {
 					reactions[update.EventID] = make(map[string]int64)
 				}
 				reactions[update.EventID][update.Reaction] = update.InteractionAt
+				reactionSources[update.SourceID] = update.EventID
 
 			case "zap":
-				zaps[update.EventID] = struct {
-					Sats          int64
-					InteractionAt int64
-				}{Sats: update.Sats, InteractionAt: update.InteractionAt}
+				zap := zaps[update.EventID]
+				zap.Sats += update.Sats
+				if update.InteractionAt > zap.InteractionAt {
+					zap.InteractionAt = update.InteractionAt
+				}
+				zaps[update.EventID] = zap
+				zapSources[update.SourceID] = update.EventID
 			}
 
 		case <-ticker.C:
@@ -3,17 +3,20 @@ package sync
 import (
 	"github.com/nbd-wtf/go-nostr"
 	"github.com/sandwich/nophr/internal/config"
+	"github.com/sandwich/nophr/internal/security"
 )
 
 // FilterBuilder creates Nostr filters based on sync configuration
 type FilterBuilder struct {
 	config *config.Sync
+	denied *security.DenyList // sync.scope.denylist_pubkeys, plus authors denied at runtime
 }
 
 // NewFilterBuilder creates a new filter builder
 func NewFilterBuilder(cfg *config.Sync) *FilterBuilder {
 	return &FilterBuilder{
 		config: cfg,
+		denied: security.NewDenyList(cfg.Scope.DenylistPubkeys),
 	}
 }
 
@@ -29,6 +32,11 @@ func (fb *FilterBuilder) BuildFilters(authors []string, since int64) []nostr.Fil
 		kinds = []int{0, 1, 3, 6, 7, 9735, 30023, 10002}
 	}
 
+	authors = fb.withoutDenied(authors)
+	if len(authors) == 0 {
+		return nil
+	}
+
 	filters := make([]nostr.Filter, 0)
 
 	// Main filter for configured authors and kinds
@@ -97,6 +105,11 @@ func (fb *FilterBuilder) BuildThreadFilter(ownerEventIDs []string, since int64)
 func (fb *FilterBuilder) BuildReplaceableFilter(authors []string) nostr.Filter {
 	replaceableKinds := []int{0, 3, 10002, 30023}
 
+	authors = fb.withoutDenied(authors)
+	if len(authors) == 0 {
+		return nostr.Filter{}
+	}
+
 	filter := nostr.Filter{
 		Authors: authors,
 		Kinds:   replaceableKinds,
@@ -132,6 +145,26 @@ func (fb *FilterBuilder) ShouldIncludeAuthor(pubkey string) bool {
 	return true
 }
 
+// IsAuthorDenied reports whether an author is on the deny list
+func (fb *FilterBuilder) IsAuthorDenied(pubkey string) bool {
+	return fb.denied.IsPubkeyDenied(pubkey)
+}
+
+// withoutDenied drops denied authors so they're never requested from relays.
+// The allowlist is applied when the graph computes the authors in scope.
+func (fb *FilterBuilder) withoutDenied(authors []string) []string {
+	if fb.denied.Count() == 0 {
+		return authors
+	}
+	allowed := make([]string, 0, len(authors))
+	for _, author := range authors {
+		if !fb.IsAuthorDenied(author) {
+			allowed = append(allowed, author)
+		}
+	}
+	return allowed
+}
+
 // GetConfiguredKinds returns the configured event kinds to sync
 func (fb *FilterBuilder) GetConfiguredKinds() []int {
 	kinds := fb.config.Kinds.ToIntSlice()
@@ -148,6 +181,7 @@ func (fb *FilterBuilder) GetConfiguredKinds() []int {
 // - Combine all kinds into a single filter (efficient for large datasets)
 // - Let negentropy handle the reconciliation
 func (fb *FilterBuilder) BuildNegentropyFilter(authors []string) nostr.Filter {
+	authors = fb.withoutDenied(authors)
 	if len(authors) == 0 {
 		return nostr.Filter{}
 	}
@@ -241,3 +241,29 @@ func TestGetConfiguredKinds(t *testing.T) {
 		})
 	}
 }
+
+func TestDeniedAuthorsExcluded(t *testing.T) {
+	fb := NewFilterBuilder(&config.Sync{
+		Scope: config.SyncScope{DenylistPubkeys: []string{"denied"}},
+	})
+
+	filters := fb.BuildFilters([]string{"alice", "denied", "bob"}, 0)
+	if len(filters) != 1 || len(filters[0].Authors) != 2 {
+		t.Fatalf("Expected one filter with 2 authors, got %v", filters)
+	}
+	for _, author := range filters[0].Authors {
+		if author == "denied" {
+			t.Error("Denied author requested in sync filter")
+		}
+	}
+
+	if filters := fb.BuildFilters([]string{"denied"}, 0); filters != nil {
+		t.Errorf("Expected no filters when every author is denied, got %v", filters)
+	}
+	if filter := fb.BuildReplaceableFilter([]string{"denied"}); len(filter.Authors) != 0 || len(filter.Kinds) != 0 {
+		t.Errorf("Expected an empty replaceable filter, got %v", filter)
+	}
+	if filter := fb.BuildNegentropyFilter([]string{"alice", "denied"}); len(filter.Authors) != 1 {
+		t.Errorf("Expected 1 author in negentropy filter, got %v", filter.Authors)
+	}
+}
@@ -60,6 +60,13 @@ func (g *Graph) ProcessContactList(ctx context.Context, event *nostr.Event, root
 
 	// Save each followed pubkey as a graph node
 	for _, followedPubkey := range following {
+		// Never push a closer node further out (e.g. a direct follow seen in a FOAF list)
+		if depth > 1 {
+			if existing, err := g.storage.GetGraphNode(ctx, rootPubkey, followedPubkey); err == nil && existing.Depth <= depth {
+				continue
+			}
+		}
+
 		node := &storage.GraphNode{
 			RootPubkey: rootPubkey,
 			Pubkey:     followedPubkey,
@@ -76,68 +83,78 @@ func (g *Graph) ProcessContactList(ctx context.Context, event *nostr.Event, root
 	return nil
 }
 
-// ComputeMutuals identifies mutual follows and updates the graph
-func (g *Graph) ComputeMutuals(ctx context.Context, rootPubkey string) error {
-	// Get all direct follows (depth 1)
-	allNodes, err := g.storage.GetGraphNodes(ctx, rootPubkey, 1)
-	if err != nil {
-		return fmt.Errorf("failed to get graph nodes: %w", err)
+// ApplyContactDiff updates the root's direct follows with only the changes in diff
+func (g *Graph) ApplyContactDiff(ctx context.Context, rootPubkey string, diff *ContactDiff, seenAt int64) error {
+	for _, pubkey := range diff.Added {
+		node := &storage.GraphNode{
+			RootPubkey: rootPubkey,
+			Pubkey:     pubkey,
+			Depth:      1,
+			LastSeen:   seenAt,
+		}
+		if err := g.storage.SaveGraphNode(ctx, node); err != nil {
+			return fmt.Errorf("failed to save graph node: %w", err)
+		}
+		if err := g.UpdateMutual(ctx, rootPubkey, pubkey); err != nil {
+			return err
+		}
 	}
 
-	directFollows := make(map[string]bool)
-	for _, node := range allNodes {
-		if node.Depth == 1 {
-			directFollows[node.Pubkey] = true
+	for _, pubkey := range diff.Removed {
+		if err := g.storage.DeleteGraphNode(ctx, rootPubkey, pubkey); err != nil {
+			return err
 		}
 	}
 
-	// For each direct follow, check if they follow us back
-	for followedPubkey := range directFollows {
-		// Get their contact list from storage
-		filter := nostr.Filter{
-			Kinds:   []int{3},
-			Authors: []string{followedPubkey},
-			Limit:   1,
-		}
+	return nil
+}
 
-		events, err := g.storage.QueryEvents(ctx, filter)
-		if err != nil {
-			continue // Skip if we can't query
-		}
+// UpdateMutual refreshes the mutual flag of one direct follow from the follows index
+func (g *Graph) UpdateMutual(ctx context.Context, rootPubkey, pubkey string) error {
+	node, err := g.storage.GetGraphNode(ctx, rootPubkey, pubkey)
+	if err != nil || node.Depth != 1 {
+		return nil // Not a direct follow
+	}
 
-		if len(events) == 0 {
-			continue // No contact list yet
-		}
+	mutual, err := g.storage.IsMutual(ctx, rootPubkey, pubkey)
+	if err != nil {
+		return err
+	}
+	if node.Mutual == mutual {
+		return nil
+	}
 
-		// Check if they follow root back
-		isMutual := false
-		for _, tag := range events[0].Tags {
-			if len(tag) >= 2 && tag[0] == "p" && tag[1] == rootPubkey {
-				isMutual = true
-				break
-			}
-		}
+	node.Mutual = mutual
+	if err := g.storage.SaveGraphNode(ctx, node); err != nil {
+		return fmt.Errorf("failed to update mutual status: %w", err)
+	}
+	return nil
+}
 
-		// Update the node if it's mutual
-		if isMutual {
-			// Fetch and update the node
-			nodes, err := g.storage.GetGraphNodes(ctx, rootPubkey, 1)
-			if err != nil {
-				continue
-			}
+// ComputeMutuals resets the mutual flags of all direct follows from the follows index
+func (g *Graph) ComputeMutuals(ctx context.Context, rootPubkey string) error {
+	nodes, err := g.storage.GetGraphNodes(ctx, rootPubkey, 1)
+	if err != nil {
+		return fmt.Errorf("failed to get graph nodes: %w", err)
+	}
+	mutuals, err := g.storage.GetMutuals(ctx, rootPubkey)
+	if err != nil {
+		return err
+	}
+	isMutual := make(map[string]bool, len(mutuals))
+	for _, pubkey := range mutuals {
+		isMutual[pubkey] = true
+	}
 
-			for _, node := range nodes {
-				if node.Pubkey == followedPubkey {
-					node.Mutual = true
-					if err := g.storage.SaveGraphNode(ctx, node); err != nil {
-						return fmt.Errorf("failed to update mutual status: %w", err)
-					}
-					break
-				}
-			}
+	for _, node := range nodes {
+		if node.Depth != 1 || node.Mutual == isMutual[node.Pubkey] {
+			continue
+		}
+		node.Mutual = isMutual[node.Pubkey]
+		if err := g.storage.SaveGraphNode(ctx, node); err != nil {
+			return fmt.Errorf("failed to update mutual status: %w", err)
 		}
 	}
-
 	return nil
 }
 
@@ -0,0 +1,48 @@
+package sync
+
+import (
+	"fmt"
+	"time"
+)
+
+// SetIdle moves the engine in or out of idle mode. While idle, the sync loop
+// waits idle.sync_interval_factor times longer between syncs and relay
+// connections no subscription is using are closed, unless idle.keep_relays
+// is set. Leaving idle mode restores the normal interval at once.
+func (e *Engine) SetIdle(idle bool) {
+	if e.idle.Swap(idle) == idle {
+		return
+	}
+	if idle {
+		e.closeIdleRelays()
+	}
+
+	select {
+	case e.idleChanged <- struct{}{}:
+	default:
+	}
+}
+
+// Idle reports whether the engine is in idle mode
+func (e *Engine) Idle() bool {
+	return e.idle.Load()
+}
+
+// idleInterval returns the wait between syncs for the adaptive interval,
+// stretched while idle
+func (e *Engine) idleInterval(interval time.Duration) time.Duration {
+	if factor := e.config.Idle.SyncIntervalFactor; e.idle.Load() && factor > 1 {
+		return interval * time.Duration(factor)
+	}
+	return interval
+}
+
+// closeIdleRelays closes the relay connections no subscription is using
+func (e *Engine) closeIdleRelays() {
+	if e.config.Idle.KeepRelays {
+		return
+	}
+	if closed := e.nostrClient.CloseIdle(); closed > 0 {
+		fmt.Printf("[SYNC] Idle: closed %d relay connections\n", closed)
+	}
+}
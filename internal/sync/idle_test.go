@@ -0,0 +1,47 @@
+package sync
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/sandwich/nophr/internal/config"
+	internalnostr "github.com/sandwich/nophr/internal/nostr"
+)
+
+func TestSetIdle(t *testing.T) {
+	cfg := config.Default()
+	cfg.Idle.SyncIntervalFactor = 6
+	e := &Engine{
+		config:      cfg,
+		nostrClient: internalnostr.New(context.Background(), &cfg.Relays),
+		idleChanged: make(chan struct{}, 1),
+	}
+
+	if got := e.idleInterval(10 * time.Second); got != 10*time.Second {
+		t.Errorf("awake interval = %v, want 10s", got)
+	}
+
+	e.SetIdle(true)
+	if got := e.idleInterval(10 * time.Second); got != time.Minute {
+		t.Errorf("idle interval = %v, want 1m", got)
+	}
+	select {
+	case <-e.idleChanged:
+	default:
+		t.Error("going idle didn't wake the sync loop")
+	}
+
+	// Setting the same mode again leaves the loop alone
+	e.SetIdle(true)
+	select {
+	case <-e.idleChanged:
+		t.Error("sync loop woken without a change")
+	default:
+	}
+
+	e.SetIdle(false)
+	if e.Idle() || e.idleInterval(10*time.Second) != 10*time.Second {
+		t.Error("waking didn't restore the interval")
+	}
+}
@@ -0,0 +1,179 @@
+package sync
+
+import (
+	"context"
+	"encoding/json"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/nbd-wtf/go-nostr"
+	"github.com/nbd-wtf/go-nostr/nip19"
+	"github.com/sandwich/nophr/internal/config"
+	"github.com/sandwich/nophr/internal/storage"
+)
+
+func signedNote(t *testing.T, sk, content string) *nostr.Event {
+	t.Helper()
+
+	pk, _ := nostr.GetPublicKey(sk)
+	event := &nostr.Event{
+		PubKey:    pk,
+		CreatedAt: nostr.Now(),
+		Kind:      1,
+		Tags:      nostr.Tags{},
+		Content:   content,
+	}
+	if err := event.Sign(sk); err != nil {
+		t.Fatalf("Failed to sign event: %v", err)
+	}
+	return event
+}
+
+func eventJSON(t *testing.T, event *nostr.Event) string {
+	t.Helper()
+
+	data, err := json.Marshal(event)
+	if err != nil {
+		t.Fatalf("Failed to marshal event: %v", err)
+	}
+	return string(data)
+}
+
+func TestReadArchive(t *testing.T) {
+	sk := nostr.GeneratePrivateKey()
+	first := eventJSON(t, signedNote(t, sk, "first"))
+	second := eventJSON(t, signedNote(t, sk, "second"))
+	primalStats := `{"kind":10000100,"content":"{\"likes\":3}"}`
+
+	tests := []struct {
+		name        string
+		format      string
+		input       string
+		wantEvents  int
+		wantSkipped int
+	}{
+		{"strfry jsonl", FormatStrfryJSONL, first + "\n\n" + second + "\n", 2, 0},
+		{"primal array", FormatPrimal, "[" + first + "," + primalStats + "," + second + "]", 2, 1},
+		{"nostrudel array", FormatNostrudel, "  [" + first + "]", 1, 0},
+		{"nostrudel lines", FormatNostrudel, first + "\n" + second, 2, 0},
+		{"garbage line", FormatStrfryJSONL, first + "\nnot json\n", 1, 1},
+		{"jsonl", FormatJSONL, first + "\n" + second + "\n", 2, 0},
+		{"nostr-tools array", FormatNostrTools, "[" + first + "," + second + "]", 2, 0},
+		{"auto array", FormatAuto, "\n[" + first + "]", 1, 0},
+		{"auto lines", FormatAuto, first + "\n" + second, 2, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			events := 0
+			skipped, err := readArchive(strings.NewReader(tt.input), tt.format, func(*nostr.Event) error {
+				events++
+				return nil
+			})
+			if err != nil {
+				t.Fatalf("readArchive() error = %v", err)
+			}
+			if events != tt.wantEvents || skipped != tt.wantSkipped {
+				t.Errorf("got %d events, %d skipped; want %d, %d", events, skipped, tt.wantEvents, tt.wantSkipped)
+			}
+		})
+	}
+
+	if _, err := readArchive(strings.NewReader(first), "damus", func(*nostr.Event) error { return nil }); err == nil {
+		t.Error("Expected error for unknown format")
+	}
+	if _, err := readArchive(strings.NewReader(first), FormatPrimal, func(*nostr.Event) error { return nil }); err == nil {
+		t.Error("Expected error for primal archive that is not an array")
+	}
+}
+
+func TestArchiveWriterRoundTrip(t *testing.T) {
+	sk := nostr.GeneratePrivateKey()
+	notes := []*nostr.Event{signedNote(t, sk, "first"), signedNote(t, sk, "second")}
+
+	for _, format := range ExportFormats {
+		for _, n := range []int{0, len(notes)} {
+			var buf strings.Builder
+			w, err := NewArchiveWriter(&buf, format)
+			if err != nil {
+				t.Fatalf("NewArchiveWriter(%s) error = %v", format, err)
+			}
+			for _, note := range notes[:n] {
+				if err := w.Write(note); err != nil {
+					t.Fatalf("Write() error = %v", err)
+				}
+			}
+			if err := w.Close(); err != nil {
+				t.Fatalf("Close() error = %v", err)
+			}
+
+			var ids []string
+			skipped, err := readArchive(strings.NewReader(buf.String()), FormatAuto, func(event *nostr.Event) error {
+				ids = append(ids, event.ID)
+				return nil
+			})
+			if err != nil {
+				t.Fatalf("%s with %d events: readArchive() error = %v\n%s", format, n, err, buf.String())
+			}
+			if len(ids) != n || skipped != 0 || w.Count() != n {
+				t.Errorf("%s: wrote %d events, read back %d (%d skipped)", format, w.Count(), len(ids), skipped)
+			}
+			for i, id := range ids {
+				if id != notes[i].ID {
+					t.Errorf("%s: event %d = %s, want %s", format, i, id, notes[i].ID)
+				}
+			}
+		}
+	}
+
+	if _, err := NewArchiveWriter(&strings.Builder{}, FormatPrimal); err == nil {
+		t.Error("Expected error for a format that can't be written")
+	}
+}
+
+func TestImport(t *testing.T) {
+	ctx := context.Background()
+
+	st, err := storage.New(ctx, &config.Storage{
+		Driver:     "sqlite",
+		SQLitePath: filepath.Join(t.TempDir(), "test.db"),
+	})
+	if err != nil {
+		t.Fatalf("Failed to create storage: %v", err)
+	}
+	defer st.Close()
+
+	sk := nostr.GeneratePrivateKey()
+	pk, _ := nostr.GetPublicKey(sk)
+	cfg := config.Default()
+	cfg.Identity.Npub, _ = nip19.EncodePublicKey(pk)
+
+	good := signedNote(t, sk, "hello")
+	tampered := signedNote(t, sk, "original")
+	tampered.Content = "edited"
+
+	archive := eventJSON(t, good) + "\n" + eventJSON(t, tampered) + "\n"
+
+	result, err := NewEngine(st, cfg).Import(strings.NewReader(archive), FormatStrfryJSONL)
+	if err != nil {
+		t.Fatalf("Import() error = %v", err)
+	}
+	if result.Imported != 1 || result.Invalid != 1 {
+		t.Errorf("Expected 1 imported and 1 invalid, got %+v", result)
+	}
+
+	exists, err := st.EventExists(ctx, good.ID)
+	if err != nil || !exists {
+		t.Errorf("Expected imported event to be stored")
+	}
+
+	// Importing the same archive again only finds duplicates
+	result, err = NewEngine(st, cfg).Import(strings.NewReader(archive), FormatStrfryJSONL)
+	if err != nil {
+		t.Fatalf("Import() error = %v", err)
+	}
+	if result.Imported != 0 || result.Duplicates != 1 {
+		t.Errorf("Expected 1 duplicate on re-import, got %+v", result)
+	}
+}
@@ -0,0 +1,170 @@
+package sync
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/nbd-wtf/go-nostr"
+)
+
+// inboxRetryDelay is the shortest wait before reopening a mention
+// subscription that failed or was closed by the relay
+const inboxRetryDelay = 10 * time.Second
+
+// inboxRelays returns where mentions of the owner are requested:
+// sync.scope.inbox_relays, else the owner's read relays from their kind 10002,
+// else the seeds
+func (e *Engine) inboxRelays(ownerPubkey string) ([]string, error) {
+	if len(e.config.Sync.Scope.InboxRelays) > 0 {
+		return e.config.Sync.Scope.InboxRelays, nil
+	}
+
+	// Get owner's INBOX relays (read relays where they receive interactions)
+	relays, err := e.discovery.GetInboxRelays(e.ctx, ownerPubkey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get inbox relays: %w", err)
+	}
+	if len(relays) == 0 {
+		fmt.Printf("[SYNC] ⚠ No inbox relays found for owner, using seed relays as fallback\n")
+		relays = e.nostrClient.GetSeedRelays()
+	}
+	return relays, nil
+}
+
+// watchInbox keeps a mention subscription open on each inbox relay until the
+// engine stops. The inbox relays are looked up again every
+// discovery.refresh_seconds, and the subscriptions are reopened on the new
+// set when it changes.
+func (e *Engine) watchInbox() {
+	defer e.wg.Done()
+
+	ownerPubkey, err := e.getOwnerPubkey()
+	if err != nil {
+		fmt.Printf("[SYNC] ⚠ Inbox watch not started: %v\n", err)
+		return
+	}
+
+	refresh := time.Duration(e.config.Discovery.RefreshSeconds) * time.Second
+	if refresh <= 0 {
+		refresh = 15 * time.Minute
+	}
+	ticker := time.NewTicker(refresh)
+	defer ticker.Stop()
+
+	var watched []string
+	var watchers sync.WaitGroup
+	stop := func() {}
+	defer func() {
+		stop()
+		watchers.Wait()
+	}()
+
+	for {
+		relays, err := e.inboxRelays(ownerPubkey)
+		if err != nil {
+			fmt.Printf("[SYNC] ⚠ Inbox relay lookup failed: %v\n", err)
+		} else if relays = sortedRelays(relays); !slices.Equal(relays, watched) {
+			stop()
+			watchers.Wait()
+
+			ctx, cancel := context.WithCancel(e.ctx)
+			stop = cancel
+			watched = relays
+			fmt.Printf("[SYNC] Watching %d inbox relays for mentions\n", len(relays))
+			for _, relay := range relays {
+				watchers.Add(1)
+				go func(relay string) {
+					defer watchers.Done()
+					e.watchInboxRelay(ctx, relay, ownerPubkey)
+				}(relay)
+			}
+		}
+
+		select {
+		case <-e.ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// sortedRelays returns a sorted copy of relays without duplicates
+func sortedRelays(relays []string) []string {
+	sorted := slices.Clone(relays)
+	slices.Sort(sorted)
+	return slices.Compact(sorted)
+}
+
+// watchInboxRelay keeps the mention filter subscribed on one relay until ctx
+// ends. When the subscription fails or is closed it is reopened from the
+// newest mention seen, after relays.policy.backoff_ms if the relay keeps
+// failing.
+func (e *Engine) watchInboxRelay(ctx context.Context, relay, ownerPubkey string) {
+	kinds := e.filterBuilder.BuildInboxFilter(ownerPubkey, 0).Kinds
+	if len(kinds) == 0 {
+		return // No interaction kinds enabled
+	}
+	since, _ := e.cursors.GetSinceCursorForRelay(ctx, relay, kinds)
+
+	failures := 0
+	for ctx.Err() == nil {
+		latest, ok := e.subscribeInbox(ctx, relay, e.filterBuilder.BuildInboxFilter(ownerPubkey, since))
+		if latest > since {
+			since = latest
+		}
+		if ok {
+			failures = 0
+		} else {
+			failures++
+		}
+
+		wait := max(inboxRetryDelay, relayBackoff(e.config.Relays.Policy.BackoffMs, failures))
+		select {
+		case <-ctx.Done():
+		case <-time.After(wait):
+		}
+	}
+}
+
+// subscribeInbox holds a mention subscription on a relay until ctx ends or the
+// relay closes it. It returns the newest created_at received and whether
+// the relay could be reached. The subscription takes no
+// relays.policy.max_concurrent_subs slot: held for as long as nophr runs, the
+// watches would leave sync without slots. Discovery caps the inbox relays at
+// discovery.max_relays_per_author.
+func (e *Engine) subscribeInbox(ctx context.Context, relay string, filter nostr.Filter) (int64, bool) {
+	ctx = e.nostrClient.Subscriptions().Exempt(ctx)
+
+	latency, err := e.nostrClient.Connect(relay)
+	if err != nil {
+		if ctx.Err() == nil {
+			fmt.Printf("[SYNC] ⚠ Failed to connect to inbox relay %s: %v\n", relay, err)
+			e.recordRelayFailure(relay, err)
+		}
+		return 0, false
+	}
+
+	e.relayStats.SubscriptionStarted(relay)
+	defer e.relayStats.SubscriptionEnded(relay)
+
+	var latest int64
+	var eventCount int64
+	for event := range e.nostrClient.SubscribeEvents(ctx, []string{relay}, nostr.Filters{filter}) {
+		eventCount++
+		e.relayStats.EventReceived(relay)
+		if int64(event.CreatedAt) > latest {
+			latest = int64(event.CreatedAt)
+		}
+		if !e.queue.push(relay, event, e.importing) {
+			break // Engine stopping
+		}
+	}
+	if ctx.Err() == nil {
+		fmt.Printf("[SYNC] Inbox subscription to %s closed after %d events, reopening\n", relay, eventCount)
+	}
+	e.recordRelaySuccess(relay, latency, eventCount)
+	return latest, true
+}
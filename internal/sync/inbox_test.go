@@ -0,0 +1,30 @@
+package sync
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/sandwich/nophr/internal/config"
+)
+
+func TestInboxRelaysConfigured(t *testing.T) {
+	cfg := config.Default()
+	cfg.Sync.Scope.InboxRelays = []string{"wss://inbox.example"}
+	e := &Engine{config: cfg}
+
+	// Configured inbox relays are used without looking up the owner's list
+	relays, err := e.inboxRelays("owner")
+	if err != nil {
+		t.Fatalf("inboxRelays() error = %v", err)
+	}
+	if !slices.Equal(relays, []string{"wss://inbox.example"}) {
+		t.Errorf("inboxRelays() = %v, want the configured relays", relays)
+	}
+}
+
+func TestSortedRelays(t *testing.T) {
+	got := sortedRelays([]string{"wss://b", "wss://a", "wss://b"})
+	if !slices.Equal(got, []string{"wss://a", "wss://b"}) {
+		t.Errorf("sortedRelays() = %v", got)
+	}
+}
@@ -0,0 +1,215 @@
+package sync
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/nbd-wtf/go-nostr"
+)
+
+// ingestQueue feeds events from relay subscriptions to the event workers.
+// Each relay gets its own bounded queue and the workers take from them in
+// turn, so a relay that floods events, or storage falling behind, fills that
+// relay's queue instead of stalling every subscription. Events already
+// queued from another relay are not queued twice.
+type ingestQueue struct {
+	mu       sync.Mutex
+	cond     *sync.Cond
+	capacity int // Events queued per relay
+
+	relays map[string]*relayQueue
+	order  []string // Relays with a queue, taken from round-robin
+	next   int
+
+	queued map[string]bool // IDs of queued events
+	closed bool
+
+	duplicates int64
+	dropped    int64
+}
+
+// relayQueue holds the events one relay delivered that wait for a worker
+type relayQueue struct {
+	events []*nostr.Event
+}
+
+// IngestStats is a snapshot of the ingest queue
+type IngestStats struct {
+	Depth      int            // Events waiting for a worker
+	Capacity   int            // Events queued per relay before new ones are dropped
+	Relays     map[string]int // Events waiting, by relay
+	Duplicates int64          // Events not queued because another relay's copy was waiting
+	Dropped    int64          // Events dropped because their relay's queue was full
+}
+
+// newIngestQueue creates a queue holding up to capacity events per relay
+func newIngestQueue(capacity int) *ingestQueue {
+	if capacity <= 0 {
+		capacity = 1000 // Safety fallback
+	}
+	q := &ingestQueue{
+		capacity: capacity,
+		relays:   make(map[string]*relayQueue),
+		queued:   make(map[string]bool),
+	}
+	q.cond = sync.NewCond(&q.mu)
+	return q
+}
+
+// push queues an event received from relay. When the relay's queue is full
+// the event is dropped, or with wait set, push blocks until a worker makes
+// room. It returns false once the queue is closed.
+func (q *ingestQueue) push(relay string, event *nostr.Event, wait bool) bool {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if q.closed {
+		return false
+	}
+	if q.queued[event.ID] {
+		q.duplicates++
+		return true
+	}
+
+	rq := q.relays[relay]
+	if rq == nil {
+		rq = &relayQueue{}
+		q.relays[relay] = rq
+		q.order = append(q.order, relay)
+	}
+
+	for len(rq.events) >= q.capacity {
+		if !wait {
+			// Dropped events are requested again by a later sync pass
+			q.dropped++
+			return true
+		}
+		q.cond.Wait()
+		if q.closed {
+			return false
+		}
+	}
+
+	rq.events = append(rq.events, event)
+	q.queued[event.ID] = true
+	q.cond.Broadcast()
+	return true
+}
+
+// pop takes the next event, going round the relays so each gets a turn. It
+// blocks while every queue is empty and returns false once the queue is
+// closed and drained.
+func (q *ingestQueue) pop() (*nostr.Event, bool) {
+	batch, ok := q.popBatch(1, 0)
+	if !ok {
+		return nil, false
+	}
+	return batch[0], true
+}
+
+// popBatch takes up to max events, going round the relays. It blocks while
+// every queue is empty, then waits up to window for more events until the
+// batch is full, so a burst is stored in one transaction while a lone event
+// is only held back briefly. It returns false once the queue is closed and
+// drained.
+func (q *ingestQueue) popBatch(max int, window time.Duration) ([]*nostr.Event, bool) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	var batch []*nostr.Event
+	var deadline time.Time
+	for {
+		for len(batch) < max {
+			event, ok := q.take()
+			if !ok {
+				break
+			}
+			batch = append(batch, event)
+		}
+
+		if len(batch) > 0 && deadline.IsZero() && window > 0 && len(batch) < max {
+			deadline = time.Now().Add(window)
+			// Wake this worker when the window ends even if nothing arrives
+			timer := time.AfterFunc(window, func() {
+				q.mu.Lock()
+				defer q.mu.Unlock()
+				q.cond.Broadcast()
+			})
+			defer timer.Stop()
+		}
+
+		full := len(batch) >= max
+		expired := len(batch) > 0 && !time.Now().Before(deadline)
+		if full || expired || q.closed {
+			return batch, len(batch) > 0
+		}
+		q.cond.Wait()
+	}
+}
+
+// take removes the next event from the relays' queues in turn, or returns
+// false when they are all empty. q.mu must be held.
+func (q *ingestQueue) take() (*nostr.Event, bool) {
+	for range len(q.order) {
+		relay := q.order[q.next%len(q.order)]
+		q.next = (q.next + 1) % len(q.order)
+
+		rq := q.relays[relay]
+		if len(rq.events) == 0 {
+			continue
+		}
+		event := rq.events[0]
+		rq.events[0] = nil
+		rq.events = rq.events[1:]
+		delete(q.queued, event.ID)
+		q.cond.Broadcast() // Wake pushers waiting for room
+		return event, true
+	}
+	return nil, false
+}
+
+// close stops new events from being queued and wakes every waiting worker
+// and pusher. Events already queued are still handed out.
+func (q *ingestQueue) close() {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	q.closed = true
+	q.cond.Broadcast()
+}
+
+// stats returns a snapshot of the queue depths and counters
+func (q *ingestQueue) stats() IngestStats {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	stats := IngestStats{
+		Capacity:   q.capacity,
+		Relays:     make(map[string]int, len(q.relays)),
+		Duplicates: q.duplicates,
+		Dropped:    q.dropped,
+	}
+	for relay, rq := range q.relays {
+		stats.Depth += len(rq.events)
+		if len(rq.events) > 0 {
+			stats.Relays[relay] = len(rq.events)
+		}
+	}
+	return stats
+}
+
+// BusiestRelays returns the relays with the most events waiting, most first
+func (s IngestStats) BusiestRelays(n int) []string {
+	relays := make([]string, 0, len(s.Relays))
+	for relay := range s.Relays {
+		relays = append(relays, relay)
+	}
+	sort.Slice(relays, func(i, j int) bool {
+		if s.Relays[relays[i]] != s.Relays[relays[j]] {
+			return s.Relays[relays[i]] > s.Relays[relays[j]]
+		}
+		return relays[i] < relays[j]
+	})
+	return relays[:min(n, len(relays))]
+}
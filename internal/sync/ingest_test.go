@@ -0,0 +1,187 @@
+package sync
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/nbd-wtf/go-nostr"
+	"github.com/sandwich/nophr/internal/config"
+	"github.com/sandwich/nophr/internal/storage"
+)
+
+func queuedEvent(id string) *nostr.Event {
+	return &nostr.Event{ID: id, PubKey: strings.Repeat("a", 64), Kind: 1}
+}
+
+func TestIngestQueueRoundRobin(t *testing.T) {
+	q := newIngestQueue(10)
+	for i := range 3 {
+		q.push("wss://busy", queuedEvent(fmt.Sprintf("busy-%d", i)), false)
+	}
+	q.push("wss://quiet", queuedEvent("quiet-0"), false)
+
+	// The quiet relay's event doesn't wait behind the busy relay's backlog
+	var got []string
+	for range 4 {
+		event, ok := q.pop()
+		if !ok {
+			t.Fatal("pop() returned false with events queued")
+		}
+		got = append(got, event.ID)
+	}
+	want := []string{"busy-0", "quiet-0", "busy-1", "busy-2"}
+	if fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("pop order = %v, want %v", got, want)
+	}
+}
+
+func TestIngestQueuePressure(t *testing.T) {
+	q := newIngestQueue(2)
+
+	q.push("wss://a", queuedEvent("1"), false)
+	q.push("wss://b", queuedEvent("1"), false) // Same event from another relay
+	q.push("wss://a", queuedEvent("2"), false)
+	q.push("wss://a", queuedEvent("3"), false) // wss://a is full
+	q.push("wss://b", queuedEvent("4"), false) // wss://b still has room
+
+	stats := q.stats()
+	if stats.Depth != 3 || stats.Duplicates != 1 || stats.Dropped != 1 {
+		t.Errorf("stats = %+v, want depth 3, 1 duplicate, 1 dropped", stats)
+	}
+	if stats.Relays["wss://a"] != 2 || stats.Relays["wss://b"] != 1 {
+		t.Errorf("relay depths = %v", stats.Relays)
+	}
+	if busiest := stats.BusiestRelays(1); len(busiest) != 1 || busiest[0] != "wss://a" {
+		t.Errorf("BusiestRelays(1) = %v", busiest)
+	}
+
+	// Once popped, an event can be queued again
+	q.pop()
+	q.push("wss://b", queuedEvent("1"), false)
+	if stats := q.stats(); stats.Depth != 3 || stats.Duplicates != 1 {
+		t.Errorf("after re-push, stats = %+v", stats)
+	}
+}
+
+func TestIngestQueueWait(t *testing.T) {
+	q := newIngestQueue(1)
+	q.push("wss://a", queuedEvent("1"), true)
+
+	pushed := make(chan bool)
+	go func() { pushed <- q.push("wss://a", queuedEvent("2"), true) }()
+
+	select {
+	case <-pushed:
+		t.Fatal("push(wait) returned while the relay's queue was full")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	q.pop()
+	if ok := <-pushed; !ok {
+		t.Error("push(wait) = false after a worker made room")
+	}
+	if stats := q.stats(); stats.Dropped != 0 || stats.Depth != 1 {
+		t.Errorf("stats = %+v, want nothing dropped and one waiting", stats)
+	}
+}
+
+func TestIngestQueueClose(t *testing.T) {
+	q := newIngestQueue(1)
+	q.push("wss://a", queuedEvent("1"), false)
+
+	// A pusher waiting for room and a worker waiting for events are both released
+	blocked := make(chan bool)
+	go func() { blocked <- q.push("wss://a", queuedEvent("2"), true) }()
+	time.Sleep(20 * time.Millisecond)
+	q.close()
+
+	if ok := <-blocked; ok {
+		t.Error("push(wait) = true after close")
+	}
+	if q.push("wss://b", queuedEvent("3"), false) {
+		t.Error("push() = true after close")
+	}
+
+	// Queued events are still handed out, then pop reports the queue done
+	if event, ok := q.pop(); !ok || event.ID != "1" {
+		t.Errorf("pop() after close = %v, %v; want the queued event", event, ok)
+	}
+	if _, ok := q.pop(); ok {
+		t.Error("pop() = true on a closed, drained queue")
+	}
+}
+
+func TestIngestQueueBatch(t *testing.T) {
+	q := newIngestQueue(10)
+	for i := range 3 {
+		q.push("wss://a", queuedEvent(fmt.Sprintf("a-%d", i)), false)
+	}
+
+	// A full batch is returned without waiting for the window
+	start := time.Now()
+	batch, ok := q.popBatch(2, time.Hour)
+	if !ok || len(batch) != 2 || time.Since(start) > time.Second {
+		t.Fatalf("popBatch(2) = %d events, %v", len(batch), ok)
+	}
+
+	// A partial batch waits for events arriving within the window
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		q.push("wss://b", queuedEvent("b-0"), false)
+	}()
+	batch, ok = q.popBatch(5, 200*time.Millisecond)
+	if !ok || len(batch) != 2 {
+		t.Fatalf("popBatch(5) = %d events, %v; want the queued and the late event", len(batch), ok)
+	}
+	if batch[0].ID != "a-2" || batch[1].ID != "b-0" {
+		t.Errorf("popBatch order = %s, %s", batch[0].ID, batch[1].ID)
+	}
+
+	// Once closed and drained, no batch is returned
+	q.close()
+	if _, ok := q.popBatch(5, time.Hour); ok {
+		t.Error("popBatch() = true on a closed, drained queue")
+	}
+}
+
+func TestEngineStopWhileRelaysDeliver(t *testing.T) {
+	st, err := storage.New(context.Background(), &config.Storage{
+		Driver:     "sqlite",
+		SQLitePath: filepath.Join(t.TempDir(), "test.db"),
+	})
+	if err != nil {
+		t.Fatalf("Failed to create storage: %v", err)
+	}
+	defer st.Close()
+
+	engine := NewEngine(st, config.Default())
+	engine.workers = engine.startWorkers()
+	engine.wg.Add(1)
+	go engine.processAggregates()
+
+	// Subscriptions still delivering while the engine stops must not panic
+	done := make(chan struct{})
+	for r := range 4 {
+		go func() {
+			defer func() { done <- struct{}{} }()
+			for i := 0; ; i++ {
+				if !engine.queue.push(fmt.Sprintf("wss://relay%d", r), queuedEvent(fmt.Sprintf("%032d%032d", r, i)), false) {
+					return
+				}
+			}
+		}()
+	}
+
+	time.Sleep(20 * time.Millisecond)
+	engine.Stop()
+	for range 4 {
+		<-done
+	}
+
+	// Events ingested after Stop skip the closed aggregate queue
+	engine.enqueueAggregate(&AggregateUpdate{Type: "reply", EventID: "x"})
+}
@@ -0,0 +1,90 @@
+package sync
+
+import (
+	"sync"
+	"unicode/utf8"
+
+	"github.com/nbd-wtf/go-nostr"
+	"github.com/sandwich/nophr/internal/config"
+)
+
+// IngestLimiter enforces sync.limits on events before they are stored and counts
+// the events it rejects or truncates
+type IngestLimiter struct {
+	config   *config.IngestLimits
+	ownerHex string // The owner's events are never limited
+
+	mu        sync.Mutex
+	rejected  map[int]int64 // By kind
+	truncated map[int]int64 // By kind
+}
+
+// NewIngestLimiter creates a limiter for the given limits
+func NewIngestLimiter(cfg *config.IngestLimits, ownerHex string) *IngestLimiter {
+	return &IngestLimiter{
+		config:    cfg,
+		ownerHex:  ownerHex,
+		rejected:  make(map[int]int64),
+		truncated: make(map[int]int64),
+	}
+}
+
+// Allow reports whether an event may be stored. With action "truncate" an oversized
+// event is cut down to the limits in place and allowed; its signature no longer
+// verifies afterwards, so only the content shown by nophr is affected.
+func (l *IngestLimiter) Allow(event *nostr.Event) bool {
+	if event.PubKey == l.ownerHex {
+		return true
+	}
+
+	limit := l.config.For(event.Kind)
+	contentOver := limit.MaxContentBytes > 0 && len(event.Content) > limit.MaxContentBytes
+	tagsOver := limit.MaxTags > 0 && len(event.Tags) > limit.MaxTags
+	if !contentOver && !tagsOver {
+		return true
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.config.Action != "truncate" {
+		l.rejected[event.Kind]++
+		return false
+	}
+
+	if contentOver {
+		event.Content = truncateUTF8(event.Content, limit.MaxContentBytes)
+	}
+	if tagsOver {
+		event.Tags = event.Tags[:limit.MaxTags]
+	}
+	l.truncated[event.Kind]++
+	return true
+}
+
+// Counts returns the number of events rejected and truncated since startup, by kind
+func (l *IngestLimiter) Counts() (rejected, truncated map[int]int64) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	rejected = make(map[int]int64, len(l.rejected))
+	for kind, n := range l.rejected {
+		rejected[kind] = n
+	}
+	truncated = make(map[int]int64, len(l.truncated))
+	for kind, n := range l.truncated {
+		truncated[kind] = n
+	}
+	return rejected, truncated
+}
+
+// truncateUTF8 cuts s to at most n bytes without splitting a character
+func truncateUTF8(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	for n > 0 && !utf8.RuneStart(s[n]) {
+		n--
+	}
+	return s[:n]
+}
@@ -0,0 +1,65 @@
+package sync
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/nbd-wtf/go-nostr"
+	"github.com/sandwich/nophr/internal/config"
+)
+
+func TestIngestLimiter(t *testing.T) {
+	limits := &config.IngestLimits{
+		MaxContentBytes: 10,
+		MaxTags:         2,
+		Kinds:           map[int]config.KindLimit{30023: {MaxContentBytes: 100}},
+		Action:          "reject",
+	}
+	limiter := NewIngestLimiter(limits, "owner")
+	threeTags := nostr.Tags{{"p", "a"}, {"p", "b"}, {"p", "c"}}
+
+	tests := []struct {
+		name  string
+		event *nostr.Event
+		want  bool
+	}{
+		{"within limits", &nostr.Event{Kind: 1, PubKey: "a", Content: "short"}, true},
+		{"content too long", &nostr.Event{Kind: 1, PubKey: "a", Content: strings.Repeat("x", 11)}, false},
+		{"too many tags", &nostr.Event{Kind: 7, PubKey: "a", Tags: threeTags}, false},
+		{"per-kind limit", &nostr.Event{Kind: 30023, PubKey: "a", Content: strings.Repeat("x", 50), Tags: threeTags}, true},
+		{"owner exempt", &nostr.Event{Kind: 1, PubKey: "owner", Content: strings.Repeat("x", 11)}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := limiter.Allow(tt.event); got != tt.want {
+				t.Errorf("Allow() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	rejected, truncated := limiter.Counts()
+	if rejected[1] != 1 || rejected[7] != 1 || len(truncated) != 0 {
+		t.Errorf("Unexpected counts: rejected %v, truncated %v", rejected, truncated)
+	}
+}
+
+func TestIngestLimiterTruncate(t *testing.T) {
+	limits := &config.IngestLimits{MaxContentBytes: 5, MaxTags: 1, Action: "truncate"}
+	limiter := NewIngestLimiter(limits, "owner")
+
+	event := &nostr.Event{Kind: 1, PubKey: "a", Content: "abcd€fgh", Tags: nostr.Tags{{"p", "a"}, {"p", "b"}}}
+	if !limiter.Allow(event) {
+		t.Fatal("Expected a truncated event to be allowed")
+	}
+	if event.Content != "abcd" {
+		t.Errorf("Expected content cut before the multi-byte character, got %q", event.Content)
+	}
+	if len(event.Tags) != 1 {
+		t.Errorf("Expected 1 tag, got %d", len(event.Tags))
+	}
+
+	if _, truncated := limiter.Counts(); truncated[1] != 1 {
+		t.Errorf("Expected one truncated kind 1 event, got %v", truncated)
+	}
+}
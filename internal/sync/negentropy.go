@@ -16,6 +16,7 @@ import (
 type NegentropyStore struct {
 	storage *storage.Storage
 	ctx     context.Context
+	limiter *IngestLimiter // Optional sync.limits enforcement
 }
 
 // NewNegentropyStore creates a new adapter wrapping nophr storage
@@ -64,6 +65,9 @@ func (s *NegentropyStore) QueryEvents(ctx context.Context, filter nostr.Filter)
 
 // SaveEvent implements eventstore.Store interface
 func (s *NegentropyStore) SaveEvent(ctx context.Context, event *nostr.Event) error {
+	if s.limiter != nil && !s.limiter.Allow(event) {
+		return nil // Oversized; skipped like in processEvent
+	}
 	return s.storage.StoreEvent(ctx, event)
 }
 
@@ -102,6 +106,7 @@ func (e *Engine) NegentropySync(ctx context.Context, relayURL string, filter nos
 
 	// Create negentropy store adapter
 	store := NewNegentropyStore(e.storage, ctx)
+	store.limiter = e.limiter
 	relayWrapper := &eventstore.RelayWrapper{Store: store}
 
 	// Attempt negentropy sync (DOWN direction = fetch missing events from relay)
@@ -0,0 +1,42 @@
+package sync
+
+import (
+	"fmt"
+)
+
+// RunOnce bootstraps and runs a single sync pass over the outbox and inbox
+// relays, then refreshes replaceable events. It returns once every relay has
+// finished and the fetched events and aggregate updates are stored, and the
+// engine can't be used afterwards. It is used instead of Start for one-shot
+// backfills; follows added since the last run are backfilled during bootstrap.
+func (e *Engine) RunOnce() error {
+	defer e.cancel()
+
+	// Aggregate updates wait for room rather than being dropped, and the
+	// workers don't start backfills that would outlive the pass
+	e.importing = true
+
+	if err := e.bootstrap(); err != nil {
+		return fmt.Errorf("bootstrap failed: %w", err)
+	}
+
+	e.workers = e.startWorkers()
+	e.wg.Add(1)
+	go e.processAggregates()
+
+	err := e.syncOnce()
+	e.relaySyncs.Wait()
+	if err == nil {
+		if err = e.refreshReplaceables(); err != nil {
+			err = fmt.Errorf("refresh failed: %w", err)
+		}
+	}
+
+	// Drain the workers before the aggregate worker, which they feed
+	e.queue.close()
+	e.workers.Wait()
+	e.closeAggregates()
+	e.wg.Wait()
+
+	return err
+}
@@ -0,0 +1,89 @@
+package sync
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/nbd-wtf/go-nostr"
+	"github.com/sandwich/nophr/internal/storage"
+)
+
+// maxRelayBackoff caps how long a failing relay is skipped between attempts
+const maxRelayBackoff = 24 * time.Hour
+
+// relayBackoff returns how long to wait before trying a relay again after
+// failures consecutive failed connects. The wait follows relays.policy.backoff_ms,
+// then doubles with each further failure up to maxRelayBackoff. An empty
+// schedule never backs off.
+func relayBackoff(schedule []int, failures int) time.Duration {
+	if failures <= 0 || len(schedule) == 0 {
+		return 0
+	}
+	if failures <= len(schedule) {
+		return time.Duration(schedule[failures-1]) * time.Millisecond
+	}
+
+	wait := time.Duration(schedule[len(schedule)-1]) * time.Millisecond
+	for i := len(schedule); i < failures; i++ {
+		wait *= 2
+		if wait >= maxRelayBackoff {
+			return maxRelayBackoff
+		}
+	}
+	return wait
+}
+
+// relayRetryAt returns when a relay may be tried again, or the zero time if
+// it isn't backing off
+func (e *Engine) relayRetryAt(health *storage.RelayHealth) time.Time {
+	if health == nil || health.LastFailure == nil {
+		return time.Time{}
+	}
+	wait := relayBackoff(e.config.Relays.Policy.BackoffMs, health.ConsecutiveFailures)
+	if wait == 0 {
+		return time.Time{}
+	}
+	return health.LastFailure.Add(wait)
+}
+
+// skipBackingOff drops the relays that failed recently enough to still be
+// backing off. If every relay is, they are all kept, so sync never stalls.
+func (e *Engine) skipBackingOff(relays []string) []string {
+	health, err := e.storage.GetRelayHealth(e.ctx)
+	if err != nil {
+		fmt.Printf("[SYNC] ⚠ Failed to load relay health: %v\n", err)
+		return relays
+	}
+
+	now := time.Now()
+	kept := make([]string, 0, len(relays))
+	for _, relay := range relays {
+		if retryAt := e.relayRetryAt(health[nostr.NormalizeURL(relay)]); retryAt.After(now) {
+			continue
+		}
+		kept = append(kept, relay)
+	}
+
+	if len(kept) == 0 {
+		return relays
+	}
+	if skipped := len(relays) - len(kept); skipped > 0 {
+		fmt.Printf("[SYNC] Skipping %d relays backing off after failed connects\n", skipped)
+	}
+	return kept
+}
+
+// recordRelaySuccess records a sync that connected to a relay
+func (e *Engine) recordRelaySuccess(relay string, latency time.Duration, events int64) {
+	if err := e.storage.RecordRelaySuccess(e.ctx, nostr.NormalizeURL(relay), latency, events, time.Now()); err != nil {
+		fmt.Printf("[SYNC] ⚠ %v\n", err)
+	}
+}
+
+// recordRelayFailure records a sync that couldn't connect to a relay
+func (e *Engine) recordRelayFailure(relay string, cause error) {
+	e.relayStats.RecordError(relay, cause)
+	if err := e.storage.RecordRelayFailure(e.ctx, nostr.NormalizeURL(relay), cause.Error(), time.Now()); err != nil {
+		fmt.Printf("[SYNC] ⚠ %v\n", err)
+	}
+}
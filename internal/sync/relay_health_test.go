@@ -0,0 +1,64 @@
+package sync
+
+import (
+	"context"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/sandwich/nophr/internal/config"
+)
+
+func TestRelayBackoff(t *testing.T) {
+	schedule := []int{500, 1500, 5000}
+
+	tests := []struct {
+		failures int
+		want     time.Duration
+	}{
+		{0, 0},
+		{1, 500 * time.Millisecond},
+		{3, 5 * time.Second},
+		{4, 10 * time.Second},
+		{6, 40 * time.Second},
+		{40, maxRelayBackoff},
+	}
+	for _, tt := range tests {
+		if got := relayBackoff(schedule, tt.failures); got != tt.want {
+			t.Errorf("relayBackoff(%d) = %v, want %v", tt.failures, got, tt.want)
+		}
+	}
+
+	if got := relayBackoff(nil, 10); got != 0 {
+		t.Errorf("relayBackoff without a schedule = %v, want 0", got)
+	}
+}
+
+func TestSkipBackingOff(t *testing.T) {
+	_, st, cleanup := setupTestCursorManager(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	cfg := &config.Config{}
+	cfg.Relays.Policy.BackoffMs = []int{60_000}
+	e := &Engine{config: cfg, storage: st, ctx: ctx, relayStats: NewRelayTracker()}
+
+	e.recordRelaySuccess("wss://good.test", 100*time.Millisecond, 10)
+	e.recordRelayFailure("wss://dead.test", context.DeadlineExceeded)
+
+	relays := []string{"wss://good.test", "wss://dead.test", "wss://new.test"}
+	if got := e.skipBackingOff(relays); !slices.Equal(got, []string{"wss://good.test", "wss://new.test"}) {
+		t.Errorf("skipBackingOff() = %v, want the dead relay skipped", got)
+	}
+
+	// With nothing else left, failing relays are still tried
+	if got := e.skipBackingOff([]string{"wss://dead.test"}); len(got) != 1 {
+		t.Errorf("skipBackingOff() = %v, want the only relay kept", got)
+	}
+
+	// Without a schedule nothing backs off
+	cfg.Relays.Policy.BackoffMs = nil
+	if got := e.skipBackingOff(relays); len(got) != len(relays) {
+		t.Errorf("skipBackingOff() without backoff = %v", got)
+	}
+}
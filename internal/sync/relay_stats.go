@@ -0,0 +1,145 @@
+package sync
+
+import (
+	"sync"
+	"time"
+)
+
+// relayActivity tracks runtime activity for a single relay
+type relayActivity struct {
+	activeSubs  int
+	lastConnect *time.Time
+	lastEvent   *time.Time
+	lastError   error
+	events      int64
+}
+
+// RelayTracker records per-relay sync activity and the overall ingest rate
+type RelayTracker struct {
+	mu     sync.RWMutex
+	relays map[string]*relayActivity
+
+	// Per-second ingest counts for the events/min calculation, indexed by
+	// unix second modulo the window size
+	ingested [rateWindow]rateBucket
+}
+
+// rateWindow is the number of one-second buckets kept for the ingest rate
+const rateWindow = 60
+
+// rateBucket counts the events stored during one second
+type rateBucket struct {
+	second int64
+	count  int64
+}
+
+// NewRelayTracker creates a new relay tracker
+func NewRelayTracker() *RelayTracker {
+	return &RelayTracker{
+		relays: make(map[string]*relayActivity),
+	}
+}
+
+// get returns the activity entry for a relay, creating it if needed
+// Must be called with mu locked
+func (t *RelayTracker) get(url string) *relayActivity {
+	a, ok := t.relays[url]
+	if !ok {
+		a = &relayActivity{}
+		t.relays[url] = a
+	}
+	return a
+}
+
+// SubscriptionStarted marks a relay as connected
+func (t *RelayTracker) SubscriptionStarted(url string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	now := time.Now()
+	a := t.get(url)
+	a.activeSubs++
+	a.lastConnect = &now
+}
+
+// SubscriptionEnded marks the end of a subscription to a relay
+func (t *RelayTracker) SubscriptionEnded(url string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	a := t.get(url)
+	if a.activeSubs > 0 {
+		a.activeSubs--
+	}
+}
+
+// EventReceived records an event received from a relay
+func (t *RelayTracker) EventReceived(url string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	now := time.Now()
+	a := t.get(url)
+	a.events++
+	a.lastEvent = &now
+}
+
+// RecordError records the last error seen for a relay
+func (t *RelayTracker) RecordError(url string, err error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.get(url).lastError = err
+}
+
+// EventStored records a successfully stored event for rate tracking
+func (t *RelayTracker) EventStored() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	now := time.Now().Unix()
+	b := &t.ingested[now%rateWindow]
+	if b.second != now {
+		b.second = now
+		b.count = 0
+	}
+	b.count++
+}
+
+// EventsPerMinute returns the number of events stored in the last minute
+func (t *RelayTracker) EventsPerMinute() float64 {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	now := time.Now().Unix()
+	var total int64
+	for _, b := range t.ingested {
+		if now-b.second < rateWindow {
+			total += b.count
+		}
+	}
+	return float64(total)
+}
+
+// snapshot returns a copy of the activity for a relay
+func (t *RelayTracker) snapshot(url string) relayActivity {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	if a, ok := t.relays[url]; ok {
+		return *a
+	}
+	return relayActivity{}
+}
+
+// urls returns all relays that have recorded activity
+func (t *RelayTracker) urls() []string {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	urls := make([]string, 0, len(t.relays))
+	for url := range t.relays {
+		urls = append(urls, url)
+	}
+	return urls
+}
@@ -0,0 +1,43 @@
+package sync
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestRelayTracker(t *testing.T) {
+	tracker := NewRelayTracker()
+
+	tracker.SubscriptionStarted("wss://a")
+	tracker.SubscriptionStarted("wss://a")
+	tracker.EventReceived("wss://a")
+	tracker.EventReceived("wss://a")
+	tracker.SubscriptionEnded("wss://a")
+	tracker.RecordError("wss://b", errors.New("refused"))
+
+	a := tracker.snapshot("wss://a")
+	if a.activeSubs != 1 || a.events != 2 || a.lastConnect == nil || a.lastEvent == nil {
+		t.Errorf("wss://a activity = %+v, want 1 open subscription and 2 events", a)
+	}
+	b := tracker.snapshot("wss://b")
+	if b.lastError == nil || b.activeSubs != 0 {
+		t.Errorf("wss://b activity = %+v, want its error and no subscriptions", b)
+	}
+
+	// Ending more subscriptions than started doesn't go negative
+	tracker.SubscriptionEnded("wss://b")
+	if tracker.snapshot("wss://b").activeSubs != 0 {
+		t.Error("expected active subscriptions to stay at 0")
+	}
+
+	if urls := tracker.urls(); len(urls) != 2 {
+		t.Errorf("urls() = %v, want both relays", urls)
+	}
+
+	for range 5 {
+		tracker.EventStored()
+	}
+	if rate := tracker.EventsPerMinute(); rate != 5 {
+		t.Errorf("EventsPerMinute() = %v, want 5", rate)
+	}
+}
@@ -2,7 +2,14 @@ package sync
 
 import (
 	"context"
+	"fmt"
+	"slices"
+	"sort"
 	"time"
+
+	"github.com/nbd-wtf/go-nostr"
+	internalnostr "github.com/sandwich/nophr/internal/nostr"
+	"github.com/sandwich/nophr/internal/storage"
 )
 
 // RelayInfo contains information about a relay
@@ -10,7 +17,13 @@ type RelayInfo struct {
 	url         string
 	connected   bool
 	lastConnect *time.Time
+	lastEvent   *time.Time
 	lastError   error
+	events      int64
+	seed        bool
+	latency     internalnostr.RelayLatency
+	health      *storage.RelayHealth
+	retryAt     time.Time
 }
 
 // URL returns the relay URL
@@ -28,37 +41,123 @@ func (r *RelayInfo) LastConnectTime() *time.Time {
 	return r.lastConnect
 }
 
+// LastEventTime returns the last time an event was received from the relay
+func (r *RelayInfo) LastEventTime() *time.Time {
+	return r.lastEvent
+}
+
 // LastError returns the last error from the relay
 func (r *RelayInfo) LastError() error {
 	return r.lastError
 }
 
-// GetRelays returns information about all configured relays
+// EventsReceived returns the number of events received from the relay since startup
+func (r *RelayInfo) EventsReceived() int64 {
+	return r.events
+}
+
+// IsSeed returns whether the relay is in relays.seeds
+func (r *RelayInfo) IsSeed() bool {
+	return r.seed
+}
+
+// Latency returns the relay's connect and first-event latency histograms
+func (r *RelayInfo) Latency() internalnostr.RelayLatency {
+	return r.latency
+}
+
+// Health returns the relay's connection record across restarts, or nil if
+// it was never synced from
+func (r *RelayInfo) Health() *storage.RelayHealth {
+	return r.health
+}
+
+// BackingOffUntil returns when sync will try the relay again after failed
+// connects, or nil if it isn't backing off
+func (r *RelayInfo) BackingOffUntil() *time.Time {
+	if !r.retryAt.After(time.Now()) {
+		return nil
+	}
+	return &r.retryAt
+}
+
+// GetRelays returns information about all configured and active relays
 func (e *Engine) GetRelays() []*RelayInfo {
-	// Get relay URLs from discovery/client
-	relays := e.discovery.GetRelays()
+	// Merge configured relays with relays seen during sync
+	seen := make(map[string]bool)
+	var urls []string
+	for _, relay := range e.discovery.GetRelays() {
+		if !seen[relay.URL] {
+			seen[relay.URL] = true
+			urls = append(urls, relay.URL)
+		}
+	}
+	for _, url := range e.relayStats.urls() {
+		if !seen[url] {
+			seen[url] = true
+			urls = append(urls, url)
+		}
+	}
+	health, err := e.storage.GetRelayHealth(e.ctx)
+	if err != nil {
+		fmt.Printf("[SYNC] ⚠ Failed to load relay health: %v\n", err)
+	}
+	for url := range health {
+		if !seen[url] {
+			seen[url] = true
+			urls = append(urls, url)
+		}
+	}
+	sort.Strings(urls)
 
-	infos := make([]*RelayInfo, 0, len(relays))
-	for _, relay := range relays {
+	infos := make([]*RelayInfo, 0, len(urls))
+	for _, url := range urls {
+		activity := e.relayStats.snapshot(url)
 		info := &RelayInfo{
-			url:       relay.URL,
-			connected: relay.Connected,
+			url:         url,
+			connected:   activity.activeSubs > 0,
+			lastConnect: activity.lastConnect,
+			lastEvent:   activity.lastEvent,
+			lastError:   activity.lastError,
+			events:      activity.events,
+			seed:        slices.ContainsFunc(e.config.Relays.Seeds, func(seed string) bool { return nostr.NormalizeURL(seed) == nostr.NormalizeURL(url) }),
 		}
-
-		if relay.LastConnect != nil {
-			info.lastConnect = relay.LastConnect
+		if e.nostrClient != nil {
+			info.latency, _ = e.nostrClient.Latency().Get(nostr.NormalizeURL(url))
 		}
-
-		if relay.LastError != nil {
-			info.lastError = relay.LastError
+		if h, ok := health[nostr.NormalizeURL(url)]; ok {
+			info.health = h
+			info.retryAt = e.relayRetryAt(h)
 		}
-
 		infos = append(infos, info)
 	}
 
 	return infos
 }
 
+// EventsPerMinute returns the number of events stored during the last minute
+func (e *Engine) EventsPerMinute() float64 {
+	return e.relayStats.EventsPerMinute()
+}
+
+// IngestStats returns the depth of the queue between relay subscriptions and
+// the event workers, and how many events it dropped or deduplicated
+func (e *Engine) IngestStats() IngestStats {
+	return e.queue.stats()
+}
+
+// SubscriptionStats returns how many relay subscriptions are open and how many
+// are waiting for a slot under relays.policy.max_concurrent_subs
+func (e *Engine) SubscriptionStats() internalnostr.SubscriptionStats {
+	return e.nostrClient.Subscriptions().Stats()
+}
+
+// OversizedEvents returns the number of events rejected and truncated by sync.limits
+// since startup, by kind
+func (e *Engine) OversizedEvents() (rejected, truncated map[int]int64) {
+	return e.limiter.Counts()
+}
+
 // TotalSynced returns the total number of events synced
 func (e *Engine) TotalSynced(ctx context.Context) (int64, error) {
 	// Count all events in storage
@@ -0,0 +1,165 @@
+package sync
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/nbd-wtf/go-nostr"
+	"github.com/sandwich/nophr/internal/nostr/helpers"
+)
+
+// threadQueueSize bounds how many of the owner's threads wait to be completed.
+// Notes queued while it is full are skipped; their parents are looked for
+// again when another reply in the thread arrives.
+const threadQueueSize = 500
+
+// threadFetchTimeout bounds one lookup of a thread's missing events
+const threadFetchTimeout = 15 * time.Second
+
+// threadRelay is the queue name events found by thread completion are
+// stored under, since they may come from several relays at once
+const threadRelay = "thread-completion"
+
+// queueThreadCompletion queues a stored note for completeThreads when it is
+// in a thread the owner takes part in: written by the owner or replying to
+// them (sync.scope.include_threads_of_mine)
+func (e *Engine) queueThreadCompletion(event *nostr.Event) {
+	if e.threadQueue == nil || !helpers.ParseThreadRefs(event.Tags).IsReply() {
+		return
+	}
+	owner := ownerHex(e.config)
+	if event.PubKey != owner && event.Tags.FindWithValue("p", owner) == nil {
+		return
+	}
+
+	select {
+	case e.threadQueue <- event:
+	default:
+	}
+}
+
+// completeThreads fetches the roots and parents of the owner's threads that
+// aren't stored yet, with their authors' profiles, until the engine stops
+func (e *Engine) completeThreads() {
+	defer e.wg.Done()
+
+	for {
+		select {
+		case <-e.ctx.Done():
+			return
+		case event := <-e.threadQueue:
+			e.completeThread(event)
+		}
+	}
+}
+
+// completeThread fetches the root and parent of a reply if they are missing,
+// from the relays its tags hint at, their authors' outbox relays and the
+// seeds. What is found goes through the ingest queue like any synced event,
+// so a fetched parent that is itself a reply in the owner's thread is
+// completed in turn.
+func (e *Engine) completeThread(event *nostr.Event) {
+	missing := e.missingThreadRefs(event)
+	if len(missing) == 0 {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(e.ctx, threadFetchTimeout)
+	defer cancel()
+
+	relays := e.threadRelays(ctx, event, missing)
+	if len(relays) == 0 {
+		return
+	}
+	events, err := e.nostrClient.FetchEvents(ctx, relays, nostr.Filter{IDs: missing})
+	if err != nil {
+		fmt.Printf("[SYNC] ⚠ Failed to fetch thread events of %s: %v\n", event.ID[:16]+"...", err)
+		return
+	}
+
+	var authors []string
+	for _, fetched := range events {
+		if !slices.Contains(missing, fetched.ID) {
+			continue // Not what was asked for
+		}
+		if !e.queue.push(threadRelay, fetched, false) {
+			return // Engine stopping
+		}
+		if !slices.Contains(authors, fetched.PubKey) && !e.hasProfile(ctx, fetched.PubKey) {
+			authors = append(authors, fetched.PubKey)
+		}
+	}
+	fmt.Printf("[SYNC] Thread completion found %d of %d missing events for %s\n", len(events), len(missing), event.ID[:16]+"...")
+
+	if len(authors) == 0 {
+		return
+	}
+	profiles, err := e.nostrClient.FetchEvents(ctx, relays, nostr.Filter{Kinds: []int{0}, Authors: authors})
+	if err != nil {
+		return
+	}
+	for _, profile := range profiles {
+		if !e.queue.push(threadRelay, profile, false) {
+			return
+		}
+	}
+}
+
+// missingThreadRefs returns the root and parent of a reply that aren't
+// stored and haven't been looked for already
+func (e *Engine) missingThreadRefs(event *nostr.Event) []string {
+	refs := helpers.ParseThreadRefs(event.Tags)
+
+	var missing []string
+	for _, id := range []string{refs.Root, refs.ReplyTo} {
+		if id == "" || slices.Contains(missing, id) || e.threadLookups.Contains(id) {
+			continue
+		}
+		if exists, err := e.storage.EventExists(e.ctx, id); err != nil || exists {
+			continue
+		}
+		e.threadLookups.Add(id)
+		missing = append(missing, id)
+	}
+	return missing
+}
+
+// threadRelays returns where a reply's missing thread events are looked for:
+// the relay hints in its e tags, the outbox relays of the authors those tags
+// name and of the reply's author, and the seeds
+func (e *Engine) threadRelays(ctx context.Context, event *nostr.Event, missing []string) []string {
+	relays, authors := threadHints(event, missing)
+	for _, author := range append(authors, event.PubKey) {
+		outbox, err := e.discovery.GetOutboxRelays(ctx, author)
+		if err == nil {
+			relays = append(relays, outbox...)
+		}
+	}
+	relays = append(relays, e.nostrClient.GetSeedRelays()...)
+	return sortedRelays(relays)
+}
+
+// threadHints returns the relay hints and author pubkeys in the e tags that
+// reference ids
+func threadHints(event *nostr.Event, ids []string) (relays, authors []string) {
+	for _, tag := range event.Tags {
+		if len(tag) < 2 || tag[0] != "e" || !slices.Contains(ids, tag[1]) {
+			continue
+		}
+		if len(tag) >= 3 && nostr.IsValidRelayURL(tag[2]) {
+			relays = append(relays, nostr.NormalizeURL(tag[2]))
+		}
+		if len(tag) >= 5 && nostr.IsValidPublicKey(tag[4]) {
+			authors = append(authors, tag[4])
+		}
+	}
+	return relays, authors
+}
+
+// hasProfile reports whether a pubkey's kind 0 is stored
+func (e *Engine) hasProfile(ctx context.Context, pubkey string) bool {
+	profiles, err := e.storage.QueryEvents(ctx, nostr.Filter{Kinds: []int{0}, Authors: []string{pubkey}, Limit: 1})
+	return err != nil || len(profiles) > 0
+}
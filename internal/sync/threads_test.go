@@ -0,0 +1,59 @@
+package sync
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/nbd-wtf/go-nostr"
+	"github.com/nbd-wtf/go-nostr/nip19"
+	"github.com/sandwich/nophr/internal/config"
+)
+
+func TestQueueThreadCompletion(t *testing.T) {
+	owner := nostr.GeneratePrivateKey()
+	ownerPubkey, _ := nostr.GetPublicKey(owner)
+	cfg := config.Default()
+	cfg.Identity.Npub, _ = nip19.EncodePublicKey(ownerPubkey)
+	e := &Engine{config: cfg, threadQueue: make(chan *nostr.Event, 10)}
+
+	root := nostr.Tag{"e", "aa", "", "root"}
+	tests := []struct {
+		name  string
+		event *nostr.Event
+		want  bool
+	}{
+		{"owner's reply", &nostr.Event{PubKey: ownerPubkey, Tags: nostr.Tags{root}}, true},
+		{"reply to the owner", &nostr.Event{PubKey: "other", Tags: nostr.Tags{root, {"p", ownerPubkey}}}, true},
+		{"owner's root note", &nostr.Event{PubKey: ownerPubkey}, false},
+		{"unrelated reply", &nostr.Event{PubKey: "other", Tags: nostr.Tags{root, {"p", "someone"}}}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e.queueThreadCompletion(tt.event)
+			if got := len(e.threadQueue) == 1; got != tt.want {
+				t.Errorf("queued = %v, want %v", got, tt.want)
+			}
+			for len(e.threadQueue) > 0 {
+				<-e.threadQueue
+			}
+		})
+	}
+}
+
+func TestThreadHints(t *testing.T) {
+	author := nostr.GeneratePrivateKey()
+	authorPubkey, _ := nostr.GetPublicKey(author)
+	event := &nostr.Event{Tags: nostr.Tags{
+		{"e", "root", "wss://root.example", "root", authorPubkey},
+		{"e", "parent", "not a relay", "reply"},
+		{"e", "quoted", "wss://quoted.example", "mention"},
+	}}
+
+	relays, authors := threadHints(event, []string{"root", "parent"})
+	if !slices.Equal(relays, []string{"wss://root.example"}) {
+		t.Errorf("relays = %v", relays)
+	}
+	if !slices.Equal(authors, []string{authorPubkey}) {
+		t.Errorf("authors = %v", authors)
+	}
+}
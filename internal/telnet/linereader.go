@@ -0,0 +1,107 @@
+package telnet
+
+import (
+	"bufio"
+	"errors"
+	"io"
+	"unicode/utf8"
+)
+
+// maxLineLength bounds a line of input; selections and search queries are short
+const maxLineLength = 256
+
+// Telnet command bytes (RFC 854)
+const (
+	iac  = 255 // Interpret as command
+	sb   = 250 // Subnegotiation begin
+	se   = 240 // Subnegotiation end
+	will = 251 // WILL, WONT, DO and DONT take an option byte
+	dont = 254
+)
+
+var errLineTooLong = errors.New("line too long")
+
+// lineReader reads lines typed by a telnet or raw TCP client, dropping telnet
+// negotiation and applying backspaces the client didn't handle itself
+type lineReader struct {
+	reader *bufio.Reader
+}
+
+func newLineReader(r io.Reader) *lineReader {
+	return &lineReader{reader: bufio.NewReader(r)}
+}
+
+// ReadLine returns the next line without its terminator
+func (lr *lineReader) ReadLine() (string, error) {
+	var line []byte
+	tooLong := false
+
+	for {
+		b, err := lr.reader.ReadByte()
+		if err != nil {
+			return "", err
+		}
+
+		switch {
+		case b == iac:
+			literal, err := lr.skipCommand()
+			if err != nil {
+				return "", err
+			}
+			if !literal {
+				continue
+			}
+		case b == '\n':
+			if tooLong {
+				return "", errLineTooLong
+			}
+			return string(line), nil
+		case b == '\r' || b == 0:
+			continue
+		case b == '\b' || b == 0x7f:
+			if len(line) > 0 {
+				_, size := utf8.DecodeLastRune(line)
+				line = line[:len(line)-size]
+			}
+			continue
+		}
+
+		if len(line) >= maxLineLength {
+			tooLong = true
+			continue
+		}
+		line = append(line, b)
+	}
+}
+
+// skipCommand consumes a telnet command after IAC. It reports true for an
+// escaped 0xFF data byte, which the caller keeps.
+func (lr *lineReader) skipCommand() (bool, error) {
+	cmd, err := lr.reader.ReadByte()
+	if err != nil {
+		return false, err
+	}
+
+	switch {
+	case cmd == iac:
+		return true, nil
+	case cmd >= will && cmd <= dont:
+		_, err = lr.reader.ReadByte() // Option
+		return false, err
+	case cmd == sb:
+		// Subnegotiation runs until IAC SE
+		for {
+			b, err := lr.reader.ReadByte()
+			if err != nil {
+				return false, err
+			}
+			if b != iac {
+				continue
+			}
+			if b, err = lr.reader.ReadByte(); err != nil || b == se {
+				return false, err
+			}
+		}
+	}
+	return false, nil
+}
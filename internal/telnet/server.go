@@ -0,0 +1,165 @@
+// Package telnet serves a line-mode text BBS for telnet and plain TCP clients.
+//
+// Pages are the Gopher server's menus and text files. Selectable menu items
+// are numbered, and a session follows them by number, so any terminal that can
+// send a line of text can browse the site.
+package telnet
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/sandwich/nophr/internal/aggregates"
+	"github.com/sandwich/nophr/internal/config"
+	"github.com/sandwich/nophr/internal/gopher"
+	"github.com/sandwich/nophr/internal/idle"
+	"github.com/sandwich/nophr/internal/sections"
+	"github.com/sandwich/nophr/internal/security"
+	"github.com/sandwich/nophr/internal/storage"
+)
+
+// idleTimeout is how long a session may wait for input
+const idleTimeout = 10 * time.Minute
+
+// Server implements the telnet BBS
+type Server struct {
+	config      *config.TelnetProtocol
+	fullConfig  *config.Config
+	gopher      *gopher.Server // Renders pages; never listens
+	rateLimiter *security.ClientLimiter
+	idle        *idle.Monitor
+
+	listener net.Listener
+	wg       sync.WaitGroup
+	ctx      context.Context
+	cancel   context.CancelFunc
+}
+
+// New creates a new telnet server
+func New(cfg *config.TelnetProtocol, fullCfg *config.Config, st *storage.Storage, aggMgr *aggregates.Manager) *Server {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	return &Server{
+		config:     cfg,
+		fullConfig: fullCfg,
+		gopher:     gopher.New(&fullCfg.Protocols.Gopher, fullCfg, st, fullCfg.Protocols.Gopher.Host, aggMgr),
+		ctx:        ctx,
+		cancel:     cancel,
+	}
+}
+
+// Start starts the telnet server
+func (s *Server) Start() error {
+	addr := fmt.Sprintf("%s:%d", s.config.Bind, s.config.Port)
+
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return fmt.Errorf("failed to start telnet server: %w", err)
+	}
+
+	s.listener = listener
+	fmt.Printf("Telnet server listening on %s\n", addr)
+
+	s.wg.Add(1)
+	go s.acceptConnections()
+
+	return nil
+}
+
+// Stop stops the telnet server
+func (s *Server) Stop() error {
+	s.cancel()
+
+	if s.listener != nil {
+		s.listener.Close()
+	}
+
+	s.wg.Wait()
+	return nil
+}
+
+// acceptConnections accepts and handles incoming connections
+func (s *Server) acceptConnections() {
+	defer s.wg.Done()
+
+	for {
+		conn, err := s.listener.Accept()
+		if err != nil {
+			select {
+			case <-s.ctx.Done():
+				return
+			default:
+				fmt.Printf("Accept error: %v\n", err)
+				continue
+			}
+		}
+
+		s.wg.Add(1)
+		go s.handleConnection(conn)
+	}
+}
+
+// handleConnection runs one BBS session until the caller quits, idles out or the server stops
+func (s *Server) handleConnection(conn net.Conn) {
+	defer s.wg.Done()
+	defer conn.Close()
+
+	// Sessions are long-lived, so a shutdown closes them rather than waiting
+	stop := context.AfterFunc(s.ctx, func() { conn.Close() })
+	defer stop()
+
+	writer := bufio.NewWriter(conn)
+	s.idle.Touch()
+	if allowed, retryAfter := s.checkRateLimit(conn); !allowed {
+		fmt.Fprintf(writer, "Rate limit exceeded, try again in %d seconds\r\n", int(retryAfter.Seconds()+0.5))
+		writer.Flush()
+		return
+	}
+
+	fmt.Printf("Telnet session from %s\n", conn.RemoteAddr())
+	sess := &session{
+		server: s,
+		conn:   conn,
+		reader: newLineReader(conn),
+		writer: writer,
+	}
+	sess.run()
+}
+
+// checkRateLimit reports whether the client may open a session
+func (s *Server) checkRateLimit(conn net.Conn) (bool, time.Duration) {
+	if s.rateLimiter == nil {
+		return true, 0
+	}
+
+	ip := security.ClientIP(conn.RemoteAddr())
+	allowed, retryAfter := s.rateLimiter.Check(ip)
+	if !allowed {
+		fmt.Printf("Telnet rate limit exceeded for %s\n", ip)
+	}
+	return allowed, retryAfter
+}
+
+// GetSectionManager returns the section manager of the Gopher pages the BBS shows
+func (s *Server) GetSectionManager() *sections.Manager {
+	return s.gopher.GetSectionManager()
+}
+
+// SetSectionManager replaces the section manager of the Gopher pages the BBS shows
+func (s *Server) SetSectionManager(m *sections.Manager) {
+	s.gopher.SetSectionManager(m)
+}
+
+// SetRateLimiter sets the per-IP rate limiter applied to new sessions (nil disables rate limiting)
+func (s *Server) SetRateLimiter(rl *security.ClientLimiter) {
+	s.rateLimiter = rl
+}
+
+// SetIdleMonitor sets the monitor told about each request (nil disables idle shedding)
+func (s *Server) SetIdleMonitor(m *idle.Monitor) {
+	s.idle = m
+}
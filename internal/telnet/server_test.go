@@ -0,0 +1,129 @@
+package telnet
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"net"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/nbd-wtf/go-nostr"
+	"github.com/nbd-wtf/go-nostr/nip19"
+	"github.com/sandwich/nophr/internal/aggregates"
+	"github.com/sandwich/nophr/internal/config"
+	"github.com/sandwich/nophr/internal/storage"
+)
+
+func TestTelnetBBS(t *testing.T) {
+	ctx := context.Background()
+	cfg := config.Default()
+	cfg.Storage = config.Storage{Driver: "sqlite", SQLitePath: filepath.Join(t.TempDir(), "test.db")}
+	cfg.Site.Title = "Test BBS"
+
+	sk := nostr.GeneratePrivateKey()
+	pubkey, _ := nostr.GetPublicKey(sk)
+	cfg.Identity.Npub, _ = nip19.EncodePublicKey(pubkey)
+
+	st, err := storage.New(ctx, &cfg.Storage)
+	if err != nil {
+		t.Fatalf("Failed to create storage: %v", err)
+	}
+	defer st.Close()
+
+	note := &nostr.Event{CreatedAt: nostr.Now(), Kind: 1, Content: "Hello from the BBS"}
+	note.Sign(sk)
+	if err := st.StoreEvent(ctx, note); err != nil {
+		t.Fatalf("Failed to store event: %v", err)
+	}
+
+	telnetCfg := &config.TelnetProtocol{
+		Enabled:   true,
+		Port:      17023, // Use non-standard port for testing
+		Bind:      "localhost",
+		PageLines: -1,
+	}
+	server := New(telnetCfg, cfg, st, aggregates.NewManager(st, cfg))
+	if err := server.Start(); err != nil {
+		t.Fatalf("Failed to start server: %v", err)
+	}
+	defer server.Stop()
+
+	conn, err := net.DialTimeout("tcp", fmt.Sprintf("localhost:%d", telnetCfg.Port), 2*time.Second)
+	if err != nil {
+		t.Fatalf("Failed to connect: %v", err)
+	}
+	defer conn.Close()
+	conn.SetDeadline(time.Now().Add(10 * time.Second))
+	reader := bufio.NewReader(conn)
+
+	home := readScreen(t, reader)
+	for _, want := range []string{"Welcome to Test BBS", "  1: Notes\r\n", "  2: Articles\r\n"} {
+		if !strings.Contains(home, want) {
+			t.Errorf("Expected %q on the home screen, got:\n%s", want, home)
+		}
+	}
+
+	// Selections may arrive wrapped in telnet negotiation
+	fmt.Fprint(conn, "\xff\xfb\x1f1\r\n")
+	notes := readScreen(t, reader)
+	if !strings.Contains(notes, "Hello from the BBS") {
+		t.Errorf("Expected the note in the Notes menu, got:\n%s", notes)
+	}
+
+	fmt.Fprint(conn, "99\r\n")
+	if screen := readScreen(t, reader); !strings.Contains(screen, "No such choice") {
+		t.Errorf("Expected an out-of-range choice to be refused, got:\n%s", screen)
+	}
+
+	fmt.Fprint(conn, "b\r\n")
+	if screen := readScreen(t, reader); !strings.Contains(screen, "  1: Notes\r\n") {
+		t.Errorf("Expected back to return home, got:\n%s", screen)
+	}
+
+	fmt.Fprint(conn, "q\r\n")
+	line, _ := reader.ReadString('\n')
+	if line != "Goodbye!\r\n" {
+		t.Errorf("Expected a goodbye, got %q", line)
+	}
+}
+
+func TestLineReader(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{"12\r\n", "12"},
+		{"\xff\xfd\x01\xff\xfb\x03notes\r\x00\n", "notes"},
+		{"\xff\xfa\x18\x00xterm\xff\xf0home\n", "home"},
+		{"abd\bc\n", "abc"},
+		{"a\xff\xffb\n", "a\xffb"},
+	}
+
+	for _, tt := range tests {
+		got, err := newLineReader(strings.NewReader(tt.input)).ReadLine()
+		if err != nil || got != tt.want {
+			t.Errorf("ReadLine(%q) = %q, %v; want %q", tt.input, got, err, tt.want)
+		}
+	}
+
+	if _, err := newLineReader(strings.NewReader(strings.Repeat("x", maxLineLength+1) + "\n")).ReadLine(); err != errLineTooLong {
+		t.Errorf("Expected errLineTooLong, got %v", err)
+	}
+}
+
+// readScreen reads output up to and including the next prompt
+func readScreen(t *testing.T, reader *bufio.Reader) string {
+	t.Helper()
+	var screen strings.Builder
+	for !strings.HasSuffix(screen.String(), "> ") {
+		b, err := reader.ReadByte()
+		if err != nil {
+			t.Fatalf("Screen ended early: %v\n%s", err, screen.String())
+		}
+		screen.WriteByte(b)
+	}
+	return screen.String()
+}
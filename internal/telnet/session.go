@@ -0,0 +1,239 @@
+package telnet
+
+import (
+	"bufio"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/sandwich/nophr/internal/gopher"
+)
+
+// page is one screen in a session's history
+type page struct {
+	selector string
+	text     bool // A text file rather than a menu
+}
+
+// session is one caller's walk through the menus
+type session struct {
+	server *Server
+	conn   net.Conn
+	reader *lineReader
+	writer *bufio.Writer
+
+	history []page        // Current page last
+	items   []gopher.Item // Numbered choices on the current menu
+}
+
+// run greets the caller and answers their selections until they quit
+func (ss *session) run() {
+	title := ss.server.fullConfig.Site.Title
+	if title == "" {
+		title = "nophr"
+	}
+	ss.writeLines([]string{
+		"",
+		"Welcome to " + title,
+		strings.Repeat("=", len([]rune(title))+11),
+		"",
+		"Type a number to open an item. ? for help, q to quit.",
+		"",
+	})
+
+	ss.history = []page{{selector: "/"}}
+	ss.show()
+
+	for {
+		ss.prompt()
+		input, ok := ss.readLine()
+		if !ok {
+			return
+		}
+
+		switch command := strings.ToLower(strings.TrimSpace(input)); command {
+		case "q", "quit", "bye", "exit":
+			ss.writeLines([]string{"Goodbye!"})
+			ss.writer.Flush()
+			return
+		case "b", "back":
+			ss.back()
+		case "h", "home":
+			ss.history = ss.history[:1]
+			ss.show()
+		case "?", "help":
+			ss.help()
+		case "":
+			// Enter redraws a menu and leaves a text page
+			if ss.current().text {
+				ss.back()
+			} else {
+				ss.show()
+			}
+		default:
+			ss.choose(command)
+		}
+	}
+}
+
+// current returns the page being viewed
+func (ss *session) current() page {
+	return ss.history[len(ss.history)-1]
+}
+
+// back returns to the previous page
+func (ss *session) back() {
+	if len(ss.history) > 1 {
+		ss.history = ss.history[:len(ss.history)-1]
+	}
+	ss.show()
+}
+
+// choose follows the numbered item the caller picked
+func (ss *session) choose(command string) {
+	n, err := strconv.Atoi(command)
+	if err != nil || n < 1 || n > len(ss.items) || ss.current().text {
+		ss.writeLines([]string{"No such choice. Type ? for help."})
+		return
+	}
+
+	item := ss.items[n-1]
+	next := page{selector: item.Selector, text: item.Type == gopher.ItemTypeTextFile}
+	if item.Type == gopher.ItemTypeSearch {
+		ss.write(item.Display + ": ")
+		query, ok := ss.readLine()
+		if !ok || strings.TrimSpace(query) == "" {
+			return
+		}
+		next.selector += "\t" + strings.TrimSpace(query)
+	}
+
+	selector, err := ss.server.gopher.GetSanitizer().SanitizeAndValidateSelector(next.selector)
+	if err != nil {
+		ss.writeLines([]string{"That can't be searched for."})
+		return
+	}
+	next.selector = selector
+
+	ss.history = append(ss.history, next)
+	ss.show()
+}
+
+// show renders the current page, numbering a menu's choices
+func (ss *session) show() {
+	current := ss.current()
+	body := ss.server.gopher.Render(current.selector)
+
+	ss.items = nil
+	if !current.text {
+		if items, ok := gopher.ParseMenu(body); ok {
+			ss.paginate(ss.menuLines(items))
+			return
+		}
+	}
+	ss.paginate(textLines(body))
+}
+
+// menuLines lays out a menu. Items on this server get a number; links
+// elsewhere are shown with their address so the caller can visit them.
+func (ss *session) menuLines(items []gopher.Item) []string {
+	gopherCfg := ss.server.fullConfig.Protocols.Gopher
+	lines := make([]string, 0, len(items))
+
+	for _, item := range items {
+		local := item.Host == gopherCfg.Host && item.Port == gopherCfg.Port
+		switch {
+		case item.Type == gopher.ItemTypeInfo || item.Type == gopher.ItemTypeError:
+			lines = append(lines, "     "+item.Display)
+		case local && (item.Type == gopher.ItemTypeDirectory || item.Type == gopher.ItemTypeTextFile || item.Type == gopher.ItemTypeSearch):
+			ss.items = append(ss.items, item)
+			lines = append(lines, fmt.Sprintf("%3d: %s", len(ss.items), item.Display))
+		case strings.HasPrefix(item.Selector, "URL:"):
+			lines = append(lines, fmt.Sprintf("     %s <%s>", item.Display, strings.TrimPrefix(item.Selector, "URL:")))
+		default:
+			lines = append(lines, fmt.Sprintf("     %s <gopher://%s:%d/%c%s>", item.Display, item.Host, item.Port, item.Type, item.Selector))
+		}
+	}
+	return lines
+}
+
+// textLines splits a text file into lines, dropping the Gopher terminator
+func textLines(body []byte) []string {
+	lines := strings.Split(strings.ReplaceAll(string(body), "\r\n", "\n"), "\n")
+	for len(lines) > 0 && (lines[len(lines)-1] == "" || lines[len(lines)-1] == ".") {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
+
+// paginate writes lines a screenful at a time, stopping early if the caller asks
+func (ss *session) paginate(lines []string) {
+	size := ss.server.config.PageLines
+	for len(lines) > 0 {
+		if size <= 0 || len(lines) <= size {
+			ss.writeLines(lines)
+			return
+		}
+
+		ss.writeLines(lines[:size])
+		lines = lines[size:]
+		ss.write("--More-- (Enter to continue, q to stop) ")
+		input, ok := ss.readLine()
+		if !ok || strings.EqualFold(strings.TrimSpace(input), "q") {
+			return
+		}
+	}
+}
+
+// prompt asks for the next selection
+func (ss *session) prompt() {
+	switch {
+	case ss.current().text:
+		ss.write("\r\n[Enter or b] back  [h] home  [q] quit > ")
+	case len(ss.items) > 0:
+		ss.write(fmt.Sprintf("\r\n[1-%d] open  [b] back  [h] home  [q] quit > ", len(ss.items)))
+	default:
+		ss.write("\r\n[b] back  [h] home  [q] quit > ")
+	}
+}
+
+// help explains the commands
+func (ss *session) help() {
+	ss.writeLines([]string{
+		"",
+		"  <number>   Open that item",
+		"  Enter      Show the menu again, or leave a text page",
+		"  b          Back to the previous page",
+		"  h          Home menu",
+		"  q          Quit",
+	})
+}
+
+// readLine waits for the caller's next line, reporting false once they are gone
+func (ss *session) readLine() (string, bool) {
+	ss.writer.Flush()
+	for {
+		ss.conn.SetReadDeadline(time.Now().Add(idleTimeout))
+		line, err := ss.reader.ReadLine()
+		if err == errLineTooLong {
+			ss.writeLines([]string{"That line is too long."})
+			ss.writer.Flush()
+			continue
+		}
+		return line, err == nil
+	}
+}
+
+// writeLines writes lines with telnet's CRLF line ends
+func (ss *session) writeLines(lines []string) {
+	for _, line := range lines {
+		ss.write(line + "\r\n")
+	}
+}
+
+func (ss *session) write(text string) {
+	ss.conn.SetWriteDeadline(time.Now().Add(time.Minute))
+	ss.writer.WriteString(text)
+}
@@ -0,0 +1,172 @@
+// Package translate translates note content on demand through a LibreTranslate
+// server or a local command, keeping each translation in storage.
+package translate
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os/exec"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/nbd-wtf/go-nostr"
+	"github.com/sandwich/nophr/internal/config"
+	"github.com/sandwich/nophr/internal/storage"
+)
+
+var (
+	// ErrUnsupportedLanguage is returned for languages not in rendering.translation.languages
+	ErrUnsupportedLanguage = errors.New("language not offered")
+
+	// ErrTooLong is returned for notes longer than rendering.translation.max_chars
+	ErrTooLong = errors.New("note is too long to translate")
+
+	// ErrBusy is returned when no translation slot frees up before the timeout
+	ErrBusy = errors.New("too many translations in progress")
+)
+
+// Translator translates events and stores the results, so each note is sent
+// to the provider at most once per language. At most max_concurrent
+// translations run at once.
+type Translator struct {
+	config  *config.Translation
+	storage *storage.Storage
+	client  *http.Client
+	slots   chan struct{}
+}
+
+// New creates a translator for the configured provider
+func New(cfg *config.Translation, st *storage.Storage) *Translator {
+	return &Translator{
+		config:  cfg,
+		storage: st,
+		client:  &http.Client{Timeout: time.Duration(cfg.TimeoutSeconds) * time.Second},
+		slots:   make(chan struct{}, max(cfg.MaxConcurrent, 1)),
+	}
+}
+
+// Enabled reports whether translations are offered
+func (t *Translator) Enabled() bool {
+	return t.config.Enabled
+}
+
+// Languages returns the target languages offered on note pages
+func (t *Translator) Languages() []string {
+	return t.config.Languages
+}
+
+// Translate returns an event's content translated into lang, from storage
+// when it was translated before
+func (t *Translator) Translate(ctx context.Context, event *nostr.Event, lang string) (string, error) {
+	if !t.config.Enabled || !slices.Contains(t.config.Languages, lang) {
+		return "", ErrUnsupportedLanguage
+	}
+
+	if cached, ok, err := t.storage.GetTranslation(ctx, event.ID, lang); err != nil {
+		return "", err
+	} else if ok {
+		return cached, nil
+	}
+
+	if t.config.MaxChars > 0 && len([]rune(event.Content)) > t.config.MaxChars {
+		return "", ErrTooLong
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, time.Duration(t.config.TimeoutSeconds)*time.Second)
+	defer cancel()
+
+	// Waiting for a slot counts towards the timeout
+	select {
+	case t.slots <- struct{}{}:
+		defer func() { <-t.slots }()
+	case <-ctx.Done():
+		return "", ErrBusy
+	}
+
+	var translated string
+	var err error
+	switch t.config.Provider {
+	case config.TranslationCommand:
+		translated, err = t.runCommand(ctx, event.Content, lang)
+	default:
+		translated, err = t.libreTranslate(ctx, event.Content, lang)
+	}
+	if err != nil {
+		return "", err
+	}
+
+	if err := t.storage.SaveTranslation(ctx, event.ID, lang, translated); err != nil {
+		return "", err
+	}
+	return translated, nil
+}
+
+// libreTranslate asks a LibreTranslate server to translate text, detecting the source language
+func (t *Translator) libreTranslate(ctx context.Context, text, lang string) (string, error) {
+	params := map[string]string{
+		"q":      text,
+		"source": "auto",
+		"target": lang,
+		"format": "text",
+	}
+	if t.config.APIKey != "" {
+		params["api_key"] = t.config.APIKey
+	}
+	body, err := json.Marshal(params)
+	if err != nil {
+		return "", err
+	}
+
+	endpoint := strings.TrimSuffix(t.config.URL, "/") + "/translate"
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(body))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := t.client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("failed to reach translation server: %w", err)
+	}
+	defer resp.Body.Close()
+
+	var result struct {
+		TranslatedText string `json:"translatedText"`
+		Error          string `json:"error"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return "", fmt.Errorf("invalid response from translation server: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK || result.Error != "" {
+		return "", fmt.Errorf("translation server returned %d: %s", resp.StatusCode, result.Error)
+	}
+	return result.TranslatedText, nil
+}
+
+// runCommand pipes text through the configured command, with {lang} in its
+// arguments replaced by the target language
+func (t *Translator) runCommand(ctx context.Context, text, lang string) (string, error) {
+	args := make([]string, len(t.config.Command)-1)
+	for i, arg := range t.config.Command[1:] {
+		args[i] = strings.ReplaceAll(arg, "{lang}", lang)
+	}
+
+	cmd := exec.CommandContext(ctx, t.config.Command[0], args...)
+	cmd.Stdin = strings.NewReader(text)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return "", fmt.Errorf("translation command failed: %w: %s", err, msg)
+		}
+		return "", fmt.Errorf("translation command failed: %w", err)
+	}
+	return strings.TrimRight(stdout.String(), "\n"), nil
+}
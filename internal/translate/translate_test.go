@@ -0,0 +1,161 @@
+package translate
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/nbd-wtf/go-nostr"
+	"github.com/sandwich/nophr/internal/config"
+	"github.com/sandwich/nophr/internal/storage"
+)
+
+func TestTranslate(t *testing.T) {
+	ctx := context.Background()
+	st, err := storage.New(ctx, &config.Storage{Driver: "sqlite", SQLitePath: filepath.Join(t.TempDir(), "test.db")})
+	if err != nil {
+		t.Fatalf("Failed to create storage: %v", err)
+	}
+	defer st.Close()
+
+	note := &nostr.Event{Kind: 1, Content: "hola mundo", CreatedAt: nostr.Now()}
+	note.Sign(nostr.GeneratePrivateKey())
+	if err := st.StoreEvent(ctx, note); err != nil {
+		t.Fatalf("Failed to store note: %v", err)
+	}
+
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		var params map[string]string
+		json.NewDecoder(r.Body).Decode(&params)
+		if r.URL.Path != "/translate" || params["target"] != "en" || params["api_key"] != "secret" {
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(`{"error":"bad request"}`))
+			return
+		}
+		w.Write([]byte(`{"translatedText":"hello world"}`))
+	}))
+	defer server.Close()
+
+	cfg := config.DefaultTranslation()
+	cfg.Enabled = true
+	cfg.URL = server.URL + "/"
+	cfg.APIKey = "secret"
+	cfg.Languages = []string{"en", "de"}
+	translator := New(&cfg, st)
+
+	for i := 0; i < 2; i++ {
+		text, err := translator.Translate(ctx, note, "en")
+		if err != nil || text != "hello world" {
+			t.Fatalf("Translate() = %q, %v", text, err)
+		}
+	}
+	if requests != 1 {
+		t.Errorf("Expected the second translation from storage, server saw %d requests", requests)
+	}
+
+	if _, err := translator.Translate(ctx, note, "fr"); !errors.Is(err, ErrUnsupportedLanguage) {
+		t.Errorf("Translate() into an unlisted language error = %v", err)
+	}
+	if _, err := translator.Translate(ctx, note, "de"); err == nil || !strings.Contains(err.Error(), "bad request") {
+		t.Errorf("Translate() with a server error = %v", err)
+	}
+
+	cfg.MaxChars = 5
+	if _, err := translator.Translate(ctx, note, "de"); !errors.Is(err, ErrTooLong) {
+		t.Errorf("Translate() of a long note error = %v", err)
+	}
+
+	t.Run("command", func(t *testing.T) {
+		cfg := config.DefaultTranslation()
+		cfg.Enabled = true
+		cfg.Provider = config.TranslationCommand
+		cfg.Command = []string{"sh", "-c", `printf '[%s] ' "$0"; cat`, "{lang}"}
+		cfg.Languages = []string{"es"}
+
+		text, err := New(&cfg, st).Translate(ctx, note, "es")
+		if err != nil || text != "[es] hola mundo" {
+			t.Errorf("Translate() = %q, %v", text, err)
+		}
+	})
+}
+
+func TestTranslateConcurrency(t *testing.T) {
+	ctx := context.Background()
+	st, err := storage.New(ctx, &config.Storage{Driver: "sqlite", SQLitePath: filepath.Join(t.TempDir(), "test.db")})
+	if err != nil {
+		t.Fatalf("Failed to create storage: %v", err)
+	}
+	defer st.Close()
+
+	var mu sync.Mutex
+	inFlight, peak := 0, 0
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		inFlight++
+		peak = max(peak, inFlight)
+		mu.Unlock()
+		<-release
+		mu.Lock()
+		inFlight--
+		mu.Unlock()
+		w.Write([]byte(`{"translatedText":"hello"}`))
+	}))
+	defer server.Close()
+
+	cfg := config.DefaultTranslation()
+	cfg.Enabled = true
+	cfg.URL = server.URL
+	cfg.MaxConcurrent = 2
+	cfg.TimeoutSeconds = 1
+	translator := New(&cfg, st)
+
+	// Five notes at once: two reach the server, the rest wait for a slot
+	var wg sync.WaitGroup
+	errs := make(chan error, 5)
+	for range 5 {
+		note := &nostr.Event{Kind: 1, Content: "hola", CreatedAt: nostr.Now()}
+		note.Sign(nostr.GeneratePrivateKey())
+		if err := st.StoreEvent(ctx, note); err != nil {
+			t.Fatalf("Failed to store note: %v", err)
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := translator.Translate(ctx, note, "en")
+			errs <- err
+		}()
+	}
+	time.Sleep(200 * time.Millisecond)
+	close(release)
+	wg.Wait()
+	close(errs)
+
+	if peak != 2 {
+		t.Errorf("Expected 2 translations at once, saw %d", peak)
+	}
+	for err := range errs {
+		if err != nil {
+			t.Errorf("Translate() error = %v", err)
+		}
+	}
+
+	// A slot that doesn't free up in time fails the translation
+	cfg.MaxConcurrent = 1
+	blocked := New(&cfg, st)
+	blocked.slots <- struct{}{}
+	note := &nostr.Event{Kind: 1, Content: "adios", CreatedAt: nostr.Now()}
+	note.Sign(nostr.GeneratePrivateKey())
+	if _, err := blocked.Translate(ctx, note, "en"); !errors.Is(err, ErrBusy) {
+		t.Errorf("Translate() without a free slot error = %v, want ErrBusy", err)
+	}
+}
@@ -5,7 +5,13 @@ set -e
 
 VERSION=${VERSION:-dev}
 COMMIT=${COMMIT:-$(git rev-parse --short HEAD 2>/dev/null || echo "unknown")}
-DATE=$(date -u +"%Y-%m-%dT%H:%M:%SZ")
+# Stamp the commit's time rather than the build's, so rebuilding a commit
+# gives the same binary. SOURCE_DATE_EPOCH overrides it.
+if [ -n "$SOURCE_DATE_EPOCH" ]; then
+    DATE=$(date -u -d "@$SOURCE_DATE_EPOCH" +"%Y-%m-%dT%H:%M:%SZ" 2>/dev/null || date -u -r "$SOURCE_DATE_EPOCH" +"%Y-%m-%dT%H:%M:%SZ")
+else
+    DATE=$(TZ=UTC git log -1 --date=format-local:"%Y-%m-%dT%H:%M:%SZ" --format=%cd 2>/dev/null || echo "unknown")
+fi
 BUILT_BY=${BUILT_BY:-$(whoami)}
 
 echo "Building nophr..."
@@ -16,6 +22,7 @@ echo "  Built by: $BUILT_BY"
 echo ""
 
 go build \
+    -trimpath \
     -ldflags "-X main.version=$VERSION -X main.commit=$COMMIT -X main.date=$DATE -X main.builtBy=$BUILT_BY" \
     -o nophr \
     ./cmd/nophr